@@ -533,6 +533,19 @@ type AppSpec struct {
 	SchemaPreprocessorRole *v1.Role          `json:"schemaPreprocessorRole,omitempty" protobuf:"bytes,2,opt,name=schemaPreprocessorRole"`
 
 	PipelineExtension *PipelineExtension `json:"pipelineExtension,omitempty" protobuf:"bytes,3,opt,name=pipelineExtension"`
+
+	// Requires the apps which must be installed before this app such as cert-manager or an ingress controller
+	Requires []AppRequirement `json:"requires,omitempty" protobuf:"bytes,4,rep,name=requires"`
+}
+
+// AppRequirement defines an app which must be installed before another app
+type AppRequirement struct {
+	// Name of the app (the name of its chart)
+	Name string `json:"name" protobuf:"bytes,1,opt,name=name"`
+	// Repository the helm repository of the app, defaults to the repository of the app which requires it
+	Repository string `json:"repository,omitempty" protobuf:"bytes,2,opt,name=repository"`
+	// Version the version of the app to install if it is missing, defaults to the latest version
+	Version string `json:"version,omitempty" protobuf:"bytes,3,opt,name=version"`
 }
 
 // PipelineExtension defines the image and command of an app which wants to modify/extend the pipeline
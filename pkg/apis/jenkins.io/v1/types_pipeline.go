@@ -141,6 +141,26 @@ type PromotePullRequestStep struct {
 
 	PullRequestURL string `json:"pullRequestURL,omitempty" protobuf:"bytes,1,opt,name=pullRequestURL"`
 	MergeCommitSHA string `json:"mergeCommitSHA,omitempty" protobuf:"bytes,2,opt,name=mergeCommitSHA"`
+	// Approval the approval of the promotion if the environment requires promotions to be approved before they merge
+	Approval *PromotionApproval `json:"approval,omitempty" protobuf:"bytes,3,opt,name=approval"`
+}
+
+// PromotionApproval is the approval state of a promotion Pull Request to an environment which requires approvals
+type PromotionApproval struct {
+	Approvers         []string     `json:"approvers,omitempty" protobuf:"bytes,1,opt,name=approvers"`
+	RequiredApprovals int          `json:"requiredApprovals,omitempty" protobuf:"bytes,2,opt,name=requiredApprovals"`
+	ApprovedBy        []string     `json:"approvedBy,omitempty" protobuf:"bytes,3,opt,name=approvedBy"`
+	ApprovedTimestamp *metav1.Time `json:"approvedTimestamp,omitempty" protobuf:"bytes,4,opt,name=approvedTimestamp"`
+}
+
+// IsApproved returns true if enough approvers approved the promotion
+func (a *PromotionApproval) IsApproved() bool {
+	return a == nil || a.ApprovedTimestamp != nil
+}
+
+// IsPending returns true if the promotion is waiting for approvals
+func (a *PromotionApproval) IsPending() bool {
+	return !a.IsApproved()
 }
 
 // PromoteUpdateStep is the step for updating a promotion after the Pull Request merges to master
@@ -89,6 +89,22 @@ func (in *AppList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AppRequirement) DeepCopyInto(out *AppRequirement) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AppRequirement.
+func (in *AppRequirement) DeepCopy() *AppRequirement {
+	if in == nil {
+		return nil
+	}
+	out := new(AppRequirement)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AppSpec) DeepCopyInto(out *AppSpec) {
 	*out = *in
@@ -107,6 +123,11 @@ func (in *AppSpec) DeepCopyInto(out *AppSpec) {
 		*out = new(PipelineExtension)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Requires != nil {
+		in, out := &in.Requires, &out.Requires
+		*out = make([]AppRequirement, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
@@ -1490,6 +1511,22 @@ func (in *FactStatus) DeepCopy() *FactStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GitMirror) DeepCopyInto(out *GitMirror) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GitMirror.
+func (in *GitMirror) DeepCopy() *GitMirror {
+	if in == nil {
+		return nil
+	}
+	out := new(GitMirror)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *GitService) DeepCopyInto(out *GitService) {
 	*out = *in
@@ -1830,6 +1867,32 @@ func (in *Merger) DeepCopy() *Merger {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NotificationTarget) DeepCopyInto(out *NotificationTarget) {
+	*out = *in
+	if in.URLFrom != nil {
+		in, out := &in.URLFrom, &out.URLFrom
+		*out = new(corev1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.To != nil {
+		in, out := &in.To, &out.To
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NotificationTarget.
+func (in *NotificationTarget) DeepCopy() *NotificationTarget {
+	if in == nil {
+		return nil
+	}
+	out := new(NotificationTarget)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Original) DeepCopyInto(out *Original) {
 	*out = *in
@@ -2089,6 +2152,44 @@ func (in *PipelineExtension) DeepCopy() *PipelineExtension {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PipelineNotificationRule) DeepCopyInto(out *PipelineNotificationRule) {
+	*out = *in
+	if in.Repositories != nil {
+		in, out := &in.Repositories, &out.Repositories
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Branches != nil {
+		in, out := &in.Branches, &out.Branches
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Events != nil {
+		in, out := &in.Events, &out.Events
+		*out = make([]PipelineEventType, len(*in))
+		copy(*out, *in)
+	}
+	if in.Targets != nil {
+		in, out := &in.Targets, &out.Targets
+		*out = make([]NotificationTarget, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PipelineNotificationRule.
+func (in *PipelineNotificationRule) DeepCopy() *PipelineNotificationRule {
+	if in == nil {
+		return nil
+	}
+	out := new(PipelineNotificationRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PipelineStageAndChildren) DeepCopyInto(out *PipelineStageAndChildren) {
 	*out = *in
@@ -2575,6 +2676,11 @@ func (in *PromoteActivityStep) DeepCopy() *PromoteActivityStep {
 func (in *PromotePullRequestStep) DeepCopyInto(out *PromotePullRequestStep) {
 	*out = *in
 	in.CoreActivityStep.DeepCopyInto(&out.CoreActivityStep)
+	if in.Approval != nil {
+		in, out := &in.Approval, &out.Approval
+		*out = new(PromotionApproval)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -2588,6 +2694,36 @@ func (in *PromotePullRequestStep) DeepCopy() *PromotePullRequestStep {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PromotionApproval) DeepCopyInto(out *PromotionApproval) {
+	*out = *in
+	if in.Approvers != nil {
+		in, out := &in.Approvers, &out.Approvers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ApprovedBy != nil {
+		in, out := &in.ApprovedBy, &out.ApprovedBy
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ApprovedTimestamp != nil {
+		in, out := &in.ApprovedTimestamp, &out.ApprovedTimestamp
+		*out = (*in).DeepCopy()
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PromotionApproval.
+func (in *PromotionApproval) DeepCopy() *PromotionApproval {
+	if in == nil {
+		return nil
+	}
+	out := new(PromotionApproval)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PromoteUpdateStep) DeepCopyInto(out *PromoteUpdateStep) {
 	*out = *in
@@ -2778,6 +2914,13 @@ func (in *QuickStartLocation) DeepCopyInto(out *QuickStartLocation) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.Variables != nil {
+		in, out := &in.Variables, &out.Variables
+		*out = make([]QuickStartVariable, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
@@ -2791,6 +2934,27 @@ func (in *QuickStartLocation) DeepCopy() *QuickStartLocation {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *QuickStartVariable) DeepCopyInto(out *QuickStartVariable) {
+	*out = *in
+	if in.Values != nil {
+		in, out := &in.Values, &out.Values
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuickStartVariable.
+func (in *QuickStartVariable) DeepCopy() *QuickStartVariable {
+	if in == nil {
+		return nil
+	}
+	out := new(QuickStartVariable)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RegexpChangeMatcher) DeepCopyInto(out *RegexpChangeMatcher) {
 	*out = *in
@@ -3061,6 +3225,74 @@ func (in *RepoContextPolicy) DeepCopy() *RepoContextPolicy {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RepositoryBranchProtection) DeepCopyInto(out *RepositoryBranchProtection) {
+	*out = *in
+	if in.RequiredStatusChecks != nil {
+		in, out := &in.RequiredStatusChecks, &out.RequiredStatusChecks
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RepositoryBranchProtection.
+func (in *RepositoryBranchProtection) DeepCopy() *RepositoryBranchProtection {
+	if in == nil {
+		return nil
+	}
+	out := new(RepositoryBranchProtection)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RepositoryLabel) DeepCopyInto(out *RepositoryLabel) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RepositoryLabel.
+func (in *RepositoryLabel) DeepCopy() *RepositoryLabel {
+	if in == nil {
+		return nil
+	}
+	out := new(RepositoryLabel)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RepositorySettings) DeepCopyInto(out *RepositorySettings) {
+	*out = *in
+	if in.BranchProtection != nil {
+		in, out := &in.BranchProtection, &out.BranchProtection
+		*out = new(RepositoryBranchProtection)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.MergeStrategies != nil {
+		in, out := &in.MergeStrategies, &out.MergeStrategies
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make([]RepositoryLabel, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RepositorySettings.
+func (in *RepositorySettings) DeepCopy() *RepositorySettings {
+	if in == nil {
+		return nil
+	}
+	out := new(RepositorySettings)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ResourceReference) DeepCopyInto(out *ResourceReference) {
 	*out = *in
@@ -3634,6 +3866,23 @@ func (in *TeamSettings) DeepCopyInto(out *TeamSettings) {
 		*out = new(DeployOptions)
 		**out = **in
 	}
+	if in.GitMirrors != nil {
+		in, out := &in.GitMirrors, &out.GitMirrors
+		*out = make([]GitMirror, len(*in))
+		copy(*out, *in)
+	}
+	if in.PipelineNotifications != nil {
+		in, out := &in.PipelineNotifications, &out.PipelineNotifications
+		*out = make([]PipelineNotificationRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.RepositorySettings != nil {
+		in, out := &in.RepositorySettings, &out.RepositorySettings
+		*out = new(RepositorySettings)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -7,6 +7,7 @@ import (
 	"github.com/jenkins-x/jx/v2/pkg/kube"
 	"github.com/jenkins-x/jx/v2/pkg/kube/naming"
 	"github.com/jenkins-x/jx/v2/pkg/kube/services"
+	"github.com/jenkins-x/jx/v2/pkg/log"
 	"github.com/jenkins-x/jx/v2/pkg/util"
 	"github.com/pkg/errors"
 	appsv1 "k8s.io/api/apps/v1"
@@ -87,8 +88,19 @@ func (d Deployment) URL(kc kubernetes.Interface, a Application) string {
 	return url
 }
 
+// KubeClientFn returns the kube client and namespace used to access the deployments of an environment, which may run
+// on a remote cluster
+type KubeClientFn func(env *v1.Environment) (kubernetes.Interface, string, error)
+
 // GetApplications fetches all Applications
 func GetApplications(factory clients.Factory) (List, error) {
+	return GetApplicationsWithKubeClients(factory, nil)
+}
+
+// GetApplicationsWithKubeClients fetches all Applications, accessing the deployments of each environment with the
+// client returned by the given function so that environments on remote clusters are included. The deployments of all
+// environments are read from the current cluster if the function is nil
+func GetApplicationsWithKubeClients(factory clients.Factory, kubeClientFn KubeClientFn) (List, error) {
 	list := List{
 		Items: make([]Application, 0),
 	}
@@ -129,16 +141,9 @@ func GetApplications(factory clients.Factory) (List, error) {
 	kubeClient, _, err := factory.CreateKubeClient()
 
 	// fetch deployments by environment (excluding dev)
-	deployments := make(map[string]map[string]appsv1.Deployment)
-	for _, env := range permanentEnvsMap {
-		if env.Spec.Kind != v1.EnvironmentKindTypeDevelopment {
-			envDeployments, err := kube.GetDeployments(kubeClient, env.Spec.Namespace)
-			if err != nil {
-				return list, err
-			}
-
-			deployments[env.Spec.Namespace] = envDeployments
-		}
+	deployments, err := getEnvironmentDeployments(permanentEnvsMap, kubeClient, kubeClientFn)
+	if err != nil {
+		return list, err
 	}
 
 	err = list.appendMatchingDeployments(permanentEnvsMap, deployments)
@@ -149,6 +154,34 @@ func GetApplications(factory clients.Factory) (List, error) {
 	return list, nil
 }
 
+// getEnvironmentDeployments returns the deployments of the environments other than dev indexed by the namespace of the
+// environment. Environments whose cluster cannot be accessed via the kube client function are skipped so that one
+// unreachable remote cluster does not hide the applications of the others
+func getEnvironmentDeployments(envs map[string]*v1.Environment, kubeClient kubernetes.Interface, kubeClientFn KubeClientFn) (map[string]map[string]appsv1.Deployment, error) {
+	deployments := make(map[string]map[string]appsv1.Deployment)
+	for _, env := range envs {
+		if env.Spec.Kind == v1.EnvironmentKindTypeDevelopment {
+			continue
+		}
+		if kubeClientFn == nil {
+			envDeployments, err := kube.GetDeployments(kubeClient, env.Spec.Namespace)
+			if err != nil {
+				return deployments, err
+			}
+			deployments[env.Spec.Namespace] = envDeployments
+			continue
+		}
+		envClient, ns, err := kubeClientFn(env)
+		if err == nil {
+			deployments[env.Spec.Namespace], err = kube.GetDeployments(envClient, ns)
+		}
+		if err != nil {
+			log.Logger().Warnf("failed to get the deployments of environment %s: %s", env.Name, err)
+		}
+	}
+	return deployments, nil
+}
+
 func getDeploymentAppNameInEnvironment(d appsv1.Deployment, e *v1.Environment) (string, error) {
 	labels, err := metav1.LabelSelectorAsMap(d.Spec.Selector)
 	if err != nil {
@@ -3,12 +3,16 @@
 package applications
 
 import (
+	"fmt"
 	"testing"
 
 	v1 "github.com/jenkins-x/jx/v2/pkg/apis/jenkins.io/v1"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	appsv1 "k8s.io/api/apps/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	kubefake "k8s.io/client-go/kubernetes/fake"
 )
 
 func TestAppendMatchingDeployments(t *testing.T) {
@@ -159,3 +163,44 @@ func TestAppendMatchingDeployments(t *testing.T) {
 		}
 	}
 }
+
+func TestGetEnvironmentDeploymentsFromRemoteClusters(t *testing.T) {
+	deployment := func(name string, ns string) *appsv1.Deployment {
+		return &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: ns}}
+	}
+	env := func(name string, ns string, kind v1.EnvironmentKindType) *v1.Environment {
+		return &v1.Environment{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec:       v1.EnvironmentSpec{Namespace: ns, Kind: kind},
+		}
+	}
+	envs := map[string]*v1.Environment{
+		"jx":            env("dev", "jx", v1.EnvironmentKindTypeDevelopment),
+		"jx-staging":    env("staging", "jx-staging", v1.EnvironmentKindTypePermanent),
+		"jx-production": env("production", "jx-production", v1.EnvironmentKindTypePermanent),
+		"jx-qa":         env("qa", "jx-qa", v1.EnvironmentKindTypePermanent),
+	}
+	localClient := kubefake.NewSimpleClientset(deployment("jx-myapp", "jx-staging"))
+	remoteClient := kubefake.NewSimpleClientset(deployment("jx-myapp", "production"))
+
+	deployments, err := getEnvironmentDeployments(envs, localClient, func(env *v1.Environment) (kubernetes.Interface, string, error) {
+		switch env.Name {
+		case "production":
+			return remoteClient, "production", nil
+		case "qa":
+			return nil, "", fmt.Errorf("cluster unreachable")
+		default:
+			return localClient, env.Spec.Namespace, nil
+		}
+	})
+	require.NoError(t, err)
+	assert.NotContains(t, deployments, "jx")
+	assert.NotContains(t, deployments, "jx-qa")
+	assert.Contains(t, deployments["jx-staging"], "jx-myapp")
+	assert.Contains(t, deployments["jx-production"], "jx-myapp")
+
+	deployments, err = getEnvironmentDeployments(envs, localClient, nil)
+	require.NoError(t, err)
+	assert.Contains(t, deployments["jx-staging"], "jx-myapp")
+	assert.Empty(t, deployments["jx-production"])
+}
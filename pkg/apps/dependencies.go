@@ -0,0 +1,189 @@
+package apps
+
+import (
+	"fmt"
+	"strings"
+
+	jenkinsv1 "github.com/jenkins-x/jx/v2/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/v2/pkg/environments"
+	"github.com/jenkins-x/jx/v2/pkg/helm"
+	"github.com/jenkins-x/jx/v2/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+)
+
+// InstallPlan is the ordered list of apps to install so that each app is installed after the apps it requires
+type InstallPlan struct {
+	// Steps the apps to install in order, the last step is the app being added
+	Steps []jenkinsv1.AppRequirement
+	// Installed the required apps which are already installed
+	Installed []string
+	// Missing the required apps which could not be found along with the reason
+	Missing map[string]string
+}
+
+// String returns a description of the plan suitable for showing to the user
+func (p *InstallPlan) String() string {
+	var sb strings.Builder
+	for i, step := range p.Steps {
+		sb.WriteString(fmt.Sprintf("  %d. %s", i+1, step.Name))
+		if step.Version != "" {
+			sb.WriteString(" " + step.Version)
+		}
+		if reason, ok := p.Missing[step.Name]; ok {
+			sb.WriteString(fmt.Sprintf(" (missing: %s)", reason))
+		}
+		sb.WriteString("\n")
+	}
+	for _, name := range p.Installed {
+		sb.WriteString(fmt.Sprintf("  - %s (already installed)\n", name))
+	}
+	return sb.String()
+}
+
+// PlanInstall works out the order to install the app and the apps it requires, transitively, which are not installed
+// yet. The requires function returns the apps an app requires, if it fails the app is recorded as missing so that the
+// whole plan can be reported rather than failing on the first missing prerequisite. An error is returned if the
+// requirements are circular.
+func PlanInstall(app jenkinsv1.AppRequirement, installed func(name string) (bool, error),
+	requires func(app jenkinsv1.AppRequirement) ([]jenkinsv1.AppRequirement, error)) (*InstallPlan, error) {
+	plan := &InstallPlan{
+		Missing: map[string]string{},
+	}
+	visited := map[string]bool{}
+	visiting := map[string]bool{}
+	var visit func(app jenkinsv1.AppRequirement, path []string) error
+	visit = func(app jenkinsv1.AppRequirement, path []string) error {
+		if visited[app.Name] {
+			return nil
+		}
+		path = append(path, app.Name)
+		if visiting[app.Name] {
+			return errors.Errorf("circular app requirements %s", strings.Join(path, " -> "))
+		}
+		visiting[app.Name] = true
+		required, err := requires(app)
+		if err != nil {
+			plan.Missing[app.Name] = err.Error()
+		}
+		for _, r := range required {
+			if r.Repository == "" {
+				r.Repository = app.Repository
+			}
+			if !visited[r.Name] && !visiting[r.Name] {
+				found, err := installed(r.Name)
+				if err != nil {
+					return errors.Wrapf(err, "checking if %s is installed", r.Name)
+				}
+				if found {
+					visited[r.Name] = true
+					plan.Installed = append(plan.Installed, r.Name)
+					continue
+				}
+			}
+			err = visit(r, path)
+			if err != nil {
+				return err
+			}
+		}
+		visiting[app.Name] = false
+		visited[app.Name] = true
+		plan.Steps = append(plan.Steps, app)
+		return nil
+	}
+	err := visit(app, nil)
+	if err != nil {
+		return nil, err
+	}
+	return plan, nil
+}
+
+// planAddApp works out the apps which must be installed before the app by inspecting the App resource of each chart
+func (o *InstallOptions) planAddApp(app string, version string, repository string, username string,
+	password string) (*InstallPlan, error) {
+	requires := func(app jenkinsv1.AppRequirement) ([]jenkinsv1.AppRequirement, error) {
+		u, p := repositoryCredentials(app.Repository, repository, username, password)
+		return o.appRequirements(app, u, p)
+	}
+	return PlanInstall(jenkinsv1.AppRequirement{
+		Name:       app,
+		Version:    version,
+		Repository: repository,
+	}, o.isAppInstalled, requires)
+}
+
+// appRequirements returns the apps required by the app by locating the App resource in its chart
+func (o *InstallOptions) appRequirements(app jenkinsv1.AppRequirement, username string, password string) ([]jenkinsv1.AppRequirement, error) {
+	_, err := helm.AddHelmRepoIfMissing(app.Repository, "", username, password, o.Helmer, o.VaultClient, o.IOFileHandles)
+	if err != nil {
+		return nil, errors.Wrapf(err, "adding helm repo %s", app.Repository)
+	}
+	chartName, err := o.resolvePrefixesAgainstRepos(app.Repository, app.Name)
+	if err != nil {
+		return nil, err
+	}
+	var answer []jenkinsv1.AppRequirement
+	inspectFn := func(dir string) error {
+		appResource, _, err := environments.LocateAppResource(o.Helmer, dir, app.Name)
+		if err != nil {
+			return errors.Wrapf(err, "locating app resource in %s", dir)
+		}
+		answer = appResource.Spec.Requires
+		return nil
+	}
+	err = helm.InspectChart(chartName, app.Version, app.Repository, username, password, o.Helmer, inspectFn)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to find chart %s in %s", app.Name, app.Repository)
+	}
+	return answer, nil
+}
+
+func (o *InstallOptions) isAppInstalled(name string) (bool, error) {
+	apps, err := o.GetApps([]string{name})
+	if err != nil {
+		return false, err
+	}
+	return apps != nil && len(apps.Items) > 0, nil
+}
+
+// installRequiredApps installs the apps the plan requires before the app being added
+func (o *InstallOptions) installRequiredApps(plan *InstallPlan, repository string, username string, password string,
+	helmUpdate bool) error {
+	app := plan.Steps[len(plan.Steps)-1].Name
+	if _, ok := plan.Missing[app]; ok && len(plan.Missing) == 1 {
+		// the app itself could not be inspected so leave adding it to report the problem
+		log.Logger().Debugf("unable to resolve the apps required by %s: %s", app, plan.Missing[app])
+		return nil
+	}
+	if len(plan.Missing) > 0 {
+		return errors.Errorf("unable to add %s as the apps it requires could not be found, the install plan is:\n%s",
+			app, plan.String())
+	}
+	if len(plan.Steps) > 1 {
+		log.Logger().Infof("Installing the apps %s requires:\n%s", util.ColorInfo(app), plan.String())
+	}
+	// the upgrade policy only applies to the app being added
+	upgradePolicy := o.UpgradePolicy
+	o.UpgradePolicy = ""
+	defer func() {
+		o.UpgradePolicy = upgradePolicy
+	}()
+	for _, step := range plan.Steps[:len(plan.Steps)-1] {
+		log.Logger().Infof("Adding required app %s", util.ColorInfo(step.Name))
+		u, p := repositoryCredentials(step.Repository, repository, username, password)
+		err := o.addApp(step.Name, step.Version, step.Repository, u, p, "", nil, nil, "", helmUpdate)
+		if err != nil {
+			return errors.Wrapf(err, "adding required app %s", step.Name)
+		}
+	}
+	return nil
+}
+
+// repositoryCredentials only uses the credentials given for the repository of the app being added for the apps it
+// requires from the same repository
+func repositoryCredentials(appRepository string, repository string, username string, password string) (string, string) {
+	if appRepository != repository {
+		return "", ""
+	}
+	return username, password
+}
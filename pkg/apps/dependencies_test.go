@@ -0,0 +1,72 @@
+// +build unit
+
+package apps_test
+
+import (
+	"testing"
+
+	jenkinsv1 "github.com/jenkins-x/jx/v2/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/v2/pkg/apps"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlanInstall(t *testing.T) {
+	requirements := map[string][]jenkinsv1.AppRequirement{
+		"jx-app-docs":  {{Name: "cert-manager"}, {Name: "nginx-ingress"}},
+		"cert-manager": {{Name: "nginx-ingress", Repository: "https://kubernetes-charts.storage.googleapis.com"}},
+	}
+	requires := func(app jenkinsv1.AppRequirement) ([]jenkinsv1.AppRequirement, error) {
+		return requirements[app.Name], nil
+	}
+	installed := func(name string) (bool, error) {
+		return name == "velero", nil
+	}
+	plan, err := apps.PlanInstall(jenkinsv1.AppRequirement{Name: "jx-app-docs", Repository: "http://chartmuseum"},
+		installed, requires)
+	require.NoError(t, err)
+
+	assert.Equal(t, []jenkinsv1.AppRequirement{
+		{Name: "nginx-ingress", Repository: "https://kubernetes-charts.storage.googleapis.com"},
+		{Name: "cert-manager", Repository: "http://chartmuseum"},
+		{Name: "jx-app-docs", Repository: "http://chartmuseum"},
+	}, plan.Steps)
+	assert.Empty(t, plan.Missing)
+
+	requirements["jx-app-docs"] = append(requirements["jx-app-docs"], jenkinsv1.AppRequirement{Name: "velero"})
+	plan, err = apps.PlanInstall(jenkinsv1.AppRequirement{Name: "jx-app-docs"}, installed, requires)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"velero"}, plan.Installed)
+	assert.Len(t, plan.Steps, 3)
+}
+
+func TestPlanInstallMissing(t *testing.T) {
+	requires := func(app jenkinsv1.AppRequirement) ([]jenkinsv1.AppRequirement, error) {
+		if app.Name == "cert-manager" {
+			return nil, errors.New("chart not found")
+		}
+		return []jenkinsv1.AppRequirement{{Name: "cert-manager"}}, nil
+	}
+	installed := func(name string) (bool, error) {
+		return false, nil
+	}
+	plan, err := apps.PlanInstall(jenkinsv1.AppRequirement{Name: "jx-app-docs"}, installed, requires)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"cert-manager": "chart not found"}, plan.Missing)
+	assert.Equal(t, "  1. cert-manager (missing: chart not found)\n  2. jx-app-docs\n", plan.String())
+}
+
+func TestPlanInstallCircular(t *testing.T) {
+	requires := func(app jenkinsv1.AppRequirement) ([]jenkinsv1.AppRequirement, error) {
+		if app.Name == "a" {
+			return []jenkinsv1.AppRequirement{{Name: "b"}}, nil
+		}
+		return []jenkinsv1.AppRequirement{{Name: "a"}}, nil
+	}
+	installed := func(name string) (bool, error) {
+		return false, nil
+	}
+	_, err := apps.PlanInstall(jenkinsv1.AppRequirement{Name: "a"}, installed, requires)
+	assert.EqualError(t, err, "circular app requirements a -> b -> a")
+}
@@ -5,11 +5,13 @@ import (
 
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/jenkins-x/jx/v2/pkg/gits"
 
 	"github.com/jenkins-x/jx/v2/pkg/helm"
 	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/jenkins-x/jx/v2/pkg/versionstream"
 	"k8s.io/helm/pkg/proto/hapi/chart"
 
 	"github.com/ghodss/yaml"
@@ -35,11 +37,30 @@ func (o *GitOpsOptions) AddApp(app string, dir string, version string, repositor
 		Message:    fmt.Sprintf("Add app %s %s", app, version),
 	}
 
+	modifyChartFn := environments.CreateAddRequirementFn(app, alias, version, repository, o.valuesFiles, dir,
+		o.Verbose, o.Helmer)
+	if o.UpgradePolicy != "" {
+		addRequirementFn := modifyChartFn
+		modifyChartFn = func(requirements *helm.Requirements, metadata *chart.Metadata, values map[string]interface{},
+			templates map[string]string, envDir string, details *gits.PullRequestDetails) error {
+			err := addRequirementFn(requirements, metadata, values, templates, envDir, details)
+			if err != nil {
+				return err
+			}
+			err = os.MkdirAll(filepath.Join(envDir, app), util.DefaultWritePermissions)
+			if err != nil {
+				return errors.Wrapf(err, "creating the directory of app %s", app)
+			}
+			return environments.SaveAppMetadata(envDir, app, &environments.AppMetadata{
+				UpgradePolicy: o.UpgradePolicy,
+			})
+		}
+	}
 	options := environments.EnvironmentPullRequestOptions{
-		Gitter: o.Gitter,
-		ModifyChartFn: environments.CreateAddRequirementFn(app, alias, version,
-			repository, o.valuesFiles, dir, o.Verbose, o.Helmer),
-		GitProvider: o.GitProvider,
+		Gitter:        o.Gitter,
+		ModifyChartFn: modifyChartFn,
+		GitProvider:   o.GitProvider,
+		Helmer:        o.Helmer,
 	}
 
 	info, err := options.Create(o.DevEnv, o.EnvironmentCloneDir, &details, nil, "", autoMerge)
@@ -98,53 +119,63 @@ func (o *GitOpsOptions) UpgradeApp(app string, version string, repository string
 		return nil
 	}
 
+	streamVersionFn, err := o.createStreamVersionFn()
+	if err != nil {
+		return err
+	}
+	manualApps := []*helm.Dependency{}
 	options := environments.EnvironmentPullRequestOptions{
 		Gitter: o.Gitter,
 		ModifyChartFn: environments.CreateUpgradeRequirementsFn(all, app, alias, version, username, password,
-			o.Helmer, inspectChartFunc, o.Verbose, o.valuesFiles),
+			o.Helmer, inspectChartFunc, o.Verbose, o.valuesFiles, streamVersionFn, &manualApps),
 		GitProvider: o.GitProvider,
+		Helmer:      o.Helmer,
 	}
 
 	_, err = options.Create(o.DevEnv, o.EnvironmentCloneDir, &details, nil, app, autoMerge)
 	if err != nil {
 		return err
 	}
+
+	// apps which need a manual approval are upgraded in pull requests of their own which are never merged
+	// automatically so that they do not hold up the upgrade of the other apps
+	for _, d := range manualApps {
+		log.Logger().Infof("Upgrading %s in a separate pull request as its upgrade policy is %s", util.ColorInfo(d.Name),
+			environments.UpgradePolicyManual)
+		err = o.UpgradeApp(d.Name, "", repository, username, password, d.Alias, interrogateChartFunc, false)
+		if err != nil {
+			return errors.Wrapf(err, "upgrading app %s", d.Name)
+		}
+	}
 	return nil
 }
 
-// DeleteApp deletes the app with alias
-func (o *GitOpsOptions) DeleteApp(app string, alias string, autoMerge bool) error {
-
-	modifyChartFn := func(requirements *helm.Requirements, metadata *chart.Metadata, values map[string]interface{},
-		templates map[string]string, dir string, details *gits.PullRequestDetails) error {
-		// See if the app already exists in requirements
-		found := false
-		for i, d := range requirements.Dependencies {
-			if d.Name == app && d.Alias == alias {
-				found = true
-				requirements.Dependencies = append(requirements.Dependencies[:i], requirements.Dependencies[i+1:]...)
-			}
-		}
-		// If app not found, add it
-		if !found {
-			a := app
-			if alias != "" {
-				a = fmt.Sprintf("%s with alias %s", a, alias)
-			}
-			return fmt.Errorf("unable to delete app %s as not installed", app)
+// createStreamVersionFn creates the function which returns the version of an app in the version stream, or nil if
+// there is no version stream
+func (o *GitOpsOptions) createStreamVersionFn() (func(dependency *helm.Dependency) (string, error), error) {
+	if o.VersionResolver == nil {
+		return nil, nil
+	}
+	prefixes, err := o.VersionResolver.GetRepositoryPrefixes()
+	if err != nil {
+		return nil, errors.Wrap(err, "loading the repository prefixes of the version stream")
+	}
+	return func(dependency *helm.Dependency) (string, error) {
+		prefix := prefixes.PrefixForURL(dependency.Repository)
+		if prefix == "" {
+			return "", nil
 		}
-		if info, err := os.Stat(filepath.Join(dir, app)); err == nil {
-			if info.IsDir() {
-				err := util.DeleteFile(info.Name())
-				if err != nil {
-					return err
-				}
-			} else {
-				log.Logger().Warnf("Not removing %s for %s because it is not a directory", info.Name(), app)
-			}
+		stableVersion, err := o.VersionResolver.StableVersion(versionstream.KindChart, prefix+"/"+dependency.Name)
+		if err != nil {
+			return "", err
 		}
-		return nil
-	}
+		return stableVersion.Version, nil
+	}, nil
+}
+
+// DeleteApp deletes the app with alias, removing its values and the files generated when it was added so that
+// nothing is left behind in the environment
+func (o *GitOpsOptions) DeleteApp(app string, alias string, autoMerge bool) error {
 	details := gits.PullRequestDetails{
 		BranchName: "delete-app-" + app,
 		Title:      fmt.Sprintf("Delete %s", app),
@@ -153,8 +184,9 @@ func (o *GitOpsOptions) DeleteApp(app string, alias string, autoMerge bool) erro
 
 	options := environments.EnvironmentPullRequestOptions{
 		Gitter:        o.Gitter,
-		ModifyChartFn: modifyChartFn,
+		ModifyChartFn: environments.CreateRemoveRequirementFn(app, alias),
 		GitProvider:   o.GitProvider,
+		Helmer:        o.Helmer,
 	}
 
 	info, err := options.Create(o.DevEnv, o.EnvironmentCloneDir, &details, nil, "", autoMerge)
@@ -162,6 +194,33 @@ func (o *GitOpsOptions) DeleteApp(app string, alias string, autoMerge bool) erro
 		return err
 	}
 	log.Logger().Infof("Delete app via Pull Request %s", info.PullRequest.URL)
+	if o.WaitTimeout > 0 {
+		return o.WaitForAppRemoval(app, o.WaitTimeout)
+	}
+	return nil
+}
+
+// WaitForAppRemoval waits for the pipeline of the environment to uninstall the app once the pull request deleting it
+// merges, by polling for the App resource of the app in the cluster until it is removed or the timeout is reached
+func (o *GitOpsOptions) WaitForAppRemoval(app string, timeout time.Duration) error {
+	helmOpts := HelmOpsOptions{
+		InstallOptions: o.InstallOptions,
+	}
+	log.Logger().Infof("Waiting up to %s for the release of %s to be uninstalled", timeout.String(), util.ColorInfo(app))
+	err := util.Retry(timeout, func() error {
+		apps, err := helmOpts.getAppsFromCRDAPI([]string{app})
+		if err != nil {
+			return err
+		}
+		if len(apps.Items) > 0 {
+			return errors.Errorf("app %s is still installed", app)
+		}
+		return nil
+	})
+	if err != nil {
+		return errors.Wrapf(err, "waiting for app %s to be uninstalled", app)
+	}
+	log.Logger().Infof("App %s has been uninstalled", util.ColorInfo(app))
 	return nil
 }
 
@@ -54,6 +54,12 @@ type InstallOptions struct {
 	VaultClient         vault.Client
 	AutoMerge           bool
 	SecretsScheme       string
+	// WaitTimeout if set deleting an app with GitOps waits up to this long for the release to be uninstalled
+	WaitTimeout time.Duration
+	// UpgradePolicy if set is stored in the metadata of an app added with GitOps
+	UpgradePolicy environments.UpgradePolicy
+	// VersionResolver if set apps upgraded with GitOps are upgraded to the version in the version stream
+	VersionResolver *versionstream.VersionResolver
 
 	valuesFiles *environments.ValuesFiles // internal variable used to track, most be passed in
 }
@@ -61,10 +67,26 @@ type InstallOptions struct {
 // AddApp adds the app at a particular version (
 // or latest if not specified) from the repository with username and password. A releaseName can be specified.
 // Values can be passed with in files or as a slice of name=value pairs. An alias can be specified.
-// GitOps or HelmOps will be automatically chosen based on the o.GitOps flag
+// GitOps or HelmOps will be automatically chosen based on the o.GitOps flag.
+// Any apps required by the app which are not installed yet are added first, if any of them cannot be found
+// nothing is added and the install plan is returned in the error
 func (o *InstallOptions) AddApp(app string, version string, repository string, username string, password string,
 	releaseName string, valuesFiles []string, setValues []string, alias string, helmUpdate bool) error {
+	if !helm.IsLocal(app) {
+		plan, err := o.planAddApp(app, version, repository, username, password)
+		if err != nil {
+			return errors.Wrapf(err, "resolving the apps required by %s", app)
+		}
+		err = o.installRequiredApps(plan, repository, username, password, helmUpdate)
+		if err != nil {
+			return err
+		}
+	}
+	return o.addApp(app, version, repository, username, password, releaseName, valuesFiles, setValues, alias, helmUpdate)
+}
 
+func (o *InstallOptions) addApp(app string, version string, repository string, username string, password string,
+	releaseName string, valuesFiles []string, setValues []string, alias string, helmUpdate bool) error {
 	o.valuesFiles = &environments.ValuesFiles{
 		Items: valuesFiles,
 	}
@@ -636,3 +658,39 @@ func (o *InstallOptions) resolvePrefixesAgainstRepos(repository string, chartNam
 	}
 	return chartName, nil
 }
+
+// ResolveChartRepository resolves a chart reference of the form repo/chart, where repo is the name of a helm
+// repository known to the helmer, into the chart name and the URL of the repository. This allows apps to be added
+// from any helm repository and not only from the apps repository of the team. References of the form
+// oci://registry/repository/chart to charts stored in OCI registries are split into the chart name and repository. If
+// the reference does not name a repository the chart is returned unchanged with an empty URL.
+func ResolveChartRepository(helmer helm.Helmer, chart string) (string, string, error) {
+	if helm.IsOCIRepository(chart) {
+		repoURL, chartName := helm.SplitOCIChartReference(chart)
+		if chartName == "" {
+			return "", "", errors.Errorf("invalid OCI chart reference %s, should be of the form oci://registry/repository/chart", chart)
+		}
+		return chartName, repoURL, nil
+	}
+	if helm.IsLocal(chart) {
+		return chart, "", nil
+	}
+	parts := strings.Split(chart, "/")
+	if len(parts) != 2 {
+		return chart, "", nil
+	}
+	repoName, chartName := parts[0], parts[1]
+	if repoName == "" || chartName == "" {
+		return "", "", errors.Errorf("invalid chart reference %s, should be of the form repo/chart", chart)
+	}
+	repos, err := helmer.ListRepos()
+	if err != nil {
+		return "", "", errors.Wrapf(err, "listing helm repos")
+	}
+	repoURL, ok := repos[repoName]
+	if !ok {
+		return "", "", errors.Errorf("no helm repository called %s, use --repository to specify the URL of the repository of %s",
+			repoName, chartName)
+	}
+	return chartName, repoURL, nil
+}
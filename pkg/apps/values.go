@@ -2,6 +2,7 @@ package apps
 
 import (
 	"encoding/base64"
+	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"strings"
@@ -20,6 +21,7 @@ import (
 	"github.com/jenkins-x/jx/v2/pkg/client/clientset/versioned"
 	"github.com/pkg/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/helm/pkg/strvals"
 )
 
 const (
@@ -89,6 +91,36 @@ func AddValuesToChart(name string, values []byte, verbose bool) (string, func(),
 	return valuesFile.Name(), cleanup, nil
 }
 
+// MergeValues merges the values files and the set values (using the same syntax as helm --set) into a single values
+// file so that they can be written into the GitOps repository of an environment. Later values files and the set
+// values override earlier values. The path to the values file is returned along with a function to remove it.
+func MergeValues(name string, valuesFiles []string, setValues []string, verbose bool) (string, func(), error) {
+	values := map[string]interface{}{}
+	for _, valuesFile := range valuesFiles {
+		data, err := ioutil.ReadFile(valuesFile)
+		if err != nil {
+			return "", func() {}, errors.Wrapf(err, "reading values file %s", valuesFile)
+		}
+		fileValues := map[string]interface{}{}
+		err = yaml.Unmarshal(data, &fileValues)
+		if err != nil {
+			return "", func() {}, errors.Wrapf(err, "unmarshaling values file %s", valuesFile)
+		}
+		util.CombineMapTrees(values, fileValues)
+	}
+	for _, setValue := range setValues {
+		err := strvals.ParseInto(setValue, values)
+		if err != nil {
+			return "", func() {}, errors.Wrapf(err, "parsing set value %s", setValue)
+		}
+	}
+	data, err := json.Marshal(values)
+	if err != nil {
+		return "", func() {}, errors.Wrapf(err, "marshaling values for %s", name)
+	}
+	return AddValuesToChart(name, data, verbose)
+}
+
 //GenerateQuestions asks questions based on the schema
 func GenerateQuestions(schema []byte, batchMode bool, askExisting bool, basePath string, secretURLClient secreturl.Client,
 	existing map[string]interface{}, vaultScheme string, handles util.IOFileHandles) ([]byte, error) {
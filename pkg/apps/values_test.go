@@ -0,0 +1,76 @@
+// +build unit
+
+package apps_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ghodss/yaml"
+	"github.com/jenkins-x/jx/v2/pkg/apps"
+	helm_test "github.com/jenkins-x/jx/v2/pkg/helm/mocks"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/petergtz/pegomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMergeValues(t *testing.T) {
+	dir, err := ioutil.TempDir("", "test-merge-values-")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir) //nolint:errcheck
+
+	first := filepath.Join(dir, "first.yaml")
+	err = ioutil.WriteFile(first, []byte("cluster:\n  enabled: true\n  slaveCount: 2\nimage: redis\n"), util.DefaultWritePermissions)
+	require.NoError(t, err)
+	second := filepath.Join(dir, "second.yaml")
+	err = ioutil.WriteFile(second, []byte("cluster:\n  slaveCount: 3\n"), util.DefaultWritePermissions)
+	require.NoError(t, err)
+
+	fileName, cleanup, err := apps.MergeValues("redis", []string{first, second}, []string{"cluster.enabled=false,password=secret"}, false)
+	defer cleanup()
+	require.NoError(t, err)
+
+	data, err := ioutil.ReadFile(fileName)
+	require.NoError(t, err)
+	values := map[string]interface{}{}
+	err = yaml.Unmarshal(data, &values)
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]interface{}{
+		"cluster": map[string]interface{}{
+			"enabled":    false,
+			"slaveCount": float64(3),
+		},
+		"image":    "redis",
+		"password": "secret",
+	}, values)
+}
+
+func TestResolveChartRepository(t *testing.T) {
+	pegomock.RegisterMockTestingT(t)
+	helmer := helm_test.NewMockHelmer()
+	pegomock.When(helmer.ListRepos()).ThenReturn(map[string]string{
+		"bitnami": "https://charts.bitnami.com/bitnami",
+	}, nil)
+
+	chart, repoURL, err := apps.ResolveChartRepository(helmer, "bitnami/redis")
+	require.NoError(t, err)
+	assert.Equal(t, "redis", chart)
+	assert.Equal(t, "https://charts.bitnami.com/bitnami", repoURL)
+
+	chart, repoURL, err = apps.ResolveChartRepository(helmer, "jx-app-jacoco")
+	require.NoError(t, err)
+	assert.Equal(t, "jx-app-jacoco", chart)
+	assert.Equal(t, "", repoURL)
+
+	chart, repoURL, err = apps.ResolveChartRepository(helmer, "oci://ghcr.io/myorg/charts/myapp")
+	require.NoError(t, err)
+	assert.Equal(t, "myapp", chart)
+	assert.Equal(t, "oci://ghcr.io/myorg/charts", repoURL)
+
+	_, _, err = apps.ResolveChartRepository(helmer, "unknown/redis")
+	assert.Error(t, err)
+}
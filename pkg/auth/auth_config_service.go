@@ -1,5 +1,10 @@
 package auth
 
+import (
+	"github.com/jenkins-x/jx/v2/pkg/log"
+	"github.com/pkg/errors"
+)
+
 // Config gets the AuthConfig from the service
 func (s *AuthConfigService) Config() *AuthConfig {
 	if s.config == nil {
@@ -38,11 +43,25 @@ func (s *AuthConfigService) DeleteServer(url string) error {
 	return s.handler.SaveConfig(s.config)
 }
 
-// LoadConfig loads the configuration from the users JX config directory
+// LoadConfig loads the configuration from the users JX config directory. The expired tokens of users who logged in
+// with an OpenID Connect identity provider are refreshed and saved
 func (s *AuthConfigService) LoadConfig() (*AuthConfig, error) {
 	var err error
 	s.config, err = s.handler.LoadConfig()
-	return s.config, err
+	if err != nil || s.config == nil {
+		return s.config, err
+	}
+	refreshed, err := RefreshOIDCTokens(s.config)
+	if err != nil {
+		log.Logger().Warnf("%s", err)
+	}
+	if refreshed {
+		err = s.handler.SaveConfig(s.config)
+		if err != nil {
+			return s.config, errors.Wrap(err, "saving the refreshed tokens")
+		}
+	}
+	return s.config, nil
 }
 
 // SaveConfig saves the configuration to disk
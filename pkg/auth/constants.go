@@ -13,4 +13,17 @@ const (
 	GitAuthConfigFile = "gitAuth.yaml"
 	// ChartmuseumAuthConfigFile config file for chartmusuem auth credentials
 	ChartmuseumAuthConfigFile = "chartmuseumAuth.yaml"
+	// OIDCAuthConfigFile config file for the tokens obtained by logging in with an OpenID Connect identity provider
+	OIDCAuthConfigFile = "oidcAuth.yaml"
 )
+
+// LocalAuthConfigFiles the auth config files stored in the jx config directory
+var LocalAuthConfigFiles = []string{
+	AddonAuthConfigFile,
+	JenkinsAuthConfigFile,
+	IssuesAuthConfigFile,
+	ChatAuthConfigFile,
+	GitAuthConfigFile,
+	ChartmuseumAuthConfigFile,
+	OIDCAuthConfigFile,
+}
@@ -0,0 +1,237 @@
+package auth
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/url"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/jenkins-x/jx/v2/pkg/log"
+	"github.com/pkg/errors"
+)
+
+const (
+	// credentialHelperPrefix the prefix of the executables implementing the docker credential helper protocol
+	credentialHelperPrefix = "docker-credential-"
+
+	// credentialsNotFound the message credential helpers return when they have no credentials for a server
+	credentialsNotFound = "credentials not found in native keychain"
+
+	// HelperSecretAPIToken indicates the API token of the user is stored by the credential helper
+	HelperSecretAPIToken = "apitoken"
+	// HelperSecretPassword indicates the password of the user is stored by the credential helper
+	HelperSecretPassword = "password"
+	// HelperSecretBearerToken indicates the bearer token of the user is stored by the credential helper
+	HelperSecretBearerToken = "bearertoken"
+)
+
+// CredentialHelper stores the secrets of users outside of the auth config, such as in the OS keychain, pass or a
+// cloud secret manager
+type CredentialHelper interface {
+	// Get returns the username and secret stored for the key, the secret is empty if none is stored
+	Get(key string) (string, string, error)
+	// Store stores the username and secret for the key
+	Store(key string, username string, secret string) error
+}
+
+var (
+	credentialHelpersLock sync.Mutex
+	credentialHelpers     = map[string]CredentialHelper{}
+)
+
+// RegisterCredentialHelper registers a credential helper implemented in process with the given name. Names which are
+// not registered use the docker-credential-<name> executable on the PATH
+func RegisterCredentialHelper(name string, helper CredentialHelper) {
+	credentialHelpersLock.Lock()
+	defer credentialHelpersLock.Unlock()
+	credentialHelpers[name] = helper
+}
+
+// NewCredentialHelper returns the credential helper with the given name
+func NewCredentialHelper(name string) CredentialHelper {
+	credentialHelpersLock.Lock()
+	defer credentialHelpersLock.Unlock()
+	if helper, ok := credentialHelpers[name]; ok {
+		return helper
+	}
+	command := name
+	if filepath.Base(name) == name {
+		command = credentialHelperPrefix + name
+	}
+	return &ExecCredentialHelper{Command: command}
+}
+
+// ExecCredentialHelper runs an executable implementing the docker credential helper protocol such as
+// docker-credential-osxkeychain, docker-credential-pass or docker-credential-secretservice
+type ExecCredentialHelper struct {
+	Command string
+}
+
+// credentialHelperCredentials the credentials exchanged with docker credential helpers
+type credentialHelperCredentials struct {
+	ServerURL string `json:"ServerURL"`
+	Username  string `json:"Username"`
+	Secret    string `json:"Secret"`
+}
+
+// Get returns the username and secret stored for the key
+func (h *ExecCredentialHelper) Get(key string) (string, string, error) {
+	out, err := h.run("get", []byte(key))
+	if err != nil {
+		if strings.Contains(out, credentialsNotFound) {
+			return "", "", nil
+		}
+		return "", "", err
+	}
+	credentials := &credentialHelperCredentials{}
+	err = json.Unmarshal([]byte(out), credentials)
+	if err != nil {
+		return "", "", errors.Wrapf(err, "parsing the credentials returned by %s", h.Command)
+	}
+	return credentials.Username, credentials.Secret, nil
+}
+
+// Store stores the username and secret for the key
+func (h *ExecCredentialHelper) Store(key string, username string, secret string) error {
+	data, err := json.Marshal(&credentialHelperCredentials{
+		ServerURL: key,
+		Username:  username,
+		Secret:    secret,
+	})
+	if err != nil {
+		return err
+	}
+	_, err = h.run("store", data)
+	return err
+}
+
+func (h *ExecCredentialHelper) run(action string, input []byte) (string, error) {
+	cmd := exec.Command(h.Command, action) // #nosec
+	cmd.Stdin = bytes.NewReader(input)
+	out, err := cmd.CombinedOutput()
+	text := strings.TrimSpace(string(out))
+	if err != nil {
+		return text, errors.Wrapf(err, "running %s %s: %s", h.Command, action, text)
+	}
+	return text, nil
+}
+
+// CredentialHelperKey returns the key the secret of the user of a server is stored under. The username is included
+// in the URL so that the secrets of several users of the same server can be stored
+func CredentialHelperKey(serverURL string, username string) string {
+	u, err := url.Parse(serverURL)
+	if err != nil || u.Host == "" || username == "" {
+		return serverURL
+	}
+	u.User = url.User(username)
+	return u.String()
+}
+
+// CredentialHelperName returns the name of the credential helper for the server, if any
+func (c *AuthConfig) CredentialHelperName(server *AuthServer) string {
+	if server.CredentialHelper != "" {
+		return server.CredentialHelper
+	}
+	return c.CredentialHelper
+}
+
+// credentialHelperConfigHandler stores the secrets of users with the credential helpers of their servers, so only
+// references to them are saved by the wrapped handler
+type credentialHelperConfigHandler struct {
+	handler ConfigHandler
+	helper  func(name string) CredentialHelper
+}
+
+func newCredentialHelperConfigHandler(handler ConfigHandler) ConfigHandler {
+	return &credentialHelperConfigHandler{
+		handler: handler,
+		helper:  NewCredentialHelper,
+	}
+}
+
+// LoadConfig loads the configuration and the secrets stored by credential helpers
+func (h *credentialHelperConfigHandler) LoadConfig() (*AuthConfig, error) {
+	config, err := h.handler.LoadConfig()
+	if err != nil || config == nil {
+		return config, err
+	}
+	for _, server := range config.Servers {
+		name := config.CredentialHelperName(server)
+		if name == "" {
+			continue
+		}
+		helper := h.helper(name)
+		for _, user := range server.Users {
+			if user.HelperSecret == "" {
+				continue
+			}
+			_, secret, err := helper.Get(CredentialHelperKey(server.URL, user.Username))
+			if err != nil {
+				log.Logger().Warnf("failed to get the secret of user %s for server %s from credential helper %s: %s", user.Username, server.URL, name, err)
+				continue
+			}
+			setHelperSecret(user, user.HelperSecret, secret)
+		}
+	}
+	return config, nil
+}
+
+// SaveConfig stores the secrets of the users of servers with credential helpers and saves the configuration without them
+func (h *credentialHelperConfigHandler) SaveConfig(config *AuthConfig) error {
+	saved := *config
+	saved.Servers = []*AuthServer{}
+	for _, server := range config.Servers {
+		name := config.CredentialHelperName(server)
+		if name == "" {
+			saved.Servers = append(saved.Servers, server)
+			continue
+		}
+		helper := h.helper(name)
+		serverCopy := *server
+		serverCopy.Users = []*UserAuth{}
+		for _, user := range server.Users {
+			userCopy := *user
+			field, secret := helperSecret(user)
+			if secret != "" {
+				err := helper.Store(CredentialHelperKey(server.URL, user.Username), user.Username, secret)
+				if err != nil {
+					return errors.Wrapf(err, "storing the secret of user %s for server %s with credential helper %s", user.Username, server.URL, name)
+				}
+				setHelperSecret(&userCopy, field, "")
+				userCopy.HelperSecret = field
+			}
+			serverCopy.Users = append(serverCopy.Users, &userCopy)
+		}
+		saved.Servers = append(saved.Servers, &serverCopy)
+	}
+	return h.handler.SaveConfig(&saved)
+}
+
+// helperSecret returns the secret of the user which is stored by credential helpers and the field it is from
+func helperSecret(user *UserAuth) (string, string) {
+	switch {
+	case user.ApiToken != "":
+		return HelperSecretAPIToken, user.ApiToken
+	case user.Password != "":
+		return HelperSecretPassword, user.Password
+	case user.BearerToken != "":
+		return HelperSecretBearerToken, user.BearerToken
+	}
+	return user.HelperSecret, ""
+}
+
+func setHelperSecret(user *UserAuth, field string, secret string) {
+	switch field {
+	case HelperSecretAPIToken:
+		user.ApiToken = secret
+	case HelperSecretPassword:
+		user.Password = secret
+	case HelperSecretBearerToken:
+		user.BearerToken = secret
+	default:
+		log.Logger().Warnf("ignoring the unknown credential helper secret %s of user %s", field, user.Username)
+	}
+}
@@ -0,0 +1,58 @@
+// +build unit
+
+package auth
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeCredentialHelper struct {
+	secrets map[string]string
+}
+
+func (f *fakeCredentialHelper) Get(key string) (string, string, error) {
+	return "", f.secrets[key], nil
+}
+
+func (f *fakeCredentialHelper) Store(key string, username string, secret string) error {
+	f.secrets[key] = secret
+	return nil
+}
+
+func TestCredentialHelperConfigHandler(t *testing.T) {
+	t.Parallel()
+
+	memory := &MemoryAuthConfigHandler{}
+	keychain := &fakeCredentialHelper{secrets: map[string]string{}}
+	handler := &credentialHelperConfigHandler{
+		handler: memory,
+		helper: func(name string) CredentialHelper {
+			assert.Equal(t, "keychain", name)
+			return keychain
+		},
+	}
+	svc := NewAuthConfigService(handler)
+
+	config := svc.Config()
+	config.GetOrCreateServerName("https://github.com", "github", "github").CredentialHelper = "keychain"
+	config.GetOrCreateUserAuth("https://github.com", "jstrachan").ApiToken = "github-token"
+	config.GetOrCreateUserAuth("https://gitlab.com", "jstrachan").ApiToken = "gitlab-token"
+	require.NoError(t, svc.SaveConfig())
+
+	assert.Equal(t, map[string]string{"https://jstrachan@github.com": "github-token"}, keychain.secrets)
+	saved := memory.config.GetServer("https://github.com").Users[0]
+	assert.Empty(t, saved.ApiToken, "the token should not be saved in the auth config")
+	assert.Equal(t, HelperSecretAPIToken, saved.HelperSecret)
+	assert.Equal(t, "gitlab-token", memory.config.GetServer("https://gitlab.com").Users[0].ApiToken)
+	assert.Equal(t, "github-token", config.FindUserAuth("https://github.com", "jstrachan").ApiToken, "the loaded config should keep the token")
+
+	loaded, err := svc.LoadConfig()
+	require.NoError(t, err)
+	user := loaded.FindUserAuth("https://github.com", "jstrachan")
+	require.NotNil(t, user)
+	assert.Equal(t, "github-token", user.ApiToken)
+	assert.False(t, user.IsInvalid())
+}
@@ -0,0 +1,277 @@
+package auth
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"sync"
+
+	"github.com/jenkins-x/jx/v2/pkg/log"
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/scrypt"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// EncryptionKeychainEnvVar the environment variable with the name of the credential helper storing the key the
+	// local auth config files are encrypted with. The keychain of the operating system is used if it is not set
+	EncryptionKeychainEnvVar = "JX_AUTH_KEYCHAIN"
+	// NoEncryptionKeychain the keychain name which disables the encryption of the local auth config files
+	NoEncryptionKeychain = "none"
+	// PlatformEncryptionKeychain the keychain name which selects the keychain of the operating system
+	PlatformEncryptionKeychain = "default"
+
+	// EncryptionFormat the format of encrypted auth config files
+	EncryptionFormat = "aes-256-gcm"
+
+	// encryptionKeyServer the key the encryption key is stored under in the keychain
+	encryptionKeyServer = "https://jenkins-x.io/jx/auth-encryption-key"
+	encryptionKeyUser   = "jx"
+	encryptionKeySize   = 32
+	saltSize            = 16
+)
+
+// EncryptedFile the contents of an encrypted auth config file
+type EncryptedFile struct {
+	// Encrypted the format the data is encrypted with
+	Encrypted string `json:"encrypted"`
+	// Salt the salt used to derive the key from a passphrase, empty if the key is stored in the keychain
+	Salt string `json:"salt,omitempty"`
+	// Data the nonce and the encrypted data
+	Data string `json:"data"`
+}
+
+var (
+	localEncryptionKeyLock sync.Mutex
+	localEncryptionKey     []byte
+	localEncryptionKeyErr  error
+	localEncryptionKeyDone bool
+)
+
+// IsEncryptedFile returns true if the data is an encrypted auth config file
+func IsEncryptedFile(data []byte) bool {
+	return parseEncryptedFile(data) != nil
+}
+
+// EncryptionKeychain returns the name of the credential helper storing the key the local auth config files are
+// encrypted with from $JX_AUTH_KEYCHAIN, defaulting to the keychain of the operating system. The files are stored in
+// plain text if the keychain is not available or the variable is 'none'
+func EncryptionKeychain() string {
+	name := os.Getenv(EncryptionKeychainEnvVar)
+	switch name {
+	case "", PlatformEncryptionKeychain:
+		return platformEncryptionKeychain()
+	default:
+		return name
+	}
+}
+
+// platformEncryptionKeychain returns the credential helper of the keychain of the operating system
+func platformEncryptionKeychain() string {
+	switch runtime.GOOS {
+	case "darwin":
+		return "osxkeychain"
+	case "windows":
+		return "wincred"
+	default:
+		return "secretservice"
+	}
+}
+
+// LocalEncryptionKey returns the key the local auth config files are encrypted with, creating and storing it in the
+// keychain the first time. Returns nil if encryption is disabled or the keychain is not available or fails, in which
+// case the files are stored in plain text
+func LocalEncryptionKey() ([]byte, error) {
+	localEncryptionKeyLock.Lock()
+	defer localEncryptionKeyLock.Unlock()
+	if !localEncryptionKeyDone {
+		name := EncryptionKeychain()
+		localEncryptionKey, localEncryptionKeyErr = keychainEncryptionKey(name)
+		if localEncryptionKeyErr != nil {
+			message := fmt.Sprintf("storing the local auth config files in plain text as the keychain %s failed: %s", name, localEncryptionKeyErr)
+			// the default keychain of the platform often fails on build machines without a desktop session
+			if os.Getenv(EncryptionKeychainEnvVar) == "" {
+				log.Logger().Debug(message)
+			} else {
+				log.Logger().Warn(message)
+			}
+			localEncryptionKey, localEncryptionKeyErr = nil, nil
+		}
+		localEncryptionKeyDone = true
+	}
+	return localEncryptionKey, localEncryptionKeyErr
+}
+
+// keychainEncryptionKey returns the key stored in the keychain, creating it if it does not exist yet
+func keychainEncryptionKey(name string) ([]byte, error) {
+	if name == NoEncryptionKeychain {
+		return nil, nil
+	}
+	helper := NewCredentialHelper(name)
+	if execHelper, ok := helper.(*ExecCredentialHelper); ok {
+		if _, err := exec.LookPath(execHelper.Command); err != nil {
+			log.Logger().Debugf("not encrypting the local auth config files as the keychain %s is not available", execHelper.Command)
+			return nil, nil
+		}
+	}
+	_, secret, err := helper.Get(encryptionKeyServer)
+	if err != nil {
+		return nil, errors.Wrapf(err, "getting the auth config encryption key from the keychain %s", name)
+	}
+	if secret != "" {
+		key, err := base64.StdEncoding.DecodeString(secret)
+		if err != nil || len(key) != encryptionKeySize {
+			return nil, errors.Errorf("the auth config encryption key in the keychain %s is invalid", name)
+		}
+		return key, nil
+	}
+	key, err := randomBytes(encryptionKeySize)
+	if err != nil {
+		return nil, err
+	}
+	err = helper.Store(encryptionKeyServer, encryptionKeyUser, base64.StdEncoding.EncodeToString(key))
+	if err != nil {
+		return nil, errors.Wrapf(err, "storing the auth config encryption key in the keychain %s", name)
+	}
+	return key, nil
+}
+
+// EncryptFile encrypts the data of an auth config file with a key derived from the given key and the label, so each
+// file is encrypted with its own key
+func EncryptFile(key []byte, label string, data []byte) ([]byte, error) {
+	return encrypt(deriveKey(key, label), "", data)
+}
+
+// DecryptFile decrypts an auth config file encrypted by EncryptFile with the same key and label
+func DecryptFile(key []byte, label string, data []byte) ([]byte, error) {
+	file := parseEncryptedFile(data)
+	if file == nil {
+		return nil, errors.New("the data is not encrypted")
+	}
+	return decrypt(deriveKey(key, label), file)
+}
+
+// EncryptWithPassphrase encrypts the data with a key derived from the passphrase so it can be decrypted on another
+// machine
+func EncryptWithPassphrase(passphrase string, data []byte) ([]byte, error) {
+	if passphrase == "" {
+		return nil, errors.New("no passphrase specified")
+	}
+	salt, err := randomBytes(saltSize)
+	if err != nil {
+		return nil, err
+	}
+	key, err := passphraseKey(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+	return encrypt(key, base64.StdEncoding.EncodeToString(salt), data)
+}
+
+// DecryptWithPassphrase decrypts the data encrypted by EncryptWithPassphrase with the same passphrase
+func DecryptWithPassphrase(passphrase string, data []byte) ([]byte, error) {
+	file := parseEncryptedFile(data)
+	if file == nil || file.Salt == "" {
+		return nil, errors.New("the data is not encrypted with a passphrase")
+	}
+	salt, err := base64.StdEncoding.DecodeString(file.Salt)
+	if err != nil {
+		return nil, errors.Wrap(err, "decoding the salt")
+	}
+	key, err := passphraseKey(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+	return decrypt(key, file)
+}
+
+func encrypt(key []byte, salt string, data []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := randomBytes(gcm.NonceSize())
+	if err != nil {
+		return nil, err
+	}
+	sealed := gcm.Seal(nonce, nonce, data, nil)
+	return yaml.Marshal(&EncryptedFile{
+		Encrypted: EncryptionFormat,
+		Salt:      salt,
+		Data:      base64.StdEncoding.EncodeToString(sealed),
+	})
+}
+
+func decrypt(key []byte, file *EncryptedFile) ([]byte, error) {
+	if file.Encrypted != EncryptionFormat {
+		return nil, errors.Errorf("unsupported encryption format %s", file.Encrypted)
+	}
+	sealed, err := base64.StdEncoding.DecodeString(file.Data)
+	if err != nil {
+		return nil, errors.Wrap(err, "decoding the encrypted data")
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < gcm.NonceSize() {
+		return nil, errors.New("the encrypted data is too short")
+	}
+	nonce := sealed[:gcm.NonceSize()]
+	data, err := gcm.Open(nil, nonce, sealed[gcm.NonceSize():], nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "decrypting the data, the key or passphrase may be wrong")
+	}
+	return data, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, errors.Wrap(err, "creating the cipher")
+	}
+	return cipher.NewGCM(block)
+}
+
+func parseEncryptedFile(data []byte) *EncryptedFile {
+	if len(data) == 0 {
+		return nil
+	}
+	file := &EncryptedFile{}
+	err := yaml.Unmarshal(data, file)
+	if err != nil || file.Encrypted == "" || file.Data == "" {
+		return nil
+	}
+	return file
+}
+
+func deriveKey(key []byte, label string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(label)) //nolint:errcheck
+	return mac.Sum(nil)
+}
+
+func passphraseKey(passphrase string, salt []byte) ([]byte, error) {
+	key, err := scrypt.Key([]byte(passphrase), salt, 1<<15, 8, 1, encryptionKeySize)
+	if err != nil {
+		return nil, errors.Wrap(err, "deriving the key from the passphrase")
+	}
+	return key, nil
+}
+
+func randomBytes(size int) ([]byte, error) {
+	answer := make([]byte, size)
+	_, err := io.ReadFull(rand.Reader, answer)
+	if err != nil {
+		return nil, errors.Wrap(err, "generating random bytes")
+	}
+	return answer, nil
+}
@@ -0,0 +1,156 @@
+// +build unit
+
+package auth
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncryptFile(t *testing.T) {
+	t.Parallel()
+
+	key, err := randomBytes(encryptionKeySize)
+	require.NoError(t, err)
+
+	data, err := EncryptFile(key, GitAuthConfigFile, []byte("servers: []\n"))
+	require.NoError(t, err)
+	assert.True(t, IsEncryptedFile(data))
+	assert.NotContains(t, string(data), "servers")
+
+	decrypted, err := DecryptFile(key, GitAuthConfigFile, data)
+	require.NoError(t, err)
+	assert.Equal(t, "servers: []\n", string(decrypted))
+
+	_, err = DecryptFile(key, JenkinsAuthConfigFile, data)
+	assert.Error(t, err, "each file should be encrypted with its own key")
+
+	assert.False(t, IsEncryptedFile([]byte("servers: []\n")))
+	assert.False(t, IsEncryptedFile(nil))
+}
+
+func TestKeychainEncryptionKey(t *testing.T) {
+	t.Parallel()
+
+	keychain := &fakeCredentialHelper{secrets: map[string]string{}}
+	RegisterCredentialHelper("test-encryption-keychain", keychain)
+
+	key, err := keychainEncryptionKey("test-encryption-keychain")
+	require.NoError(t, err)
+	assert.Len(t, key, encryptionKeySize)
+	assert.Contains(t, keychain.secrets, encryptionKeyServer, "the key should be stored in the keychain")
+
+	again, err := keychainEncryptionKey("test-encryption-keychain")
+	require.NoError(t, err)
+	assert.Equal(t, key, again, "the stored key should be reused")
+
+	key, err = keychainEncryptionKey(NoEncryptionKeychain)
+	require.NoError(t, err)
+	assert.Nil(t, key)
+}
+
+func TestFileAuthConfigHandlerEncryptsPlainTextFiles(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "test-auth-encryption-")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	fileName := filepath.Join(dir, GitAuthConfigFile)
+	err = ioutil.WriteFile(fileName, []byte("servers:\n- url: https://github.com\n  users:\n  - username: jstrachan\n    apitoken: mytoken\n"), 0600)
+	require.NoError(t, err)
+
+	key, err := randomBytes(encryptionKeySize)
+	require.NoError(t, err)
+	handler := &FileAuthConfigHandler{
+		fileName: fileName,
+		encryptionKey: func() ([]byte, error) {
+			return key, nil
+		},
+	}
+
+	config, err := handler.LoadConfig()
+	require.NoError(t, err)
+	assert.Equal(t, "mytoken", config.FindUserAuth("https://github.com", "jstrachan").ApiToken)
+
+	data, err := ioutil.ReadFile(fileName)
+	require.NoError(t, err)
+	assert.False(t, IsEncryptedFile(data), "the plain text file should not be rewritten when it is loaded")
+
+	err = handler.SaveConfig(config)
+	require.NoError(t, err)
+	data, err = ioutil.ReadFile(fileName)
+	require.NoError(t, err)
+	assert.True(t, IsEncryptedFile(data), "the plain text file should be encrypted when it is saved")
+	assert.NotContains(t, string(data), "mytoken")
+
+	config, err = handler.LoadConfig()
+	require.NoError(t, err)
+	assert.Equal(t, "mytoken", config.FindUserAuth("https://github.com", "jstrachan").ApiToken)
+
+	handler.encryptionKey = nil
+	_, err = handler.LoadConfig()
+	assert.Error(t, err, "encrypted files cannot be loaded without the key")
+
+	handler.serverKind = string(GitServerKind)
+	_, err = handler.LoadConfig()
+	require.Error(t, err, "encrypted git auth files should not fall back to the git credentials")
+	assert.Contains(t, err.Error(), fileName)
+	handler.serverKind = ""
+
+	handler.encryptionKey = func() ([]byte, error) {
+		return nil, errors.New("the keychain is locked")
+	}
+	err = handler.SaveConfig(config)
+	require.NoError(t, err, "a failing keychain should fall back to plain text")
+	data, err = ioutil.ReadFile(fileName)
+	require.NoError(t, err)
+	assert.False(t, IsEncryptedFile(data))
+	assert.Contains(t, string(data), "mytoken")
+}
+
+func TestEncryptionKeychainDefaultsToThePlatform(t *testing.T) {
+	defer os.Setenv(EncryptionKeychainEnvVar, os.Getenv(EncryptionKeychainEnvVar))
+
+	os.Unsetenv(EncryptionKeychainEnvVar)
+	assert.Equal(t, platformEncryptionKeychain(), EncryptionKeychain(), "the platform keychain should be used by default")
+
+	os.Setenv(EncryptionKeychainEnvVar, NoEncryptionKeychain)
+	assert.Equal(t, NoEncryptionKeychain, EncryptionKeychain())
+
+	os.Setenv(EncryptionKeychainEnvVar, "pass")
+	assert.Equal(t, "pass", EncryptionKeychain())
+
+	os.Setenv(EncryptionKeychainEnvVar, PlatformEncryptionKeychain)
+	assert.Equal(t, platformEncryptionKeychain(), EncryptionKeychain())
+}
+
+func TestExportAndImportConfigs(t *testing.T) {
+	t.Parallel()
+
+	config := &AuthConfig{}
+	config.GetOrCreateUserAuth("https://github.com", "jstrachan").ApiToken = "mytoken"
+
+	data, err := ExportConfigs(map[string]*AuthConfig{GitAuthConfigFile: config}, "my passphrase")
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "mytoken")
+
+	_, err = ImportConfigs(data, "wrong passphrase")
+	assert.Error(t, err)
+
+	configs, err := ImportConfigs(data, "my passphrase")
+	require.NoError(t, err)
+	require.Contains(t, configs, GitAuthConfigFile)
+	assert.Equal(t, "mytoken", configs[GitAuthConfigFile].FindUserAuth("https://github.com", "jstrachan").ApiToken)
+
+	data, err = ExportConfigs(map[string]*AuthConfig{"../gitAuth.yaml": config}, "my passphrase")
+	require.NoError(t, err)
+	_, err = ImportConfigs(data, "my passphrase")
+	assert.Error(t, err, "files outside of the config directory should not be imported")
+}
@@ -0,0 +1,47 @@
+package auth
+
+import (
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/yaml"
+)
+
+// ConfigExport the local auth configs exported to move them between machines
+type ConfigExport struct {
+	// Configs the auth configs keyed by the name of their file in the jx config directory
+	Configs map[string]*AuthConfig `json:"configs"`
+}
+
+// ExportConfigs returns the auth configs keyed by file name encrypted with the passphrase. The secrets stored by
+// credential helpers should already be loaded into the configs so that they are exported too
+func ExportConfigs(configs map[string]*AuthConfig, passphrase string) ([]byte, error) {
+	data, err := yaml.Marshal(&ConfigExport{Configs: configs})
+	if err != nil {
+		return nil, errors.Wrap(err, "marshalling the auth configs")
+	}
+	data, err = EncryptWithPassphrase(passphrase, data)
+	if err != nil {
+		return nil, errors.Wrap(err, "encrypting the auth configs")
+	}
+	return data, nil
+}
+
+// ImportConfigs returns the auth configs keyed by file name decrypted with the passphrase they were exported with
+func ImportConfigs(data []byte, passphrase string) (map[string]*AuthConfig, error) {
+	data, err := DecryptWithPassphrase(passphrase, data)
+	if err != nil {
+		return nil, errors.Wrap(err, "decrypting the auth configs")
+	}
+	export := &ConfigExport{}
+	err = yaml.Unmarshal(data, export)
+	if err != nil {
+		return nil, errors.Wrap(err, "unmarshalling the auth configs")
+	}
+	for fileName, config := range export.Configs {
+		if fileName != filepath.Base(fileName) || config == nil {
+			return nil, errors.Errorf("invalid auth config file %q in the export", fileName)
+		}
+	}
+	return export.Configs, nil
+}
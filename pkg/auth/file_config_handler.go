@@ -5,6 +5,7 @@ import (
 	"io/ioutil"
 	"path/filepath"
 
+	"github.com/jenkins-x/jx/v2/pkg/log"
 	"github.com/jenkins-x/jx/v2/pkg/util"
 	"github.com/pkg/errors"
 	"sigs.k8s.io/yaml"
@@ -16,10 +17,11 @@ type ServerKind string
 // GitServerKind indicate the server kind for git
 const GitServerKind ServerKind = "git"
 
-// NewFileAuthConfigService creates a new file config service
+// NewFileAuthConfigService creates a new file config service. The secrets of the users of servers with credential
+// helpers are stored by the helpers rather than in the file
 func NewFileAuthConfigService(filename string, serverKind string) (ConfigService, error) {
 	handler, err := newFileAuthConfigHandler(filename, serverKind)
-	return NewAuthConfigService(handler), err
+	return NewAuthConfigService(newCredentialHelperConfigHandler(handler)), err
 }
 
 // newFileAuthConfigHandler creates a new FileBasedAuthConfigService that stores its data under the given filename
@@ -27,7 +29,8 @@ func NewFileAuthConfigService(filename string, serverKind string) (ConfigService
 // Config directory
 func newFileAuthConfigHandler(fileName string, serverKind string) (ConfigHandler, error) {
 	svc := &FileAuthConfigHandler{
-		serverKind: serverKind,
+		serverKind:    serverKind,
+		encryptionKey: LocalEncryptionKey,
 	}
 	// If the fileName is an absolute path, use that. Otherwise treat it as a config filename to be used in
 	if fileName == filepath.Base(fileName) {
@@ -42,35 +45,69 @@ func newFileAuthConfigHandler(fileName string, serverKind string) (ConfigHandler
 	return svc, nil
 }
 
-// loadFileAuth loads the auth config from given file
-func (s *FileAuthConfigHandler) loadFileAuth(fileName string) (*AuthConfig, error) {
+// loadFileAuth loads the auth config from given file, decrypting it if it is encrypted. Returns true if the file was
+// encrypted
+func (s *FileAuthConfigHandler) loadFileAuth(fileName string) (*AuthConfig, bool, error) {
 	if fileName == "" {
-		return nil, fmt.Errorf("empty file name for auth config")
+		return nil, false, fmt.Errorf("empty file name for auth config")
 	}
 	exists, err := util.FileExists(fileName)
 	if err != nil {
-		return nil, fmt.Errorf("checking if the auth config file exists %s due to %s", fileName, err)
+		return nil, false, fmt.Errorf("checking if the auth config file exists %s due to %s", fileName, err)
 	}
 	if !exists {
-		return nil, fmt.Errorf("auth config file %q does not exist", fileName)
+		return nil, false, fmt.Errorf("auth config file %q does not exist", fileName)
 	}
 	data, err := ioutil.ReadFile(fileName)
 	if err != nil {
-		return nil, errors.Wrapf(err, "loading the auth config from file %q", fileName)
+		return nil, false, errors.Wrapf(err, "loading the auth config from file %q", fileName)
+	}
+	encrypted := IsEncryptedFile(data)
+	if encrypted {
+		data, err = s.decrypt(fileName, data)
+		if err != nil {
+			return nil, true, err
+		}
 	}
 	config := &AuthConfig{}
 	if err := yaml.Unmarshal(data, config); err != nil {
-		return nil, errors.Wrapf(err, "unmarshaling the auth config YAML from file %q", fileName)
+		return nil, encrypted, errors.Wrapf(err, "unmarshaling the auth config YAML from file %q", fileName)
 	}
-	return config, nil
+	return config, encrypted, nil
 }
 
-// LoadConfig loads the configuration from the users JX config directory
+// decrypt decrypts the auth config file with the key from the keychain
+func (s *FileAuthConfigHandler) decrypt(fileName string, data []byte) ([]byte, error) {
+	key, err := s.key()
+	if err != nil {
+		return nil, err
+	}
+	if key == nil {
+		return nil, errors.Errorf("the auth config file %q is encrypted but the keychain %s storing its key is not available, set $%s to the keychain", fileName, EncryptionKeychain(), EncryptionKeychainEnvVar)
+	}
+	data, err = DecryptFile(key, filepath.Base(fileName), data)
+	if err != nil {
+		return nil, errors.Wrapf(err, "decrypting the auth config file %q, use 'jx auth import' to restore it from an export", fileName)
+	}
+	return data, nil
+}
+
+// key returns the key auth config files are encrypted with or nil if they are stored in plain text
+func (s *FileAuthConfigHandler) key() ([]byte, error) {
+	if s.encryptionKey == nil {
+		return nil, nil
+	}
+	return s.encryptionKey()
+}
+
+// LoadConfig loads the configuration from the users JX config directory. Files stored in plain text are left as they
+// are and only encrypted when the configuration is saved
 func (s *FileAuthConfigHandler) LoadConfig() (*AuthConfig, error) {
-	config, err := s.loadFileAuth(s.fileName)
+	config, encrypted, err := s.loadFileAuth(s.fileName)
 	if err != nil {
-		// Try to load the auth config from git credentials file
-		if s.serverKind == string(GitServerKind) {
+		// Try to load the auth config from git credentials file unless the file exists but cannot be decrypted, as
+		// falling back would hide the credentials of the file
+		if s.serverKind == string(GitServerKind) && !encrypted {
 			gitConfig, err := loadGitCredentialsAuth()
 			if err != nil {
 				return nil, errors.Wrap(err, "loading the auth config from git credentials file")
@@ -92,5 +129,16 @@ func (s *FileAuthConfigHandler) SaveConfig(config *AuthConfig) error {
 	if err != nil {
 		return err
 	}
+	key, err := s.key()
+	if err != nil {
+		log.Logger().Warnf("saving the auth config file %q in plain text: %s", fileName, err)
+		key = nil
+	}
+	if key != nil {
+		data, err = EncryptFile(key, filepath.Base(fileName), data)
+		if err != nil {
+			return errors.Wrapf(err, "encrypting the auth config file %q", fileName)
+		}
+	}
 	return ioutil.WriteFile(fileName, data, util.DefaultWritePermissions)
 }
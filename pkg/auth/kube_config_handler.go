@@ -2,6 +2,7 @@ package auth
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 
 	"github.com/jenkins-x/jx/v2/pkg/util"
@@ -22,6 +23,8 @@ const (
 	labelCredentialsType = "jenkins.io/credentials-type"
 	// labelGithubAppOwner the label to indicate the owner of a repository for github app token secrets
 	labelGithubAppOwner = "jenkins.io/githubapp-owner"
+	// annotationScopes the comma separated organisation or repository patterns the credentials are restricted to
+	annotationScopes = "jenkins.io/scopes"
 	// valueCreatedByJX for resources created by the Jenkins X CLI
 	valueCreatedByJX = "jx"
 	// valueCredentialTypeUsernamePassword for user password credential secrets
@@ -36,6 +39,16 @@ const (
 	usernameKey = "username"
 	// secretDataPassword the password in a Secret/Credentials
 	passwordKey = "password"
+	// githubAppIDKey the ID of the GitHub App in a Secret/Credentials
+	githubAppIDKey = "appId"
+	// githubAppInstallationIDKey the ID of the GitHub App installation in a Secret/Credentials
+	githubAppInstallationIDKey = "appInstallationId"
+	// githubAppPrivateKeyKey the PEM encoded private key of the GitHub App in a Secret/Credentials
+	githubAppPrivateKeyKey = "appPrivateKey"
+	// sshPrivateKeyKey the PEM encoded ssh private key in a Secret/Credentials
+	sshPrivateKeyKey = "sshPrivateKey"
+	// sshKnownHostsKey the known_hosts lines of the git server in a Secret/Credentials
+	sshKnownHostsKey = "knownHosts"
 	// secretPrefix prefix for pipeline secrets
 	secretPrefix = "jx-pipeline"
 )
@@ -63,19 +76,21 @@ func (k *KubeAuthConfigHandler) LoadConfig() (*AuthConfig, error) {
 					continue
 				}
 				user.GithubAppOwner = labels[labelGithubAppOwner]
+				user.Scopes = splitScopes(annotations[annotationScopes])
 				var server *AuthServer
 
-				// for github app mode lets share the same server and have multiple users
-				if user.GithubAppOwner != "" {
-					for _, s := range config.Servers {
-						if s.URL == url {
-							server = s
-							break
-						}
+				// for github app mode and scoped credentials lets share the same server and have multiple users
+				for _, s := range config.Servers {
+					if s.URL == url && (user.GithubAppOwner != "" || user.IsScoped() || onlyScopedUsers(s)) {
+						server = s
+						break
 					}
 				}
 				if server != nil {
 					server.Users = append(server.Users, &user)
+					if user.GithubAppOwner == "" && !user.IsScoped() {
+						server.CurrentUser = user.Username
+					}
 				} else {
 					server = &AuthServer{
 						URL:  url,
@@ -85,7 +100,7 @@ func (k *KubeAuthConfigHandler) LoadConfig() (*AuthConfig, error) {
 							&user,
 						},
 					}
-					if user.GithubAppOwner == "" {
+					if user.GithubAppOwner == "" && !user.IsScoped() {
 						server.CurrentUser = user.Username
 					}
 					if config.Servers == nil {
@@ -95,7 +110,7 @@ func (k *KubeAuthConfigHandler) LoadConfig() (*AuthConfig, error) {
 				}
 				config.CurrentServer = server.URL
 				config.PipeLineServer = server.URL
-				if user.GithubAppOwner == "" {
+				if user.GithubAppOwner == "" && !user.IsScoped() {
 					config.PipeLineUsername = user.Username
 					config.DefaultUsername = user.Username
 				}
@@ -105,58 +120,89 @@ func (k *KubeAuthConfigHandler) LoadConfig() (*AuthConfig, error) {
 	return config, nil
 }
 
-// SaveConfig saves the config into kuberntes secret
+// SaveConfig saves the config into kuberntes secret. Credentials scoped to some organisations or repositories are
+// saved into a secret per user
 func (k *KubeAuthConfigHandler) SaveConfig(config *AuthConfig) error {
 	for _, server := range config.Servers {
-		name := k.secretName(server)
-		labels := k.labels(server)
-		annotations := k.annotations(server)
-		secret, err := k.client.CoreV1().Secrets(k.namespace).Get(name, metav1.GetOptions{})
-		create := false
-		if err != nil {
-			create = true
-			secret = &corev1.Secret{
-				ObjectMeta: metav1.ObjectMeta{
-					Name:        name,
-					Labels:      labels,
-					Annotations: annotations,
-				},
-				Data: map[string][]byte{},
-			}
-		} else {
-			secret.Labels = util.MergeMaps(secret.Labels, labels)
-			secret.Annotations = util.MergeMaps(secret.Annotations, annotations)
-		}
 		user := server.CurrentAuth()
 		if user == nil {
 			return fmt.Errorf("current user for %q server is empty", server.URL)
 		}
-		if user.Username == "" {
-			return errors.New("empty username")
+		if !user.IsScoped() {
+			err := k.saveUserAuth(server, user, k.secretName(server))
+			if err != nil {
+				return err
+			}
 		}
-		if user.ApiToken == "" && user.Password == "" {
-			return errors.New("empty credentials")
+		for _, u := range server.Users {
+			if u.IsScoped() {
+				err := k.saveUserAuth(server, u, k.secretName(server)+"-"+util.SanitizeLabel(strings.ToLower(u.Username)))
+				if err != nil {
+					return err
+				}
+			}
 		}
-		secret.Data[usernameKey] = []byte(user.Username)
-		if user.ApiToken != "" {
-			secret.Data[passwordKey] = []byte(user.ApiToken)
-		} else {
-			secret.Data[passwordKey] = []byte(user.Password)
+	}
+	return nil
+}
+
+// saveUserAuth saves the credentials of the user of the server into the named secret
+func (k *KubeAuthConfigHandler) saveUserAuth(server *AuthServer, user *UserAuth, name string) error {
+	labels := k.labels(server)
+	annotations := k.annotations(server)
+	if user.IsScoped() {
+		annotations[annotationScopes] = strings.Join(user.Scopes, ",")
+	}
+	secret, err := k.client.CoreV1().Secrets(k.namespace).Get(name, metav1.GetOptions{})
+	create := false
+	if err != nil {
+		create = true
+		secret = &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        name,
+				Labels:      labels,
+				Annotations: annotations,
+			},
+			Data: map[string][]byte{},
 		}
-		if user.GithubAppOwner != "" {
-			labels := map[string]string{
-				labelGithubAppOwner: user.GithubAppOwner,
-			}
-			secret.Labels = util.MergeMaps(secret.Labels, labels)
+	} else {
+		secret.Labels = util.MergeMaps(secret.Labels, labels)
+		secret.Annotations = util.MergeMaps(secret.Annotations, annotations)
+	}
+	if user.Username == "" {
+		return errors.New("empty username")
+	}
+	if user.ApiToken == "" && user.Password == "" && !user.IsGithubApp() && user.SSH == nil {
+		return errors.New("empty credentials")
+	}
+	secret.Data[usernameKey] = []byte(user.Username)
+	if user.ApiToken != "" {
+		secret.Data[passwordKey] = []byte(user.ApiToken)
+	} else if user.Password != "" {
+		secret.Data[passwordKey] = []byte(user.Password)
+	}
+	if user.IsGithubApp() {
+		secret.Data[githubAppIDKey] = []byte(strconv.FormatInt(user.GithubAppID, 10))
+		secret.Data[githubAppInstallationIDKey] = []byte(strconv.FormatInt(user.GithubAppInstallationID, 10))
+		secret.Data[githubAppPrivateKeyKey] = []byte(user.GithubAppPrivateKey)
+	}
+	if user.SSH != nil && user.SSH.PrivateKey != "" {
+		secret.Data[sshPrivateKeyKey] = []byte(user.SSH.PrivateKey)
+		secret.Data[sshKnownHostsKey] = []byte(strings.Join(user.SSH.KnownHosts, "\n"))
+	}
+	if user.GithubAppOwner != "" {
+		labels := map[string]string{
+			labelGithubAppOwner: user.GithubAppOwner,
 		}
-		if create {
-			if _, err := k.client.CoreV1().Secrets(k.namespace).Create(secret); err != nil {
-				return errors.Wrapf(err, "creating secret %q", name)
-			}
-		} else {
-			if _, err := k.client.CoreV1().Secrets(k.namespace).Update(secret); err != nil {
-				return errors.Wrapf(err, "updating secret %q", name)
-			}
+		secret.Labels = util.MergeMaps(secret.Labels, labels)
+	}
+	if create {
+		if _, err := k.client.CoreV1().Secrets(k.namespace).Create(secret); err != nil {
+			return errors.Wrapf(err, "creating secret %q", name)
+		}
+	} else {
+		if _, err := k.client.CoreV1().Secrets(k.namespace).Update(secret); err != nil {
+			return errors.Wrapf(err, "updating secret %q", name)
 		}
 	}
 	return nil
@@ -217,15 +263,55 @@ func (k *KubeAuthConfigHandler) userFromSecret(secret corev1.Secret) (UserAuth,
 	if !ok || len(username) == 0 {
 		return UserAuth{}, fmt.Errorf("no user name found in secret '%s'", secret.Name)
 	}
-	password, ok := data[passwordKey]
-	if !ok || len(password) == 0 {
+	user := UserAuth{
+		Username:                string(username),
+		ApiToken:                string(data[passwordKey]),
+		GithubAppID:             parseGithubAppID(string(data[githubAppIDKey])),
+		GithubAppInstallationID: parseGithubAppID(string(data[githubAppInstallationIDKey])),
+		GithubAppPrivateKey:     string(data[githubAppPrivateKeyKey]),
+	}
+	if key := string(data[sshPrivateKeyKey]); key != "" {
+		user.SSH = &SSHAuth{
+			PrivateKey: key,
+			KnownHosts: nonEmptyLines(string(data[sshKnownHostsKey])),
+		}
+	}
+	if user.ApiToken == "" && !user.IsGithubApp() && user.SSH == nil {
 		return UserAuth{}, fmt.Errorf("no password found in secret '%s'", secret.Name)
 	}
+	return user, nil
+}
 
-	return UserAuth{
-		Username: string(username),
-		ApiToken: string(password),
-	}, nil
+// onlyScopedUsers returns true if all the users of the server are scoped to some organisations or repositories
+func onlyScopedUsers(server *AuthServer) bool {
+	for _, user := range server.Users {
+		if !user.IsScoped() {
+			return false
+		}
+	}
+	return len(server.Users) > 0
+}
+
+func splitScopes(text string) []string {
+	var answer []string
+	for _, scope := range strings.Split(text, ",") {
+		scope = strings.TrimSpace(scope)
+		if scope != "" {
+			answer = append(answer, scope)
+		}
+	}
+	return answer
+}
+
+func nonEmptyLines(text string) []string {
+	var answer []string
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" {
+			answer = append(answer, line)
+		}
+	}
+	return answer
 }
 
 // NewKubeAuthConfigHandler creates a handler which loads/stores the auth config from/into Kubernetes secrets
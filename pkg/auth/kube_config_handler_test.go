@@ -513,6 +513,78 @@ func TestSaveConfig(t *testing.T) {
 				},
 			},
 		},
+		"save config into kubernetes secrets with scoped credentials": {
+			namespace:  "test",
+			serverKind: "git",
+			config: &AuthConfig{
+				Servers: []*AuthServer{
+					{
+						URL: "https://github.com",
+						Users: []*UserAuth{
+							{
+								Username: "test1",
+								ApiToken: "test1",
+							},
+							{
+								Username: "team-bot",
+								ApiToken: "team",
+								Scopes:   []string{"myorg/team-*"},
+							},
+						},
+						Name:        "GitHub",
+						Kind:        "github",
+						CurrentUser: "test1",
+					},
+				},
+				CurrentServer: "https://github.com",
+			},
+			err: false,
+			want: []*corev1.Secret{
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "jx-pipeline-git-github-github",
+						Namespace: "test",
+						Labels: map[string]string{
+							labelCredentialsType: valueCredentialTypeUsernamePassword,
+							labelCreatedBy:       valueCreatedByJX,
+							labelKind:            "git",
+							labelServiceKind:     "github",
+						},
+						Annotations: map[string]string{
+							annotationCredentialsDescription: fmt.Sprintf("Configuration and credentials for server https://github.com"),
+							annotationURL:                    "https://github.com",
+							annotationName:                   "GitHub",
+						},
+					},
+					Data: map[string][]byte{
+						"username": []byte("test1"),
+						"password": []byte("test1"),
+					},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "jx-pipeline-git-github-github-team-bot",
+						Namespace: "test",
+						Labels: map[string]string{
+							labelCredentialsType: valueCredentialTypeUsernamePassword,
+							labelCreatedBy:       valueCreatedByJX,
+							labelKind:            "git",
+							labelServiceKind:     "github",
+						},
+						Annotations: map[string]string{
+							annotationCredentialsDescription: fmt.Sprintf("Configuration and credentials for server https://github.com"),
+							annotationURL:                    "https://github.com",
+							annotationName:                   "GitHub",
+							annotationScopes:                 "myorg/team-*",
+						},
+					},
+					Data: map[string][]byte{
+						"username": []byte("team-bot"),
+						"password": []byte("team"),
+					},
+				},
+			},
+		},
 		"save config into kubernetes secret without API token": {
 			namespace:  "test",
 			serverKind: "git",
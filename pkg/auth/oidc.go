@@ -0,0 +1,346 @@
+package auth
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/jenkins-x/jx/v2/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+)
+
+const (
+	// OIDCServerKind the kind of the auth servers of OpenID Connect identity providers
+	OIDCServerKind = "oidc"
+
+	deviceCodeGrantType   = "urn:ietf:params:oauth:grant-type:device_code"
+	refreshTokenGrantType = "refresh_token"
+
+	// defaultDevicePollInterval the interval to poll for the token if the identity provider does not specify one
+	defaultDevicePollInterval = 5 * time.Second
+
+	// oidcExpiryLeeway tokens are refreshed this long before they expire so they do not expire while in use
+	oidcExpiryLeeway = time.Minute
+)
+
+// DefaultOIDCScopes the scopes requested when logging in. offline_access is needed to be issued a refresh token
+var DefaultOIDCScopes = []string{"openid", "profile", "email", "offline_access"}
+
+// OIDCClient logs in to an OpenID Connect identity provider using the OAuth 2.0 device authorization grant
+type OIDCClient struct {
+	Issuer   string
+	ClientID string
+	Scopes   []string
+	Client   *http.Client
+
+	sleep    func(time.Duration)
+	now      func() time.Time
+	metadata *oidcMetadata
+}
+
+// DeviceAuthorization the codes returned by the identity provider when starting a device login
+type DeviceAuthorization struct {
+	DeviceCode              string `json:"device_code"`
+	UserCode                string `json:"user_code"`
+	VerificationURI         string `json:"verification_uri"`
+	VerificationURIComplete string `json:"verification_uri_complete,omitempty"`
+	ExpiresIn               int    `json:"expires_in"`
+	Interval                int    `json:"interval,omitempty"`
+}
+
+// oidcMetadata the endpoints of the identity provider from its discovery document
+type oidcMetadata struct {
+	Issuer                      string `json:"issuer"`
+	TokenEndpoint               string `json:"token_endpoint"`
+	DeviceAuthorizationEndpoint string `json:"device_authorization_endpoint"`
+}
+
+// oidcTokenResponse the response of the token endpoint, including its errors
+type oidcTokenResponse struct {
+	AccessToken      string `json:"access_token"`
+	IDToken          string `json:"id_token"`
+	RefreshToken     string `json:"refresh_token"`
+	ExpiresIn        int    `json:"expires_in"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+// NewOIDCClient creates a client to log in with the given identity provider
+func NewOIDCClient(issuer string, clientID string, scopes []string) *OIDCClient {
+	if len(scopes) == 0 {
+		scopes = DefaultOIDCScopes
+	}
+	return &OIDCClient{
+		Issuer:   strings.TrimSuffix(issuer, "/"),
+		ClientID: clientID,
+		Scopes:   scopes,
+		Client:   &http.Client{Timeout: 30 * time.Second},
+		sleep:    time.Sleep,
+		now:      time.Now,
+	}
+}
+
+// StartDeviceLogin starts a device login returning the code the user has to enter at the verification URI
+func (c *OIDCClient) StartDeviceLogin() (*DeviceAuthorization, error) {
+	metadata, err := c.discover()
+	if err != nil {
+		return nil, err
+	}
+	if metadata.DeviceAuthorizationEndpoint == "" {
+		return nil, fmt.Errorf("the identity provider %s does not support the device authorization grant", c.Issuer)
+	}
+	values := url.Values{
+		"client_id": {c.ClientID},
+		"scope":     {strings.Join(c.Scopes, " ")},
+	}
+	body, status, err := c.post(metadata.DeviceAuthorizationEndpoint, values)
+	if err != nil {
+		return nil, err
+	}
+	if status != http.StatusOK {
+		return nil, fmt.Errorf("starting the device login at %s returned %d: %s", metadata.DeviceAuthorizationEndpoint, status, string(body))
+	}
+	answer := &DeviceAuthorization{}
+	err = json.Unmarshal(body, answer)
+	if err != nil {
+		return nil, errors.Wrapf(err, "parsing the device authorization returned from %s", metadata.DeviceAuthorizationEndpoint)
+	}
+	if answer.DeviceCode == "" || answer.UserCode == "" {
+		return nil, fmt.Errorf("no device code returned from %s", metadata.DeviceAuthorizationEndpoint)
+	}
+	return answer, nil
+}
+
+// WaitForDeviceLogin polls the identity provider until the user has approved the device login, returning the user
+// with the short lived token
+func (c *OIDCClient) WaitForDeviceLogin(device *DeviceAuthorization) (*UserAuth, error) {
+	metadata, err := c.discover()
+	if err != nil {
+		return nil, err
+	}
+	interval := time.Duration(device.Interval) * time.Second
+	if interval <= 0 {
+		interval = defaultDevicePollInterval
+	}
+	deadline := c.now().Add(time.Duration(device.ExpiresIn) * time.Second)
+	values := url.Values{
+		"grant_type":  {deviceCodeGrantType},
+		"device_code": {device.DeviceCode},
+		"client_id":   {c.ClientID},
+	}
+	for {
+		c.sleep(interval)
+		token, err := c.requestToken(metadata.TokenEndpoint, values)
+		if err != nil {
+			return nil, err
+		}
+		switch token.Error {
+		case "":
+			return c.userAuth(token, "")
+		case "authorization_pending":
+		case "slow_down":
+			interval += defaultDevicePollInterval
+		case "access_denied":
+			return nil, errors.New("the login was denied")
+		case "expired_token":
+			return nil, errors.New("the login expired before it was approved")
+		default:
+			return nil, fmt.Errorf("logging in with %s failed: %s %s", c.Issuer, token.Error, token.ErrorDescription)
+		}
+		if device.ExpiresIn > 0 && c.now().After(deadline) {
+			return nil, errors.New("the login expired before it was approved")
+		}
+	}
+}
+
+// Refresh obtains a new short lived token for the user using its refresh token
+func (c *OIDCClient) Refresh(user *UserAuth) error {
+	if user.OIDC == nil || user.OIDC.RefreshToken == "" {
+		return fmt.Errorf("the user %s has no refresh token, please run 'jx login' again", user.Username)
+	}
+	metadata, err := c.discover()
+	if err != nil {
+		return err
+	}
+	values := url.Values{
+		"grant_type":    {refreshTokenGrantType},
+		"refresh_token": {user.OIDC.RefreshToken},
+		"client_id":     {c.ClientID},
+	}
+	token, err := c.requestToken(metadata.TokenEndpoint, values)
+	if err != nil {
+		return err
+	}
+	if token.Error != "" {
+		return fmt.Errorf("refreshing the token of %s failed: %s %s", user.Username, token.Error, token.ErrorDescription)
+	}
+	refreshed, err := c.userAuth(token, user.OIDC.RefreshToken)
+	if err != nil {
+		return err
+	}
+	user.BearerToken = refreshed.BearerToken
+	user.OIDC = refreshed.OIDC
+	if user.Username == "" {
+		user.Username = refreshed.Username
+	}
+	return nil
+}
+
+// IsOIDC returns true if the user logged in with an OpenID Connect identity provider
+func (a *UserAuth) IsOIDC() bool {
+	return a.OIDC != nil && a.OIDC.Issuer != ""
+}
+
+// OIDCTokenExpired returns true if the user logged in with an OpenID Connect identity provider and its token has
+// expired or is about to
+func (a *UserAuth) OIDCTokenExpired(now time.Time) bool {
+	if !a.IsOIDC() || a.OIDC.Expiry.IsZero() {
+		return false
+	}
+	return now.Add(oidcExpiryLeeway).After(a.OIDC.Expiry)
+}
+
+// RefreshOIDCTokens refreshes the expired tokens of the users who logged in with an OpenID Connect identity
+// provider, returning true if any token was refreshed
+func RefreshOIDCTokens(config *AuthConfig) (bool, error) {
+	refreshed := false
+	now := time.Now()
+	for _, server := range config.Servers {
+		for _, user := range server.Users {
+			if !user.OIDCTokenExpired(now) {
+				continue
+			}
+			client := NewOIDCClient(user.OIDC.Issuer, user.OIDC.ClientID, user.OIDC.Scopes)
+			err := client.Refresh(user)
+			if err != nil {
+				return refreshed, errors.Wrapf(err, "refreshing the token of user %s for server %s", user.Username, server.URL)
+			}
+			log.Logger().Debugf("refreshed the token of user %s for server %s", user.Username, server.URL)
+			refreshed = true
+		}
+	}
+	return refreshed, nil
+}
+
+// userAuth creates the user from a token response. The ID token is preferred as the bearer token as it is what
+// Kubernetes and most APIs verify
+func (c *OIDCClient) userAuth(token *oidcTokenResponse, previousRefreshToken string) (*UserAuth, error) {
+	bearer := token.IDToken
+	if bearer == "" {
+		bearer = token.AccessToken
+	}
+	if bearer == "" {
+		return nil, fmt.Errorf("no token returned from %s", c.Issuer)
+	}
+	refreshToken := token.RefreshToken
+	if refreshToken == "" {
+		// identity providers which do not rotate refresh tokens omit them when refreshing
+		refreshToken = previousRefreshToken
+	}
+	answer := &UserAuth{
+		Username:    idTokenSubject(token.IDToken),
+		BearerToken: bearer,
+		OIDC: &OIDCAuth{
+			Issuer:       c.Issuer,
+			ClientID:     c.ClientID,
+			Scopes:       c.Scopes,
+			RefreshToken: refreshToken,
+		},
+	}
+	if token.ExpiresIn > 0 {
+		answer.OIDC.Expiry = c.now().Add(time.Duration(token.ExpiresIn) * time.Second)
+	}
+	return answer, nil
+}
+
+// discover loads the endpoints of the identity provider from its discovery document
+func (c *OIDCClient) discover() (*oidcMetadata, error) {
+	if c.metadata != nil {
+		return c.metadata, nil
+	}
+	u := util.UrlJoin(c.Issuer, ".well-known", "openid-configuration")
+	resp, err := c.Client.Get(u)
+	if err != nil {
+		return nil, errors.Wrapf(err, "discovering the identity provider at %s", u)
+	}
+	defer resp.Body.Close() //nolint:errcheck
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("discovering the identity provider at %s returned %s: %s", u, resp.Status, string(body))
+	}
+	metadata := &oidcMetadata{}
+	err = json.Unmarshal(body, metadata)
+	if err != nil {
+		return nil, errors.Wrapf(err, "parsing the discovery document returned from %s", u)
+	}
+	if metadata.TokenEndpoint == "" {
+		return nil, fmt.Errorf("no token endpoint in the discovery document returned from %s", u)
+	}
+	c.metadata = metadata
+	return metadata, nil
+}
+
+func (c *OIDCClient) requestToken(endpoint string, values url.Values) (*oidcTokenResponse, error) {
+	body, status, err := c.post(endpoint, values)
+	if err != nil {
+		return nil, err
+	}
+	token := &oidcTokenResponse{}
+	err = json.Unmarshal(body, token)
+	if err != nil {
+		return nil, errors.Wrapf(err, "parsing the token returned from %s with status %d", endpoint, status)
+	}
+	if status != http.StatusOK && token.Error == "" {
+		return nil, fmt.Errorf("requesting a token from %s returned %d: %s", endpoint, status, string(body))
+	}
+	return token, nil
+}
+
+func (c *OIDCClient) post(endpoint string, values url.Values) ([]byte, int, error) {
+	req, err := http.NewRequest(http.MethodPost, endpoint, strings.NewReader(values.Encode()))
+	if err != nil {
+		return nil, 0, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	resp, err := c.Client.Do(req)
+	if err != nil {
+		return nil, 0, errors.Wrapf(err, "posting to %s", endpoint)
+	}
+	defer resp.Body.Close() //nolint:errcheck
+	body, err := ioutil.ReadAll(resp.Body)
+	return body, resp.StatusCode, err
+}
+
+// idTokenSubject returns the email or else the subject of an ID token without verifying it, the identity provider
+// returned it directly to us over TLS and the APIs it is sent to verify it
+func idTokenSubject(idToken string) string {
+	parts := strings.Split(idToken, ".")
+	if len(parts) != 3 {
+		return ""
+	}
+	data, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return ""
+	}
+	claims := struct {
+		Subject string `json:"sub"`
+		Email   string `json:"email"`
+	}{}
+	if json.Unmarshal(data, &claims) != nil {
+		return ""
+	}
+	if claims.Email != "" {
+		return claims.Email
+	}
+	return claims.Subject
+}
@@ -0,0 +1,122 @@
+// +build unit
+
+package auth
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOIDCDeviceLoginAndRefresh(t *testing.T) {
+	t.Parallel()
+
+	claims := base64.RawURLEncoding.EncodeToString([]byte(`{"sub":"1234","email":"jstrachan@example.com"}`))
+	idToken := "header." + claims + ".signature"
+	polls := 0
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/.well-known/openid-configuration":
+			_ = json.NewEncoder(w).Encode(map[string]string{
+				"issuer":                        server.URL,
+				"token_endpoint":                server.URL + "/token",
+				"device_authorization_endpoint": server.URL + "/device",
+			})
+		case "/device":
+			assert.Equal(t, "jx", r.FormValue("client_id"))
+			assert.Equal(t, "openid offline_access", r.FormValue("scope"))
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"device_code":      "device-123",
+				"user_code":        "ABCD-EFGH",
+				"verification_uri": server.URL + "/activate",
+				"expires_in":       600,
+				"interval":         1,
+			})
+		case "/token":
+			switch r.FormValue("grant_type") {
+			case deviceCodeGrantType:
+				assert.Equal(t, "device-123", r.FormValue("device_code"))
+				polls++
+				if polls < 3 {
+					w.WriteHeader(http.StatusBadRequest)
+					_ = json.NewEncoder(w).Encode(map[string]string{"error": "authorization_pending"})
+					return
+				}
+				_ = json.NewEncoder(w).Encode(map[string]interface{}{
+					"access_token":  "access-1",
+					"id_token":      idToken,
+					"refresh_token": "refresh-1",
+					"expires_in":    300,
+				})
+			case refreshTokenGrantType:
+				assert.Equal(t, "refresh-1", r.FormValue("refresh_token"))
+				_ = json.NewEncoder(w).Encode(map[string]interface{}{
+					"access_token": "access-2",
+					"expires_in":   300,
+				})
+			}
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	client := NewOIDCClient(server.URL, "jx", []string{"openid", "offline_access"})
+	client.sleep = func(time.Duration) {}
+	client.now = func() time.Time { return now }
+
+	device, err := client.StartDeviceLogin()
+	require.NoError(t, err)
+	assert.Equal(t, "ABCD-EFGH", device.UserCode)
+
+	user, err := client.WaitForDeviceLogin(device)
+	require.NoError(t, err)
+	assert.Equal(t, 3, polls)
+	assert.Equal(t, "jstrachan@example.com", user.Username)
+	assert.Equal(t, idToken, user.BearerToken)
+	assert.Equal(t, "refresh-1", user.OIDC.RefreshToken)
+	assert.Equal(t, now.Add(5*time.Minute), user.OIDC.Expiry)
+	assert.False(t, user.IsInvalid())
+
+	assert.False(t, user.OIDCTokenExpired(now))
+	assert.True(t, user.OIDCTokenExpired(now.Add(4*time.Minute+30*time.Second)), "tokens about to expire should be refreshed")
+
+	err = client.Refresh(user)
+	require.NoError(t, err)
+	assert.Equal(t, "access-2", user.BearerToken)
+	assert.Equal(t, "refresh-1", user.OIDC.RefreshToken, "the refresh token should be kept when it is not rotated")
+	assert.Equal(t, "jstrachan@example.com", user.Username)
+}
+
+func TestOIDCDeviceLoginDenied(t *testing.T) {
+	t.Parallel()
+
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/.well-known/openid-configuration" {
+			_ = json.NewEncoder(w).Encode(map[string]string{"token_endpoint": server.URL + "/token"})
+			return
+		}
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(map[string]string{"error": "access_denied"})
+	}))
+	defer server.Close()
+
+	client := NewOIDCClient(server.URL, "jx", nil)
+	client.sleep = func(time.Duration) {}
+
+	_, err := client.StartDeviceLogin()
+	assert.Error(t, err, "the identity provider does not support the device flow")
+
+	_, err = client.WaitForDeviceLogin(&DeviceAuthorization{DeviceCode: "device-123", UserCode: "ABCD"})
+	assert.EqualError(t, err, "the login was denied")
+}
@@ -0,0 +1,83 @@
+package auth
+
+import (
+	"path"
+	"strings"
+)
+
+// exactScopeRank ranks scopes naming a repository without wildcards above any pattern
+const exactScopeRank = 1000
+
+// IsScoped returns true if the credentials are restricted to some organisations or repositories of the server
+func (a *UserAuth) IsScoped() bool {
+	return len(a.Scopes) > 0
+}
+
+// ScopeRank returns how narrowly the scopes of the credentials match the repository, higher ranks are narrower. Returns
+// -1 if the credentials are scoped and none of the scopes match or 0 if the credentials are not scoped
+func (a *UserAuth) ScopeRank(owner string, repo string) int {
+	if !a.IsScoped() {
+		return 0
+	}
+	answer := -1
+	for _, scope := range a.Scopes {
+		rank := scopeRank(scope, owner, repo)
+		if rank > answer {
+			answer = rank
+		}
+	}
+	return answer
+}
+
+// scopeRank returns the rank of the scope for the repository or -1 if it does not match. Scopes without a repository
+// such as myorg match all the repositories of the organisation. Narrower scopes have more literal characters
+func scopeRank(scope string, owner string, repo string) int {
+	scope = strings.Trim(strings.TrimSpace(scope), "/")
+	if scope == "" || owner == "" {
+		return -1
+	}
+	if !strings.Contains(scope, "/") {
+		scope += "/*"
+	}
+	target := owner + "/" + repo
+	if repo == "" {
+		target = owner + "/"
+	}
+	matched, err := path.Match(strings.ToLower(scope), strings.ToLower(target))
+	if err != nil || !matched {
+		return -1
+	}
+	literal := len(scope) - strings.Count(scope, "*") - strings.Count(scope, "?")
+	if !strings.ContainsAny(scope, "*?[") {
+		return exactScopeRank + literal
+	}
+	return 1 + literal
+}
+
+// FindUserAuthForRepository returns the credentials of the server whose scopes most narrowly match the repository or
+// nil if no scoped credentials match it
+func (s *AuthServer) FindUserAuthForRepository(owner string, repo string) *UserAuth {
+	if s == nil {
+		return nil
+	}
+	var answer *UserAuth
+	best := 0
+	for _, user := range s.Users {
+		rank := user.ScopeRank(owner, repo)
+		if rank > best {
+			answer = user
+			best = rank
+		}
+	}
+	return answer
+}
+
+// GetPipelineAuthForRepository returns the pipeline server and the narrowest scoped credentials matching the
+// repository, defaulting to the pipeline user
+func (c *AuthConfig) GetPipelineAuthForRepository(owner string, repo string) (*AuthServer, *UserAuth) {
+	server, user := c.GetPipelineAuth()
+	if scoped := server.FindUserAuthForRepository(owner, repo); scoped != nil {
+		return server, scoped
+	}
+	return server, user
+}
@@ -0,0 +1,51 @@
+// +build unit
+
+package auth_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jenkins-x/jx/v2/pkg/auth"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindUserAuthForRepository(t *testing.T) {
+	t.Parallel()
+
+	server := &auth.AuthServer{
+		URL: "https://github.com",
+		Users: []*auth.UserAuth{
+			{Username: "admin", ApiToken: "admin"},
+			{Username: "org-bot", ApiToken: "org", Scopes: []string{"myorg"}},
+			{Username: "team-bot", ApiToken: "team", Scopes: []string{"myorg/team-*", "otherorg/shared"}},
+			{Username: "app-bot", ApiToken: "app", Scopes: []string{"myorg/team-app"}},
+		},
+	}
+	config := &auth.AuthConfig{
+		Servers:          []*auth.AuthServer{server},
+		PipeLineServer:   "https://github.com",
+		PipeLineUsername: "admin",
+	}
+
+	testCases := map[string]string{
+		"myorg/other":      "org-bot",
+		"myorg/team-web":   "team-bot",
+		"MyOrg/Team-Web":   "team-bot",
+		"myorg/team-app":   "app-bot",
+		"otherorg/shared":  "team-bot",
+		"otherorg/private": "admin",
+		"someone/repo":     "admin",
+	}
+	for repository, expected := range testCases {
+		parts := strings.SplitN(repository, "/", 2)
+		_, user := config.GetPipelineAuthForRepository(parts[0], parts[1])
+		if assert.NotNil(t, user, repository) {
+			assert.Equal(t, expected, user.Username, repository)
+		}
+	}
+
+	assert.Nil(t, server.FindUserAuthForRepository("someone", "repo"), "unscoped credentials should not be returned")
+	assert.Equal(t, -1, server.Users[2].ScopeRank("myorg", "other"))
+	assert.Equal(t, 0, server.Users[0].ScopeRank("myorg", "other"))
+}
@@ -1,6 +1,8 @@
 package auth
 
 import (
+	"time"
+
 	"github.com/jenkins-x/jx/v2/pkg/secreturl"
 	"github.com/jenkins-x/jx/v2/pkg/vault"
 	"k8s.io/client-go/kubernetes"
@@ -14,6 +16,10 @@ type AuthServer struct {
 	Kind  string      `json:"kind"`
 
 	CurrentUser string `json:"currentuser"`
+
+	// CredentialHelper the name of the credential helper which stores the secrets of the users of the server instead
+	// of the auth config, such as osxkeychain, pass or secretservice. Overrides the default of the auth config
+	CredentialHelper string `json:"credentialHelper,omitempty"`
 }
 
 type UserAuth struct {
@@ -25,6 +31,57 @@ type UserAuth struct {
 	// GithubAppOwner if using GitHub Apps this represents the owner organisation/user which owns this token.
 	// we need to maintain a different token per owner
 	GithubAppOwner string `json:"appOwner,omitempty"`
+
+	// GithubAppID if authenticating as a GitHub App installation rather than with a personal access token this is
+	// the ID of the app. Short lived installation tokens are minted on demand instead of using the ApiToken
+	GithubAppID int64 `json:"appId,omitempty"`
+	// GithubAppInstallationID the ID of the installation of the GitHub App in the owner organisation/user
+	GithubAppInstallationID int64 `json:"appInstallationId,omitempty"`
+	// GithubAppPrivateKey the PEM encoded private key of the GitHub App
+	GithubAppPrivateKey string `json:"appPrivateKey,omitempty"`
+
+	// Scopes the organisation or repository patterns such as myorg, myorg/* or myorg/myrepo-* the credentials are
+	// restricted to. Credentials without scopes are used for any repository of the server
+	Scopes []string `json:"scopes,omitempty"`
+
+	// SSH if specified git operations use ssh rather than the token in https URLs
+	SSH *SSHAuth `json:"ssh,omitempty"`
+
+	// OIDC if the user logged in with an OpenID Connect identity provider the BearerToken is a short lived token
+	// which is refreshed using these details when it expires
+	OIDC *OIDCAuth `json:"oidc,omitempty"`
+
+	// HelperSecret the secret of the user which is stored by the credential helper of the server rather than in the
+	// auth config, one of apitoken, password or bearertoken
+	HelperSecret string `json:"helperSecret,omitempty"`
+}
+
+// SSHAuth the ssh authentication used for git operations. If no private key is specified the ssh agent is used
+type SSHAuth struct {
+	// PrivateKeyFile the file containing the private key
+	PrivateKeyFile string `json:"privateKeyFile,omitempty"`
+	// PrivateKey the PEM encoded private key, used when the key is stored in a secret rather than a file
+	PrivateKey string `json:"privateKey,omitempty"`
+	// KnownHostsFile the known_hosts file used to verify the server, defaults to ~/.ssh/known_hosts
+	KnownHostsFile string `json:"knownHostsFile,omitempty"`
+	// KnownHosts the known_hosts lines of the server which are added to the known hosts file if missing
+	KnownHosts []string `json:"knownHosts,omitempty"`
+	// AcceptNewHostKeys trusts the key of a server on first use rather than requiring it to be known
+	AcceptNewHostKeys bool `json:"acceptNewHostKeys,omitempty"`
+}
+
+// OIDCAuth the OpenID Connect login of a user
+type OIDCAuth struct {
+	// Issuer the URL of the identity provider
+	Issuer string `json:"issuer"`
+	// ClientID the ID of the client registered with the identity provider for jx
+	ClientID string `json:"clientId"`
+	// Scopes the scopes requested when logging in
+	Scopes []string `json:"scopes,omitempty"`
+	// RefreshToken the token used to obtain a new BearerToken when it expires
+	RefreshToken string `json:"refreshToken,omitempty"`
+	// Expiry when the BearerToken expires
+	Expiry time.Time `json:"expiry,omitempty"`
 }
 
 type AuthConfig struct {
@@ -34,6 +91,9 @@ type AuthConfig struct {
 	CurrentServer    string `json:"currentserver"`
 	PipeLineUsername string `json:"pipelineusername"`
 	PipeLineServer   string `json:"pipelineserver"`
+
+	// CredentialHelper the name of the default credential helper which stores the secrets of the users of all servers
+	CredentialHelper string `json:"credentialHelper,omitempty"`
 }
 
 // AuthConfigService implements the generic features of the ConfigService because we don't have superclasses
@@ -44,8 +104,9 @@ type AuthConfigService struct {
 
 // FileAuthConfigHandler is a config handlerthat loads/saves the auth config from/to the local filesystem
 type FileAuthConfigHandler struct {
-	fileName   string
-	serverKind string
+	fileName      string
+	serverKind    string
+	encryptionKey func() ([]byte, error)
 }
 
 // VaultAuthConfigHandler is a config handler that loads/saves the auth configs from/to Vault
@@ -2,7 +2,10 @@ package auth
 
 import (
 	"os"
+	"strconv"
 	"strings"
+
+	"github.com/jenkins-x/jx/v2/pkg/log"
 )
 
 const (
@@ -10,6 +13,10 @@ const (
 	apiTokenSuffix    = "_API_TOKEN"
 	bearerTokenSuffix = "_BEARER_TOKEN"
 	DefaultUsername   = "dummy"
+
+	githubAppIDSuffix             = "_GITHUB_APP_ID"
+	githubAppInstallationIDSuffix = "_GITHUB_APP_INSTALLATION_ID"
+	githubAppPrivateKeySuffix     = "_GITHUB_APP_PRIVATE_KEY"
 )
 
 // UsernameEnv builds the username environment variable name
@@ -46,7 +53,14 @@ func CreateAuthUserFromEnvironment(prefix string) UserAuth {
 		user.BearerToken = bearerToken
 	}
 
-	if user.ApiToken != "" || user.Password != "" {
+	appID, set := os.LookupEnv(strings.ToUpper(prefix) + githubAppIDSuffix)
+	if set {
+		user.GithubAppID = parseGithubAppID(appID)
+		user.GithubAppInstallationID = parseGithubAppID(os.Getenv(strings.ToUpper(prefix) + githubAppInstallationIDSuffix))
+		user.GithubAppPrivateKey = os.Getenv(strings.ToUpper(prefix) + githubAppPrivateKeySuffix)
+	}
+
+	if user.ApiToken != "" || user.Password != "" || user.IsGithubApp() {
 		if user.Username == "" {
 			user.Username = DefaultUsername
 		}
@@ -55,13 +69,35 @@ func CreateAuthUserFromEnvironment(prefix string) UserAuth {
 	return user
 }
 
+func parseGithubAppID(value string) int64 {
+	if value == "" {
+		return 0
+	}
+	id, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		log.Logger().Warnf("ignoring invalid GitHub App ID %s", value)
+	}
+	return id
+}
+
+// IsGithubApp returns true if the user authenticates as a GitHub App installation rather than with a token
+func (a *UserAuth) IsGithubApp() bool {
+	return a.GithubAppID != 0 && a.GithubAppInstallationID != 0 && a.GithubAppPrivateKey != ""
+}
+
 // IsInvalid returns true if the user auth has a valid token
 func (a *UserAuth) IsInvalid() bool {
+	if a.IsGithubApp() || (a.SSH != nil && a.Username != "") {
+		return false
+	}
 	return a.BearerToken == "" && (a.ApiToken == "" || a.Username == "")
 }
 
 // Valid returns true when the user authentication is valid, otherwise false
 func (a *UserAuth) IsValid() bool {
+	if a.IsGithubApp() {
+		return true
+	}
 	if a.Username == "" {
 		return false
 	}
@@ -0,0 +1,57 @@
+package boot
+
+import (
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+)
+
+const (
+	// UpgradeCommand is the comment on a pull request of the dev environment repository which triggers a boot upgrade
+	UpgradeCommand = "/upgrade boot"
+	// UpgradePipelineContext is the context of the pipeline which runs the boot upgrade
+	UpgradePipelineContext = "upgrade-boot"
+	// UpgradePipelineFileName is the file in the dev environment repository which defines the boot upgrade pipeline
+	UpgradePipelineFileName = "jenkins-x-" + UpgradePipelineContext + ".yml"
+)
+
+// upgradePipeline runs `jx upgrade boot` and comments the link of the upgrade pull request on the pull request which
+// triggered it
+const upgradePipeline = `buildPack: none
+pipelineConfig:
+  pipelines:
+    pullRequest:
+      pipeline:
+        agent:
+          image: gcr.io/jenkinsxio/builder-go
+        stages:
+        - name: upgrade-boot
+          steps:
+          - name: upgrade-boot
+            command: jx
+            args:
+            - upgrade
+            - boot
+            - --batch-mode
+            - --report-pull-request
+`
+
+// EnsureUpgradePipeline adds the pipeline triggered by the UpgradeCommand to the dev environment repository in the
+// given directory unless the repository already has one
+func EnsureUpgradePipeline(dir string) error {
+	fileName := filepath.Join(dir, UpgradePipelineFileName)
+	exists, err := util.FileExists(fileName)
+	if err != nil {
+		return errors.Wrapf(err, "checking if %s exists", fileName)
+	}
+	if exists {
+		return nil
+	}
+	err = ioutil.WriteFile(fileName, []byte(upgradePipeline), util.DefaultWritePermissions)
+	if err != nil {
+		return errors.Wrapf(err, "writing the boot upgrade pipeline %s", fileName)
+	}
+	return nil
+}
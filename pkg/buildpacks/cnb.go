@@ -0,0 +1,126 @@
+package buildpacks
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jenkins-x/jx/v2/pkg/config"
+	"github.com/jenkins-x/jx/v2/pkg/jenkinsfile"
+	"github.com/jenkins-x/jx/v2/pkg/tekton/syntax"
+	"github.com/jenkins-x/jx/v2/pkg/versionstream"
+)
+
+const (
+	// BuildPackCNB builds the images of an application with Cloud Native Buildpacks instead of a Dockerfile
+	BuildPackCNB = "cnb"
+
+	// CNBPlatformPack runs the buildpacks lifecycle of the builder image in the pipeline like `pack build` does,
+	// without needing a docker daemon
+	CNBPlatformPack = "pack"
+	// CNBPlatformKpack builds the images with kpack which has to be installed in the cluster
+	CNBPlatformKpack = "kpack"
+
+	// DefaultCNBBuilderImage the builder image of the pack platform whose version is resolved from the version stream
+	DefaultCNBBuilderImage = "paketobuildpacks/builder"
+	// DefaultKpackClusterBuilder the kpack ClusterBuilder used by default
+	DefaultKpackClusterBuilder = "default"
+	// DefaultKpImage the image with the kpack CLI whose version is resolved from the version stream
+	DefaultKpImage = "kpack/kp"
+
+	cnbStepName = "build-cnb"
+)
+
+var (
+	// CNBPlatforms the platforms which can run Cloud Native Buildpacks builds
+	CNBPlatforms = []string{CNBPlatformPack, CNBPlatformKpack}
+)
+
+// CNBConfig the Cloud Native Buildpacks build of an application
+type CNBConfig struct {
+	// Platform the platform running the build, either pack or kpack
+	Platform string
+	// Builder the builder image for pack or the name of the ClusterBuilder for kpack
+	Builder string
+	// Image the repository of the image to build without a tag
+	Image string
+	// AppName the name of the application which is also the name of its kpack Image
+	AppName string
+	// ToolImage the image running the kpack CLI
+	ToolImage string
+}
+
+// Validate returns an error if the platform is not supported
+func (c *CNBConfig) Validate() error {
+	for _, p := range CNBPlatforms {
+		if c.Platform == p {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid Cloud Native Buildpacks platform %q, should be one of %s", c.Platform, strings.Join(CNBPlatforms, ", "))
+}
+
+// ResolveImages defaults the builder and the tool image, resolving their versions from the version stream
+func (c *CNBConfig) ResolveImages(resolver *versionstream.VersionResolver) error {
+	var err error
+	switch c.Platform {
+	case CNBPlatformKpack:
+		if c.Builder == "" {
+			c.Builder = DefaultKpackClusterBuilder
+		}
+		if c.ToolImage == "" {
+			c.ToolImage = DefaultKpImage
+		}
+		c.ToolImage, err = resolver.ResolveDockerImage(c.ToolImage)
+		if err != nil {
+			return err
+		}
+	default:
+		if c.Builder == "" {
+			c.Builder = DefaultCNBBuilderImage
+		}
+		c.Builder, err = resolver.ResolveDockerImage(c.Builder)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// BuildStep returns the step of the pipeline building and publishing the image with the given tag from the
+// given git revision
+func (c *CNBConfig) BuildStep(tag string, revision string) *syntax.Step {
+	image := c.Image + ":" + tag
+	if c.Platform == CNBPlatformKpack {
+		return &syntax.Step{
+			Name:  cnbStepName,
+			Image: c.ToolImage,
+			Command: fmt.Sprintf("kp image save %s --tag %s --additional-tag %s --cluster-builder %s --git $SOURCE_URL --git-revision %s --wait",
+				c.AppName, c.Image, image, c.Builder, revision),
+		}
+	}
+	return &syntax.Step{
+		Name:    cnbStepName,
+		Image:   c.Builder,
+		Command: fmt.Sprintf("/cnb/lifecycle/creator -app=. -cache-image=%s-cache %s", c.Image, image),
+	}
+}
+
+// ApplyToProjectConfig replaces the steps of the build pack which build the image of the Pull Request and release
+// pipelines with a Cloud Native Buildpacks build
+func (c *CNBConfig) ApplyToProjectConfig(projectConfig *config.ProjectConfig) {
+	pipelines := &projectConfig.GetOrCreatePipelineConfig().Pipelines
+	if pipelines.PullRequest == nil {
+		pipelines.PullRequest = &jenkinsfile.PipelineLifecycles{}
+	}
+	pipelines.PullRequest.Build = &jenkinsfile.PipelineLifecycle{
+		Replace: true,
+		Steps:   []*syntax.Step{c.BuildStep("$PREVIEW_VERSION", "$PULL_PULL_SHA")},
+	}
+	if pipelines.Release == nil {
+		pipelines.Release = &jenkinsfile.PipelineLifecycles{}
+	}
+	pipelines.Release.Build = &jenkinsfile.PipelineLifecycle{
+		Replace: true,
+		Steps:   []*syntax.Step{c.BuildStep("$VERSION", "$PULL_BASE_SHA")},
+	}
+}
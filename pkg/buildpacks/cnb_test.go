@@ -0,0 +1,79 @@
+// +build unit
+
+package buildpacks_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jenkins-x/jx/v2/pkg/buildpacks"
+	"github.com/jenkins-x/jx/v2/pkg/config"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/jenkins-x/jx/v2/pkg/versionstream"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCNBResolveImages(t *testing.T) {
+	t.Parallel()
+	versionsDir, err := ioutil.TempDir("", "versions")
+	require.NoError(t, err)
+	defer os.RemoveAll(versionsDir)
+
+	dockerDir := filepath.Join(versionsDir, "docker", "paketobuildpacks")
+	require.NoError(t, os.MkdirAll(dockerDir, util.DefaultWritePermissions))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dockerDir, "builder.yml"), []byte("version: 0.1.2-base\n"), util.DefaultWritePermissions))
+	resolver := &versionstream.VersionResolver{VersionsDir: versionsDir}
+
+	cnb := &buildpacks.CNBConfig{Platform: buildpacks.CNBPlatformPack}
+	require.NoError(t, cnb.ResolveImages(resolver))
+	assert.Equal(t, "paketobuildpacks/builder:0.1.2-base", cnb.Builder)
+
+	cnb = &buildpacks.CNBConfig{Platform: buildpacks.CNBPlatformPack, Builder: "gcr.io/buildpacks/builder:v1"}
+	require.NoError(t, cnb.ResolveImages(resolver))
+	assert.Equal(t, "gcr.io/buildpacks/builder:v1", cnb.Builder)
+
+	cnb = &buildpacks.CNBConfig{Platform: buildpacks.CNBPlatformKpack}
+	require.NoError(t, cnb.ResolveImages(resolver))
+	assert.Equal(t, buildpacks.DefaultKpackClusterBuilder, cnb.Builder)
+}
+
+func TestCNBApplyToProjectConfig(t *testing.T) {
+	t.Parallel()
+	cnb := &buildpacks.CNBConfig{
+		Platform: buildpacks.CNBPlatformPack,
+		Builder:  "paketobuildpacks/builder:0.1.2-base",
+		Image:    "$DOCKER_REGISTRY/myorg/myapp",
+		AppName:  "myapp",
+	}
+	projectConfig := &config.ProjectConfig{BuildPack: "go"}
+	cnb.ApplyToProjectConfig(projectConfig)
+
+	pipelines := projectConfig.PipelineConfig.Pipelines
+	require.NotNil(t, pipelines.PullRequest)
+	require.NotNil(t, pipelines.PullRequest.Build)
+	assert.True(t, pipelines.PullRequest.Build.Replace)
+	require.Len(t, pipelines.PullRequest.Build.Steps, 1)
+	step := pipelines.PullRequest.Build.Steps[0]
+	assert.Equal(t, "paketobuildpacks/builder:0.1.2-base", step.Image)
+	assert.Equal(t, "/cnb/lifecycle/creator -app=. -cache-image=$DOCKER_REGISTRY/myorg/myapp-cache $DOCKER_REGISTRY/myorg/myapp:$PREVIEW_VERSION", step.Command)
+
+	require.NotNil(t, pipelines.Release)
+	require.NotNil(t, pipelines.Release.Build)
+	assert.Contains(t, pipelines.Release.Build.Steps[0].Command, "$DOCKER_REGISTRY/myorg/myapp:$VERSION")
+
+	cnb.Platform = buildpacks.CNBPlatformKpack
+	cnb.Builder = "default"
+	cnb.ToolImage = "kpack/kp:0.1.0"
+	step = cnb.BuildStep("$VERSION", "$PULL_BASE_SHA")
+	assert.Equal(t, "kpack/kp:0.1.0", step.Image)
+	assert.Equal(t, "kp image save myapp --tag $DOCKER_REGISTRY/myorg/myapp --additional-tag $DOCKER_REGISTRY/myorg/myapp:$VERSION --cluster-builder default --git $SOURCE_URL --git-revision $PULL_BASE_SHA --wait", step.Command)
+}
+
+func TestCNBValidate(t *testing.T) {
+	t.Parallel()
+	assert.NoError(t, (&buildpacks.CNBConfig{Platform: buildpacks.CNBPlatformKpack}).Validate())
+	assert.Error(t, (&buildpacks.CNBConfig{Platform: "docker"}).Validate())
+}
@@ -0,0 +1,267 @@
+package buildpacks
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/Azure/draft/pkg/linguist"
+	"github.com/jenkins-x/jx/v2/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// BuildPackOverrideFileName the file of a repository which chooses its build pack instead of detecting it
+	BuildPackOverrideFileName = ".jx/buildpack.yaml"
+
+	// CustomJenkinsBuildPack the build pack of repositories with their own Jenkinsfile
+	CustomJenkinsBuildPack = "custom-jenkins"
+
+	rootMarkerScore      = 100
+	subDirMarkerScore    = 40
+	buildToolMarkerScore = 200
+)
+
+// BuildPackOverride the build pack of a repository chosen in its override file
+type BuildPackOverride struct {
+	// Pack the name of the build pack
+	Pack string `json:"pack,omitempty"`
+	// Version the git reference of the build packs repository
+	Version string `json:"version,omitempty"`
+	// Image the image of the containers building the repository
+	Image string `json:"image,omitempty"`
+}
+
+// BuildPackCandidate a build pack matching a repository with a score of how well it matches
+type BuildPackCandidate struct {
+	// Pack the name of the build pack
+	Pack string `json:"pack"`
+	// Score the higher the better the build pack matches
+	Score int `json:"score"`
+	// Reasons what in the repository matches the build pack
+	Reasons []string `json:"reasons,omitempty"`
+}
+
+// BuildPackDetection the build pack chosen for a repository and how it was chosen
+type BuildPackDetection struct {
+	// Pack the name of the chosen build pack which is empty if none matches
+	Pack string `json:"pack,omitempty"`
+	// Override the override file of the repository if it has one
+	Override *BuildPackOverride `json:"override,omitempty"`
+	// Candidates the build packs matching the repository from the best match
+	Candidates []*BuildPackCandidate `json:"candidates,omitempty"`
+}
+
+// buildPackMarker a file whose presence scores a build pack
+type buildPackMarker struct {
+	glob  string
+	pack  string
+	score int
+}
+
+// buildPackMarkers the files of the build tools of the languages of the build packs. The build tools which only have one
+// build pack outweigh the detected languages
+var buildPackMarkers = []buildPackMarker{
+	{glob: "build.gradle", pack: "gradle", score: buildToolMarkerScore},
+	{glob: "build.gradle.kts", pack: "gradle", score: buildToolMarkerScore},
+	{glob: "plugins.txt", pack: "jenkins", score: buildToolMarkerScore},
+	{glob: "packager-config.yml", pack: "cwp", score: buildToolMarkerScore},
+	{glob: "env/Chart.yaml", pack: "environment", score: buildToolMarkerScore},
+	{glob: "go.mod", pack: "go", score: rootMarkerScore},
+	{glob: "Gopkg.toml", pack: "go", score: rootMarkerScore},
+	{glob: "package.json", pack: "javascript", score: rootMarkerScore},
+	{glob: "tsconfig.json", pack: "typescript", score: rootMarkerScore},
+	{glob: "requirements.txt", pack: "python", score: rootMarkerScore},
+	{glob: "setup.py", pack: "python", score: rootMarkerScore},
+	{glob: "Pipfile", pack: "python", score: rootMarkerScore},
+	{glob: "Gemfile", pack: "ruby", score: rootMarkerScore},
+	{glob: "Cargo.toml", pack: "rust", score: rootMarkerScore},
+	{glob: "composer.json", pack: "php", score: rootMarkerScore},
+	{glob: "build.sbt", pack: "scala", score: rootMarkerScore},
+	{glob: "Package.swift", pack: "swift", score: rootMarkerScore},
+	{glob: "*.csproj", pack: "csharp", score: rootMarkerScore},
+}
+
+// LoadBuildPackOverride loads the build pack override file of the repository returning nil if it has none
+func LoadBuildPackOverride(dir string) (*BuildPackOverride, error) {
+	fileName := filepath.Join(dir, filepath.FromSlash(BuildPackOverrideFileName))
+	exists, err := util.FileExists(fileName)
+	if err != nil || !exists {
+		return nil, err
+	}
+	data, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return nil, errors.Wrapf(err, "reading %s", fileName)
+	}
+	override := &BuildPackOverride{}
+	err = yaml.Unmarshal(data, override)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unmarshalling %s", fileName)
+	}
+	return override, nil
+}
+
+// DetectBuildPack scores the build packs in the packs directory against the build tools, languages, Dockerfile, charts
+// and Jenkinsfile of the repository, choosing the best match unless the override file of the repository chooses
+// an existing build pack. The build tools of the subdirectories also score so that repositories with several languages
+// are detected
+func DetectBuildPack(dir string, packsDir string) (*BuildPackDetection, error) {
+	override, err := LoadBuildPackOverride(dir)
+	if err != nil {
+		return nil, err
+	}
+	answer := &BuildPackDetection{Override: override}
+
+	scores := map[string]*BuildPackCandidate{}
+	add := func(pack string, score int, reason string) {
+		c := scores[pack]
+		if c == nil {
+			c = &BuildPackCandidate{Pack: pack}
+			scores[pack] = c
+		}
+		c.Score += score
+		c.Reasons = append(c.Reasons, reason)
+	}
+
+	err = scoreBuildTools(dir, packsDir, add)
+	if err != nil {
+		return nil, err
+	}
+	langs, err := linguist.ProcessDir(dir)
+	if err != nil {
+		log.Logger().Warnf("Failed to detect the languages of %s: %s", dir, err.Error())
+	}
+	for _, lang := range langs {
+		detectedLang := linguist.Alias(lang)
+		if int(detectedLang.Percent) > 0 {
+			add(strings.ToLower(detectedLang.Language), int(detectedLang.Percent), fmt.Sprintf("%.1f%% %s", detectedLang.Percent, detectedLang.Language))
+		}
+	}
+	err = scoreDockerAndCharts(dir, add)
+	if err != nil {
+		return nil, err
+	}
+
+	for pack, c := range scores {
+		exists, err := util.DirExists(filepath.Join(packsDir, pack))
+		if err != nil {
+			return nil, err
+		}
+		if exists {
+			answer.Candidates = append(answer.Candidates, c)
+		}
+	}
+	sort.Slice(answer.Candidates, func(i, j int) bool {
+		c1 := answer.Candidates[i]
+		c2 := answer.Candidates[j]
+		if c1.Score != c2.Score {
+			return c1.Score > c2.Score
+		}
+		return c1.Pack < c2.Pack
+	})
+
+	if override != nil && override.Pack != "" {
+		exists, err := util.DirExists(filepath.Join(packsDir, override.Pack))
+		if err != nil {
+			return nil, err
+		}
+		if exists {
+			answer.Pack = override.Pack
+			return answer, nil
+		}
+		log.Logger().Warnf("The build pack %s of %s does not exist in %s so detecting the build pack", override.Pack, BuildPackOverrideFileName, packsDir)
+	}
+	if len(answer.Candidates) > 0 {
+		answer.Pack = answer.Candidates[0].Pack
+	}
+	return answer, nil
+}
+
+// scoreBuildTools scores the build packs of the build tools in the repository and in its subdirectories
+func scoreBuildTools(dir string, packsDir string, add func(pack string, score int, reason string)) error {
+	pomName := filepath.Join(dir, "pom.xml")
+	exists, err := util.FileExists(pomName)
+	if err != nil {
+		return err
+	}
+	if exists {
+		pack, err := util.PomFlavour(pomName)
+		if err != nil {
+			return err
+		}
+		exists, err = util.DirExists(filepath.Join(packsDir, pack))
+		if err != nil {
+			return err
+		}
+		if !exists {
+			pack = util.MAVEN
+		}
+		add(pack, buildToolMarkerScore, "pom.xml")
+	}
+
+	for _, marker := range buildPackMarkers {
+		files, err := filepath.Glob(filepath.Join(dir, filepath.FromSlash(marker.glob)))
+		if err != nil {
+			return err
+		}
+		if len(files) > 0 {
+			add(marker.pack, marker.score, marker.glob)
+		}
+		// only the build tools of languages are looked for in the subdirectories of multi language repositories
+		if marker.score != rootMarkerScore {
+			continue
+		}
+		files, err = filepath.Glob(filepath.Join(dir, "*", marker.glob))
+		if err != nil {
+			return err
+		}
+		for _, f := range files {
+			rel, err := filepath.Rel(dir, f)
+			if err != nil {
+				return err
+			}
+			add(marker.pack, subDirMarkerScore, filepath.ToSlash(rel))
+		}
+	}
+	return nil
+}
+
+// scoreDockerAndCharts scores the docker, helm and custom Jenkinsfile build packs which are only used when the
+// repository matches no language
+func scoreDockerAndCharts(dir string, add func(pack string, score int, reason string)) error {
+	hasDocker, err := util.FileExists(filepath.Join(dir, "Dockerfile"))
+	if err != nil {
+		return err
+	}
+	charts, err := filepath.Glob(filepath.Join(dir, "charts", "*", "Chart.yaml"))
+	if err != nil {
+		return err
+	}
+	if len(charts) == 0 {
+		charts, err = filepath.Glob(filepath.Join(dir, "*", "Chart.yaml"))
+		if err != nil {
+			return err
+		}
+	}
+	hasHelm := len(charts) > 0
+	switch {
+	case hasDocker && hasHelm:
+		add("docker-helm", 3, "Dockerfile and chart")
+	case hasDocker:
+		add("docker", 2, "Dockerfile")
+	case hasHelm:
+		add("helm", 2, "chart")
+	}
+	hasJenkinsfile, err := util.FileExists(filepath.Join(dir, "Jenkinsfile"))
+	if err != nil {
+		return err
+	}
+	if hasJenkinsfile {
+		add(CustomJenkinsBuildPack, 1, "Jenkinsfile")
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+// +build unit
+
+package buildpacks_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jenkins-x/jx/v2/pkg/buildpacks"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func createDetectTestDirs(t *testing.T, files map[string]string) (string, string, func()) {
+	packsDir, err := ioutil.TempDir("", "packs")
+	require.NoError(t, err)
+	for _, pack := range []string{"go", "javascript", "maven", "docker", "docker-helm", "custom-jenkins"} {
+		require.NoError(t, os.MkdirAll(filepath.Join(packsDir, pack), util.DefaultWritePermissions))
+	}
+	dir, err := ioutil.TempDir("", "repo")
+	require.NoError(t, err)
+	for name, text := range files {
+		fileName := filepath.Join(dir, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(fileName), util.DefaultWritePermissions))
+		require.NoError(t, ioutil.WriteFile(fileName, []byte(text), util.DefaultWritePermissions))
+	}
+	return dir, packsDir, func() {
+		os.RemoveAll(dir)
+		os.RemoveAll(packsDir)
+	}
+}
+
+func TestDetectBuildPackMultiLanguage(t *testing.T) {
+	t.Parallel()
+	dir, packsDir, cleanup := createDetectTestDirs(t, map[string]string{
+		"go.mod":                "module example.com/myapp\n",
+		"main.go":               "package main\n\nfunc main() {}\n",
+		"Dockerfile":            "FROM scratch\n",
+		"frontend/package.json": "{}\n",
+		"frontend/index.js":     "console.log('hello')\n",
+	})
+	defer cleanup()
+
+	detection, err := buildpacks.DetectBuildPack(dir, packsDir)
+	require.NoError(t, err)
+	assert.Equal(t, "go", detection.Pack)
+	assert.Nil(t, detection.Override)
+
+	packs := []string{}
+	for _, c := range detection.Candidates {
+		packs = append(packs, c.Pack)
+	}
+	require.Contains(t, packs, "javascript")
+	assert.Equal(t, "go", packs[0])
+	assert.Equal(t, "docker", packs[len(packs)-1], "the docker build pack should only be used when no language matches")
+	for _, c := range detection.Candidates {
+		if c.Pack == "javascript" {
+			assert.Contains(t, c.Reasons, "frontend/package.json")
+		}
+	}
+}
+
+func TestDetectBuildPackFallsBackToDocker(t *testing.T) {
+	t.Parallel()
+	dir, packsDir, cleanup := createDetectTestDirs(t, map[string]string{
+		"Dockerfile":               "FROM scratch\n",
+		"charts/myapp/Chart.yaml":  "name: myapp\n",
+		"charts/myapp/values.yaml": "replicaCount: 1\n",
+	})
+	defer cleanup()
+
+	detection, err := buildpacks.DetectBuildPack(dir, packsDir)
+	require.NoError(t, err)
+	assert.Equal(t, "docker-helm", detection.Pack)
+}
+
+func TestDetectBuildPackOverride(t *testing.T) {
+	t.Parallel()
+	dir, packsDir, cleanup := createDetectTestDirs(t, map[string]string{
+		"go.mod":                             "module example.com/myapp\n",
+		"main.go":                            "package main\n\nfunc main() {}\n",
+		buildpacks.BuildPackOverrideFileName: "pack: docker\nversion: v1.2.3\nimage: gcr.io/myorg/builder:1.0.0\n",
+	})
+	defer cleanup()
+
+	detection, err := buildpacks.DetectBuildPack(dir, packsDir)
+	require.NoError(t, err)
+	assert.Equal(t, "docker", detection.Pack)
+	require.NotNil(t, detection.Override)
+	assert.Equal(t, "v1.2.3", detection.Override.Version)
+	assert.Equal(t, "gcr.io/myorg/builder:1.0.0", detection.Override.Image)
+
+	overrideFile := filepath.Join(dir, filepath.FromSlash(buildpacks.BuildPackOverrideFileName))
+	require.NoError(t, ioutil.WriteFile(overrideFile, []byte("pack: does-not-exist\n"), util.DefaultWritePermissions))
+	detection, err = buildpacks.DetectBuildPack(dir, packsDir)
+	require.NoError(t, err)
+	assert.Equal(t, "go", detection.Pack, "an override of a missing build pack should be ignored")
+}
@@ -0,0 +1,148 @@
+package chartrepo
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"path"
+	"path/filepath"
+	"time"
+
+	"github.com/ghodss/yaml"
+	"github.com/jenkins-x/jx/v2/pkg/cloud/buckets"
+	"github.com/jenkins-x/jx/v2/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+	"gocloud.dev/blob"
+	"k8s.io/helm/pkg/chartutil"
+	"k8s.io/helm/pkg/repo"
+)
+
+// IndexFileName the name of the index of a chart repository
+const IndexFileName = "index.yaml"
+
+// Bucket stores charts in a GCS or S3 bucket, maintaining the index of the repository in the bucket. The bucket URL
+// can contain a path, such as gs://mybucket/charts, to store the charts in a folder of the bucket
+type Bucket struct {
+	RepoURL   string
+	BucketURL string
+	Timeout   time.Duration
+}
+
+// NewBucket creates a chart repository stored in the bucket and served from the repository URL
+func NewBucket(repoURL string, bucketURL string) *Bucket {
+	return &Bucket{
+		RepoURL:   repoURL,
+		BucketURL: bucketURL,
+		Timeout:   time.Minute,
+	}
+}
+
+// URL returns the URL helm uses to fetch the charts stored in the bucket
+func (b *Bucket) URL() string {
+	return b.RepoURL
+}
+
+// Publish writes the chart archive to the bucket and adds the chart to the index of the repository
+func (b *Bucket) Publish(tarball string) error {
+	u, err := url.Parse(b.BucketURL)
+	if err != nil {
+		return errors.Wrapf(err, "failed to parse bucket URL %s", b.BucketURL)
+	}
+	bucketURL, prefix := buckets.SplitBucketURL(u)
+	if u.Scheme == "file" {
+		// the path of a local bucket is the directory of the bucket
+		bucketURL, prefix = b.BucketURL, ""
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), b.Timeout)
+	defer cancel()
+	bucket, err := blob.Open(ctx, bucketURL)
+	if err != nil {
+		return errors.Wrapf(err, "failed to open bucket %s", bucketURL)
+	}
+
+	data, err := ioutil.ReadFile(tarball)
+	if err != nil {
+		return errors.Wrapf(err, "failed to read the chart archive '%s'", tarball)
+	}
+	name := filepath.Base(tarball)
+	key := path.Join(prefix, name)
+	log.Logger().Infof("Uploading chart file %s to %s", util.ColorInfo(tarball), util.ColorInfo(b.BucketURL))
+	err = bucket.WriteAll(ctx, key, data, nil)
+	if err != nil {
+		return errors.Wrapf(err, "failed to write %s to bucket %s", key, bucketURL)
+	}
+
+	indexKey := path.Join(prefix, IndexFileName)
+	index, err := loadIndex(ctx, bucket, indexKey)
+	if err != nil {
+		return errors.Wrapf(err, "failed to load the index of the chart repository in bucket %s", b.BucketURL)
+	}
+	err = AddChartToIndex(index, tarball, b.RepoURL)
+	if err != nil {
+		return err
+	}
+	indexData, err := yaml.Marshal(index)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal the index of the chart repository")
+	}
+	err = bucket.WriteAll(ctx, indexKey, indexData, nil)
+	if err != nil {
+		return errors.Wrapf(err, "failed to write %s to bucket %s", indexKey, bucketURL)
+	}
+	return nil
+}
+
+// AddChartToIndex adds the chart archive served from the repository URL to the index, replacing any existing entry
+// for the same version of the chart
+func AddChartToIndex(index *repo.IndexFile, tarball string, repoURL string) error {
+	chart, err := chartutil.Load(tarball)
+	if err != nil {
+		return errors.Wrapf(err, "failed to load the chart archive '%s'", tarball)
+	}
+	data, err := ioutil.ReadFile(tarball)
+	if err != nil {
+		return errors.Wrapf(err, "failed to read the chart archive '%s'", tarball)
+	}
+	digest := fmt.Sprintf("%x", sha256.Sum256(data))
+	md := chart.Metadata
+	versions := index.Entries[md.Name]
+	for i, v := range versions {
+		if v.Version == md.Version {
+			index.Entries[md.Name] = append(versions[:i], versions[i+1:]...)
+			break
+		}
+	}
+	index.Add(md, filepath.Base(tarball), repoURL, digest)
+	index.SortEntries()
+	index.Generated = time.Now()
+	return nil
+}
+
+// loadIndex loads the index of the repository from the bucket returning a new index if there is none yet
+func loadIndex(ctx context.Context, bucket *blob.Bucket, key string) (*repo.IndexFile, error) {
+	iter := bucket.List(&blob.ListOptions{Prefix: key})
+	obj, err := iter.Next(ctx)
+	if err == io.EOF || (err == nil && obj.Key != key) {
+		return repo.NewIndexFile(), nil
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to find %s", key)
+	}
+	data, err := bucket.ReadAll(ctx, key)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read %s", key)
+	}
+	index := &repo.IndexFile{}
+	err = yaml.Unmarshal(data, index)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse %s", key)
+	}
+	if index.Entries == nil {
+		index.Entries = map[string]repo.ChartVersions{}
+	}
+	return index, nil
+}
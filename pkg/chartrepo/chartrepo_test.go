@@ -0,0 +1,174 @@
+// +build unit
+
+package chartrepo_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ghodss/yaml"
+	"github.com/jenkins-x/jx/v2/pkg/chartrepo"
+	"github.com/jenkins-x/jx/v2/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/helm/pkg/chartutil"
+	"k8s.io/helm/pkg/proto/hapi/chart"
+	"k8s.io/helm/pkg/repo"
+)
+
+type uploadedChart struct {
+	method      string
+	path        string
+	contentType string
+	username    string
+	password    string
+}
+
+func newChartServer(uploaded *uploadedChart) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		uploaded.method = r.Method
+		uploaded.path = r.URL.Path
+		uploaded.contentType = r.Header.Get("Content-Type")
+		uploaded.username, uploaded.password, _ = r.BasicAuth()
+		w.WriteHeader(http.StatusCreated)
+	}))
+}
+
+func packageChart(t *testing.T, dir string, version string) string {
+	tarball, err := chartutil.Save(&chart.Chart{
+		Metadata: &chart.Metadata{
+			ApiVersion: "v1",
+			Name:       "myapp",
+			Version:    version,
+		},
+	}, dir)
+	require.NoError(t, err)
+	return tarball
+}
+
+func TestPublishChart(t *testing.T) {
+	dir, err := ioutil.TempDir("", "test-chartrepo-")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir) //nolint:errcheck
+	tarball := packageChart(t, dir, "1.0.0")
+
+	testCases := []struct {
+		kind        config.ChartRepositoryType
+		repoPath    string
+		method      string
+		path        string
+		contentType string
+	}{
+		{config.ChartRepositoryTypeChartMuseum, "", http.MethodPost, "/api/charts", "application/gzip"},
+		{config.ChartRepositoryTypeNexus, "/repository/helm-hosted", http.MethodPut, "/repository/helm-hosted/myapp-1.0.0.tgz", "application/gzip"},
+		{config.ChartRepositoryTypeArtifactory, "/artifactory/helm-local", http.MethodPut, "/artifactory/helm-local/myapp-1.0.0.tgz", "application/gzip"},
+		{config.ChartRepositoryTypeHarbor, "/chartrepo/myproject", http.MethodPost, "/api/chartrepo/myproject/charts", "multipart/form-data"},
+	}
+	for _, tc := range testCases {
+		t.Run(string(tc.kind), func(t *testing.T) {
+			uploaded := &uploadedChart{}
+			server := newChartServer(uploaded)
+			defer server.Close()
+
+			chartRepo, err := chartrepo.NewChartRepository(tc.kind, server.URL+tc.repoPath, "", "user", "pass")
+			require.NoError(t, err)
+			assert.Equal(t, server.URL+tc.repoPath, chartRepo.URL())
+
+			err = chartRepo.Publish(tarball)
+			require.NoError(t, err)
+			assert.Equal(t, tc.method, uploaded.method)
+			assert.Equal(t, tc.path, uploaded.path)
+			assert.Contains(t, uploaded.contentType, tc.contentType)
+			assert.Equal(t, "user", uploaded.username)
+			assert.Equal(t, "pass", uploaded.password)
+		})
+	}
+}
+
+func TestPublishChartFailure(t *testing.T) {
+	dir, err := ioutil.TempDir("", "test-chartrepo-")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir) //nolint:errcheck
+	tarball := packageChart(t, dir, "1.0.0")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	chartRepo, err := chartrepo.NewChartRepository(config.ChartRepositoryTypeChartMuseum, server.URL, "", "user", "wrong")
+	require.NoError(t, err)
+	err = chartRepo.Publish(tarball)
+	assert.Error(t, err)
+}
+
+func TestPublishChartToBucket(t *testing.T) {
+	dir, err := ioutil.TempDir("", "test-chartrepo-")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir) //nolint:errcheck
+	chartsDir := filepath.Join(dir, "charts")
+	bucketDir := filepath.Join(dir, "bucket")
+	require.NoError(t, os.MkdirAll(chartsDir, 0755))
+	require.NoError(t, os.MkdirAll(bucketDir, 0755))
+
+	repoURL := "https://storage.googleapis.com/mybucket"
+	chartRepo, err := chartrepo.NewChartRepository(config.ChartRepositoryTypeBucket, repoURL, "file://"+bucketDir, "", "")
+	require.NoError(t, err)
+
+	for _, version := range []string{"1.0.0", "1.1.0", "1.1.0"} {
+		err = chartRepo.Publish(packageChart(t, chartsDir, version))
+		require.NoError(t, err)
+	}
+
+	assert.FileExists(t, filepath.Join(bucketDir, "myapp-1.0.0.tgz"))
+	assert.FileExists(t, filepath.Join(bucketDir, "myapp-1.1.0.tgz"))
+	data, err := ioutil.ReadFile(filepath.Join(bucketDir, chartrepo.IndexFileName))
+	require.NoError(t, err)
+	index := &repo.IndexFile{}
+	require.NoError(t, yaml.Unmarshal(data, index))
+
+	versions := index.Entries["myapp"]
+	require.Len(t, versions, 2)
+	assert.Equal(t, "1.1.0", versions[0].Version)
+	assert.Equal(t, []string{repoURL + "/myapp-1.1.0.tgz"}, versions[0].URLs)
+	assert.Equal(t, "1.0.0", versions[1].Version)
+	assert.NotEmpty(t, versions[1].Digest)
+}
+
+func TestNewChartRepositoryForBucketRequiresBucketURL(t *testing.T) {
+	_, err := chartrepo.NewChartRepository(config.ChartRepositoryTypeBucket, "https://charts.example.com", "", "", "")
+	assert.Error(t, err)
+
+	_, err = chartrepo.NewChartRepository("unknown", "https://charts.example.com", "", "", "")
+	assert.Error(t, err)
+}
+
+func TestBucketRepositoryURL(t *testing.T) {
+	testCases := map[string]string{
+		"gs://mybucket":        "https://storage.googleapis.com/mybucket",
+		"gs://mybucket/charts": "https://storage.googleapis.com/mybucket/charts",
+		"s3://mybucket":        "https://mybucket.s3.amazonaws.com",
+		"s3://mybucket/charts": "https://mybucket.s3.amazonaws.com/charts",
+	}
+	for bucketURL, expected := range testCases {
+		actual, err := chartrepo.BucketRepositoryURL(bucketURL)
+		require.NoError(t, err)
+		assert.Equal(t, expected, actual, "repository URL of bucket %s", bucketURL)
+	}
+
+	_, err := chartrepo.BucketRepositoryURL("azblob://mybucket")
+	assert.Error(t, err)
+}
+
+func TestHarborUploadURL(t *testing.T) {
+	actual, err := chartrepo.HarborUploadURL("https://harbor.example.com/chartrepo/myproject/")
+	require.NoError(t, err)
+	assert.Equal(t, "https://harbor.example.com/api/chartrepo/myproject/charts", actual)
+
+	_, err = chartrepo.HarborUploadURL("https://harbor.example.com/myproject")
+	assert.Error(t, err)
+}
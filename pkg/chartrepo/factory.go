@@ -0,0 +1,61 @@
+package chartrepo
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/jenkins-x/jx/v2/pkg/config"
+	"github.com/pkg/errors"
+)
+
+// NewChartRepository creates the chart repository of the given kind. The bucket URL is only used by bucket
+// repositories which do not need credentials
+func NewChartRepository(kind config.ChartRepositoryType, repoURL string, bucketURL string, username string,
+	password string) (ChartRepository, error) {
+	server := HTTPServer{
+		RepoURL:  repoURL,
+		Username: username,
+		Password: password,
+		Client:   &http.Client{},
+	}
+	switch kind {
+	case config.ChartRepositoryTypeChartMuseum, "":
+		return &ChartMuseum{HTTPServer: server}, nil
+	case config.ChartRepositoryTypeHarbor:
+		return &Harbor{HTTPServer: server}, nil
+	case config.ChartRepositoryTypeNexus, config.ChartRepositoryTypeArtifactory:
+		return &UploadServer{HTTPServer: server}, nil
+	case config.ChartRepositoryTypeBucket:
+		if bucketURL == "" {
+			return nil, errors.New("no bucket URL for the chart repository, please configure storage.repository.url")
+		}
+		if repoURL == "" {
+			var err error
+			repoURL, err = BucketRepositoryURL(bucketURL)
+			if err != nil {
+				return nil, err
+			}
+		}
+		return NewBucket(repoURL, bucketURL), nil
+	default:
+		return nil, errors.Errorf("unknown chart repository kind %s, should be one of %s", kind,
+			strings.Join(config.ChartRepositoryTypeValues, ", "))
+	}
+}
+
+// BucketRepositoryURL returns the URL helm uses to fetch the charts stored in the GCS or S3 bucket
+func BucketRepositoryURL(bucketURL string) (string, error) {
+	u, err := url.Parse(bucketURL)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to parse bucket URL %s", bucketURL)
+	}
+	switch u.Scheme {
+	case "gs":
+		return "https://storage.googleapis.com/" + u.Host + strings.TrimSuffix(u.Path, "/"), nil
+	case "s3":
+		return "https://" + u.Host + ".s3.amazonaws.com" + strings.TrimSuffix(u.Path, "/"), nil
+	default:
+		return "", errors.Errorf("cannot determine the chart repository URL of bucket %s, please configure cluster.chartRepository", bucketURL)
+	}
+}
@@ -0,0 +1,140 @@
+package chartrepo
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/jenkins-x/jx/v2/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+)
+
+// HTTPServer is a chart repository server which charts are uploaded to over HTTP using basic authentication
+type HTTPServer struct {
+	RepoURL  string
+	Username string
+	Password string
+	Client   *http.Client
+}
+
+// URL returns the URL of the chart repository
+func (s *HTTPServer) URL() string {
+	return s.RepoURL
+}
+
+// upload sends the body to the URL failing if the server does not accept it
+func (s *HTTPServer) upload(method string, u string, contentType string, body io.Reader) error {
+	req, err := http.NewRequest(method, u, body)
+	if err != nil {
+		return errors.Wrapf(err, "failed to build the chart upload request for endpoint '%s'", u)
+	}
+	if s.Username != "" || s.Password != "" {
+		req.SetBasicAuth(s.Username, s.Password)
+	}
+	req.Header.Set("Content-Type", contentType)
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		return errors.Wrapf(err, "failed to execute the chart upload HTTP request, url: '%s'", u)
+	}
+	defer res.Body.Close()
+	data, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return errors.Wrap(err, "failed to read the response body of chart upload request")
+	}
+	responseMessage := string(data)
+	log.Logger().Infof("Received %d response: %s", res.StatusCode, responseMessage)
+	if res.StatusCode >= 300 {
+		return fmt.Errorf("Failed to post chart to %s due to response %d: %s", u, res.StatusCode, responseMessage)
+	}
+	return nil
+}
+
+// ChartMuseum uploads charts using the ChartMuseum API which is also supported by bucketrepo
+type ChartMuseum struct {
+	HTTPServer
+}
+
+// Publish posts the chart to the ChartMuseum API which maintains the index of the repository
+func (c *ChartMuseum) Publish(tarball string) error {
+	file, err := os.Open(tarball)
+	if err != nil {
+		return errors.Wrapf(err, "failed to open the chart archive '%s'", tarball)
+	}
+	defer file.Close()
+	u := util.UrlJoin(c.RepoURL, "/api/charts")
+	log.Logger().Infof("Uploading chart file %s to %s", util.ColorInfo(tarball), util.ColorInfo(u))
+	return c.upload(http.MethodPost, u, "application/gzip", file)
+}
+
+// Harbor uploads charts to the chart repository of a Harbor project whose URL is of the form
+// https://harbor.example.com/chartrepo/myproject
+type Harbor struct {
+	HTTPServer
+}
+
+// Publish posts the chart as a form to the chart repository API of the Harbor project
+func (h *Harbor) Publish(tarball string) error {
+	u, err := HarborUploadURL(h.RepoURL)
+	if err != nil {
+		return err
+	}
+	data, err := ioutil.ReadFile(tarball)
+	if err != nil {
+		return errors.Wrapf(err, "failed to read the chart archive '%s'", tarball)
+	}
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	part, err := writer.CreateFormFile("chart", filepath.Base(tarball))
+	if err != nil {
+		return errors.Wrap(err, "failed to create the chart upload form")
+	}
+	_, err = part.Write(data)
+	if err != nil {
+		return errors.Wrap(err, "failed to write the chart upload form")
+	}
+	err = writer.Close()
+	if err != nil {
+		return errors.Wrap(err, "failed to write the chart upload form")
+	}
+	log.Logger().Infof("Uploading chart file %s to %s", util.ColorInfo(tarball), util.ColorInfo(u))
+	return h.upload(http.MethodPost, u, writer.FormDataContentType(), &body)
+}
+
+// HarborUploadURL returns the API URL charts are posted to for the chart repository URL of a Harbor project
+func HarborUploadURL(repoURL string) (string, error) {
+	repoURL = strings.TrimSuffix(repoURL, "/")
+	i := strings.LastIndex(repoURL, "/chartrepo/")
+	if i < 0 {
+		return "", errors.Errorf("invalid Harbor chart repository URL %s, should be of the form https://harbor.example.com/chartrepo/myproject", repoURL)
+	}
+	return repoURL[:i] + "/api" + repoURL[i:] + "/charts", nil
+}
+
+// UploadServer uploads charts by putting the chart archive in the repository which then maintains its index, such as
+// Nexus helm hosted repositories and Artifactory helm repositories
+type UploadServer struct {
+	HTTPServer
+}
+
+// Publish puts the chart archive into the repository
+func (s *UploadServer) Publish(tarball string) error {
+	file, err := os.Open(tarball)
+	if err != nil {
+		return errors.Wrapf(err, "failed to open the chart archive '%s'", tarball)
+	}
+	defer file.Close()
+	u := util.UrlJoin(s.RepoURL, filepath.Base(tarball))
+	log.Logger().Infof("Uploading chart file %s to %s", util.ColorInfo(tarball), util.ColorInfo(u))
+	return s.upload(http.MethodPut, u, "application/gzip", file)
+}
@@ -0,0 +1,11 @@
+package chartrepo
+
+// ChartRepository is a helm chart repository which charts are released to
+type ChartRepository interface {
+	// URL returns the URL helm uses to install charts from the repository
+	URL() string
+
+	// Publish uploads the packaged chart in the tarball so that it can be installed from the repository, updating
+	// the index of the repository if the server does not maintain it
+	Publish(tarball string) error
+}
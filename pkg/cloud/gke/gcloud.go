@@ -643,11 +643,10 @@ func (g *GCloud) FindServiceAccount(serviceAccount string, projectID string) boo
 	return true
 }
 
-// GetOrCreateServiceAccount retrieves or creates a GCP service account. It will return the path to the file where the service
-// account token is stored
-func (g *GCloud) GetOrCreateServiceAccount(serviceAccount string, projectID string, clusterConfigDir string, roles []string) (string, error) {
+// EnsureServiceAccount creates the GCP service account with the roles if it does not exist, without creating any keys
+func (g *GCloud) EnsureServiceAccount(serviceAccount string, projectID string, roles []string) error {
 	if projectID == "" {
-		return "", errors.New("cannot get/create a service account without a projectId")
+		return errors.New("cannot get/create a service account without a projectId")
 	}
 
 	found := g.FindServiceAccount(serviceAccount, projectID)
@@ -657,11 +656,11 @@ func (g *GCloud) GetOrCreateServiceAccount(serviceAccount string, projectID stri
 		// if it doesn't check to see if we have permissions to create (assign roles) to a service account
 		hasPerm, err := g.CheckPermission("resourcemanager.projects.setIamPolicy", projectID)
 		if err != nil {
-			return "", err
+			return err
 		}
 
 		if !hasPerm {
-			return "", errors.New("User does not have the required role 'resourcemanager.projects.setIamPolicy' to configure a service account")
+			return errors.New("User does not have the required role 'resourcemanager.projects.setIamPolicy' to configure a service account")
 		}
 
 		// create service
@@ -681,7 +680,7 @@ func (g *GCloud) GetOrCreateServiceAccount(serviceAccount string, projectID stri
 		}
 		_, err = cmd.RunWithoutRetry()
 		if err != nil {
-			return "", err
+			return err
 		}
 
 		// assign roles to service account
@@ -703,7 +702,7 @@ func (g *GCloud) GetOrCreateServiceAccount(serviceAccount string, projectID stri
 			}
 			_, err := cmd.Run()
 			if err != nil {
-				return "", err
+				return err
 			}
 		}
 
@@ -711,7 +710,22 @@ func (g *GCloud) GetOrCreateServiceAccount(serviceAccount string, projectID stri
 		log.Logger().Info("Service Account exists")
 	}
 
-	err := os.MkdirAll(clusterConfigDir, os.ModePerm)
+	return nil
+}
+
+// GetOrCreateServiceAccount retrieves or creates a GCP service account. It will return the path to the file where the service
+// account token is stored
+func (g *GCloud) GetOrCreateServiceAccount(serviceAccount string, projectID string, clusterConfigDir string, roles []string) (string, error) {
+	if projectID == "" {
+		return "", errors.New("cannot get/create a service account without a projectId")
+	}
+
+	err := g.EnsureServiceAccount(serviceAccount, projectID, roles)
+	if err != nil {
+		return "", err
+	}
+
+	err = os.MkdirAll(clusterConfigDir, os.ModePerm)
 	if err != nil {
 		return "", errors.Wrapf(err, "Failed to create directory: %s", clusterConfigDir)
 	}
@@ -739,6 +753,33 @@ func (g *GCloud) GetOrCreateServiceAccount(serviceAccount string, projectID stri
 	return keyPath, nil
 }
 
+// BindWorkloadIdentity allows the Kubernetes service account in the namespace to impersonate the GCP service account
+// using GKE Workload Identity
+func (g *GCloud) BindWorkloadIdentity(serviceAccount string, projectID string, namespace string, kubeServiceAccount string) error {
+	member := fmt.Sprintf("serviceAccount:%s.svc.id.goog[%s/%s]", projectID, namespace, kubeServiceAccount)
+	log.Logger().Infof("Allowing %s to use the service account %s", util.ColorInfo(member), util.ColorInfo(serviceAccount))
+	args := []string{"iam",
+		"service-accounts",
+		"add-iam-policy-binding",
+		ServiceAccountEmail(serviceAccount, projectID),
+		"--role",
+		"roles/iam.workloadIdentityUser",
+		"--member",
+		member,
+		"--project",
+		projectID}
+
+	cmd := util.Command{
+		Name: "gcloud",
+		Args: args,
+	}
+	_, err := cmd.Run()
+	if err != nil {
+		return errors.Wrapf(err, "binding %s to the service account %s", member, serviceAccount)
+	}
+	return nil
+}
+
 // ConfigureBucketRoles gives the given roles to the given service account
 func (g *GCloud) ConfigureBucketRoles(projectID string, serviceAccount string, bucketURL string, roles []string) error {
 	member := fmt.Sprintf("serviceAccount:%s@%s.iam.gserviceaccount.com", serviceAccount, projectID)
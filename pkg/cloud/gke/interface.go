@@ -16,6 +16,8 @@ type GClouder interface {
 	DeleteAllObjectsInBucket(bucketName string) error
 	DeleteBucket(bucketName string) error
 	FindServiceAccount(serviceAccount string, projectID string) bool
+	EnsureServiceAccount(serviceAccount string, projectID string, roles []string) error
+	BindWorkloadIdentity(serviceAccount string, projectID string, namespace string, kubeServiceAccount string) error
 	GetOrCreateServiceAccount(serviceAccount string, projectID string, clusterConfigDir string, roles []string) (string, error)
 	CreateServiceAccountKey(serviceAccount string, projectID string, keyPath string) error
 	GetServiceAccountKeys(serviceAccount string, projectID string) ([]string, error)
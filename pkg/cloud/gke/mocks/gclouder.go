@@ -35,6 +35,21 @@ func (mock *MockGClouder) AddBucketLabel(_param0 string, _param1 string) {
 	pegomock.GetGenericMockFrom(mock).Invoke("AddBucketLabel", params, []reflect.Type{})
 }
 
+func (mock *MockGClouder) BindWorkloadIdentity(_param0 string, _param1 string, _param2 string, _param3 string) error {
+	if mock == nil {
+		panic("mock must not be nil. Use myMock := NewMockGClouder().")
+	}
+	params := []pegomock.Param{_param0, _param1, _param2, _param3}
+	result := pegomock.GetGenericMockFrom(mock).Invoke("BindWorkloadIdentity", params, []reflect.Type{reflect.TypeOf((*error)(nil)).Elem()})
+	var ret0 error
+	if len(result) != 0 {
+		if result[0] != nil {
+			ret0 = result[0].(error)
+		}
+	}
+	return ret0
+}
+
 func (mock *MockGClouder) BucketExists(_param0 string, _param1 string) (bool, error) {
 	if mock == nil {
 		panic("mock must not be nil. Use myMock := NewMockGClouder().")
@@ -347,6 +362,21 @@ func (mock *MockGClouder) EnableAPIs(_param0 string, _param1 ...string) error {
 	return ret0
 }
 
+func (mock *MockGClouder) EnsureServiceAccount(_param0 string, _param1 string, _param2 []string) error {
+	if mock == nil {
+		panic("mock must not be nil. Use myMock := NewMockGClouder().")
+	}
+	params := []pegomock.Param{_param0, _param1, _param2}
+	result := pegomock.GetGenericMockFrom(mock).Invoke("EnsureServiceAccount", params, []reflect.Type{reflect.TypeOf((*error)(nil)).Elem()})
+	var ret0 error
+	if len(result) != 0 {
+		if result[0] != nil {
+			ret0 = result[0].(error)
+		}
+	}
+	return ret0
+}
+
 func (mock *MockGClouder) FindBucket(_param0 string) bool {
 	if mock == nil {
 		panic("mock must not be nil. Use myMock := NewMockGClouder().")
@@ -676,6 +706,45 @@ func (c *MockGClouder_AddBucketLabel_OngoingVerification) GetAllCapturedArgument
 	return
 }
 
+func (verifier *VerifierMockGClouder) BindWorkloadIdentity(_param0 string, _param1 string, _param2 string, _param3 string) *MockGClouder_BindWorkloadIdentity_OngoingVerification {
+	params := []pegomock.Param{_param0, _param1, _param2, _param3}
+	methodInvocations := pegomock.GetGenericMockFrom(verifier.mock).Verify(verifier.inOrderContext, verifier.invocationCountMatcher, "BindWorkloadIdentity", params, verifier.timeout)
+	return &MockGClouder_BindWorkloadIdentity_OngoingVerification{mock: verifier.mock, methodInvocations: methodInvocations}
+}
+
+type MockGClouder_BindWorkloadIdentity_OngoingVerification struct {
+	mock              *MockGClouder
+	methodInvocations []pegomock.MethodInvocation
+}
+
+func (c *MockGClouder_BindWorkloadIdentity_OngoingVerification) GetCapturedArguments() (string, string, string, string) {
+	_param0, _param1, _param2, _param3 := c.GetAllCapturedArguments()
+	return _param0[len(_param0)-1], _param1[len(_param1)-1], _param2[len(_param2)-1], _param3[len(_param3)-1]
+}
+
+func (c *MockGClouder_BindWorkloadIdentity_OngoingVerification) GetAllCapturedArguments() (_param0 []string, _param1 []string, _param2 []string, _param3 []string) {
+	params := pegomock.GetGenericMockFrom(c.mock).GetInvocationParams(c.methodInvocations)
+	if len(params) > 0 {
+		_param0 = make([]string, len(c.methodInvocations))
+		for u, param := range params[0] {
+			_param0[u] = param.(string)
+		}
+		_param1 = make([]string, len(c.methodInvocations))
+		for u, param := range params[1] {
+			_param1[u] = param.(string)
+		}
+		_param2 = make([]string, len(c.methodInvocations))
+		for u, param := range params[2] {
+			_param2[u] = param.(string)
+		}
+		_param3 = make([]string, len(c.methodInvocations))
+		for u, param := range params[3] {
+			_param3[u] = param.(string)
+		}
+	}
+	return
+}
+
 func (verifier *VerifierMockGClouder) BucketExists(_param0 string, _param1 string) *MockGClouder_BucketExists_OngoingVerification {
 	params := []pegomock.Param{_param0, _param1}
 	methodInvocations := pegomock.GetGenericMockFrom(verifier.mock).Verify(verifier.inOrderContext, verifier.invocationCountMatcher, "BucketExists", params, verifier.timeout)
@@ -1321,6 +1390,41 @@ func (c *MockGClouder_EnableAPIs_OngoingVerification) GetAllCapturedArguments()
 	return
 }
 
+func (verifier *VerifierMockGClouder) EnsureServiceAccount(_param0 string, _param1 string, _param2 []string) *MockGClouder_EnsureServiceAccount_OngoingVerification {
+	params := []pegomock.Param{_param0, _param1, _param2}
+	methodInvocations := pegomock.GetGenericMockFrom(verifier.mock).Verify(verifier.inOrderContext, verifier.invocationCountMatcher, "EnsureServiceAccount", params, verifier.timeout)
+	return &MockGClouder_EnsureServiceAccount_OngoingVerification{mock: verifier.mock, methodInvocations: methodInvocations}
+}
+
+type MockGClouder_EnsureServiceAccount_OngoingVerification struct {
+	mock              *MockGClouder
+	methodInvocations []pegomock.MethodInvocation
+}
+
+func (c *MockGClouder_EnsureServiceAccount_OngoingVerification) GetCapturedArguments() (string, string, []string) {
+	_param0, _param1, _param2 := c.GetAllCapturedArguments()
+	return _param0[len(_param0)-1], _param1[len(_param1)-1], _param2[len(_param2)-1]
+}
+
+func (c *MockGClouder_EnsureServiceAccount_OngoingVerification) GetAllCapturedArguments() (_param0 []string, _param1 []string, _param2 [][]string) {
+	params := pegomock.GetGenericMockFrom(c.mock).GetInvocationParams(c.methodInvocations)
+	if len(params) > 0 {
+		_param0 = make([]string, len(c.methodInvocations))
+		for u, param := range params[0] {
+			_param0[u] = param.(string)
+		}
+		_param1 = make([]string, len(c.methodInvocations))
+		for u, param := range params[1] {
+			_param1[u] = param.(string)
+		}
+		_param2 = make([][]string, len(c.methodInvocations))
+		for u, param := range params[2] {
+			_param2[u] = param.([]string)
+		}
+	}
+	return
+}
+
 func (verifier *VerifierMockGClouder) FindBucket(_param0 string) *MockGClouder_FindBucket_OngoingVerification {
 	params := []pegomock.Param{_param0}
 	methodInvocations := pegomock.GetGenericMockFrom(verifier.mock).Verify(verifier.inOrderContext, verifier.invocationCountMatcher, "FindBucket", params, verifier.timeout)
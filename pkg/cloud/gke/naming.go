@@ -27,6 +27,11 @@ func GcpServiceAccountSecretName(serviceName string) string {
 	return generateName(serviceName, "gcp-sa")
 }
 
+// ServiceAccountEmail returns the email address of a GCP service account in the project
+func ServiceAccountEmail(serviceAccount string, projectID string) string {
+	return fmt.Sprintf("%s@%s.iam.gserviceaccount.com", serviceAccount, projectID)
+}
+
 func generateName(serviceName string, name string) string {
 	return fmt.Sprintf("%s-%s", serviceName, name)
 }
@@ -0,0 +1,191 @@
+package identity
+
+import (
+	"fmt"
+
+	"github.com/jenkins-x/jx/v2/pkg/cloud"
+	"github.com/jenkins-x/jx/v2/pkg/cloud/gke"
+	"github.com/jenkins-x/jx/v2/pkg/config"
+	"github.com/jenkins-x/jx/v2/pkg/kube/naming"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// AnnotationGKEServiceAccount the annotation binding a service account to a GCP service account with GKE Workload
+	// Identity
+	AnnotationGKEServiceAccount = "iam.gke.io/gcp-service-account"
+	// AnnotationEKSRoleARN the annotation binding a service account to an IAM role with EKS IAM Roles for Service Accounts
+	AnnotationEKSRoleARN = "eks.amazonaws.com/role-arn"
+	// AnnotationAKSClientID the annotation binding a service account to a managed identity with AKS workload identity
+	AnnotationAKSClientID = "azure.workload.identity/client-id"
+	// LabelAKSUse the label which makes AKS inject the workload identity into the pods of a service account
+	LabelAKSUse = "azure.workload.identity/use"
+
+	// PipelineServiceAccount the service account pipelines run as
+	PipelineServiceAccount = "tekton-bot"
+	// VeleroServiceAccount the service account of Velero
+	VeleroServiceAccount = "velero"
+)
+
+// Binding binds a Kubernetes service account to the identity of the cloud provider it uses
+type Binding struct {
+	// Namespace the namespace of the service account
+	Namespace string
+	// ServiceAccount the name of the Kubernetes service account
+	ServiceAccount string
+	// CloudServiceAccount the name of the GCP service account on GKE
+	CloudServiceAccount string
+	// Roles the roles of the GCP service account on GKE
+	Roles []string
+	// Identity the GCP service account email, AWS IAM role ARN or Azure client ID. Empty if the identity is created
+	// outside of jx, such as the IAM roles eksctl creates for the service accounts
+	Identity string
+}
+
+// Enabled returns true if workload identity is enabled for a provider which supports it
+func Enabled(requirements *config.RequirementsConfig) bool {
+	if requirements == nil || !requirements.Cluster.WorkloadIdentity {
+		return false
+	}
+	switch requirements.Cluster.Provider {
+	case cloud.GKE, cloud.EKS, cloud.AKS:
+		return true
+	}
+	return false
+}
+
+// Bindings returns the service accounts in the namespace which use the workload identity of the cloud provider
+func Bindings(requirements *config.RequirementsConfig, ns string) []*Binding {
+	if !Enabled(requirements) {
+		return nil
+	}
+	clusterName := requirements.Cluster.ClusterName
+	answer := []*Binding{}
+	if requirements.Kaniko {
+		answer = append(answer, &Binding{
+			Namespace:           ns,
+			ServiceAccount:      PipelineServiceAccount,
+			CloudServiceAccount: naming.ToValidGCPServiceAccount(fmt.Sprintf("%s-ko", clusterName)),
+			Roles:               gke.KanikoServiceAccountRoles,
+		})
+	}
+	if vns := requirements.Velero.Namespace; vns != "" {
+		cloudServiceAccount := requirements.Velero.ServiceAccount
+		if cloudServiceAccount == "" {
+			cloudServiceAccount = naming.ToValidNameTruncated(fmt.Sprintf("%s-vo", clusterName), 30)
+		}
+		answer = append(answer, &Binding{
+			Namespace:           vns,
+			ServiceAccount:      VeleroServiceAccount,
+			CloudServiceAccount: cloudServiceAccount,
+			Roles:               gke.VeleroServiceAccountRoles,
+		})
+	}
+	for _, binding := range answer {
+		switch requirements.Cluster.Provider {
+		case cloud.GKE:
+			binding.Identity = gke.ServiceAccountEmail(binding.CloudServiceAccount, requirements.Cluster.ProjectID)
+		case cloud.AKS:
+			if requirements.Cluster.AzureConfig != nil {
+				binding.Identity = requirements.Cluster.AzureConfig.WorkloadIdentityClientID
+			}
+		}
+	}
+	return answer
+}
+
+// Annotate annotates the service account so that its pods use the identity, returning true if it was modified
+func Annotate(sa *corev1.ServiceAccount, provider string, identity string) bool {
+	key := annotationKey(provider)
+	if key == "" || identity == "" {
+		return false
+	}
+	modified := false
+	if sa.Annotations == nil {
+		sa.Annotations = map[string]string{}
+	}
+	if sa.Annotations[key] != identity {
+		sa.Annotations[key] = identity
+		modified = true
+	}
+	if provider == cloud.AKS {
+		if sa.Labels == nil {
+			sa.Labels = map[string]string{}
+		}
+		if sa.Labels[LabelAKSUse] != "true" {
+			sa.Labels[LabelAKSUse] = "true"
+			modified = true
+		}
+	}
+	return modified
+}
+
+// Configure creates or annotates the service account of the binding so that its pods use the identity
+func Configure(kubeClient kubernetes.Interface, provider string, binding *Binding) error {
+	serviceAccounts := kubeClient.CoreV1().ServiceAccounts(binding.Namespace)
+	sa, err := serviceAccounts.Get(binding.ServiceAccount, metav1.GetOptions{})
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			return errors.Wrapf(err, "getting the service account %s in namespace %s", binding.ServiceAccount, binding.Namespace)
+		}
+		sa = &corev1.ServiceAccount{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      binding.ServiceAccount,
+				Namespace: binding.Namespace,
+			},
+		}
+		Annotate(sa, provider, binding.Identity)
+		_, err = serviceAccounts.Create(sa)
+		if err != nil {
+			return errors.Wrapf(err, "creating the service account %s in namespace %s", binding.ServiceAccount, binding.Namespace)
+		}
+		return nil
+	}
+	if !Annotate(sa, provider, binding.Identity) {
+		return nil
+	}
+	_, err = serviceAccounts.Update(sa)
+	if err != nil {
+		return errors.Wrapf(err, "annotating the service account %s in namespace %s", binding.ServiceAccount, binding.Namespace)
+	}
+	return nil
+}
+
+// Verify verifies the service account of the binding is bound to its identity
+func Verify(kubeClient kubernetes.Interface, provider string, binding *Binding) error {
+	sa, err := kubeClient.CoreV1().ServiceAccounts(binding.Namespace).Get(binding.ServiceAccount, metav1.GetOptions{})
+	if err != nil {
+		return errors.Wrapf(err, "getting the service account %s in namespace %s", binding.ServiceAccount, binding.Namespace)
+	}
+	key := annotationKey(provider)
+	if key == "" {
+		return errors.Errorf("workload identity is not supported on provider %s", provider)
+	}
+	actual := sa.Annotations[key]
+	if actual == "" {
+		return errors.Errorf("the service account %s in namespace %s has no %s annotation", binding.ServiceAccount, binding.Namespace, key)
+	}
+	if binding.Identity != "" && actual != binding.Identity {
+		return errors.Errorf("the service account %s in namespace %s is bound to %s rather than %s", binding.ServiceAccount, binding.Namespace, actual, binding.Identity)
+	}
+	if provider == cloud.AKS && sa.Labels[LabelAKSUse] != "true" {
+		return errors.Errorf("the service account %s in namespace %s is missing the label %s=true", binding.ServiceAccount, binding.Namespace, LabelAKSUse)
+	}
+	return nil
+}
+
+func annotationKey(provider string) string {
+	switch provider {
+	case cloud.GKE:
+		return AnnotationGKEServiceAccount
+	case cloud.EKS:
+		return AnnotationEKSRoleARN
+	case cloud.AKS:
+		return AnnotationAKSClientID
+	}
+	return ""
+}
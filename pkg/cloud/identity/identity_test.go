@@ -0,0 +1,85 @@
+// +build unit
+
+package identity_test
+
+import (
+	"testing"
+
+	"github.com/jenkins-x/jx/v2/pkg/cloud"
+	"github.com/jenkins-x/jx/v2/pkg/cloud/identity"
+	"github.com/jenkins-x/jx/v2/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestBindings(t *testing.T) {
+	t.Parallel()
+
+	requirements := config.NewRequirementsConfig()
+	requirements.Cluster.Provider = cloud.GKE
+	requirements.Cluster.ClusterName = "mycluster"
+	requirements.Cluster.ProjectID = "myproject"
+	requirements.Kaniko = true
+	requirements.Velero.Namespace = "velero"
+	assert.Empty(t, identity.Bindings(requirements, "jx"), "workload identity is disabled by default")
+
+	requirements.Cluster.WorkloadIdentity = true
+	bindings := identity.Bindings(requirements, "jx")
+	require.Len(t, bindings, 2)
+	assert.Equal(t, "jx", bindings[0].Namespace)
+	assert.Equal(t, identity.PipelineServiceAccount, bindings[0].ServiceAccount)
+	assert.Equal(t, "mycluster-ko", bindings[0].CloudServiceAccount)
+	assert.Equal(t, "mycluster-ko@myproject.iam.gserviceaccount.com", bindings[0].Identity)
+	assert.Equal(t, "velero", bindings[1].Namespace)
+	assert.Equal(t, identity.VeleroServiceAccount, bindings[1].ServiceAccount)
+	assert.Equal(t, "mycluster-vo@myproject.iam.gserviceaccount.com", bindings[1].Identity)
+
+	requirements.Cluster.Provider = cloud.EKS
+	bindings = identity.Bindings(requirements, "jx")
+	require.Len(t, bindings, 2)
+	assert.Empty(t, bindings[0].Identity, "the IAM roles are created by eksctl on EKS")
+
+	requirements.Cluster.Provider = cloud.KUBERNETES
+	assert.Empty(t, identity.Bindings(requirements, "jx"))
+}
+
+func TestConfigureAndVerify(t *testing.T) {
+	t.Parallel()
+
+	binding := &identity.Binding{
+		Namespace:      "jx",
+		ServiceAccount: identity.PipelineServiceAccount,
+		Identity:       "00000000-0000-0000-0000-000000000000",
+	}
+	kubeClient := fake.NewSimpleClientset(&corev1.ServiceAccount{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      binding.ServiceAccount,
+			Namespace: binding.Namespace,
+		},
+	})
+
+	err := identity.Verify(kubeClient, cloud.AKS, binding)
+	assert.Error(t, err, "the service account is not annotated yet")
+
+	err = identity.Configure(kubeClient, cloud.AKS, binding)
+	require.NoError(t, err)
+	err = identity.Verify(kubeClient, cloud.AKS, binding)
+	require.NoError(t, err)
+
+	sa, err := kubeClient.CoreV1().ServiceAccounts(binding.Namespace).Get(binding.ServiceAccount, metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, binding.Identity, sa.Annotations[identity.AnnotationAKSClientID])
+	assert.Equal(t, "true", sa.Labels[identity.LabelAKSUse])
+	assert.False(t, identity.Annotate(sa, cloud.AKS, binding.Identity), "the service account is already annotated")
+
+	other := &identity.Binding{
+		Namespace:      binding.Namespace,
+		ServiceAccount: binding.ServiceAccount,
+		Identity:       "11111111-1111-1111-1111-111111111111",
+	}
+	err = identity.Verify(kubeClient, cloud.AKS, other)
+	assert.Error(t, err, "the service account is bound to a different identity")
+}
@@ -2,6 +2,7 @@ package add
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/jenkins-x/jx/v2/pkg/cmd/helper"
 
@@ -9,6 +10,7 @@ import (
 	"github.com/jenkins-x/jx/v2/pkg/cmd/templates"
 
 	"github.com/jenkins-x/jx/v2/pkg/apps"
+	"github.com/jenkins-x/jx/v2/pkg/environments"
 
 	"github.com/jenkins-x/jx/v2/pkg/io/secrets"
 
@@ -41,6 +43,8 @@ type AddAppOptions struct {
 	HelmUpdate  bool
 	AutoMerge   bool
 
+	UpgradePolicy string
+
 	// Used for testing
 	CloneDir string
 }
@@ -51,18 +55,43 @@ const (
 	optionSet        = "set"
 	optionAlias      = "alias"
 	optionNamespace  = "namespace"
+
+	optionUpgradePolicy = "upgrade-policy"
 )
 
 var (
 	add_app_long = templates.LongDesc(`
 		Adds an App to Jenkins X (an app is similar to an addon),
+
+		Apps can be added from the apps repository of your team, from any helm repository using --repository or
+		from a helm repository you have already added using a reference of the form repo/chart. Charts stored in OCI
+		registries can be added using a reference of the form oci://registry/repository/chart.
+
+		If the chart contains a values.schema.json you are asked questions generated from the schema. When using GitOps
+		the answers, any --values files and any --set values are written to the environment repository in a Pull Request.
+
+		When using GitOps the --upgrade-policy of the app controls how 'jx upgrade apps' upgrades it: 'auto' apps are
+		upgraded to the version in the version stream, 'manual' apps are upgraded in Pull Requests of their own which
+		are never merged automatically and 'pinned' apps are only upgraded when a version is specified.
 `)
 	add_app_example = templates.Examples(`
 		# Add an app
 		jx add app jx-app-jacoco
 
 		# Add an app from a local path
-		jx add app .`)
+		jx add app .
+
+		# Add an app from any helm repository
+		jx add app redis --repository https://charts.bitnami.com/bitnami
+
+		# Add an app from a helm repository you have already added
+		jx add app bitnami/redis --set cluster.enabled=false
+
+		# Add an app from a chart stored in an OCI registry
+		jx add app oci://ghcr.io/myorg/charts/myapp --version 1.0.0
+
+		# Add an app which is only upgraded when a version is specified
+		jx add app jx-app-jacoco --upgrade-policy pinned`)
 )
 
 // NewCmdAddApp creates a command object for the "create" command
@@ -108,15 +137,19 @@ func (o *AddAppOptions) addFlags(cmd *cobra.Command, defaultNamespace string) {
 		"Should we run helm update first to ensure we use the latest version (available when NOT using GitOps for your dev environment)")
 	cmd.Flags().StringVarP(&o.Namespace, optionNamespace, "n", "", "The Namespace to install into (available when NOT using GitOps for your dev environment)")
 	cmd.Flags().StringArrayVarP(&o.ValuesFiles, optionValues, "f", []string{}, "List of locations for values files, "+
-		"can be local files or URLs (available when NOT using GitOps for your dev environment)")
+		"can be local files or URLs (only local files are supported when using GitOps for your dev environment)")
 	cmd.Flags().StringArrayVarP(&o.SetValues, optionSet, "s", []string{},
-		"The chart set values (can specify multiple or separate values with commas: key1=val1,key2=val2) (available when NOT using GitOps for your dev environment)")
+		"The chart set values (can specify multiple or separate values with commas: key1=val1,key2=val2)")
 	cmd.Flags().BoolVarP(&o.AutoMerge, "auto-merge", "", false, "Automatically merge GitOps pull requests that pass CI")
+	cmd.Flags().StringVarP(&o.UpgradePolicy, optionUpgradePolicy, "", "",
+		fmt.Sprintf("How 'jx upgrade apps' upgrades the app, one of %s (available when using GitOps for your dev environment)",
+			strings.Join(environments.UpgradePolicyValues, ", ")))
 }
 
 // Run implements this command
 func (o *AddAppOptions) Run() error {
 	o.GitOps, o.DevEnv = o.GetDevEnv()
+	repoSpecified := o.Repo != ""
 	if o.Repo == "" {
 		o.Repo = o.DevEnv.Spec.TeamSettings.AppsRepository
 	}
@@ -160,13 +193,6 @@ func (o *AddAppOptions) Run() error {
 		if o.Namespace != "" && o.Namespace != kube.DefaultNamespace {
 			return util.InvalidOptionf(optionNamespace, o.Namespace, msg, optionNamespace)
 		}
-		if len(o.SetValues) > 0 {
-			return util.InvalidOptionf(optionSet, o.SetValues, msg, optionSet)
-		}
-		if len(o.ValuesFiles) > 1 {
-			return util.InvalidOptionf(optionValues, o.SetValues,
-				"no more than one --%s can be specified when using GitOps for your dev environment", optionValues)
-		}
 
 		gitProvider, _, err := o.CreateGitProviderForURLWithoutKind(o.DevEnv.Spec.Source.URL)
 		if err != nil {
@@ -174,8 +200,19 @@ func (o *AddAppOptions) Run() error {
 		}
 		installOpts.GitProvider = gitProvider
 		installOpts.Gitter = o.Git()
+
+		if o.UpgradePolicy != "" {
+			installOpts.UpgradePolicy, err = environments.ParseUpgradePolicy(o.UpgradePolicy)
+			if err != nil {
+				return util.InvalidOption(optionUpgradePolicy, o.UpgradePolicy, environments.UpgradePolicyValues)
+			}
+		}
 	}
 	if !o.GitOps {
+		if o.UpgradePolicy != "" {
+			return util.InvalidOptionf(optionUpgradePolicy, o.UpgradePolicy,
+				"unable to specify --%s when NOT using GitOps for your dev environment", optionUpgradePolicy)
+		}
 		if o.Alias != "" && o.ReleaseName == "" {
 			bin, noTiller, helmTemplate, err := o.TeamHelmBin()
 			if err != nil {
@@ -214,15 +251,36 @@ func (o *AddAppOptions) Run() error {
 		return o.Cmd.Help()
 	}
 
+	app := args[0]
+	if !repoSpecified {
+		chartName, repoURL, err := apps.ResolveChartRepository(installOpts.Helmer, app)
+		if err != nil {
+			return err
+		}
+		if repoURL != "" {
+			app = chartName
+			o.Repo = repoURL
+		}
+	}
 	if o.Repo == "" {
 		return fmt.Errorf("must specify a repository")
 	}
 
+	if o.GitOps && (len(o.SetValues) > 0 || len(o.ValuesFiles) > 1) {
+		// the values are written to the environment repository so merge them into a single values file
+		valuesFile, cleanup, err := apps.MergeValues(app, o.ValuesFiles, o.SetValues, o.Verbose)
+		defer cleanup()
+		if err != nil {
+			return errors.Wrapf(err, "merging the values for %s", app)
+		}
+		o.ValuesFiles = []string{valuesFile}
+		o.SetValues = nil
+	}
+
 	var version string
 	if o.Version != "" {
 		version = o.Version
 	}
-	app := args[0]
 	return installOpts.AddApp(app, version, o.Repo, o.Username, o.Password, o.ReleaseName, o.ValuesFiles, o.SetValues,
 		o.Alias, o.HelmUpdate)
 }
@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/jenkins-x/jx/v2/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// AuthPassphraseEnvVar the environment variable with the passphrase used to export and import the auth configs
+const AuthPassphraseEnvVar = "JX_AUTH_PASSPHRASE"
+
+// AuthOptions the options for the auth command
+type AuthOptions struct {
+	*opts.CommonOptions
+}
+
+var (
+	authLong = templates.LongDesc(`
+		Manages the local auth config files in ~/.jx which store the credentials used by jx.
+
+		The files are encrypted at rest with a key stored in the keychain of the platform (osxkeychain, wincred or
+		secretservice) when it is available. Set $JX_AUTH_KEYCHAIN to use another credential helper or 'none' to disable
+		the encryption. Files stored in plain text are encrypted the next time they are saved. If the keychain fails the
+		files are saved in plain text.
+
+		As the key does not leave the machine use 'jx auth export' and 'jx auth import' to move the credentials to another
+		machine.
+`)
+
+	authExample = templates.Examples(`
+		# exports the credentials encrypted with a passphrase
+		jx auth export -o jx-auth.yaml
+
+		# imports the credentials on another machine
+		jx auth import jx-auth.yaml
+`)
+)
+
+// NewCmdAuth creates the command
+func NewCmdAuth(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &AuthOptions{
+		CommonOptions: commonOpts,
+	}
+	cmd := &cobra.Command{
+		Use:     "auth",
+		Short:   "Manages the encrypted local auth config files",
+		Long:    authLong,
+		Example: authExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+	cmd.AddCommand(NewCmdAuthExport(commonOpts))
+	cmd.AddCommand(NewCmdAuthImport(commonOpts))
+	return cmd
+}
+
+// Run implements the command
+func (o *AuthOptions) Run() error {
+	return o.Cmd.Help()
+}
+
+// authPassphrase returns the passphrase the auth configs are exported with from the environment or by prompting for it
+func authPassphrase(o *opts.CommonOptions, confirm bool) (string, error) {
+	if passphrase := os.Getenv(AuthPassphraseEnvVar); passphrase != "" {
+		return passphrase, nil
+	}
+	if o.BatchMode {
+		return "", errors.Errorf("no passphrase specified in $%s", AuthPassphraseEnvVar)
+	}
+	handles := o.GetIOFileHandles()
+	passphrase, err := util.PickPassword("Passphrase:", "The passphrase the credentials are encrypted with", handles)
+	if err != nil {
+		return "", err
+	}
+	if confirm {
+		again, err := util.PickPassword("Confirm passphrase:", "Enter the passphrase again", handles)
+		if err != nil {
+			return "", err
+		}
+		if again != passphrase {
+			return "", errors.New("the passphrases do not match")
+		}
+	}
+	return passphrase, nil
+}
@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/jenkins-x/jx/v2/pkg/auth"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/v2/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// AuthExportOptions the options for the auth export command
+type AuthExportOptions struct {
+	*opts.CommonOptions
+
+	OutputFile string
+}
+
+var (
+	authExportLong = templates.LongDesc(`
+		Exports the local auth config files, including the secrets stored by credential helpers, to a single file
+		encrypted with a passphrase so they can be imported on another machine with 'jx auth import'.
+
+		The passphrase is read from $JX_AUTH_PASSPHRASE or prompted for.
+`)
+
+	authExportExample = templates.Examples(`
+		# exports the credentials
+		jx auth export -o jx-auth.yaml
+`)
+)
+
+// NewCmdAuthExport creates the command
+func NewCmdAuthExport(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &AuthExportOptions{
+		CommonOptions: commonOpts,
+	}
+	cmd := &cobra.Command{
+		Use:     "export",
+		Short:   "Exports the local auth config files encrypted with a passphrase",
+		Long:    authExportLong,
+		Example: authExportExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+	cmd.Flags().StringVarP(&options.OutputFile, "output", "o", "jx-auth.yaml", "The file the credentials are exported to")
+	return cmd
+}
+
+// Run implements the command
+func (o *AuthExportOptions) Run() error {
+	dir, err := util.ConfigDir()
+	if err != nil {
+		return err
+	}
+	configs := map[string]*auth.AuthConfig{}
+	for _, fileName := range auth.LocalAuthConfigFiles {
+		exists, err := util.FileExists(filepath.Join(dir, fileName))
+		if err != nil {
+			return errors.Wrapf(err, "checking if %s exists", fileName)
+		}
+		if !exists {
+			continue
+		}
+		authConfigSvc, err := auth.NewFileAuthConfigService(fileName, "")
+		if err != nil {
+			return errors.Wrapf(err, "creating the auth config service from file %s", fileName)
+		}
+		config, err := authConfigSvc.LoadConfig()
+		if err != nil {
+			return errors.Wrapf(err, "loading the auth config from file %s", fileName)
+		}
+		configs[fileName] = config
+	}
+	if len(configs) == 0 {
+		return errors.Errorf("no auth config files found in %s", dir)
+	}
+
+	passphrase, err := authPassphrase(o.CommonOptions, true)
+	if err != nil {
+		return err
+	}
+	data, err := auth.ExportConfigs(configs, passphrase)
+	if err != nil {
+		return err
+	}
+	err = ioutil.WriteFile(o.OutputFile, data, 0600)
+	if err != nil {
+		return errors.Wrapf(err, "writing %s", o.OutputFile)
+	}
+	log.Logger().Infof("Exported %d auth config files to %s", len(configs), util.ColorInfo(o.OutputFile))
+	return nil
+}
@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+
+	"github.com/jenkins-x/jx/v2/pkg/auth"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/v2/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// AuthImportOptions the options for the auth import command
+type AuthImportOptions struct {
+	*opts.CommonOptions
+
+	Overwrite bool
+}
+
+var (
+	authImportLong = templates.LongDesc(`
+		Imports the auth config files exported with 'jx auth export', encrypting them with the key from the keychain of
+		this machine.
+
+		The passphrase is read from $JX_AUTH_PASSPHRASE or prompted for.
+`)
+
+	authImportExample = templates.Examples(`
+		# imports the credentials
+		jx auth import jx-auth.yaml
+
+		# imports the credentials replacing the existing auth config files
+		jx auth import jx-auth.yaml --overwrite
+`)
+)
+
+// NewCmdAuthImport creates the command
+func NewCmdAuthImport(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &AuthImportOptions{
+		CommonOptions: commonOpts,
+	}
+	cmd := &cobra.Command{
+		Use:     "import <file>",
+		Short:   "Imports the auth config files exported with 'jx auth export'",
+		Long:    authImportLong,
+		Example: authImportExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+	cmd.Flags().BoolVarP(&options.Overwrite, "overwrite", "", false, "Replaces the existing auth config files")
+	return cmd
+}
+
+// Run implements the command
+func (o *AuthImportOptions) Run() error {
+	if len(o.Args) != 1 {
+		return errors.New("expected the file to import")
+	}
+	data, err := ioutil.ReadFile(o.Args[0])
+	if err != nil {
+		return errors.Wrapf(err, "reading %s", o.Args[0])
+	}
+	passphrase, err := authPassphrase(o.CommonOptions, false)
+	if err != nil {
+		return err
+	}
+	configs, err := auth.ImportConfigs(data, passphrase)
+	if err != nil {
+		return err
+	}
+
+	dir, err := util.ConfigDir()
+	if err != nil {
+		return err
+	}
+	fileNames := []string{}
+	for fileName := range configs {
+		exists, err := util.FileExists(filepath.Join(dir, fileName))
+		if err != nil {
+			return errors.Wrapf(err, "checking if %s exists", fileName)
+		}
+		if exists && !o.Overwrite {
+			return errors.Errorf("the auth config file %s already exists, use --overwrite to replace it", filepath.Join(dir, fileName))
+		}
+		fileNames = append(fileNames, fileName)
+	}
+	sort.Strings(fileNames)
+	for _, fileName := range fileNames {
+		authConfigSvc, err := auth.NewFileAuthConfigService(fileName, "")
+		if err != nil {
+			return errors.Wrapf(err, "creating the auth config service from file %s", fileName)
+		}
+		authConfigSvc.SetConfig(configs[fileName])
+		err = authConfigSvc.SaveConfig()
+		if err != nil {
+			return errors.Wrapf(err, "saving the auth config file %s", fileName)
+		}
+		log.Logger().Infof("Imported %s", util.ColorInfo(filepath.Join(dir, fileName)))
+	}
+	return nil
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/google/uuid"
 
@@ -48,6 +49,9 @@ type BootOptions struct {
 	// RequirementsFile provided by the user to override the default requirements file from repository
 	RequirementsFile string
 
+	// RequirementsProfile the comma separated requirements profiles to merge over the requirements file
+	RequirementsProfile string
+
 	AttemptRestore bool
 
 	// UpgradeGit if we want to automatically upgrade this boot clone if there have been changes since the current clone
@@ -103,6 +107,7 @@ func NewCmdBoot(commonOpts *opts.CommonOptions) *cobra.Command {
 	cmd.Flags().StringVarP(&options.EndStep, "end-step", "e", "", "the step in the pipeline to end at")
 	cmd.Flags().StringVarP(&options.HelmLogLevel, "helm-log", "v", "", "sets the helm logging level from 0 to 9. Passed into the helm CLI via the '-v' argument. Useful to diagnose helm related issues")
 	cmd.Flags().StringVarP(&options.RequirementsFile, "requirements", "r", "", "requirements file which will overwrite the default requirements file")
+	cmd.Flags().StringVarP(&options.RequirementsProfile, "profile", "", "", fmt.Sprintf("comma separated requirements profiles whose jx-requirements-<profile>.yml files are merged over the requirements file. Defaults to $%s", config.RequirementsProfileEnvVar))
 	cmd.Flags().BoolVarP(&options.AttemptRestore, "attempt-restore", "a", false, "attempt to boot from an existing dev environment repository")
 	cmd.Flags().BoolVarP(&options.NoUpgradeGit, "no-update-git", "", false, "disables any attempt to update the local git clone if its old")
 
@@ -153,7 +158,8 @@ func (o *BootOptions) Run() error {
 		o.Dir = cloneDir
 	}
 
-	requirements, requirementsFile, err := config.LoadRequirementsConfig(o.Dir, config.DefaultFailOnValidationError)
+	requirementsOptions := o.requirementsOptions()
+	requirements, requirementsFile, err := config.LoadRequirementsConfigWithOptions(o.Dir, config.DefaultFailOnValidationError, requirementsOptions)
 	if err != nil {
 		return errors.Wrapf(err, "unable to load %s", config.RequirementsConfigFileName)
 	}
@@ -230,6 +236,9 @@ func (o *BootOptions) Run() error {
 	if o.HelmLogLevel != "" {
 		so.AdditionalEnvVars["JX_HELM_VERBOSE"] = o.HelmLogLevel
 	}
+	if len(requirementsOptions.Profiles) > 0 {
+		so.AdditionalEnvVars[config.RequirementsProfileEnvVar] = strings.Join(requirementsOptions.Profiles, ",")
+	}
 
 	// Set the namespace in the pipeline
 	so.CommonOptions.SetDevNamespace(requirements.Cluster.Namespace)
@@ -329,7 +338,11 @@ func (o *BootOptions) createBootClone(bootConfigGitURL string, bootConfigGitRef
 		return "", errors.Wrapf(err, "failed to create directory: %s", cloneDir)
 	}
 
-	err = o.Git().Clone(bootConfigGitURL, cloneDir)
+	cloneURL, err := o.GitCloneURL(bootConfigGitURL)
+	if err != nil {
+		return "", err
+	}
+	err = o.Git().CloneWithOptions(cloneURL, cloneDir, gits.PartialCloneOptions())
 	if err != nil {
 		return "", errors.Wrapf(err, "failed to clone git URL %s to directory: %s", bootConfigGitURL, cloneDir)
 	}
@@ -421,7 +434,11 @@ func (o *BootOptions) cloneDevEnvironment(gitURL string) (bool, string, error) {
 		return false, "", errors.Wrapf(err, "failed to create directory: %s", cloneDir)
 	}
 
-	err = o.Git().Clone(gitURL, cloneDir)
+	cloneURL, err := o.GitCloneURL(gitURL)
+	if err != nil {
+		return false, "", err
+	}
+	err = o.Git().CloneWithOptions(cloneURL, cloneDir, gits.PartialCloneOptions())
 	if err != nil {
 		log.Logger().Infof("failed to clone git URL %s to directory: %s", gitURL, cloneDir)
 		rmErr := os.RemoveAll(cloneDir)
@@ -497,14 +514,15 @@ func existingBootClone(dir string) (bool, error) {
 }
 
 func (o *BootOptions) overrideRequirements(defaultBootConfigURL string) error {
-	requirements, requirementsFile, err := config.LoadRequirementsConfig(o.Dir, config.DefaultFailOnValidationError)
+	requirementsOptions := o.requirementsOptions()
+	requirements, requirementsFile, err := config.LoadRequirementsConfigWithOptions(o.Dir, config.DefaultFailOnValidationError, requirementsOptions)
 	if err != nil {
 		return errors.Wrapf(err, "loading requirements from dir %q", o.Dir)
 	}
 
 	// overwrite the default requirements with provided requirements
 	if o.RequirementsFile != "" {
-		providedRequirements, err := config.LoadRequirementsConfigFile(o.RequirementsFile, config.DefaultFailOnValidationError)
+		providedRequirements, err := config.LoadRequirementsConfigFileWithOptions(o.RequirementsFile, config.DefaultFailOnValidationError, config.RequirementsOptions{})
 		if err != nil {
 			return errors.Wrapf(err, "loading requirements from file %q", o.RequirementsFile)
 		}
@@ -516,13 +534,23 @@ func (o *BootOptions) overrideRequirements(defaultBootConfigURL string) error {
 		requirements.BootConfigURL = defaultBootConfigURL
 	}
 
-	if err := requirements.SaveConfig(requirementsFile); err != nil {
+	if err := requirements.SaveConfigWithOptions(requirementsFile, requirementsOptions); err != nil {
 		return errors.Wrapf(err, "saving the requirements into file %q", requirementsFile)
 	}
 
 	return nil
 }
 
+// requirementsOptions returns the options to load the requirements with the profiles of the --profile flag which
+// defaults to the profiles of the $JX_REQUIREMENTS_PROFILE environment variable
+func (o *BootOptions) requirementsOptions() config.RequirementsOptions {
+	options := o.RequirementsOptions()
+	if o.RequirementsProfile != "" {
+		options.Profiles = config.ParseRequirementsProfiles(o.RequirementsProfile)
+	}
+	return options
+}
+
 func (o *BootOptions) determineGitRef(resolver *versionstream.VersionResolver, requirements *config.RequirementsConfig, gitURL string) (string, error) {
 	// If the GitRef is not overridden and is set to it's default value then look up the version number
 	log.Logger().Infof("Attempting to resolve version for boot config %s from %s", util.ColorInfo(gitURL), util.ColorInfo(requirements.VersionStream.URL))
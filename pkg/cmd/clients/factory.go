@@ -813,7 +813,7 @@ func (f *factory) CreateHelm(verbose bool,
 	} else {
 		h = helmCLI
 	}
-	if noTiller && !helmTemplate && helmBinary != "helm3" {
+	if noTiller && !helmTemplate && !helmCLI.IsHelm3() {
 		h.SetHost(helm.GetTillerAddress())
 		helm.StartLocalTillerIfNotRunning() //nolint:errcheck
 	}
@@ -55,6 +55,7 @@ import (
 	"github.com/jenkins-x/jx/v2/pkg/cmd/add"
 	"github.com/jenkins-x/jx/v2/pkg/cmd/namespace"
 	"github.com/jenkins-x/jx/v2/pkg/cmd/promote"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/rollback"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
@@ -127,6 +128,7 @@ func NewJXCommand(f clients.Factory, in terminal.FileReader, out terminal.FileWr
 	environmentsCommands := []*cobra.Command{
 		preview.NewCmdPreview(commonOpts),
 		promote.NewCmdPromote(commonOpts),
+		rollback.NewCmdRollback(commonOpts),
 	}
 	environmentsCommands = append(environmentsCommands, findCommands("environment", createCommands, deleteCommands, editCommands, getCommands)...)
 
@@ -155,6 +157,8 @@ func NewJXCommand(f clients.Factory, in terminal.FileReader, out terminal.FileWr
 				compliance.NewCompliance(commonOpts),
 				NewCmdCompletion(commonOpts),
 				NewCmdContext(commonOpts),
+				NewCmdAuth(commonOpts),
+				NewCmdLogin(commonOpts),
 				NewCmdEnvironment(commonOpts),
 				NewCmdTeam(commonOpts),
 				namespace.NewCmdNamespace(commonOpts),
@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"fmt"
+	"os"
 	"sort"
 	"strings"
 
@@ -22,11 +23,17 @@ type ContextOptions struct {
 	*opts.CommonOptions
 
 	Filter string
+	Shell  bool
 }
 
 var (
 	context_long = templates.LongDesc(`
-		Displays or changes the current Kubernetes context (cluster).`)
+		Displays or changes the current Kubernetes context (cluster).
+
+		The current context is shared by all the terminals using the same kube config file, so changing it in one
+		terminal changes it in the others. Use '--shell' to change the context of the current shell only: the output is
+		evaluated by the shell to put an overlay kube config file ahead of the shared ones in KUBECONFIG. The context and
+		namespace changes made by 'jx context' and 'jx namespace' in that shell then only change the overlay.`)
 	context_example = templates.Examples(`
 		# to select the context to switch to
 		jx context
@@ -35,7 +42,10 @@ var (
 		jx ctx
 
 		# view the current context
-		jx ctx -b`)
+		jx ctx -b
+
+		# use the prod context in the current shell only leaving the other terminals using their context
+		eval "$(jx ctx --shell prod)"`)
 )
 
 func NewCmdContext(commonOpts *opts.CommonOptions) *cobra.Command {
@@ -56,6 +66,7 @@ func NewCmdContext(commonOpts *opts.CommonOptions) *cobra.Command {
 		},
 	}
 	cmd.Flags().StringVarP(&options.Filter, "filter", "f", "", "Filter the list of contexts to switch between using the given text")
+	cmd.Flags().BoolVarP(&options.Shell, "shell", "", false, "Changes the context of the current shell only by outputting the command the shell should evaluate")
 	return cmd
 }
 
@@ -97,6 +108,21 @@ func (o *ContextOptions) Run() error {
 		ctxName = pick
 	}
 	info := util.ColorInfo
+	shellConfig := kube.ShellKubeConfig()
+	if o.Shell && shellConfig == "" {
+		if ctxName == "" {
+			ctxName = config.CurrentContext
+		}
+		overlay, err := kube.CreateShellKubeConfig(config, ctxName)
+		if err != nil {
+			return err
+		}
+		ctx := config.Contexts[ctxName]
+		fmt.Fprintf(o.Err, "Now using namespace '%s' from context named '%s' on server '%s' in this shell.\n",
+			info(ctx.Namespace), info(ctxName), info(kube.Server(config, ctx)))
+		fmt.Fprintln(o.Out, kube.ShellExportCommand(overlay))
+		return nil
+	}
 	if ctxName != "" && ctxName != config.CurrentContext {
 		ctx := config.Contexts[ctxName]
 		if ctx == nil {
@@ -104,7 +130,11 @@ func (o *ContextOptions) Run() error {
 		}
 		newConfig := *config
 		newConfig.CurrentContext = ctxName
-		err = clientcmd.ModifyConfig(po, newConfig, false)
+		if shellConfig != "" {
+			err = kube.WriteShellKubeConfig(shellConfig, config, ctxName)
+		} else {
+			err = clientcmd.ModifyConfig(po, newConfig, false)
+		}
 		if err != nil {
 			return fmt.Errorf("Failed to update the kube config %s", err)
 		}
@@ -120,7 +150,12 @@ func (o *ContextOptions) Run() error {
 }
 
 func (o *ContextOptions) PickContext(names []string, defaultValue string) (string, error) {
-	surveyOpts := survey.WithStdio(o.In, o.Out, o.Err)
+	out := o.Out
+	if o.Shell {
+		// the output of the command is evaluated by the shell so ask on the terminal instead
+		out = os.Stderr
+	}
+	surveyOpts := survey.WithStdio(o.In, out, o.Err)
 	if len(names) == 0 {
 		return "", nil
 	}
@@ -49,6 +49,7 @@ func NewCmdController(commonOpts *opts.CommonOptions) *cobra.Command {
 	cmd.AddCommand(pipeline.NewCmdControllerPipelineRunner(commonOpts))
 	cmd.AddCommand(NewCmdControllerRole(commonOpts))
 	cmd.AddCommand(NewCmdControllerTeam(commonOpts))
+	cmd.AddCommand(NewCmdControllerTokenExchange(commonOpts))
 	cmd.AddCommand(NewCmdControllerCommitStatus(commonOpts))
 	return cmd
 }
@@ -18,6 +18,7 @@ import (
 	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
 	"github.com/jenkins-x/jx/v2/pkg/gits"
 	"github.com/jenkins-x/jx/v2/pkg/logs"
+	"github.com/jenkins-x/jx/v2/pkg/notify"
 	"k8s.io/apimachinery/pkg/fields"
 
 	"github.com/jenkins-x/jx/v2/pkg/collector"
@@ -338,6 +339,7 @@ func (o *ControllerBuildOptions) onPipelinePod(obj interface{}, kubeClient kuber
 							log.Logger().Warnf("Failed to %s PipelineActivities for build %s: %s", operation, pri.Name, err)
 							return err
 						}
+						previousStatus := a.Spec.Status
 						if o.updatePipelineActivityForRun(kubeClient, ns, a, pri, pod) {
 							log.Logger().Debugf("updating PipelineActivity %s from updatePipelineActivityForRun()", a.Name)
 							_, err := activities.PatchUpdate(a)
@@ -346,6 +348,7 @@ func (o *ControllerBuildOptions) onPipelinePod(obj interface{}, kubeClient kuber
 								name = a.Name
 								return err
 							}
+							o.notifyPipelineEvent(kubeClient, ns, a, previousStatus)
 						}
 						return nil
 					})
@@ -791,6 +794,24 @@ func (o *ControllerBuildOptions) updatePipelineActivityForRun(kubeClient kuberne
 	return originYaml != newYaml
 }
 
+// notifyPipelineEvent notifies the targets of the pipeline notification rules of the team settings when the status of
+// the activity changes so that the pipeline started, succeeded or failed
+func (o *ControllerBuildOptions) notifyPipelineEvent(kubeClient kubernetes.Interface, ns string, activity *v1.PipelineActivity, previousStatus v1.ActivityStatusType) {
+	if o.DryRun {
+		return
+	}
+	event := notify.EventForStatusChange(activity, previousStatus)
+	if event == nil {
+		return
+	}
+	devEnv := o.EnvironmentCache.Item(kube.LabelValueDevEnvironment)
+	if devEnv == nil || len(devEnv.Spec.TeamSettings.PipelineNotifications) == 0 {
+		return
+	}
+	// notifications are sent in the background so that slow targets do not delay updating the activities
+	go notify.NotifyPipelineEvent(kubeClient, ns, devEnv.Spec.TeamSettings.PipelineNotifications, event)
+}
+
 func updateForStage(si *tekton.StageInfo, a *v1.PipelineActivity) {
 	_, stage, _ := kube.GetOrCreateStage(a, si.GetStageNameIncludingParents())
 	containersTerminated := false
@@ -1395,13 +1416,25 @@ func toScmStatusDescriptionRunningStages(activity *v1.PipelineActivity) reportSt
 	if len(stagesByStatus[v1.ActivityStatusTypeRunning]) > 0 {
 		info.runningStages = strings.Join(stagesByStatus[v1.ActivityStatusTypeRunning], ",")
 		info.description = fmt.Sprintf("Pipeline running stage(s): %s", strings.Join(stagesByStatus[v1.ActivityStatusTypeRunning], ", "))
-		if len(info.description) > 63 {
-			info.description = info.description[:59] + "..."
-		}
+	} else if info.scmStatus == "failure" && len(stagesByStatus[v1.ActivityStatusTypeFailed]) > 0 {
+		// parallel stages such as the combinations of a matrix are reported in the single status of the pipeline
+		failed := stagesByStatus[v1.ActivityStatusTypeFailed]
+		info.description = fmt.Sprintf("Pipeline failed %d of %d stage(s): %s", len(failed), countStages(stagesByStatus), strings.Join(failed, ", "))
+	}
+	if len(info.description) > 63 {
+		info.description = info.description[:59] + "..."
 	}
 	return info
 }
 
+func countStages(stagesByStatus map[v1.ActivityStatusType][]string) int {
+	count := 0
+	for _, stages := range stagesByStatus {
+		count += len(stages)
+	}
+	return count
+}
+
 // createStepDescription uses the spec of the container to return a description
 func createStepDescription(containerName string, pod *corev1.Pod) string {
 	containers, _, isInit := kube.GetContainersWithStatusAndIsInit(pod)
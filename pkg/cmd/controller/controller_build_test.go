@@ -162,6 +162,32 @@ func TestCreateReportTargetURL(t *testing.T) {
 	assert.Equal(t, "https://myconsole.acme.com/teams/jx/projects/jstrachan/myapp/PR-5/3", actual, "created git report URL for params %#v", params)
 }
 
+func TestToScmStatusDescriptionWithFailedStages(t *testing.T) {
+	stage := func(name string, status v1.ActivityStatusType) v1.PipelineActivityStep {
+		return v1.PipelineActivityStep{
+			Kind: v1.ActivityStepKindTypeStage,
+			Stage: &v1.StageActivityStep{
+				CoreActivityStep: v1.CoreActivityStep{
+					Name:   name,
+					Status: status,
+				},
+			},
+		}
+	}
+	activity := &v1.PipelineActivity{
+		Spec: v1.PipelineActivitySpec{
+			Status: v1.ActivityStatusTypeFailed,
+			Steps: []v1.PipelineActivityStep{
+				stage("test-1.12", v1.ActivityStatusTypeSucceeded),
+				stage("test-1.13", v1.ActivityStatusTypeFailed),
+			},
+		},
+	}
+	info := toScmStatusDescriptionRunningStages(activity)
+	assert.Equal(t, "failure", info.scmStatus)
+	assert.Equal(t, "Pipeline failed 1 of 2 stage(s): test-1.13", info.description)
+}
+
 func TestUpdateForStagePreTekton051(t *testing.T) {
 	pod := tekton_helpers_test.AssertLoadSinglePod(t, path.Join("test_data", "controller_build", "update_stage_info_pre_tekton_0.5.1"))
 	si := &tekton.StageInfo{
@@ -0,0 +1,115 @@
+package controller
+
+import (
+	"github.com/jenkins-x/jx/v2/pkg/cloud"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/v2/pkg/config"
+	"github.com/jenkins-x/jx/v2/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/tokenexchange"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// ControllerTokenExchangeOptions holds the options for the token exchange service
+type ControllerTokenExchangeOptions struct {
+	*opts.CommonOptions
+	BindAddress string
+	Port        int
+}
+
+var (
+	controllerTokenExchangeLong = templates.LongDesc(`
+		Runs the token exchange which mints short lived git and container registry tokens for the pipelines and pods of
+		preview environments, so the long lived pipeline secrets do not have to be copied into every preview namespace.
+
+		Pods authenticate with the token of their service account, which must be in the namespace of a preview
+		Environment. Git tokens are GitHub App installation tokens which can only access the repository of the preview.
+		Registry tokens are minted with the cloud identity of the token exchange on GKE and EKS.
+`)
+
+	controllerTokenExchangeExample = templates.Examples(`
+		jx controller token-exchange
+`)
+)
+
+// NewCmdControllerTokenExchange creates the command
+func NewCmdControllerTokenExchange(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := ControllerTokenExchangeOptions{
+		CommonOptions: commonOpts,
+	}
+	cmd := &cobra.Command{
+		Use:     "token-exchange",
+		Short:   "Runs the service which mints short lived tokens for preview environments",
+		Long:    controllerTokenExchangeLong,
+		Example: controllerTokenExchangeExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+	cmd.Flags().IntVarP(&options.Port, optionPort, "", 8080, "The TCP port to listen on.")
+	cmd.Flags().StringVarP(&options.BindAddress, optionBind, "", "",
+		"The interface address to bind to (by default, will listen on all interfaces/addresses).")
+	return cmd
+}
+
+// Run starts the token exchange
+func (o *ControllerTokenExchangeOptions) Run() error {
+	kubeClient, ns, err := o.KubeClientAndDevNamespace()
+	if err != nil {
+		return err
+	}
+	jxClient, _, err := o.JXClient()
+	if err != nil {
+		return err
+	}
+
+	minters := map[string]tokenexchange.Minter{}
+	authConfigSvc, err := o.GitAuthConfigService()
+	if err != nil {
+		return errors.Wrap(err, "creating the git auth config service")
+	}
+	minters[tokenexchange.KindGit] = &tokenexchange.GitHubAppMinter{
+		AuthConfig: authConfigSvc.Config(),
+	}
+
+	registryMinter, err := o.registryMinter()
+	if err != nil {
+		return err
+	}
+	if registryMinter != nil {
+		minters[tokenexchange.KindRegistry] = registryMinter
+	}
+
+	server := tokenexchange.NewHTTPTokenExchangeServer(o.BindAddress, o.Port, kubeClient, jxClient, ns, minters)
+	return server.Start()
+}
+
+// registryMinter returns the minter of tokens for the container registry of the cloud provider, if it is supported
+func (o *ControllerTokenExchangeOptions) registryMinter() (tokenexchange.Minter, error) {
+	settings, err := o.TeamSettings()
+	if err != nil {
+		return nil, errors.Wrap(err, "loading the team settings")
+	}
+	requirements, err := config.GetRequirementsConfigFromTeamSettings(settings)
+	if err != nil {
+		return nil, errors.Wrap(err, "loading the requirements from the team settings")
+	}
+	if requirements == nil {
+		log.Logger().Warnf("not minting registry tokens as there are no requirements in the team settings")
+		return nil, nil
+	}
+	registry := o.GetDockerRegistry(nil)
+	switch requirements.Cluster.Provider {
+	case cloud.GKE:
+		return &tokenexchange.GCRMinter{Registry: registry}, nil
+	case cloud.EKS:
+		return &tokenexchange.ECRMinter{Registry: registry, Region: requirements.Cluster.Region}, nil
+	}
+	log.Logger().Warnf("not minting registry tokens as they are not supported on provider %s", requirements.Cluster.Provider)
+	return nil, nil
+}
@@ -1,10 +1,14 @@
 package create
 
 import (
+	"os"
+
 	"github.com/jenkins-x/jx/v2/pkg/cmd/create/options"
 	"github.com/jenkins-x/jx/v2/pkg/cmd/create/vault"
 	"github.com/jenkins-x/jx/v2/pkg/cmd/helper"
 	"github.com/jenkins-x/jx/v2/pkg/cmd/importcmd"
+	"github.com/jenkins-x/jx/v2/pkg/generators"
+	"github.com/jenkins-x/jx/v2/pkg/gits"
 	"github.com/spf13/cobra"
 
 	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
@@ -68,6 +72,7 @@ func NewCmdCreate(commonOpts *opts.CommonOptions) *cobra.Command {
 	cmd.AddCommand(NewCmdCreateGit(commonOpts))
 	cmd.AddCommand(NewCmdCreateIssue(commonOpts))
 	cmd.AddCommand(NewCmdCreateJenkins(commonOpts))
+	cmd.AddCommand(NewCmdCreateMirror(commonOpts))
 	cmd.AddCommand(NewCmdCreatePostPreviewJob(commonOpts))
 	cmd.AddCommand(NewCmdCreateProject(commonOpts))
 	cmd.AddCommand(NewCmdCreatePullRequest(commonOpts))
@@ -98,6 +103,53 @@ func (o *CreateProjectOptions) ImportCreatedProject(outDir string) error {
 	return importOptions.Run()
 }
 
+// CreateFromGenerator creates a project with the generator in the output directory, then imports it using the
+// pipeline defaults of the generator unless the build pack or deploy kind are specified
+func (o *CreateProjectOptions) CreateFromGenerator(generator generators.Generator, values map[string]string) error {
+	dir := o.OutDir
+	if dir == "" {
+		var err error
+		dir, err = os.Getwd()
+		if err != nil {
+			return err
+		}
+	}
+
+	name := o.Repository
+	var details *gits.CreateRepoData
+	if !o.BatchMode {
+		var err error
+		details, err = o.GetGitRepositoryDetails()
+		if err != nil {
+			return err
+		}
+		name = details.RepoName
+	}
+
+	outDir, err := generator.Generate(&generators.GenerateArguments{
+		Dir:           dir,
+		Name:          name,
+		Values:        values,
+		BatchMode:     o.BatchMode,
+		IOFileHandles: o.GetIOFileHandles(),
+	})
+	if err != nil {
+		return err
+	}
+
+	defaults := generator.PipelineDefaults()
+	if o.DraftPack == "" {
+		o.DraftPack = defaults.BuildPack
+	}
+	if o.DeployKind == "" {
+		o.DeployKind = defaults.DeployKind
+	}
+	if details != nil {
+		o.ConfigureImportOptions(details)
+	}
+	return o.ImportCreatedProject(outDir)
+}
+
 func (o *CreateProjectOptions) addCreateAppFlags(cmd *cobra.Command) {
 	cmd.Flags().BoolVarP(&o.DisableImport, "no-import", "", false, "Disable import after the creation")
 	cmd.Flags().StringVarP(&o.OutDir, opts.OptionOutputDir, "o", "", "Directory to output the project to. Defaults to the current directory")
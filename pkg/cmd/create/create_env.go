@@ -49,6 +49,10 @@ var (
 
 		# Creates a new Environment passing in the required data on the command line
 		jx create env -n prod -l Production --no-gitops --namespace my-prod
+
+		# Creates a new Environment from the 'regional' template of the environmentTemplates in jx-requirements.yml
+		# running on the remote cluster 'europe' from its clusters
+		jx create env -b -n prod-eu -l "Production EU" --template regional --remote-cluster europe
 	`)
 )
 
@@ -71,6 +75,11 @@ type CreateEnvOptions struct {
 	Vault                  bool
 	PullSecrets            string
 	Update                 bool
+	Template               string
+	RemoteCluster          string
+
+	template      *config.EnvironmentTemplateConfig
+	remoteCluster *config.RemoteClusterConfig
 }
 
 // NewCmdCreateEnv creates a command object for the "create" command
@@ -121,6 +130,8 @@ func NewCmdCreateEnv(commonOpts *opts.CommonOptions) *cobra.Command {
 	cmd.Flags().BoolVarP(&options.Prow, "prow", "", false, "Install and use Prow for environment promotion")
 	cmd.Flags().BoolVarP(&options.Vault, "vault", "", false, "Sets up a Hashicorp Vault for storing secrets during the cluster creation")
 	cmd.Flags().StringVarP(&options.PullSecrets, optionPullSecrets, "", "", "A list of Kubernetes secret names that will be attached to the service account (e.g. foo, bar, baz)")
+	cmd.Flags().StringVarP(&options.Template, optionEnvTemplate, "", "", "The name of the environment template from the environmentTemplates of the requirements which defaults the namespace, policies, apps and repository of the Environment")
+	cmd.Flags().StringVarP(&options.RemoteCluster, optionRemoteCluster, "", "", "The name of the remote cluster from the clusters of the requirements which runs the Environment")
 
 	opts.AddGitRepoOptionsArguments(cmd, &options.GitRepositoryOptions)
 	options.HelmValuesConfig.AddExposeControllerValues(cmd, false)
@@ -188,6 +199,11 @@ func (o *CreateEnvOptions) Run() error {
 		}
 	}
 
+	err = o.applyEnvironmentTemplate(jxClient, ns)
+	if err != nil {
+		return err
+	}
+
 	env := v1.Environment{}
 	o.Options.Spec.PromotionStrategy = v1.PromotionStrategyType(o.PromotionStrategy)
 	gitProvider, err := kube.CreateEnvironmentSurvey(o.BatchMode, authConfigSvc, devEnv, &env, &o.Options, o.Update, o.ForkEnvironmentGitRepo, ns,
@@ -222,6 +238,14 @@ func (o *CreateEnvOptions) Run() error {
 		if err != nil {
 			return err
 		}
+		err = o.setupTemplateNamespace(&env, ns)
+		if err != nil {
+			return err
+		}
+		err = o.registerRemoteCluster(&env)
+		if err != nil {
+			return err
+		}
 	}
 
 	/* It is important this pull secret handling goes after any namespace creation code; the service account exists in the created namespace */
@@ -236,27 +260,33 @@ func (o *CreateEnvOptions) Run() error {
 		}
 		imagePullSecrets := strings.Fields(o.PullSecrets)
 		saName := "default"
-		//log.Logger().Infof("Patching the secrets %s for the service account %s\n", imagePullSecrets, saName)
-		err = serviceaccount.PatchImagePullSecrets(kubeClient, env.Spec.Namespace, saName, imagePullSecrets)
+		envKubeClient, envNS, err := o.environmentKubeClient(&env)
 		if err != nil {
-			return fmt.Errorf("Failed to add pull secrets %s to service account %s in namespace %s: %v", imagePullSecrets, saName, env.Spec.Namespace, err)
+			return err
+		}
+		if envKubeClient == nil {
+			log.Logger().Warnf("The remote cluster %s has no kube context so the pull secret(s) %s have to be added to service account %s in namespace %s manually", o.remoteCluster.Name, imagePullSecrets, saName, envNS)
 		} else {
-			log.Logger().Infof("Service account \"%s\" in namespace \"%s\" configured to use pull secret(s) %s ", saName, env.Spec.Namespace, imagePullSecrets)
-			log.Logger().Infof("Pull secret(s) must exist in namespace %s before deploying your applications in this environment ", env.Spec.Namespace)
+			//log.Logger().Infof("Patching the secrets %s for the service account %s\n", imagePullSecrets, saName)
+			err = serviceaccount.PatchImagePullSecrets(envKubeClient, envNS, saName, imagePullSecrets)
+			if err != nil {
+				return fmt.Errorf("Failed to add pull secrets %s to service account %s in namespace %s: %v", imagePullSecrets, saName, envNS, err)
+			} else {
+				log.Logger().Infof("Service account \"%s\" in namespace \"%s\" configured to use pull secret(s) %s ", saName, envNS, imagePullSecrets)
+				log.Logger().Infof("Pull secret(s) must exist in namespace %s before deploying your applications in this environment ", envNS)
+			}
 		}
 	}
 
 	// Skip the environment registration if gitops mode is active
-	if o.GitOpsMode {
-		return nil
-	}
-
-	err = o.RegisterEnvironment(&env, gitProvider, authConfigSvc)
-	if err != nil {
-		return errors.Wrapf(err, "registering the environment %s/%s", env.GetNamespace(), env.GetName())
+	if !o.GitOpsMode {
+		err = o.RegisterEnvironment(&env, gitProvider, authConfigSvc)
+		if err != nil {
+			return errors.Wrapf(err, "registering the environment %s/%s", env.GetNamespace(), env.GetName())
+		}
 	}
 
-	return nil
+	return o.addTemplateApps(&env)
 }
 
 // RegisterEnvironment performs the environment registration
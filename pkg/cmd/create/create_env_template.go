@@ -0,0 +1,262 @@
+package create
+
+import (
+	"fmt"
+	"strings"
+
+	v1 "github.com/jenkins-x/jx/v2/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/v2/pkg/client/clientset/versioned"
+	"github.com/jenkins-x/jx/v2/pkg/config"
+	"github.com/jenkins-x/jx/v2/pkg/environments"
+	"github.com/jenkins-x/jx/v2/pkg/gits"
+	"github.com/jenkins-x/jx/v2/pkg/helm"
+	"github.com/jenkins-x/jx/v2/pkg/kube"
+	"github.com/jenkins-x/jx/v2/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/helm/pkg/proto/hapi/chart"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	optionEnvTemplate   = "template"
+	optionRemoteCluster = "remote-cluster"
+)
+
+// applyEnvironmentTemplate loads the environment template and remote cluster from the requirements in the team
+// settings and defaults the options of the new environment from them
+func (o *CreateEnvOptions) applyEnvironmentTemplate(jxClient versioned.Interface, ns string) error {
+	if o.Template == "" && o.RemoteCluster == "" {
+		return nil
+	}
+	teamSettings, err := kube.GetDevEnvTeamSettings(jxClient, ns)
+	if err != nil {
+		return errors.Wrapf(err, "failed to find the team settings in namespace %s", ns)
+	}
+	requirements, err := config.GetRequirementsConfigFromTeamSettings(teamSettings)
+	if err != nil {
+		return errors.Wrap(err, "failed to get the requirements from the team settings")
+	}
+	if requirements == nil {
+		return fmt.Errorf("the team settings have no requirements so the environment templates and remote clusters of jx-requirements.yml cannot be used")
+	}
+	name := o.Options.Name
+
+	if o.Template != "" {
+		if name == "" {
+			return util.MissingOption(kube.OptionName)
+		}
+		t, err := requirements.EnvironmentTemplate(o.Template)
+		if err != nil {
+			return err
+		}
+		for _, app := range t.Apps {
+			if app.Name == "" || app.Version == "" || app.Repository == "" {
+				return fmt.Errorf("the apps of environment template %s need a name, version and repository", t.Name)
+			}
+		}
+		if o.Options.Spec.Namespace == "" {
+			o.Options.Spec.Namespace, err = t.NamespaceFor(ns, name)
+			if err != nil {
+				return err
+			}
+		}
+		if o.PromotionStrategy == "" {
+			o.PromotionStrategy = string(t.PromotionStrategy)
+		}
+		if t.Order != 0 && !o.flagChanged("order") {
+			o.Options.Spec.Order = t.Order
+		}
+		if t.GitTemplateURL != "" && !o.flagChanged("fork-git-repo") {
+			o.ForkEnvironmentGitRepo = t.GitTemplateURL
+		}
+		if o.BranchPattern == "" {
+			o.BranchPattern = t.BranchPattern
+		}
+		if o.PullSecrets == "" {
+			o.PullSecrets = strings.Join(t.PullSecrets, " ")
+		}
+		if o.RemoteCluster == "" {
+			o.RemoteCluster = t.RemoteCluster
+		}
+		o.template = t
+		log.Logger().Infof("Creating environment %s from template %s", util.ColorInfo(name), util.ColorInfo(t.Name))
+	}
+
+	if o.RemoteCluster != "" {
+		cluster := requirements.RemoteCluster(o.RemoteCluster)
+		if cluster == nil {
+			names := []string{}
+			for _, c := range requirements.Clusters {
+				names = append(names, c.Name)
+			}
+			return util.InvalidOption(optionRemoteCluster, o.RemoteCluster, names)
+		}
+		if cluster.Environment != "" && name != "" && cluster.Environment != name {
+			return fmt.Errorf("the remote cluster %s already runs environment %s", cluster.Name, cluster.Environment)
+		}
+		o.Options.Spec.RemoteCluster = true
+		if o.Options.Spec.Cluster == "" {
+			o.Options.Spec.Cluster = cluster.Name
+		}
+		o.remoteCluster = cluster
+	}
+	return nil
+}
+
+// flagChanged returns true if the flag was specified on the command line
+func (o *CreateEnvOptions) flagChanged(name string) bool {
+	return o.Cmd != nil && o.Cmd.Flags().Changed(name)
+}
+
+// environmentKubeClient returns the kube client and namespace of the cluster which runs the environment, or nil if
+// the environment runs on a remote cluster jx cannot connect to
+func (o *CreateEnvOptions) environmentKubeClient(env *v1.Environment) (kubernetes.Interface, string, error) {
+	ns := env.Spec.Namespace
+	if o.remoteCluster == nil {
+		kubeClient, err := o.KubeClient()
+		return kubeClient, ns, err
+	}
+	cluster := o.remoteCluster
+	if cluster.Namespace != "" {
+		ns = cluster.Namespace
+	}
+	if cluster.Context == "" {
+		return nil, ns, nil
+	}
+	kubeClient, err := kube.CreateKubeClientForContext(cluster.Context)
+	if err != nil {
+		return nil, ns, errors.Wrapf(err, "failed to create the kube client for remote cluster %s", cluster.Name)
+	}
+	return kubeClient, ns, nil
+}
+
+// setupTemplateNamespace creates the namespace of the environment in the cluster which runs it with the labels,
+// annotations and policies of the template
+func (o *CreateEnvOptions) setupTemplateNamespace(env *v1.Environment, team string) error {
+	if o.template == nil && o.remoteCluster == nil {
+		return nil
+	}
+	kubeClient, ns, err := o.environmentKubeClient(env)
+	if err != nil {
+		return err
+	}
+	if kubeClient == nil {
+		log.Logger().Warnf("The remote cluster %s has no kube context so the namespace %s of environment %s has to be set up in it manually", o.remoteCluster.Name, ns, env.Name)
+		return nil
+	}
+	labels := map[string]string{
+		kube.LabelTeam:        team,
+		kube.LabelEnvironment: env.Name,
+	}
+	annotations := map[string]string{}
+	if o.template != nil {
+		for k, v := range o.template.NamespaceLabels {
+			labels[k] = v
+		}
+		for k, v := range o.template.NamespaceAnnotations {
+			annotations[k] = v
+		}
+	}
+	err = kube.EnsureNamespaceCreated(kubeClient, ns, labels, annotations)
+	if err != nil {
+		return errors.Wrapf(err, "creating the namespace %s of environment %s", ns, env.Name)
+	}
+	if o.template != nil && o.template.Policy != nil {
+		log.Logger().Infof("Applying the policies of template %s to namespace %s", util.ColorInfo(o.template.Name), util.ColorInfo(ns))
+		err = kube.ApplyNamespacePolicy(kubeClient, ns, *o.template.Policy)
+		if err != nil {
+			return errors.Wrapf(err, "applying the policies of template %s to namespace %s", o.template.Name, ns)
+		}
+	}
+	return nil
+}
+
+// registerRemoteCluster records that the remote cluster runs the environment in the requirements of the team settings
+func (o *CreateEnvOptions) registerRemoteCluster(env *v1.Environment) error {
+	if o.remoteCluster == nil {
+		return nil
+	}
+	err := o.ModifyDevEnvironment(func(devEnv *v1.Environment) error {
+		requirements, err := config.GetRequirementsConfigFromTeamSettings(&devEnv.Spec.TeamSettings)
+		if err != nil {
+			return errors.Wrap(err, "failed to get the requirements from the team settings")
+		}
+		if requirements == nil {
+			return fmt.Errorf("the team settings have no requirements")
+		}
+		cluster := requirements.RemoteCluster(o.remoteCluster.Name)
+		if cluster == nil {
+			return fmt.Errorf("the remote cluster %s was removed from the requirements", o.remoteCluster.Name)
+		}
+		if cluster.Environment != "" && cluster.Environment != env.Name {
+			return fmt.Errorf("the remote cluster %s already runs environment %s", cluster.Name, cluster.Environment)
+		}
+		cluster.Environment = env.Name
+		if _, err := requirements.Environment(env.Name); err != nil {
+			requirements.Environments = append(requirements.Environments, config.EnvironmentConfig{
+				Key:               env.Name,
+				RemoteCluster:     true,
+				PromotionStrategy: env.Spec.PromotionStrategy,
+			})
+		}
+		data, err := yaml.Marshal(requirements)
+		if err != nil {
+			return errors.Wrap(err, "marshalling the requirements")
+		}
+		devEnv.Spec.TeamSettings.BootRequirements = string(data)
+		return nil
+	})
+	if err != nil {
+		return errors.Wrapf(err, "registering environment %s on remote cluster %s", env.Name, o.remoteCluster.Name)
+	}
+	log.Logger().Infof("Environment %s runs on remote cluster %s. Add it to the clusters of your jx-requirements.yml so that it is kept on the next boot", util.ColorInfo(env.Name), util.ColorInfo(o.remoteCluster.Name))
+	return nil
+}
+
+// addTemplateApps raises a Pull Request adding the apps of the template to the environment repository which merges
+// once its checks pass
+func (o *CreateEnvOptions) addTemplateApps(env *v1.Environment) error {
+	if o.template == nil || len(o.template.Apps) == 0 {
+		return nil
+	}
+	gitURL := env.Spec.Source.URL
+	if gitURL == "" {
+		log.Logger().Warnf("Environment %s has no git repository so the apps of template %s cannot be added to it", env.Name, o.template.Name)
+		return nil
+	}
+	gitProvider, _, err := o.CreateGitProviderForURLWithoutKind(gitURL)
+	if err != nil {
+		return errors.Wrapf(err, "creating git provider for %s", gitURL)
+	}
+	names := []string{}
+	for _, app := range o.template.Apps {
+		names = append(names, app.Name)
+	}
+	details := gits.PullRequestDetails{
+		BranchName: "add-template-apps-" + o.template.Name,
+		Title:      fmt.Sprintf("chore: add the apps of environment template %s", o.template.Name),
+		Message:    fmt.Sprintf("chore: add the apps %s of environment template %s", strings.Join(names, ", "), o.template.Name),
+	}
+	modifyChartFn := func(requirements *helm.Requirements, metadata *chart.Metadata, values map[string]interface{},
+		templates map[string]string, dir string, details *gits.PullRequestDetails) error {
+		for _, app := range o.template.Apps {
+			requirements.SetAppVersion(app.Name, app.Version, app.Repository, app.Alias)
+		}
+		return nil
+	}
+	options := environments.EnvironmentPullRequestOptions{
+		Gitter:        o.Git(),
+		GitProvider:   gitProvider,
+		ModifyChartFn: modifyChartFn,
+	}
+	info, err := options.Create(env, "", &details, nil, "", true)
+	if err != nil {
+		return errors.Wrapf(err, "creating the Pull Request adding the apps of template %s", o.template.Name)
+	}
+	if info != nil && info.PullRequest != nil {
+		log.Logger().Infof("Added the apps %s to environment %s via Pull Request %s", util.ColorInfo(strings.Join(names, ", ")), util.ColorInfo(env.Name), util.ColorInfo(info.PullRequest.URL))
+	}
+	return nil
+}
@@ -20,6 +20,7 @@ import (
 	"github.com/jenkins-x/jx/v2/pkg/log"
 	"github.com/jenkins-x/jx/v2/pkg/nodes"
 	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 	"k8s.io/apimachinery/pkg/util/uuid"
 )
@@ -38,6 +39,15 @@ var (
  		# using browser automation to login to the Git server
 		# with the username and password to find the API Token
 		jx create git token -n local -p somePassword someUserName	
+
+		# Add an API Token which is only used for the repositories of a team
+		jx create git token --scope myorg/team-* -t myToken team-bot
+
+		# Store the API Token in the OS keychain using the docker-credential-osxkeychain helper
+		jx create git token --credential-helper osxkeychain -t myToken someUserName
+
+		# Authenticate as a GitHub App installation instead of using a personal access token
+		jx create git token --github-app-id 1234 --github-app-installation-id 5678 --github-app-private-key app.pem my-bot
 	`)
 )
 
@@ -50,6 +60,12 @@ type CreateGitTokenOptions struct {
 	Password    string
 	ApiToken    string
 	Timeout     string
+
+	GitHubAppID             int64
+	GitHubAppInstallationID int64
+	GitHubAppPrivateKeyFile string
+	CredentialHelper        string
+	Scopes                  []string
 }
 
 // NewCmdCreateGitToken creates a command
@@ -77,6 +93,11 @@ func NewCmdCreateGitToken(commonOpts *opts.CommonOptions) *cobra.Command {
 	cmd.Flags().StringVarP(&options.ApiToken, "api-token", "t", "", "The API Token for the user")
 	cmd.Flags().StringVarP(&options.Password, "password", "p", "", "The User password to try automatically create a new API Token")
 	cmd.Flags().StringVarP(&options.Timeout, "timeout", "", "", "The timeout if using browser automation to generate the API token (by passing username and password)")
+	cmd.Flags().Int64VarP(&options.GitHubAppID, "github-app-id", "", 0, "The ID of the GitHub App to authenticate as instead of using an API token")
+	cmd.Flags().Int64VarP(&options.GitHubAppInstallationID, "github-app-installation-id", "", 0, "The ID of the installation of the GitHub App")
+	cmd.Flags().StringVarP(&options.GitHubAppPrivateKeyFile, "github-app-private-key", "", "", "The file containing the PEM encoded private key of the GitHub App")
+	cmd.Flags().StringArrayVarP(&options.Scopes, "scope", "", nil, "The organisations or repository patterns such as myorg or myorg/team-* the API token is restricted to. The narrowest matching token is used for a repository")
+	cmd.Flags().StringVarP(&options.CredentialHelper, "credential-helper", "", "", "The credential helper to store the API token with instead of the auth config such as osxkeychain, pass or secretservice")
 
 	return cmd
 }
@@ -114,6 +135,25 @@ func (o *CreateGitTokenOptions) Run() error {
 	if o.ApiToken != "" {
 		userAuth.ApiToken = o.ApiToken
 	}
+	if len(o.Scopes) > 0 {
+		userAuth.Scopes = o.Scopes
+	}
+	if o.GitHubAppID != 0 {
+		if o.GitHubAppInstallationID == 0 || o.GitHubAppPrivateKeyFile == "" {
+			return fmt.Errorf("the --github-app-installation-id and --github-app-private-key options are required with --github-app-id")
+		}
+		key, err := ioutil.ReadFile(o.GitHubAppPrivateKeyFile)
+		if err != nil {
+			return errors.Wrapf(err, "reading the GitHub App private key %s", o.GitHubAppPrivateKeyFile)
+		}
+		userAuth.GithubAppID = o.GitHubAppID
+		userAuth.GithubAppInstallationID = o.GitHubAppInstallationID
+		userAuth.GithubAppPrivateKey = string(key)
+		_, err = gits.GitHubAppUserAuth(server.URL, userAuth)
+		if err != nil {
+			return errors.Wrapf(err, "verifying the GitHub App credentials")
+		}
+	}
 
 	tokenUrl := gits.ProviderAccessTokenURL(server.Kind, server.URL, userAuth.Username)
 
@@ -140,6 +180,9 @@ func (o *CreateGitTokenOptions) Run() error {
 		}
 	}
 
+	if o.CredentialHelper != "" {
+		server.CredentialHelper = o.CredentialHelper
+	}
 	config.CurrentServer = server.URL
 	err = authConfigSvc.SaveConfig()
 	if err != nil {
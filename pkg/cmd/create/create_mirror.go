@@ -0,0 +1,122 @@
+package create
+
+import (
+	v1 "github.com/jenkins-x/jx/v2/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/create/options"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/v2/pkg/gits"
+	"github.com/jenkins-x/jx/v2/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+const (
+	optionOrigin     = "origin"
+	optionMirror     = "mirror"
+	optionMirrorHost = "host"
+)
+
+var (
+	createMirrorLong = templates.LongDesc(`
+		Registers a mirror of git repositories for your team.
+
+		Repositories whose URL starts with the origin URL are cloned from the mirror instead, falling back to the origin
+		if the mirror fails. Changes are always pushed to the origin. This allows repositories such as the version
+		stream to be cloned in clusters whose egress to the public git servers is restricted.
+`)
+
+	createMirrorExample = templates.Examples(`
+		# Clone all GitHub repositories from an internal mirror
+		jx create mirror --origin https://github.com --mirror https://git.example.com/github
+
+		# Clone the jenkins-x repositories from a mirror host which mirrors repositories under their origin host and path
+		# i.e. https://git.example.com/github.com/jenkins-x
+		jx create mirror --origin https://github.com/jenkins-x --host git.example.com
+	`)
+)
+
+// CreateMirrorOptions the options for the create mirror command
+type CreateMirrorOptions struct {
+	options.CreateOptions
+
+	Name      string
+	OriginURL string
+	MirrorURL string
+	Host      string
+}
+
+// NewCmdCreateMirror creates a command object for the "create mirror" command
+func NewCmdCreateMirror(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &CreateMirrorOptions{
+		CreateOptions: options.CreateOptions{
+			CommonOptions: commonOpts,
+		},
+	}
+
+	cmd := &cobra.Command{
+		Use:     "mirror",
+		Short:   "Registers a mirror of git repositories for your team",
+		Aliases: []string{"mirrors", "gitmirror"},
+		Long:    createMirrorLong,
+		Example: createMirrorExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+
+	cmd.Flags().StringVarP(&options.Name, opts.OptionName, "n", "", "The name of the mirror, defaults to the host of the origin URL")
+	cmd.Flags().StringVarP(&options.OriginURL, optionOrigin, "o", "", "The URL prefix of the repositories to mirror such as https://github.com or https://github.com/jenkins-x")
+	cmd.Flags().StringVarP(&options.MirrorURL, optionMirror, "m", "", "The URL prefix of the mirrored repositories which replaces the origin URL prefix")
+	cmd.Flags().StringVarP(&options.Host, optionMirrorHost, "", "", "The mirror host which mirrors repositories under their origin host and path, used if no mirror URL is specified")
+	return cmd
+}
+
+// Run implements the command
+func (o *CreateMirrorOptions) Run() error {
+	if o.OriginURL == "" {
+		return util.MissingOption(optionOrigin)
+	}
+	if o.MirrorURL == "" {
+		if o.Host == "" {
+			return util.MissingOption(optionMirror)
+		}
+		mirrorURL, err := gits.MirrorURLForHost(o.Host, o.OriginURL)
+		if err != nil {
+			return err
+		}
+		o.MirrorURL = mirrorURL
+	}
+	if o.Name == "" {
+		host, err := util.UrlHostNameWithoutPort(o.OriginURL)
+		if err != nil {
+			return errors.Wrapf(err, "finding the host of %s", o.OriginURL)
+		}
+		o.Name = host
+	}
+	mirror := v1.GitMirror{
+		Name:      o.Name,
+		OriginURL: o.OriginURL,
+		MirrorURL: o.MirrorURL,
+	}
+
+	callback := func(env *v1.Environment) error {
+		settings := &env.Spec.TeamSettings
+		for i, m := range settings.GitMirrors {
+			if m.OriginURL == mirror.OriginURL {
+				settings.GitMirrors[i] = mirror
+				log.Logger().Infof("Updating the mirror of %s to %s", util.ColorInfo(mirror.OriginURL), util.ColorInfo(mirror.MirrorURL))
+				return nil
+			}
+		}
+		settings.GitMirrors = append(settings.GitMirrors, mirror)
+		log.Logger().Infof("Adding the mirror %s of %s", util.ColorInfo(mirror.MirrorURL), util.ColorInfo(mirror.OriginURL))
+		return nil
+	}
+	return o.ModifyDevEnvironment(callback)
+}
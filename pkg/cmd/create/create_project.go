@@ -3,11 +3,11 @@ package create
 import (
 	"fmt"
 	"os"
-
-	"github.com/jenkins-x/jx/v2/pkg/cmd/create/options"
+	"strings"
 
 	"github.com/jenkins-x/jx/v2/pkg/cmd/helper"
 	"github.com/jenkins-x/jx/v2/pkg/cmd/importcmd"
+	"github.com/jenkins-x/jx/v2/pkg/generators"
 
 	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
 	"github.com/jenkins-x/jx/v2/pkg/cmd/templates"
@@ -17,48 +17,56 @@ import (
 
 const (
 	createQuickstartName = "Create new application from a Quickstart"
-	createSpringName     = "Create new Spring Boot microservice"
 	importDirName        = "Import existing code from a directory"
 	importGitName        = "Import code from a git repository"
 	importGitHubName     = "Import code from a github repository"
+
+	createGeneratorPrefix = "Create new "
 )
 
 var (
-	createProjectNames = []string{
-		createQuickstartName,
-		createSpringName,
-		importDirName,
-		importGitName,
-		importGitHubName,
-	}
-
 	createProjectLong = templates.LongDesc(`
-		Create a new Project by importing code, using a Quickstart or custom wizard for Spring.
+		Create a new Project by importing code, using a Quickstart or a generator such as Spring Boot, .NET, Quarkus
+		or Next.js.
+
+		Use '--generator' to create the project with a generator without the wizard, passing the options of the
+		generator via '--generator-option name=value'.
 
 ` + helper.SeeAlsoText("jx create quickstart", "jx create spring", "jx import"))
 
 	createProjectExample = templates.Examples(`
 		# Create a project
 		jx create project
+
+		# Create a .NET web API called myapi
+		jx create project --generator dotnet --name myapi
+
+		# Create a Quarkus microservice in the com.acme group
+		jx create project --generator quarkus --generator-option group=com.acme
 	`)
 )
 
 // CreateProjectWizardOptions the options for the command
 type CreateProjectWizardOptions struct {
-	options.CreateOptions
+	CreateProjectOptions
+
+	Generator        string
+	GeneratorOptions []string
 }
 
 // NewCmdCreateProject creates a command object for the "create" command
 func NewCmdCreateProject(commonOpts *opts.CommonOptions) *cobra.Command {
 	options := &CreateProjectWizardOptions{
-		CreateOptions: options.CreateOptions{
-			CommonOptions: commonOpts,
+		CreateProjectOptions: CreateProjectOptions{
+			ImportOptions: importcmd.ImportOptions{
+				CommonOptions: commonOpts,
+			},
 		},
 	}
 
 	cmd := &cobra.Command{
 		Use:     "project",
-		Short:   "Create a new Project by importing code, using a Quickstart or custom wizard for Spring",
+		Short:   "Create a new Project by importing code, using a Quickstart or a generator",
 		Long:    createProjectLong,
 		Example: createProjectExample,
 		Run: func(cmd *cobra.Command, args []string) {
@@ -68,23 +76,44 @@ func NewCmdCreateProject(commonOpts *opts.CommonOptions) *cobra.Command {
 			helper.CheckErr(err)
 		},
 	}
+	options.addCreateAppFlags(cmd)
 
+	cmd.Flags().StringVarP(&options.Generator, "generator", "", "", fmt.Sprintf("The generator creating the project. Should be one of %s", strings.Join(generators.Names(), ", ")))
+	cmd.Flags().StringArrayVarP(&options.GeneratorOptions, "generator-option", "", []string{}, "The value of an option of the generator in the form name=value")
 	return cmd
 }
 
 // Run implements the command
 func (o *CreateProjectWizardOptions) Run() error {
-	name, err := util.PickName(createProjectNames, "Which kind of project you want to create: ",
+	if o.Generator != "" {
+		return o.createFromGenerator(o.Generator)
+	}
+
+	names := []string{createQuickstartName}
+	generatorLabels := map[string]string{}
+	for _, name := range generators.Names() {
+		generator, err := generators.Get(name)
+		if err != nil {
+			return err
+		}
+		label := createGeneratorPrefix + generator.Description()
+		names = append(names, label)
+		generatorLabels[label] = name
+	}
+	names = append(names, importDirName, importGitName, importGitHubName)
+
+	name, err := util.PickName(names, "Which kind of project you want to create: ",
 		"Jenkins X supports a number of diffferent wizards for creating or importing new projects.",
 		o.GetIOFileHandles())
 	if err != nil {
 		return err
 	}
+	if generatorName, ok := generatorLabels[name]; ok {
+		return o.createFromGenerator(generatorName)
+	}
 	switch name {
 	case createQuickstartName:
 		return o.createQuickstart()
-	case createSpringName:
-		return o.createSpring()
 	case importDirName:
 		return o.importDir()
 	case importGitName:
@@ -102,10 +131,20 @@ func (o *CreateProjectWizardOptions) createQuickstart() error {
 	return w.Run()
 }
 
-func (o *CreateProjectWizardOptions) createSpring() error {
-	w := &CreateSpringOptions{}
-	w.CommonOptions = o.CommonOptions
-	return w.Run()
+func (o *CreateProjectWizardOptions) createFromGenerator(name string) error {
+	generator, err := generators.Get(name)
+	if err != nil {
+		return err
+	}
+	values := map[string]string{}
+	for _, option := range o.GeneratorOptions {
+		parts := strings.SplitN(option, "=", 2)
+		if len(parts) != 2 {
+			return util.InvalidOptionf("generator-option", option, "should be in the form name=value")
+		}
+		values[parts[0]] = parts[1]
+	}
+	return o.CreateFromGenerator(generator, values)
 }
 
 func (o *CreateProjectWizardOptions) importDir() error {
@@ -56,6 +56,11 @@ type CreatePullRequestOptions struct {
 	Push   bool
 	Fork   bool
 
+	Draft     bool
+	AutoMerge bool
+	Reviewers []string
+	Assignees []string
+
 	Results *gits.PullRequestInfo
 }
 
@@ -88,6 +93,10 @@ func NewCmdCreatePullRequest(commonOpts *opts.CommonOptions) *cobra.Command {
 	cmd.Flags().StringArrayVarP(&options.Labels, "label", "l", []string{}, "The labels to add to the pullrequest")
 	cmd.Flags().BoolVarP(&options.Push, "push", "", false, "If true the contents of the source directory will be committed, pushed, and used to create the pull request")
 	cmd.Flags().BoolVarP(&options.Fork, "fork", "", false, "If true, and the username configured to push the repo is different from the org name a PR is being created against, assume that this is a fork")
+	cmd.Flags().BoolVarP(&options.Draft, "draft", "", false, "Creates the pull request as a draft if the git provider supports it")
+	cmd.Flags().BoolVarP(&options.AutoMerge, "auto-merge", "", false, "Labels the pull request so that it is merged once its status checks pass")
+	cmd.Flags().StringArrayVarP(&options.Reviewers, "reviewer", "", []string{}, "The users to request reviews of the pull request from")
+	cmd.Flags().StringArrayVarP(&options.Assignees, "assignee", "", []string{}, "The users to assign the pull request to")
 
 	return cmd
 }
@@ -124,7 +133,23 @@ func (o *CreatePullRequestOptions) Run() error {
 		return errors.WithStack(err)
 	}
 
-	o.Results, err = gits.PushRepoAndCreatePullRequest(o.Dir, gitInfo, forkInfo, o.Base, details, nil, o.Push, details.Message, o.Push, false, o.Git(), provider)
+	creator := &gits.PullRequestCreator{
+		Dir:           o.Dir,
+		UpstreamRepo:  gitInfo,
+		ForkRepo:      forkInfo,
+		Base:          o.Base,
+		Details:       details,
+		Commit:        o.Push,
+		CommitMessage: details.Message,
+		Push:          o.Push,
+		Draft:         o.Draft,
+		AutoMerge:     o.AutoMerge,
+		Reviewers:     o.Reviewers,
+		Assignees:     o.Assignees,
+		Gitter:        o.Git(),
+		Provider:      provider,
+	}
+	o.Results, err = creator.Create()
 	if err != nil {
 		return errors.Wrapf(err, "failed to create PR for base %s and head branch %s", o.Base, details.BranchName)
 	}
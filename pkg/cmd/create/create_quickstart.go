@@ -17,6 +17,7 @@ import (
 	"github.com/jenkins-x/jx/v2/pkg/github"
 	"github.com/jenkins-x/jx/v2/pkg/gits"
 	"github.com/jenkins-x/jx/v2/pkg/log"
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 
 	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
@@ -36,6 +37,12 @@ var (
 		This will create a new project for you from the selected template.
 		It will exclude any work-in-progress repos (containing the "WIP-" pattern)
 
+		The quickstarts are searched for in all the catalogs of the team, which are the git organisations and catalog
+		YAML files added via 'jx create quickstartlocation'. If the catalog of the chosen quickstart defines template
+		variables then their values are asked for, or passed via '--var', and replace the REPLACE_ME_<NAME> placeholders
+		in the source of the quickstart. If the quickstart has a 'quickstart.yaml' file declaring template parameters then
+		its files are rendered as Go templates with the values of the parameters, also passed via '--var' or asked for.
+
 		For more documentation see: [https://jenkins-x.io/developing/create-quickstart/](https://jenkins-x.io/developing/create-quickstart/)
 
 ` + helper.SeeAlsoText("jx create project"))
@@ -49,6 +56,9 @@ var (
 		jx create quickstart
 
 		jx create quickstart -f http
+
+		# Create a quickstart from a catalog of the team setting its template variables
+		jx create quickstart --catalog platform -f spring --var owner-team=payments
 	`)
 )
 
@@ -97,6 +107,7 @@ func NewCmdCreateQuickstart(commonOpts *opts.CommonOptions) *cobra.Command {
 	cmd.Flags().StringVarP(&options.Filter.Text, "filter", "f", "", "The text filter")
 	cmd.Flags().StringVarP(&options.Filter.ProjectName, "project-name", "p", "", "The project name (for use with -b batch mode)")
 	cmd.Flags().BoolVarP(&options.Filter.AllowML, "machine-learning", "", false, "Allow machine-learning quickstarts in results")
+	cmd.Flags().StringVarP(&options.Filter.Catalog, "catalog", "", "", "The name of the quickstarts catalog to filter on")
 	return cmd
 }
 
@@ -176,6 +187,14 @@ func (o *CreateQuickstartOptions) CreateQuickStart(q *quickstarts.QuickstartForm
 	if isMLProjectSet(q.Quickstart) {
 		return fmt.Errorf("you have tried to select a machine-learning quickstart projectset please try again using jx create mlquickstart instead")
 	}
+	values, err := util.ExtractKeyValuePairs(o.Variables, "=")
+	if err != nil {
+		return util.InvalidOptionError("var", o.Variables, err)
+	}
+	q.Variables, err = quickstarts.ResolveVariables(q.Quickstart.Variables, values, o.BatchMode, o.GetIOFileHandles())
+	if err != nil {
+		return err
+	}
 	dir := o.OutDir
 	if dir == "" {
 		dir, err = os.Getwd()
@@ -187,6 +206,11 @@ func (o *CreateQuickstartOptions) CreateQuickStart(q *quickstarts.QuickstartForm
 	if err != nil {
 		return err
 	}
+	err = quickstarts.ReplaceVariables(genDir, q.Variables)
+	if err != nil {
+		return errors.Wrapf(err, "replacing the template variables of quickstart %s", q.Quickstart.ID)
+	}
+	o.VariableValues = q.Variables
 
 	// if there is a charts folder named after the app name, lets rename it to the generated app name
 	folder := ""
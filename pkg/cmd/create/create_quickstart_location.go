@@ -18,6 +18,8 @@ const (
 	optionOwner   = "owner"
 	optionGitUrl  = "url"
 	optionGitKind = "kind"
+
+	optionCatalogURL = "catalog-url"
 )
 
 var (
@@ -38,6 +40,9 @@ var (
 		# Create a quickstart location for your Git repo and organisation 
 		jx create quickstartlocation --url https://mygit.server.com --owner my-quickstarts
 
+		# Create a quickstart catalog from a YAML file in a private repository of your Git server
+		jx create quickstartlocation --name platform --url https://mygit.server.com --catalog-url https://mygit.server.com/raw/platform/quickstarts/master/catalog.yml
+
 	`)
 )
 
@@ -45,11 +50,13 @@ var (
 type CreateQuickstartLocationOptions struct {
 	options.CreateOptions
 
-	GitUrl   string
-	GitKind  string
-	Owner    string
-	Includes []string
-	Excludes []string
+	GitUrl     string
+	GitKind    string
+	Owner      string
+	Includes   []string
+	Excludes   []string
+	Name       string
+	CatalogURL string
 }
 
 // NewCmdCreateQuickstartLocation creates a command object for the "create" command
@@ -79,6 +86,8 @@ func NewCmdCreateQuickstartLocation(commonOpts *opts.CommonOptions) *cobra.Comma
 	cmd.Flags().StringVarP(&options.Owner, optionOwner, "o", "", "The owner is the user or organisation of the Git provider used to find repositories")
 	cmd.Flags().StringArrayVarP(&options.Includes, "includes", "i", []string{"*"}, "The patterns to include repositories")
 	cmd.Flags().StringArrayVarP(&options.Excludes, "excludes", "x", []string{"WIP-*"}, "The patterns to exclude repositories")
+	cmd.Flags().StringVarP(&options.Name, "name", "", "", "The name of the quickstarts catalog used to filter quickstarts. Defaults to the owner")
+	cmd.Flags().StringVarP(&options.CatalogURL, optionCatalogURL, "", "", "The URL of a catalog YAML file listing the quickstarts and their template variables, which is downloaded using the credentials of the Git service")
 
 	return cmd
 }
@@ -93,7 +102,7 @@ func (o *CreateQuickstartLocationOptions) Run() error {
 	if o.GitUrl == "" {
 		return util.MissingOption(optionGitUrl)
 	}
-	if o.Owner == "" {
+	if o.Owner == "" && o.CatalogURL == "" {
 		return util.MissingOption(optionOwner)
 	}
 
@@ -117,24 +126,32 @@ func (o *CreateQuickstartLocationOptions) Run() error {
 
 	var location *v1.QuickStartLocation
 	for i, l := range locations {
-		if l.GitURL == o.GitUrl && l.Owner == o.Owner {
+		if l.GitURL == o.GitUrl && l.Owner == o.Owner && l.CatalogURL == o.CatalogURL {
 			location = &locations[i]
 		}
 	}
 	if location == nil {
 		locations = append(locations, v1.QuickStartLocation{
-			GitURL:  o.GitUrl,
-			GitKind: o.GitKind,
-			Owner:   o.Owner,
+			GitURL:     o.GitUrl,
+			GitKind:    o.GitKind,
+			Owner:      o.Owner,
+			CatalogURL: o.CatalogURL,
 		})
 	}
 	location = &locations[len(locations)-1]
 	location.Includes = o.Includes
 	location.Excludes = o.Excludes
+	if o.Name != "" {
+		location.Name = o.Name
+	}
 
 	callback := func(env *v1.Environment) error {
 		env.Spec.TeamSettings.QuickstartLocations = locations
-		log.Logger().Infof("Adding the quickstart git owner %s", util.ColorInfo(util.UrlJoin(o.GitUrl, o.Owner)))
+		if o.CatalogURL != "" {
+			log.Logger().Infof("Adding the quickstarts catalog %s", util.ColorInfo(o.CatalogURL))
+		} else {
+			log.Logger().Infof("Adding the quickstart git owner %s", util.ColorInfo(util.UrlJoin(o.GitUrl, o.Owner)))
+		}
 		return nil
 	}
 	return o.ModifyDevEnvironment(callback)
@@ -1,22 +1,16 @@
 package create
 
 import (
-	"fmt"
-	"os"
-
 	"github.com/jenkins-x/jx/v2/pkg/cmd/importcmd"
 
 	"github.com/jenkins-x/jx/v2/pkg/cmd/helper"
 
-	"github.com/jenkins-x/jx/v2/pkg/gits"
-
 	"github.com/spf13/cobra"
 
 	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
 	"github.com/jenkins-x/jx/v2/pkg/cmd/templates"
-	"github.com/jenkins-x/jx/v2/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/generators"
 	"github.com/jenkins-x/jx/v2/pkg/spring"
-	"github.com/jenkins-x/jx/v2/pkg/util"
 )
 
 var (
@@ -93,59 +87,9 @@ func NewCmdCreateSpring(commonOpts *opts.CommonOptions) *cobra.Command {
 
 // Run implements the command
 func (o *CreateSpringOptions) Run() error {
-	cacheDir, err := util.CacheDir()
-	if err != nil {
-		return err
-	}
-
-	data := &o.SpringForm
-
-	var details *gits.CreateRepoData
-
-	if !o.BatchMode {
-		details, err = o.GetGitRepositoryDetails()
-		if err != nil {
-			return err
-		}
-
-		data.ArtifactId = details.RepoName
-	}
-
-	model, err := spring.LoadSpringBoot(cacheDir)
-	if err != nil {
-		return fmt.Errorf("Failed to load Spring Boot model %s", err)
-	}
-	err = model.CreateSurvey(&o.SpringForm, o.Advanced, o.BatchMode)
-	if err != nil {
-		return err
+	generator := &generators.SpringGenerator{
+		Form:     o.SpringForm,
+		Advanced: o.Advanced,
 	}
-
-	// always add in actuator as its required for health checking
-	if !util.Contains(o.SpringForm.Dependencies, "actuator") {
-		o.SpringForm.Dependencies = append(o.SpringForm.Dependencies, "actuator")
-	}
-	// always add web as the JVM tends to terminate if its not added
-	if !util.Contains(o.SpringForm.Dependencies, "web") {
-		o.SpringForm.Dependencies = append(o.SpringForm.Dependencies, "web")
-	}
-
-	dir := o.OutDir
-	if dir == "" {
-		dir, err = os.Getwd()
-		if err != nil {
-			return err
-		}
-	}
-
-	outDir, err := data.CreateProject(dir)
-	if err != nil {
-		return err
-	}
-	log.Logger().Infof("Created Spring Boot project at %s", util.ColorInfo(outDir))
-
-	if details != nil {
-		o.ConfigureImportOptions(details)
-	}
-
-	return o.ImportCreatedProject(outDir)
+	return o.CreateFromGenerator(generator, nil)
 }
@@ -1,6 +1,8 @@
 package deletecmd
 
 import (
+	"time"
+
 	"github.com/jenkins-x/jx/v2/pkg/apps"
 	"github.com/jenkins-x/jx/v2/pkg/cmd/helper"
 
@@ -18,6 +20,11 @@ var (
 	deleteAppLong = templates.LongDesc(`
 		Deletes one or more Apps (an app is similar to an addon)
 
+		When using GitOps a pull request is created against the environment which removes the chart of the app from
+		the requirements.yaml along with its values in the values.yaml and the files generated when the app was added,
+		such as its values files, schemas and secrets. Use --wait to wait for the pipeline of the environment to
+		uninstall the release once the pull request merges.
+
 `)
 
 	deleteAppExample = templates.Examples(`
@@ -26,6 +33,9 @@ var (
 
 		# delete a specific app 
 		jx delete app jx-app-cheese
+
+		# delete an app using GitOps, merging the pull request and waiting for the release to be uninstalled
+		jx delete app jx-app-cheese --auto-merge --wait
 	`)
 )
 
@@ -46,6 +56,8 @@ type DeleteAppOptions struct {
 	Purge       bool
 	Alias       string
 	AutoMerge   bool
+	Wait        bool
+	WaitTimeout time.Duration
 
 	// Used for testing
 	CloneDir string
@@ -78,6 +90,9 @@ func NewCmdDeleteApp(commonOpts *opts.CommonOptions) *cobra.Command {
 	cmd.Flags().StringVarP(&o.Alias, opts.OptionAlias, "", "",
 		"An alias to use for the app (available when using GitOps for your dev environment)")
 	cmd.Flags().BoolVarP(&o.AutoMerge, "auto-merge", "", false, "Automatically merge GitOps pull requests that pass CI")
+	cmd.Flags().BoolVarP(&o.Wait, "wait", "", false,
+		"Wait for the GitOps pipeline to uninstall the release once the pull request merges (available when using GitOps for your dev environment)")
+	cmd.Flags().DurationVarP(&o.WaitTimeout, "wait-timeout", "", 30*time.Minute, "The maximum duration to wait for the release to be uninstalled")
 
 	return cmd
 }
@@ -111,6 +126,9 @@ func (o *DeleteAppOptions) Run() error {
 		}
 		installOptions.GitProvider = gitProvider
 		installOptions.Gitter = o.Git()
+		if o.Wait {
+			installOptions.WaitTimeout = o.WaitTimeout
+		}
 	}
 	if !o.GitOps {
 		err := o.EnsureHelm()
@@ -122,6 +140,10 @@ func (o *DeleteAppOptions) Run() error {
 			return util.InvalidOptionf(opts.OptionAlias, o.Alias,
 				"Unable to specify --%s when NOT using GitOps for your dev environment", opts.OptionAlias)
 		}
+		if o.Wait {
+			return util.InvalidOptionf("wait", o.Wait,
+				"Unable to specify --%s when NOT using GitOps for your dev environment", "wait")
+		}
 	}
 
 	jxClient, ns, err := o.JXClientAndDevNamespace()
@@ -12,6 +12,7 @@ import (
 	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
 	"github.com/jenkins-x/jx/v2/pkg/kube"
 	"github.com/jenkins-x/jx/v2/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/previews"
 	"github.com/jenkins-x/jx/v2/pkg/util"
 	"github.com/spf13/cobra"
 )
@@ -111,6 +112,7 @@ func (o *DeletePreviewOptions) Run() error {
 	return o.DeletePreview(o.Name)
 }
 
+// DeletePreview deletes the preview environment, its fixtures, helm release and namespace
 func (o *DeletePreviewOptions) DeletePreview(name string) error {
 	jxClient, ns, err := o.JXClient()
 	if err != nil {
@@ -121,20 +123,14 @@ func (o *DeletePreviewOptions) DeletePreview(name string) error {
 	if err != nil {
 		return err
 	}
-	releaseName := kube.GetPreviewEnvironmentReleaseName(environment)
-	if len(releaseName) > 0 {
-		log.Logger().Infof("Deleting helm release: %s", util.ColorInfo(releaseName))
-		err = o.Helm().DeleteRelease(ns, releaseName, true)
-		if err != nil {
-			return err
-		}
+	kubeClient, err := o.KubeClient()
+	if err != nil {
+		return err
 	}
-
-	log.Logger().Infof("Deleting preview environment: %s", util.ColorInfo(name))
-	deleteOptions := &DeleteEnvOptions{
-		CommonOptions:   o.CommonOptions,
-		DeleteNamespace: true,
+	previewKubeClient, helmer, cleanup, err := o.ClientsForEnvironment(environment)
+	if err != nil {
+		return err
 	}
-	deleteOptions.Args = []string{name}
-	return deleteOptions.Run()
+	defer cleanup()
+	return previews.DeletePreviewEnvironment(kubeClient, previewKubeClient, jxClient, helmer, ns, environment)
 }
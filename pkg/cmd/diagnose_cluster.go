@@ -0,0 +1,287 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	jenkinsio "github.com/jenkins-x/jx/v2/pkg/apis/jenkins.io"
+	v1 "github.com/jenkins-x/jx/v2/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/v2/pkg/health"
+	"github.com/jenkins-x/jx/v2/pkg/io/secrets"
+	"github.com/jenkins-x/jx/v2/pkg/kube"
+	"github.com/jenkins-x/jx/v2/pkg/kube/services"
+	"github.com/jenkins-x/jx/v2/pkg/version"
+	"github.com/jenkins-x/jx/v2/pkg/versionstream"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// chartLighthouse the chart of Lighthouse in the version stream
+	chartLighthouse = "jenkins-x/lighthouse"
+	// deploymentLighthouseWebhooks the deployment of Lighthouse receiving the webhooks
+	deploymentLighthouseWebhooks = "lighthouse-webhooks"
+	// deploymentTektonWebhook the deployment of the admission webhook of Tekton Pipelines
+	deploymentTektonWebhook = "tekton-pipelines-webhook"
+	// serviceHook the service of Prow or Lighthouse git providers send webhooks to
+	serviceHook = "hook"
+)
+
+var (
+	diagnoseClusterLong = templates.LongDesc(`
+		Checks the health of the Jenkins X components of the cluster: Tekton, Lighthouse or Prow, ChartMuseum, Vault,
+		the ingress, the webhook endpoint, the custom resource definitions and the skew of the versions of the
+		components against the version stream.
+
+		Each component is reported as OK, WARNING, ERROR or SKIPPED if the team does not use it, with a hint of how to
+		fix the components which are not healthy. Use '--json' to output the report for automation. The command fails
+		if any component is broken.
+`)
+
+	diagnoseClusterExample = templates.Examples(`
+		# Check the health of the Jenkins X components of the cluster
+		jx diagnose cluster
+
+		# Output the report as JSON
+		jx diagnose cluster --json
+`)
+
+	// diagnoseCRDs the custom resource definitions Jenkins X registers
+	diagnoseCRDs = []string{"apps", "buildpacks", "commitstatuses", "environmentrolebindings", "environments", "extensions",
+		"facts", "gitservices", "pipelineactivities", "pipelinestructures", "plugins", "releases", "schedulers",
+		"sourcerepositories", "sourcerepositorygroups", "teams", "users", "workflows"}
+)
+
+// DiagnoseClusterOptions the options for the diagnose cluster command
+type DiagnoseClusterOptions struct {
+	*opts.CommonOptions
+
+	JSON bool
+}
+
+// NewCmdDiagnoseCluster creates the command
+func NewCmdDiagnoseCluster(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &DiagnoseClusterOptions{
+		CommonOptions: commonOpts,
+	}
+	cmd := &cobra.Command{
+		Use:     "cluster",
+		Short:   "Checks the health of the Jenkins X components of the cluster",
+		Long:    diagnoseClusterLong,
+		Example: diagnoseClusterExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+	cmd.Flags().BoolVarP(&options.JSON, "json", "", false, "Outputs the report as JSON")
+	return cmd
+}
+
+// Run implements the command
+func (o *DiagnoseClusterOptions) Run() error {
+	kubeClient, ns, err := o.KubeClientAndDevNamespace()
+	if err != nil {
+		return errors.Wrap(err, "failed to create kubeClient")
+	}
+	jxClient, _, err := o.JXClient()
+	if err != nil {
+		return errors.Wrap(err, "failed to create jxClient")
+	}
+	apiClient, err := o.ApiExtensionsClient()
+	if err != nil {
+		return errors.Wrap(err, "failed to create the API extensions client")
+	}
+
+	webHookEngine := v1.WebHookEngineNone
+	devEnv, err := kube.GetDevEnvironment(jxClient, ns)
+	if err != nil {
+		return errors.Wrapf(err, "failed to find the development environment in namespace %s", ns)
+	}
+	if devEnv != nil {
+		webHookEngine = devEnv.Spec.WebHookEngine
+	}
+	usesJenkins := webHookEngine == v1.WebHookEngineJenkins
+
+	report := &health.ClusterReport{
+		Namespace: ns,
+	}
+	report.Add(health.CheckDeployments(kubeClient, ns, "tekton", []string{kube.DeploymentTektonController, deploymentTektonWebhook}, !usesJenkins,
+		fmt.Sprintf("check the logs of the %s pods and upgrade Tekton with 'jx upgrade boot' or 'jx upgrade platform'", kube.DeploymentTektonController)))
+	switch webHookEngine {
+	case v1.WebHookEngineLighthouse:
+		report.Add(health.CheckDeployments(kubeClient, ns, "lighthouse", []string{deploymentLighthouseWebhooks}, true,
+			fmt.Sprintf("check the logs of the %s pods and upgrade Lighthouse with 'jx upgrade boot'", deploymentLighthouseWebhooks)))
+	case v1.WebHookEngineProw:
+		report.Add(health.CheckDeployments(kubeClient, ns, "prow", []string{serviceHook, kube.DeploymentProwBuild}, true,
+			"check the logs of the Prow pods and reinstall Prow with 'jx upgrade addon prow'"))
+	default:
+		message := "the team has no webhook engine"
+		if usesJenkins {
+			message = "the team uses Jenkins for webhooks"
+		}
+		report.Add(health.CheckResult{
+			Component: "lighthouse",
+			Status:    health.CheckStatusSkipped,
+			Message:   message,
+		})
+	}
+	report.Add(health.CheckDeployments(kubeClient, ns, "chartmuseum", []string{kube.ServiceChartMuseum}, false,
+		fmt.Sprintf("check the persistent volume and the logs of the %s pods", kube.ServiceChartMuseum)))
+	if o.GetSecretsLocation() == secrets.VaultLocationKind {
+		report.Add(health.CheckDeployments(kubeClient, ns, "vault", []string{kube.DefaultVaultOperatorReleaseName}, true,
+			fmt.Sprintf("check the logs of the %s pods and the status of the Vault with 'kubectl get vaults'", kube.DefaultVaultOperatorReleaseName)))
+	} else {
+		report.Add(health.CheckResult{
+			Component: "vault",
+			Status:    health.CheckStatusSkipped,
+			Message:   fmt.Sprintf("secrets are stored in %s", o.GetSecretsLocation()),
+		})
+	}
+	report.Add(checkIngress(kubeClient, ns))
+	if usesJenkins || webHookEngine == v1.WebHookEngineNone {
+		report.Add(health.CheckResult{
+			Component: "webhooks",
+			Status:    health.CheckStatusSkipped,
+			Message:   "the team does not use Lighthouse or Prow",
+		})
+	} else {
+		report.Add(checkWebhookEndpoint(kubeClient, ns))
+	}
+	crds := []string{}
+	for _, name := range diagnoseCRDs {
+		crds = append(crds, name+"."+jenkinsio.GroupName)
+	}
+	report.Add(health.CheckCRDs(apiClient, crds, "register them with 'jx upgrade crd'"))
+	report.Add(o.checkVersions(kubeClient, ns, webHookEngine))
+
+	if o.JSON {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return errors.Wrap(err, "marshalling the report to JSON")
+		}
+		fmt.Fprintln(o.Out, string(data))
+	} else {
+		report.Render(o.Out)
+	}
+
+	failed := report.Failed()
+	if len(failed) > 0 {
+		return fmt.Errorf("the components %s of the cluster are not healthy", strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+// checkIngress checks that the ingress has a domain and that the ingresses of the namespace have an address
+func checkIngress(kubeClient kubernetes.Interface, ns string) health.CheckResult {
+	result := health.CheckResult{
+		Component:   "ingress",
+		Remediation: "configure the domain and the ingress controller with 'jx upgrade ingress'",
+	}
+	ic, err := kube.GetIngressConfig(kubeClient, ns)
+	if err != nil {
+		result.Status = health.CheckStatusError
+		result.Message = fmt.Sprintf("failed to load the %s ConfigMap: %s", kube.IngressConfigConfigmap, err.Error())
+		return result
+	}
+	if ic.Domain == "" {
+		result.Status = health.CheckStatusError
+		result.Message = "no domain is configured"
+		return result
+	}
+	list, err := kubeClient.ExtensionsV1beta1().Ingresses(ns).List(metav1.ListOptions{})
+	if err != nil {
+		result.Status = health.CheckStatusError
+		result.Message = fmt.Sprintf("failed to list the ingresses of namespace %s: %s", ns, err.Error())
+		return result
+	}
+	pending := []string{}
+	for _, ing := range list.Items {
+		if len(ing.Status.LoadBalancer.Ingress) == 0 {
+			pending = append(pending, ing.Name)
+		}
+	}
+	if len(pending) > 0 {
+		result.Status = health.CheckStatusWarning
+		result.Message = fmt.Sprintf("the ingresses %s have no address yet", strings.Join(pending, ", "))
+		result.Remediation = "check that the ingress controller is running and has a load balancer"
+		return result
+	}
+	result.Status = health.CheckStatusOK
+	result.Message = fmt.Sprintf("domain %s with %d ingresses", ic.Domain, len(list.Items))
+	return result
+}
+
+// checkWebhookEndpoint checks that the service git providers send webhooks to can be reached from outside the cluster
+func checkWebhookEndpoint(kubeClient kubernetes.Interface, ns string) health.CheckResult {
+	result := health.CheckResult{
+		Component:   "webhooks",
+		Remediation: "expose the hook service with 'jx upgrade ingress' then recreate the webhooks with 'jx update webhooks'",
+	}
+	hookURL, err := services.FindServiceURL(kubeClient, ns, serviceHook)
+	if err != nil || hookURL == "" {
+		result.Status = health.CheckStatusError
+		result.Message = "the hook service has no external URL so git providers cannot send webhooks"
+		return result
+	}
+	result.Status = health.CheckStatusOK
+	result.Message = fmt.Sprintf("webhooks are received at %s", hookURL)
+	return result
+}
+
+// checkVersions compares the versions of jx and of the charts of the pipeline components with the version stream
+func (o *DiagnoseClusterOptions) checkVersions(kubeClient kubernetes.Interface, ns string, webHookEngine v1.WebHookEngineType) health.CheckResult {
+	remediation := "upgrade the cluster to the version stream with 'jx upgrade boot' and jx with 'jx upgrade cli'"
+	resolver, err := o.GetVersionResolver()
+	if err != nil {
+		return health.CheckResult{
+			Component:   "versions",
+			Status:      health.CheckStatusWarning,
+			Message:     fmt.Sprintf("failed to load the version stream: %s", err.Error()),
+			Remediation: "check the version stream of the team with 'jx get teams'",
+		}
+	}
+	versions := []health.ComponentVersion{}
+	jxVersion := health.ComponentVersion{
+		Name:    "jx",
+		Current: version.GetVersion(),
+	}
+	latest, err := o.GetLatestJXVersion(resolver)
+	if err == nil {
+		jxVersion.Expected = latest.String()
+	}
+	versions = append(versions, jxVersion)
+
+	charts := map[string]string{kube.ChartTekton: kube.DeploymentTektonController}
+	if webHookEngine == v1.WebHookEngineLighthouse {
+		charts[chartLighthouse] = deploymentLighthouseWebhooks
+	}
+	for _, chart := range []string{kube.ChartTekton, chartLighthouse} {
+		deployment := charts[chart]
+		if deployment == "" {
+			continue
+		}
+		v := health.ComponentVersion{
+			Name: chart,
+		}
+		v.Current, err = health.DeploymentChartVersion(kubeClient, ns, deployment)
+		if err != nil {
+			return health.CheckResult{
+				Component: "versions",
+				Status:    health.CheckStatusError,
+				Message:   fmt.Sprintf("failed to get the deployment %s: %s", deployment, err.Error()),
+			}
+		}
+		v.Expected, _ = resolver.StableVersionNumber(versionstream.KindChart, chart)
+		versions = append(versions, v)
+	}
+	return health.CheckVersions(versions, remediation)
+}
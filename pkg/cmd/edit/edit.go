@@ -61,6 +61,7 @@ func NewCmdEdit(commonOpts *opts.CommonOptions) *cobra.Command {
 	cmd.AddCommand(NewCmdEditDeployKind(commonOpts))
 	cmd.AddCommand(NewCmdEditEnv(commonOpts))
 	cmd.AddCommand(NewCmdEditHelmBin(commonOpts))
+	cmd.AddCommand(NewCmdEditParameters(commonOpts))
 	cmd.AddCommand(requirements.NewCmdEditRequirements(commonOpts))
 	cmd.AddCommand(NewCmdEditStorage(commonOpts))
 	cmd.AddCommand(NewCmdEditUserRole(commonOpts))
@@ -21,6 +21,10 @@ import (
 var (
 	editBuildpackLong = templates.LongDesc(`
 		Edits the build pack configuration for your team
+
+		A team using its own fork of the build packs can use '--version-stream' to resolve the git reference of the
+		build packs from the version stream of the team rather than using a fixed reference. The reference is then
+		upgraded by a Pull Request on the version stream created by 'jx upgrade buildpacks'.
 `)
 
 	editBuildpackExample = templates.Examples(`
@@ -32,6 +36,9 @@ var (
 
         # to switch to kubernetes workloads for your team
 		jx edit buildpack -n kubernetes-workloads
+
+        # to use a fork of the build packs whose version is pinned in the version stream of your team
+		jx edit buildpack -u https://github.com/myorg/jenkins-x-kubernetes.git --version-stream
 		
 		For more documentation see: [https://jenkins-x.io/architecture/build-packs/](https://jenkins-x.io/architecture/build-packs/)
 	`)
@@ -44,6 +51,7 @@ type EditBuildPackOptions struct {
 	BuildPackName string
 	BuildPackURL  string
 	BuildPackRef  string
+	VersionStream bool
 }
 
 // NewCmdEditBuildpack creates a command object for the "create" command
@@ -72,6 +80,7 @@ func NewCmdEditBuildpack(commonOpts *opts.CommonOptions) *cobra.Command {
 	cmd.Flags().StringVarP(&options.BuildPackURL, "url", "u", "", "The URL for the build pack Git repository")
 	cmd.Flags().StringVarP(&options.BuildPackRef, "ref", "r", "", "The Git reference (branch,tag,sha) in the Git repository to use")
 	cmd.Flags().StringVarP(&options.BuildPackName, "name", "n", "", "The name of the BuildPack resource to use")
+	cmd.Flags().BoolVarP(&options.VersionStream, "version-stream", "", false, "Resolves the Git reference of the build packs from the version stream of the team instead of using --ref")
 	return cmd
 }
 
@@ -89,6 +98,7 @@ func (o *EditBuildPackOptions) Run() error {
 	buildPackURL := o.BuildPackURL
 	BuildPackRef := o.BuildPackRef
 	buildPackName := o.BuildPackName
+	versionStreamChanged := o.Cmd != nil && o.Cmd.Flags().Changed("version-stream")
 
 	if buildPackName != "" {
 		var buildPack *v1.BuildPack
@@ -110,16 +120,19 @@ func (o *EditBuildPackOptions) Run() error {
 	}
 	if o.BatchMode {
 		if buildPackURL == "" && BuildPackRef == "" {
-			return nil
-		}
-		if buildPackURL == "" {
-			return util.MissingOption("url")
-		}
-		if BuildPackRef == "" {
-			return util.MissingOption("ref")
+			if !versionStreamChanged {
+				return nil
+			}
+		} else {
+			if buildPackURL == "" {
+				return util.MissingOption("url")
+			}
+			if BuildPackRef == "" && !o.VersionStream {
+				return util.MissingOption("ref")
+			}
 		}
 	} else {
-		if buildPackURL == "" || BuildPackRef == "" {
+		if buildPackURL == "" || (BuildPackRef == "" && !o.VersionStream) {
 			teamSettings, err := o.TeamSettings()
 			if err != nil {
 				return err
@@ -173,9 +186,18 @@ func (o *EditBuildPackOptions) Run() error {
 		if BuildPackRef != "" {
 			teamSettings.BuildPackRef = BuildPackRef
 		}
-		teamSettings.BuildPackName = buildPackName
+		if buildPackName != "" || buildPackURL != "" {
+			teamSettings.BuildPackName = buildPackName
+		}
+		if versionStreamChanged {
+			teamSettings.BuildPackVersionStream = o.VersionStream
+		}
 
-		log.Logger().Infof("Setting the team build pack to %s repo: %s ref: %s", util.ColorInfo(buildPackName), util.ColorInfo(buildPackURL), util.ColorInfo(BuildPackRef))
+		if teamSettings.BuildPackVersionStream {
+			log.Logger().Infof("Setting the team build pack to %s repo: %s with its version from the version stream", util.ColorInfo(buildPackName), util.ColorInfo(teamSettings.BuildPackURL))
+		} else {
+			log.Logger().Infof("Setting the team build pack to %s repo: %s ref: %s", util.ColorInfo(buildPackName), util.ColorInfo(buildPackURL), util.ColorInfo(BuildPackRef))
+		}
 		return nil
 	}
 	return o.ModifyDevEnvironment(callback)
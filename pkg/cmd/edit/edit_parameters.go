@@ -0,0 +1,207 @@
+package edit
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/ghodss/yaml"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/v2/pkg/helm"
+	"github.com/jenkins-x/jx/v2/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+const defaultEditor = "vi"
+
+var (
+	editParametersLong = templates.LongDesc(`
+		Edits the 'env/parameters.yaml' file of the boot configuration used by 'jx boot'
+
+		If the file is encrypted with sops it is decrypted while it is edited and encrypted again with the same keys when
+		it is saved. Files are also encrypted if a '.sops.yaml' file in the boot configuration has creation rules for them,
+		so that the parameters, including any secrets, can be stored in git without storing them in plain text.
+
+		Without a property the file is opened in the editor from $EDITOR.
+`)
+
+	editParametersExample = templates.Examples(`
+		# edits the parameters in your editor
+		jx edit parameters
+
+		# sets an individual parameter using its path
+		jx edit parameters pipelineUser.token mytoken
+
+		# sets a parameter and commits the change to the local git clone
+		jx edit parameters enableDocker true --commit
+`)
+)
+
+// EditParametersOptions the options for the edit parameters command
+type EditParametersOptions struct {
+	*opts.CommonOptions
+
+	Dir           string
+	Commit        bool
+	CommitMessage string
+}
+
+// NewCmdEditParameters creates the command
+func NewCmdEditParameters(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &EditParametersOptions{
+		CommonOptions: commonOpts,
+	}
+	cmd := &cobra.Command{
+		Use:     "parameters [property value]",
+		Short:   "Edits the 'env/parameters.yaml' file of the boot configuration, decrypting and encrypting it with sops",
+		Long:    editParametersLong,
+		Example: editParametersExample,
+		Aliases: []string{"params", "parameter"},
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+	cmd.Flags().StringVarP(&options.Dir, "dir", "", ".", "the directory of the boot configuration containing the 'env/parameters.yaml' file")
+	cmd.Flags().BoolVarP(&options.Commit, "commit", "", false, "commits the modified 'env/parameters.yaml' file if it is inside a git repository")
+	cmd.Flags().StringVarP(&options.CommitMessage, "commit-message", "", "chore(config): update parameters.yaml", "the message used when committing the modified 'env/parameters.yaml' file")
+	return cmd
+}
+
+// Run implements the command
+func (o *EditParametersOptions) Run() error {
+	if len(o.Args) != 0 && len(o.Args) != 2 {
+		return errors.Errorf("expected a property path and a value but got %d arguments", len(o.Args))
+	}
+	fileName := filepath.Join(o.Dir, "env", helm.ParametersYAMLFile)
+	var data []byte
+	var err error
+	if len(o.Args) == 2 {
+		data, err = o.setProperty(fileName, o.Args[0], o.Args[1])
+	} else {
+		data, err = o.editFile(fileName)
+	}
+	if err != nil {
+		return err
+	}
+	if data == nil {
+		log.Logger().Infof("no changes to %s", util.ColorInfo(fileName))
+		return nil
+	}
+
+	encrypted, err := helm.WriteSOPSFile(fileName, data)
+	if err != nil {
+		return errors.Wrapf(err, "saving %s", fileName)
+	}
+	if encrypted {
+		log.Logger().Infof("saved file: %s encrypted with sops", util.ColorInfo(fileName))
+	} else {
+		log.Logger().Infof("saved file: %s", util.ColorInfo(fileName))
+		log.Logger().Warnf("the file is not encrypted, add a %s file with creation rules for it to encrypt it with sops", helm.SOPSConfigFileName)
+	}
+
+	if o.Commit {
+		return o.commitChanges(fileName)
+	}
+	return nil
+}
+
+// setProperty returns the parameters with the property at the dot separated path set to the value
+func (o *EditParametersOptions) setProperty(fileName string, path string, text string) ([]byte, error) {
+	values, err := helm.LoadValuesFile(fileName)
+	if err != nil {
+		return nil, errors.Wrapf(err, "loading %s", fileName)
+	}
+	var value interface{}
+	err = yaml.Unmarshal([]byte(text), &value)
+	if err != nil || value == nil {
+		value = text
+	}
+	util.SetMapValueViaPath(values, path, value)
+	data, err := yaml.Marshal(values)
+	if err != nil {
+		return nil, errors.Wrap(err, "marshalling the parameters")
+	}
+	return data, nil
+}
+
+// editFile opens the decrypted parameters in the editor returning them if they have been changed
+func (o *EditParametersOptions) editFile(fileName string) ([]byte, error) {
+	var original []byte
+	exists, err := util.FileExists(fileName)
+	if err != nil {
+		return nil, errors.Wrapf(err, "checking %s exists", fileName)
+	}
+	if exists {
+		original, _, err = helm.ReadSOPSFile(fileName)
+		if err != nil {
+			return nil, err
+		}
+	}
+	tmpFile, err := ioutil.TempFile("", "parameters-*.yaml")
+	if err != nil {
+		return nil, errors.Wrap(err, "creating a temporary file")
+	}
+	tmpFileName := tmpFile.Name()
+	defer os.Remove(tmpFileName) //nolint:errcheck
+	_, err = tmpFile.Write(original)
+	tmpFile.Close() //nolint:errcheck
+	if err != nil {
+		return nil, errors.Wrapf(err, "writing %s", tmpFileName)
+	}
+
+	editor := os.Getenv("EDITOR")
+	if editor == "" {
+		editor = defaultEditor
+	}
+	cmd := exec.Command(editor, tmpFileName) // #nosec
+	cmd.Stdin = o.In
+	cmd.Stdout = o.Out
+	cmd.Stderr = o.Err
+	err = cmd.Run()
+	if err != nil {
+		return nil, errors.Wrapf(err, "running the editor %s", editor)
+	}
+
+	data, err := ioutil.ReadFile(tmpFileName)
+	if err != nil {
+		return nil, errors.Wrapf(err, "reading %s", tmpFileName)
+	}
+	if string(data) == string(original) {
+		return nil, nil
+	}
+	_, err = helm.LoadValues(data)
+	if err != nil {
+		return nil, errors.Wrap(err, "the edited parameters are not valid YAML")
+	}
+	return data, nil
+}
+
+func (o *EditParametersOptions) commitChanges(fileName string) error {
+	gitter := o.Git()
+	dir := filepath.Dir(fileName)
+	_, gitConf, err := gitter.FindGitConfigDir(dir)
+	if err != nil {
+		return errors.Wrapf(err, "finding git repository for %s", dir)
+	}
+	if gitConf == "" {
+		log.Logger().Warnf("not committing changes as %s is not inside a git repository", util.ColorInfo(dir))
+		return nil
+	}
+	err = gitter.Add(dir, filepath.Base(fileName))
+	if err != nil {
+		return errors.Wrapf(err, "adding %s to git", fileName)
+	}
+	err = gitter.CommitIfChanges(dir, o.CommitMessage)
+	if err != nil {
+		return errors.Wrapf(err, "committing %s", fileName)
+	}
+	return nil
+}
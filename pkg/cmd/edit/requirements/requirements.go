@@ -4,11 +4,15 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 
+	"github.com/ghodss/yaml"
+
 	"github.com/jenkins-x/jx/v2/pkg/cmd/helper"
 	"github.com/jenkins-x/jx/v2/pkg/config"
 	"github.com/jenkins-x/jx/v2/pkg/gits"
+	"github.com/jenkins-x/jx/v2/pkg/io/secrets"
 
 	"github.com/jenkins-x/jx/v2/pkg/log"
 	"github.com/pkg/errors"
@@ -25,10 +29,17 @@ type RequirementsOptions struct {
 
 	Dir string
 
-	Requirements  config.RequirementsConfig
-	SecretStorage string
-	Webhook       string
-	Flags         RequirementBools
+	Requirements      config.RequirementsConfig
+	SecretStorage     string
+	Webhook           string
+	IngressController string
+	DNSProvider       string
+	ChartKind         string
+	Flags             RequirementBools
+	Commit            bool
+	CommitMessage     string
+	Encrypt           bool
+	SensitiveFields   []string
 }
 
 // RequirementBools for the boolean flags we only update if specified on the CLI
@@ -45,6 +56,15 @@ var (
 	requirementsExample = templates.Examples(`
 		# edits the local 'jx-requirements.yml' file used for 'jx boot'
 		jx edit requirements --domain foo.com --tls --provider eks
+
+		# sets an individual property using its path in the 'jx-requirements.yml' file
+		jx edit requirements cluster.clusterName foo
+
+		# sets an individual property and commits the change to the local git clone
+		jx edit requirements environments.1.remoteCluster true --commit
+
+		# stores the cluster project in the secret storage replacing it with a secret URL in the 'jx-requirements.yml' file
+		jx edit requirements --sensitive cluster.project --encrypt
 `)
 )
 
@@ -54,7 +74,7 @@ func NewCmdEditRequirements(commonOpts *opts.CommonOptions) *cobra.Command {
 		CommonOptions: commonOpts,
 	}
 	cmd := &cobra.Command{
-		Use:     "requirements",
+		Use:     "requirements [property value]",
 		Short:   "Edits the local 'jx-requirements.yml file for 'jx boot'",
 		Long:    requirementsLong,
 		Example: requirementsExample,
@@ -72,6 +92,10 @@ func NewCmdEditRequirements(commonOpts *opts.CommonOptions) *cobra.Command {
 		},
 	}
 	cmd.Flags().StringVarP(&options.Dir, "dir", "", ".", "the directory to search for the 'jx-requirements.yml' file")
+	cmd.Flags().BoolVarP(&options.Commit, "commit", "", false, "commits the modified 'jx-requirements.yml' file if it is inside a git repository")
+	cmd.Flags().StringVarP(&options.CommitMessage, "commit-message", "", "chore(config): update jx-requirements.yml", "the message used when committing the modified 'jx-requirements.yml' file")
+	cmd.Flags().BoolVarP(&options.Encrypt, "encrypt", "", false, "stores the values of the sensitive fields in the secret storage replacing them with secret URLs")
+	cmd.Flags().StringArrayVarP(&options.SensitiveFields, "sensitive", "", nil, "the path of a property such as 'cluster.project' to add to the sensitive fields")
 
 	// bools
 	cmd.Flags().BoolVarP(&options.Flags.AutoUpgrade, "autoupgrade", "", false, "enables or disables auto upgrades")
@@ -104,7 +128,7 @@ func NewCmdEditRequirements(commonOpts *opts.CommonOptions) *cobra.Command {
 	cmd.Flags().StringVarP(&options.Requirements.Cluster.HelmMajorVersion, "helm-version", "", "", "configures the Helm major version. e.g. 3 to try helm 3")
 
 	// git
-	cmd.Flags().StringVarP(&options.Requirements.Cluster.GitKind, "git-kind", "", "", fmt.Sprintf("the kind of git repository to use. Possible values: %s", strings.Join(gits.KindGits, ", ")))
+	cmd.Flags().StringVarP(&options.Requirements.Cluster.GitKind, "git-kind", "", "", fmt.Sprintf("the kind of git repository to use. Possible values: %s", strings.Join(gits.GitKinds(), ", ")))
 	cmd.Flags().StringVarP(&options.Requirements.Cluster.GitName, "git-name", "", "", "the name of the git repository")
 	cmd.Flags().StringVarP(&options.Requirements.Cluster.GitServer, "git-server", "", "", "the git server host such as https://github.com or https://gitlab.com")
 	cmd.Flags().StringVarP(&options.Requirements.Cluster.EnvironmentGitOwner, "env-git-owner", "", "", "the git owner (organisation or user) used to own the git repositories for the environments")
@@ -112,6 +136,9 @@ func NewCmdEditRequirements(commonOpts *opts.CommonOptions) *cobra.Command {
 	// ingress
 	cmd.Flags().StringVarP(&options.Requirements.Ingress.Domain, "domain", "d", "", "configures the domain name")
 	cmd.Flags().StringVarP(&options.Requirements.Ingress.TLS.Email, "tls-email", "", "", "the TLS email address to enable TLS on the domain")
+	cmd.Flags().StringVarP(&options.IngressController, "ingress-controller", "", "", fmt.Sprintf("configures the kind of ingress controller. Values: %s", strings.Join(config.IngressControllerTypeValues, ", ")))
+	cmd.Flags().StringVarP(&options.ChartKind, "chart-kind", "", "", fmt.Sprintf("configures the kind of chart repository charts are released to. Values: %s", strings.Join(config.ChartRepositoryTypeValues, ", ")))
+	cmd.Flags().StringVarP(&options.DNSProvider, "dns-provider", "", "", fmt.Sprintf("configures the DNS provider used by external-dns. Values: %s", strings.Join(config.DNSProviderTypeValues, ", ")))
 
 	// storage
 	cmd.Flags().StringVarP(&options.Requirements.Storage.Logs.URL, "bucket-logs", "", "", "the bucket URL to store logs")
@@ -138,7 +165,13 @@ func NewCmdEditRequirements(commonOpts *opts.CommonOptions) *cobra.Command {
 
 // Run runs the command
 func (o *RequirementsOptions) Run() error {
-	requirements, fileName, err := config.LoadRequirementsConfig(o.Dir, config.DefaultFailOnValidationError)
+	// the property path and value arguments if specified
+	args := o.Cmd.Flags().Args()
+	if len(args) != 0 && len(args) != 2 {
+		return errors.Errorf("expected a property path and a value but got %d arguments", len(args))
+	}
+
+	requirements, fileName, err := config.LoadRequirementsConfigWithOptions(o.Dir, config.DefaultFailOnValidationError, o.RequirementsOptions())
 	if err != nil {
 		return err
 	}
@@ -158,12 +191,140 @@ func (o *RequirementsOptions) Run() error {
 		return err
 	}
 
-	err = o.Requirements.SaveConfig(fileName)
+	if len(args) == 2 {
+		err = o.setProperty(args[0], args[1])
+		if err != nil {
+			return err
+		}
+	}
+
+	if o.Encrypt {
+		client, err := o.GetSecretURLClient(secrets.ToSecretsLocation(string(o.Requirements.SecretStorage)))
+		if err != nil {
+			return errors.Wrap(err, "creating the secret URL client")
+		}
+		err = o.Requirements.EncryptSensitiveFields(client)
+		if err != nil {
+			return errors.Wrapf(err, "encrypting the sensitive fields of %s", fileName)
+		}
+	}
+
+	err = o.Requirements.SaveConfigWithOptions(fileName, o.RequirementsOptions())
 	if err != nil {
 		return errors.Wrapf(err, "failed to save %s", fileName)
 	}
 
 	log.Logger().Infof("saved file: %s", util.ColorInfo(fileName))
+
+	if o.Commit {
+		return o.commitChanges(fileName)
+	}
+	return nil
+}
+
+// setProperty sets the property at the given dot separated path to the value, validating the resulting requirements
+func (o *RequirementsOptions) setProperty(path string, text string) error {
+	var value interface{}
+	err := yaml.Unmarshal([]byte(text), &value)
+	if err != nil {
+		value = text
+	}
+
+	requirements, err := o.requirementsWithProperty(path, value)
+	if err != nil {
+		if _, ok := value.(string); ok {
+			return err
+		}
+		// lets try again treating the value as a string e.g. for numeric project numbers
+		requirements, err = o.requirementsWithProperty(path, text)
+		if err != nil {
+			return err
+		}
+	}
+	o.Requirements = *requirements
+	return nil
+}
+
+func (o *RequirementsOptions) requirementsWithProperty(path string, value interface{}) (*config.RequirementsConfig, error) {
+	m, err := util.ToObjectMap(&o.Requirements)
+	if err != nil {
+		return nil, errors.Wrap(err, "converting requirements to a map")
+	}
+	err = setMapValueViaPath(m, strings.Split(path, "."), value)
+	if err != nil {
+		return nil, errors.Wrapf(err, "setting property %s", path)
+	}
+	data, err := yaml.Marshal(m)
+	if err != nil {
+		return nil, errors.Wrap(err, "marshalling requirements")
+	}
+	validationErrors, err := config.ValidateRequirementsData(data)
+	if err != nil {
+		return nil, errors.Wrapf(err, "validating requirements after setting property %s", path)
+	}
+	if len(validationErrors) > 0 {
+		return nil, errors.Errorf("invalid value for property %s:\n%s", path, strings.Join(validationErrors, "\n"))
+	}
+	requirements := &config.RequirementsConfig{}
+	err = yaml.Unmarshal(data, requirements)
+	if err != nil {
+		return nil, errors.Wrap(err, "unmarshalling requirements")
+	}
+	return requirements, nil
+}
+
+// setMapValueViaPath sets the value in the tree of maps and slices creating any missing maps
+func setMapValueViaPath(m map[string]interface{}, paths []string, value interface{}) error {
+	key := paths[0]
+	if len(paths) == 1 {
+		m[key] = value
+		return nil
+	}
+	switch child := m[key].(type) {
+	case map[string]interface{}:
+		return setMapValueViaPath(child, paths[1:], value)
+	case []interface{}:
+		idx, err := strconv.Atoi(paths[1])
+		if err != nil || idx < 0 || idx >= len(child) {
+			return errors.Errorf("invalid index %s for %s which has %d entries", paths[1], key, len(child))
+		}
+		if len(paths) == 2 {
+			child[idx] = value
+			return nil
+		}
+		entry, ok := child[idx].(map[string]interface{})
+		if !ok {
+			return errors.Errorf("entry %d of %s is not an object", idx, key)
+		}
+		return setMapValueViaPath(entry, paths[2:], value)
+	case nil:
+		entry := map[string]interface{}{}
+		m[key] = entry
+		return setMapValueViaPath(entry, paths[1:], value)
+	default:
+		return errors.Errorf("property %s is not an object", key)
+	}
+}
+
+func (o *RequirementsOptions) commitChanges(fileName string) error {
+	gitter := o.Git()
+	dir := filepath.Dir(fileName)
+	_, gitConf, err := gitter.FindGitConfigDir(dir)
+	if err != nil {
+		return errors.Wrapf(err, "finding git repository for %s", dir)
+	}
+	if gitConf == "" {
+		log.Logger().Warnf("not committing changes as %s is not inside a git repository", util.ColorInfo(dir))
+		return nil
+	}
+	err = gitter.Add(dir, filepath.Base(fileName))
+	if err != nil {
+		return errors.Wrapf(err, "adding %s to git", fileName)
+	}
+	err = gitter.CommitIfChanges(dir, o.CommitMessage)
+	if err != nil {
+		return errors.Wrapf(err, "committing %s", fileName)
+	}
 	return nil
 }
 
@@ -171,8 +332,8 @@ func (o *RequirementsOptions) applyDefaults() error {
 	r := &o.Requirements
 
 	gitKind := r.Cluster.GitKind
-	if gitKind != "" && util.StringArrayIndex(gits.KindGits, gitKind) < 0 {
-		return util.InvalidOption("git-kind", gitKind, gits.KindGits)
+	if gitKind != "" && util.StringArrayIndex(gits.GitKinds(), gitKind) < 0 {
+		return util.InvalidOption("git-kind", gitKind, gits.GitKinds())
 	}
 
 	// override boolean flags if specified
@@ -222,6 +383,32 @@ func (o *RequirementsOptions) applyDefaults() error {
 		}
 	}
 
+	if o.IngressController != "" {
+		if util.StringArrayIndex(config.IngressControllerTypeValues, o.IngressController) < 0 {
+			return util.InvalidOption("ingress-controller", o.IngressController, config.IngressControllerTypeValues)
+		}
+		r.Ingress.Controller.Kind = config.IngressControllerType(o.IngressController)
+	}
+	if o.ChartKind != "" {
+		if util.StringArrayIndex(config.ChartRepositoryTypeValues, o.ChartKind) < 0 {
+			return util.InvalidOption("chart-kind", o.ChartKind, config.ChartRepositoryTypeValues)
+		}
+		r.Cluster.ChartKind = config.ChartRepositoryType(o.ChartKind)
+	}
+	if o.DNSProvider != "" {
+		if util.StringArrayIndex(config.DNSProviderTypeValues, o.DNSProvider) < 0 {
+			return util.InvalidOption("dns-provider", o.DNSProvider, config.DNSProviderTypeValues)
+		}
+		r.Ingress.DNSProvider = config.DNSProviderType(o.DNSProvider)
+		r.Ingress.ExternalDNS = true
+	}
+
+	for _, field := range o.SensitiveFields {
+		if util.StringArrayIndex(r.SensitiveFields, field) < 0 {
+			r.SensitiveFields = append(r.SensitiveFields, field)
+		}
+	}
+
 	// default flags if associated values
 	if r.AutoUpdate.Schedule != "" {
 		r.AutoUpdate.Enabled = true
@@ -66,6 +66,37 @@ func TestCmdEditRequirements(t *testing.T) {
 			},
 			initialFile: gitOpsEnabled,
 		},
+		{
+			name: "set-property",
+			args: []string{"cluster.clusterName", "mycluster"},
+			callback: func(t *testing.T, req *config.RequirementsConfig) {
+				assert.Equal(t, "mycluster", req.Cluster.ClusterName, "req.Cluster.ClusterName")
+				assert.True(t, req.GitOps, "req.GitOps")
+			},
+			initialFile: gitOpsEnabled,
+		},
+		{
+			name: "set-numeric-string-property",
+			args: []string{"cluster.gke.projectNumber", "1234"},
+			callback: func(t *testing.T, req *config.RequirementsConfig) {
+				assert.Equal(t, "1234", req.Cluster.GKEConfig.ProjectNumber, "req.Cluster.GKEConfig.ProjectNumber")
+			},
+			initialFile: gitOpsEnabled,
+		},
+		{
+			name: "set-bool-property",
+			args: []string{"kaniko", "true"},
+			callback: func(t *testing.T, req *config.RequirementsConfig) {
+				assert.True(t, req.Kaniko, "req.Kaniko")
+			},
+			initialFile: gitOpsEnabled,
+		},
+		{
+			name:        "bad-property",
+			args:        []string{"cluster.clusterNme", "mycluster"},
+			fail:        true,
+			initialFile: gitOpsEnabled,
+		},
 		{
 			name:        "bad-git-kind",
 			args:        []string{"--git-kind=gitlob"},
@@ -2,9 +2,15 @@ package gc
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/jenkins-x/jx/v2/pkg/cmd/deletecmd"
 	"github.com/jenkins-x/jx/v2/pkg/cmd/preview"
+	"github.com/jenkins-x/jx/v2/pkg/config"
+	"github.com/jenkins-x/jx/v2/pkg/errorutil"
+	"github.com/jenkins-x/jx/v2/pkg/kube"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
 
 	"github.com/jenkins-x/jx/v2/pkg/cmd/helper"
 	"github.com/jenkins-x/jx/v2/pkg/cmd/promote"
@@ -30,6 +36,7 @@ type GCPreviewsOptions struct {
 
 	DisableImport bool
 	OutDir        string
+	DryRun        bool
 }
 
 var (
@@ -37,11 +44,21 @@ var (
 		Garbage collect Jenkins X preview environments.  If a pull request is merged or closed the associated preview
 		environment will be deleted.
 
+		Preview environments are also deleted when they are older than their TTL or have had no new commits for longer
+		than their idle timeout. These default to the 'previews' section of the requirements of the team and can be
+		overridden by the 'previewEnvironments' section of the 'jenkins-x.yml' of each repository.
+
+		Deleting a preview environment deletes its ingresses (and so their DNS records), helm release and namespace and,
+		if 'deleteImages' is enabled in the requirements, the tag of its image from the container registry.
+
 `)
 
 	GCPreviewsExample = templates.Examples(`
 		jx garbage collect previews
 		jx gc previews
+
+		# show which preview environments would be deleted
+		jx gc previews --dry-run
 `)
 )
 
@@ -63,6 +80,7 @@ func NewCmdGCPreviews(commonOpts *opts.CommonOptions) *cobra.Command {
 			helper.CheckErr(err)
 		},
 	}
+	cmd.Flags().BoolVarP(&options.DryRun, "dry-run", "", false, "Only logs the preview environments which would be deleted")
 	return cmd
 }
 
@@ -84,63 +102,122 @@ func (o *GCPreviewsOptions) Run() error {
 		return nil
 	}
 
+	defaults := o.PreviewsConfigFromTeamSettings()
+	now := time.Now()
+
 	var previewFound bool
-	for _, e := range envs.Items {
-		if e.Spec.Kind == v1.EnvironmentKindTypePreview {
-			previewFound = true
-			gitInfo, err := gits.ParseGitURL(e.Spec.Source.URL)
-			if err != nil {
-				return err
-			}
-			// we need pull request info to include
-			authConfigSvc, err := o.GitAuthConfigService()
-			if err != nil {
-				return err
-			}
-
-			gitKind, err := o.GitServerKind(gitInfo)
-			if err != nil {
-				return err
-			}
-
-			ghOwner, err := o.GetGitHubAppOwner(gitInfo)
-			if err != nil {
-				return err
-			}
-			gitProvider, err := gitInfo.CreateProvider(o.InCluster(), authConfigSvc, gitKind, ghOwner, o.Git(), o.BatchMode, o.GetIOFileHandles())
-			if err != nil {
-				return err
-			}
-			prNum, err := strconv.Atoi(e.Spec.PreviewGitSpec.Name)
-			if err != nil {
-				log.Logger().Warn("Unable to convert PR " + e.Spec.PreviewGitSpec.Name + " to a number")
-			}
-			pullRequest, err := gitProvider.GetPullRequest(gitInfo.Organisation, gitInfo, prNum)
-			if err != nil {
-				log.Logger().Warnf("Can not get pull request %s, skipping: %s", e.Spec.PreviewGitSpec.Name, err)
-				continue
-			}
-
-			lowerState := strings.ToLower(*pullRequest.State)
-
-			if strings.HasPrefix(lowerState, "clos") || strings.HasPrefix(lowerState, "merged") || strings.HasPrefix(lowerState, "superseded") || strings.HasPrefix(lowerState, "declined") {
-				// lets delete the preview environment
-				deleteOpts := deletecmd.DeletePreviewOptions{
-					PreviewOptions: preview.PreviewOptions{
-						PromoteOptions: promote.PromoteOptions{
-							CommonOptions: o.CommonOptions,
-						},
-					},
-				}
-				err = deleteOpts.DeletePreview(e.Name)
-				if err != nil {
-					return fmt.Errorf("failed to delete preview environment %s: %v\n", e.Name, err)
-				}
-			}
+	var errs []error
+	for i := range envs.Items {
+		e := &envs.Items[i]
+		if e.Spec.Kind != v1.EnvironmentKindTypePreview {
+			continue
+		}
+		previewFound = true
+		reason, err := o.expiryReason(e, now, defaults)
+		if err != nil {
+			log.Logger().Warnf("Can not check whether preview environment %s has expired, skipping: %s", e.Name, err)
+			continue
+		}
+		if reason == "" {
+			continue
+		}
+		if o.DryRun {
+			log.Logger().Infof("Would delete preview environment %s as %s", util.ColorInfo(e.Name), reason)
+			continue
+		}
+		log.Logger().Infof("Deleting preview environment %s as %s", util.ColorInfo(e.Name), reason)
+		err = o.deletePreview(e, defaults)
+		if err != nil {
+			errs = append(errs, err)
 		}
 	}
 	if !previewFound {
 		log.Logger().Debug("no preview environments found")
 	}
+	return errorutil.CombineErrors(errs...)
+}
+
+// expiryReason returns why the preview environment should be deleted or an empty string if it should be kept
+func (o *GCPreviewsOptions) expiryReason(e *v1.Environment, now time.Time, defaults *config.PreviewsConfig) (string, error) {
+	reason, err := kube.PreviewExpiry(e, now, defaults)
+	if err != nil || reason != "" {
+		return reason, err
+	}
+
+	gitInfo, err := gits.ParseGitURL(e.Spec.Source.URL)
+	if err != nil {
+		return "", err
+	}
+	prNum, err := strconv.Atoi(e.Spec.PreviewGitSpec.Name)
+	if err != nil {
+		log.Logger().Warn("Unable to convert PR " + e.Spec.PreviewGitSpec.Name + " to a number")
+		return "", nil
+	}
+	// we need pull request info to include
+	authConfigSvc, err := o.GitAuthConfigService()
+	if err != nil {
+		return "", err
+	}
+
+	gitKind, err := o.GitServerKind(gitInfo)
+	if err != nil {
+		return "", err
+	}
+
+	ghOwner, err := o.GetGitHubAppOwner(gitInfo)
+	if err != nil {
+		return "", err
+	}
+	gitProvider, err := gitInfo.CreateProvider(o.InCluster(), authConfigSvc, gitKind, ghOwner, o.Git(), o.BatchMode, o.GetIOFileHandles())
+	if err != nil {
+		return "", err
+	}
+	pullRequest, err := gitProvider.GetPullRequest(gitInfo.Organisation, gitInfo, prNum)
+	if err != nil {
+		return "", errors.Wrapf(err, "getting pull request %s", e.Spec.PreviewGitSpec.Name)
+	}
+	if pullRequest.State == nil {
+		return "", nil
+	}
+
+	lowerState := strings.ToLower(*pullRequest.State)
+	if strings.HasPrefix(lowerState, "clos") || strings.HasPrefix(lowerState, "merged") || strings.HasPrefix(lowerState, "superseded") || strings.HasPrefix(lowerState, "declined") {
+		return fmt.Sprintf("its pull request is %s", lowerState), nil
+	}
+	return "", nil
+}
+
+// deletePreview deletes the ingresses of the preview environment before its namespace so that their DNS records are
+// removed, then the preview environment and optionally the tag of its image
+func (o *GCPreviewsOptions) deletePreview(e *v1.Environment, defaults *config.PreviewsConfig) error {
+	if e.Spec.Namespace != "" {
+		kubeClient, _, err := o.KubeClientForEnvironment(e)
+		if err != nil {
+			return err
+		}
+		err = kubeClient.ExtensionsV1beta1().Ingresses(e.Spec.Namespace).DeleteCollection(&metav1.DeleteOptions{}, metav1.ListOptions{})
+		if err != nil {
+			log.Logger().Warnf("failed to delete the ingresses of preview environment %s: %s", e.Name, err)
+		}
+	}
+
+	deleteOpts := deletecmd.DeletePreviewOptions{
+		PreviewOptions: preview.PreviewOptions{
+			PromoteOptions: promote.PromoteOptions{
+				CommonOptions: o.CommonOptions,
+			},
+		},
+	}
+	err := deleteOpts.DeletePreview(e.Name)
+	if err != nil {
+		return fmt.Errorf("failed to delete preview environment %s: %v", e.Name, err)
+	}
+
+	if defaults.DeleteImages {
+		err = o.DeletePreviewImage(e.Annotations[kube.AnnotationPreviewImage])
+		if err != nil {
+			return errors.Wrapf(err, "deleting the image of preview environment %s", e.Name)
+		}
+	}
 	return nil
 }
@@ -77,6 +77,7 @@ func NewCmdGet(commonOpts *opts.CommonOptions) *cobra.Command {
 	cmd.AddCommand(NewCmdGetAddon(commonOpts))
 	cmd.AddCommand(NewCmdGetApps(commonOpts))
 	cmd.AddCommand(NewCmdGetApplications(commonOpts))
+	cmd.AddCommand(NewCmdGetAudit(commonOpts))
 	cmd.AddCommand(NewCmdGetBranchPattern(commonOpts))
 	cmd.AddCommand(NewCmdGetBuild(commonOpts))
 	cmd.AddCommand(NewCmdGetBuildPack(commonOpts))
@@ -92,12 +93,17 @@ func NewCmdGet(commonOpts *opts.CommonOptions) *cobra.Command {
 	cmd.AddCommand(NewCmdGetIssues(commonOpts))
 	cmd.AddCommand(NewCmdGetLimits(commonOpts))
 	cmd.AddCommand(NewCmdGetLang(commonOpts))
+	cmd.AddCommand(NewCmdGetMirrors(commonOpts))
 	cmd.AddCommand(NewCmdGetPipeline(commonOpts))
 	cmd.AddCommand(NewCmdGetPostPreviewJob(commonOpts))
 	cmd.AddCommand(NewCmdGetPreview(commonOpts))
+	cmd.AddCommand(NewCmdGetPromotions(commonOpts))
 	cmd.AddCommand(NewCmdGetQuickstartLocation(commonOpts))
 	cmd.AddCommand(NewCmdGetQuickstarts(commonOpts))
 	cmd.AddCommand(NewCmdGetRelease(commonOpts))
+	cmd.AddCommand(NewCmdGetRequirements(commonOpts))
+	cmd.AddCommand(NewCmdGetRollbacks(commonOpts))
+	cmd.AddCommand(NewCmdGetSecrets(commonOpts))
 	cmd.AddCommand(NewCmdGetStorage(commonOpts))
 	cmd.AddCommand(NewCmdGetTeam(commonOpts))
 	cmd.AddCommand(NewCmdGetTeamRole(commonOpts))
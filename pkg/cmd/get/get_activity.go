@@ -298,6 +298,9 @@ func describePromotePullRequest(promote *v1.PromotePullRequestStep) string {
 	if promote.MergeCommitSHA != "" {
 		description += " Merge SHA: " + util.ColorInfo(promote.MergeCommitSHA)
 	}
+	if promote.Approval != nil {
+		description += " " + describePromotionApproval(promote.Approval)
+	}
 	return description
 }
 
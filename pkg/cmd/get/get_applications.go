@@ -7,6 +7,7 @@ import (
 
 	"github.com/jenkins-x/jx/v2/pkg/applications"
 	"github.com/jenkins-x/jx/v2/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/v2/pkg/flagger"
 
 	"github.com/jenkins-x/jx/v2/pkg/table"
 	"github.com/pkg/errors"
@@ -31,6 +32,7 @@ type GetApplicationsOptions struct {
 	HideUrl     bool
 	HidePod     bool
 	Previews    bool
+	Local       bool
 }
 
 // Applications is a map indexed by the application name then the environment name
@@ -53,6 +55,8 @@ type ApplicationEnvironmentInfo struct {
 var (
 	getVersionLong = templates.LongDesc(`
 		Display applications across environments.
+
+		The applications of environments running on remote clusters declared in the requirements are read via the kube contexts of the clusters.
 `)
 
 	getVersionExample = templates.Examples(`
@@ -73,6 +77,9 @@ var (
 
 		# List applications just showing the versions (hiding urls and pod counts)
 		jx get applications -u -p
+
+		# List applications only in the environments of the current cluster
+		jx get applications --local
 	`)
 )
 
@@ -99,6 +106,7 @@ func NewCmdGetApplications(commonOpts *opts.CommonOptions) *cobra.Command {
 	cmd.Flags().BoolVarP(&options.Previews, "preview", "w", false, "Show preview environments only")
 	cmd.Flags().StringVarP(&options.Environment, "env", "e", "", "Filter applications in the given environment")
 	cmd.Flags().StringVarP(&options.Namespace, "namespace", "n", "", "Filter applications in the given namespace")
+	cmd.Flags().BoolVarP(&options.Local, "local", "", false, "Only read the applications of environments from the current cluster rather than the remote clusters declared in the requirements")
 	return cmd
 }
 
@@ -109,7 +117,22 @@ func (o *GetApplicationsOptions) Run() error {
 		return nil
 	}
 
-	list, err := applications.GetApplications(o.CommonOptions.GetFactory())
+	kubeClient, err := o.KubeClient()
+	if err != nil {
+		return err
+	}
+	kubeClients := map[string]kubernetes.Interface{}
+	var kubeClientFn applications.KubeClientFn
+	if !o.Local {
+		kubeClientFn = func(env *v1.Environment) (kubernetes.Interface, string, error) {
+			envClient, ns, err := o.KubeClientForEnvironment(env)
+			if err == nil {
+				kubeClients[env.Name] = envClient
+			}
+			return envClient, ns, err
+		}
+	}
+	list, err := applications.GetApplicationsWithKubeClients(o.CommonOptions.GetFactory(), kubeClientFn)
 	if err != nil {
 		return errors.Wrap(err, "fetching applications")
 	}
@@ -118,18 +141,45 @@ func (o *GetApplicationsOptions) Run() error {
 		return nil
 	}
 
-	kubeClient, err := o.KubeClient()
-	if err != nil {
-		return err
-	}
-	table := o.generateTable(kubeClient, list)
+	canaries := o.canaryStatuses(list)
+	table := o.generateTable(kubeClient, kubeClients, list, canaries)
 	table.Render()
 
 	return nil
 }
 
-func (o *GetApplicationsOptions) generateTable(kubeClient kubernetes.Interface, list applications.List) table.Table {
-	table := o.generateTableHeaders(list)
+// canaryStatuses returns the statuses of the Canaries of the environments whose applications are rolled out
+// progressively, indexed by the environment name and then the deployment name
+func (o *GetApplicationsOptions) canaryStatuses(list applications.List) map[string]map[string]*flagger.CanaryStatus {
+	answer := map[string]map[string]*flagger.CanaryStatus{}
+	progressiveDelivery := o.ProgressiveDeliveryConfigFromTeamSettings()
+	if !progressiveDelivery.IsEnabled() {
+		return answer
+	}
+	dynamicClient, _, err := o.GetFactory().CreateDynamicClient()
+	if err != nil {
+		log.Logger().Warnf("failed to create the dynamic client to get the Canaries: %s", err)
+		return answer
+	}
+	for name, env := range list.Environments() {
+		if !progressiveDelivery.IsEnabledForEnvironment(name) {
+			continue
+		}
+		if cluster, _ := o.RemoteClusterForEnvironment(&env); cluster != nil {
+			// the Canaries of remote clusters are not visible to the dynamic client of the current cluster
+			continue
+		}
+		statuses, err := flagger.GetCanaryStatuses(dynamicClient, env.Spec.Namespace)
+		if err != nil {
+			log.Logger().Warnf("failed to get the Canaries of environment %s: %s", name, err)
+		}
+		answer[name] = statuses
+	}
+	return answer
+}
+
+func (o *GetApplicationsOptions) generateTable(kubeClient kubernetes.Interface, kubeClients map[string]kubernetes.Interface, list applications.List, canaries map[string]map[string]*flagger.CanaryStatus) table.Table {
+	table := o.generateTableHeaders(list, canaries)
 
 	for _, a := range list.Items {
 		row := []string{}
@@ -140,6 +190,10 @@ func (o *GetApplicationsOptions) generateTable(kubeClient kubernetes.Interface,
 			for _, k := range o.sortedKeys(list.Environments()) {
 
 				if ae, ok := a.Environments[k]; ok {
+					envClient := kubeClients[k]
+					if envClient == nil {
+						envClient = kubeClient
+					}
 					for _, d := range ae.Deployments {
 						name = kube.GetAppName(d.Deployment.Name, k)
 						if ae.Environment.Spec.Kind == v1.EnvironmentKindTypeEdit {
@@ -154,7 +208,14 @@ func (o *GetApplicationsOptions) generateTable(kubeClient kubernetes.Interface,
 							row = append(row, d.Pods())
 						}
 						if !o.HideUrl {
-							row = append(row, d.URL(kubeClient, a))
+							row = append(row, d.URL(envClient, a))
+						}
+						if statuses, ok := canaries[k]; ok {
+							canary := ""
+							if status := statuses[d.Deployment.Name]; status != nil {
+								canary = status.String()
+							}
+							row = append(row, canary)
 						}
 					}
 				} else {
@@ -167,6 +228,9 @@ func (o *GetApplicationsOptions) generateTable(kubeClient kubernetes.Interface,
 					if !o.HideUrl {
 						row = append(row, "")
 					}
+					if _, ok := canaries[k]; ok {
+						row = append(row, "")
+					}
 				}
 			}
 			row = append([]string{name}, row...)
@@ -197,7 +261,7 @@ func (o *GetApplicationsOptions) sortedKeys(envs map[string]v1.Environment) []st
 	return keys
 }
 
-func (o *GetApplicationsOptions) generateTableHeaders(list applications.List) table.Table {
+func (o *GetApplicationsOptions) generateTableHeaders(list applications.List, canaries map[string]map[string]*flagger.CanaryStatus) table.Table {
 	t := o.CreateTable()
 	title := "APPLICATION"
 	titles := []string{title}
@@ -213,6 +277,9 @@ func (o *GetApplicationsOptions) generateTableHeaders(list applications.List) ta
 		if !o.HideUrl {
 			titles = append(titles, "URL")
 		}
+		if _, ok := canaries[k]; ok {
+			titles = append(titles, "CANARY")
+		}
 	}
 	t.AddRow(titles...)
 	return t
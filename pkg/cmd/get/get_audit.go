@@ -0,0 +1,40 @@
+package get
+
+import (
+	"github.com/jenkins-x/jx/v2/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
+	"github.com/spf13/cobra"
+)
+
+// GetAuditOptions the command line options
+type GetAuditOptions struct {
+	GetOptions
+}
+
+// NewCmdGetAudit creates the command object
+func NewCmdGetAudit(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &GetAuditOptions{
+		GetOptions: GetOptions{
+			CommonOptions: commonOpts,
+		},
+	}
+
+	cmd := &cobra.Command{
+		Use:   "audit",
+		Short: "Display the audit trail of the changes jx generates",
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+
+	cmd.AddCommand(NewCmdGetAuditPullRequests(commonOpts))
+	return cmd
+}
+
+// Run implements this command
+func (o *GetAuditOptions) Run() error {
+	return o.Cmd.Help()
+}
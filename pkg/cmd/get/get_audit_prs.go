@@ -0,0 +1,204 @@
+package get
+
+import (
+	"sort"
+
+	"github.com/jenkins-x/jx/v2/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/v2/pkg/gits"
+	"github.com/jenkins-x/jx/v2/pkg/kube"
+	"github.com/jenkins-x/jx/v2/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// GetAuditPullRequestsOptions the command line options
+type GetAuditPullRequestsOptions struct {
+	GetOptions
+
+	GitURLs []string
+	Commits int
+}
+
+// AuditedChange a change jx generated along with its provenance
+type AuditedChange struct {
+	// Repository the git repository of the change
+	Repository string `json:"repository"`
+	// URL the URL of the pull request or commit
+	URL string `json:"url,omitempty"`
+	// Title the title of the pull request or the subject of the commit
+	Title string `json:"title,omitempty"`
+	// State open for an open pull request or merged for a commit on the default branch
+	State string `json:"state"`
+	// SHA the SHA of the commit
+	SHA string `json:"sha,omitempty"`
+	// Provenance how jx generated the change
+	Provenance *gits.Provenance `json:"provenance"`
+}
+
+var (
+	getAuditPullRequestsLong = templates.LongDesc(`
+		Display the provenance of the Pull Requests and commits jx generated, such as promotions, upgrades and
+		dependency updates.
+
+		The open Pull Requests and the recent commits of the default branch of each repository are searched. By default
+		the git repositories of the environments of the current team are searched.
+`)
+
+	getAuditPullRequestsExample = templates.Examples(`
+		# List the generated changes to the environment repositories
+		jx get audit prs
+
+		# List the generated changes to a repository as YAML
+		jx get audit prs --repo https://github.com/myorg/myapp.git -o yaml
+	`)
+)
+
+// NewCmdGetAuditPullRequests creates the command object
+func NewCmdGetAuditPullRequests(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &GetAuditPullRequestsOptions{
+		GetOptions: GetOptions{
+			CommonOptions: commonOpts,
+		},
+	}
+
+	cmd := &cobra.Command{
+		Use:     "prs",
+		Short:   "Display the provenance of the Pull Requests and commits jx generated",
+		Aliases: []string{"pr", "pullrequests"},
+		Long:    getAuditPullRequestsLong,
+		Example: getAuditPullRequestsExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+	options.AddGetFlags(cmd)
+	cmd.Flags().StringArrayVarP(&options.GitURLs, "repo", "r", []string{}, "The git repositories to search. Defaults to the environment repositories of the team")
+	cmd.Flags().IntVarP(&options.Commits, "commits", "", 50, "The number of recent commits of the default branch to search, 0 searches only the open Pull Requests")
+	return cmd
+}
+
+// Run implements this command
+func (o *GetAuditPullRequestsOptions) Run() error {
+	gitURLs := o.GitURLs
+	if len(gitURLs) == 0 {
+		var err error
+		gitURLs, err = o.environmentGitURLs()
+		if err != nil {
+			return err
+		}
+	}
+	changes := []*AuditedChange{}
+	for _, gitURL := range gitURLs {
+		repoChanges, err := o.auditRepository(gitURL)
+		if err != nil {
+			return err
+		}
+		changes = append(changes, repoChanges...)
+	}
+
+	if o.Output != "" {
+		return o.renderResult(changes, o.Output)
+	}
+	if len(changes) == 0 {
+		return outputEmptyListWarning(o.Out)
+	}
+	table := o.CreateTable()
+	table.AddRow("REPOSITORY", "CHANGE", "STATE", "KIND", "JX VERSION", "VERSION STREAM REF", "TRIGGERED BY", "PIPELINE")
+	for _, change := range changes {
+		p := change.Provenance
+		table.AddRow(change.Repository, change.URL, change.State, p.Kind, p.ToolVersion, p.VersionStreamRef, p.TriggeredBy, p.Pipeline)
+	}
+	table.Render()
+	return nil
+}
+
+// auditRepository returns the changes with provenance in the open pull requests and recent commits of the repository
+func (o *GetAuditPullRequestsOptions) auditRepository(gitURL string) ([]*AuditedChange, error) {
+	provider, gitInfo, err := o.CreateGitProviderForURLWithoutKind(gitURL)
+	if err != nil {
+		return nil, errors.Wrapf(err, "creating git provider for %s", gitURL)
+	}
+	repository := gitInfo.Organisation + "/" + gitInfo.Name
+	answer := []*AuditedChange{}
+
+	prs, err := provider.ListOpenPullRequests(gitInfo.Organisation, gitInfo.Name)
+	if err != nil {
+		return nil, errors.Wrapf(err, "listing the open pull requests of %s", gitURL)
+	}
+	sort.SliceStable(prs, func(i, j int) bool {
+		return util.DereferenceInt(prs[j].Number) < util.DereferenceInt(prs[i].Number)
+	})
+	for _, pr := range prs {
+		provenance, err := gits.ParseProvenance(pr.Body)
+		if err != nil {
+			log.Logger().Warnf("ignoring the provenance of pull request %s: %s", pr.URL, err)
+			continue
+		}
+		if provenance == nil {
+			continue
+		}
+		answer = append(answer, &AuditedChange{
+			Repository: repository,
+			URL:        pr.URL,
+			Title:      pr.Title,
+			State:      "open",
+			SHA:        pr.LastCommitSha,
+			Provenance: provenance,
+		})
+	}
+
+	if o.Commits <= 0 {
+		return answer, nil
+	}
+	commits, err := provider.ListCommits(gitInfo.Organisation, gitInfo.Name, &gits.ListCommitsArguments{
+		Page:    1,
+		PerPage: o.Commits,
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "listing the commits of %s", gitURL)
+	}
+	for _, commit := range commits {
+		provenance := gits.ParseProvenanceTrailers(commit.Message)
+		if provenance == nil {
+			continue
+		}
+		answer = append(answer, &AuditedChange{
+			Repository: repository,
+			URL:        commit.URL,
+			Title:      commit.Subject(),
+			State:      "merged",
+			SHA:        commit.SHA,
+			Provenance: provenance,
+		})
+	}
+	return answer, nil
+}
+
+// environmentGitURLs returns the git repositories of the environments of the team
+func (o *GetAuditPullRequestsOptions) environmentGitURLs() ([]string, error) {
+	jxClient, ns, err := o.JXClientAndDevNamespace()
+	if err != nil {
+		return nil, err
+	}
+	envMap, names, err := kube.GetEnvironments(jxClient, ns)
+	if err != nil {
+		return nil, errors.Wrapf(err, "listing the environments in %s", ns)
+	}
+	answer := []string{}
+	for _, name := range names {
+		env := envMap[name]
+		if env != nil && env.Spec.Source.URL != "" && util.StringArrayIndex(answer, env.Spec.Source.URL) < 0 {
+			answer = append(answer, env.Spec.Source.URL)
+		}
+	}
+	if len(answer) == 0 {
+		return nil, errors.New("no environments have git repositories, please specify the repositories with --repo")
+	}
+	return answer, nil
+}
@@ -1,6 +1,7 @@
 package get
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
 	"strings"
@@ -14,8 +15,10 @@ import (
 	"github.com/jenkins-x/jx/v2/pkg/logs"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 
+	"github.com/acarl005/stripansi"
 	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
 	"github.com/jenkins-x/jx/v2/pkg/cmd/templates"
 	"github.com/jenkins-x/jx/v2/pkg/log"
@@ -34,17 +37,36 @@ type GetBuildLogsOptions struct {
 	WaitForPipelineDuration time.Duration
 	TektonLogger            *logs.TektonLogger
 	FailIfPodFails          bool
+	Multiplex               bool
+	Step                    string
+	Since                   time.Duration
 }
 
 // CLILogWriter is an implementation of logs.LogWriter that will show logs in the standard output
 type CLILogWriter struct {
 	*opts.CommonOptions
+
+	// Prefix prefixes each line with the stage and step which logged it
+	Prefix bool
+	// JSON writes each line as a JSON object with the stage and step which logged it
+	JSON bool
+}
+
+// jsonLogLine is a log line written by the CLILogWriter in JSON
+type jsonLogLine struct {
+	Stage string `json:"stage,omitempty"`
+	Step  string `json:"step,omitempty"`
+	Line  string `json:"line"`
 }
 
 var (
 	get_build_log_long = templates.LongDesc(`
 		Display a build log
 
+		The logs of parallel stages are streamed at the same time, with each line prefixed by its stage and step. Use
+		--multiplex=false to stream one stage after another instead.
+
+		If the pods of the build have been garbage collected the log is read from the long term storage bucket.
 `)
 
 	get_build_log_example = templates.Examples(`
@@ -65,6 +87,12 @@ var (
 
 		# View the build logs for a specific tekton build pod
 		jx get build log --pod my-pod-name
+
+		# View the logs of the step 'build' of the last 10 minutes of the builds of the repo cheese
+		jx get build log --repo cheese --step build --since 10m
+
+		# View the build logs as JSON, one object per line with the stage and step of the line
+		jx get build log --repo cheese --output json
 	`)
 )
 
@@ -103,6 +131,10 @@ func NewCmdGetBuildLogs(commonOpts *opts.CommonOptions) *cobra.Command {
 	cmd.Flags().StringVarP(&options.BuildFilter.GitURL, "giturl", "g", "", "The git URL to filter on. If you specify a link to a github repository or PR we can filter the query of build pods accordingly")
 	cmd.Flags().StringVarP(&options.BuildFilter.Context, "context", "", "", "Filters the context of the build")
 	cmd.Flags().BoolVarP(&options.CurrentFolder, "current", "c", false, "Display logs using current folder as repo name, and parent folder as owner")
+	cmd.Flags().BoolVarP(&options.Multiplex, "multiplex", "", true, "Streams the logs of parallel stages at the same time, prefixing each line with its stage and step")
+	cmd.Flags().StringVarP(&options.Step, "step", "", "", "Only displays the logs of the steps with this name")
+	cmd.Flags().DurationVarP(&options.Since, "since", "", 0, "Only displays the logs of running builds newer than this duration such as 5m or 1h")
+	cmd.Flags().StringVarP(&options.Output, "output", "", "", "The output format of the log lines, either 'json' or plain text if not specified")
 	options.AddBaseFlags(cmd)
 
 	return cmd
@@ -114,6 +146,9 @@ func (o *GetBuildLogsOptions) Run() error {
 	if err != nil {
 		return err
 	}
+	if o.Output != "" && o.Output != "json" {
+		return util.InvalidOptionf("output", o.Output, "the only supported output format is 'json'")
+	}
 	jxClient, ns, err := o.JXClientAndDevNamespace()
 	if err != nil {
 		return err
@@ -162,8 +197,13 @@ func (o *GetBuildLogsOptions) getProwBuildLog(kubeClient kubernetes.Interface, t
 			Namespace:    ns,
 			LogWriter: &CLILogWriter{
 				CommonOptions: o.CommonOptions,
+				Prefix:        o.Multiplex,
+				JSON:          o.Output == "json",
 			},
 			FailIfPodFails: o.FailIfPodFails,
+			Multiplex:      o.Multiplex,
+			Step:           o.Step,
+			Since:          o.Since,
 		}
 	}
 	var waitableCondition bool
@@ -237,7 +277,25 @@ func (o *GetBuildLogsOptions) getTektonLogs(kubeClient kubernetes.Interface, tek
 
 	log.Logger().Infof("Build logs for %s", util.ColorInfo(name))
 	name = strings.TrimSuffix(name, " ")
-	return false, o.TektonLogger.GetRunningBuildLogs(pa, name, false)
+	err = o.TektonLogger.GetRunningBuildLogs(pa, name, false)
+	if err != logs.ErrNoBuildPods {
+		return false, err
+	}
+
+	// the pods may have been garbage collected after the log was stored in the bucket
+	paName := pa.Name
+	pa, err = jxClient.JenkinsV1().PipelineActivities(pa.Namespace).Get(paName, metav1.GetOptions{})
+	if err != nil {
+		return false, errors.Wrapf(err, "reloading PipelineActivity %s", paName)
+	}
+	if pa.Spec.BuildLogsURL == "" {
+		return false, logs.ErrNoBuildPods
+	}
+	authSvc, err := o.GitAuthConfigService()
+	if err != nil {
+		return false, err
+	}
+	return false, o.TektonLogger.StreamPipelinePersistentLogs(pa.Spec.BuildLogsURL, jxClient, ns, authSvc)
 }
 
 // StreamLog implementation of LogWriter.StreamLog for CLILogWriter, this implementation will tail logs for the provided pod /container through the defined logger
@@ -248,13 +306,45 @@ func (o *CLILogWriter) StreamLog(lch <-chan logs.LogLine, ech <-chan error) erro
 			if !ok {
 				return nil
 			}
-			fmt.Println(l.Line)
+			err := o.writeLine(l)
+			if err != nil {
+				return err
+			}
 		case err := <-ech:
 			return err
 		}
 	}
 }
 
+// writeLine writes the line as plain text, prefixed with its stage and step if required, or as JSON
+func (o *CLILogWriter) writeLine(l logs.LogLine) error {
+	step := strings.TrimPrefix(l.Step, "step-")
+	if o.JSON {
+		data, err := json.Marshal(&jsonLogLine{
+			Stage: l.Stage,
+			Step:  step,
+			Line:  stripansi.Strip(strings.TrimLeft(l.Line, "\n")),
+		})
+		if err != nil {
+			return errors.Wrap(err, "marshalling log line to JSON")
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+	if o.Prefix && l.Stage != "" {
+		prefix := l.Stage
+		if step != "" {
+			prefix += "/" + step
+		}
+		for _, line := range strings.Split(strings.TrimLeft(l.Line, "\n"), "\n") {
+			fmt.Printf("[%s] %s\n", util.ColorInfo(prefix), line)
+		}
+		return nil
+	}
+	fmt.Println(l.Line)
+	return nil
+}
+
 // WriteLog implementation of LogWriter.WriteLog for CLILogWriter, this implementation will write the provided log line through the defined logger
 func (o *CLILogWriter) WriteLog(logLine logs.LogLine, lch chan<- logs.LogLine) error {
 	lch <- logLine
@@ -0,0 +1,65 @@
+package get
+
+import (
+	"github.com/jenkins-x/jx/v2/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/templates"
+	"github.com/spf13/cobra"
+)
+
+// GetMirrorsOptions containers the CLI options
+type GetMirrorsOptions struct {
+	GetOptions
+}
+
+var (
+	getMirrorsLong = templates.LongDesc(`
+		Display the mirrors git repositories are cloned from for the current Team.
+`)
+
+	getMirrorsExample = templates.Examples(`
+		# List all the git mirrors
+		jx get mirrors
+	`)
+)
+
+// NewCmdGetMirrors creates the new command for: jx get mirrors
+func NewCmdGetMirrors(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &GetMirrorsOptions{
+		GetOptions: GetOptions{
+			CommonOptions: commonOpts,
+		},
+	}
+	cmd := &cobra.Command{
+		Use:     "mirrors",
+		Short:   "Display the mirrors git repositories are cloned from",
+		Aliases: []string{"mirror", "gitmirrors"},
+		Long:    getMirrorsLong,
+		Example: getMirrorsExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+
+	options.AddGetFlags(cmd)
+	return cmd
+}
+
+// Run implements this command
+func (o *GetMirrorsOptions) Run() error {
+	settings, err := o.TeamSettings()
+	if err != nil {
+		return err
+	}
+
+	table := o.CreateTable()
+	table.AddRow("NAME", "ORIGIN", "MIRROR")
+	for _, mirror := range settings.GitMirrors {
+		table.AddRow(mirror.Name, mirror.OriginURL, mirror.MirrorURL)
+	}
+	table.Render()
+	return nil
+}
@@ -2,6 +2,7 @@ package get
 
 import (
 	"fmt"
+	"strconv"
 
 	"github.com/jenkins-x/jx/v2/pkg/cmd/helper"
 	"github.com/jenkins-x/jx/v2/pkg/kube/naming"
@@ -9,6 +10,9 @@ import (
 	v1 "github.com/jenkins-x/jx/v2/pkg/apis/jenkins.io/v1"
 	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
 	"github.com/jenkins-x/jx/v2/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/v2/pkg/kube"
+	"github.com/jenkins-x/jx/v2/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/previews"
 	"github.com/spf13/cobra"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
@@ -22,7 +26,11 @@ type GetPreviewOptions struct {
 
 var (
 	getPreviewLong = templates.LongDesc(`
-		Display one or more preview environments.
+		Display one or more preview environments along with the remote cluster they run in, if any.
+
+		If the team settings define a previews budget then the number of preview environments of the team and each
+		repository is displayed against its limit, along with the resources used by each preview namespace against
+		its quota.
 ` + helper.SeeAlsoText("jx get env"))
 
 	getPreviewExample = templates.Examples(`
@@ -70,7 +78,68 @@ func (o *GetPreviewOptions) Run() error {
 		return o.CurrentPreviewUrl()
 	}
 	o.PreviewOnly = true
-	return o.GetEnvOptions.Run()
+	err := o.GetEnvOptions.Run()
+	if err != nil || o.Output != "" || len(o.Args) > 0 {
+		return err
+	}
+	return o.renderBudget()
+}
+
+// renderBudget displays the number of preview environments of the team and each repository and the resources used by
+// each preview namespace against the previews budget
+func (o *GetPreviewOptions) renderBudget() error {
+	budget := o.PreviewsConfigFromTeamSettings().Budget
+	if budget == nil {
+		return nil
+	}
+	client, ns, err := o.JXClientAndDevNamespace()
+	if err != nil {
+		return err
+	}
+	envList, err := client.JenkinsV1().Environments(ns).List(metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+
+	log.Blank()
+	table := o.CreateTable()
+	table.AddRow("BUDGET", "PREVIEWS", "LIMIT")
+	for _, u := range previews.Usage(envList.Items, budget) {
+		limit := "unlimited"
+		if u.Limit > 0 {
+			limit = strconv.Itoa(u.Limit)
+		}
+		table.AddRow(u.Scope, strconv.Itoa(u.Previews), limit)
+	}
+	table.Render()
+
+	environments := o.filterEnvironments(envList.Items)
+	kube.SortEnvironments(environments)
+	table = o.CreateTable()
+	table.AddRow("NAMESPACE", "QUOTA USED/HARD")
+	found := false
+	for i := range environments {
+		env := &environments[i]
+		kubeClient, _, err := o.KubeClientForEnvironment(env)
+		if err != nil {
+			log.Logger().Warnf("%s", err)
+			continue
+		}
+		usage, err := previews.QuotaUsage(kubeClient, env.Spec.Namespace)
+		if err != nil {
+			log.Logger().Warnf("%s", err)
+			continue
+		}
+		if usage != "" {
+			table.AddRow(env.Spec.Namespace, usage)
+			found = true
+		}
+	}
+	if found {
+		log.Blank()
+		table.Render()
+	}
+	return nil
 }
 
 func (o *GetPreviewOptions) CurrentPreviewUrl() error {
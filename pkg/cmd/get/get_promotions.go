@@ -0,0 +1,144 @@
+package get
+
+import (
+	"fmt"
+	"strings"
+
+	v1 "github.com/jenkins-x/jx/v2/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/v2/pkg/kube"
+	"github.com/jenkins-x/jx/v2/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// GetPromotionsOptions containers the CLI options
+type GetPromotionsOptions struct {
+	*opts.CommonOptions
+
+	Filter      string
+	Environment string
+	Pending     bool
+}
+
+var (
+	getPromotionsLong = templates.LongDesc(`
+		Display the promotions of applications to environments, including the promotions waiting for approval.
+
+		Promotions to environments whose requirements list approvers only merge once enough approvers comment '/approve promote' on their Pull Requests.
+`)
+
+	getPromotionsExample = templates.Examples(`
+		# List the promotions of the applications of the current team
+		jx get promotions
+
+		# List the promotions waiting for approval
+		jx get promotions --pending
+
+		# List the promotions of application 'foo' to production
+		jx get promotions -f foo -e production
+	`)
+)
+
+// NewCmdGetPromotions creates the new command for: jx get promotions
+func NewCmdGetPromotions(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &GetPromotionsOptions{
+		CommonOptions: commonOpts,
+	}
+	cmd := &cobra.Command{
+		Use:     "promotions",
+		Short:   "Display the promotions of applications to environments and their approvals",
+		Aliases: []string{"promotion", "promote"},
+		Long:    getPromotionsLong,
+		Example: getPromotionsExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+	cmd.Flags().StringVarP(&options.Filter, "filter", "f", "", "Text to filter the application names")
+	cmd.Flags().StringVarP(&options.Environment, "env", "e", "", "The environment to filter on")
+	cmd.Flags().BoolVarP(&options.Pending, "pending", "p", false, "Only display the promotions waiting for approval")
+	return cmd
+}
+
+// Run implements this command
+func (o *GetPromotionsOptions) Run() error {
+	client, ns, err := o.JXClientAndDevNamespace()
+	if err != nil {
+		return err
+	}
+	list, err := client.JenkinsV1().PipelineActivities(ns).List(metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	kube.SortActivities(list.Items)
+
+	table := o.CreateTable()
+	table.AddRow("APPLICATION", "VERSION", "ENVIRONMENT", "STARTED AGO", "STATUS", "APPROVAL", "PULL REQUEST")
+	count := 0
+	for i := range list.Items {
+		activity := &list.Items[i]
+		app := activity.RepositoryName()
+		if o.Filter != "" && !strings.Contains(app, o.Filter) {
+			continue
+		}
+		for _, step := range activity.Spec.Steps {
+			promote := step.Promote
+			if promote == nil || !o.matches(promote) {
+				continue
+			}
+			status := promote.Status
+			approval := ""
+			pullRequestURL := ""
+			if pr := promote.PullRequest; pr != nil {
+				pullRequestURL = pr.PullRequestURL
+				approval = describePromotionApproval(pr.Approval)
+				if pr.Status == v1.ActivityStatusTypeWaitingForApproval {
+					status = pr.Status
+				}
+			}
+			table.AddRow(app, activity.Spec.Version, promote.Environment, timeToString(promote.StartedTimestamp),
+				statusString(status), approval, pullRequestURL)
+			count++
+		}
+	}
+	if count == 0 {
+		if o.Pending {
+			log.Logger().Infof("No promotions are waiting for approval in namespace %s", util.ColorInfo(ns))
+		} else {
+			log.Logger().Infof("No promotions found in namespace %s", util.ColorInfo(ns))
+		}
+		return nil
+	}
+	table.Render()
+	return nil
+}
+
+func (o *GetPromotionsOptions) matches(promote *v1.PromoteActivityStep) bool {
+	if o.Environment != "" && promote.Environment != o.Environment {
+		return false
+	}
+	if o.Pending {
+		pr := promote.PullRequest
+		return pr != nil && pr.Approval.IsPending() && !pr.Status.IsTerminated()
+	}
+	return true
+}
+
+// describePromotionApproval describes who approved a promotion or whose approval it is waiting for
+func describePromotionApproval(approval *v1.PromotionApproval) string {
+	if approval == nil {
+		return ""
+	}
+	if approval.IsApproved() {
+		return "Approved by " + util.ColorInfo(strings.Join(approval.ApprovedBy, ", "))
+	}
+	return fmt.Sprintf("Pending %d/%d from %s", len(approval.ApprovedBy), approval.RequiredApprovals,
+		util.ColorStatus(strings.Join(approval.Approvers, ", ")))
+}
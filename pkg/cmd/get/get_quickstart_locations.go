@@ -9,6 +9,7 @@ import (
 	"github.com/jenkins-x/jx/v2/pkg/cmd/templates"
 	"github.com/jenkins-x/jx/v2/pkg/gits"
 	"github.com/jenkins-x/jx/v2/pkg/kube"
+	"github.com/jenkins-x/jx/v2/pkg/quickstarts"
 	"github.com/spf13/cobra"
 )
 
@@ -73,14 +74,14 @@ func (o *GetQuickstartLocationOptions) Run() error {
 	}
 
 	table := o.CreateTable()
-	table.AddRow("GIT SERVER", "KIND", "OWNER", "INCLUDES", "EXCLUDES")
+	table.AddRow("NAME", "GIT SERVER", "KIND", "OWNER", "CATALOG", "INCLUDES", "EXCLUDES")
 
 	for _, location := range locations {
 		kind := location.GitKind
 		if kind == "" {
 			kind = gits.KindGitHub
 		}
-		table.AddRow(location.GitURL, kind, location.Owner, strings.Join(location.Includes, ", "), strings.Join(location.Excludes, ", "))
+		table.AddRow(quickstarts.CatalogName(&location), location.GitURL, kind, location.Owner, location.CatalogURL, strings.Join(location.Includes, ", "), strings.Join(location.Excludes, ", "))
 	}
 	table.Render()
 	return nil
@@ -23,13 +23,16 @@ type GetQuickstartsOptions struct {
 
 var (
 	getQuickstartsLong = templates.LongDesc(`
-		Display the available quickstarts
+		Display the available quickstarts of all the quickstart catalogs of the team
 
 `)
 
 	getQuickstartsExample = templates.Examples(`
 		# List all the available quickstarts
 		jx get quickstarts
+
+		# List the quickstarts of a catalog with a tag
+		jx get quickstarts --catalog platform --tag spring
 	`)
 )
 
@@ -62,6 +65,7 @@ func NewCmdGetQuickstarts(commonOpts *opts.CommonOptions) *cobra.Command {
 	cmd.Flags().StringVarP(&options.Filter.Language, "language", "l", "", "The language to filter on")
 	cmd.Flags().StringVarP(&options.Filter.Framework, "framework", "", "", "The framework to filter on")
 	cmd.Flags().BoolVarP(&options.Filter.AllowML, "machine-learning", "", false, "Allow machine-learning quickstarts in results")
+	cmd.Flags().StringVarP(&options.Filter.Catalog, "catalog", "", "", "The name of the quickstarts catalog to filter on")
 	cmd.Flags().BoolVarP(&options.ShortFormat, "short", "s", false, "return minimal details")
 	cmd.Flags().BoolVarP(&options.IgnoreTeam, "ignore-team", "", false, "ignores the quickstarts added to the Team Settings")
 
@@ -81,14 +85,14 @@ func (o *GetQuickstartsOptions) Run() error {
 	if o.ShortFormat {
 		table.AddRow("NAME")
 	} else {
-		table.AddRow("NAME", "OWNER", "VERSION", "LANGUAGE", "URL")
+		table.AddRow("NAME", "OWNER", "CATALOG", "VERSION", "LANGUAGE", "URL")
 	}
 
 	for _, qs := range filteredQuickstarts {
 		if o.ShortFormat {
 			table.AddRow(qs.Name)
 		} else {
-			table.AddRow(qs.Name, qs.Owner, qs.Version, qs.Language, qs.DownloadZipURL)
+			table.AddRow(qs.Name, qs.Owner, qs.Catalog, qs.Version, qs.Language, qs.DownloadZipURL)
 		}
 	}
 	table.Render()
@@ -0,0 +1,87 @@
+package get
+
+import (
+	"fmt"
+
+	"github.com/jenkins-x/jx/v2/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/v2/pkg/config"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/templates"
+)
+
+// GetRequirementsOptions the command line options
+type GetRequirementsOptions struct {
+	GetOptions
+
+	Dir     string
+	Profile string
+}
+
+var (
+	getRequirementsLong = templates.LongDesc(`
+		Displays the effective requirements used by 'jx boot'.
+
+		The effective requirements are the contents of the 'jx-requirements.yml' file with any defaults applied,
+		any requirements profiles merged in and any 'JX_REQUIREMENT_*' environment variable overrides.
+`)
+
+	getRequirementsExample = templates.Examples(`
+		# display the effective requirements as YAML
+		jx get requirements
+
+		# display the effective requirements as JSON
+		jx get requirements -o json
+
+		# display the effective requirements with the jx-requirements-gke.yml profile merged in
+		jx get requirements --profile gke
+	`)
+)
+
+// NewCmdGetRequirements creates the command
+func NewCmdGetRequirements(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &GetRequirementsOptions{
+		GetOptions: GetOptions{
+			CommonOptions: commonOpts,
+		},
+	}
+
+	cmd := &cobra.Command{
+		Use:     "requirements",
+		Short:   "Displays the effective requirements used by 'jx boot'",
+		Long:    getRequirementsLong,
+		Example: getRequirementsExample,
+		Aliases: []string{"req", "require", "requirement"},
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+	cmd.Flags().StringVarP(&options.Dir, "dir", "d", ".", fmt.Sprintf("the directory to search for the '%s' file", config.RequirementsConfigFileName))
+	cmd.Flags().StringVarP(&options.Output, "output", "o", "yaml", "The output format. Possible values: yaml, json")
+	cmd.Flags().StringVarP(&options.Profile, "profile", "", "", fmt.Sprintf("comma separated requirements profiles to merge over the requirements file. Defaults to $%s", config.RequirementsProfileEnvVar))
+	return cmd
+}
+
+// Run implements this command
+func (o *GetRequirementsOptions) Run() error {
+	options := o.RequirementsOptions()
+	if o.Profile != "" {
+		options.Profiles = config.ParseRequirementsProfiles(o.Profile)
+	}
+	requirements, fileName, err := config.LoadRequirementsConfigWithOptions(o.Dir, config.DefaultFailOnValidationError, options)
+	if err != nil {
+		return errors.Wrapf(err, "loading requirements from dir %s", o.Dir)
+	}
+	requirements.OverrideRequirementsFromEnvironment(nil)
+
+	err = o.renderResult(requirements, o.Output)
+	if err != nil {
+		return errors.Wrapf(err, "rendering requirements from %s", fileName)
+	}
+	return nil
+}
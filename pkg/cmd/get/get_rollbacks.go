@@ -0,0 +1,195 @@
+package get
+
+import (
+	"strings"
+
+	v1 "github.com/jenkins-x/jx/v2/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/v2/pkg/environments"
+	"github.com/jenkins-x/jx/v2/pkg/gits"
+	"github.com/jenkins-x/jx/v2/pkg/helm"
+	"github.com/jenkins-x/jx/v2/pkg/kube"
+	"github.com/jenkins-x/jx/v2/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// GetRollbacksOptions the command line options
+type GetRollbacksOptions struct {
+	GetOptions
+
+	Environment string
+	Commits     int
+}
+
+// Rollback a Pull Request or commit rolling back an application in an environment
+type Rollback struct {
+	// Environment the name of the environment
+	Environment string `json:"environment"`
+	// Title the title of the pull request or the subject of the commit
+	Title string `json:"title"`
+	// State open for an open pull request or merged for a commit on the default branch
+	State string `json:"state"`
+	// URL the URL of the pull request or commit
+	URL string `json:"url,omitempty"`
+	// SHA the SHA of the commit
+	SHA string `json:"sha,omitempty"`
+	// Provenance who triggered the rollback and how, if known
+	Provenance *gits.Provenance `json:"provenance,omitempty"`
+}
+
+var (
+	getRollbacksLong = templates.LongDesc(`
+		Display the rollbacks of the applications in the environments.
+
+		The rollbacks are the Pull Requests raised by 'jx rollback' or by 'jx promote' when a promotion fails its health
+		checks. The open Pull Requests and the recent commits of the environment requirements of each environment
+		repository are searched.
+`)
+
+	getRollbacksExample = templates.Examples(`
+		# List the rollbacks of all the environments
+		jx get rollbacks
+
+		# List the rollbacks of production as YAML
+		jx get rollbacks --env production -o yaml
+	`)
+)
+
+// NewCmdGetRollbacks creates the command object
+func NewCmdGetRollbacks(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &GetRollbacksOptions{
+		GetOptions: GetOptions{
+			CommonOptions: commonOpts,
+		},
+	}
+
+	cmd := &cobra.Command{
+		Use:     "rollbacks",
+		Short:   "Display the rollbacks of the applications in the environments",
+		Aliases: []string{"rollback"},
+		Long:    getRollbacksLong,
+		Example: getRollbacksExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+	options.AddGetFlags(cmd)
+	cmd.Flags().StringVarP(&options.Environment, opts.OptionEnvironment, "e", "", "The environment to display the rollbacks of. Defaults to all the permanent environments")
+	cmd.Flags().IntVarP(&options.Commits, "commits", "", 100, "The number of recent commits of the environment requirements to search, 0 searches only the open Pull Requests")
+	return cmd
+}
+
+// Run implements this command
+func (o *GetRollbacksOptions) Run() error {
+	jxClient, ns, err := o.JXClientAndDevNamespace()
+	if err != nil {
+		return err
+	}
+	envMap, names, err := kube.GetEnvironments(jxClient, ns)
+	if err != nil {
+		return errors.Wrapf(err, "listing the environments in %s", ns)
+	}
+	if o.Environment != "" && envMap[o.Environment] == nil {
+		return util.InvalidOption(opts.OptionEnvironment, o.Environment, names)
+	}
+	rollbacks := []*Rollback{}
+	for _, name := range names {
+		env := envMap[name]
+		if env.Spec.Kind != v1.EnvironmentKindTypePermanent || env.Spec.Source.URL == "" {
+			continue
+		}
+		if o.Environment != "" && name != o.Environment {
+			continue
+		}
+		envRollbacks, err := o.environmentRollbacks(env)
+		if err != nil {
+			return err
+		}
+		rollbacks = append(rollbacks, envRollbacks...)
+	}
+
+	if o.Output != "" {
+		return o.renderResult(rollbacks, o.Output)
+	}
+	if len(rollbacks) == 0 {
+		return outputEmptyListWarning(o.Out)
+	}
+	table := o.CreateTable()
+	table.AddRow("ENVIRONMENT", "ROLLBACK", "STATE", "TRIGGERED BY", "URL")
+	for _, r := range rollbacks {
+		triggeredBy := ""
+		if r.Provenance != nil {
+			triggeredBy = r.Provenance.TriggeredBy
+		}
+		table.AddRow(r.Environment, r.Title, r.State, triggeredBy, r.URL)
+	}
+	table.Render()
+	return nil
+}
+
+// environmentRollbacks returns the open rollback pull requests and the recent rollback commits of the environment
+func (o *GetRollbacksOptions) environmentRollbacks(env *v1.Environment) ([]*Rollback, error) {
+	gitURL := env.Spec.Source.URL
+	provider, gitInfo, err := o.CreateGitProviderForURLWithoutKind(gitURL)
+	if err != nil {
+		return nil, errors.Wrapf(err, "creating git provider for %s", gitURL)
+	}
+	answer := []*Rollback{}
+
+	prs, err := provider.ListOpenPullRequests(gitInfo.Organisation, gitInfo.Name)
+	if err != nil {
+		return nil, errors.Wrapf(err, "listing the open pull requests of %s", gitURL)
+	}
+	for _, pr := range prs {
+		if !strings.HasPrefix(pr.Title, environments.RollbackTitlePrefix) {
+			continue
+		}
+		provenance, err := gits.ParseProvenance(pr.Body)
+		if err != nil {
+			log.Logger().Debugf("ignoring the provenance of pull request %s: %s", pr.URL, err)
+		}
+		answer = append(answer, &Rollback{
+			Environment: env.Name,
+			Title:       pr.Title,
+			State:       "open",
+			URL:         pr.URL,
+			SHA:         pr.LastCommitSha,
+			Provenance:  provenance,
+		})
+	}
+
+	if o.Commits <= 0 {
+		return answer, nil
+	}
+	commits, err := provider.ListCommits(gitInfo.Organisation, gitInfo.Name, &gits.ListCommitsArguments{
+		SHA:     env.Spec.Source.Ref,
+		Path:    "env/" + helm.RequirementsFileName,
+		Page:    1,
+		PerPage: o.Commits,
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "listing the commits of %s", gitURL)
+	}
+	for _, commit := range commits {
+		subject := commit.Subject()
+		if !strings.HasPrefix(subject, environments.RollbackTitlePrefix) {
+			continue
+		}
+		answer = append(answer, &Rollback{
+			Environment: env.Name,
+			Title:       subject,
+			State:       "merged",
+			URL:         commit.URL,
+			SHA:         commit.SHA,
+			Provenance:  gits.ParseProvenanceTrailers(commit.Message),
+		})
+	}
+	return answer, nil
+}
@@ -0,0 +1,319 @@
+package get
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jenkins-x/jx/v2/pkg/auth"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/v2/pkg/gits"
+	"github.com/jenkins-x/jx/v2/pkg/io/secrets"
+	"github.com/jenkins-x/jx/v2/pkg/kube"
+	"github.com/jenkins-x/jx/v2/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	secretKindHMAC   = "hmac"
+	secretKindDocker = "docker"
+	secretKindOther  = "other"
+
+	secretStorageKube = "kube"
+
+	minHMACTokenLength = 32
+	minTokenLength     = 20
+)
+
+var (
+	// jxSecretKinds the kinds of the well known secrets jx manages which are not labelled with their kind
+	jxSecretKinds = map[string]string{
+		"hmac-token":                 secretKindHMAC,
+		"lighthouse-hmac-token":      secretKindHMAC,
+		"jenkins-docker-cfg":         secretKindDocker,
+		kube.SecretJenkinsReleaseGPG: "gpg",
+	}
+
+	// broadTokenScopes the git token scopes which grant more than the pipelines need
+	broadTokenScopes = []string{"admin:org", "admin:enterprise", "admin:gpg_key", "admin:public_key", "delete_repo", "site_admin"}
+
+	getSecretsLong = templates.LongDesc(`
+		Display the secrets Jenkins X manages in the development namespace, where their values are stored and when they
+		were last rotated.
+
+		With --audit the git tokens are checked with their git providers for their scopes and expiry and any weak,
+		expiring, overly broad or stale credentials are flagged.
+`)
+
+	getSecretsExample = templates.Examples(`
+		# List the secrets
+		jx get secrets
+
+		# Audit the secrets
+		jx get secrets --audit
+
+		# Audit the secrets flagging any not rotated in the last 30 days as YAML
+		jx get secrets --audit --rotation-days 30 -o yaml
+	`)
+)
+
+// GetSecretsOptions the command line options
+type GetSecretsOptions struct {
+	GetOptions
+
+	Audit        bool
+	RotationDays int
+	ExpiryDays   int
+}
+
+// AuditedSecret describes a secret jx manages
+type AuditedSecret struct {
+	// Name the name of the Kubernetes Secret
+	Name string `json:"name"`
+	// Kind the kind of secret such as git, hmac or docker
+	Kind string `json:"kind"`
+	// Storage where the value of the secret is stored such as vault, local, external-secrets or kube
+	Storage string `json:"storage"`
+	// LastRotated when the secret was last rotated or created
+	LastRotated *time.Time `json:"lastRotated,omitempty"`
+	// URL the server the secret is for, if any
+	URL string `json:"url,omitempty"`
+	// Scopes the scopes of a git token
+	Scopes []string `json:"scopes,omitempty"`
+	// ExpiresAt when a git token expires
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+	// Issues the weak, expiring, overly broad or stale credentials found by an audit
+	Issues []string `json:"issues,omitempty"`
+}
+
+// NewCmdGetSecrets creates the command object
+func NewCmdGetSecrets(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &GetSecretsOptions{
+		GetOptions: GetOptions{
+			CommonOptions: commonOpts,
+		},
+	}
+
+	cmd := &cobra.Command{
+		Use:     "secrets",
+		Short:   "Display the secrets Jenkins X manages and optionally audits them",
+		Aliases: []string{"secret"},
+		Long:    getSecretsLong,
+		Example: getSecretsExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+	options.AddGetFlags(cmd)
+	cmd.Flags().BoolVarP(&options.Audit, "audit", "", false, "Checks the scopes and expiry of the git tokens with their git providers and flags weak, expiring, overly broad or stale credentials")
+	cmd.Flags().IntVarP(&options.RotationDays, "rotation-days", "", 90, "The number of days after which a secret which has not been rotated is flagged by an audit")
+	cmd.Flags().IntVarP(&options.ExpiryDays, "expiry-days", "", 14, "The number of days before a git token expires that it is flagged by an audit")
+	return cmd
+}
+
+// Run implements this command
+func (o *GetSecretsOptions) Run() error {
+	kubeClient, ns, err := o.KubeClientAndDevNamespace()
+	if err != nil {
+		return errors.Wrap(err, "creating the kube client")
+	}
+	list, err := kubeClient.CoreV1().Secrets(ns).List(metav1.ListOptions{})
+	if err != nil {
+		return errors.Wrapf(err, "listing the secrets in namespace %s", ns)
+	}
+	location := o.GetSecretsLocation()
+
+	answer := []*AuditedSecret{}
+	for i := range list.Items {
+		secret := &list.Items[i]
+		kind := jxSecretKind(secret)
+		if kind == "" {
+			continue
+		}
+		audited := &AuditedSecret{
+			Name:        secret.Name,
+			Kind:        kind,
+			Storage:     secretStorage(secret, location),
+			LastRotated: lastRotated(secret),
+			URL:         secret.Annotations[kube.AnnotationURL],
+		}
+		if o.Audit {
+			if kind == kube.ValueKindGit {
+				o.inspectGitToken(secret, audited)
+			}
+			audited.Issues = append(audited.Issues, auditSecret(secret, audited, time.Now(), o.RotationDays, o.ExpiryDays)...)
+		}
+		answer = append(answer, audited)
+	}
+	sort.Slice(answer, func(i, j int) bool {
+		return answer[i].Name < answer[j].Name
+	})
+
+	if o.Output != "" {
+		return o.renderResult(answer, o.Output)
+	}
+	if len(answer) == 0 {
+		return outputEmptyListWarning(o.Out)
+	}
+	table := o.CreateTable()
+	if o.Audit {
+		table.AddRow("NAME", "KIND", "STORAGE", "LAST ROTATED", "EXPIRES", "SCOPES", "ISSUES")
+	} else {
+		table.AddRow("NAME", "KIND", "STORAGE", "LAST ROTATED")
+	}
+	for _, s := range answer {
+		rotated := ""
+		if s.LastRotated != nil {
+			rotated = s.LastRotated.Format("2006-01-02")
+		}
+		if !o.Audit {
+			table.AddRow(s.Name, s.Kind, s.Storage, rotated)
+			continue
+		}
+		expires := ""
+		if s.ExpiresAt != nil {
+			expires = s.ExpiresAt.Format("2006-01-02")
+		}
+		issues := util.ColorInfo("OK")
+		if len(s.Issues) > 0 {
+			issues = util.ColorWarning(strings.Join(s.Issues, ", "))
+		}
+		table.AddRow(s.Name, s.Kind, s.Storage, rotated, expires, strings.Join(s.Scopes, ","), issues)
+	}
+	table.Render()
+	return nil
+}
+
+// inspectGitToken queries the git provider for the scopes and expiry of the token in the git secret
+func (o *GetSecretsOptions) inspectGitToken(secret *v1.Secret, audited *AuditedSecret) {
+	if audited.URL == "" {
+		return
+	}
+	server := &auth.AuthServer{
+		URL:  audited.URL,
+		Kind: secret.Labels[kube.LabelServiceKind],
+	}
+	user := &auth.UserAuth{
+		Username: string(secret.Data[kube.SecretDataUsername]),
+		ApiToken: string(secret.Data[kube.SecretDataPassword]),
+	}
+	provider, err := gits.CreateProvider(server, user, o.Git())
+	if err != nil {
+		log.Logger().Warnf("failed to create the git provider for secret %s: %s", secret.Name, err)
+		return
+	}
+	inspector, ok := provider.(gits.TokenInspector)
+	if !ok {
+		log.Logger().Debugf("%s does not describe its tokens so the scopes of secret %s are not checked", provider.Kind(), secret.Name)
+		return
+	}
+	info, err := inspector.GetTokenInfo()
+	if err != nil {
+		audited.Issues = append(audited.Issues, "token rejected by git provider")
+		log.Logger().Warnf("failed to inspect the token of secret %s: %s", secret.Name, err)
+		return
+	}
+	audited.Scopes = info.Scopes
+	audited.ExpiresAt = info.ExpiresAt
+	if info.ExpiresAt == nil {
+		audited.Issues = append(audited.Issues, "never expires")
+	}
+}
+
+// jxSecretKind returns the kind of the secret if jx manages it, otherwise blank
+func jxSecretKind(secret *v1.Secret) string {
+	if kind := secret.Labels[kube.LabelKind]; kind != "" {
+		return kind
+	}
+	if kind, ok := jxSecretKinds[secret.Name]; ok {
+		return kind
+	}
+	if strings.HasPrefix(secret.Name, kube.SecretJenkinsPipelinePrefix) {
+		return secretKindOther
+	}
+	return ""
+}
+
+// secretStorage returns where the value of the secret is stored. Secrets synced by the External Secrets Operator are
+// stored in a cloud secret manager and secrets installed by helm are generated from the secret storage of the cluster,
+// any other secrets are only stored in Kubernetes
+func secretStorage(secret *v1.Secret, location secrets.SecretsLocationKind) string {
+	for _, owner := range secret.OwnerReferences {
+		if owner.Kind == "ExternalSecret" {
+			return string(secrets.ExternalSecretsLocationKind)
+		}
+	}
+	managedBy := secret.Labels["app.kubernetes.io/managed-by"]
+	heritage := secret.Labels["heritage"]
+	if managedBy == "Helm" || heritage == "Helm" || heritage == "Tiller" {
+		return string(location)
+	}
+	return secretStorageKube
+}
+
+// lastRotated returns when the secret was last rotated, defaulting to when it was created
+func lastRotated(secret *v1.Secret) *time.Time {
+	if value := secret.Annotations[kube.AnnotationRotatedAt]; value != "" {
+		t, err := time.Parse(time.RFC3339, value)
+		if err == nil {
+			return &t
+		}
+	}
+	if secret.CreationTimestamp.IsZero() {
+		return nil
+	}
+	t := secret.CreationTimestamp.Time
+	return &t
+}
+
+// auditSecret returns the issues with the secret such as weak, expiring, overly broad or stale credentials
+func auditSecret(secret *v1.Secret, audited *AuditedSecret, now time.Time, rotationDays int, expiryDays int) []string {
+	issues := []string{}
+	switch audited.Kind {
+	case secretKindHMAC:
+		for _, value := range secret.Data {
+			if len(value) < minHMACTokenLength {
+				issues = append(issues, "weak HMAC token")
+				break
+			}
+		}
+	case kube.ValueKindGit:
+		if len(secret.Data[kube.SecretDataPassword]) < minTokenLength {
+			issues = append(issues, "weak token")
+		}
+		broad := []string{}
+		for _, scope := range audited.Scopes {
+			if util.StringArrayIndex(broadTokenScopes, scope) >= 0 {
+				broad = append(broad, scope)
+			}
+		}
+		if len(broad) > 0 {
+			issues = append(issues, fmt.Sprintf("overly broad scopes %s", strings.Join(broad, ",")))
+		}
+	}
+	if audited.ExpiresAt != nil {
+		days := int(audited.ExpiresAt.Sub(now).Hours() / 24)
+		if audited.ExpiresAt.Before(now) {
+			issues = append(issues, "expired")
+		} else if days <= expiryDays {
+			issues = append(issues, fmt.Sprintf("expires in %d days", days))
+		}
+	}
+	if audited.LastRotated != nil && rotationDays > 0 {
+		days := int(now.Sub(*audited.LastRotated).Hours() / 24)
+		if days > rotationDays {
+			issues = append(issues, fmt.Sprintf("not rotated for %d days", days))
+		}
+	}
+	return issues
+}
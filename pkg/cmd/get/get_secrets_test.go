@@ -0,0 +1,67 @@
+// +build unit
+
+package get
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jenkins-x/jx/v2/pkg/io/secrets"
+	"github.com/jenkins-x/jx/v2/pkg/kube"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestAuditSecrets(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC)
+	created := metav1.NewTime(now.AddDate(0, -6, 0))
+
+	hmac := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "lighthouse-hmac-token",
+			CreationTimestamp: created,
+			Annotations: map[string]string{
+				kube.AnnotationRotatedAt: now.AddDate(0, 0, -10).Format(time.RFC3339),
+			},
+		},
+		Data: map[string][]byte{"hmac": []byte("short")},
+	}
+	audited := &AuditedSecret{Kind: jxSecretKind(hmac), LastRotated: lastRotated(hmac)}
+	assert.Equal(t, secretKindHMAC, audited.Kind)
+	assert.Equal(t, []string{"weak HMAC token"}, auditSecret(hmac, audited, now, 90, 14))
+
+	expires := now.AddDate(0, 0, 5)
+	git := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "jx-pipeline-git-github-github",
+			CreationTimestamp: created,
+			Labels: map[string]string{
+				kube.LabelKind:                 kube.ValueKindGit,
+				"app.kubernetes.io/managed-by": "Helm",
+			},
+		},
+		Data: map[string][]byte{kube.SecretDataPassword: []byte("0123456789abcdef0123456789abcdef01234567")},
+	}
+	audited = &AuditedSecret{
+		Kind:        jxSecretKind(git),
+		LastRotated: lastRotated(git),
+		Scopes:      []string{"repo", "admin:org", "delete_repo"},
+		ExpiresAt:   &expires,
+	}
+	assert.Equal(t, kube.ValueKindGit, audited.Kind)
+	assert.Equal(t, "vault", secretStorage(git, secrets.VaultLocationKind))
+	assert.Equal(t, []string{
+		"overly broad scopes admin:org,delete_repo",
+		"expires in 5 days",
+		"not rotated for 183 days",
+	}, auditSecret(git, audited, now, 90, 14))
+
+	other := &v1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "my-app-secret"}}
+	assert.Empty(t, jxSecretKind(other), "secrets jx does not manage should be ignored")
+	require.Nil(t, lastRotated(other))
+	assert.Equal(t, secretStorageKube, secretStorage(other, secrets.VaultLocationKind))
+}
@@ -17,6 +17,7 @@ import (
 	gojenkins "github.com/jenkins-x/golang-jenkins"
 	v1 "github.com/jenkins-x/jx/v2/pkg/apis/jenkins.io/v1"
 	"github.com/jenkins-x/jx/v2/pkg/auth"
+	"github.com/jenkins-x/jx/v2/pkg/buildpacks"
 	"github.com/jenkins-x/jx/v2/pkg/cloud/amazon"
 	"github.com/jenkins-x/jx/v2/pkg/cmd/edit"
 	"github.com/jenkins-x/jx/v2/pkg/cmd/helper"
@@ -32,6 +33,7 @@ import (
 	"github.com/jenkins-x/jx/v2/pkg/kube/naming"
 	"github.com/jenkins-x/jx/v2/pkg/log"
 	"github.com/jenkins-x/jx/v2/pkg/prow"
+	"github.com/jenkins-x/jx/v2/pkg/quickstarts"
 	"github.com/jenkins-x/jx/v2/pkg/util"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
@@ -57,10 +59,15 @@ type ImportOptions struct {
 	AppName                 string
 	GitHub                  bool
 	DryRun                  bool
+	DryRunReportFile        string
+	DryRunCopy              bool
 	SelectAll               bool
 	DisableDraft            bool
+	DisableMonorepo         bool
+	ExistingChart           bool
 	DisableJenkinsfileCheck bool
 	DisableWebhooks         bool
+	DisableRepoSettings     bool
 	SelectFilter            string
 	Jenkinsfile             string
 	BranchPattern           string
@@ -68,11 +75,15 @@ type ImportOptions struct {
 	ImportGitCommitMessage  string
 	ListDraftPacks          bool
 	DraftPack               string
+	BuildPackMode           string
+	CNBPlatform             string
+	CNBBuilder              string
 	DockerRegistryOrg       string
 	GitDetails              gits.CreateRepoData
 	DeployKind              string
 	DeployOptions           v1.DeployOptions
 	SchedulerName           string
+	Variables               []string
 
 	DisableDotGitSearch   bool
 	InitialisedGit        bool
@@ -88,8 +99,11 @@ type ImportOptions struct {
 	ImportMode            string
 	UseDefaultGit         bool
 	GithubAppInstalled    bool
+	MonorepoApps          []MonorepoApp
+	VariableValues        map[string]string
 
-	reporter ImportReporter
+	reporter     ImportReporter
+	dryRunReport *ImportReport
 }
 
 var (
@@ -100,7 +114,28 @@ var (
 	    Or you can use '--dir' to specify a directory to import.
 
 	    You can specify the git URL as an argument.
-	    
+
+	    If the repository has no Dockerfile or chart of its own but at least two subdirectories with their own Dockerfile,
+	    chart or pipeline then it is imported as a monorepo: each subdirectory is an application with its own pipeline
+	    in a 'jenkins-x-<app>.yml' file which only runs when the subdirectory changes, and is released and promoted
+	    separately. Use '--no-monorepo' to import the repository as a single application.
+
+	    Use '--dry-run' to see what the import would do before trusting it with an existing repository: the changes are
+	    made to a copy of the repository and reported, with the files added or modified, the pipelines registered and
+	    the webhook and other resources which would be created, without pushing or changing anything in the cluster.
+	    Use '--dry-run-copy=false' to make the local changes to the repository itself.
+
+	    Use '--existing-chart' to import a repository which already has its own Dockerfile and a chart in 'charts/<app>':
+	    nothing is scaffolded, the chart is checked to be named after the application with an image which releases
+	    can change and only the pipeline configuration is added before the webhooks and promotion are set up.
+
+	    If the repository has a 'quickstart.yaml' file declaring template parameters then its files are rendered as Go
+	    templates with the values of the parameters, which are passed via '--var' or asked for, before it is imported.
+
+	    If the team settings have 'repositorySettings' then the branch protection, required status checks, merge
+	    strategies and labels of the repository are configured once it has been pushed. Use '--no-repo-settings' to
+	    leave the repository as it is and 'jx step repo sync-settings' to reconcile the settings later.
+
 		For more documentation see: [https://jenkins-x.io/docs/using-jx/creating/import/](https://jenkins-x.io/docs/using-jx/creating/import/)
 	    
 ` + helper.SeeAlsoText("jx create project"))
@@ -123,6 +158,18 @@ var (
 
         # Import all repositories from a GitHub organisation which contain the text foo
 		jx import --github --org myname --all --filter foo 
+
+        # Import the current folder building its images with Cloud Native Buildpacks instead of a Dockerfile
+		jx import --build-pack cnb
+
+        # Report what importing the current folder would change without pushing or importing anything
+		jx import --dry-run --dry-run-report import-report.yaml
+
+        # Import a Git repository whose files are templates setting the values of its parameters
+		jx import --url https://github.com/myorg/spring-template.git --var group=com.acme --var database=postgres
+
+        # Import a repository which already has its own Dockerfile and chart without scaffolding
+		jx import --existing-chart
 		`)
 
 	deployKinds = []string{opts.DeployKindKnative, opts.DeployKindDefault}
@@ -173,13 +220,22 @@ func (options *ImportOptions) AddImportFlags(cmd *cobra.Command, createProject b
 	cmd.Flags().StringVarP(&options.Repository, "name", "", notCreateProject("n"), "Specify the Git repository name to import the project into (if it is not already in one)")
 	cmd.Flags().StringVarP(&options.Credentials, "credentials", notCreateProject("c"), "", "The Jenkins credentials name used by the job")
 	cmd.Flags().StringVarP(&options.Jenkinsfile, "jenkinsfile", notCreateProject("j"), "", "The name of the Jenkinsfile to use. If not specified then 'Jenkinsfile' will be used")
-	cmd.Flags().BoolVarP(&options.DryRun, "dry-run", "", false, "Performs local changes to the repo but skips the import into Jenkins X")
+	cmd.Flags().BoolVarP(&options.DryRun, "dry-run", "", false, "Reports the files the import would add or modify, the webhook and the pipelines it would create without changing the repository, pushing or importing into Jenkins X")
+	cmd.Flags().StringVarP(&options.DryRunReportFile, "dry-run-report", "", "", "The file to save the report of the dry run to as YAML")
+	cmd.Flags().BoolVarP(&options.DryRunCopy, "dry-run-copy", "", true, "Makes the local changes of a dry run to a copy of the repository. Disable to make them to the repository itself")
 	cmd.Flags().BoolVarP(&options.DisableDraft, "no-draft", "", false, "Disable Draft from trying to default a Dockerfile and Helm Chart")
+	cmd.Flags().BoolVarP(&options.DisableMonorepo, "no-monorepo", "", false, "Disable detecting the applications in the subdirectories of a monorepo and import the repository as a single application")
+	cmd.Flags().BoolVarP(&options.ExistingChart, "existing-chart", "", false, "Imports a repository with its own Dockerfile and chart in charts/<app> without scaffolding, only adding the pipeline configuration")
+	cmd.Flags().BoolVarP(&options.DisableRepoSettings, "no-repo-settings", "", false, "Disable configuring the branch protection, merge strategies and labels of the repository from the repository settings of the team")
 	cmd.Flags().BoolVarP(&options.DisableJenkinsfileCheck, "no-jenkinsfile", "", false, "Disable defaulting a Jenkinsfile if its missing")
 	cmd.Flags().StringVarP(&options.ImportGitCommitMessage, "import-commit-message", "", "", "Specifies the initial commit message used when importing the project")
 	cmd.Flags().StringVarP(&options.BranchPattern, "branches", "", "", "The branch pattern for branches to trigger CI/CD pipelines on")
 	cmd.Flags().BoolVarP(&options.ListDraftPacks, "list-packs", "", false, "list available draft packs")
 	cmd.Flags().StringVarP(&options.DraftPack, "pack", "", "", "The name of the pack to use")
+	cmd.Flags().StringVarP(&options.BuildPackMode, "build-pack", "", "", fmt.Sprintf("How to build the images. Use '%s' to build them with Cloud Native Buildpacks instead of a Dockerfile", buildpacks.BuildPackCNB))
+	cmd.Flags().StringVarP(&options.CNBPlatform, "cnb-platform", "", buildpacks.CNBPlatformPack, fmt.Sprintf("The platform running the Cloud Native Buildpacks builds. Should be one of %s", strings.Join(buildpacks.CNBPlatforms, ", ")))
+	cmd.Flags().StringVarP(&options.CNBBuilder, "cnb-builder", "", "", "The builder image for pack or the ClusterBuilder for kpack. Defaults to the builder in the version stream")
+	cmd.Flags().StringArrayVarP(&options.Variables, "var", "", []string{}, fmt.Sprintf("The value of a template parameter or variable in the form name=value used when the project has a %s file or comes from a quickstart catalog", quickstarts.TemplateSchemaFileName))
 	cmd.Flags().StringVarP(&options.SchedulerName, "scheduler", "", "", "The name of the Scheduler configuration to use for ChatOps when using Prow")
 	cmd.Flags().StringVarP(&options.DockerRegistryOrg, "docker-registry-org", "", "", "The name of the docker registry organisation to use. If not specified then the Git provider organisation will be used")
 	cmd.Flags().StringVarP(&options.ExternalJenkinsBaseURL, "external-jenkins-url", "", "", "The jenkins url that an external git provider needs to use")
@@ -210,7 +266,11 @@ func (options *ImportOptions) Run() error {
 
 	options.SetBatchMode(options.BatchMode)
 
-	var err error
+	err := options.validateBuildPackMode()
+	if err != nil {
+		return err
+	}
+
 	isProw := false
 	jxClient, ns, err := options.JXClientAndDevNamespace()
 	if err != nil {
@@ -328,6 +388,13 @@ func (options *ImportOptions) Run() error {
 		}
 	}
 
+	if options.DryRun {
+		err = options.prepareDryRun()
+		if err != nil {
+			return err
+		}
+	}
+
 	checkForJenkinsfile := options.Jenkinsfile == "" && !options.DisableJenkinsfileCheck
 	shouldClone := checkForJenkinsfile || !options.DisableDraft
 
@@ -346,6 +413,12 @@ func (options *ImportOptions) Run() error {
 			if err != nil {
 				return err
 			}
+			if options.dryRunReport != nil {
+				err = options.dryRunReport.snapshot(options.Dir)
+				if err != nil {
+					return err
+				}
+			}
 		}
 	} else {
 		err = options.DiscoverGit()
@@ -381,7 +454,29 @@ func (options *ImportOptions) Run() error {
 	}
 	options.AppName = naming.ToValidName(strings.ToLower(options.AppName))
 
-	if !options.DisableDraft {
+	err = options.renderQuickstartTemplate()
+	if err != nil {
+		return err
+	}
+
+	if !options.DisableMonorepo && !options.ExistingChart {
+		options.MonorepoApps, err = DetectMonorepoApps(options.Dir)
+		if err != nil {
+			return err
+		}
+	}
+	if len(options.MonorepoApps) > 0 {
+		log.Logger().Infof("Importing %s as a monorepo with the applications %s", util.ColorInfo(options.AppName), util.ColorInfo(strings.Join(options.monorepoAppNames(), ", ")))
+		err = options.importMonorepoApps()
+		if err != nil {
+			return err
+		}
+	} else if options.ExistingChart {
+		err = options.importExistingChart()
+		if err != nil {
+			return err
+		}
+	} else if !options.DisableDraft {
 		err = options.DraftCreate()
 		if err != nil {
 			return err
@@ -393,9 +488,11 @@ func (options *ImportOptions) Run() error {
 		return err
 	}
 
-	err = options.fixMaven()
-	if err != nil {
-		return err
+	if !options.ExistingChart {
+		err = options.fixMaven()
+		if err != nil {
+			return err
+		}
 	}
 
 	if options.RepoURL == "" {
@@ -409,7 +506,7 @@ func (options *ImportOptions) Run() error {
 			}
 		}
 	} else {
-		if shouldClone {
+		if shouldClone && !options.DryRun {
 			err = options.Git().Push(options.Dir, "origin", false, "HEAD")
 			if err != nil {
 				return err
@@ -418,9 +515,15 @@ func (options *ImportOptions) Run() error {
 		}
 	}
 
+	if !options.DryRun && !options.DisableRepoSettings {
+		err = options.configureRepositorySettings()
+		if err != nil {
+			return err
+		}
+	}
+
 	if options.DryRun {
-		log.Logger().Info("dry-run so skipping import to Jenkins X")
-		return nil
+		return options.reportDryRun()
 	}
 
 	if !isProw {
@@ -476,6 +579,13 @@ func (options *ImportOptions) ImportProjectsFromGitHub() error {
 			GitProvider:             options.GitProvider,
 			DisableJenkinsfileCheck: options.DisableJenkinsfileCheck,
 			DisableDraft:            options.DisableDraft,
+			DisableMonorepo:         options.DisableMonorepo,
+			ExistingChart:           options.ExistingChart,
+			DisableRepoSettings:     options.DisableRepoSettings,
+			BuildPackMode:           options.BuildPackMode,
+			CNBPlatform:             options.CNBPlatform,
+			CNBBuilder:              options.CNBBuilder,
+			Variables:               options.Variables,
 		}
 		log.Logger().Infof("Importing repository %s", util.ColorInfo(r.Name))
 		err = o2.Run()
@@ -517,6 +627,10 @@ func (options *ImportOptions) DraftCreate() error {
 	if !filepath.IsAbs(jenkinsfile) {
 		jenkinsfile = filepath.Join(dir, jenkinsfile)
 	}
+	existingFiles, err := options.existingCNBGeneratedFiles()
+	if err != nil {
+		return err
+	}
 	args := &opts.InvokeDraftPack{
 		Dir:                     dir,
 		CustomDraftPack:         options.DraftPack,
@@ -585,6 +699,13 @@ func (options *ImportOptions) DraftCreate() error {
 		return err
 	}
 
+	if options.BuildPackMode == buildpacks.BuildPackCNB {
+		err = options.configureCNB(dockerRegistryOrg, existingFiles)
+		if err != nil {
+			return err
+		}
+	}
+
 	// Create Prow owners file
 	err = options.CreateProwOwnersFile()
 	if err != nil {
@@ -967,12 +1088,20 @@ func (options *ImportOptions) doImport() error {
 		return err
 	}
 
-	if dockerfileExists {
+	if dockerfileExists || options.BuildPackMode == buildpacks.BuildPackCNB {
 		err = options.ensureDockerRepositoryExists()
 		if err != nil {
 			return err
 		}
 	}
+	for _, app := range options.MonorepoApps {
+		appOptions := *options
+		appOptions.AppName = app.Name
+		err = appOptions.ensureDockerRepositoryExists()
+		if err != nil {
+			return err
+		}
+	}
 
 	isProw, err := options.IsProw()
 	if err != nil {
@@ -1044,6 +1173,15 @@ func (options *ImportOptions) addProwConfig(gitURL string, gitKind string) error
 		sr, err := kube.GetOrCreateSourceRepositoryCallback(jxClient, currentNamespace, gitInfo.Name, gitInfo.Organisation, gitInfo.HostURLWithoutUser(), callback)
 		log.Logger().Debugf("have SourceRepository: %s\n", sr.Name)
 
+		// a monorepo gets its own Scheduler which triggers the pipeline of each application on changes to its directory
+		var monorepoScheduler *v1.Scheduler
+		if len(options.MonorepoApps) > 0 && options.SchedulerName == "" {
+			monorepoScheduler, err = options.applyMonorepoScheduler(jxClient, currentNamespace, sr)
+			if err != nil {
+				return err
+			}
+		}
+
 		// lets update the Scheduler if one is specified and its different to the default
 		schedulerName := options.SchedulerName
 		if schedulerName != "" && schedulerName != sr.Spec.Scheduler.Name {
@@ -1076,6 +1214,12 @@ func (options *ImportOptions) addProwConfig(gitURL string, gitKind string) error
 			pro.CommonOptions = options.CommonOptions
 
 			changeFn := func(dir string, gitInfo *gits.GitRepository) ([]string, error) {
+				if monorepoScheduler != nil {
+					err := writeSchedulerToYaml(dir, monorepoScheduler)
+					if err != nil {
+						return nil, err
+					}
+				}
 				return nil, writeSourceRepoToYaml(dir, sr)
 			}
 
@@ -1095,6 +1239,9 @@ func (options *ImportOptions) addProwConfig(gitURL string, gitKind string) error
 			return err
 		}
 	} else {
+		if len(options.MonorepoApps) > 0 {
+			log.Logger().Warnf("The pipelines of the monorepo applications are only triggered by changes to their directories when using a Scheduler")
+		}
 		err = prow.AddApplication(client, []string{repo}, currentNamespace, options.DraftPack, settings)
 		if err != nil {
 			return err
@@ -1560,6 +1707,7 @@ func (options *ImportOptions) DefaultsFromTeamSettings() error {
 	if err != nil {
 		return err
 	}
+	options.ConfigureGitMirrors(settings)
 	return options.DefaultValuesFromTeamSettings(settings)
 }
 
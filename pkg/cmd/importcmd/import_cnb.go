@@ -0,0 +1,94 @@
+package importcmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	v1 "github.com/jenkins-x/jx/v2/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/v2/pkg/buildpacks"
+	"github.com/jenkins-x/jx/v2/pkg/config"
+	"github.com/jenkins-x/jx/v2/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+)
+
+// cnbGeneratedFiles the files the build packs generate to build the image which are not used by Cloud Native
+// Buildpacks builds
+var cnbGeneratedFiles = []string{"Dockerfile", "skaffold.yaml"}
+
+// validateBuildPackMode returns an error if the build pack mode or the Cloud Native Buildpacks platform is invalid
+func (options *ImportOptions) validateBuildPackMode() error {
+	switch options.BuildPackMode {
+	case "":
+		return nil
+	case buildpacks.BuildPackCNB:
+		cnb := buildpacks.CNBConfig{Platform: options.CNBPlatform}
+		return cnb.Validate()
+	default:
+		return util.InvalidOption("build-pack", options.BuildPackMode, []string{buildpacks.BuildPackCNB})
+	}
+}
+
+// existingCNBGeneratedFiles returns the files of the directory which would otherwise be generated by the build pack
+// so that the user's own Dockerfile is kept
+func (options *ImportOptions) existingCNBGeneratedFiles() (map[string]bool, error) {
+	answer := map[string]bool{}
+	for _, name := range cnbGeneratedFiles {
+		exists, err := util.FileExists(filepath.Join(options.Dir, name))
+		if err != nil {
+			return nil, err
+		}
+		answer[name] = exists
+	}
+	return answer, nil
+}
+
+// configureCNB replaces the image build of the pipelines the build pack generated with a Cloud Native Buildpacks build,
+// removing the generated Dockerfile and skaffold configuration
+func (options *ImportOptions) configureCNB(dockerRegistryOrg string, existingFiles map[string]bool) error {
+	settings, err := options.TeamSettings()
+	if err != nil {
+		return err
+	}
+	if settings.GetImportMode() != v1.ImportModeTypeYAML {
+		return fmt.Errorf("Cloud Native Buildpacks builds require the %s import mode", v1.ImportModeTypeYAML)
+	}
+
+	resolver, err := options.GetVersionResolver()
+	if err != nil {
+		return err
+	}
+	cnb := &buildpacks.CNBConfig{
+		Platform: options.CNBPlatform,
+		Builder:  options.CNBBuilder,
+		Image:    fmt.Sprintf("$DOCKER_REGISTRY/%s/%s", dockerRegistryOrg, options.AppName),
+		AppName:  options.AppName,
+	}
+	err = cnb.ResolveImages(resolver)
+	if err != nil {
+		return errors.Wrap(err, "resolving the Cloud Native Buildpacks images from the version stream")
+	}
+
+	projectConfig, fileName, err := config.LoadProjectConfig(options.Dir)
+	if err != nil {
+		return err
+	}
+	cnb.ApplyToProjectConfig(projectConfig)
+	err = projectConfig.SaveConfig(fileName)
+	if err != nil {
+		return errors.Wrapf(err, "saving %s", fileName)
+	}
+
+	for _, name := range cnbGeneratedFiles {
+		if existingFiles[name] {
+			continue
+		}
+		err = os.Remove(filepath.Join(options.Dir, name))
+		if err != nil && !os.IsNotExist(err) {
+			return errors.Wrapf(err, "removing the generated %s", name)
+		}
+	}
+	log.Logger().Infof("Building the images of %s with Cloud Native Buildpacks using %s %s", util.ColorInfo(options.AppName), cnb.Platform, util.ColorInfo(cnb.Builder))
+	return nil
+}
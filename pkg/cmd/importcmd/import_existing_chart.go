@@ -0,0 +1,219 @@
+package importcmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	v1 "github.com/jenkins-x/jx/v2/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/v2/pkg/buildpacks"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/v2/pkg/config"
+	"github.com/jenkins-x/jx/v2/pkg/helm"
+	"github.com/jenkins-x/jx/v2/pkg/jenkinsfile"
+	"github.com/jenkins-x/jx/v2/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+)
+
+const (
+	// ExistingChartBuildPack the build pack of repositories imported with their own chart and Dockerfile unless
+	// another one is chosen
+	ExistingChartBuildPack = "docker-helm"
+
+	// the prefixes of the lines of values.yaml 'jx step tag' replaces with the image of a release
+	valuesYamlRepositoryPrefix = "  repository:"
+	valuesYamlTagPrefix        = "  tag:"
+)
+
+// validateExistingChart checks that the chart and Dockerfile of a repository imported with '--existing-chart' can be
+// released and promoted as they are, returning warnings for what only limits the pipelines
+func validateExistingChart(dir string, appName string) ([]string, error) {
+	exists, err := util.FileExists(filepath.Join(dir, "Dockerfile"))
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, fmt.Errorf("there is no Dockerfile in %s to build the image of the chart", dir)
+	}
+
+	chartDir := filepath.Join(dir, "charts", appName)
+	chartFile := filepath.Join(chartDir, helm.ChartFileName)
+	exists, err = util.FileExists(chartFile)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		charts, err := filepath.Glob(filepath.Join(dir, "charts", "*", helm.ChartFileName))
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range charts {
+			name := filepath.Base(filepath.Dir(c))
+			if name != "preview" {
+				return nil, fmt.Errorf("the chart should be in charts/%s to be promoted. Rename charts/%s or import with '--name %s'", appName, name, name)
+			}
+		}
+		return nil, fmt.Errorf("there is no chart in charts/%s", appName)
+	}
+	name, _, err := helm.LoadChartNameAndVersion(chartFile)
+	if err != nil {
+		return nil, errors.Wrapf(err, "loading %s", chartFile)
+	}
+	if name != appName {
+		return nil, fmt.Errorf("the name of the chart in %s is %s rather than %s so the application could not be promoted", chartFile, name, appName)
+	}
+
+	valuesFile := filepath.Join(chartDir, helm.ValuesFileName)
+	exists, err = util.FileExists(valuesFile)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, fmt.Errorf("there is no %s in charts/%s for the image of a release", helm.ValuesFileName, appName)
+	}
+	hasRepository, hasTag, err := valuesImageLines(valuesFile)
+	if err != nil {
+		return nil, err
+	}
+	if !hasRepository || !hasTag {
+		return nil, fmt.Errorf("the image of %s should be an indented 'repository:' and 'tag:' such as 'image.repository' and 'image.tag' so that releases can change it", valuesFile)
+	}
+
+	warnings := []string{}
+	exists, err = util.FileExists(filepath.Join(dir, "charts", "preview", helm.ChartFileName))
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		warnings = append(warnings, "there is no charts/preview chart so Pull Requests will fail to create Preview Environments")
+	}
+	return warnings, nil
+}
+
+// valuesImageLines returns whether the values file has the image repository and tag lines releases replace
+func valuesImageLines(fileName string) (bool, bool, error) {
+	f, err := os.Open(fileName)
+	if err != nil {
+		return false, false, errors.Wrapf(err, "opening %s", fileName)
+	}
+	defer f.Close()
+	hasRepository := false
+	hasTag := false
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, valuesYamlRepositoryPrefix) {
+			hasRepository = true
+		} else if strings.HasPrefix(line, valuesYamlTagPrefix) {
+			hasTag = true
+		}
+	}
+	return hasRepository, hasTag, scanner.Err()
+}
+
+// importExistingChart imports a repository with its own chart and Dockerfile without scaffolding, only adding the
+// pipeline configuration once the chart has been validated
+func (options *ImportOptions) importExistingChart() error {
+	dir := options.Dir
+	warnings, err := validateExistingChart(dir, options.AppName)
+	if err != nil {
+		return errors.Wrapf(err, "validating the existing chart of %s", dir)
+	}
+	for _, w := range warnings {
+		log.Logger().Warnf("%s", w)
+	}
+
+	settings, err := options.TeamSettings()
+	if err != nil {
+		return err
+	}
+	yamlMode := settings.GetImportMode() == v1.ImportModeTypeYAML
+	if !yamlMode {
+		jenkinsfileName := options.Jenkinsfile
+		if jenkinsfileName == "" {
+			jenkinsfileName = jenkinsfile.Name
+		}
+		exists, err := util.FileExists(filepath.Join(dir, jenkinsfileName))
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return fmt.Errorf("importing an existing chart requires a %s or the %s import mode", jenkinsfileName, v1.ImportModeTypeYAML)
+		}
+		return nil
+	}
+
+	pack, err := options.existingChartBuildPack()
+	if err != nil {
+		return err
+	}
+	options.DraftPack, err = options.InvokeDraftPack(&opts.InvokeDraftPack{
+		Dir:                     dir,
+		CustomDraftPack:         pack,
+		InitialisedGit:          options.InitialisedGit,
+		DisableJenkinsfileCheck: true,
+		DisableAddFiles:         true,
+	})
+	if err != nil {
+		return err
+	}
+
+	fileName := filepath.Join(dir, config.ProjectConfigFileName)
+	projectConfig, err := config.LoadProjectConfigFile(fileName)
+	if err != nil {
+		return err
+	}
+	if projectConfig.BuildPack == "" {
+		projectConfig.BuildPack = options.DraftPack
+		err = projectConfig.SaveConfig(fileName)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = options.CreateProwOwnersFile()
+	if err != nil {
+		return err
+	}
+	err = options.CreateProwOwnersAliasesFile()
+	if err != nil {
+		return err
+	}
+	err = options.Git().Add(dir, "*")
+	if err != nil {
+		return err
+	}
+	err = options.Git().CommitIfChanges(dir, "Add the Jenkins X pipeline configuration")
+	if err != nil {
+		return err
+	}
+	log.Logger().Infof("Imported the existing chart charts/%s with the build pack %s", util.ColorInfo(options.AppName), util.ColorInfo(options.DraftPack))
+	return nil
+}
+
+// existingChartBuildPack returns the build pack to use for an existing chart, defaulting to the docker-helm build pack
+// which builds the Dockerfile of the repository unless the flags, the build pack override file or the project
+// configuration choose another one
+func (options *ImportOptions) existingChartBuildPack() (string, error) {
+	if options.DraftPack != "" {
+		return options.DraftPack, nil
+	}
+	override, err := buildpacks.LoadBuildPackOverride(options.Dir)
+	if err != nil {
+		return "", err
+	}
+	if override != nil && override.Pack != "" {
+		return override.Pack, nil
+	}
+	projectConfig, _, err := config.LoadProjectConfig(options.Dir)
+	if err != nil {
+		return "", err
+	}
+	if projectConfig.BuildPack != "" {
+		return projectConfig.BuildPack, nil
+	}
+	return ExistingChartBuildPack, nil
+}
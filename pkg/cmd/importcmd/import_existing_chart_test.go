@@ -0,0 +1,56 @@
+// +build unit
+
+package importcmd
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateExistingChart(t *testing.T) {
+	t.Parallel()
+	dir, err := ioutil.TempDir("", "existing-chart")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	writeFile := func(name string, text string) {
+		fileName := filepath.Join(dir, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(fileName), util.DefaultWritePermissions))
+		require.NoError(t, ioutil.WriteFile(fileName, []byte(text), util.DefaultWritePermissions))
+	}
+
+	_, err = validateExistingChart(dir, "myapp")
+	require.Error(t, err, "a repository without a Dockerfile should be rejected")
+
+	writeFile("Dockerfile", "FROM scratch\n")
+	writeFile("charts/other/Chart.yaml", "name: other\nversion: 0.1.0\n")
+	_, err = validateExistingChart(dir, "myapp")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "--name other")
+
+	require.NoError(t, os.RemoveAll(filepath.Join(dir, "charts", "other")))
+	writeFile("charts/myapp/Chart.yaml", "name: something-else\nversion: 0.1.0\n")
+	_, err = validateExistingChart(dir, "myapp")
+	require.Error(t, err, "a chart which is not named after the application should be rejected")
+
+	writeFile("charts/myapp/Chart.yaml", "name: myapp\nversion: 0.1.0\n")
+	writeFile("charts/myapp/values.yaml", "image: myorg/myapp:latest\n")
+	_, err = validateExistingChart(dir, "myapp")
+	require.Error(t, err, "an image which releases cannot change should be rejected")
+
+	writeFile("charts/myapp/values.yaml", "image:\n  repository: myorg/myapp\n  tag: latest\n")
+	warnings, err := validateExistingChart(dir, "myapp")
+	require.NoError(t, err)
+	assert.Len(t, warnings, 1, "a missing preview chart should only be a warning")
+
+	writeFile("charts/preview/Chart.yaml", "name: preview\nversion: 0.1.0\n")
+	warnings, err = validateExistingChart(dir, "myapp")
+	require.NoError(t, err)
+	assert.Empty(t, warnings)
+}
@@ -0,0 +1,271 @@
+package importcmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	jenkinsio "github.com/jenkins-x/jx/v2/pkg/apis/jenkins.io"
+	v1 "github.com/jenkins-x/jx/v2/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/v2/pkg/client/clientset/versioned"
+	"github.com/jenkins-x/jx/v2/pkg/config"
+	"github.com/jenkins-x/jx/v2/pkg/kube/naming"
+	"github.com/jenkins-x/jx/v2/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/prow"
+	"github.com/jenkins-x/jx/v2/pkg/tekton/syntax"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// AnnotationMonorepoApps the comma separated names of the applications of a monorepo on its SourceRepository
+	AnnotationMonorepoApps = "jenkins.io/monorepo-apps"
+)
+
+var (
+	monorepoSkipDirs = map[string]bool{
+		"charts":       true,
+		"node_modules": true,
+		"vendor":       true,
+	}
+)
+
+// MonorepoApp an application in a subdirectory of a repository which has its own Dockerfile, chart or pipeline
+type MonorepoApp struct {
+	// Name the name of the application which is used for its pipeline context, chart and promotion
+	Name string
+	// Dir the directory of the application relative to the root of the repository
+	Dir string
+}
+
+// PipelineFileName returns the file at the root of the repository with the pipeline of the application
+func (a *MonorepoApp) PipelineFileName() string {
+	return fmt.Sprintf("jenkins-x-%s.yml", a.Name)
+}
+
+// DetectMonorepoApps returns the applications in the subdirectories of the directory or nil if it is not a monorepo.
+// A directory is a monorepo if it contains at least two subdirectories with their own Dockerfile, chart or pipeline
+// and has no Dockerfile or chart of its own
+func DetectMonorepoApps(dir string) ([]MonorepoApp, error) {
+	if isMonorepoAppDir(dir) {
+		return nil, nil
+	}
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, errors.Wrapf(err, "reading directory %s", dir)
+	}
+	var answer []MonorepoApp
+	for _, f := range files {
+		name := f.Name()
+		if !f.IsDir() || strings.HasPrefix(name, ".") || monorepoSkipDirs[name] {
+			continue
+		}
+		if isMonorepoAppDir(filepath.Join(dir, name)) {
+			answer = append(answer, MonorepoApp{
+				Name: naming.ToValidName(strings.ToLower(name)),
+				Dir:  name,
+			})
+		}
+	}
+	if len(answer) < 2 {
+		return nil, nil
+	}
+	sort.Slice(answer, func(i, j int) bool {
+		return answer[i].Name < answer[j].Name
+	})
+	return answer, nil
+}
+
+// isMonorepoAppDir returns true if the directory has a Dockerfile, a chart or a pipeline
+func isMonorepoAppDir(dir string) bool {
+	for _, name := range []string{"Dockerfile", config.ProjectConfigFileName} {
+		exists, err := util.FileExists(filepath.Join(dir, name))
+		if err == nil && exists {
+			return true
+		}
+	}
+	exists, err := util.DirExists(filepath.Join(dir, "charts"))
+	return err == nil && exists
+}
+
+// MonorepoPipelineConfig returns the pipeline of the application which runs from its directory, based on the
+// pipeline the build pack generated in the directory if there is one
+func MonorepoPipelineConfig(dir string, app MonorepoApp) (*config.ProjectConfig, error) {
+	projectConfig, _, err := config.LoadProjectConfig(filepath.Join(dir, app.Dir))
+	if err != nil {
+		return nil, errors.Wrapf(err, "loading the pipeline of application %s", app.Name)
+	}
+	pipelineConfig := projectConfig.GetOrCreatePipelineConfig()
+	if pipelineConfig.Agent == nil {
+		pipelineConfig.Agent = &syntax.Agent{}
+	}
+	pipelineConfig.Agent.Dir = "./" + filepath.ToSlash(app.Dir)
+	return projectConfig, nil
+}
+
+// MonorepoScheduler returns the Scheduler of the repository which runs the pipeline of each application only when
+// its directory changes, replacing the jobs of the parent schedulers which build the whole repository
+func MonorepoScheduler(name string, apps []MonorepoApp) *v1.Scheduler {
+	presubmits := &v1.Presubmits{Replace: true}
+	postsubmits := &v1.Postsubmits{Replace: true}
+	for i := range apps {
+		app := apps[i]
+		jobName := app.Name
+		releaseName := "release-" + app.Name
+		agent := prow.TektonAgent
+		runIfChanged := fmt.Sprintf("^%s/", regexp.QuoteMeta(filepath.ToSlash(app.Dir)))
+		trigger := fmt.Sprintf(`(?m)^/test( all| %s),?(\s+|$)`, app.Name)
+		rerunCommand := "/test " + app.Name
+		alwaysRun := false
+		report := true
+		releaseReport := false
+		presubmits.Items = append(presubmits.Items, &v1.Presubmit{
+			JobBase:             &v1.JobBase{Name: &jobName, Agent: &agent},
+			Brancher:            &v1.Brancher{},
+			RegexpChangeMatcher: &v1.RegexpChangeMatcher{RunIfChanged: &runIfChanged},
+			AlwaysRun:           &alwaysRun,
+			Context:             &jobName,
+			Report:              &report,
+			Trigger:             &trigger,
+			RerunCommand:        &rerunCommand,
+			Queries: []*v1.Query{
+				{
+					Labels: &v1.ReplaceableSliceOfStrings{Items: []string{"approved"}},
+					MissingLabels: &v1.ReplaceableSliceOfStrings{Items: []string{
+						"do-not-merge",
+						"do-not-merge/hold",
+						"do-not-merge/work-in-progress",
+						"needs-ok-to-test",
+						"needs-rebase",
+					}},
+				},
+			},
+		})
+		postsubmits.Items = append(postsubmits.Items, &v1.Postsubmit{
+			JobBase:             &v1.JobBase{Name: &releaseName, Agent: &agent},
+			RegexpChangeMatcher: &v1.RegexpChangeMatcher{RunIfChanged: &runIfChanged},
+			Brancher: &v1.Brancher{
+				Branches: &v1.ReplaceableSliceOfStrings{Items: []string{"master"}},
+			},
+			Context: &jobName,
+			Report:  &releaseReport,
+		})
+	}
+	agent := prow.TektonAgent
+	return &v1.Scheduler{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: jenkinsio.GroupAndVersion,
+			Kind:       "Scheduler",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+		},
+		Spec: v1.SchedulerSpec{
+			ScehdulerAgent: &v1.SchedulerAgent{Agent: &agent},
+			Presubmits:     presubmits,
+			Postsubmits:    postsubmits,
+		},
+	}
+}
+
+// importMonorepoApps generates the Dockerfile, chart and pipeline of each application of the monorepo in its
+// directory using the build packs, then a pipeline at the root of the repository per application which the
+// Scheduler of the repository runs when the directory of the application changes
+func (options *ImportOptions) importMonorepoApps() error {
+	dir := options.Dir
+	for _, app := range options.MonorepoApps {
+		log.Logger().Infof("Importing application %s from directory %s", util.ColorInfo(app.Name), util.ColorInfo(app.Dir))
+		if !options.DisableDraft {
+			appOptions := *options
+			appOptions.Dir = filepath.Join(dir, app.Dir)
+			appOptions.AppName = app.Name
+			appOptions.MonorepoApps = nil
+			appOptions.DisableJenkinsfileCheck = true
+			err := appOptions.DraftCreate()
+			if err != nil {
+				return errors.Wrapf(err, "creating application %s", app.Name)
+			}
+			options.Organisation = appOptions.Organisation
+			options.GitUserAuth = appOptions.GitUserAuth
+		}
+
+		projectConfig, err := MonorepoPipelineConfig(dir, app)
+		if err != nil {
+			return err
+		}
+		err = projectConfig.SaveConfig(filepath.Join(dir, app.PipelineFileName()))
+		if err != nil {
+			return errors.Wrapf(err, "saving the pipeline of application %s", app.Name)
+		}
+	}
+
+	err := options.Git().Add(dir, "*")
+	if err != nil {
+		return err
+	}
+	return options.Git().CommitIfChanges(dir, "Add the pipelines of the monorepo applications")
+}
+
+// monorepoAppNames returns the names of the applications of the monorepo
+func (options *ImportOptions) monorepoAppNames() []string {
+	var answer []string
+	for _, app := range options.MonorepoApps {
+		answer = append(answer, app.Name)
+	}
+	return answer
+}
+
+// applyMonorepoScheduler creates or updates the Scheduler of the monorepo with the path based triggers of its
+// applications and registers the applications on the SourceRepository so they are promoted separately
+func (options *ImportOptions) applyMonorepoScheduler(jxClient versioned.Interface, ns string, sr *v1.SourceRepository) (*v1.Scheduler, error) {
+	scheduler := MonorepoScheduler(sr.Name+"-scheduler", options.MonorepoApps)
+	schedulers := jxClient.JenkinsV1().Schedulers(ns)
+	existing, err := schedulers.Get(scheduler.Name, metav1.GetOptions{})
+	if err == nil {
+		existing.Spec = scheduler.Spec
+		_, err = schedulers.Update(existing)
+	} else if apierrors.IsNotFound(err) {
+		_, err = schedulers.Create(scheduler)
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "applying the Scheduler %s", scheduler.Name)
+	}
+
+	if sr.Annotations == nil {
+		sr.Annotations = map[string]string{}
+	}
+	sr.Annotations[AnnotationMonorepoApps] = strings.Join(options.monorepoAppNames(), ",")
+	sr.Spec.Scheduler.Name = scheduler.Name
+	_, err = jxClient.JenkinsV1().SourceRepositories(ns).Update(sr)
+	if err != nil {
+		return nil, errors.Wrapf(err, "updating the SourceRepository %s with the Scheduler %s", sr.Name, scheduler.Name)
+	}
+	return scheduler, nil
+}
+
+// writeSchedulerToYaml marshals a Scheduler to the given directory next to the SourceRepository using it
+func writeSchedulerToYaml(dir string, scheduler *v1.Scheduler) error {
+	outDir := filepath.Join(dir, "repositories", "templates")
+	err := os.MkdirAll(outDir, util.DefaultWritePermissions)
+	if err != nil {
+		return errors.Wrapf(err, "failed to make directories %s", outDir)
+	}
+
+	fileName := filepath.Join(outDir, scheduler.Name+"-sch.yaml")
+	data, err := yaml.Marshal(scheduler)
+	if err != nil {
+		return errors.Wrapf(err, "failed to marshal Scheduler %s to yaml", scheduler.Name)
+	}
+	err = ioutil.WriteFile(fileName, data, util.DefaultWritePermissions)
+	if err != nil {
+		return errors.Wrapf(err, "failed to save Scheduler file %s", fileName)
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+// +build unit
+
+package importcmd
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectMonorepoApps(t *testing.T) {
+	t.Parallel()
+	dir, err := ioutil.TempDir("", "monorepo")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	writeFile := func(name string) {
+		fileName := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(fileName), util.DefaultWritePermissions))
+		require.NoError(t, ioutil.WriteFile(fileName, []byte("test"), util.DefaultWritePermissions))
+	}
+	writeFile("README.md")
+	writeFile("frontend/Dockerfile")
+	writeFile("Orders_Service/charts/orders/Chart.yaml")
+	writeFile("docs/index.md")
+	writeFile(".github/Dockerfile")
+	writeFile("node_modules/foo/Dockerfile")
+
+	apps, err := DetectMonorepoApps(dir)
+	require.NoError(t, err)
+	assert.Equal(t, []MonorepoApp{
+		{Name: "frontend", Dir: "frontend"},
+		{Name: "orders-service", Dir: "Orders_Service"},
+	}, apps)
+
+	writeFile("Dockerfile")
+	apps, err = DetectMonorepoApps(dir)
+	require.NoError(t, err)
+	assert.Empty(t, apps, "a repository with its own Dockerfile is not a monorepo")
+}
+
+func TestDetectMonorepoAppsSingleApp(t *testing.T) {
+	t.Parallel()
+	dir, err := ioutil.TempDir("", "monorepo")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "backend", "charts"), util.DefaultWritePermissions))
+
+	apps, err := DetectMonorepoApps(dir)
+	require.NoError(t, err)
+	assert.Empty(t, apps)
+}
+
+func TestMonorepoScheduler(t *testing.T) {
+	t.Parallel()
+	apps := []MonorepoApp{
+		{Name: "frontend", Dir: "frontend"},
+		{Name: "orders-service", Dir: "orders.service"},
+	}
+	scheduler := MonorepoScheduler("myorg-myrepo-scheduler", apps)
+	assert.Equal(t, "myorg-myrepo-scheduler", scheduler.Name)
+
+	presubmits := scheduler.Spec.Presubmits
+	require.NotNil(t, presubmits)
+	assert.True(t, presubmits.Replace)
+	require.Len(t, presubmits.Items, 2)
+	presubmit := presubmits.Items[1]
+	assert.Equal(t, "orders-service", *presubmit.Name)
+	assert.Equal(t, "orders-service", *presubmit.Context)
+	assert.False(t, *presubmit.AlwaysRun)
+	assert.Equal(t, "/test orders-service", *presubmit.RerunCommand)
+
+	runIfChanged := regexp.MustCompile(*presubmit.RunIfChanged)
+	assert.True(t, runIfChanged.MatchString("orders.service/main.go"))
+	assert.False(t, runIfChanged.MatchString("ordersxservice/main.go"))
+	assert.False(t, runIfChanged.MatchString("frontend/orders.service/main.go"))
+
+	trigger := regexp.MustCompile(*presubmit.Trigger)
+	assert.True(t, trigger.MatchString("/test orders-service"))
+	assert.True(t, trigger.MatchString("/test all"))
+	assert.False(t, trigger.MatchString("/test frontend"))
+
+	postsubmits := scheduler.Spec.Postsubmits
+	require.NotNil(t, postsubmits)
+	assert.True(t, postsubmits.Replace)
+	require.Len(t, postsubmits.Items, 2)
+	assert.Equal(t, "release-frontend", *postsubmits.Items[0].Name)
+	assert.Equal(t, "frontend", *postsubmits.Items[0].Context)
+	assert.Equal(t, "^frontend/", *postsubmits.Items[0].RunIfChanged)
+}
+
+func TestMonorepoPipelineConfig(t *testing.T) {
+	t.Parallel()
+	dir, err := ioutil.TempDir("", "monorepo")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	appDir := filepath.Join(dir, "frontend")
+	require.NoError(t, os.MkdirAll(appDir, util.DefaultWritePermissions))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(appDir, "jenkins-x.yml"), []byte("buildPack: javascript\n"), util.DefaultWritePermissions))
+
+	app := MonorepoApp{Name: "frontend", Dir: "frontend"}
+	projectConfig, err := MonorepoPipelineConfig(dir, app)
+	require.NoError(t, err)
+	assert.Equal(t, "javascript", projectConfig.BuildPack)
+	require.NotNil(t, projectConfig.PipelineConfig)
+	assert.Equal(t, "./frontend", projectConfig.PipelineConfig.Agent.Dir)
+	assert.Equal(t, "jenkins-x-frontend.yml", app.PipelineFileName())
+}
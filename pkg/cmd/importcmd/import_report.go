@@ -0,0 +1,284 @@
+package importcmd
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/v2/pkg/config"
+	"github.com/jenkins-x/jx/v2/pkg/gits"
+	"github.com/jenkins-x/jx/v2/pkg/jenkinsfile"
+	"github.com/jenkins-x/jx/v2/pkg/kube/services"
+	"github.com/jenkins-x/jx/v2/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+	"sigs.k8s.io/yaml"
+)
+
+// ImportReport the changes an import would make which a dry run reports instead of applying them
+type ImportReport struct {
+	// AppName the name of the imported application
+	AppName string `json:"appName"`
+	// Source the directory or git URL being imported
+	Source string `json:"source"`
+	// WorkDir the repository the local changes were made to, which is a copy unless they are made in place
+	WorkDir string `json:"workDir"`
+	// Added the files the import adds to the repository
+	Added []string `json:"added,omitempty"`
+	// Modified the files of the repository the import modifies
+	Modified []string `json:"modified,omitempty"`
+	// Removed the files the import removes from the repository
+	Removed []string `json:"removed,omitempty"`
+	// Pipelines the pipelines which would be registered
+	Pipelines []string `json:"pipelines,omitempty"`
+	// Actions the changes which would be made to the git provider and the cluster
+	Actions []string `json:"actions,omitempty"`
+
+	files map[string][]byte
+}
+
+// prepareDryRun starts the report of a dry run. Unless the changes are made in place it copies the directory being
+// imported to a temporary directory, or clones into one, so that a dry run makes no changes to the user's repository
+func (options *ImportOptions) prepareDryRun() error {
+	options.dryRunReport = &ImportReport{Source: options.RepoURL}
+	var tmpDir string
+	var err error
+	if options.DryRunCopy {
+		tmpDir, err = ioutil.TempDir("", "jx-import-dry-run-")
+		if err != nil {
+			return errors.Wrap(err, "creating the dry run directory")
+		}
+	}
+	if options.RepoURL != "" {
+		if tmpDir != "" {
+			options.Dir = tmpDir
+		}
+		return nil
+	}
+
+	dir, err := filepath.Abs(options.Dir)
+	if err != nil {
+		return err
+	}
+	if !options.DisableDotGitSearch {
+		root, _, err := options.Git().FindGitConfigDir(dir)
+		if err != nil {
+			return err
+		}
+		if root != "" {
+			dir = root
+		}
+	}
+	options.dryRunReport.Source = dir
+	if tmpDir == "" {
+		return options.dryRunReport.snapshot(dir)
+	}
+
+	// keep the name of the directory as it is the default name of the application
+	_, name := filepath.Split(dir)
+	workDir := filepath.Join(tmpDir, name)
+	err = util.CopyDir(dir, workDir, true)
+	if err != nil {
+		return errors.Wrapf(err, "copying %s to %s", dir, workDir)
+	}
+	options.Dir = workDir
+	return options.dryRunReport.snapshot(workDir)
+}
+
+// snapshot records the files of the repository before the import changes them
+func (r *ImportReport) snapshot(dir string) error {
+	r.WorkDir = dir
+	files, err := readRepositoryFiles(dir)
+	if err != nil {
+		return err
+	}
+	r.files = files
+	return nil
+}
+
+// diff reports the files the import added, modified or removed since the snapshot
+func (r *ImportReport) diff(dir string) error {
+	files, err := readRepositoryFiles(dir)
+	if err != nil {
+		return err
+	}
+	r.Added, r.Modified, r.Removed = nil, nil, nil
+	for name, data := range files {
+		old, ok := r.files[name]
+		if !ok {
+			r.Added = append(r.Added, name)
+		} else if !bytes.Equal(old, data) {
+			r.Modified = append(r.Modified, name)
+		}
+	}
+	for name := range r.files {
+		if _, ok := files[name]; !ok {
+			r.Removed = append(r.Removed, name)
+		}
+	}
+	sort.Strings(r.Added)
+	sort.Strings(r.Modified)
+	sort.Strings(r.Removed)
+	return nil
+}
+
+// readRepositoryFiles returns the contents of the files of the repository indexed by their relative paths
+func readRepositoryFiles(dir string) (map[string][]byte, error) {
+	answer := map[string][]byte{}
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if info.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return errors.Wrapf(err, "reading %s", path)
+		}
+		answer[filepath.ToSlash(rel)] = data
+		return nil
+	})
+	return answer, err
+}
+
+// reportDryRun completes the report of the dry run with the pipelines and the remote changes the import would make,
+// then logs it and saves it to the report file if there is one
+func (options *ImportOptions) reportDryRun() error {
+	report := options.dryRunReport
+	if report == nil {
+		return nil
+	}
+	report.AppName = options.AppName
+	err := report.diff(options.Dir)
+	if err != nil {
+		return err
+	}
+	options.reportPipelinesAndActions(report)
+
+	report.log()
+	if options.DryRunReportFile != "" {
+		data, err := yaml.Marshal(report)
+		if err != nil {
+			return errors.Wrap(err, "marshalling the dry run report")
+		}
+		err = ioutil.WriteFile(options.DryRunReportFile, data, util.DefaultWritePermissions)
+		if err != nil {
+			return errors.Wrapf(err, "saving the dry run report to %s", options.DryRunReportFile)
+		}
+		log.Logger().Infof("Saved the dry run report to %s", util.ColorInfo(options.DryRunReportFile))
+	}
+	return nil
+}
+
+// reportPipelinesAndActions adds the pipelines and the changes to the git provider and the cluster the import would
+// make. It only reads from the cluster so it reports what it can if the cluster cannot be reached
+func (options *ImportOptions) reportPipelinesAndActions(report *ImportReport) {
+	repoName := options.AppName
+	if options.RepoURL != "" {
+		gitInfo, err := gits.ParseGitURL(options.RepoURL)
+		if err == nil {
+			repoName = gitInfo.Organisation + "/" + gitInfo.Name
+		}
+	} else {
+		repoName = options.getOrganisationOrCurrentUser() + "/" + options.AppName
+		report.Actions = append(report.Actions, fmt.Sprintf("create the git repository %s", repoName))
+	}
+	report.Actions = append(report.Actions, fmt.Sprintf("push the commits to the git repository %s", repoName))
+	if !options.DisableRepoSettings {
+		settings, err := options.TeamSettings()
+		if err == nil && settings.RepositorySettings != nil {
+			report.Actions = append(report.Actions, fmt.Sprintf("configure the branch protection, merge strategies and labels of %s from the team settings", repoName))
+		}
+	}
+
+	isProw, err := options.IsProw()
+	if err != nil {
+		log.Logger().Warnf("Failed to find out if the team uses Prow or Lighthouse so the report may be incomplete: %s", err.Error())
+	}
+	if !isProw {
+		jenkinsfileName := options.Jenkinsfile
+		if jenkinsfileName == "" {
+			jenkinsfileName = jenkinsfile.Name
+		}
+		report.Pipelines = append(report.Pipelines, fmt.Sprintf("Jenkins multi branch job %s using %s", repoName, jenkinsfileName))
+		report.Actions = append(report.Actions, fmt.Sprintf("create a webhook on %s triggering the Jenkins job", repoName))
+		return
+	}
+
+	if len(options.MonorepoApps) > 0 {
+		for _, app := range options.MonorepoApps {
+			report.Pipelines = append(report.Pipelines, fmt.Sprintf("%s: pull request and release pipelines of %s in %s", app.Name, app.Dir, app.PipelineFileName()))
+		}
+	} else {
+		report.Pipelines = append(report.Pipelines, fmt.Sprintf("%s: pull request and release pipelines in %s", options.AppName, config.ProjectConfigFileName))
+	}
+
+	githubAppMode, err := options.IsGitHubAppMode()
+	if err != nil {
+		log.Logger().Warnf("Failed to find out if the team uses the GitHub App: %s", err.Error())
+	}
+	if !options.DisableWebhooks && !githubAppMode {
+		hookURL := options.prowHookURL()
+		if hookURL == "" {
+			hookURL = "the hook service of the cluster"
+		}
+		report.Actions = append(report.Actions, fmt.Sprintf("create a webhook on %s to %s", repoName, hookURL))
+	}
+	report.Actions = append(report.Actions, fmt.Sprintf("create the SourceRepository %s", options.AppName))
+	settings, err := options.TeamSettings()
+	if err == nil && settings.IsSchedulerMode() {
+		if len(options.MonorepoApps) > 0 && options.SchedulerName == "" {
+			report.Actions = append(report.Actions, fmt.Sprintf("create the Scheduler %s-scheduler triggering the pipeline of each application", options.AppName))
+		}
+		report.Actions = append(report.Actions, "create a Pull Request on the development environment repository adding the SourceRepository")
+	} else {
+		report.Actions = append(report.Actions, fmt.Sprintf("add %s to the Prow configuration", repoName))
+	}
+	report.Actions = append(report.Actions, fmt.Sprintf("start the pipeline of the %s branch", opts.MasterBranch))
+}
+
+// prowHookURL returns the URL of the hook service webhooks are sent to or an empty string if it cannot be found
+func (options *ImportOptions) prowHookURL() string {
+	client, ns, err := options.KubeClientAndDevNamespace()
+	if err != nil {
+		return ""
+	}
+	baseURL, err := services.FindServiceURL(client, ns, "hook")
+	if err != nil || baseURL == "" {
+		return ""
+	}
+	return util.UrlJoin(baseURL, "hook")
+}
+
+// log logs the report
+func (r *ImportReport) log() {
+	logger := log.Logger()
+	logger.Infof("Dry run of the import of %s from %s", util.ColorInfo(r.AppName), util.ColorInfo(r.Source))
+	logList := func(title string, items []string) {
+		if len(items) == 0 {
+			return
+		}
+		logger.Infof("\n%s:", title)
+		for _, item := range items {
+			logger.Infof("  %s", item)
+		}
+	}
+	logList("Files added", r.Added)
+	logList("Files modified", r.Modified)
+	logList("Files removed", r.Removed)
+	logList("Pipelines registered", r.Pipelines)
+	logList("Changes to the git provider and the cluster", r.Actions)
+	logger.Infof("\nNothing was pushed or changed in the cluster. The local changes were made to %s", util.ColorInfo(r.WorkDir))
+}
@@ -0,0 +1,74 @@
+// +build unit
+
+package importcmd
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImportReportDiff(t *testing.T) {
+	t.Parallel()
+	dir, err := ioutil.TempDir("", "import-report")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	writeFile := func(name string, text string) {
+		fileName := filepath.Join(dir, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(fileName), util.DefaultWritePermissions))
+		require.NoError(t, ioutil.WriteFile(fileName, []byte(text), util.DefaultWritePermissions))
+	}
+	writeFile("main.go", "package main\n")
+	writeFile("pom.xml", "<project/>\n")
+	writeFile("Jenkinsfile", "pipeline {}\n")
+	writeFile(".git/config", "[core]\n")
+
+	report := &ImportReport{}
+	require.NoError(t, report.snapshot(dir))
+
+	writeFile("Dockerfile", "FROM scratch\n")
+	writeFile("charts/myapp/Chart.yaml", "name: myapp\n")
+	writeFile("pom.xml", "<project><build/></project>\n")
+	writeFile(".git/config", "[core]\n[remote \"origin\"]\n")
+	require.NoError(t, os.Remove(filepath.Join(dir, "Jenkinsfile")))
+
+	require.NoError(t, report.diff(dir))
+	assert.Equal(t, []string{"Dockerfile", "charts/myapp/Chart.yaml"}, report.Added)
+	assert.Equal(t, []string{"pom.xml"}, report.Modified)
+	assert.Equal(t, []string{"Jenkinsfile"}, report.Removed)
+}
+
+func TestPrepareDryRunCopiesTheRepository(t *testing.T) {
+	t.Parallel()
+	dir, err := ioutil.TempDir("", "import-report")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	appDir := filepath.Join(dir, "myapp")
+	require.NoError(t, os.MkdirAll(appDir, util.DefaultWritePermissions))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(appDir, "main.go"), []byte("package main\n"), util.DefaultWritePermissions))
+
+	options := &ImportOptions{
+		Dir:                 appDir,
+		DryRunCopy:          true,
+		DisableDotGitSearch: true,
+	}
+	require.NoError(t, options.prepareDryRun())
+	defer os.RemoveAll(filepath.Dir(options.Dir))
+
+	assert.NotEqual(t, appDir, options.Dir)
+	assert.Equal(t, "myapp", filepath.Base(options.Dir), "the copy should keep the name of the directory")
+	assert.Equal(t, appDir, options.dryRunReport.Source)
+
+	require.NoError(t, ioutil.WriteFile(filepath.Join(options.Dir, "Dockerfile"), []byte("FROM scratch\n"), util.DefaultWritePermissions))
+	require.NoError(t, options.dryRunReport.diff(options.Dir))
+	assert.Equal(t, []string{"Dockerfile"}, options.dryRunReport.Added)
+
+	_, err = os.Stat(filepath.Join(appDir, "Dockerfile"))
+	assert.True(t, os.IsNotExist(err), "the repository being imported should not change")
+}
@@ -0,0 +1,35 @@
+package importcmd
+
+import (
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/v2/pkg/gits"
+	"github.com/jenkins-x/jx/v2/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+)
+
+// configureRepositorySettings applies the branch protection, merge strategies and labels of the team settings to the
+// imported repository, protecting the branch which was pushed
+func (options *ImportOptions) configureRepositorySettings() error {
+	settings, err := options.TeamSettings()
+	if err != nil {
+		return err
+	}
+	if settings.RepositorySettings == nil || options.GitProvider == nil || options.RepoURL == "" {
+		return nil
+	}
+	gitInfo, err := gits.ParseGitURL(options.RepoURL)
+	if err != nil {
+		return errors.Wrapf(err, "parsing git URL %s", options.RepoURL)
+	}
+	branch, err := options.Git().Branch(options.Dir)
+	if err != nil || branch == "" {
+		branch = opts.MasterBranch
+	}
+	_, err = gits.ApplyRepositorySettings(options.GitProvider, gitInfo.Organisation, gitInfo.Name, branch, settings.RepositorySettings)
+	if err != nil {
+		log.Logger().Warnf("Failed to configure the repository settings of %s so they can be reconciled later with %s: %s",
+			gitInfo.URL, util.ColorInfo("jx step repo sync-settings"), err.Error())
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package importcmd
+
+import (
+	"github.com/jenkins-x/jx/v2/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/quickstarts"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+)
+
+// renderQuickstartTemplate renders the files of the imported directory if it has a quickstart template schema, using
+// the values of its parameters passed via '--var' or asked for, then commits the rendered files
+func (options *ImportOptions) renderQuickstartTemplate() error {
+	schema, err := quickstarts.LoadTemplateSchema(options.Dir)
+	if err != nil {
+		return err
+	}
+	if schema == nil {
+		return nil
+	}
+	values, err := util.ExtractKeyValuePairs(options.Variables, "=")
+	if err != nil {
+		return util.InvalidOptionError("var", options.Variables, err)
+	}
+	for k, v := range options.VariableValues {
+		if _, ok := values[k]; !ok {
+			values[k] = v
+		}
+	}
+	values, err = quickstarts.ResolveVariables(schema.Parameters, values, options.BatchMode, options.GetIOFileHandles())
+	if err != nil {
+		return err
+	}
+	data := &quickstarts.TemplateData{
+		AppName:      options.AppName,
+		Organisation: options.Organisation,
+		Values:       values,
+	}
+	err = schema.Render(options.Dir, data)
+	if err != nil {
+		return errors.Wrapf(err, "rendering the quickstart templates of %s", options.Dir)
+	}
+	log.Logger().Infof("Rendered the quickstart templates of %s", util.ColorInfo(options.AppName))
+
+	err = options.Git().Add(options.Dir, "*")
+	if err != nil {
+		return err
+	}
+	return options.Git().CommitIfChanges(options.Dir, "chore: render the quickstart templates")
+}
@@ -0,0 +1,148 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/jenkins-x/jx/v2/pkg/auth"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/v2/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/browser"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// LoginOptions the options for the login command
+type LoginOptions struct {
+	*opts.CommonOptions
+
+	Issuer    string
+	ClientID  string
+	Scopes    []string
+	Server    string
+	NoBrowser bool
+}
+
+var (
+	loginLong = templates.LongDesc(`
+		Logs in to the OpenID Connect identity provider of the cluster using the device authorization flow.
+
+		A code is displayed which you enter in the browser to approve the login. The short lived token issued is stored
+		in ~/.jx/oidcAuth.yaml along with a refresh token which jx uses to refresh the token automatically when it
+		expires, so long lived static tokens do not need to be stored in the auth config.
+
+		Running the command without an issuer logs in again to the identity provider last logged in to.
+`)
+
+	loginExample = templates.Examples(`
+		# log in to an identity provider
+		jx login --issuer https://dex.example.com --client-id jx
+
+		# log in again when the refresh token has expired
+		jx login
+
+		# log in from a terminal without a browser
+		jx login --issuer https://dex.example.com --client-id jx --no-browser
+`)
+)
+
+// NewCmdLogin creates the command
+func NewCmdLogin(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &LoginOptions{
+		CommonOptions: commonOpts,
+	}
+	cmd := &cobra.Command{
+		Use:     "login",
+		Short:   "Logs in to the OpenID Connect identity provider of the cluster",
+		Long:    loginLong,
+		Example: loginExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+	cmd.Flags().StringVarP(&options.Issuer, "issuer", "", "", "The URL of the OpenID Connect identity provider")
+	cmd.Flags().StringVarP(&options.ClientID, "client-id", "", "", "The ID of the client registered with the identity provider for jx")
+	cmd.Flags().StringArrayVarP(&options.Scopes, "scope", "", auth.DefaultOIDCScopes, "The scopes to request")
+	cmd.Flags().StringVarP(&options.Server, "server", "", "", "The URL of the server the token is used to access. Defaults to the issuer")
+	cmd.Flags().BoolVarP(&options.NoBrowser, "no-browser", "", false, "Do not open the verification page in a browser")
+	return cmd
+}
+
+// Run implements the command
+func (o *LoginOptions) Run() error {
+	authConfigSvc, err := o.OIDCAuthConfigService()
+	if err != nil {
+		return err
+	}
+	config := authConfigSvc.Config()
+	if o.Issuer == "" {
+		o.defaultFromCurrentLogin(config)
+	}
+	if o.Issuer == "" {
+		return util.MissingOption("issuer")
+	}
+	if o.ClientID == "" {
+		return util.MissingOption("client-id")
+	}
+	if o.Server == "" {
+		o.Server = o.Issuer
+	}
+
+	client := auth.NewOIDCClient(o.Issuer, o.ClientID, o.Scopes)
+	device, err := client.StartDeviceLogin()
+	if err != nil {
+		return errors.Wrapf(err, "starting the login with %s", o.Issuer)
+	}
+	verificationURL := device.VerificationURIComplete
+	if verificationURL == "" {
+		verificationURL = device.VerificationURI
+	}
+	log.Logger().Infof("To log in open %s and enter the code %s", util.ColorInfo(device.VerificationURI), util.ColorInfo(device.UserCode))
+	if !o.NoBrowser && !o.BatchMode {
+		err = browser.OpenURL(verificationURL)
+		if err != nil {
+			log.Logger().Debugf("failed to open %s in a browser: %s", verificationURL, err)
+		}
+	}
+
+	user, err := client.WaitForDeviceLogin(device)
+	if err != nil {
+		return errors.Wrapf(err, "logging in with %s", o.Issuer)
+	}
+	config.GetOrCreateServerName(o.Server, "", auth.OIDCServerKind)
+	err = authConfigSvc.SaveUserAuth(o.Server, user)
+	if err != nil {
+		return errors.Wrap(err, "saving the token")
+	}
+	expiry := ""
+	if !user.OIDC.Expiry.IsZero() {
+		expiry = fmt.Sprintf(" until %s", user.OIDC.Expiry.Local().Format("15:04"))
+	}
+	log.Logger().Infof("Logged in to %s as %s%s", util.ColorInfo(o.Server), util.ColorInfo(user.Username), expiry)
+	return nil
+}
+
+// defaultFromCurrentLogin defaults the issuer and client from the server logged in to last
+func (o *LoginOptions) defaultFromCurrentLogin(config *auth.AuthConfig) {
+	server := config.GetServer(config.CurrentServer)
+	if server == nil {
+		return
+	}
+	for _, user := range server.Users {
+		if user.IsOIDC() {
+			o.Issuer = user.OIDC.Issuer
+			if o.ClientID == "" {
+				o.ClientID = user.OIDC.ClientID
+			}
+			if o.Server == "" {
+				o.Server = server.URL
+			}
+			return
+		}
+	}
+}
@@ -2,6 +2,7 @@ package namespace
 
 import (
 	"fmt"
+	"os"
 
 	"github.com/jenkins-x/jx/v2/pkg/cmd/helper"
 	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
@@ -31,11 +32,16 @@ type NamespaceOptions struct {
 	*opts.CommonOptions
 
 	Create bool
+	Shell  bool
 }
 
 var (
 	namespaceLong = templates.LongDesc(`
-		Displays or changes the current namespace.`)
+		Displays or changes the current namespace.
+
+		Use '--shell' to change the namespace of the current shell only: the output is evaluated by the shell to put an
+		overlay kube config file with a copy of the current context ahead of the shared ones in KUBECONFIG. Once a shell
+		uses its own context, see 'jx context --shell', changing the namespace only changes that shell.`)
 	namespaceExample = templates.Examples(`
 		# view the current namespace
 		jx --batch-mode ns
@@ -47,7 +53,10 @@ var (
 		jx ns cheese
 
 		# change the current namespace to 'brie' creating it if necessary
-		jx ns --create brie`)
+		jx ns --create brie
+
+		# change the namespace to 'staging' in the current shell only
+		eval "$(jx ns --shell staging)"`)
 )
 
 func NewCmdNamespace(commonOpts *opts.CommonOptions) *cobra.Command {
@@ -69,6 +78,7 @@ func NewCmdNamespace(commonOpts *opts.CommonOptions) *cobra.Command {
 	}
 
 	cmd.Flags().BoolVarP(&options.Create, "create", "c", false, "Creates the specified namespace if it does not exist")
+	cmd.Flags().BoolVarP(&options.Shell, "shell", "", false, "Changes the namespace of the current shell only by outputting the command the shell should evaluate")
 	return cmd
 }
 
@@ -93,6 +103,9 @@ func (o *NamespaceOptions) Run() error {
 	}
 
 	info := util.ColorInfo
+	if o.Shell && kube.ShellKubeConfig() == "" {
+		return o.changeShellNamespace(client, config, ns)
+	}
 	if ns != "" && ns != currentNS {
 		ctx, err := changeNamespace(client, config, pathOptions, ns, o.Create)
 		if err != nil {
@@ -125,19 +138,52 @@ func namespace(o *NamespaceOptions) string {
 	return ns
 }
 
-func changeNamespace(client kubernetes.Interface, config *api.Config, pathOptions clientcmd.ConfigAccess, ns string, create bool) (*api.Context, error) {
+// changeShellNamespace creates an overlay kube config file with a copy of the current context using the namespace
+// and outputs the command making the shell use it
+func (o *NamespaceOptions) changeShellNamespace(client kubernetes.Interface, config *api.Config, ns string) error {
+	ctxName := kube.CurrentContextName(config)
+	ctx := kube.CurrentContext(config)
+	if ctx == nil {
+		return fmt.Errorf("there is no current Kubernetes context to use in the shell")
+	}
+	if ns != "" {
+		err := ensureNamespace(client, ns, o.Create)
+		if err != nil {
+			return err
+		}
+		// only the copy of the context in the overlay is saved
+		ctx = ctx.DeepCopy()
+		ctx.Namespace = ns
+		config = config.DeepCopy()
+		config.Contexts[ctxName] = ctx
+	}
+	overlay, err := kube.CreateShellKubeConfig(config, ctxName)
+	if err != nil {
+		return err
+	}
+	_, _ = fmt.Fprintf(o.Err, "Now using namespace '%s' on server '%s' in this shell.\n", util.ColorInfo(ctx.Namespace), util.ColorInfo(kube.Server(config, ctx)))
+	_, _ = fmt.Fprintln(o.Out, kube.ShellExportCommand(overlay))
+	return nil
+}
+
+func ensureNamespace(client kubernetes.Interface, ns string, create bool) error {
 	_, err := client.CoreV1().Namespaces().Get(ns, meta_v1.GetOptions{})
 	if err != nil {
 		switch err.(type) {
 		case *api_errors.StatusError:
-			err = handleStatusError(err, client, ns, create)
-			if err != nil {
-				return nil, err
-			}
+			return handleStatusError(err, client, ns, create)
 		default:
-			return nil, errors.Wrapf(err, "getting namespace %q", ns)
+			return errors.Wrapf(err, "getting namespace %q", ns)
 		}
 	}
+	return nil
+}
+
+func changeNamespace(client kubernetes.Interface, config *api.Config, pathOptions clientcmd.ConfigAccess, ns string, create bool) (*api.Context, error) {
+	err := ensureNamespace(client, ns, create)
+	if err != nil {
+		return nil, err
+	}
 	newConfig := *config
 	ctx := kube.CurrentContext(config)
 	if ctx == nil {
@@ -222,7 +268,12 @@ func pick(o *NamespaceOptions, names []string, defaultNamespace string) (string,
 		Default: defaultNamespace,
 	}
 
-	surveyOpts := survey.WithStdio(o.In, o.Out, o.Err)
+	out := o.Out
+	if o.Shell {
+		// the output of the command is evaluated by the shell so ask on the terminal instead
+		out = os.Stderr
+	}
+	surveyOpts := survey.WithStdio(o.In, out, o.Err)
 	err := survey.AskOne(prompt, &name, nil, surveyOpts)
 	return name, err
 }
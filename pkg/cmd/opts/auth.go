@@ -180,3 +180,16 @@ func (o *CommonOptions) GitLocalAuthConfigService() (auth.ConfigService, error)
 	}
 	return o.factory.CreateLocalGitAuthConfigService()
 }
+
+// OIDCAuthConfigService creates the auth config service for the tokens obtained by logging in with an OpenID Connect
+// identity provider. The tokens are always stored locally as they are specific to the user running jx
+func (o *CommonOptions) OIDCAuthConfigService() (auth.ConfigService, error) {
+	authService, err := auth.NewFileAuthConfigService(auth.OIDCAuthConfigFile, auth.OIDCServerKind)
+	if err != nil {
+		return nil, errors.Wrapf(err, "creating the auth config service from file %s", auth.OIDCAuthConfigFile)
+	}
+	if _, err := authService.LoadConfig(); err != nil {
+		return nil, errors.Wrapf(err, "loading auth config from file %s", auth.OIDCAuthConfigFile)
+	}
+	return authService, nil
+}
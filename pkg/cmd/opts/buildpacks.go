@@ -5,6 +5,7 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/jenkins-x/jx/v2/pkg/buildpacks"
 
@@ -13,10 +14,10 @@ import (
 	"github.com/pkg/errors"
 
 	"github.com/jenkins-x/jx/v2/pkg/config"
-	jxdraft "github.com/jenkins-x/jx/v2/pkg/draft"
 	"github.com/jenkins-x/jx/v2/pkg/jenkinsfile"
 	"github.com/jenkins-x/jx/v2/pkg/jenkinsfile/gitresolver"
 	"github.com/jenkins-x/jx/v2/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/tekton/syntax"
 	"github.com/jenkins-x/jx/v2/pkg/util"
 )
 
@@ -42,10 +43,33 @@ func (o *CommonOptions) InitBuildPacks(i *InvokeDraftPack) (string, *v1.TeamSett
 		return "", settings, err
 	}
 	buildPackURL := settings.BuildPackURL
+	buildPackRef := settings.BuildPackRef
 	if i != nil && i.ProjectConfig != nil && i.ProjectConfig.BuildPackGitURL != "" {
 		buildPackURL = i.ProjectConfig.BuildPackGitURL
 	}
-	dir, err := gitresolver.InitBuildPack(o.Git(), buildPackURL, settings.BuildPackRef)
+	if settings.BuildPackVersionStream {
+		resolver, err := o.GetVersionResolver()
+		if err != nil {
+			return "", settings, errors.Wrap(err, "creating the version stream resolver")
+		}
+		version, err := resolver.ResolveGitVersion(buildPackURL)
+		if err != nil {
+			return "", settings, errors.Wrapf(err, "resolving the version of the build packs %s", buildPackURL)
+		}
+		if version != "" {
+			buildPackRef = version
+		}
+	}
+	if i != nil && i.Dir != "" {
+		override, err := buildpacks.LoadBuildPackOverride(i.Dir)
+		if err != nil {
+			return "", settings, err
+		}
+		if override != nil && override.Version != "" {
+			buildPackRef = override.Version
+		}
+	}
+	dir, err := gitresolver.InitBuildPack(o.Git(), buildPackURL, buildPackRef)
 	return dir, settings, err
 }
 
@@ -74,13 +98,15 @@ func (o *CommonOptions) InvokeDraftPack(i *InvokeDraftPack) (string, error) {
 		defaultJenkinsfile = filepath.Join(dir, jenkinsfile.Name)
 	}
 
-	pomName := filepath.Join(dir, "pom.xml")
-	gradleName := filepath.Join(dir, "build.gradle")
-	jenkinsPluginsName := filepath.Join(dir, "plugins.txt")
-	packagerConfigName := filepath.Join(dir, "packager-config.yml")
 	jenkinsxYaml := filepath.Join(dir, config.ProjectConfigFileName)
-	envChart := filepath.Join(dir, "env/Chart.yaml")
 	lpack := ""
+	override, err := buildpacks.LoadBuildPackOverride(dir)
+	if err != nil {
+		return "", err
+	}
+	if len(customDraftPack) == 0 && override != nil {
+		customDraftPack = override.Pack
+	}
 	if len(customDraftPack) == 0 {
 		if i.ProjectConfig == nil {
 			i.ProjectConfig, _, err = config.LoadProjectConfig(dir)
@@ -106,89 +132,23 @@ func (o *CommonOptions) InvokeDraftPack(i *InvokeDraftPack) (string, error) {
 	}
 
 	if len(lpack) == 0 {
-		if exists, err := util.FileExists(pomName); err == nil && exists {
-			pack, err := util.PomFlavour(pomName)
-			if err != nil {
-				return "", err
-			}
-			lpack = filepath.Join(packsDir, pack)
-
-			exists, _ = util.DirExists(lpack)
-			if !exists {
-				log.Logger().Warn("defaulting to maven pack")
-				lpack = filepath.Join(packsDir, "maven")
-			}
-		} else if exists, err := util.FileExists(gradleName); err == nil && exists {
-			lpack = filepath.Join(packsDir, "gradle")
-		} else if exists, err := util.FileExists(jenkinsPluginsName); err == nil && exists {
-			lpack = filepath.Join(packsDir, "jenkins")
-		} else if exists, err := util.FileExists(packagerConfigName); err == nil && exists {
-			lpack = filepath.Join(packsDir, "cwp")
-		} else if exists, err := util.FileExists(envChart); err == nil && exists {
-			lpack = filepath.Join(packsDir, "environment")
-		} else {
-			// pack detection time
-			lpack, err = jxdraft.DoPackDetectionForBuildPack(o.Out, dir, packsDir)
-
-			if err != nil {
-				if lpack == "" {
-					// lets detect docker and/or helm
-
-					// TODO one day when our pipelines can include steps conditional on the presence of a file glob
-					// we can just use a single docker/helm package that does docker and/or helm
-					// but for now we've 3 separate packs for docker, docker-helm and helm
-					hasDocker := false
-					hasHelm := false
-
-					if exists, err2 := util.FileExists(filepath.Join(dir, "Dockerfile")); err2 == nil && exists {
-						hasDocker = true
-					}
-
-					// lets check for a helm pack
-					files, err2 := filepath.Glob(filepath.Join(dir, "charts/*/Chart.yaml"))
-					if err2 != nil {
-						return "", errors.Wrapf(err, "failed to detect if there was a chart file in dir %s", dir)
-					}
-					if len(files) == 0 {
-						files, err2 = filepath.Glob(filepath.Join(dir, "*/Chart.yaml"))
-						if err2 != nil {
-							return "", errors.Wrapf(err, "failed to detect if there was a chart file in dir %s", dir)
-						}
-					}
-					if len(files) > 0 {
-						hasHelm = true
-					}
-
-					if hasDocker {
-						if hasHelm {
-							lpack = filepath.Join(packsDir, "docker-helm")
-							err = nil
-						} else {
-							lpack = filepath.Join(packsDir, "docker")
-							err = nil
-						}
-					} else if hasHelm {
-						lpack = filepath.Join(packsDir, "helm")
-						err = nil
-					}
-				}
-				if lpack == "" {
-					// lets check for custom jenkinsfile build pack
-					exists, err2 := util.FileExists(filepath.Join(dir, jenkinsfile.Name))
-					if exists && err2 == nil {
-						i.CreateJenkinsxYamlIfMissing = true
-						disableJenkinsfileCheck = false
-						backupJenkinsfile = false
-						jenkinsfilePath = defaultJenkinsfile
-						lpack = filepath.Join(packsDir, "custom-jenkins")
-						err = nil
-					}
-				}
-				if err != nil {
-					return "", err
-				}
-			}
+		detection, err := buildpacks.DetectBuildPack(dir, packsDir)
+		if err != nil {
+			return "", err
+		}
+		for _, c := range detection.Candidates {
+			log.Logger().Debugf("build pack %s scored %d from %s", c.Pack, c.Score, strings.Join(c.Reasons, ", "))
+		}
+		if detection.Pack == "" {
+			return "", fmt.Errorf("failed to detect a build pack for %s using the packs in %s. Run 'jx step detect' to see why", dir, packsDir)
 		}
+		if detection.Pack == buildpacks.CustomJenkinsBuildPack {
+			i.CreateJenkinsxYamlIfMissing = true
+			disableJenkinsfileCheck = false
+			backupJenkinsfile = false
+			jenkinsfilePath = defaultJenkinsfile
+		}
+		lpack = filepath.Join(packsDir, detection.Pack)
 	}
 	log.Logger().Infof("selected pack: %s", lpack)
 	draftPack := filepath.Base(lpack)
@@ -257,8 +217,22 @@ func (o *CommonOptions) InvokeDraftPack(i *InvokeDraftPack) (string, error) {
 		if err != nil {
 			return draftPack, err
 		}
+		modified := false
 		if pipelineConfig.BuildPack != draftPack {
 			pipelineConfig.BuildPack = draftPack
+			modified = true
+		}
+		if override != nil && override.Image != "" {
+			pc := pipelineConfig.GetOrCreatePipelineConfig()
+			if pc.Agent == nil {
+				pc.Agent = &syntax.Agent{}
+			}
+			if pc.Agent.Image != override.Image {
+				pc.Agent.Image = override.Image
+				modified = true
+			}
+		}
+		if modified {
 			err = pipelineConfig.SaveConfig(jenkinsxYaml)
 			if err != nil {
 				return draftPack, err
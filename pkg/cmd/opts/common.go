@@ -58,6 +58,7 @@ const (
 	OptionBatchMode        = "batch-mode"
 	OptionClusterName      = "cluster-name"
 	OptionEnvironment      = "env"
+	OptionGitImpl          = "git-impl"
 	OptionInstallDeps      = "install-dependencies"
 	OptionLabel            = "label"
 	OptionName             = "name"
@@ -112,6 +113,7 @@ type CommonOptions struct {
 	Domain                 string
 	Err                    io.Writer
 	ExternalJenkinsBaseURL string
+	GitImpl                string
 	In                     terminal.FileReader
 	InstallDependencies    bool
 	ModifyDevEnvironmentFn ModifyDevEnvironmentFn
@@ -238,6 +240,7 @@ func (o *CommonOptions) AddBaseFlags(cmd *cobra.Command) {
 	cmd.PersistentFlags().BoolVarP(&o.BatchMode, OptionBatchMode, "b", defaultBatchMode, "Runs in batch mode without prompting for user input")
 	levels := strings.Join(log.GetLevels(), ", ")
 	cmd.PersistentFlags().BoolVarP(&o.Verbose, OptionVerbose, "", false, fmt.Sprintf("Enables verbose output. The environment variable JX_LOG_LEVEL has precedence over this flag and allows setting the logging level to any value of: %s", levels))
+	cmd.PersistentFlags().StringVarP(&o.GitImpl, OptionGitImpl, "", os.Getenv(gits.GitImplEnvVar), fmt.Sprintf("The git implementation to use, either %s for the git binary or %s for the built in go-git library. Defaults to $%s", gits.GitImplCLI, gits.GitImplGoGit, gits.GitImplEnvVar))
 
 	o.Cmd = cmd
 }
@@ -516,7 +519,16 @@ func (o *CommonOptions) JXClientDevAndAdminNamespace() (versioned.Interface, str
 // Git returns the git client
 func (o *CommonOptions) Git() gits.Gitter {
 	if o.git == nil {
-		o.git = gits.NewGitCLI()
+		impl := o.GitImpl
+		if impl == "" {
+			impl = os.Getenv(gits.GitImplEnvVar)
+		}
+		git, err := gits.NewGitter(impl)
+		if err != nil {
+			log.Logger().Warnf("%s, falling back to the git binary", err)
+			git = gits.NewGitCLI()
+		}
+		o.git = git
 	}
 	return o.git
 }
@@ -549,7 +561,7 @@ func (o *CommonOptions) Helm() helm.Helmer {
 
 			// check helmfile featureflag but default to existing behaviour if there's any issues
 			var helmer helm.Helmer
-			r, _, err := config.LoadRequirementsConfig("", config.DefaultFailOnValidationError)
+			r, _, err := config.LoadRequirementsConfigWithOptions("", config.DefaultFailOnValidationError, o.RequirementsOptions())
 			if err != nil {
 				r = config.NewRequirementsConfig()
 			}
@@ -13,9 +13,11 @@ import (
 	jenkinsv1 "github.com/jenkins-x/jx/v2/pkg/apis/jenkins.io/v1"
 	"github.com/jenkins-x/jx/v2/pkg/auth"
 	"github.com/jenkins-x/jx/v2/pkg/gits"
+	"github.com/jenkins-x/jx/v2/pkg/io/secrets"
 	"github.com/jenkins-x/jx/v2/pkg/issues"
 	"github.com/jenkins-x/jx/v2/pkg/kube"
 	"github.com/jenkins-x/jx/v2/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/secreturl"
 	"github.com/jenkins-x/jx/v2/pkg/util"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
@@ -202,7 +204,7 @@ func (o *CommonOptions) GitServerHostURLKind(hostURL string) (string, error) {
 		if o.BatchMode {
 			return "", fmt.Errorf("No Git server kind could be found for URL %s\nPlease try specify it via: jx create git server someKind %s", hostURL, hostURL)
 		}
-		kind, err = util.PickName(gits.KindGits, fmt.Sprintf("Pick what kind of Git server is: %s", hostURL), "", o.GetIOFileHandles())
+		kind, err = util.PickName(gits.GitKinds(), fmt.Sprintf("Pick what kind of Git server is: %s", hostURL), "", o.GetIOFileHandles())
 		if err != nil {
 			return "", err
 		}
@@ -401,7 +403,8 @@ func (o *CommonOptions) GetPipelineGitHubAppAuth(ghOwner string) (*auth.AuthServ
 	return server, user, nil
 }
 
-// GetPipelineGitAuthForRepo returns the pipeline git authentication credentials for a repo
+// GetPipelineGitAuthForRepo returns the pipeline git authentication credentials for a repo, preferring the credentials
+// scoped most narrowly to the repository
 func (o *CommonOptions) GetPipelineGitAuthForRepo(gitInfo *gits.GitRepository) (*auth.AuthServer, *auth.UserAuth, error) {
 	ghOwner, err := o.GetGitHubAppOwner(gitInfo)
 	if err != nil {
@@ -410,7 +413,196 @@ func (o *CommonOptions) GetPipelineGitAuthForRepo(gitInfo *gits.GitRepository) (
 	if ghOwner != "" {
 		return o.GetPipelineGitHubAppAuth(ghOwner)
 	}
-	return o.GetPipelineGitAuth()
+	authConfig, err := o.getAuthConfig()
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to get auth config")
+	}
+	if gitInfo == nil {
+		server, user := authConfig.GetPipelineAuth()
+		return server, user, nil
+	}
+	server, user := authConfig.GetPipelineAuthForRepository(gitInfo.Organisation, gitInfo.Name)
+	return server, user, nil
+}
+
+// GitCloneURL returns the URL used to clone or push the given git repository. If the current user of the git server
+// authenticates with ssh then the Gitter is configured to use its key and the ssh form of the URL is returned
+func (o *CommonOptions) GitCloneURL(gitURL string) (string, error) {
+	if gitURL == "" {
+		return gitURL, nil
+	}
+	gitInfo, err := gits.ParseGitURL(gitURL)
+	if err != nil {
+		return gitURL, nil
+	}
+	authConfig, err := o.getAuthConfig()
+	if err != nil {
+		log.Logger().Debugf("unable to load the git auth config: %s", err)
+		return gitURL, nil
+	}
+	server := authConfig.GetServer(gitInfo.HostURLWithoutUser())
+	if server == nil {
+		return gitURL, nil
+	}
+	user := server.CurrentAuth()
+	if user == nil || user.SSH == nil {
+		return gitURL, nil
+	}
+	configurer, ok := o.Git().(gits.SSHConfigurer)
+	if !ok {
+		return "", fmt.Errorf("the git implementation does not support ssh authentication for %s", server.URL)
+	}
+	err = configurer.ConfigureSSH(gitURL, user.SSH)
+	if err != nil {
+		return "", errors.Wrapf(err, "configuring ssh authentication for %s", server.URL)
+	}
+	return gits.SSHCloneURL(gitURL)
+}
+
+// ConfigureCommitSigning configures the Gitter to sign its commits if commit signing is enabled in the requirements,
+// reading the signing key from the secret storage
+func (o *CommonOptions) ConfigureCommitSigning(requirements *config.RequirementsConfig) error {
+	signing := requirements.CommitSigning
+	if !signing.IsEnabled() {
+		return nil
+	}
+	if signing.Key == "" {
+		return errors.New("commit signing is enabled but no key is specified in the requirements")
+	}
+	_, _, _, err := secreturl.ParseURI(signing.Key)
+	if err != nil {
+		return fmt.Errorf("the commit signing key %s is not a secret URL", signing.Key)
+	}
+	client, err := o.GetSecretURLClient(secrets.ToSecretsLocation(string(requirements.SecretStorage)))
+	if err != nil {
+		return errors.Wrap(err, "creating the secret URL client to read the commit signing key")
+	}
+	key, err := secreturl.ResolveURI(client, signing.Key)
+	if err != nil {
+		return errors.Wrapf(err, "reading the commit signing key %s", signing.Key)
+	}
+	signer, ok := o.Git().(gits.CommitSigner)
+	if !ok {
+		return errors.New("the git implementation does not support signing commits")
+	}
+	err = signer.ConfigureCommitSigning(&gits.CommitSigning{
+		Format: signing.SigningFormat(),
+		Key:    key,
+		KeyID:  signing.KeyID,
+	})
+	if err != nil {
+		return errors.Wrap(err, "configuring commit signing")
+	}
+	log.Logger().Infof("signing commits with the %s key %s", signing.SigningFormat(), util.ColorInfo(signing.Key))
+	return nil
+}
+
+// ConfigureCommitSigningFromTeamSettings configures commit signing from the requirements stored in the team settings.
+// Nothing is configured if the team settings cannot be loaded, such as when not connected to a cluster
+func (o *CommonOptions) ConfigureCommitSigningFromTeamSettings() error {
+	settings, err := o.TeamSettings()
+	if err != nil {
+		log.Logger().Debugf("unable to load the team settings to configure commit signing: %s", err)
+		return nil
+	}
+	requirements, err := config.GetRequirementsConfigFromTeamSettings(settings)
+	if err != nil {
+		return errors.Wrap(err, "reading the requirements from the team settings")
+	}
+	if requirements == nil {
+		return nil
+	}
+	return o.ConfigureCommitSigning(requirements)
+}
+
+// PullRequestsConfigFromTeamSettings returns the default reviewers and assignees of the pull requests jx generates
+// from the requirements stored in the team settings, or nil if there are none or the team settings cannot be loaded
+func (o *CommonOptions) PullRequestsConfigFromTeamSettings() *config.PullRequestsConfig {
+	requirements := o.requirementsFromTeamSettings("find the default pull request reviewers")
+	if requirements == nil {
+		return nil
+	}
+	return requirements.PullRequests
+}
+
+// PullRequestProvenance returns the provenance of a change of the given kind generated by jx, including the version
+// stream in the team settings if they can be loaded. The SHA the version stream is cloned at is recorded, falling back
+// to its configured ref if it cannot be cloned. The git user is assumed to have triggered the change unless the
+// pipeline says otherwise
+func (o *CommonOptions) PullRequestProvenance(kind string) *gits.Provenance {
+	provenance := gits.NewProvenance(kind)
+	if provenance.TriggeredBy == "" {
+		username, err := o.Git().Username("")
+		if err == nil {
+			provenance.TriggeredBy = username
+		}
+	}
+	requirements := o.requirementsFromTeamSettings("find the version stream")
+	if requirements != nil {
+		provenance.VersionStreamURL = requirements.VersionStream.URL
+		provenance.VersionStreamRef = requirements.VersionStream.Ref
+		sha, err := o.versionStreamSHA()
+		if err != nil {
+			log.Logger().Debugf("unable to find the SHA of the version stream so recording its ref %s: %s", requirements.VersionStream.Ref, err)
+		} else {
+			provenance.VersionStreamRef = sha
+		}
+	}
+	return provenance
+}
+
+// versionStreamSHA returns the SHA of the HEAD of the cloned version stream
+func (o *CommonOptions) versionStreamSHA() (string, error) {
+	resolver, err := o.GetVersionResolver()
+	if err != nil {
+		return "", errors.Wrap(err, "cloning the version stream")
+	}
+	sha, err := o.Git().GetLatestCommitSha(resolver.VersionsDir)
+	if err != nil {
+		return "", errors.Wrapf(err, "getting the HEAD of the version stream in %s", resolver.VersionsDir)
+	}
+	return sha, nil
+}
+
+// requirementsFromTeamSettings returns the requirements stored in the team settings, or nil if they cannot be loaded
+// such as when not connected to a cluster
+func (o *CommonOptions) requirementsFromTeamSettings(purpose string) *config.RequirementsConfig {
+	settings, err := o.TeamSettings()
+	if err != nil {
+		log.Logger().Debugf("unable to load the team settings to %s: %s", purpose, err)
+		return nil
+	}
+	requirements, err := config.GetRequirementsConfigFromTeamSettings(settings)
+	if err != nil || requirements == nil {
+		log.Logger().Debugf("unable to read the requirements from the team settings to %s: %v", purpose, err)
+		return nil
+	}
+	return requirements
+}
+
+// ConfigureGitMirrors makes the Gitter clone repositories from the mirrors in the team settings before falling back
+// to their origin
+func (o *CommonOptions) ConfigureGitMirrors(settings *jenkinsv1.TeamSettings) {
+	if settings == nil || len(settings.GitMirrors) == 0 {
+		return
+	}
+	configurer, ok := o.Git().(gits.MirrorConfigurer)
+	if !ok {
+		log.Logger().Warnf("the git implementation does not support cloning from mirrors")
+		return
+	}
+	configurer.ConfigureMirrors(settings.GitMirrors)
+}
+
+// ConfigureGitMirrorsFromTeamSettings makes the Gitter clone repositories from the mirrors in the team settings.
+// Nothing is configured if the team settings cannot be loaded, such as when not connected to a cluster
+func (o *CommonOptions) ConfigureGitMirrorsFromTeamSettings() {
+	settings, err := o.TeamSettings()
+	if err != nil {
+		log.Logger().Debugf("unable to load the team settings to configure git mirrors: %s", err)
+		return
+	}
+	o.ConfigureGitMirrors(settings)
 }
 
 // DisableFeatures iterates over all the repositories in org (except those that match excludes) and disables issue
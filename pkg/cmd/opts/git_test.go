@@ -0,0 +1,50 @@
+// +build unit
+
+package opts
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	v1 "github.com/jenkins-x/jx/v2/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/v2/pkg/gits"
+	"github.com/jenkins-x/jx/v2/pkg/kube"
+	"github.com/jenkins-x/jx/v2/pkg/versionstream"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPullRequestProvenanceRecordsTheVersionStreamSHA(t *testing.T) {
+	versionsDir, err := ioutil.TempDir("", "test-provenance")
+	require.NoError(t, err)
+	defer os.RemoveAll(versionsDir) //nolint:errcheck
+
+	gitter := gits.NewGitCLI()
+	require.NoError(t, gitter.Init(versionsDir))
+	require.NoError(t, gitter.Config(versionsDir, "user.name", "test-user"))
+	require.NoError(t, gitter.Config(versionsDir, "user.email", "test-user@example.com"))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(versionsDir, "README.md"), []byte("versions"), 0600))
+	require.NoError(t, gitter.Add(versionsDir, "README.md"))
+	require.NoError(t, gitter.CommitDir(versionsDir, "initial commit"))
+	sha, err := gitter.GetLatestCommitSha(versionsDir)
+	require.NoError(t, err)
+
+	devEnv := kube.CreateDefaultDevEnvironment("jx")
+	devEnv.Spec.TeamSettings.BootRequirements = "versionStream:\n  url: https://github.com/jenkins-x/jenkins-x-versions.git\n  ref: master\n"
+	o := &CommonOptions{}
+	o.SetGit(gitter)
+	o.ModifyDevEnvironmentFn = func(callback func(env *v1.Environment) error) error {
+		return callback(devEnv)
+	}
+
+	o.SetVersionResolver(&versionstream.VersionResolver{VersionsDir: versionsDir})
+	provenance := o.PullRequestProvenance("promote")
+	assert.Equal(t, "https://github.com/jenkins-x/jenkins-x-versions.git", provenance.VersionStreamURL)
+	assert.Equal(t, sha, provenance.VersionStreamRef, "the SHA of the cloned version stream should be recorded")
+
+	o.SetVersionResolver(&versionstream.VersionResolver{VersionsDir: filepath.Join(versionsDir, "missing")})
+	provenance = o.PullRequestProvenance("promote")
+	assert.Equal(t, "master", provenance.VersionStreamRef, "the configured ref should be recorded if the SHA is unknown")
+}
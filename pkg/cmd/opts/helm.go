@@ -19,6 +19,7 @@ import (
 
 	"github.com/jenkins-x/jx/v2/pkg/environments"
 
+	"github.com/jenkins-x/jx/v2/pkg/externalsecrets"
 	"github.com/jenkins-x/jx/v2/pkg/helm"
 	"github.com/jenkins-x/jx/v2/pkg/kube"
 	"github.com/jenkins-x/jx/v2/pkg/kube/services"
@@ -27,6 +28,7 @@ import (
 	"github.com/pkg/errors"
 	rbacv1 "k8s.io/api/rbac/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
 )
 
 const (
@@ -39,7 +41,7 @@ const (
 	// DefaultOnlyHelmClient indicates if only the client is initialized
 	DefaultOnlyHelmClient = false
 	// DefaultHelm3 indicates if helm 3 is used
-	DefaultHelm3 = false
+	DefaultHelm3 = true
 	// DefaultSkipTiller skips the tiller server initialization
 	DefaultSkipTiller = false
 	// DefaultGlobalTiller indicates if a global tiller server is used
@@ -395,6 +397,13 @@ func (o *CommonOptions) InstallChartWithOptionsAndTimeout(options helm.InstallCh
 	if err != nil {
 		return err
 	}
+	return o.InstallChartOnCluster(options, o.Helm(), client, timeout)
+}
+
+// InstallChartOnCluster uses the options and the timeout to run helm install or helm upgrade with the helm and kube
+// clients of the cluster the chart is installed in
+func (o *CommonOptions) InstallChartOnCluster(options helm.InstallChartOptions, helmer helm.Helmer, client kubernetes.Interface, timeout string) error {
+	var err error
 	if options.VersionsDir == "" {
 		options.VersionsDir, _, err = o.CloneJXVersionsRepo(options.VersionsGitURL, options.VersionsGitRef)
 		if err != nil {
@@ -405,7 +414,18 @@ func (o *CommonOptions) InstallChartWithOptionsAndTimeout(options helm.InstallCh
 	if err != nil {
 		return errors.Wrap(err, "failed to create a Secret RL client")
 	}
-	return helm.InstallFromChartOptions(options, o.Helm(), client, timeout, secretURLClient)
+	return helm.InstallFromChartOptions(options, helmer, client, timeout, secretURLClient)
+}
+
+// RequirementsOptions returns the options used to load and save the requirements. The requirements profiles are
+// enabled via $JX_REQUIREMENTS_PROFILE and the secret URLs of any sensitive requirements are resolved using the
+// secret URL client of the requirements secret storage
+func (o *CommonOptions) RequirementsOptions() config.RequirementsOptions {
+	options := config.DefaultRequirementsOptions()
+	options.SecretURLClientFactory = func(storage config.SecretStorageType) (secreturl.Client, error) {
+		return o.GetSecretURLClient(secrets.ToSecretsLocation(string(storage)))
+	}
+	return options
 }
 
 // GetSecretURLClient create a new secret URL client base on a given secrets location. If the location is auto,
@@ -427,6 +447,12 @@ func (o *CommonOptions) GetSecretURLClient(location secrets.SecretsLocationKind)
 			return o.secretURLClient, errors.Wrapf(err, "getting the file system secrets directory")
 		}
 		o.secretURLClient = localvault.NewFileSystemClient(dir)
+	case secrets.ExternalSecretsLocationKind:
+		kubeClient, ns, err := o.KubeClientAndDevNamespace()
+		if err != nil {
+			return o.secretURLClient, errors.Wrapf(err, "creating the kube client")
+		}
+		o.secretURLClient = externalsecrets.NewClient(kubeClient, ns)
 	case secrets.AutoLocationKind:
 		location := o.detectSecretsLocation()
 		o.secretURLClient, err = o.GetSecretURLClient(location)
@@ -572,6 +598,10 @@ func (o *CommonOptions) AddChartRepos(dir string, helmBinary string, chartRepos
 					if err != nil {
 						return errors.Wrapf(err, "failed to add Helm repository '%s'", repo)
 					}
+					if helm.IsOCIRepository(repo) {
+						// helm resolves dependencies in OCI registries directly from the repository URL
+						continue
+					}
 					dep.Repository = fmt.Sprintf("@%s", name)
 					changed = true
 				}
@@ -815,6 +845,20 @@ func (o *CommonOptions) ReleaseChartRepositoryURL() string {
 	return chartRepo
 }
 
+// ReleaseChartRepositoryKind returns the kind of chart repository charts are released to, configured in the
+// requirements, along with the URL of the bucket used to store the charts of bucket repositories
+func (o *CommonOptions) ReleaseChartRepositoryKind() (config.ChartRepositoryType, string) {
+	requirements := o.requirementsFromTeamSettings("find the kind of chart repository")
+	if requirements == nil {
+		return config.ChartRepositoryTypeChartMuseum, ""
+	}
+	kind := requirements.Cluster.ChartKind
+	if kind == "" {
+		kind = config.ChartRepositoryTypeChartMuseum
+	}
+	return kind, requirements.Storage.Repository.URL
+}
+
 // EnsureHelm ensures helm is installed
 func (o *CommonOptions) EnsureHelm() error {
 	_, err := o.Helm().Version(false)
@@ -0,0 +1,82 @@
+package opts
+
+import (
+	"strings"
+
+	"github.com/jenkins-x/jx/v2/pkg/config"
+	"github.com/jenkins-x/jx/v2/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/packages"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+)
+
+// PreviewsConfigFromTeamSettings returns the team defaults of the lifecycle of the preview environments, which are
+// empty if there are none or the team settings cannot be loaded
+func (o *CommonOptions) PreviewsConfigFromTeamSettings() *config.PreviewsConfig {
+	settings, err := o.TeamSettings()
+	if err != nil {
+		log.Logger().Debugf("failed to load the team settings: %s", err)
+		return &config.PreviewsConfig{}
+	}
+	requirements, err := config.GetRequirementsConfigFromTeamSettings(settings)
+	if err != nil {
+		log.Logger().Warnf("failed to load the requirements from the team settings: %s", err)
+		return &config.PreviewsConfig{}
+	}
+	if requirements == nil || requirements.Previews == nil {
+		return &config.PreviewsConfig{}
+	}
+	return requirements.Previews
+}
+
+// DeletePreviewImage deletes the tag of the image of a preview environment from its container registry using crane,
+// only warning if crane is not installed. As registries delete images by digest the image is kept if another tag
+// such as a release points to the same digest
+func (o *CommonOptions) DeletePreviewImage(image string) error {
+	if image == "" || strings.HasSuffix(image, ":") {
+		return nil
+	}
+	if _, err := packages.LookupForBinary("crane"); err != nil {
+		log.Logger().Warnf("not deleting the image %s as crane is not installed", util.ColorInfo(image))
+		return nil
+	}
+	digest, err := craneOutput("digest", image)
+	if err != nil {
+		return errors.Wrapf(err, "resolving the digest of image %s", image)
+	}
+	separator := strings.LastIndex(image, ":")
+	repository, tag := image[:separator], image[separator+1:]
+	out, err := craneOutput("ls", repository)
+	if err != nil {
+		return errors.Wrapf(err, "listing the tags of repository %s", repository)
+	}
+	for _, other := range strings.Fields(out) {
+		if other == tag {
+			continue
+		}
+		otherDigest, err := craneOutput("digest", repository+":"+other)
+		if err != nil {
+			return errors.Wrapf(err, "resolving the digest of image %s:%s", repository, other)
+		}
+		if otherDigest == digest {
+			log.Logger().Warnf("not deleting the image %s as the tag %s points to the same digest", util.ColorInfo(image), util.ColorInfo(other))
+			return nil
+		}
+	}
+	_, err = craneOutput("delete", repository+"@"+digest)
+	if err != nil {
+		return errors.Wrapf(err, "deleting image %s", image)
+	}
+	log.Logger().Infof("Deleted image %s", util.ColorInfo(image))
+	return nil
+}
+
+// craneOutput runs crane returning its trimmed output
+func craneOutput(args ...string) (string, error) {
+	cmd := util.Command{
+		Name: "crane",
+		Args: args,
+	}
+	out, err := cmd.RunWithoutRetry()
+	return strings.TrimSpace(out), err
+}
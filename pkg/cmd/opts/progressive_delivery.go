@@ -0,0 +1,25 @@
+package opts
+
+import (
+	"github.com/jenkins-x/jx/v2/pkg/config"
+	"github.com/jenkins-x/jx/v2/pkg/log"
+)
+
+// ProgressiveDeliveryConfigFromTeamSettings returns the progressive delivery configuration of the requirements stored
+// in the team settings, which is disabled if there is none or the team settings cannot be loaded
+func (o *CommonOptions) ProgressiveDeliveryConfigFromTeamSettings() *config.ProgressiveDeliveryConfig {
+	settings, err := o.TeamSettings()
+	if err != nil {
+		log.Logger().Debugf("failed to load the team settings: %s", err)
+		return &config.ProgressiveDeliveryConfig{}
+	}
+	requirements, err := config.GetRequirementsConfigFromTeamSettings(settings)
+	if err != nil {
+		log.Logger().Warnf("failed to load the requirements from the team settings: %s", err)
+		return &config.ProgressiveDeliveryConfig{}
+	}
+	if requirements == nil || requirements.ProgressiveDelivery == nil {
+		return &config.ProgressiveDeliveryConfig{}
+	}
+	return requirements.ProgressiveDelivery
+}
@@ -0,0 +1,20 @@
+package opts
+
+import (
+	"github.com/jenkins-x/jx/v2/pkg/config"
+)
+
+// PromotionApprovalFromTeamSettings returns the approvals required to promote to the environment with the given key
+// from the requirements stored in the team settings, or nil if promotions need no approval or the team settings cannot
+// be loaded
+func (o *CommonOptions) PromotionApprovalFromTeamSettings(envKey string) *config.PromotionApprovalConfig {
+	requirements := o.requirementsFromTeamSettings("find the promotion approvers")
+	if requirements == nil {
+		return nil
+	}
+	env, err := requirements.Environment(envKey)
+	if err != nil || !env.Approval.IsRequired() {
+		return nil
+	}
+	return env.Approval
+}
@@ -0,0 +1,20 @@
+package opts
+
+import (
+	"github.com/jenkins-x/jx/v2/pkg/config"
+)
+
+// EnvironmentFreezesFromTeamSettings returns the configuration of the environment with the given key from the
+// requirements stored in the team settings if it has freeze windows, or nil if it has none or the team settings cannot
+// be loaded
+func (o *CommonOptions) EnvironmentFreezesFromTeamSettings(envKey string) *config.EnvironmentConfig {
+	requirements := o.requirementsFromTeamSettings("find the freeze windows")
+	if requirements == nil {
+		return nil
+	}
+	env, err := requirements.Environment(envKey)
+	if err != nil || len(env.Freezes) == 0 {
+		return nil
+	}
+	return env
+}
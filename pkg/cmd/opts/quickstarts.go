@@ -57,7 +57,7 @@ func (o *CommonOptions) LoadQuickStartsFromLocations(locations []v1.QuickStartLo
 			m = map[string]v1.QuickStartLocation{}
 			gitMap[loc.GitURL] = m
 		}
-		m[loc.Owner] = loc
+		m[loc.Owner+loc.CatalogURL] = loc
 	}
 	model := quickstarts.NewQuickstartModel()
 
@@ -74,12 +74,31 @@ func (o *CommonOptions) LoadQuickStartsFromLocations(locations []v1.QuickStartLo
 			if kube.IsDefaultQuickstartLocation(location) && (userAuth == nil || userAuth.IsInvalid()) {
 				continue
 			}
-			gitProvider, err := o.GitProviderForGitServerURL(gitURL, kind, "")
-			if err != nil {
-				return model, err
+
+			// a public catalog YAML file outside of a git server needs no git provider
+			var gitProvider gits.GitProvider
+			if gitURL != "" {
+				var err error
+				gitProvider, err = o.GitProviderForGitServerURL(gitURL, kind, "")
+				if err != nil {
+					return model, err
+				}
+			}
+			if location.CatalogURL != "" {
+				log.Logger().Debugf("Loading the quickstarts catalog %s", location.CatalogURL)
+				catalog, err := quickstarts.LoadCatalog(location.CatalogURL, gitProvider)
+				if err != nil {
+					log.Logger().Warnf("Failed to load the quickstarts catalog %s: %s", quickstarts.CatalogName(&location), err.Error())
+					continue
+				}
+				model.LoadCatalogQuickstarts(gitProvider, &location, catalog)
+				continue
+			}
+			if gitProvider == nil {
+				continue
 			}
 			log.Logger().Debugf("Searching for repositories in Git server %s owner %s includes %s excludes %s as user %s ", gitProvider.ServerURL(), location.Owner, strings.Join(location.Includes, ", "), strings.Join(location.Excludes, ", "), gitProvider.CurrentUsername())
-			err = model.LoadGithubQuickstarts(gitProvider, location.Owner, location.Includes, location.Excludes)
+			err := model.LoadLocationQuickstarts(gitProvider, &location)
 			if err != nil {
 				log.Logger().Debugf("Quickstart load error: %s", err.Error())
 			}
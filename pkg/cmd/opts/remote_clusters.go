@@ -0,0 +1,136 @@
+package opts
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	v1 "github.com/jenkins-x/jx/v2/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/v2/pkg/config"
+	"github.com/jenkins-x/jx/v2/pkg/helm"
+	"github.com/jenkins-x/jx/v2/pkg/kube"
+	"github.com/jenkins-x/jx/v2/pkg/log"
+	"github.com/pkg/errors"
+	"k8s.io/client-go/kubernetes"
+)
+
+// RemoteClusterForEnvironment returns the remote cluster from the boot requirements which runs the given environment
+// or nil if the environment runs in the development cluster. A preview environment runs in the cluster it was created in
+func (o *CommonOptions) RemoteClusterForEnvironment(env *v1.Environment) (*config.RemoteClusterConfig, error) {
+	requirements, err := o.requirementsFromTeamSettings()
+	if err != nil {
+		return nil, err
+	}
+	if requirements == nil {
+		return nil, nil
+	}
+	if env.Spec.Kind == v1.EnvironmentKindTypePreview {
+		if env.Spec.Cluster == "" {
+			return nil, nil
+		}
+		cluster := requirements.RemoteCluster(env.Spec.Cluster)
+		if cluster == nil {
+			return nil, fmt.Errorf("the cluster %s of preview environment %s is not in the clusters of the requirements", env.Spec.Cluster, env.Name)
+		}
+		return cluster, nil
+	}
+	return requirements.ClusterForEnvironment(env.Name), nil
+}
+
+// PreviewCluster returns the remote cluster from the boot requirements which runs new preview environments or nil if
+// they run in the development cluster
+func (o *CommonOptions) PreviewCluster() (*config.RemoteClusterConfig, error) {
+	requirements, err := o.requirementsFromTeamSettings()
+	if err != nil {
+		return nil, err
+	}
+	return requirements.PreviewCluster()
+}
+
+func (o *CommonOptions) requirementsFromTeamSettings() (*config.RequirementsConfig, error) {
+	jxClient, ns, err := o.JXClientAndDevNamespace()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create the jx client")
+	}
+	teamSettings, err := kube.GetDevEnvTeamSettings(jxClient, ns)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to find the team settings in namespace %s", ns)
+	}
+	requirements, err := config.GetRequirementsConfigFromTeamSettings(teamSettings)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get the requirements from the team settings")
+	}
+	return requirements, nil
+}
+
+// KubeClientForEnvironment returns the kube client and namespace used to access the resources of the given environment.
+// If the boot requirements declare a remote cluster with a kube context for the environment then a client for that
+// context is returned, otherwise the client of the current cluster is used
+func (o *CommonOptions) KubeClientForEnvironment(env *v1.Environment) (kubernetes.Interface, string, error) {
+	ns := env.Spec.Namespace
+	cluster, err := o.RemoteClusterForEnvironment(env)
+	if err != nil {
+		log.Logger().Debugf("failed to find the remote cluster of environment %s: %s", env.Name, err.Error())
+	}
+	if cluster == nil || cluster.Context == "" {
+		kubeClient, err := o.KubeClient()
+		return kubeClient, ns, err
+	}
+	if cluster.Namespace != "" && env.Spec.Kind != v1.EnvironmentKindTypePreview {
+		ns = cluster.Namespace
+	}
+	kubeClient, err := kube.CreateKubeClientForContext(cluster.Context)
+	if err != nil {
+		return nil, ns, errors.Wrapf(err, "failed to create the kube client for cluster %s of environment %s", cluster.Name, env.Name)
+	}
+	return kubeClient, ns, nil
+}
+
+// ClientsForEnvironment returns the kube client and the helm client used to manage the resources and releases of the
+// given environment, which access the remote cluster of the environment if it has one, along with a function removing
+// the temporary files of the helm client once it is no longer used
+func (o *CommonOptions) ClientsForEnvironment(env *v1.Environment) (kubernetes.Interface, helm.Helmer, func(), error) {
+	cluster, err := o.RemoteClusterForEnvironment(env)
+	if err != nil {
+		return nil, nil, nil, errors.Wrapf(err, "failed to find the remote cluster of environment %s", env.Name)
+	}
+	if cluster == nil || cluster.Context == "" {
+		kubeClient, err := o.KubeClient()
+		return kubeClient, o.Helm(), func() {}, err
+	}
+	kubeClient, err := kube.CreateKubeClientForContext(cluster.Context)
+	if err != nil {
+		return nil, nil, nil, errors.Wrapf(err, "failed to create the kube client for cluster %s", cluster.Name)
+	}
+	helmer, cleanup, err := o.HelmForCluster(cluster, kubeClient, env.Spec.Namespace)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return kubeClient, helmer, cleanup, nil
+}
+
+// HelmForCluster returns a helm client which runs its commands against the remote cluster using the kube context of
+// the cluster, so that the releases of environments running in the cluster can be installed and deleted. The returned
+// function removes the kube config written for the helm client
+func (o *CommonOptions) HelmForCluster(cluster *config.RemoteClusterConfig, kubeClient kubernetes.Interface, ns string) (helm.Helmer, func(), error) {
+	helmBinary, _, helmTemplate, err := o.TeamHelmBin()
+	if err != nil {
+		return nil, nil, err
+	}
+	kubeConfig, err := kube.WriteKubeConfigForContext(cluster.Context)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "failed to write the kube config of cluster %s", cluster.Name)
+	}
+	cleanup := func() {
+		err := os.RemoveAll(filepath.Dir(kubeConfig))
+		if err != nil {
+			log.Logger().Warnf("failed to remove the kube config %s: %s", kubeConfig, err)
+		}
+	}
+	helmCLI := helm.NewHelmCLIWithCompatibilityCheck(helmBinary, helm.V2, "", o.Verbose)
+	helmCLI.Runner.SetEnvVariable("KUBECONFIG", kubeConfig)
+	if helmTemplate {
+		return helm.NewHelmTemplate(helmCLI, "", kubeClient, ns), cleanup, nil
+	}
+	return helmCLI, cleanup, nil
+}
@@ -0,0 +1,84 @@
+package opts
+
+import (
+	"fmt"
+
+	"github.com/jenkins-x/jx/v2/pkg/config"
+	"github.com/jenkins-x/jx/v2/pkg/kube"
+	"github.com/jenkins-x/jx/v2/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/packages"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+)
+
+// InstallSyft installs syft, which generates the SBOMs of images
+func (o *CommonOptions) InstallSyft() error {
+	return packages.InstallOrUpdateBinary(packages.InstallOrUpdateBinaryOptions{
+		Binary:              "syft",
+		DownloadUrlTemplate: "https://github.com/anchore/syft/releases/download/v{{.version}}/syft_{{.version}}_{{.os}}_{{.arch}}.{{.extension}}",
+		Version:             packages.SyftVersion,
+		Archived:            true,
+	})
+}
+
+// InstallCosign installs cosign, which signs images and verifies their signatures
+func (o *CommonOptions) InstallCosign() error {
+	return packages.InstallOrUpdateBinary(packages.InstallOrUpdateBinaryOptions{
+		Binary:              "cosign",
+		DownloadUrlTemplate: "https://github.com/sigstore/cosign/releases/download/v{{.version}}/cosign-{{.os}}-{{.arch}}",
+		Version:             packages.CosignVersion,
+	})
+}
+
+// SupplyChainConfigFromTeamSettings returns the supply chain configuration of the requirements stored in the team
+// settings, which is empty if there is none or the team settings cannot be loaded
+func (o *CommonOptions) SupplyChainConfigFromTeamSettings() *config.SupplyChainConfig {
+	settings, err := o.TeamSettings()
+	if err != nil {
+		log.Logger().Debugf("failed to load the team settings: %s", err)
+		return &config.SupplyChainConfig{}
+	}
+	requirements, err := config.GetRequirementsConfigFromTeamSettings(settings)
+	if err != nil {
+		log.Logger().Warnf("failed to load the requirements from the team settings: %s", err)
+		return &config.SupplyChainConfig{}
+	}
+	if requirements == nil || requirements.SupplyChain == nil {
+		return &config.SupplyChainConfig{}
+	}
+	return requirements.SupplyChain
+}
+
+// CosignKey returns the cosign key to sign images with or verify them against, defaulting to the key pair in the
+// jx-cosign secret of the dev namespace
+func (o *CommonOptions) CosignKey(key string) (string, error) {
+	if key == "" {
+		key = o.SupplyChainConfigFromTeamSettings().Key
+	}
+	if key != "" {
+		return key, nil
+	}
+	_, ns, err := o.KubeClientAndDevNamespace()
+	if err != nil {
+		return "", errors.Wrap(err, "finding the dev namespace")
+	}
+	return fmt.Sprintf("k8s://%s/%s", ns, kube.SecretCosign), nil
+}
+
+// VerifyImageSignature verifies the image was signed by the cosign key
+func (o *CommonOptions) VerifyImageSignature(image string, key string) error {
+	err := o.InstallCosign()
+	if err != nil {
+		return errors.Wrap(err, "installing cosign")
+	}
+	cmd := util.Command{
+		Name: "cosign",
+		Args: []string{"verify", "--key", key, image},
+	}
+	_, err = cmd.RunWithoutRetry()
+	if err != nil {
+		return errors.Wrapf(err, "verifying the signature of image %s", image)
+	}
+	log.Logger().Infof("verified the signature of image %s", util.ColorInfo(image))
+	return nil
+}
@@ -169,6 +169,11 @@ func (o *CommonOptions) ConfigureCommonOptions(requirements *config.Requirements
 		// if using helmfile lets disable eagerly creating the dev Environment and instead lets create that via helm
 		o.ModifyDevEnvironmentFn = o.HelmfileModifyDevEnvironment
 	}
+	// the secret storage may not be available yet when booting a new cluster so commits are left unsigned
+	err := o.ConfigureCommitSigning(requirements)
+	if err != nil {
+		log.Logger().Warnf("commits will not be signed: %s", err)
+	}
 	return nil
 }
 
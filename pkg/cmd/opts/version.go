@@ -147,5 +147,12 @@ func (o *CommonOptions) CloneJXVersionsRepo(versionRepository string, versionRef
 	if err != nil {
 		log.Logger().Debugf("Unable to load team settings because %v", err)
 	}
+	o.ConfigureGitMirrors(settings)
+	if versionRepository != "" {
+		versionRepository, err = o.GitCloneURL(versionRepository)
+		if err != nil {
+			return "", "", err
+		}
+	}
 	return versionstreamrepo.CloneJXVersionsRepo(versionRepository, versionRef, settings, o.Git(), o.BatchMode, o.AdvancedMode, o.GetIOFileHandles())
 }
@@ -12,11 +12,8 @@ import (
 
 	"github.com/jenkins-x/jx/v2/pkg/builds"
 
-	"github.com/jenkins-x/jx/v2/pkg/cmd/opts/step"
-
 	"github.com/jenkins-x/jx/v2/pkg/cmd/helper"
 	"github.com/jenkins-x/jx/v2/pkg/cmd/promote"
-	"github.com/jenkins-x/jx/v2/pkg/cmd/step/pr"
 	"github.com/jenkins-x/jx/v2/pkg/kube/naming"
 
 	"github.com/pkg/errors"
@@ -36,6 +33,7 @@ import (
 	"github.com/jenkins-x/jx/v2/pkg/gits"
 	"github.com/jenkins-x/jx/v2/pkg/kube"
 	"github.com/jenkins-x/jx/v2/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/previews"
 	"github.com/jenkins-x/jx/v2/pkg/util"
 	kserve "github.com/knative/serving/pkg/client/clientset/versioned"
 	"github.com/spf13/cobra"
@@ -49,6 +47,31 @@ var (
 	previewLong = templates.LongDesc(`
 		Creates or updates a Preview Environment for the given Pull Request or Branch.
 
+		The 'previewEnvironments.dependencies' of the 'jenkins-x.yml' can list other services to preview the change with.
+		A dependency with a 'version' is deployed into the Preview Environment while a dependency with a 'pullRequest'
+		such as 'myorg/orders#12' is connected to the service of the Preview Environment of that Pull Request.
+
+		The 'previewEnvironments.fixtures' can list resources such as a 'postgres' schema, seeded from a SQL file of the
+		repository, or the output of a 'command' which are provisioned once for the Preview Environment and destroyed
+		with it. Their connection details are stored in a Secret in the Preview Environment named after the fixture.
+
+		If 'previews.scaleToZero' is enabled in the requirements of the team the Preview Environment scales to zero pods
+		when idle, either by deploying the application as a Knative service or by routing its ingresses through the
+		interceptor of the KEDA HTTP add-on, and is woken by its next request.
+
+		If 'previews.protection' is configured in the requirements of the team the ingresses of the Preview Environment
+		require either basic auth, whose generated credentials are commented on the Pull Request, or signing in with an
+		OAuth2 Proxy.
+
+		If 'previews.budget' is configured in the requirements of the team a new Preview Environment is only created
+		within the maximum number of Preview Environments of the team and of its repository, which the
+		'previewEnvironments.maximumInstances' of the 'jenkins-x.yml' can lower, evicting the least recently updated
+		Preview Environments if 'evictOldest' is enabled. The namespace of the Preview Environment is limited by a
+		ResourceQuota and LimitRange named 'preview-budget'.
+
+		If 'previews.cluster' names a cluster from the 'clusters' of the requirements of the team the Preview Environment
+		is created in that cluster, using its kube context, rather than in the development cluster.
+
 		For more documentation on Preview Environments see: [https://jenkins-x.io/about/features/#preview-environments](https://jenkins-x.io/about/features/#preview-environments)
 
 `)
@@ -201,10 +224,6 @@ func (o *PreviewOptions) Run() error {
 	if err != nil {
 		return err
 	}
-	kserveClient, _, err := o.KnativeServeClient()
-	if err != nil {
-		return err
-	}
 	apisClient, err := o.ApiExtensionsClient()
 	if err != nil {
 		return err
@@ -240,6 +259,10 @@ func (o *PreviewOptions) Run() error {
 	if err != nil {
 		return err
 	}
+	dependencies, err := PreviewDependencies(projectConfig)
+	if err != nil {
+		return err
+	}
 
 	if o.GitInfo == nil {
 		log.Logger().Warnf("No GitInfo found")
@@ -348,11 +371,18 @@ func (o *PreviewOptions) Run() error {
 		}
 	}
 
+	var previewConfig *config.PreviewEnvironmentConfig
+	if projectConfig != nil {
+		previewConfig = projectConfig.PreviewEnvironments
+	}
+	previewsConfig := o.PreviewsConfigFromTeamSettings()
+	ttl, idleTimeout := config.PreviewLifecycle(previewsConfig, previewConfig)
+
 	environmentsResource := jxClient.JenkinsV1().Environments(ns)
 	env, err := environmentsResource.Get(o.Name, metav1.GetOptions{})
 	if err == nil {
 		// lets check for updates...
-		update := false
+		update := kube.SetPreviewLifecycle(env, ttl, idleTimeout, time.Now())
 
 		spec := &env.Spec
 		source := &spec.Source
@@ -429,6 +459,16 @@ func (o *PreviewOptions) Run() error {
 			}
 		}
 	} else {
+		err = o.EnforcePreviewBudget(kubeClient, jxClient, ns, previewsConfig.Budget, previewConfig)
+		if err != nil {
+			return err
+		}
+		var cluster string
+		cluster, err = o.previewClusterName()
+		if err != nil {
+			return err
+		}
+
 		// lets create a new preview environment
 		previewGitSpec := v1.PreviewGitSpec{
 			ApplicationName: o.Application,
@@ -454,6 +494,7 @@ func (o *PreviewOptions) Run() error {
 			Spec: v1.EnvironmentSpec{
 				Namespace:         o.Namespace,
 				Label:             o.Label,
+				Cluster:           cluster,
 				Kind:              v1.EnvironmentKindTypePreview,
 				PromotionStrategy: v1.PromotionStrategyTypeAutomatic,
 				PullRequestURL:    o.PullRequestURL,
@@ -466,7 +507,8 @@ func (o *PreviewOptions) Run() error {
 				PreviewGitSpec: previewGitSpec,
 			},
 		}
-		_, err = environmentsResource.Create(env)
+		kube.SetPreviewLifecycle(env, ttl, idleTimeout, time.Now())
+		env, err = environmentsResource.Create(env)
 		if err != nil {
 			return fmt.Errorf("Failed to create environment in namespace %s due to: %s", ns, err)
 		}
@@ -478,15 +520,57 @@ func (o *PreviewOptions) Run() error {
 		return err
 	}
 
+	previewCluster, err := o.clientsForPreviewCluster(env, ns)
+	if err != nil {
+		return err
+	}
+	defer previewCluster.cleanup()
+	previewKubeClient := previewCluster.kubeClient
+
+	err = o.ApplyPreviewBudget(previewKubeClient, previewsConfig.Budget)
+	if err != nil {
+		return err
+	}
+
+	err = o.ConnectPreviewDependencies(previewKubeClient, jxClient, ns, dependencies)
+	if err != nil {
+		return err
+	}
+
+	err = o.ProvisionPreviewFixtures(kubeClient, previewKubeClient, environmentsResource, env, ns, previewConfig)
+	if err != nil {
+		return err
+	}
+
 	domain, err := kube.GetCurrentDomain(kubeClient, ns)
 	if err != nil {
 		return err
 	}
+	if env.Spec.Cluster != "" {
+		// lets use the domain of the preview cluster if it has an ingress config
+		if previewDomain, err := kube.GetCurrentDomain(previewKubeClient, ns); err == nil && previewDomain != "" {
+			domain = previewDomain
+		}
+	}
 
 	values, err := o.GetPreviewValuesConfig(projectConfig, domain)
 	if err != nil {
 		return err
 	}
+	scaleToZero := previewsConfig.ScaleToZeroProvider()
+	if scaleToZero == config.ScaleToZeroProviderKnative {
+		values.Preview.KnativeDeploy = true
+	}
+
+	// lets record the image so that its tag can be deleted from the registry when the preview is garbage collected
+	image := values.Preview.Image.Repository + ":" + values.Preview.Image.Tag
+	if values.Preview.Image.Tag != "" && env.Annotations[kube.AnnotationPreviewImage] != image {
+		env.Annotations[kube.AnnotationPreviewImage] = image
+		env, err = environmentsResource.PatchUpdate(env)
+		if err != nil {
+			return fmt.Errorf("Failed to update Environment %s due to %s", o.Name, err)
+		}
+	}
 
 	config, err := values.String()
 	if err != nil {
@@ -498,6 +582,11 @@ func (o *PreviewOptions) Run() error {
 		return err
 	}
 
+	err = o.AddPreviewDependencies(dir, dependencies)
+	if err != nil {
+		return errors.Wrap(err, "adding the dependencies of the preview")
+	}
+
 	configFileName := filepath.Join(dir, opts.ExtraValuesFile)
 	log.Logger().Infof("%s", config)
 	err = ioutil.WriteFile(configFileName, []byte(config), 0644)
@@ -524,12 +613,31 @@ func (o *PreviewOptions) Run() error {
 		helmOptions.ValueFiles = append(helmOptions.ValueFiles, defaultValuesFileName)
 	}
 
-	err = o.InstallChartWithOptions(helmOptions)
+	err = o.InstallChartOnCluster(helmOptions, previewCluster.helmer, previewKubeClient, opts.DefaultInstallTimeout)
 	if err != nil {
 		return err
 	}
 
-	url, appNames, err := o.findPreviewURL(kubeClient, kserveClient)
+	if scaleToZero == config.ScaleToZeroProviderKEDA {
+		dynamicClient := previewCluster.dynamicClient
+		if dynamicClient == nil {
+			dynamicClient, _, err = o.GetFactory().CreateDynamicClient()
+			if err != nil {
+				return errors.Wrap(err, "creating the dynamic client")
+			}
+		}
+		err = previews.EnableKEDAScaleToZero(previewKubeClient, dynamicClient, o.Namespace, previewsConfig.ScaleToZero)
+		if err != nil {
+			return errors.Wrapf(err, "scaling preview environment %s to zero when idle", o.Name)
+		}
+	}
+
+	access, err := previews.ProtectPreview(previewKubeClient, o.Namespace, previewsConfig.Protection)
+	if err != nil {
+		return errors.Wrapf(err, "protecting preview environment %s", o.Name)
+	}
+
+	url, appNames, err := o.findPreviewURL(previewKubeClient, previewCluster.kserveClient)
 
 	if url == "" {
 		log.Logger().Warnf("Could not find the service URL in namespace %s for names %s: %s", o.Namespace, strings.Join(appNames, ", "), err.Error())
@@ -537,11 +645,6 @@ func (o *PreviewOptions) Run() error {
 		writePreviewURL(o, url)
 	}
 
-	comment := fmt.Sprintf(":star: PR built and available in a preview environment **%s**", o.Name)
-	if url != "" {
-		comment += fmt.Sprintf(" [here](%s) ", url)
-	}
-
 	pipeline := o.GetJenkinsJobName()
 	build := builds.GetBuildNumber()
 
@@ -596,11 +699,7 @@ func (o *PreviewOptions) Run() error {
 			if err != nil {
 				return errors.Errorf("preview application %s not available, error was %v", url, err)
 			}
-			// 200 - 299 : successful for most types of applications
-			// 401 : an application requiring authentication
-			// 404 : return code for an application where the domain resolves but the root path is not found
-			// 403 : forbidden, the client may not use the same credentials later, default return code for sprint-security
-			if resp.StatusCode < 200 || (resp.StatusCode >= 300 && resp.StatusCode != 401 && resp.StatusCode != 403 && resp.StatusCode != 404) {
+			if !isAvailableStatus(resp.StatusCode) {
 				return errors.Errorf("preview application %s not available, error was %d %s", url, resp.StatusCode, resp.Status)
 			}
 			return nil
@@ -633,27 +732,18 @@ func (o *PreviewOptions) Run() error {
 		log.Logger().Infof("Preview application is now available at: %s\n", util.ColorInfo(url))
 	}
 
-	stepPRCommentOptions := pr.StepPRCommentOptions{
-		Flags: pr.StepPRCommentFlags{
-			Owner:      o.GitInfo.Organisation,
-			Repository: o.GitInfo.Name,
-			Comment:    comment,
-			PR:         o.PullRequestName,
-		},
-		StepPROptions: pr.StepPROptions{
-			StepOptions: step.StepOptions{
-				CommonOptions: o.CommonOptions,
-			},
-		},
-	}
-	stepPRCommentOptions.BatchMode = true
 	if !o.NoComment {
-		err = stepPRCommentOptions.Run()
-	}
-	if err != nil {
-		log.Logger().Warnf("Failed to comment on the Pull Request with owner %s repo %s: %s", o.GitInfo.Organisation, o.GitInfo.Name, err)
+		endpoints, err := FindPreviewEndpoints(previewKubeClient, o.Namespace)
+		if err != nil {
+			log.Logger().Warnf("Failed to find the endpoints of the preview environment %s: %s", o.Name, err)
+		}
+		comment := PreviewComment(o.Name, url, endpoints, access, dependencies, build)
+		err = o.commentOnPullRequest(comment)
+		if err != nil {
+			log.Logger().Warnf("Failed to comment on the Pull Request with owner %s repo %s: %s", o.GitInfo.Organisation, o.GitInfo.Name, err)
+		}
 	}
-	return o.RunPostPreviewSteps(kubeClient, o.Namespace, url, pipeline, build, o.Application)
+	return o.RunPostPreviewSteps(previewKubeClient, o.Namespace, url, pipeline, build, o.Application)
 }
 
 // findPreviewURL finds the preview URL
@@ -0,0 +1,64 @@
+package preview
+
+import (
+	"github.com/jenkins-x/jx/v2/pkg/client/clientset/versioned"
+	"github.com/jenkins-x/jx/v2/pkg/config"
+	"github.com/jenkins-x/jx/v2/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/previews"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// EnforcePreviewBudget makes room for a new preview environment of the repository in the budget of the team and the
+// repository by deleting the least recently updated preview environments, failing if the budget does not allow
+// eviction
+func (o *PreviewOptions) EnforcePreviewBudget(kubeClient kubernetes.Interface, jxClient versioned.Interface, devNs string, budget *config.PreviewBudgetConfig, previewConfig *config.PreviewEnvironmentConfig) error {
+	maximumInstances := 0
+	if previewConfig != nil {
+		maximumInstances = previewConfig.MaximumInstances
+	}
+	if budget == nil && maximumInstances <= 0 {
+		return nil
+	}
+	envs, err := jxClient.JenkinsV1().Environments(devNs).List(metav1.ListOptions{})
+	if err != nil {
+		return errors.Wrapf(err, "listing the environments in namespace %s", devNs)
+	}
+	evict, err := previews.PreviewsToEvict(envs.Items, budget, o.previewRepository(), maximumInstances)
+	if err != nil {
+		return errors.Wrapf(err, "creating preview environment %s", o.Name)
+	}
+	for _, env := range evict {
+		log.Logger().Infof("Evicting preview environment %s to stay within the previews budget", util.ColorInfo(env.Name))
+		previewKubeClient, helmer, cleanup, err := o.ClientsForEnvironment(env)
+		if err != nil {
+			return err
+		}
+		err = previews.DeletePreviewEnvironment(kubeClient, previewKubeClient, jxClient, helmer, devNs, env)
+		cleanup()
+		if err != nil {
+			return errors.Wrapf(err, "evicting preview environment %s", env.Name)
+		}
+	}
+	return nil
+}
+
+// ApplyPreviewBudget applies the quota and default requests of the budget of the repository to the preview namespace
+func (o *PreviewOptions) ApplyPreviewBudget(kubeClient kubernetes.Interface, budget *config.PreviewBudgetConfig) error {
+	_, quota := budget.RepositoryLimits(o.previewRepository(), 0)
+	var defaultRequests map[string]string
+	if budget != nil {
+		defaultRequests = budget.DefaultRequests
+	}
+	return previews.ApplyBudget(kubeClient, o.Namespace, quota, defaultRequests)
+}
+
+// previewRepository returns the owner/repository of the preview environment
+func (o *PreviewOptions) previewRepository() string {
+	if o.GitInfo == nil {
+		return ""
+	}
+	return o.GitInfo.Organisation + "/" + o.GitInfo.Name
+}
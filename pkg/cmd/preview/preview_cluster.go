@@ -0,0 +1,99 @@
+package preview
+
+import (
+	"fmt"
+
+	v1 "github.com/jenkins-x/jx/v2/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/v2/pkg/helm"
+	"github.com/jenkins-x/jx/v2/pkg/kube"
+	kserve "github.com/knative/serving/pkg/client/clientset/versioned"
+	"github.com/pkg/errors"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+)
+
+// previewClusterClients the clients of the cluster which runs a preview environment
+type previewClusterClients struct {
+	kubeClient   kubernetes.Interface
+	kserveClient kserve.Interface
+	helmer       helm.Helmer
+	// dynamicClient the dynamic client of a remote cluster, which is nil for the development cluster
+	dynamicClient dynamic.Interface
+	// cleanup removes the temporary files of the clients once they are no longer used
+	cleanup func()
+}
+
+// previewClusterName returns the name of the remote cluster new preview environments are created in or an empty
+// string if they are created in the development cluster
+func (o *PreviewOptions) previewClusterName() (string, error) {
+	cluster, err := o.PreviewCluster()
+	if err != nil {
+		return "", errors.Wrap(err, "finding the cluster of the preview environments")
+	}
+	if cluster == nil {
+		return "", nil
+	}
+	return cluster.Name, nil
+}
+
+// clientsForPreviewCluster returns the clients of the cluster which runs the preview environment, creating the
+// namespace of the preview environment if it runs in a remote cluster
+func (o *PreviewOptions) clientsForPreviewCluster(env *v1.Environment, devNs string) (*previewClusterClients, error) {
+	if env.Spec.Cluster == "" {
+		kubeClient, err := o.KubeClient()
+		if err != nil {
+			return nil, err
+		}
+		kserveClient, _, err := o.KnativeServeClient()
+		if err != nil {
+			return nil, err
+		}
+		return &previewClusterClients{
+			kubeClient:   kubeClient,
+			kserveClient: kserveClient,
+			helmer:       o.Helm(),
+			cleanup:      func() {},
+		}, nil
+	}
+
+	cluster, err := o.RemoteClusterForEnvironment(env)
+	if err != nil {
+		return nil, err
+	}
+	if cluster == nil || cluster.Context == "" {
+		return nil, fmt.Errorf("no kube context found for the cluster %s of preview environment %s", env.Spec.Cluster, env.Name)
+	}
+	config, err := kube.KubeConfigForContext(cluster.Context)
+	if err != nil {
+		return nil, err
+	}
+	answer := &previewClusterClients{}
+	answer.kubeClient, err = kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, errors.Wrapf(err, "creating the kube client of cluster %s", cluster.Name)
+	}
+	answer.kserveClient, err = kserve.NewForConfig(config)
+	if err != nil {
+		return nil, errors.Wrapf(err, "creating the knative client of cluster %s", cluster.Name)
+	}
+	answer.dynamicClient, err = dynamic.NewForConfig(config)
+	if err != nil {
+		return nil, errors.Wrapf(err, "creating the dynamic client of cluster %s", cluster.Name)
+	}
+	answer.helmer, answer.cleanup, err = o.HelmForCluster(cluster, answer.kubeClient, env.Spec.Namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	// the namespace of an environment in a remote cluster is not created with the environment
+	labels := map[string]string{
+		kube.LabelTeam:        devNs,
+		kube.LabelEnvironment: env.Name,
+	}
+	err = kube.EnsureNamespaceCreated(answer.kubeClient, env.Spec.Namespace, labels, nil)
+	if err != nil {
+		answer.cleanup()
+		return nil, errors.Wrapf(err, "creating the namespace %s in cluster %s", env.Spec.Namespace, cluster.Name)
+	}
+	return answer, nil
+}
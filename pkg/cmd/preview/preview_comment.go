@@ -0,0 +1,157 @@
+package preview
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jenkins-x/jx/v2/pkg/config"
+	"github.com/jenkins-x/jx/v2/pkg/gits"
+	"github.com/jenkins-x/jx/v2/pkg/kube/services"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// PreviewCommentMarker marks the comment on the Pull Request which is updated on each build of the preview
+	PreviewCommentMarker = "<!-- jx-preview -->"
+
+	endpointHealthTimeout = 10 * time.Second
+)
+
+// PreviewEndpoint an exposed service or ingress of a preview environment
+type PreviewEndpoint struct {
+	Name string
+	URL  string
+	// Status the HTTP status of the endpoint or the error requesting it
+	Status  string
+	Healthy bool
+}
+
+// PreviewComment returns the comment on the Pull Request for a build of its preview environment, including the
+// instructions on how to access it if it is protected
+func PreviewComment(name string, url string, endpoints []*PreviewEndpoint, access string, dependencies []config.PreviewDependency, build string) string {
+	comment := fmt.Sprintf("%s\n:star: PR built and available in a preview environment **%s**", PreviewCommentMarker, name)
+	if url != "" {
+		comment += fmt.Sprintf(" [here](%s) ", url)
+	}
+	if len(endpoints) > 0 {
+		comment += "\n\n| Endpoint | URL | Status |\n| --- | --- | --- |"
+		for _, e := range endpoints {
+			icon := ":white_check_mark:"
+			if !e.Healthy {
+				icon = ":x:"
+			}
+			comment += fmt.Sprintf("\n| %s | %s | %s %s |", e.Name, e.URL, icon, e.Status)
+		}
+	}
+	if access != "" {
+		comment += "\n\n:lock: " + access
+	}
+	if len(dependencies) > 0 {
+		comment += "\n\nwith the dependencies:\n"
+		for i := range dependencies {
+			comment += fmt.Sprintf("\n* %s", dependencies[i].String())
+		}
+	}
+	if build != "" {
+		comment += fmt.Sprintf("\n\nUpdated by build %s at %s", build, time.Now().UTC().Format(time.RFC3339))
+	}
+	return comment
+}
+
+// FindPreviewEndpoints returns the exposed services and ingresses of the preview namespace with their health
+func FindPreviewEndpoints(kubeClient kubernetes.Interface, ns string) ([]*PreviewEndpoint, error) {
+	urls := map[string]string{}
+	serviceURLs, err := services.FindServiceURLs(kubeClient, ns)
+	if err != nil {
+		return nil, errors.Wrapf(err, "finding the service URLs in namespace %s", ns)
+	}
+	for _, s := range serviceURLs {
+		urls[s.URL] = s.Name
+	}
+	ingresses, err := kubeClient.ExtensionsV1beta1().Ingresses(ns).List(metav1.ListOptions{})
+	if err != nil {
+		return nil, errors.Wrapf(err, "listing the ingresses in namespace %s", ns)
+	}
+	for i := range ingresses.Items {
+		url := services.IngressURL(&ingresses.Items[i])
+		if url != "" && urls[url] == "" {
+			urls[url] = ingresses.Items[i].Name
+		}
+	}
+
+	answer := []*PreviewEndpoint{}
+	for url, name := range urls {
+		healthy, status := checkEndpointHealth(url)
+		answer = append(answer, &PreviewEndpoint{
+			Name:    name,
+			URL:     url,
+			Status:  status,
+			Healthy: healthy,
+		})
+	}
+	sort.Slice(answer, func(i, j int) bool {
+		return answer[i].Name < answer[j].Name
+	})
+	return answer, nil
+}
+
+// checkEndpointHealth requests the URL once, treating the same status codes as available as the availability check
+// of the preview
+func checkEndpointHealth(url string) (bool, string) {
+	client := http.Client{Timeout: endpointHealthTimeout}
+	resp, err := client.Get(url) // #nosec
+	if err != nil {
+		return false, "unreachable"
+	}
+	defer resp.Body.Close()
+	return isAvailableStatus(resp.StatusCode), strconv.Itoa(resp.StatusCode)
+}
+
+// isAvailableStatus returns true if the status code shows the application is available
+//
+// 200 - 299 : successful for most types of applications
+// 401 : an application requiring authentication
+// 404 : return code for an application where the domain resolves but the root path is not found
+// 403 : forbidden, the client may not use the same credentials later, default return code for sprint-security
+func isAvailableStatus(code int) bool {
+	return (code >= 200 && code < 300) || code == 401 || code == 403 || code == 404
+}
+
+// commentOnPullRequest adds the comment to the Pull Request or updates the comment of a previous build of the preview
+func (o *PreviewOptions) commentOnPullRequest(comment string) error {
+	if o.PullRequestName == "" {
+		return fmt.Errorf("no Pull Request number provided")
+	}
+	prNumber, err := strconv.Atoi(o.PullRequestName)
+	if err != nil {
+		return errors.Wrapf(err, "parsing Pull Request number %s", o.PullRequestName)
+	}
+	authConfigSvc, err := o.GitAuthConfigService()
+	if err != nil {
+		return err
+	}
+	gitKind, err := o.GitServerKind(o.GitInfo)
+	if err != nil {
+		return err
+	}
+	ghOwner, err := o.GetGitHubAppOwner(o.GitInfo)
+	if err != nil {
+		return err
+	}
+	provider, err := o.GitInfo.CreateProvider(o.InCluster(), authConfigSvc, gitKind, ghOwner, o.Git(), true, o.GetIOFileHandles())
+	if err != nil {
+		return err
+	}
+	pr := &gits.GitPullRequest{
+		Owner:  o.GitInfo.Organisation,
+		Repo:   o.GitInfo.Name,
+		Number: &prNumber,
+	}
+	return gits.UpsertPullRequestComment(provider, pr, PreviewCommentMarker, strings.TrimSpace(comment))
+}
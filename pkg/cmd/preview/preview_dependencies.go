@@ -0,0 +1,115 @@
+package preview
+
+import (
+	"fmt"
+	"path/filepath"
+
+	v1 "github.com/jenkins-x/jx/v2/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/v2/pkg/client/clientset/versioned"
+	"github.com/jenkins-x/jx/v2/pkg/config"
+	"github.com/jenkins-x/jx/v2/pkg/helm"
+	"github.com/jenkins-x/jx/v2/pkg/kube"
+	"github.com/jenkins-x/jx/v2/pkg/kube/naming"
+	"github.com/jenkins-x/jx/v2/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// PreviewDependencies returns the validated dependencies of the preview environment
+func PreviewDependencies(projectConfig *config.ProjectConfig) ([]config.PreviewDependency, error) {
+	if projectConfig == nil || projectConfig.PreviewEnvironments == nil {
+		return nil, nil
+	}
+	dependencies := projectConfig.PreviewEnvironments.Dependencies
+	for i := range dependencies {
+		err := dependencies[i].Validate()
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid previewEnvironments in %s", config.ProjectConfigFileName)
+		}
+	}
+	return dependencies, nil
+}
+
+// AddPreviewDependencies adds the dependencies deployed at a version to the requirements of the preview chart in the
+// given directory and builds the dependencies of the chart
+func (o *PreviewOptions) AddPreviewDependencies(dir string, dependencies []config.PreviewDependency) error {
+	fileName := filepath.Join(dir, helm.RequirementsFileName)
+	requirements, err := helm.LoadRequirementsFile(fileName)
+	if err != nil {
+		return errors.Wrapf(err, "loading %s", fileName)
+	}
+	modified := false
+	for _, d := range dependencies {
+		if d.Version == "" {
+			continue
+		}
+		repository := d.Repository
+		if repository == "" {
+			repository = o.DefaultChartRepositoryURL()
+		}
+		log.Logger().Infof("Adding dependency %s version %s to the preview", util.ColorInfo(d.Name), util.ColorInfo(d.Version))
+		requirements.SetAppVersion(d.Name, d.Version, repository, "")
+		modified = true
+	}
+	if !modified {
+		return nil
+	}
+	err = helm.SaveFile(fileName, requirements)
+	if err != nil {
+		return errors.Wrapf(err, "saving %s", fileName)
+	}
+	_, err = o.HelmInitDependencyBuild(dir, o.DefaultReleaseCharts(), nil)
+	return err
+}
+
+// ConnectPreviewDependencies connects the preview environment to the services of the preview environments of the
+// Pull Requests it depends on by creating services in the preview namespace which resolve to them
+func (o *PreviewOptions) ConnectPreviewDependencies(kubeClient kubernetes.Interface, jxClient versioned.Interface, devNs string, dependencies []config.PreviewDependency) error {
+	for _, d := range dependencies {
+		if d.PullRequest == "" {
+			continue
+		}
+		owner := ""
+		if o.GitInfo != nil {
+			owner = o.GitInfo.Organisation
+		}
+		owner, repository, number, err := d.PullRequestRef(owner)
+		if err != nil {
+			return err
+		}
+		envName := naming.ToValidName(fmt.Sprintf("%s-%s-pr-%d", owner, repository, number))
+		env, err := kube.GetEnvironment(jxClient, devNs, envName)
+		if err != nil || env == nil || env.Spec.Kind != v1.EnvironmentKindTypePreview {
+			return fmt.Errorf("no preview environment %s found for dependency %s. Has a preview of %s been built?", envName, d.Name, d.PullRequest)
+		}
+
+		services := kubeClient.CoreV1().Services(o.Namespace)
+		service, err := services.Get(d.Name, metav1.GetOptions{})
+		create := err != nil
+		if create {
+			service = &corev1.Service{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      d.Name,
+					Namespace: o.Namespace,
+				},
+			}
+		}
+		service.Spec = corev1.ServiceSpec{
+			Type:         corev1.ServiceTypeExternalName,
+			ExternalName: fmt.Sprintf("%s.%s.svc.cluster.local", d.Name, env.Spec.Namespace),
+		}
+		if create {
+			_, err = services.Create(service)
+		} else {
+			_, err = services.Update(service)
+		}
+		if err != nil {
+			return errors.Wrapf(err, "connecting dependency %s to preview environment %s", d.Name, envName)
+		}
+		log.Logger().Infof("Connected dependency %s to the preview environment %s", util.ColorInfo(d.Name), util.ColorInfo(envName))
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+package preview
+
+import (
+	v1 "github.com/jenkins-x/jx/v2/pkg/apis/jenkins.io/v1"
+	jenkinsv1client "github.com/jenkins-x/jx/v2/pkg/client/clientset/versioned/typed/jenkins.io/v1"
+	"github.com/jenkins-x/jx/v2/pkg/config"
+	"github.com/jenkins-x/jx/v2/pkg/previews"
+	"github.com/pkg/errors"
+	"k8s.io/client-go/kubernetes"
+)
+
+// ProvisionPreviewFixtures records the fixtures of the preview environment on it, so that they are destroyed with it
+// even if provisioning them fails part way, then provisions the fixtures which are not provisioned yet. The preview
+// kube client is the client of the cluster which runs the preview environment
+func (o *PreviewOptions) ProvisionPreviewFixtures(kubeClient kubernetes.Interface, previewKubeClient kubernetes.Interface, environments jenkinsv1client.EnvironmentInterface, env *v1.Environment, devNs string, previewConfig *config.PreviewEnvironmentConfig) error {
+	if previewConfig == nil || len(previewConfig.Fixtures) == 0 {
+		return nil
+	}
+	changed, err := previews.AddFixtures(env, previewConfig.Fixtures)
+	if err != nil {
+		return err
+	}
+	if changed {
+		_, err = environments.PatchUpdate(env)
+		if err != nil {
+			return errors.Wrapf(err, "recording the fixtures of preview environment %s", env.Name)
+		}
+	}
+	ctx := &previews.FixtureContext{
+		KubeClient:        kubeClient,
+		DevNamespace:      devNs,
+		PreviewName:       env.Name,
+		PreviewNamespace:  o.Namespace,
+		PreviewKubeClient: previewKubeClient,
+		Dir:               o.Dir,
+	}
+	return previews.ProvisionFixtures(ctx, previewConfig.Fixtures)
+}
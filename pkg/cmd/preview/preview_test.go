@@ -4,6 +4,7 @@ package preview_test
 
 import (
 	"os"
+	"strings"
 	"testing"
 
 	"github.com/jenkins-x/jx/v2/pkg/cmd/preview"
@@ -13,6 +14,7 @@ import (
 	"github.com/jenkins-x/jx/v2/pkg/config"
 	gits_test "github.com/jenkins-x/jx/v2/pkg/gits/mocks"
 	helm_test "github.com/jenkins-x/jx/v2/pkg/helm/mocks"
+	"github.com/stretchr/testify/assert"
 )
 
 func TestGetPreviewValuesConfig(t *testing.T) {
@@ -99,3 +101,21 @@ preview:
 		}
 	}
 }
+
+func TestPreviewComment(t *testing.T) {
+	t.Parallel()
+
+	endpoints := []*preview.PreviewEndpoint{
+		{Name: "myapp", URL: "https://myapp.jx-pr-1.example.com", Status: "200", Healthy: true},
+		{Name: "admin", URL: "https://admin.jx-pr-1.example.com", Status: "unreachable"},
+	}
+	dependencies := []config.PreviewDependency{{Name: "orders", Version: "1.2.3"}}
+	comment := preview.PreviewComment("myorg-myapp-pr-1", endpoints[0].URL, endpoints, "sign in via https://oauth2.example.com", dependencies, "3")
+
+	assert.True(t, strings.HasPrefix(comment, preview.PreviewCommentMarker), "the comment is marked so that it is updated by later builds")
+	assert.Contains(t, comment, "| myapp | https://myapp.jx-pr-1.example.com | :white_check_mark: 200 |")
+	assert.Contains(t, comment, "| admin | https://admin.jx-pr-1.example.com | :x: unreachable |")
+	assert.Contains(t, comment, ":lock: sign in via https://oauth2.example.com")
+	assert.Contains(t, comment, "* orders 1.2.3")
+	assert.Contains(t, comment, "Updated by build 3")
+}
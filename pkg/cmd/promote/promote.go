@@ -18,6 +18,7 @@ import (
 
 	v1 "github.com/jenkins-x/jx/v2/pkg/apis/jenkins.io/v1"
 	"github.com/jenkins-x/jx/v2/pkg/environments"
+	"github.com/jenkins-x/jx/v2/pkg/flagger"
 
 	"k8s.io/helm/pkg/proto/hapi/chart"
 
@@ -70,15 +71,27 @@ type PromoteOptions struct {
 	PullRequestPollTime     string
 	Filter                  string
 	Alias                   string
+	Draft                   bool
+	AutoMerge               bool
+	MergeQueue              bool
+	VerifyImages            []string
+	HealthCheckWindow       string
+	SmokeTestURL            string
+	NoRollback              bool
+	Through                 string
+	OverrideFreeze          bool
+	ReleaseManifest         string
 
 	// calculated fields
-	TimeoutDuration         *time.Duration
-	PullRequestPollDuration *time.Duration
-	Activities              typev1.PipelineActivityInterface
-	GitInfo                 *gits.GitRepository
-	releaseResource         *v1.Release
-	ReleaseInfo             *ReleaseInfo
-	prow                    bool
+	TimeoutDuration           *time.Duration
+	PullRequestPollDuration   *time.Duration
+	HealthCheckWindowDuration *time.Duration
+	Activities                typev1.PipelineActivityInterface
+	GitInfo                   *gits.GitRepository
+	releaseResource           *v1.Release
+	ReleaseInfo               *ReleaseInfo
+	prow                      bool
+	manifest                  *ReleaseManifest
 
 	// Used for testing
 	CloneDir string
@@ -88,7 +101,11 @@ type ReleaseInfo struct {
 	ReleaseName     string
 	FullAppName     string
 	Version         string
+	PreviousVersion string
 	PullRequestInfo *gits.PullRequestInfo
+
+	// PreviousVersions the versions the applications of a release manifest had in the environment before the promotion
+	PreviousVersions map[string]string
 }
 
 var (
@@ -107,6 +124,15 @@ var (
 		# Promote a version of the myapp application to production
 		jx promote --app myapp --version 1.2.3 --env production
 
+		# Promote a version of the myapp application to staging and then to production once it is healthy in staging
+		jx promote --app myapp --version 1.2.3 --through staging,production
+
+		# Promote a fix to production in an emergency even though production is frozen
+		jx promote --app myapp --version 1.2.4 --env production --override-freeze
+
+		# Promote the applications of a release manifest together to production in a single Pull Request
+		jx promote --release-manifest release.yaml --env production
+
 		# To search for all the available charts for a given name use -f.
 		# e.g. to find a redis chart to install
 		jx promote -f redis
@@ -140,6 +166,7 @@ func NewCmdPromote(commonOpts *opts.CommonOptions) *cobra.Command {
 	cmd.Flags().StringVarP(&options.Namespace, "namespace", "n", "", "The Namespace to promote to")
 	cmd.Flags().StringVarP(&options.Environment, opts.OptionEnvironment, "e", "", "The Environment to promote to")
 	cmd.Flags().BoolVarP(&options.AllAutomatic, "all-auto", "", false, "Promote to all automatic environments in order")
+	cmd.Flags().StringVarP(&options.Through, optionThrough, "", "", "The comma separated environments to promote through in order, only promoting to each environment once the application is healthy in the previous one")
 
 	options.AddPromoteOptions(cmd)
 	return cmd
@@ -163,6 +190,15 @@ func (o *PromoteOptions) AddPromoteOptions(cmd *cobra.Command) {
 	cmd.Flags().BoolVarP(&o.NoPoll, "no-poll", "", false, "Disables polling for Pull Request or Pipeline status")
 	cmd.Flags().BoolVarP(&o.NoWaitAfterMerge, "no-wait", "", false, "Disables waiting for completing promotion after the Pull request is merged")
 	cmd.Flags().BoolVarP(&o.IgnoreLocalFiles, "ignore-local-file", "", false, "Ignores the local file system when deducing the Git repository")
+	cmd.Flags().BoolVarP(&o.Draft, "draft", "", false, "Creates the promote Pull Request as a draft if the git provider supports it")
+	cmd.Flags().BoolVarP(&o.AutoMerge, "auto-merge", "", false, "Makes the git provider merge the promote Pull Request once its checks pass")
+	cmd.Flags().BoolVarP(&o.MergeQueue, "merge-queue", "", false, "Adds the promote Pull Request to the merge queue of the git provider, falling back to --auto-merge if it has none")
+	cmd.Flags().StringArrayVarP(&o.VerifyImages, "verify-image", "", nil, "The images whose cosign signatures are verified before promoting. Defaults to the image of the application version if verifyOnPromote is enabled in the supply chain requirements")
+	cmd.Flags().StringVarP(&o.HealthCheckWindow, optionHealthCheckWindow, "", "", "The window to check the health of the application for once the environment pipeline has applied it. A Pull Request reverting the promotion is raised if the health checks fail. Health checks are disabled if not specified")
+	cmd.Flags().StringVarP(&o.SmokeTestURL, "smoke-test-url", "", "", "The URL which must return a successful status for the application to be healthy")
+	cmd.Flags().BoolVarP(&o.NoRollback, "no-rollback", "", false, "Fails the promotion without raising a Pull Request reverting it if the health checks fail")
+	cmd.Flags().StringVarP(&o.ReleaseManifest, optionReleaseManifest, "", "", "The YAML file of a release manifest listing the name and version of each application to promote together in a single Pull Request")
+	cmd.Flags().BoolVarP(&o.OverrideFreeze, optionOverrideFreeze, "", false, "Promotes in an emergency even if the environment is frozen. Otherwise promote Pull Requests are queued until the freeze ends")
 }
 
 func (o *PromoteOptions) hasApplicationFlag() bool {
@@ -250,6 +286,12 @@ func (o *PromoteOptions) EnsureApplicationNameIsDefined(sf searchForChartFn, df
 
 // Run implements this command
 func (o *PromoteOptions) Run() error {
+	if o.ReleaseManifest != "" {
+		err := o.loadReleaseManifest()
+		if err != nil {
+			return err
+		}
+	}
 	err := o.EnsureApplicationNameIsDefined(o.SearchForChart, o.DiscoverAppName)
 	if err != nil {
 		return err
@@ -263,6 +305,12 @@ func (o *PromoteOptions) Run() error {
 		o.Namespace = ns
 	}
 
+	err = o.ConfigureCommitSigningFromTeamSettings()
+	if err != nil {
+		return errors.Wrap(err, "configuring commit signing")
+	}
+	o.ConfigureGitMirrorsFromTeamSettings()
+
 	prow, err := o.IsProw()
 	if err != nil {
 		return err
@@ -277,7 +325,10 @@ func (o *PromoteOptions) Run() error {
 	if o.HelmRepositoryURL == "" {
 		o.HelmRepositoryURL = o.DefaultChartRepositoryURL()
 	}
-	if o.Environment == "" && !o.BatchMode {
+	if o.Through != "" && (o.Environment != "" || o.AllAutomatic) {
+		return fmt.Errorf("--%s cannot be combined with --%s or --all-auto", optionThrough, opts.OptionEnvironment)
+	}
+	if o.Environment == "" && o.Through == "" && !o.BatchMode {
 		names := []string{}
 		m, allEnvNames, err := kube.GetOrderedEnvironments(jxClient, ns)
 		if err != nil {
@@ -309,6 +360,26 @@ func (o *PromoteOptions) Run() error {
 		}
 		o.TimeoutDuration = &duration
 	}
+	if o.HealthCheckWindow != "" {
+		duration, err := time.ParseDuration(o.HealthCheckWindow)
+		if err != nil {
+			return fmt.Errorf("Invalid duration format %s for option --%s: %s", o.HealthCheckWindow, optionHealthCheckWindow, err)
+		}
+		o.HealthCheckWindowDuration = &duration
+	}
+
+	if o.Through != "" {
+		envs, err := TrainEnvironments(jxClient, ns, strings.Split(o.Through, ","))
+		if err != nil {
+			return err
+		}
+		o.Activities = jxClient.JenkinsV1().PipelineActivities(ns)
+		err = o.verifyImageSignatures()
+		if err != nil {
+			return err
+		}
+		return o.PromoteThrough(envs)
+	}
 
 	targetNS, env, err := o.GetTargetNamespace(o.Namespace, o.Environment)
 	if err != nil {
@@ -323,6 +394,10 @@ func (o *PromoteOptions) Run() error {
 		o.ReleaseName = releaseName
 	}
 
+	err = o.verifyImageSignatures()
+	if err != nil {
+		return err
+	}
 	if o.AllAutomatic {
 		return o.PromoteAllAutomatic()
 	}
@@ -353,6 +428,34 @@ func (o *PromoteOptions) Run() error {
 	return err
 }
 
+// verifyImageSignatures verifies the signatures of the images to promote so that only images signed by the release
+// pipelines are promoted
+func (o *PromoteOptions) verifyImageSignatures() error {
+	images := o.VerifyImages
+	if len(images) == 0 {
+		if !o.SupplyChainConfigFromTeamSettings().VerifyOnPromote {
+			return nil
+		}
+		if o.Version == "" {
+			return fmt.Errorf("the version to promote must be specified to verify the signature of its image")
+		}
+		for _, app := range o.promotedApps() {
+			images = append(images, fmt.Sprintf("%s/%s/%s:%s", o.GetDockerRegistry(nil), o.GetDockerRegistryOrg(nil, o.GitInfo), app.Name, app.Version))
+		}
+	}
+	key, err := o.CosignKey("")
+	if err != nil {
+		return err
+	}
+	for _, image := range images {
+		err = o.VerifyImageSignature(image, key)
+		if err != nil {
+			return errors.Wrapf(err, "not promoting %s", o.Application)
+		}
+	}
+	return nil
+}
+
 func (o *PromoteOptions) PromoteAllAutomatic() error {
 	kubeClient, currentNs, err := o.KubeClientAndNamespace()
 	if err != nil {
@@ -461,6 +564,7 @@ func (o *PromoteOptions) Promote(targetNS string, env *v1.Environment, warnIfAut
 		if source.URL != "" && env.Spec.Kind.IsPermanent() {
 			err := o.PromoteViaPullRequest(env, releaseInfo)
 			if err == nil {
+				approvalGate := o.newPromotionApprovalGate(env)
 				startPromotePR := func(a *v1.PipelineActivity, s *v1.PipelineActivityStep, ps *v1.PromoteActivityStep, p *v1.PromotePullRequestStep) error {
 					err = kube.StartPromotionPullRequest(a, s, ps, p)
 					if err != nil {
@@ -470,6 +574,10 @@ func (o *PromoteOptions) Promote(targetNS string, env *v1.Environment, warnIfAut
 					if pr != nil && pr.PullRequest != nil && p.PullRequestURL == "" {
 						p.PullRequestURL = pr.PullRequest.URL
 					}
+					if approvalGate != nil && p.Approval == nil {
+						p.Approval = approvalGate.activityApproval()
+						p.Status = v1.ActivityStatusTypeWaitingForApproval
+					}
 					if version != "" && a.Spec.Version == "" {
 						a.Spec.Version = version
 					}
@@ -486,6 +594,15 @@ func (o *PromoteOptions) Promote(targetNS string, env *v1.Environment, warnIfAut
 		}
 	}
 
+	if o.manifest != nil {
+		return releaseInfo, fmt.Errorf("the release manifest %s can only be promoted to permanent environments with a git repository", o.ReleaseManifest)
+	}
+
+	err = o.checkFreezeBeforeInstall(env)
+	if err != nil {
+		return releaseInfo, err
+	}
+
 	err = o.verifyHelmConfigured()
 	if err != nil {
 		return releaseInfo, err
@@ -553,10 +670,34 @@ func (o *PromoteOptions) PromoteViaPullRequest(env *v1.Environment, releaseInfo
 		BranchName: "promote-" + app + "-" + versionName,
 		Title:      "chore: " + app + " to " + versionName,
 		Message:    fmt.Sprintf("chore: Promote %s to version %s", app, versionName),
+		Draft:      o.Draft,
+		AutoMerge:  o.AutoMerge,
+		MergeQueue: o.MergeQueue,
+	}
+	if o.manifest != nil {
+		details.Message += fmt.Sprintf("\n\nPromotes the apps of release %s together: %s", app, o.manifest.Describe())
+	}
+	defaults := o.PullRequestsConfigFromTeamSettings()
+	details.AddDefaultReviewers(defaults)
+	err := details.ApplyNamingConventions(defaults, gits.PullRequestNamingValues{
+		Kind:        "promote",
+		Version:     versionName,
+		Component:   app,
+		Environment: env.Name,
+	}, o.Git())
+	if err != nil {
+		return errors.Wrap(err, "applying the pull request naming conventions")
 	}
 
+	progressiveDelivery := o.ProgressiveDeliveryConfigFromTeamSettings()
 	modifyChartFn := func(requirements *helm.Requirements, metadata *chart.Metadata, values map[string]interface{},
 		templates map[string]string, dir string, details *gits.PullRequestDetails) error {
+		if o.manifest != nil {
+			if !progressiveDelivery.IsEnabledForEnvironment(env.Name) {
+				return o.setReleaseManifestVersions(requirements, values, dir, releaseInfo, nil)
+			}
+			return o.setReleaseManifestVersions(requirements, values, dir, releaseInfo, progressiveDelivery)
+		}
 		var err error
 		if version == "" {
 			version, err = o.findLatestVersion(app)
@@ -564,7 +705,23 @@ func (o *PromoteOptions) PromoteViaPullRequest(env *v1.Environment, releaseInfo
 				return err
 			}
 		}
+		for _, dep := range requirements.Dependencies {
+			if dep != nil && dep.Name == app && dep.Version != version {
+				releaseInfo.PreviousVersion = dep.Version
+			}
+		}
 		requirements.SetAppVersion(app, version, o.HelmRepositoryURL, o.Alias)
+		if progressiveDelivery.IsEnabledForEnvironment(env.Name) {
+			valuesKey := app
+			if o.Alias != "" {
+				valuesKey = o.Alias
+			}
+			flagger.SetCanaryValues(values, valuesKey, progressiveDelivery)
+			err = helm.SaveFile(filepath.Join(dir, helm.ValuesFileName), values)
+			if err != nil {
+				return errors.Wrapf(err, "saving the canary values of %s", valuesKey)
+			}
+		}
 		return nil
 	}
 	gitProvider, _, err := o.CreateGitProviderForURLWithoutKind(env.Spec.Source.URL)
@@ -581,6 +738,8 @@ func (o *PromoteOptions) PromoteViaPullRequest(env *v1.Environment, releaseInfo
 		Gitter:        o.Git(),
 		ModifyChartFn: modifyChartFn,
 		GitProvider:   gitProvider,
+		Provenance:    o.PullRequestProvenance("promote"),
+		Helmer:        o.Helm(),
 	}
 	filter := &gits.PullRequestFilter{}
 	if releaseInfo.PullRequestInfo != nil && releaseInfo.PullRequestInfo.PullRequest != nil {
@@ -588,7 +747,35 @@ func (o *PromoteOptions) PromoteViaPullRequest(env *v1.Environment, releaseInfo
 	}
 	info, err := options.Create(env, envDir, &details, filter, "", true)
 	releaseInfo.PullRequestInfo = info
-	return err
+	if err != nil {
+		return err
+	}
+	approvalGate := o.newPromotionApprovalGate(env)
+	if approvalGate != nil && filter.Number == nil && info != nil && info.PullRequest != nil {
+		pr := info.PullRequest
+		err = approvalGate.requestApproval(gitProvider, pr)
+		if err != nil {
+			log.Logger().Warnf("Failed to comment on Pull Request %s how to approve it: %s", pr.URL, err)
+		}
+		err = gitProvider.UpdatePullRequestStatus(pr)
+		if err == nil {
+			err = approvalGate.updateStatus(gitProvider, pr)
+		}
+		if err != nil {
+			log.Logger().Warnf("Failed to mark Pull Request %s as waiting for approval: %s", pr.URL, err)
+		}
+	}
+	freezeGate := o.newPromotionFreezeGate(env)
+	if freezeGate != nil && filter.Number == nil && info != nil && info.PullRequest != nil {
+		pr := info.PullRequest
+		if o.checkPromotionFreeze(freezeGate, gitProvider, pr) {
+			err = freezeGate.queue(gitProvider, pr)
+			if err != nil {
+				log.Logger().Warnf("Failed to comment on Pull Request %s that it is queued until the freeze ends: %s", pr.URL, err)
+			}
+		}
+	}
+	return nil
 }
 
 func (o *PromoteOptions) GetTargetNamespace(ns string, env string) (string, *v1.Environment, error) {
@@ -671,6 +858,9 @@ func (o *PromoteOptions) WaitForPromotion(ns string, env *v1.Environment, releas
 			}
 			return err
 		}
+		if o.HealthCheckWindowDuration != nil && !o.NoWaitAfterMerge {
+			return o.checkHealthAfterPromotion(env, releaseInfo, promoteKey)
+		}
 	}
 	return nil
 }
@@ -696,6 +886,8 @@ func (o *PromoteOptions) waitForGitOpsPullRequest(ns string, env *v1.Environment
 	}
 
 	if pullRequestInfo != nil {
+		approvalGate := o.newPromotionApprovalGate(env)
+		freezeGate := o.newPromotionFreezeGate(env)
 		for {
 			pr := pullRequestInfo.PullRequest
 			gitProvider, _, err := o.CreateGitProviderForURLWithoutKind(env.Spec.Source.URL)
@@ -825,6 +1017,16 @@ func (o *PromoteOptions) waitForGitOpsPullRequest(ns string, env *v1.Environment
 						return fmt.Errorf("Promotion failed as Pull Request %s is closed without merging", pr.URL)
 					}
 
+					// lets not merge until the promotion is approved if the environment requires approvals
+					approved := o.checkPromotionApproval(approvalGate, gitProvider, pr, promoteKey)
+
+					// lets queue the merge until the freeze ends if the environment is frozen
+					frozen := o.checkPromotionFreeze(freezeGate, gitProvider, pr)
+					if frozen && freezeGate.end.Add(duration).After(end) {
+						end = freezeGate.end.Add(duration)
+						log.Logger().Infof("Waiting for the freeze of environment %s to end at %s before merging Pull Request %s", util.ColorInfo(env.Name), util.ColorInfo(freezeGate.end.Format(time.RFC3339)), util.ColorInfo(pr.URL))
+					}
+
 					// lets try merge if the status is good
 					status, err := gitProvider.PullRequestLastCommitStatus(pr)
 					if err != nil {
@@ -834,7 +1036,7 @@ func (o *PromoteOptions) waitForGitOpsPullRequest(ns string, env *v1.Environment
 						log.Logger().Info("The build for the Pull Request last commit is currently in progress.")
 					} else {
 						if status == "success" {
-							if !(o.NoMergePullRequest) {
+							if !(o.NoMergePullRequest) && approved && !frozen {
 								tideMerge := false
 								// Now check if tide is running or not
 								commitStatues, err := gitProvider.ListCommitStatus(pr.Owner, pr.Repo, pr.LastCommitSha)
@@ -1047,20 +1249,21 @@ func (o *PromoteOptions) CommentOnIssues(targetNS string, environment *v1.Enviro
 	if err != nil {
 		return err
 	}
-	kubeClient, err := o.KubeClient()
+	// the environment may run in a remote cluster
+	kubeClient, serviceNS, err := o.KubeClientForEnvironment(environment)
 	if err != nil {
 		return err
 	}
 	appNames := []string{app, o.ReleaseName, ens + "-" + app}
 	url := ""
 	for _, n := range appNames {
-		url, err = services.FindServiceURL(kubeClient, ens, naming.ToValidName(n))
+		url, err = services.FindServiceURL(kubeClient, serviceNS, naming.ToValidName(n))
 		if url != "" {
 			break
 		}
 	}
 	if url == "" {
-		log.Logger().Warnf("Could not find the service URL in namespace %s for names %s", ens, strings.Join(appNames, ", "))
+		log.Logger().Warnf("Could not find the service URL in namespace %s for names %s", serviceNS, strings.Join(appNames, ", "))
 	}
 	available := ""
 	if url != "" {
@@ -1068,9 +1271,9 @@ func (o *PromoteOptions) CommentOnIssues(targetNS string, environment *v1.Enviro
 	}
 
 	if available == "" {
-		ing, err := kubeClient.ExtensionsV1beta1().Ingresses(ens).Get(app, metav1.GetOptions{})
+		ing, err := kubeClient.ExtensionsV1beta1().Ingresses(serviceNS).Get(app, metav1.GetOptions{})
 		if err != nil || ing == nil && o.ReleaseName != "" && o.ReleaseName != app {
-			ing, err = kubeClient.ExtensionsV1beta1().Ingresses(ens).Get(o.ReleaseName, metav1.GetOptions{})
+			ing, err = kubeClient.ExtensionsV1beta1().Ingresses(serviceNS).Get(o.ReleaseName, metav1.GetOptions{})
 		}
 		if ing != nil {
 			if len(ing.Spec.Rules) > 0 {
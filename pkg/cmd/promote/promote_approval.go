@@ -0,0 +1,205 @@
+package promote
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	v1 "github.com/jenkins-x/jx/v2/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/v2/pkg/config"
+	"github.com/jenkins-x/jx/v2/pkg/gits"
+	"github.com/jenkins-x/jx/v2/pkg/kube"
+	"github.com/jenkins-x/jx/v2/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// ApproveCommand the comment an approver adds to a promotion Pull Request to approve it
+	ApproveCommand = "/approve promote"
+	// ApproveCancelCommand the comment an approver adds to a promotion Pull Request to withdraw their approval
+	ApproveCancelCommand = "/approve promote cancel"
+	// ApprovalStatusContext the context of the commit status of promotion Pull Requests which is pending until they
+	// are approved, so that branch protection can require it
+	ApprovalStatusContext = "promotion/approval"
+)
+
+// PromotionApprovers returns the approvers who approved a promotion in the comments on its Pull Request, which are in
+// the order they were created so that later comments withdrawing an approval win
+func PromotionApprovers(policy *config.PromotionApprovalConfig, comments []*gits.GitPullRequestComment) []string {
+	answer := []string{}
+	if !policy.IsRequired() {
+		return answer
+	}
+	approvers := map[string]string{}
+	for _, approver := range policy.Approvers {
+		approvers[strings.ToLower(approver)] = approver
+	}
+	approved := map[string]bool{}
+	for _, comment := range comments {
+		if comment == nil || comment.Author == nil {
+			continue
+		}
+		approver, ok := approvers[strings.ToLower(comment.Author.Login)]
+		if !ok {
+			continue
+		}
+		for _, line := range strings.Split(comment.Body, "\n") {
+			switch strings.ToLower(strings.Join(strings.Fields(line), " ")) {
+			case ApproveCommand:
+				approved[approver] = true
+			case ApproveCancelCommand:
+				delete(approved, approver)
+			}
+		}
+	}
+	for _, approver := range policy.Approvers {
+		if approved[approver] && util.StringArrayIndex(answer, approver) < 0 {
+			answer = append(answer, approver)
+		}
+	}
+	return answer
+}
+
+// promotionApprovalGate holds back the merge of a promotion Pull Request until enough approvers approve it
+type promotionApprovalGate struct {
+	env            string
+	policy         *config.PromotionApprovalConfig
+	approvedBy     []string
+	approved       bool
+	statusSha      string
+	logUnsupported bool
+}
+
+// newPromotionApprovalGate returns the gate of the promotion Pull Requests of the environment, or nil if the
+// environment does not require promotions to be approved
+func (o *PromoteOptions) newPromotionApprovalGate(env *v1.Environment) *promotionApprovalGate {
+	policy := o.PromotionApprovalFromTeamSettings(env.Name)
+	if policy == nil {
+		return nil
+	}
+	return &promotionApprovalGate{env: env.Name, policy: policy}
+}
+
+// activityApproval returns the approval recorded on the PipelineActivity of a promotion to the environment
+func (g *promotionApprovalGate) activityApproval() *v1.PromotionApproval {
+	return &v1.PromotionApproval{
+		Approvers:         g.policy.Approvers,
+		RequiredApprovals: g.policy.RequiredCount(),
+	}
+}
+
+// statusDescription describes the approval of the Pull Request in its commit status
+func (g *promotionApprovalGate) statusDescription() string {
+	if g.approved {
+		return fmt.Sprintf("Approved by %s", strings.Join(g.approvedBy, ", "))
+	}
+	return fmt.Sprintf("%d of %d approvals, approvers comment %s", len(g.approvedBy), g.policy.RequiredCount(), ApproveCommand)
+}
+
+// requestApproval comments on a new promotion Pull Request how to approve it
+func (g *promotionApprovalGate) requestApproval(gitProvider gits.GitProvider, pr *gits.GitPullRequest) error {
+	logins := []string{}
+	for _, approver := range g.policy.Approvers {
+		logins = append(logins, "@"+approver)
+	}
+	comment := fmt.Sprintf("Promotion to the **%s** environment requires approval from %d of: %s\n\nApprovers can approve it by commenting `%s` or withdraw their approval with `%s`.",
+		g.env, g.policy.RequiredCount(), strings.Join(logins, ", "), ApproveCommand, ApproveCancelCommand)
+	return gitProvider.AddPRComment(pr, comment)
+}
+
+// updateStatus sets the approval commit status of the last commit of the Pull Request
+func (g *promotionApprovalGate) updateStatus(gitProvider gits.GitProvider, pr *gits.GitPullRequest) error {
+	if pr.LastCommitSha == "" {
+		return nil
+	}
+	state := "pending"
+	if g.approved {
+		state = "success"
+	}
+	_, err := gitProvider.UpdateCommitStatus(pr.Owner, pr.Repo, pr.LastCommitSha, &gits.GitRepoStatus{
+		Context:     ApprovalStatusContext,
+		State:       state,
+		Description: g.statusDescription(),
+		TargetURL:   pr.URL,
+	})
+	if err != nil {
+		return errors.Wrapf(err, "setting the %s status of Pull Request %s", ApprovalStatusContext, pr.URL)
+	}
+	g.statusSha = pr.LastCommitSha
+	return nil
+}
+
+// checkPromotionApproval returns true if the promotion Pull Request may merge, updating its approval commit status
+// and the approval on the PipelineActivity whenever the approvals change
+func (o *PromoteOptions) checkPromotionApproval(gate *promotionApprovalGate, gitProvider gits.GitProvider, pr *gits.GitPullRequest, promoteKey *kube.PromoteStepActivityKey) bool {
+	if gate == nil {
+		return true
+	}
+	lister, ok := gitProvider.(gits.PullRequestCommentLister)
+	if !ok || pr.Number == nil {
+		if !gate.logUnsupported {
+			gate.logUnsupported = true
+			log.Logger().Warnf("%s cannot list the comments of Pull Request %s so it has to be merged manually once approved", gitProvider.Kind(), pr.URL)
+		}
+		return false
+	}
+	comments, err := lister.ListPullRequestComments(pr.Owner, pr.Repo, *pr.Number)
+	if err != nil {
+		log.Logger().Warnf("Failed to list the comments of Pull Request %s: %s", pr.URL, err)
+		return false
+	}
+	approvedBy := PromotionApprovers(gate.policy, comments)
+	approved := len(approvedBy) >= gate.policy.RequiredCount()
+	changed := approved != gate.approved || strings.Join(approvedBy, ",") != strings.Join(gate.approvedBy, ",")
+	gate.approvedBy = approvedBy
+	gate.approved = approved
+	if changed {
+		if approved {
+			log.Logger().Infof("Pull Request %s was approved by %s", util.ColorInfo(pr.URL), util.ColorInfo(strings.Join(approvedBy, ", ")))
+		} else {
+			log.Logger().Infof("Pull Request %s is waiting for approval: %s", util.ColorInfo(pr.URL), gate.statusDescription())
+		}
+		err = o.updateActivityApproval(gate, promoteKey)
+		if err != nil {
+			log.Logger().Warnf("Failed to update the approval of the PipelineActivity: %s", err)
+		}
+	}
+	if changed || gate.statusSha != pr.LastCommitSha {
+		err = gate.updateStatus(gitProvider, pr)
+		if err != nil {
+			log.Logger().Warnf("%s", err)
+		}
+	}
+	return approved
+}
+
+// updateActivityApproval records the approvals of the promotion on its PipelineActivity
+func (o *PromoteOptions) updateActivityApproval(gate *promotionApprovalGate, promoteKey *kube.PromoteStepActivityKey) error {
+	jxClient, _, err := o.JXClient()
+	if err != nil {
+		return errors.Wrap(err, "getting jx client")
+	}
+	kubeClient, err := o.KubeClient()
+	if err != nil {
+		return errors.Wrap(err, "getting kube client")
+	}
+	updateApproval := func(a *v1.PipelineActivity, s *v1.PipelineActivityStep, ps *v1.PromoteActivityStep, p *v1.PromotePullRequestStep) error {
+		if p.Approval == nil {
+			p.Approval = gate.activityApproval()
+		}
+		p.Approval.ApprovedBy = gate.approvedBy
+		p.Approval.ApprovedTimestamp = nil
+		if gate.approved {
+			p.Approval.ApprovedTimestamp = &metav1.Time{Time: time.Now()}
+			if p.Status == v1.ActivityStatusTypeWaitingForApproval {
+				p.Status = v1.ActivityStatusTypeRunning
+			}
+		} else if !p.Status.IsTerminated() {
+			p.Status = v1.ActivityStatusTypeWaitingForApproval
+		}
+		return nil
+	}
+	return promoteKey.OnPromotePullRequest(kubeClient, jxClient, o.Namespace, updateApproval)
+}
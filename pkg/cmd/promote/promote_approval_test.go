@@ -0,0 +1,85 @@
+// +build unit
+
+package promote_test
+
+import (
+	"testing"
+
+	"github.com/jenkins-x/jx/v2/pkg/cmd/promote"
+	"github.com/jenkins-x/jx/v2/pkg/config"
+	"github.com/jenkins-x/jx/v2/pkg/gits"
+	"github.com/stretchr/testify/assert"
+)
+
+func approvalComment(login string, body string) *gits.GitPullRequestComment {
+	return &gits.GitPullRequestComment{Author: &gits.GitUser{Login: login}, Body: body}
+}
+
+func TestPromotionApprovers(t *testing.T) {
+	t.Parallel()
+
+	policy := &config.PromotionApprovalConfig{Approvers: []string{"alice", "Bob", "carol"}, RequiredApprovals: 2}
+
+	tests := []struct {
+		name     string
+		comments []*gits.GitPullRequestComment
+		expected []string
+	}{
+		{
+			name:     "no comments",
+			expected: []string{},
+		},
+		{
+			name: "approvals in approver order",
+			comments: []*gits.GitPullRequestComment{
+				approvalComment("carol", "/approve promote"),
+				approvalComment("alice", "looks good\n  /APPROVE   promote  "),
+			},
+			expected: []string{"alice", "carol"},
+		},
+		{
+			name: "logins are case insensitive and approvals counted once",
+			comments: []*gits.GitPullRequestComment{
+				approvalComment("bob", "/approve promote"),
+				approvalComment("BOB", "/approve promote"),
+			},
+			expected: []string{"Bob"},
+		},
+		{
+			name: "other people and commands are ignored",
+			comments: []*gits.GitPullRequestComment{
+				approvalComment("mallory", "/approve promote"),
+				approvalComment("alice", "/approve"),
+				approvalComment("carol", "I will /approve promote later"),
+				{Body: "/approve promote"},
+			},
+			expected: []string{},
+		},
+		{
+			name: "cancelled approvals",
+			comments: []*gits.GitPullRequestComment{
+				approvalComment("alice", "/approve promote"),
+				approvalComment("carol", "/approve promote"),
+				approvalComment("alice", "/approve promote cancel"),
+			},
+			expected: []string{"carol"},
+		},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, promote.PromotionApprovers(policy, tt.comments), tt.name)
+	}
+
+	assert.Empty(t, promote.PromotionApprovers(nil, []*gits.GitPullRequestComment{approvalComment("alice", "/approve promote")}))
+}
+
+func TestPromotionApprovalRequiredCount(t *testing.T) {
+	t.Parallel()
+
+	var none *config.PromotionApprovalConfig
+	assert.False(t, none.IsRequired())
+	assert.Equal(t, 0, none.RequiredCount())
+
+	assert.Equal(t, 1, (&config.PromotionApprovalConfig{Approvers: []string{"alice", "bob"}}).RequiredCount())
+	assert.Equal(t, 2, (&config.PromotionApprovalConfig{Approvers: []string{"alice", "bob"}, RequiredApprovals: 2}).RequiredCount())
+	assert.Equal(t, 2, (&config.PromotionApprovalConfig{Approvers: []string{"alice", "bob"}, RequiredApprovals: 5}).RequiredCount())
+}
@@ -0,0 +1,158 @@
+package promote
+
+import (
+	"fmt"
+	"time"
+
+	v1 "github.com/jenkins-x/jx/v2/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/v2/pkg/config"
+	"github.com/jenkins-x/jx/v2/pkg/gits"
+	"github.com/jenkins-x/jx/v2/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+)
+
+const (
+	optionOverrideFreeze = "override-freeze"
+
+	// FreezeStatusContext the context of the commit status of promotion Pull Requests which is pending while their
+	// environment is frozen, so that branch protection can require it
+	FreezeStatusContext = "promotion/freeze"
+)
+
+// promotionFreezeGate holds back the merge of a promotion Pull Request while its environment is frozen
+type promotionFreezeGate struct {
+	env        string
+	config     *config.EnvironmentConfig
+	freeze     *config.FreezeWindowConfig
+	end        time.Time
+	overridden bool
+	checked    bool
+	statusSha  string
+}
+
+// newPromotionFreezeGate returns the gate of the promotions to the environment, or nil if the environment has no
+// freeze windows
+func (o *PromoteOptions) newPromotionFreezeGate(env *v1.Environment) *promotionFreezeGate {
+	envConfig := o.EnvironmentFreezesFromTeamSettings(env.Name)
+	if envConfig == nil {
+		return nil
+	}
+	return &promotionFreezeGate{env: env.Name, config: envConfig, overridden: o.OverrideFreeze}
+}
+
+// frozen returns true if the environment is frozen
+func (g *promotionFreezeGate) frozen() bool {
+	return g != nil && g.freeze != nil
+}
+
+// held returns true if the promotion is held back by a freeze which is not overridden
+func (g *promotionFreezeGate) held() bool {
+	return g.frozen() && !g.overridden
+}
+
+// check updates the freeze of the environment at the given time, returning true if it changed since the last check
+func (g *promotionFreezeGate) check(now time.Time) (bool, error) {
+	freeze, end, err := g.config.FreezeAt(now)
+	if err != nil {
+		return false, err
+	}
+	changed := !g.checked || freeze != g.freeze || !end.Equal(g.end)
+	g.checked = true
+	g.freeze = freeze
+	g.end = end
+	return changed, nil
+}
+
+// describe describes the freeze of the environment
+func (g *promotionFreezeGate) describe() string {
+	if !g.frozen() {
+		return fmt.Sprintf("Environment %s is not frozen", g.env)
+	}
+	description := fmt.Sprintf("Environment %s is frozen by %s until %s", g.env, g.freeze.Label(), g.end.Format(time.RFC3339))
+	if g.overridden {
+		description += fmt.Sprintf(" but the freeze was overridden with --%s", optionOverrideFreeze)
+	}
+	return description
+}
+
+// queue comments on a new promotion Pull Request that it is queued until the freeze ends
+func (g *promotionFreezeGate) queue(gitProvider gits.GitProvider, pr *gits.GitPullRequest) error {
+	comment := fmt.Sprintf("Promotion to the **%s** environment is queued as it is frozen by **%s** until %s. It proceeds automatically once the freeze ends.",
+		g.env, g.freeze.Label(), g.end.Format(time.RFC3339))
+	return gitProvider.AddPRComment(pr, comment)
+}
+
+// updateStatus sets the freeze commit status of the last commit of the Pull Request
+func (g *promotionFreezeGate) updateStatus(gitProvider gits.GitProvider, pr *gits.GitPullRequest) error {
+	if pr.LastCommitSha == "" {
+		return nil
+	}
+	state := "success"
+	if g.held() {
+		state = "pending"
+	}
+	_, err := gitProvider.UpdateCommitStatus(pr.Owner, pr.Repo, pr.LastCommitSha, &gits.GitRepoStatus{
+		Context:     FreezeStatusContext,
+		State:       state,
+		Description: g.describe(),
+		TargetURL:   pr.URL,
+	})
+	if err != nil {
+		return errors.Wrapf(err, "setting the %s status of Pull Request %s", FreezeStatusContext, pr.URL)
+	}
+	g.statusSha = pr.LastCommitSha
+	return nil
+}
+
+// checkPromotionFreeze returns true if the promotion Pull Request has to wait for the freeze of its environment to end,
+// updating its freeze commit status whenever the freeze changes once the Pull Request has been held back
+func (o *PromoteOptions) checkPromotionFreeze(gate *promotionFreezeGate, gitProvider gits.GitProvider, pr *gits.GitPullRequest) bool {
+	if gate == nil {
+		return false
+	}
+	wasHeld := gate.held()
+	changed, err := gate.check(time.Now())
+	if err != nil {
+		log.Logger().Warnf("Failed to check the freeze windows of environment %s: %s", gate.env, err)
+		return false
+	}
+	if changed {
+		if gate.held() {
+			log.Logger().Infof("Pull Request %s is queued: %s", util.ColorInfo(pr.URL), gate.describe())
+		} else if gate.frozen() {
+			log.Logger().Warnf("%s", gate.describe())
+		} else if wasHeld {
+			log.Logger().Infof("The freeze of environment %s ended so Pull Request %s can proceed", util.ColorInfo(gate.env), util.ColorInfo(pr.URL))
+		}
+	}
+	if (changed && (gate.frozen() || gate.statusSha != "")) || (gate.statusSha != "" && gate.statusSha != pr.LastCommitSha) {
+		err = gate.updateStatus(gitProvider, pr)
+		if err != nil {
+			log.Logger().Warnf("%s", err)
+		}
+	}
+	return gate.held()
+}
+
+// checkFreezeBeforeInstall returns an error if the environment is frozen and cannot be promoted to directly
+func (o *PromoteOptions) checkFreezeBeforeInstall(env *v1.Environment) error {
+	if env == nil {
+		return nil
+	}
+	gate := o.newPromotionFreezeGate(env)
+	if gate == nil {
+		return nil
+	}
+	_, err := gate.check(time.Now())
+	if err != nil {
+		return errors.Wrapf(err, "checking the freeze windows of environment %s", env.Name)
+	}
+	if gate.held() {
+		return fmt.Errorf("%s, use --%s to promote anyway", gate.describe(), optionOverrideFreeze)
+	}
+	if gate.frozen() {
+		log.Logger().Warnf("%s", gate.describe())
+	}
+	return nil
+}
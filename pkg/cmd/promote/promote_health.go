@@ -0,0 +1,269 @@
+package promote
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	v1 "github.com/jenkins-x/jx/v2/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/v2/pkg/environments"
+	"github.com/jenkins-x/jx/v2/pkg/gits"
+	"github.com/jenkins-x/jx/v2/pkg/helm"
+	"github.com/jenkins-x/jx/v2/pkg/kube"
+	"github.com/jenkins-x/jx/v2/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/helm/pkg/proto/hapi/chart"
+)
+
+const (
+	optionHealthCheckWindow = "health-check-window"
+
+	// reasonProgressDeadlineExceeded the reason of the progressing condition of a deployment whose rollout is stuck
+	reasonProgressDeadlineExceeded = "ProgressDeadlineExceeded"
+)
+
+var (
+	healthCheckPollInterval = time.Second * 10
+
+	// failedContainerReasons the reasons a container is waiting for which mean it will not become ready on its own
+	failedContainerReasons = []string{"CrashLoopBackOff", "ImagePullBackOff", "ErrImagePull", "CreateContainerConfigError", "InvalidImageName"}
+
+	smokeTestClient = &http.Client{Timeout: time.Second * 10}
+)
+
+// CheckReleaseHealth checks the rollout of the deployments of the app in the namespace, the readiness of their pods and
+// the optional smoke test URL. It returns why the release is not healthy yet, which is empty once it is healthy, or an
+// error if it has failed in a way it will not recover from
+func CheckReleaseHealth(kubeClient kubernetes.Interface, ns string, app string, smokeTestURL string) (string, error) {
+	list, err := kubeClient.AppsV1().Deployments(ns).List(metav1.ListOptions{})
+	if err != nil {
+		return "", errors.Wrapf(err, "listing deployments in namespace %s", ns)
+	}
+	found := false
+	for i := range list.Items {
+		d := &list.Items[i]
+		if kube.GetName(&d.ObjectMeta) != app {
+			continue
+		}
+		found = true
+		reason, err := deploymentHealth(kubeClient, d)
+		if err != nil || reason != "" {
+			return reason, err
+		}
+	}
+	if !found {
+		return fmt.Sprintf("no deployments of %s found in namespace %s", app, ns), nil
+	}
+	if smokeTestURL != "" {
+		resp, err := smokeTestClient.Get(smokeTestURL)
+		if err != nil {
+			return fmt.Sprintf("smoke test %s failed: %s", smokeTestURL, err), nil
+		}
+		resp.Body.Close()
+		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+			return fmt.Sprintf("smoke test %s returned status %d", smokeTestURL, resp.StatusCode), nil
+		}
+	}
+	return "", nil
+}
+
+// deploymentHealth returns why the rollout of the deployment is not complete yet or an error if it has failed
+func deploymentHealth(kubeClient kubernetes.Interface, d *appsv1.Deployment) (string, error) {
+	for _, c := range d.Status.Conditions {
+		if c.Type == appsv1.DeploymentProgressing && c.Reason == reasonProgressDeadlineExceeded {
+			return "", fmt.Errorf("deployment %s exceeded its progress deadline: %s", d.Name, c.Message)
+		}
+	}
+	selector, err := metav1.LabelSelectorAsSelector(d.Spec.Selector)
+	if err != nil {
+		return "", errors.Wrapf(err, "parsing the selector of deployment %s", d.Name)
+	}
+	pods, err := kubeClient.CoreV1().Pods(d.Namespace).List(metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return "", errors.Wrapf(err, "listing the pods of deployment %s", d.Name)
+	}
+	for _, pod := range pods.Items {
+		for _, s := range pod.Status.ContainerStatuses {
+			if s.State.Waiting != nil && util.StringArrayIndex(failedContainerReasons, s.State.Waiting.Reason) >= 0 {
+				return "", fmt.Errorf("container %s of pod %s is in %s: %s", s.Name, pod.Name, s.State.Waiting.Reason, s.State.Waiting.Message)
+			}
+		}
+	}
+
+	replicas := int32(1)
+	if d.Spec.Replicas != nil {
+		replicas = *d.Spec.Replicas
+	}
+	if d.Generation > d.Status.ObservedGeneration {
+		return fmt.Sprintf("waiting for deployment %s to be observed", d.Name), nil
+	}
+	if d.Status.UpdatedReplicas < replicas {
+		return fmt.Sprintf("%d of %d replicas of deployment %s are updated", d.Status.UpdatedReplicas, replicas, d.Name), nil
+	}
+	if d.Status.Replicas > d.Status.UpdatedReplicas {
+		return fmt.Sprintf("%d old replicas of deployment %s are pending termination", d.Status.Replicas-d.Status.UpdatedReplicas, d.Name), nil
+	}
+	if d.Status.AvailableReplicas < replicas {
+		return fmt.Sprintf("%d of %d replicas of deployment %s are available", d.Status.AvailableReplicas, replicas, d.Name), nil
+	}
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if pod.Status.Phase != corev1.PodSucceeded && !kube.IsPodReady(pod) {
+			return fmt.Sprintf("pod %s of deployment %s is not ready", pod.Name, d.Name), nil
+		}
+	}
+	return "", nil
+}
+
+// waitForHealthyRelease watches the health of the promoted release in the cluster of the environment for the health
+// check window. The release must not fail during the window and must be healthy at its end
+func (o *PromoteOptions) waitForHealthyRelease(env *v1.Environment) error {
+	kubeClient, ns, err := o.KubeClientForEnvironment(env)
+	if err != nil {
+		return errors.Wrapf(err, "getting kube client for environment %s", env.Name)
+	}
+	window := *o.HealthCheckWindowDuration
+	log.Logger().Infof("checking the health of %s in namespace %s for %s", util.ColorInfo(o.Application), util.ColorInfo(ns), window.String())
+	end := time.Now().Add(window)
+	lastReason := ""
+	for {
+		reason, err := o.checkPromotedAppsHealth(kubeClient, ns)
+		if err != nil {
+			return err
+		}
+		if reason != lastReason {
+			lastReason = reason
+			if reason == "" {
+				log.Logger().Infof("%s is healthy", util.ColorInfo(o.Application))
+			} else {
+				log.Logger().Infof("%s is not healthy yet: %s", util.ColorInfo(o.Application), reason)
+			}
+		}
+		if time.Now().After(end) {
+			if reason != "" {
+				return fmt.Errorf("%s did not become healthy within %s: %s", o.Application, window.String(), reason)
+			}
+			return nil
+		}
+		time.Sleep(healthCheckPollInterval)
+	}
+}
+
+// checkPromotedAppsHealth returns the reasons the promoted applications are not healthy yet or an empty string if
+// they are all healthy
+func (o *PromoteOptions) checkPromotedAppsHealth(kubeClient kubernetes.Interface, ns string) (string, error) {
+	if o.manifest == nil {
+		return CheckReleaseHealth(kubeClient, ns, o.Application, o.SmokeTestURL)
+	}
+	reasons := []string{}
+	smokeTestURL := o.SmokeTestURL
+	for _, app := range o.manifest.Apps {
+		reason, err := CheckReleaseHealth(kubeClient, ns, app.Name, smokeTestURL)
+		if err != nil {
+			return "", err
+		}
+		// the smoke test checks the release as a whole so lets only run it once
+		smokeTestURL = ""
+		if reason != "" {
+			reasons = append(reasons, app.Name+": "+reason)
+		}
+	}
+	return strings.Join(reasons, "; "), nil
+}
+
+// RollbackViaPullRequest raises a Pull Request on the environment reverting the app to the version it had before the
+// promotion, or removing it if it was not in the environment before. All the apps of a release manifest are reverted
+// together
+func (o *PromoteOptions) RollbackViaPullRequest(env *v1.Environment, releaseInfo *ReleaseInfo, cause error) (*gits.PullRequestInfo, error) {
+	app := o.Application
+	previousVersion := releaseInfo.PreviousVersion
+	title := environments.RollbackTitlePrefix + "remove " + app
+	if previousVersion != "" {
+		title = environments.RollbackTitlePrefix + app + " to " + previousVersion
+	}
+	if o.manifest != nil {
+		title = environments.RollbackTitlePrefix + "release " + app + " " + releaseInfo.Version
+	}
+	details := gits.PullRequestDetails{
+		BranchName: "revert-" + app + "-" + releaseInfo.Version,
+		Title:      title,
+		Message:    fmt.Sprintf("%s\n\nVersion %s of %s failed its health checks: %s", title, releaseInfo.Version, app, cause),
+		AutoMerge:  o.AutoMerge,
+		MergeQueue: o.MergeQueue,
+	}
+	details.AddDefaultReviewers(o.PullRequestsConfigFromTeamSettings())
+
+	modifyChartFn := func(requirements *helm.Requirements, metadata *chart.Metadata, values map[string]interface{},
+		templates map[string]string, dir string, details *gits.PullRequestDetails) error {
+		if o.manifest != nil {
+			for _, a := range o.manifest.Apps {
+				previous, ok := releaseInfo.PreviousVersions[a.Name]
+				if !ok {
+					requirements.RemoveApplication(a.Name)
+					continue
+				}
+				requirements.SetAppVersion(a.Name, previous, "", "")
+			}
+			return nil
+		}
+		if previousVersion == "" {
+			requirements.RemoveApplication(app)
+			return nil
+		}
+		requirements.SetAppVersion(app, previousVersion, "", "")
+		return nil
+	}
+	gitProvider, _, err := o.CreateGitProviderForURLWithoutKind(env.Spec.Source.URL)
+	if err != nil {
+		return nil, errors.Wrapf(err, "creating git provider for %s", env.Spec.Source.URL)
+	}
+	options := environments.EnvironmentPullRequestOptions{
+		Gitter:        o.Git(),
+		ModifyChartFn: modifyChartFn,
+		GitProvider:   gitProvider,
+		Provenance:    o.PullRequestProvenance("promote"),
+		Helmer:        o.Helm(),
+	}
+	return options.Create(env, "", &details, nil, "", true)
+}
+
+// checkHealthAfterPromotion checks the health of the release once the environment pipeline has applied it, raising a
+// Pull Request reverting it if it fails unless rollbacks are disabled
+func (o *PromoteOptions) checkHealthAfterPromotion(env *v1.Environment, releaseInfo *ReleaseInfo, promoteKey *kube.PromoteStepActivityKey) error {
+	healthErr := o.waitForHealthyRelease(env)
+	if healthErr == nil {
+		return nil
+	}
+	log.Logger().Warnf("%s failed its health checks: %s", o.Application, healthErr)
+
+	jxClient, _, err := o.JXClient()
+	if err != nil {
+		return errors.Wrap(err, "getting jx client")
+	}
+	kubeClient, err := o.KubeClient()
+	if err != nil {
+		return errors.Wrap(err, "getting kube client")
+	}
+	err = promoteKey.OnPromoteUpdate(kubeClient, jxClient, o.Namespace, kube.FailedPromotionUpdate)
+	if err != nil {
+		log.Logger().Warnf("Failed to update PipelineActivity: %s", err)
+	}
+
+	if o.NoRollback {
+		return errors.Wrapf(healthErr, "promoting %s", o.Application)
+	}
+	info, err := o.RollbackViaPullRequest(env, releaseInfo, healthErr)
+	if err != nil {
+		return errors.Wrapf(err, "raising the Pull Request rolling back %s after: %s", o.Application, healthErr)
+	}
+	if info != nil && info.PullRequest != nil {
+		log.Logger().Infof("created Pull Request %s rolling back %s", util.ColorInfo(info.PullRequest.URL), util.ColorInfo(o.Application))
+	}
+	return errors.Wrapf(healthErr, "rolled back %s", o.Application)
+}
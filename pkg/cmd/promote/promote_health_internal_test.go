@@ -0,0 +1,137 @@
+// +build unit
+
+package promote
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	v1 "github.com/jenkins-x/jx/v2/pkg/apis/jenkins.io/v1"
+	jxfake "github.com/jenkins-x/jx/v2/pkg/client/clientset/versioned/fake"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/v2/pkg/kube"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+)
+
+const remoteClusterRequirements = `clusters:
+- name: prod-cluster
+  environment: production
+  context: prod-context
+  namespace: jx-production
+`
+
+// remoteKubeConfig is the kube config of the remote cluster whose API server is the given URL
+const remoteKubeConfig = `apiVersion: v1
+kind: Config
+clusters:
+- name: prod-cluster
+  cluster:
+    server: %s
+contexts:
+- name: prod-context
+  context:
+    cluster: prod-cluster
+    user: prod-user
+current-context: prod-context
+users:
+- name: prod-user
+  user:
+    token: test-token
+`
+
+// newRemoteClusterServer returns an API server of a remote cluster running a healthy deployment of jx-myapp in the
+// namespace
+func newRemoteClusterServer(t *testing.T, ns string) *httptest.Server {
+	replicas := int32(1)
+	labels := map[string]string{"app": "jx-myapp"}
+	deployments := &appsv1.DeploymentList{
+		TypeMeta: metav1.TypeMeta{Kind: "DeploymentList", APIVersion: "apps/v1"},
+		Items: []appsv1.Deployment{
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "jx-myapp", Namespace: ns, Labels: labels},
+				Spec: appsv1.DeploymentSpec{
+					Replicas: &replicas,
+					Selector: &metav1.LabelSelector{MatchLabels: labels},
+				},
+				Status: appsv1.DeploymentStatus{Replicas: 1, UpdatedReplicas: 1, AvailableReplicas: 1},
+			},
+		},
+	}
+	pods := &corev1.PodList{
+		TypeMeta: metav1.TypeMeta{Kind: "PodList", APIVersion: "v1"},
+		Items: []corev1.Pod{
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "jx-myapp-abc", Namespace: ns, Labels: labels},
+				Status: corev1.PodStatus{
+					Phase:      corev1.PodRunning,
+					Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}},
+				},
+			},
+		},
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc(fmt.Sprintf("/apis/apps/v1/namespaces/%s/deployments", ns), func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		require.NoError(t, json.NewEncoder(w).Encode(deployments))
+	})
+	mux.HandleFunc(fmt.Sprintf("/api/v1/namespaces/%s/pods", ns), func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		require.NoError(t, json.NewEncoder(w).Encode(pods))
+	})
+	return httptest.NewServer(mux)
+}
+
+func TestWaitForHealthyReleaseInRemoteCluster(t *testing.T) {
+	server := newRemoteClusterServer(t, "jx-production")
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "test-remote-kubeconfig-")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir) //nolint:errcheck
+	kubeConfig := filepath.Join(dir, "config")
+	err = ioutil.WriteFile(kubeConfig, []byte(fmt.Sprintf(remoteKubeConfig, server.URL)), 0600)
+	require.NoError(t, err)
+	originalKubeConfig, hadKubeConfig := os.LookupEnv("KUBECONFIG")
+	err = os.Setenv("KUBECONFIG", kubeConfig)
+	require.NoError(t, err)
+	defer func() {
+		if hadKubeConfig {
+			os.Setenv("KUBECONFIG", originalKubeConfig) //nolint:errcheck
+		} else {
+			os.Unsetenv("KUBECONFIG") //nolint:errcheck
+		}
+	}()
+
+	devEnv := kube.NewPermanentEnvironment(kube.LabelValueDevEnvironment)
+	devEnv.Spec.Kind = v1.EnvironmentKindTypeDevelopment
+	devEnv.Spec.TeamSettings.BootRequirements = remoteClusterRequirements
+	env := kube.NewPermanentEnvironment("production")
+	env.Spec.Namespace = "jx-production"
+
+	commonOpts := opts.NewCommonOptionsWithFactory(nil)
+	commonOpts.SetDevNamespace("jx")
+	commonOpts.SetJxClient(jxfake.NewSimpleClientset(devEnv, env))
+	// the deployment only runs in the remote cluster
+	commonOpts.SetKubeClient(kubefake.NewSimpleClientset())
+
+	window := time.Duration(0)
+	o := &PromoteOptions{
+		CommonOptions:             &commonOpts,
+		Application:               "myapp",
+		HealthCheckWindowDuration: &window,
+	}
+	err = o.waitForHealthyRelease(env)
+	assert.NoError(t, err, "the release should be checked in the remote cluster of the environment")
+}
@@ -0,0 +1,145 @@
+// +build unit
+
+package promote_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jenkins-x/jx/v2/pkg/cmd/promote"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+)
+
+const healthTestNamespace = "jx-staging"
+
+func healthTestDeployment(updated int32, available int32, conditions ...appsv1.DeploymentCondition) *appsv1.Deployment {
+	replicas := int32(2)
+	labels := map[string]string{"app": "jx-myapp"}
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "jx-myapp",
+			Namespace:  healthTestNamespace,
+			Labels:     labels,
+			Generation: 3,
+		},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Selector: &metav1.LabelSelector{MatchLabels: labels},
+		},
+		Status: appsv1.DeploymentStatus{
+			ObservedGeneration: 3,
+			Replicas:           updated,
+			UpdatedReplicas:    updated,
+			AvailableReplicas:  available,
+			Conditions:         conditions,
+		},
+	}
+}
+
+func healthTestPod(name string, ready bool, waitingReason string) *corev1.Pod {
+	readyStatus := corev1.ConditionFalse
+	if ready {
+		readyStatus = corev1.ConditionTrue
+	}
+	containerStatus := corev1.ContainerStatus{Name: "myapp", Ready: ready}
+	if waitingReason != "" {
+		containerStatus.State.Waiting = &corev1.ContainerStateWaiting{Reason: waitingReason}
+	}
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: healthTestNamespace,
+			Labels:    map[string]string{"app": "jx-myapp"},
+		},
+		Status: corev1.PodStatus{
+			Phase:             corev1.PodRunning,
+			Conditions:        []corev1.PodCondition{{Type: corev1.PodReady, Status: readyStatus}},
+			ContainerStatuses: []corev1.ContainerStatus{containerStatus},
+		},
+	}
+}
+
+func TestCheckReleaseHealth(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		objects     []runtime.Object
+		unhealthy   bool
+		expectError bool
+	}{
+		{
+			name:    "healthy",
+			objects: []runtime.Object{healthTestDeployment(2, 2), healthTestPod("a", true, ""), healthTestPod("b", true, "")},
+		},
+		{
+			name:      "no deployments",
+			unhealthy: true,
+		},
+		{
+			name:      "rollout in progress",
+			objects:   []runtime.Object{healthTestDeployment(1, 1), healthTestPod("a", true, "")},
+			unhealthy: true,
+		},
+		{
+			name:      "pod not ready",
+			objects:   []runtime.Object{healthTestDeployment(2, 2), healthTestPod("a", true, ""), healthTestPod("b", false, "")},
+			unhealthy: true,
+		},
+		{
+			name:        "crash looping",
+			objects:     []runtime.Object{healthTestDeployment(2, 1), healthTestPod("a", true, ""), healthTestPod("b", false, "CrashLoopBackOff")},
+			expectError: true,
+		},
+		{
+			name: "progress deadline exceeded",
+			objects: []runtime.Object{healthTestDeployment(1, 1, appsv1.DeploymentCondition{
+				Type:   appsv1.DeploymentProgressing,
+				Status: corev1.ConditionFalse,
+				Reason: "ProgressDeadlineExceeded",
+			})},
+			expectError: true,
+		},
+	}
+	for _, tt := range tests {
+		kubeClient := kubefake.NewSimpleClientset(tt.objects...)
+		reason, err := promote.CheckReleaseHealth(kubeClient, healthTestNamespace, "myapp", "")
+		if tt.expectError {
+			assert.Error(t, err, tt.name)
+			continue
+		}
+		require.NoError(t, err, tt.name)
+		if tt.unhealthy {
+			assert.NotEmpty(t, reason, tt.name)
+		} else {
+			assert.Empty(t, reason, tt.name)
+		}
+	}
+}
+
+func TestCheckReleaseHealthSmokeTest(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/down" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	kubeClient := kubefake.NewSimpleClientset(healthTestDeployment(2, 2), healthTestPod("a", true, ""), healthTestPod("b", true, ""))
+	reason, err := promote.CheckReleaseHealth(kubeClient, healthTestNamespace, "myapp", server.URL)
+	require.NoError(t, err)
+	assert.Empty(t, reason)
+
+	reason, err = promote.CheckReleaseHealth(kubeClient, healthTestNamespace, "myapp", server.URL+"/down")
+	require.NoError(t, err)
+	assert.Contains(t, reason, "503")
+}
@@ -0,0 +1,169 @@
+package promote
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/v2/pkg/config"
+	"github.com/jenkins-x/jx/v2/pkg/flagger"
+	"github.com/jenkins-x/jx/v2/pkg/helm"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	optionReleaseManifest = "release-manifest"
+)
+
+// ReleaseManifest is a set of applications with compatible versions which are promoted together in a single Pull
+// Request so that they move between environments in lockstep
+type ReleaseManifest struct {
+	// Name the name of the release
+	Name string `json:"name"`
+	// Version the version of the release
+	Version string `json:"version"`
+	// Apps the applications of the release
+	Apps []ReleaseManifestApp `json:"apps"`
+}
+
+// ReleaseManifestApp is an application of a release manifest
+type ReleaseManifestApp struct {
+	// Name the name of the chart of the application
+	Name string `json:"name"`
+	// Version the version of the chart
+	Version string `json:"version"`
+	// Alias the optional alias of the chart in the environment
+	Alias string `json:"alias,omitempty"`
+	// Repository the URL of the chart repository. Defaults to the chart repository of the team
+	Repository string `json:"repository,omitempty"`
+}
+
+// LoadReleaseManifest loads and validates the release manifest in the given file
+func LoadReleaseManifest(fileName string) (*ReleaseManifest, error) {
+	exists, err := util.FileExists(fileName)
+	if err != nil {
+		return nil, errors.Wrapf(err, "checking if the release manifest %s exists", fileName)
+	}
+	if !exists {
+		return nil, fmt.Errorf("the release manifest %s does not exist", fileName)
+	}
+	data, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return nil, errors.Wrapf(err, "reading the release manifest %s", fileName)
+	}
+	manifest := &ReleaseManifest{}
+	err = yaml.Unmarshal(data, manifest)
+	if err != nil {
+		return nil, errors.Wrapf(err, "parsing the release manifest %s", fileName)
+	}
+	err = manifest.Validate()
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid release manifest %s", fileName)
+	}
+	return manifest, nil
+}
+
+// Validate returns an error if the release has no name, version or applications or if an application is missing its
+// version or is listed more than once
+func (m *ReleaseManifest) Validate() error {
+	if m.Name == "" {
+		return fmt.Errorf("the release has no name")
+	}
+	if m.Version == "" {
+		return fmt.Errorf("release %s has no version", m.Name)
+	}
+	if len(m.Apps) == 0 {
+		return fmt.Errorf("release %s has no apps", m.Name)
+	}
+	names := map[string]bool{}
+	for _, app := range m.Apps {
+		if app.Name == "" {
+			return fmt.Errorf("an app of release %s has no name", m.Name)
+		}
+		if app.Version == "" {
+			return fmt.Errorf("the app %s of release %s has no version", app.Name, m.Name)
+		}
+		if names[app.Name] {
+			return fmt.Errorf("the app %s is listed more than once in release %s", app.Name, m.Name)
+		}
+		names[app.Name] = true
+	}
+	return nil
+}
+
+// Describe returns the applications and their versions
+func (m *ReleaseManifest) Describe() string {
+	apps := []string{}
+	for _, app := range m.Apps {
+		apps = append(apps, app.Name+" "+app.Version)
+	}
+	return strings.Join(apps, ", ")
+}
+
+// loadReleaseManifest loads the release manifest to promote, which is promoted as an application named after the
+// release with the version of the release
+func (o *PromoteOptions) loadReleaseManifest() error {
+	if o.Application != "" || o.Version != "" || o.Filter != "" || o.Alias != "" || len(o.Args) > 0 {
+		return fmt.Errorf("--%s cannot be combined with an application, --%s, --version, --filter or --alias", optionReleaseManifest, opts.OptionApplication)
+	}
+	manifest, err := LoadReleaseManifest(o.ReleaseManifest)
+	if err != nil {
+		return err
+	}
+	o.manifest = manifest
+	o.Application = manifest.Name
+	o.Version = manifest.Version
+	return nil
+}
+
+// promotedApps returns the applications of the release manifest or the application being promoted
+func (o *PromoteOptions) promotedApps() []ReleaseManifestApp {
+	if o.manifest != nil {
+		return o.manifest.Apps
+	}
+	return []ReleaseManifestApp{
+		{
+			Name:       o.Application,
+			Version:    o.Version,
+			Alias:      o.Alias,
+			Repository: o.HelmRepositoryURL,
+		},
+	}
+}
+
+// setReleaseManifestVersions sets the versions of all the applications of the release manifest in the environment
+// chart, recording the versions they had before so that the release can be rolled back as a whole
+func (o *PromoteOptions) setReleaseManifestVersions(requirements *helm.Requirements, values map[string]interface{}, dir string,
+	releaseInfo *ReleaseInfo, progressiveDelivery *config.ProgressiveDeliveryConfig) error {
+	releaseInfo.PreviousVersions = map[string]string{}
+	for _, app := range o.manifest.Apps {
+		for _, dep := range requirements.Dependencies {
+			if dep != nil && dep.Name == app.Name {
+				releaseInfo.PreviousVersions[app.Name] = dep.Version
+			}
+		}
+		repository := app.Repository
+		if repository == "" {
+			repository = o.HelmRepositoryURL
+		}
+		requirements.SetAppVersion(app.Name, app.Version, repository, app.Alias)
+		if progressiveDelivery != nil {
+			valuesKey := app.Name
+			if app.Alias != "" {
+				valuesKey = app.Alias
+			}
+			flagger.SetCanaryValues(values, valuesKey, progressiveDelivery)
+		}
+	}
+	if progressiveDelivery != nil {
+		err := helm.SaveFile(filepath.Join(dir, helm.ValuesFileName), values)
+		if err != nil {
+			return errors.Wrapf(err, "saving the canary values of release %s", o.manifest.Name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+// +build unit
+
+package promote_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jenkins-x/jx/v2/pkg/cmd/promote"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadReleaseManifest(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "test-release-manifest-")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir) //nolint:errcheck
+
+	fileName := filepath.Join(dir, "release.yaml")
+	err = ioutil.WriteFile(fileName, []byte(`name: checkout
+version: 2.1.0
+apps:
+- name: cart
+  version: 1.4.2
+- name: payments
+  version: 3.0.1
+  alias: pay
+`), 0644)
+	require.NoError(t, err)
+
+	manifest, err := promote.LoadReleaseManifest(fileName)
+	require.NoError(t, err)
+	assert.Equal(t, "checkout", manifest.Name)
+	assert.Equal(t, "2.1.0", manifest.Version)
+	require.Len(t, manifest.Apps, 2)
+	assert.Equal(t, "pay", manifest.Apps[1].Alias)
+	assert.Equal(t, "cart 1.4.2, payments 3.0.1", manifest.Describe())
+
+	_, err = promote.LoadReleaseManifest(filepath.Join(dir, "missing.yaml"))
+	assert.Error(t, err)
+}
+
+func TestValidateReleaseManifest(t *testing.T) {
+	t.Parallel()
+
+	app := promote.ReleaseManifestApp{Name: "cart", Version: "1.4.2"}
+	for name, manifest := range map[string]promote.ReleaseManifest{
+		"no name":    {Version: "1.0.0", Apps: []promote.ReleaseManifestApp{app}},
+		"no version": {Name: "checkout", Apps: []promote.ReleaseManifestApp{app}},
+		"no apps":    {Name: "checkout", Version: "1.0.0"},
+		"app without version": {Name: "checkout", Version: "1.0.0", Apps: []promote.ReleaseManifestApp{
+			{Name: "cart"},
+		}},
+		"duplicate app": {Name: "checkout", Version: "1.0.0", Apps: []promote.ReleaseManifestApp{app, app}},
+	} {
+		assert.Error(t, manifest.Validate(), name)
+	}
+}
@@ -0,0 +1,130 @@
+package promote
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	v1 "github.com/jenkins-x/jx/v2/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/v2/pkg/client/clientset/versioned"
+	"github.com/jenkins-x/jx/v2/pkg/kube"
+	"github.com/jenkins-x/jx/v2/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+)
+
+const (
+	optionThrough = "through"
+
+	// defaultTrainHealthCheckWindow the window the health of the application is checked for in each environment of a
+	// promotion train before promoting to the next one if no --health-check-window is specified
+	defaultTrainHealthCheckWindow = 2 * time.Minute
+)
+
+// TrainStage is the promotion to one of the environments of a promotion train
+type TrainStage struct {
+	Environment    *v1.Environment
+	Status         v1.ActivityStatusType
+	PullRequestURL string
+}
+
+// TrainEnvironments returns the permanent environments of the team with the given names in the order a promotion train
+// promotes through them
+func TrainEnvironments(jxClient versioned.Interface, ns string, names []string) ([]*v1.Environment, error) {
+	m, envNames, err := kube.GetEnvironments(jxClient, ns)
+	if err != nil {
+		return nil, errors.Wrapf(err, "getting the environments in namespace %s", ns)
+	}
+	answer := []*v1.Environment{}
+	seen := map[string]bool{}
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("the environment %s is listed more than once in --%s", name, optionThrough)
+		}
+		seen[name] = true
+		env := m[name]
+		if env == nil {
+			return nil, util.InvalidOption(optionThrough, name, envNames)
+		}
+		if !env.Spec.Kind.IsPermanent() {
+			return nil, fmt.Errorf("cannot promote through environment %s as it is not a permanent environment", name)
+		}
+		if env.Spec.Namespace == "" {
+			return nil, fmt.Errorf("environment %s does not have a namespace associated with it", name)
+		}
+		answer = append(answer, env)
+	}
+	if len(answer) == 0 {
+		return nil, util.MissingOption(optionThrough)
+	}
+	return answer, nil
+}
+
+// PromoteThrough promotes through the environments in order, only promoting to an environment once the promotion to
+// the previous one merged and the application is healthy in it
+func (o *PromoteOptions) PromoteThrough(envs []*v1.Environment) error {
+	if o.NoPoll || o.NoWaitAfterMerge || o.TimeoutDuration == nil || o.PullRequestPollDuration == nil {
+		return fmt.Errorf("a promotion train waits for each promotion to complete so --%s cannot be used with --no-poll, --no-wait or without a timeout", optionThrough)
+	}
+	if o.HealthCheckWindowDuration == nil {
+		window := defaultTrainHealthCheckWindow
+		o.HealthCheckWindowDuration = &window
+	}
+	stages := []*TrainStage{}
+	for _, env := range envs {
+		stages = append(stages, &TrainStage{Environment: env, Status: v1.ActivityStatusTypePending})
+	}
+	releaseName := o.ReleaseName
+	for i, stage := range stages {
+		env := stage.Environment
+		stage.Status = v1.ActivityStatusTypeRunning
+		o.logTrain(stages)
+
+		// lets default the release name for the namespace of each environment unless one was specified
+		o.ReleaseName = releaseName
+		releaseInfo, err := o.Promote(env.Spec.Namespace, env, false)
+		if err == nil && releaseInfo != nil {
+			o.ReleaseInfo = releaseInfo
+			pr := releaseInfo.PullRequestInfo
+			if pr != nil && pr.PullRequest != nil {
+				stage.PullRequestURL = pr.PullRequest.URL
+			}
+			err = o.WaitForPromotion(env.Spec.Namespace, env, releaseInfo)
+		}
+		if err != nil {
+			stage.Status = v1.ActivityStatusTypeFailed
+			for _, remaining := range stages[i+1:] {
+				remaining.Status = v1.ActivityStatusTypeNotExecuted
+			}
+			o.logTrain(stages)
+			return errors.Wrapf(err, "the promotion train stopped at environment %s", env.Name)
+		}
+		stage.Status = v1.ActivityStatusTypeSucceeded
+	}
+	o.logTrain(stages)
+	log.Logger().Infof("Promoted %s version %s through %s", util.ColorInfo(o.Application), util.ColorInfo(o.Version), util.ColorInfo(trainNames(stages)))
+	return nil
+}
+
+// logTrain displays the progress of the promotion train
+func (o *PromoteOptions) logTrain(stages []*TrainStage) {
+	log.Logger().Infof("Promotion train %s:", util.ColorInfo(trainNames(stages)))
+	table := o.CreateTable()
+	table.AddRow("ENVIRONMENT", "STATUS", "PULL REQUEST")
+	for _, stage := range stages {
+		table.AddRow(stage.Environment.Name, stage.Status.String(), stage.PullRequestURL)
+	}
+	table.Render()
+}
+
+func trainNames(stages []*TrainStage) string {
+	names := []string{}
+	for _, stage := range stages {
+		names = append(names, stage.Environment.Name)
+	}
+	return strings.Join(names, " -> ")
+}
@@ -0,0 +1,60 @@
+// +build unit
+
+package promote_test
+
+import (
+	"testing"
+
+	v1 "github.com/jenkins-x/jx/v2/pkg/apis/jenkins.io/v1"
+	jxfake "github.com/jenkins-x/jx/v2/pkg/client/clientset/versioned/fake"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/promote"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func trainTestEnvironment(name string, kind v1.EnvironmentKindType, ns string) *v1.Environment {
+	return &v1.Environment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "jx",
+		},
+		Spec: v1.EnvironmentSpec{
+			Kind:      kind,
+			Namespace: ns,
+		},
+	}
+}
+
+func TestTrainEnvironments(t *testing.T) {
+	t.Parallel()
+
+	jxClient := jxfake.NewSimpleClientset(
+		trainTestEnvironment("dev", v1.EnvironmentKindTypeDevelopment, "jx"),
+		trainTestEnvironment("staging", v1.EnvironmentKindTypePermanent, "jx-staging"),
+		trainTestEnvironment("production", v1.EnvironmentKindTypePermanent, "jx-production"),
+		trainTestEnvironment("broken", v1.EnvironmentKindTypePermanent, ""),
+	)
+
+	envs, err := promote.TrainEnvironments(jxClient, "jx", []string{"staging", " production "})
+	require.NoError(t, err)
+	require.Len(t, envs, 2)
+	assert.Equal(t, "staging", envs[0].Name)
+	assert.Equal(t, "production", envs[1].Name)
+
+	envs, err = promote.TrainEnvironments(jxClient, "jx", []string{"production", "staging"})
+	require.NoError(t, err)
+	assert.Equal(t, "production", envs[0].Name)
+
+	for _, names := range [][]string{
+		{},
+		{""},
+		{"staging", "staging"},
+		{"staging", "qa"},
+		{"dev", "staging"},
+		{"broken"},
+	} {
+		_, err = promote.TrainEnvironments(jxClient, "jx", names)
+		assert.Error(t, err, "%v", names)
+	}
+}
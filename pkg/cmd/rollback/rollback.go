@@ -0,0 +1,352 @@
+package rollback
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	v1 "github.com/jenkins-x/jx/v2/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/promote"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/v2/pkg/environments"
+	"github.com/jenkins-x/jx/v2/pkg/gits"
+	"github.com/jenkins-x/jx/v2/pkg/helm"
+	"github.com/jenkins-x/jx/v2/pkg/kube"
+	"github.com/jenkins-x/jx/v2/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/helm/pkg/proto/hapi/chart"
+)
+
+const (
+	optionPullRequestPollTime = "pull-request-poll-time"
+)
+
+var (
+	healthPollInterval = time.Second * 10
+)
+
+// RollbackOptions the options for the rollback command
+type RollbackOptions struct {
+	*opts.CommonOptions
+
+	Environment         string
+	Application         string
+	Version             string
+	Reason              string
+	Commits             int
+	Wait                bool
+	NoMergePullRequest  bool
+	Timeout             string
+	PullRequestPollTime string
+}
+
+var (
+	rollbackLong = templates.LongDesc(`
+		Rolls back an application in an environment by raising a Pull Request on the environment repository which
+		reverts it to an earlier version.
+
+		If no version is specified the version deployed before the current one is found in the history of the
+		environment repository, skipping the versions which were already rolled back. The rollback restores a version
+		which was already deployed so it is not held back by the freeze windows or approvals of the environment.
+
+		Use 'jx get rollbacks' to audit the rollbacks of the environments.
+`)
+
+	rollbackExample = templates.Examples(`
+		# Roll back myapp in production to the version it had before
+		jx rollback --env production myapp
+
+		# Roll back myapp in production to version 1.2.3
+		jx rollback --env production myapp 1.2.3
+
+		# Roll back myapp in production and wait for the rollback to be deployed
+		jx rollback --env production myapp --wait --reason "orders are failing"
+	`)
+)
+
+// NewCmdRollback creates the command object
+func NewCmdRollback(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &RollbackOptions{
+		CommonOptions: commonOpts,
+	}
+	cmd := &cobra.Command{
+		Use:     "rollback <application> [version]",
+		Short:   "Rolls back an application in an environment to an earlier version",
+		Long:    rollbackLong,
+		Example: rollbackExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+	cmd.Flags().StringVarP(&options.Environment, opts.OptionEnvironment, "e", "", "The environment to roll back the application in")
+	cmd.Flags().StringVarP(&options.Reason, "reason", "", "", "The reason for the rollback which is added to the Pull Request")
+	cmd.Flags().IntVarP(&options.Commits, "commits", "", 100, "The number of recent commits of the environment requirements to search for the previous version, 0 searches the whole history")
+	cmd.Flags().BoolVarP(&options.Wait, "wait", "w", false, "Waits for the Pull Request to merge and the rolled back version to be deployed")
+	cmd.Flags().BoolVarP(&options.NoMergePullRequest, "no-merge", "", false, "Disables the automatic merge of the rollback Pull Request when waiting")
+	cmd.Flags().StringVarP(&options.Timeout, opts.OptionTimeout, "t", "30m", "The timeout to wait for the rollback to be deployed")
+	cmd.Flags().StringVarP(&options.PullRequestPollTime, optionPullRequestPollTime, "", "20s", "Poll time when waiting for the Pull Request to merge")
+	return cmd
+}
+
+// Run implements this command
+func (o *RollbackOptions) Run() error {
+	if len(o.Args) == 0 {
+		return util.MissingArgument("application")
+	}
+	if len(o.Args) > 2 {
+		return fmt.Errorf("unexpected arguments %v, specify the application and an optional version", o.Args[2:])
+	}
+	o.Application = o.Args[0]
+	if len(o.Args) > 1 {
+		o.Version = o.Args[1]
+	}
+	if o.Environment == "" {
+		return util.MissingOption(opts.OptionEnvironment)
+	}
+	timeout, err := time.ParseDuration(o.Timeout)
+	if err != nil {
+		return util.InvalidOption(opts.OptionTimeout, o.Timeout, nil)
+	}
+	pollTime, err := time.ParseDuration(o.PullRequestPollTime)
+	if err != nil {
+		return util.InvalidOption(optionPullRequestPollTime, o.PullRequestPollTime, nil)
+	}
+
+	jxClient, ns, err := o.JXClientAndDevNamespace()
+	if err != nil {
+		return err
+	}
+	envMap, names, err := kube.GetEnvironments(jxClient, ns)
+	if err != nil {
+		return errors.Wrapf(err, "listing the environments in %s", ns)
+	}
+	env := envMap[o.Environment]
+	if env == nil {
+		return util.InvalidOption(opts.OptionEnvironment, o.Environment, names)
+	}
+	if env.Spec.Source.URL == "" {
+		return fmt.Errorf("environment %s has no git repository so it cannot be rolled back", env.Name)
+	}
+
+	err = o.ConfigureCommitSigningFromTeamSettings()
+	if err != nil {
+		return errors.Wrap(err, "configuring commit signing")
+	}
+	o.ConfigureGitMirrorsFromTeamSettings()
+
+	current, target, err := o.findRollbackVersion(env)
+	if err != nil {
+		return err
+	}
+	log.Logger().Infof("Rolling back %s in environment %s from version %s to %s deployed by %s", util.ColorInfo(o.Application),
+		util.ColorInfo(env.Name), util.ColorInfo(current), util.ColorInfo(target.Version), util.ColorInfo(target.Subject))
+
+	pr, err := o.RollbackViaPullRequest(env, current, target.Version)
+	if err != nil {
+		return err
+	}
+	if pr == nil {
+		return nil
+	}
+	log.Logger().Infof("Created Pull Request %s rolling back %s", util.ColorInfo(pr.URL), util.ColorInfo(o.Application))
+	if !o.Wait {
+		return nil
+	}
+	end := time.Now().Add(timeout)
+	err = o.waitForPullRequest(env, pr, end, pollTime)
+	if err != nil {
+		return err
+	}
+	return o.waitForDeployment(env, target.Version, end)
+}
+
+// findRollbackVersion returns the current version of the application in the environment and the change which deployed
+// the version to roll back to
+func (o *RollbackOptions) findRollbackVersion(env *v1.Environment) (string, *environments.AppVersionChange, error) {
+	tmpDir, err := ioutil.TempDir("", "jx-rollback-")
+	if err != nil {
+		return "", nil, errors.Wrap(err, "failed to create a temporary directory")
+	}
+	defer os.RemoveAll(tmpDir) //nolint:errcheck
+
+	gitURL := env.Spec.Source.URL
+	cloneURL, err := o.GitCloneURL(gitURL)
+	if err != nil {
+		return "", nil, err
+	}
+	dir := filepath.Join(tmpDir, "source")
+	err = o.Git().CloneWithOptions(cloneURL, dir, &gits.CloneOptions{
+		Filter: gits.BlobNoneFilter,
+		Branch: env.Spec.Source.Ref,
+	})
+	if err != nil {
+		return "", nil, errors.Wrapf(err, "cloning %s", gitURL)
+	}
+	history, err := environments.AppVersionHistory(o.Git(), dir, o.Application, o.Commits)
+	if err != nil {
+		return "", nil, errors.Wrapf(err, "finding the versions of %s in environment %s", o.Application, env.Name)
+	}
+	if len(history) == 0 || history[0].Version == "" {
+		return "", nil, fmt.Errorf("%s is not deployed in environment %s", o.Application, env.Name)
+	}
+	current := history[0].Version
+	if o.Version != "" {
+		if o.Version == current {
+			return "", nil, fmt.Errorf("%s is already at version %s in environment %s", o.Application, current, env.Name)
+		}
+		target := environments.FindAppVersion(history, o.Version)
+		if target == nil {
+			return "", nil, fmt.Errorf("version %s of %s was not found in the history of environment %s so it cannot be rolled back to, use 'jx promote' instead",
+				o.Version, o.Application, env.Name)
+		}
+		return current, target, nil
+	}
+	target := environments.PreviousAppVersion(history)
+	if target == nil {
+		return "", nil, fmt.Errorf("no version of %s before %s was found in environment %s", o.Application, current, env.Name)
+	}
+	return current, target, nil
+}
+
+// RollbackViaPullRequest raises a Pull Request on the environment repository which sets the application to the version
+func (o *RollbackOptions) RollbackViaPullRequest(env *v1.Environment, current string, version string) (*gits.GitPullRequest, error) {
+	title := environments.RollbackTitlePrefix + o.Application + " to " + version
+	message := fmt.Sprintf("%s\n\nRolls back %s in environment %s from version %s to %s", title, o.Application, env.Name, current, version)
+	if o.Reason != "" {
+		message += fmt.Sprintf(": %s", o.Reason)
+	}
+	details := gits.PullRequestDetails{
+		BranchName: "revert-" + o.Application + "-" + current,
+		Title:      title,
+		Message:    message,
+	}
+	details.AddDefaultReviewers(o.PullRequestsConfigFromTeamSettings())
+
+	modifyChartFn := func(requirements *helm.Requirements, metadata *chart.Metadata, values map[string]interface{},
+		templates map[string]string, dir string, details *gits.PullRequestDetails) error {
+		requirements.SetAppVersion(o.Application, version, "", "")
+		return nil
+	}
+	gitProvider, _, err := o.CreateGitProviderForURLWithoutKind(env.Spec.Source.URL)
+	if err != nil {
+		return nil, errors.Wrapf(err, "creating git provider for %s", env.Spec.Source.URL)
+	}
+	options := environments.EnvironmentPullRequestOptions{
+		Gitter:        o.Git(),
+		ModifyChartFn: modifyChartFn,
+		GitProvider:   gitProvider,
+		Provenance:    o.PullRequestProvenance("rollback"),
+		Helmer:        o.Helm(),
+	}
+	info, err := options.Create(env, "", &details, nil, "", true)
+	if err != nil {
+		return nil, errors.Wrapf(err, "creating the Pull Request rolling back %s", o.Application)
+	}
+	if info == nil {
+		return nil, nil
+	}
+	return info.PullRequest, nil
+}
+
+// waitForPullRequest waits for the rollback Pull Request to merge, merging it once its checks pass unless disabled
+func (o *RollbackOptions) waitForPullRequest(env *v1.Environment, pr *gits.GitPullRequest, end time.Time, pollTime time.Duration) error {
+	gitProvider, _, err := o.CreateGitProviderForURLWithoutKind(env.Spec.Source.URL)
+	if err != nil {
+		return errors.Wrapf(err, "creating git provider for %s", env.Spec.Source.URL)
+	}
+	logMergeFailure := false
+	lastStatus := ""
+	for {
+		err = gitProvider.UpdatePullRequestStatus(pr)
+		if err != nil {
+			log.Logger().Warnf("Failed to query the Pull Request status for %s %s", pr.URL, err)
+		} else if pr.Merged != nil && *pr.Merged {
+			log.Logger().Infof("Pull Request %s is merged", util.ColorInfo(pr.URL))
+			return nil
+		} else if pr.IsClosed() {
+			return fmt.Errorf("the rollback failed as Pull Request %s is closed without merging", pr.URL)
+		} else {
+			status, err := gitProvider.PullRequestLastCommitStatus(pr)
+			if err != nil {
+				log.Logger().Warnf("Failed to query the Pull Request last commit status for %s ref %s %s", pr.URL, pr.LastCommitSha, err)
+			} else {
+				if status != lastStatus {
+					lastStatus = status
+					log.Logger().Infof("Pull Request %s has status %s", util.ColorInfo(pr.URL), util.ColorInfo(status))
+				}
+				if status == "error" || status == "failure" {
+					return fmt.Errorf("Pull Request %s last commit has status %s for ref %s", pr.URL, status, pr.LastCommitSha)
+				}
+				if status == promote.GitStatusSuccess && !o.NoMergePullRequest {
+					err = gitProvider.MergePullRequest(pr, "jx rollback automatically merged the rollback Pull Request")
+					if err != nil && !logMergeFailure {
+						logMergeFailure = true
+						log.Logger().Warnf("Failed to merge the Pull Request %s due to %s maybe I don't have karma?", pr.URL, err)
+					}
+				}
+			}
+		}
+		if time.Now().After(end) {
+			return fmt.Errorf("timed out waiting for Pull Request %s to merge", pr.URL)
+		}
+		time.Sleep(pollTime)
+	}
+}
+
+// waitForDeployment waits for the deployments of the application in the environment to run the version and be healthy
+func (o *RollbackOptions) waitForDeployment(env *v1.Environment, version string, end time.Time) error {
+	kubeClient, ns, err := o.KubeClientForEnvironment(env)
+	if err != nil {
+		return errors.Wrapf(err, "creating the kube client of environment %s", env.Name)
+	}
+	lastReason := ""
+	for {
+		reason, err := DeploymentVersionHealth(kubeClient, ns, o.Application, version)
+		if err != nil {
+			return errors.Wrapf(err, "rolling back %s in environment %s", o.Application, env.Name)
+		}
+		if reason == "" {
+			log.Logger().Infof("%s is rolled back to version %s in environment %s", util.ColorInfo(o.Application), util.ColorInfo(version), util.ColorInfo(env.Name))
+			return nil
+		}
+		if reason != lastReason {
+			lastReason = reason
+			log.Logger().Infof("Waiting for the rollback of %s: %s", util.ColorInfo(o.Application), reason)
+		}
+		if time.Now().After(end) {
+			return fmt.Errorf("timed out waiting for %s to be rolled back to version %s in environment %s: %s", o.Application, version, env.Name, reason)
+		}
+		time.Sleep(healthPollInterval)
+	}
+}
+
+// DeploymentVersionHealth returns why the deployments of the application in the namespace do not run the version or
+// are not healthy yet, which is empty once they are, or an error if the rollout failed
+func DeploymentVersionHealth(kubeClient kubernetes.Interface, ns string, app string, version string) (string, error) {
+	list, err := kubeClient.AppsV1().Deployments(ns).List(metav1.ListOptions{})
+	if err != nil {
+		return "", errors.Wrapf(err, "listing deployments in namespace %s", ns)
+	}
+	for i := range list.Items {
+		d := &list.Items[i]
+		if kube.GetName(&d.ObjectMeta) != app {
+			continue
+		}
+		deployed := kube.GetVersion(&d.ObjectMeta)
+		if deployed != version {
+			return fmt.Sprintf("deployment %s runs version %s", d.Name, deployed), nil
+		}
+	}
+	return promote.CheckReleaseHealth(kubeClient, ns, app, "")
+}
@@ -5,6 +5,7 @@ import (
 	"io/ioutil"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/jenkins-x/jx/v2/pkg/cmd/helper"
 
@@ -36,6 +37,12 @@ type RshOptions struct {
 	DevPod      bool
 	Username    string
 	Environment string
+
+	Pipeline      string
+	Step          string
+	PreviousSteps bool
+	KeepPod       bool
+	Timeout       time.Duration
 }
 
 var (
@@ -56,6 +63,12 @@ var (
 
 		# To execute something in the remote shell (like classic rsh or ssh commands)
 		jx rsh -e 'do something'
+
+		# Open a terminal in a recreated pod of the failed step of a pipeline
+		jx rsh --pipeline 'myorg/myrepo/master #3'
+
+		# Open a terminal in a recreated pod of the build step of the latest build of a pull request
+		jx rsh --pipeline myorg/myrepo/PR-12 --step build
 `)
 )
 
@@ -84,6 +97,11 @@ func NewCmdRsh(commonOpts *opts.CommonOptions) *cobra.Command {
 	cmd.Flags().StringVarP(&options.ExecCmd, "execute", "e", DefaultRshCommand, "Execute this command on the remote container")
 	cmd.Flags().StringVarP(&options.Username, "username", "", "", "The username to create the DevPod. If not specified defaults to the current operating system user or $USER'")
 	cmd.Flags().StringVarP(&options.Environment, "environment", "", "", "The environment in which to look for the Deployment. Defaults to the current environment")
+	cmd.Flags().StringVarP(&options.Pipeline, "pipeline", "", "", "The pipeline, such as 'myorg/myrepo/master #3', to recreate the pod of a step of and open a terminal in. Defaults to the latest build if no build number is specified")
+	cmd.Flags().StringVarP(&options.Step, "step", "", "", "The step of the pipeline to debug. Defaults to the step which failed")
+	cmd.Flags().BoolVarP(&options.PreviousSteps, "previous-steps", "", true, "Runs the steps before the step of the pipeline to recreate its workspace, repeating any of their side effects")
+	cmd.Flags().BoolVarP(&options.KeepPod, "keep", "", false, "Keeps the pod recreating the step of the pipeline after the terminal exits")
+	cmd.Flags().DurationVarP(&options.Timeout, "timeout", "", 10*time.Minute, "The timeout to wait for the pod recreating the step of the pipeline to be ready")
 
 	return cmd
 }
@@ -91,6 +109,17 @@ func NewCmdRsh(commonOpts *opts.CommonOptions) *cobra.Command {
 func (o *RshOptions) Run() error {
 	args := o.Args
 
+	if o.Pipeline != "" {
+		client, devNs, err := o.KubeClientAndDevNamespace()
+		if err != nil {
+			return err
+		}
+		if o.Namespace != "" {
+			devNs = o.Namespace
+		}
+		return o.debugPipelineStep(client, devNs)
+	}
+
 	client, curNs, err := o.KubeClientAndNamespace()
 	if err != nil {
 		return err
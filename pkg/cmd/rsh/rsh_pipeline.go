@@ -0,0 +1,252 @@
+package rsh
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	v1 "github.com/jenkins-x/jx/v2/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/v2/pkg/kube"
+	"github.com/jenkins-x/jx/v2/pkg/kube/naming"
+	"github.com/jenkins-x/jx/v2/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/tekton"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// LabelDebugStep the label of the pods recreating a step of a pipeline to debug it, whose value is the step
+	LabelDebugStep = "jenkins.io/debug-step"
+
+	// stepContainerPrefix the prefix Tekton adds to the names of the containers of the steps
+	stepContainerPrefix = "step-"
+
+	// debugShellCommand opens bash if the image of the step has it, otherwise sh
+	debugShellCommand = "if command -v bash > /dev/null; then exec bash; else exec sh; fi"
+
+	// debugPodLifetime how long a debug pod runs for if it is not deleted
+	debugPodLifetime = 8 * time.Hour
+)
+
+// debugPipelineStep recreates the pod of a step of a pipeline, running the steps before it to recreate its workspace,
+// and opens a shell in the container of the step instead of running it
+func (o *RshOptions) debugPipelineStep(kubeClient kubernetes.Interface, ns string) error {
+	filter, err := parsePipelineFilter(o.Pipeline)
+	if err != nil {
+		return err
+	}
+	podList, err := kubeClient.CoreV1().Pods(ns).List(metav1.ListOptions{LabelSelector: filter.labelSelector()})
+	if err != nil {
+		return errors.Wrapf(err, "listing the pods of pipeline %s in namespace %s", o.Pipeline, ns)
+	}
+	var pods []corev1.Pod
+	for _, pod := range podList.Items {
+		if filter.matches(&pod) {
+			pods = append(pods, pod)
+		}
+	}
+	pod, container := findPipelineStep(pods, o.Step)
+	if pod == nil {
+		if o.Step != "" {
+			return fmt.Errorf("no step %s found in the pods of pipeline %s", o.Step, o.Pipeline)
+		}
+		return fmt.Errorf("no failed step found in the pods of pipeline %s, use --step to debug a step which did not fail", o.Pipeline)
+	}
+	stepName := strings.TrimPrefix(container.Name, stepContainerPrefix)
+	if command := stepCommand(container); command != "" {
+		log.Logger().Infof("step %s of pod %s ran: %s", util.ColorInfo(stepName), util.ColorInfo(pod.Name), command)
+	}
+
+	debugPod := debugPodForStep(pod, container.Name, o.PreviousSteps)
+	log.Logger().Infof("creating pod %s to debug step %s", util.ColorInfo(debugPod.Name), util.ColorInfo(stepName))
+	created, err := kubeClient.CoreV1().Pods(ns).Create(debugPod)
+	if err != nil {
+		return errors.Wrapf(err, "creating pod %s", debugPod.Name)
+	}
+	if !o.KeepPod {
+		defer func() {
+			err := kubeClient.CoreV1().Pods(ns).Delete(created.Name, &metav1.DeleteOptions{})
+			if err != nil {
+				log.Logger().Warnf("failed to delete pod %s: %s", created.Name, err)
+			}
+		}()
+	}
+
+	if o.PreviousSteps {
+		log.Logger().Info("waiting for the previous steps to recreate the workspace")
+	}
+	err = kube.WaitForPodNameToBeReady(kubeClient, ns, created.Name, o.Timeout)
+	if err != nil {
+		return errors.Wrapf(err, "waiting for pod %s to be ready, see 'kubectl logs -n %s %s --all-containers'", created.Name, ns, created.Name)
+	}
+
+	command := debugShellCommand
+	if o.ExecCmd != "" && o.ExecCmd != DefaultRshCommand {
+		command = o.ExecCmd
+	}
+	a := []string{"exec", "-it", "-n", ns, "-c", container.Name, created.Name, "--", DefaultShell, "-c", command}
+	log.Logger().Debugf("Running command: kubectl %s", strings.Join(a, " "))
+	return o.RunCommandInteractive(true, "kubectl", a...)
+}
+
+// pipelineFilter matches the pods of a pipeline such as 'myorg/myrepo/master #3'
+type pipelineFilter struct {
+	Owner      string
+	Repository string
+	Branch     string
+	// Build the build number, which matches every build if it is empty
+	Build string
+}
+
+// parsePipelineFilter parses a pipeline of the form owner/repository/branch with an optional ' #build'
+func parsePipelineFilter(pipeline string) (*pipelineFilter, error) {
+	name := strings.TrimSpace(pipeline)
+	build := ""
+	if i := strings.LastIndex(name, "#"); i >= 0 {
+		build = strings.TrimSpace(name[i+1:])
+		name = strings.TrimSpace(name[:i])
+	}
+	paths := strings.SplitN(name, "/", 3)
+	if len(paths) != 3 || paths[0] == "" || paths[1] == "" || paths[2] == "" {
+		return nil, util.InvalidOptionf("pipeline", pipeline, "the pipeline must be of the form owner/repository/branch with an optional ' #build'")
+	}
+	return &pipelineFilter{
+		Owner:      paths[0],
+		Repository: paths[1],
+		Branch:     paths[2],
+		Build:      build,
+	}, nil
+}
+
+// labelSelector returns the label selector of the pods of the builds of pipelines
+func (f *pipelineFilter) labelSelector() string {
+	selector := fmt.Sprintf("%s=%s", tekton.LabelType, tekton.BuildPipeline.String())
+	if f.Build != "" {
+		selector += fmt.Sprintf(",%s=%s", v1.LabelBuild, f.Build)
+	}
+	return selector
+}
+
+// matches returns true if the pod belongs to the pipeline. The labels are compared as they are displayed by
+// 'jx get build logs' as the labels keep the case of the owner, repository and branch
+func (f *pipelineFilter) matches(pod *corev1.Pod) bool {
+	return naming.ToValidName(pod.Labels[v1.LabelOwner]) == naming.ToValidName(f.Owner) &&
+		naming.ToValidName(pod.Labels[v1.LabelRepository]) == naming.ToValidName(f.Repository) &&
+		naming.ToValidName(pod.Labels[v1.LabelBranch]) == naming.ToValidName(f.Branch)
+}
+
+// findPipelineStep returns the most recent pod with the step of the given name, or with a failed step if no step is
+// specified, along with the container of the step
+func findPipelineStep(pods []corev1.Pod, step string) (*corev1.Pod, *corev1.Container) {
+	sort.Slice(pods, func(i, j int) bool {
+		return pods[j].CreationTimestamp.Before(&pods[i].CreationTimestamp)
+	})
+	for i := range pods {
+		pod := &pods[i]
+		if pod.Labels[LabelDebugStep] != "" {
+			continue
+		}
+		for j := range pod.Spec.Containers {
+			container := &pod.Spec.Containers[j]
+			if !strings.HasPrefix(container.Name, stepContainerPrefix) {
+				continue
+			}
+			if step != "" {
+				if container.Name == step || container.Name == stepContainerPrefix+step {
+					return pod, container
+				}
+				continue
+			}
+			if stepFailed(pod, container.Name) {
+				return pod, container
+			}
+		}
+	}
+	return nil, nil
+}
+
+func stepFailed(pod *corev1.Pod, containerName string) bool {
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.Name == containerName && status.State.Terminated != nil && status.State.Terminated.ExitCode != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// stepCommand returns the command the Tekton entrypoint of the step ran
+func stepCommand(container *corev1.Container) string {
+	for i, arg := range container.Args {
+		if arg == "-entrypoint" && i+1 < len(container.Args) {
+			command := []string{container.Args[i+1]}
+			for _, a := range container.Args[i+2:] {
+				if a != "--" {
+					command = append(command, a)
+				}
+			}
+			return strings.Join(command, " ")
+		}
+	}
+	return strings.Join(append(append([]string{}, container.Command...), container.Args...), " ")
+}
+
+// debugPodForStep returns a pod with the same image, environment and volumes as the given step of the pipeline pod
+// which keeps running so that a shell can be opened in it. The steps before it are run as init containers, so that
+// they recreate the workspace of the step, if previousSteps is true
+func debugPodForStep(pod *corev1.Pod, containerName string, previousSteps bool) *corev1.Pod {
+	spec := pod.Spec.DeepCopy()
+	spec.NodeName = ""
+	spec.RestartPolicy = corev1.RestartPolicyNever
+	spec.ActiveDeadlineSeconds = nil
+	spec.Containers = nil
+
+	stepName := strings.TrimPrefix(containerName, stepContainerPrefix)
+	for _, c := range pod.Spec.Containers {
+		if !strings.HasPrefix(c.Name, stepContainerPrefix) {
+			continue
+		}
+		container := *c.DeepCopy()
+		if container.Name != containerName {
+			if previousSteps {
+				spec.InitContainers = append(spec.InitContainers, container)
+			}
+			continue
+		}
+		container.Command = []string{DefaultShell, "-c", fmt.Sprintf("sleep %d", int(debugPodLifetime.Seconds()))}
+		container.Args = nil
+		container.LivenessProbe = nil
+		container.ReadinessProbe = nil
+		container.Stdin = true
+		container.TTY = true
+		spec.Containers = append(spec.Containers, container)
+		break
+	}
+
+	labels := map[string]string{
+		LabelDebugStep: naming.ToValidName(stepName),
+	}
+	for _, k := range []string{v1.LabelOwner, v1.LabelRepository, v1.LabelBranch, v1.LabelBuild, tekton.LabelContext} {
+		if v := pod.Labels[k]; v != "" {
+			labels[k] = v
+		}
+	}
+	annotations := map[string]string{}
+	for k, v := range pod.Annotations {
+		if !strings.HasPrefix(k, "tekton.dev/") && !strings.HasPrefix(k, "pipeline.tekton.dev/") {
+			annotations[k] = v
+		}
+	}
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        naming.ToValidNameTruncated(fmt.Sprintf("debug-%s-%s", pod.Name, stepName), 63),
+			Namespace:   pod.Namespace,
+			Labels:      labels,
+			Annotations: annotations,
+		},
+		Spec: *spec,
+	}
+}
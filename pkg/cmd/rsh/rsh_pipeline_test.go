@@ -0,0 +1,123 @@
+// +build unit
+
+package rsh
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestPipelineFilter(t *testing.T) {
+	filter, err := parsePipelineFilter("myorg/myrepo/master #3")
+	require.NoError(t, err)
+	assert.Equal(t, "jenkins.io/pipelineType=build,build=3", filter.labelSelector())
+
+	filter, err = parsePipelineFilter("myorg/myrepo/pr-12")
+	require.NoError(t, err)
+	assert.Equal(t, "jenkins.io/pipelineType=build", filter.labelSelector())
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels: map[string]string{"owner": "MyOrg", "repository": "myrepo", "branch": "PR-12"},
+		},
+	}
+	assert.True(t, filter.matches(pod))
+	pod.Labels["branch"] = "PR-13"
+	assert.False(t, filter.matches(pod))
+
+	_, err = parsePipelineFilter("myrepo #3")
+	assert.Error(t, err)
+}
+
+func TestDebugPodForFailedStep(t *testing.T) {
+	now := time.Now()
+	pods := []corev1.Pod{
+		stepPod("old", now.Add(-time.Hour), 1),
+		stepPod("new", now, 1),
+	}
+	pod, container := findPipelineStep(pods, "")
+	require.NotNil(t, pod)
+	assert.Equal(t, "new", pod.Name)
+	assert.Equal(t, "step-build", container.Name)
+	assert.Equal(t, "mvn install", stepCommand(container))
+
+	pod, container = findPipelineStep(pods, "git-merge")
+	require.NotNil(t, pod)
+	assert.Equal(t, "step-git-merge", container.Name)
+
+	debugPod := debugPodForStep(pod, "step-build", true)
+	assert.Equal(t, "debug-new-build", debugPod.Name)
+	assert.Equal(t, "build", debugPod.Labels[LabelDebugStep])
+	assert.Equal(t, "myorg", debugPod.Labels["owner"])
+	assert.Empty(t, debugPod.Labels["tekton.dev/task"])
+	assert.Equal(t, corev1.RestartPolicyNever, debugPod.Spec.RestartPolicy)
+
+	var initContainers []string
+	for _, c := range debugPod.Spec.InitContainers {
+		initContainers = append(initContainers, c.Name)
+	}
+	assert.Equal(t, []string{"place-tools", "step-git-merge"}, initContainers)
+	require.Len(t, debugPod.Spec.Containers, 1)
+	debugContainer := debugPod.Spec.Containers[0]
+	assert.Equal(t, "step-build", debugContainer.Name)
+	assert.Equal(t, "maven", debugContainer.Image)
+	assert.Equal(t, "/workspace/source", debugContainer.WorkingDir)
+	assert.Equal(t, []corev1.EnvVar{{Name: "FOO", Value: "bar"}}, debugContainer.Env)
+	assert.Equal(t, []string{DefaultShell, "-c", "sleep 28800"}, debugContainer.Command)
+	assert.Empty(t, debugContainer.Args)
+
+	debugPod = debugPodForStep(pod, "step-build", false)
+	require.Len(t, debugPod.Spec.InitContainers, 1)
+	assert.Equal(t, "place-tools", debugPod.Spec.InitContainers[0].Name)
+
+	// pods without a failed step are not debugged by default
+	pod, _ = findPipelineStep([]corev1.Pod{stepPod("ok", now, 0)}, "")
+	assert.Nil(t, pod)
+}
+
+func stepPod(name string, created time.Time, exitCode int32) corev1.Pod {
+	return corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			Namespace:         "jx",
+			CreationTimestamp: metav1.NewTime(created),
+			Labels: map[string]string{
+				"owner":           "myorg",
+				"tekton.dev/task": "myorg-myrepo-master-build",
+			},
+		},
+		Spec: corev1.PodSpec{
+			InitContainers: []corev1.Container{{Name: "place-tools", Image: "entrypoint"}},
+			Containers: []corev1.Container{
+				{
+					Name:  "step-git-merge",
+					Image: "builder-jx",
+					Args:  []string{"-wait_file", "", "-post_file", "/tekton/tools/0", "-entrypoint", "jx", "--", "step", "git", "merge"},
+				},
+				{
+					Name:       "step-build",
+					Image:      "maven",
+					WorkingDir: "/workspace/source",
+					Env:        []corev1.EnvVar{{Name: "FOO", Value: "bar"}},
+					Args:       []string{"-wait_file", "/tekton/tools/0", "-post_file", "/tekton/tools/1", "-entrypoint", "mvn", "--", "install"},
+				},
+			},
+		},
+		Status: corev1.PodStatus{
+			ContainerStatuses: []corev1.ContainerStatus{
+				{
+					Name:  "step-git-merge",
+					State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: 0}},
+				},
+				{
+					Name:  "step-build",
+					State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: exitCode}},
+				},
+			},
+		},
+	}
+}
@@ -4,11 +4,16 @@ import (
 	"fmt"
 	"net/url"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 
+	v1 "github.com/jenkins-x/jx/v2/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/v2/pkg/client/clientset/versioned"
+	"github.com/jenkins-x/jx/v2/pkg/kube/naming"
 	"github.com/jenkins-x/jx/v2/pkg/tekton/metapipeline"
 	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/labels"
 
 	"github.com/jenkins-x/jx/v2/pkg/cmd/helper"
 	"github.com/jenkins-x/jx/v2/pkg/jenkins"
@@ -49,12 +54,22 @@ type StartPipelineOptions struct {
 	Context      string
 	CustomLabels []string
 	CustomEnvs   []string
+	FromBuild    int
+	FromStep     string
+	BaseBranch   string
 }
 
 var (
 	startPipelineLong = templates.LongDesc(`
 		Starts the pipeline build.
 
+		When using Tekton the --from-build option reruns a previous build of the pipeline: the new build checks out the
+		same commit, or the same pull request merge, as the previous build and uses the same pipeline kind and context.
+		This lets you rerun a failed or cancelled build without pushing a new commit.
+
+		The --from-step option restarts the pipeline from the given step: the steps before it are skipped and the
+		remaining steps run against the same commit as the latest build, or the build given by --from-build.
+
 `)
 
 	startPipelineExample = templates.Examples(`
@@ -66,6 +81,12 @@ var (
 
 		# Select the pipeline to start and tail the log
 		jx start pipeline -t
+
+		# Rerun build 3 of a pull request from the same commit
+		jx start pipeline foo/bar/PR-12 --from-build 3
+
+		# Restart the latest build of the master branch from the promote step
+		jx start pipeline foo/bar/master --from-step promote-changelog
 	`)
 )
 
@@ -96,6 +117,9 @@ func NewCmdStartPipeline(commonOpts *opts.CommonOptions) *cobra.Command {
 	cmd.Flags().StringVar(&options.ServiceAccount, "service-account", "tekton-bot", "The Kubernetes ServiceAccount to use to run the meta pipeline")
 	cmd.Flags().StringArrayVarP(&options.CustomLabels, "label", "l", nil, "List of custom labels to be applied to the generated PipelineRun (can be use multiple times)")
 	cmd.Flags().StringArrayVarP(&options.CustomEnvs, "env", "e", nil, "List of custom environment variables to be applied to the generated PipelineRun that are created (can be use multiple times)")
+	cmd.Flags().IntVarP(&options.FromBuild, "from-build", "", 0, "Reruns the given build number of the pipeline from the same commit with the same parameters (Tekton only)")
+	cmd.Flags().StringVarP(&options.FromStep, "from-step", "", "", "Restarts the latest build of the pipeline, or the build given by --from-build, from the given step skipping the steps before it (Tekton only)")
+	cmd.Flags().StringVarP(&options.BaseBranch, "base-branch", "", "", "The branch a pull request is merged into when rerunning a pull request build. Defaults to the default branch of the repository")
 
 	options.JenkinsSelector.AddFlags(cmd)
 
@@ -166,6 +190,12 @@ func (o *StartPipelineOptions) Run() error {
 		}
 		args = []string{name}
 	}
+	if o.FromBuild > 0 && !devEnv.Spec.IsLighthouse() {
+		return util.InvalidOptionf("from-build", o.FromBuild, "rerunning a previous build is only supported with Tekton")
+	}
+	if o.FromStep != "" && !devEnv.Spec.IsLighthouse() {
+		return util.InvalidOptionf("from-step", o.FromStep, "restarting a pipeline from a step is only supported with Tekton")
+	}
 	for _, a := range args {
 		if devEnv.Spec.IsLighthouse() {
 			err = o.createMetaPipeline(a)
@@ -228,6 +258,31 @@ func (o *StartPipelineOptions) createMetaPipeline(jobName string) error {
 
 	pullRef := metapipeline.NewPullRef(sourceURL, branch, "")
 	pipelineKind := o.determinePipelineKind(branch)
+	pipelineContext := o.Context
+	if o.FromBuild > 0 || o.FromStep != "" {
+		activity, err := findPipelineActivity(jxClient, ns, owner, repo, branch, o.FromBuild, o.Context)
+		if err != nil {
+			return err
+		}
+		baseBranch := ""
+		if isPullRequestBranch(branch) {
+			baseBranch, err = o.pullRequestBaseBranch(sourceURL, owner, repo)
+			if err != nil {
+				return err
+			}
+		}
+		pullRef, pipelineKind = o.rerunPullRef(sourceURL, branch, baseBranch, activity)
+		if pipelineContext == "" {
+			pipelineContext = activity.Spec.Context
+		}
+		if o.FromStep != "" {
+			log.Logger().Infof("restarting build %s of %s from step %s at commit %s", util.ColorInfo(activity.Spec.Build),
+				util.ColorInfo(jobName), util.ColorInfo(o.FromStep), util.ColorInfo(activity.Spec.LastCommitSHA))
+		} else {
+			log.Logger().Infof("rerunning build %s of %s from commit %s", util.ColorInfo(activity.Spec.Build),
+				util.ColorInfo(jobName), util.ColorInfo(activity.Spec.LastCommitSHA))
+		}
+	}
 	envVarMap, err := util.ExtractKeyValuePairs(o.CustomEnvs, "=")
 	if err != nil {
 		return errors.Wrap(err, "unable to parse env variables")
@@ -241,10 +296,11 @@ func (o *StartPipelineOptions) createMetaPipeline(jobName string) error {
 	pipelineCreateParam := metapipeline.PipelineCreateParam{
 		PullRef:        pullRef,
 		PipelineKind:   pipelineKind,
-		Context:        o.Context,
+		Context:        pipelineContext,
 		EnvVariables:   envVarMap,
 		Labels:         labelMap,
 		ServiceAccount: o.ServiceAccount,
+		StartStep:      o.FromStep,
 	}
 
 	pipelineActivity, tektonCRDs, err := client.Create(pipelineCreateParam)
@@ -265,6 +321,90 @@ func (o *StartPipelineOptions) createMetaPipeline(jobName string) error {
 	return nil
 }
 
+// findPipelineActivity finds the PipelineActivity of the given build of the branch of a repository, or of the latest
+// build if the build number is not positive
+func findPipelineActivity(jxClient versioned.Interface, ns string, owner string, repo string, branch string, build int, context string) (*v1.PipelineActivity, error) {
+	selectorLabels := labels.Set{
+		v1.LabelOwner:      naming.ToValidValue(owner),
+		v1.LabelRepository: naming.ToValidValue(repo),
+		v1.LabelBranch:     naming.ToValidValue(branch),
+	}
+	if build > 0 {
+		selectorLabels[v1.LabelBuild] = strconv.Itoa(build)
+	}
+	activities, err := kube.ListSelectedPipelineActivities(jxClient.JenkinsV1().PipelineActivities(ns), labels.SelectorFromSet(selectorLabels), nil)
+	if err != nil {
+		return nil, errors.Wrapf(err, "listing the PipelineActivities of %s/%s/%s", owner, repo, branch)
+	}
+	var answer *v1.PipelineActivity
+	latest := 0
+	for i := range activities.Items {
+		activity := &activities.Items[i]
+		if context != "" && activity.Spec.Context != context {
+			continue
+		}
+		if build > 0 {
+			return activity, nil
+		}
+		number, err := strconv.Atoi(activity.Spec.Build)
+		if err == nil && number > latest {
+			answer = activity
+			latest = number
+		}
+	}
+	if answer != nil {
+		return answer, nil
+	}
+	if build > 0 {
+		return nil, fmt.Errorf("could not find build %d of %s/%s/%s", build, owner, repo, branch)
+	}
+	return nil, fmt.Errorf("could not find any builds of %s/%s/%s", owner, repo, branch)
+}
+
+// pullRequestBaseBranch returns the branch the pull requests of the repository are merged into which defaults to the
+// default branch of the repository as the base branch is not recorded on the PipelineActivity
+func (o *StartPipelineOptions) pullRequestBaseBranch(sourceURL string, owner string, repo string) (string, error) {
+	if o.BaseBranch != "" {
+		return o.BaseBranch, nil
+	}
+	provider, _, err := o.CreateGitProviderForURLWithoutKind(sourceURL)
+	if err != nil {
+		return "", errors.Wrapf(err, "creating git provider for %s", sourceURL)
+	}
+	repository, err := provider.GetRepository(owner, repo)
+	if err != nil {
+		return "", errors.Wrapf(err, "getting repository %s/%s", owner, repo)
+	}
+	if repository.DefaultBranch == "" {
+		return "", fmt.Errorf("could not determine the default branch of %s/%s, please specify the base branch of the pull request using --base-branch", owner, repo)
+	}
+	return repository.DefaultBranch, nil
+}
+
+// rerunPullRef returns the pull ref and pipeline kind which rebuild the same commit as the given previous build. The
+// base branch is only used for pull request builds
+func (o *StartPipelineOptions) rerunPullRef(sourceURL string, branch string, baseBranch string, activity *v1.PipelineActivity) (metapipeline.PullRef, metapipeline.PipelineKind) {
+	spec := activity.Spec
+	if !isPullRequestBranch(branch) {
+		return metapipeline.NewPullRef(sourceURL, branch, spec.LastCommitSHA), o.determinePipelineKind(branch)
+	}
+
+	prRef := metapipeline.PullRequestRef{
+		ID:       strings.TrimPrefix(strings.ToUpper(branch), "PR-"),
+		MergeSHA: spec.LastCommitSHA,
+	}
+	kind := metapipeline.PullRequestPipeline
+	if o.PipelineKind != "" {
+		kind = metapipeline.StringToPipelineKind(o.PipelineKind)
+	}
+	return metapipeline.NewPullRefWithPullRequest(sourceURL, baseBranch, spec.BaseSHA, prRef), kind
+}
+
+// isPullRequestBranch returns true if the branch of a pipeline is a pull request such as PR-12
+func isPullRequestBranch(branch string) bool {
+	return strings.HasPrefix(strings.ToUpper(branch), "PR-")
+}
+
 func (o *StartPipelineOptions) createProwJob(jobname string) error {
 	parts := strings.Split(jobname, "/")
 	if len(parts) != 3 {
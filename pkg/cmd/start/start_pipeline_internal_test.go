@@ -0,0 +1,107 @@
+// +build unit
+
+package start
+
+import (
+	"testing"
+
+	v1 "github.com/jenkins-x/jx/v2/pkg/apis/jenkins.io/v1"
+	jxfake "github.com/jenkins-x/jx/v2/pkg/client/clientset/versioned/fake"
+	"github.com/jenkins-x/jx/v2/pkg/tekton/metapipeline"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func newActivity(name string, branch string, build string, context string) *v1.PipelineActivity {
+	return &v1.PipelineActivity{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "jx",
+			Labels: map[string]string{
+				v1.LabelOwner:      "foo",
+				v1.LabelRepository: "bar",
+				v1.LabelBranch:     branch,
+				v1.LabelBuild:      build,
+			},
+		},
+		Spec: v1.PipelineActivitySpec{
+			Build:         build,
+			Context:       context,
+			LastCommitSHA: "sha-" + name,
+		},
+	}
+}
+
+func TestFindPipelineActivity(t *testing.T) {
+	jxClient := jxfake.NewSimpleClientset([]runtime.Object{
+		newActivity("foo-bar-master-1", "master", "1", "release"),
+		newActivity("foo-bar-master-2", "master", "2", "release"),
+		newActivity("foo-bar-master-10", "master", "10", "release"),
+		newActivity("foo-bar-master-11", "master", "11", "lint"),
+		newActivity("foo-bar-pr-12-3", "PR-12", "3", "pr"),
+	}...)
+
+	activity, err := findPipelineActivity(jxClient, "jx", "foo", "bar", "master", 2, "")
+	require.NoError(t, err)
+	assert.Equal(t, "foo-bar-master-2", activity.Name)
+
+	activity, err = findPipelineActivity(jxClient, "jx", "foo", "bar", "master", 0, "")
+	require.NoError(t, err)
+	assert.Equal(t, "foo-bar-master-11", activity.Name, "the latest build should be found")
+
+	activity, err = findPipelineActivity(jxClient, "jx", "foo", "bar", "master", 0, "release")
+	require.NoError(t, err)
+	assert.Equal(t, "foo-bar-master-10", activity.Name, "the latest build of the context should be found")
+
+	activity, err = findPipelineActivity(jxClient, "jx", "foo", "bar", "PR-12", 3, "")
+	require.NoError(t, err)
+	assert.Equal(t, "foo-bar-pr-12-3", activity.Name)
+
+	_, err = findPipelineActivity(jxClient, "jx", "foo", "bar", "master", 5, "")
+	assert.Error(t, err, "a missing build should fail")
+
+	_, err = findPipelineActivity(jxClient, "jx", "foo", "bar", "master", 11, "release")
+	assert.Error(t, err, "a build of another context should fail")
+
+	_, err = findPipelineActivity(jxClient, "jx", "foo", "bar", "feature", 0, "")
+	assert.Error(t, err, "a branch without builds should fail")
+}
+
+func TestRerunPullRef(t *testing.T) {
+	sourceURL := "https://github.com/foo/bar.git"
+	o := &StartPipelineOptions{}
+
+	activity := &v1.PipelineActivity{
+		Spec: v1.PipelineActivitySpec{
+			LastCommitSHA: "abc123",
+		},
+	}
+	pullRef, kind := o.rerunPullRef(sourceURL, "master", "", activity)
+	assert.Equal(t, "master", pullRef.BaseBranch())
+	assert.Equal(t, "abc123", pullRef.BaseSHA())
+	assert.Empty(t, pullRef.PullRequests())
+	assert.Equal(t, metapipeline.ReleasePipeline, kind)
+
+	pullRef, kind = o.rerunPullRef(sourceURL, "feature", "", activity)
+	assert.Equal(t, "feature", pullRef.BaseBranch())
+	assert.Equal(t, metapipeline.FeaturePipeline, kind)
+
+	activity = &v1.PipelineActivity{
+		Spec: v1.PipelineActivitySpec{
+			LastCommitSHA: "merge123",
+			BaseSHA:       "base123",
+		},
+	}
+	pullRef, kind = o.rerunPullRef(sourceURL, "PR-12", "main", activity)
+	assert.Equal(t, "main", pullRef.BaseBranch(), "the pull request should be merged into the given base branch")
+	assert.Equal(t, "base123", pullRef.BaseSHA())
+	require.Len(t, pullRef.PullRequests(), 1)
+	assert.Equal(t, metapipeline.PullRequestRef{ID: "12", MergeSHA: "merge123"}, pullRef.PullRequests()[0])
+	assert.Equal(t, metapipeline.PullRequestPipeline, kind)
+
+	o.PipelineKind = metapipeline.FeaturePipeline.String()
+	_, kind = o.rerunPullRef(sourceURL, "pr-12", "main", activity)
+	assert.Equal(t, metapipeline.FeaturePipeline, kind, "the pipeline kind option should be used")
+}
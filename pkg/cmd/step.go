@@ -9,7 +9,10 @@ import (
 	"github.com/jenkins-x/jx/v2/pkg/cmd/step/bdd"
 	"github.com/jenkins-x/jx/v2/pkg/cmd/step/boot"
 	"github.com/jenkins-x/jx/v2/pkg/cmd/step/buildpack"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/step/cache"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/step/change"
 	"github.com/jenkins-x/jx/v2/pkg/cmd/step/cluster"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/step/cosign"
 	"github.com/jenkins-x/jx/v2/pkg/cmd/step/create"
 	"github.com/jenkins-x/jx/v2/pkg/cmd/step/e2e"
 	"github.com/jenkins-x/jx/v2/pkg/cmd/step/env"
@@ -20,9 +23,11 @@ import (
 	"github.com/jenkins-x/jx/v2/pkg/cmd/step/nexus"
 	"github.com/jenkins-x/jx/v2/pkg/cmd/step/post"
 	"github.com/jenkins-x/jx/v2/pkg/cmd/step/pr"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/step/repo"
 	"github.com/jenkins-x/jx/v2/pkg/cmd/step/report"
 	"github.com/jenkins-x/jx/v2/pkg/cmd/step/restore"
 	"github.com/jenkins-x/jx/v2/pkg/cmd/step/scheduler"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/step/secrets"
 	"github.com/jenkins-x/jx/v2/pkg/cmd/step/syntax"
 	"github.com/jenkins-x/jx/v2/pkg/cmd/step/update"
 	"github.com/jenkins-x/jx/v2/pkg/cmd/step/verify"
@@ -52,12 +57,16 @@ func NewCmdStep(commonOpts *opts.CommonOptions) *cobra.Command {
 	cmd.AddCommand(bdd.NewCmdStepBDD(commonOpts))
 	cmd.AddCommand(e2e.NewCmdStepE2E(commonOpts))
 	cmd.AddCommand(step.NewCmdStepBlog(commonOpts))
+	cmd.AddCommand(change.NewCmdStepChange(commonOpts))
 	cmd.AddCommand(step.NewCmdStepChangelog(commonOpts))
 	cmd.AddCommand(cluster.NewCmdStepCluster(commonOpts))
+	cmd.AddCommand(cosign.NewCmdStepCosign(commonOpts))
 	cmd.AddCommand(step.NewCmdStepCredential(commonOpts))
 	cmd.AddCommand(create.NewCmdStepCreate(commonOpts))
 	cmd.AddCommand(step.NewCmdStepCustomPipeline(commonOpts))
+	cmd.AddCommand(step.NewCmdStepDetect(commonOpts))
 	cmd.AddCommand(env.NewCmdStepEnv(commonOpts))
+	cmd.AddCommand(step.NewCmdStepExchangeTokens(commonOpts))
 	cmd.AddCommand(expose.NewCmdStepExpose(commonOpts))
 	cmd.AddCommand(get.NewCmdStepGet(commonOpts))
 	cmd.AddCommand(git.NewCmdStepGit(commonOpts))
@@ -69,9 +78,12 @@ func NewCmdStep(commonOpts *opts.CommonOptions) *cobra.Command {
 	cmd.AddCommand(step.NewCmdStepNextBuildNumber(commonOpts))
 	cmd.AddCommand(pr.NewCmdStepPR(commonOpts))
 	cmd.AddCommand(post.NewCmdStepPost(commonOpts))
+	cmd.AddCommand(repo.NewCmdStepRepo(commonOpts))
 	cmd.AddCommand(step.NewCmdStepRelease(commonOpts))
 	cmd.AddCommand(step.NewCmdStepReplicate(commonOpts))
+	cmd.AddCommand(secrets.NewCmdStepSecrets(commonOpts))
 	cmd.AddCommand(step.NewCmdStepSplitMonorepo(commonOpts))
+	cmd.AddCommand(step.NewCmdStepSyft(commonOpts))
 	cmd.AddCommand(syntax.NewCmdStepSyntax(commonOpts))
 	cmd.AddCommand(step.NewCmdStepTag(commonOpts))
 	cmd.AddCommand(step.NewCmdStepValidate(commonOpts))
@@ -80,6 +92,7 @@ func NewCmdStep(commonOpts *opts.CommonOptions) *cobra.Command {
 	cmd.AddCommand(step.NewCmdStepWaitForChart(commonOpts))
 	cmd.AddCommand(step.NewCmdStepStash(commonOpts))
 	cmd.AddCommand(step.NewCmdStepUnstash(commonOpts))
+	cmd.AddCommand(cache.NewCmdStepCache(commonOpts))
 	cmd.AddCommand(step.NewCmdStepValuesSchemaTemplate(commonOpts))
 	cmd.AddCommand(scheduler.NewCmdStepScheduler(commonOpts))
 	cmd.AddCommand(config.NewCmdStepPatchConfigMap(commonOpts))
@@ -765,7 +765,7 @@ func (o *StepBDDOptions) createCluster(cluster *CreateCluster) error {
 }
 
 func (o *StepBDDOptions) getTestSpecificRequirementsIfExists(cluster *CreateCluster) (*config.RequirementsConfig, error) {
-	requirements, requirementsFile, _ := config.LoadRequirementsConfig(o.Flags.Dir, config.DefaultFailOnValidationError)
+	requirements, requirementsFile, _ := config.LoadRequirementsConfigWithOptions(o.Flags.Dir, config.DefaultFailOnValidationError, o.RequirementsOptions())
 	if requirements == nil {
 		return nil, nil
 	}
@@ -784,7 +784,7 @@ func (o *StepBDDOptions) getTestSpecificRequirementsIfExists(cluster *CreateClus
 		o.ensureTestEnvironmentRepoSetup(requirements, "staging")
 		o.ensureTestEnvironmentRepoSetup(requirements, "production")
 
-		err := requirements.SaveConfig(requirementsFile)
+		err := requirements.SaveConfigWithOptions(requirementsFile, o.RequirementsOptions())
 		if err != nil {
 			return nil, errors.Wrapf(err, "failed to save file %s after setting the cluster name to %s", requirementsFile, cluster.Name)
 		}
@@ -110,7 +110,7 @@ func (o *StepBootVaultOptions) Run() error {
 		return err
 	}
 
-	requirements, _, err := config.LoadRequirementsConfig(o.Dir, config.DefaultFailOnValidationError)
+	requirements, _, err := config.LoadRequirementsConfigWithOptions(o.Dir, config.DefaultFailOnValidationError, o.RequirementsOptions())
 	if err != nil {
 		return err
 	}
@@ -139,7 +139,17 @@ func (o *StepBootVaultOptions) setupExternalVault(requirements *config.Requireme
 	if namespace == "" {
 		namespace = ns
 	}
-	vault, err := vault.NewExternalVault(requirements.Vault.URL, requirements.Vault.ServiceAccount, namespace, requirements.Vault.SecretEngineMountPoint, requirements.Vault.KubernetesAuthPath)
+	vault, err := vault.FromMap(map[string]string{
+		vault.URL:                    requirements.Vault.URL,
+		vault.ServiceAccount:         requirements.Vault.ServiceAccount,
+		vault.Namespace:              namespace,
+		vault.SecretEngineMountPoint: requirements.Vault.SecretEngineMountPoint,
+		vault.KubernetesAuthPath:     requirements.Vault.KubernetesAuthPath,
+		vault.KubernetesAuthRole:     requirements.Vault.KubernetesAuthRole,
+		vault.EnterpriseNamespace:    requirements.Vault.EnterpriseNamespace,
+		vault.AuthMethod:             requirements.Vault.AuthMethod,
+		vault.TokenSecret:            requirements.Vault.TokenSecret,
+	}, namespace)
 	if err != nil {
 		return errors.Wrapf(err, "invalid configuration for external Vault setup")
 	}
@@ -161,7 +171,7 @@ func (o *StepBootVaultOptions) setupExternalVault(requirements *config.Requireme
 	}
 
 	log.Logger().Infof("Using external Vault instance %s - %s", vault.URL, util.ColorInfo("OK"))
-	return nil
+	return o.installAgentInjector(requirements, ns, vault.URL)
 }
 
 func (o *StepBootVaultOptions) setupInClusterVault(requirements *config.RequirementsConfig, ns string, kubeClient kubernetes.Interface) error {
@@ -249,7 +259,8 @@ func (o *StepBootVaultOptions) setupInClusterVault(requirements *config.Requirem
 	if err != nil {
 		return errors.Wrap(err, "unable to create/update Vault")
 	}
-	return nil
+	vaultURL := fmt.Sprintf("http://%s.%s:%s", requirements.Vault.Name, ns, pkgvault.DefaultVaultPort)
+	return o.installAgentInjector(requirements, ns, vaultURL)
 }
 
 func (o *StepBootVaultOptions) createAWSParam(requirements *config.RequirementsConfig) (create.AWSParam, error) {
@@ -378,6 +389,32 @@ func (o *StepBootVaultOptions) installOperator(requirements *config.Requirements
 	return nil
 }
 
+// installAgentInjector installs the Vault Agent injector if the secrets are delivered to pods with the Vault Agent
+func (o *StepBootVaultOptions) installAgentInjector(requirements *config.RequirementsConfig, ns string, vaultURL string) error {
+	if !pkgvault.AgentEnabled(requirements) {
+		return nil
+	}
+	values := []string{
+		"server.enabled=false",
+		"injector.enabled=true",
+		"injector.externalVaultAddr=" + vaultURL,
+	}
+	log.Logger().Infof("Installing the Vault Agent injector %s with helm values: %v", util.ColorInfo(pkgvault.AgentInjectorReleaseName), util.ColorInfo(values))
+
+	helmOptions := helm.InstallChartOptions{
+		Chart:       pkgvault.AgentInjectorChart,
+		ReleaseName: pkgvault.AgentInjectorReleaseName,
+		Repository:  pkgvault.AgentInjectorRepository,
+		Ns:          ns,
+		SetValues:   values,
+	}
+	err := o.InstallChartWithOptions(helmOptions)
+	if err != nil {
+		return errors.Wrap(err, "unable to install the Vault Agent injector")
+	}
+	return nil
+}
+
 // verifyVaultIngress verifies there is a Vault ingress and if not create one if there is a file at
 func (o *StepBootVaultOptions) verifyVaultIngress(requirements *config.RequirementsConfig, kubeClient kubernetes.Interface, ns string, systemVaultName string) (bool, error) {
 	fileName := filepath.Join(o.Dir, "vault-ing.tmpl.yaml")
@@ -0,0 +1,163 @@
+package cache
+
+import (
+	"compress/gzip"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/jenkins-x/jx/v2/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts/step"
+	"github.com/jenkins-x/jx/v2/pkg/kube"
+	"github.com/jenkins-x/jx/v2/pkg/kube/naming"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/mholt/archiver"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+const (
+	archiveExtension = ".tar.gz"
+)
+
+// StepCacheOptions contains the command line flags shared by the cache steps
+type StepCacheOptions struct {
+	step.StepOptions
+
+	Name      string
+	Key       string
+	Paths     []string
+	Dir       string
+	BucketURL string
+	Owner     string
+	Repo      string
+	Timeout   time.Duration
+}
+
+// NewCmdStepCache creates the command
+func NewCmdStepCache(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &StepCacheOptions{
+		StepOptions: step.StepOptions{
+			CommonOptions: commonOpts,
+		},
+	}
+
+	cmd := &cobra.Command{
+		Use:   "cache",
+		Short: "cache [command]",
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+	cmd.AddCommand(NewCmdStepCacheRestore(commonOpts))
+	cmd.AddCommand(NewCmdStepCacheSave(commonOpts))
+	return cmd
+}
+
+// Run implements this command
+func (o *StepCacheOptions) Run() error {
+	return o.Cmd.Help()
+}
+
+func (o *StepCacheOptions) addFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&o.Name, "name", "n", "", "The name of the cache")
+	cmd.Flags().StringVarP(&o.Key, "key", "k", "", "The key of the cache, defaults to the name of the cache")
+	cmd.Flags().StringArrayVarP(&o.Paths, "path", "p", nil, "The directories to cache, a leading '~' is replaced by the home directory")
+	cmd.Flags().StringVarP(&o.Dir, "dir", "d", "", "The directory, usually a mounted PersistentVolumeClaim, to store the cache in. If not specified the cache is stored in the bucket")
+	cmd.Flags().StringVarP(&o.BucketURL, "bucket-url", "", "", "The bucket URL to store the cache in. Defaults to the storage location of the team for the '"+kube.ClassificationCache+"' classifier")
+	cmd.Flags().StringVarP(&o.Owner, "owner", "", "", "The owner of the repository being built, defaults to $REPO_OWNER")
+	cmd.Flags().StringVarP(&o.Repo, "repo", "", "", "The repository being built, defaults to $REPO_NAME")
+	cmd.Flags().DurationVarP(&o.Timeout, "timeout", "", time.Minute*5, "The timeout to read or write the cache in the bucket")
+}
+
+// validate checks the options and defaults the key, owner and repository
+func (o *StepCacheOptions) validate() error {
+	if o.Name == "" {
+		return util.MissingOption("name")
+	}
+	if len(o.Paths) == 0 {
+		return util.MissingOption("path")
+	}
+	if o.Key == "" {
+		o.Key = o.Name
+	}
+	if o.Owner == "" {
+		o.Owner = os.Getenv("REPO_OWNER")
+	}
+	if o.Repo == "" {
+		o.Repo = os.Getenv("REPO_NAME")
+	}
+	if o.Owner == "" || o.Repo == "" {
+		gitInfo, err := o.FindGitInfo("")
+		if err != nil {
+			return errors.Wrap(err, "failed to find the repository being built, please specify --owner and --repo")
+		}
+		if o.Owner == "" {
+			o.Owner = gitInfo.Organisation
+		}
+		if o.Repo == "" {
+			o.Repo = gitInfo.Name
+		}
+	}
+	return nil
+}
+
+// bucketURL returns the bucket URL to store the cache in
+func (o *StepCacheOptions) bucketURL() (string, error) {
+	if o.BucketURL != "" {
+		return o.BucketURL, nil
+	}
+	settings, err := o.TeamSettings()
+	if err != nil {
+		return "", errors.Wrap(err, "failed to load the team settings")
+	}
+	location := settings.StorageLocationOrDefault(kube.ClassificationCache)
+	if location.BucketURL == "" {
+		return "", errors.Errorf("no bucket is configured to store caches, use 'jx edit storage -c %s --bucket-url <url>' or a claimName for the cache", kube.ClassificationCache)
+	}
+	return location.BucketURL, nil
+}
+
+// archiveName returns the path of the archive of the cached directory relative to the cache storage
+func (o *StepCacheOptions) archiveName(path string) string {
+	name := naming.ToValidName(strings.TrimPrefix(path, "~"))
+	if name == "" {
+		name = "home"
+	}
+	return filepath.Join("jenkins-x", kube.ClassificationCache, o.Owner, o.Repo, o.Key, name+archiveExtension)
+}
+
+// bucketFileURL returns the URL of the archive in the bucket
+func bucketFileURL(bucketURL string, name string) (*url.URL, error) {
+	u, err := url.Parse(bucketURL)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse bucket URL %s", bucketURL)
+	}
+	u.Path = "/" + filepath.ToSlash(name)
+	return u, nil
+}
+
+// expandPath replaces a leading '~' with the home directory
+func expandPath(path string) string {
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		return filepath.Join(util.HomeDir(), strings.TrimPrefix(path, "~"))
+	}
+	return path
+}
+
+// newTarGz creates the archiver for the cache archives which overwrites existing files
+func newTarGz() *archiver.TarGz {
+	return &archiver.TarGz{
+		Tar: &archiver.Tar{
+			OverwriteExisting: true,
+			MkdirAll:          true,
+		},
+		CompressionLevel: gzip.DefaultCompression,
+	}
+}
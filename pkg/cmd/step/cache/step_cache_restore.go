@@ -0,0 +1,138 @@
+package cache
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/jenkins-x/jx/v2/pkg/cloud/buckets"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts/step"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/v2/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// StepCacheRestoreOptions contains the command line flags
+type StepCacheRestoreOptions struct {
+	StepCacheOptions
+}
+
+var (
+	stepCacheRestoreLong = templates.LongDesc(`
+		Restores the cached directories of a pipeline saved by a previous build with 'jx step cache save'.
+
+		The caches are stored in the storage location of the team for the 'cache' classifier or, if --dir is specified,
+		in a directory which is usually a mounted PersistentVolumeClaim. A missing cache is not an error so that the
+		first build of a repository succeeds.
+
+		These steps are usually added to the pipeline by the 'caches' section of the jenkins-x.yml file.
+`)
+
+	stepCacheRestoreExample = templates.Examples(`
+		# restores the local maven repository
+		jx step cache restore --name maven --path ~/.m2/repository
+
+		# restores node_modules from a mounted volume
+		jx step cache restore --name node --path node_modules --dir /jx-cache/node
+`)
+)
+
+// NewCmdStepCacheRestore creates the command
+func NewCmdStepCacheRestore(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &StepCacheRestoreOptions{
+		StepCacheOptions: StepCacheOptions{
+			StepOptions: step.StepOptions{
+				CommonOptions: commonOpts,
+			},
+		},
+	}
+	cmd := &cobra.Command{
+		Use:     "restore",
+		Short:   "Restores the cached directories of a pipeline",
+		Long:    stepCacheRestoreLong,
+		Example: stepCacheRestoreExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+	options.addFlags(cmd)
+	return cmd
+}
+
+// Run implements this command
+func (o *StepCacheRestoreOptions) Run() error {
+	err := o.validate()
+	if err != nil {
+		return err
+	}
+	bucketURL := ""
+	if o.Dir == "" {
+		bucketURL, err = o.bucketURL()
+		if err != nil {
+			log.Logger().Warnf("not restoring cache %s: %s", o.Name, err)
+			return nil
+		}
+	}
+	for _, path := range o.Paths {
+		err = o.restorePath(path, bucketURL)
+		if err != nil {
+			return errors.Wrapf(err, "restoring %s from cache %s", path, o.Name)
+		}
+	}
+	return nil
+}
+
+func (o *StepCacheRestoreOptions) restorePath(path string, bucketURL string) error {
+	name := o.archiveName(path)
+	archive := filepath.Join(o.Dir, name)
+	if o.Dir == "" {
+		u, err := bucketFileURL(bucketURL, name)
+		if err != nil {
+			return err
+		}
+		data, err := buckets.ReadBucketURL(u, o.Timeout)
+		if err != nil {
+			log.Logger().Infof("no cache found for %s: %s", util.ColorInfo(path), err)
+			return nil
+		}
+		tmpDir, err := ioutil.TempDir("", "jx-cache-")
+		if err != nil {
+			return errors.Wrap(err, "creating temp dir")
+		}
+		defer os.RemoveAll(tmpDir) //nolint:errcheck
+		archive = filepath.Join(tmpDir, filepath.Base(name))
+		err = ioutil.WriteFile(archive, data, util.DefaultWritePermissions)
+		if err != nil {
+			return errors.Wrapf(err, "writing %s", archive)
+		}
+	} else {
+		exists, err := util.FileExists(archive)
+		if err != nil {
+			return errors.Wrapf(err, "checking if %s exists", archive)
+		}
+		if !exists {
+			log.Logger().Infof("no cache found for %s", util.ColorInfo(path))
+			return nil
+		}
+	}
+
+	dir := expandPath(path)
+	parent := filepath.Dir(dir)
+	err := os.MkdirAll(parent, util.DefaultWritePermissions)
+	if err != nil {
+		return errors.Wrapf(err, "creating directory %s", parent)
+	}
+	err = newTarGz().Unarchive(archive, parent)
+	if err != nil {
+		return errors.Wrapf(err, "extracting %s to %s", archive, parent)
+	}
+	log.Logger().Infof("restored %s from cache %s", util.ColorInfo(path), util.ColorInfo(o.Name))
+	return nil
+}
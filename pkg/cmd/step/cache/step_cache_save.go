@@ -0,0 +1,139 @@
+package cache
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/jenkins-x/jx/v2/pkg/cloud/buckets"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts/step"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/v2/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// StepCacheSaveOptions contains the command line flags
+type StepCacheSaveOptions struct {
+	StepCacheOptions
+}
+
+var (
+	stepCacheSaveLong = templates.LongDesc(`
+		Saves the cached directories of a pipeline so that later builds can restore them with 'jx step cache restore'.
+
+		The caches are stored in the storage location of the team for the 'cache' classifier or, if --dir is specified,
+		in a directory which is usually a mounted PersistentVolumeClaim. Directories which do not exist are skipped.
+
+		These steps are usually added to the pipeline by the 'caches' section of the jenkins-x.yml file.
+`)
+
+	stepCacheSaveExample = templates.Examples(`
+		# saves the local maven repository
+		jx step cache save --name maven --path ~/.m2/repository
+
+		# saves node_modules to a mounted volume
+		jx step cache save --name node --path node_modules --dir /jx-cache/node
+`)
+)
+
+// NewCmdStepCacheSave creates the command
+func NewCmdStepCacheSave(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &StepCacheSaveOptions{
+		StepCacheOptions: StepCacheOptions{
+			StepOptions: step.StepOptions{
+				CommonOptions: commonOpts,
+			},
+		},
+	}
+	cmd := &cobra.Command{
+		Use:     "save",
+		Short:   "Saves the cached directories of a pipeline",
+		Long:    stepCacheSaveLong,
+		Example: stepCacheSaveExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+	options.addFlags(cmd)
+	return cmd
+}
+
+// Run implements this command
+func (o *StepCacheSaveOptions) Run() error {
+	err := o.validate()
+	if err != nil {
+		return err
+	}
+	bucketURL := ""
+	if o.Dir == "" {
+		bucketURL, err = o.bucketURL()
+		if err != nil {
+			log.Logger().Warnf("not saving cache %s: %s", o.Name, err)
+			return nil
+		}
+	}
+	for _, path := range o.Paths {
+		err = o.savePath(path, bucketURL)
+		if err != nil {
+			return errors.Wrapf(err, "saving %s to cache %s", path, o.Name)
+		}
+	}
+	return nil
+}
+
+func (o *StepCacheSaveOptions) savePath(path string, bucketURL string) error {
+	dir := expandPath(path)
+	exists, err := util.DirExists(dir)
+	if err != nil {
+		return errors.Wrapf(err, "checking if %s exists", dir)
+	}
+	if !exists {
+		log.Logger().Infof("not caching %s as it does not exist", util.ColorInfo(path))
+		return nil
+	}
+
+	name := o.archiveName(path)
+	if o.Dir != "" {
+		archive := filepath.Join(o.Dir, name)
+		err = os.MkdirAll(filepath.Dir(archive), util.DefaultWritePermissions)
+		if err != nil {
+			return errors.Wrapf(err, "creating directory %s", filepath.Dir(archive))
+		}
+		err = newTarGz().Archive([]string{dir}, archive)
+		if err != nil {
+			return errors.Wrapf(err, "archiving %s to %s", dir, archive)
+		}
+		log.Logger().Infof("saved %s to cache %s", util.ColorInfo(path), util.ColorInfo(o.Name))
+		return nil
+	}
+
+	tmpDir, err := ioutil.TempDir("", "jx-cache-")
+	if err != nil {
+		return errors.Wrap(err, "creating temp dir")
+	}
+	defer os.RemoveAll(tmpDir) //nolint:errcheck
+	archive := filepath.Join(tmpDir, filepath.Base(name))
+	err = newTarGz().Archive([]string{dir}, archive)
+	if err != nil {
+		return errors.Wrapf(err, "archiving %s to %s", dir, archive)
+	}
+	data, err := ioutil.ReadFile(archive)
+	if err != nil {
+		return errors.Wrapf(err, "reading %s", archive)
+	}
+	err = buckets.WriteBucket(bucketURL, filepath.ToSlash(name), data, o.Timeout)
+	if err != nil {
+		// a build should not fail as its cache could not be saved
+		log.Logger().Warnf("failed to save %s to cache %s: %s", path, o.Name, err)
+		return nil
+	}
+	log.Logger().Infof("saved %s to cache %s", util.ColorInfo(path), util.ColorInfo(o.Name))
+	return nil
+}
@@ -0,0 +1,64 @@
+// +build unit
+
+package cache_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts/step"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/step/cache"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStepCacheSaveAndRestoreWithDir(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "test-step-cache-")
+	require.NoError(t, err)
+	defer os.RemoveAll(tmpDir) //nolint:errcheck
+
+	cacheDir := filepath.Join(tmpDir, "cache")
+	repository := filepath.Join(tmpDir, "home", ".m2", "repository")
+	fileName := filepath.Join(repository, "org", "cheese", "cheese.jar")
+	require.NoError(t, os.MkdirAll(filepath.Dir(fileName), util.DefaultWritePermissions))
+	require.NoError(t, ioutil.WriteFile(fileName, []byte("cheese"), util.DefaultWritePermissions))
+
+	cacheOptions := cache.StepCacheOptions{
+		StepOptions: step.StepOptions{
+			CommonOptions: &opts.CommonOptions{},
+		},
+		Name:  "maven",
+		Paths: []string{repository, filepath.Join(tmpDir, "does-not-exist")},
+		Dir:   cacheDir,
+		Owner: "jstrachan",
+		Repo:  "cheese",
+	}
+
+	save := &cache.StepCacheSaveOptions{StepCacheOptions: cacheOptions}
+	err = save.Run()
+	require.NoError(t, err)
+
+	require.NoError(t, os.RemoveAll(repository))
+
+	restore := &cache.StepCacheRestoreOptions{StepCacheOptions: cacheOptions}
+	err = restore.Run()
+	require.NoError(t, err)
+
+	data, err := ioutil.ReadFile(fileName)
+	require.NoError(t, err, "the cached file should have been restored")
+	assert.Equal(t, "cheese", string(data))
+
+	// a different key does not find the cache but is not an error
+	require.NoError(t, os.RemoveAll(repository))
+	restore = &cache.StepCacheRestoreOptions{StepCacheOptions: cacheOptions}
+	restore.Key = "other"
+	err = restore.Run()
+	require.NoError(t, err)
+	exists, err := util.FileExists(fileName)
+	require.NoError(t, err)
+	assert.False(t, exists, "the cache of another key should not be restored")
+}
@@ -0,0 +1,42 @@
+package change
+
+import (
+	"github.com/jenkins-x/jx/v2/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts/step"
+	"github.com/spf13/cobra"
+)
+
+// StepChangeOptions contains the command line flags
+type StepChangeOptions struct {
+	step.StepOptions
+}
+
+// NewCmdStepChange Steps a command object for the "step change" command
+func NewCmdStepChange(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &StepChangeOptions{
+		StepOptions: step.StepOptions{
+			CommonOptions: commonOpts,
+		},
+	}
+
+	cmd := &cobra.Command{
+		Use:   "change",
+		Short: "change [command]",
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+
+	cmd.AddCommand(NewCmdStepChangeRepos(commonOpts))
+
+	return cmd
+}
+
+// Run implements this command
+func (o *StepChangeOptions) Run() error {
+	return o.Cmd.Help()
+}
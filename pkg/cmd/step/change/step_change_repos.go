@@ -0,0 +1,190 @@
+package change
+
+import (
+	"time"
+
+	"github.com/jenkins-x/jx/v2/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts/step"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/v2/pkg/gitops/batch"
+	"github.com/jenkins-x/jx/v2/pkg/gits"
+	"github.com/jenkins-x/jx/v2/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+var (
+	stepChangeReposLong = templates.LongDesc(`
+		Applies a change to a number of git repositories, creating a Pull Request on each of them.
+
+		The command is run by the shell in a fresh clone of each repository and any changes it makes are committed. Running
+		the same change again updates the open Pull Requests which have the given labels rather than creating new ones.
+`)
+
+	stepChangeReposExample = templates.Examples(`
+		# update the go version used by a number of repositories
+		jx step change repos --repo https://github.com/myorg/foo.git --repo https://github.com/myorg/bar.git \
+			--title "chore: use go 1.13" --label go-upgrade \
+			--command "sed -i 's/golang:1.12/golang:1.13/' Dockerfile"
+
+		# see what the change would do without pushing it
+		jx step change repos --repo https://github.com/myorg/foo.git --title "chore: tidy" --command "go mod tidy" --dry-run
+`)
+)
+
+// StepChangeReposOptions contains the command line flags
+type StepChangeReposOptions struct {
+	step.StepOptions
+
+	GitURLs       []string
+	Command       string
+	Title         string
+	Message       string
+	Branch        string
+	Base          string
+	Labels        []string
+	DryRun        bool
+	Draft         bool
+	AutoMerge     bool
+	MergeQueue    bool
+	Reviewers     []string
+	TeamReviewers []string
+	Assignees     []string
+	Retries       int
+	RetryDelay    time.Duration
+
+	Results []*batch.Result
+}
+
+// NewCmdStepChangeRepos Creates a new Command object
+func NewCmdStepChangeRepos(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &StepChangeReposOptions{
+		StepOptions: step.StepOptions{
+			CommonOptions: commonOpts,
+		},
+	}
+
+	cmd := &cobra.Command{
+		Use:     "repos",
+		Short:   "Applies a change to a number of git repositories creating a Pull Request on each of them",
+		Long:    stepChangeReposLong,
+		Example: stepChangeReposExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+	cmd.Flags().StringArrayVarP(&options.GitURLs, "repo", "r", []string{}, "The git repositories to change")
+	cmd.Flags().StringVarP(&options.Command, "command", "c", "", "The shell command which changes each repository. It is run in the directory of the clone")
+	cmd.Flags().StringVarP(&options.Title, "title", "t", "", "The title of the Pull Requests")
+	cmd.Flags().StringVarP(&options.Message, "message", "m", "", "The message of the Pull Requests and their commits. Defaults to the title")
+	cmd.Flags().StringVarP(&options.Branch, "branch", "", "", "The branch the Pull Requests are created from. Defaults to a branch named after the title")
+	cmd.Flags().StringVarP(&options.Base, "base", "", "master", "The branch to create the Pull Requests into")
+	cmd.Flags().StringArrayVarP(&options.Labels, "label", "l", []string{}, "The labels to add to the Pull Requests. Open Pull Requests with all of the labels are updated instead of creating new ones")
+	cmd.Flags().BoolVarP(&options.DryRun, "dry-run", "", false, "Perform a dry run, the changes will be generated and committed, but not pushed or have PRs created")
+	cmd.Flags().BoolVarP(&options.Draft, "draft", "", false, "Creates the PRs as drafts if the git provider supports it")
+	cmd.Flags().BoolVarP(&options.AutoMerge, "auto-merge", "", false, "Merges the PRs once their checks pass")
+	cmd.Flags().BoolVarP(&options.MergeQueue, "merge-queue", "", false, "Adds the PRs to the merge queue of the git provider, falling back to --auto-merge if it has none")
+	cmd.Flags().StringArrayVarP(&options.Reviewers, "reviewer", "", []string{}, "The users to request reviews of the PRs from")
+	cmd.Flags().StringArrayVarP(&options.TeamReviewers, "team-reviewer", "", []string{}, "The teams to request reviews of the PRs from")
+	cmd.Flags().StringArrayVarP(&options.Assignees, "assignee", "", []string{}, "The users to assign the PRs to")
+	cmd.Flags().IntVarP(&options.Retries, "retries", "", 0, "How many times to retry a repository which could not be changed")
+	cmd.Flags().DurationVarP(&options.RetryDelay, "retry-delay", "", batch.DefaultRetryDelay, "How long to wait before retrying a repository")
+	return cmd
+}
+
+// Run implements this command
+func (o *StepChangeReposOptions) Run() error {
+	if len(o.GitURLs) == 0 {
+		return util.MissingOption("repo")
+	}
+	if o.Command == "" {
+		return util.MissingOption("command")
+	}
+	if o.Title == "" {
+		return util.MissingOption("title")
+	}
+	message := o.Message
+	if message == "" {
+		message = o.Title
+	}
+	branch := o.Branch
+	if branch == "" {
+		branch = o.Git().ConvertToValidBranchName("jx-change-" + o.Title)
+	}
+
+	b := &batch.Batch{
+		GitURLs:        o.GitURLs,
+		Base:           o.Base,
+		BranchName:     o.Base,
+		Labels:         o.Labels,
+		UpdateIfExists: true,
+		DryRun:         o.DryRun,
+		Draft:          o.Draft,
+		AutoMerge:      o.AutoMerge,
+		MergeQueue:     o.MergeQueue,
+		Reviewers:      o.Reviewers,
+		TeamReviewers:  o.TeamReviewers,
+		Assignees:      o.Assignees,
+		Retries:        o.Retries,
+		RetryDelay:     o.RetryDelay,
+		Provenance:     o.PullRequestProvenance("change"),
+		Gitter:         o.Git(),
+		CreateProvider: func(gitURL string) (gits.GitProvider, error) {
+			provider, _, err := o.CreateGitProviderForURLWithoutKind(gitURL)
+			return provider, err
+		},
+	}
+	var err error
+	o.Results, err = b.Apply(func(dir string, gitInfo *gits.GitRepository) (*batch.Change, error) {
+		log.Logger().Infof("Running %s in %s", util.ColorInfo(o.Command), util.ColorInfo(gitInfo.URL))
+		cmd := util.Command{
+			Dir:  dir,
+			Name: "sh",
+			Args: []string{"-c", o.Command},
+			Out:  o.Out,
+			Err:  o.Err,
+		}
+		_, err := cmd.RunWithoutRetry()
+		if err != nil {
+			return nil, errors.Wrapf(err, "running %s", o.Command)
+		}
+		return &batch.Change{
+			Details: &gits.PullRequestDetails{
+				BranchName: branch,
+				Title:      o.Title,
+				Message:    message,
+			},
+		}, nil
+	})
+	for _, result := range o.Results {
+		switch {
+		case result.Error != nil:
+			log.Logger().Infof("%s %s", util.ColorInfo(result.GitURL), util.ColorError("failed"))
+		case result.PullRequestInfo != nil && result.PullRequestInfo.PullRequest != nil:
+			log.Logger().Infof("%s %s", util.ColorInfo(result.GitURL), result.PullRequestInfo.PullRequest.URL)
+		case o.DryRun:
+			log.Logger().Infof("%s %s", util.ColorInfo(result.GitURL), "not pushed as this is a dry run")
+		default:
+			log.Logger().Infof("%s %s", util.ColorInfo(result.GitURL), "unchanged")
+		}
+	}
+	if err != nil {
+		return errors.Wrapf(err, "failed to change %d of the repositories", failed(o.Results))
+	}
+	return nil
+}
+
+func failed(results []*batch.Result) int {
+	answer := 0
+	for _, result := range results {
+		if result.Error != nil {
+			answer++
+		}
+	}
+	return answer
+}
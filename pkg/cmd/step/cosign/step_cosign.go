@@ -0,0 +1,41 @@
+package cosign
+
+import (
+	"github.com/jenkins-x/jx/v2/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts/step"
+	"github.com/spf13/cobra"
+)
+
+// StepCosignOptions contains the command line flags
+type StepCosignOptions struct {
+	step.StepOptions
+}
+
+// NewCmdStepCosign creates the command for the cosign steps which sign images and verify their signatures
+func NewCmdStepCosign(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &StepCosignOptions{
+		StepOptions: step.StepOptions{
+			CommonOptions: commonOpts,
+		},
+	}
+	cmd := &cobra.Command{
+		Use:   "cosign",
+		Short: "cosign [command]",
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+	cmd.AddCommand(NewCmdStepCosignPublishKey(commonOpts))
+	cmd.AddCommand(NewCmdStepCosignSign(commonOpts))
+	cmd.AddCommand(NewCmdStepCosignVerify(commonOpts))
+	return cmd
+}
+
+// Run implements this command
+func (o *StepCosignOptions) Run() error {
+	return o.Cmd.Help()
+}
@@ -0,0 +1,170 @@
+package cosign
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/jenkins-x/jx/v2/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts/step"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/v2/pkg/environments"
+	"github.com/jenkins-x/jx/v2/pkg/gits"
+	"github.com/jenkins-x/jx/v2/pkg/helm"
+	"github.com/jenkins-x/jx/v2/pkg/kube"
+	"github.com/jenkins-x/jx/v2/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/helm/pkg/proto/hapi/chart"
+)
+
+const (
+	// PublicKeyFile the file of the public key published to the dev environment repository
+	PublicKeyFile = "cosign.pub"
+
+	// secretKeyPublicKey the key of the public key in the secret cosign generates
+	secretKeyPublicKey = "cosign.pub"
+)
+
+// StepCosignPublishKeyOptions contains the command line flags
+type StepCosignPublishKeyOptions struct {
+	step.StepOptions
+
+	NoGenerate bool
+	AutoMerge  bool
+}
+
+var (
+	stepCosignPublishKeyLong = templates.LongDesc(`
+		This pipeline step publishes the public key images are signed with to the dev environment repository, via a Pull Request, so that anyone can verify the images.
+
+		The key pair is generated in the jx-cosign secret of the dev namespace if it does not exist
+`)
+
+	stepCosignPublishKeyExample = templates.Examples(`
+		# generate the key pair if needed and publish its public key
+		jx step cosign publish-key
+`)
+)
+
+// NewCmdStepCosignPublishKey creates the command
+func NewCmdStepCosignPublishKey(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := StepCosignPublishKeyOptions{
+		StepOptions: step.StepOptions{
+			CommonOptions: commonOpts,
+		},
+	}
+	cmd := &cobra.Command{
+		Use:     "publish-key",
+		Short:   "Publishes the public key images are signed with to the dev environment repository",
+		Long:    stepCosignPublishKeyLong,
+		Example: stepCosignPublishKeyExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+	cmd.Flags().BoolVarP(&options.NoGenerate, "no-generate", "", false, "Fails rather than generating the key pair if it does not exist")
+	cmd.Flags().BoolVarP(&options.AutoMerge, "auto-merge", "", false, "Makes the git provider merge the Pull Request once its checks pass")
+	return cmd
+}
+
+// Run implements the command
+func (o *StepCosignPublishKeyOptions) Run() error {
+	kubeClient, ns, err := o.KubeClientAndDevNamespace()
+	if err != nil {
+		return err
+	}
+	jxClient, _, err := o.JXClient()
+	if err != nil {
+		return err
+	}
+	secrets := kubeClient.CoreV1().Secrets(ns)
+	secret, err := secrets.Get(kube.SecretCosign, metav1.GetOptions{})
+	if err != nil {
+		if !k8serrors.IsNotFound(err) {
+			return errors.Wrapf(err, "getting secret %s in namespace %s", kube.SecretCosign, ns)
+		}
+		if o.NoGenerate {
+			return fmt.Errorf("there is no secret %s in namespace %s", kube.SecretCosign, ns)
+		}
+		err = o.generateKeyPair(ns)
+		if err != nil {
+			return err
+		}
+		secret, err = secrets.Get(kube.SecretCosign, metav1.GetOptions{})
+		if err != nil {
+			return errors.Wrapf(err, "getting secret %s in namespace %s", kube.SecretCosign, ns)
+		}
+	}
+	publicKey := secret.Data[secretKeyPublicKey]
+	if len(publicKey) == 0 {
+		return fmt.Errorf("secret %s in namespace %s has no key %s", kube.SecretCosign, ns, secretKeyPublicKey)
+	}
+
+	devEnv, err := kube.GetDevEnvironment(jxClient, ns)
+	if err != nil {
+		return errors.Wrapf(err, "getting the dev environment in namespace %s", ns)
+	}
+	if devEnv == nil || devEnv.Spec.Source.URL == "" {
+		return fmt.Errorf("the dev environment in namespace %s has no git repository to publish the public key to", ns)
+	}
+	gitProvider, _, err := o.CreateGitProviderForURLWithoutKind(devEnv.Spec.Source.URL)
+	if err != nil {
+		return errors.Wrapf(err, "creating git provider for %s", devEnv.Spec.Source.URL)
+	}
+	modifyChartFn := func(requirements *helm.Requirements, metadata *chart.Metadata, values map[string]interface{},
+		templates map[string]string, dir string, details *gits.PullRequestDetails) error {
+		return ioutil.WriteFile(filepath.Join(dir, PublicKeyFile), publicKey, util.DefaultFileWritePermissions)
+	}
+	details := gits.PullRequestDetails{
+		BranchName: "publish-cosign-key",
+		Title:      "Publish the public key images are signed with",
+		Message:    fmt.Sprintf("The images built by the release pipelines are signed with this key. Verify them via `cosign verify --key %s <image>`", PublicKeyFile),
+	}
+	options := environments.EnvironmentPullRequestOptions{
+		Gitter:        o.Git(),
+		ModifyChartFn: modifyChartFn,
+		GitProvider:   gitProvider,
+	}
+	info, err := options.Create(devEnv, "", &details, nil, "", o.AutoMerge)
+	if err != nil {
+		return errors.Wrap(err, "creating the Pull Request publishing the public key")
+	}
+	if info == nil || info.PullRequest == nil {
+		log.Logger().Infof("the public key is already published to %s", util.ColorInfo(devEnv.Spec.Source.URL))
+		return nil
+	}
+	log.Logger().Infof("created Pull Request %s publishing the public key", util.ColorInfo(info.PullRequest.URL))
+	return nil
+}
+
+// generateKeyPair generates the key pair in the cosign secret of the namespace, protected by a random password which
+// cosign stores in the secret
+func (o *StepCosignPublishKeyOptions) generateKeyPair(ns string) error {
+	err := o.InstallCosign()
+	if err != nil {
+		return errors.Wrap(err, "installing cosign")
+	}
+	password, err := util.RandStringBytesMaskImprSrc(32)
+	if err != nil {
+		return errors.Wrap(err, "generating the password of the key pair")
+	}
+	cmd := util.Command{
+		Name: "cosign",
+		Args: []string{"generate-key-pair", fmt.Sprintf("k8s://%s/%s", ns, kube.SecretCosign)},
+		Env:  map[string]string{"COSIGN_PASSWORD": password},
+	}
+	_, err = cmd.RunWithoutRetry()
+	if err != nil {
+		return errors.Wrapf(err, "generating the key pair in secret %s in namespace %s", kube.SecretCosign, ns)
+	}
+	log.Logger().Infof("generated the key pair in secret %s in namespace %s", util.ColorInfo(kube.SecretCosign), util.ColorInfo(ns))
+	return nil
+}
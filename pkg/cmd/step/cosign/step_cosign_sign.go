@@ -0,0 +1,109 @@
+package cosign
+
+import (
+	"github.com/jenkins-x/jx/v2/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts/step"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/v2/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// StepCosignSignOptions contains the command line flags
+type StepCosignSignOptions struct {
+	step.StepOptions
+
+	Image    string
+	Key      string
+	SBOM     string
+	SBOMType string
+}
+
+var (
+	stepCosignSignLong = templates.LongDesc(`
+		This pipeline step signs an image using cosign, attesting its SBOM if one is specified.
+
+		The key defaults to the key in the supply chain requirements, or else the key pair in the jx-cosign secret of the dev namespace
+`)
+
+	stepCosignSignExample = templates.Examples(`
+		# sign an image
+		jx step cosign sign --image gcr.io/myproject/myapp:1.2.3
+
+		# sign an image and attest the SBOM generated by 'jx step syft'
+		jx step cosign sign --image gcr.io/myproject/myapp:1.2.3 --sbom sbom.spdx.json
+`)
+)
+
+// NewCmdStepCosignSign creates the command
+func NewCmdStepCosignSign(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := StepCosignSignOptions{
+		StepOptions: step.StepOptions{
+			CommonOptions: commonOpts,
+		},
+	}
+	cmd := &cobra.Command{
+		Use:     "sign",
+		Short:   "Signs an image",
+		Long:    stepCosignSignLong,
+		Example: stepCosignSignExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+	cmd.Flags().StringVarP(&options.Image, "image", "i", "", "The image to sign")
+	cmd.Flags().StringVarP(&options.Key, "key", "k", "", "The cosign key to sign with such as k8s://jx/jx-cosign")
+	cmd.Flags().StringVarP(&options.SBOM, "sbom", "", "", "The file of the SBOM of the image to attest")
+	cmd.Flags().StringVarP(&options.SBOMType, "sbom-type", "", "spdx", "The type of the SBOM predicate")
+	return cmd
+}
+
+// Run implements the command
+func (o *StepCosignSignOptions) Run() error {
+	if o.Image == "" {
+		return util.MissingOption("image")
+	}
+	key, err := o.CosignKey(o.Key)
+	if err != nil {
+		return err
+	}
+	err = o.InstallCosign()
+	if err != nil {
+		return errors.Wrap(err, "installing cosign")
+	}
+	cmd := util.Command{
+		Name: "cosign",
+		Args: []string{"sign", "--key", key, o.Image},
+	}
+	_, err = cmd.RunWithoutRetry()
+	if err != nil {
+		return errors.Wrapf(err, "signing image %s", o.Image)
+	}
+	log.Logger().Infof("signed image %s with key %s", util.ColorInfo(o.Image), util.ColorInfo(key))
+
+	if o.SBOM == "" {
+		return nil
+	}
+	exists, err := util.FileExists(o.SBOM)
+	if err != nil {
+		return errors.Wrapf(err, "checking if %s exists", o.SBOM)
+	}
+	if !exists {
+		return util.InvalidOptionf("sbom", o.SBOM, "the SBOM file does not exist")
+	}
+	cmd = util.Command{
+		Name: "cosign",
+		Args: []string{"attest", "--key", key, "--predicate", o.SBOM, "--type", o.SBOMType, o.Image},
+	}
+	_, err = cmd.RunWithoutRetry()
+	if err != nil {
+		return errors.Wrapf(err, "attesting the SBOM %s of image %s", o.SBOM, o.Image)
+	}
+	log.Logger().Infof("attested the SBOM %s of image %s", util.ColorInfo(o.SBOM), util.ColorInfo(o.Image))
+	return nil
+}
@@ -0,0 +1,76 @@
+package cosign
+
+import (
+	"github.com/jenkins-x/jx/v2/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts/step"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/spf13/cobra"
+)
+
+// StepCosignVerifyOptions contains the command line flags
+type StepCosignVerifyOptions struct {
+	step.StepOptions
+
+	Images []string
+	Key    string
+}
+
+var (
+	stepCosignVerifyLong = templates.LongDesc(`
+		This pipeline step verifies the signatures of images using cosign.
+
+		The key defaults to the key in the supply chain requirements, or else the key pair in the jx-cosign secret of the dev namespace
+`)
+
+	stepCosignVerifyExample = templates.Examples(`
+		# verify the signature of an image
+		jx step cosign verify --image gcr.io/myproject/myapp:1.2.3
+
+		# verify the signature of an image against the public key published to the dev environment repository
+		jx step cosign verify --image gcr.io/myproject/myapp:1.2.3 --key cosign.pub
+`)
+)
+
+// NewCmdStepCosignVerify creates the command
+func NewCmdStepCosignVerify(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := StepCosignVerifyOptions{
+		StepOptions: step.StepOptions{
+			CommonOptions: commonOpts,
+		},
+	}
+	cmd := &cobra.Command{
+		Use:     "verify",
+		Short:   "Verifies the signatures of images",
+		Long:    stepCosignVerifyLong,
+		Example: stepCosignVerifyExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+	cmd.Flags().StringArrayVarP(&options.Images, "image", "i", nil, "The images to verify")
+	cmd.Flags().StringVarP(&options.Key, "key", "k", "", "The cosign key to verify against such as k8s://jx/jx-cosign or the file of a public key")
+	return cmd
+}
+
+// Run implements the command
+func (o *StepCosignVerifyOptions) Run() error {
+	if len(o.Images) == 0 {
+		return util.MissingOption("image")
+	}
+	key, err := o.CosignKey(o.Key)
+	if err != nil {
+		return err
+	}
+	for _, image := range o.Images {
+		err = o.VerifyImageSignature(image, key)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -94,10 +94,16 @@ func (o *CreateHelmfileOptions) Run() error {
 		return errors.Wrap(err, "failed listing helm repos")
 	}
 
+	// install the apps after the apps they require
+	ordered, err := apps.InstallOrder()
+	if err != nil {
+		return errors.Wrap(err, "failed to order applications")
+	}
+
 	// iterate over all apps and split them into phases to generate separate helmfiles for each
 	var applications []config.Application
 	var systemApplications []config.Application
-	for _, app := range apps.Applications {
+	for _, app := range ordered {
 		// default phase is apps so set it in if empty
 		if app.Phase == "" || app.Phase == config.PhaseApps {
 			applications = append(applications, app)
@@ -172,6 +178,7 @@ func (o *CreateHelmfileOptions) generateHelmFile(applications []config.Applicati
 			Namespace: app.Namespace,
 			Chart:     chartName,
 			Values:    extraValuesFiles,
+			Needs:     o.releaseNeeds(app, applications, apps.DefaultNamespace),
 		}
 		releases = append(releases, release)
 	}
@@ -208,6 +215,23 @@ func (o *CreateHelmfileOptions) generateHelmFile(applications []config.Applicati
 	return nil
 }
 
+// releaseNeeds returns the releases in the same helmfile which the release of the application needs
+func (o *CreateHelmfileOptions) releaseNeeds(app config.Application, applications []config.Application, defaultNamespace string) []string {
+	var needs []string
+	for _, name := range app.Requires {
+		for _, required := range applications {
+			if required.Name == name {
+				ns := required.Namespace
+				if ns == "" {
+					ns = defaultNamespace
+				}
+				needs = append(needs, ns+"/"+name)
+			}
+		}
+	}
+	return needs
+}
+
 func (o *CreateHelmfileOptions) writeHelmfile(err error, phase string, data []byte) error {
 	exists, err := util.DirExists(path.Join(o.outputDir, phase))
 	if err != nil || !exists {
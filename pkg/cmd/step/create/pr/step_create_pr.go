@@ -32,6 +32,10 @@ type StepCreatePrOptions struct {
 	SkipCommit    bool
 	SkipAutoMerge bool
 	Labels        []string
+	Draft         bool
+	Reviewers     []string
+	TeamReviewers []string
+	Assignees     []string
 }
 
 // NewCmdStepCreatePr Steps a command object for the "step" command
@@ -83,6 +87,10 @@ func AddStepCreatePrFlags(cmd *cobra.Command, o *StepCreatePrOptions) {
 	cmd.Flags().BoolVarP(&o.DryRun, "dry-run", "", false, "Perform a dry run, the change will be generated and committed, but not pushed or have a PR created")
 	cmd.Flags().BoolVarP(&o.SkipAutoMerge, "skip-auto-merge", "", false, "Disable auto merge of the PR if status checks pass")
 	cmd.Flags().StringArrayVarP(&o.Labels, "labels", "", []string{}, "Labels to add to the created PR")
+	cmd.Flags().BoolVarP(&o.Draft, "draft", "", false, "Creates the PR as a draft if the git provider supports it")
+	cmd.Flags().StringArrayVarP(&o.Reviewers, "reviewer", "", []string{}, "The users to request reviews of the created PR from")
+	cmd.Flags().StringArrayVarP(&o.TeamReviewers, "team-reviewer", "", []string{}, "The teams to request reviews of the created PR from")
+	cmd.Flags().StringArrayVarP(&o.Assignees, "assignee", "", []string{}, "The users to assign the created PR to")
 }
 
 // ValidateOptions validates the common options for all PR creation steps
@@ -125,8 +133,19 @@ func (o *StepCreatePrOptions) CreatePullRequest(kind string, update operations.C
 	if o.DryRun {
 		log.Logger().Infof("--dry-run specified. Change will be created and committed to local git repo, but not pushed. No pull request will be created or updated. A fork will still be created.")
 	}
+	err := o.ConfigureCommitSigningFromTeamSettings()
+	if err != nil {
+		return errors.Wrap(err, "configuring commit signing")
+	}
+	o.ConfigureGitMirrorsFromTeamSettings()
 	op := o.createPullRequestOperation()
-	var err error
+	if defaults := o.PullRequestsConfigFromTeamSettings(); defaults != nil {
+		op.Reviewers = append(op.Reviewers, defaults.Reviewers...)
+		op.TeamReviewers = append(op.TeamReviewers, defaults.TeamReviewers...)
+		op.Assignees = append(op.Assignees, defaults.Assignees...)
+		op.NamingConventions = defaults
+	}
+	op.Provenance = o.PullRequestProvenance(kind)
 	o.Results, err = op.CreatePullRequest(kind, update)
 	if err != nil {
 		return errors.Wrap(err, "unable to create pull request")
@@ -147,6 +166,10 @@ func (o *StepCreatePrOptions) createPullRequestOperation() operations.PullReques
 		SkipCommit:    o.SkipCommit,
 		SkipAutoMerge: o.SkipAutoMerge,
 		Labels:        o.Labels,
+		Draft:         o.Draft,
+		Reviewers:     o.Reviewers,
+		TeamReviewers: o.TeamReviewers,
+		Assignees:     o.Assignees,
 	}
 	authorName, authorEmail, err := gits.EnsureUserAndEmailSetup(o.Git())
 	if err != nil {
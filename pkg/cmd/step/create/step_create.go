@@ -28,6 +28,7 @@ func NewCmdStepCreate(commonOpts *opts.CommonOptions) *cobra.Command {
 		},
 	}
 	cmd.AddCommand(NewCmdStepCreateDevPodWorkpace(commonOpts))
+	cmd.AddCommand(NewCmdStepCreateExternalSecrets(commonOpts))
 	cmd.AddCommand(helmfile.NewCmdCreateHelmfile(commonOpts))
 	cmd.AddCommand(NewCmdStepCreateTask(commonOpts))
 	cmd.AddCommand(NewCmdStepCreateInstallValues(commonOpts))
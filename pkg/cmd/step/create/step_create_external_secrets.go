@@ -0,0 +1,100 @@
+package create
+
+import (
+	"os"
+
+	"github.com/jenkins-x/jx/v2/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts/step"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/v2/pkg/config"
+	"github.com/jenkins-x/jx/v2/pkg/externalsecrets"
+	"github.com/jenkins-x/jx/v2/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/spf13/cobra"
+)
+
+var (
+	createExternalSecretsLong = templates.LongDesc(`
+		Creates the ExternalSecret resources for the secrets referenced by the helm charts in a directory along with a
+		JSON schema of the secrets which have to be stored in the cloud secret manager.
+
+		The secrets are referenced in the charts using external-secrets:path:key URLs which are generated by 'jx step create values'
+		when the secretStorage of the requirements is external-secrets.
+`)
+
+	createExternalSecretsExample = templates.Examples(`
+		# create the ExternalSecret resources and schema for the charts in the current directory
+		jx step create external-secrets
+
+		# create them for the charts in the env directory into the output directory
+		jx step create external-secrets -d env --output-dir output
+			`)
+)
+
+// StepCreateExternalSecretsOptions contains the command line flags
+type StepCreateExternalSecretsOptions struct {
+	step.StepCreateOptions
+
+	Dir       string
+	OutputDir string
+	Namespace string
+}
+
+// NewCmdStepCreateExternalSecrets Creates a new Command object
+func NewCmdStepCreateExternalSecrets(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &StepCreateExternalSecretsOptions{
+		StepCreateOptions: step.StepCreateOptions{
+			StepOptions: step.StepOptions{
+				CommonOptions: commonOpts,
+			},
+		},
+	}
+
+	cmd := &cobra.Command{
+		Use:     "external-secrets",
+		Short:   "Creates the ExternalSecret resources and secrets schema for the helm charts in a directory",
+		Long:    createExternalSecretsLong,
+		Example: createExternalSecretsExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+
+	cmd.Flags().StringVarP(&options.Dir, "dir", "d", "", "the directory of the helm charts, defaults to the current directory")
+	cmd.Flags().StringVarP(&options.OutputDir, "output-dir", "", "", "the directory to write the resources and schema to, defaults to the directory of the helm charts")
+	cmd.Flags().StringVarP(&options.Namespace, "namespace", "", "", "the namespace Jenkins X is installed into. If not specified it defaults to $DEPLOY_NAMESPACE or else defaults to the current kubernetes namespace")
+	return cmd
+}
+
+// Run implements this command
+func (o *StepCreateExternalSecretsOptions) Run() error {
+	ns, err := o.GetDeployNamespace(o.Namespace)
+	if err != nil {
+		return err
+	}
+	if o.Dir == "" {
+		o.Dir, err = os.Getwd()
+		if err != nil {
+			return err
+		}
+	}
+	if o.OutputDir == "" {
+		o.OutputDir = o.Dir
+	}
+	requirements, _, err := config.LoadRequirementsConfigWithOptions(o.Dir, config.DefaultFailOnValidationError, o.RequirementsOptions())
+	if err != nil {
+		return err
+	}
+	files, err := externalsecrets.WriteResources(o.Dir, o.OutputDir, ns, requirements)
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		log.Logger().Infof("Created %s", util.ColorInfo(file))
+	}
+	return nil
+}
@@ -21,6 +21,7 @@ import (
 
 	"github.com/ghodss/yaml"
 	jxclient "github.com/jenkins-x/jx/v2/pkg/client/clientset/versioned"
+	"github.com/jenkins-x/jx/v2/pkg/cloud/identity"
 	"github.com/jenkins-x/jx/v2/pkg/cmd/helper"
 	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
 	syntaxstep "github.com/jenkins-x/jx/v2/pkg/cmd/step/syntax"
@@ -34,6 +35,7 @@ import (
 	"github.com/jenkins-x/jx/v2/pkg/tekton"
 	"github.com/jenkins-x/jx/v2/pkg/tekton/syntax"
 	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/jenkins-x/jx/v2/pkg/vault"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 	pipelineapi "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
@@ -110,9 +112,11 @@ type StepCreateTaskOptions struct {
 	NoKaniko            bool
 	SemanticRelease     bool
 	KanikoImage         string
+	Platforms           []string
 	KanikoSecretMount   string
 	KanikoSecret        string
 	KanikoSecretKey     string
+	WorkloadIdentity    bool
 	ProjectID           string
 	DockerRegistry      string
 	DockerRegistryOrg   string
@@ -172,7 +176,7 @@ func NewCmdStepCreateTaskAndOption(commonOpts *opts.CommonOptions) (*cobra.Comma
 	cmd.Flags().BoolVarP(&createTaskNoApply, noApplyOptionName, "", false, "Disables creating the Pipeline resources in the kubernetes cluster and just outputs the generated Task to the console or output file")
 	cmd.Flags().BoolVarP(&options.DryRun, "dry-run", "", false, "Disables creating the Pipeline resources in the kubernetes cluster and just outputs the generated Task to the console or output file, without side effects")
 	cmd.Flags().BoolVarP(&options.InterpretMode, "interpret", "", false, "Enable interpret mode. Rather than spinning up Tekton CRDs to create a Pod just invoke the commands in the current shell directly. Useful for bootstrapping installations of Jenkins X and tekton using a pipeline before you have installed Tekton.")
-	cmd.Flags().StringVarP(&options.StartStep, "start-step", "", "", "When in interpret mode this specifies the step to start at")
+	cmd.Flags().StringVarP(&options.StartStep, "start-step", "", "", "The step to start at, the steps before it are skipped")
 	cmd.Flags().StringVarP(&options.EndStep, "end-step", "", "", "When in interpret mode this specifies the step to end at")
 	cmd.Flags().BoolVarP(&options.ViewSteps, "view", "", false, "Just view the steps that would be created")
 	cmd.Flags().BoolVarP(&options.EffectivePipeline, "effective-pipeline", "", false, "Just view the effective pipeline definition that would be created")
@@ -210,9 +214,11 @@ func (o *StepCreateTaskOptions) AddCommonFlags(cmd *cobra.Command) {
 	cmd.Flags().BoolVarP(&o.NoReleasePrepare, "no-release-prepare", "", false, "Disables creating the release version number and tagging git and triggering the release pipeline from the new tag")
 	cmd.Flags().BoolVarP(&o.NoKaniko, "no-kaniko", "", false, "Disables using kaniko directly for building docker images")
 	cmd.Flags().StringVarP(&o.KanikoImage, "kaniko-image", "", syntax.KanikoDockerImage, "The docker image for Kaniko")
+	cmd.Flags().StringArrayVarP(&o.Platforms, "platform", "", nil, "The platforms, such as linux/arm64, kaniko builds images for. Multiple platforms build a multi-arch image. Defaults to the buildPlatforms in the requirements")
 	cmd.Flags().StringVarP(&o.KanikoSecretMount, "kaniko-secret-mount", "", kanikoSecretMount, "The mount point of the Kaniko secret")
 	cmd.Flags().StringVarP(&o.KanikoSecret, "kaniko-secret", "", kanikoSecretName, "The name of the kaniko secret")
 	cmd.Flags().StringVarP(&o.KanikoSecretKey, "kaniko-secret-key", "", kanikoSecretKey, "The key in the Kaniko Secret to mount")
+	cmd.Flags().BoolVarP(&o.WorkloadIdentity, "workload-identity", "", false, "Kaniko uses the workload identity of the service account rather than mounting the Kaniko secret. Defaults to the workloadIdentity setting in the requirements")
 	cmd.Flags().StringVarP(&o.ProjectID, "project-id", "", "", "The cloud project ID. If not specified we default to the install project")
 	cmd.Flags().StringVarP(&o.DockerRegistry, "docker-registry", "", "", "The Docker Registry host name to use which is added as a prefix to docker images")
 	cmd.Flags().StringVarP(&o.DockerRegistryOrg, "docker-registry-org", "", "", "The Docker registry organisation. If blank the git repository owner is used")
@@ -495,6 +501,9 @@ func (o *StepCreateTaskOptions) createEffectiveProjectConfigFromOptions(tektonCl
 	if o.KanikoSecretMount == "" {
 		o.KanikoSecretMount = kanikoSecretMount
 	}
+	if !o.WorkloadIdentity {
+		o.WorkloadIdentity = o.workloadIdentityEnabled()
+	}
 
 	if o.DockerRegistry == "" && !o.InterpretMode {
 		data, err := kube.GetConfigMapData(kubeClient, kube.ConfigMapJenkinsDockerRegistry, ns)
@@ -576,6 +585,7 @@ func (o *StepCreateTaskOptions) createEffectiveProjectConfigFromOptions(tektonCl
 
 // createEffectiveProjectConfig creates the effective parsed pipeline which is then used to generate the Tekton CRDs.
 func (o *StepCreateTaskOptions) createEffectiveProjectConfig(packsDir string, projectConfig *config.ProjectConfig, projectConfigFile string, resolver jenkinsfile.ImportFileResolver, ns string) (*config.ProjectConfig, error) {
+	supplyChain := o.SupplyChainConfigFromTeamSettings()
 	createEffective := &syntaxstep.StepSyntaxEffectiveOptions{
 		Pack:              o.Pack,
 		BuildPackURL:      o.BuildPackURL,
@@ -585,6 +595,9 @@ func (o *StepCreateTaskOptions) createEffectiveProjectConfig(packsDir string, pr
 		DefaultImage:      o.DefaultImage,
 		UseKaniko:         !o.NoKaniko,
 		KanikoImage:       o.KanikoImage,
+		Platforms:         o.buildPlatforms(),
+		GenerateSBOM:      supplyChain.SBOM,
+		SignImages:        supplyChain.Sign,
 		ProjectID:         o.ProjectID,
 		DockerRegistry:    o.DockerRegistry,
 		DockerRegistryOrg: o.DockerRegistryOrg,
@@ -649,12 +662,21 @@ func (o *StepCreateTaskOptions) generateTektonCRDs(effectiveProjectConfig *confi
 		InterpretMode:      o.InterpretMode,
 	}
 
+	effectivePipeline.ApplyConditions(o.conditionContext(effectiveProjectConfig.PipelineConfig.Env))
+	o.applyPipelinePodDefaults(effectivePipeline)
+
 	pipeline, tasks, structure, err := effectivePipeline.GenerateCRDs(crdParams)
 	if err != nil {
 		return nil, errors.Wrapf(err, "generation failed for Pipeline")
 	}
 
 	tasks, pipeline = o.enhanceTasksAndPipeline(tasks, pipeline, effectiveProjectConfig.PipelineConfig.Env)
+	if o.StartStep != "" && !o.InterpretMode {
+		err = skipStepsBefore(tasks, o.StartStep)
+		if err != nil {
+			return nil, err
+		}
+	}
 	resources := []*pipelineapi.PipelineResource{tekton.GenerateSourceRepoResource(pipelineName, o.GitInfo, o.Revision)}
 
 	var timeout *metav1.Duration
@@ -666,6 +688,11 @@ func (o *StepCreateTaskOptions) generateTektonCRDs(effectiveProjectConfig *confi
 	}
 	prLabels := util.MergeMaps(o.labels, effectivePipeline.GetPodLabels())
 	run := tekton.CreatePipelineRun(resources, pipeline.Name, pipeline.APIVersion, prLabels, o.ServiceAccount, o.pipelineParams, timeout, effectivePipeline.GetPossibleAffinityPolicy(pipeline.Name), effectivePipeline.GetTolerations())
+	run.Spec.PodTemplate.NodeSelector = effectivePipeline.GetNodeSelector()
+	run.Spec.PodTemplate.SecurityContext = effectivePipeline.GetSecurityContext()
+	if annotations := o.vaultAgentAnnotations(); len(annotations) > 0 {
+		run.Annotations = util.MergeMaps(run.Annotations, annotations)
+	}
 
 	tektonCRDs, err := tekton.NewCRDWrapper(pipeline, tasks, resources, structure, run)
 	if err != nil {
@@ -675,6 +702,104 @@ func (o *StepCreateTaskOptions) generateTektonCRDs(effectiveProjectConfig *confi
 	return tektonCRDs, nil
 }
 
+// vaultAgentAnnotations returns the annotations which make the Vault Agent inject the pipeline secrets into the pods
+// of the pipeline, which Tekton propagates from the PipelineRun
+func (o *StepCreateTaskOptions) vaultAgentAnnotations() map[string]string {
+	settings, err := o.TeamSettings()
+	if err != nil {
+		log.Logger().Debugf("failed to load the team settings: %s", err)
+		return nil
+	}
+	requirements, err := config.GetRequirementsConfigFromTeamSettings(settings)
+	if err != nil {
+		log.Logger().Warnf("failed to load the requirements from the team settings: %s", err)
+		return nil
+	}
+	if !vault.AgentEnabled(requirements) {
+		return nil
+	}
+	agentSecrets := []vault.AgentSecret{}
+	for _, ref := range requirements.Vault.Agent.PipelineSecrets {
+		secret, err := vault.ParseAgentSecret(ref)
+		if err != nil {
+			log.Logger().Warnf("ignoring the pipeline secret %s: %s", ref, err)
+			continue
+		}
+		agentSecrets = append(agentSecrets, secret)
+	}
+	return vault.AgentAnnotations(agentSecrets, requirements, true)
+}
+
+// applyPipelinePodDefaults applies the default resources, node selector, tolerations and security context of the
+// pipeline pods of the team to the pipeline, which its own options override
+func (o *StepCreateTaskOptions) applyPipelinePodDefaults(effectivePipeline *syntax.ParsedPipeline) {
+	settings, err := o.TeamSettings()
+	if err != nil {
+		log.Logger().Debugf("failed to load the team settings: %s", err)
+		return
+	}
+	requirements, err := config.GetRequirementsConfigFromTeamSettings(settings)
+	if err != nil {
+		log.Logger().Warnf("failed to load the requirements from the team settings: %s", err)
+		return
+	}
+	if requirements == nil || requirements.PipelinePods == nil {
+		return
+	}
+	pods := requirements.PipelinePods
+	resources, err := pods.Resources()
+	if err != nil {
+		log.Logger().Warnf("ignoring the default resources of the pipeline pods: %s", err)
+		resources = corev1.ResourceRequirements{}
+	}
+	effectivePipeline.ApplyPodDefaults(resources, pods.NodeSelector, pods.Tolerations, pods.SecurityContext)
+}
+
+// buildPlatforms returns the platforms kaniko builds images for, which default to the build platforms of the team
+func (o *StepCreateTaskOptions) buildPlatforms() []string {
+	platforms := o.Platforms
+	if len(platforms) == 0 {
+		settings, err := o.TeamSettings()
+		if err != nil {
+			log.Logger().Debugf("failed to load the team settings: %s", err)
+			return nil
+		}
+		requirements, err := config.GetRequirementsConfigFromTeamSettings(settings)
+		if err != nil {
+			log.Logger().Warnf("failed to load the requirements from the team settings: %s", err)
+			return nil
+		}
+		if requirements != nil {
+			platforms = requirements.BuildPlatforms
+		}
+	}
+	var answer []string
+	for _, platform := range platforms {
+		if err := syntax.ValidatePlatform(platform); err != nil {
+			log.Logger().Warnf("ignoring build platform: %s", err)
+			continue
+		}
+		answer = append(answer, platform)
+	}
+	return answer
+}
+
+// workloadIdentityEnabled returns true if the pipelines use the workload identity of their service account to access
+// the cloud provider rather than static keys stored in secrets
+func (o *StepCreateTaskOptions) workloadIdentityEnabled() bool {
+	settings, err := o.TeamSettings()
+	if err != nil {
+		log.Logger().Debugf("failed to load the team settings: %s", err)
+		return false
+	}
+	requirements, err := config.GetRequirementsConfigFromTeamSettings(settings)
+	if err != nil {
+		log.Logger().Warnf("failed to load the requirements from the team settings: %s", err)
+		return false
+	}
+	return identity.Enabled(requirements)
+}
+
 func (o *StepCreateTaskOptions) loadProjectConfig() (*config.ProjectConfig, string, error) {
 	if o.Context != "" {
 		fileName := filepath.Join(o.CloneDir, fmt.Sprintf("jenkins-x-%s.yml", o.Context))
@@ -1014,7 +1139,7 @@ func (o *StepCreateTaskOptions) modifyEnvVars(container *corev1.Container, globa
 		}
 	}
 
-	if isKanikoExecutorStep(container) && !o.NoKaniko {
+	if usesKanikoCredentials(container) && !o.NoKaniko && !o.WorkloadIdentity {
 		if kube.GetSliceEnvVar(envVars, "GOOGLE_APPLICATION_CREDENTIALS") == nil {
 			envVars = append(envVars, corev1.EnvVar{
 				Name:  "GOOGLE_APPLICATION_CREDENTIALS",
@@ -1042,7 +1167,8 @@ func (o *StepCreateTaskOptions) modifyEnvVars(container *corev1.Container, globa
 func (o *StepCreateTaskOptions) modifyVolumes(container *corev1.Container, volumes []corev1.Volume) []corev1.Volume {
 	answer := volumes
 
-	if isKanikoExecutorStep(container) && !o.NoKaniko {
+	// with workload identity kaniko uses the ambient credentials of the service account
+	if usesKanikoCredentials(container) && !o.NoKaniko && !o.WorkloadIdentity {
 		kubeClient, ns, err := o.KubeClientAndDevNamespace()
 		if err != nil {
 			log.Logger().Warnf("failed to find kaniko secret: %s", err)
@@ -1189,6 +1315,55 @@ func (o *StepCreateTaskOptions) cloneGitRepositoryToTempDir(gitURL string, branc
 	return tmpDir
 }
 
+// conditionContext returns what the conditions of the stages and steps of the pipeline are evaluated against
+func (o *StepCreateTaskOptions) conditionContext(env []corev1.EnvVar) syntax.ConditionContext {
+	ctx := syntax.ConditionContext{
+		Branch: o.Branch,
+		Env:    map[string]string{},
+	}
+	for _, e := range env {
+		ctx.Env[e.Name] = e.Value
+	}
+	customEnvs, err := util.ExtractKeyValuePairs(o.CustomEnvs, "=")
+	if err != nil {
+		log.Logger().Warnf("failed to parse the custom environment variables: %s", err)
+	}
+	ctx.Env = util.MergeMaps(ctx.Env, customEnvs)
+
+	ctx.ChangedFiles, err = o.changedFiles()
+	if err != nil {
+		// without the changed files every change condition matches so that nothing is skipped by mistake
+		log.Logger().Warnf("failed to find the changed files in %s, not skipping any stages or steps on changes: %s", o.CloneDir, err)
+		ctx.ChangedFiles = nil
+	}
+	return ctx
+}
+
+// changedFiles returns the files changed by a pull request or, for any other build, by the last commit
+func (o *StepCreateTaskOptions) changedFiles() ([]string, error) {
+	base := "HEAD~1"
+	pr, err := o.parsePullRefs()
+	if err != nil {
+		return nil, err
+	}
+	if pr != nil && len(pr.ToMerge) > 0 && pr.BaseSha != "" {
+		base = pr.BaseSha
+	}
+	output, err := o.Git().ListChangedFilesFromBranch(o.CloneDir, base)
+	if err != nil {
+		return nil, err
+	}
+	answer := []string{}
+	for _, line := range strings.Split(output, "\n") {
+		// each line is the status followed by the path, or by both paths of a renamed or copied file
+		fields := strings.Split(strings.TrimSpace(line), "\t")
+		if len(fields) > 1 {
+			answer = append(answer, fields[1:]...)
+		}
+	}
+	return answer, nil
+}
+
 // parsePullRefs creates a Prow PullRefs struct from the PULL_REFS environment variable, if it id set.
 func (o *StepCreateTaskOptions) parsePullRefs() (*tekton.PullRefs, error) {
 	var pr *tekton.PullRefs
@@ -1459,6 +1634,30 @@ func (o *StepCreateTaskOptions) getClientsAndNamespace() (tektonclient.Interface
 	return tektonClient, jxClient, kubeClient, ns, nil
 }
 
+// skipStepsBefore replaces the steps which run before the start step with steps which only log that they were skipped
+// so that a pipeline can be restarted from a step. The git-merge steps still run so that each task builds the same
+// source as the original build
+func skipStepsBefore(tasks []*pipelineapi.Task, startStep string) error {
+	names := []string{}
+	for _, task := range tasks {
+		for i := range task.Spec.Steps {
+			step := &task.Spec.Steps[i]
+			if step.Name == startStep {
+				return nil
+			}
+			names = append(names, step.Name)
+			if step.Name == "git-merge" {
+				continue
+			}
+			msg := fmt.Sprintf("SKIP %s: restarting the pipeline from step %s", step.Name, startStep)
+			step.Image = syntax.DefaultContainerImage
+			step.Command = []string{"echo"}
+			step.Args = []string{msg}
+		}
+	}
+	return util.InvalidOption("start-step", startStep, names)
+}
+
 func (o *StepCreateTaskOptions) interpretPipeline(ns string, projectConfig *config.ProjectConfig, crds *tekton.CRDWrapper) error {
 	steps := []corev1.Container{}
 	for _, task := range crds.Tasks() {
@@ -1596,3 +1795,19 @@ func isKanikoExecutorStep(container *corev1.Container) bool {
 	return strings.HasPrefix(strings.Join(container.Command, " "), "/kaniko/executor") ||
 		(len(container.Args) > 0 && strings.HasPrefix(strings.Join(container.Args, " "), "/kaniko/executor"))
 }
+
+// usesKanikoCredentials returns true if the container pushes images with the credentials of kaniko, which are also
+// used to combine the images built for each platform into a multi-arch image and to generate the SBOMs of images and
+// sign them
+func usesKanikoCredentials(container *corev1.Container) bool {
+	if isKanikoExecutorStep(container) {
+		return true
+	}
+	for _, command := range []string{syntax.ImageIndexCommand, syntax.SBOMCommand, syntax.SignImageCommand} {
+		if strings.HasPrefix(strings.Join(container.Command, " "), command) ||
+			(len(container.Args) > 0 && strings.HasPrefix(strings.Join(container.Args, " "), command)) {
+			return true
+		}
+	}
+	return false
+}
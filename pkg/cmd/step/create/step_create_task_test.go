@@ -644,3 +644,35 @@ func assertLoadPodTemplates(t *testing.T) map[string]*corev1.Pod {
 	}
 	return nil
 }
+
+func TestSkipStepsBefore(t *testing.T) {
+	t.Parallel()
+
+	newTask := func(names ...string) *pipelineapi.Task {
+		task := &pipelineapi.Task{}
+		for _, name := range names {
+			task.Spec.Steps = append(task.Spec.Steps, pipelineapi.Step{Container: corev1.Container{
+				Name:    name,
+				Image:   "maven",
+				Command: []string{"/bin/sh", "-c"},
+				Args:    []string{name},
+			}})
+		}
+		return task
+	}
+	tasks := []*pipelineapi.Task{newTask("git-merge", "build", "test"), newTask("git-merge", "deploy", "promote")}
+
+	err := skipStepsBefore(tasks, "deploy")
+	assert.NoError(t, err)
+
+	for _, step := range tasks[0].Spec.Steps[1:] {
+		assert.Equal(t, []string{"echo"}, step.Command, "step %s should be skipped", step.Name)
+	}
+	assert.Equal(t, []string{"/bin/sh", "-c"}, tasks[0].Spec.Steps[0].Command, "the git-merge step should still run")
+	assert.Equal(t, []string{"/bin/sh", "-c"}, tasks[1].Spec.Steps[0].Command, "the git-merge step should still run")
+	assert.Equal(t, []string{"deploy"}, tasks[1].Spec.Steps[1].Args)
+	assert.Equal(t, []string{"promote"}, tasks[1].Spec.Steps[2].Args)
+
+	err = skipStepsBefore([]*pipelineapi.Task{newTask("build")}, "missing")
+	assert.Error(t, err)
+}
@@ -106,7 +106,7 @@ func (o *StepCreateTemplatedConfigOptions) Run() error {
 	if err := o.checkFlags(); err != nil {
 		return err
 	}
-	requirements, _, err := config.LoadRequirementsConfig(o.RequirementsDir, config.DefaultFailOnValidationError)
+	requirements, _, err := config.LoadRequirementsConfigWithOptions(o.RequirementsDir, config.DefaultFailOnValidationError, o.RequirementsOptions())
 	if err != nil {
 		return errors.Wrapf(err, "loading requirements file form dir %q", o.RequirementsDir)
 	}
@@ -6,6 +6,7 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/jenkins-x/jx/v2/pkg/kube/cluster"
 	v1 "k8s.io/api/core/v1"
@@ -110,7 +111,7 @@ func NewCmdStepCreateValues(commonOpts *opts.CommonOptions) *cobra.Command {
 	cmd.Flags().StringVarP(&options.Name, "name", "", "values", "the kind of the file to create (and, by default, the schema name)")
 	cmd.Flags().StringVarP(&options.BasePath, "secret-base-path", "", "", fmt.Sprintf("the secret path used to store secrets in vault / file system. Typically a unique name per cluster+team. If none is specified we will default it to the cluster name from the %s file in the current or a parent directory.", config.RequirementsConfigFileName))
 	cmd.Flags().StringVarP(&options.ValuesFile, "out", "", "", "the path to the file to create, overrides --dir and --name")
-	cmd.Flags().StringVarP(&options.SecretsScheme, optionSecretsScheme, "", "", fmt.Sprintf("the scheme to store/reference any secrets in, valid options are vault and local. If none are specified we will default it from the %s file in the current or a parent directory.", config.RequirementsConfigFileName))
+	cmd.Flags().StringVarP(&options.SecretsScheme, optionSecretsScheme, "", "", fmt.Sprintf("the scheme to store/reference any secrets in, valid options are vault, local and external-secrets. If none are specified we will default it from the %s file in the current or a parent directory.", config.RequirementsConfigFileName))
 	return cmd
 }
 
@@ -129,7 +130,7 @@ func (o *StepCreateValuesOptions) Run() error {
 		}
 	}
 	// lets default to the install requirements setting
-	requirements, fileName, err := config.LoadRequirementsConfig(o.Dir, config.DefaultFailOnValidationError)
+	requirements, fileName, err := config.LoadRequirementsConfigWithOptions(o.Dir, config.DefaultFailOnValidationError, o.RequirementsOptions())
 	if err != nil {
 		return err
 	}
@@ -155,8 +156,8 @@ func (o *StepCreateValuesOptions) Run() error {
 		}
 
 	}
-	if !(o.SecretsScheme == "vault" || o.SecretsScheme == "local") {
-		err = util.InvalidArgf(optionSecretsScheme, "Use one of vault or local")
+	if util.StringArrayIndex(config.SecretStorageTypeValues, o.SecretsScheme) < 0 {
+		err = util.InvalidArgf(optionSecretsScheme, "Use one of %s", strings.Join(config.SecretStorageTypeValues, ", "))
 		if err != nil {
 			return err
 		}
@@ -228,10 +229,18 @@ func (o *StepCreateValuesOptions) CreateValuesFile(secretURLClient secreturl.Cli
 	if err != nil {
 		return errors.WithStack(err)
 	}
-	err = util.CopyFile(valuesFileName, o.ValuesFile)
+	data, err := ioutil.ReadFile(valuesFileName)
+	if err != nil {
+		return errors.Wrapf(err, "reading %s", valuesFileName)
+	}
+	// lets keep the file encrypted if it was encrypted with sops or there is a sops configuration for it
+	encrypted, err := helm.WriteSOPSFile(o.ValuesFile, data)
 	if err != nil {
 		return errors.Wrapf(err, "moving %s to %s", valuesFileName, o.ValuesFile)
 	}
+	if encrypted {
+		log.Logger().Infof("encrypted %s with sops", util.ColorInfo(o.ValuesFile))
+	}
 	return nil
 }
 
@@ -298,7 +307,7 @@ func (o *StepCreateValuesOptions) verifyRegistryConfig(requirements *config.Requ
 
 		if registry != "" {
 			requirements.Cluster.Registry = registry
-			err = requirements.SaveConfig(requirementsFileName)
+			err = requirements.SaveConfigWithOptions(requirementsFileName, o.RequirementsOptions())
 			if err != nil {
 				return errors.Wrapf(err, "failed to save changes to file: %s", requirementsFileName)
 			}
@@ -320,7 +329,7 @@ func (o *StepCreateValuesOptions) createExternalRegistryValues(requirements *con
 	if val, exists := params["enableDocker"]; exists && val.(bool) {
 		dockerConfig := params["docker"].(map[string]interface{})
 		requirements.Cluster.Registry = getValidRegistryPushURL(dockerConfig["url"].(string))
-		err = requirements.SaveConfig(requirementsConfigFile)
+		err = requirements.SaveConfigWithOptions(requirementsConfigFile, o.RequirementsOptions())
 		if err != nil {
 			return errors.Wrap(err, "error saving the modified requirements.yml file")
 		}
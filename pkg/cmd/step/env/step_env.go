@@ -31,6 +31,7 @@ func NewCmdStepEnv(commonOpts *opts.CommonOptions) *cobra.Command {
 		},
 	}
 	cmd.AddCommand(NewCmdStepEnvApply(commonOpts))
+	cmd.AddCommand(NewCmdStepEnvEphemeral(commonOpts))
 	return cmd
 }
 
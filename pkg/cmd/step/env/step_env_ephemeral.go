@@ -0,0 +1,382 @@
+package env
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	v1 "github.com/jenkins-x/jx/v2/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/v2/pkg/builds"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts/step"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/v2/pkg/helm"
+	"github.com/jenkins-x/jx/v2/pkg/kube"
+	"github.com/jenkins-x/jx/v2/pkg/kube/naming"
+	"github.com/jenkins-x/jx/v2/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// EphemeralEnvironmentEnvVar the environment variable with the name of the ephemeral environment the tests run against
+	EphemeralEnvironmentEnvVar = "JX_TEST_ENVIRONMENT"
+	// EphemeralNamespaceEnvVar the environment variable with the namespace of the ephemeral environment the tests run against
+	EphemeralNamespaceEnvVar = "JX_TEST_NAMESPACE"
+
+	optionApp = "app"
+)
+
+// StepEnvEphemeralOptions contains the command line flags
+type StepEnvEphemeralOptions struct {
+	StepEnvOptions
+
+	Name        string
+	From        string
+	Apps        []string
+	Only        bool
+	TestCommand string
+	Keep        bool
+	Delete      bool
+
+	// calculated fields
+	devNamespace string
+}
+
+// AppVersion is the version of an application deployed into an ephemeral environment
+type AppVersion struct {
+	Name    string
+	Version string
+}
+
+var (
+	stepEnvEphemeralLong = templates.LongDesc(`
+		Creates a short lived test environment for integration testing several applications together.
+
+		The environment is created in its own namespace from the configuration of an existing environment, staging by
+		default, with the given versions of the applications. The test command is run against it with the
+		` + EphemeralEnvironmentEnvVar + ` and ` + EphemeralNamespaceEnvVar + ` environment variables set and the environment
+		is then torn down, whether the tests pass or not.
+
+		Without a test command the environment is kept so that later steps of the pipeline can test it and delete it with --delete.
+`)
+
+	stepEnvEphemeralExample = templates.Examples(`
+		# run the integration tests against the new versions of two applications and the other applications of staging
+		jx step env ephemeral --app orders:1.2.3 --app payments:0.4.0 --test "make integration-test"
+
+		# create an environment with only the given applications and delete it in a later step
+		jx step env ephemeral --name it-orders --app orders:1.2.3 --app payments:0.4.0 --only
+		jx step env ephemeral --name it-orders --delete
+`)
+)
+
+// NewCmdStepEnvEphemeral registers the command
+func NewCmdStepEnvEphemeral(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := StepEnvEphemeralOptions{
+		StepEnvOptions: StepEnvOptions{
+			StepOptions: step.StepOptions{
+				CommonOptions: commonOpts,
+			},
+		},
+	}
+	cmd := &cobra.Command{
+		Use:     "ephemeral",
+		Short:   "Creates a short lived environment to run integration tests against",
+		Aliases: []string{"ephemeral-test"},
+		Long:    stepEnvEphemeralLong,
+		Example: stepEnvEphemeralExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+	cmd.Flags().StringVarP(&options.Name, kube.OptionName, "n", "", "The name of the environment. Defaults to the pipeline and build number")
+	cmd.Flags().StringVarP(&options.From, "from", "", "staging", "The environment whose configuration the ephemeral environment is created from")
+	cmd.Flags().StringArrayVarP(&options.Apps, optionApp, "a", nil, "The application to deploy at a version in the form name:version")
+	cmd.Flags().BoolVarP(&options.Only, "only", "", false, "Only deploys the given applications instead of all the applications of the environment it is created from")
+	cmd.Flags().StringVarP(&options.TestCommand, "test", "", "", "The command running the test suite against the environment")
+	cmd.Flags().BoolVarP(&options.Keep, "keep", "", false, "Keeps the environment after running the test suite")
+	cmd.Flags().BoolVarP(&options.Delete, "delete", "", false, "Deletes the environment with the given name")
+	return cmd
+}
+
+// Run performs the command
+func (o *StepEnvEphemeralOptions) Run() error {
+	if o.Name == "" {
+		o.Name = o.defaultName()
+		if o.Name == "" {
+			return util.MissingOption(kube.OptionName)
+		}
+	}
+	o.Name = naming.ToValidNameTruncated(o.Name, 40)
+
+	if o.Delete {
+		return o.Teardown()
+	}
+	apps, err := ParseAppVersions(o.Apps)
+	if err != nil {
+		return err
+	}
+	if len(apps) == 0 && o.Only {
+		return util.MissingOption(optionApp)
+	}
+	env, err := o.CreateEnvironment()
+	if err != nil {
+		return err
+	}
+	if o.TestCommand == "" {
+		log.Logger().Infof("Created environment %s in namespace %s, delete it with: jx step env ephemeral --name %s --delete", util.ColorInfo(env.Name), util.ColorInfo(env.Spec.Namespace), env.Name)
+		o.Keep = true
+	}
+	if !o.Keep {
+		defer func() {
+			err := o.Teardown()
+			if err != nil {
+				log.Logger().Warnf("Failed to tear down environment %s: %s", env.Name, err)
+			}
+		}()
+	}
+	err = o.Deploy(env, apps)
+	if err != nil {
+		return err
+	}
+	if o.TestCommand == "" {
+		return nil
+	}
+	return o.RunTests(env)
+}
+
+// defaultName returns the name of the environment for the current pipeline and build or an empty string if this
+// is not running in a pipeline
+func (o *StepEnvEphemeralOptions) defaultName() string {
+	pipeline := o.GetJenkinsJobName()
+	build := builds.GetBuildNumber()
+	if pipeline == "" || build == "" {
+		return ""
+	}
+	return "it-" + pipeline + "-" + build
+}
+
+// ParseAppVersions parses the applications and versions in the form name:version
+func ParseAppVersions(texts []string) ([]AppVersion, error) {
+	answer := []AppVersion{}
+	names := map[string]bool{}
+	for _, text := range texts {
+		idx := strings.Index(text, ":")
+		if idx <= 0 || idx == len(text)-1 {
+			return nil, util.InvalidOptionf(optionApp, text, "the application must be in the form name:version")
+		}
+		app := AppVersion{
+			Name:    strings.TrimSpace(text[:idx]),
+			Version: strings.TrimSpace(text[idx+1:]),
+		}
+		if names[app.Name] {
+			return nil, util.InvalidOptionf(optionApp, text, "application %s is specified more than once", app.Name)
+		}
+		names[app.Name] = true
+		answer = append(answer, app)
+	}
+	return answer, nil
+}
+
+// CreateEnvironment creates the test environment and its namespace
+func (o *StepEnvEphemeralOptions) CreateEnvironment() (*v1.Environment, error) {
+	jxClient, ns, err := o.JXClientAndDevNamespace()
+	if err != nil {
+		return nil, err
+	}
+	o.devNamespace = ns
+	kubeClient, err := o.KubeClient()
+	if err != nil {
+		return nil, err
+	}
+	from, err := kube.GetEnvironment(jxClient, ns, o.From)
+	if err != nil {
+		return nil, errors.Wrapf(err, "finding the environment %s to create the environment from", o.From)
+	}
+	if from.Spec.Source.URL == "" {
+		return nil, fmt.Errorf("environment %s has no git repository to create the environment from", from.Name)
+	}
+	env := &v1.Environment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: o.Name,
+			Annotations: map[string]string{
+				kube.AnnotationReleaseName: o.Name,
+			},
+		},
+		Spec: v1.EnvironmentSpec{
+			Namespace:         ns + "-" + o.Name,
+			Label:             "Test " + o.Name,
+			Kind:              v1.EnvironmentKindTypeTest,
+			PromotionStrategy: v1.PromotionStrategyTypeNever,
+			Order:             999,
+			Source:            from.Spec.Source,
+		},
+	}
+	_, err = jxClient.JenkinsV1().Environments(ns).Create(env)
+	if err != nil {
+		return nil, errors.Wrapf(err, "creating environment %s", env.Name)
+	}
+	log.Logger().Infof("Created environment %s from environment %s", util.ColorInfo(env.Name), util.ColorInfo(from.Name))
+	err = kube.EnsureEnvironmentNamespaceSetup(kubeClient, jxClient, env, ns)
+	if err != nil {
+		return env, errors.Wrapf(err, "setting up the namespace %s of environment %s", env.Spec.Namespace, env.Name)
+	}
+	return env, nil
+}
+
+// Deploy deploys the chart of the environment it was created from with the given versions of the applications
+func (o *StepEnvEphemeralOptions) Deploy(env *v1.Environment, apps []AppVersion) error {
+	tmpDir, err := ioutil.TempDir("", "jx-env-ephemeral-")
+	if err != nil {
+		return errors.Wrap(err, "failed to create a temporary directory")
+	}
+	defer os.RemoveAll(tmpDir) //nolint:errcheck
+
+	gitURL := env.Spec.Source.URL
+	branch := env.Spec.Source.Ref
+	if branch == "" {
+		branch = "master"
+	}
+	cloneURL, err := o.GitCloneURL(gitURL)
+	if err != nil {
+		return err
+	}
+	dir := filepath.Join(tmpDir, "source")
+	err = o.Git().ShallowCloneBranch(cloneURL, branch, dir)
+	if err != nil {
+		return errors.Wrapf(err, "cloning branch %s of %s", branch, gitURL)
+	}
+	envDir := filepath.Join(dir, "env")
+	err = o.setAppVersions(envDir, apps)
+	if err != nil {
+		return err
+	}
+
+	apply := &StepEnvApplyOptions{
+		StepEnvOptions: o.StepEnvOptions,
+		Namespace:      env.Spec.Namespace,
+		Dir:            envDir,
+		ReleaseName:    env.Annotations[kube.AnnotationReleaseName],
+		Wait:           true,
+		Force:          true,
+	}
+	err = apply.Run()
+	if err != nil {
+		return errors.Wrapf(err, "deploying environment %s", env.Name)
+	}
+	return nil
+}
+
+// setAppVersions sets the versions of the applications in the requirements of the environment chart
+func (o *StepEnvEphemeralOptions) setAppVersions(envDir string, apps []AppVersion) error {
+	fileName := filepath.Join(envDir, helm.RequirementsFileName)
+	requirements, err := helm.LoadRequirementsFile(fileName)
+	if err != nil {
+		return errors.Wrapf(err, "loading %s", fileName)
+	}
+	if o.Only {
+		names := map[string]bool{}
+		for _, app := range apps {
+			names[app.Name] = true
+		}
+		for _, dep := range append([]*helm.Dependency{}, requirements.Dependencies...) {
+			if dep != nil && !names[dep.Name] {
+				requirements.RemoveApplication(dep.Name)
+			}
+		}
+	}
+	for _, app := range apps {
+		repository := ""
+		if requirementsDependency(requirements, app.Name) == nil {
+			repository = o.DefaultChartRepositoryURL()
+		}
+		requirements.SetAppVersion(app.Name, app.Version, repository, "")
+		log.Logger().Infof("Deploying %s version %s", util.ColorInfo(app.Name), util.ColorInfo(app.Version))
+	}
+	return helm.SaveFile(fileName, requirements)
+}
+
+// requirementsDependency returns the dependency of the application or nil
+func requirementsDependency(requirements *helm.Requirements, name string) *helm.Dependency {
+	for _, dep := range requirements.Dependencies {
+		if dep != nil && dep.Name == name {
+			return dep
+		}
+	}
+	return nil
+}
+
+// RunTests runs the test command against the environment
+func (o *StepEnvEphemeralOptions) RunTests(env *v1.Environment) error {
+	log.Logger().Infof("Running %s against environment %s", util.ColorInfo(o.TestCommand), util.ColorInfo(env.Name))
+	cmd := util.Command{
+		Name: "sh",
+		Args: []string{"-c", o.TestCommand},
+		Out:  o.Out,
+		Err:  o.Err,
+		Env: map[string]string{
+			EphemeralEnvironmentEnvVar: env.Name,
+			EphemeralNamespaceEnvVar:   env.Spec.Namespace,
+		},
+	}
+	_, err := cmd.RunWithoutRetry()
+	if err != nil {
+		return errors.Wrapf(err, "the tests failed against environment %s", env.Name)
+	}
+	log.Logger().Infof("The tests passed against environment %s", util.ColorInfo(env.Name))
+	return nil
+}
+
+// Teardown deletes the helm release, namespace and resource of the environment
+func (o *StepEnvEphemeralOptions) Teardown() error {
+	jxClient, ns, err := o.JXClientAndDevNamespace()
+	if err != nil {
+		return err
+	}
+	// applying the environment chart switches the dev namespace to the namespace of the environment
+	if o.devNamespace != "" {
+		ns = o.devNamespace
+	}
+	kubeClient, err := o.KubeClient()
+	if err != nil {
+		return err
+	}
+	env, err := kube.GetEnvironment(jxClient, ns, o.Name)
+	if err != nil {
+		return errors.Wrapf(err, "finding environment %s", o.Name)
+	}
+	if env.Spec.Kind != v1.EnvironmentKindTypeTest {
+		return fmt.Errorf("environment %s is a %s environment rather than an ephemeral test environment", env.Name, string(env.Spec.Kind))
+	}
+	envNs := env.Spec.Namespace
+	releaseName := env.Annotations[kube.AnnotationReleaseName]
+	if releaseName != "" {
+		log.Logger().Infof("Deleting helm release %s", util.ColorInfo(releaseName))
+		err = o.Helm().DeleteRelease(envNs, releaseName, true)
+		if err != nil {
+			log.Logger().Warnf("Failed to delete helm release %s: %s", releaseName, err)
+		}
+	}
+	if envNs != "" {
+		err = kubeClient.CoreV1().Namespaces().Delete(envNs, &metav1.DeleteOptions{})
+		if err != nil && !k8serrors.IsNotFound(err) {
+			return errors.Wrapf(err, "deleting namespace %s", envNs)
+		}
+	}
+	err = jxClient.JenkinsV1().Environments(ns).Delete(env.Name, &metav1.DeleteOptions{})
+	if err != nil && !k8serrors.IsNotFound(err) {
+		return errors.Wrapf(err, "deleting environment %s", env.Name)
+	}
+	log.Logger().Infof("Deleted environment %s and namespace %s", util.ColorInfo(env.Name), util.ColorInfo(envNs))
+	return nil
+}
@@ -0,0 +1,32 @@
+// +build unit
+
+package env_test
+
+import (
+	"testing"
+
+	"github.com/jenkins-x/jx/v2/pkg/cmd/step/env"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAppVersions(t *testing.T) {
+	t.Parallel()
+
+	apps, err := env.ParseAppVersions([]string{"orders:1.2.3", "payments: 0.4.0"})
+	require.NoError(t, err)
+	assert.Equal(t, []env.AppVersion{
+		{Name: "orders", Version: "1.2.3"},
+		{Name: "payments", Version: "0.4.0"},
+	}, apps)
+
+	for _, texts := range [][]string{
+		{"orders"},
+		{"orders:"},
+		{":1.2.3"},
+		{"orders:1.2.3", "orders:1.2.4"},
+	} {
+		_, err = env.ParseAppVersions(texts)
+		assert.Error(t, err, "parsing %v", texts)
+	}
+}
@@ -104,7 +104,7 @@ func (o *StepExposeOptions) Run() error {
 		return err
 	}
 
-	requirements, _, err := config.LoadRequirementsConfig(o.Dir, config.DefaultFailOnValidationError)
+	requirements, _, err := config.LoadRequirementsConfigWithOptions(o.Dir, config.DefaultFailOnValidationError, o.RequirementsOptions())
 	if err != nil {
 		return errors.Wrapf(err, "failed to load requirements YAML")
 	}
@@ -9,6 +9,7 @@ import (
 
 	"github.com/jenkins-x/jx/v2/pkg/auth"
 	"github.com/jenkins-x/jx/v2/pkg/cmd/opts/step"
+	"github.com/jenkins-x/jx/v2/pkg/gits"
 	"github.com/jenkins-x/jx/v2/pkg/gits/credentialhelper"
 	"github.com/pkg/errors"
 
@@ -255,6 +256,13 @@ func (o *StepGitCredentialsOptions) CreateGitCredentialsFromAuthService(authConf
 			if githubAppEnabled && o.RepoOwner != "" && gitAuth.GithubAppOwner != o.RepoOwner {
 				continue
 			}
+			if gitAuth.IsGithubApp() {
+				appAuth, err := gits.GitHubAppUserAuth(server.URL, gitAuth)
+				if err != nil {
+					return nil, errors.Wrapf(err, "creating git credentials for %s", server.URL)
+				}
+				gitAuth = appAuth
+			}
 			username := gitAuth.Username
 			password := gitAuth.ApiToken
 			if password == "" {
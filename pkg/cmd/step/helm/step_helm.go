@@ -64,7 +64,10 @@ func NewCmdStepHelm(commonOpts *opts.CommonOptions) *cobra.Command {
 	cmd.AddCommand(NewCmdStepHelmEnv(commonOpts))
 	cmd.AddCommand(NewCmdStepHelmInstall(commonOpts))
 	cmd.AddCommand(NewCmdStepHelmList(commonOpts))
+	cmd.AddCommand(NewCmdStepHelmMigrate(commonOpts))
+	cmd.AddCommand(NewCmdStepHelmPostRender(commonOpts))
 	cmd.AddCommand(NewCmdStepHelmRelease(commonOpts))
+	cmd.AddCommand(NewCmdStepHelmValues(commonOpts))
 	cmd.AddCommand(NewCmdStepHelmVersion(commonOpts))
 	return cmd
 }
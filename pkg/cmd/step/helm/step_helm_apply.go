@@ -7,6 +7,7 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/ghodss/yaml"
 	"github.com/google/uuid"
 	"github.com/mholt/archiver"
 	"github.com/pkg/errors"
@@ -17,12 +18,14 @@ import (
 	"github.com/jenkins-x/jx/v2/pkg/cmd/opts/step"
 	"github.com/jenkins-x/jx/v2/pkg/cmd/templates"
 	"github.com/jenkins-x/jx/v2/pkg/config"
+	"github.com/jenkins-x/jx/v2/pkg/externalsecrets"
 	"github.com/jenkins-x/jx/v2/pkg/gits"
 	"github.com/jenkins-x/jx/v2/pkg/helm"
 	configio "github.com/jenkins-x/jx/v2/pkg/io"
 	"github.com/jenkins-x/jx/v2/pkg/io/secrets"
 	"github.com/jenkins-x/jx/v2/pkg/kube"
 	"github.com/jenkins-x/jx/v2/pkg/kube/naming"
+	"github.com/jenkins-x/jx/v2/pkg/kustomize"
 	"github.com/jenkins-x/jx/v2/pkg/log"
 	"github.com/jenkins-x/jx/v2/pkg/platform"
 	"github.com/jenkins-x/jx/v2/pkg/secreturl/fakevault"
@@ -44,6 +47,14 @@ type StepHelmApplyOptions struct {
 	NoVault            bool
 	NoMasking          bool
 	ProviderValuesDir  string
+	Engine             string
+	Diff               bool
+	Selectors          []string
+	KustomizeDir       string
+	CRDPolicy          string
+	HookPolicy         string
+	CRDDiff            bool
+	TemplateDir        string
 }
 
 var (
@@ -52,6 +63,26 @@ var (
 
 		This step is usually used to apply any GitOps promotion changes into a Staging or Production cluster.
 
+		If the chart contains a kustomize overlay in the 'kustomize' directory it is applied to the manifests rendered
+		by helm as a post renderer, so that upstream charts can be patched, labelled or have their images changed
+		without forking them. This requires helm 3.
+
+		The values of the chart are layered using the optional values hierarchy in the 'values' directory of the chart
+		which is merged on top of the values.yaml in this order: 'values/base.yaml' shared by every environment, the
+		overlay of the environment being applied such as 'values/environments/staging.yaml' and then the overrides of
+		each app such as 'values/apps/jx-app-cheese.yaml' which are nested under the name of the app. Maps are merged
+		recursively and any other value replaces the previous one. Use 'jx step helm values --effective' to see the
+		merged values.
+
+		The CustomResourceDefinitions in the 'crds' directories of the chart and its dependencies are installed by helm
+		by default, which only installs them along with the release so they are never upgraded. Use --crd-policy to
+		apply them with kubectl before the chart instead, so that upgrades of charts such as cert-manager or tekton
+		upgrade their CustomResourceDefinitions too, or to skip them. Use --crd-diff to log the changes to the
+		CustomResourceDefinitions before they are applied and --hook-policy to skip the hooks of the chart.
+
+		Use --template-dir to render the resources of the chart into a directory with the same values and release name
+		it would be applied with, without changing the cluster.
+
         Environment Variables:
 		- JX_NO_DELETE_TMP_DIR="true" - prevents the removal of the temporary directory.
 `)
@@ -60,11 +91,26 @@ var (
 		# apply the chart in the env folder to namespace jx-staging
 		jx step helm apply --dir env --namespace jx-staging
 
+		# deploy each chart of the env folder as its own release with helmfile
+		jx step helm apply --dir env --namespace jx-staging --engine helmfile
+
+		# show the changes helmfile would make to the env folder in a pull request
+		jx step helm apply --dir env --namespace jx-staging --engine helmfile --diff
+
+		# upgrade the CustomResourceDefinitions of the charts with a server side apply, showing their changes first
+		jx step helm apply --dir env --namespace jx-staging --crd-policy server-side --crd-diff
+
+		# render the resources the chart in the env folder would apply to namespace jx-staging
+		jx step helm apply --dir env --namespace jx-staging --template-dir /tmp/jx-staging
+
 `)
 
 	defaultValueFileNames = []string{"values.yaml", "myvalues.yaml", helm.SecretsFileName, filepath.Join("env", helm.SecretsFileName)}
 )
 
+// vaultAgentValuesFileName the values file of the Vault Agent annotations
+const vaultAgentValuesFileName = "vault-agent-values.yaml"
+
 func NewCmdStepHelmApply(commonOpts *opts.CommonOptions) *cobra.Command {
 	options := StepHelmApplyOptions{
 		StepHelmOptions: StepHelmOptions{
@@ -98,12 +144,35 @@ func NewCmdStepHelmApply(commonOpts *opts.CommonOptions) *cobra.Command {
 	cmd.Flags().BoolVarP(&options.NoVault, "no-vault", "", false, "Disables loading secrets from Vault. e.g. if bootstrapping core services like Ingress before we have a Vault")
 	cmd.Flags().BoolVarP(&options.NoMasking, "no-masking", "", false, "The effective 'values.yaml' file is output to the console with parameters masked. Enabling this flag will show the unmasked secrets in the console output")
 	cmd.Flags().StringVarP(&options.ProviderValuesDir, "provider-values-dir", "", "", "The optional directory of kubernetes provider specific override values.tmpl.yaml files a kubernetes provider specific folder")
+	cmd.Flags().StringVarP(&options.Engine, "engine", "", "", fmt.Sprintf("The engine used to deploy the chart. Values: %s. Defaults to the 'deployEngine' of the environment in the 'jx-requirements.yml'", strings.Join(config.DeployEngineTypeValues, ", ")))
+	cmd.Flags().BoolVarP(&options.Diff, "diff", "", false, "Only shows the changes helmfile would make, commenting them on the pull request identified by $REPO_OWNER, $REPO_NAME and $PULL_NUMBER")
+	cmd.Flags().StringArrayVarP(&options.Selectors, "selector", "l", nil, "Only applies the helmfile releases matching the label selector, such as 'name=myapp'")
+	cmd.Flags().StringVarP(&options.KustomizeDir, "kustomize-dir", "", "kustomize", "The directory, relative to the chart, of the kustomize overlay applied to the rendered manifests")
+	cmd.Flags().StringVarP(&options.CRDPolicy, "crd-policy", "", string(helm.CRDPolicyHelm), fmt.Sprintf("How the CustomResourceDefinitions of the chart are applied. Values: %s", strings.Join(helm.CRDPolicyValues, ", ")))
+	cmd.Flags().StringVarP(&options.HookPolicy, "hook-policy", "", string(helm.HookPolicyRun), fmt.Sprintf("Whether the hooks of the chart are executed. Values: %s", strings.Join(helm.HookPolicyValues, ", ")))
+	cmd.Flags().BoolVarP(&options.CRDDiff, "crd-diff", "", false, "Logs the changes to the CustomResourceDefinitions of the chart before the chart is applied")
+	cmd.Flags().StringVarP(&options.TemplateDir, "template-dir", "", "", "Renders the resources of the chart with the generated values into the directory rather than applying the chart")
 
 	return cmd
 }
 
 func (o *StepHelmApplyOptions) Run() error {
 	var err error
+	if o.Engine != "" && util.StringArrayIndex(config.DeployEngineTypeValues, o.Engine) < 0 {
+		return util.InvalidOption("engine", o.Engine, config.DeployEngineTypeValues)
+	}
+	if o.CRDPolicy == "" {
+		o.CRDPolicy = string(helm.CRDPolicyHelm)
+	}
+	if o.HookPolicy == "" {
+		o.HookPolicy = string(helm.HookPolicyRun)
+	}
+	if util.StringArrayIndex(helm.CRDPolicyValues, o.CRDPolicy) < 0 {
+		return util.InvalidOption("crd-policy", o.CRDPolicy, helm.CRDPolicyValues)
+	}
+	if util.StringArrayIndex(helm.HookPolicyValues, o.HookPolicy) < 0 {
+		return util.InvalidOption("hook-policy", o.HookPolicy, helm.HookPolicyValues)
+	}
 	chartName := o.Dir
 	dir := o.Dir
 	releaseName := o.ReleaseName
@@ -148,9 +217,11 @@ func (o *StepHelmApplyOptions) Run() error {
 		return err
 	}
 
-	err = kube.EnsureNamespaceCreated(kubeClient, ns, nil, nil)
-	if err != nil {
-		return err
+	if o.TemplateDir == "" {
+		err = kube.EnsureNamespaceCreated(kubeClient, ns, nil, nil)
+		if err != nil {
+			return err
+		}
 	}
 
 	_, devNs, err := o.KubeClientAndDevNamespace()
@@ -189,7 +260,7 @@ func (o *StepHelmApplyOptions) Run() error {
 		defer os.RemoveAll(rootTmpDir) //nolint:errcheck
 	}
 
-	if os.Getenv(kube.DisableBuildLockEnvKey) == "" {
+	if o.TemplateDir == "" && os.Getenv(kube.DisableBuildLockEnvKey) == "" {
 		release, err := kube.AcquireBuildLock(kubeClient, devNs, ns)
 		if err != nil {
 			return errors.Wrapf(err, "fail to acquire the lock")
@@ -264,6 +335,18 @@ func (o *StepHelmApplyOptions) Run() error {
 	if err != nil {
 		return errors.Wrap(err, "failed to create a Secret RL client")
 	}
+	var agentClient *vault.AgentClient
+	if vault.AgentEnabled(requirements) {
+		// the secrets are injected into the pods by the Vault Agent so the vault: URIs are replaced with their files
+		agentClient = vault.NewAgentClient()
+		secretURLClient = agentClient
+	}
+	if requirements.SecretStorage == config.SecretStorageTypeExternalSecrets && o.TemplateDir == "" {
+		err = o.applyExternalSecrets(dir, requirements)
+		if err != nil {
+			return errors.Wrap(err, "applying the ExternalSecret resources")
+		}
+	}
 
 	DefaultEnvironments(requirements, devGitInfo)
 
@@ -281,6 +364,10 @@ func (o *StepHelmApplyOptions) Run() error {
 			return errors.Wrapf(err, "failed to overwrite provider values in dir: %s", dir)
 		}
 	}
+	chartValues, err = helm.ApplyValuesLayers(chartValues, dir, o.environmentName(ns, devNs))
+	if err != nil {
+		return errors.Wrapf(err, "failed to merge the values hierarchy in dir: %s", dir)
+	}
 
 	chartValuesFile := filepath.Join(dir, helm.ValuesFileName)
 	err = ioutil.WriteFile(chartValuesFile, chartValues, 0755)
@@ -313,6 +400,27 @@ func (o *StepHelmApplyOptions) Run() error {
 		}
 	}
 
+	if o.deployEngine(requirements, ns, devNs) == config.DeployEngineHelmfile {
+		if o.TemplateDir != "" {
+			return errors.New("the --template-dir flag is only supported by the helm engine")
+		}
+		if o.CRDPolicy != string(helm.CRDPolicyHelm) || o.HookPolicy != string(helm.HookPolicyRun) || o.CRDDiff {
+			return errors.New("the --crd-policy, --hook-policy and --crd-diff flags are only supported by the helm engine")
+		}
+		if agentClient != nil {
+			agentValuesFile, err := writeVaultAgentValues(dir, agentClient.Secrets(), requirements)
+			if err != nil {
+				return errors.Wrap(err, "writing the Vault Agent annotations")
+			}
+			if agentValuesFile != "" {
+				valueFiles = append(valueFiles, agentValuesFile)
+			}
+		}
+		return o.applyHelmfile(dir, ns, helmBinary, valueFiles, requirements)
+	} else if o.Diff {
+		return errors.New("the --diff flag is only supported by the helmfile engine")
+	}
+
 	_, err = o.HelmInitDependencyBuild(dir, o.DefaultReleaseCharts(), valueFiles)
 	if err != nil {
 		return err
@@ -370,7 +478,35 @@ func (o *StepHelmApplyOptions) Run() error {
 		return errors.Wrap(err, "applying chart overrides")
 	}
 
+	if agentClient != nil {
+		agentValuesFile, err := writeVaultAgentValues(dir, agentClient.Secrets(), requirements)
+		if err != nil {
+			return errors.Wrap(err, "writing the Vault Agent annotations")
+		}
+		if agentValuesFile != "" {
+			valueFiles = append(valueFiles, agentValuesFile)
+		}
+	}
+
+	err = o.configurePostRenderer(dir, rootTmpDir)
+	if err != nil {
+		return err
+	}
+
 	setValues, setStrings := o.getChartValues(ns)
+	if o.TemplateDir != "" {
+		err = o.Helm().Template(dir, releaseName, ns, o.TemplateDir, false, setValues, setStrings, valueFiles)
+		if err != nil {
+			return errors.Wrapf(err, "rendering helm chart '%s'", chartName)
+		}
+		return nil
+	}
+
+	err = o.applyCRDs(dir, filepath.Join(rootTmpDir, helm.CRDsDirName))
+	if err != nil {
+		return err
+	}
+	o.Helm().SetInstallPolicy(helm.CRDPolicy(o.CRDPolicy), helm.HookPolicy(o.HookPolicy))
 
 	helmOptions := helm.InstallChartOptions{
 		Chart:       chartName,
@@ -399,10 +535,64 @@ func (o *StepHelmApplyOptions) Run() error {
 	return nil
 }
 
+// writeVaultAgentValues writes the annotations which make the Vault Agent inject the secrets referenced by the chart
+// into the global.vaultAgent.annotations value so that charts can template them into their pods
+func writeVaultAgentValues(dir string, agentSecrets []vault.AgentSecret, requirements *config.RequirementsConfig) (string, error) {
+	annotations := vault.AgentAnnotations(agentSecrets, requirements, false)
+	if len(annotations) == 0 {
+		return "", nil
+	}
+	values := map[string]interface{}{
+		"global": map[string]interface{}{
+			"vaultAgent": map[string]interface{}{
+				"annotations": annotations,
+			},
+		},
+	}
+	data, err := yaml.Marshal(values)
+	if err != nil {
+		return "", errors.Wrap(err, "marshalling the Vault Agent values")
+	}
+	fileName := filepath.Join(dir, vaultAgentValuesFileName)
+	err = ioutil.WriteFile(fileName, data, util.DefaultWritePermissions)
+	if err != nil {
+		return "", errors.Wrapf(err, "writing %s", fileName)
+	}
+	return fileName, nil
+}
+
+// configurePostRenderer makes helm apply the kustomize overlay of the chart in dir to the rendered manifests if there
+// is one, writing the post renderer executable into binDir
+func (o *StepHelmApplyOptions) configurePostRenderer(dir string, binDir string) error {
+	overlayDir := o.KustomizeDir
+	if overlayDir != "" && !filepath.IsAbs(overlayDir) {
+		overlayDir = filepath.Join(dir, overlayDir)
+	}
+	exists, err := kustomize.HasOverlay(overlayDir)
+	if err != nil {
+		return errors.Wrapf(err, "checking for a kustomize overlay in %s", overlayDir)
+	}
+	if !exists {
+		o.Helm().SetPostRenderer("")
+		return nil
+	}
+	jxBinary, err := os.Executable()
+	if err != nil {
+		return errors.Wrap(err, "finding the jx binary")
+	}
+	postRenderer, err := kustomize.WritePostRenderer(binDir, jxBinary, overlayDir)
+	if err != nil {
+		return err
+	}
+	log.Logger().Infof("Applying the kustomize overlay %s to the rendered manifests", util.ColorInfo(o.KustomizeDir))
+	o.Helm().SetPostRenderer(postRenderer)
+	return nil
+}
+
 // getRequirements tries to load the requirements either from the team settings or local requirements file
 func (o *StepHelmApplyOptions) getRequirements() (*config.RequirementsConfig, string, error) {
 	// Try to load first the requirements from current directory
-	requirements, requirementsFileName, err := config.LoadRequirementsConfig(o.Dir, config.DefaultFailOnValidationError)
+	requirements, requirementsFileName, err := config.LoadRequirementsConfigWithOptions(o.Dir, config.DefaultFailOnValidationError, o.RequirementsOptions())
 	if err == nil {
 		return requirements, requirementsFileName, nil
 	}
@@ -602,3 +792,40 @@ func (o *StepHelmApplyOptions) fetchSecretFilesFromVault(dir string, store confi
 	}
 	return files, nil
 }
+
+// applyExternalSecrets applies the ExternalSecret resources for the secrets referenced by the charts in dir so that
+// the External Secrets Operator syncs them into the dev namespace before the values are generated
+func (o *StepHelmApplyOptions) applyExternalSecrets(dir string, requirements *config.RequirementsConfig) error {
+	_, devNs, err := o.KubeClientAndDevNamespace()
+	if err != nil {
+		return errors.Wrap(err, "finding the dev namespace")
+	}
+	refs, err := externalsecrets.FindSecretReferencesInDir(dir)
+	if err != nil {
+		return err
+	}
+	if len(refs) == 0 {
+		return nil
+	}
+	resources, err := externalsecrets.ExternalSecrets(refs, devNs, requirements)
+	if err != nil {
+		return err
+	}
+	data, err := externalsecrets.ToYAML(resources)
+	if err != nil {
+		return err
+	}
+	tmpFile, err := ioutil.TempFile("", "external-secrets-")
+	if err != nil {
+		return errors.Wrap(err, "failed to create temporary file for the ExternalSecret YAML")
+	}
+	tmpFileName := tmpFile.Name()
+	defer os.Remove(tmpFileName) //nolint:errcheck
+
+	err = ioutil.WriteFile(tmpFileName, data, util.DefaultWritePermissions)
+	if err != nil {
+		return errors.Wrapf(err, "failed to save the ExternalSecret YAML file %s", tmpFileName)
+	}
+	log.Logger().Infof("Applying %d ExternalSecret resources in namespace %s", len(resources), util.ColorInfo(devNs))
+	return o.RunCommand("kubectl", "apply", "-f", tmpFileName, "-n", devNs)
+}
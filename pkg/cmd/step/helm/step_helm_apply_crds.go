@@ -0,0 +1,82 @@
+package helm
+
+import (
+	"os/exec"
+	"strings"
+
+	"github.com/jenkins-x/jx/v2/pkg/helm"
+	"github.com/jenkins-x/jx/v2/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+)
+
+// applyCRDs applies the CustomResourceDefinitions of the chart in dir and its dependencies, which are collected into
+// crdsDir, before the chart is installed if the CRD policy applies them first, logging the changes to them first if
+// --crd-diff is enabled
+func (o *StepHelmApplyOptions) applyCRDs(dir string, crdsDir string) error {
+	policy := helm.CRDPolicy(o.CRDPolicy)
+	if !policy.AppliedFirst() && !o.CRDDiff {
+		return nil
+	}
+	files, err := helm.CollectCRDs(dir, crdsDir)
+	if err != nil {
+		return errors.Wrapf(err, "collecting the CustomResourceDefinitions of the chart in %s", dir)
+	}
+	if len(files) == 0 {
+		log.Logger().Debugf("No CustomResourceDefinitions found in the chart in %s", dir)
+		return nil
+	}
+	serverSide := policy == helm.CRDPolicyServerSide
+
+	if o.CRDDiff {
+		diff, err := diffCRDs(crdsDir, serverSide)
+		if err != nil {
+			return err
+		}
+		if diff == "" {
+			log.Logger().Infof("No changes to the %d CustomResourceDefinitions of the chart", len(files))
+		} else {
+			log.Logger().Infof("Changes to the CustomResourceDefinitions of the chart:\n%s", diff)
+		}
+	}
+	if !policy.AppliedFirst() {
+		return nil
+	}
+
+	log.Logger().Infof("Applying the %d CustomResourceDefinitions of the chart before the chart", len(files))
+	args := []string{"apply", "-f", crdsDir}
+	if serverSide {
+		args = append(args, "--server-side", "--force-conflicts")
+	}
+	cmd := util.Command{
+		Name: "kubectl",
+		Args: args,
+	}
+	output, err := cmd.RunWithoutRetry()
+	if err != nil {
+		return errors.Wrapf(err, "running kubectl %s", strings.Join(args, " "))
+	}
+	log.Logger().Debug(output)
+	return nil
+}
+
+// diffCRDs returns the changes applying the CustomResourceDefinitions in crdsDir would make to the cluster
+func diffCRDs(crdsDir string, serverSide bool) (string, error) {
+	args := []string{"diff", "-f", crdsDir}
+	if serverSide {
+		args = append(args, "--server-side", "--force-conflicts")
+	}
+	cmd := util.Command{
+		Name: "kubectl",
+		Args: args,
+	}
+	output, err := cmd.RunWithoutRetry()
+	if err != nil {
+		// kubectl diff exits with 1 when there are changes
+		if exitErr, ok := errors.Cause(err).(*exec.ExitError); ok && exitErr.ExitCode() == 1 {
+			return output, nil
+		}
+		return "", errors.Wrapf(err, "running kubectl %s", strings.Join(args, " "))
+	}
+	return output, nil
+}
@@ -0,0 +1,185 @@
+package helm
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"github.com/jenkins-x/jx/v2/pkg/config"
+	"github.com/jenkins-x/jx/v2/pkg/gits"
+	"github.com/jenkins-x/jx/v2/pkg/helm"
+	"github.com/jenkins-x/jx/v2/pkg/helmfile"
+	"github.com/jenkins-x/jx/v2/pkg/kube"
+	"github.com/jenkins-x/jx/v2/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/jenkins-x/jx/v2/pkg/versionstream"
+	"github.com/pkg/errors"
+)
+
+// deployEngine returns the engine used to deploy the environment in the namespace, either from the command line or
+// from the requirements of the environment
+func (o *StepHelmApplyOptions) deployEngine(requirements *config.RequirementsConfig, ns string, devNs string) config.DeployEngineType {
+	if o.Engine != "" {
+		return config.DeployEngineType(o.Engine)
+	}
+	return requirements.DeployEngine(o.environmentName(ns, devNs))
+}
+
+// environmentName returns the name of the environment deployed to the namespace or an empty string if it cannot be found
+func (o *StepHelmApplyOptions) environmentName(ns string, devNs string) string {
+	if ns == devNs {
+		return kube.LabelValueDevEnvironment
+	}
+	jxClient, _, err := o.JXClient()
+	if err != nil {
+		log.Logger().Warnf("failed to create the jx client to find the environment of namespace %s: %s", ns, err)
+		return ""
+	}
+	envs, _, err := kube.GetEnvironments(jxClient, devNs)
+	if err != nil {
+		log.Logger().Warnf("failed to find the environment of namespace %s: %s", ns, err)
+		return ""
+	}
+	for name, env := range envs {
+		if env.Spec.Namespace == ns {
+			return name
+		}
+	}
+	return ""
+}
+
+// applyHelmfile deploys each dependency of the environment chart in dir as its own release with helmfile. In diff mode
+// the changes are only shown and commented on the pull request which triggered the pipeline
+func (o *StepHelmApplyOptions) applyHelmfile(dir string, ns string, helmBinary string, valueFiles []string, requirements *config.RequirementsConfig) error {
+	values, err := o.helmfileValues(ns, valueFiles)
+	if err != nil {
+		return err
+	}
+	req, err := helm.LoadRequirementsFile(filepath.Join(dir, helm.RequirementsFileName))
+	if err != nil {
+		return errors.Wrapf(err, "failed to load the requirements of the environment chart in %s", dir)
+	}
+	var prefixes *versionstream.RepositoryPrefixes
+	if o.Boot {
+		resolver, err := o.getOrCreateVersionResolver(requirements)
+		if err != nil {
+			return err
+		}
+		prefixes, err = resolver.GetRepositoryPrefixes()
+		if err != nil {
+			return errors.Wrap(err, "failed to load repository prefixes")
+		}
+	}
+	state, err := helmfile.GenerateEnvironment(dir, ns, req, values, prefixes)
+	if err != nil {
+		return errors.Wrapf(err, "generating the helmfile for the environment chart in %s", dir)
+	}
+	fileName := filepath.Join(dir, helmfile.HelmfileFileName)
+	err = helmfile.SaveState(fileName, state)
+	if err != nil {
+		return err
+	}
+	log.Logger().Debugf("Generated %s with %d releases", fileName, len(state.Releases))
+
+	args := []string{"--file", fileName}
+	if helmBinary != "" && helmBinary != "helm" {
+		args = append(args, "--helm-binary", helmBinary)
+	}
+	for _, selector := range o.Selectors {
+		args = append(args, "--selector", selector)
+	}
+	if o.Diff {
+		args = append(args, "diff", "--suppress-secrets")
+	} else {
+		// apply the releases one at a time in the order of the requirements.yaml file
+		args = append(args, "apply", "--suppress-secrets", "--concurrency", "1")
+	}
+	cmd := util.Command{
+		Dir:  dir,
+		Name: "helmfile",
+		Args: args,
+	}
+	output, err := cmd.RunWithoutRetry()
+	if err != nil {
+		return errors.Wrapf(err, "running helmfile %s", strings.Join(args, " "))
+	}
+	log.Logger().Info(output)
+	if o.Diff {
+		return o.reportHelmfileDiff(ns, output)
+	}
+	return nil
+}
+
+// helmfileValues merges the values files and the namespace values of the environment chart into the values which are
+// split between the releases
+func (o *StepHelmApplyOptions) helmfileValues(ns string, valueFiles []string) (map[string]interface{}, error) {
+	values := map[string]interface{}{}
+	for _, valueFile := range valueFiles {
+		data, err := ioutil.ReadFile(valueFile)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to load file %s", valueFile)
+		}
+		m := map[string]interface{}{}
+		err = yaml.Unmarshal(data, &m)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to unmarshal file %s", valueFile)
+		}
+		util.CombineMapTrees(values, m)
+	}
+	setValues, setStrings := o.getChartValues(ns)
+	for _, value := range setValues {
+		k, v := splitChartValue(value)
+		if b, err := strconv.ParseBool(v); err == nil {
+			util.SetMapValueViaPath(values, k, b)
+		} else {
+			util.SetMapValueViaPath(values, k, v)
+		}
+	}
+	for _, value := range setStrings {
+		k, v := splitChartValue(value)
+		util.SetMapValueViaPath(values, k, v)
+	}
+	return values, nil
+}
+
+func splitChartValue(value string) (string, string) {
+	parts := strings.SplitN(value, "=", 2)
+	if len(parts) < 2 {
+		return parts[0], ""
+	}
+	return parts[0], parts[1]
+}
+
+// reportHelmfileDiff comments the helmfile diff on the pull request identified by $REPO_OWNER, $REPO_NAME and
+// $PULL_NUMBER if there is one
+func (o *StepHelmApplyOptions) reportHelmfileDiff(ns string, diff string) error {
+	owner := os.Getenv(REPO_OWNER)
+	repo := os.Getenv(REPO_NAME)
+	number, err := strconv.Atoi(os.Getenv("PULL_NUMBER"))
+	if owner == "" || repo == "" || err != nil {
+		log.Logger().Debugf("not commenting the diff as $REPO_OWNER, $REPO_NAME and $PULL_NUMBER do not identify a pull request")
+		return nil
+	}
+	_, provider, _, err := o.CreateGitProvider(o.Dir)
+	if err != nil {
+		return errors.Wrap(err, "failed to get git provider")
+	}
+	comment := fmt.Sprintf("No changes to the releases in namespace `%s`", ns)
+	if strings.TrimSpace(diff) != "" {
+		comment = fmt.Sprintf("Changes to the releases in namespace `%s`:\n\n```diff\n%s\n```", ns, strings.TrimSpace(diff))
+	}
+	pr := &gits.GitPullRequest{
+		Owner:  owner,
+		Repo:   repo,
+		Number: &number,
+	}
+	err = provider.AddPRComment(pr, comment)
+	if err != nil {
+		return errors.Wrapf(err, "commenting on pull request %s/%s #%d", owner, repo, number)
+	}
+	return nil
+}
@@ -12,6 +12,7 @@ import (
 	"github.com/ghodss/yaml"
 	"github.com/google/uuid"
 	"github.com/mholt/archiver"
+	"github.com/petergtz/pegomock"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	v12 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -94,3 +95,55 @@ func TestApplyAppsTemplateOverrides(t *testing.T) {
 	assert.Equal(t, "jx-app-dummy", app.Labels[helm.LabelAppName])
 
 }
+
+func TestApplyTemplateDirRendersTheChart(t *testing.T) {
+	testOptions := testhelpers.CreateAppTestOptions(true, "dummy", t)
+	defer testOptions.Cleanup() //nolint:errcheck
+
+	envsDir, err := testOptions.CommonOptions.EnvironmentsDir()
+	require.NoError(t, err)
+	templateDir, err := ioutil.TempDir("", "test-template-dir-")
+	require.NoError(t, err)
+	defer os.RemoveAll(templateDir) //nolint:errcheck
+
+	sto := helm_cmd.StepHelmApplyOptions{
+		StepHelmOptions: helm_cmd.StepHelmOptions{
+			Dir: filepath.Join(envsDir, testOptions.DevEnv.Name),
+			StepOptions: step.StepOptions{
+				CommonOptions: testOptions.CommonOptions,
+			},
+		},
+		Namespace:          "jx-staging",
+		DisableHelmVersion: true,
+		TemplateDir:        templateDir,
+	}
+	err = sto.Run()
+	require.NoError(t, err)
+
+	_, _, ns, outputDir, _, _, _, _ := testOptions.MockHelmer.VerifyWasCalledOnce().Template(
+		pegomock.AnyString(),
+		pegomock.AnyString(),
+		pegomock.AnyString(),
+		pegomock.AnyString(),
+		pegomock.AnyBool(),
+		pegomock.AnyStringSlice(),
+		pegomock.AnyStringSlice(),
+		pegomock.AnyStringSlice()).GetCapturedArguments()
+	assert.Equal(t, "jx-staging", ns)
+	assert.Equal(t, templateDir, outputDir)
+	testOptions.MockHelmer.VerifyWasCalled(pegomock.Never()).UpgradeChart(
+		pegomock.AnyString(),
+		pegomock.AnyString(),
+		pegomock.AnyString(),
+		pegomock.AnyString(),
+		pegomock.AnyBool(),
+		pegomock.AnyInt(),
+		pegomock.AnyBool(),
+		pegomock.AnyBool(),
+		pegomock.AnyStringSlice(),
+		pegomock.AnyStringSlice(),
+		pegomock.AnyStringSlice(),
+		pegomock.AnyString(),
+		pegomock.AnyString(),
+		pegomock.AnyString())
+}
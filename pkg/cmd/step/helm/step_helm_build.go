@@ -91,7 +91,7 @@ func (o *StepHelmBuildOptions) Run() error {
 	}
 
 	if o.Boot {
-		requirements, requirementsFileName, err := config.LoadRequirementsConfig(dir, config.DefaultFailOnValidationError)
+		requirements, requirementsFileName, err := config.LoadRequirementsConfigWithOptions(dir, config.DefaultFailOnValidationError, o.RequirementsOptions())
 		if err != nil {
 			return err
 		}
@@ -0,0 +1,194 @@
+package helm
+
+import (
+	"strings"
+
+	"github.com/blang/semver"
+	v1 "github.com/jenkins-x/jx/v2/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts/step"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/v2/pkg/helm"
+	"github.com/jenkins-x/jx/v2/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// StepHelmMigrateOptions contains the command line flags
+type StepHelmMigrateOptions struct {
+	StepHelmOptions
+
+	HelmBinary      string
+	TillerNamespace string
+	Releases        []string
+	DryRun          bool
+	Cleanup         bool
+}
+
+var (
+	stepHelmMigrateLong = templates.LongDesc(`
+		Migrates the helm 2 releases stored by tiller to helm 3 using the helm 2to3 plugin and switches the team settings
+		of the development environment to helm 3 so that tiller is no longer used.
+
+		The releases are converted in place so nothing is reinstalled. Once all the releases have been migrated use
+		the --cleanup flag to remove the helm 2 configuration, release data and tiller.
+`)
+
+	stepHelmMigrateExample = templates.Examples(`
+		# shows what would be migrated
+		jx step helm migrate --dry-run
+
+		# migrates all the helm 2 releases to helm 3
+		jx step helm migrate
+
+		# migrates the releases and then removes the helm 2 data and tiller
+		jx step helm migrate --cleanup
+`)
+)
+
+// NewCmdStepHelmMigrate creates the command object
+func NewCmdStepHelmMigrate(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := StepHelmMigrateOptions{
+		StepHelmOptions: StepHelmOptions{
+			StepOptions: step.StepOptions{
+				CommonOptions: commonOpts,
+			},
+		},
+	}
+	cmd := &cobra.Command{
+		Use:     "migrate",
+		Short:   "Migrates the helm 2 releases to helm 3 so tiller is no longer used",
+		Aliases: []string{"2to3"},
+		Long:    stepHelmMigrateLong,
+		Example: stepHelmMigrateExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+	cmd.Flags().StringVarP(&options.HelmBinary, "helm-binary", "", "helm", "The helm 3 binary used to migrate the releases")
+	cmd.Flags().StringVarP(&options.TillerNamespace, "tiller-namespace", "", opts.DefaultTillerNamesapce, "The namespace tiller stores the helm 2 releases in")
+	cmd.Flags().StringArrayVarP(&options.Releases, "release", "r", nil, "The helm 2 releases to migrate. Defaults to all the releases in the tiller namespace")
+	cmd.Flags().BoolVarP(&options.DryRun, "dry-run", "", false, "Shows what would be migrated without changing anything")
+	cmd.Flags().BoolVarP(&options.Cleanup, "cleanup", "", false, "Removes the helm 2 configuration, release data and tiller after migrating the releases")
+	return cmd
+}
+
+// Run performs the CLI command
+func (o *StepHelmMigrateOptions) Run() error {
+	kubeClient, err := o.KubeClient()
+	if err != nil {
+		return errors.Wrap(err, "creating the kube client")
+	}
+	err = o.verifyHelm3()
+	if err != nil {
+		return err
+	}
+	releases := o.Releases
+	if len(releases) == 0 {
+		releases, err = helm.Helm2Releases(kubeClient, o.TillerNamespace)
+		if err != nil {
+			return err
+		}
+	}
+	log.Logger().Infof("Migrating %d helm 2 releases in tiller namespace %s", len(releases), util.ColorInfo(o.TillerNamespace))
+
+	err = o.ensure2to3Plugin()
+	if err != nil {
+		return err
+	}
+	_, err = o.run2to3("move", "config", "--skip-confirmation")
+	if err != nil {
+		return errors.Wrap(err, "moving the helm 2 configuration")
+	}
+	for _, release := range releases {
+		log.Logger().Infof("Converting release %s", util.ColorInfo(release))
+		_, err = o.run2to3("convert", release, "--tiller-ns", o.TillerNamespace)
+		if err != nil {
+			return errors.Wrapf(err, "converting the helm 2 release %s", release)
+		}
+	}
+
+	if o.DryRun {
+		log.Logger().Infof("Not updating the team settings as this is a dry run")
+		return nil
+	}
+	err = o.ModifyDevEnvironment(func(env *v1.Environment) error {
+		env.Spec.TeamSettings.HelmBinary = o.HelmBinary
+		env.Spec.TeamSettings.NoTiller = true
+		env.Spec.TeamSettings.HelmTemplate = false
+		return nil
+	})
+	if err != nil {
+		return errors.Wrap(err, "switching the team settings to helm 3")
+	}
+	log.Logger().Infof("The team now uses %s", util.ColorInfo(o.HelmBinary))
+
+	if o.Cleanup {
+		log.Logger().Infof("Removing the helm 2 data and tiller from namespace %s", util.ColorInfo(o.TillerNamespace))
+		_, err = o.run2to3("cleanup", "--tiller-ns", o.TillerNamespace, "--skip-confirmation")
+		if err != nil {
+			return errors.Wrap(err, "cleaning up the helm 2 data")
+		}
+	}
+	return nil
+}
+
+// verifyHelm3 verifies the helm binary is helm 3 as the 2to3 plugin is a helm 3 plugin
+func (o *StepHelmMigrateOptions) verifyHelm3() error {
+	output, err := helm.NewHelmCLI(o.HelmBinary, helm.V3, "", o.Verbose).VersionWithArgs(false)
+	if err != nil {
+		return errors.Wrapf(err, "getting the version of %s", o.HelmBinary)
+	}
+	v, err := semver.ParseTolerant(strings.TrimPrefix(strings.TrimSpace(output), "Client: "))
+	if err != nil {
+		return errors.Wrapf(err, "parsing the version of %s", o.HelmBinary)
+	}
+	if v.Major != 3 {
+		return errors.Errorf("%s is helm v%d, use --helm-binary to specify a helm 3 binary", o.HelmBinary, v.Major)
+	}
+	return nil
+}
+
+func (o *StepHelmMigrateOptions) ensure2to3Plugin() error {
+	output, err := o.runHelm("plugin", "list")
+	if err != nil {
+		return errors.Wrap(err, "listing the helm plugins")
+	}
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) > 0 && fields[0] == helm.Helm2to3PluginName {
+			return nil
+		}
+	}
+	log.Logger().Infof("Installing the helm %s plugin", util.ColorInfo(helm.Helm2to3PluginName))
+	_, err = o.runHelm("plugin", "install", helm.Helm2to3PluginURL)
+	if err != nil {
+		return errors.Wrapf(err, "installing the helm %s plugin", helm.Helm2to3PluginName)
+	}
+	return nil
+}
+
+func (o *StepHelmMigrateOptions) run2to3(args ...string) (string, error) {
+	args = append([]string{helm.Helm2to3PluginName}, args...)
+	if o.DryRun {
+		args = append(args, "--dry-run")
+	}
+	return o.runHelm(args...)
+}
+
+func (o *StepHelmMigrateOptions) runHelm(args ...string) (string, error) {
+	cmd := util.Command{
+		Name: o.HelmBinary,
+		Args: args,
+	}
+	output, err := cmd.RunWithoutRetry()
+	if o.Verbose && output != "" {
+		log.Logger().Info(output)
+	}
+	return output, err
+}
@@ -0,0 +1,92 @@
+package helm
+
+import (
+	"io/ioutil"
+	"os"
+
+	"github.com/jenkins-x/jx/v2/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts/step"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/v2/pkg/kustomize"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// StepHelmPostRenderOptions contains the command line flags
+type StepHelmPostRenderOptions struct {
+	StepHelmOptions
+}
+
+var (
+	stepHelmPostRenderLong = templates.LongDesc(`
+		Applies a kustomize overlay to the manifests rendered by helm which are read from standard input, writing the
+		result to standard output.
+
+		This command is run by helm as a post renderer when 'jx step helm apply' finds a kustomize overlay in the
+		environment chart so that patches, labels and images can be changed without forking the charts.
+`)
+
+	stepHelmPostRenderExample = templates.Examples(`
+		# applies the overlay in the env/kustomize directory to the rendered manifests
+		helm template env | jx step helm post-render --dir env/kustomize
+`)
+)
+
+// NewCmdStepHelmPostRender creates the command object
+func NewCmdStepHelmPostRender(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := StepHelmPostRenderOptions{
+		StepHelmOptions: StepHelmOptions{
+			StepOptions: step.StepOptions{
+				CommonOptions: commonOpts,
+			},
+		},
+	}
+	cmd := &cobra.Command{
+		Use:     "post-render",
+		Short:   "Applies a kustomize overlay to the manifests rendered by helm",
+		Long:    stepHelmPostRenderLong,
+		Example: stepHelmPostRenderExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+	cmd.Flags().StringVarP(&options.Dir, "dir", "d", "", "The directory containing the kustomize overlay")
+	return cmd
+}
+
+// Run performs the CLI command
+func (o *StepHelmPostRenderOptions) Run() error {
+	if o.Dir == "" {
+		return util.MissingOption("dir")
+	}
+	exists, err := kustomize.HasOverlay(o.Dir)
+	if err != nil {
+		return errors.Wrapf(err, "checking for a kustomize overlay in %s", o.Dir)
+	}
+	if !exists {
+		return errors.Errorf("there is no %s in directory %s", kustomize.KustomizationFileName, o.Dir)
+	}
+	in := o.In
+	if in == nil {
+		in = os.Stdin
+	}
+	manifests, err := ioutil.ReadAll(in)
+	if err != nil {
+		return errors.Wrap(err, "reading the rendered manifests")
+	}
+	output, err := kustomize.PostRender(o.Kustomize(), o.Dir, manifests)
+	if err != nil {
+		return errors.Wrapf(err, "applying the kustomize overlay in %s", o.Dir)
+	}
+	out := o.Out
+	if out == nil {
+		out = os.Stdout
+	}
+	_, err = out.Write(output)
+	return err
+}
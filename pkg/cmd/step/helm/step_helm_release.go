@@ -1,14 +1,13 @@
 package helm
 
 import (
-	"bufio"
 	"fmt"
-	"io/ioutil"
-	"net/http"
 	"os"
 	"path/filepath"
 
+	"github.com/jenkins-x/jx/v2/pkg/chartrepo"
 	"github.com/jenkins-x/jx/v2/pkg/cmd/opts/step"
+	"github.com/jenkins-x/jx/v2/pkg/config"
 
 	"github.com/jenkins-x/jx/v2/pkg/cmd/helper"
 
@@ -30,7 +29,11 @@ type StepHelmReleaseOptions struct {
 
 var (
 	StepHelmReleaseLong = templates.LongDesc(`
-		This pipeline step releases the Helm chart in the current directory
+		This pipeline step releases the Helm chart in the current directory to the chart repository.
+
+		The kind of chart repository is configured via 'cluster.chartKind' in the jx-requirements.yml and can be one
+		of: chartmuseum, nexus, artifactory, harbor or bucket. When using a bucket the chart and the index of the
+		repository are written to the bucket configured via 'storage.repository.url'.
 `)
 
 	StepHelmReleaseExample = templates.Examples(`
@@ -103,15 +106,31 @@ func (o *StepHelmReleaseOptions) Run() error {
 	}
 	defer os.Remove(tarball)
 
-	chartRepo := o.ReleaseChartRepositoryURL()
+	kind, bucketURL := o.ReleaseChartRepositoryKind()
+	userName := ""
+	password := ""
+	if kind != config.ChartRepositoryTypeBucket {
+		userName, password, err = o.chartRepositoryCredentials()
+		if err != nil {
+			return err
+		}
+	}
+	chartRepo, err := chartrepo.NewChartRepository(kind, o.ReleaseChartRepositoryURL(), bucketURL, userName, password)
+	if err != nil {
+		return errors.Wrap(err, "failed to create the chart repository")
+	}
+	return chartRepo.Publish(tarball)
+}
 
+// chartRepositoryCredentials returns the credentials used to upload charts to the chart repository
+func (o *StepHelmReleaseOptions) chartRepositoryCredentials() (string, string, error) {
 	userName := os.Getenv("CHARTMUSEUM_CREDS_USR")
 	password := os.Getenv("CHARTMUSEUM_CREDS_PSW")
 	if userName == "" || password == "" {
 		// lets try load them from the secret directly
 		client, ns, err := o.KubeClientAndNamespace()
 		if err != nil {
-			return errors.Wrap(err, "failed to create the kube client")
+			return "", "", errors.Wrap(err, "failed to create the kube client")
 		}
 		secret, err := client.CoreV1().Secrets(ns).Get(kube.SecretJenkinsChartMuseum, metav1.GetOptions{})
 		if err != nil {
@@ -131,45 +150,10 @@ func (o *StepHelmReleaseOptions) Run() error {
 		}
 	}
 	if userName == "" {
-		return fmt.Errorf("No environment variable $CHARTMUSEUM_CREDS_USR defined")
+		return "", "", fmt.Errorf("No environment variable $CHARTMUSEUM_CREDS_USR defined")
 	}
 	if password == "" {
-		return fmt.Errorf("No environment variable CHARTMUSEUM_CREDS_PSW defined")
-	}
-
-	// post the tarball to the chart repository
-	client := http.Client{}
-
-	u := util.UrlJoin(chartRepo, "/api/charts")
-
-	file, err := os.Open(tarball)
-	if err != nil {
-		return errors.Wrapf(err, "failed to open the chart archive '%s'", tarball)
-	}
-	log.Logger().Infof("Uploading chart file %s to %s", util.ColorInfo(tarball), util.ColorInfo(u))
-	req, err := http.NewRequest(http.MethodPost, u, bufio.NewReader(file))
-	if err != nil {
-		return errors.Wrapf(err, "failed to build the chart upload request for endpoint '%s'", u)
-	}
-	req.SetBasicAuth(userName, password)
-	req.Header.Set("Content-Type", "application/gzip")
-	res, err := client.Do(req)
-	if err != nil {
-		if res == nil {
-			return errors.Wrapf(err, "failed to execute the chart upload HTTP request, url: '%s', error: '%v'", u, err)
-		}
-		errRes, _ := ioutil.ReadAll(res.Body)
-		return errors.Wrapf(err, "failed to execute the chart upload HTTP request, url: '%s', status: '%s', response: '%s'", u, res.Status, string(errRes))
-	}
-	body, err := ioutil.ReadAll(res.Body)
-	if err != nil {
-		return errors.Wrap(err, "failed to read the response body of chart upload request")
-	}
-	responseMessage := string(body)
-	statusCode := res.StatusCode
-	log.Logger().Infof("Received %d response: %s", statusCode, responseMessage)
-	if statusCode >= 300 {
-		return fmt.Errorf("Failed to post chart to %s due to response %d: %s", u, statusCode, responseMessage)
+		return "", "", fmt.Errorf("No environment variable CHARTMUSEUM_CREDS_PSW defined")
 	}
-	return nil
+	return userName, password, nil
 }
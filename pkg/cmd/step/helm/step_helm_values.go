@@ -0,0 +1,118 @@
+package helm
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/jenkins-x/jx/v2/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts/step"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/v2/pkg/config"
+	"github.com/jenkins-x/jx/v2/pkg/helm"
+	"github.com/jenkins-x/jx/v2/pkg/log"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// StepHelmValuesOptions contains the command line flags
+type StepHelmValuesOptions struct {
+	StepHelmOptions
+
+	Environment string
+	Effective   bool
+}
+
+var (
+	stepHelmValuesLong = templates.LongDesc(`
+		Shows the values hierarchy of the helm chart in a directory for an environment.
+
+		The values in the 'values' directory of the chart are merged on top of the values.yaml of the chart in this
+		order: 'values/base.yaml' shared by every environment, the overlay of the environment such as
+		'values/environments/staging.yaml' and then the overrides of each app such as 'values/apps/jx-app-cheese.yaml'
+		which are nested under the name of the app.
+
+		By default the values files are listed in the order they are merged. Use --effective to print the merged values
+		which 'jx step helm apply' uses. Secret URLs are not resolved so that secrets are not shown.
+`)
+
+	stepHelmValuesExample = templates.Examples(`
+		# lists the values files merged for the staging environment
+		jx step helm values --dir env --env staging
+
+		# prints the merged values of the staging environment
+		jx step helm values --dir env --env staging --effective
+`)
+)
+
+// NewCmdStepHelmValues creates the command object
+func NewCmdStepHelmValues(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := StepHelmValuesOptions{
+		StepHelmOptions: StepHelmOptions{
+			StepOptions: step.StepOptions{
+				CommonOptions: commonOpts,
+			},
+		},
+	}
+	cmd := &cobra.Command{
+		Use:     "values",
+		Short:   "Shows the values hierarchy of the helm chart for an environment",
+		Long:    stepHelmValuesLong,
+		Example: stepHelmValuesExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+	cmd.Flags().StringVarP(&options.Dir, "dir", "d", ".", "The directory containing the helm chart")
+	cmd.Flags().StringVarP(&options.Environment, "env", "e", "", "The name of the environment whose overlay is merged")
+	cmd.Flags().BoolVarP(&options.Effective, "effective", "", false, "Prints the merged values rather than the values files")
+	return cmd
+}
+
+// Run performs the CLI command
+func (o *StepHelmValuesOptions) Run() error {
+	dir, err := filepath.Abs(o.Dir)
+	if err != nil {
+		return errors.Wrapf(err, "could not find absolute path of dir %s", o.Dir)
+	}
+	out := o.Out
+	if out == nil {
+		out = os.Stdout
+	}
+	if !o.Effective {
+		layers, err := helm.ValuesLayers(dir, o.Environment)
+		if err != nil {
+			return errors.Wrapf(err, "finding the values hierarchy in %s", dir)
+		}
+		fmt.Fprintln(out, filepath.Join(dir, helm.ValuesFileName)) //nolint:errcheck
+		for _, layer := range layers {
+			if layer.Key != "" {
+				fmt.Fprintf(out, "%s (nested under %s)\n", layer.File, layer.Key) //nolint:errcheck
+			} else {
+				fmt.Fprintln(out, layer.File) //nolint:errcheck
+			}
+		}
+		return nil
+	}
+
+	requirements, _, err := config.LoadRequirementsConfigWithOptions(dir, config.DefaultFailOnValidationError, o.RequirementsOptions())
+	if err != nil {
+		// environment repositories other than the development environment have no requirements
+		log.Logger().Debugf("using the default requirements to render the values: %s", err)
+		requirements = config.NewRequirementsConfig()
+	}
+	values, _, err := helm.GenerateValues(requirements, nil, dir, nil, o.Verbose, nil)
+	if err != nil {
+		return errors.Wrapf(err, "generating values.yaml for tree from %s", dir)
+	}
+	values, err = helm.ApplyValuesLayers(values, dir, o.Environment)
+	if err != nil {
+		return errors.Wrapf(err, "failed to merge the values hierarchy in dir: %s", dir)
+	}
+	_, err = out.Write(values)
+	return err
+}
@@ -0,0 +1,42 @@
+package repo
+
+import (
+	"github.com/jenkins-x/jx/v2/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts/step"
+	"github.com/spf13/cobra"
+)
+
+// StepRepoOptions contains the command line flags
+type StepRepoOptions struct {
+	step.StepOptions
+}
+
+// NewCmdStepRepo Steps a command object for the "step repo" command
+func NewCmdStepRepo(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &StepRepoOptions{
+		StepOptions: step.StepOptions{
+			CommonOptions: commonOpts,
+		},
+	}
+
+	cmd := &cobra.Command{
+		Use:   "repo",
+		Short: "pipeline step repo",
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+
+	cmd.AddCommand(NewCmdStepRepoSyncSettings(commonOpts))
+
+	return cmd
+}
+
+// Run implements this command
+func (o *StepRepoOptions) Run() error {
+	return o.Cmd.Help()
+}
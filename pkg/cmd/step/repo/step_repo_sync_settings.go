@@ -0,0 +1,140 @@
+package repo
+
+import (
+	"fmt"
+
+	"github.com/jenkins-x/jx/v2/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts/step"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/v2/pkg/gits"
+	"github.com/jenkins-x/jx/v2/pkg/kube"
+	"github.com/jenkins-x/jx/v2/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// StepRepoSyncSettingsOptions contains the command line flags
+type StepRepoSyncSettingsOptions struct {
+	step.StepOptions
+
+	Dir    string
+	Branch string
+	All    bool
+}
+
+var (
+	stepRepoSyncSettingsLong = templates.LongDesc(`
+		Reconciles the branch protection, required status checks, merge strategies and labels of git repositories with
+		the 'repositorySettings' of the team settings.
+
+		The repositories are configured when they are imported unless 'jx import --no-repo-settings' is used. This step
+		configures them again after the team settings have changed or if they have been changed by hand.
+`)
+
+	stepRepoSyncSettingsExample = templates.Examples(`
+		# Reconciles the settings of the repository in the current directory
+		jx step repo sync-settings
+
+		# Reconciles the settings of all the repositories imported into the team
+		jx step repo sync-settings --all
+`)
+)
+
+// NewCmdStepRepoSyncSettings creates the command
+func NewCmdStepRepoSyncSettings(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &StepRepoSyncSettingsOptions{
+		StepOptions: step.StepOptions{
+			CommonOptions: commonOpts,
+		},
+	}
+	cmd := &cobra.Command{
+		Use:     "sync-settings",
+		Short:   "Reconciles the settings of git repositories with the repository settings of the team",
+		Long:    stepRepoSyncSettingsLong,
+		Example: stepRepoSyncSettingsExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+	cmd.Flags().StringVarP(&options.Dir, "dir", "d", "", "The directory of the git repository. Defaults to the current directory")
+	cmd.Flags().StringVarP(&options.Branch, "branch", "b", "", "The branch to protect. Defaults to the current branch of the repository or master with --all")
+	cmd.Flags().BoolVarP(&options.All, "all", "", false, "Reconciles the settings of all the SourceRepositories of the team")
+	return cmd
+}
+
+// Run implements the command
+func (o *StepRepoSyncSettingsOptions) Run() error {
+	settings, err := o.TeamSettings()
+	if err != nil {
+		return errors.Wrap(err, "loading the team settings")
+	}
+	repoSettings := settings.RepositorySettings
+	if repoSettings == nil {
+		log.Logger().Infof("The team settings have no repository settings so there is nothing to reconcile")
+		return nil
+	}
+	err = gits.ValidateRepositorySettings(repoSettings)
+	if err != nil {
+		return errors.Wrap(err, "validating the repository settings of the team")
+	}
+
+	if !o.All {
+		gitInfo, provider, _, err := o.CreateGitProvider(o.Dir)
+		if err != nil {
+			return err
+		}
+		if provider == nil {
+			return fmt.Errorf("no git provider could be found for the repository in %s", o.Dir)
+		}
+		branch := o.Branch
+		if branch == "" {
+			branch, err = o.Git().Branch(o.Dir)
+			if err != nil {
+				return errors.Wrapf(err, "finding the current branch of %s", o.Dir)
+			}
+		}
+		_, err = gits.ApplyRepositorySettings(provider, gitInfo.Organisation, gitInfo.Name, branch, repoSettings)
+		return err
+	}
+
+	jxClient, ns, err := o.JXClientAndDevNamespace()
+	if err != nil {
+		return err
+	}
+	srList, err := jxClient.JenkinsV1().SourceRepositories(ns).List(metav1.ListOptions{})
+	if err != nil {
+		return errors.Wrapf(err, "failed to query the SourceRepository resources in namespace %s", ns)
+	}
+	branch := o.Branch
+	if branch == "" {
+		branch = opts.MasterBranch
+	}
+	failed := []string{}
+	for i := range srList.Items {
+		sr := &srList.Items[i]
+		gitURL, err := kube.GetRepositoryGitURL(sr)
+		if err != nil {
+			log.Logger().Warnf("Failed to find the git URL of SourceRepository %s: %s", sr.Name, err.Error())
+			failed = append(failed, sr.Name)
+			continue
+		}
+		provider, gitInfo, err := o.CreateGitProviderForURLWithoutKind(gitURL)
+		if err == nil {
+			_, err = gits.ApplyRepositorySettings(provider, gitInfo.Organisation, gitInfo.Name, branch, repoSettings)
+		}
+		if err != nil {
+			log.Logger().Warnf("Failed to reconcile the settings of %s: %s", util.ColorInfo(gitURL), err.Error())
+			failed = append(failed, sr.Name)
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("failed to reconcile the settings of the SourceRepositories %v", failed)
+	}
+	return nil
+}
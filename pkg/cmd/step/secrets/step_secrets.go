@@ -0,0 +1,42 @@
+package secrets
+
+import (
+	"github.com/jenkins-x/jx/v2/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts/step"
+	"github.com/spf13/cobra"
+)
+
+// StepSecretsOptions contains the command line flags
+type StepSecretsOptions struct {
+	step.StepOptions
+}
+
+// NewCmdStepSecrets Steps a command object for the "step secrets" command
+func NewCmdStepSecrets(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &StepSecretsOptions{
+		StepOptions: step.StepOptions{
+			CommonOptions: commonOpts,
+		},
+	}
+
+	cmd := &cobra.Command{
+		Use:   "secrets",
+		Short: "secrets [command]",
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+
+	cmd.AddCommand(NewCmdStepSecretsRotate(commonOpts))
+
+	return cmd
+}
+
+// Run implements this command
+func (o *StepSecretsOptions) Run() error {
+	return o.Cmd.Help()
+}
@@ -0,0 +1,439 @@
+package secrets
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jenkins-x/jx/v2/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts/step"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/update"
+	"github.com/jenkins-x/jx/v2/pkg/config"
+	iosecrets "github.com/jenkins-x/jx/v2/pkg/io/secrets"
+	"github.com/jenkins-x/jx/v2/pkg/kube"
+	"github.com/jenkins-x/jx/v2/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/secreturl"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	v1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// RotateKindHMAC the HMAC token used to validate webhooks
+	RotateKindHMAC = "hmac"
+	// RotateKindGit the git tokens of the pipeline user
+	RotateKindGit = "git"
+	// RotateKindDocker the docker registry credentials
+	RotateKindDocker = "docker"
+
+	hmacSecretPath        = "prow"
+	hmacSecretKey         = "hmacToken"
+	hmacSecretDataKey     = "hmac"
+	pipelineUserPath      = "pipelineUser"
+	pipelineUserNameKey   = "username"
+	pipelineUserTokenKey  = "token"
+	dockerSecretPath      = "docker"
+	dockerURLKey          = "url"
+	dockerUsernameKey     = "username"
+	dockerPasswordKey     = "password"
+	dockerConfigSecret    = "jenkins-docker-cfg"
+	dockerConfigSecretKey = "config.json"
+	hmacTokenLength       = 41
+)
+
+var (
+	// RotateKinds the kinds of secrets which can be rotated
+	RotateKinds = []string{RotateKindHMAC, RotateKindGit, RotateKindDocker}
+
+	hmacSecretNames = []string{"hmac-token", "lighthouse-hmac-token"}
+
+	stepSecretsRotateLong = templates.LongDesc(`
+		Rotates the secrets of Jenkins X.
+
+		The HMAC token used to validate webhooks is regenerated and stored in the secret storage, unless the secrets are
+		synced from a cloud secret manager by the External Secrets Operator in which case it is re-read. The pipeline git
+		tokens and docker registry credentials are re-read from the secret storage so they should be rotated there first.
+
+		The Kubernetes Secrets are then updated along with the webhooks of the repositories and the deployments which use
+		the secrets are restarted.
+`)
+
+	stepSecretsRotateExample = templates.Examples(`
+		# rotates all the secrets
+		jx step secrets rotate
+
+		# regenerates the HMAC token and updates the webhooks
+		jx step secrets rotate --kind hmac
+
+		# shows which secrets and deployments would be changed
+		jx step secrets rotate --dry-run
+			`)
+)
+
+// StepSecretsRotateOptions contains the command line flags
+type StepSecretsRotateOptions struct {
+	step.StepOptions
+
+	Kinds      []string
+	BasePath   string
+	NoRestart  bool
+	NoWebhooks bool
+	DryRun     bool
+}
+
+// NewCmdStepSecretsRotate Creates a new Command object
+func NewCmdStepSecretsRotate(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &StepSecretsRotateOptions{
+		StepOptions: step.StepOptions{
+			CommonOptions: commonOpts,
+		},
+	}
+
+	cmd := &cobra.Command{
+		Use:     "rotate",
+		Short:   "Rotates the webhook HMAC token, pipeline git tokens and docker registry credentials",
+		Long:    stepSecretsRotateLong,
+		Example: stepSecretsRotateExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+
+	cmd.Flags().StringArrayVarP(&options.Kinds, "kind", "k", nil, fmt.Sprintf("the kinds of secrets to rotate. Values: %s. Defaults to all of them", strings.Join(RotateKinds, ", ")))
+	cmd.Flags().StringVarP(&options.BasePath, "secret-base-path", "", "", "the path the secrets are stored under in the secret storage. Defaults to the cluster name of the requirements")
+	cmd.Flags().BoolVarP(&options.NoRestart, "no-restart", "", false, "does not restart the deployments using the rotated secrets")
+	cmd.Flags().BoolVarP(&options.NoWebhooks, "no-webhooks", "", false, "does not update the webhooks of the repositories with the new HMAC token")
+	cmd.Flags().BoolVarP(&options.DryRun, "dry-run", "", false, "only logs the secrets and deployments which would be changed")
+	return cmd
+}
+
+// Run implements this command
+func (o *StepSecretsRotateOptions) Run() error {
+	kinds := o.Kinds
+	if len(kinds) == 0 {
+		kinds = RotateKinds
+	}
+	for _, kind := range kinds {
+		if util.StringArrayIndex(RotateKinds, kind) < 0 {
+			return util.InvalidOption("kind", kind, RotateKinds)
+		}
+	}
+	kubeClient, ns, err := o.KubeClientAndDevNamespace()
+	if err != nil {
+		return errors.Wrap(err, "creating the kube client")
+	}
+
+	location := iosecrets.AutoLocationKind
+	requirements, err := o.requirements()
+	if err != nil {
+		return err
+	}
+	if requirements != nil {
+		location = iosecrets.ToSecretsLocation(string(requirements.SecretStorage))
+		if o.BasePath == "" {
+			o.BasePath = requirements.Cluster.ClusterName
+		}
+	}
+	if o.BasePath == "" {
+		return util.MissingOption("secret-base-path")
+	}
+	secretClient, err := o.GetSecretURLClient(location)
+	if err != nil {
+		return errors.Wrap(err, "creating the secret storage client")
+	}
+
+	r := &rotator{
+		kubeClient:   kubeClient,
+		ns:           ns,
+		secretClient: secretClient,
+		basePath:     o.BasePath,
+		regenerate:   location != iosecrets.ExternalSecretsLocationKind,
+		dryRun:       o.DryRun,
+	}
+	for _, kind := range kinds {
+		switch kind {
+		case RotateKindHMAC:
+			token, err := r.rotateHMAC()
+			if err != nil {
+				return errors.Wrap(err, "rotating the HMAC token")
+			}
+			if token != "" && !o.NoWebhooks && !o.DryRun {
+				err = o.updateWebhooks(token)
+				if err != nil {
+					return errors.Wrap(err, "updating the webhooks with the new HMAC token")
+				}
+			}
+		case RotateKindGit:
+			err = r.rotateGitTokens()
+			if err != nil {
+				return errors.Wrap(err, "rotating the pipeline git tokens")
+			}
+		case RotateKindDocker:
+			err = r.rotateDockerCredentials()
+			if err != nil {
+				return errors.Wrap(err, "rotating the docker registry credentials")
+			}
+		}
+	}
+
+	if o.NoRestart || len(r.updated) == 0 {
+		return nil
+	}
+	if o.DryRun {
+		log.Logger().Infof("Would restart the deployments using secrets %s", util.ColorInfo(strings.Join(r.updated, ", ")))
+		return nil
+	}
+	restarted, err := kube.RestartDeploymentsUsingSecrets(kubeClient, ns, r.updated)
+	if err != nil {
+		return err
+	}
+	for _, name := range restarted {
+		log.Logger().Infof("Restarted deployment %s", util.ColorInfo(name))
+	}
+	return nil
+}
+
+// requirements returns the requirements of the cluster from the team settings if it was installed with boot
+func (o *StepSecretsRotateOptions) requirements() (*config.RequirementsConfig, error) {
+	settings, err := o.TeamSettings()
+	if err != nil {
+		return nil, errors.Wrap(err, "loading the team settings")
+	}
+	return config.GetRequirementsConfigFromTeamSettings(settings)
+}
+
+// updateWebhooks updates the webhooks of all the repositories with the new HMAC token
+func (o *StepSecretsRotateOptions) updateWebhooks(token string) error {
+	webhooks := &update.UpdateWebhooksOptions{
+		CommonOptions:  o.CommonOptions,
+		ExactHookMatch: true,
+		HMAC:           token,
+		WarnOnFail:     true,
+	}
+	return webhooks.Run()
+}
+
+// rotator updates the Kubernetes Secrets from the secret storage, recording the names of the secrets it updated
+type rotator struct {
+	kubeClient   kubernetes.Interface
+	ns           string
+	secretClient secreturl.Client
+	basePath     string
+	regenerate   bool
+	dryRun       bool
+	updated      []string
+}
+
+// rotateHMAC regenerates the HMAC token, or re-reads it if the secret storage cannot be written, and updates the
+// Kubernetes Secrets with it. The token is returned if any secret was updated
+func (r *rotator) rotateHMAC() (string, error) {
+	path := r.path(hmacSecretPath)
+	var token string
+	var err error
+	if r.regenerate {
+		token, err = util.RandStringBytesMaskImprSrc(hmacTokenLength)
+		if err != nil {
+			return "", errors.Wrap(err, "generating the HMAC token")
+		}
+		err = r.write(path, hmacSecretKey, token)
+		if err != nil {
+			return "", err
+		}
+	} else {
+		token, err = r.read(path, hmacSecretKey)
+		if err != nil {
+			return "", err
+		}
+	}
+	updated := false
+	for _, name := range hmacSecretNames {
+		found, err := r.updateSecret(name, func(secret *v1.Secret) error {
+			secret.Data[hmacSecretDataKey] = []byte(token)
+			return nil
+		})
+		if err != nil {
+			return "", err
+		}
+		updated = updated || found
+	}
+	if !updated {
+		log.Logger().Warnf("No HMAC token secret found in namespace %s", r.ns)
+		return "", nil
+	}
+	return token, nil
+}
+
+// rotateGitTokens updates the pipeline git credentials of the pipeline user with its token from the secret storage
+func (r *rotator) rotateGitTokens() error {
+	path := r.path(pipelineUserPath)
+	username, err := r.read(path, pipelineUserNameKey)
+	if err != nil {
+		return err
+	}
+	token, err := r.read(path, pipelineUserTokenKey)
+	if err != nil {
+		return err
+	}
+	list, err := r.kubeClient.CoreV1().Secrets(r.ns).List(metav1.ListOptions{
+		LabelSelector: kube.LabelKind + "=" + kube.ValueKindGit,
+	})
+	if err != nil {
+		return errors.Wrapf(err, "listing the git secrets in namespace %s", r.ns)
+	}
+	for _, secret := range list.Items {
+		if !strings.HasPrefix(secret.Name, kube.SecretJenkinsPipelineGitCredentials) || string(secret.Data[kube.SecretDataUsername]) != username {
+			continue
+		}
+		_, err = r.updateSecret(secret.Name, func(secret *v1.Secret) error {
+			secret.Data[kube.SecretDataPassword] = []byte(token)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// rotateDockerCredentials updates the docker config used by the pipelines with the registry credentials from the
+// secret storage
+func (r *rotator) rotateDockerCredentials() error {
+	path := r.path(dockerSecretPath)
+	data, err := r.secretClient.Read(path)
+	if err != nil || len(data) == 0 {
+		log.Logger().Warnf("No docker registry credentials found at %s in the secret storage: %v", path, err)
+		return nil
+	}
+	registry, err := r.read(path, dockerURLKey)
+	if err != nil {
+		return err
+	}
+	username, err := r.read(path, dockerUsernameKey)
+	if err != nil {
+		return err
+	}
+	password, err := r.read(path, dockerPasswordKey)
+	if err != nil {
+		return err
+	}
+	host := registry
+	if i := strings.Index(host, "://"); i >= 0 {
+		host = host[i+3:]
+	}
+	host = strings.TrimSuffix(host, "/")
+
+	found, err := r.updateSecret(dockerConfigSecret, func(secret *v1.Secret) error {
+		dockerConfig := map[string]interface{}{}
+		if existing := secret.Data[dockerConfigSecretKey]; len(existing) > 0 {
+			err := json.Unmarshal(existing, &dockerConfig)
+			if err != nil {
+				return errors.Wrapf(err, "parsing the %s of secret %s", dockerConfigSecretKey, secret.Name)
+			}
+		}
+		auths, _ := dockerConfig["auths"].(map[string]interface{})
+		if auths == nil {
+			auths = map[string]interface{}{}
+		}
+		auth, _ := auths[host].(map[string]interface{})
+		if auth == nil {
+			auth = map[string]interface{}{}
+		}
+		auth["auth"] = base64.StdEncoding.EncodeToString([]byte(username + ":" + password))
+		auths[host] = auth
+		dockerConfig["auths"] = auths
+		value, err := json.Marshal(dockerConfig)
+		if err != nil {
+			return errors.Wrapf(err, "marshalling the %s of secret %s", dockerConfigSecretKey, secret.Name)
+		}
+		secret.Data[dockerConfigSecretKey] = value
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if !found {
+		log.Logger().Warnf("No docker config secret %s found in namespace %s", dockerConfigSecret, r.ns)
+	}
+	return nil
+}
+
+// updateSecret modifies the Kubernetes Secret if it exists, returning false if it does not
+func (r *rotator) updateSecret(name string, fn func(secret *v1.Secret) error) (bool, error) {
+	secret, err := r.kubeClient.CoreV1().Secrets(r.ns).Get(name, metav1.GetOptions{})
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, errors.Wrapf(err, "getting secret %s in namespace %s", name, r.ns)
+	}
+	if secret.Data == nil {
+		secret.Data = map[string][]byte{}
+	}
+	err = fn(secret)
+	if err != nil {
+		return true, err
+	}
+	if secret.Annotations == nil {
+		secret.Annotations = map[string]string{}
+	}
+	secret.Annotations[kube.AnnotationRotatedAt] = time.Now().Format(time.RFC3339)
+	if r.dryRun {
+		log.Logger().Infof("Would update secret %s", util.ColorInfo(name))
+	} else {
+		_, err = r.kubeClient.CoreV1().Secrets(r.ns).Update(secret)
+		if err != nil {
+			return true, errors.Wrapf(err, "updating secret %s in namespace %s", name, r.ns)
+		}
+		log.Logger().Infof("Updated secret %s", util.ColorInfo(name))
+	}
+	if util.StringArrayIndex(r.updated, name) < 0 {
+		r.updated = append(r.updated, name)
+	}
+	return true, nil
+}
+
+// read reads the value of the key of the secret at path in the secret storage
+func (r *rotator) read(path string, key string) (string, error) {
+	data, err := r.secretClient.Read(path)
+	if err != nil {
+		return "", errors.Wrapf(err, "reading %s from the secret storage", path)
+	}
+	value, ok := data[key]
+	if !ok {
+		return "", errors.Errorf("no %s found in %s in the secret storage", key, path)
+	}
+	return util.AsString(value)
+}
+
+// write stores the value of the key of the secret at path in the secret storage
+func (r *rotator) write(path string, key string, value string) error {
+	if r.dryRun {
+		log.Logger().Infof("Would store a new %s at %s in the secret storage", util.ColorInfo(key), util.ColorInfo(path))
+		return nil
+	}
+	data, _ := r.secretClient.Read(path)
+	if data == nil {
+		data = map[string]interface{}{}
+	}
+	data[key] = value
+	_, err := r.secretClient.Write(path, data)
+	if err != nil {
+		return errors.Wrapf(err, "writing %s to the secret storage", path)
+	}
+	return nil
+}
+
+func (r *rotator) path(name string) string {
+	return r.basePath + "/" + name
+}
@@ -0,0 +1,107 @@
+// +build unit
+
+package secrets
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+
+	"github.com/jenkins-x/jx/v2/pkg/kube"
+	"github.com/jenkins-x/jx/v2/pkg/secreturl/fakevault"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func secret(name string, labels map[string]string, data map[string]string) *v1.Secret {
+	answer := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "jx",
+			Labels:    labels,
+		},
+		Data: map[string][]byte{},
+	}
+	for k, v := range data {
+		answer.Data[k] = []byte(v)
+	}
+	return answer
+}
+
+func TestRotateSecrets(t *testing.T) {
+	t.Parallel()
+
+	gitLabels := map[string]string{kube.LabelKind: kube.ValueKindGit}
+	kubeClient := fake.NewSimpleClientset(
+		secret("lighthouse-hmac-token", nil, map[string]string{"hmac": "old"}),
+		secret("jx-pipeline-git-github-github", gitLabels, map[string]string{"username": "jx-bot", "password": "old"}),
+		secret("jx-pipeline-git-github-other", gitLabels, map[string]string{"username": "someone", "password": "theirs"}),
+		secret("jenkins-docker-cfg", nil, map[string]string{"config.json": `{"auths":{}}`}),
+	)
+	secretClient := fakevault.NewFakeClient()
+	_, err := secretClient.Write("mycluster/pipelineUser", map[string]interface{}{"username": "jx-bot", "token": "new-token"})
+	require.NoError(t, err)
+	_, err = secretClient.Write("mycluster/docker", map[string]interface{}{"url": "https://registry.acme.com/", "username": "bot", "password": "new-password"})
+	require.NoError(t, err)
+
+	r := &rotator{
+		kubeClient:   kubeClient,
+		ns:           "jx",
+		secretClient: secretClient,
+		basePath:     "mycluster",
+		regenerate:   true,
+	}
+
+	token, err := r.rotateHMAC()
+	require.NoError(t, err)
+	assert.Len(t, token, hmacTokenLength)
+	stored, err := r.read("mycluster/prow", "hmacToken")
+	require.NoError(t, err)
+	assert.Equal(t, token, stored)
+
+	require.NoError(t, r.rotateGitTokens())
+	require.NoError(t, r.rotateDockerCredentials())
+	assert.Equal(t, []string{"lighthouse-hmac-token", "jx-pipeline-git-github-github", "jenkins-docker-cfg"}, r.updated)
+
+	get := func(name string) *v1.Secret {
+		answer, err := kubeClient.CoreV1().Secrets("jx").Get(name, metav1.GetOptions{})
+		require.NoError(t, err)
+		return answer
+	}
+	assert.Equal(t, token, string(get("lighthouse-hmac-token").Data["hmac"]))
+	assert.NotEmpty(t, get("lighthouse-hmac-token").Annotations[kube.AnnotationRotatedAt])
+	assert.Equal(t, "new-token", string(get("jx-pipeline-git-github-github").Data["password"]))
+	assert.Equal(t, "theirs", string(get("jx-pipeline-git-github-other").Data["password"]))
+
+	dockerConfig := map[string]map[string]map[string]string{}
+	err = json.Unmarshal(get("jenkins-docker-cfg").Data["config.json"], &dockerConfig)
+	require.NoError(t, err)
+	assert.Equal(t, base64.StdEncoding.EncodeToString([]byte("bot:new-password")), dockerConfig["auths"]["registry.acme.com"]["auth"])
+}
+
+func TestRotateHMACRereadsWhenSecretStorageIsReadOnly(t *testing.T) {
+	t.Parallel()
+
+	kubeClient := fake.NewSimpleClientset(secret("hmac-token", nil, map[string]string{"hmac": "old"}))
+	secretClient := fakevault.NewFakeClient()
+	_, err := secretClient.Write("mycluster/prow", map[string]interface{}{"hmacToken": "rotated-in-the-cloud"})
+	require.NoError(t, err)
+
+	r := &rotator{
+		kubeClient:   kubeClient,
+		ns:           "jx",
+		secretClient: secretClient,
+		basePath:     "mycluster",
+		dryRun:       true,
+	}
+	token, err := r.rotateHMAC()
+	require.NoError(t, err)
+	assert.Equal(t, "rotated-in-the-cloud", token)
+
+	unchanged, err := kubeClient.CoreV1().Secrets("jx").Get("hmac-token", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "old", string(unchanged.Data["hmac"]), "dry runs should not update secrets")
+}
@@ -0,0 +1,128 @@
+package step
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/jenkins-x/jx/v2/pkg/buildpacks"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts/step"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/v2/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/yaml"
+)
+
+// StepDetectOptions contains the command line flags
+type StepDetectOptions struct {
+	step.StepOptions
+
+	Dir      string
+	PacksDir string
+	Output   string
+}
+
+var (
+	stepDetectLong = templates.LongDesc(`
+		This pipeline step shows which build pack 'jx import' chooses for a repository and why.
+
+		The build packs are scored against the build tools, languages, Dockerfile, charts and Jenkinsfile of the
+		repository, including the build tools of its subdirectories, and the best match is chosen. A repository can
+		choose its build pack, the version of the build packs and the image of its build containers in a
+		'` + buildpacks.BuildPackOverrideFileName + `' file such as:
+
+		    pack: go
+		    version: v1.2.3
+		    image: gcr.io/jenkinsxio/builder-go:2.1.150
+`)
+
+	stepDetectExample = templates.Examples(`
+		# shows the build pack chosen for the current directory
+		jx step detect
+
+		# shows the detection of another directory as YAML
+		jx step detect --dir ../myapp -o yaml
+`)
+)
+
+// NewCmdStepDetect creates the command
+func NewCmdStepDetect(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := StepDetectOptions{
+		StepOptions: step.StepOptions{
+			CommonOptions: commonOpts,
+		},
+	}
+	cmd := &cobra.Command{
+		Use:     "detect",
+		Short:   "Shows the build pack chosen for a repository and why",
+		Long:    stepDetectLong,
+		Example: stepDetectExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+	cmd.Flags().StringVarP(&options.Dir, "dir", "d", ".", "The directory of the repository")
+	cmd.Flags().StringVarP(&options.PacksDir, "packs-dir", "", "", "The directory of the build packs. Defaults to the build packs of the team")
+	cmd.Flags().StringVarP(&options.Output, "output", "o", "", "The format of the output. Use 'yaml' to show the detection as YAML")
+	return cmd
+}
+
+// Run implements the command
+func (o *StepDetectOptions) Run() error {
+	packsDir := o.PacksDir
+	if packsDir == "" {
+		var err error
+		packsDir, _, err = o.InitBuildPacks(&opts.InvokeDraftPack{Dir: o.Dir})
+		if err != nil {
+			return errors.Wrap(err, "initialising the build packs of the team")
+		}
+	}
+	detection, err := buildpacks.DetectBuildPack(o.Dir, packsDir)
+	if err != nil {
+		return errors.Wrapf(err, "detecting the build pack of %s", o.Dir)
+	}
+
+	switch o.Output {
+	case "":
+	case "yaml":
+		data, err := yaml.Marshal(detection)
+		if err != nil {
+			return errors.Wrap(err, "marshalling the detection to YAML")
+		}
+		_, err = fmt.Fprint(o.Out, string(data))
+		return err
+	default:
+		return util.InvalidOption("output", o.Output, []string{"yaml"})
+	}
+
+	table := o.CreateTable()
+	table.AddRow("PACK", "SCORE", "REASONS")
+	for _, c := range detection.Candidates {
+		table.AddRow(c.Pack, strconv.Itoa(c.Score), strings.Join(c.Reasons, ", "))
+	}
+	table.Render()
+
+	if detection.Override != nil && detection.Override.Pack != "" && detection.Override.Pack == detection.Pack {
+		log.Logger().Infof("\nselected build pack %s from %s", util.ColorInfo(detection.Pack), buildpacks.BuildPackOverrideFileName)
+	} else if detection.Pack != "" {
+		log.Logger().Infof("\nselected build pack %s", util.ColorInfo(detection.Pack))
+	} else {
+		log.Logger().Warnf("\nno build pack matches %s", o.Dir)
+	}
+	if detection.Override != nil {
+		if detection.Override.Version != "" {
+			log.Logger().Infof("using version %s of the build packs", util.ColorInfo(detection.Override.Version))
+		}
+		if detection.Override.Image != "" {
+			log.Logger().Infof("using the build image %s", util.ColorInfo(detection.Override.Image))
+		}
+	}
+	return nil
+}
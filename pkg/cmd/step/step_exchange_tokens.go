@@ -0,0 +1,125 @@
+package step
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/jenkins-x/jx/v2/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts/step"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/v2/pkg/kube"
+	"github.com/jenkins-x/jx/v2/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/tokenexchange"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// StepExchangeTokensOptions contains the command line arguments for this command
+type StepExchangeTokensOptions struct {
+	step.StepOptions
+
+	URL                string
+	DevNamespace       string
+	Kinds              []string
+	GitCredentialsFile string
+	DockerConfigFile   string
+}
+
+var (
+	stepExchangeTokensLong = templates.LongDesc(`
+		Exchanges the service account token of a pod in a preview environment for short lived git and container
+		registry tokens minted by the token exchange, so the long lived pipeline secrets do not need to be copied into
+		the preview namespace.
+
+		The git tokens are written to the git credentials file and the registry tokens to the docker config file.
+`)
+
+	stepExchangeTokensExample = templates.Examples(`
+		# get git and registry tokens for the preview
+		jx step exchange-tokens
+
+		# only get a git token
+		jx step exchange-tokens --kind git
+`)
+)
+
+// NewCmdStepExchangeTokens creates the command
+func NewCmdStepExchangeTokens(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := StepExchangeTokensOptions{
+		StepOptions: step.StepOptions{
+			CommonOptions: commonOpts,
+		},
+	}
+	cmd := &cobra.Command{
+		Use:     "exchange-tokens",
+		Short:   "Exchanges the service account token of a preview environment for short lived git and registry tokens",
+		Long:    stepExchangeTokensLong,
+		Example: stepExchangeTokensExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+	cmd.Flags().StringVarP(&options.URL, "url", "", "", "The URL of the token exchange. Defaults to $"+tokenexchange.URLEnvVar+" or the token exchange service in the development namespace")
+	cmd.Flags().StringVarP(&options.DevNamespace, "dev-namespace", "", kube.DefaultNamespace, "The development namespace the token exchange runs in")
+	cmd.Flags().StringArrayVarP(&options.Kinds, "kind", "k", []string{tokenexchange.KindGit, tokenexchange.KindRegistry}, "The kinds of tokens to request")
+	cmd.Flags().StringVarP(&options.GitCredentialsFile, "git-credentials-file", "", "", "The git credentials file to write the git tokens to. Defaults to $XDG_CONFIG_HOME/git/credentials")
+	cmd.Flags().StringVarP(&options.DockerConfigFile, "docker-config-file", "", "", "The docker config file to write the registry tokens to. Defaults to $DOCKER_CONFIG/config.json")
+	return cmd
+}
+
+// Run runs the command
+func (o *StepExchangeTokensOptions) Run() error {
+	exchangeURL := o.URL
+	if exchangeURL == "" {
+		exchangeURL = os.Getenv(tokenexchange.URLEnvVar)
+	}
+	if exchangeURL == "" {
+		exchangeURL = tokenexchange.DefaultURL(o.DevNamespace)
+	}
+	tokens, err := tokenexchange.RequestTokensWithServiceAccount(exchangeURL, o.Kinds)
+	if err != nil {
+		return err
+	}
+
+	gitCredentials, err := tokenexchange.GitCredentialsData(tokens)
+	if err != nil {
+		return err
+	}
+	if len(gitCredentials) > 0 {
+		fileName := o.GitCredentialsFile
+		if fileName == "" {
+			fileName = util.GitCredentialsFile()
+		}
+		err = os.MkdirAll(filepath.Dir(fileName), util.DefaultWritePermissions)
+		if err != nil {
+			return errors.Wrapf(err, "creating the directory of %s", fileName)
+		}
+		err = ioutil.WriteFile(fileName, gitCredentials, 0600)
+		if err != nil {
+			return errors.Wrapf(err, "writing %s", fileName)
+		}
+		log.Logger().Infof("Wrote the git tokens to %s", util.ColorInfo(fileName))
+	}
+
+	for _, token := range tokens {
+		if token.Kind == tokenexchange.KindRegistry {
+			fileName := o.DockerConfigFile
+			if fileName == "" {
+				fileName = tokenexchange.DockerConfigFile()
+			}
+			err = tokenexchange.WriteDockerConfig(fileName, tokens)
+			if err != nil {
+				return errors.Wrapf(err, "writing the registry tokens to %s", fileName)
+			}
+			log.Logger().Infof("Wrote the registry tokens to %s", util.ColorInfo(fileName))
+			break
+		}
+	}
+	return nil
+}
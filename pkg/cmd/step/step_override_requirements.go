@@ -41,7 +41,7 @@ func NewCmdStepOverrideRequirements(commonOpts *opts.CommonOptions) *cobra.Comma
 
 // Run implements this command
 func (o *StepOverrideRequirementsOptions) Run() error {
-	requirements, requirementsFileName, err := config.LoadRequirementsConfig(o.Dir, config.DefaultFailOnValidationError)
+	requirements, requirementsFileName, err := config.LoadRequirementsConfigWithOptions(o.Dir, config.DefaultFailOnValidationError, o.RequirementsOptions())
 	if err != nil {
 		return err
 	}
@@ -63,7 +63,7 @@ func (o *StepOverrideRequirementsOptions) overrideRequirements(requirements *con
 	})
 
 	log.Logger().Debugf("saving %s", requirementsFileName)
-	err := requirements.SaveConfig(requirementsFileName)
+	err := requirements.SaveConfigWithOptions(requirementsFileName, o.RequirementsOptions())
 	if err != nil {
 		return nil, errors.Wrapf(err, "save config %s", requirementsFileName)
 	}
@@ -0,0 +1,90 @@
+package step
+
+import (
+	"github.com/jenkins-x/jx/v2/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts/step"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/v2/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+const (
+	// DefaultSBOMFile the default file the SBOM of an image is written to
+	DefaultSBOMFile = "sbom.spdx.json"
+
+	defaultSBOMFormat = "spdx-json"
+)
+
+// StepSyftOptions contains the command line flags
+type StepSyftOptions struct {
+	step.StepOptions
+
+	Image      string
+	OutputFile string
+	Format     string
+}
+
+var (
+	stepSyftLong = templates.LongDesc(`
+		This pipeline step generates the software bill of materials (SBOM) of an image using syft.
+
+		The SBOM can then be attested when signing the image via 'jx step cosign sign --sbom'
+`)
+
+	stepSyftExample = templates.Examples(`
+		# generate the SBOM of an image
+		jx step syft --image gcr.io/myproject/myapp:1.2.3
+
+		# generate a CycloneDX SBOM
+		jx step syft --image gcr.io/myproject/myapp:1.2.3 --format cyclonedx-json --output-file sbom.cdx.json
+`)
+)
+
+// NewCmdStepSyft creates the command
+func NewCmdStepSyft(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := StepSyftOptions{
+		StepOptions: step.StepOptions{
+			CommonOptions: commonOpts,
+		},
+	}
+	cmd := &cobra.Command{
+		Use:     "syft",
+		Short:   "Generates the SBOM of an image",
+		Long:    stepSyftLong,
+		Example: stepSyftExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+	cmd.Flags().StringVarP(&options.Image, "image", "i", "", "The image to generate the SBOM of")
+	cmd.Flags().StringVarP(&options.OutputFile, "output-file", "o", DefaultSBOMFile, "The file the SBOM is written to")
+	cmd.Flags().StringVarP(&options.Format, "format", "f", defaultSBOMFormat, "The format of the SBOM such as spdx-json or cyclonedx-json")
+	return cmd
+}
+
+// Run implements the command
+func (o *StepSyftOptions) Run() error {
+	if o.Image == "" {
+		return util.MissingOption("image")
+	}
+	err := o.InstallSyft()
+	if err != nil {
+		return errors.Wrap(err, "installing syft")
+	}
+	cmd := util.Command{
+		Name: "syft",
+		Args: []string{"packages", o.Image, "--output", o.Format, "--file", o.OutputFile},
+	}
+	_, err = cmd.RunWithoutRetry()
+	if err != nil {
+		return errors.Wrapf(err, "generating the SBOM of image %s", o.Image)
+	}
+	log.Logger().Infof("generated the SBOM of image %s in %s", util.ColorInfo(o.Image), util.ColorInfo(o.OutputFile))
+	return nil
+}
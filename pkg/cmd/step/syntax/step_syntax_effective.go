@@ -42,6 +42,9 @@ type StepSyntaxEffectiveOptions struct {
 	DefaultImage      string
 	UseKaniko         bool
 	KanikoImage       string
+	Platforms         []string
+	GenerateSBOM      bool
+	SignImages        bool
 	ProjectID         string
 	DockerRegistry    string
 	DockerRegistryOrg string
@@ -114,6 +117,9 @@ func (o *StepSyntaxEffectiveOptions) addFlags(cmd *cobra.Command) {
 	cmd.Flags().BoolVarP(&o.UseKaniko, "use-kaniko", "", true, "Enables using kaniko directly for building docker images")
 	cmd.Flags().BoolVarP(&o.ShortView, "short", "s", false, "Use short concise output")
 	cmd.Flags().StringVarP(&o.KanikoImage, "kaniko-image", "", syntax.KanikoDockerImage, "The docker image for Kaniko")
+	cmd.Flags().StringArrayVarP(&o.Platforms, "platform", "", nil, "The platforms, such as linux/arm64, kaniko builds images for. Multiple platforms build a multi-arch image")
+	cmd.Flags().BoolVarP(&o.GenerateSBOM, "sbom", "", false, "Generates the SBOMs of the images kaniko builds in release pipelines")
+	cmd.Flags().BoolVarP(&o.SignImages, "sign", "", false, "Signs the images kaniko builds in release pipelines")
 	cmd.Flags().StringVarP(&o.ProjectID, "project-id", "", "", "The cloud project ID. If not specified we default to the install project")
 	cmd.Flags().StringVarP(&o.DockerRegistry, "docker-registry", "", "", "The Docker Registry host name to use which is added as a prefix to docker images")
 	cmd.Flags().StringVarP(&o.DockerRegistryOrg, "docker-registry-org", "", "", "The Docker registry organisation. If blank the git repository owner is used")
@@ -160,6 +166,11 @@ func (o *StepSyntaxEffectiveOptions) Run() error {
 	if err != nil {
 		return err
 	}
+	for _, platform := range o.Platforms {
+		if err := syntax.ValidatePlatform(platform); err != nil {
+			return util.InvalidOptionf("platform", platform, err.Error())
+		}
+	}
 	if o.Verbose {
 		log.Logger().Info("setting up docker registry\n")
 	}
@@ -324,7 +335,19 @@ func (o *StepSyntaxEffectiveOptions) CreateEffectivePipeline(packsDir string, pr
 		return nil, fmt.Errorf("failed to find PipelineConfig in file %s", projectConfigFile)
 	}
 
-	err := o.combineEnvVars(pipelineConfig)
+	if len(pipelineConfig.Imports) > 0 {
+		var err error
+		resolver, err = gitresolver.CreateImportsResolver(pipelineConfig.Imports, o.Git(), o.VersionResolver, resolver)
+		if err != nil {
+			return nil, err
+		}
+	}
+	err := pipelineConfig.ResolveIncludes(resolver)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to resolve the includes of the pipeline in file %s", projectConfigFile)
+	}
+
+	err = o.combineEnvVars(pipelineConfig)
 	if err != nil {
 		return nil, errors.Wrapf(err, "failed to combine env vars")
 	}
@@ -437,6 +460,10 @@ func (o *StepSyntaxEffectiveOptions) createPipelineForKind(kind string, lifecycl
 		DockerRegistryOrg: o.GetDockerRegistryOrg(projectConfig, o.GitInfo),
 		KanikoImage:       o.KanikoImage,
 		UseKaniko:         o.UseKaniko,
+		Platforms:         o.Platforms,
+		// only releases are signed as the images of pull requests are not promoted
+		GenerateSBOM: o.GenerateSBOM && kind == jenkinsfile.PipelineKindRelease,
+		SignImages:   o.SignImages && kind == jenkinsfile.PipelineKindRelease,
 	}
 	parsed.ReplacePlaceholdersInStepAndStageDirs(replacePlaceholderArgs)
 	parsed.AddContainerEnvVarsToPipeline(pipelineConfig.Env)
@@ -451,6 +478,7 @@ func (o *StepSyntaxEffectiveOptions) createPipelineForKind(kind string, lifecycl
 		}
 		parsed.Options.ContainerOptions = mergedContainer
 	}
+	parsed.AddSecrets(pipelineConfig.Secrets)
 
 	for _, override := range pipelines.Overrides {
 		if override.MatchesPipeline(kind) {
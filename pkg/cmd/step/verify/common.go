@@ -1,7 +1,11 @@
 package verify
 
 import (
+	"github.com/jenkins-x/jx/v2/pkg/cloud"
+	"github.com/jenkins-x/jx/v2/pkg/cloud/identity"
+	"github.com/jenkins-x/jx/v2/pkg/config"
 	"github.com/jenkins-x/jx/v2/pkg/kube"
+	"github.com/jenkins-x/jx/v2/pkg/kube/cluster"
 	"github.com/jenkins-x/jx/v2/pkg/log"
 	"github.com/jenkins-x/jx/v2/pkg/util"
 	"github.com/pkg/errors"
@@ -56,3 +60,65 @@ func (o *StepVerifyOptions) createSecret(ns string, name, key, data string) erro
 	return nil
 
 }
+
+// configureWorkloadIdentities creates the cloud identities of the service accounts using workload identity and binds
+// the service accounts to them, so that no static keys are stored in secrets
+func (o *StepVerifyOptions) configureWorkloadIdentities(requirements *config.RequirementsConfig, ns string) error {
+	kubeClient, _, err := o.KubeClientAndDevNamespace()
+	if err != nil {
+		return err
+	}
+	provider := requirements.Cluster.Provider
+	projectID := requirements.Cluster.ProjectID
+	for _, binding := range identity.Bindings(requirements, ns) {
+		// the cloud identities are only modified from outside of the cluster as pipelines do not have the permissions
+		if provider == cloud.GKE && !cluster.IsInCluster() {
+			log.Logger().Infof("Configuring the workload identity %s of service account %s in namespace %s", util.ColorInfo(binding.Identity), util.ColorInfo(binding.ServiceAccount), util.ColorInfo(binding.Namespace))
+			err = o.GCloud().EnsureServiceAccount(binding.CloudServiceAccount, projectID, binding.Roles)
+			if err != nil {
+				return errors.Wrapf(err, "creating the service account %s", binding.CloudServiceAccount)
+			}
+			if binding.ServiceAccount == identity.VeleroServiceAccount && requirements.Storage.Backup.URL != "" {
+				err = o.GCloud().ConfigureBucketRoles(projectID, binding.CloudServiceAccount, requirements.Storage.Backup.URL, binding.Roles)
+				if err != nil {
+					return errors.Wrap(err, "associate the IAM roles to the bucket")
+				}
+			}
+			err = o.GCloud().BindWorkloadIdentity(binding.CloudServiceAccount, projectID, binding.Namespace, binding.ServiceAccount)
+			if err != nil {
+				return err
+			}
+		}
+		if binding.Identity == "" {
+			// the identities are bound by the provider tooling such as the eksctl IRSA configuration
+			continue
+		}
+		err = kube.EnsureNamespaceCreated(kubeClient, binding.Namespace, map[string]string{
+			kube.LabelCreatedBy: "jx-boot",
+		}, nil)
+		if err != nil {
+			return err
+		}
+		err = identity.Configure(kubeClient, provider, binding)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateWorkloadIdentities verifies the service accounts using workload identity are bound to their cloud identities
+func (o *StepVerifyOptions) validateWorkloadIdentities(requirements *config.RequirementsConfig, provider string, ns string) error {
+	kubeClient, _, err := o.KubeClientAndDevNamespace()
+	if err != nil {
+		return err
+	}
+	for _, binding := range identity.Bindings(requirements, ns) {
+		err = identity.Verify(kubeClient, provider, binding)
+		if err != nil {
+			return errors.Wrap(err, "verifying the workload identity")
+		}
+		log.Logger().Infof("service account %s in namespace %s uses workload identity: %s", util.ColorInfo(binding.ServiceAccount), util.ColorInfo(binding.Namespace), util.ColorInfo("OK"))
+	}
+	return nil
+}
@@ -0,0 +1,178 @@
+package verify
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	v1 "github.com/jenkins-x/jx/v2/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts/step"
+	helm_cmd "github.com/jenkins-x/jx/v2/pkg/cmd/step/helm"
+	"github.com/jenkins-x/jx/v2/pkg/environments"
+	"github.com/jenkins-x/jx/v2/pkg/helm"
+	"github.com/jenkins-x/jx/v2/pkg/kube"
+	"github.com/jenkins-x/jx/v2/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+)
+
+// verifyDrift compares the charts of the permanent environments at the versions committed to their git repositories
+// with the resources in their clusters, reporting the resources changed outside of GitOps and reverting them if
+// requested
+func (o *StepVerifyEnvironmentsOptions) verifyDrift() error {
+	jxClient, ns, err := o.JXClientAndDevNamespace()
+	if err != nil {
+		return err
+	}
+	envMap, names, err := kube.GetEnvironments(jxClient, ns)
+	if err != nil {
+		return errors.Wrapf(err, "failed to load Environments in namespace %s", ns)
+	}
+	if o.DriftEnvironment != "" && envMap[o.DriftEnvironment] == nil {
+		return util.InvalidOption("env", o.DriftEnvironment, names)
+	}
+	drifted := []string{}
+	for _, name := range names {
+		env := envMap[name]
+		if env.Spec.Kind != v1.EnvironmentKindTypePermanent || env.Spec.Source.URL == "" {
+			continue
+		}
+		if o.DriftEnvironment != "" && name != o.DriftEnvironment {
+			continue
+		}
+		resources, err := o.environmentDrift(env)
+		if err != nil {
+			return errors.Wrapf(err, "detecting the drift of environment %s", name)
+		}
+		if len(resources) == 0 {
+			log.Logger().Infof("Environment %s matches its git repository", util.ColorInfo(name))
+			continue
+		}
+		log.Logger().Warnf("Environment %s has %d resources changed outside of its git repository:", name, len(resources))
+		for _, r := range resources {
+			log.Logger().Warnf("  %s", r.String())
+			log.Logger().Debugf("%s", r.Diff)
+		}
+		if !o.Revert {
+			drifted = append(drifted, name)
+		}
+	}
+	if len(drifted) > 0 {
+		return fmt.Errorf("the environments %s drifted from their git repositories, use --revert to revert them", strings.Join(drifted, ", "))
+	}
+	return nil
+}
+
+// environmentDrift renders the chart of the environment from its git repository and returns the resources which
+// differ in the cluster, reverting them if requested
+func (o *StepVerifyEnvironmentsOptions) environmentDrift(env *v1.Environment) ([]*environments.DriftedResource, error) {
+	tmpDir, err := ioutil.TempDir("", "jx-env-drift-")
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create a temporary directory")
+	}
+	defer os.RemoveAll(tmpDir) //nolint:errcheck
+
+	gitURL := env.Spec.Source.URL
+	branch := env.Spec.Source.Ref
+	if branch == "" {
+		branch = "master"
+	}
+	cloneURL, err := o.GitCloneURL(gitURL)
+	if err != nil {
+		return nil, err
+	}
+	dir := filepath.Join(tmpDir, "source")
+	err = o.Git().ShallowCloneBranch(cloneURL, branch, dir)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cloning branch %s of %s", branch, gitURL)
+	}
+
+	ns := env.Spec.Namespace
+	kubeArgs := []string{}
+	cluster, err := o.RemoteClusterForEnvironment(env)
+	if err != nil {
+		log.Logger().Debugf("failed to find the remote cluster of environment %s: %s", env.Name, err)
+	}
+	if cluster != nil && cluster.Context != "" {
+		kubeArgs = append(kubeArgs, "--context", cluster.Context)
+		if cluster.Namespace != "" {
+			ns = cluster.Namespace
+		}
+	}
+	kubeArgs = append(kubeArgs, "--namespace", ns)
+
+	outputDir := filepath.Join(tmpDir, "output")
+	err = o.renderEnvironment(dir, ns, outputDir)
+	if err != nil {
+		return nil, errors.Wrapf(err, "rendering the chart of %s", gitURL)
+	}
+
+	args := append([]string{"diff", "--recursive", "-f", outputDir}, kubeArgs...)
+	output, err := kubectlDiff(args)
+	if err != nil {
+		return nil, err
+	}
+	resources := environments.ParseKubectlDiff(output)
+	if len(resources) == 0 || !o.Revert {
+		return resources, nil
+	}
+
+	log.Logger().Infof("Reverting the %d drifted resources of environment %s to its git repository", len(resources), util.ColorInfo(env.Name))
+	args = append([]string{"apply", "--recursive", "-f", outputDir}, kubeArgs...)
+	cmd := util.Command{
+		Name: "kubectl",
+		Args: args,
+	}
+	output, err = cmd.RunWithoutRetry()
+	if err != nil {
+		return resources, errors.Wrapf(err, "running kubectl %s", strings.Join(args, " "))
+	}
+	log.Logger().Debug(output)
+	return resources, nil
+}
+
+// renderEnvironment renders the environment chart in dir into outputDir with the values and release name which
+// `jx step helm apply` applies it with in the environment pipeline, removing the Secrets so that their values are not
+// logged along with the resources of helm hooks which are not kept in the cluster
+func (o *StepVerifyEnvironmentsOptions) renderEnvironment(dir string, ns string, outputDir string) error {
+	chartFile, err := helm.FindChartFileName(dir)
+	if err != nil {
+		return err
+	}
+	stepApply := &helm_cmd.StepHelmApplyOptions{
+		StepHelmOptions: helm_cmd.StepHelmOptions{
+			StepOptions: step.StepOptions{
+				CommonOptions: o.CommonOptions,
+			},
+			Dir: filepath.Dir(chartFile),
+		},
+		Namespace:          ns,
+		DisableHelmVersion: true,
+		TemplateDir:        outputDir,
+	}
+	err = stepApply.Run()
+	if err != nil {
+		return err
+	}
+	return environments.RemoveUnmanagedResources(outputDir)
+}
+
+// kubectlDiff runs kubectl diff which dry runs applying the resources on the server, returning the differences
+func kubectlDiff(args []string) (string, error) {
+	cmd := util.Command{
+		Name: "kubectl",
+		Args: args,
+	}
+	output, err := cmd.RunWithoutRetry()
+	if err != nil {
+		// kubectl diff exits with 1 when there are changes
+		if exitErr, ok := errors.Cause(err).(*exec.ExitError); ok && exitErr.ExitCode() == 1 {
+			return output, nil
+		}
+		return "", errors.Wrapf(err, "running kubectl %s", strings.Join(args, " "))
+	}
+	return output, nil
+}
@@ -22,6 +22,7 @@ import (
 	"github.com/jenkins-x/jx/v2/pkg/gits"
 	"github.com/jenkins-x/jx/v2/pkg/kube"
 	"github.com/jenkins-x/jx/v2/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/prow"
 	"github.com/jenkins-x/jx/v2/pkg/util"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
@@ -38,7 +39,10 @@ const (
 // StepVerifyEnvironmentsOptions contains the command line flags
 type StepVerifyEnvironmentsOptions struct {
 	StepVerifyOptions
-	Dir string
+	Dir              string
+	Drift            bool
+	Revert           bool
+	DriftEnvironment string
 }
 
 // NewCmdStepVerifyEnvironments creates the `jx step verify pod` command
@@ -63,17 +67,23 @@ func NewCmdStepVerifyEnvironments(commonOpts *opts.CommonOptions) *cobra.Command
 		},
 	}
 	cmd.Flags().StringVarP(&options.Dir, "dir", "d", "", fmt.Sprintf("The directory to look for the %s file, by default the current working directory", config.RequirementsConfigFileName))
+	cmd.Flags().BoolVarP(&options.Drift, "drift", "", false, "Reports the resources of the permanent environments which were changed in the cluster outside of their git repositories rather than verifying the git repositories. Secrets are not compared")
+	cmd.Flags().BoolVarP(&options.Revert, "revert", "", false, "Reverts the resources which drifted from the git repositories when used with --drift")
+	cmd.Flags().StringVarP(&options.DriftEnvironment, "env", "e", "", "The environment to check for drift, defaults to all permanent environments")
 	return cmd
 }
 
 // Run implements this command
 func (o *StepVerifyEnvironmentsOptions) Run() error {
+	if o.Drift {
+		return o.verifyDrift()
+	}
 	jxClient, ns, err := o.JXClientAndDevNamespace()
 	if err != nil {
 		return err
 	}
 
-	requirements, requirementsFileName, err := config.LoadRequirementsConfig(o.Dir, config.DefaultFailOnValidationError)
+	requirements, requirementsFileName, err := config.LoadRequirementsConfigWithOptions(o.Dir, config.DefaultFailOnValidationError, o.RequirementsOptions())
 	if err != nil {
 		return err
 	}
@@ -108,6 +118,10 @@ func (o *StepVerifyEnvironmentsOptions) Run() error {
 
 	for _, name := range names {
 		env := envMap[name]
+		err = o.updateRemoteClusterGitURL(requirements, env)
+		if err != nil {
+			return err
+		}
 		gitURL := env.Spec.Source.URL
 		if gitURL != "" && (env.Spec.Kind == v1.EnvironmentKindTypePermanent || (env.Spec.Kind == v1.EnvironmentKindTypeDevelopment && requirements.GitOps)) {
 			log.Logger().Infof("Validating git repository for %s environment at URL %s\n", info(name), info(gitURL))
@@ -122,11 +136,46 @@ func (o *StepVerifyEnvironmentsOptions) Run() error {
 		}
 	}
 
+	err = o.applyGovernance(requirements, envMap, names)
+	if err != nil {
+		return err
+	}
+	err = o.enableMeshInjection(requirements, envMap, names)
+	if err != nil {
+		return err
+	}
+
 	log.Logger().Infof("Environment git repositories look good\n")
 	fmt.Println()
 	return nil
 }
 
+// applyGovernance applies the governance policies from the requirements to the namespace of each environment
+// running in this cluster
+func (o *StepVerifyEnvironmentsOptions) applyGovernance(requirements *config.RequirementsConfig, envMap map[string]*v1.Environment, names []string) error {
+	if requirements.Governance == nil {
+		return nil
+	}
+	kubeClient, err := o.KubeClient()
+	if err != nil {
+		return errors.Wrap(err, "creating the kube client")
+	}
+	for _, name := range names {
+		env := envMap[name]
+		envNS := env.Spec.Namespace
+		if envNS == "" || requirements.IsRemoteEnvironment(name) {
+			continue
+		}
+		policy := requirements.Governance.PolicyForEnvironment(name, env.Spec.Kind == v1.EnvironmentKindTypeDevelopment)
+		log.Logger().Infof("Applying the governance policies to namespace %s of environment %s", util.ColorInfo(envNS), util.ColorInfo(name))
+		err = kube.ApplyNamespacePolicy(kubeClient, envNS, policy)
+		if err != nil {
+			return errors.Wrapf(err, "applying the governance policies for environment %s", name)
+		}
+	}
+	return nil
+}
+
 func (o *StepVerifyEnvironmentsOptions) storeRequirementsInTeamSettings(requirements *config.RequirementsConfig) error {
 	log.Logger().Infof("Storing the requirements in team settings in the dev environment\n")
 	err := o.ModifyDevEnvironment(func(env *v1.Environment) error {
@@ -345,6 +394,7 @@ func (o *StepVerifyEnvironmentsOptions) createEnvironmentRepository(name string,
 			if err != nil {
 				return errors.Wrap(err, "handle dev environment repository")
 			}
+			o.addUpgradeBootCommand(envGitInfo, requirements)
 		}
 	} else {
 		gitRepoOptions := &gits.GitRepositoryOptions{
@@ -362,7 +412,70 @@ func (o *StepVerifyEnvironmentsOptions) createEnvironmentRepository(name string,
 		if err != nil {
 			return errors.Wrapf(err, "failed to create git repository for gitURL %s", gitURL)
 		}
+
+		if requirements.IsRemoteEnvironment(environment.GetName()) {
+			provider, err := envGitInfo.CreateProviderForUser(server, userAuth, gitKind, gitter)
+			if err != nil {
+				return errors.Wrap(err, "unable to create git provider")
+			}
+			err = o.registerRemoteEnvironment(requirements, environment, envGitInfo, provider)
+			if err != nil {
+				return errors.Wrapf(err, "registering remote environment %s", environment.GetName())
+			}
+		}
+	}
+	return nil
+}
+
+// registerRemoteEnvironment marks the Environment as running on a remote cluster and registers its git repository
+// with the development cluster so that pull request pipelines and webhooks work without running 'jx create environment'
+func (o *StepVerifyEnvironmentsOptions) registerRemoteEnvironment(requirements *config.RequirementsConfig, environment *v1.Environment, envGitInfo *gits.GitRepository, provider gits.GitProvider) error {
+	jxClient, ns, err := o.JXClientAndDevNamespace()
+	if err != nil {
+		return err
+	}
+	if !environment.Spec.RemoteCluster {
+		environment.Spec.RemoteCluster = true
+		_, err = jxClient.JenkinsV1().Environments(ns).PatchUpdate(environment)
+		if err != nil {
+			return errors.Wrapf(err, "marking environment %s as remote", environment.GetName())
+		}
+	}
+
+	sr, err := kube.GetOrCreateSourceRepository(jxClient, ns, envGitInfo.Name, envGitInfo.Organisation, envGitInfo.HostURLWithoutUser())
+	if err != nil {
+		return errors.Wrapf(err, "creating SourceRepository for %s", envGitInfo.URL)
+	}
+	log.Logger().Debugf("have SourceRepository %s for remote environment %s", sr.Name, environment.GetName())
+
+	if requirements.Webhook != config.WebhookTypeProw && requirements.Webhook != config.WebhookTypeLighthouse {
+		return nil
+	}
+	err = o.CreateWebhookProw(environment.Spec.Source.URL, provider)
+	if err != nil {
+		return errors.Wrapf(err, "creating webhook for %s", environment.Spec.Source.URL)
+	}
+	log.Logger().Infof("Registered remote environment %s with git repository %s", util.ColorInfo(environment.GetName()), util.ColorInfo(envGitInfo.URL))
+	return nil
+}
+
+// updateRemoteClusterGitURL uses the environment git URL of the remote cluster which runs the environment if specified
+func (o *StepVerifyEnvironmentsOptions) updateRemoteClusterGitURL(requirements *config.RequirementsConfig, environment *v1.Environment) error {
+	cluster := requirements.ClusterForEnvironment(environment.GetName())
+	if cluster == nil || cluster.EnvironmentGitURL == "" || cluster.EnvironmentGitURL == environment.Spec.Source.URL {
+		return nil
+	}
+	jxClient, ns, err := o.JXClientAndDevNamespace()
+	if err != nil {
+		return err
 	}
+	environment.Spec.Source.URL = cluster.EnvironmentGitURL
+	_, err = jxClient.JenkinsV1().Environments(ns).PatchUpdate(environment)
+	if err != nil {
+		return errors.Wrapf(err, "updating the git URL of environment %s to %s", environment.GetName(), cluster.EnvironmentGitURL)
+	}
+	log.Logger().Infof("Environment %s runs on remote cluster %s using git repository %s", util.ColorInfo(environment.GetName()),
+		util.ColorInfo(cluster.Name), util.ColorInfo(cluster.EnvironmentGitURL))
 	return nil
 }
 
@@ -387,7 +500,7 @@ func (o *StepVerifyEnvironmentsOptions) handleDevEnvironmentRepository(envGitInf
 		}
 	}
 
-	err = o.pushDevEnvironmentUpdates(environmentRepo, dir, provider, gitter)
+	err = o.pushDevEnvironmentUpdates(environmentRepo, dir, provider, gitter, requirements)
 	if err != nil {
 		return errors.Wrapf(err, "error updating dev environment for %s", envGitInfo.Name)
 	}
@@ -400,6 +513,23 @@ func (o *StepVerifyEnvironmentsOptions) handleDevEnvironmentRepository(envGitInf
 	return nil
 }
 
+// addUpgradeBootCommand lets users upgrade the boot configuration by commenting boot.UpgradeCommand on a pull request
+// of the dev environment repository. Failures are only logged as the command is not required to run the cluster
+func (o *StepVerifyEnvironmentsOptions) addUpgradeBootCommand(envGitInfo *gits.GitRepository, requirements *config.RequirementsConfig) {
+	if requirements.Webhook != config.WebhookTypeProw && requirements.Webhook != config.WebhookTypeLighthouse {
+		return
+	}
+	kubeClient, ns, err := o.KubeClientAndDevNamespace()
+	if err == nil {
+		err = prow.AddUpgradeBootCommand(kubeClient, []string{envGitInfo.Organisation + "/" + envGitInfo.Name}, ns)
+	}
+	if err != nil {
+		log.Logger().Warnf("failed to add the %s ChatOps command to %s: %s", boot.UpgradeCommand, envGitInfo.URL, err)
+		return
+	}
+	log.Logger().Infof("Comment %s on a pull request of %s to upgrade the boot configuration", util.ColorInfo(boot.UpgradeCommand), util.ColorInfo(envGitInfo.URL))
+}
+
 func (o *StepVerifyEnvironmentsOptions) createDevEnvironmentRepository(gitInfo *gits.GitRepository, localRepoDir string, fromGitURL string, fromGitRef string, privateRepo bool, requirements *config.RequirementsConfig, provider gits.GitProvider, gitter gits.Gitter) (*gits.GitRepository, error) {
 	isDefaultBootURL, err := gits.IsDefaultBootConfigURL(fromGitURL)
 	if err != nil {
@@ -451,7 +581,7 @@ func (o *StepVerifyEnvironmentsOptions) createDevEnvironmentRepository(gitInfo *
 	return duplicateInfo, nil
 }
 
-func (o *StepVerifyEnvironmentsOptions) pushDevEnvironmentUpdates(environmentRepo *gits.GitRepository, localRepoDir string, provider gits.GitProvider, gitter gits.Gitter) error {
+func (o *StepVerifyEnvironmentsOptions) pushDevEnvironmentUpdates(environmentRepo *gits.GitRepository, localRepoDir string, provider gits.GitProvider, gitter gits.Gitter, requirements *config.RequirementsConfig) error {
 	_, _, _, _, err := gits.ForkAndPullRepo(environmentRepo.CloneURL, localRepoDir, "master", "master", provider, gitter, environmentRepo.Name)
 	if err != nil {
 		return errors.Wrapf(err, "forking and pulling %s", environmentRepo.CloneURL)
@@ -462,6 +592,14 @@ func (o *StepVerifyEnvironmentsOptions) pushDevEnvironmentUpdates(environmentRep
 		return errors.Wrap(err, "failed to modify dev environment config")
 	}
 
+	// the upgrade pipeline is triggered by a comment on a Pull Request which only prow and lighthouse handle
+	if requirements.Webhook == config.WebhookTypeProw || requirements.Webhook == config.WebhookTypeLighthouse {
+		err = boot.EnsureUpgradePipeline(localRepoDir)
+		if err != nil {
+			return errors.Wrap(err, "failed to add the boot upgrade pipeline to the dev environment")
+		}
+	}
+
 	hasChanges, err := gitter.HasChanges(localRepoDir)
 	if err != nil {
 		return errors.Wrap(err, "unable to check for changes")
@@ -570,5 +708,5 @@ func (o *StepVerifyEnvironmentsOptions) updateEnvironmentIngressConfig(requireme
 		}
 	}
 
-	return requirements.SaveConfig(requirementsFileName)
+	return requirements.SaveConfigWithOptions(requirementsFileName, o.RequirementsOptions())
 }
@@ -11,8 +11,10 @@ import (
 	"github.com/jenkins-x/jx/v2/pkg/cloud/gke"
 	"github.com/jenkins-x/jx/v2/pkg/cloud/gke/externaldns"
 	"github.com/jenkins-x/jx/v2/pkg/config"
+	"github.com/jenkins-x/jx/v2/pkg/helm"
 	"github.com/jenkins-x/jx/v2/pkg/kube"
 	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/jenkins-x/jx/v2/pkg/versionstream"
 
 	"github.com/jenkins-x/jx/v2/pkg/cloud"
 	"github.com/jenkins-x/jx/v2/pkg/cmd/helper"
@@ -22,6 +24,7 @@ import (
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 	pipelineapi "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 )
@@ -111,7 +114,7 @@ func (o *StepVerifyIngressOptions) Run() error {
 			return fmt.Errorf("no default namespace found")
 		}
 	}
-	requirements, requirementsFileName, err := config.LoadRequirementsConfig(o.Dir, config.DefaultFailOnValidationError)
+	requirements, requirementsFileName, err := config.LoadRequirementsConfigWithOptions(o.Dir, config.DefaultFailOnValidationError, o.RequirementsOptions())
 	if err != nil {
 		return errors.Wrapf(err, "failed to load Jenkins X requirements")
 	}
@@ -125,6 +128,21 @@ func (o *StepVerifyIngressOptions) Run() error {
 		log.Logger().Warnf("No provider configured\n")
 	}
 
+	err = requirements.Ingress.ValidateIngressStack(requirements.Cluster.Provider)
+	if err != nil {
+		return errors.Wrapf(err, "invalid ingress configuration in %s", requirementsFileName)
+	}
+	o.defaultIngressControllerService(requirements)
+	log.Logger().Infof("using the %s ingress controller\n", info(string(requirements.Ingress.ControllerKind())))
+
+	// the boot pipeline installs nginx so it is only verified here when its service is needed to discover the domain
+	if requirements.Ingress.ControllerKind() != config.IngressControllerTypeNginx || requirements.Ingress.Domain == "" {
+		err = o.installIngressController(requirements)
+		if err != nil {
+			return errors.Wrapf(err, "failed to install the %s ingress controller", requirements.Ingress.ControllerKind())
+		}
+	}
+
 	if requirements.Ingress.Domain == "" {
 		err = o.discoverIngressDomain(requirements, requirementsFileName)
 		if err != nil {
@@ -141,7 +159,7 @@ func (o *StepVerifyIngressOptions) Run() error {
 			log.Logger().Info("using GKE with external dns, you can also now enable TLS")
 		}
 
-		if requirements.Ingress.ExternalDNS {
+		if requirements.Ingress.ExternalDNS && requirements.Ingress.DNSProviderKind(requirements.Cluster.Provider) == config.DNSProviderTypeCloudDNS {
 			log.Logger().Infof("validating the external-dns secret in namespace %s\n", info(ns))
 
 			kubeClient, err := o.KubeClient()
@@ -180,6 +198,20 @@ func (o *StepVerifyIngressOptions) Run() error {
 		}
 	}
 
+	dnsProvider := requirements.Ingress.DNSProviderKind(requirements.Cluster.Provider)
+	if requirements.Ingress.ExternalDNS && dnsProvider == "" {
+		log.Logger().Warnf("skipping external-dns as no dnsProvider is configured for the kubernetes provider %s\n", requirements.Cluster.Provider)
+	} else if requirements.Ingress.ExternalDNS && dnsProvider != config.DNSProviderTypeCloudDNS {
+		log.Logger().Infof("using external-dns with the %s DNS provider for domain %s\n", info(string(dnsProvider)), info(requirements.Ingress.Domain))
+		if requirements.Ingress.IsAutoDNSDomain() {
+			return fmt.Errorf("external-dns is not supported with automated domains like %s, you will need to use a real domain you own", requirements.Ingress.Domain)
+		}
+		err = o.installExternalDNS(requirements, dnsProvider, ns)
+		if err != nil {
+			return errors.Wrapf(err, "failed to install external-dns for the %s DNS provider", dnsProvider)
+		}
+	}
+
 	// TLS uses cert-manager to ask LetsEncrypt for a signed certificate
 	if requirements.Ingress.TLS.Enabled {
 		if requirements.Cluster.Provider != cloud.GKE {
@@ -195,7 +227,124 @@ func (o *StepVerifyIngressOptions) Run() error {
 		}
 	}
 
-	return requirements.SaveConfig(requirementsFileName)
+	return requirements.SaveConfigWithOptions(requirementsFileName, o.RequirementsOptions())
+}
+
+// defaultIngressControllerService defaults the ingress controller service used to discover the domain from the
+// ingress controller in the requirements unless it was specified on the command line
+func (o *StepVerifyIngressOptions) defaultIngressControllerService(requirements *config.RequirementsConfig) {
+	ns, name := requirements.Ingress.ControllerService()
+	if ns != "" && (o.IngressNamespace == "" || o.IngressNamespace == opts.DefaultIngressNamesapce) {
+		o.IngressNamespace = ns
+	}
+	if name != "" && (o.IngressService == "" || o.IngressService == opts.DefaultIngressServiceName) {
+		o.IngressService = name
+	}
+}
+
+// installIngressController installs the ingress controller of the requirements if its load balancer service cannot be
+// found and missing resources are lazily created
+func (o *StepVerifyIngressOptions) installIngressController(requirements *config.RequirementsConfig) error {
+	kind := requirements.Ingress.ControllerKind()
+	if kind == config.IngressControllerTypeCloud {
+		// the cloud load balancers do not need installing
+		return nil
+	}
+	kubeClient, err := o.KubeClient()
+	if err != nil {
+		return errors.Wrap(err, "creating kubernetes client")
+	}
+	_, err = kubeClient.CoreV1().Services(o.IngressNamespace).Get(o.IngressService, metav1.GetOptions{})
+	if err == nil {
+		return nil
+	}
+	if !apierrors.IsNotFound(err) {
+		return errors.Wrapf(err, "getting the ingress controller service %s in namespace %s", o.IngressService, o.IngressNamespace)
+	}
+
+	chart, releaseName := requirements.Ingress.ControllerChart()
+	if chart == "" {
+		return fmt.Errorf("the %s ingress controller service %s was not found in namespace %s, please install it before booting Jenkins X",
+			kind, o.IngressService, o.IngressNamespace)
+	}
+	if !o.LazyCreate {
+		log.Logger().Warnf("the %s ingress controller service %s was not found in namespace %s\n", kind, o.IngressService, o.IngressNamespace)
+		return nil
+	}
+
+	values := []string{"rbac.create=true"}
+	if kind == config.IngressControllerTypeNginx {
+		values = append(values, fmt.Sprintf("controller.extraArgs.publish-service=%s/%s", o.IngressNamespace, o.IngressService))
+	}
+	version, err := o.GetVersionNumber(versionstream.KindChart, chart, requirements.VersionStream.URL, requirements.VersionStream.Ref)
+	if err != nil {
+		return errors.Wrapf(err, "failed to load version of chart %s", chart)
+	}
+	log.Logger().Infof("installing the %s ingress controller into namespace %s\n", util.ColorInfo(string(kind)), util.ColorInfo(o.IngressNamespace))
+	return o.Retry(3, time.Second, func() error {
+		return o.InstallChartWithOptions(helm.InstallChartOptions{
+			Chart:       chart,
+			ReleaseName: releaseName,
+			Version:     version,
+			Ns:          o.IngressNamespace,
+			SetValues:   values,
+			HelmUpdate:  true,
+		})
+	})
+}
+
+// installExternalDNS installs external-dns for the DNS provider into the namespace if it is not already installed and
+// missing resources are lazily created. The credentials to the DNS service come from the cloud identity of the nodes
+func (o *StepVerifyIngressOptions) installExternalDNS(requirements *config.RequirementsConfig, dnsProvider config.DNSProviderType, ns string) error {
+	kubeClient, err := o.KubeClient()
+	if err != nil {
+		return errors.Wrap(err, "creating kubernetes client")
+	}
+	if ns == "" {
+		_, ns, err = o.KubeClientAndDevNamespace()
+		if err != nil {
+			return errors.Wrap(err, "getting the dev namespace")
+		}
+	}
+	_, err = kubeClient.AppsV1().Deployments(ns).Get(kube.DefaultExternalDNSReleaseName, metav1.GetOptions{})
+	if err == nil {
+		return nil
+	}
+	if !apierrors.IsNotFound(err) {
+		return errors.Wrapf(err, "getting the external-dns deployment in namespace %s", ns)
+	}
+	if !o.LazyCreate {
+		log.Logger().Warnf("external-dns was not found in namespace %s\n", ns)
+		return nil
+	}
+
+	err = o.Helm().AddRepo(kube.ChartOwnerExternalDNS, kube.ChartURLExternalDNS, "", "")
+	if err != nil {
+		return errors.Wrapf(err, "adding helm repo")
+	}
+	values := []string{
+		"provider=" + dnsProvider.ExternalDNSProvider(),
+		"sources={ingress}",
+		"rbac.create=true",
+		"txtOwnerId=jx-external-dns",
+		"domainFilters={" + requirements.Ingress.Domain + "}",
+	}
+	switch dnsProvider {
+	case config.DNSProviderTypeRoute53:
+		values = append(values, "aws.zoneType=public")
+	case config.DNSProviderTypeAzureDNS:
+		values = append(values, "azure.useManagedIdentityExtension=true")
+	}
+	log.Logger().Infof("installing external-dns into namespace %s\n", util.ColorInfo(ns))
+	return o.Retry(2, time.Second, func() error {
+		return o.InstallChartWithOptions(helm.InstallChartOptions{
+			Chart:       kube.ChartExternalDNS,
+			ReleaseName: kube.DefaultExternalDNSReleaseName,
+			Ns:          ns,
+			SetValues:   values,
+			HelmUpdate:  true,
+		})
+	})
 }
 
 func (o *StepVerifyIngressOptions) discoverIngressDomain(requirements *config.RequirementsConfig, requirementsFileName string) error {
@@ -246,7 +395,7 @@ func (o *StepVerifyIngressOptions) discoverIngressDomain(requirements *config.Re
 		return fmt.Errorf("failed to discover domain for ingress service %s/%s", o.IngressNamespace, o.IngressService)
 	}
 	requirements.Ingress.Domain = domain
-	err = requirements.SaveConfig(requirementsFileName)
+	err = requirements.SaveConfigWithOptions(requirementsFileName, o.RequirementsOptions())
 	if err != nil {
 		return errors.Wrapf(err, "failed to save changes to file: %s", requirementsFileName)
 	}
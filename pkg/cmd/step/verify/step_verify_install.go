@@ -6,6 +6,7 @@ import (
 	"github.com/jenkins-x/jx/v2/pkg/cmd/opts/step"
 
 	"github.com/jenkins-x/jx/v2/pkg/cloud"
+	"github.com/jenkins-x/jx/v2/pkg/cloud/identity"
 	"github.com/jenkins-x/jx/v2/pkg/cmd/helper"
 	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
 	"github.com/jenkins-x/jx/v2/pkg/config"
@@ -82,7 +83,7 @@ func (o *StepVerifyInstallOptions) Run() error {
 		return err
 	}
 
-	requirements, _, err := config.LoadRequirementsConfig(o.Dir, config.DefaultFailOnValidationError)
+	requirements, _, err := config.LoadRequirementsConfigWithOptions(o.Dir, config.DefaultFailOnValidationError, o.RequirementsOptions())
 	if err != nil {
 		return err
 	}
@@ -100,7 +101,12 @@ func (o *StepVerifyInstallOptions) Run() error {
 		provider = requirements.Cluster.Provider
 	}
 
-	if requirements.Kaniko {
+	if identity.Enabled(requirements) {
+		err = o.validateWorkloadIdentities(requirements, provider, ns)
+		if err != nil {
+			return err
+		}
+	} else if requirements.Kaniko {
 		if provider == cloud.GKE {
 			err = o.validateKaniko(ns)
 			if err != nil {
@@ -86,7 +86,7 @@ func (o *StepVerifyPackagesOptions) Run() error {
 		verifyMap[k] = packages[k]
 	}
 
-	requirements, _, err := config.LoadRequirementsConfig(o.Dir, config.DefaultFailOnValidationError)
+	requirements, _, err := config.LoadRequirementsConfigWithOptions(o.Dir, config.DefaultFailOnValidationError, o.RequirementsOptions())
 	if err != nil {
 		return errors.Wrapf(err, "failed to load boot requirements")
 	}
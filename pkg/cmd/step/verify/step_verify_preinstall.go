@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
-	"path"
 	"path/filepath"
 	"strings"
 
@@ -15,17 +14,20 @@ import (
 	"sigs.k8s.io/yaml"
 
 	"github.com/jenkins-x/jx/v2/pkg/boot"
+	"github.com/jenkins-x/jx/v2/pkg/chartrepo"
 	"github.com/jenkins-x/jx/v2/pkg/cloud"
 	"github.com/jenkins-x/jx/v2/pkg/cloud/amazon"
 	"github.com/jenkins-x/jx/v2/pkg/cloud/buckets"
 	"github.com/jenkins-x/jx/v2/pkg/cloud/factory"
 	"github.com/jenkins-x/jx/v2/pkg/cloud/gke"
+	"github.com/jenkins-x/jx/v2/pkg/cloud/identity"
 	"github.com/jenkins-x/jx/v2/pkg/cmd/create"
 	"github.com/jenkins-x/jx/v2/pkg/cmd/helper"
 	"github.com/jenkins-x/jx/v2/pkg/cmd/namespace"
 	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
 	"github.com/jenkins-x/jx/v2/pkg/cmd/opts/step"
 	"github.com/jenkins-x/jx/v2/pkg/config"
+	"github.com/jenkins-x/jx/v2/pkg/flagger"
 	"github.com/jenkins-x/jx/v2/pkg/gits"
 	"github.com/jenkins-x/jx/v2/pkg/io/secrets"
 	"github.com/jenkins-x/jx/v2/pkg/kube"
@@ -94,7 +96,7 @@ func NewCmdStepVerifyPreInstall(commonOpts *opts.CommonOptions) *cobra.Command {
 // Run implements this command
 func (o *StepVerifyPreInstallOptions) Run() error {
 	info := util.ColorInfo
-	requirements, requirementsFileName, err := config.LoadRequirementsConfig(o.Dir, config.DefaultFailOnValidationError)
+	requirements, requirementsFileName, err := config.LoadRequirementsConfigWithOptions(o.Dir, config.DefaultFailOnValidationError, o.RequirementsOptions())
 	if err != nil {
 		return err
 	}
@@ -205,7 +207,15 @@ func (o *StepVerifyPreInstallOptions) Run() error {
 			return err
 		}
 	}
-	if requirements.Kaniko {
+	workloadIdentity := identity.Enabled(requirements)
+	if workloadIdentity && o.LazyCreate {
+		err = o.configureWorkloadIdentities(requirements, ns)
+		if err != nil {
+			return errors.Wrap(err, "configuring workload identity")
+		}
+		log.Logger().Info("\n")
+	}
+	if requirements.Kaniko && !workloadIdentity {
 		if requirements.Cluster.Provider == cloud.GKE {
 			log.Logger().Infof("Validating Kaniko secret in namespace %s", info(ns))
 
@@ -229,7 +239,7 @@ func (o *StepVerifyPreInstallOptions) Run() error {
 		}
 	}
 
-	if vns := requirements.Velero.Namespace; vns != "" {
+	if vns := requirements.Velero.Namespace; vns != "" && !workloadIdentity {
 		if requirements.Cluster.Provider == cloud.GKE {
 			log.Logger().Infof("Validating the velero secret in namespace %s", info(vns))
 
@@ -277,6 +287,14 @@ func (o *StepVerifyPreInstallOptions) Run() error {
 		}
 	}
 
+	if requirements.ProgressiveDelivery.IsEnabled() && o.LazyCreate {
+		err = o.verifyProgressiveDelivery(requirements)
+		if err != nil {
+			return err
+		}
+		log.Logger().Info("\n")
+	}
+
 	// Lets update the TeamSettings with the VersionStream data from the jx-requirements.yml file so we make sure
 	// we are upgrading with the latest versions
 	log.Logger().Infof("Cluster looks good, you are ready to '%s' now!", info("jx boot"))
@@ -972,6 +990,30 @@ func (o *StepVerifyPreInstallOptions) ValidateRequirements(requirements *config.
 			return fmt.Errorf("invalid requirements in file %s cannot use prow as a webhook for git kind: %s server: %s. Please try using lighthouse instead", fileName, kind, server)
 		}
 	}
+	err := flagger.Validate(requirements.ProgressiveDelivery)
+	if err != nil {
+		return errors.Wrapf(err, "invalid requirements in file %s", fileName)
+	}
+	err = requirements.Previews.Validate()
+	if err != nil {
+		return errors.Wrapf(err, "invalid requirements in file %s", fileName)
+	}
+	_, err = requirements.PreviewCluster()
+	if err != nil {
+		return errors.Wrapf(err, "invalid requirements in file %s", fileName)
+	}
+	for _, env := range requirements.Environments {
+		approval := env.Approval
+		if approval != nil && approval.RequiredApprovals > len(approval.Approvers) {
+			return fmt.Errorf("invalid requirements in file %s environment %s requires %d promotion approvals but only has %d approvers", fileName, env.Key, approval.RequiredApprovals, len(approval.Approvers))
+		}
+		for i := range env.Freezes {
+			err = env.Freezes[i].Validate()
+			if err != nil {
+				return errors.Wrapf(err, "invalid requirements in file %s environment %s freeze %s", fileName, env.Key, env.Freezes[i].Label())
+			}
+		}
+	}
 	if requirements.Repository == config.RepositoryTypeBucketRepo && requirements.Cluster.ChartRepository == "" {
 		requirements.Cluster.ChartRepository = "http://bucketrepo/bucketrepo/charts/"
 		err := o.SaveConfig(requirements, fileName)
@@ -979,6 +1021,21 @@ func (o *StepVerifyPreInstallOptions) ValidateRequirements(requirements *config.
 			return errors.Wrapf(err, "failed to save changes to file: %s", fileName)
 		}
 	}
+	if requirements.Cluster.ChartKind == config.ChartRepositoryTypeBucket && requirements.Cluster.ChartRepository == "" {
+		bucketURL := requirements.Storage.Repository.URL
+		if bucketURL == "" {
+			return fmt.Errorf("invalid requirements in file %s storing charts in a bucket requires the storage.repository.url bucket", fileName)
+		}
+		repoURL, err := chartrepo.BucketRepositoryURL(bucketURL)
+		if err != nil {
+			return errors.Wrapf(err, "invalid requirements in file %s", fileName)
+		}
+		requirements.Cluster.ChartRepository = repoURL
+		err = o.SaveConfig(requirements, fileName)
+		if err != nil {
+			return errors.Wrapf(err, "failed to save changes to file: %s", fileName)
+		}
+	}
 
 	// lets verify that we have a repository name defined for every environment
 	modified := false
@@ -1003,32 +1060,10 @@ func (o *StepVerifyPreInstallOptions) ValidateRequirements(requirements *config.
 	return nil
 }
 
-// SaveConfig saves the configuration file to the given project directory
+// SaveConfig saves the configuration file to the given project directory only writing the changes to the base
+// requirements file if any requirements profiles are enabled
 func (o *StepVerifyPreInstallOptions) SaveConfig(c *config.RequirementsConfig, fileName string) error {
-	data, err := yaml.Marshal(c)
-	if err != nil {
-		return err
-	}
-	err = ioutil.WriteFile(fileName, data, util.DefaultWritePermissions)
-	if err != nil {
-		return errors.Wrapf(err, "failed to save file %s", fileName)
-	}
-
-	if c.Helmfile {
-		y := config.RequirementsValues{
-			RequirementsConfig: c,
-		}
-		data, err = yaml.Marshal(y)
-		if err != nil {
-			return err
-		}
-
-		err = ioutil.WriteFile(path.Join(path.Dir(fileName), config.RequirementsValuesFileName), data, util.DefaultWritePermissions)
-		if err != nil {
-			return errors.Wrapf(err, "failed to save file %s", config.RequirementsValuesFileName)
-		}
-	}
-	return nil
+	return c.SaveConfigWithOptions(fileName, o.RequirementsOptions())
 }
 
 func modifyMapIfNotBlank(m map[string]string, key string, value string) {
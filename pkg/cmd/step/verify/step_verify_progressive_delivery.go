@@ -0,0 +1,79 @@
+package verify
+
+import (
+	v1 "github.com/jenkins-x/jx/v2/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/v2/pkg/config"
+	"github.com/jenkins-x/jx/v2/pkg/flagger"
+	"github.com/jenkins-x/jx/v2/pkg/helm"
+	"github.com/jenkins-x/jx/v2/pkg/kube"
+	"github.com/jenkins-x/jx/v2/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// verifyProgressiveDelivery installs the service mesh or ingress controller of the progressive delivery provider and
+// Flagger unless they are installed already
+func (o *StepVerifyPreInstallOptions) verifyProgressiveDelivery(requirements *config.RequirementsConfig) error {
+	pd := requirements.ProgressiveDelivery
+	charts := append(flagger.ProviderCharts(flagger.Provider(pd)), flagger.FlaggerChart(pd))
+	for _, chart := range charts {
+		err := o.installProgressiveDeliveryChart(chart)
+		if err != nil {
+			return errors.Wrapf(err, "installing chart %s for progressive delivery", chart.Name)
+		}
+	}
+	return nil
+}
+
+// installProgressiveDeliveryChart installs the chart unless its deployment exists already
+func (o *StepVerifyPreInstallOptions) installProgressiveDeliveryChart(chart flagger.Chart) error {
+	kubeClient, err := o.KubeClient()
+	if err != nil {
+		return errors.Wrap(err, "creating the kube client")
+	}
+	_, err = kubeClient.AppsV1().Deployments(chart.Namespace).Get(chart.Deployment, metav1.GetOptions{})
+	if err == nil {
+		log.Logger().Debugf("deployment %s already exists in namespace %s so not installing chart %s", chart.Deployment, chart.Namespace, chart.Name)
+		return nil
+	}
+	if !apierrors.IsNotFound(err) {
+		return errors.Wrapf(err, "getting deployment %s in namespace %s", chart.Deployment, chart.Namespace)
+	}
+	log.Logger().Infof("Installing chart %s in namespace %s for progressive delivery", util.ColorInfo(chart.Name), util.ColorInfo(chart.Namespace))
+	return o.InstallChartWithOptions(helm.InstallChartOptions{
+		Chart:       chart.Name,
+		Repository:  chart.Repository,
+		ReleaseName: chart.ReleaseName,
+		Ns:          chart.Namespace,
+		SetValues:   chart.SetValues,
+		Wait:        true,
+	})
+}
+
+// enableMeshInjection enables the injection of the Istio sidecars into the pods of the namespaces of the environments
+// whose applications are rolled out progressively via Istio
+func (o *StepVerifyEnvironmentsOptions) enableMeshInjection(requirements *config.RequirementsConfig, envMap map[string]*v1.Environment, names []string) error {
+	pd := requirements.ProgressiveDelivery
+	if !pd.IsEnabled() || flagger.Provider(pd) != flagger.ProviderIstio {
+		return nil
+	}
+	kubeClient, err := o.KubeClient()
+	if err != nil {
+		return errors.Wrap(err, "creating the kube client")
+	}
+	for _, name := range names {
+		env := envMap[name]
+		envNS := env.Spec.Namespace
+		if envNS == "" || requirements.IsRemoteEnvironment(name) || !pd.IsEnabledForEnvironment(name) {
+			continue
+		}
+		log.Logger().Infof("Enabling Istio in namespace %s of environment %s", util.ColorInfo(envNS), util.ColorInfo(name))
+		err = kube.EnsureNamespaceCreated(kubeClient, envNS, map[string]string{flagger.LabelIstioInjection: "enabled"}, nil)
+		if err != nil {
+			return errors.Wrapf(err, "enabling Istio for environment %s", name)
+		}
+	}
+	return nil
+}
@@ -13,10 +13,15 @@ import (
 	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
 	"github.com/jenkins-x/jx/v2/pkg/cmd/templates"
 	"github.com/jenkins-x/jx/v2/pkg/config"
+	"github.com/jenkins-x/jx/v2/pkg/gits"
 	"github.com/jenkins-x/jx/v2/pkg/helm"
+	"github.com/jenkins-x/jx/v2/pkg/kube"
 	"github.com/jenkins-x/jx/v2/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/util"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 var (
@@ -37,6 +42,12 @@ var (
 		# override the error if the 'jx' binary is out of range (e.g. for development)
         export JX_DISABLE_VERIFY_JX="true"
 		jx step verify packages
+
+		# reports any differences between the 'jx-requirements.yml' file and the live cluster
+		jx step verify requirements --live
+
+		# creates a Pull Request to update the 'jx-requirements.yml' file to match the live cluster
+		jx step verify requirements --live --pr
 	`)
 )
 
@@ -44,7 +55,10 @@ var (
 type StepVerifyRequirementsOptions struct {
 	step.StepOptions
 
-	Dir string
+	Dir         string
+	Live        bool
+	PullRequest bool
+	FailOnDrift bool
 }
 
 // NewCmdStepVerifyRequirements creates the `jx step verify pod` command
@@ -69,6 +83,9 @@ func NewCmdStepVerifyRequirements(commonOpts *opts.CommonOptions) *cobra.Command
 		},
 	}
 	cmd.Flags().StringVarP(&options.Dir, "dir", "d", ".", "the directory to recursively look for 'requirements.yaml' files")
+	cmd.Flags().BoolVarP(&options.Live, "live", "", false, "compares the 'jx-requirements.yml' file with the live cluster and reports any drift")
+	cmd.Flags().BoolVarP(&options.PullRequest, "pr", "", false, "when used with --live creates a Pull Request to update the 'jx-requirements.yml' file to match the live cluster")
+	cmd.Flags().BoolVarP(&options.FailOnDrift, "fail-on-drift", "", false, "when used with --live fails the command if the 'jx-requirements.yml' file does not match the live cluster")
 
 	return cmd
 }
@@ -82,10 +99,13 @@ func (o *StepVerifyRequirementsOptions) Run() error {
 			return err
 		}
 	}
-	requirements, _, err := config.LoadRequirementsConfig(o.Dir, config.DefaultFailOnValidationError)
+	requirements, fileName, err := config.LoadRequirementsConfigWithOptions(o.Dir, config.DefaultFailOnValidationError, o.RequirementsOptions())
 	if err != nil {
 		return errors.Wrapf(err, "failed to load boot requirements")
 	}
+	if o.Live {
+		return o.verifyLive(requirements, fileName)
+	}
 	vs := requirements.VersionStream
 
 	log.Logger().Debugf("Verifying the helm requirements versions in dir: %s using version stream URL: %s and git ref: %s\n", o.Dir, vs.URL, vs.Ref)
@@ -159,3 +179,171 @@ func (o *StepVerifyRequirementsOptions) verifyRequirementsYAML(resolver *version
 	}
 	return nil
 }
+
+// verifyLive compares the requirements with the live cluster reporting any drift
+func (o *StepVerifyRequirementsOptions) verifyLive(requirements *config.RequirementsConfig, fileName string) error {
+	if fileName == "" {
+		return fmt.Errorf("no %s file found in dir %s", config.RequirementsConfigFileName, o.Dir)
+	}
+	live, err := o.liveRequirements()
+	if err != nil {
+		return errors.Wrap(err, "failed to find the requirements of the live cluster")
+	}
+	drifts, err := config.DiffRequirements(requirements, live)
+	if err != nil {
+		return errors.Wrapf(err, "failed to compare %s with the live cluster", fileName)
+	}
+	if len(drifts) == 0 {
+		log.Logger().Infof("the requirements in %s match the live cluster", util.ColorInfo(fileName))
+		return nil
+	}
+
+	table := o.CreateTable()
+	table.AddRow("PROPERTY", "REQUIREMENTS", "CLUSTER")
+	for _, d := range drifts {
+		table.AddRow(d.Path, d.Requirements, d.Cluster)
+	}
+	table.Render()
+	log.Logger().Warnf("%d requirements in %s do not match the live cluster", len(drifts), util.ColorWarning(fileName))
+
+	if o.PullRequest {
+		err = o.reconcileRequirements(requirements, fileName, drifts, live)
+		if err != nil {
+			return err
+		}
+	}
+	if o.FailOnDrift {
+		return fmt.Errorf("%d requirements in %s do not match the live cluster", len(drifts), fileName)
+	}
+	return nil
+}
+
+// liveRequirements returns the requirements values which can be found in the live cluster keyed by property path
+func (o *StepVerifyRequirementsOptions) liveRequirements() (map[string]interface{}, error) {
+	kubeClient, ns, err := o.KubeClientAndDevNamespace()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create the kube client")
+	}
+	live := map[string]interface{}{}
+
+	ic, err := kube.GetIngressConfig(kubeClient, ns)
+	if err != nil {
+		log.Logger().Debugf("failed to find the ingress config in namespace %s: %s", ns, err.Error())
+	} else {
+		live["ingress.domain"] = ic.Domain
+		live["ingress.tls.enabled"] = ic.TLS
+		if ic.Email != "" {
+			live["ingress.tls.email"] = ic.Email
+		}
+	}
+
+	_, err = kubeClient.CoreV1().Secrets(ns).Get(kube.SecretKaniko, metav1.GetOptions{})
+	if err == nil {
+		live["kaniko"] = true
+	} else if apierrors.IsNotFound(err) {
+		live["kaniko"] = false
+	} else {
+		log.Logger().Debugf("failed to find the secret %s in namespace %s: %s", kube.SecretKaniko, ns, err.Error())
+	}
+
+	jxClient, _, err := o.JXClient()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create the jx client")
+	}
+	settings, err := kube.GetDevEnvTeamSettings(jxClient, ns)
+	if err != nil {
+		log.Logger().Debugf("failed to find the team settings in namespace %s: %s", ns, err.Error())
+	} else {
+		storage := map[string]string{
+			"storage.logs.url":    kube.ClassificationLogs,
+			"storage.reports.url": kube.ClassificationReports,
+		}
+		for p, classifier := range storage {
+			for _, sl := range settings.StorageLocations {
+				if sl.Classifier == classifier && sl.BucketURL != "" {
+					live[p] = sl.BucketURL
+				}
+			}
+		}
+	}
+
+	vaultOperatorClient, err := o.VaultOperatorClient()
+	if err != nil {
+		log.Logger().Debugf("failed to create the vault operator client: %s", err.Error())
+		return live, nil
+	}
+	vaults, err := vaultOperatorClient.VaultV1alpha1().Vaults(ns).List(metav1.ListOptions{})
+	if err != nil {
+		log.Logger().Debugf("failed to list the vaults in namespace %s: %s", ns, err.Error())
+		return live, nil
+	}
+	// without a vault the secrets may be stored locally or by an external secrets operator so the storage is unknown
+	if len(vaults.Items) > 0 {
+		live["secretStorage"] = string(config.SecretStorageTypeVault)
+	}
+	if len(vaults.Items) == 1 {
+		live["vault.name"] = vaults.Items[0].Name
+	}
+	return live, nil
+}
+
+// reconcileRequirements updates the requirements file to match the live cluster and creates a Pull Request
+func (o *StepVerifyRequirementsOptions) reconcileRequirements(requirements *config.RequirementsConfig, fileName string, drifts []config.RequirementsDrift, live map[string]interface{}) error {
+	changes := map[string]interface{}{}
+	var lines []string
+	for _, d := range drifts {
+		changes[d.Path] = live[d.Path]
+		lines = append(lines, fmt.Sprintf("* `%s` from `%s` to `%s`", d.Path, d.Requirements, d.Cluster))
+	}
+	err := requirements.ReconcileRequirements(changes)
+	if err != nil {
+		return errors.Wrapf(err, "failed to reconcile %s", fileName)
+	}
+	err = requirements.SaveConfigWithOptions(fileName, o.RequirementsOptions())
+	if err != nil {
+		return errors.Wrapf(err, "failed to save %s", fileName)
+	}
+
+	dir := filepath.Dir(fileName)
+	gitInfo, provider, _, err := o.CreateGitProvider(dir)
+	if err != nil {
+		return errors.Wrap(err, "failed to get git provider")
+	}
+	upstreamInfo, err := provider.GetRepository(gitInfo.Organisation, gitInfo.Name)
+	if err != nil {
+		return errors.Wrapf(err, "getting repository %s/%s", gitInfo.Organisation, gitInfo.Name)
+	}
+	base := upstreamInfo.DefaultBranch
+	if base == "" {
+		// the git provider does not report the default branch so use the branch of the dev environment checkout
+		base, err = o.Git().Branch(dir)
+		if err != nil {
+			return errors.Wrapf(err, "failed to find the branch of %s", dir)
+		}
+	}
+	details := gits.PullRequestDetails{
+		BranchName: "jx-requirements-drift",
+		Title:      "fix(config): update jx-requirements.yml to match the cluster",
+		Message:    "Updates the requirements which do not match the live cluster:\n\n" + strings.Join(lines, "\n"),
+	}
+	creator := &gits.PullRequestCreator{
+		Dir:            dir,
+		UpstreamRepo:   upstreamInfo,
+		Base:           base,
+		Details:        &details,
+		UpdateIfExists: true,
+		Commit:         true,
+		CommitMessage:  details.Title,
+		Push:           true,
+		Gitter:         o.Git(),
+		Provider:       provider,
+	}
+	info, err := creator.Create()
+	if err != nil {
+		return errors.Wrapf(err, "failed to create PR for base %s and head branch %s", base, details.BranchName)
+	}
+	if info != nil && info.PullRequest != nil {
+		log.Logger().Infof("created Pull Request %s", util.ColorInfo(info.PullRequest.URL))
+	}
+	return nil
+}
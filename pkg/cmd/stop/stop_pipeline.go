@@ -37,6 +37,10 @@ var (
 	stopPipelineLong = templates.LongDesc(`
 		Stops the pipeline build.
 
+		When using Tekton the running PipelineRun of the build is cancelled. The pipeline can either be picked from the
+		running pipelines or specified as 'owner/repo/branch' with the --build option. A cancelled pipeline can be rerun
+		from the same commit with 'jx start pipeline owner/repo/branch --from-build N'.
+
 `)
 
 	stopPipelineExample = templates.Examples(`
@@ -69,7 +73,7 @@ func NewCmdStopPipeline(commonOpts *opts.CommonOptions) *cobra.Command {
 			helper.CheckErr(err)
 		},
 	}
-	cmd.Flags().IntVarP(&options.Build, "build", "", 0, "The build number to stop")
+	cmd.Flags().IntVarP(&options.Build, "build", "", 0, "The build number to stop, defaults to the last build for Jenkins or to the only running build for Tekton")
 	cmd.Flags().StringVarP(&options.Filter, "filter", "f", "", "Filters all the available jobs by those that contain the given text")
 	options.JenkinsSelector.AddFlags(cmd)
 
@@ -219,6 +223,12 @@ func (o *StopPipelineOptions) cancelPipelineRun() error {
 	}
 	for _, a := range args {
 		pr := m[a]
+		if pr == nil {
+			pr, err = o.findRunningPipelineRun(a, names, m)
+			if err != nil {
+				return err
+			}
+		}
 		if pr == nil {
 			return fmt.Errorf("no PipelineRun found for name %s", a)
 		}
@@ -238,3 +248,27 @@ func (o *StopPipelineOptions) cancelPipelineRun() error {
 	}
 	return nil
 }
+
+// findRunningPipelineRun finds the running PipelineRun for a pipeline specified as owner/repo/branch using the build
+// number if specified
+func (o *StopPipelineOptions) findRunningPipelineRun(pipeline string, names []string, m map[string]*pipelineapi.PipelineRun) (*pipelineapi.PipelineRun, error) {
+	prefix := pipeline + " #"
+	if o.Build > 0 {
+		prefix = fmt.Sprintf("%s%d", prefix, o.Build)
+	}
+	matches := []string{}
+	for _, name := range names {
+		if name == prefix || strings.HasPrefix(name, prefix+"-") || (o.Build <= 0 && strings.HasPrefix(name, prefix)) {
+			matches = append(matches, name)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return nil, nil
+	case 1:
+		return m[matches[0]], nil
+	default:
+		return nil, fmt.Errorf("pipeline %s matches several running PipelineRuns %s, please specify one of them or the build number using --build",
+			pipeline, strings.Join(matches, ", "))
+	}
+}
@@ -0,0 +1,54 @@
+// +build unit
+
+package stop
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	pipelineapi "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestFindRunningPipelineRun(t *testing.T) {
+	m := map[string]*pipelineapi.PipelineRun{}
+	names := []string{
+		"foo/bar/PR-1 #3",
+		"foo/bar/master #4-lint",
+		"foo/bar/master #4-release",
+		"foo/bar/master #12",
+		"foo/other/master #7",
+	}
+	for _, name := range names {
+		m[name] = &pipelineapi.PipelineRun{ObjectMeta: metav1.ObjectMeta{Name: name}}
+	}
+
+	o := &StopPipelineOptions{}
+	pr, err := o.findRunningPipelineRun("foo/bar/PR-1", names, m)
+	require.NoError(t, err)
+	require.NotNil(t, pr)
+	assert.Equal(t, "foo/bar/PR-1 #3", pr.Name, "the only running build should be found")
+
+	pr, err = o.findRunningPipelineRun("foo/missing/master", names, m)
+	require.NoError(t, err)
+	assert.Nil(t, pr)
+
+	_, err = o.findRunningPipelineRun("foo/bar/master", names, m)
+	assert.Error(t, err, "several running builds should need the build number")
+
+	o.Build = 12
+	pr, err = o.findRunningPipelineRun("foo/bar/master", names, m)
+	require.NoError(t, err)
+	require.NotNil(t, pr)
+	assert.Equal(t, "foo/bar/master #12", pr.Name)
+
+	o.Build = 1
+	pr, err = o.findRunningPipelineRun("foo/bar/master", names, m)
+	require.NoError(t, err)
+	assert.Nil(t, pr, "build 1 should not match build 12")
+
+	o.Build = 4
+	_, err = o.findRunningPipelineRun("foo/bar/master", names, m)
+	assert.Error(t, err, "a build with several contexts should be ambiguous")
+}
@@ -20,12 +20,14 @@ import (
 	jenkinsv1 "github.com/jenkins-x/jx/v2/pkg/apis/jenkins.io/v1"
 	cmd_test "github.com/jenkins-x/jx/v2/pkg/cmd/clients/mocks"
 	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/v2/pkg/environments"
 	"github.com/jenkins-x/jx/v2/pkg/gits"
 	"github.com/jenkins-x/jx/v2/pkg/helm"
 	helm_test "github.com/jenkins-x/jx/v2/pkg/helm/mocks"
 	"github.com/jenkins-x/jx/v2/pkg/io/secrets"
 	"github.com/jenkins-x/jx/v2/pkg/kube"
 	vault_test "github.com/jenkins-x/jx/v2/pkg/vault/mocks"
+	"github.com/jenkins-x/jx/v2/pkg/versionstream"
 	"github.com/petergtz/pegomock"
 	uuid "github.com/satori/go.uuid"
 	"github.com/stretchr/testify/assert"
@@ -193,6 +195,42 @@ func (o *AppTestOptions) DirectlyAddAppToGitOps(appName string, values map[strin
 	return name, alias, version, nil
 }
 
+// DirectlySetUpgradePolicyInGitOps sets the upgrade policy of the app in the metadata of the app in the dev env repo
+// without using a pull request
+func (o *AppTestOptions) DirectlySetUpgradePolicyInGitOps(name string, policy environments.UpgradePolicy) error {
+	dir := o.DevEnvRepo.CloneDir
+
+	err := o.CommonOptions.Git().Checkout(dir, "master")
+	if err != nil {
+		return errors.Wrapf(err, "checking out master")
+	}
+	err = os.MkdirAll(filepath.Join(dir, name), 0700)
+	if err != nil {
+		return err
+	}
+	err = environments.SaveAppMetadata(dir, name, &environments.AppMetadata{
+		UpgradePolicy: policy,
+	})
+	if err != nil {
+		return err
+	}
+	err = o.CommonOptions.Git().Add(dir, filepath.Join(name, environments.AppMetadataFileName))
+	if err != nil {
+		return errors.Wrapf(err, "adding the metadata of %s to git", name)
+	}
+	err = o.CommonOptions.Git().CommitDir(dir, fmt.Sprintf("directly setting the upgrade policy of %s", name))
+	if err != nil {
+		return errors.Wrapf(err, "running git commit in %s", dir)
+	}
+
+	// Go back to a detached head
+	err = o.CommonOptions.Git().Checkout(dir, "--detach")
+	if err != nil {
+		return errors.Wrapf(err, "detaching from master")
+	}
+	return nil
+}
+
 // Cleanup must be run in a defer statement whenever CreateAppTestOptions is run
 func (o *AppTestOptions) Cleanup() error {
 	err := CleanupTestEnvironmentDir(o.CommonOptions)
@@ -323,6 +361,11 @@ func CreateAppTestOptions(gitOps bool, appName string, t assert.TestingT) *AppTe
 		installerMock,
 	)
 
+	// an empty version stream so that apps are upgraded to the versions of the charts stubbed by the tests
+	o.CommonOptions.SetVersionResolver(&versionstream.VersionResolver{
+		VersionsDir: filepath.Join(tempJxHome, "versions"),
+	})
+
 	err = CreateTestEnvironmentDir(o.CommonOptions)
 	assert.NoError(t, err)
 	o.FakeGitProvider = fakeGitProvider
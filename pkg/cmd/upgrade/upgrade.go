@@ -26,6 +26,9 @@ var (
 
 		# upgrade extensions
 		jx upgrade extensions 
+
+		# upgrade the build packs of the team pinned in the version stream
+		jx upgrade buildpacks
 	`)
 )
 
@@ -57,6 +60,7 @@ func NewCmdUpgrade(commonOpts *opts.CommonOptions) *cobra.Command {
 	cmd.AddCommand(NewCmdUpgradeApps(commonOpts))
 	cmd.AddCommand(NewCmdUpgradeCRDs(commonOpts))
 	cmd.AddCommand(NewCmdUpgradeBoot(commonOpts))
+	cmd.AddCommand(NewCmdUpgradeBuildPacks(commonOpts))
 
 	return cmd
 }
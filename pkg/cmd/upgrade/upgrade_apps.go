@@ -20,6 +20,15 @@ import (
 var (
 	upgradeAppsLong = templates.LongDesc(`
 		Upgrades Apps to newer releases (an app is similar to an addon)
+
+		When using GitOps each app is upgraded according to the upgrade policy in its metadata.yaml file in the environment
+		repository, which can be set when adding the app with 'jx add app --upgrade-policy':
+
+		* auto (the default) apps are upgraded to the version in the version stream, or the latest version if the app
+		  is not in the version stream
+		* manual apps are upgraded in a Pull Request of their own which is never merged automatically, so that one app
+		  cannot hold up the upgrade of the other apps
+		* pinned apps are only upgraded when a --version is specified
 `)
 
 	upgradeAppsExample = templates.Examples(`
@@ -170,6 +179,12 @@ func (o *UpgradeAppsOptions) Run() error {
 
 		installOpts.GitProvider = gitProvider
 		installOpts.Gitter = o.Git()
+
+		resolver, err := o.GetVersionResolver()
+		if err != nil {
+			return errors.Wrap(err, "creating the version resolver")
+		}
+		installOpts.VersionResolver = resolver
 	}
 	if !o.GitOps {
 		msg := "Unable to specify --%s when NOT using GitOps for your dev environment"
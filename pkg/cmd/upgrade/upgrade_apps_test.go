@@ -16,6 +16,7 @@ import (
 	"github.com/jenkins-x/jx/v2/pkg/cmd/upgrade"
 
 	"github.com/jenkins-x/jx/v2/pkg/cmd/add"
+	"github.com/jenkins-x/jx/v2/pkg/environments"
 
 	helm_test "github.com/jenkins-x/jx/v2/pkg/helm/mocks"
 	"github.com/jenkins-x/jx/v2/pkg/kube"
@@ -697,3 +698,61 @@ func TestUpgradeAllAppsForGitOps(t *testing.T) {
 	}
 	assert.Len(t, found, 2)
 }
+
+func TestUpgradeAllAppsHonoursUpgradePolicyForGitOps(t *testing.T) {
+	testOptions := testhelpers.CreateAppTestOptions(true, "", t)
+	defer func() {
+		err := testOptions.Cleanup()
+		assert.NoError(t, err)
+	}()
+	name1, alias1, version1, err := testOptions.DirectlyAddAppToGitOps("", nil, "")
+	assert.NoError(t, err)
+	name2, alias2, version2, err := testOptions.DirectlyAddAppToGitOps("", nil, "")
+	assert.NoError(t, err)
+	err = testOptions.DirectlySetUpgradePolicyInGitOps(name1, environments.UpgradePolicyPinned)
+	assert.NoError(t, err)
+
+	newVersion2, err := semver.Parse(version2)
+	assert.NoError(t, err)
+	newVersion2.Minor++
+
+	commonOpts := *testOptions.CommonOptions
+	o := &upgrade.UpgradeAppsOptions{
+		AddOptions: add.AddOptions{
+			CommonOptions: &commonOpts,
+		},
+		Repo:       helm.FakeChartmusuem,
+		GitOps:     true,
+		HelmUpdate: true,
+		DevEnv:     testOptions.DevEnv,
+	}
+	envDir, err := o.CommonOptions.EnvironmentsDir()
+	assert.NoError(t, err)
+	devEnvDir := testOptions.GetFullDevEnvDir(envDir)
+	o.CloneDir = devEnvDir
+
+	helm_test.StubFetchChart(name2, "",
+		helm.FakeChartmusuem, &chart.Chart{
+			Metadata: &chart.Metadata{
+				Name:    name2,
+				Version: newVersion2.String(),
+			},
+		}, testOptions.MockHelmer)
+
+	err = o.Run()
+	assert.NoError(t, err)
+	pr, err := testOptions.FakeGitProvider.GetPullRequest(testOptions.OrgName, testOptions.DevEnvRepoInfo, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, fmt.Sprintf("Upgrade all apps:\n\n* %s from %s to %s", name2, version2, newVersion2.String()),
+		pr.Body)
+	requirements, err := helm.LoadRequirementsFile(filepath.Join(devEnvDir, helm.RequirementsFileName))
+	assert.NoError(t, err)
+	for _, d := range requirements.Dependencies {
+		if d.Name == name1 && d.Alias == alias1 {
+			assert.Equal(t, version1, d.Version, "the pinned app should not be upgraded")
+		}
+		if d.Name == name2 && d.Alias == alias2 {
+			assert.Equal(t, newVersion2.String(), d.Version)
+		}
+	}
+}
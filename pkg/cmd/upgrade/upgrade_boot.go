@@ -5,7 +5,9 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/jenkins-x/jx/v2/pkg/boot"
 
@@ -13,6 +15,8 @@ import (
 	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
 	"github.com/jenkins-x/jx/v2/pkg/cmd/templates"
 	"github.com/jenkins-x/jx/v2/pkg/config"
+	"github.com/jenkins-x/jx/v2/pkg/environments"
+	"github.com/jenkins-x/jx/v2/pkg/gitops/batch"
 	"github.com/jenkins-x/jx/v2/pkg/gits"
 	"github.com/jenkins-x/jx/v2/pkg/gits/operations"
 	"github.com/jenkins-x/jx/v2/pkg/helm"
@@ -32,17 +36,37 @@ type UpgradeBootOptions struct {
 	UpgradeVersionStreamRef string
 	LatestRelease           bool
 	Labels                  []string
+	Draft                   bool
+	AutoMerge               bool
+	MergeQueue              bool
+	Wait                    bool
+	WaitTimeout             time.Duration
+	PollInterval            time.Duration
+	ReportPullRequest       bool
+
+	namingConventions *config.PullRequestsConfig
+	upgradeVersionRef string
+	provenance        *gits.Provenance
 }
 
 var (
 	upgradeBootLong = templates.LongDesc(`
 		This command creates a pr for upgrading a jx boot gitOps cluster, incorporating changes to the boot
         config and version stream ref
+
+		A pr upgrading the version stream ref is also created on the environment repository of each remote cluster
+		declared in the clusters section of the requirements
 `)
 
 	upgradeBootExample = templates.Examples(`
 		# create pr for upgrading a jx boot gitOps cluster
 		jx upgrade boot
+
+		# create pr which is merged once its checks pass
+		jx upgrade boot --auto-merge
+
+		# create pr which is merged once its checks pass and wait for it to merge
+		jx upgrade boot --auto-merge --wait
 `)
 
 	filesExcludedFromCherryPick = []string{
@@ -77,6 +101,13 @@ func NewCmdUpgradeBoot(commonOpts *opts.CommonOptions) *cobra.Command {
 	cmd.Flags().StringVarP(&options.UpgradeVersionStreamRef, "upgrade-version-stream-ref", "", config.DefaultVersionsRef, "a version stream ref to use to upgrade to")
 	cmd.Flags().BoolVarP(&options.LatestRelease, "latest-release", "", false, "upgrade to latest release tag")
 	cmd.Flags().StringArrayVarP(&options.Labels, "labels", "", []string{}, "Labels to add to the generated upgrade PR")
+	cmd.Flags().BoolVarP(&options.Draft, "draft", "", false, "Creates the upgrade PR as a draft if the git provider supports it")
+	cmd.Flags().BoolVarP(&options.AutoMerge, "auto-merge", "", false, "Makes the git provider merge the upgrade PR once its checks pass")
+	cmd.Flags().BoolVarP(&options.MergeQueue, "merge-queue", "", false, "Adds the upgrade PR to the merge queue of the git provider, falling back to --auto-merge if it has none")
+	cmd.Flags().BoolVarP(&options.Wait, "wait", "", false, "Waits for the upgrade PR to merge, failing if any of its checks fail or it is closed")
+	cmd.Flags().DurationVarP(&options.WaitTimeout, "wait-timeout", "", time.Hour, "The maximum duration to wait for the upgrade PR to merge")
+	cmd.Flags().DurationVarP(&options.PollInterval, "poll-interval", "", gits.DefaultPullRequestPollInterval, "How often the upgrade PR is checked while waiting for it to merge")
+	cmd.Flags().BoolVarP(&options.ReportPullRequest, "report-pull-request", "", false, "Comments the result on the PR which triggered this pipeline, found from $REPO_OWNER, $REPO_NAME and $PULL_NUMBER, such as when running the "+boot.UpgradeCommand+" ChatOps command")
 
 	return cmd
 }
@@ -95,18 +126,34 @@ func (o *UpgradeBootOptions) Run() error {
 		}
 	}
 
-	requirements, requirementsFile, err := config.LoadRequirementsConfig(o.Dir, config.DefaultFailOnValidationError)
+	requirements, requirementsFile, err := config.LoadRequirementsConfigWithOptions(o.Dir, config.DefaultFailOnValidationError, o.RequirementsOptions())
 	if err != nil {
 		return errors.Wrapf(err, "failed to load requirements config %s", requirementsFile)
 	}
+	err = o.ConfigureCommitSigning(requirements)
+	if err != nil {
+		return errors.Wrap(err, "failed to configure commit signing")
+	}
+	o.namingConventions = requirements.PullRequests
 	reqsVersionStream := requirements.VersionStream
 	upgradeVersionRef, err := o.upgradeAvailable(reqsVersionStream.URL, reqsVersionStream.Ref, o.UpgradeVersionStreamRef)
 	if err != nil {
 		return errors.Wrap(err, "failed to get check for available update")
 	}
 	if upgradeVersionRef == "" {
-		return nil
+		return o.reportPullRequest(nil, "The boot configuration is already up to date.")
+	}
+	o.upgradeVersionRef = upgradeVersionRef
+	o.provenance = o.PullRequestProvenance("upgrade")
+	o.provenance.VersionStreamURL = reqsVersionStream.URL
+	o.provenance.VersionStreamRef = upgradeVersionRef
+
+	// render the environment chart before upgrading it so the resource changes can be commented on the pull request
+	manifestDiff, err := environments.NewManifestDiff(o.Helm(), o.Dir, requirements.Cluster.Namespace)
+	if err != nil {
+		log.Logger().Warnf("Not commenting the resource changes on the pull request: %s", err)
 	}
+	defer manifestDiff.Cleanup()
 
 	localBranch, err := o.checkoutNewBranch()
 	if err != nil {
@@ -143,7 +190,7 @@ func (o *UpgradeBootOptions) Run() error {
 	}
 
 	// load modified requirements so we can merge with the base ones
-	modifiedRequirements, modifiedRequirementsFile, err := config.LoadRequirementsConfig(o.Dir, config.DefaultFailOnValidationError)
+	modifiedRequirements, modifiedRequirementsFile, err := config.LoadRequirementsConfigWithOptions(o.Dir, config.DefaultFailOnValidationError, o.RequirementsOptions())
 	if err != nil {
 		return errors.Wrapf(err, "failed to load requirements config %s", modifiedRequirementsFile)
 	}
@@ -158,15 +205,40 @@ func (o *UpgradeBootOptions) Run() error {
 		return errors.Wrap(err, "failed to create a merge commit for jx-requirements.yml")
 	}
 
-	err = o.raisePR()
+	prInfo, err := o.raisePR(requirements.PullRequests)
 	if err != nil {
 		return errors.Wrap(err, "failed to raise pr")
 	}
 
+	if manifestDiff != nil && prInfo != nil && prInfo.PullRequest != nil {
+		err = manifestDiff.Comment(o.Dir, prInfo.GitProvider, prInfo.PullRequest)
+		if err != nil {
+			log.Logger().Warnf("Failed to comment the resource changes on the pull request: %s", err)
+		}
+	}
+
 	err = o.deleteLocalBranch(localBranch)
 	if err != nil {
 		return errors.Wrapf(err, "failed to delete local branch %s", localBranch)
 	}
+
+	err = o.upgradeRemoteClusters(requirements)
+	if err != nil {
+		return errors.Wrap(err, "failed to upgrade the remote clusters")
+	}
+
+	if prInfo != nil && prInfo.PullRequest != nil {
+		err = o.reportPullRequest(prInfo.GitProvider, fmt.Sprintf("Created the boot upgrade pull request %s", prInfo.PullRequest.URL))
+	} else {
+		err = o.reportPullRequest(nil, "There were no changes to upgrade the boot configuration with.")
+	}
+	if err != nil {
+		return err
+	}
+
+	if o.Wait && prInfo != nil && prInfo.PullRequest != nil {
+		return gits.WaitForPullRequestToMerge(prInfo.GitProvider, prInfo.PullRequest, o.WaitTimeout, o.PollInterval)
+	}
 	return nil
 }
 
@@ -176,7 +248,7 @@ func (o UpgradeBootOptions) createCommitForRequirements(requirementsFileName str
 		return errors.Wrap(err, "failed to list changed files")
 	}
 	if reqsChanged {
-		err := o.Git().AddCommitFiles(o.Dir, "Merge jx-requirements.yml", []string{requirementsFileName})
+		err := o.Git().AddCommitFiles(o.Dir, o.commitMessage("Merge jx-requirements.yml"), []string{requirementsFileName})
 		if err != nil {
 			return errors.Wrapf(err, "error creating a commit with the merged jx-requirements.yml file from dir %s",
 				requirementsFileName)
@@ -192,7 +264,7 @@ func (o UpgradeBootOptions) determineBootConfigURL(versionStreamURL string) (str
 	}
 
 	if bootConfigURL == "" {
-		requirements, requirementsFile, err := config.LoadRequirementsConfig(o.Dir, config.DefaultFailOnValidationError)
+		requirements, requirementsFile, err := config.LoadRequirementsConfigWithOptions(o.Dir, config.DefaultFailOnValidationError, o.RequirementsOptions())
 		if err != nil {
 			return "", errors.Wrapf(err, "failed to load requirements config %s", requirementsFile)
 		}
@@ -259,7 +331,7 @@ func (o *UpgradeBootOptions) checkoutNewBranch() (string, error) {
 }
 
 func (o *UpgradeBootOptions) updateVersionStreamRef(upgradeRef string) error {
-	requirements, requirementsFile, err := config.LoadRequirementsConfig(o.Dir, config.DefaultFailOnValidationError)
+	requirements, requirementsFile, err := config.LoadRequirementsConfigWithOptions(o.Dir, config.DefaultFailOnValidationError, o.RequirementsOptions())
 	if err != nil {
 		return errors.Wrapf(err, "failed to load requirements file %s", requirementsFile)
 	}
@@ -267,11 +339,11 @@ func (o *UpgradeBootOptions) updateVersionStreamRef(upgradeRef string) error {
 	if requirements.VersionStream.Ref != upgradeRef {
 		log.Logger().Infof("Upgrading version stream ref to %s", util.ColorInfo(upgradeRef))
 		requirements.VersionStream.Ref = upgradeRef
-		err = requirements.SaveConfig(requirementsFile)
+		err = requirements.SaveConfigWithOptions(requirementsFile, o.RequirementsOptions())
 		if err != nil {
 			return errors.Wrapf(err, "failed to write version stream to %s", requirementsFile)
 		}
-		err = o.Git().AddCommitFiles(o.Dir, "feat: upgrade version stream", []string{requirementsFile})
+		err = o.Git().AddCommitFiles(o.Dir, o.commitMessage("feat: upgrade version stream"), []string{requirementsFile})
 		if err != nil {
 			return errors.Wrapf(err, "failed to commit requirements file %s", requirementsFile)
 		}
@@ -473,34 +545,167 @@ func (o *UpgradeBootOptions) setupGitConfig(dir string) error {
 	return nil
 }
 
-func (o *UpgradeBootOptions) raisePR() error {
+func (o *UpgradeBootOptions) raisePR(defaults *config.PullRequestsConfig) (*gits.PullRequestInfo, error) {
 	gitInfo, provider, _, err := o.CreateGitProvider(o.Dir)
 	if err != nil {
-		return errors.Wrap(err, "failed to get git provider")
+		return nil, errors.Wrap(err, "failed to get git provider")
 	}
 
 	upstreamInfo, err := provider.GetRepository(gitInfo.Organisation, gitInfo.Name)
 	if err != nil {
-		return errors.Wrapf(err, "getting repository %s/%s", gitInfo.Organisation, gitInfo.Name)
+		return nil, errors.Wrapf(err, "getting repository %s/%s", gitInfo.Organisation, gitInfo.Name)
 	}
 
 	details, filter, err := o.prDetailsAndFilter()
 	if err != nil {
-		return errors.Wrapf(err, "failed to get PR details and filter")
+		return nil, errors.Wrapf(err, "failed to get PR details and filter")
+	}
+	details.AddDefaultReviewers(defaults)
+	err = details.ApplyNamingConventions(defaults, o.namingValues(), o.Git())
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to apply the pull request naming conventions")
 	}
 
-	_, err = gits.PushRepoAndCreatePullRequest(o.Dir, upstreamInfo, nil, "master", &details, &filter, false, details.Title, true, false, o.Git(), provider)
+	b := &batch.Batch{
+		Base:       "master",
+		Labels:     filter.Labels,
+		SkipCommit: true,
+		Provenance: o.provenance,
+		Gitter:     o.Git(),
+	}
+	prInfo, err := b.CreatePullRequest(o.Dir, "", "master", upstreamInfo, nil, provider, &batch.Change{
+		Details:       &details,
+		CommitMessage: details.Title,
+	})
 	if err != nil {
-		return errors.Wrapf(err, "failed to create PR for base %s and head branch %s", "master", details.BranchName)
+		return nil, errors.Wrapf(err, "failed to create PR for base %s and head branch %s", "master", details.BranchName)
+	}
+	return prInfo, nil
+}
+
+// upgradeRemoteClusters creates a pull request on the environment repository of each remote cluster in the requirements
+// upgrading it to the same version stream ref as the development cluster
+func (o *UpgradeBootOptions) upgradeRemoteClusters(requirements *config.RequirementsConfig) error {
+	for _, cluster := range requirements.Clusters {
+		if cluster.EnvironmentGitURL == "" {
+			log.Logger().Warnf("Not upgrading remote cluster %s as it has no environmentGitUrl", cluster.Name)
+			continue
+		}
+		pro := operations.PullRequestOperation{
+			CommonOptions:     o.CommonOptions,
+			GitURLs:           []string{cluster.EnvironmentGitURL},
+			BranchName:        "jx_boot_upgrade",
+			Component:         "boot",
+			Version:           o.upgradeVersionRef,
+			SkipAutoMerge:     !o.AutoMerge,
+			Labels:            append([]string{boot.PullRequestLabel}, o.Labels...),
+			Draft:             o.Draft,
+			NamingConventions: requirements.PullRequests,
+			Provenance:        o.provenance,
+		}
+		prInfo, err := pro.CreatePullRequest("versionStream", o.updateRemoteVersionStreamRefFn())
+		if err != nil {
+			return errors.Wrapf(err, "failed to create the upgrade pull request for remote cluster %s", cluster.Name)
+		}
+		if prInfo != nil && prInfo.PullRequest != nil {
+			log.Logger().Infof("Created the upgrade pull request %s for remote cluster %s", util.ColorInfo(prInfo.PullRequest.URL), util.ColorInfo(cluster.Name))
+		}
 	}
 	return nil
 }
 
+// updateRemoteVersionStreamRefFn returns the function upgrading the version stream ref of the requirements of a remote
+// cluster. The profiles of the development cluster do not apply to the remote requirements so are not used
+func (o *UpgradeBootOptions) updateRemoteVersionStreamRefFn() operations.ChangeFilesFn {
+	return func(dir string, gitInfo *gits.GitRepository) ([]string, error) {
+		requirements, fileName, err := config.LoadRequirementsConfigWithOptions(dir, config.DefaultFailOnValidationError, config.RequirementsOptions{})
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to load the requirements of %s", gitInfo.URL)
+		}
+		oldRef := requirements.VersionStream.Ref
+		if oldRef == o.upgradeVersionRef {
+			return nil, nil
+		}
+		requirements.VersionStream.Ref = o.upgradeVersionRef
+		err = requirements.SaveConfigWithOptions(fileName, config.RequirementsOptions{})
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to save %s", fileName)
+		}
+		return []string{oldRef}, nil
+	}
+}
+
+// reportPullRequest comments on the pull request which triggered the pipeline running this command if enabled
+func (o *UpgradeBootOptions) reportPullRequest(provider gits.GitProvider, comment string) error {
+	if !o.ReportPullRequest {
+		return nil
+	}
+	owner := os.Getenv("REPO_OWNER")
+	repo := os.Getenv("REPO_NAME")
+	number, err := strconv.Atoi(os.Getenv("PULL_NUMBER"))
+	if owner == "" || repo == "" || err != nil {
+		log.Logger().Warnf("cannot report the result as $REPO_OWNER, $REPO_NAME and $PULL_NUMBER do not identify a pull request")
+		return nil
+	}
+	if provider == nil {
+		_, provider, _, err = o.CreateGitProvider(o.Dir)
+		if err != nil {
+			return errors.Wrap(err, "failed to get git provider")
+		}
+	}
+	pr := &gits.GitPullRequest{
+		Owner:  owner,
+		Repo:   repo,
+		Number: &number,
+	}
+	err = provider.AddPRComment(pr, comment)
+	if err != nil {
+		return errors.Wrapf(err, "commenting on pull request %s/%s #%d", owner, repo, number)
+	}
+	return nil
+}
+
+// namingValues returns the values available to the naming conventions of the upgrade pull request and its commits
+func (o *UpgradeBootOptions) namingValues() gits.PullRequestNamingValues {
+	return gits.PullRequestNamingValues{
+		Kind:        "upgrade",
+		Version:     o.upgradeVersionRef,
+		Component:   "boot",
+		Environment: kube.LabelValueDevEnvironment,
+	}
+}
+
+// commitMessage returns the message of a commit of the upgrade following the commit message conventions of the team,
+// with the provenance of the upgrade as trailers
+func (o *UpgradeBootOptions) commitMessage(message string) string {
+	details := gits.PullRequestDetails{
+		CommitMessage: message,
+	}
+	err := details.ApplyNamingConventions(&config.PullRequestsConfig{
+		CommitMessageTemplate: o.commitMessageTemplate(),
+	}, o.namingValues(), nil)
+	if err != nil {
+		log.Logger().Warnf("failed to apply the commit message conventions to %q: %s", message, err)
+		return o.provenance.AddTrailers(message)
+	}
+	return o.provenance.AddTrailers(details.CommitMessage)
+}
+
+func (o *UpgradeBootOptions) commitMessageTemplate() string {
+	if o.namingConventions == nil {
+		return ""
+	}
+	return o.namingConventions.CommitMessageTemplate
+}
+
 func (o *UpgradeBootOptions) prDetailsAndFilter() (gits.PullRequestDetails, gits.PullRequestFilter, error) {
 	details := gits.PullRequestDetails{
 		BranchName: fmt.Sprintf("jx_boot_upgrade"),
 		Title:      "feat(config): upgrade configuration",
 		Message:    "Upgrade configuration",
+		Draft:      o.Draft,
+		AutoMerge:  o.AutoMerge,
+		MergeQueue: o.MergeQueue,
 	}
 
 	labels := []string{}
@@ -599,7 +804,7 @@ func (o *UpgradeBootOptions) updatePipelineBuilderImage(resolver *versionstream.
 			return errors.Wrapf(err, "failed to build path for pipeline file %s", match)
 		}
 	}
-	err = o.Git().AddCommitFiles(o.Dir, "feat: upgrade pipeline builder images", matches)
+	err = o.Git().AddCommitFiles(o.Dir, o.commitMessage("feat: upgrade pipeline builder images"), matches)
 	if err != nil {
 		log.Logger().Info("Skipping builder image update as no changes were detected")
 	}
@@ -621,7 +826,7 @@ func (o *UpgradeBootOptions) updateTemplateBuilderImage(resolver *versionstream.
 	if err != nil {
 		return errors.WithStack(err)
 	}
-	err = o.Git().AddCommitFiles(o.Dir, "feat: upgrade template builder images", []string{templateFile})
+	err = o.Git().AddCommitFiles(o.Dir, o.commitMessage("feat: upgrade template builder images"), []string{templateFile})
 	if err != nil {
 		log.Logger().Info("Skipping template builder image update as no changes were detected")
 	}
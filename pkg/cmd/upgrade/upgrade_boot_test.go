@@ -287,3 +287,30 @@ func TestUpdateVersionStreamRefAlternative(t *testing.T) {
 
 	assert.Equal(t, "22222222", vs.Ref, "UpdateVersionStreamRef Ref")
 }
+
+func TestUpdateRemoteVersionStreamRef(t *testing.T) {
+	t.Parallel()
+
+	o := TestUpgradeBootOptions{}
+	o.setup(defaultBootRequirements, "", "", "")
+
+	tmpDir := o.createTmpRequirements(t)
+	defer func() {
+		err := os.RemoveAll(tmpDir)
+		require.NoError(t, err, "could not clean up temp jx-requirements")
+	}()
+
+	o.upgradeVersionRef = "22222222"
+	fn := o.updateRemoteVersionStreamRefFn()
+	oldRefs, err := fn(tmpDir, &gits.GitRepository{URL: "https://github.com/foo/environment-prod.git"})
+	require.NoError(t, err, "could not update remote version stream ref")
+	assert.Equal(t, []string{"2367726d02b8c"}, oldRefs)
+
+	requirements, _, err := config.LoadRequirementsConfig(tmpDir, config.DefaultFailOnValidationError)
+	require.NoError(t, err, "could not get requirements file")
+	assert.Equal(t, "22222222", requirements.VersionStream.Ref, "remote VersionStream Ref")
+
+	oldRefs, err = fn(tmpDir, &gits.GitRepository{URL: "https://github.com/foo/environment-prod.git"})
+	require.NoError(t, err, "could not update remote version stream ref")
+	assert.Empty(t, oldRefs, "an up to date remote cluster should not be changed")
+}
@@ -0,0 +1,174 @@
+package upgrade
+
+import (
+	"fmt"
+
+	"github.com/jenkins-x/jx/v2/pkg/cmd/helper"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/opts"
+	"github.com/jenkins-x/jx/v2/pkg/cmd/templates"
+	"github.com/jenkins-x/jx/v2/pkg/config"
+	"github.com/jenkins-x/jx/v2/pkg/gits"
+	"github.com/jenkins-x/jx/v2/pkg/gits/operations"
+	"github.com/jenkins-x/jx/v2/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/jenkins-x/jx/v2/pkg/versionstream"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+var (
+	upgradeBuildPacksLong = templates.LongDesc(`
+		Upgrades the build packs of the team by creating a Pull Request on the version stream of the team which
+		changes the pinned Git reference of the build packs repository.
+
+		The team should resolve its build packs from the version stream, which is enabled via:
+
+		    jx edit buildpack --url https://github.com/myorg/jenkins-x-kubernetes.git --version-stream
+
+		so that a team using its own fork of the build packs upgrades it like any other version rather than using a
+		stale copy. The version defaults to the tag of the latest release of the build packs repository.
+`)
+
+	upgradeBuildPacksExample = templates.Examples(`
+		# Creates a Pull Request upgrading the build packs to their latest release
+		jx upgrade buildpacks
+
+		# Creates a Pull Request upgrading the build packs to a tag
+		jx upgrade buildpacks --version v1.2.3
+	`)
+)
+
+// UpgradeBuildPacksOptions the options for the upgrade build packs command
+type UpgradeBuildPacksOptions struct {
+	UpgradeOptions
+
+	Version string
+	Base    string
+	DryRun  bool
+}
+
+// NewCmdUpgradeBuildPacks defines the command
+func NewCmdUpgradeBuildPacks(commonOpts *opts.CommonOptions) *cobra.Command {
+	options := &UpgradeBuildPacksOptions{
+		UpgradeOptions: UpgradeOptions{
+			CommonOptions: commonOpts,
+		},
+	}
+
+	cmd := &cobra.Command{
+		Use:     "buildpacks",
+		Short:   "Creates a Pull Request on the version stream upgrading the build packs of the team",
+		Aliases: []string{"buildpack", "bp"},
+		Long:    upgradeBuildPacksLong,
+		Example: upgradeBuildPacksExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			options.Cmd = cmd
+			options.Args = args
+			err := options.Run()
+			helper.CheckErr(err)
+		},
+	}
+	cmd.Flags().StringVarP(&options.Version, "version", "v", "", "The Git reference of the build packs to upgrade to. Defaults to the tag of their latest release")
+	cmd.Flags().StringVarP(&options.Base, "base", "", "", "The branch of the version stream to create the Pull Request on. Defaults to the version stream ref of the team")
+	cmd.Flags().BoolVarP(&options.DryRun, "dry-run", "", false, "Makes the change to a clone of the version stream without creating the Pull Request")
+	return cmd
+}
+
+// Run implements the command
+func (o *UpgradeBuildPacksOptions) Run() error {
+	settings, err := o.TeamSettings()
+	if err != nil {
+		return errors.Wrap(err, "loading the team settings")
+	}
+	buildPackURL := settings.BuildPackURL
+	if !settings.BuildPackVersionStream {
+		return fmt.Errorf("the build packs %s of the team use the fixed reference %s rather than the version stream. Use 'jx edit buildpack --version-stream' first", buildPackURL, settings.BuildPackRef)
+	}
+
+	version := o.Version
+	if version == "" {
+		provider, gitInfo, err := o.CreateGitProviderForURLWithoutKind(buildPackURL)
+		if err != nil {
+			return errors.Wrapf(err, "creating the git provider for %s", buildPackURL)
+		}
+		release, err := provider.GetLatestRelease(gitInfo.Organisation, gitInfo.Name)
+		if err != nil {
+			return errors.Wrapf(err, "finding the latest release of %s. Use --version to choose the version", buildPackURL)
+		}
+		if release == nil || release.TagName == "" {
+			return fmt.Errorf("there are no releases of %s. Use --version to choose the version", buildPackURL)
+		}
+		version = release.TagName
+	}
+
+	resolver, err := o.GetVersionResolver()
+	if err != nil {
+		return errors.Wrap(err, "creating the version stream resolver")
+	}
+	current, err := resolver.StableVersionNumber(versionstream.KindGit, buildPackURL)
+	if err != nil {
+		return errors.Wrapf(err, "loading the version of %s from the version stream", buildPackURL)
+	}
+	if current == version {
+		log.Logger().Infof("The build packs %s are already at version %s", util.ColorInfo(buildPackURL), util.ColorInfo(version))
+		return nil
+	}
+
+	versionStreamURL := settings.VersionStreamURL
+	if versionStreamURL == "" {
+		versionStreamURL = config.DefaultVersionsURL
+	}
+	base := o.Base
+	if base == "" {
+		base = settings.VersionStreamRef
+	}
+	if base == "" {
+		base = config.DefaultVersionsRef
+	}
+	pro := operations.PullRequestOperation{
+		CommonOptions: o.CommonOptions,
+		GitURLs:       []string{versionStreamURL},
+		SrcGitURL:     buildPackURL,
+		Base:          base,
+		Version:       version,
+		DryRun:        o.DryRun,
+	}
+	authorName, authorEmail, _ := gits.EnsureUserAndEmailSetup(o.Git())
+	if authorName != "" && authorEmail != "" {
+		pro.AuthorName = authorName
+		pro.AuthorEmail = authorEmail
+	}
+	log.Logger().Infof("Upgrading the build packs %s from %s to %s in the version stream %s", util.ColorInfo(buildPackURL), util.ColorInfo(current), util.ColorInfo(version), util.ColorInfo(versionStreamURL))
+	info, err := pro.CreatePullRequest("buildpacks", CreateBuildPacksVersionFn(buildPackURL, version))
+	if err != nil {
+		return errors.Wrapf(err, "creating the Pull Request on %s", versionStreamURL)
+	}
+	if info != nil && info.PullRequest != nil {
+		log.Logger().Infof("Created Pull Request %s", util.ColorInfo(info.PullRequest.URL))
+	}
+	return nil
+}
+
+// CreateBuildPacksVersionFn creates the ChangeFilesFn which pins the build packs repository to the version in the
+// version stream
+func CreateBuildPacksVersionFn(buildPackURL string, version string) operations.ChangeFilesFn {
+	return func(dir string, gitInfo *gits.GitRepository) ([]string, error) {
+		sv, err := versionstream.LoadStableVersion(dir, versionstream.KindGit, buildPackURL)
+		if err != nil {
+			return nil, errors.Wrapf(err, "loading the version of %s", buildPackURL)
+		}
+		oldVersions := []string{}
+		if sv.Version != "" {
+			oldVersions = append(oldVersions, sv.Version)
+		}
+		sv.Version = version
+		if sv.GitURL == "" {
+			sv.GitURL = buildPackURL
+		}
+		err = versionstream.SaveStableVersion(dir, versionstream.KindGit, versionstream.GitURLToName(buildPackURL), sv)
+		if err != nil {
+			return nil, errors.Wrapf(err, "saving the version of %s", buildPackURL)
+		}
+		return oldVersions, nil
+	}
+}
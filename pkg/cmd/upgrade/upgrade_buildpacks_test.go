@@ -0,0 +1,39 @@
+// +build unit
+
+package upgrade
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/jenkins-x/jx/v2/pkg/versionstream"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateBuildPacksVersionFn(t *testing.T) {
+	t.Parallel()
+	dir, err := ioutil.TempDir("", "upgrade-buildpacks")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	buildPackURL := "https://github.com/myorg/jenkins-x-kubernetes.git"
+	oldVersions, err := CreateBuildPacksVersionFn(buildPackURL, "v1.0.0")(dir, nil)
+	require.NoError(t, err)
+	assert.Empty(t, oldVersions)
+
+	sv, err := versionstream.LoadStableVersion(dir, versionstream.KindGit, buildPackURL)
+	require.NoError(t, err)
+	assert.Equal(t, "v1.0.0", sv.Version)
+	assert.Equal(t, buildPackURL, sv.GitURL)
+
+	oldVersions, err = CreateBuildPacksVersionFn(buildPackURL, "v1.1.0")(dir, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"v1.0.0"}, oldVersions)
+
+	resolver := &versionstream.VersionResolver{VersionsDir: dir}
+	version, err := resolver.ResolveGitVersion(buildPackURL)
+	require.NoError(t, err)
+	assert.Equal(t, "v1.1.0", version)
+}
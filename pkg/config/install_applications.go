@@ -41,6 +41,8 @@ type Application struct {
 	Namespace string `json:"namespace,omitempty"`
 	// Phase of the pipeline to install application
 	Phase Phase `json:"phase,omitempty"`
+	// Requires the names of the applications which must be installed before this application
+	Requires []string `json:"requires,omitempty"`
 }
 
 // Phase of the pipeline to install application
@@ -90,3 +92,68 @@ func LoadApplicationsConfig(dir string) (*ApplicationConfig, error) {
 
 	return config, err
 }
+
+// InstallOrder returns the applications in the order they should be installed so that every application is installed
+// after the applications it requires. Applications in the system phase are installed before those in the apps phase
+// so a system application cannot require an apps application. An error describing every missing requirement is
+// returned if an application requires one which is not configured, or if the requirements are circular.
+func (c *ApplicationConfig) InstallOrder() ([]Application, error) {
+	apps := map[string]Application{}
+	for _, app := range c.Applications {
+		apps[app.Name] = app
+	}
+	var problems []string
+	for _, app := range c.Applications {
+		for _, name := range app.Requires {
+			required, ok := apps[name]
+			if !ok {
+				problems = append(problems, fmt.Sprintf("%s requires %s which is not in %s", app.Name, name, ApplicationsConfigFileName))
+			} else if app.phase() == PhaseSystem && required.phase() == PhaseApps {
+				problems = append(problems, fmt.Sprintf("%s in phase %s requires %s which is installed later in phase %s",
+					app.Name, PhaseSystem, name, PhaseApps))
+			}
+		}
+	}
+	if len(problems) > 0 {
+		return nil, errors.Errorf("missing application requirements:\n%s", strings.Join(problems, "\n"))
+	}
+
+	var answer []Application
+	visited := map[string]bool{}
+	visiting := map[string]bool{}
+	var visit func(app Application, path []string) error
+	visit = func(app Application, path []string) error {
+		if visited[app.Name] {
+			return nil
+		}
+		path = append(path, app.Name)
+		if visiting[app.Name] {
+			return errors.Errorf("circular application requirements %s", strings.Join(path, " -> "))
+		}
+		visiting[app.Name] = true
+		for _, name := range app.Requires {
+			err := visit(apps[name], path)
+			if err != nil {
+				return err
+			}
+		}
+		visited[app.Name] = true
+		answer = append(answer, app)
+		return nil
+	}
+	for _, app := range c.Applications {
+		err := visit(app, nil)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return answer, nil
+}
+
+// phase returns the phase of the application defaulting to the apps phase
+func (a *Application) phase() Phase {
+	if a.Phase == "" {
+		return PhaseApps
+	}
+	return a.Phase
+}
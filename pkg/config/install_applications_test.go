@@ -33,3 +33,46 @@ func TestGoodPhase(t *testing.T) {
 	assert.Equal(t, "external-dns", apps.Applications[1].Name)
 	assert.Equal(t, PhaseApps, apps.Applications[1].Phase)
 }
+
+func TestInstallOrder(t *testing.T) {
+	apps := &ApplicationConfig{
+		Applications: []Application{
+			{Name: "jx-app-docs", Requires: []string{"cert-manager", "nginx-ingress"}},
+			{Name: "nginx-ingress", Phase: PhaseSystem},
+			{Name: "cert-manager", Phase: PhaseSystem, Requires: []string{"nginx-ingress"}},
+			{Name: "velero", Phase: PhaseSystem},
+		},
+	}
+	ordered, err := apps.InstallOrder()
+	assert.NoError(t, err)
+
+	var names []string
+	for _, app := range ordered {
+		names = append(names, app.Name)
+	}
+	assert.Equal(t, []string{"nginx-ingress", "cert-manager", "jx-app-docs", "velero"}, names)
+}
+
+func TestInstallOrderMissingRequirements(t *testing.T) {
+	apps := &ApplicationConfig{
+		Applications: []Application{
+			{Name: "jx-app-docs", Requires: []string{"cert-manager"}},
+			{Name: "external-dns", Phase: PhaseSystem, Requires: []string{"jx-app-docs"}},
+		},
+	}
+	_, err := apps.InstallOrder()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "jx-app-docs requires cert-manager which is not in jx-apps.yml")
+	assert.Contains(t, err.Error(), "external-dns in phase system requires jx-app-docs")
+}
+
+func TestInstallOrderCircularRequirements(t *testing.T) {
+	apps := &ApplicationConfig{
+		Applications: []Application{
+			{Name: "a", Requires: []string{"b"}},
+			{Name: "b", Requires: []string{"a"}},
+		},
+	}
+	_, err := apps.InstallOrder()
+	assert.EqualError(t, err, "circular application requirements a -> b -> a")
+}
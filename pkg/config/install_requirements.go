@@ -87,6 +87,8 @@ const (
 	RequirementIngressTLSProduction = "JX_REQUIREMENT_INGRESS_TLS_PRODUCTION"
 	// RequirementChartRepository the helm chart repository for jx
 	RequirementChartRepository = "JX_REQUIREMENT_CHART_REPOSITORY"
+	// RequirementChartKind the kind of helm chart repository for jx
+	RequirementChartKind = "JX_REQUIREMENT_CHART_KIND"
 	// RequirementRegistry the container registry for jx
 	RequirementRegistry = "JX_REQUIREMENT_REGISTRY"
 	// RequirementRepository the artifact repository for jx
@@ -135,10 +137,13 @@ const (
 	// SecretStorageTypeLocal specifies that we use the local file system in
 	// `~/.jx/localSecrets` to store secrets
 	SecretStorageTypeLocal SecretStorageType = "local"
+	// SecretStorageTypeExternalSecrets specifies that we use ExternalSecret resources of the External Secrets Operator
+	// to populate the Kubernetes Secrets from a cloud secret manager
+	SecretStorageTypeExternalSecrets SecretStorageType = "external-secrets"
 )
 
 // SecretStorageTypeValues the string values for the secret storage
-var SecretStorageTypeValues = []string{"local", "vault"}
+var SecretStorageTypeValues = []string{"local", "vault", "external-secrets"}
 
 // WebhookType is the type of a webhook strategy
 type WebhookType string
@@ -159,6 +164,51 @@ const (
 // WebhookTypeValues the string values for the webhook types
 var WebhookTypeValues = []string{"jenkins", "lighthouse", "prow"}
 
+// CommitSigningFormat is the kind of signature added to the commits made by jx
+type CommitSigningFormat string
+
+const (
+	// CommitSigningFormatGPG signs commits with a GPG key
+	CommitSigningFormatGPG CommitSigningFormat = "gpg"
+	// CommitSigningFormatSSH signs commits with a SSH key, which requires git 2.34 or later
+	CommitSigningFormatSSH CommitSigningFormat = "ssh"
+)
+
+// CommitSigningFormatValues the string values for the commit signing formats
+var CommitSigningFormatValues = []string{"gpg", "ssh"}
+
+// DeployEngineType is the engine used to deploy the charts of an environment
+type DeployEngineType string
+
+const (
+	// DeployEngineHelm deploys the environment as a single umbrella chart
+	DeployEngineHelm DeployEngineType = "helm"
+	// DeployEngineHelmfile deploys each chart of the environment as its own release using helmfile
+	DeployEngineHelmfile DeployEngineType = "helmfile"
+)
+
+// DeployEngineTypeValues the string values for the deploy engines
+var DeployEngineTypeValues = []string{"helm", "helmfile"}
+
+// ChartRepositoryType is the kind of chart repository which charts are released to
+type ChartRepositoryType string
+
+const (
+	// ChartRepositoryTypeChartMuseum releases charts to ChartMuseum, or bucketrepo, via the ChartMuseum API
+	ChartRepositoryTypeChartMuseum ChartRepositoryType = "chartmuseum"
+	// ChartRepositoryTypeNexus releases charts to a Sonatype Nexus helm hosted repository
+	ChartRepositoryTypeNexus ChartRepositoryType = "nexus"
+	// ChartRepositoryTypeArtifactory releases charts to an Artifactory helm repository
+	ChartRepositoryTypeArtifactory ChartRepositoryType = "artifactory"
+	// ChartRepositoryTypeHarbor releases charts to the chart repository of a Harbor project
+	ChartRepositoryTypeHarbor ChartRepositoryType = "harbor"
+	// ChartRepositoryTypeBucket releases charts to a GCS or S3 bucket maintaining the index of the repository in the bucket
+	ChartRepositoryTypeBucket ChartRepositoryType = "bucket"
+)
+
+// ChartRepositoryTypeValues the string values for the chart repository types
+var ChartRepositoryTypeValues = []string{"chartmuseum", "nexus", "artifactory", "harbor", "bucket"}
+
 // RepositoryType is the type of a repository we use to store artifacts (jars, tarballs, npm packages etc)
 type RepositoryType string
 
@@ -223,6 +273,56 @@ type EnvironmentConfig struct {
 	PromotionStrategy v1.PromotionStrategyType `json:"promotionStrategy,omitempty"`
 	// URLTemplate is the template to use for your environment's exposecontroller generated URLs
 	URLTemplate string `json:"urlTemplate,omitempty"`
+	// DeployEngine the engine used to deploy the charts of the environment. Defaults to helm
+	DeployEngine DeployEngineType `json:"deployEngine,omitempty"`
+	// Approval the approvals required before promotion pull requests for the environment can merge
+	Approval *PromotionApprovalConfig `json:"approval,omitempty"`
+	// Freezes the windows during which promotions to the environment are held back until the freeze ends
+	Freezes []FreezeWindowConfig `json:"freezes,omitempty"`
+}
+
+// PromotionApprovalConfig configures who has to approve promotions to an environment before their pull requests merge
+type PromotionApprovalConfig struct {
+	// Approvers the git user logins of the people allowed to approve promotions
+	Approvers []string `json:"approvers,omitempty"`
+	// RequiredApprovals the number of approvers who have to approve a promotion. Defaults to 1
+	RequiredApprovals int `json:"requiredApprovals,omitempty"`
+}
+
+// IsRequired returns true if promotions have to be approved
+func (c *PromotionApprovalConfig) IsRequired() bool {
+	return c != nil && len(c.Approvers) > 0
+}
+
+// RequiredCount returns the number of approvers who have to approve a promotion, which is at least one and at most
+// the number of approvers
+func (c *PromotionApprovalConfig) RequiredCount() int {
+	if !c.IsRequired() {
+		return 0
+	}
+	if c.RequiredApprovals <= 0 {
+		return 1
+	}
+	if c.RequiredApprovals > len(c.Approvers) {
+		return len(c.Approvers)
+	}
+	return c.RequiredApprovals
+}
+
+// RemoteClusterConfig describes a remote cluster which runs an environment separately from the development cluster
+type RemoteClusterConfig struct {
+	// Name the name of the remote cluster
+	Name string `json:"name"`
+	// Environment the key of the environment which runs on this cluster
+	Environment string `json:"environment"`
+	// Context the name of the kube context used to connect to the cluster
+	Context string `json:"context,omitempty"`
+	// Region the cloud region of the cluster
+	Region string `json:"region,omitempty"`
+	// Namespace the namespace of the environment in the cluster. Defaults to the namespace of the environment
+	Namespace string `json:"namespace,omitempty"`
+	// EnvironmentGitURL the git URL of the environment repository which the cluster is deployed from
+	EnvironmentGitURL string `json:"environmentGitUrl,omitempty"`
 }
 
 // IngressConfig contains dns specific requirements
@@ -246,6 +346,10 @@ type IngressConfig struct {
 	TLS TLSConfig `json:"tls"`
 	// DomainIssuerURL contains a URL used to retrieve a Domain
 	DomainIssuerURL string `json:"domainIssuerURL,omitempty"`
+	// Controller the ingress controller used to expose services. Defaults to nginx
+	Controller IngressControllerConfig `json:"controller,omitempty"`
+	// DNSProvider the DNS service used by external-dns. Defaults from the kubernetes provider
+	DNSProvider DNSProviderType `json:"dnsProvider,omitempty"`
 }
 
 // BuildPackConfig contains build pack info
@@ -308,6 +412,9 @@ type AzureConfig struct {
 	// RegistrySubscription the registry subscription for defaulting the container registry.
 	// Not used if you specify a Registry explicitly
 	RegistrySubscription string `json:"registrySubscription,omitempty"`
+	// WorkloadIdentityClientID the client ID of the managed identity federated with the service accounts when using
+	// AKS workload identity
+	WorkloadIdentityClientID string `json:"workloadIdentityClientID,omitempty"`
 }
 
 // GKEConfig contains GKE specific requirements
@@ -322,6 +429,9 @@ type ClusterConfig struct {
 	AzureConfig *AzureConfig `json:"azure,omitempty"`
 	// ChartRepository the repository URL to deploy charts to
 	ChartRepository string `json:"chartRepository,omitempty"`
+	// ChartKind the kind of chart repository charts are released to. When using a bucket the charts and the index
+	// of the repository are stored in the bucket of the repository storage
+	ChartKind ChartRepositoryType `json:"chartKind,omitempty"`
 	// GKEConfig the gke specific configuration
 	GKEConfig *GKEConfig `json:"gke,omitempty"`
 	// EnvironmentGitOwner the default git owner for environment repositories if none is specified explicitly
@@ -370,6 +480,10 @@ type ClusterConfig struct {
 	// If it's false, cluster wide permissions will be used, normal, namespaced permissions will be used otherwise
 	// and extra steps will be necessary to get the cluster working
 	StrictPermissions bool `json:"strictPermissions,omitempty"`
+	// WorkloadIdentity uses the workload identity of the cloud provider, such as GKE Workload Identity, EKS IAM Roles
+	// for Service Accounts or AKS workload identity, so that pipelines and components use ambient credentials
+	// instead of static keys stored in secrets
+	WorkloadIdentity bool `json:"workloadIdentity,omitempty"`
 }
 
 // VaultConfig contains Vault configuration for Boot
@@ -408,6 +522,39 @@ type VaultConfig struct {
 	// KubernetesAuthPath is the auth path of used for this cluster
 	// If not specified the 'kubernetes' is used.
 	KubernetesAuthPath string `json:"kubernetesAuthPath,omitempty"`
+
+	// KubernetesAuthRole is the Vault role used to login with the Kubernetes auth method.
+	// If not specified the name of the service account is used.
+	KubernetesAuthRole string `json:"kubernetesAuthRole,omitempty"`
+
+	// EnterpriseNamespace is the Vault Enterprise namespace containing the secret engine and auth method.
+	EnterpriseNamespace string `json:"enterpriseNamespace,omitempty"`
+
+	// AuthMethod is how to authenticate against an external Vault, either 'kubernetes' or 'token'.
+	// If not specified the 'kubernetes' is used.
+	AuthMethod string `json:"authMethod,omitempty"`
+
+	// TokenSecret is the name of the Kubernetes secret in the namespace containing the Vault token in its 'token'
+	// key when using the 'token' auth method.
+	TokenSecret string `json:"tokenSecret,omitempty"`
+
+	// Agent configures delivering secrets to pods with the Vault Agent sidecar injector rather than jx reading them
+	// from Vault when generating the charts
+	Agent *VaultAgentConfig `json:"agent,omitempty"`
+}
+
+// VaultAgentConfig configures the Vault Agent sidecar injector
+type VaultAgentConfig struct {
+	// Enabled installs the injector and annotates the pods to have the secrets they reference injected
+	Enabled bool `json:"enabled,omitempty"`
+
+	// Role is the Vault Kubernetes auth role the agents login with.
+	// If not specified the KubernetesAuthRole is used.
+	Role string `json:"role,omitempty"`
+
+	// PipelineSecrets are the path:key references of the secrets injected into the pipeline pods, such as
+	// mycluster/pipelineUser:token
+	PipelineSecrets []string `json:"pipelineSecrets,omitempty"`
 }
 
 // VaultAWSConfig contains all the Vault configuration needed by Vault to be deployed in AWS
@@ -505,6 +652,143 @@ type GithubAppConfig struct {
 	URL string `json:"url,omitempty"`
 }
 
+// CommitSigningConfig configures the signing of the commits jx makes to the GitOps repositories, such as when
+// booting, upgrading, promoting or updating dependencies, so that branches which require signed commits can be used
+type CommitSigningConfig struct {
+	// Enabled whether commits made by jx are signed
+	Enabled bool `json:"enabled"`
+	// Format the kind of key used to sign commits, gpg or ssh. Defaults to gpg
+	Format CommitSigningFormat `json:"format,omitempty"`
+	// Key the secret URL of the ASCII armored GPG private key or the SSH private key in the secret storage, such as
+	// `vault:jx/commit-signing:key` or `local:commit-signing:key`
+	Key string `json:"key,omitempty"`
+	// KeyID the ID of the GPG key to sign with. Defaults to the key matching the committer email
+	KeyID string `json:"keyId,omitempty"`
+}
+
+// SupplyChainConfig configures generating software bills of materials (SBOMs) of the images built by release
+// pipelines with syft and signing the images with cosign
+type SupplyChainConfig struct {
+	// SBOM whether release pipelines generate an SBOM of their images, which is attested when images are signed
+	SBOM bool `json:"sbom,omitempty"`
+	// Sign whether release pipelines sign their images
+	Sign bool `json:"sign,omitempty"`
+	// Key the cosign key images are signed with. Defaults to the jx-cosign secret in the dev namespace, such as
+	// `k8s://jx/jx-cosign`
+	Key string `json:"key,omitempty"`
+	// VerifyOnPromote whether the signatures of the images of applications are verified before promoting them
+	VerifyOnPromote bool `json:"verifyOnPromote,omitempty"`
+}
+
+// ProgressiveDeliveryStrategy the strategy Flagger uses to shift the traffic of an application to a new version
+type ProgressiveDeliveryStrategy string
+
+const (
+	// ProgressiveDeliveryStrategyCanary gradually shifts the traffic to the new version while its metrics are healthy
+	ProgressiveDeliveryStrategyCanary ProgressiveDeliveryStrategy = "canary"
+	// ProgressiveDeliveryStrategyBlueGreen runs the analysis against the new version before switching all the traffic
+	// to it
+	ProgressiveDeliveryStrategyBlueGreen ProgressiveDeliveryStrategy = "blue-green"
+)
+
+// ProgressiveDeliveryStrategyValues the string values for the progressive delivery strategies
+var ProgressiveDeliveryStrategyValues = []string{"canary", "blue-green"}
+
+// ProgressiveDeliveryConfig configures the progressive delivery of applications via Flagger
+type ProgressiveDeliveryConfig struct {
+	// Enabled whether boot installs Flagger and promotions enable canary rollouts of the applications
+	Enabled bool `json:"enabled,omitempty"`
+	// Strategy either canary or blue-green. Defaults to canary
+	Strategy ProgressiveDeliveryStrategy `json:"strategy,omitempty"`
+	// Provider the service mesh or ingress controller Flagger routes the traffic with such as istio, gloo, nginx,
+	// contour or kubernetes. Defaults to istio
+	Provider string `json:"provider,omitempty"`
+	// Namespace the namespace Flagger is installed in. Defaults to istio-system
+	Namespace string `json:"namespace,omitempty"`
+	// Environments the environments whose applications are rolled out progressively. Defaults to production
+	Environments []string `json:"environments,omitempty"`
+	// Interval the interval of the analysis of a new version such as 1m
+	Interval string `json:"interval,omitempty"`
+	// Threshold the number of failed checks after which a new version is rolled back
+	Threshold int `json:"threshold,omitempty"`
+	// MaxWeight the maximum percentage of the traffic routed to a new version by the canary strategy
+	MaxWeight int `json:"maxWeight,omitempty"`
+	// StepWeight the percentage of the traffic the canary strategy shifts to a new version at each interval
+	StepWeight int `json:"stepWeight,omitempty"`
+	// Iterations the number of intervals the blue-green strategy analyses a new version for
+	Iterations int `json:"iterations,omitempty"`
+}
+
+// IsEnabled returns true if applications are rolled out progressively
+func (c *ProgressiveDeliveryConfig) IsEnabled() bool {
+	return c != nil && c.Enabled
+}
+
+// IsEnabledForEnvironment returns true if the applications of the environment are rolled out progressively
+func (c *ProgressiveDeliveryConfig) IsEnabledForEnvironment(env string) bool {
+	if !c.IsEnabled() {
+		return false
+	}
+	if len(c.Environments) == 0 {
+		return env == "production"
+	}
+	for _, e := range c.Environments {
+		if e == env {
+			return true
+		}
+	}
+	return false
+}
+
+// PullRequestsConfig configures the pull requests jx generates, such as when upgrading, promoting or updating
+// dependencies, so that the right people are notified of them
+type PullRequestsConfig struct {
+	// Reviewers the users whose reviews of the pull requests are requested
+	Reviewers []string `json:"reviewers,omitempty"`
+	// TeamReviewers the teams whose reviews of the pull requests are requested, if supported by the git provider
+	TeamReviewers []string `json:"teamReviewers,omitempty"`
+	// Assignees the users the pull requests are assigned to
+	Assignees []string `json:"assignees,omitempty"`
+	// BranchNameTemplate the go template of the branch names of the pull requests. The template can use the .Kind,
+	// .Version, .Component and .Environment of the change as well as the .BranchName jx would use otherwise
+	BranchNameTemplate string `json:"branchNameTemplate,omitempty"`
+	// TitleTemplate the go template of the titles of the pull requests, such as
+	// "chore({{ .Component }}): {{ .Title }}". The template can use the same values as the BranchNameTemplate and
+	// the .Title jx would use otherwise
+	TitleTemplate string `json:"titleTemplate,omitempty"`
+	// CommitMessageTemplate the go template of the commit messages of the pull requests so that they pass the commit
+	// lint policies of the repositories. The template can use the same values as the BranchNameTemplate and the
+	// .CommitMessage jx would use otherwise
+	CommitMessageTemplate string `json:"commitMessageTemplate,omitempty"`
+}
+
+// ExternalSecretsConfig configures how the secrets of the helm charts are looked up by the External Secrets Operator
+type ExternalSecretsConfig struct {
+	// SecretStoreName the name of the SecretStore or ClusterSecretStore which accesses the cloud secret manager
+	SecretStoreName string `json:"secretStoreName,omitempty"`
+	// SecretStoreKind the kind of the secret store, either SecretStore or ClusterSecretStore. Defaults to
+	// ClusterSecretStore
+	SecretStoreKind string `json:"secretStoreKind,omitempty"`
+	// KeyPrefix an optional prefix of the keys of the secrets in the cloud secret manager. The keys already start with
+	// the secret base path, which defaults to the cluster name
+	KeyPrefix string `json:"keyPrefix,omitempty"`
+	// RefreshInterval how often the External Secrets Operator refreshes the secrets, such as 1h. Defaults to 1h
+	RefreshInterval string `json:"refreshInterval,omitempty"`
+}
+
+// IsEnabled returns true if commits should be signed
+func (c *CommitSigningConfig) IsEnabled() bool {
+	return c != nil && c.Enabled
+}
+
+// SigningFormat returns the format of the signatures, defaulting to gpg
+func (c *CommitSigningConfig) SigningFormat() CommitSigningFormat {
+	if c.Format == "" {
+		return CommitSigningFormatGPG
+	}
+	return c.Format
+}
+
 // RequirementsValues contains the logical installation requirements in the `jx-requirements.yml` file as helm values
 type RequirementsValues struct {
 	// RequirementsConfig contains the logical installation requirements
@@ -514,18 +798,33 @@ type RequirementsValues struct {
 // RequirementsConfig contains the logical installation requirements in the `jx-requirements.yml` file when
 // installing, configuring or upgrading Jenkins X via `jx boot`
 type RequirementsConfig struct {
+	// APIVersion the version of the layout of the requirements file. Older layouts are migrated when loaded
+	APIVersion string `json:"apiVersion,omitempty"`
 	// AutoUpdate contains auto update config
 	AutoUpdate AutoUpdateConfig `json:"autoUpdate,omitempty"`
 	// BootConfigURL contains the url to which the dev environment is associated with
 	BootConfigURL string `json:"bootConfigURL,omitempty"`
 	// BuildPackConfig contains custom build pack settings
 	BuildPacks *BuildPackConfig `json:"buildPacks,omitempty"`
+	// BuildPlatforms the platforms, such as linux/amd64 and linux/arm64, the images of the pipelines are built for.
+	// Multiple platforms build a multi-arch image
+	BuildPlatforms []string `json:"buildPlatforms,omitempty"`
 	// Cluster contains cluster specific requirements
 	Cluster ClusterConfig `json:"cluster"`
+	// Clusters the remote clusters which run environments separately from the development cluster
+	Clusters []RemoteClusterConfig `json:"clusters,omitempty"`
+	// CommitSigning configures signing the commits made by jx
+	CommitSigning *CommitSigningConfig `json:"commitSigning,omitempty"`
 	// Environments the requirements for the environments
 	Environments []EnvironmentConfig `json:"environments,omitempty"`
+	// ExternalSecrets configures the ExternalSecret resources generated when the secret storage is external-secrets
+	ExternalSecrets *ExternalSecretsConfig `json:"externalSecrets,omitempty"`
 	// GithubApp contains github app config
 	GithubApp *GithubAppConfig `json:"githubApp,omitempty"`
+	// Governance the resource quotas and namespace policies applied to the environment namespaces
+	Governance *GovernanceConfig `json:"governance,omitempty"`
+	// EnvironmentTemplates the templates used to create long lived environments with `jx create environment`
+	EnvironmentTemplates []EnvironmentTemplateConfig `json:"environmentTemplates,omitempty"`
 	// GitOps if enabled we will setup a webhook in the boot configuration git repository so that we can
 	// re-run 'jx boot' when changes merge to the master branch
 	GitOps bool `json:"gitops,omitempty"`
@@ -536,12 +835,25 @@ type RequirementsConfig struct {
 	Kaniko bool `json:"kaniko,omitempty"`
 	// Ingress contains ingress specific requirements
 	Ingress IngressConfig `json:"ingress"`
+	// PipelinePods the defaults of the pods of the pipelines such as their resources and the nodes they run on
+	PipelinePods *PipelinePodsConfig `json:"pipelinePods,omitempty"`
+	// Previews the team defaults of the lifecycle of the preview environments
+	Previews *PreviewsConfig `json:"previews,omitempty"`
+	// ProgressiveDelivery configures the canary or blue-green rollouts of applications via Flagger
+	ProgressiveDelivery *ProgressiveDeliveryConfig `json:"progressiveDelivery,omitempty"`
+	// PullRequests the default reviewers and assignees of the pull requests jx generates
+	PullRequests *PullRequestsConfig `json:"pullRequests,omitempty"`
 	// Repository specifies what kind of artifact repository you wish to use for storing artifacts (jars, tarballs, npm modules etc)
 	Repository RepositoryType `json:"repository,omitempty"`
 	// SecretStorage how should we store secrets for the cluster
 	SecretStorage SecretStorageType `json:"secretStorage,omitempty"`
+	// SensitiveFields the paths of the properties, such as `cluster.project`, which are stored in the secret storage
+	// rather than in this file. Their values are replaced by secret URLs when encrypted via `jx edit requirements --encrypt`
+	SensitiveFields []string `json:"sensitiveFields,omitempty"`
 	// Storage contains storage requirements
 	Storage StorageConfig `json:"storage"`
+	// SupplyChain configures generating the SBOMs of the images built by release pipelines and signing them
+	SupplyChain *SupplyChainConfig `json:"supplyChain,omitempty"`
 	// Terraform specifies if  we are managing the kubernetes cluster and cloud resources with Terraform
 	Terraform bool `json:"terraform,omitempty"`
 	// Vault the configuration for vault
@@ -557,6 +869,7 @@ type RequirementsConfig struct {
 // NewRequirementsConfig creates a default configuration file
 func NewRequirementsConfig() *RequirementsConfig {
 	return &RequirementsConfig{
+		APIVersion:    RequirementsAPIVersion,
 		SecretStorage: SecretStorageTypeLocal,
 		Webhook:       WebhookTypeProw,
 	}
@@ -566,6 +879,12 @@ func NewRequirementsConfig() *RequirementsConfig {
 // if there is not a file called `jx-requirements.yml` in the given dir we will scan up the parent
 // directories looking for the requirements file as we often run 'jx' steps in sub directories.
 func LoadRequirementsConfig(dir string, failOnValidationErrors bool) (*RequirementsConfig, string, error) {
+	return LoadRequirementsConfigWithOptions(dir, failOnValidationErrors, DefaultRequirementsOptions())
+}
+
+// LoadRequirementsConfigWithOptions loads the project configuration like LoadRequirementsConfig merging the
+// requirements profiles of the given options
+func LoadRequirementsConfigWithOptions(dir string, failOnValidationErrors bool, options RequirementsOptions) (*RequirementsConfig, string, error) {
 	absolute, err := filepath.Abs(dir)
 	if err != nil {
 		return nil, "", errors.Wrap(err, "creating absolute path")
@@ -583,29 +902,95 @@ func LoadRequirementsConfig(dir string, failOnValidationErrors bool) (*Requireme
 			continue
 		}
 
-		config, err := LoadRequirementsConfigFile(fileName, failOnValidationErrors)
+		config, err := LoadRequirementsConfigFileWithOptions(fileName, failOnValidationErrors, options)
 		return config, fileName, err
 	}
 	return nil, "", errors.New("jx-requirements.yml file not found")
 }
 
-// LoadRequirementsConfigFile loads a specific project YAML configuration file
+// LoadRequirementsConfigFile loads a specific project YAML configuration file.
+// Any requirements profiles enabled via the $JX_REQUIREMENTS_PROFILE environment variable are merged over the file,
+// see RequirementsProfiles for details.
 func LoadRequirementsConfigFile(fileName string, failOnValidationErrors bool) (*RequirementsConfig, error) {
-	config := &RequirementsConfig{}
+	return LoadRequirementsConfigFileWithOptions(fileName, failOnValidationErrors, DefaultRequirementsOptions())
+}
+
+// LoadRequirementsConfigFileWithOptions loads a specific project YAML configuration file merging the requirements
+// profiles of the given options over it
+func LoadRequirementsConfigFileWithOptions(fileName string, failOnValidationErrors bool, options RequirementsOptions) (*RequirementsConfig, error) {
 	_, err := os.Stat(fileName)
 	if err != nil {
 		return nil, errors.Wrapf(err, "checking if file %s exists", fileName)
 	}
 
+	data, err := loadRequirementsData(fileName, failOnValidationErrors)
+	if err != nil {
+		return nil, err
+	}
+	config, err := unmarshalRequirementsData(fileName, data, failOnValidationErrors, options)
+	if err != nil {
+		return nil, err
+	}
+	config.decryptSensitiveFieldsIfPossible(fileName, options)
+	return config, nil
+}
+
+// unmarshalRequirementsData merges the requirements profiles over the data of the given requirements file and
+// unmarshals the result adding any default values
+func unmarshalRequirementsData(fileName string, data []byte, failOnValidationErrors bool, options RequirementsOptions) (*RequirementsConfig, error) {
+	for _, profile := range options.Profiles {
+		profileFileName := RequirementsProfileFileName(fileName, profile)
+		exists, err := util.FileExists(profileFileName)
+		if err != nil {
+			return nil, errors.Wrapf(err, "checking if file %s exists", profileFileName)
+		}
+		if !exists {
+			return nil, fmt.Errorf("no file %s found for requirements profile %s", profileFileName, profile)
+		}
+		overlay, err := loadRequirementsData(profileFileName, failOnValidationErrors)
+		if err != nil {
+			return nil, err
+		}
+		data, err = MergeRequirementsData(data, overlay)
+		if err != nil {
+			return nil, errors.Wrapf(err, "merging requirements profile file %s", profileFileName)
+		}
+		log.Logger().Debugf("merged requirements profile %s from %s", profile, profileFileName)
+	}
+
+	config := &RequirementsConfig{}
+	err := yaml.Unmarshal(data, config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal YAML file %s due to %s", fileName, err)
+	}
+
+	config.addDefaults()
+	config.handleDeprecation()
+	return config, nil
+}
+
+// loadRequirementsData reads, migrates and validates the given requirements file returning the migrated data
+func loadRequirementsData(fileName string, failOnValidationErrors bool) ([]byte, error) {
 	data, err := ioutil.ReadFile(fileName)
 	if err != nil {
 		return nil, fmt.Errorf("failed to load file %s due to %s", fileName, err)
 	}
 
-	validationErrors, err := util.ValidateYaml(config, data)
+	data, changes, err := MigrateRequirementsData(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to migrate YAML file %s due to %s", fileName, err)
+	}
+	if len(changes) > 0 {
+		log.Logger().Infof("migrated YAML file %s to apiVersion %s:\n%s", fileName, RequirementsAPIVersion, strings.Join(changes, "\n"))
+	}
+
+	validationErrors, err := ValidateRequirementsData(data)
 	if err != nil {
 		return nil, fmt.Errorf("failed to validate YAML file %s due to %s", fileName, err)
 	}
+	for _, w := range RequirementsDeprecations(data) {
+		log.Logger().Warnf("%s in YAML file %s", w, fileName)
+	}
 
 	if len(validationErrors) > 0 {
 		log.Logger().Warnf("validation failures in YAML file %s: %s", fileName, strings.Join(validationErrors, ", "))
@@ -614,15 +999,7 @@ func LoadRequirementsConfigFile(fileName string, failOnValidationErrors bool) (*
 			return nil, fmt.Errorf("validation failures in YAML file %s:\n%s", fileName, strings.Join(validationErrors, "\n"))
 		}
 	}
-
-	err = yaml.Unmarshal(data, config)
-	if err != nil {
-		return nil, fmt.Errorf("failed to unmarshal YAML file %s due to %s", fileName, err)
-	}
-
-	config.addDefaults()
-	config.handleDeprecation()
-	return config, nil
+	return data, nil
 }
 
 // GetRequirementsConfigFromTeamSettings reads the BootRequirements string from TeamSettings and unmarshals it
@@ -637,8 +1014,11 @@ func GetRequirementsConfigFromTeamSettings(settings *v1.TeamSettings) (*Requirem
 	}
 
 	config := &RequirementsConfig{}
-	data := []byte(settings.BootRequirements)
-	validationErrors, err := util.ValidateYaml(config, data)
+	data, _, err := MigrateRequirementsData([]byte(settings.BootRequirements))
+	if err != nil {
+		return config, fmt.Errorf("failed to migrate requirements from team settings due to %s", err)
+	}
+	validationErrors, err := ValidateRequirementsData(data)
 	if err != nil {
 		return config, fmt.Errorf("failed to validate requirements from team settings due to %s", err)
 	}
@@ -660,8 +1040,30 @@ func (c *RequirementsConfig) IsEmpty() bool {
 
 // SaveConfig saves the configuration file to the given project directory
 func (c *RequirementsConfig) SaveConfig(fileName string) error {
+	return c.SaveConfigWithOptions(fileName, DefaultRequirementsOptions())
+}
+
+// SaveConfigWithOptions saves the configuration file to the given project directory. If any requirements profiles
+// are enabled then only the changes made since the requirements were loaded are saved to the file so that the
+// values merged from the profile files are not written to it
+func (c *RequirementsConfig) SaveConfigWithOptions(fileName string, options RequirementsOptions) error {
 	c.handleDeprecation()
-	data, err := yaml.Marshal(c)
+	if c.APIVersion == "" {
+		c.APIVersion = RequirementsAPIVersion
+	}
+	saved, err := c.loadSavedRequirements(fileName, options)
+	if err != nil {
+		return err
+	}
+	object, err := c.objectWithSecretURLs(saved, options)
+	if err != nil {
+		return err
+	}
+	saveObject, err := c.objectToSave(object, saved, options)
+	if err != nil {
+		return err
+	}
+	data, err := yaml.Marshal(saveObject)
 	if err != nil {
 		return err
 	}
@@ -671,8 +1073,8 @@ func (c *RequirementsConfig) SaveConfig(fileName string) error {
 	}
 
 	if c.Helmfile {
-		y := RequirementsValues{
-			RequirementsConfig: c,
+		y := map[string]interface{}{
+			"jxRequirements": object,
 		}
 		data, err = yaml.Marshal(y)
 		if err != nil {
@@ -688,6 +1090,69 @@ func (c *RequirementsConfig) SaveConfig(fileName string) error {
 	return nil
 }
 
+// savedRequirements the requirements file being overwritten by a save
+type savedRequirements struct {
+	// base the contents of the requirements file
+	base map[string]interface{}
+	// loaded the requirements as they were loaded from the file with any requirements profiles merged over it
+	loaded *RequirementsConfig
+}
+
+// loadSavedRequirements loads the requirements file being overwritten if it is needed to work out what to save,
+// which is when requirements profiles are enabled or there are sensitive fields. Returns nil if the file does not exist
+func (c *RequirementsConfig) loadSavedRequirements(fileName string, options RequirementsOptions) (*savedRequirements, error) {
+	if len(options.Profiles) == 0 && len(c.SensitiveFields) == 0 {
+		return nil, nil
+	}
+	exists, err := util.FileExists(fileName)
+	if err != nil {
+		return nil, errors.Wrapf(err, "checking if file %s exists", fileName)
+	}
+	if !exists {
+		return nil, nil
+	}
+	data, err := loadRequirementsData(fileName, false)
+	if err != nil {
+		return nil, err
+	}
+	base := map[string]interface{}{}
+	err = yaml.Unmarshal(data, &base)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to unmarshal YAML file %s", fileName)
+	}
+	if base == nil {
+		base = map[string]interface{}{}
+	}
+	loaded, err := unmarshalRequirementsData(fileName, data, false, options)
+	if err != nil {
+		return nil, errors.Wrapf(err, "loading the requirements profiles of %s", fileName)
+	}
+	return &savedRequirements{
+		base:   base,
+		loaded: loaded,
+	}, nil
+}
+
+// objectToSave returns the object to write to the requirements file. When requirements profiles are enabled only
+// the differences between the requirements as they were loaded with the profiles and the given object are applied
+// to the base requirements file
+func (c *RequirementsConfig) objectToSave(object interface{}, saved *savedRequirements, options RequirementsOptions) (interface{}, error) {
+	if len(options.Profiles) == 0 || saved == nil {
+		return object, nil
+	}
+	saved.loaded.APIVersion = c.APIVersion
+	loadedMap, err := util.ToObjectMap(saved.loaded)
+	if err != nil {
+		return nil, errors.Wrap(err, "converting the loaded requirements to a map")
+	}
+	currentMap, err := util.ToObjectMap(object)
+	if err != nil {
+		return nil, errors.Wrap(err, "converting requirements to a map")
+	}
+	applyRequirementsChanges(saved.base, loadedMap, currentMap)
+	return saved.base, nil
+}
+
 type environmentsSliceTransformer struct{}
 
 // environmentsSliceTransformer.Transformer is handling the correct merge of two EnvironmentConfig slices
@@ -764,6 +1229,43 @@ func (c *RequirementsConfig) Environment(name string) (*EnvironmentConfig, error
 	return nil, fmt.Errorf("environment %q not found", name)
 }
 
+// DeployEngine returns the engine used to deploy the charts of the environment with the given name
+func (c *RequirementsConfig) DeployEngine(name string) DeployEngineType {
+	env, err := c.Environment(name)
+	if err != nil || env.DeployEngine == "" {
+		return DeployEngineHelm
+	}
+	return env.DeployEngine
+}
+
+// RemoteEnvironments returns the environment configurations which run on a remote cluster to the development cluster
+func (c *RequirementsConfig) RemoteEnvironments() []EnvironmentConfig {
+	var answer []EnvironmentConfig
+	for _, env := range c.Environments {
+		if env.RemoteCluster || c.ClusterForEnvironment(env.Key) != nil {
+			answer = append(answer, env)
+		}
+	}
+	return answer
+}
+
+// IsRemoteEnvironment returns true if the environment with the given name runs on a remote cluster
+func (c *RequirementsConfig) IsRemoteEnvironment(name string) bool {
+	env, err := c.Environment(name)
+	return err == nil && (env.RemoteCluster || c.ClusterForEnvironment(name) != nil)
+}
+
+// ClusterForEnvironment returns the remote cluster which runs the environment with the given key or nil if the
+// environment runs in the development cluster
+func (c *RequirementsConfig) ClusterForEnvironment(key string) *RemoteClusterConfig {
+	for i := range c.Clusters {
+		if c.Clusters[i].Environment == key {
+			return &c.Clusters[i]
+		}
+	}
+	return nil
+}
+
 // ToMap converts this object to a map of maps for use in helm templating
 func (c *RequirementsConfig) ToMap() (map[string]interface{}, error) {
 	m, err := util.ToObjectMap(c)
@@ -888,6 +1390,9 @@ func (c *RequirementsConfig) OverrideRequirementsFromEnvironment(gcloudFn func()
 	if "" != os.Getenv(RequirementChartRepository) {
 		c.Cluster.ChartRepository = os.Getenv(RequirementChartRepository)
 	}
+	if "" != os.Getenv(RequirementChartKind) {
+		c.Cluster.ChartKind = ChartRepositoryType(os.Getenv(RequirementChartKind))
+	}
 	if "" != os.Getenv(RequirementRegistry) {
 		c.Cluster.Registry = os.Getenv(RequirementRegistry)
 	}
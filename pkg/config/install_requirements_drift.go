@@ -0,0 +1,84 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+)
+
+// RequirementsDrift describes a requirement whose value differs from the live cluster
+type RequirementsDrift struct {
+	// Path the property path in the `jx-requirements.yml` file such as `ingress.domain`
+	Path string `json:"path"`
+	// Requirements the value in the `jx-requirements.yml` file
+	Requirements string `json:"requirements"`
+	// Cluster the value found in the live cluster
+	Cluster string `json:"cluster"`
+}
+
+// DiffRequirements compares the requirements with the live values found in the cluster which are keyed by their
+// property path. Only the properties which could be found in the cluster are compared.
+func DiffRequirements(c *RequirementsConfig, live map[string]interface{}) ([]RequirementsDrift, error) {
+	m, err := util.ToObjectMap(c)
+	if err != nil {
+		return nil, errors.Wrap(err, "converting requirements to a map")
+	}
+	var paths []string
+	for p := range live {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+
+	var answer []RequirementsDrift
+	for _, p := range paths {
+		actual := live[p]
+		expected := lookupMapValue(m, p)
+		if _, ok := actual.(bool); ok && expected == nil {
+			// false values are omitted from the file
+			expected = false
+		}
+		if driftValue(expected) != driftValue(actual) {
+			answer = append(answer, RequirementsDrift{
+				Path:         p,
+				Requirements: driftValue(expected),
+				Cluster:      driftValue(actual),
+			})
+		}
+	}
+	return answer, nil
+}
+
+// ReconcileRequirements updates the requirements with the live values found in the cluster keyed by property path
+func (c *RequirementsConfig) ReconcileRequirements(live map[string]interface{}) error {
+	m, err := util.ToObjectMap(c)
+	if err != nil {
+		return errors.Wrap(err, "converting requirements to a map")
+	}
+	for p, v := range live {
+		util.SetMapValueViaPath(m, p, v)
+	}
+	return c.replaceFromMap(m)
+}
+
+// lookupMapValue returns the value at the given dot separated path without creating any missing maps
+func lookupMapValue(m map[string]interface{}, path string) interface{} {
+	var value interface{} = m
+	for _, key := range strings.Split(path, ".") {
+		entry, ok := value.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		value = entry[key]
+	}
+	return value
+}
+
+func driftValue(value interface{}) string {
+	if value == nil {
+		return ""
+	}
+	return fmt.Sprintf("%v", value)
+}
@@ -0,0 +1,271 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// freezeDateFormat the format of the dates of one off freezes which start at the beginning of the day and end at
+	// the end of the day
+	freezeDateFormat = "2006-01-02"
+
+	// maxFreezeChain the maximum number of overlapping freezes followed to find when a freeze ends
+	maxFreezeChain = 100
+)
+
+// FreezeWindowConfig configures a window during which promotions to an environment are held back. A window either
+// recurs, starting whenever its cron schedule matches and lasting for its duration, or is a one off window between
+// its start and end
+type FreezeWindowConfig struct {
+	// Name the name of the freeze, such as `weekend` or `black-friday`, which is shown on the held back promotions
+	Name string `json:"name,omitempty"`
+	// Schedule the cron expression `minute hour day-of-month month day-of-week` of when a recurring freeze starts
+	Schedule string `json:"schedule,omitempty"`
+	// Duration how long a recurring freeze lasts such as `60h`
+	Duration string `json:"duration,omitempty"`
+	// Start the date such as `2020-12-24` or RFC 3339 time a one off freeze starts
+	Start string `json:"start,omitempty"`
+	// End the date such as `2021-01-02` or RFC 3339 time a one off freeze ends. A freeze ending on a date lasts
+	// until the end of that day
+	End string `json:"end,omitempty"`
+	// TimeZone the IANA time zone of the schedule and dates such as `Europe/London`. Defaults to UTC
+	TimeZone string `json:"timeZone,omitempty"`
+}
+
+// FreezeAt returns the freeze window of the environment which is active at the given time along with the time all
+// the overlapping freezes end, or nil if no freeze is active
+func (c *EnvironmentConfig) FreezeAt(t time.Time) (*FreezeWindowConfig, time.Time, error) {
+	var answer *FreezeWindowConfig
+	end := t
+	for i := 0; i < maxFreezeChain; i++ {
+		found := false
+		for j := range c.Freezes {
+			w := &c.Freezes[j]
+			active, windowEnd, err := w.ActiveAt(end)
+			if err != nil {
+				return nil, t, errors.Wrapf(err, "invalid freeze %s of environment %s", w.Label(), c.Key)
+			}
+			if active && windowEnd.After(end) {
+				if answer == nil {
+					answer = w
+				}
+				end = windowEnd
+				found = true
+			}
+		}
+		if !found {
+			break
+		}
+	}
+	return answer, end, nil
+}
+
+// Label returns the name of the freeze or a description of when it happens if it has no name
+func (w *FreezeWindowConfig) Label() string {
+	if w.Name != "" {
+		return w.Name
+	}
+	if w.Schedule != "" {
+		return fmt.Sprintf("'%s' for %s", w.Schedule, w.Duration)
+	}
+	return fmt.Sprintf("%s to %s", w.Start, w.End)
+}
+
+// Validate returns an error if the freeze window is not a valid recurring or one off window
+func (w *FreezeWindowConfig) Validate() error {
+	_, _, err := w.ActiveAt(time.Now())
+	return err
+}
+
+// ActiveAt returns true if the freeze window is active at the given time along with the time the window ends
+func (w *FreezeWindowConfig) ActiveAt(t time.Time) (bool, time.Time, error) {
+	loc := time.UTC
+	if w.TimeZone != "" {
+		var err error
+		loc, err = time.LoadLocation(w.TimeZone)
+		if err != nil {
+			return false, t, errors.Wrapf(err, "invalid time zone %s", w.TimeZone)
+		}
+	}
+	t = t.In(loc)
+	if w.Schedule != "" {
+		if w.Start != "" || w.End != "" {
+			return false, t, fmt.Errorf("a freeze cannot have both a schedule and a start or end")
+		}
+		if w.Duration == "" {
+			return false, t, fmt.Errorf("the recurring freeze '%s' has no duration", w.Schedule)
+		}
+		duration, err := time.ParseDuration(w.Duration)
+		if err != nil || duration <= 0 {
+			return false, t, fmt.Errorf("invalid duration %s of the recurring freeze '%s'", w.Duration, w.Schedule)
+		}
+		schedule, err := parseCronSchedule(w.Schedule)
+		if err != nil {
+			return false, t, err
+		}
+		start, ok := schedule.lastMatch(t, t.Add(-duration))
+		if !ok {
+			return false, t, nil
+		}
+		end := start.Add(duration)
+		return end.After(t), end, nil
+	}
+	if w.Start == "" || w.End == "" {
+		return false, t, fmt.Errorf("a freeze needs either a schedule and a duration or a start and an end")
+	}
+	start, err := parseFreezeTime(w.Start, loc, false)
+	if err != nil {
+		return false, t, err
+	}
+	end, err := parseFreezeTime(w.End, loc, true)
+	if err != nil {
+		return false, t, err
+	}
+	if !end.After(start) {
+		return false, t, fmt.Errorf("the freeze ends at %s before it starts at %s", w.End, w.Start)
+	}
+	return !t.Before(start) && t.Before(end), end, nil
+}
+
+// parseFreezeTime parses a date or RFC 3339 time, returning the end of the day for dates which end a freeze
+func parseFreezeTime(text string, loc *time.Location, endOfDay bool) (time.Time, error) {
+	t, err := time.ParseInLocation(freezeDateFormat, text, loc)
+	if err == nil {
+		if endOfDay {
+			t = t.AddDate(0, 0, 1)
+		}
+		return t, nil
+	}
+	t, err = time.Parse(time.RFC3339, text)
+	if err != nil {
+		return t, fmt.Errorf("invalid freeze time %s, expected a date such as 2020-12-24 or an RFC 3339 time", text)
+	}
+	return t, nil
+}
+
+// cronSchedule the minutes, hours, days of the month, months and days of the week matched by a cron expression
+type cronSchedule struct {
+	minutes     []bool
+	hours       []bool
+	daysOfMonth []bool
+	months      []bool
+	daysOfWeek  []bool
+	anyDOM      bool
+	anyDOW      bool
+}
+
+// parseCronSchedule parses a cron expression of the form `minute hour day-of-month month day-of-week` where each field
+// is `*` or a comma separated list of values and ranges with optional steps such as `1-5` or `*/15`
+func parseCronSchedule(expr string) (*cronSchedule, error) {
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("invalid schedule '%s', expected the 5 fields minute hour day-of-month month day-of-week", expr)
+	}
+	s := &cronSchedule{
+		anyDOM: fields[2] == "*",
+		anyDOW: fields[4] == "*",
+	}
+	var err error
+	parsers := []struct {
+		values *[]bool
+		min    int
+		max    int
+	}{
+		{&s.minutes, 0, 59},
+		{&s.hours, 0, 23},
+		{&s.daysOfMonth, 1, 31},
+		{&s.months, 1, 12},
+		{&s.daysOfWeek, 0, 7},
+	}
+	for i, p := range parsers {
+		*p.values, err = parseCronField(fields[i], p.min, p.max)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid schedule '%s'", expr)
+		}
+	}
+	// both 0 and 7 are Sunday
+	if s.daysOfWeek[7] {
+		s.daysOfWeek[0] = true
+	}
+	return s, nil
+}
+
+// parseCronField parses a field of a cron expression into the values between min and max it matches
+func parseCronField(field string, min int, max int) ([]bool, error) {
+	answer := make([]bool, max+1)
+	for _, part := range strings.Split(field, ",") {
+		step := 1
+		if i := strings.Index(part, "/"); i >= 0 {
+			var err error
+			step, err = strconv.Atoi(part[i+1:])
+			if err != nil || step <= 0 {
+				return nil, fmt.Errorf("invalid step in %s", part)
+			}
+			part = part[:i]
+		}
+		from, to := min, max
+		if part != "*" {
+			bounds := strings.SplitN(part, "-", 2)
+			var err error
+			from, err = strconv.Atoi(bounds[0])
+			if err != nil {
+				return nil, fmt.Errorf("invalid value %s", bounds[0])
+			}
+			to = from
+			if len(bounds) == 2 {
+				to, err = strconv.Atoi(bounds[1])
+				if err != nil {
+					return nil, fmt.Errorf("invalid value %s", bounds[1])
+				}
+			} else if step > 1 {
+				to = max
+			}
+		}
+		if from < min || to > max || from > to {
+			return nil, fmt.Errorf("%s is outside of the range %d-%d", part, min, max)
+		}
+		for v := from; v <= to; v += step {
+			answer[v] = true
+		}
+	}
+	return answer, nil
+}
+
+// matchesDay returns true if the schedule matches the day of the time. As in cron a day matches either the day of the
+// month or the day of the week if both are restricted
+func (s *cronSchedule) matchesDay(t time.Time) bool {
+	if !s.months[int(t.Month())] {
+		return false
+	}
+	dom := s.daysOfMonth[t.Day()]
+	dow := s.daysOfWeek[int(t.Weekday())]
+	if s.anyDOM || s.anyDOW {
+		return dom && dow
+	}
+	return dom || dow
+}
+
+// lastMatch returns the latest minute at or before t which the schedule matches, searching no further back than after
+func (s *cronSchedule) lastMatch(t time.Time, after time.Time) (time.Time, bool) {
+	c := time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), 0, 0, t.Location())
+	for c.After(after) {
+		if !s.matchesDay(c) {
+			c = time.Date(c.Year(), c.Month(), c.Day(), 0, 0, 0, 0, c.Location()).Add(-time.Minute)
+			continue
+		}
+		if !s.hours[c.Hour()] {
+			c = time.Date(c.Year(), c.Month(), c.Day(), c.Hour(), 0, 0, 0, c.Location()).Add(-time.Minute)
+			continue
+		}
+		if s.minutes[c.Minute()] {
+			return c, true
+		}
+		c = c.Add(-time.Minute)
+	}
+	return c, false
+}
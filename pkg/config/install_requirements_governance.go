@@ -0,0 +1,132 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+)
+
+// NetworkPolicyType is the kind of default network policy applied to an environment namespace
+type NetworkPolicyType string
+
+const (
+	// NetworkPolicyTypeNone does not apply a default network policy
+	NetworkPolicyTypeNone NetworkPolicyType = "none"
+	// NetworkPolicyTypeDenyAll denies all ingress traffic to the pods in the namespace
+	NetworkPolicyTypeDenyAll NetworkPolicyType = "deny-all"
+	// NetworkPolicyTypeSameNamespace only allows ingress traffic from pods in the same namespace
+	NetworkPolicyTypeSameNamespace NetworkPolicyType = "same-namespace"
+)
+
+// NetworkPolicyTypeValues the string values for the network policy types
+var NetworkPolicyTypeValues = []string{"deny-all", "none", "same-namespace"}
+
+// PodSecurityLevelValues the Pod Security Standard levels which can be enforced on a namespace
+var PodSecurityLevelValues = []string{"baseline", "privileged", "restricted"}
+
+// GovernanceConfig contains the guardrails applied to the environment namespaces
+type GovernanceConfig struct {
+	// Default the policies applied to every environment namespace other than the development environment
+	Default NamespacePolicyConfig `json:"default,omitempty"`
+	// Environments the policies for specific environments keyed by the environment key which override the default
+	// policies. Policies are only applied to the development environment if it is specified here
+	Environments map[string]NamespacePolicyConfig `json:"environments,omitempty"`
+}
+
+// NamespacePolicyConfig contains the policies applied to a namespace
+type NamespacePolicyConfig struct {
+	// ResourceQuota the hard limits of the ResourceQuota of the namespace such as `requests.cpu: "4"`
+	ResourceQuota map[string]string `json:"resourceQuota,omitempty"`
+	// LimitRange the default resources of the containers in the namespace
+	LimitRange *LimitRangeConfig `json:"limitRange,omitempty"`
+	// NetworkPolicy the default network policy of the namespace
+	NetworkPolicy NetworkPolicyType `json:"networkPolicy,omitempty"`
+	// PodSecurity the Pod Security Standard level enforced on the namespace: privileged, baseline or restricted
+	PodSecurity string `json:"podSecurity,omitempty"`
+}
+
+// LimitRangeConfig contains the default resources of the containers in a namespace such as `memory: 512Mi`
+type LimitRangeConfig struct {
+	// DefaultRequests the resource requests of containers which do not specify them
+	DefaultRequests map[string]string `json:"defaultRequests,omitempty"`
+	// DefaultLimits the resource limits of containers which do not specify them
+	DefaultLimits map[string]string `json:"defaultLimits,omitempty"`
+	// Max the maximum resources of a container
+	Max map[string]string `json:"max,omitempty"`
+}
+
+// IsEmpty returns true if there are no policies
+func (p *NamespacePolicyConfig) IsEmpty() bool {
+	return len(p.ResourceQuota) == 0 && p.LimitRange == nil && p.NetworkPolicy == "" && p.PodSecurity == ""
+}
+
+// PolicyForEnvironment returns the policies for the environment with the given key, overriding the default policies
+// with any policies specified for the environment. The default policies are not applied to the development environment
+func (g *GovernanceConfig) PolicyForEnvironment(key string, development bool) NamespacePolicyConfig {
+	answer := NamespacePolicyConfig{}
+	if !development {
+		answer = g.Default
+	}
+	override, ok := g.Environments[key]
+	if !ok {
+		return answer
+	}
+	if len(override.ResourceQuota) > 0 {
+		answer.ResourceQuota = override.ResourceQuota
+	}
+	if override.LimitRange != nil {
+		answer.LimitRange = override.LimitRange
+	}
+	if override.NetworkPolicy != "" {
+		answer.NetworkPolicy = override.NetworkPolicy
+	}
+	if override.PodSecurity != "" {
+		answer.PodSecurity = override.PodSecurity
+	}
+	return answer
+}
+
+// validationErrors returns an error message keyed by the path of each policy with an unsupported value
+func (g *GovernanceConfig) validationErrors() map[string]string {
+	answer := map[string]string{}
+	g.Default.addValidationErrors("governance.default", answer)
+	for key, policy := range g.Environments {
+		policy.addValidationErrors("governance.environments."+key, answer)
+	}
+	return answer
+}
+
+func (p *NamespacePolicyConfig) addValidationErrors(path string, answer map[string]string) {
+	if p.NetworkPolicy != "" && util.StringArrayIndex(NetworkPolicyTypeValues, string(p.NetworkPolicy)) < 0 {
+		answer[path+".networkPolicy"] = fmt.Sprintf("unsupported network policy %s, supported values: %s", p.NetworkPolicy, strings.Join(NetworkPolicyTypeValues, ", "))
+	}
+	if p.PodSecurity != "" && util.StringArrayIndex(PodSecurityLevelValues, p.PodSecurity) < 0 {
+		answer[path+".podSecurity"] = fmt.Sprintf("unsupported pod security level %s, supported values: %s", p.PodSecurity, strings.Join(PodSecurityLevelValues, ", "))
+	}
+}
+
+// governanceValidationErrors returns a message for each governance policy with an unsupported value in the given
+// `jx-requirements.yml` data
+func governanceValidationErrors(data []byte) []string {
+	requirements := &RequirementsConfig{}
+	err := yaml.Unmarshal(data, requirements)
+	if err != nil || requirements.Governance == nil {
+		// the schema validation reports data which cannot be unmarshalled
+		return nil
+	}
+	failures := requirements.Governance.validationErrors()
+	var paths []string
+	for p := range failures {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+
+	var answer []string
+	for _, p := range paths {
+		answer = append(answer, withLineNumber(data, p, fmt.Sprintf("%s: %s", p, failures[p])))
+	}
+	return answer
+}
@@ -0,0 +1,140 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jenkins-x/jx/v2/pkg/cloud"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+)
+
+// IngressControllerType is the kind of ingress controller used to expose services
+type IngressControllerType string
+
+const (
+	// IngressControllerTypeNginx uses the nginx ingress controller
+	IngressControllerTypeNginx IngressControllerType = "nginx"
+	// IngressControllerTypeIstio uses the Istio ingress gateway
+	IngressControllerTypeIstio IngressControllerType = "istio"
+	// IngressControllerTypeTraefik uses the Traefik ingress controller
+	IngressControllerTypeTraefik IngressControllerType = "traefik"
+	// IngressControllerTypeCloud uses the L7 load balancer of the cloud provider such as GCE or the AWS ALB
+	IngressControllerTypeCloud IngressControllerType = "cloud"
+)
+
+// IngressControllerTypeValues the string values for the ingress controller types
+var IngressControllerTypeValues = []string{"cloud", "istio", "nginx", "traefik"}
+
+// DNSProviderType is the kind of DNS service external-dns uses to manage the DNS records of the domain
+type DNSProviderType string
+
+const (
+	// DNSProviderTypeRoute53 uses AWS Route 53
+	DNSProviderTypeRoute53 DNSProviderType = "route53"
+	// DNSProviderTypeCloudDNS uses Google Cloud DNS
+	DNSProviderTypeCloudDNS DNSProviderType = "cloud-dns"
+	// DNSProviderTypeAzureDNS uses Azure DNS
+	DNSProviderTypeAzureDNS DNSProviderType = "azure-dns"
+)
+
+// DNSProviderTypeValues the string values for the DNS provider types
+var DNSProviderTypeValues = []string{"azure-dns", "cloud-dns", "route53"}
+
+// ingressControllerServices the default namespace and service of the load balancer of each ingress controller
+var ingressControllerServices = map[IngressControllerType][2]string{
+	IngressControllerTypeNginx:   {"kube-system", "jxing-nginx-ingress-controller"},
+	IngressControllerTypeIstio:   {"istio-system", "istio-ingressgateway"},
+	IngressControllerTypeTraefik: {"kube-system", "traefik"},
+}
+
+// ingressControllerCharts the helm chart and release name used to install each ingress controller. Istio is installed
+// with its own tooling and the cloud L7 load balancers are provided by the cloud so neither has a chart
+var ingressControllerCharts = map[IngressControllerType][2]string{
+	IngressControllerTypeNginx:   {"stable/nginx-ingress", "jxing"},
+	IngressControllerTypeTraefik: {"stable/traefik", "traefik"},
+}
+
+// externalDNSProviders the value of the provider setting of external-dns for each DNS provider
+var externalDNSProviders = map[DNSProviderType]string{
+	DNSProviderTypeRoute53:  "aws",
+	DNSProviderTypeCloudDNS: "google",
+	DNSProviderTypeAzureDNS: "azure",
+}
+
+// IngressControllerConfig configures the ingress controller
+type IngressControllerConfig struct {
+	// Kind the kind of ingress controller. Defaults to nginx
+	Kind IngressControllerType `json:"kind,omitempty"`
+	// Namespace the namespace of the load balancer service of the ingress controller
+	Namespace string `json:"namespace,omitempty"`
+	// Service the name of the load balancer service of the ingress controller
+	Service string `json:"service,omitempty"`
+}
+
+// ControllerKind returns the kind of ingress controller defaulting to nginx
+func (i *IngressConfig) ControllerKind() IngressControllerType {
+	if i.Controller.Kind == "" {
+		return IngressControllerTypeNginx
+	}
+	return i.Controller.Kind
+}
+
+// ControllerService returns the namespace and name of the load balancer service of the ingress controller used to
+// discover the domain. Both are empty if the controller does not use a load balancer service
+func (i *IngressConfig) ControllerService() (string, string) {
+	defaults := ingressControllerServices[i.ControllerKind()]
+	ns := i.Controller.Namespace
+	if ns == "" {
+		ns = defaults[0]
+	}
+	name := i.Controller.Service
+	if name == "" {
+		name = defaults[1]
+	}
+	return ns, name
+}
+
+// ControllerChart returns the helm chart and release name used to install the ingress controller. Both are empty if
+// the ingress controller cannot be installed with a chart
+func (i *IngressConfig) ControllerChart() (string, string) {
+	chart := ingressControllerCharts[i.ControllerKind()]
+	return chart[0], chart[1]
+}
+
+// ExternalDNSProvider returns the value of the provider setting of external-dns for the DNS provider
+func (t DNSProviderType) ExternalDNSProvider() string {
+	return externalDNSProviders[t]
+}
+
+// DNSProviderKind returns the DNS provider used by external-dns defaulting it from the kubernetes provider
+func (i *IngressConfig) DNSProviderKind(provider string) DNSProviderType {
+	if i.DNSProvider != "" {
+		return i.DNSProvider
+	}
+	switch provider {
+	case cloud.GKE:
+		return DNSProviderTypeCloudDNS
+	case cloud.EKS, cloud.AWS:
+		return DNSProviderTypeRoute53
+	case cloud.AKS:
+		return DNSProviderTypeAzureDNS
+	default:
+		return ""
+	}
+}
+
+// ValidateIngressStack returns an error if the ingress controller or DNS provider are not supported. External DNS is
+// skipped rather than rejected when no DNS provider is configured or can be derived from the kubernetes provider
+func (i *IngressConfig) ValidateIngressStack(provider string) error {
+	kind := string(i.ControllerKind())
+	if util.StringArrayIndex(IngressControllerTypeValues, kind) < 0 {
+		return fmt.Errorf("unsupported ingress controller %s, supported values: %s", kind, strings.Join(IngressControllerTypeValues, ", "))
+	}
+	if i.DNSProvider != "" && util.StringArrayIndex(DNSProviderTypeValues, string(i.DNSProvider)) < 0 {
+		return fmt.Errorf("unsupported DNS provider %s, supported values: %s", i.DNSProvider, strings.Join(DNSProviderTypeValues, ", "))
+	}
+	if i.ControllerKind() == IngressControllerTypeCloud && i.Domain == "" {
+		return fmt.Errorf("a domain must be specified when using the %s ingress controller", IngressControllerTypeCloud)
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/ghodss/yaml"
+	"github.com/pkg/errors"
+)
+
+const (
+	// RequirementsAPIVersionV1 the implied version of `jx-requirements.yml` files which do not specify an apiVersion
+	RequirementsAPIVersionV1 = "v1"
+	// RequirementsAPIVersion the current version of the `jx-requirements.yml` file layout
+	RequirementsAPIVersion = "v2"
+)
+
+// requirementsMigration upgrades the raw requirements map from one apiVersion to the next returning a description
+// of each change it made
+type requirementsMigration struct {
+	from    string
+	to      string
+	migrate func(m map[string]interface{}) []string
+}
+
+var requirementsMigrations = []requirementsMigration{
+	{
+		from:    RequirementsAPIVersionV1,
+		to:      RequirementsAPIVersion,
+		migrate: migrateRequirementsV1ToV2,
+	},
+}
+
+// MigrateRequirementsData upgrades `jx-requirements.yml` data using an older layout to the current apiVersion.
+// It returns the migrated data along with a description of each change. If only the apiVersion needed updating the
+// original data is returned so that any validation errors still refer to the original line numbers.
+func MigrateRequirementsData(data []byte) ([]byte, []string, error) {
+	m := map[string]interface{}{}
+	err := yaml.Unmarshal(data, &m)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to unmarshal requirements")
+	}
+	if m == nil {
+		return data, nil, nil
+	}
+
+	version := RequirementsAPIVersionV1
+	if v, ok := m["apiVersion"]; ok && v != nil {
+		version = fmt.Sprintf("%v", v)
+	}
+	if version == RequirementsAPIVersion {
+		return data, nil, nil
+	}
+
+	var changes []string
+	for _, migration := range requirementsMigrations {
+		if migration.from != version {
+			continue
+		}
+		changes = append(changes, migration.migrate(m)...)
+		version = migration.to
+	}
+	if version != RequirementsAPIVersion {
+		return nil, nil, errors.Errorf("unsupported requirements apiVersion %s, the latest supported version is %s", version, RequirementsAPIVersion)
+	}
+	if len(changes) == 0 {
+		return data, nil, nil
+	}
+
+	m["apiVersion"] = RequirementsAPIVersion
+	answer, err := yaml.Marshal(m)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to marshal migrated requirements")
+	}
+	return answer, changes, nil
+}
+
+// migrateRequirementsV1ToV2 moves the vault settings out of the cluster section and replaces the
+// inverted environmentGitPrivate flag with environmentGitPublic
+func migrateRequirementsV1ToV2(m map[string]interface{}) []string {
+	var changes []string
+	cluster, ok := m["cluster"].(map[string]interface{})
+	if !ok {
+		return changes
+	}
+	vault, ok := m["vault"].(map[string]interface{})
+	if !ok {
+		vault = map[string]interface{}{}
+	}
+	moves := []struct {
+		from string
+		to   string
+	}{
+		{"vaultName", "name"},
+		{"vaultSAName", "serviceAccount"},
+	}
+	for _, move := range moves {
+		value, ok := cluster[move.from]
+		if !ok || value == "" {
+			continue
+		}
+		if _, exists := vault[move.to]; exists {
+			continue
+		}
+		vault[move.to] = value
+		m["vault"] = vault
+		changes = append(changes, fmt.Sprintf("copied cluster.%s to vault.%s", move.from, move.to))
+	}
+
+	// if both flags are specified we leave them alone so that unmarshalling reports the conflict
+	private, ok := cluster["environmentGitPrivate"]
+	if _, exists := cluster["environmentGitPublic"]; ok && !exists {
+		delete(cluster, "environmentGitPrivate")
+		cluster["environmentGitPublic"] = private != true
+		changes = append(changes, "replaced cluster.environmentGitPrivate with cluster.environmentGitPublic")
+	}
+	return changes
+}
@@ -0,0 +1,52 @@
+package config
+
+import (
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// PipelinePodsConfig contains the defaults of the pods of the pipelines of the team, which the options of the
+// pipelines in jenkins-x.yml can override
+type PipelinePodsConfig struct {
+	// Requests the default resource requests of the containers of the steps such as `memory: 512Mi`
+	Requests map[string]string `json:"requests,omitempty"`
+	// Limits the default resource limits of the containers of the steps such as `cpu: "2"`
+	Limits map[string]string `json:"limits,omitempty"`
+	// NodeSelector the labels of the nodes the pods are scheduled on, such as those of a dedicated build node pool
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+	// Tolerations the tolerations of the pods, such as those of the taints of a dedicated build node pool
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+	// SecurityContext the security context of the pods
+	SecurityContext *corev1.PodSecurityContext `json:"securityContext,omitempty"`
+}
+
+// Resources returns the default resource requests and limits of the containers of the steps
+func (c *PipelinePodsConfig) Resources() (corev1.ResourceRequirements, error) {
+	answer := corev1.ResourceRequirements{}
+	var err error
+	answer.Requests, err = parseResourceList(c.Requests)
+	if err != nil {
+		return answer, errors.Wrap(err, "parsing the requests of the pipeline pods")
+	}
+	answer.Limits, err = parseResourceList(c.Limits)
+	if err != nil {
+		return answer, errors.Wrap(err, "parsing the limits of the pipeline pods")
+	}
+	return answer, nil
+}
+
+func parseResourceList(values map[string]string) (corev1.ResourceList, error) {
+	if len(values) == 0 {
+		return nil, nil
+	}
+	answer := corev1.ResourceList{}
+	for k, v := range values {
+		q, err := resource.ParseQuantity(v)
+		if err != nil {
+			return nil, errors.Wrapf(err, "parsing the quantity %s of resource %s", v, k)
+		}
+		answer[corev1.ResourceName(k)] = q
+	}
+	return answer, nil
+}
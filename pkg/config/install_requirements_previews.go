@@ -0,0 +1,241 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// PreviewsConfig the team defaults of the lifecycle of the preview environments which the preview environment
+// configuration in the `jenkins-x.yml` of each repository can override
+type PreviewsConfig struct {
+	// TTL the maximum age of a preview environment, such as 72h or 7d, after which it is deleted even if its Pull
+	// Request is still open
+	TTL string `json:"ttl,omitempty"`
+	// IdleTimeout how long a preview environment is kept without new commits to its Pull Request, such as 2d
+	IdleTimeout string `json:"idleTimeout,omitempty"`
+	// DeleteImages deletes the image tags of the preview environments from the container registry when they are deleted
+	DeleteImages bool `json:"deleteImages,omitempty"`
+	// ScaleToZero scales the pods of idle preview environments down to zero until their next request
+	ScaleToZero *PreviewScaleToZeroConfig `json:"scaleToZero,omitempty"`
+	// Protection protects the ingresses of the preview environments with basic auth or OAuth2 Proxy
+	Protection *PreviewProtectionConfig `json:"protection,omitempty"`
+	// Budget limits the number and resources of the preview environments of the team and its repositories
+	Budget *PreviewBudgetConfig `json:"budget,omitempty"`
+	// Cluster the name of the remote cluster from the clusters section which runs the preview environments rather than
+	// the development cluster
+	Cluster string `json:"cluster,omitempty"`
+}
+
+// PreviewCluster returns the remote cluster which runs the preview environments or nil if they run in the development
+// cluster
+func (c *RequirementsConfig) PreviewCluster() (*RemoteClusterConfig, error) {
+	if c == nil || c.Previews == nil || c.Previews.Cluster == "" {
+		return nil, nil
+	}
+	cluster := c.RemoteCluster(c.Previews.Cluster)
+	if cluster == nil {
+		return nil, fmt.Errorf("the previews cluster %s is not in the clusters of the requirements", c.Previews.Cluster)
+	}
+	if cluster.Context == "" {
+		return nil, fmt.Errorf("the previews cluster %s has no kube context to connect to it with", cluster.Name)
+	}
+	return cluster, nil
+}
+
+// PreviewBudgetConfig the resource budget of the preview environments
+type PreviewBudgetConfig struct {
+	// MaxPreviews the maximum number of preview environments of the team, which is unlimited if zero
+	MaxPreviews int `json:"maxPreviews,omitempty"`
+	// MaxPreviewsPerRepository the maximum number of preview environments of each repository, which is unlimited if
+	// zero. The `maximumInstances` of the preview environments of a `jenkins-x.yml` can only lower it
+	MaxPreviewsPerRepository int `json:"maxPreviewsPerRepository,omitempty"`
+	// EvictOldest deletes the least recently updated preview environments to make room for a new preview environment
+	// rather than failing to create it
+	EvictOldest bool `json:"evictOldest,omitempty"`
+	// Quota the hard limits of the ResourceQuota of each preview namespace such as `requests.cpu: 1`
+	Quota map[string]string `json:"quota,omitempty"`
+	// DefaultRequests the default resource requests of the containers in each preview namespace such as `cpu: 100m`,
+	// so that the pods of charts which do not specify requests are admitted by the quota
+	DefaultRequests map[string]string `json:"defaultRequests,omitempty"`
+	// Repositories the budgets of repositories, keyed by owner/repository, which override the team budget
+	Repositories map[string]*PreviewRepositoryBudget `json:"repositories,omitempty"`
+}
+
+// PreviewRepositoryBudget the resource budget of the preview environments of a repository
+type PreviewRepositoryBudget struct {
+	// MaxPreviews the maximum number of preview environments of the repository
+	MaxPreviews int `json:"maxPreviews,omitempty"`
+	// Quota the hard limits of the ResourceQuota of the preview namespaces of the repository
+	Quota map[string]string `json:"quota,omitempty"`
+}
+
+// RepositoryLimits returns the maximum number of preview environments and the quota of each preview namespace of the
+// repository, lowered by the maximum instances of its `jenkins-x.yml`
+func (c *PreviewBudgetConfig) RepositoryLimits(repository string, maximumInstances int) (int, map[string]string) {
+	max := 0
+	var quota map[string]string
+	if c != nil {
+		max = c.MaxPreviewsPerRepository
+		quota = c.Quota
+		if r := c.Repositories[repository]; r != nil {
+			if r.MaxPreviews > 0 {
+				max = r.MaxPreviews
+			}
+			if len(r.Quota) > 0 {
+				quota = r.Quota
+			}
+		}
+	}
+	if maximumInstances > 0 && (max == 0 || maximumInstances < max) {
+		max = maximumInstances
+	}
+	return max, quota
+}
+
+const (
+	// ScaleToZeroProviderKnative deploys the applications of preview environments as Knative services
+	ScaleToZeroProviderKnative = "knative"
+	// ScaleToZeroProviderKEDA scales the deployments of preview environments with the KEDA HTTP add-on
+	ScaleToZeroProviderKEDA = "keda"
+)
+
+// PreviewScaleToZeroConfig configures scaling the pods of idle preview environments down to zero
+type PreviewScaleToZeroConfig struct {
+	// Enabled scales idle preview environments to zero
+	Enabled bool `json:"enabled,omitempty"`
+	// Provider scales the preview environments, either `knative` (the default) or `keda`
+	Provider string `json:"provider,omitempty"`
+	// ScaleDownPeriod how long a preview environment receives no requests before it is scaled to zero, such as 5m
+	ScaleDownPeriod string `json:"scaleDownPeriod,omitempty"`
+	// KEDANamespace the namespace the KEDA HTTP add-on is installed in, defaulting to keda
+	KEDANamespace string `json:"kedaNamespace,omitempty"`
+}
+
+const (
+	// PreviewProtectionBasic protects the preview environments with basic auth using generated credentials
+	PreviewProtectionBasic = "basic"
+	// PreviewProtectionOAuth2 protects the preview environments by signing in with a shared OAuth2 Proxy
+	PreviewProtectionOAuth2 = "oauth2"
+)
+
+// PreviewProtectionConfig configures how the ingresses of the preview environments are protected
+type PreviewProtectionConfig struct {
+	// Kind the kind of protection, either `basic` or `oauth2`
+	Kind string `json:"kind,omitempty"`
+	// Username the user name of the generated basic auth credentials, defaulting to preview
+	Username string `json:"username,omitempty"`
+	// OAuth2ProxyURL the URL of the OAuth2 Proxy people sign in with, such as https://oauth2.example.com
+	OAuth2ProxyURL string `json:"oauth2ProxyURL,omitempty"`
+	// Instructions the instructions commented on the Pull Requests on how to sign in to the preview environments
+	Instructions string `json:"instructions,omitempty"`
+}
+
+// ScaleToZeroProvider returns the provider which scales the preview environments to zero or an empty string if they
+// are not scaled to zero
+func (c *PreviewsConfig) ScaleToZeroProvider() string {
+	if c == nil || c.ScaleToZero == nil || !c.ScaleToZero.Enabled {
+		return ""
+	}
+	if c.ScaleToZero.Provider == "" {
+		return ScaleToZeroProviderKnative
+	}
+	return c.ScaleToZero.Provider
+}
+
+// Validate returns an error if the TTL or idle timeout is not a valid duration
+func (c *PreviewsConfig) Validate() error {
+	if c == nil {
+		return nil
+	}
+	_, err := ParsePreviewDuration(c.TTL)
+	if err != nil {
+		return errors.Wrap(err, "invalid previews ttl")
+	}
+	_, err = ParsePreviewDuration(c.IdleTimeout)
+	if err != nil {
+		return errors.Wrap(err, "invalid previews idleTimeout")
+	}
+	provider := c.ScaleToZeroProvider()
+	if provider != "" && provider != ScaleToZeroProviderKnative && provider != ScaleToZeroProviderKEDA {
+		return fmt.Errorf("invalid previews scaleToZero provider %q, must be %s or %s", provider, ScaleToZeroProviderKnative, ScaleToZeroProviderKEDA)
+	}
+	if p := c.Protection; p != nil {
+		switch p.Kind {
+		case PreviewProtectionBasic:
+		case PreviewProtectionOAuth2:
+			if p.OAuth2ProxyURL == "" {
+				return fmt.Errorf("previews protection of kind %s requires the oauth2ProxyURL", p.Kind)
+			}
+		default:
+			return fmt.Errorf("invalid previews protection kind %q, must be %s or %s", p.Kind, PreviewProtectionBasic, PreviewProtectionOAuth2)
+		}
+	}
+	if b := c.Budget; b != nil {
+		if b.MaxPreviews < 0 || b.MaxPreviewsPerRepository < 0 {
+			return fmt.Errorf("the previews budget cannot have a negative maximum number of previews")
+		}
+		for name, r := range b.Repositories {
+			if strings.Count(name, "/") != 1 {
+				return fmt.Errorf("invalid repository %q of the previews budget, must be owner/repository", name)
+			}
+			if r != nil && r.MaxPreviews < 0 {
+				return fmt.Errorf("the previews budget of repository %s cannot have a negative maximum number of previews", name)
+			}
+		}
+	}
+	if c.ScaleToZero != nil && c.ScaleToZero.ScaleDownPeriod != "" {
+		_, err = time.ParseDuration(c.ScaleToZero.ScaleDownPeriod)
+		if err != nil {
+			return errors.Wrap(err, "invalid previews scaleToZero scaleDownPeriod")
+		}
+	}
+	return nil
+}
+
+// PreviewLifecycle returns the TTL and idle timeout of the preview environments of a repository, the preview
+// environment configuration of the repository overriding the team defaults
+func PreviewLifecycle(team *PreviewsConfig, project *PreviewEnvironmentConfig) (string, string) {
+	ttl := ""
+	idleTimeout := ""
+	if team != nil {
+		ttl = team.TTL
+		idleTimeout = team.IdleTimeout
+	}
+	if project != nil {
+		if project.TTL != "" {
+			ttl = project.TTL
+		}
+		if project.IdleTimeout != "" {
+			idleTimeout = project.IdleTimeout
+		}
+	}
+	return ttl, idleTimeout
+}
+
+// ParsePreviewDuration parses a duration which can also be a number of days such as 7d. An empty string is a zero
+// duration which disables the expiry
+func ParsePreviewDuration(text string) (time.Duration, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return 0, nil
+	}
+	if strings.HasSuffix(text, "d") {
+		days, err := strconv.Atoi(strings.TrimSuffix(text, "d"))
+		if err != nil || days < 0 {
+			return 0, fmt.Errorf("%q is not a valid number of days", text)
+		}
+		return time.Duration(days) * 24 * time.Hour, nil
+	}
+	d, err := time.ParseDuration(text)
+	if err != nil {
+		return 0, errors.Wrapf(err, "parsing duration %q", text)
+	}
+	if d < 0 {
+		return 0, fmt.Errorf("duration %q is negative", text)
+	}
+	return d, nil
+}
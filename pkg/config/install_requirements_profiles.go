@@ -0,0 +1,222 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"github.com/jenkins-x/jx/v2/pkg/secreturl"
+	"github.com/pkg/errors"
+)
+
+// RequirementsProfileEnvVar the environment variable used to enable one or more comma separated requirements profiles
+const RequirementsProfileEnvVar = "JX_REQUIREMENTS_PROFILE"
+
+// RequirementsProfiles returns the requirements profiles enabled via the $JX_REQUIREMENTS_PROFILE environment variable.
+//
+// Each profile `foo` corresponds to a `jx-requirements-foo.yml` file next to the `jx-requirements.yml` file which is
+// merged over it when loaded. The precedence, from lowest to highest, is:
+//
+// * the default values
+// * the `jx-requirements.yml` file
+// * each profile file in the order the profiles are listed
+// * the `JX_REQUIREMENT_*` environment variables applied by OverrideRequirementsFromEnvironment
+func RequirementsProfiles() []string {
+	return ParseRequirementsProfiles(os.Getenv(RequirementsProfileEnvVar))
+}
+
+// ParseRequirementsProfiles parses the comma separated list of requirements profiles
+func ParseRequirementsProfiles(text string) []string {
+	var answer []string
+	for _, p := range strings.Split(text, ",") {
+		p = strings.TrimSpace(p)
+		if p != "" {
+			answer = append(answer, p)
+		}
+	}
+	return answer
+}
+
+// RequirementsOptions the options used to load and save requirements files
+type RequirementsOptions struct {
+	// Profiles the requirements profiles merged over the requirements file in order
+	Profiles []string
+
+	// SecretURLClientFactory if specified creates the secret URL client used to resolve the secret URLs of the
+	// sensitive fields when loading and to store any changed sensitive values when saving
+	SecretURLClientFactory func(storage SecretStorageType) (secreturl.Client, error)
+}
+
+// DefaultRequirementsOptions returns the options enabling the requirements profiles of the $JX_REQUIREMENTS_PROFILE
+// environment variable
+func DefaultRequirementsOptions() RequirementsOptions {
+	return RequirementsOptions{
+		Profiles: RequirementsProfiles(),
+	}
+}
+
+// RequirementsProfileFileName returns the file name of the given profile for the given requirements file
+func RequirementsProfileFileName(requirementsFileName string, profile string) string {
+	ext := filepath.Ext(requirementsFileName)
+	base := strings.TrimSuffix(requirementsFileName, ext)
+	return fmt.Sprintf("%s-%s%s", base, profile, ext)
+}
+
+// MergeRequirementsData merges the overlay requirements YAML over the base requirements YAML.
+//
+// Objects are merged recursively with any value in the overlay replacing the base value, including false and empty
+// values. The environments are merged by their key so that an overlay only needs to specify the changed properties
+// of an environment. Any other list replaces the base list.
+func MergeRequirementsData(base []byte, overlay []byte) ([]byte, error) {
+	baseMap := map[string]interface{}{}
+	err := yaml.Unmarshal(base, &baseMap)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal base requirements")
+	}
+	overlayMap := map[string]interface{}{}
+	err = yaml.Unmarshal(overlay, &overlayMap)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal overlay requirements")
+	}
+	if baseMap == nil {
+		baseMap = map[string]interface{}{}
+	}
+	mergeRequirementsMaps(baseMap, overlayMap)
+	return yaml.Marshal(baseMap)
+}
+
+func mergeRequirementsMaps(base map[string]interface{}, overlay map[string]interface{}) {
+	for k, v := range overlay {
+		switch value := v.(type) {
+		case map[string]interface{}:
+			existing, ok := base[k].(map[string]interface{})
+			if ok {
+				mergeRequirementsMaps(existing, value)
+				continue
+			}
+		case []interface{}:
+			existing, ok := base[k].([]interface{})
+			if ok && k == "environments" {
+				base[k] = mergeEnvironmentLists(existing, value)
+				continue
+			}
+		}
+		base[k] = v
+	}
+}
+
+// mergeEnvironmentLists merges the environments using their key appending any new environments
+func mergeEnvironmentLists(base []interface{}, overlay []interface{}) []interface{} {
+	for _, o := range overlay {
+		env, ok := o.(map[string]interface{})
+		key, hasKey := env["key"]
+		merged := false
+		if ok && hasKey {
+			for _, b := range base {
+				existing, ok := b.(map[string]interface{})
+				if ok && existing["key"] == key {
+					mergeRequirementsMaps(existing, env)
+					merged = true
+					break
+				}
+			}
+		}
+		if !merged {
+			base = append(base, o)
+		}
+	}
+	return base
+}
+
+// applyRequirementsChanges applies the changes made from the loaded requirements to the current requirements onto the
+// base requirements so that the values merged from the profiles are not written to the base requirements file
+func applyRequirementsChanges(base map[string]interface{}, loaded map[string]interface{}, current map[string]interface{}) {
+	for k, v := range current {
+		old, found := loaded[k]
+		if found && reflect.DeepEqual(old, v) {
+			continue
+		}
+		switch value := v.(type) {
+		case map[string]interface{}:
+			oldMap, ok := old.(map[string]interface{})
+			if ok {
+				baseMap, ok := base[k].(map[string]interface{})
+				if !ok {
+					baseMap = map[string]interface{}{}
+					base[k] = baseMap
+				}
+				applyRequirementsChanges(baseMap, oldMap, value)
+				continue
+			}
+		case []interface{}:
+			oldList, ok := old.([]interface{})
+			if ok && k == "environments" {
+				baseList, _ := base[k].([]interface{})
+				base[k] = applyEnvironmentChanges(baseList, oldList, value)
+				continue
+			}
+		}
+		base[k] = v
+	}
+	for k := range loaded {
+		if _, found := current[k]; !found {
+			delete(base, k)
+		}
+	}
+}
+
+// applyEnvironmentChanges applies the changes made to the environments using their key. An environment only defined
+// in a profile gets an entry in the base environments holding just its changed properties
+func applyEnvironmentChanges(base []interface{}, loaded []interface{}, current []interface{}) []interface{} {
+	for _, c := range current {
+		env, _ := c.(map[string]interface{})
+		key, hasKey := env["key"]
+		if !hasKey {
+			continue
+		}
+		old := findEnvironmentByKey(loaded, key)
+		if old == nil {
+			base = append(base, c)
+			continue
+		}
+		if reflect.DeepEqual(old, env) {
+			continue
+		}
+		baseEnv := findEnvironmentByKey(base, key)
+		if baseEnv == nil {
+			baseEnv = map[string]interface{}{
+				"key": key,
+			}
+			base = append(base, baseEnv)
+		}
+		applyRequirementsChanges(baseEnv, old, env)
+	}
+	for _, l := range loaded {
+		env, _ := l.(map[string]interface{})
+		key, hasKey := env["key"]
+		if hasKey && findEnvironmentByKey(current, key) == nil {
+			var remaining []interface{}
+			for _, b := range base {
+				existing, ok := b.(map[string]interface{})
+				if !ok || existing["key"] != key {
+					remaining = append(remaining, b)
+				}
+			}
+			base = remaining
+		}
+	}
+	return base
+}
+
+func findEnvironmentByKey(envs []interface{}, key interface{}) map[string]interface{} {
+	for _, e := range envs {
+		env, ok := e.(map[string]interface{})
+		if ok && env["key"] == key {
+			return env
+		}
+	}
+	return nil
+}
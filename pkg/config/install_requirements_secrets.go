@@ -0,0 +1,222 @@
+package config
+
+import (
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"github.com/jenkins-x/jx/v2/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/secreturl"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+)
+
+// RequirementsSecretName the name of the secret in the secret storage used to store the sensitive requirements
+const RequirementsSecretName = "requirements"
+
+// SensitiveFieldSecretPath returns the path of the secret used to store the sensitive fields
+func (c *RequirementsConfig) SensitiveFieldSecretPath() string {
+	if c.Cluster.ClusterName == "" {
+		return RequirementsSecretName
+	}
+	return c.Cluster.ClusterName + "/" + RequirementsSecretName
+}
+
+// EncryptSensitiveFields stores the values of the SensitiveFields in the secret storage, replacing the values in
+// the requirements with secret URLs such as `vault:mycluster/requirements:cluster.project`
+func (c *RequirementsConfig) EncryptSensitiveFields(client secreturl.Client) error {
+	if len(c.SensitiveFields) == 0 {
+		return nil
+	}
+	m, err := util.ToObjectMap(c)
+	if err != nil {
+		return errors.Wrap(err, "converting requirements to a map")
+	}
+	storage := c.SecretStorage
+	if storage == "" {
+		storage = SecretStorageTypeLocal
+	}
+	secretPath := c.SensitiveFieldSecretPath()
+	secret := map[string]interface{}{}
+	for _, field := range c.SensitiveFields {
+		value := sensitiveFieldValue(m, field)
+		if value == "" || isSecretURL(value) {
+			continue
+		}
+		secret[field] = value
+		util.SetMapValueViaPath(m, field, secreturl.ToURI(secretPath, field, string(storage)))
+	}
+	if len(secret) == 0 {
+		return nil
+	}
+
+	// lets preserve any previously encrypted fields
+	existing, err := client.Read(secretPath)
+	if err == nil {
+		for k, v := range existing {
+			if _, ok := secret[k]; !ok {
+				secret[k] = v
+			}
+		}
+	}
+	_, err = client.Write(secretPath, secret)
+	if err != nil {
+		return errors.Wrapf(err, "writing sensitive requirements to secret %s", secretPath)
+	}
+	return c.replaceFromMap(m)
+}
+
+// DecryptSensitiveFields replaces any secret URLs in the SensitiveFields with their values from the secret storage.
+// Saving the requirements writes the secret URLs back to the file rather than the values
+func (c *RequirementsConfig) DecryptSensitiveFields(client secreturl.Client) error {
+	if !c.HasEncryptedFields() {
+		return nil
+	}
+	m, err := util.ToObjectMap(c)
+	if err != nil {
+		return errors.Wrap(err, "converting requirements to a map")
+	}
+	for _, field := range c.SensitiveFields {
+		url := sensitiveFieldValue(m, field)
+		if !isSecretURL(url) {
+			continue
+		}
+		value, err := secreturl.ResolveURI(client, url)
+		if err != nil {
+			return errors.Wrapf(err, "resolving sensitive requirement %s", field)
+		}
+		util.SetMapValueViaPath(m, field, value)
+	}
+	return c.replaceFromMap(m)
+}
+
+// HasEncryptedFields returns true if any of the SensitiveFields currently contain a secret URL
+func (c *RequirementsConfig) HasEncryptedFields() bool {
+	if len(c.SensitiveFields) == 0 {
+		return false
+	}
+	m, err := util.ToObjectMap(c)
+	if err != nil {
+		return false
+	}
+	for _, field := range c.SensitiveFields {
+		if isSecretURL(sensitiveFieldValue(m, field)) {
+			return true
+		}
+	}
+	return false
+}
+
+// decryptSensitiveFieldsIfPossible resolves the secret URLs in the sensitive fields if a secret URL client
+// can be created, otherwise the secret URLs are left in place
+func (c *RequirementsConfig) decryptSensitiveFieldsIfPossible(fileName string, options RequirementsOptions) {
+	if options.SecretURLClientFactory == nil || !c.HasEncryptedFields() {
+		return
+	}
+	client, err := options.SecretURLClientFactory(c.SecretStorage)
+	if err == nil {
+		err = c.DecryptSensitiveFields(client)
+	}
+	if err != nil {
+		log.Logger().Warnf("unable to decrypt the sensitive fields in %s: %s", fileName, err.Error())
+	}
+}
+
+// objectWithSecretURLs returns the requirements to be saved with the values of any sensitive fields which are secret
+// URLs in the saved requirements file replaced by those URLs. Changed values are written to the secret storage if a
+// secret URL client can be created, otherwise the changes are not saved and a warning is logged
+func (c *RequirementsConfig) objectWithSecretURLs(saved *savedRequirements, options RequirementsOptions) (interface{}, error) {
+	if saved == nil || len(c.SensitiveFields) == 0 {
+		return c, nil
+	}
+	loadedMap, err := util.ToObjectMap(saved.loaded)
+	if err != nil {
+		return nil, errors.Wrap(err, "converting the loaded requirements to a map")
+	}
+	m, err := util.ToObjectMap(c)
+	if err != nil {
+		return nil, errors.Wrap(err, "converting requirements to a map")
+	}
+	var client secreturl.Client
+	var unsaved []string
+	for _, field := range c.SensitiveFields {
+		url := sensitiveFieldValue(loadedMap, field)
+		value := sensitiveFieldValue(m, field)
+		if !isSecretURL(url) || value == url || isSecretURL(value) {
+			continue
+		}
+		if client == nil && options.SecretURLClientFactory != nil {
+			client, err = options.SecretURLClientFactory(c.SecretStorage)
+			if err != nil {
+				log.Logger().Warnf("unable to create the secret URL client for %s: %s", c.SecretStorage, err.Error())
+				client = nil
+				options.SecretURLClientFactory = nil
+			}
+		}
+		if client == nil {
+			unsaved = append(unsaved, field)
+		} else {
+			err = writeSensitiveValue(client, url, value)
+			if err != nil {
+				return nil, errors.Wrapf(err, "saving sensitive requirement %s", field)
+			}
+		}
+		util.SetMapValueViaPath(m, field, url)
+	}
+	if len(unsaved) > 0 {
+		log.Logger().Warnf("the changes to the encrypted requirements %s are not saved as the secret storage is not available",
+			strings.Join(unsaved, ", "))
+	}
+	return m, nil
+}
+
+// writeSensitiveValue writes the value to the secret URL if it has changed
+func writeSensitiveValue(client secreturl.Client, url string, value string) error {
+	existing, err := secreturl.ResolveURI(client, url)
+	if err == nil && existing == value {
+		return nil
+	}
+	_, path, key, err := secreturl.ParseURI(url)
+	if err != nil {
+		return err
+	}
+	secret, err := client.Read(path)
+	if err != nil || secret == nil {
+		secret = map[string]interface{}{}
+	}
+	secret[key] = value
+	_, err = client.Write(path, secret)
+	if err != nil {
+		return errors.Wrapf(err, "writing secret %s", path)
+	}
+	return nil
+}
+
+// replaceFromMap replaces the contents of this configuration with the given map
+func (c *RequirementsConfig) replaceFromMap(m map[string]interface{}) error {
+	data, err := yaml.Marshal(m)
+	if err != nil {
+		return errors.Wrap(err, "marshalling requirements")
+	}
+	answer := RequirementsConfig{}
+	err = yaml.Unmarshal(data, &answer)
+	if err != nil {
+		return errors.Wrap(err, "unmarshalling requirements")
+	}
+	*c = answer
+	return nil
+}
+
+// sensitiveFieldValue returns the string value at the given path without creating any missing maps
+func sensitiveFieldValue(m map[string]interface{}, path string) string {
+	text, _ := lookupMapValue(m, path).(string)
+	return text
+}
+
+func isSecretURL(value string) bool {
+	for _, storage := range SecretStorageTypeValues {
+		if strings.HasPrefix(value, storage+":") {
+			return true
+		}
+	}
+	return false
+}
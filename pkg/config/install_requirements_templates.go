@@ -0,0 +1,98 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"text/template"
+
+	v1 "github.com/jenkins-x/jx/v2/pkg/apis/jenkins.io/v1"
+	"github.com/pkg/errors"
+)
+
+// EnvironmentTemplateConfig configures a template used by `jx create environment --template` to create long lived
+// environments in a single command
+type EnvironmentTemplateConfig struct {
+	// Name the name of the template
+	Name string `json:"name"`
+	// Namespace the Go template of the namespace of the environment such as `{{ .Team }}-{{ .Name }}` which can use the
+	// team and the environment name. Defaults to the team namespace followed by the environment name
+	Namespace string `json:"namespace,omitempty"`
+	// NamespaceLabels the labels added to the namespace of the environment
+	NamespaceLabels map[string]string `json:"namespaceLabels,omitempty"`
+	// NamespaceAnnotations the annotations added to the namespace of the environment
+	NamespaceAnnotations map[string]string `json:"namespaceAnnotations,omitempty"`
+	// Policy the resource quotas and namespace policies applied to the namespace of the environment
+	Policy *NamespacePolicyConfig `json:"policy,omitempty"`
+	// PromotionStrategy the promotion strategy of the environment
+	PromotionStrategy v1.PromotionStrategyType `json:"promotionStrategy,omitempty"`
+	// Order the order of the environment
+	Order int32 `json:"order,omitempty"`
+	// RemoteCluster the name of the remote cluster from the clusters section which runs the environment
+	RemoteCluster string `json:"remoteCluster,omitempty"`
+	// GitTemplateURL the git URL of the repository the environment repository is created from
+	GitTemplateURL string `json:"gitTemplateUrl,omitempty"`
+	// BranchPattern the branch pattern of the webhook which triggers the pipelines of the environment repository
+	BranchPattern string `json:"branchPattern,omitempty"`
+	// PullSecrets the names of the image pull secrets attached to the default service account of the namespace
+	PullSecrets []string `json:"pullSecrets,omitempty"`
+	// Apps the charts deployed to the environment when it is created
+	Apps []EnvironmentTemplateApp `json:"apps,omitempty"`
+}
+
+// EnvironmentTemplateApp a chart deployed to the environments created from a template
+type EnvironmentTemplateApp struct {
+	// Name the name of the chart
+	Name string `json:"name"`
+	// Version the version of the chart
+	Version string `json:"version"`
+	// Repository the URL of the chart repository
+	Repository string `json:"repository"`
+	// Alias the optional alias of the chart in the environment
+	Alias string `json:"alias,omitempty"`
+}
+
+// EnvironmentTemplate returns the environment template with the given name
+func (c *RequirementsConfig) EnvironmentTemplate(name string) (*EnvironmentTemplateConfig, error) {
+	names := []string{}
+	for i := range c.EnvironmentTemplates {
+		t := &c.EnvironmentTemplates[i]
+		if t.Name == name {
+			return t, nil
+		}
+		names = append(names, t.Name)
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("environment template %q not found as the requirements have no environmentTemplates", name)
+	}
+	return nil, fmt.Errorf("environment template %q not found, the templates are: %v", name, names)
+}
+
+// RemoteCluster returns the remote cluster with the given name or nil if there is no such cluster
+func (c *RequirementsConfig) RemoteCluster(name string) *RemoteClusterConfig {
+	for i := range c.Clusters {
+		if c.Clusters[i].Name == name {
+			return &c.Clusters[i]
+		}
+	}
+	return nil
+}
+
+// NamespaceFor returns the namespace of the environment with the given name created from the template in the team
+func (t *EnvironmentTemplateConfig) NamespaceFor(team string, name string) (string, error) {
+	if t.Namespace == "" {
+		return team + "-" + name, nil
+	}
+	tmpl, err := template.New(t.Name).Option("missingkey=error").Parse(t.Namespace)
+	if err != nil {
+		return "", errors.Wrapf(err, "parsing the namespace %q of environment template %s", t.Namespace, t.Name)
+	}
+	var buf bytes.Buffer
+	err = tmpl.Execute(&buf, map[string]string{
+		"Team": team,
+		"Name": name,
+	})
+	if err != nil {
+		return "", errors.Wrapf(err, "evaluating the namespace %q of environment template %s", t.Namespace, t.Name)
+	}
+	return buf.String(), nil
+}
@@ -9,6 +9,7 @@ import (
 	"path"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
@@ -19,6 +20,8 @@ import (
 	"github.com/jenkins-x/jx/v2/pkg/config"
 	"github.com/jenkins-x/jx/v2/pkg/gits"
 	"github.com/jenkins-x/jx/v2/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/secreturl"
+	"github.com/jenkins-x/jx/v2/pkg/secreturl/fakevault"
 	"github.com/jenkins-x/jx/v2/pkg/util"
 	"github.com/stretchr/testify/assert"
 )
@@ -467,5 +470,655 @@ func TestLoadRequirementsConfig_load_invalid_yaml(t *testing.T) {
 
 	_, _, err = config.LoadRequirementsConfig(testDir, config.DefaultFailOnValidationError)
 	requirementsConfigPath := path.Join(absolute, config.RequirementsConfigFileName)
-	assert.EqualError(t, err, fmt.Sprintf("validation failures in YAML file %s:\nenvironments.0: Additional property namespace is not allowed", requirementsConfigPath))
+	assert.EqualError(t, err, fmt.Sprintf("validation failures in YAML file %s:\nline 26: environments.0: Additional property namespace is not allowed", requirementsConfigPath))
+}
+
+func TestRemoteEnvironments(t *testing.T) {
+	t.Parallel()
+
+	requirements := config.NewRequirementsConfig()
+	requirements.Environments = []config.EnvironmentConfig{
+		{Key: "dev"},
+		{Key: "staging", RemoteCluster: true},
+		{Key: "production", RemoteCluster: true},
+	}
+
+	remotes := requirements.RemoteEnvironments()
+	require.Len(t, remotes, 2)
+	assert.Equal(t, "staging", remotes[0].Key)
+	assert.Equal(t, "production", remotes[1].Key)
+
+	assert.False(t, requirements.IsRemoteEnvironment("dev"))
+	assert.True(t, requirements.IsRemoteEnvironment("staging"))
+	assert.False(t, requirements.IsRemoteEnvironment("does-not-exist"))
+}
+
+func TestDeployEngine(t *testing.T) {
+	t.Parallel()
+
+	requirements := config.NewRequirementsConfig()
+	requirements.Environments = []config.EnvironmentConfig{
+		{Key: "dev"},
+		{Key: "staging", DeployEngine: config.DeployEngineHelmfile},
+	}
+
+	assert.Equal(t, config.DeployEngineHelm, requirements.DeployEngine("dev"))
+	assert.Equal(t, config.DeployEngineHelmfile, requirements.DeployEngine("staging"))
+	assert.Equal(t, config.DeployEngineHelm, requirements.DeployEngine("does-not-exist"))
+}
+
+func TestClusterForEnvironment(t *testing.T) {
+	t.Parallel()
+
+	requirements := config.NewRequirementsConfig()
+	requirements.Environments = []config.EnvironmentConfig{
+		{Key: "dev"},
+		{Key: "staging"},
+		{Key: "production"},
+	}
+	requirements.Clusters = []config.RemoteClusterConfig{
+		{
+			Name:              "prod-cluster",
+			Environment:       "production",
+			Context:           "gke_myproject_europe-west1_prod",
+			Region:            "europe-west1",
+			EnvironmentGitURL: "https://github.com/myorg/environment-prod.git",
+		},
+	}
+
+	assert.Nil(t, requirements.ClusterForEnvironment("staging"))
+	cluster := requirements.ClusterForEnvironment("production")
+	require.NotNil(t, cluster)
+	assert.Equal(t, "gke_myproject_europe-west1_prod", cluster.Context)
+
+	assert.False(t, requirements.IsRemoteEnvironment("staging"))
+	assert.True(t, requirements.IsRemoteEnvironment("production"))
+	remotes := requirements.RemoteEnvironments()
+	require.Len(t, remotes, 1)
+	assert.Equal(t, "production", remotes[0].Key)
+}
+
+func TestRequirementsDeprecations(t *testing.T) {
+	t.Parallel()
+
+	data := []byte(`cluster:
+  provider: gke
+  vaultName: myvault
+  vaultSAName: myvault-sa
+vault:
+  serviceAccount: myvault-sa
+`)
+	warnings := config.RequirementsDeprecations(data)
+	assert.Equal(t, []string{"line 3: cluster.vaultName is deprecated, use vault.name instead"}, warnings)
+}
+
+func TestValidateRequirementsData(t *testing.T) {
+	t.Parallel()
+
+	data := []byte(`cluster:
+  provider: gke
+  clusterNme: foo
+kaniko: "yes"
+`)
+	validationErrors, err := config.ValidateRequirementsData(data)
+	require.NoError(t, err)
+	assert.Contains(t, validationErrors, "line 3: cluster: Additional property clusterNme is not allowed")
+	assert.Contains(t, validationErrors, "line 4: kaniko: Invalid type. Expected: boolean, given: string")
+}
+
+func TestValidateRequirementsDataRejectsUnsupportedGovernanceValues(t *testing.T) {
+	t.Parallel()
+
+	data := []byte(`governance:
+  default:
+    networkPolicy: allow-all
+  environments:
+    production:
+      networkPolicy: deny-all
+      podSecurity: strict
+`)
+	validationErrors, err := config.ValidateRequirementsData(data)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"line 3: governance.default.networkPolicy: unsupported network policy allow-all, supported values: deny-all, none, same-namespace",
+		"line 7: governance.environments.production.podSecurity: unsupported pod security level strict, supported values: baseline, privileged, restricted",
+	}, validationErrors)
+
+	data = []byte(`governance:
+  default:
+    networkPolicy: same-namespace
+    podSecurity: baseline
+`)
+	validationErrors, err = config.ValidateRequirementsData(data)
+	require.NoError(t, err)
+	assert.Empty(t, validationErrors)
+}
+
+func TestMigrateRequirementsData(t *testing.T) {
+	t.Parallel()
+
+	data := []byte(`cluster:
+  provider: gke
+  vaultName: myvault
+  environmentGitPrivate: true
+`)
+	migrated, changes, err := config.MigrateRequirementsData(data)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"copied cluster.vaultName to vault.name",
+		"replaced cluster.environmentGitPrivate with cluster.environmentGitPublic",
+	}, changes)
+
+	requirements := &config.RequirementsConfig{}
+	err = yaml.Unmarshal(migrated, requirements)
+	require.NoError(t, err)
+	assert.Equal(t, config.RequirementsAPIVersion, requirements.APIVersion)
+	assert.Equal(t, "myvault", requirements.Vault.Name)
+	assert.False(t, requirements.Cluster.EnvironmentGitPublic)
+
+	current := []byte("apiVersion: v2\ncluster:\n  provider: gke\n")
+	migrated, changes, err = config.MigrateRequirementsData(current)
+	require.NoError(t, err)
+	assert.Empty(t, changes)
+	assert.Equal(t, current, migrated)
+
+	_, _, err = config.MigrateRequirementsData([]byte("apiVersion: v99\n"))
+	assert.Error(t, err)
+}
+
+func TestLoadRequirementsConfigWithProfiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "jx-test-requirements-profiles")
+	require.NoError(t, err, "failed to create tmp directory")
+	defer func() {
+		_ = os.RemoveAll(dir)
+	}()
+
+	base := `cluster:
+  provider: gke
+  clusterName: base
+kaniko: true
+environments:
+- key: dev
+- key: staging
+  owner: base-owner
+  repository: environment-staging
+`
+	gke := `cluster:
+  clusterName: gke-cluster
+  zone: europe-west1-b
+kaniko: false
+environments:
+- key: staging
+  owner: gke-owner
+- key: production
+  remoteCluster: true
+`
+	team := `cluster:
+  clusterName: team-cluster
+`
+	err = ioutil.WriteFile(filepath.Join(dir, config.RequirementsConfigFileName), []byte(base), 0644)
+	require.NoError(t, err)
+	err = ioutil.WriteFile(filepath.Join(dir, "jx-requirements-gke.yml"), []byte(gke), 0644)
+	require.NoError(t, err)
+	err = ioutil.WriteFile(filepath.Join(dir, "jx-requirements-team.yml"), []byte(team), 0644)
+	require.NoError(t, err)
+
+	err = os.Setenv(config.RequirementsProfileEnvVar, "gke, team")
+	require.NoError(t, err)
+	defer os.Unsetenv(config.RequirementsProfileEnvVar)
+
+	requirements, _, err := config.LoadRequirementsConfig(dir, config.DefaultFailOnValidationError)
+	require.NoError(t, err)
+
+	assert.Equal(t, "team-cluster", requirements.Cluster.ClusterName)
+	assert.Equal(t, "europe-west1-b", requirements.Cluster.Zone)
+	assert.Equal(t, "gke", requirements.Cluster.Provider)
+	assert.False(t, requirements.Kaniko)
+	require.Len(t, requirements.Environments, 3)
+	assert.Equal(t, "gke-owner", requirements.Environments[1].Owner)
+	assert.Equal(t, "environment-staging", requirements.Environments[1].Repository)
+	assert.True(t, requirements.Environments[2].RemoteCluster)
+
+	err = os.Setenv(config.RequirementsProfileEnvVar, "missing")
+	require.NoError(t, err)
+	_, _, err = config.LoadRequirementsConfig(dir, config.DefaultFailOnValidationError)
+	assert.Error(t, err)
+}
+
+func TestSaveRequirementsConfigWithProfiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "jx-test-requirements-profiles-save")
+	require.NoError(t, err, "failed to create tmp directory")
+	defer func() {
+		_ = os.RemoveAll(dir)
+	}()
+
+	base := `cluster:
+  provider: gke
+  clusterName: base
+environments:
+- key: dev
+- key: staging
+  owner: base-owner
+`
+	gke := `cluster:
+  clusterName: gke-cluster
+  zone: europe-west1-b
+environments:
+- key: staging
+  owner: gke-owner
+- key: production
+  remoteCluster: true
+`
+	fileName := filepath.Join(dir, config.RequirementsConfigFileName)
+	err = ioutil.WriteFile(fileName, []byte(base), 0644)
+	require.NoError(t, err)
+	err = ioutil.WriteFile(filepath.Join(dir, "jx-requirements-gke.yml"), []byte(gke), 0644)
+	require.NoError(t, err)
+
+	options := config.RequirementsOptions{Profiles: []string{"gke"}}
+	requirements, _, err := config.LoadRequirementsConfigWithOptions(dir, config.DefaultFailOnValidationError, options)
+	require.NoError(t, err)
+
+	requirements.Cluster.ProjectID = "my-project"
+	requirements.Environments[0].Owner = "dev-owner"
+	requirements.Environments[2].Owner = "production-owner"
+	err = requirements.SaveConfigWithOptions(fileName, options)
+	require.NoError(t, err)
+
+	saved, err := config.LoadRequirementsConfigFileWithOptions(fileName, config.DefaultFailOnValidationError, config.RequirementsOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "base", saved.Cluster.ClusterName)
+	assert.Equal(t, "", saved.Cluster.Zone)
+	assert.Equal(t, "my-project", saved.Cluster.ProjectID)
+	require.Len(t, saved.Environments, 3)
+	assert.Equal(t, "dev-owner", saved.Environments[0].Owner)
+	assert.Equal(t, "base-owner", saved.Environments[1].Owner)
+	assert.Equal(t, "production", saved.Environments[2].Key)
+	assert.Equal(t, "production-owner", saved.Environments[2].Owner)
+	assert.False(t, saved.Environments[2].RemoteCluster)
+
+	reloaded, _, err := config.LoadRequirementsConfigWithOptions(dir, config.DefaultFailOnValidationError, options)
+	require.NoError(t, err)
+	assert.Equal(t, "gke-cluster", reloaded.Cluster.ClusterName)
+	assert.Equal(t, "europe-west1-b", reloaded.Cluster.Zone)
+	assert.Equal(t, "my-project", reloaded.Cluster.ProjectID)
+	assert.Equal(t, "gke-owner", reloaded.Environments[1].Owner)
+	assert.True(t, reloaded.Environments[2].RemoteCluster)
+}
+
+func TestEncryptSensitiveFields(t *testing.T) {
+	dir, err := ioutil.TempDir("", "jx-test-requirements-secrets")
+	require.NoError(t, err, "failed to create tmp directory")
+	defer func() {
+		_ = os.RemoveAll(dir)
+	}()
+
+	client := fakevault.NewFakeClient()
+	options := config.RequirementsOptions{
+		SecretURLClientFactory: func(storage config.SecretStorageType) (secreturl.Client, error) {
+			return client, nil
+		},
+	}
+
+	requirements := config.NewRequirementsConfig()
+	requirements.SecretStorage = config.SecretStorageTypeVault
+	requirements.Cluster.ClusterName = "mycluster"
+	requirements.Cluster.ProjectID = "my-project"
+	requirements.SensitiveFields = []string{"cluster.project"}
+
+	err = requirements.EncryptSensitiveFields(client)
+	require.NoError(t, err)
+	assert.Equal(t, "vault:mycluster/requirements:cluster.project", requirements.Cluster.ProjectID)
+	assert.True(t, requirements.HasEncryptedFields())
+
+	fileName := filepath.Join(dir, config.RequirementsConfigFileName)
+	err = requirements.SaveConfigWithOptions(fileName, options)
+	require.NoError(t, err)
+
+	loaded, err := config.LoadRequirementsConfigFileWithOptions(fileName, config.DefaultFailOnValidationError, options)
+	require.NoError(t, err)
+	assert.Equal(t, "my-project", loaded.Cluster.ProjectID)
+	assert.Equal(t, "mycluster", loaded.Cluster.ClusterName)
+
+	err = loaded.SaveConfigWithOptions(fileName, options)
+	require.NoError(t, err)
+	data, err := ioutil.ReadFile(fileName)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "vault:mycluster/requirements:cluster.project")
+	assert.NotContains(t, string(data), "my-project")
+
+	// a changed value is written to the secret storage rather than the file
+	loaded.Cluster.ProjectID = "new-project"
+	err = loaded.SaveConfigWithOptions(fileName, options)
+	require.NoError(t, err)
+	data, err = ioutil.ReadFile(fileName)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "vault:mycluster/requirements:cluster.project")
+	assert.NotContains(t, string(data), "new-project")
+	value, err := secreturl.ResolveURI(client, "vault:mycluster/requirements:cluster.project")
+	require.NoError(t, err)
+	assert.Equal(t, "new-project", value)
+
+	// without a secret URL client the secret URLs are kept rather than saving the values unencrypted
+	loaded.Cluster.ProjectID = "other-project"
+	err = loaded.SaveConfigWithOptions(fileName, config.RequirementsOptions{})
+	require.NoError(t, err)
+	data, err = ioutil.ReadFile(fileName)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "vault:mycluster/requirements:cluster.project")
+	assert.NotContains(t, string(data), "other-project")
+
+	encrypted, err := config.LoadRequirementsConfigFile(fileName, config.DefaultFailOnValidationError)
+	require.NoError(t, err)
+	assert.Equal(t, "vault:mycluster/requirements:cluster.project", encrypted.Cluster.ProjectID)
+}
+
+func TestDiffRequirements(t *testing.T) {
+	t.Parallel()
+
+	requirements := config.NewRequirementsConfig()
+	requirements.Ingress.Domain = "foo.com"
+	requirements.Ingress.TLS.Enabled = true
+	requirements.Kaniko = false
+	requirements.SecretStorage = config.SecretStorageTypeVault
+
+	live := map[string]interface{}{
+		"ingress.domain":      "bar.com",
+		"ingress.tls.enabled": true,
+		"kaniko":              false,
+		"secretStorage":       "local",
+	}
+	drifts, err := config.DiffRequirements(requirements, live)
+	require.NoError(t, err)
+	assert.Equal(t, []config.RequirementsDrift{
+		{Path: "ingress.domain", Requirements: "foo.com", Cluster: "bar.com"},
+		{Path: "secretStorage", Requirements: "vault", Cluster: "local"},
+	}, drifts)
+
+	err = requirements.ReconcileRequirements(live)
+	require.NoError(t, err)
+	assert.Equal(t, "bar.com", requirements.Ingress.Domain)
+	assert.Equal(t, config.SecretStorageTypeLocal, requirements.SecretStorage)
+
+	drifts, err = config.DiffRequirements(requirements, live)
+	require.NoError(t, err)
+	assert.Empty(t, drifts)
+}
+
+func TestIngressControllerAndDNSProvider(t *testing.T) {
+	t.Parallel()
+
+	ingress := config.IngressConfig{}
+	assert.Equal(t, config.IngressControllerTypeNginx, ingress.ControllerKind())
+	ns, name := ingress.ControllerService()
+	assert.Equal(t, "kube-system", ns)
+	assert.Equal(t, "jxing-nginx-ingress-controller", name)
+
+	ingress.Controller.Kind = config.IngressControllerTypeIstio
+	ns, name = ingress.ControllerService()
+	assert.Equal(t, "istio-system", ns)
+	assert.Equal(t, "istio-ingressgateway", name)
+
+	ingress.Controller.Service = "my-gateway"
+	_, name = ingress.ControllerService()
+	assert.Equal(t, "my-gateway", name)
+
+	chart, _ := ingress.ControllerChart()
+	assert.Equal(t, "", chart, "istio should not be installed with a chart")
+	ingress.Controller.Kind = config.IngressControllerTypeTraefik
+	chart, release := ingress.ControllerChart()
+	assert.Equal(t, "stable/traefik", chart)
+	assert.Equal(t, "traefik", release)
+	ingress.Controller.Kind = config.IngressControllerTypeIstio
+
+	assert.Equal(t, "aws", config.DNSProviderTypeRoute53.ExternalDNSProvider())
+	assert.Equal(t, "google", config.DNSProviderTypeCloudDNS.ExternalDNSProvider())
+	assert.Equal(t, "", config.DNSProviderType("").ExternalDNSProvider())
+
+	assert.Equal(t, config.DNSProviderTypeCloudDNS, ingress.DNSProviderKind(cloud.GKE))
+	assert.Equal(t, config.DNSProviderTypeRoute53, ingress.DNSProviderKind(cloud.EKS))
+	assert.Equal(t, config.DNSProviderTypeAzureDNS, ingress.DNSProviderKind(cloud.AKS))
+	assert.Equal(t, config.DNSProviderType(""), ingress.DNSProviderKind(cloud.KUBERNETES))
+
+	ingress.ExternalDNS = true
+	assert.NoError(t, ingress.ValidateIngressStack(cloud.GKE))
+	assert.NoError(t, ingress.ValidateIngressStack(cloud.KUBERNETES), "external-dns without a DNS provider should be skipped")
+	ingress.DNSProvider = config.DNSProviderTypeRoute53
+	assert.NoError(t, ingress.ValidateIngressStack(cloud.KUBERNETES))
+
+	ingress.Controller.Kind = config.IngressControllerTypeCloud
+	assert.Error(t, ingress.ValidateIngressStack(cloud.GKE), "cloud ingress controller requires a domain")
+	ingress.Domain = "example.com"
+	assert.NoError(t, ingress.ValidateIngressStack(cloud.GKE))
+
+	ingress.Controller.Kind = "unknown"
+	assert.Error(t, ingress.ValidateIngressStack(cloud.GKE))
+}
+
+func TestGovernancePolicyForEnvironment(t *testing.T) {
+	t.Parallel()
+
+	governance := config.GovernanceConfig{
+		Default: config.NamespacePolicyConfig{
+			ResourceQuota: map[string]string{"requests.cpu": "4"},
+			NetworkPolicy: config.NetworkPolicyTypeSameNamespace,
+		},
+		Environments: map[string]config.NamespacePolicyConfig{
+			"production": {
+				PodSecurity:   "restricted",
+				NetworkPolicy: config.NetworkPolicyTypeDenyAll,
+			},
+		},
+	}
+
+	dev := governance.PolicyForEnvironment("dev", true)
+	assert.True(t, dev.IsEmpty(), "the default policies should not be applied to the dev environment")
+
+	staging := governance.PolicyForEnvironment("staging", false)
+	assert.Equal(t, governance.Default, staging)
+
+	production := governance.PolicyForEnvironment("production", false)
+	assert.Equal(t, map[string]string{"requests.cpu": "4"}, production.ResourceQuota)
+	assert.Equal(t, config.NetworkPolicyTypeDenyAll, production.NetworkPolicy)
+	assert.Equal(t, "restricted", production.PodSecurity)
+	assert.Nil(t, production.LimitRange)
+}
+
+func TestEnvironmentFreezeAt(t *testing.T) {
+	t.Parallel()
+
+	env := config.EnvironmentConfig{
+		Key: "production",
+		Freezes: []config.FreezeWindowConfig{
+			{
+				Name:     "weekend",
+				Schedule: "0 18 * * 5",
+				Duration: "60h",
+			},
+			{
+				Name:  "migration",
+				Start: "2020-12-07T00:00:00Z",
+				End:   "2020-12-07T12:00:00Z",
+			},
+			{
+				Name:  "holidays",
+				Start: "2020-12-21",
+				End:   "2020-12-23",
+			},
+		},
+	}
+
+	freeze, _, err := env.FreezeAt(time.Date(2020, 12, 4, 17, 59, 0, 0, time.UTC))
+	require.NoError(t, err)
+	assert.Nil(t, freeze, "the weekend freeze should not have started")
+
+	freeze, end, err := env.FreezeAt(time.Date(2020, 12, 5, 12, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	require.NotNil(t, freeze)
+	assert.Equal(t, "weekend", freeze.Name)
+	assert.True(t, time.Date(2020, 12, 7, 12, 0, 0, 0, time.UTC).Equal(end), "the freeze should last until the overlapping migration freeze ends but ends at %s", end)
+
+	freeze, end, err = env.FreezeAt(time.Date(2020, 12, 22, 9, 30, 0, 0, time.UTC))
+	require.NoError(t, err)
+	require.NotNil(t, freeze)
+	assert.Equal(t, "holidays", freeze.Name)
+	assert.True(t, time.Date(2020, 12, 24, 0, 0, 0, 0, time.UTC).Equal(end), "the freeze should last until the end of its end date but ends at %s", end)
+
+	freeze, _, err = env.FreezeAt(time.Date(2020, 12, 24, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	assert.Nil(t, freeze, "the holidays freeze should have ended")
+
+	for _, w := range []config.FreezeWindowConfig{
+		{Schedule: "61 * * * *", Duration: "1h"},
+		{Schedule: "0 18 * * 5"},
+		{Schedule: "0 18 * *", Duration: "1h"},
+		{Start: "2020-12-24"},
+		{Start: "2020-12-24", End: "2020-12-23"},
+		{Start: "2020-12-24", End: "2020-12-26", TimeZone: "Nowhere/Special"},
+	} {
+		assert.Error(t, w.Validate(), "%#v", w)
+	}
+}
+
+func TestEnvironmentTemplate(t *testing.T) {
+	t.Parallel()
+
+	requirements := config.NewRequirementsConfig()
+	requirements.Clusters = []config.RemoteClusterConfig{
+		{
+			Name:    "europe",
+			Context: "gke_myproject_europe-west1_europe",
+		},
+	}
+	requirements.EnvironmentTemplates = []config.EnvironmentTemplateConfig{
+		{
+			Name: "default",
+		},
+		{
+			Name:          "regional",
+			Namespace:     "{{ .Team }}-{{ .Name }}-apps",
+			RemoteCluster: "europe",
+		},
+	}
+
+	_, err := requirements.EnvironmentTemplate("missing")
+	assert.Error(t, err)
+
+	template, err := requirements.EnvironmentTemplate("default")
+	require.NoError(t, err)
+	ns, err := template.NamespaceFor("jx", "prod")
+	require.NoError(t, err)
+	assert.Equal(t, "jx-prod", ns)
+
+	template, err = requirements.EnvironmentTemplate("regional")
+	require.NoError(t, err)
+	ns, err = template.NamespaceFor("jx", "prod-eu")
+	require.NoError(t, err)
+	assert.Equal(t, "jx-prod-eu-apps", ns)
+
+	cluster := requirements.RemoteCluster(template.RemoteCluster)
+	require.NotNil(t, cluster)
+	assert.Equal(t, "gke_myproject_europe-west1_europe", cluster.Context)
+	assert.Nil(t, requirements.RemoteCluster("asia"))
+
+	template.Namespace = "{{ .Region }}"
+	_, err = template.NamespaceFor("jx", "prod-eu")
+	assert.Error(t, err, "unknown template fields should fail")
+}
+
+func TestPreviewLifecycle(t *testing.T) {
+	t.Parallel()
+
+	d, err := config.ParsePreviewDuration("7d")
+	require.NoError(t, err)
+	assert.Equal(t, 7*24*time.Hour, d)
+	d, err = config.ParsePreviewDuration("36h")
+	require.NoError(t, err)
+	assert.Equal(t, 36*time.Hour, d)
+	d, err = config.ParsePreviewDuration("")
+	require.NoError(t, err)
+	assert.Equal(t, time.Duration(0), d, "an empty duration disables the expiry")
+	for _, text := range []string{"xd", "-1d", "-2h", "soon"} {
+		_, err = config.ParsePreviewDuration(text)
+		assert.Error(t, err, "parsing %s", text)
+	}
+
+	team := &config.PreviewsConfig{TTL: "7d", IdleTimeout: "2d"}
+	require.NoError(t, team.Validate())
+	assert.Error(t, (&config.PreviewsConfig{IdleTimeout: "forever"}).Validate())
+	assert.Equal(t, "", team.ScaleToZeroProvider())
+	team.ScaleToZero = &config.PreviewScaleToZeroConfig{Enabled: true}
+	assert.Equal(t, config.ScaleToZeroProviderKnative, team.ScaleToZeroProvider())
+	assert.Error(t, (&config.PreviewsConfig{ScaleToZero: &config.PreviewScaleToZeroConfig{Enabled: true, Provider: "hpa"}}).Validate())
+	assert.Error(t, (&config.PreviewsConfig{ScaleToZero: &config.PreviewScaleToZeroConfig{ScaleDownPeriod: "1d"}}).Validate())
+	assert.NoError(t, (&config.PreviewsConfig{Protection: &config.PreviewProtectionConfig{Kind: config.PreviewProtectionBasic}}).Validate())
+	assert.Error(t, (&config.PreviewsConfig{Protection: &config.PreviewProtectionConfig{Kind: config.PreviewProtectionOAuth2}}).Validate())
+	assert.Error(t, (&config.PreviewsConfig{Protection: &config.PreviewProtectionConfig{Kind: "ldap"}}).Validate())
+
+	ttl, idleTimeout := config.PreviewLifecycle(team, &config.PreviewEnvironmentConfig{IdleTimeout: "12h"})
+	assert.Equal(t, "7d", ttl)
+	assert.Equal(t, "12h", idleTimeout)
+
+	ttl, idleTimeout = config.PreviewLifecycle(nil, nil)
+	assert.Equal(t, "", ttl)
+	assert.Equal(t, "", idleTimeout)
+}
+
+func TestPreviewBudgetRepositoryLimits(t *testing.T) {
+	t.Parallel()
+
+	budget := &config.PreviewBudgetConfig{
+		MaxPreviewsPerRepository: 5,
+		Quota:                    map[string]string{"requests.cpu": "1"},
+		Repositories: map[string]*config.PreviewRepositoryBudget{
+			"myorg/big": {MaxPreviews: 10, Quota: map[string]string{"requests.cpu": "4"}},
+		},
+	}
+	require.NoError(t, (&config.PreviewsConfig{Budget: budget}).Validate())
+
+	max, quota := budget.RepositoryLimits("myorg/small", 0)
+	assert.Equal(t, 5, max)
+	assert.Equal(t, "1", quota["requests.cpu"])
+	max, quota = budget.RepositoryLimits("myorg/big", 0)
+	assert.Equal(t, 10, max)
+	assert.Equal(t, "4", quota["requests.cpu"])
+	max, _ = budget.RepositoryLimits("myorg/big", 3)
+	assert.Equal(t, 3, max, "the maximum instances of the jenkins-x.yml lowers the budget")
+	max, _ = budget.RepositoryLimits("myorg/big", 20)
+	assert.Equal(t, 10, max, "the maximum instances of the jenkins-x.yml cannot raise the budget")
+
+	var none *config.PreviewBudgetConfig
+	max, quota = none.RepositoryLimits("myorg/small", 2)
+	assert.Equal(t, 2, max)
+	assert.Empty(t, quota)
+
+	assert.Error(t, (&config.PreviewsConfig{Budget: &config.PreviewBudgetConfig{MaxPreviews: -1}}).Validate())
+	assert.Error(t, (&config.PreviewsConfig{Budget: &config.PreviewBudgetConfig{Repositories: map[string]*config.PreviewRepositoryBudget{"big": {}}}}).Validate())
+}
+
+func TestPreviewCluster(t *testing.T) {
+	t.Parallel()
+
+	requirements := config.NewRequirementsConfig()
+	cluster, err := requirements.PreviewCluster()
+	require.NoError(t, err)
+	assert.Nil(t, cluster, "previews run in the development cluster by default")
+
+	requirements.Clusters = []config.RemoteClusterConfig{
+		{Name: "previews", Context: "gke_myproject_previews"},
+		{Name: "staging", Environment: "staging"},
+	}
+	requirements.Previews = &config.PreviewsConfig{Cluster: "previews"}
+	cluster, err = requirements.PreviewCluster()
+	require.NoError(t, err)
+	require.NotNil(t, cluster)
+	assert.Equal(t, "gke_myproject_previews", cluster.Context)
+
+	requirements.Previews.Cluster = "staging"
+	_, err = requirements.PreviewCluster()
+	assert.Error(t, err, "the previews cluster needs a kube context")
+
+	requirements.Previews.Cluster = "unknown"
+	_, err = requirements.PreviewCluster()
+	assert.Error(t, err)
 }
@@ -0,0 +1,73 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/ghodss/yaml"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+)
+
+// deprecatedRequirements maps the paths of deprecated `jx-requirements.yml` properties to their replacements
+var deprecatedRequirements = map[string]string{
+	"cluster.vaultName":             "vault.name",
+	"cluster.vaultSAName":           "vault.serviceAccount",
+	"cluster.environmentGitPrivate": "cluster.environmentGitPublic",
+}
+
+// ValidateRequirementsData validates the given `jx-requirements.yml` data against the JSON schema generated from
+// RequirementsConfig. Unknown properties, type mismatches, other schema failures and unsupported governance policy
+// values are returned as messages prefixed with the line number of the failing property where it can be found.
+func ValidateRequirementsData(data []byte) ([]string, error) {
+	resultErrors, err := util.ValidateYamlErrors(&RequirementsConfig{}, data)
+	if err != nil {
+		return nil, err
+	}
+	var answer []string
+	for _, e := range resultErrors {
+		field := e.Field()
+		if property, ok := e.Details()["property"]; ok && e.Type() == "additional_property_not_allowed" {
+			if field == "(root)" {
+				field = fmt.Sprintf("%v", property)
+			} else {
+				field = fmt.Sprintf("%s.%v", field, property)
+			}
+		}
+		answer = append(answer, withLineNumber(data, field, e.String()))
+	}
+	answer = append(answer, governanceValidationErrors(data)...)
+	return answer, nil
+}
+
+// RequirementsDeprecations returns a warning for each deprecated property used in the given `jx-requirements.yml` data
+// without its replacement property also being specified
+func RequirementsDeprecations(data []byte) []string {
+	m := map[string]interface{}{}
+	err := yaml.Unmarshal(data, &m)
+	if err != nil {
+		return nil
+	}
+	var paths []string
+	for p := range deprecatedRequirements {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+
+	var answer []string
+	for _, p := range paths {
+		if util.GetMapValueViaPath(m, p) == nil || util.GetMapValueViaPath(m, deprecatedRequirements[p]) != nil {
+			continue
+		}
+		message := fmt.Sprintf("%s is deprecated, use %s instead", p, deprecatedRequirements[p])
+		answer = append(answer, withLineNumber(data, p, message))
+	}
+	return answer
+}
+
+func withLineNumber(data []byte, path string, message string) string {
+	line := util.YamlPathLine(data, path)
+	if line <= 0 {
+		return message
+	}
+	return fmt.Sprintf("line %d: %s", line, message)
+}
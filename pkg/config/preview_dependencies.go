@@ -0,0 +1,55 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Validate returns an error if the dependency does not specify exactly one of a version or a Pull Request
+func (d *PreviewDependency) Validate() error {
+	if d.Name == "" {
+		return fmt.Errorf("preview dependency is missing a name")
+	}
+	if (d.Version == "") == (d.PullRequest == "") {
+		return fmt.Errorf("preview dependency %s must specify either a version or a pullRequest", d.Name)
+	}
+	if d.PullRequest != "" {
+		_, _, _, err := d.PullRequestRef("owner")
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// PullRequestRef returns the owner, repository and number of the Pull Request of the dependency, the owner defaulting
+// to the given owner of the repository of the preview environment
+func (d *PreviewDependency) PullRequestRef(defaultOwner string) (string, string, int, error) {
+	idx := strings.LastIndex(d.PullRequest, "#")
+	if idx < 0 {
+		return "", "", 0, fmt.Errorf("pullRequest %q of preview dependency %s must be of the form owner/repository#number", d.PullRequest, d.Name)
+	}
+	number, err := strconv.Atoi(d.PullRequest[idx+1:])
+	if err != nil || number <= 0 {
+		return "", "", 0, fmt.Errorf("pullRequest %q of preview dependency %s does not end with a Pull Request number", d.PullRequest, d.Name)
+	}
+	owner := defaultOwner
+	repository := d.PullRequest[:idx]
+	if i := strings.Index(repository, "/"); i >= 0 {
+		owner = repository[:i]
+		repository = repository[i+1:]
+	}
+	if owner == "" || repository == "" {
+		return "", "", 0, fmt.Errorf("pullRequest %q of preview dependency %s must be of the form owner/repository#number", d.PullRequest, d.Name)
+	}
+	return owner, repository, number, nil
+}
+
+// String returns a description of the dependency for Pull Request comments
+func (d *PreviewDependency) String() string {
+	if d.PullRequest != "" {
+		return fmt.Sprintf("%s from the preview of %s", d.Name, d.PullRequest)
+	}
+	return fmt.Sprintf("%s %s", d.Name, d.Version)
+}
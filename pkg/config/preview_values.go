@@ -13,6 +13,8 @@ type Image struct {
 
 type Preview struct {
 	Image *Image `json:"image,omitempty"`
+	// KnativeDeploy deploys the application as a Knative service which scales to zero when idle
+	KnativeDeploy bool `json:"knativeDeploy,omitempty"`
 }
 
 type PreviewValuesConfig struct {
@@ -46,6 +46,59 @@ type ProjectConfig struct {
 type PreviewEnvironmentConfig struct {
 	Disabled         bool `json:"disabled,omitempty"`
 	MaximumInstances int  `json:"maximumInstances,omitempty"`
+	// TTL the maximum age of the preview environments of the repository, overriding the team default
+	TTL string `json:"ttl,omitempty"`
+	// IdleTimeout how long the preview environments of the repository are kept without new commits, overriding the
+	// team default
+	IdleTimeout string `json:"idleTimeout,omitempty"`
+	// Dependencies the other services deployed into or connected to the preview environments of the repository so
+	// that cross service changes can be previewed together
+	Dependencies []PreviewDependency `json:"dependencies,omitempty"`
+	// Fixtures the resources such as database schemas provisioned for each preview environment of the repository and
+	// destroyed with it
+	Fixtures []PreviewFixture `json:"fixtures,omitempty"`
+}
+
+// PreviewFixture a resource provisioned for a preview environment, such as a database schema, whose connection
+// details are stored in a Secret in the preview namespace
+type PreviewFixture struct {
+	// Name the name of the fixture
+	Name string `json:"name"`
+	// Kind the kind of the fixture such as `postgres` or `command`
+	Kind string `json:"kind"`
+	// Secret the name of the Secret in the preview namespace with the connection details, defaulting to the name
+	Secret string `json:"secret,omitempty"`
+	// Seed the file of the repository with the data to seed the fixture with, such as a SQL file for `postgres`
+	Seed string `json:"seed,omitempty"`
+	// Provision the command of a `command` fixture which provisions it and outputs its connection details as
+	// KEY=VALUE lines
+	Provision string `json:"provision,omitempty"`
+	// Destroy the command of a `command` fixture which destroys it
+	Destroy string `json:"destroy,omitempty"`
+	// Parameters the parameters specific to the kind of the fixture
+	Parameters map[string]string `json:"parameters,omitempty"`
+}
+
+// SecretName returns the name of the Secret with the connection details of the fixture
+func (f *PreviewFixture) SecretName() string {
+	if f.Secret != "" {
+		return f.Secret
+	}
+	return f.Name
+}
+
+// PreviewDependency a service which a preview environment depends on, either deployed at a version into the preview
+// environment or connected to the preview environment of an open Pull Request of the service
+type PreviewDependency struct {
+	// Name the name of the chart of the service, which is also the name of its kubernetes service
+	Name string `json:"name"`
+	// Version the version of the chart to deploy into the preview environment
+	Version string `json:"version,omitempty"`
+	// Repository the chart repository of the chart, defaulting to the chart repository of the team
+	Repository string `json:"repository,omitempty"`
+	// PullRequest the Pull Request whose preview environment to connect to such as `myorg/orders#12` or `orders#12`
+	// for a repository of the same owner
+	PullRequest string `json:"pullRequest,omitempty"`
 }
 
 type IssueTrackerConfig struct {
@@ -104,3 +104,34 @@ func TestGetPipeline(t *testing.T) {
 	assert.Equal(t, err.Error(), "no pipeline defined for kind feature")
 	assert.Nil(t, featurePipeline)
 }
+
+func TestPreviewDependencies(t *testing.T) {
+	t.Parallel()
+
+	d := &config.PreviewDependency{Name: "orders", PullRequest: "orders#12"}
+	assert.NoError(t, d.Validate())
+	owner, repository, number, err := d.PullRequestRef("myorg")
+	assert.NoError(t, err)
+	assert.Equal(t, "myorg", owner)
+	assert.Equal(t, "orders", repository)
+	assert.Equal(t, 12, number)
+
+	d = &config.PreviewDependency{Name: "payments", PullRequest: "otherorg/payments-service#3"}
+	owner, repository, number, err = d.PullRequestRef("myorg")
+	assert.NoError(t, err)
+	assert.Equal(t, "otherorg", owner)
+	assert.Equal(t, "payments-service", repository)
+	assert.Equal(t, 3, number)
+
+	assert.NoError(t, (&config.PreviewDependency{Name: "orders", Version: "1.2.3"}).Validate())
+	for _, d := range []config.PreviewDependency{
+		{Version: "1.2.3"},
+		{Name: "orders"},
+		{Name: "orders", Version: "1.2.3", PullRequest: "orders#12"},
+		{Name: "orders", PullRequest: "orders"},
+		{Name: "orders", PullRequest: "orders#abc"},
+		{Name: "orders", PullRequest: "/orders#1"},
+	} {
+		assert.Error(t, d.Validate(), "validating %#v", d)
+	}
+}
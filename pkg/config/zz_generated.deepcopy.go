@@ -321,6 +321,22 @@ func (in *ClusterConfig) DeepCopy() *ClusterConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CommitSigningConfig) DeepCopyInto(out *CommitSigningConfig) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CommitSigningConfig.
+func (in *CommitSigningConfig) DeepCopy() *CommitSigningConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(CommitSigningConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *EnabledConfig) DeepCopyInto(out *EnabledConfig) {
 	*out = *in
@@ -341,6 +357,16 @@ func (in *EnabledConfig) DeepCopy() *EnabledConfig {
 func (in *EnvironmentConfig) DeepCopyInto(out *EnvironmentConfig) {
 	*out = *in
 	out.Ingress = in.Ingress
+	if in.Approval != nil {
+		in, out := &in.Approval, &out.Approval
+		*out = new(PromotionApprovalConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Freezes != nil {
+		in, out := &in.Freezes, &out.Freezes
+		*out = make([]FreezeWindowConfig, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
@@ -354,6 +380,67 @@ func (in *EnvironmentConfig) DeepCopy() *EnvironmentConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EnvironmentTemplateApp) DeepCopyInto(out *EnvironmentTemplateApp) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EnvironmentTemplateApp.
+func (in *EnvironmentTemplateApp) DeepCopy() *EnvironmentTemplateApp {
+	if in == nil {
+		return nil
+	}
+	out := new(EnvironmentTemplateApp)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EnvironmentTemplateConfig) DeepCopyInto(out *EnvironmentTemplateConfig) {
+	*out = *in
+	if in.NamespaceLabels != nil {
+		in, out := &in.NamespaceLabels, &out.NamespaceLabels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.NamespaceAnnotations != nil {
+		in, out := &in.NamespaceAnnotations, &out.NamespaceAnnotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Policy != nil {
+		in, out := &in.Policy, &out.Policy
+		*out = new(NamespacePolicyConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PullSecrets != nil {
+		in, out := &in.PullSecrets, &out.PullSecrets
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Apps != nil {
+		in, out := &in.Apps, &out.Apps
+		*out = make([]EnvironmentTemplateApp, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EnvironmentTemplateConfig.
+func (in *EnvironmentTemplateConfig) DeepCopy() *EnvironmentTemplateConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(EnvironmentTemplateConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ExposeController) DeepCopyInto(out *ExposeController) {
 	*out = *in
@@ -394,6 +481,38 @@ func (in *ExposeControllerConfig) DeepCopy() *ExposeControllerConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExternalSecretsConfig) DeepCopyInto(out *ExternalSecretsConfig) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExternalSecretsConfig.
+func (in *ExternalSecretsConfig) DeepCopy() *ExternalSecretsConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(ExternalSecretsConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FreezeWindowConfig) DeepCopyInto(out *FreezeWindowConfig) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FreezeWindowConfig.
+func (in *FreezeWindowConfig) DeepCopy() *FreezeWindowConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(FreezeWindowConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *GKEConfig) DeepCopyInto(out *GKEConfig) {
 	*out = *in
@@ -426,6 +545,30 @@ func (in *GithubAppConfig) DeepCopy() *GithubAppConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GovernanceConfig) DeepCopyInto(out *GovernanceConfig) {
+	*out = *in
+	in.Default.DeepCopyInto(&out.Default)
+	if in.Environments != nil {
+		in, out := &in.Environments, &out.Environments
+		*out = make(map[string]NamespacePolicyConfig, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GovernanceConfig.
+func (in *GovernanceConfig) DeepCopy() *GovernanceConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(GovernanceConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Grafana) DeepCopyInto(out *Grafana) {
 	*out = *in
@@ -535,6 +678,7 @@ func (in *Image) DeepCopy() *Image {
 func (in *IngressConfig) DeepCopyInto(out *IngressConfig) {
 	*out = *in
 	out.TLS = in.TLS
+	out.Controller = in.Controller
 	return
 }
 
@@ -548,6 +692,22 @@ func (in *IngressConfig) DeepCopy() *IngressConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IngressControllerConfig) DeepCopyInto(out *IngressControllerConfig) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IngressControllerConfig.
+func (in *IngressControllerConfig) DeepCopy() *IngressControllerConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(IngressControllerConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *IssueTrackerConfig) DeepCopyInto(out *IssueTrackerConfig) {
 	*out = *in
@@ -749,6 +909,71 @@ func (in *KanikoSecret) DeepCopy() *KanikoSecret {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LimitRangeConfig) DeepCopyInto(out *LimitRangeConfig) {
+	*out = *in
+	if in.DefaultRequests != nil {
+		in, out := &in.DefaultRequests, &out.DefaultRequests
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.DefaultLimits != nil {
+		in, out := &in.DefaultLimits, &out.DefaultLimits
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Max != nil {
+		in, out := &in.Max, &out.Max
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LimitRangeConfig.
+func (in *LimitRangeConfig) DeepCopy() *LimitRangeConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(LimitRangeConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NamespacePolicyConfig) DeepCopyInto(out *NamespacePolicyConfig) {
+	*out = *in
+	if in.ResourceQuota != nil {
+		in, out := &in.ResourceQuota, &out.ResourceQuota
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.LimitRange != nil {
+		in, out := &in.LimitRange, &out.LimitRange
+		*out = new(LimitRangeConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NamespacePolicyConfig.
+func (in *NamespacePolicyConfig) DeepCopy() *NamespacePolicyConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(NamespacePolicyConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Nexus) DeepCopyInto(out *Nexus) {
 	*out = *in
@@ -802,12 +1027,108 @@ func (in *Preview) DeepCopy() *Preview {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PreviewBudgetConfig) DeepCopyInto(out *PreviewBudgetConfig) {
+	*out = *in
+	if in.Quota != nil {
+		in, out := &in.Quota, &out.Quota
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.DefaultRequests != nil {
+		in, out := &in.DefaultRequests, &out.DefaultRequests
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Repositories != nil {
+		in, out := &in.Repositories, &out.Repositories
+		*out = make(map[string]*PreviewRepositoryBudget, len(*in))
+		for key, val := range *in {
+			var outVal *PreviewRepositoryBudget
+			if val == nil {
+				(*out)[key] = nil
+			} else {
+				in, out := &val, &outVal
+				*out = new(PreviewRepositoryBudget)
+				(*in).DeepCopyInto(*out)
+			}
+			(*out)[key] = outVal
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PreviewBudgetConfig.
+func (in *PreviewBudgetConfig) DeepCopy() *PreviewBudgetConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(PreviewBudgetConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PreviewDependency) DeepCopyInto(out *PreviewDependency) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PreviewDependency.
+func (in *PreviewDependency) DeepCopy() *PreviewDependency {
+	if in == nil {
+		return nil
+	}
+	out := new(PreviewDependency)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PreviewEnvironmentConfig) DeepCopyInto(out *PreviewEnvironmentConfig) {
 	*out = *in
+	if in.Dependencies != nil {
+		in, out := &in.Dependencies, &out.Dependencies
+		*out = make([]PreviewDependency, len(*in))
+		copy(*out, *in)
+	}
+	if in.Fixtures != nil {
+		in, out := &in.Fixtures, &out.Fixtures
+		*out = make([]PreviewFixture, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PreviewFixture) DeepCopyInto(out *PreviewFixture) {
+	*out = *in
+	if in.Parameters != nil {
+		in, out := &in.Parameters, &out.Parameters
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PreviewFixture.
+func (in *PreviewFixture) DeepCopy() *PreviewFixture {
+	if in == nil {
+		return nil
+	}
+	out := new(PreviewFixture)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PreviewEnvironmentConfig.
 func (in *PreviewEnvironmentConfig) DeepCopy() *PreviewEnvironmentConfig {
 	if in == nil {
@@ -857,7 +1178,7 @@ func (in *ProjectConfig) DeepCopyInto(out *ProjectConfig) {
 	if in.PreviewEnvironments != nil {
 		in, out := &in.PreviewEnvironments, &out.PreviewEnvironments
 		*out = new(PreviewEnvironmentConfig)
-		**out = **in
+		(*in).DeepCopyInto(*out)
 	}
 	if in.IssueTracker != nil {
 		in, out := &in.IssueTracker, &out.IssueTracker
@@ -919,24 +1240,312 @@ func (in *ProwValuesConfig) DeepCopy() *ProwValuesConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PipelinePodsConfig) DeepCopyInto(out *PipelinePodsConfig) {
+	*out = *in
+	if in.Requests != nil {
+		in, out := &in.Requests, &out.Requests
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Limits != nil {
+		in, out := &in.Limits, &out.Limits
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Tolerations != nil {
+		in, out := &in.Tolerations, &out.Tolerations
+		*out = make([]v1.Toleration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.SecurityContext != nil {
+		in, out := &in.SecurityContext, &out.SecurityContext
+		*out = new(v1.PodSecurityContext)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PipelinePodsConfig.
+func (in *PipelinePodsConfig) DeepCopy() *PipelinePodsConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(PipelinePodsConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PreviewProtectionConfig) DeepCopyInto(out *PreviewProtectionConfig) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PreviewProtectionConfig.
+func (in *PreviewProtectionConfig) DeepCopy() *PreviewProtectionConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(PreviewProtectionConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PreviewRepositoryBudget) DeepCopyInto(out *PreviewRepositoryBudget) {
+	*out = *in
+	if in.Quota != nil {
+		in, out := &in.Quota, &out.Quota
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PreviewRepositoryBudget.
+func (in *PreviewRepositoryBudget) DeepCopy() *PreviewRepositoryBudget {
+	if in == nil {
+		return nil
+	}
+	out := new(PreviewRepositoryBudget)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PreviewScaleToZeroConfig) DeepCopyInto(out *PreviewScaleToZeroConfig) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PreviewScaleToZeroConfig.
+func (in *PreviewScaleToZeroConfig) DeepCopy() *PreviewScaleToZeroConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(PreviewScaleToZeroConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PreviewsConfig) DeepCopyInto(out *PreviewsConfig) {
+	*out = *in
+	if in.ScaleToZero != nil {
+		in, out := &in.ScaleToZero, &out.ScaleToZero
+		*out = new(PreviewScaleToZeroConfig)
+		**out = **in
+	}
+	if in.Protection != nil {
+		in, out := &in.Protection, &out.Protection
+		*out = new(PreviewProtectionConfig)
+		**out = **in
+	}
+	if in.Budget != nil {
+		in, out := &in.Budget, &out.Budget
+		*out = new(PreviewBudgetConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PreviewsConfig.
+func (in *PreviewsConfig) DeepCopy() *PreviewsConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(PreviewsConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProgressiveDeliveryConfig) DeepCopyInto(out *ProgressiveDeliveryConfig) {
+	*out = *in
+	if in.Environments != nil {
+		in, out := &in.Environments, &out.Environments
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProgressiveDeliveryConfig.
+func (in *ProgressiveDeliveryConfig) DeepCopy() *ProgressiveDeliveryConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(ProgressiveDeliveryConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PromotionApprovalConfig) DeepCopyInto(out *PromotionApprovalConfig) {
+	*out = *in
+	if in.Approvers != nil {
+		in, out := &in.Approvers, &out.Approvers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PromotionApprovalConfig.
+func (in *PromotionApprovalConfig) DeepCopy() *PromotionApprovalConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(PromotionApprovalConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PullRequestsConfig) DeepCopyInto(out *PullRequestsConfig) {
+	*out = *in
+	if in.Reviewers != nil {
+		in, out := &in.Reviewers, &out.Reviewers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.TeamReviewers != nil {
+		in, out := &in.TeamReviewers, &out.TeamReviewers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Assignees != nil {
+		in, out := &in.Assignees, &out.Assignees
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PullRequestsConfig.
+func (in *PullRequestsConfig) DeepCopy() *PullRequestsConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(PullRequestsConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RemoteClusterConfig) DeepCopyInto(out *RemoteClusterConfig) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RemoteClusterConfig.
+func (in *RemoteClusterConfig) DeepCopy() *RemoteClusterConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(RemoteClusterConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RequirementsConfig) DeepCopyInto(out *RequirementsConfig) {
 	*out = *in
 	out.AutoUpdate = in.AutoUpdate
 	out.BuildPacks = in.BuildPacks
+	if in.BuildPlatforms != nil {
+		in, out := &in.BuildPlatforms, &out.BuildPlatforms
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	in.Cluster.DeepCopyInto(&out.Cluster)
+	if in.Clusters != nil {
+		in, out := &in.Clusters, &out.Clusters
+		*out = make([]RemoteClusterConfig, len(*in))
+		copy(*out, *in)
+	}
+	if in.CommitSigning != nil {
+		in, out := &in.CommitSigning, &out.CommitSigning
+		*out = new(CommitSigningConfig)
+		**out = **in
+	}
 	if in.Environments != nil {
 		in, out := &in.Environments, &out.Environments
 		*out = make([]EnvironmentConfig, len(*in))
-		copy(*out, *in)
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ExternalSecrets != nil {
+		in, out := &in.ExternalSecrets, &out.ExternalSecrets
+		*out = new(ExternalSecretsConfig)
+		**out = **in
 	}
 	if in.GithubApp != nil {
 		in, out := &in.GithubApp, &out.GithubApp
 		*out = new(GithubAppConfig)
 		**out = **in
 	}
+	if in.Governance != nil {
+		in, out := &in.Governance, &out.Governance
+		*out = new(GovernanceConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.EnvironmentTemplates != nil {
+		in, out := &in.EnvironmentTemplates, &out.EnvironmentTemplates
+		*out = make([]EnvironmentTemplateConfig, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	out.Ingress = in.Ingress
+	if in.PipelinePods != nil {
+		in, out := &in.PipelinePods, &out.PipelinePods
+		*out = new(PipelinePodsConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Previews != nil {
+		in, out := &in.Previews, &out.Previews
+		*out = new(PreviewsConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ProgressiveDelivery != nil {
+		in, out := &in.ProgressiveDelivery, &out.ProgressiveDelivery
+		*out = new(ProgressiveDeliveryConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PullRequests != nil {
+		in, out := &in.PullRequests, &out.PullRequests
+		*out = new(PullRequestsConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SensitiveFields != nil {
+		in, out := &in.SensitiveFields, &out.SensitiveFields
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	out.Storage = in.Storage
+	if in.SupplyChain != nil {
+		in, out := &in.SupplyChain, &out.SupplyChain
+		*out = new(SupplyChainConfig)
+		**out = **in
+	}
 	in.Vault.DeepCopyInto(&out.Vault)
 	out.Velero = in.Velero
 	out.VersionStream = in.VersionStream
@@ -1010,6 +1619,22 @@ func (in *StorageEntryConfig) DeepCopy() *StorageEntryConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SupplyChainConfig) DeepCopyInto(out *SupplyChainConfig) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SupplyChainConfig.
+func (in *SupplyChainConfig) DeepCopy() *SupplyChainConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(SupplyChainConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TLSConfig) DeepCopyInto(out *TLSConfig) {
 	*out = *in
@@ -1059,6 +1684,27 @@ func (in *VaultAWSUnsealConfig) DeepCopy() *VaultAWSUnsealConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VaultAgentConfig) DeepCopyInto(out *VaultAgentConfig) {
+	*out = *in
+	if in.PipelineSecrets != nil {
+		in, out := &in.PipelineSecrets, &out.PipelineSecrets
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultAgentConfig.
+func (in *VaultAgentConfig) DeepCopy() *VaultAgentConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(VaultAgentConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *VaultConfig) DeepCopyInto(out *VaultConfig) {
 	*out = *in
@@ -1067,6 +1713,11 @@ func (in *VaultConfig) DeepCopyInto(out *VaultConfig) {
 		*out = new(VaultAWSConfig)
 		**out = **in
 	}
+	if in.Agent != nil {
+		in, out := &in.Agent, &out.Agent
+		*out = new(VaultAgentConfig)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
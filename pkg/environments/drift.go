@@ -0,0 +1,145 @@
+package environments
+
+import (
+	"bufio"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// kubectlDiffPrefix the prefix of the line of the output of kubectl diff which starts the diff of a resource
+	kubectlDiffPrefix = "diff -u -N "
+
+	// annotationHelmHook the annotation of the resources which helm only creates for hooks and tests
+	annotationHelmHook = "helm.sh/hook"
+)
+
+// DriftedResource is a resource of an environment which differs in the cluster from the environment chart in git
+type DriftedResource struct {
+	Kind      string
+	Namespace string
+	Name      string
+	Diff      string
+}
+
+// String returns the kind and name of the resource
+func (r *DriftedResource) String() string {
+	if r.Namespace == "" {
+		return fmt.Sprintf("%s %s", r.Kind, r.Name)
+	}
+	return fmt.Sprintf("%s %s/%s", r.Kind, r.Namespace, r.Name)
+}
+
+// RemoveUnmanagedResources removes the Secrets and helm hook resources from the YAML files in dir
+func RemoveUnmanagedResources(dir string) error {
+	return filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() || !strings.HasSuffix(path, ".yaml") {
+			return err
+		}
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return errors.Wrapf(err, "failed to read file %s", path)
+		}
+		docs := []string{}
+		for _, doc := range splitYAMLDocuments(string(data)) {
+			obj := map[string]interface{}{}
+			err = yaml.Unmarshal([]byte(doc), &obj)
+			if err != nil {
+				return errors.Wrapf(err, "failed to parse a resource in file %s", path)
+			}
+			if len(obj) == 0 || isUnmanagedResource(obj) {
+				continue
+			}
+			docs = append(docs, doc)
+		}
+		if len(docs) == 0 {
+			return os.Remove(path)
+		}
+		return ioutil.WriteFile(path, []byte(strings.Join(docs, "---\n")), info.Mode())
+	})
+}
+
+// isUnmanagedResource returns true if the resource is a Secret or a helm hook
+func isUnmanagedResource(obj map[string]interface{}) bool {
+	if obj["kind"] == "Secret" {
+		return true
+	}
+	metadata, _ := obj["metadata"].(map[string]interface{})
+	annotations, _ := metadata["annotations"].(map[string]interface{})
+	_, hook := annotations[annotationHelmHook]
+	return hook
+}
+
+// splitYAMLDocuments splits the YAML into its documents
+func splitYAMLDocuments(text string) []string {
+	docs := []string{}
+	var sb strings.Builder
+	scanner := bufio.NewScanner(strings.NewReader(text))
+	scanner.Buffer(make([]byte, 64*1024), 10*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.TrimRight(line, " ") == "---" {
+			docs = append(docs, sb.String())
+			sb.Reset()
+			continue
+		}
+		sb.WriteString(line)
+		sb.WriteString("\n")
+	}
+	return append(docs, sb.String())
+}
+
+// ParseKubectlDiff returns the resources changed in the output of kubectl diff, which diffs a file per resource named
+// group.version.Kind.namespace.name
+func ParseKubectlDiff(output string) []*DriftedResource {
+	answer := []*DriftedResource{}
+	var current *DriftedResource
+	var sb strings.Builder
+	for _, line := range strings.Split(output, "\n") {
+		if strings.HasPrefix(line, kubectlDiffPrefix) {
+			if current != nil {
+				current.Diff = sb.String()
+				answer = append(answer, current)
+			}
+			sb.Reset()
+			fields := strings.Fields(strings.TrimPrefix(line, kubectlDiffPrefix))
+			current = &DriftedResource{}
+			if len(fields) > 0 {
+				current = parseKubectlDiffFileName(filepath.Base(fields[len(fields)-1]))
+			}
+		}
+		if current != nil {
+			sb.WriteString(line)
+			sb.WriteString("\n")
+		}
+	}
+	if current != nil {
+		current.Diff = sb.String()
+		answer = append(answer, current)
+	}
+	return answer
+}
+
+// parseKubectlDiffFileName parses the kind, namespace and name of a resource from a file name of kubectl diff. The
+// group may contain dots so the kind is the first part starting with an upper case letter
+func parseKubectlDiffFileName(name string) *DriftedResource {
+	parts := strings.Split(name, ".")
+	for i, part := range parts {
+		if part == "" || !unicode.IsUpper(rune(part[0])) || i+2 >= len(parts) {
+			continue
+		}
+		return &DriftedResource{
+			Kind:      part,
+			Namespace: parts[i+1],
+			Name:      strings.Join(parts[i+2:], "."),
+		}
+	}
+	return &DriftedResource{Name: name}
+}
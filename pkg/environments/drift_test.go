@@ -0,0 +1,79 @@
+// +build unit
+
+package environments_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jenkins-x/jx/v2/pkg/environments"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseKubectlDiff(t *testing.T) {
+	t.Parallel()
+
+	output := `diff -u -N /tmp/LIVE-123/apps.v1.Deployment.jx-staging.jx-myapp /tmp/MERGED-456/apps.v1.Deployment.jx-staging.jx-myapp
+--- /tmp/LIVE-123/apps.v1.Deployment.jx-staging.jx-myapp
++++ /tmp/MERGED-456/apps.v1.Deployment.jx-staging.jx-myapp
+@@ -10,7 +10,7 @@
+-  replicas: 5
++  replicas: 2
+diff -u -N /tmp/LIVE-123/networking.k8s.io.v1beta1.Ingress.jx-staging.myapp.example.com /tmp/MERGED-456/networking.k8s.io.v1beta1.Ingress.jx-staging.myapp.example.com
+--- /tmp/LIVE-123/networking.k8s.io.v1beta1.Ingress.jx-staging.myapp.example.com
++++ /tmp/MERGED-456/networking.k8s.io.v1beta1.Ingress.jx-staging.myapp.example.com
+@@ -1,3 +1,3 @@
+-  host: old.example.com
++  host: myapp.example.com
+`
+	resources := environments.ParseKubectlDiff(output)
+	require.Len(t, resources, 2)
+	assert.Equal(t, "Deployment jx-staging/jx-myapp", resources[0].String())
+	assert.Contains(t, resources[0].Diff, "+  replicas: 2")
+	assert.NotContains(t, resources[0].Diff, "host")
+	assert.Equal(t, "Ingress", resources[1].Kind)
+	assert.Equal(t, "jx-staging", resources[1].Namespace)
+	assert.Equal(t, "myapp.example.com", resources[1].Name)
+
+	assert.Empty(t, environments.ParseKubectlDiff(""))
+}
+
+func TestRemoveUnmanagedResources(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "test-remove-unmanaged-resources-")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir) //nolint:errcheck
+
+	writeManifest(t, dir, "secret.yaml", "apiVersion: v1\nkind: Secret\nmetadata:\n  name: creds\n")
+	writeManifest(t, dir, "app.yaml", `---
+apiVersion: v1
+kind: Service
+metadata:
+  name: myapp
+---
+apiVersion: v1
+kind: Pod
+metadata:
+  name: myapp-test
+  annotations:
+    helm.sh/hook: test-success
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: myapp
+`)
+
+	err = environments.RemoveUnmanagedResources(dir)
+	require.NoError(t, err)
+
+	templatesDir := filepath.Join(dir, "env", "templates")
+	assert.NoFileExists(t, filepath.Join(templatesDir, "secret.yaml"))
+	data, err := ioutil.ReadFile(filepath.Join(templatesDir, "app.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, "apiVersion: v1\nkind: Service\nmetadata:\n  name: myapp\n", string(data))
+}
@@ -25,7 +25,7 @@ import (
 	helmchart "k8s.io/helm/pkg/proto/hapi/chart"
 )
 
-//ValuesFiles is a wrapper for a slice of values files to allow them to be passed around as a pointer
+// ValuesFiles is a wrapper for a slice of values files to allow them to be passed around as a pointer
 type ValuesFiles struct {
 	Items []string
 }
@@ -43,6 +43,11 @@ type EnvironmentPullRequestOptions struct {
 	GitProvider   gits.GitProvider
 	ModifyChartFn ModifyChartFn
 	Labels        []string
+	// Provenance describes how the change was generated, if specified it is embedded in the pull request
+	Provenance *gits.Provenance
+	// Helmer if specified renders the environment chart before and after the change to comment the changes to the
+	// Kubernetes resources on the pull request
+	Helmer helm.Helmer
 }
 
 // Create a pull request against the environment repository for env.
@@ -71,6 +76,15 @@ func (o *EnvironmentPullRequestOptions) Create(env *jenkinsv1.Environment, prDir
 			prDir)
 	}
 
+	var manifestDiff *ManifestDiff
+	if o.Helmer != nil {
+		manifestDiff, err = NewManifestDiff(o.Helmer, dir, env.Spec.Namespace)
+		if err != nil {
+			log.Logger().Warnf("Not commenting the resource changes on the pull request: %s", err)
+		}
+		defer manifestDiff.Cleanup()
+	}
+
 	err = ModifyChartFiles(dir, pullRequestDetails, o.ModifyChartFn, chartName)
 	if err != nil {
 		return nil, err
@@ -82,10 +96,29 @@ func (o *EnvironmentPullRequestOptions) Create(env *jenkinsv1.Environment, prDir
 		labels = append(labels, gits.LabelUpdatebot)
 	}
 	pullRequestDetails.Labels = labels
-	prInfo, err := gits.PushRepoAndCreatePullRequest(dir, upstreamRepo, forkURL, base, pullRequestDetails, filter, true, pullRequestDetails.Message, true, false, o.Gitter, o.GitProvider)
+	creator := &gits.PullRequestCreator{
+		Dir:          dir,
+		UpstreamRepo: upstreamRepo,
+		ForkRepo:     forkURL,
+		Base:         base,
+		Details:      pullRequestDetails,
+		Filter:       filter,
+		Commit:       true,
+		Push:         true,
+		Provenance:   o.Provenance,
+		Gitter:       o.Gitter,
+		Provider:     o.GitProvider,
+	}
+	prInfo, err := creator.Create()
 	if err != nil {
 		return nil, err
 	}
+	if manifestDiff != nil && prInfo != nil && prInfo.PullRequest != nil {
+		err = manifestDiff.Comment(dir, o.GitProvider, prInfo.PullRequest)
+		if err != nil {
+			log.Logger().Warnf("Failed to comment the resource changes on the pull request: %s", err)
+		}
+	}
 	return prInfo, nil
 }
 
@@ -155,9 +188,14 @@ func ModifyChartFiles(dir string, details *gits.PullRequestDetails, modifyFn Mod
 // The passed inspectChartFunc will be called whilst the chart for each requirement is unpacked on the disk.
 // Operations are carried out using the helmer interface and there will be more logging if verbose is true.
 // The passed valuesFiles are used to add a values.yaml to each requirement.
+// The upgrade policy of each app in its AppMetadata is honoured: pinned apps are only upgraded when a version is
+// specified and, when upgrading all requirements, apps with the manual policy are skipped and appended to manualApps
+// so that they can be upgraded in pull requests of their own. If no version is specified the version returned by
+// streamVersionFn is used, falling back to the latest version if it is nil or returns an empty version.
 func CreateUpgradeRequirementsFn(all bool, chartName string, alias string, version string, username string,
 	password string, helmer helm.Helmer, inspectChartFunc func(chartDir string,
-		existingValues map[string]interface{}) error, verbose bool, valuesFiles *ValuesFiles) ModifyChartFn {
+		existingValues map[string]interface{}) error, verbose bool, valuesFiles *ValuesFiles,
+	streamVersionFn func(dependency *helm.Dependency) (string, error), manualApps *[]*helm.Dependency) ModifyChartFn {
 	upgraded := false
 	return func(requirements *helm.Requirements, metadata *chart.Metadata, values map[string]interface{},
 		templates map[string]string, envDir string, details *gits.PullRequestDetails) error {
@@ -175,47 +213,83 @@ func CreateUpgradeRequirementsFn(all bool, chartName string, alias string, versi
 					upgrade = true
 				}
 			}
-			if upgrade {
-				upgraded = true
-
-				oldVersion := d.Version
-				err := helm.InspectChart(d.Name, version, d.Repository, username, password, helmer,
-					func(chartDir string) error {
-						if all || version == "" {
-							// Upgrade to the latest version
-							_, chartVersion, err := helm.LoadChartNameAndVersion(filepath.Join(chartDir, "Chart.yaml"))
-							if err != nil {
-								return errors.Wrapf(err, "error loading chart from %s", chartDir)
-							}
-							version = chartVersion
-							if verbose {
-								log.Logger().Infof("No version specified so using latest version which is %s", util.ColorInfo(version))
-							}
-						}
+			if !upgrade {
+				continue
+			}
+
+			appMetadata, err := LoadAppMetadata(envDir, d.Name)
+			if err != nil {
+				return errors.Wrapf(err, "loading the metadata of app %s", d.Name)
+			}
+			switch appMetadata.Policy() {
+			case UpgradePolicyPinned:
+				if all || version == "" {
+					log.Logger().Infof("Not upgrading %s as its version is pinned to %s", util.ColorInfo(d.Name), d.Version)
+					continue
+				}
+			case UpgradePolicyManual:
+				if all {
+					if manualApps != nil {
+						*manualApps = append(*manualApps, d)
+					}
+					continue
+				}
+			}
 
-						err := inspectChartFunc(chartDir, values)
+			upgradeVersion := version
+			if all {
+				upgradeVersion = ""
+			}
+			if upgradeVersion == "" && streamVersionFn != nil {
+				upgradeVersion, err = streamVersionFn(d)
+				if err != nil {
+					return errors.Wrapf(err, "resolving the version stream version of %s", d.Name)
+				}
+				if upgradeVersion != "" && verbose {
+					log.Logger().Infof("Using version %s of %s from the version stream", util.ColorInfo(upgradeVersion), d.Name)
+				}
+			}
+			oldVersion := d.Version
+			err = helm.InspectChart(d.Name, upgradeVersion, d.Repository, username, password, helmer,
+				func(chartDir string) error {
+					if upgradeVersion == "" {
+						// Upgrade to the latest version
+						_, chartVersion, err := helm.LoadChartNameAndVersion(filepath.Join(chartDir, "Chart.yaml"))
 						if err != nil {
-							return errors.Wrapf(err, "running inspectChartFunc for %s", d.Name)
+							return errors.Wrapf(err, "error loading chart from %s", chartDir)
 						}
-						err = CreateNestedRequirementDir(envDir, chartName, chartDir, version, d.Repository, verbose,
-							valuesFiles, helmer)
-						if err != nil {
-							return errors.Wrapf(err, "creating nested app dir in chart dir %s", chartDir)
+						upgradeVersion = chartVersion
+						if verbose {
+							log.Logger().Infof("No version specified so using latest version which is %s", util.ColorInfo(upgradeVersion))
 						}
-						return nil
-					})
-				if err != nil {
-					return errors.Wrapf(err, "inspecting chart %s", d.Name)
-				}
+					}
 
-				// Do the upgrade
-				d.Version = version
-				if !all {
-					details.Title = fmt.Sprintf("Upgrade %s to %s", chartName, version)
-					details.Message = fmt.Sprintf("Upgrade %s from %s to %s", chartName, oldVersion, version)
-				} else {
-					details.Message = fmt.Sprintf("%s\n* %s from %s to %s", details.Message, d.Name, oldVersion, version)
-				}
+					err := inspectChartFunc(chartDir, values)
+					if err != nil {
+						return errors.Wrapf(err, "running inspectChartFunc for %s", d.Name)
+					}
+					err = CreateNestedRequirementDir(envDir, d.Name, chartDir, upgradeVersion, d.Repository, verbose,
+						valuesFiles, helmer)
+					if err != nil {
+						return errors.Wrapf(err, "creating nested app dir in chart dir %s", chartDir)
+					}
+					return nil
+				})
+			if err != nil {
+				return errors.Wrapf(err, "inspecting chart %s", d.Name)
+			}
+			if oldVersion == upgradeVersion {
+				continue
+			}
+
+			// Do the upgrade
+			upgraded = true
+			d.Version = upgradeVersion
+			if !all {
+				details.Title = fmt.Sprintf("Upgrade %s to %s", chartName, upgradeVersion)
+				details.Message = fmt.Sprintf("Upgrade %s from %s to %s", chartName, oldVersion, upgradeVersion)
+			} else {
+				details.Message = fmt.Sprintf("%s\n* %s from %s to %s", details.Message, d.Name, oldVersion, upgradeVersion)
 			}
 		}
 		if !upgraded {
@@ -267,6 +341,64 @@ func CreateAddRequirementFn(chartName string, alias string, version string, repo
 	}
 }
 
+// CreateRemoveRequirementFn creates the ModifyChartFn that removes the chartName with alias from the requirements of
+// the environment chart. The values of the chart in the values.yaml of the environment and the directory created for
+// the chart when it was added, containing its values, schemas, secrets and release.yaml, are removed too so that
+// nothing is left behind once the release is uninstalled.
+func CreateRemoveRequirementFn(chartName string, alias string) ModifyChartFn {
+	return func(requirements *helm.Requirements, chart *helmchart.Metadata, values map[string]interface{},
+		templates map[string]string, envDir string, details *gits.PullRequestDetails) error {
+		found := false
+		aliased := false
+		dependencies := make([]*helm.Dependency, 0)
+		for _, d := range requirements.Dependencies {
+			if d.Name == chartName && d.Alias == alias {
+				found = true
+				continue
+			}
+			if d.Name == chartName {
+				aliased = true
+			}
+			dependencies = append(dependencies, d)
+		}
+		if !found {
+			a := chartName
+			if alias != "" {
+				a = fmt.Sprintf("%s with alias %s", a, alias)
+			}
+			return fmt.Errorf("unable to delete app %s as not installed", a)
+		}
+		requirements.Dependencies = dependencies
+
+		key := alias
+		if key == "" {
+			key = chartName
+		}
+		if _, ok := values[key]; ok {
+			delete(values, key)
+			valuesFile := filepath.Join(envDir, helm.ValuesFileName)
+			err := helm.SaveFile(valuesFile, values)
+			if err != nil {
+				return errors.Wrapf(err, "removing the values of %s", key)
+			}
+		}
+
+		// the directory is shared by all the aliases of the chart
+		appDir := filepath.Join(envDir, chartName)
+		if info, err := os.Stat(appDir); err == nil && !aliased {
+			if info.IsDir() {
+				err = os.RemoveAll(appDir)
+				if err != nil {
+					return errors.Wrapf(err, "removing the files of %s", chartName)
+				}
+			} else {
+				log.Logger().Warnf("Not removing %s for %s because it is not a directory", appDir, chartName)
+			}
+		}
+		return nil
+	}
+}
+
 // CreateNestedRequirementDir creates the a directory for a chart being added as a requirement, adding a README.md,
 // the release.yaml, and the values.yaml. The dir is the unpacked chart directory to which the requirement is being
 // added. The requirementName, requirementVersion,
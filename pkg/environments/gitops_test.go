@@ -0,0 +1,93 @@
+// +build unit
+
+package environments_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jenkins-x/jx/v2/pkg/environments"
+	"github.com/jenkins-x/jx/v2/pkg/gits"
+	"github.com/jenkins-x/jx/v2/pkg/helm"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	helmchart "k8s.io/helm/pkg/proto/hapi/chart"
+)
+
+func TestCreateRemoveRequirementFn(t *testing.T) {
+	envDir, err := ioutil.TempDir("", "test-remove-requirement-")
+	require.NoError(t, err)
+	defer os.RemoveAll(envDir) //nolint:errcheck
+
+	appDir := filepath.Join(envDir, "jx-app-cheese")
+	require.NoError(t, os.MkdirAll(appDir, util.DefaultWritePermissions))
+	for _, name := range []string{helm.ValuesFileName, "values.schema.json", "release.yaml"} {
+		err = ioutil.WriteFile(filepath.Join(appDir, name), []byte("{}"), util.DefaultWritePermissions)
+		require.NoError(t, err)
+	}
+
+	requirements := &helm.Requirements{
+		Dependencies: []*helm.Dependency{
+			{Name: "jx-app-cheese", Version: "1.0.0"},
+			{Name: "jx-app-wine", Version: "2.0.0"},
+		},
+	}
+	values := map[string]interface{}{
+		"jx-app-cheese": map[string]interface{}{
+			"password": "vault:gitOps/jenkins-x/env/cheese:password",
+		},
+		"jx-app-wine": map[string]interface{}{
+			"enabled": true,
+		},
+	}
+
+	fn := environments.CreateRemoveRequirementFn("jx-app-cheese", "")
+	err = fn(requirements, &helmchart.Metadata{}, values, nil, envDir, &gits.PullRequestDetails{})
+	require.NoError(t, err)
+
+	require.Len(t, requirements.Dependencies, 1)
+	assert.Equal(t, "jx-app-wine", requirements.Dependencies[0].Name)
+	exists, err := util.DirExists(appDir)
+	require.NoError(t, err)
+	assert.False(t, exists, "the directory of the app should be removed")
+
+	savedValues, err := helm.LoadValuesFile(filepath.Join(envDir, helm.ValuesFileName))
+	require.NoError(t, err)
+	assert.NotContains(t, savedValues, "jx-app-cheese")
+	assert.Contains(t, savedValues, "jx-app-wine")
+
+	err = fn(requirements, &helmchart.Metadata{}, values, nil, envDir, &gits.PullRequestDetails{})
+	assert.Error(t, err, "should fail to delete an app which is not installed")
+}
+
+func TestCreateRemoveRequirementFnKeepsFilesOfOtherAliases(t *testing.T) {
+	envDir, err := ioutil.TempDir("", "test-remove-requirement-")
+	require.NoError(t, err)
+	defer os.RemoveAll(envDir) //nolint:errcheck
+
+	appDir := filepath.Join(envDir, "jx-app-cheese")
+	require.NoError(t, os.MkdirAll(appDir, util.DefaultWritePermissions))
+
+	requirements := &helm.Requirements{
+		Dependencies: []*helm.Dependency{
+			{Name: "jx-app-cheese", Alias: "cheddar", Version: "1.0.0"},
+			{Name: "jx-app-cheese", Alias: "brie", Version: "1.0.0"},
+		},
+	}
+	values := map[string]interface{}{
+		"cheddar": map[string]interface{}{},
+		"brie":    map[string]interface{}{},
+	}
+
+	fn := environments.CreateRemoveRequirementFn("jx-app-cheese", "cheddar")
+	err = fn(requirements, &helmchart.Metadata{}, values, nil, envDir, &gits.PullRequestDetails{})
+	require.NoError(t, err)
+
+	require.Len(t, requirements.Dependencies, 1)
+	assert.Equal(t, "brie", requirements.Dependencies[0].Alias)
+	assert.DirExists(t, appDir)
+	assert.NotContains(t, values, "cheddar")
+}
@@ -0,0 +1,109 @@
+package environments
+
+import (
+	"strings"
+
+	"github.com/jenkins-x/jx/v2/pkg/gits"
+	"github.com/jenkins-x/jx/v2/pkg/helm"
+	"github.com/pkg/errors"
+)
+
+const (
+	// RollbackTitlePrefix the prefix of the titles of the Pull Requests and commits which roll back an application in
+	// an environment
+	RollbackTitlePrefix = "revert: "
+
+	// envRequirementsPath the path of the requirements of the environment chart in the environment repository
+	envRequirementsPath = "env/" + helm.RequirementsFileName
+)
+
+// AppVersionChange is a commit of an environment repository which changed the version of an application
+type AppVersionChange struct {
+	// Version the version of the application after the commit, empty if the commit removed it
+	Version string
+	// SHA the SHA of the commit
+	SHA string
+	// Subject the subject of the commit message
+	Subject string
+}
+
+// IsRollback returns true if the commit rolled back the application
+func (c *AppVersionChange) IsRollback() bool {
+	return strings.HasPrefix(c.Subject, RollbackTitlePrefix)
+}
+
+// AppVersionHistory returns the changes of the version of the application in the environment repository cloned in
+// the directory, newest first, looking at the given number of the most recent commits of the environment requirements
+func AppVersionHistory(gitter gits.Gitter, dir string, app string, maxCommits int) ([]*AppVersionChange, error) {
+	commits, err := gitter.GetCommitsForFile(dir, envRequirementsPath, maxCommits)
+	if err != nil {
+		return nil, errors.Wrapf(err, "listing the commits of %s", envRequirementsPath)
+	}
+	answer := []*AppVersionChange{}
+	for _, commit := range commits {
+		sha := commit.SHA
+		data, err := gitter.LoadFileFromBranch(dir, sha, envRequirementsPath)
+		if err != nil {
+			// the commit deleted the requirements
+			data = ""
+		}
+		requirements, err := helm.LoadRequirements([]byte(data))
+		if err != nil {
+			return nil, errors.Wrapf(err, "parsing %s at commit %s", envRequirementsPath, sha)
+		}
+		change := &AppVersionChange{
+			Version: requirementsAppVersion(requirements, app),
+			SHA:     sha,
+			Subject: strings.SplitN(commit.Message, "\n", 2)[0],
+		}
+		// the versions are listed newest first so a commit which kept the version replaces the newer change
+		if len(answer) > 0 && answer[len(answer)-1].Version == change.Version {
+			answer[len(answer)-1] = change
+			continue
+		}
+		answer = append(answer, change)
+	}
+	return answer, nil
+}
+
+// requirementsAppVersion returns the version of the application in the requirements or an empty string
+func requirementsAppVersion(requirements *helm.Requirements, app string) string {
+	for _, dep := range requirements.Dependencies {
+		if dep != nil && (dep.Name == app || dep.Alias == app) {
+			return dep.Version
+		}
+	}
+	return ""
+}
+
+// PreviousAppVersion returns the change which deployed the version of the application before its current version in
+// the history, skipping the versions which were rolled back, or nil if there is no such version
+func PreviousAppVersion(history []*AppVersionChange) *AppVersionChange {
+	if len(history) == 0 {
+		return nil
+	}
+	current := history[0].Version
+	rolledBack := map[string]bool{}
+	for i, change := range history {
+		if change.IsRollback() && i+1 < len(history) {
+			rolledBack[history[i+1].Version] = true
+		}
+	}
+	for _, change := range history[1:] {
+		if change.Version != "" && change.Version != current && !rolledBack[change.Version] {
+			return change
+		}
+	}
+	return nil
+}
+
+// FindAppVersion returns the most recent change which deployed the version of the application or nil if the version
+// was never deployed
+func FindAppVersion(history []*AppVersionChange, version string) *AppVersionChange {
+	for _, change := range history {
+		if change.Version == version {
+			return change
+		}
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+// +build unit
+
+package environments_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/jenkins-x/jx/v2/pkg/environments"
+	"github.com/jenkins-x/jx/v2/pkg/gits"
+	gits_test "github.com/jenkins-x/jx/v2/pkg/gits/mocks"
+	"github.com/petergtz/pegomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPreviousAppVersion(t *testing.T) {
+	t.Parallel()
+
+	history := []*environments.AppVersionChange{
+		{Version: "1.0.3", SHA: "e", Subject: "chore: myapp to 1.0.3"},
+		{Version: "1.0.1", SHA: "d", Subject: "revert: myapp to 1.0.1"},
+		{Version: "1.0.2", SHA: "c", Subject: "chore: myapp to 1.0.2"},
+		{Version: "1.0.1", SHA: "b", Subject: "chore: myapp to 1.0.1"},
+		{Version: "", SHA: "a", Subject: "chore: initial environment"},
+	}
+	previous := environments.PreviousAppVersion(history)
+	require.NotNil(t, previous)
+	assert.Equal(t, "1.0.1", previous.Version)
+	assert.Equal(t, "d", previous.SHA)
+
+	// rolling back the rollback skips the version which was rolled back
+	history = history[1:]
+	previous = environments.PreviousAppVersion(history)
+	assert.Nil(t, previous, "1.0.2 was rolled back and the app was not deployed before 1.0.1")
+
+	assert.Nil(t, environments.PreviousAppVersion(nil))
+}
+
+func TestFindAppVersion(t *testing.T) {
+	t.Parallel()
+
+	history := []*environments.AppVersionChange{
+		{Version: "1.0.2", SHA: "c"},
+		{Version: "1.0.1", SHA: "b"},
+		{Version: "1.0.0", SHA: "a"},
+	}
+	change := environments.FindAppVersion(history, "1.0.1")
+	require.NotNil(t, change)
+	assert.Equal(t, "b", change.SHA)
+	assert.Nil(t, environments.FindAppVersion(history, "0.9.0"))
+}
+
+func TestAppVersionHistory(t *testing.T) {
+	pegomock.RegisterMockTestingT(t)
+
+	gitter := gits_test.NewMockGitter()
+	pegomock.When(gitter.GetCommitsForFile("source", "env/requirements.yaml", 10)).ThenReturn([]gits.GitCommit{
+		{SHA: "d", Message: "revert: myapp to 1.0.1\n\nthe release is broken"},
+		{SHA: "c", Message: "chore: other to 2.0.0"},
+		{SHA: "b", Message: "chore: myapp to 1.0.2"},
+		{SHA: "a", Message: "chore: initial environment"},
+	}, nil)
+	pegomock.When(gitter.LoadFileFromBranch("source", "d", "env/requirements.yaml")).ThenReturn(
+		"dependencies:\n- name: myapp\n  version: 1.0.1\n- name: other\n  version: 2.0.0\n", nil)
+	pegomock.When(gitter.LoadFileFromBranch("source", "c", "env/requirements.yaml")).ThenReturn(
+		"dependencies:\n- name: myapp\n  version: 1.0.2\n- name: other\n  version: 2.0.0\n", nil)
+	pegomock.When(gitter.LoadFileFromBranch("source", "b", "env/requirements.yaml")).ThenReturn(
+		"dependencies:\n- name: myapp\n  version: 1.0.2\n", nil)
+	pegomock.When(gitter.LoadFileFromBranch("source", "a", "env/requirements.yaml")).ThenReturn(
+		"", errors.New("the requirements do not exist"))
+
+	history, err := environments.AppVersionHistory(gitter, "source", "myapp", 10)
+	require.NoError(t, err)
+	assert.Equal(t, []*environments.AppVersionChange{
+		{Version: "1.0.1", SHA: "d", Subject: "revert: myapp to 1.0.1"},
+		{Version: "1.0.2", SHA: "b", Subject: "chore: myapp to 1.0.2"},
+		{Version: "", SHA: "a", Subject: "chore: initial environment"},
+	}, history, "the commits which kept the version should be skipped")
+}
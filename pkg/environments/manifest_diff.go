@@ -0,0 +1,208 @@
+package environments
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/jenkins-x/jx/v2/pkg/gits"
+	"github.com/jenkins-x/jx/v2/pkg/helm"
+	"github.com/jenkins-x/jx/v2/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+const (
+	// manifestDiffReleaseName the release name used to render the environment chart
+	manifestDiffReleaseName = "jx"
+	// maxManifestDiffCommentSize keeps the comment below the size git providers accept for a comment
+	maxManifestDiffCommentSize = 60000
+)
+
+// ManifestDiff renders the Kubernetes resources of an environment chart before and after a change so that the
+// difference can be commented on the pull request which makes the change. Reviewers can then see the concrete resource
+// changes rather than a change to the chart versions or values.
+type ManifestDiff struct {
+	Helmer    helm.Helmer
+	Namespace string
+
+	baseDir string
+}
+
+// NewManifestDiff renders the environment chart in dir before it is changed
+func NewManifestDiff(helmer helm.Helmer, dir string, ns string) (*ManifestDiff, error) {
+	d := &ManifestDiff{
+		Helmer:    helmer,
+		Namespace: ns,
+	}
+	baseDir, err := d.render(dir)
+	if err != nil {
+		if baseDir != "" {
+			os.RemoveAll(filepath.Dir(baseDir)) //nolint:errcheck
+		}
+		return nil, errors.Wrapf(err, "rendering the environment chart in %s", dir)
+	}
+	d.baseDir = baseDir
+	return d, nil
+}
+
+// Diff renders the changed environment chart in dir and returns the unified diff of the resources
+func (d *ManifestDiff) Diff(dir string) (string, error) {
+	headDir, err := d.render(dir)
+	if headDir != "" {
+		defer os.RemoveAll(filepath.Dir(headDir)) //nolint:errcheck
+	}
+	if err != nil {
+		return "", errors.Wrapf(err, "rendering the environment chart in %s", dir)
+	}
+	return DiffManifests(d.baseDir, headDir)
+}
+
+// Comment renders the changed environment chart in dir and comments the difference of the resources on the pull request
+func (d *ManifestDiff) Comment(dir string, provider gits.GitProvider, pr *gits.GitPullRequest) error {
+	diff, err := d.Diff(dir)
+	if err != nil {
+		return err
+	}
+	err = provider.AddPRComment(pr, ManifestDiffComment(diff))
+	if err != nil {
+		return errors.Wrapf(err, "commenting the resource changes on pull request %s", pr.URL)
+	}
+	return nil
+}
+
+// Cleanup removes the rendered resources
+func (d *ManifestDiff) Cleanup() {
+	if d != nil && d.baseDir != "" {
+		err := os.RemoveAll(filepath.Dir(d.baseDir))
+		if err != nil {
+			log.Logger().Warnf("failed to remove %s: %s", d.baseDir, err)
+		}
+	}
+}
+
+// render renders a copy of the environment chart in dir so that the dependencies downloaded to render it are not
+// committed, returning the directory containing the resources
+func (d *ManifestDiff) render(dir string) (string, error) {
+	chartFile, err := helm.FindChartFileName(dir)
+	if err != nil {
+		return "", err
+	}
+	tmpDir, err := ioutil.TempDir("", "jx-manifest-diff-")
+	if err != nil {
+		return "", errors.Wrap(err, "failed to create a temporary directory")
+	}
+	chartDir := filepath.Join(tmpDir, "chart")
+	outputDir := filepath.Join(tmpDir, "output")
+	err = util.CopyDir(filepath.Dir(chartFile), chartDir, true)
+	if err != nil {
+		return outputDir, errors.Wrapf(err, "failed to copy the chart in %s", dir)
+	}
+	d.Helmer.SetCWD(chartDir)
+	err = d.Helmer.BuildDependency()
+	if err != nil {
+		return outputDir, errors.Wrap(err, "building the chart dependencies")
+	}
+	err = d.Helmer.Template(chartDir, manifestDiffReleaseName, d.Namespace, outputDir, false, nil, nil, nil)
+	if err != nil {
+		return outputDir, errors.Wrap(err, "templating the chart")
+	}
+	return outputDir, nil
+}
+
+// DiffManifests returns the unified diff of the files in the base and head directories
+func DiffManifests(baseDir string, headDir string) (string, error) {
+	baseFiles, err := manifestFiles(baseDir)
+	if err != nil {
+		return "", err
+	}
+	headFiles, err := manifestFiles(headDir)
+	if err != nil {
+		return "", err
+	}
+	names := map[string]bool{}
+	for name := range baseFiles {
+		names[name] = true
+	}
+	for name := range headFiles {
+		names[name] = true
+	}
+	var sortedNames []string
+	for name := range names {
+		sortedNames = append(sortedNames, name)
+	}
+	sort.Strings(sortedNames)
+
+	var sb strings.Builder
+	for _, name := range sortedNames {
+		diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+			A:        splitLines(baseFiles[name]),
+			B:        splitLines(headFiles[name]),
+			FromFile: "a/" + name,
+			ToFile:   "b/" + name,
+			Context:  3,
+		})
+		if err != nil {
+			return "", errors.Wrapf(err, "failed to diff %s", name)
+		}
+		sb.WriteString(diff)
+	}
+	return sb.String(), nil
+}
+
+// ManifestDiffComment returns the pull request comment for the diff of the resources which is collapsed as it can be long
+func ManifestDiffComment(diff string) string {
+	diff = strings.TrimSpace(diff)
+	if diff == "" {
+		return "This change does not modify any Kubernetes resources."
+	}
+	if len(diff) > maxManifestDiffCommentSize {
+		diff = diff[:maxManifestDiffCommentSize] + "\n... (truncated)"
+	}
+	return fmt.Sprintf("<details>\n<summary>Kubernetes resource changes</summary>\n\n```diff\n%s\n```\n</details>", diff)
+}
+
+// manifestFiles loads the files in dir keyed by their path relative to dir
+func manifestFiles(dir string) (map[string]string, error) {
+	answer := map[string]string{}
+	exists, err := util.DirExists(dir)
+	if err != nil || !exists {
+		// nothing was rendered
+		return answer, err
+	}
+	err = filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return errors.Wrapf(err, "failed to read file %s", path)
+		}
+		name, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		answer[filepath.ToSlash(name)] = string(data)
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to load the resources in %s", dir)
+	}
+	return answer, nil
+}
+
+// splitLines splits the text into lines which each end with a new line
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	if !strings.HasSuffix(text, "\n") {
+		text += "\n"
+	}
+	lines := strings.SplitAfter(text, "\n")
+	return lines[:len(lines)-1]
+}
@@ -0,0 +1,61 @@
+// +build unit
+
+package environments_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jenkins-x/jx/v2/pkg/environments"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffManifests(t *testing.T) {
+	baseDir, err := ioutil.TempDir("", "test-diff-manifests-base-")
+	require.NoError(t, err)
+	defer os.RemoveAll(baseDir) //nolint:errcheck
+	headDir, err := ioutil.TempDir("", "test-diff-manifests-head-")
+	require.NoError(t, err)
+	defer os.RemoveAll(headDir) //nolint:errcheck
+
+	writeManifest(t, baseDir, "deployment.yaml", "kind: Deployment\nspec:\n  replicas: 1\n")
+	writeManifest(t, headDir, "deployment.yaml", "kind: Deployment\nspec:\n  replicas: 2\n")
+	writeManifest(t, baseDir, "configmap.yaml", "kind: ConfigMap\n")
+	writeManifest(t, headDir, "configmap.yaml", "kind: ConfigMap\n")
+	writeManifest(t, headDir, "service.yaml", "kind: Service\n")
+
+	diff, err := environments.DiffManifests(baseDir, headDir)
+	require.NoError(t, err)
+	assert.Equal(t, `--- a/env/templates/deployment.yaml
++++ b/env/templates/deployment.yaml
+@@ -1,3 +1,3 @@
+ kind: Deployment
+ spec:
+-  replicas: 1
++  replicas: 2
+--- a/env/templates/service.yaml
++++ b/env/templates/service.yaml
+@@ -0,0 +1 @@
++kind: Service
+`, diff)
+}
+
+func TestManifestDiffComment(t *testing.T) {
+	assert.Equal(t, "This change does not modify any Kubernetes resources.", environments.ManifestDiffComment(""))
+
+	comment := environments.ManifestDiffComment("-  replicas: 1\n+  replicas: 2\n")
+	assert.True(t, strings.HasPrefix(comment, "<details>\n<summary>Kubernetes resource changes</summary>"))
+	assert.Contains(t, comment, "```diff\n-  replicas: 1\n+  replicas: 2\n```")
+}
+
+func writeManifest(t *testing.T, dir string, name string, content string) {
+	templatesDir := filepath.Join(dir, "env", "templates")
+	err := os.MkdirAll(templatesDir, 0755)
+	require.NoError(t, err)
+	err = ioutil.WriteFile(filepath.Join(templatesDir, name), []byte(content), 0644)
+	require.NoError(t, err)
+}
@@ -0,0 +1,98 @@
+package environments
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+)
+
+// UpgradePolicy defines how 'jx upgrade apps' upgrades an app installed in an environment
+type UpgradePolicy string
+
+const (
+	// UpgradePolicyAuto the app is upgraded to the version in the version stream, or the latest version if it is not
+	// in the version stream, together with the other apps
+	UpgradePolicyAuto UpgradePolicy = "auto"
+	// UpgradePolicyManual the app is upgraded in its own pull request which is never merged automatically
+	UpgradePolicyManual UpgradePolicy = "manual"
+	// UpgradePolicyPinned the app is only upgraded when a version is specified explicitly
+	UpgradePolicyPinned UpgradePolicy = "pinned"
+
+	// AppMetadataFileName the name of the file in the directory of an app in the environment which contains the
+	// metadata of the app
+	AppMetadataFileName = "metadata.yaml"
+)
+
+// UpgradePolicyValues the valid upgrade policies
+var UpgradePolicyValues = []string{string(UpgradePolicyAuto), string(UpgradePolicyManual), string(UpgradePolicyPinned)}
+
+// ParseUpgradePolicy parses the upgrade policy, an empty text is the auto policy
+func ParseUpgradePolicy(text string) (UpgradePolicy, error) {
+	if text == "" {
+		return UpgradePolicyAuto, nil
+	}
+	policy := UpgradePolicy(strings.ToLower(text))
+	if util.StringArrayIndex(UpgradePolicyValues, string(policy)) < 0 {
+		return "", errors.Errorf("invalid upgrade policy %s, should be one of %s", text,
+			strings.Join(UpgradePolicyValues, ", "))
+	}
+	return policy, nil
+}
+
+// AppMetadata the metadata of an app stored next to its values in the environment
+type AppMetadata struct {
+	// UpgradePolicy how the app is upgraded, defaults to auto
+	UpgradePolicy UpgradePolicy `json:"upgradePolicy,omitempty"`
+}
+
+// Policy returns the upgrade policy of the app defaulting to auto
+func (m *AppMetadata) Policy() UpgradePolicy {
+	if m == nil || m.UpgradePolicy == "" {
+		return UpgradePolicyAuto
+	}
+	return m.UpgradePolicy
+}
+
+// LoadAppMetadata loads the metadata of the app in the environment in envDir, returning empty metadata if the app has
+// none
+func LoadAppMetadata(envDir string, app string) (*AppMetadata, error) {
+	metadata := &AppMetadata{}
+	fileName := filepath.Join(envDir, app, AppMetadataFileName)
+	exists, err := util.FileExists(fileName)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to check if %s exists", fileName)
+	}
+	if !exists {
+		return metadata, nil
+	}
+	data, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read %s", fileName)
+	}
+	err = yaml.Unmarshal(data, metadata)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to unmarshal %s", fileName)
+	}
+	if _, err := ParseUpgradePolicy(string(metadata.UpgradePolicy)); err != nil {
+		return nil, errors.Wrapf(err, "invalid metadata in %s", fileName)
+	}
+	return metadata, nil
+}
+
+// SaveAppMetadata saves the metadata of the app in the environment in envDir
+func SaveAppMetadata(envDir string, app string, metadata *AppMetadata) error {
+	fileName := filepath.Join(envDir, app, AppMetadataFileName)
+	data, err := yaml.Marshal(metadata)
+	if err != nil {
+		return errors.Wrapf(err, "failed to marshal the metadata of app %s", app)
+	}
+	err = ioutil.WriteFile(fileName, data, util.DefaultWritePermissions)
+	if err != nil {
+		return errors.Wrapf(err, "failed to write %s", fileName)
+	}
+	return nil
+}
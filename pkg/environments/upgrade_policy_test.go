@@ -0,0 +1,52 @@
+// +build unit
+
+package environments_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jenkins-x/jx/v2/pkg/environments"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAppMetadata(t *testing.T) {
+	envDir, err := ioutil.TempDir("", "test-app-metadata-")
+	require.NoError(t, err)
+	defer os.RemoveAll(envDir) //nolint:errcheck
+
+	metadata, err := environments.LoadAppMetadata(envDir, "jx-app-cheese")
+	require.NoError(t, err)
+	assert.Equal(t, environments.UpgradePolicyAuto, metadata.Policy(), "apps without metadata should be upgraded automatically")
+
+	require.NoError(t, os.MkdirAll(filepath.Join(envDir, "jx-app-cheese"), util.DefaultWritePermissions))
+	err = environments.SaveAppMetadata(envDir, "jx-app-cheese", &environments.AppMetadata{
+		UpgradePolicy: environments.UpgradePolicyPinned,
+	})
+	require.NoError(t, err)
+	metadata, err = environments.LoadAppMetadata(envDir, "jx-app-cheese")
+	require.NoError(t, err)
+	assert.Equal(t, environments.UpgradePolicyPinned, metadata.Policy())
+
+	fileName := filepath.Join(envDir, "jx-app-cheese", environments.AppMetadataFileName)
+	require.NoError(t, ioutil.WriteFile(fileName, []byte("upgradePolicy: sometimes\n"), util.DefaultWritePermissions))
+	_, err = environments.LoadAppMetadata(envDir, "jx-app-cheese")
+	assert.Error(t, err)
+}
+
+func TestParseUpgradePolicy(t *testing.T) {
+	policy, err := environments.ParseUpgradePolicy("")
+	require.NoError(t, err)
+	assert.Equal(t, environments.UpgradePolicyAuto, policy)
+
+	policy, err = environments.ParseUpgradePolicy("Manual")
+	require.NoError(t, err)
+	assert.Equal(t, environments.UpgradePolicyManual, policy)
+
+	_, err = environments.ParseUpgradePolicy("sometimes")
+	assert.Error(t, err)
+}
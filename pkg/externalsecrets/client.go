@@ -0,0 +1,93 @@
+package externalsecrets
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/jenkins-x/jx/v2/pkg/secreturl"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// URIScheme the scheme of the secret URLs which refer to secrets synced by the External Secrets Operator
+	URIScheme = "external-secrets"
+
+	// DefaultSyncTimeout how long to wait for the External Secrets Operator to sync a secret
+	DefaultSyncTimeout = 2 * time.Minute
+)
+
+var externalSecretsURIRegex = regexp.MustCompile(`:[\s"]*external-secrets:[-_.\w\/:]*`)
+
+// Client reads the secrets the External Secrets Operator syncs from a cloud secret manager into Kubernetes Secrets.
+// The secrets are owned by the cloud secret manager so they cannot be written
+type Client struct {
+	KubeClient  kubernetes.Interface
+	Namespace   string
+	SyncTimeout time.Duration
+}
+
+// NewClient creates a secret URL client which reads the secrets synced by the External Secrets Operator into the
+// given namespace
+func NewClient(kubeClient kubernetes.Interface, namespace string) secreturl.Client {
+	return &Client{
+		KubeClient:  kubeClient,
+		Namespace:   namespace,
+		SyncTimeout: DefaultSyncTimeout,
+	}
+}
+
+// Read reads the Kubernetes Secret synced for the secret path, waiting for the External Secrets Operator to create it
+func (c *Client) Read(secretName string) (map[string]interface{}, error) {
+	name := SecretName(secretName)
+	answer := map[string]interface{}{}
+	err := util.Retry(c.SyncTimeout, func() error {
+		secret, err := c.KubeClient.CoreV1().Secrets(c.Namespace).Get(name, metav1.GetOptions{})
+		if err != nil {
+			return errors.Wrapf(err, "getting Secret %s in namespace %s", name, c.Namespace)
+		}
+		for k, v := range secret.Data {
+			answer[k] = string(v)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "the External Secrets Operator has not synced the secret %s", secretName)
+	}
+	return answer, nil
+}
+
+// ReadObject reads a generic named object from the synced Kubernetes Secret
+func (c *Client) ReadObject(secretName string, secret interface{}) error {
+	m, err := c.Read(secretName)
+	if err != nil {
+		return err
+	}
+	err = util.ToStructFromMapStringInterface(m, &secret)
+	if err != nil {
+		return errors.Wrapf(err, "deserializing the secret %q", secretName)
+	}
+	return nil
+}
+
+// Write fails as the secrets have to be stored in the cloud secret manager
+func (c *Client) Write(secretName string, data map[string]interface{}) (map[string]interface{}, error) {
+	return nil, notWritableError(secretName)
+}
+
+// WriteObject fails as the secrets have to be stored in the cloud secret manager
+func (c *Client) WriteObject(secretName string, secret interface{}) (map[string]interface{}, error) {
+	return nil, notWritableError(secretName)
+}
+
+// ReplaceURIs will replace any external-secrets: URIs in a string
+func (c *Client) ReplaceURIs(s string) (string, error) {
+	return secreturl.ReplaceURIs(s, c, externalSecretsURIRegex, URIScheme+":")
+}
+
+func notWritableError(secretName string) error {
+	return fmt.Errorf("cannot write secret %s as secrets are synced by the External Secrets Operator, please store it in the cloud secret manager", secretName)
+}
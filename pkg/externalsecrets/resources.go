@@ -0,0 +1,284 @@
+package externalsecrets
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"github.com/jenkins-x/jx/v2/pkg/config"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+)
+
+const (
+	// APIVersion the API version of the ExternalSecret resources
+	APIVersion = "external-secrets.io/v1beta1"
+	// Kind the kind of the ExternalSecret resources
+	Kind = "ExternalSecret"
+
+	// DefaultSecretStoreKind the kind of secret store the ExternalSecret resources refer to by default
+	DefaultSecretStoreKind = "ClusterSecretStore"
+	// DefaultRefreshInterval how often the External Secrets Operator refreshes the secrets by default
+	DefaultRefreshInterval = "1h"
+
+	// ResourcesFileName the name of the file the ExternalSecret resources are written to
+	ResourcesFileName = "external-secrets.yaml"
+	// SchemaFileName the name of the file the JSON schema of the secrets in the cloud secret manager is written to
+	SchemaFileName = "secrets.schema.json"
+
+	secretNamePrefix = "jx-"
+)
+
+var (
+	referenceRegex     = regexp.MustCompile(`external-secrets:([-_.\w\/]+):([-_.\w]+)`)
+	invalidNameChars   = regexp.MustCompile(`[^-a-z0-9]+`)
+	invalidRemoteChars = regexp.MustCompile(`[^-_\w]+`)
+)
+
+// SecretReference is a reference to a key of a secret in the cloud secret manager, such as
+// external-secrets:jx/adminUser:password
+type SecretReference struct {
+	// Path the path of the secret, such as jx/adminUser
+	Path string
+	// Key the key of the value in the secret, such as password
+	Key string
+}
+
+// FindSecretReferences returns the references to secrets in the text
+func FindSecretReferences(text string) []SecretReference {
+	answer := []SecretReference{}
+	for _, match := range referenceRegex.FindAllStringSubmatch(text, -1) {
+		answer = append(answer, SecretReference{Path: match[1], Key: match[2]})
+	}
+	return answer
+}
+
+// FindSecretReferencesInDir returns the references to secrets in the YAML files of the helm charts in dir, such as
+// their parameters and values templates. Each reference is only returned once
+func FindSecretReferencesInDir(dir string) ([]SecretReference, error) {
+	answer := []SecretReference{}
+	found := map[SecretReference]bool{}
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if info.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		ext := filepath.Ext(path)
+		if ext != ".yaml" && ext != ".yml" {
+			return nil
+		}
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return errors.Wrapf(err, "reading file %s", path)
+		}
+		for _, ref := range FindSecretReferences(string(data)) {
+			if !found[ref] {
+				found[ref] = true
+				answer = append(answer, ref)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "finding the secret references in %s", dir)
+	}
+	sortReferences(answer)
+	return answer, nil
+}
+
+// SecretName returns the name of the Kubernetes Secret the External Secrets Operator syncs the secret path into
+func SecretName(path string) string {
+	name := invalidNameChars.ReplaceAllString(strings.ToLower(path), "-")
+	return secretNamePrefix + strings.Trim(name, "-")
+}
+
+// RemoteKey returns the key of the secret path in the cloud secret manager. Path separators are replaced as most
+// cloud secret managers do not allow them
+func RemoteKey(prefix string, path string) string {
+	key := path
+	if prefix != "" {
+		key = prefix + "/" + path
+	}
+	return strings.Trim(invalidRemoteChars.ReplaceAllString(key, "-"), "-")
+}
+
+// ExternalSecrets creates an ExternalSecret resource for each secret path referenced, which makes the External
+// Secrets Operator sync the referenced keys from the cloud secret manager into a Kubernetes Secret
+func ExternalSecrets(refs []SecretReference, namespace string, requirements *config.RequirementsConfig) ([]map[string]interface{}, error) {
+	cfg := settings(requirements)
+	if cfg.SecretStoreName == "" && len(refs) > 0 {
+		return nil, errors.New("no secret store specified in the externalSecrets.secretStoreName of the requirements")
+	}
+	keysByPath := map[string][]string{}
+	paths := []string{}
+	for _, ref := range refs {
+		if _, ok := keysByPath[ref.Path]; !ok {
+			paths = append(paths, ref.Path)
+		}
+		if util.StringArrayIndex(keysByPath[ref.Path], ref.Key) < 0 {
+			keysByPath[ref.Path] = append(keysByPath[ref.Path], ref.Key)
+		}
+	}
+	sort.Strings(paths)
+
+	answer := []map[string]interface{}{}
+	for _, path := range paths {
+		keys := keysByPath[path]
+		sort.Strings(keys)
+		data := []interface{}{}
+		for _, key := range keys {
+			data = append(data, map[string]interface{}{
+				"secretKey": key,
+				"remoteRef": map[string]interface{}{
+					"key":      RemoteKey(cfg.KeyPrefix, path),
+					"property": key,
+				},
+			})
+		}
+		name := SecretName(path)
+		answer = append(answer, map[string]interface{}{
+			"apiVersion": APIVersion,
+			"kind":       Kind,
+			"metadata": map[string]interface{}{
+				"name":      name,
+				"namespace": namespace,
+			},
+			"spec": map[string]interface{}{
+				"refreshInterval": cfg.RefreshInterval,
+				"secretStoreRef": map[string]interface{}{
+					"name": cfg.SecretStoreName,
+					"kind": cfg.SecretStoreKind,
+				},
+				"target": map[string]interface{}{
+					"name":           name,
+					"creationPolicy": "Owner",
+				},
+				"data": data,
+			},
+		})
+	}
+	return answer, nil
+}
+
+// Schema returns the JSON schema of the secrets which have to be stored in the cloud secret manager for the
+// references, keyed by their remote keys
+func Schema(refs []SecretReference, requirements *config.RequirementsConfig) map[string]interface{} {
+	cfg := settings(requirements)
+	properties := map[string]interface{}{}
+	required := []string{}
+	for _, ref := range refs {
+		remoteKey := RemoteKey(cfg.KeyPrefix, ref.Path)
+		secret, ok := properties[remoteKey].(map[string]interface{})
+		if !ok {
+			secret = map[string]interface{}{
+				"type":        "object",
+				"description": "the secret " + ref.Path,
+				"properties":  map[string]interface{}{},
+				"required":    []string{},
+			}
+			properties[remoteKey] = secret
+			required = append(required, remoteKey)
+		}
+		secret["properties"].(map[string]interface{})[ref.Key] = map[string]interface{}{
+			"type": "string",
+		}
+		keys := secret["required"].([]string)
+		if util.StringArrayIndex(keys, ref.Key) < 0 {
+			keys = append(keys, ref.Key)
+			sort.Strings(keys)
+			secret["required"] = keys
+		}
+	}
+	sort.Strings(required)
+	return map[string]interface{}{
+		"$schema":    "http://json-schema.org/draft-07/schema#",
+		"type":       "object",
+		"properties": properties,
+		"required":   required,
+	}
+}
+
+// WriteResources writes the ExternalSecret resources and the JSON schema of the secrets referenced by the helm charts
+// in dir to the output directory, returning the names of the files written
+func WriteResources(dir string, outDir string, namespace string, requirements *config.RequirementsConfig) ([]string, error) {
+	refs, err := FindSecretReferencesInDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	resources, err := ExternalSecrets(refs, namespace, requirements)
+	if err != nil {
+		return nil, err
+	}
+	err = os.MkdirAll(outDir, util.DefaultWritePermissions)
+	if err != nil {
+		return nil, errors.Wrapf(err, "creating directory %s", outDir)
+	}
+
+	data, err := ToYAML(resources)
+	if err != nil {
+		return nil, err
+	}
+	resourcesFile := filepath.Join(outDir, ResourcesFileName)
+	err = ioutil.WriteFile(resourcesFile, data, util.DefaultWritePermissions)
+	if err != nil {
+		return nil, errors.Wrapf(err, "saving file %s", resourcesFile)
+	}
+
+	data, err = json.MarshalIndent(Schema(refs, requirements), "", "  ")
+	if err != nil {
+		return nil, errors.Wrap(err, "marshalling the secrets schema to JSON")
+	}
+	schemaFile := filepath.Join(outDir, SchemaFileName)
+	err = ioutil.WriteFile(schemaFile, data, util.DefaultWritePermissions)
+	if err != nil {
+		return nil, errors.Wrapf(err, "saving file %s", schemaFile)
+	}
+	return []string{resourcesFile, schemaFile}, nil
+}
+
+// ToYAML returns the resources as a multi document YAML file
+func ToYAML(resources []map[string]interface{}) ([]byte, error) {
+	docs := []string{}
+	for _, resource := range resources {
+		data, err := yaml.Marshal(resource)
+		if err != nil {
+			return nil, errors.Wrap(err, "marshalling ExternalSecret to YAML")
+		}
+		docs = append(docs, string(data))
+	}
+	return []byte(strings.Join(docs, "---\n")), nil
+}
+
+// settings returns the external secrets configuration of the requirements with the defaults applied
+func settings(requirements *config.RequirementsConfig) config.ExternalSecretsConfig {
+	answer := config.ExternalSecretsConfig{}
+	if requirements != nil && requirements.ExternalSecrets != nil {
+		answer = *requirements.ExternalSecrets
+	}
+	if answer.SecretStoreKind == "" {
+		answer.SecretStoreKind = DefaultSecretStoreKind
+	}
+	if answer.RefreshInterval == "" {
+		answer.RefreshInterval = DefaultRefreshInterval
+	}
+	return answer
+}
+
+func sortReferences(refs []SecretReference) {
+	sort.Slice(refs, func(i, j int) bool {
+		if refs[i].Path != refs[j].Path {
+			return refs[i].Path < refs[j].Path
+		}
+		return refs[i].Key < refs[j].Key
+	})
+}
@@ -0,0 +1,95 @@
+// +build unit
+
+package externalsecrets_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jenkins-x/jx/v2/pkg/config"
+	"github.com/jenkins-x/jx/v2/pkg/externalsecrets"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+const parameters = `adminUser:
+  username: admin
+  password: external-secrets:mycluster/adminUser:password
+pipelineUser:
+  token: "external-secrets:mycluster/pipelineUser:token"
+  username: external-secrets:mycluster/pipelineUser:username
+`
+
+func TestExternalSecretsFromDir(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "external-secrets-")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	err = ioutil.WriteFile(filepath.Join(dir, "parameters.yaml"), []byte(parameters), 0600)
+	require.NoError(t, err)
+
+	refs, err := externalsecrets.FindSecretReferencesInDir(dir)
+	require.NoError(t, err)
+	assert.Equal(t, []externalsecrets.SecretReference{
+		{Path: "mycluster/adminUser", Key: "password"},
+		{Path: "mycluster/pipelineUser", Key: "token"},
+		{Path: "mycluster/pipelineUser", Key: "username"},
+	}, refs)
+
+	requirements := config.NewRequirementsConfig()
+	_, err = externalsecrets.ExternalSecrets(refs, "jx", requirements)
+	assert.Error(t, err, "should fail without a secret store")
+
+	requirements.ExternalSecrets = &config.ExternalSecretsConfig{SecretStoreName: "gcp"}
+	resources, err := externalsecrets.ExternalSecrets(refs, "jx", requirements)
+	require.NoError(t, err)
+	require.Len(t, resources, 2)
+
+	resource := resources[1]
+	assert.Equal(t, "jx-mycluster-pipelineuser", resource["metadata"].(map[string]interface{})["name"])
+	spec := resource["spec"].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{"name": "gcp", "kind": "ClusterSecretStore"}, spec["secretStoreRef"])
+	assert.Equal(t, "1h", spec["refreshInterval"])
+	data := spec["data"].([]interface{})
+	require.Len(t, data, 2)
+	assert.Equal(t, map[string]interface{}{
+		"secretKey": "token",
+		"remoteRef": map[string]interface{}{
+			"key":      "mycluster-pipelineUser",
+			"property": "token",
+		},
+	}, data[0])
+
+	schema := externalsecrets.Schema(refs, requirements)
+	assert.Equal(t, []string{"mycluster-adminUser", "mycluster-pipelineUser"}, schema["required"])
+	pipelineUser := schema["properties"].(map[string]interface{})["mycluster-pipelineUser"].(map[string]interface{})
+	assert.Equal(t, []string{"token", "username"}, pipelineUser["required"])
+}
+
+func TestClientReadsSyncedSecrets(t *testing.T) {
+	t.Parallel()
+
+	kubeClient := fake.NewSimpleClientset(&v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "jx-mycluster-adminuser",
+			Namespace: "jx",
+		},
+		Data: map[string][]byte{
+			"password": []byte("secret"),
+		},
+	})
+	client := externalsecrets.NewClient(kubeClient, "jx")
+
+	text, err := client.ReplaceURIs("password: external-secrets:mycluster/adminUser:password")
+	require.NoError(t, err)
+	assert.Equal(t, "password: secret", text)
+
+	_, err = client.Write("mycluster/adminUser", map[string]interface{}{"password": "changed"})
+	assert.Error(t, err, "secrets should not be writable")
+}
@@ -0,0 +1,218 @@
+package flagger
+
+import (
+	"fmt"
+
+	"github.com/jenkins-x/jx/v2/pkg/config"
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+const (
+	// ProviderIstio routes the traffic with the Istio service mesh
+	ProviderIstio = "istio"
+	// ProviderGloo routes the traffic with the Gloo ingress controller
+	ProviderGloo = "gloo"
+	// ProviderNginx routes the traffic with the NGINX ingress controller
+	ProviderNginx = "nginx"
+	// ProviderContour routes the traffic with the Contour ingress controller
+	ProviderContour = "contour"
+	// ProviderKubernetes switches the Kubernetes services without shifting traffic gradually, which only supports the
+	// blue-green strategy
+	ProviderKubernetes = "kubernetes"
+
+	// LabelIstioInjection the label of the namespaces whose pods Istio injects its sidecar into
+	LabelIstioInjection = "istio-injection"
+
+	// DefaultNamespace the namespace Flagger is installed in by default
+	DefaultNamespace = "istio-system"
+
+	// CanaryValuesKey the key of the values of the charts of applications which configures their Canary resource
+	CanaryValuesKey = "canary"
+
+	// PhaseSucceeded the phase of a Canary whose last rollout succeeded
+	PhaseSucceeded = "Succeeded"
+	// PhaseFailed the phase of a Canary whose last rollout failed and was rolled back
+	PhaseFailed = "Failed"
+	// PhaseProgressing the phase of a Canary whose rollout is being analysed
+	PhaseProgressing = "Progressing"
+
+	defaultInterval   = "1m"
+	defaultThreshold  = 5
+	defaultMaxWeight  = 50
+	defaultStepWeight = 10
+	defaultIterations = 10
+)
+
+var (
+	// ProviderValues the providers Flagger can route the traffic with
+	ProviderValues = []string{ProviderIstio, ProviderGloo, ProviderNginx, ProviderContour, ProviderKubernetes}
+
+	// CanaryResource the resource of the Flagger Canaries
+	CanaryResource = schema.GroupVersionResource{Group: "flagger.app", Version: "v1beta1", Resource: "canaries"}
+)
+
+// Provider returns the provider Flagger routes the traffic with
+func Provider(c *config.ProgressiveDeliveryConfig) string {
+	if c == nil || c.Provider == "" {
+		return ProviderIstio
+	}
+	return c.Provider
+}
+
+// Namespace returns the namespace Flagger is installed in
+func Namespace(c *config.ProgressiveDeliveryConfig) string {
+	if c == nil || c.Namespace == "" {
+		return DefaultNamespace
+	}
+	return c.Namespace
+}
+
+// Validate validates the progressive delivery requirements
+func Validate(c *config.ProgressiveDeliveryConfig) error {
+	if !c.IsEnabled() {
+		return nil
+	}
+	switch c.Strategy {
+	case "", config.ProgressiveDeliveryStrategyCanary, config.ProgressiveDeliveryStrategyBlueGreen:
+	default:
+		return fmt.Errorf("invalid progressive delivery strategy %s, it must be one of %v", c.Strategy, config.ProgressiveDeliveryStrategyValues)
+	}
+	provider := Provider(c)
+	valid := false
+	for _, p := range ProviderValues {
+		if p == provider {
+			valid = true
+		}
+	}
+	if !valid {
+		return fmt.Errorf("invalid progressive delivery provider %s, it must be one of %v", provider, ProviderValues)
+	}
+	if provider == ProviderKubernetes && c.Strategy != config.ProgressiveDeliveryStrategyBlueGreen {
+		return fmt.Errorf("the progressive delivery provider %s only supports the %s strategy", provider, config.ProgressiveDeliveryStrategyBlueGreen)
+	}
+	if c.MaxWeight < 0 || c.MaxWeight > 100 || c.StepWeight < 0 || c.StepWeight > 100 {
+		return fmt.Errorf("the progressive delivery maxWeight and stepWeight must be percentages")
+	}
+	return nil
+}
+
+// CanaryValues returns the values of the chart of an application which make it generate the Canary resource for the
+// progressive delivery requirements
+func CanaryValues(c *config.ProgressiveDeliveryConfig) map[string]interface{} {
+	interval := c.Interval
+	if interval == "" {
+		interval = defaultInterval
+	}
+	threshold := c.Threshold
+	if threshold == 0 {
+		threshold = defaultThreshold
+	}
+	analysis := map[string]interface{}{
+		"interval":  interval,
+		"threshold": threshold,
+	}
+	if c.Strategy == config.ProgressiveDeliveryStrategyBlueGreen {
+		iterations := c.Iterations
+		if iterations == 0 {
+			iterations = defaultIterations
+		}
+		analysis["iterations"] = iterations
+	} else {
+		maxWeight := c.MaxWeight
+		if maxWeight == 0 {
+			maxWeight = defaultMaxWeight
+		}
+		stepWeight := c.StepWeight
+		if stepWeight == 0 {
+			stepWeight = defaultStepWeight
+		}
+		analysis["maxWeight"] = maxWeight
+		analysis["stepWeight"] = stepWeight
+	}
+	return map[string]interface{}{
+		"enabled":        true,
+		"provider":       Provider(c),
+		"canaryAnalysis": analysis,
+	}
+}
+
+// SetCanaryValues sets the canary values of the application in the values of an environment chart, keeping any other
+// canary values of the application such as its metrics or hosts
+func SetCanaryValues(values map[string]interface{}, app string, c *config.ProgressiveDeliveryConfig) {
+	appValues, ok := values[app].(map[string]interface{})
+	if !ok {
+		appValues = map[string]interface{}{}
+		values[app] = appValues
+	}
+	canary, ok := appValues[CanaryValuesKey].(map[string]interface{})
+	if !ok {
+		canary = map[string]interface{}{}
+		appValues[CanaryValuesKey] = canary
+	}
+	for k, v := range CanaryValues(c) {
+		existing, ok := canary[k].(map[string]interface{})
+		generated, isMap := v.(map[string]interface{})
+		if ok && isMap {
+			for gk, gv := range generated {
+				existing[gk] = gv
+			}
+			continue
+		}
+		canary[k] = v
+	}
+}
+
+// CanaryStatus the status of the analysis of the rollout of an application
+type CanaryStatus struct {
+	Phase        string
+	CanaryWeight int64
+	FailedChecks int64
+	Iterations   int64
+}
+
+// String returns a summary of the status
+func (s *CanaryStatus) String() string {
+	switch s.Phase {
+	case PhaseProgressing:
+		if s.CanaryWeight == 0 && s.Iterations > 0 {
+			return fmt.Sprintf("%s iteration %d", s.Phase, s.Iterations)
+		}
+		return fmt.Sprintf("%s %d%%", s.Phase, s.CanaryWeight)
+	case PhaseFailed:
+		return fmt.Sprintf("%s (%d failed checks)", s.Phase, s.FailedChecks)
+	default:
+		return s.Phase
+	}
+}
+
+// GetCanaryStatuses returns the statuses of the Canaries in the namespace indexed by the name of the deployment they
+// roll out
+func GetCanaryStatuses(client dynamic.Interface, ns string) (map[string]*CanaryStatus, error) {
+	answer := map[string]*CanaryStatus{}
+	list, err := client.Resource(CanaryResource).Namespace(ns).List(metav1.ListOptions{})
+	if err != nil {
+		if meta.IsNoMatchError(err) || apierrors.IsNotFound(err) {
+			return answer, nil
+		}
+		return answer, errors.Wrapf(err, "listing the Canaries in namespace %s", ns)
+	}
+	for _, item := range list.Items {
+		target, _, _ := unstructured.NestedString(item.Object, "spec", "targetRef", "name")
+		if target == "" {
+			target = item.GetName()
+		}
+		status := &CanaryStatus{}
+		status.Phase, _, _ = unstructured.NestedString(item.Object, "status", "phase")
+		status.CanaryWeight, _, _ = unstructured.NestedInt64(item.Object, "status", "canaryWeight")
+		status.FailedChecks, _, _ = unstructured.NestedInt64(item.Object, "status", "failedChecks")
+		status.Iterations, _, _ = unstructured.NestedInt64(item.Object, "status", "iterations")
+		answer[target] = status
+	}
+	return answer, nil
+}
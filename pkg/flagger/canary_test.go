@@ -0,0 +1,130 @@
+// +build unit
+
+package flagger_test
+
+import (
+	"testing"
+
+	"github.com/jenkins-x/jx/v2/pkg/config"
+	"github.com/jenkins-x/jx/v2/pkg/flagger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+)
+
+func TestValidate(t *testing.T) {
+	t.Parallel()
+
+	assert.NoError(t, flagger.Validate(nil))
+	assert.NoError(t, flagger.Validate(&config.ProgressiveDeliveryConfig{Enabled: true}))
+	assert.NoError(t, flagger.Validate(&config.ProgressiveDeliveryConfig{
+		Enabled:  true,
+		Provider: flagger.ProviderKubernetes,
+		Strategy: config.ProgressiveDeliveryStrategyBlueGreen,
+	}))
+	assert.Error(t, flagger.Validate(&config.ProgressiveDeliveryConfig{Enabled: true, Strategy: "big-bang"}))
+	assert.Error(t, flagger.Validate(&config.ProgressiveDeliveryConfig{Enabled: true, Provider: "traefik"}))
+	assert.Error(t, flagger.Validate(&config.ProgressiveDeliveryConfig{Enabled: true, Provider: flagger.ProviderKubernetes}))
+	assert.Error(t, flagger.Validate(&config.ProgressiveDeliveryConfig{Enabled: true, MaxWeight: 150}))
+}
+
+func TestIsEnabledForEnvironment(t *testing.T) {
+	t.Parallel()
+
+	var disabled *config.ProgressiveDeliveryConfig
+	assert.False(t, disabled.IsEnabledForEnvironment("production"))
+
+	c := &config.ProgressiveDeliveryConfig{Enabled: true}
+	assert.True(t, c.IsEnabledForEnvironment("production"))
+	assert.False(t, c.IsEnabledForEnvironment("staging"))
+
+	c.Environments = []string{"staging"}
+	assert.True(t, c.IsEnabledForEnvironment("staging"))
+	assert.False(t, c.IsEnabledForEnvironment("production"))
+}
+
+func TestSetCanaryValues(t *testing.T) {
+	t.Parallel()
+
+	values := map[string]interface{}{
+		"myapp": map[string]interface{}{
+			"replicaCount": 2,
+			"canary": map[string]interface{}{
+				"host": "myapp.example.com",
+				"canaryAnalysis": map[string]interface{}{
+					"interval": "5m",
+					"metrics":  []interface{}{"request-success-rate"},
+				},
+			},
+		},
+	}
+	flagger.SetCanaryValues(values, "myapp", &config.ProgressiveDeliveryConfig{Enabled: true, Interval: "2m", StepWeight: 20})
+
+	expected := map[string]interface{}{
+		"myapp": map[string]interface{}{
+			"replicaCount": 2,
+			"canary": map[string]interface{}{
+				"enabled":  true,
+				"provider": flagger.ProviderIstio,
+				"host":     "myapp.example.com",
+				"canaryAnalysis": map[string]interface{}{
+					"interval":   "2m",
+					"threshold":  5,
+					"maxWeight":  50,
+					"stepWeight": 20,
+					"metrics":    []interface{}{"request-success-rate"},
+				},
+			},
+		},
+	}
+	assert.Equal(t, expected, values)
+
+	values = map[string]interface{}{}
+	flagger.SetCanaryValues(values, "other", &config.ProgressiveDeliveryConfig{
+		Enabled:  true,
+		Provider: flagger.ProviderKubernetes,
+		Strategy: config.ProgressiveDeliveryStrategyBlueGreen,
+	})
+	analysis := values["other"].(map[string]interface{})["canary"].(map[string]interface{})["canaryAnalysis"].(map[string]interface{})
+	assert.Equal(t, 10, analysis["iterations"])
+	assert.NotContains(t, analysis, "maxWeight")
+}
+
+func TestGetCanaryStatuses(t *testing.T) {
+	t.Parallel()
+
+	canary := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "flagger.app/v1beta1",
+		"kind":       "Canary",
+		"metadata": map[string]interface{}{
+			"name":      "myapp",
+			"namespace": "jx-production",
+		},
+		"spec": map[string]interface{}{
+			"targetRef": map[string]interface{}{
+				"name": "jx-myapp",
+			},
+		},
+		"status": map[string]interface{}{
+			"phase":        "Progressing",
+			"canaryWeight": int64(30),
+			"failedChecks": int64(1),
+		},
+	}}
+	client := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme())
+	_, err := client.Resource(flagger.CanaryResource).Namespace("jx-production").Create(canary, metav1.CreateOptions{})
+	require.NoError(t, err)
+
+	statuses, err := flagger.GetCanaryStatuses(client, "jx-production")
+	require.NoError(t, err)
+	require.Contains(t, statuses, "jx-myapp")
+	status := statuses["jx-myapp"]
+	assert.Equal(t, "Progressing 30%", status.String())
+	assert.Equal(t, int64(1), status.FailedChecks)
+
+	status = &flagger.CanaryStatus{Phase: flagger.PhaseFailed, FailedChecks: 5}
+	assert.Equal(t, "Failed (5 failed checks)", status.String())
+}
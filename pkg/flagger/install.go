@@ -0,0 +1,53 @@
+package flagger
+
+import (
+	"github.com/jenkins-x/jx/v2/pkg/config"
+	"github.com/jenkins-x/jx/v2/pkg/kube"
+)
+
+// Chart a helm chart installed for progressive delivery
+type Chart struct {
+	Name        string
+	Repository  string
+	ReleaseName string
+	Namespace   string
+	SetValues   []string
+	// Deployment the deployment in the namespace whose existence means the chart is already installed
+	Deployment string
+}
+
+// ProviderCharts returns the charts of the service mesh or ingress controller of the provider in the order they are
+// installed. The NGINX ingress controller is installed by boot already and the kubernetes provider needs nothing
+func ProviderCharts(provider string) []Chart {
+	switch provider {
+	case ProviderIstio:
+		istioRepo := "https://istio-release.storage.googleapis.com/charts"
+		return []Chart{
+			{Name: "base", Repository: istioRepo, ReleaseName: "istio-base", Namespace: DefaultNamespace, Deployment: "istiod"},
+			{Name: "istiod", Repository: istioRepo, ReleaseName: "istiod", Namespace: DefaultNamespace, Deployment: "istiod"},
+			{Name: "gateway", Repository: istioRepo, ReleaseName: "istio-ingressgateway", Namespace: DefaultNamespace, Deployment: "istio-ingressgateway"},
+		}
+	case ProviderGloo:
+		return []Chart{
+			{Name: "gloo", Repository: "https://storage.googleapis.com/solo-public-helm", ReleaseName: "gloo", Namespace: "gloo-system", Deployment: "gloo"},
+		}
+	case ProviderContour:
+		return []Chart{
+			{Name: "contour", Repository: "https://charts.bitnami.com/bitnami", ReleaseName: "contour", Namespace: "projectcontour", Deployment: "contour-contour"},
+		}
+	default:
+		return nil
+	}
+}
+
+// FlaggerChart returns the chart of Flagger configured for the progressive delivery requirements
+func FlaggerChart(c *config.ProgressiveDeliveryConfig) Chart {
+	return Chart{
+		Name:        "flagger",
+		Repository:  "https://flagger.app",
+		ReleaseName: kube.DefaultFlaggerReleaseName,
+		Namespace:   Namespace(c),
+		SetValues:   []string{"meshProvider=" + Provider(c), "prometheus.install=true"},
+		Deployment:  kube.DefaultFlaggerReleaseName,
+	}
+}
@@ -0,0 +1,154 @@
+package generators
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/jenkins-x/jx/v2/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+)
+
+// CommandGenerator creates projects by running the command line tool of a framework such as 'dotnet new'. The
+// arguments of the command can refer to the name of the project as $name and to the values of the options by their
+// names, such as $group
+type CommandGenerator struct {
+	// GeneratorName the name of the generator
+	GeneratorName string
+	// GeneratorDescription describes the projects the generator creates
+	GeneratorDescription string
+	// Command the command line tool creating the projects
+	Command string
+	// Args the arguments of the command
+	Args []string
+	// Options the default values of the options the arguments can refer to
+	Options map[string]string
+	// Markers the file patterns of the projects the generator creates
+	Markers []string
+	// MarkerText if not empty the text one of the marker files must contain
+	MarkerText string
+	// Defaults the defaults of the pipelines of the projects
+	Defaults PipelineDefaults
+}
+
+func init() {
+	Register(&CommandGenerator{
+		GeneratorName:        "dotnet",
+		GeneratorDescription: ".NET web API",
+		Command:              "dotnet",
+		Args:                 []string{"new", "$template", "--name", "$name", "--output", "$name"},
+		Options:              map[string]string{"template": "webapi"},
+		Markers:              []string{"*.csproj"},
+		Defaults:             PipelineDefaults{BuildPack: "csharp"},
+	})
+	Register(&CommandGenerator{
+		GeneratorName:        "nextjs",
+		GeneratorDescription: "Next.js application",
+		Command:              "npx",
+		Args:                 []string{"create-next-app@$version", "$name", "--use-npm"},
+		Options:              map[string]string{"version": "latest"},
+		Markers:              []string{"package.json"},
+		MarkerText:           `"next"`,
+		Defaults:             PipelineDefaults{BuildPack: "javascript"},
+	})
+	Register(&CommandGenerator{
+		GeneratorName:        "quarkus",
+		GeneratorDescription: "Quarkus microservice",
+		Command:              "mvn",
+		Args:                 []string{"-B", "io.quarkus:quarkus-maven-plugin:$version:create", "-DprojectGroupId=$group", "-DprojectArtifactId=$name"},
+		Options:              map[string]string{"group": "com.example", "version": "1.3.2.Final"},
+		Markers:              []string{"pom.xml"},
+		MarkerText:           "io.quarkus",
+		Defaults:             PipelineDefaults{BuildPack: "maven"},
+	})
+}
+
+// Name the name of the generator
+func (g *CommandGenerator) Name() string {
+	return g.GeneratorName
+}
+
+// Description describes the projects the generator creates
+func (g *CommandGenerator) Description() string {
+	return g.GeneratorDescription
+}
+
+// Detect returns true if the directory contains one of the marker files with the marker text
+func (g *CommandGenerator) Detect(dir string) (bool, error) {
+	for _, marker := range g.Markers {
+		files, err := filepath.Glob(filepath.Join(dir, marker))
+		if err != nil {
+			return false, err
+		}
+		for _, f := range files {
+			if g.MarkerText == "" {
+				return true, nil
+			}
+			data, err := ioutil.ReadFile(f)
+			if err != nil {
+				return false, errors.Wrapf(err, "reading %s", f)
+			}
+			if strings.Contains(string(data), g.MarkerText) {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+// Generate runs the command in the directory of the arguments
+func (g *CommandGenerator) Generate(args *GenerateArguments) (string, error) {
+	if args.Name == "" {
+		return "", util.MissingOption("name")
+	}
+	values := map[string]string{}
+	for k, v := range g.Options {
+		values[k] = v
+	}
+	for k, v := range args.Values {
+		if _, ok := g.Options[k]; !ok {
+			return "", util.InvalidOption("generator-option", k, util.SortedMapKeys(g.Options))
+		}
+		values[k] = v
+	}
+	values["name"] = args.Name
+
+	cmdArgs := []string{}
+	for _, arg := range g.Args {
+		cmdArgs = append(cmdArgs, os.Expand(arg, func(name string) string {
+			return values[name]
+		}))
+	}
+	cmd := util.Command{
+		Dir:  args.Dir,
+		Name: g.Command,
+		Args: cmdArgs,
+		In:   args.IOFileHandles.In,
+		Out:  args.IOFileHandles.Out,
+		Err:  args.IOFileHandles.Err,
+	}
+	log.Logger().Infof("Running %s", util.ColorInfo(cmd.String()))
+	_, err := cmd.RunWithoutRetry()
+	if err != nil {
+		return "", errors.Wrapf(err, "running %s", cmd.String())
+	}
+
+	outDir := filepath.Join(args.Dir, args.Name)
+	found, err := g.Detect(outDir)
+	if err != nil {
+		return "", err
+	}
+	if !found {
+		return "", fmt.Errorf("%s did not create a %s in %s", g.Command, g.GeneratorDescription, outDir)
+	}
+	log.Logger().Infof("Created %s project at %s", g.GeneratorDescription, util.ColorInfo(outDir))
+	return outDir, nil
+}
+
+// PipelineDefaults the defaults of the pipelines of the projects the generator creates
+func (g *CommandGenerator) PipelineDefaults() PipelineDefaults {
+	return g.Defaults
+}
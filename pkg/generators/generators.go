@@ -0,0 +1,108 @@
+package generators
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+)
+
+// Generator creates the source code of new projects such as Spring Boot, .NET or Next.js applications
+type Generator interface {
+	// Name the name of the generator used by 'jx create project --generator'
+	Name() string
+
+	// Description describes the projects the generator creates
+	Description() string
+
+	// Detect returns true if the directory contains a project of the kind the generator creates
+	Detect(dir string) (bool, error)
+
+	// Generate creates a new project in a subdirectory of the directory of the arguments returning the directory of the
+	// new project
+	Generate(args *GenerateArguments) (string, error)
+
+	// PipelineDefaults the defaults of the pipelines of the projects the generator creates
+	PipelineDefaults() PipelineDefaults
+}
+
+// GenerateArguments the arguments of the generation of a project
+type GenerateArguments struct {
+	// Dir the directory the project is created in
+	Dir string
+	// Name the name of the project which is the name of the directory it is created in
+	Name string
+	// Values the values of the options of the generator keyed by their names
+	Values map[string]string
+	// BatchMode disables asking for the values which are not specified
+	BatchMode bool
+	// IOFileHandles the input and output of the questions asked
+	IOFileHandles util.IOFileHandles
+}
+
+// PipelineDefaults the defaults of the pipelines of the projects a generator creates
+type PipelineDefaults struct {
+	// BuildPack the build pack used to import the projects. The build pack is detected if it is empty
+	BuildPack string
+	// DeployKind the kind of deployment of the projects if it is not the default of the team
+	DeployKind string
+}
+
+var (
+	lock       sync.RWMutex
+	generators = map[string]Generator{}
+)
+
+// Register registers the generator so that it can be used by 'jx create project', replacing any generator of the
+// same name
+func Register(generator Generator) {
+	lock.Lock()
+	defer lock.Unlock()
+	generators[generator.Name()] = generator
+}
+
+// Get returns the generator of the given name
+func Get(name string) (Generator, error) {
+	lock.RLock()
+	defer lock.RUnlock()
+	generator := generators[name]
+	if generator == nil {
+		return nil, util.InvalidArg(name, names())
+	}
+	return generator, nil
+}
+
+// Names returns the sorted names of the registered generators
+func Names() []string {
+	lock.RLock()
+	defer lock.RUnlock()
+	return names()
+}
+
+func names() []string {
+	answer := []string{}
+	for name := range generators {
+		answer = append(answer, name)
+	}
+	sort.Strings(answer)
+	return answer
+}
+
+// Detect returns the generator of the project in the directory or nil if no registered generator detects it
+func Detect(dir string) (Generator, error) {
+	for _, name := range Names() {
+		generator, err := Get(name)
+		if err != nil {
+			return nil, err
+		}
+		found, err := generator.Detect(dir)
+		if err != nil {
+			return nil, errors.Wrapf(err, "detecting %s projects in %s", name, dir)
+		}
+		if found {
+			return generator, nil
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,77 @@
+// +build unit
+
+package generators_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jenkins-x/jx/v2/pkg/generators"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuiltInGenerators(t *testing.T) {
+	t.Parallel()
+	names := generators.Names()
+	for _, name := range []string{"dotnet", "nextjs", "quarkus", generators.SpringGeneratorName} {
+		assert.Contains(t, names, name)
+	}
+	_, err := generators.Get("does-not-exist")
+	assert.Error(t, err)
+
+	dir, err := ioutil.TempDir("", "generators")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "pom.xml"), []byte("<groupId>io.quarkus</groupId>\n"), util.DefaultWritePermissions))
+
+	generator, err := generators.Detect(dir)
+	require.NoError(t, err)
+	require.NotNil(t, generator)
+	assert.Equal(t, "quarkus", generator.Name())
+	assert.Equal(t, "maven", generator.PipelineDefaults().BuildPack)
+}
+
+func TestCommandGenerator(t *testing.T) {
+	t.Parallel()
+	dir, err := ioutil.TempDir("", "generators")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	generator := &generators.CommandGenerator{
+		GeneratorName:        "test",
+		GeneratorDescription: "test application",
+		Command:              "sh",
+		Args:                 []string{"-c", "mkdir $name && echo '$framework' > $name/app.txt"},
+		Options:              map[string]string{"framework": "default"},
+		Markers:              []string{"*.txt"},
+		MarkerText:           "custom",
+	}
+
+	_, err = generator.Generate(&generators.GenerateArguments{
+		Dir:    dir,
+		Name:   "myapp",
+		Values: map[string]string{"unknown": "value"},
+	})
+	require.Error(t, err, "an unknown option should be rejected")
+
+	_, err = generator.Generate(&generators.GenerateArguments{
+		Dir:  dir,
+		Name: "other",
+	})
+	require.Error(t, err, "the project should not be detected without the marker text")
+
+	outDir, err := generator.Generate(&generators.GenerateArguments{
+		Dir:    dir,
+		Name:   "myapp",
+		Values: map[string]string{"framework": "custom"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "myapp"), outDir)
+	data, err := ioutil.ReadFile(filepath.Join(outDir, "app.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "custom\n", string(data))
+}
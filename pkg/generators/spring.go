@@ -0,0 +1,130 @@
+package generators
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/jenkins-x/jx/v2/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/spring"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+)
+
+// SpringGeneratorName the name of the Spring Boot generator
+const SpringGeneratorName = "spring"
+
+// SpringGenerator creates Spring Boot applications using start.spring.io
+type SpringGenerator struct {
+	// Form the values of the Spring Boot project which are asked for unless they are specified
+	Form spring.SpringBootForm
+	// Advanced asks for the advanced values such as the packaging and the project type
+	Advanced bool
+}
+
+func init() {
+	Register(&SpringGenerator{
+		Form: spring.SpringBootForm{
+			DependencyKinds: spring.DefaultDependencyKinds,
+		},
+	})
+}
+
+// Name the name of the generator
+func (g *SpringGenerator) Name() string {
+	return SpringGeneratorName
+}
+
+// Description describes the projects the generator creates
+func (g *SpringGenerator) Description() string {
+	return "Spring Boot microservice"
+}
+
+// Detect returns true if the directory contains a Maven or Gradle build of a Spring Boot application
+func (g *SpringGenerator) Detect(dir string) (bool, error) {
+	for _, name := range []string{"pom.xml", "build.gradle", "build.gradle.kts"} {
+		fileName := filepath.Join(dir, name)
+		exists, err := util.FileExists(fileName)
+		if err != nil {
+			return false, err
+		}
+		if !exists {
+			continue
+		}
+		data, err := ioutil.ReadFile(fileName)
+		if err != nil {
+			return false, errors.Wrapf(err, "reading %s", fileName)
+		}
+		if strings.Contains(string(data), "org.springframework.boot") {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Generate creates the Spring Boot application. The values of the arguments are keyed by the names of the options of
+// 'jx create spring' such as 'group', 'artifact' or 'dep' with a comma separated list of dependencies
+func (g *SpringGenerator) Generate(args *GenerateArguments) (string, error) {
+	data := g.Form
+	data.Dependencies = append([]string{}, g.Form.Dependencies...)
+	for name, value := range args.Values {
+		switch name {
+		case spring.OptionGroupId:
+			data.GroupId = value
+		case spring.OptionArtifactId:
+			data.ArtifactId = value
+		case spring.OptionLanguage:
+			data.Language = value
+		case spring.OptionJavaVersion:
+			data.JavaVersion = value
+		case spring.OptionBootVersion:
+			data.BootVersion = value
+		case spring.OptionPackaging:
+			data.Packaging = value
+		case spring.OptionType:
+			data.Type = value
+		case spring.OptionDependency:
+			data.Dependencies = append(data.Dependencies, strings.Split(value, ",")...)
+		default:
+			return "", util.InvalidOption("generator-option", name, []string{spring.OptionGroupId, spring.OptionArtifactId, spring.OptionLanguage,
+				spring.OptionJavaVersion, spring.OptionBootVersion, spring.OptionPackaging, spring.OptionType, spring.OptionDependency})
+		}
+	}
+	if data.ArtifactId == "" {
+		data.ArtifactId = args.Name
+	}
+
+	cacheDir, err := util.CacheDir()
+	if err != nil {
+		return "", err
+	}
+	model, err := spring.LoadSpringBoot(cacheDir)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to load the Spring Boot model")
+	}
+	err = model.CreateSurvey(&data, g.Advanced, args.BatchMode)
+	if err != nil {
+		return "", err
+	}
+
+	// always add in actuator as its required for health checking
+	if !util.Contains(data.Dependencies, "actuator") {
+		data.Dependencies = append(data.Dependencies, "actuator")
+	}
+	// always add web as the JVM tends to terminate if its not added
+	if !util.Contains(data.Dependencies, "web") {
+		data.Dependencies = append(data.Dependencies, "web")
+	}
+
+	outDir, err := data.CreateProject(args.Dir)
+	if err != nil {
+		return "", err
+	}
+	log.Logger().Infof("Created Spring Boot project at %s", util.ColorInfo(outDir))
+	return outDir, nil
+}
+
+// PipelineDefaults the Maven or Gradle build pack is detected from the project type
+func (g *SpringGenerator) PipelineDefaults() PipelineDefaults {
+	return PipelineDefaults{}
+}
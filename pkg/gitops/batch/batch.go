@@ -0,0 +1,278 @@
+package batch
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/jenkins-x/jx/v2/pkg/errorutil"
+	"github.com/jenkins-x/jx/v2/pkg/gits"
+	"github.com/jenkins-x/jx/v2/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+)
+
+// DefaultRetryDelay how long to wait before retrying a repository which could not be changed
+const DefaultRetryDelay = 5 * time.Second
+
+// ChangeFn modifies the local clone of a repository in dir, returning the pull request to create for the change or nil
+// if the repository does not need changing. gitInfo is the repository the changes are pushed to, which is the fork if
+// the repository had to be forked
+type ChangeFn func(dir string, gitInfo *gits.GitRepository) (*Change, error)
+
+// ProviderFn creates the git provider for a repository
+type ProviderFn func(gitURL string) (gits.GitProvider, error)
+
+// Change describes the pull request for the modification of a repository
+type Change struct {
+	// Details the branch, title, message and labels of the pull request
+	Details *gits.PullRequestDetails
+	// CommitMessage the message used to commit any changes the ChangeFn did not commit itself, defaults to the
+	// commit message of the details
+	CommitMessage string
+}
+
+// Result is the outcome of changing a repository
+type Result struct {
+	// GitURL the repository
+	GitURL string
+	// PullRequestInfo the created or updated pull request, nil if the repository did not need changing or this is a
+	// dry run
+	PullRequestInfo *gits.PullRequestInfo
+	// Error why the repository could not be changed
+	Error error
+}
+
+// Batch applies a change to a number of repositories, creating a pull request on each of them or updating the
+// existing pull request for an earlier change
+type Batch struct {
+	// GitURLs the repositories to change. Each repository is only changed once even if it is listed more than once
+	GitURLs []string
+	// Base the branch the pull requests are merged into, defaults to master
+	Base string
+	// BranchName the branch the repositories are cloned into
+	BranchName string
+	// Labels are added to the pull requests and find the open pull request of an earlier change to update
+	Labels []string
+	// UpdateIfExists updates an open pull request from the same branch if no Labels are specified
+	UpdateIfExists bool
+	// SkipCommit leaves any changes the ChangeFn did not commit itself out of the pull requests
+	SkipCommit bool
+	// DryRun commits the changes without pushing them or creating pull requests
+	DryRun bool
+	// Draft creates the pull requests as drafts, if supported by the git provider
+	Draft bool
+	// AutoMerge makes the pull requests merge once their checks pass
+	AutoMerge bool
+	// MergeQueue adds the pull requests to the merge queue of the git provider
+	MergeQueue bool
+	// Reviewers the users asked to review the pull requests
+	Reviewers []string
+	// TeamReviewers the teams asked to review the pull requests
+	TeamReviewers []string
+	// Assignees the users the pull requests are assigned to
+	Assignees []string
+	// Retries how many times a repository which could not be changed is tried again from a fresh clone
+	Retries int
+	// RetryDelay how long to wait before retrying, defaults to DefaultRetryDelay
+	RetryDelay time.Duration
+	// Provenance describes how the changes were generated. It is embedded in the pull requests and commit messages
+	Provenance *gits.Provenance
+
+	Gitter         gits.Gitter
+	CreateProvider ProviderFn
+}
+
+// Apply changes each of the repositories and creates or updates their pull requests. All the repositories are tried
+// even if some of them fail, the failures are returned as a combined error as well as in the results
+func (b *Batch) Apply(change ChangeFn) ([]*Result, error) {
+	if b.CreateProvider == nil {
+		return nil, errors.New("no git provider factory specified")
+	}
+	results := []*Result{}
+	errs := []error{}
+	for _, gitURL := range UniqueGitURLs(b.GitURLs) {
+		result := &Result{GitURL: gitURL}
+		err := b.retry(gitURL, func() error {
+			var err error
+			result.PullRequestInfo, err = b.applyToRepository(gitURL, change)
+			return err
+		})
+		if err != nil {
+			result.Error = err
+			errs = append(errs, errors.Wrapf(err, "changing %s", gitURL))
+		}
+		results = append(results, result)
+	}
+	return results, errorutil.CombineErrors(errs...)
+}
+
+// CreatePullRequest commits any changes in the local clone in dir and creates, or updates, the pull request for them.
+// If startSha is specified no pull request is created unless commits were made since it
+func (b *Batch) CreatePullRequest(dir string, startSha string, base string, upstreamRepo *gits.GitRepository, forkRepo *gits.GitRepository, provider gits.GitProvider, change *Change) (*gits.PullRequestInfo, error) {
+	var answer *gits.PullRequestInfo
+	err := b.retry(upstreamRepo.URL, func() error {
+		var err error
+		answer, err = b.createPullRequest(dir, startSha, base, upstreamRepo, forkRepo, provider, change)
+		return err
+	})
+	return answer, err
+}
+
+func (b *Batch) applyToRepository(gitURL string, change ChangeFn) (*gits.PullRequestInfo, error) {
+	provider, err := b.CreateProvider(gitURL)
+	if err != nil {
+		return nil, errors.Wrapf(err, "creating git provider for %s", gitURL)
+	}
+	dir, err := ioutil.TempDir("", "batch-change")
+	if err != nil {
+		return nil, err
+	}
+	if !b.DryRun {
+		// dry runs leave the commits behind for the user to look at
+		defer os.RemoveAll(dir) //nolint:errcheck
+	}
+	dir, base, upstreamRepo, forkRepo, err := gits.ForkAndPullRepo(gitURL, dir, b.Base, b.BranchName, provider, b.Gitter, "")
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to fork and pull %s", gitURL)
+	}
+	startSha, err := b.Gitter.GetLatestCommitSha(dir)
+	if err != nil {
+		return nil, errors.Wrapf(err, "getting the latest commit of %s", dir)
+	}
+	gitInfo := upstreamRepo
+	if forkRepo != nil {
+		gitInfo = forkRepo
+	}
+	c, err := change(dir, gitInfo)
+	if err != nil {
+		return nil, err
+	}
+	if c == nil || c.Details == nil {
+		log.Logger().Infof("No changes needed for %s", util.ColorInfo(gitURL))
+		return nil, nil
+	}
+	return b.createPullRequest(dir, startSha, base, upstreamRepo, forkRepo, provider, c)
+}
+
+func (b *Batch) createPullRequest(dir string, startSha string, base string, upstreamRepo *gits.GitRepository, forkRepo *gits.GitRepository, provider gits.GitProvider, change *Change) (*gits.PullRequestInfo, error) {
+	details := *change.Details
+	if !b.SkipCommit {
+		err := b.commit(dir, change)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if startSha != "" {
+		sha, err := b.Gitter.GetLatestCommitSha(dir)
+		if err != nil {
+			return nil, errors.Wrapf(err, "getting the latest commit of %s", dir)
+		}
+		if sha == startSha {
+			log.Logger().Warnf("No changes made to the source code in %s. Code must be up to date!", dir)
+			return nil, nil
+		}
+	}
+
+	labels := append([]string{}, details.Labels...)
+	for _, label := range b.Labels {
+		if util.StringArrayIndex(labels, label) < 0 {
+			labels = append(labels, label)
+		}
+	}
+	details.Labels = labels
+	var filter *gits.PullRequestFilter
+	if len(b.Labels) > 0 {
+		filter = &gits.PullRequestFilter{
+			Labels: b.Labels,
+		}
+	}
+	if base == "" {
+		base = b.Base
+	}
+	creator := &gits.PullRequestCreator{
+		Dir:            dir,
+		UpstreamRepo:   upstreamRepo,
+		ForkRepo:       forkRepo,
+		Base:           base,
+		Details:        &details,
+		Filter:         filter,
+		UpdateIfExists: b.UpdateIfExists,
+		Push:           true,
+		DryRun:         b.DryRun,
+		Draft:          b.Draft,
+		AutoMerge:      b.AutoMerge,
+		MergeQueue:     b.MergeQueue,
+		Reviewers:      b.Reviewers,
+		TeamReviewers:  b.TeamReviewers,
+		Assignees:      b.Assignees,
+		Provenance:     b.Provenance,
+		Gitter:         b.Gitter,
+		Provider:       provider,
+	}
+	return creator.Create()
+}
+
+// commit commits any changes the ChangeFn did not commit itself
+func (b *Batch) commit(dir string, change *Change) error {
+	err := b.Gitter.Add(dir, "-A")
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	changed, err := b.Gitter.HasChanges(dir)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if !changed {
+		return nil
+	}
+	message := change.CommitMessage
+	if message == "" {
+		message = change.Details.CommitMessage
+	}
+	if message == "" {
+		message = change.Details.Message
+	}
+	err = b.Gitter.CommitDir(dir, b.Provenance.AddTrailers(message))
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
+// retry calls fn until it succeeds or the retries are used up
+func (b *Batch) retry(name string, fn func() error) error {
+	delay := b.RetryDelay
+	if delay <= 0 {
+		delay = DefaultRetryDelay
+	}
+	for attempt := 0; ; attempt++ {
+		err := fn()
+		if err == nil || attempt >= b.Retries {
+			return err
+		}
+		log.Logger().Warnf("failed to change %s, retrying in %s: %s", name, delay.String(), err)
+		time.Sleep(delay)
+	}
+}
+
+// UniqueGitURLs returns the git URLs without any which refer to the same repository as an earlier one, such as the
+// same repository with and without a .git suffix
+func UniqueGitURLs(gitURLs []string) []string {
+	answer := []string{}
+	found := map[string]bool{}
+	for _, gitURL := range gitURLs {
+		key := strings.ToLower(gitURL)
+		gitInfo, err := gits.ParseGitURL(gitURL)
+		if err == nil {
+			key = strings.ToLower(gitInfo.Host + "/" + gitInfo.Organisation + "/" + gitInfo.Name)
+		}
+		if gitURL == "" || found[key] {
+			continue
+		}
+		found[key] = true
+		answer = append(answer, gitURL)
+	}
+	return answer
+}
@@ -0,0 +1,60 @@
+// +build unit
+
+package batch_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jenkins-x/jx/v2/pkg/gitops/batch"
+	"github.com/jenkins-x/jx/v2/pkg/gits"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUniqueGitURLs(t *testing.T) {
+	t.Parallel()
+
+	gitURLs := batch.UniqueGitURLs([]string{
+		"https://github.com/jx/foo.git",
+		"https://github.com/jx/foo",
+		"https://github.com/JX/Foo.git",
+		"",
+		"https://github.com/jx/bar.git",
+		"https://gitlab.com/jx/foo.git",
+	})
+	assert.Equal(t, []string{
+		"https://github.com/jx/foo.git",
+		"https://github.com/jx/bar.git",
+		"https://gitlab.com/jx/foo.git",
+	}, gitURLs)
+}
+
+func TestApplyRetriesAndContinuesPastFailures(t *testing.T) {
+	t.Parallel()
+
+	attempts := map[string]int{}
+	b := &batch.Batch{
+		GitURLs:    []string{"https://github.com/jx/foo.git", "https://github.com/jx/foo", "https://github.com/jx/bar.git"},
+		Retries:    2,
+		RetryDelay: time.Millisecond,
+		CreateProvider: func(gitURL string) (gits.GitProvider, error) {
+			attempts[gitURL]++
+			return nil, errors.New("no credentials")
+		},
+	}
+	results, err := b.Apply(func(dir string, gitInfo *gits.GitRepository) (*batch.Change, error) {
+		t.Fatal("the change should not be applied without a git provider")
+		return nil, nil
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "https://github.com/jx/bar.git")
+
+	require.Len(t, results, 2, "the duplicate repository should only be changed once")
+	for _, result := range results {
+		assert.Error(t, result.Error)
+		assert.Nil(t, result.PullRequestInfo)
+		assert.Equal(t, 3, attempts[result.GitURL], "%s should be tried once and retried twice", result.GitURL)
+	}
+}
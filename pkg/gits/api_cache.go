@@ -0,0 +1,328 @@
+package gits
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jenkins-x/jx/v2/pkg/log"
+)
+
+const (
+	// APICacheEnvVar the environment variable which disables caching git provider API responses if set to false
+	APICacheEnvVar = "JX_GIT_API_CACHE"
+
+	// APICacheTTLEnvVar the environment variable to override how long cached git provider API responses are used
+	// without asking the server if they have changed, e.g. 30s. A value of 0 always revalidates them
+	APICacheTTLEnvVar = "JX_GIT_API_CACHE_TTL"
+
+	// APICacheDirEnvVar the environment variable of a directory to also cache git provider API responses on disk so
+	// they can be revalidated by later jx processes, such as the steps of a pipeline
+	APICacheDirEnvVar = "JX_GIT_API_CACHE_DIR"
+
+	// CacheHeader the header added to responses which were served from the cache
+	CacheHeader = "X-From-Cache"
+
+	defaultAPICacheTTL        = 10 * time.Second
+	defaultAPICacheMaxEntries = 1000
+)
+
+// authHeaders the request headers which identify the user so that responses are never shared between users
+var authHeaders = []string{"Authorization", "Private-Token", "Accept"}
+
+// readOnlyRequestKey the context key which marks requests as not changing anything on the server
+type readOnlyRequestKey struct{}
+
+var (
+	defaultAPICache     *APICache
+	defaultAPICacheOnce sync.Once
+)
+
+// APICache caches the responses of git provider API calls which have an ETag or Last-Modified header
+type APICache struct {
+	// TTL how long a response is used without revalidating it
+	TTL time.Duration
+	// Dir if specified responses are also stored in this directory
+	Dir string
+	// MaxEntries the maximum number of responses kept in memory
+	MaxEntries int
+
+	lock    sync.Mutex
+	entries map[string]*cachedResponse
+}
+
+// cachedResponse a response stored in the cache
+type cachedResponse struct {
+	StatusCode int         `json:"statusCode"`
+	Header     http.Header `json:"header"`
+	Body       []byte      `json:"body"`
+	Stored     time.Time   `json:"stored"`
+}
+
+// NewAPICache creates an in memory cache with the given TTL
+func NewAPICache(ttl time.Duration) *APICache {
+	return &APICache{
+		TTL:        ttl,
+		MaxEntries: defaultAPICacheMaxEntries,
+		entries:    map[string]*cachedResponse{},
+	}
+}
+
+// DefaultAPICache returns the cache shared by all the git providers of this process, configured from the
+// environment, or nil if caching is disabled
+func DefaultAPICache() *APICache {
+	if os.Getenv(APICacheEnvVar) == "false" {
+		return nil
+	}
+	defaultAPICacheOnce.Do(func() {
+		ttl := defaultAPICacheTTL
+		if value := os.Getenv(APICacheTTLEnvVar); value != "" {
+			d, err := time.ParseDuration(value)
+			if err != nil {
+				log.Logger().Warnf("ignoring invalid duration %s in $%s", value, APICacheTTLEnvVar)
+			} else {
+				ttl = d
+			}
+		}
+		defaultAPICache = NewAPICache(ttl)
+		defaultAPICache.Dir = os.Getenv(APICacheDirEnvVar)
+	})
+	return defaultAPICache
+}
+
+// CacheTransport is a http.RoundTripper which serves recent GET responses from the cache and revalidates older ones
+// with conditional requests, which do not count against the rate limits of most git providers
+type CacheTransport struct {
+	Base  http.RoundTripper
+	Cache *APICache
+}
+
+// NewCacheTransport wraps the given transport with the default cache. The transport is returned as is if caching
+// is disabled
+func NewCacheTransport(base http.RoundTripper) http.RoundTripper {
+	cache := DefaultAPICache()
+	if cache == nil {
+		return base
+	}
+	return &CacheTransport{
+		Base:  base,
+		Cache: cache,
+	}
+}
+
+// NewAPITransport returns the transport used for git provider API calls which caches responses and retries
+// transient failures
+func NewAPITransport(base http.RoundTripper) http.RoundTripper {
+	return NewCacheTransport(NewRetryTransport(base))
+}
+
+// NewAPIClient returns the http client used for git provider API calls which caches responses and retries
+// transient failures
+func NewAPIClient(timeout time.Duration) *http.Client {
+	return &http.Client{
+		Transport: NewAPITransport(nil),
+		Timeout:   timeout,
+	}
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *CacheTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !isCacheableRequest(req) {
+		resp, err := t.Base.RoundTrip(req)
+		if err == nil && !isReadOnlyRequest(req) && resp.StatusCode < 400 {
+			// the change may affect any cached listing so lets revalidate everything
+			t.Cache.Expire()
+		}
+		return resp, err
+	}
+	key := cacheKey(req)
+	entry := t.Cache.get(key)
+	if entry != nil && time.Since(entry.Stored) < t.Cache.TTL {
+		return entry.response(req), nil
+	}
+	if entry != nil {
+		req = req.Clone(req.Context())
+		if etag := entry.Header.Get("ETag"); etag != "" {
+			req.Header.Set("If-None-Match", etag)
+		}
+		if lastModified := entry.Header.Get("Last-Modified"); lastModified != "" {
+			req.Header.Set("If-Modified-Since", lastModified)
+		}
+	}
+	resp, err := t.Base.RoundTrip(req)
+	if err != nil {
+		return resp, err
+	}
+	if resp.StatusCode == http.StatusNotModified && entry != nil {
+		resp.Body.Close() //nolint:errcheck
+		revalidated := *entry
+		revalidated.Stored = time.Now()
+		t.Cache.put(key, &revalidated)
+		return revalidated.response(req), nil
+	}
+	if resp.StatusCode != http.StatusOK || !isCacheableResponse(resp) {
+		return resp, nil
+	}
+	body, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close() //nolint:errcheck
+	if err != nil {
+		return nil, err
+	}
+	t.Cache.put(key, &cachedResponse{
+		StatusCode: resp.StatusCode,
+		Header:     resp.Header,
+		Body:       body,
+		Stored:     time.Now(),
+	})
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+	return resp, nil
+}
+
+// Expire makes all the cached responses be revalidated before they are used again
+func (c *APICache) Expire() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	for key, entry := range c.entries {
+		// entries are replaced rather than modified as they are read without the lock
+		expired := *entry
+		expired.Stored = time.Time{}
+		c.entries[key] = &expired
+	}
+}
+
+func (c *APICache) get(key string) *cachedResponse {
+	c.lock.Lock()
+	entry := c.entries[key]
+	c.lock.Unlock()
+	if entry != nil || c.Dir == "" {
+		return entry
+	}
+	data, err := ioutil.ReadFile(filepath.Join(c.Dir, key+".json"))
+	if err != nil {
+		return nil
+	}
+	entry = &cachedResponse{}
+	err = json.Unmarshal(data, entry)
+	if err != nil {
+		return nil
+	}
+	// responses from other processes are always revalidated
+	entry.Stored = time.Time{}
+	return entry
+}
+
+func (c *APICache) put(key string, entry *cachedResponse) {
+	c.lock.Lock()
+	if c.entries == nil {
+		c.entries = map[string]*cachedResponse{}
+	}
+	if _, ok := c.entries[key]; !ok && c.MaxEntries > 0 && len(c.entries) >= c.MaxEntries {
+		c.evictOldest()
+	}
+	c.entries[key] = entry
+	c.lock.Unlock()
+
+	if c.Dir == "" {
+		return
+	}
+	data, err := json.Marshal(entry)
+	if err == nil {
+		err = os.MkdirAll(c.Dir, 0700)
+	}
+	if err == nil {
+		err = ioutil.WriteFile(filepath.Join(c.Dir, key+".json"), data, 0600)
+	}
+	if err != nil {
+		log.Logger().Debugf("failed to write to the git API cache %s: %s", c.Dir, err)
+	}
+}
+
+func (c *APICache) evictOldest() {
+	oldestKey := ""
+	var oldest time.Time
+	for key, entry := range c.entries {
+		if oldestKey == "" || entry.Stored.Before(oldest) {
+			oldestKey = key
+			oldest = entry.Stored
+		}
+	}
+	delete(c.entries, oldestKey)
+}
+
+func (e *cachedResponse) response(req *http.Request) *http.Response {
+	header := http.Header{}
+	for k, v := range e.Header {
+		header[k] = append([]string(nil), v...)
+	}
+	header.Set(CacheHeader, "1")
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", e.StatusCode, http.StatusText(e.StatusCode)),
+		StatusCode:    e.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          ioutil.NopCloser(bytes.NewReader(e.Body)),
+		ContentLength: int64(len(e.Body)),
+		Request:       req,
+	}
+}
+
+// WithReadOnlyRequest marks the requests made with the returned context as not changing anything on the server even
+// though they are not GET requests, such as GraphQL queries, so they neither expire the cache nor prevent retries
+func WithReadOnlyRequest(ctx context.Context) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, readOnlyRequestKey{}, true)
+}
+
+// isReadOnlyRequest returns true if the request does not change anything on the server
+func isReadOnlyRequest(req *http.Request) bool {
+	if req.Method == http.MethodGet || req.Method == http.MethodHead {
+		return true
+	}
+	readOnly, _ := req.Context().Value(readOnlyRequestKey{}).(bool)
+	return readOnly
+}
+
+// isCacheableRequest returns true for GET requests which the caller has not made conditional or partial itself
+func isCacheableRequest(req *http.Request) bool {
+	if req.Method != http.MethodGet {
+		return false
+	}
+	for _, name := range []string{"If-None-Match", "If-Modified-Since", "Range"} {
+		if req.Header.Get(name) != "" {
+			return false
+		}
+	}
+	return !strings.Contains(req.Header.Get("Cache-Control"), "no-cache")
+}
+
+// isCacheableResponse returns true if the response can be revalidated
+func isCacheableResponse(resp *http.Response) bool {
+	if strings.Contains(resp.Header.Get("Cache-Control"), "no-store") {
+		return false
+	}
+	return resp.Header.Get("ETag") != "" || resp.Header.Get("Last-Modified") != ""
+}
+
+// cacheKey returns the hash of the URL and the headers which identify the user
+func cacheKey(req *http.Request) string {
+	h := sha256.New()
+	h.Write([]byte(req.URL.String())) //nolint:errcheck
+	for _, name := range authHeaders {
+		h.Write([]byte("\n" + name + ": " + req.Header.Get(name))) //nolint:errcheck
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
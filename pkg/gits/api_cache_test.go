@@ -0,0 +1,104 @@
+// +build unit
+
+package gits_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jenkins-x/jx/v2/pkg/gits"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCacheTransport(t *testing.T) {
+	t.Parallel()
+
+	var requests, notModified int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		if r.Method == http.MethodPost {
+			w.WriteHeader(http.StatusCreated)
+			return
+		}
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			atomic.AddInt32(&notModified, 1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		_, _ = w.Write([]byte("repo"))
+	}))
+	defer server.Close()
+
+	cache := gits.NewAPICache(time.Hour)
+	client := &http.Client{Transport: &gits.CacheTransport{Base: http.DefaultTransport, Cache: cache}}
+
+	get := func(token string) *http.Response {
+		req, err := http.NewRequest(http.MethodGet, server.URL+"/repos/jenkins-x/jx", nil)
+		require.NoError(t, err)
+		req.Header.Set("Authorization", "token "+token)
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		body, err := ioutil.ReadAll(resp.Body)
+		require.NoError(t, err)
+		resp.Body.Close() //nolint:errcheck
+		assert.Equal(t, "repo", string(body))
+		return resp
+	}
+
+	assert.Empty(t, get("a").Header.Get(gits.CacheHeader))
+	assert.Equal(t, "1", get("a").Header.Get(gits.CacheHeader))
+	assert.Equal(t, int32(1), atomic.LoadInt32(&requests), "fresh responses should be served without a request")
+
+	// another user must not see the response of the first
+	assert.Empty(t, get("b").Header.Get(gits.CacheHeader))
+	assert.Equal(t, int32(2), atomic.LoadInt32(&requests))
+
+	// a change makes the responses be revalidated
+	resp, err := client.Post(server.URL+"/repos/jenkins-x/jx/pulls", "application/json", nil)
+	require.NoError(t, err)
+	resp.Body.Close() //nolint:errcheck
+	assert.Equal(t, http.StatusOK, get("a").StatusCode)
+	assert.Equal(t, int32(4), atomic.LoadInt32(&requests))
+	assert.Equal(t, int32(1), atomic.LoadInt32(&notModified))
+}
+
+func TestCacheTransportDiskCache(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "test-api-cache")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir) //nolint:errcheck
+
+	var notModified int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-Modified-Since") != "" {
+			atomic.AddInt32(&notModified, 1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("Last-Modified", "Mon, 02 Jan 2006 15:04:05 GMT")
+		_, _ = w.Write([]byte("pulls"))
+	}))
+	defer server.Close()
+
+	for i := 0; i < 2; i++ {
+		// each cache simulates a separate jx process
+		cache := gits.NewAPICache(time.Hour)
+		cache.Dir = dir
+		client := &http.Client{Transport: &gits.CacheTransport{Base: http.DefaultTransport, Cache: cache}}
+		resp, err := client.Get(server.URL + "/pulls")
+		require.NoError(t, err)
+		body, err := ioutil.ReadAll(resp.Body)
+		require.NoError(t, err)
+		resp.Body.Close() //nolint:errcheck
+		assert.Equal(t, "pulls", string(body))
+	}
+	assert.Equal(t, int32(1), atomic.LoadInt32(&notModified))
+}
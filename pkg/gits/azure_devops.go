@@ -0,0 +1,1381 @@
+package gits
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/github"
+	"github.com/jenkins-x/jx/v2/pkg/auth"
+	"github.com/jenkins-x/jx/v2/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+)
+
+const (
+	// azureDevOpsAPIVersion the version of the Azure DevOps REST API used
+	azureDevOpsAPIVersion = "5.1"
+
+	// azureDevOpsPageSize the page size used when listing pull requests and commits
+	azureDevOpsPageSize = 100
+
+	// azureDevOpsProfileURL the URL of the Azure DevOps service used to look up the current user and organisations
+	azureDevOpsProfileURL = "https://app.vssps.visualstudio.com"
+)
+
+// azureDevOpsWebHookEvents the service hook events which are sent to a webhook
+var azureDevOpsWebHookEvents = []string{"git.push", "git.pullrequest.created", "git.pullrequest.updated", "git.pullrequest.merged", "ms.vss-code.git-pullrequest-comment-event"}
+
+// AzureDevOpsProvider implements GitProvider interface for Azure DevOps Repos
+//
+// The owner of a repository is the Azure DevOps organisation. As repositories are grouped into projects inside an
+// organisation an owner of the form `organisation/project` can be used to select the project explicitly, otherwise
+// the project is found by looking up the repository in the organisation.
+type AzureDevOpsProvider struct {
+	Client   *http.Client
+	Username string
+
+	Server auth.AuthServer
+	User   auth.UserAuth
+	Git    Gitter
+
+	repositories map[string]*azureRepository
+}
+
+type azureList struct {
+	Count int             `json:"count"`
+	Value json.RawMessage `json:"value"`
+}
+
+type azureProject struct {
+	ID   string `json:"id,omitempty"`
+	Name string `json:"name,omitempty"`
+}
+
+type azureRepository struct {
+	ID            string       `json:"id,omitempty"`
+	Name          string       `json:"name,omitempty"`
+	URL           string       `json:"url,omitempty"`
+	RemoteURL     string       `json:"remoteUrl,omitempty"`
+	SSHURL        string       `json:"sshUrl,omitempty"`
+	WebURL        string       `json:"webUrl,omitempty"`
+	DefaultBranch string       `json:"defaultBranch,omitempty"`
+	IsFork        bool         `json:"isFork,omitempty"`
+	Project       azureProject `json:"project,omitempty"`
+}
+
+type azureIdentity struct {
+	ID          string `json:"id,omitempty"`
+	DisplayName string `json:"displayName,omitempty"`
+	UniqueName  string `json:"uniqueName,omitempty"`
+	URL         string `json:"url,omitempty"`
+	ImageURL    string `json:"imageUrl,omitempty"`
+}
+
+type azureLabel struct {
+	ID     string `json:"id,omitempty"`
+	Name   string `json:"name,omitempty"`
+	Active bool   `json:"active,omitempty"`
+}
+
+type azureCommitRef struct {
+	CommitID string `json:"commitId,omitempty"`
+}
+
+type azurePullRequest struct {
+	PullRequestID         int             `json:"pullRequestId,omitempty"`
+	Repository            azureRepository `json:"repository,omitempty"`
+	Status                string          `json:"status,omitempty"`
+	CreatedBy             azureIdentity   `json:"createdBy,omitempty"`
+	CreationDate          *time.Time      `json:"creationDate,omitempty"`
+	ClosedDate            *time.Time      `json:"closedDate,omitempty"`
+	Title                 string          `json:"title,omitempty"`
+	Description           string          `json:"description,omitempty"`
+	SourceRefName         string          `json:"sourceRefName,omitempty"`
+	TargetRefName         string          `json:"targetRefName,omitempty"`
+	MergeStatus           string          `json:"mergeStatus,omitempty"`
+	LastMergeSourceCommit *azureCommitRef `json:"lastMergeSourceCommit,omitempty"`
+	LastMergeCommit       *azureCommitRef `json:"lastMergeCommit,omitempty"`
+	Reviewers             []azureIdentity `json:"reviewers,omitempty"`
+	Labels                []azureLabel    `json:"labels,omitempty"`
+}
+
+type azureGitUserDate struct {
+	Name  string     `json:"name,omitempty"`
+	Email string     `json:"email,omitempty"`
+	Date  *time.Time `json:"date,omitempty"`
+}
+
+type azureCommit struct {
+	CommitID  string           `json:"commitId,omitempty"`
+	Comment   string           `json:"comment,omitempty"`
+	Author    azureGitUserDate `json:"author,omitempty"`
+	Committer azureGitUserDate `json:"committer,omitempty"`
+	RemoteURL string           `json:"remoteUrl,omitempty"`
+}
+
+type azureStatusContext struct {
+	Name  string `json:"name,omitempty"`
+	Genre string `json:"genre,omitempty"`
+}
+
+type azureStatus struct {
+	ID          int                `json:"id,omitempty"`
+	State       string             `json:"state,omitempty"`
+	Description string             `json:"description,omitempty"`
+	TargetURL   string             `json:"targetUrl,omitempty"`
+	URL         string             `json:"url,omitempty"`
+	Context     azureStatusContext `json:"context,omitempty"`
+}
+
+type azureRef struct {
+	Name     string `json:"name,omitempty"`
+	ObjectID string `json:"objectId,omitempty"`
+	IsLocked bool   `json:"isLocked,omitempty"`
+}
+
+type azureItem struct {
+	ObjectID      string `json:"objectId,omitempty"`
+	CommitID      string `json:"commitId,omitempty"`
+	GitObjectType string `json:"gitObjectType,omitempty"`
+	Path          string `json:"path,omitempty"`
+	Content       string `json:"content,omitempty"`
+	URL           string `json:"url,omitempty"`
+}
+
+type azureSubscription struct {
+	ID               string            `json:"id,omitempty"`
+	PublisherID      string            `json:"publisherId,omitempty"`
+	EventType        string            `json:"eventType,omitempty"`
+	ResourceVersion  string            `json:"resourceVersion,omitempty"`
+	ConsumerID       string            `json:"consumerId,omitempty"`
+	ConsumerActionID string            `json:"consumerActionId,omitempty"`
+	PublisherInputs  map[string]string `json:"publisherInputs,omitempty"`
+	ConsumerInputs   map[string]string `json:"consumerInputs,omitempty"`
+}
+
+// azureStatusStates maps the Azure DevOps commit status states to the git provider states
+var azureStatusStates = map[string]string{
+	"succeeded":     "success",
+	"failed":        "failure",
+	"error":         "error",
+	"pending":       "pending",
+	"notSet":        "pending",
+	"notApplicable": "success",
+}
+
+// NewAzureDevOpsProvider creates a new git provider for Azure DevOps Repos using a personal access token
+func NewAzureDevOpsProvider(server *auth.AuthServer, user *auth.UserAuth, git Gitter) (GitProvider, error) {
+	provider := AzureDevOpsProvider{
+		Client:       NewAPIClient(2 * time.Minute),
+		Server:       *server,
+		User:         *user,
+		Username:     user.Username,
+		Git:          git,
+		repositories: map[string]*azureRepository{},
+	}
+	return &provider, nil
+}
+
+// AzureDevOpsAccessTokenURL returns the URL to create a personal access token in Azure DevOps
+func AzureDevOpsAccessTokenURL(url string) string {
+	return util.UrlJoin(url, "_usersSettings/tokens")
+}
+
+// IsAzureDevOpsURL returns true if the given git server URL is hosted by Azure DevOps
+func IsAzureDevOpsURL(gitServerURL string) bool {
+	u, err := url.Parse(gitServerURL)
+	if err != nil || u.Host == "" {
+		return false
+	}
+	host := strings.ToLower(u.Hostname())
+	return host == "dev.azure.com" || host == "ssh.dev.azure.com" || strings.HasSuffix(host, ".visualstudio.com")
+}
+
+// AzureDevOpsCloneURL returns the HTTPS clone URL of an Azure DevOps repository
+func AzureDevOpsCloneURL(repo *GitRepository) string {
+	host := repo.Host
+	if !strings.Contains(host, ":/") {
+		host = "https://" + host
+	}
+	project := repo.Project
+	if project == "" {
+		project = repo.Name
+	}
+	if strings.HasSuffix(strings.ToLower(repo.Host), ".visualstudio.com") {
+		return util.UrlJoin(host, project, "_git", repo.Name)
+	}
+	return util.UrlJoin(host, repo.Organisation, project, "_git", repo.Name)
+}
+
+// parseAzureDevOpsPath populates the organisation, project and name from the path of an Azure DevOps git URL such as
+// `<org>/<project>/_git/<repo>` returning false if the path is not an Azure DevOps path
+func parseAzureDevOpsPath(paths []string, info *GitRepository) bool {
+	idx := -1
+	for i, p := range paths {
+		if p == "_git" {
+			idx = i
+			break
+		}
+	}
+	if idx < 1 || idx+1 >= len(paths) {
+		return false
+	}
+	info.Name = paths[idx+1]
+	host := strings.ToLower(info.Host)
+	if strings.HasSuffix(host, ".visualstudio.com") {
+		// the organisation is the sub domain for the legacy URLs
+		info.Organisation = strings.Split(info.Host, ".")[0]
+	} else {
+		info.Organisation = paths[0]
+	}
+	if idx == 1 && info.Organisation == paths[0] {
+		// the default repository of a project has the same name as the project
+		info.Project = info.Name
+	} else {
+		info.Project = paths[idx-1]
+	}
+	return true
+}
+
+// splitAzureDevOpsOwner splits an owner of the form `organisation/project` into its organisation and project
+func splitAzureDevOpsOwner(owner string) (string, string) {
+	paths := strings.SplitN(owner, "/", 2)
+	if len(paths) == 2 {
+		return paths[0], paths[1]
+	}
+	return owner, ""
+}
+
+// apiURL returns the URL of the given REST API path in the organisation and optional project
+func (p *AzureDevOpsProvider) apiURL(org string, project string, path string, query url.Values) string {
+	base := strings.TrimSuffix(p.Server.URL, "/")
+	// the legacy visualstudio.com URLs include the organisation in the host name
+	if !strings.HasSuffix(strings.ToLower(base), ".visualstudio.com") {
+		base = util.UrlJoin(base, url.PathEscape(org))
+	}
+	if project != "" {
+		base = util.UrlJoin(base, url.PathEscape(project))
+	}
+	if query == nil {
+		query = url.Values{}
+	}
+	query.Set("api-version", azureDevOpsAPIVersion)
+	return util.UrlJoin(base, "_apis", path) + "?" + query.Encode()
+}
+
+// do invokes the REST API unmarshalling the response into the result if it is not nil
+func (p *AzureDevOpsProvider) do(method string, u string, body interface{}, result interface{}) error {
+	var data []byte
+	if body != nil {
+		var err error
+		data, err = json.Marshal(body)
+		if err != nil {
+			return errors.Wrapf(err, "marshalling the request body for %s", u)
+		}
+	}
+	req, err := http.NewRequest(method, u, bytes.NewReader(data))
+	if err != nil {
+		return errors.Wrapf(err, "creating the request %s %s", method, u)
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Content-Type", "application/json")
+	token := base64.StdEncoding.EncodeToString([]byte(p.Username + ":" + p.User.ApiToken))
+	req.Header.Set("Authorization", "Basic "+token)
+
+	resp, err := p.Client.Do(req)
+	if err != nil {
+		return errors.Wrapf(err, "invoking %s %s", method, u)
+	}
+	defer resp.Body.Close()
+	data, err = ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return errors.Wrapf(err, "reading the response of %s %s", method, u)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return &azureDevOpsError{Method: method, URL: u, StatusCode: resp.StatusCode, Body: string(data)}
+	}
+	if result == nil || len(data) == 0 {
+		return nil
+	}
+	err = json.Unmarshal(data, result)
+	if err != nil {
+		return errors.Wrapf(err, "unmarshalling the response of %s %s", method, u)
+	}
+	return nil
+}
+
+// list invokes the REST API unmarshalling the values of the returned list into the result
+func (p *AzureDevOpsProvider) list(u string, result interface{}) (int, error) {
+	answer := azureList{}
+	err := p.do(http.MethodGet, u, nil, &answer)
+	if err != nil {
+		return 0, err
+	}
+	if len(answer.Value) == 0 {
+		return 0, nil
+	}
+	err = json.Unmarshal(answer.Value, result)
+	if err != nil {
+		return 0, errors.Wrapf(err, "unmarshalling the values of %s", u)
+	}
+	return answer.Count, nil
+}
+
+// azureDevOpsError is returned when the Azure DevOps REST API returns an unsuccessful status code
+type azureDevOpsError struct {
+	Method     string
+	URL        string
+	StatusCode int
+	Body       string
+}
+
+func (e *azureDevOpsError) Error() string {
+	return fmt.Sprintf("%s %s returned status %d: %s", e.Method, e.URL, e.StatusCode, e.Body)
+}
+
+func isAzureDevOpsNotFound(err error) bool {
+	e, ok := errors.Cause(err).(*azureDevOpsError)
+	return ok && e.StatusCode == http.StatusNotFound
+}
+
+// findRepository finds the repository with the given name in the owner
+func (p *AzureDevOpsProvider) findRepository(owner string, name string) (*azureRepository, error) {
+	key := owner + "/" + name
+	if repo, ok := p.repositories[key]; ok {
+		return repo, nil
+	}
+	org, project := splitAzureDevOpsOwner(owner)
+	repos := []azureRepository{}
+	_, err := p.list(p.apiURL(org, project, "git/repositories", nil), &repos)
+	if err != nil {
+		return nil, errors.Wrapf(err, "listing the repositories of %s", owner)
+	}
+	var answer *azureRepository
+	for i := range repos {
+		repo := &repos[i]
+		if !strings.EqualFold(repo.Name, name) {
+			continue
+		}
+		if answer != nil {
+			return nil, fmt.Errorf("the repository %s exists in projects %s and %s of organisation %s, use an owner of the form %s to select the project",
+				name, answer.Project.Name, repo.Project.Name, org, util.ColorInfo(org+"/<project>"))
+		}
+		answer = repo
+	}
+	if answer == nil {
+		return nil, fmt.Errorf("could not find the repository %s in %s", name, owner)
+	}
+	p.repositories[key] = answer
+	return answer, nil
+}
+
+// repositoryAPIURL returns the URL of a REST API path of a repository
+func (p *AzureDevOpsProvider) repositoryAPIURL(owner string, name string, path string, query url.Values) (string, *azureRepository, error) {
+	repo, err := p.findRepository(owner, name)
+	if err != nil {
+		return "", nil, err
+	}
+	org, _ := splitAzureDevOpsOwner(owner)
+	apiPath := util.UrlJoin("git/repositories", repo.ID)
+	if path != "" {
+		apiPath = util.UrlJoin(apiPath, path)
+	}
+	return p.apiURL(org, repo.Project.ID, apiPath, query), repo, nil
+}
+
+func (p *AzureDevOpsProvider) toGitRepository(owner string, repo *azureRepository) *GitRepository {
+	org, _ := splitAzureDevOpsOwner(owner)
+	return &GitRepository{
+		Name:          repo.Name,
+		HTMLURL:       repo.WebURL,
+		CloneURL:      repo.RemoteURL,
+		SSHURL:        repo.SSHURL,
+		URL:           repo.WebURL,
+		Fork:          repo.IsFork,
+		Organisation:  org,
+		Project:       repo.Project.Name,
+		Private:       true,
+		HasIssues:     true,
+		HasWiki:       true,
+		HasProjects:   true,
+		DefaultBranch: strings.TrimPrefix(repo.DefaultBranch, "refs/heads/"),
+	}
+}
+
+// ListOrganisations lists the Azure DevOps organisations the current user is a member of
+func (p *AzureDevOpsProvider) ListOrganisations() ([]GitOrganisation, error) {
+	profile := azureIdentity{}
+	err := p.do(http.MethodGet, azureDevOpsProfileURL+"/_apis/profile/profiles/me?api-version="+azureDevOpsAPIVersion, nil, &profile)
+	if err != nil {
+		return nil, errors.Wrap(err, "getting the profile of the current user")
+	}
+	accounts := []struct {
+		AccountName string `json:"accountName"`
+	}{}
+	query := url.Values{}
+	query.Set("memberId", profile.ID)
+	query.Set("api-version", azureDevOpsAPIVersion)
+	_, err = p.list(azureDevOpsProfileURL+"/_apis/accounts?"+query.Encode(), &accounts)
+	if err != nil {
+		return nil, errors.Wrap(err, "listing the organisations of the current user")
+	}
+	answer := []GitOrganisation{}
+	for _, a := range accounts {
+		answer = append(answer, GitOrganisation{Login: a.AccountName})
+	}
+	return answer, nil
+}
+
+// ListRepositories lists the repositories in the organisation or `organisation/project`
+func (p *AzureDevOpsProvider) ListRepositories(org string) ([]*GitRepository, error) {
+	o, project := splitAzureDevOpsOwner(org)
+	repos := []azureRepository{}
+	_, err := p.list(p.apiURL(o, project, "git/repositories", nil), &repos)
+	if err != nil {
+		return nil, errors.Wrapf(err, "listing the repositories of %s", org)
+	}
+	answer := []*GitRepository{}
+	for i := range repos {
+		answer = append(answer, p.toGitRepository(org, &repos[i]))
+	}
+	return answer, nil
+}
+
+// CreateRepository creates a repository in the project of the owner. If the owner does not specify a project then
+// the project with the same name as the organisation is used
+func (p *AzureDevOpsProvider) CreateRepository(org string, name string, private bool) (*GitRepository, error) {
+	o, project := splitAzureDevOpsOwner(org)
+	if project == "" {
+		project = o
+	}
+	proj := azureProject{}
+	err := p.do(http.MethodGet, p.apiURL(o, "", util.UrlJoin("projects", url.PathEscape(project)), nil), nil, &proj)
+	if err != nil {
+		return nil, errors.Wrapf(err, "getting the project %s in organisation %s", project, o)
+	}
+	body := azureRepository{
+		Name:    name,
+		Project: azureProject{ID: proj.ID},
+	}
+	repo := azureRepository{}
+	err = p.do(http.MethodPost, p.apiURL(o, proj.ID, "git/repositories", nil), body, &repo)
+	if err != nil {
+		return nil, errors.Wrapf(err, "creating the repository %s in %s/%s", name, o, project)
+	}
+	return p.toGitRepository(org, &repo), nil
+}
+
+// GetRepository returns the repository
+func (p *AzureDevOpsProvider) GetRepository(org string, name string) (*GitRepository, error) {
+	repo, err := p.findRepository(org, name)
+	if err != nil {
+		return nil, err
+	}
+	return p.toGitRepository(org, repo), nil
+}
+
+// DeleteRepository deletes the repository
+func (p *AzureDevOpsProvider) DeleteRepository(org string, name string) error {
+	u, _, err := p.repositoryAPIURL(org, name, "", nil)
+	if err != nil {
+		return err
+	}
+	err = p.do(http.MethodDelete, u, nil, nil)
+	if err != nil {
+		return errors.Wrapf(err, "deleting the repository %s/%s", org, name)
+	}
+	delete(p.repositories, org+"/"+name)
+	return nil
+}
+
+// ForkRepository is not supported; pull requests are created from branches in the same repository
+func (p *AzureDevOpsProvider) ForkRepository(originalOrg string, name string, destinationOrg string) (*GitRepository, error) {
+	return nil, fmt.Errorf("forking repositories is not supported on Azure DevOps")
+}
+
+// RenameRepository renames the repository
+func (p *AzureDevOpsProvider) RenameRepository(org string, name string, newName string) (*GitRepository, error) {
+	u, _, err := p.repositoryAPIURL(org, name, "", nil)
+	if err != nil {
+		return nil, err
+	}
+	repo := azureRepository{}
+	err = p.do(http.MethodPatch, u, azureRepository{Name: newName}, &repo)
+	if err != nil {
+		return nil, errors.Wrapf(err, "renaming the repository %s/%s to %s", org, name, newName)
+	}
+	delete(p.repositories, org+"/"+name)
+	return p.toGitRepository(org, &repo), nil
+}
+
+// ValidateRepositoryName returns an error if the repository already exists
+func (p *AzureDevOpsProvider) ValidateRepositoryName(org string, name string) error {
+	_, err := p.findRepository(org, name)
+	if err == nil {
+		return fmt.Errorf("repository %s/%s already exists", org, name)
+	}
+	return nil
+}
+
+func azureDevOpsRefName(branch string) string {
+	// lets remove any fork owner prefix
+	idx := strings.Index(branch, ":")
+	if idx >= 0 {
+		branch = branch[idx+1:]
+	}
+	if strings.HasPrefix(branch, "refs/") {
+		return branch
+	}
+	return "refs/heads/" + branch
+}
+
+func (p *AzureDevOpsProvider) toPullRequest(owner string, pr *azurePullRequest) *GitPullRequest {
+	org, _ := splitAzureDevOpsOwner(owner)
+	number := pr.PullRequestID
+	state := "open"
+	merged := pr.Status == "completed"
+	mergeable := pr.MergeStatus == "succeeded"
+	if pr.Status != "active" {
+		state = "closed"
+	}
+	headRef := strings.TrimPrefix(pr.SourceRefName, "refs/heads/")
+	answer := &GitPullRequest{
+		URL: util.UrlJoin(pr.Repository.WebURL, "pullrequest", strconv.Itoa(number)),
+		Author: &GitUser{
+			URL:       pr.CreatedBy.URL,
+			Login:     pr.CreatedBy.UniqueName,
+			Name:      pr.CreatedBy.DisplayName,
+			AvatarURL: pr.CreatedBy.ImageURL,
+		},
+		Owner:     org,
+		Repo:      pr.Repository.Name,
+		Number:    &number,
+		Mergeable: &mergeable,
+		Merged:    &merged,
+		HeadRef:   &headRef,
+		State:     &state,
+		ClosedAt:  pr.ClosedDate,
+		Title:     pr.Title,
+		Body:      pr.Description,
+		UpdatedAt: pr.CreationDate,
+		HeadOwner: &org,
+	}
+	if merged {
+		answer.MergedAt = pr.ClosedDate
+	}
+	if pr.LastMergeSourceCommit != nil {
+		answer.LastCommitSha = pr.LastMergeSourceCommit.CommitID
+	}
+	if pr.LastMergeCommit != nil && merged {
+		answer.MergeCommitSHA = &pr.LastMergeCommit.CommitID
+	}
+	for _, r := range pr.Reviewers {
+		answer.RequestedReviewers = append(answer.RequestedReviewers, &GitUser{
+			URL:   r.URL,
+			Login: r.UniqueName,
+			Name:  r.DisplayName,
+		})
+	}
+	for i := range pr.Labels {
+		answer.Labels = append(answer.Labels, &Label{Name: &pr.Labels[i].Name})
+	}
+	return answer
+}
+
+// CreatePullRequest creates a pull request from the head branch to the base branch
+func (p *AzureDevOpsProvider) CreatePullRequest(data *GitPullRequestArguments) (*GitPullRequest, error) {
+	owner := data.GitRepository.Organisation
+	if data.GitRepository.Project != "" && data.GitRepository.Project != owner {
+		owner = owner + "/" + data.GitRepository.Project
+	}
+	base := data.Base
+	if base == "" {
+		base = "master"
+	}
+	u, _, err := p.repositoryAPIURL(owner, data.GitRepository.Name, "pullrequests", nil)
+	if err != nil {
+		return nil, err
+	}
+	body := azurePullRequest{
+		Title:         data.Title,
+		Description:   data.Body,
+		SourceRefName: azureDevOpsRefName(data.Head),
+		TargetRefName: azureDevOpsRefName(base),
+	}
+	for _, l := range data.Labels {
+		body.Labels = append(body.Labels, azureLabel{Name: l})
+	}
+	pr := azurePullRequest{}
+	err = p.do(http.MethodPost, u, body, &pr)
+	if err != nil {
+		return nil, errors.Wrapf(err, "creating pull request from %s to %s on %s/%s", data.Head, base, owner, data.GitRepository.Name)
+	}
+	return p.toPullRequest(owner, &pr), nil
+}
+
+// UpdatePullRequest updates the title and description of the pull request
+func (p *AzureDevOpsProvider) UpdatePullRequest(data *GitPullRequestArguments, number int) (*GitPullRequest, error) {
+	owner := data.GitRepository.Organisation
+	if data.GitRepository.Project != "" && data.GitRepository.Project != owner {
+		owner = owner + "/" + data.GitRepository.Project
+	}
+	u, _, err := p.repositoryAPIURL(owner, data.GitRepository.Name, util.UrlJoin("pullrequests", strconv.Itoa(number)), nil)
+	if err != nil {
+		return nil, err
+	}
+	body := azurePullRequest{
+		Title:       data.Title,
+		Description: data.Body,
+	}
+	pr := azurePullRequest{}
+	err = p.do(http.MethodPatch, u, body, &pr)
+	if err != nil {
+		return nil, errors.Wrapf(err, "updating pull request %d on %s/%s", number, owner, data.GitRepository.Name)
+	}
+	return p.toPullRequest(owner, &pr), nil
+}
+
+func (p *AzureDevOpsProvider) getPullRequest(owner string, repo string, number int) (*azurePullRequest, error) {
+	u, _, err := p.repositoryAPIURL(owner, repo, util.UrlJoin("pullrequests", strconv.Itoa(number)), nil)
+	if err != nil {
+		return nil, err
+	}
+	pr := azurePullRequest{}
+	err = p.do(http.MethodGet, u, nil, &pr)
+	if err != nil {
+		return nil, errors.Wrapf(err, "getting pull request %d on %s/%s", number, owner, repo)
+	}
+	return &pr, nil
+}
+
+// UpdatePullRequestStatus reloads the pull request
+func (p *AzureDevOpsProvider) UpdatePullRequestStatus(pr *GitPullRequest) error {
+	if pr.Number == nil {
+		return fmt.Errorf("missing the number of pull request %s", pr.URL)
+	}
+	result, err := p.getPullRequest(pr.Owner, pr.Repo, *pr.Number)
+	if err != nil {
+		return err
+	}
+	*pr = *p.toPullRequest(pr.Owner, result)
+	return nil
+}
+
+// GetPullRequest returns the pull request
+func (p *AzureDevOpsProvider) GetPullRequest(owner string, repo *GitRepository, number int) (*GitPullRequest, error) {
+	pr, err := p.getPullRequest(owner, repo.Name, number)
+	if err != nil {
+		return nil, err
+	}
+	return p.toPullRequest(owner, pr), nil
+}
+
+// ListOpenPullRequests lists the active pull requests including their labels
+func (p *AzureDevOpsProvider) ListOpenPullRequests(owner string, repo string) ([]*GitPullRequest, error) {
+	answer := []*GitPullRequest{}
+	for skip := 0; ; skip += azureDevOpsPageSize {
+		query := url.Values{}
+		query.Set("searchCriteria.status", "active")
+		query.Set("$top", strconv.Itoa(azureDevOpsPageSize))
+		query.Set("$skip", strconv.Itoa(skip))
+		u, _, err := p.repositoryAPIURL(owner, repo, "pullrequests", query)
+		if err != nil {
+			return nil, err
+		}
+		prs := []azurePullRequest{}
+		_, err = p.list(u, &prs)
+		if err != nil {
+			return nil, errors.Wrapf(err, "listing open pull requests on %s/%s", owner, repo)
+		}
+		for i := range prs {
+			answer = append(answer, p.toPullRequest(owner, &prs[i]))
+		}
+		if len(prs) < azureDevOpsPageSize {
+			break
+		}
+	}
+	return answer, nil
+}
+
+func toAzureDevOpsGitCommit(commit *azureCommit) *GitCommit {
+	return &GitCommit{
+		SHA:     commit.CommitID,
+		Message: commit.Comment,
+		URL:     commit.RemoteURL,
+		Author: &GitUser{
+			Name:  commit.Author.Name,
+			Email: commit.Author.Email,
+		},
+		Committer: &GitUser{
+			Name:  commit.Committer.Name,
+			Email: commit.Committer.Email,
+		},
+	}
+}
+
+// GetPullRequestCommits returns the commits of the pull request
+func (p *AzureDevOpsProvider) GetPullRequestCommits(owner string, repo *GitRepository, number int) ([]*GitCommit, error) {
+	u, _, err := p.repositoryAPIURL(owner, repo.Name, util.UrlJoin("pullrequests", strconv.Itoa(number), "commits"), nil)
+	if err != nil {
+		return nil, err
+	}
+	commits := []azureCommit{}
+	_, err = p.list(u, &commits)
+	if err != nil {
+		return nil, errors.Wrapf(err, "listing the commits of pull request %d on %s/%s", number, owner, repo.Name)
+	}
+	answer := []*GitCommit{}
+	for i := range commits {
+		answer = append(answer, toAzureDevOpsGitCommit(&commits[i]))
+	}
+	return answer, nil
+}
+
+// PullRequestLastCommitStatus returns the state of the most recent status of the last commit of the pull request
+func (p *AzureDevOpsProvider) PullRequestLastCommitStatus(pr *GitPullRequest) (string, error) {
+	ref := pr.LastCommitSha
+	if ref == "" {
+		return "", fmt.Errorf("missing the last commit sha of pull request %s", pr.URL)
+	}
+	statuses, err := p.ListCommitStatus(pr.Owner, pr.Repo, ref)
+	if err != nil {
+		return "", err
+	}
+	// statuses are returned most recent first
+	for _, status := range statuses {
+		if status.State != "" {
+			return status.State, nil
+		}
+	}
+	return "", fmt.Errorf("could not find a status for repository %s/%s with ref %s", pr.Owner, pr.Repo, ref)
+}
+
+// ListCommitStatus lists the statuses of the commit
+func (p *AzureDevOpsProvider) ListCommitStatus(org string, repo string, sha string) ([]*GitRepoStatus, error) {
+	u, _, err := p.repositoryAPIURL(org, repo, util.UrlJoin("commits", sha, "statuses"), nil)
+	if err != nil {
+		return nil, err
+	}
+	statuses := []azureStatus{}
+	_, err = p.list(u, &statuses)
+	if err != nil {
+		return nil, errors.Wrapf(err, "listing the statuses of commit %s on %s/%s", sha, org, repo)
+	}
+	answer := []*GitRepoStatus{}
+	for _, s := range statuses {
+		answer = append(answer, &GitRepoStatus{
+			ID:          strconv.Itoa(s.ID),
+			Context:     s.Context.Name,
+			URL:         s.URL,
+			State:       azureStatusStates[s.State],
+			TargetURL:   s.TargetURL,
+			Description: s.Description,
+		})
+	}
+	return answer, nil
+}
+
+// ListCommits lists the commits of the repository
+func (p *AzureDevOpsProvider) ListCommits(owner string, repo string, opt *ListCommitsArguments) ([]*GitCommit, error) {
+	query := url.Values{}
+	if opt != nil {
+		if opt.SHA != "" {
+			query.Set("searchCriteria.itemVersion.version", opt.SHA)
+			query.Set("searchCriteria.itemVersion.versionType", "commit")
+		}
+		if opt.Path != "" {
+			query.Set("searchCriteria.itemPath", opt.Path)
+		}
+		if opt.Author != "" {
+			query.Set("searchCriteria.author", opt.Author)
+		}
+		if !opt.Since.IsZero() {
+			query.Set("searchCriteria.fromDate", opt.Since.Format(time.RFC3339))
+		}
+		if !opt.Until.IsZero() {
+			query.Set("searchCriteria.toDate", opt.Until.Format(time.RFC3339))
+		}
+		if opt.PerPage > 0 {
+			query.Set("searchCriteria.$top", strconv.Itoa(opt.PerPage))
+			if opt.Page > 1 {
+				query.Set("searchCriteria.$skip", strconv.Itoa((opt.Page-1)*opt.PerPage))
+			}
+		}
+	}
+	u, _, err := p.repositoryAPIURL(owner, repo, "commits", query)
+	if err != nil {
+		return nil, err
+	}
+	commits := []azureCommit{}
+	_, err = p.list(u, &commits)
+	if err != nil {
+		return nil, errors.Wrapf(err, "listing the commits of %s/%s", owner, repo)
+	}
+	answer := []*GitCommit{}
+	for i := range commits {
+		answer = append(answer, toAzureDevOpsGitCommit(&commits[i]))
+	}
+	return answer, nil
+}
+
+// UpdateCommitStatus adds a status to the commit
+func (p *AzureDevOpsProvider) UpdateCommitStatus(org string, repo string, sha string, status *GitRepoStatus) (*GitRepoStatus, error) {
+	u, _, err := p.repositoryAPIURL(org, repo, util.UrlJoin("commits", sha, "statuses"), nil)
+	if err != nil {
+		return nil, err
+	}
+	state := "notSet"
+	for k, v := range azureStatusStates {
+		if v == status.State && k != "notApplicable" && k != "notSet" {
+			state = k
+			break
+		}
+	}
+	body := azureStatus{
+		State:       state,
+		Description: status.Description,
+		TargetURL:   status.TargetURL,
+		Context: azureStatusContext{
+			Name:  status.Context,
+			Genre: "jenkins-x",
+		},
+	}
+	result := azureStatus{}
+	err = p.do(http.MethodPost, u, body, &result)
+	if err != nil {
+		return nil, errors.Wrapf(err, "updating the status of commit %s on %s/%s", sha, org, repo)
+	}
+	return &GitRepoStatus{
+		ID:          strconv.Itoa(result.ID),
+		Context:     result.Context.Name,
+		URL:         result.URL,
+		State:       azureStatusStates[result.State],
+		TargetURL:   result.TargetURL,
+		Description: result.Description,
+	}, nil
+}
+
+// MergePullRequest completes the pull request using the given message as the merge commit message
+func (p *AzureDevOpsProvider) MergePullRequest(pr *GitPullRequest, message string) error {
+	if pr.Number == nil {
+		return fmt.Errorf("missing the number of pull request %s", pr.URL)
+	}
+	current, err := p.getPullRequest(pr.Owner, pr.Repo, *pr.Number)
+	if err != nil {
+		return err
+	}
+	u, _, err := p.repositoryAPIURL(pr.Owner, pr.Repo, util.UrlJoin("pullrequests", strconv.Itoa(*pr.Number)), nil)
+	if err != nil {
+		return err
+	}
+	body := map[string]interface{}{
+		"status":                "completed",
+		"lastMergeSourceCommit": current.LastMergeSourceCommit,
+		"completionOptions": map[string]interface{}{
+			"mergeCommitMessage": message,
+			"deleteSourceBranch": true,
+		},
+	}
+	err = p.do(http.MethodPatch, u, body, nil)
+	if err != nil {
+		return errors.Wrapf(err, "merging pull request %s", pr.URL)
+	}
+	return nil
+}
+
+// listWebHookSubscriptions lists the service hook subscriptions which send events of the repository to a webhook
+func (p *AzureDevOpsProvider) listWebHookSubscriptions(owner string, name string) ([]azureSubscription, *azureRepository, error) {
+	repo, err := p.findRepository(owner, name)
+	if err != nil {
+		return nil, nil, err
+	}
+	org, _ := splitAzureDevOpsOwner(owner)
+	subscriptions := []azureSubscription{}
+	_, err = p.list(p.apiURL(org, "", "hooks/subscriptions", nil), &subscriptions)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "listing the service hook subscriptions of %s", org)
+	}
+	answer := []azureSubscription{}
+	for _, s := range subscriptions {
+		if s.ConsumerID == "webHooks" && s.PublisherInputs["repository"] == repo.ID {
+			answer = append(answer, s)
+		}
+	}
+	return answer, repo, nil
+}
+
+// CreateWebHook creates service hook subscriptions to send the push, pull request and comment events to the webhook
+func (p *AzureDevOpsProvider) CreateWebHook(data *GitWebHookArguments) error {
+	owner := data.Owner
+	if owner == "" && data.Repo != nil {
+		owner = data.Repo.Organisation
+	}
+	if data.Repo == nil || data.Repo.Name == "" {
+		return fmt.Errorf("missing property Repo")
+	}
+	if data.URL == "" {
+		return fmt.Errorf("missing property URL")
+	}
+	subscriptions, repo, err := p.listWebHookSubscriptions(owner, data.Repo.Name)
+	if err != nil {
+		return err
+	}
+	existing := map[string]bool{}
+	for _, s := range subscriptions {
+		if s.ConsumerInputs["url"] == data.URL {
+			existing[s.EventType] = true
+		}
+	}
+	org, _ := splitAzureDevOpsOwner(owner)
+	log.Logger().Infof("Creating Azure DevOps webhook for %s/%s for url %s", util.ColorInfo(owner), util.ColorInfo(data.Repo.Name), util.ColorInfo(data.URL))
+	for _, event := range azureDevOpsWebHookEvents {
+		if existing[event] {
+			continue
+		}
+		err = p.do(http.MethodPost, p.apiURL(org, "", "hooks/subscriptions", nil), p.webHookSubscription(repo, event, data), nil)
+		if err != nil {
+			return errors.Wrapf(err, "creating the %s webhook for %s/%s", event, owner, data.Repo.Name)
+		}
+	}
+	return nil
+}
+
+func (p *AzureDevOpsProvider) webHookSubscription(repo *azureRepository, event string, data *GitWebHookArguments) azureSubscription {
+	consumerInputs := map[string]string{
+		"url": data.URL,
+	}
+	if data.Secret != "" {
+		consumerInputs["basicAuthUsername"] = "jenkins-x"
+		consumerInputs["basicAuthPassword"] = data.Secret
+	}
+	if data.InsecureSSL {
+		consumerInputs["acceptUntrustedCerts"] = "true"
+	}
+	return azureSubscription{
+		PublisherID:      "tfs",
+		EventType:        event,
+		ResourceVersion:  "1.0",
+		ConsumerID:       "webHooks",
+		ConsumerActionID: "httpRequest",
+		PublisherInputs: map[string]string{
+			"projectId":  repo.Project.ID,
+			"repository": repo.ID,
+		},
+		ConsumerInputs: consumerInputs,
+	}
+}
+
+// ListWebHooks lists the webhook URLs which receive events from the repository
+func (p *AzureDevOpsProvider) ListWebHooks(org string, repo string) ([]*GitWebHookArguments, error) {
+	subscriptions, _, err := p.listWebHookSubscriptions(org, repo)
+	if err != nil {
+		return nil, err
+	}
+	answer := []*GitWebHookArguments{}
+	found := map[string]bool{}
+	for _, s := range subscriptions {
+		u := s.ConsumerInputs["url"]
+		if found[u] {
+			continue
+		}
+		found[u] = true
+		answer = append(answer, &GitWebHookArguments{
+			Owner:       org,
+			Repo:        &GitRepository{Name: repo, Organisation: org},
+			URL:         u,
+			InsecureSSL: s.ConsumerInputs["acceptUntrustedCerts"] == "true",
+		})
+	}
+	return answer, nil
+}
+
+// UpdateWebHook updates the subscriptions sending events to the existing webhook URL
+func (p *AzureDevOpsProvider) UpdateWebHook(data *GitWebHookArguments) error {
+	owner := data.Owner
+	if owner == "" && data.Repo != nil {
+		owner = data.Repo.Organisation
+	}
+	if data.Repo == nil || data.Repo.Name == "" {
+		return fmt.Errorf("missing property Repo")
+	}
+	subscriptions, repo, err := p.listWebHookSubscriptions(owner, data.Repo.Name)
+	if err != nil {
+		return err
+	}
+	org, _ := splitAzureDevOpsOwner(owner)
+	updated := false
+	for _, s := range subscriptions {
+		if s.ConsumerInputs["url"] != data.ExistingURL {
+			continue
+		}
+		subscription := p.webHookSubscription(repo, s.EventType, data)
+		subscription.ID = s.ID
+		log.Logger().Infof("Updating Azure DevOps webhook for %s/%s from %s to %s", util.ColorInfo(owner), util.ColorInfo(data.Repo.Name), data.ExistingURL, util.ColorInfo(data.URL))
+		err = p.do(http.MethodPut, p.apiURL(org, "", util.UrlJoin("hooks/subscriptions", s.ID), nil), subscription, nil)
+		if err != nil {
+			return errors.Wrapf(err, "updating the %s webhook for %s/%s", s.EventType, owner, data.Repo.Name)
+		}
+		updated = true
+	}
+	if !updated {
+		return p.CreateWebHook(data)
+	}
+	return nil
+}
+
+func (p *AzureDevOpsProvider) IsGitHub() bool {
+	return false
+}
+
+func (p *AzureDevOpsProvider) IsGitea() bool {
+	return false
+}
+
+func (p *AzureDevOpsProvider) IsBitbucketCloud() bool {
+	return false
+}
+
+func (p *AzureDevOpsProvider) IsBitbucketServer() bool {
+	return false
+}
+
+func (p *AzureDevOpsProvider) IsGerrit() bool {
+	return false
+}
+
+func (p *AzureDevOpsProvider) Kind() string {
+	return KindAzureDevOps
+}
+
+// GetIssue is not supported as Azure Boards work items are not git issues
+func (p *AzureDevOpsProvider) GetIssue(org string, name string, number int) (*GitIssue, error) {
+	log.Logger().Warn("Azure DevOps does not support git issue tracking")
+	return nil, nil
+}
+
+// IssueURL returns the URL of the pull request as Azure DevOps does not support git issues
+func (p *AzureDevOpsProvider) IssueURL(org string, name string, number int, isPull bool) string {
+	if !isPull {
+		log.Logger().Warn("Azure DevOps does not support git issue tracking")
+		return ""
+	}
+	repo, err := p.findRepository(org, name)
+	if err != nil {
+		return ""
+	}
+	return util.UrlJoin(repo.WebURL, "pullrequest", strconv.Itoa(number))
+}
+
+func (p *AzureDevOpsProvider) SearchIssues(org string, name string, query string) ([]*GitIssue, error) {
+	log.Logger().Warn("Azure DevOps does not support git issue tracking")
+	return nil, nil
+}
+
+func (p *AzureDevOpsProvider) SearchIssuesClosedSince(org string, name string, t time.Time) ([]*GitIssue, error) {
+	log.Logger().Warn("Azure DevOps does not support git issue tracking")
+	return nil, nil
+}
+
+func (p *AzureDevOpsProvider) CreateIssue(owner string, repo string, issue *GitIssue) (*GitIssue, error) {
+	log.Logger().Warn("Azure DevOps does not support git issue tracking")
+	return nil, nil
+}
+
+func (p *AzureDevOpsProvider) HasIssues() bool {
+	return false
+}
+
+// AddPRComment adds a comment thread to the pull request
+func (p *AzureDevOpsProvider) AddPRComment(pr *GitPullRequest, comment string) error {
+	if pr.Number == nil {
+		return fmt.Errorf("missing the number of pull request %s", pr.URL)
+	}
+	return p.CreateIssueComment(pr.Owner, pr.Repo, *pr.Number, comment)
+}
+
+// CreateIssueComment adds a comment thread to the pull request with the given number
+func (p *AzureDevOpsProvider) CreateIssueComment(owner string, repo string, number int, comment string) error {
+	u, _, err := p.repositoryAPIURL(owner, repo, util.UrlJoin("pullrequests", strconv.Itoa(number), "threads"), nil)
+	if err != nil {
+		return err
+	}
+	body := map[string]interface{}{
+		"comments": []map[string]interface{}{
+			{
+				"parentCommentId": 0,
+				"content":         comment,
+				"commentType":     1,
+			},
+		},
+		"status": 1,
+	}
+	err = p.do(http.MethodPost, u, body, nil)
+	if err != nil {
+		return errors.Wrapf(err, "commenting on pull request %d on %s/%s", number, owner, repo)
+	}
+	return nil
+}
+
+func (p *AzureDevOpsProvider) UpdateRelease(owner string, repo string, tag string, releaseInfo *GitRelease) error {
+	log.Logger().Warn("Azure DevOps does not support releases")
+	return nil
+}
+
+// UpdateReleaseStatus is not supported for this git provider
+func (p *AzureDevOpsProvider) UpdateReleaseStatus(owner string, repo string, tag string, releaseInfo *GitRelease) error {
+	log.Logger().Warn("Azure DevOps does not support releases")
+	return nil
+}
+
+func (p *AzureDevOpsProvider) ListReleases(org string, name string) ([]*GitRelease, error) {
+	log.Logger().Warn("Azure DevOps does not support releases")
+	return []*GitRelease{}, nil
+}
+
+// GetRelease is not supported for this git provider
+func (p *AzureDevOpsProvider) GetRelease(org string, name string, tag string) (*GitRelease, error) {
+	log.Logger().Warn("Azure DevOps does not support releases")
+	return nil, nil
+}
+
+// GetLatestRelease is not supported for this git provider
+func (p *AzureDevOpsProvider) GetLatestRelease(org string, name string) (*GitRelease, error) {
+	log.Logger().Warn("Azure DevOps does not support releases")
+	return nil, nil
+}
+
+// UploadReleaseAsset is not supported for this git provider
+func (p *AzureDevOpsProvider) UploadReleaseAsset(org string, repo string, id int64, name string, asset *os.File) (*GitReleaseAsset, error) {
+	log.Logger().Warn("Azure DevOps does not support releases")
+	return nil, nil
+}
+
+// GetContent returns the content of the file at the given path and ref
+func (p *AzureDevOpsProvider) GetContent(org string, name string, path string, ref string) (*GitFileContent, error) {
+	query := url.Values{}
+	query.Set("path", path)
+	query.Set("includeContent", "true")
+	if ref != "" {
+		query.Set("versionDescriptor.version", ref)
+	}
+	u, _, err := p.repositoryAPIURL(org, name, "items", query)
+	if err != nil {
+		return nil, err
+	}
+	item := azureItem{}
+	err = p.do(http.MethodGet, u, nil, &item)
+	if err != nil {
+		return nil, errors.Wrapf(err, "getting the content of %s in %s/%s", path, org, name)
+	}
+	return &GitFileContent{
+		Type:    item.GitObjectType,
+		Size:    len(item.Content),
+		Name:    item.Path[strings.LastIndex(item.Path, "/")+1:],
+		Path:    item.Path,
+		Content: item.Content,
+		Sha:     item.ObjectID,
+		Url:     item.URL,
+	}, nil
+}
+
+// JenkinsWebHookPath returns the path of the Jenkins git plugin notify commit hook
+func (p *AzureDevOpsProvider) JenkinsWebHookPath(gitURL string, secret string) string {
+	return "/git/notifyCommit?url=" + url.QueryEscape(gitURL)
+}
+
+func (p *AzureDevOpsProvider) Label() string {
+	return p.Server.Label()
+}
+
+func (p *AzureDevOpsProvider) ServerURL() string {
+	return p.Server.URL
+}
+
+// BranchArchiveURL returns the URL to download a zip of the branch
+func (p *AzureDevOpsProvider) BranchArchiveURL(org string, name string, branch string) string {
+	query := url.Values{}
+	query.Set("$format", "zip")
+	query.Set("versionDescriptor.version", branch)
+	query.Set("download", "true")
+	u, _, err := p.repositoryAPIURL(org, name, "items", query)
+	if err != nil {
+		return ""
+	}
+	return u
+}
+
+func (p *AzureDevOpsProvider) CurrentUsername() string {
+	return p.Username
+}
+
+func (p *AzureDevOpsProvider) UserAuth() auth.UserAuth {
+	return p.User
+}
+
+func (p *AzureDevOpsProvider) UserInfo(username string) *GitUser {
+	return &GitUser{
+		Login: username,
+	}
+}
+
+func (p *AzureDevOpsProvider) AddCollaborator(user string, organisation string, repo string) error {
+	log.Logger().Infof("Automatically adding the pipeline user as a collaborator is currently not implemented for Azure DevOps. Please add user: %v as a contributor to the project of this repository.", user)
+	return nil
+}
+
+func (p *AzureDevOpsProvider) ListInvitations() ([]*github.RepositoryInvitation, *github.Response, error) {
+	log.Logger().Infof("Automatically adding the pipeline user as a collaborator is currently not implemented for Azure DevOps.")
+	return []*github.RepositoryInvitation{}, &github.Response{}, nil
+}
+
+func (p *AzureDevOpsProvider) AcceptInvitation(ID int64) (*github.Response, error) {
+	log.Logger().Infof("Automatically adding the pipeline user as a collaborator is currently not implemented for Azure DevOps.")
+	return &github.Response{}, nil
+}
+
+// ShouldForkForPullRequest returns false as pull requests are created from branches in the same repository
+func (p *AzureDevOpsProvider) ShouldForkForPullRequest(originalOwner string, repoName string, username string) bool {
+	return false
+}
+
+// AddLabelsToIssue adds labels to the pull request with the given number
+func (p *AzureDevOpsProvider) AddLabelsToIssue(owner string, repo string, number int, labels []string) error {
+	u, _, err := p.repositoryAPIURL(owner, repo, util.UrlJoin("pullrequests", strconv.Itoa(number), "labels"), nil)
+	if err != nil {
+		return err
+	}
+	for _, label := range labels {
+		err = p.do(http.MethodPost, u, azureLabel{Name: label}, nil)
+		if err != nil {
+			return errors.Wrapf(err, "adding label %s to pull request %d on %s/%s", label, number, owner, repo)
+		}
+	}
+	return nil
+}
+
+// RequestReviewers adds the users, given by their unique names such as their email addresses, as reviewers of the
+// pull request
+func (p *AzureDevOpsProvider) RequestReviewers(pr *GitPullRequest, reviewers []string) error {
+	return p.addReviewers(pr, reviewers)
+}
+
+// RequestTeamReviewers adds the teams as reviewers of the pull request. Teams are identities in Azure DevOps so are
+// added like users
+func (p *AzureDevOpsProvider) RequestTeamReviewers(pr *GitPullRequest, teams []string) error {
+	return p.addReviewers(pr, teams)
+}
+
+func (p *AzureDevOpsProvider) addReviewers(pr *GitPullRequest, names []string) error {
+	if pr.Number == nil {
+		return fmt.Errorf("missing the number of pull request %s", pr.URL)
+	}
+	org, _ := splitAzureDevOpsOwner(pr.Owner)
+	for _, name := range names {
+		id, err := p.findIdentityID(org, name)
+		if err != nil {
+			return err
+		}
+		u, _, err := p.repositoryAPIURL(pr.Owner, pr.Repo, util.UrlJoin("pullrequests", strconv.Itoa(*pr.Number), "reviewers", id), nil)
+		if err != nil {
+			return err
+		}
+		err = p.do(http.MethodPut, u, map[string]int{"vote": 0}, nil)
+		if err != nil {
+			return errors.Wrapf(err, "adding reviewer %s to pull request %d on %s/%s", name, *pr.Number, pr.Owner, pr.Repo)
+		}
+	}
+	return nil
+}
+
+// findIdentityID returns the ID of the user, team or group of the organisation with the given name
+func (p *AzureDevOpsProvider) findIdentityID(org string, name string) (string, error) {
+	query := url.Values{}
+	query.Set("searchFilter", "General")
+	query.Set("filterValue", name)
+	identities := []azureIdentity{}
+	_, err := p.list(p.identitiesURL(org, query), &identities)
+	if err != nil {
+		return "", errors.Wrapf(err, "looking up the identity %s in %s", name, org)
+	}
+	switch len(identities) {
+	case 0:
+		return "", fmt.Errorf("no identity %s found in %s", name, org)
+	case 1:
+		return identities[0].ID, nil
+	default:
+		return "", fmt.Errorf("%d identities named %s found in %s", len(identities), name, org)
+	}
+}
+
+// identitiesURL returns the URL of the identities API of the organisation, which Azure DevOps Services hosts on the
+// vssps service and Azure DevOps Server hosts on the collection
+func (p *AzureDevOpsProvider) identitiesURL(org string, query url.Values) string {
+	answer := p.apiURL(org, "", "identities", query)
+	u, err := url.Parse(answer)
+	if err != nil {
+		return answer
+	}
+	host := strings.ToLower(u.Host)
+	switch {
+	case host == "dev.azure.com":
+		u.Host = "vssps.dev.azure.com"
+	case strings.HasSuffix(host, ".visualstudio.com") && !strings.HasSuffix(host, ".vssps.visualstudio.com"):
+		u.Host = strings.TrimSuffix(host, ".visualstudio.com") + ".vssps.visualstudio.com"
+	}
+	return u.String()
+}
+
+// GetBranch returns the branch with the commit at its tip
+func (p *AzureDevOpsProvider) GetBranch(owner string, repo string, branch string) (*GitBranch, error) {
+	query := url.Values{}
+	query.Set("filter", "heads/"+branch)
+	u, _, err := p.repositoryAPIURL(owner, repo, "refs", query)
+	if err != nil {
+		return nil, err
+	}
+	refs := []azureRef{}
+	_, err = p.list(u, &refs)
+	if err != nil {
+		if isAzureDevOpsNotFound(err) {
+			return nil, nil
+		}
+		return nil, errors.Wrapf(err, "getting branch %s of %s/%s", branch, owner, repo)
+	}
+	for _, ref := range refs {
+		if ref.Name == "refs/heads/"+branch {
+			return &GitBranch{
+				Name: branch,
+				Commit: &GitCommit{
+					SHA:    ref.ObjectID,
+					Branch: branch,
+				},
+				Protected: ref.IsLocked,
+			}, nil
+		}
+	}
+	return nil, nil
+}
+
+// GetProjects is not supported as Azure Boards work items are not git projects
+func (p *AzureDevOpsProvider) GetProjects(owner string, repo string) ([]GitProject, error) {
+	return nil, nil
+}
+
+// ConfigureFeatures is not supported for this git provider
+func (p *AzureDevOpsProvider) ConfigureFeatures(owner string, repo string, issues *bool, projects *bool, wikis *bool) (*GitRepository, error) {
+	return p.GetRepository(owner, repo)
+}
+
+// IsWikiEnabled returns true as every Azure DevOps project has a wiki
+func (p *AzureDevOpsProvider) IsWikiEnabled(owner string, repo string) (bool, error) {
+	return true, nil
+}
@@ -0,0 +1,137 @@
+// +build unit
+
+package gits_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"testing"
+
+	"github.com/jenkins-x/jx/v2/pkg/auth"
+	"github.com/jenkins-x/jx/v2/pkg/gits"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const azureDevOpsRepositories = `{"count": 1, "value": [{
+  "id": "repo-id", "name": "test-repo",
+  "remoteUrl": "https://dev.azure.com/test-org/test-project/_git/test-repo",
+  "webUrl": "https://dev.azure.com/test-org/test-project/_git/test-repo",
+  "project": {"id": "project-id", "name": "test-project"}
+}]}`
+
+const azureDevOpsPullRequest = `{
+  "pullRequestId": 7, "status": "active", "title": "my title", "mergeStatus": "succeeded",
+  "sourceRefName": "refs/heads/my-branch", "targetRefName": "refs/heads/master",
+  "lastMergeSourceCommit": {"commitId": "abc123"},
+  "repository": {"id": "repo-id", "name": "test-repo", "webUrl": "https://dev.azure.com/test-org/test-project/_git/test-repo"},
+  "labels": [{"name": "updatebot"}]
+}`
+
+func TestAzureDevOpsProviderPullRequests(t *testing.T) {
+	t.Parallel()
+
+	var createdPR map[string]interface{}
+	var labels []string
+	mux := http.NewServeMux()
+	repositories := func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(azureDevOpsRepositories)) //nolint:errcheck
+	}
+	mux.HandleFunc("/test-org/_apis/git/repositories", repositories)
+	mux.HandleFunc("/test-org/test-project/_apis/git/repositories", repositories)
+	mux.HandleFunc("/test-org/project-id/_apis/git/repositories/repo-id/pullrequests", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			data, _ := ioutil.ReadAll(r.Body)
+			json.Unmarshal(data, &createdPR)        //nolint:errcheck
+			w.Write([]byte(azureDevOpsPullRequest)) //nolint:errcheck
+			return
+		}
+		assert.Equal(t, "active", r.URL.Query().Get("searchCriteria.status"))
+		w.Write([]byte(`{"count": 1, "value": [` + azureDevOpsPullRequest + `]}`)) //nolint:errcheck
+	})
+	mux.HandleFunc("/test-org/project-id/_apis/git/repositories/repo-id/pullrequests/7/labels", func(w http.ResponseWriter, r *http.Request) {
+		label := map[string]string{}
+		data, _ := ioutil.ReadAll(r.Body)
+		json.Unmarshal(data, &label) //nolint:errcheck
+		labels = append(labels, label["name"])
+		w.Write([]byte(`{}`)) //nolint:errcheck
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	provider, err := gits.NewAzureDevOpsProvider(&auth.AuthServer{URL: server.URL, Kind: gits.KindAzureDevOps}, &auth.UserAuth{Username: "test-user", ApiToken: "test-token"}, nil)
+	require.NoError(t, err)
+
+	gitInfo, err := gits.ParseGitURL("https://dev.azure.com/test-org/test-project/_git/test-repo")
+	require.NoError(t, err)
+	assert.Equal(t, "test-project", gitInfo.Project)
+
+	pr, err := provider.CreatePullRequest(&gits.GitPullRequestArguments{
+		GitRepository: gitInfo,
+		Title:         "my title",
+		Head:          "my-branch",
+		Base:          "master",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "refs/heads/my-branch", createdPR["sourceRefName"])
+	assert.Equal(t, "refs/heads/master", createdPR["targetRefName"])
+	assert.Equal(t, 7, util.DereferenceInt(pr.Number))
+	assert.Equal(t, "abc123", pr.LastCommitSha)
+	assert.Equal(t, "https://dev.azure.com/test-org/test-project/_git/test-repo/pullrequest/7", pr.URL)
+	assert.Equal(t, "open", util.DereferenceString(pr.State))
+
+	err = provider.AddLabelsToIssue(pr.Owner, pr.Repo, 7, []string{"updatebot"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"updatebot"}, labels)
+
+	prs, err := gits.FilterOpenPullRequests(provider, "test-org", "test-repo", gits.PullRequestFilter{Labels: []string{"updatebot"}})
+	require.NoError(t, err)
+	require.Len(t, prs, 1)
+	assert.Equal(t, "my-branch", util.DereferenceString(prs[0].HeadRef))
+}
+
+func TestAzureDevOpsProviderRequestReviewers(t *testing.T) {
+	t.Parallel()
+
+	reviewers := []string{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/test-org/_apis/git/repositories", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(azureDevOpsRepositories)) //nolint:errcheck
+	})
+	mux.HandleFunc("/test-org/_apis/identities", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "General", r.URL.Query().Get("searchFilter"))
+		switch r.URL.Query().Get("filterValue") {
+		case "jdoe@example.com":
+			w.Write([]byte(`{"count": 1, "value": [{"id": "user-id"}]}`)) //nolint:errcheck
+		case "[test-project]\\reviewers":
+			w.Write([]byte(`{"count": 1, "value": [{"id": "team-id"}]}`)) //nolint:errcheck
+		default:
+			w.Write([]byte(`{"count": 0, "value": []}`)) //nolint:errcheck
+		}
+	})
+	mux.HandleFunc("/test-org/project-id/_apis/git/repositories/repo-id/pullrequests/7/reviewers/", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPut, r.Method)
+		reviewers = append(reviewers, path.Base(r.URL.Path))
+		w.Write([]byte(`{}`)) //nolint:errcheck
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	provider, err := gits.NewAzureDevOpsProvider(&auth.AuthServer{URL: server.URL, Kind: gits.KindAzureDevOps}, &auth.UserAuth{Username: "test-user", ApiToken: "test-token"}, nil)
+	require.NoError(t, err)
+
+	number := 7
+	pr := &gits.GitPullRequest{Owner: "test-org", Repo: "test-repo", Number: &number}
+	err = provider.(gits.PullRequestReviewerRequester).RequestReviewers(pr, []string{"jdoe@example.com"})
+	require.NoError(t, err)
+	err = provider.(gits.PullRequestTeamReviewerRequester).RequestTeamReviewers(pr, []string{"[test-project]\\reviewers"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"user-id", "team-id"}, reviewers)
+
+	err = provider.(gits.PullRequestReviewerRequester).RequestReviewers(pr, []string{"unknown"})
+	assert.Error(t, err, "an unknown reviewer should fail")
+}
@@ -55,6 +55,7 @@ func NewBitbucketCloudProvider(server *auth.AuthServer, user *auth.UserAuth, git
 	}
 
 	cfg := bitbucket.NewConfiguration()
+	cfg.HTTPClient = NewAPIClient(0)
 	provider.Client = bitbucket.NewAPIClient(cfg)
 
 	return &provider, nil
@@ -325,10 +326,13 @@ func (b *BitbucketCloudProvider) CreatePullRequest(
 		Branch: &base,
 	}
 
+	// bitbucket has no labels so they are stored in the description
+	body := PullRequestBodyWithLabels(data.Body, data.Labels)
 	bPullrequest := bitbucket.Pullrequest{
 		Source:      &source,
 		Destination: &destination,
 		Title:       data.Title,
+		Summary:     &bitbucket.IssueContent{Raw: body},
 	}
 
 	var options = map[string]interface{}{
@@ -381,6 +385,9 @@ func (b *BitbucketCloudProvider) CreatePullRequest(
 		Repo:   pr.Destination.Repository.Name,
 		Number: prID,
 		State:  &pr.State,
+		Title:  data.Title,
+		Body:   body,
+		Labels: pullRequestLabels(body),
 	}
 
 	return newPR, nil
@@ -388,7 +395,90 @@ func (b *BitbucketCloudProvider) CreatePullRequest(
 
 // UpdatePullRequest updates pull request number with data
 func (b *BitbucketCloudProvider) UpdatePullRequest(data *GitPullRequestArguments, number int) (*GitPullRequest, error) {
-	return nil, errors.Errorf("Not yet implemented for bitbucket")
+	owner := data.GitRepository.Organisation
+	if owner == "" {
+		owner = b.Username
+	}
+	body := PullRequestBodyWithLabels(data.Body, data.Labels)
+	pr, err := b.updatePullRequest(owner, data.GitRepository.Name, number, data.Title, body)
+	if err != nil {
+		return nil, err
+	}
+	return b.toPullRequest(pr, number), nil
+}
+
+// updatePullRequest updates the title and description of a pull request
+func (b *BitbucketCloudProvider) updatePullRequest(owner string, repo string, number int, title string, body string) (bitbucket.Pullrequest, error) {
+	options := map[string]interface{}{
+		"body": bitbucket.Pullrequest{
+			Title:   title,
+			Summary: &bitbucket.IssueContent{Raw: body},
+		},
+	}
+	pr, _, err := b.Client.PullrequestsApi.RepositoriesUsernameRepoSlugPullrequestsPullRequestIdPut(
+		b.Context,
+		owner,
+		repo,
+		int32(number),
+		options,
+	)
+	if err != nil {
+		return pr, errors.Wrapf(err, "updating pull request %d on %s/%s", number, owner, repo)
+	}
+	return pr, nil
+}
+
+// RequestReviewers adds the users, given by their user names or {uuid}s, as reviewers of the pull request. Bitbucket
+// Cloud has no teams of reviewers nor assignees of pull requests
+func (b *BitbucketCloudProvider) RequestReviewers(pr *GitPullRequest, reviewers []string) error {
+	if pr.Number == nil {
+		return fmt.Errorf("missing the number of pull request %s", pr.URL)
+	}
+	prID := int32(*pr.Number)
+	bitbucketPR, _, err := b.Client.PullrequestsApi.RepositoriesUsernameRepoSlugPullrequestsPullRequestIdGet(
+		b.Context,
+		pr.Owner,
+		pr.Repo,
+		prID,
+	)
+	if err != nil {
+		return errors.Wrapf(err, "getting pull request %d on %s/%s", prID, pr.Owner, pr.Repo)
+	}
+	// the reviewers replace the existing ones so those need to be kept
+	users := bitbucketPR.Reviewers
+	for _, reviewer := range reviewers {
+		user := bitbucket.User{Username: reviewer}
+		if strings.HasPrefix(reviewer, "{") {
+			user = bitbucket.User{Uuid: reviewer}
+		}
+		found := false
+		for _, existing := range users {
+			if (user.Username != "" && existing.Username == user.Username) || (user.Uuid != "" && existing.Uuid == user.Uuid) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			users = append(users, user)
+		}
+	}
+	options := map[string]interface{}{
+		"body": bitbucket.Pullrequest{
+			Title:     bitbucketPR.Title,
+			Reviewers: users,
+		},
+	}
+	_, _, err = b.Client.PullrequestsApi.RepositoriesUsernameRepoSlugPullrequestsPullRequestIdPut(
+		b.Context,
+		pr.Owner,
+		pr.Repo,
+		prID,
+		options,
+	)
+	if err != nil {
+		return errors.Wrapf(err, "requesting reviews of pull request %d on %s/%s from %s", prID, pr.Owner, pr.Repo, strings.Join(reviewers, ", "))
+	}
+	return nil
 }
 
 func (b *BitbucketCloudProvider) UpdatePullRequestStatus(pr *GitPullRequest) error {
@@ -471,6 +561,21 @@ func (b *BitbucketCloudProvider) toPullRequest(pr bitbucket.Pullrequest, number
 		Number: &number,
 		State:  &pr.State,
 		Author: author,
+		Title:  pr.Title,
+		Labels: []*Label{},
+	}
+	if pr.Summary != nil {
+		answer.Body = pr.Summary.Raw
+		answer.Labels = pullRequestLabels(pr.Summary.Raw)
+	}
+	if pr.Source != nil {
+		if pr.Source.Branch != nil {
+			answer.HeadRef = &pr.Source.Branch.Name
+		}
+		if pr.Source.Repository != nil {
+			headOwner := strings.Split(pr.Source.Repository.FullName, "/")[0]
+			answer.HeadOwner = &headOwner
+		}
 	}
 	return answer
 }
@@ -1071,9 +1176,28 @@ func (b *BitbucketCloudProvider) ListCommits(owner, repo string, opt *ListCommit
 }
 
 // AddLabelsToIssue adds labels to issues or pullrequests
+// Bitbucket has no labels so they are only supported on pull requests where they are stored in the description
 func (b *BitbucketCloudProvider) AddLabelsToIssue(owner, repo string, number int, labels []string) error {
-	log.Logger().Warn("Adding labels to issues or pullrequest not implemented/supported on bitbucket cloud")
-	return nil
+	pr, _, err := b.Client.PullrequestsApi.RepositoriesUsernameRepoSlugPullrequestsPullRequestIdGet(
+		b.Context,
+		owner,
+		repo,
+		int32(number),
+	)
+	if err != nil {
+		log.Logger().Warnf("Adding labels to issues is not supported on bitbucket cloud, no pull request %d found on %s/%s", number, owner, repo)
+		return nil
+	}
+	body := ""
+	if pr.Summary != nil {
+		body = pr.Summary.Raw
+	}
+	newBody := PullRequestBodyWithLabels(body, labels)
+	if newBody == body {
+		return nil
+	}
+	_, err = b.updatePullRequest(owner, repo, number, pr.Title, newBody)
+	return err
 }
 
 // GetLatestRelease fetches the latest release from the git provider for org and name
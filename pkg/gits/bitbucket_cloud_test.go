@@ -3,6 +3,8 @@
 package gits_test
 
 import (
+	"encoding/json"
+	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -10,6 +12,8 @@ import (
 	"github.com/jenkins-x/jx/v2/pkg/auth"
 	"github.com/jenkins-x/jx/v2/pkg/gits"
 	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
 	bitbucket "github.com/wbrefvem/go-bitbucket"
 )
@@ -516,3 +520,46 @@ func TestBitbucketCloudProviderTestSuite(t *testing.T) {
 func (suite *BitbucketCloudProviderTestSuite) TearDownSuite() {
 	suite.server.Close()
 }
+
+func TestBitbucketCloudProviderRequestReviewers(t *testing.T) {
+	t.Parallel()
+
+	var update map[string]interface{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repositories/test-user/test-repo/pullrequests/1", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPut {
+			data, _ := ioutil.ReadAll(r.Body)
+			json.Unmarshal(data, &update) //nolint:errcheck
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id": 1, "title": "my title", "reviewers": [{"username": "existing"}]}`)) //nolint:errcheck
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	gp, err := setupGitProvider(server.URL, "Test Auth Server", "test-user")
+	require.NoError(t, err)
+	provider := gp.(*gits.BitbucketCloudProvider)
+	cfg := bitbucket.NewConfiguration()
+	cfg.BasePath = server.URL
+	provider.Client = bitbucket.NewAPIClient(cfg)
+
+	number := 1
+	pr := &gits.GitPullRequest{Owner: "test-user", Repo: "test-repo", Number: &number}
+	err = provider.RequestReviewers(pr, []string{"existing", "jdoe", "{b8a64e3f-2f3c-4a8e-9d2d-0c3b6a3c1f6e}"})
+	require.NoError(t, err)
+
+	require.NotNil(t, update)
+	assert.Equal(t, "my title", update["title"])
+	reviewers := []string{}
+	for _, reviewer := range update["reviewers"].([]interface{}) {
+		user := reviewer.(map[string]interface{})
+		if username, ok := user["username"].(string); ok && username != "" {
+			reviewers = append(reviewers, username)
+		} else {
+			reviewers = append(reviewers, user["uuid"].(string))
+		}
+	}
+	assert.Equal(t, []string{"existing", "jdoe", "{b8a64e3f-2f3c-4a8e-9d2d-0c3b6a3c1f6e}"}, reviewers,
+		"the existing reviewers should be kept")
+}
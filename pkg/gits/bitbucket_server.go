@@ -1,9 +1,12 @@
 package gits
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
+	"io/ioutil"
+	"net/http"
 	"net/url"
 	"os"
 	"strconv"
@@ -120,6 +123,7 @@ func NewBitbucketServerProvider(server *auth.AuthServer, user *auth.UserAuth, gi
 	}
 
 	cfg := bitbucket.NewConfiguration(server.URL + "/rest")
+	cfg.HTTPClient = NewAPIClient(0)
 	provider.Client = bitbucket.NewAPIClient(apiKeyAuthContext, cfg)
 
 	return &provider, nil
@@ -371,8 +375,9 @@ func (b *BitbucketServerProvider) CreatePullRequest(data *GitPullRequestArgument
 	}
 	var bPullRequest, bPR bitbucket.PullRequest
 	var options = map[string]interface{}{
-		"title":       data.Title,
-		"description": data.Body,
+		"title": data.Title,
+		// bitbucket has no labels so they are stored in the description
+		"description": PullRequestBodyWithLabels(data.Body, data.Labels),
 		"state":       "OPEN",
 		"open":        true,
 		"closed":      false,
@@ -436,12 +441,120 @@ func (b *BitbucketServerProvider) CreatePullRequest(data *GitPullRequestArgument
 		Number: &bPR.ID,
 		State:  &bPR.State,
 		Title:  bPR.Title,
+		Body:   bPR.Description,
+		Labels: pullRequestLabels(bPR.Description),
 	}, nil
 }
 
 // UpdatePullRequest updates pull request number with data
 func (b *BitbucketServerProvider) UpdatePullRequest(data *GitPullRequestArguments, number int) (*GitPullRequest, error) {
-	return nil, errors.Errorf("Not yet implemented for bitbucket")
+	projectKey := strings.ToUpper(data.GitRepository.Organisation)
+	if projectKey == "" {
+		projectKey = strings.ToUpper(data.GitRepository.Project)
+	}
+	bPR, err := b.getPullRequest(projectKey, data.GitRepository.Name, number)
+	if err != nil {
+		return nil, err
+	}
+	bPR, err = b.updatePullRequest(projectKey, data.GitRepository.Name, bPR, map[string]interface{}{
+		"title":       data.Title,
+		"description": PullRequestBodyWithLabels(data.Body, data.Labels),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return b.toPullRequest(bPR)
+}
+
+func (b *BitbucketServerProvider) getPullRequest(projectKey string, repo string, number int) (*bitbucket.PullRequest, error) {
+	apiResponse, err := b.Client.DefaultApi.GetPullRequest(projectKey, repo, number)
+	if err != nil {
+		return nil, errors.Wrapf(err, "getting pull request %d on %s/%s", number, projectKey, repo)
+	}
+	var bPR bitbucket.PullRequest
+	err = mapstructure.Decode(apiResponse.Values, &bPR)
+	if err != nil {
+		return nil, err
+	}
+	return &bPR, nil
+}
+
+// updatePullRequest updates the fields, such as the title, description and reviewers, of a pull request. The API
+// client does not support this so we call the REST API directly
+func (b *BitbucketServerProvider) updatePullRequest(projectKey string, repo string, bPR *bitbucket.PullRequest, fields map[string]interface{}) (*bitbucket.PullRequest, error) {
+	body := map[string]interface{}{
+		"version": bPR.Version,
+	}
+	for k, v := range fields {
+		body[k] = v
+	}
+	requestBody, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	u := util.UrlJoin(b.Server.URL, "rest", "api", "1.0", "projects", projectKey, "repos", repo, "pull-requests", strconv.Itoa(bPR.ID))
+	req, err := http.NewRequest(http.MethodPut, u, bytes.NewReader(requestBody))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+b.User.ApiToken)
+	resp, err := NewAPIClient(time.Minute).Do(req)
+	if err != nil {
+		return nil, errors.Wrapf(err, "updating pull request %d on %s/%s", bPR.ID, projectKey, repo)
+	}
+	defer resp.Body.Close() //nolint:errcheck
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("updating pull request %d on %s/%s returned %s: %s", bPR.ID, projectKey, repo, resp.Status, string(data))
+	}
+	answer := &bitbucket.PullRequest{}
+	err = json.Unmarshal(data, answer)
+	if err != nil {
+		return nil, errors.Wrapf(err, "parsing the updated pull request %d on %s/%s", bPR.ID, projectKey, repo)
+	}
+	return answer, nil
+}
+
+// RequestReviewers adds the users, given by their user names, as reviewers of the pull request. Bitbucket Server has
+// no teams of reviewers nor assignees of pull requests
+func (b *BitbucketServerProvider) RequestReviewers(pr *GitPullRequest, reviewers []string) error {
+	if pr.Number == nil {
+		return fmt.Errorf("missing the number of pull request %s", pr.URL)
+	}
+	projectKey, repo := parseBitBucketServerURL(pr.URL)
+	bPR, err := b.getPullRequest(projectKey, repo, *pr.Number)
+	if err != nil {
+		return err
+	}
+	// the reviewers replace the existing ones so those need to be kept
+	names := []string{}
+	for _, reviewer := range bPR.Reviewers {
+		names = append(names, reviewer.User.Name)
+	}
+	for _, reviewer := range reviewers {
+		if util.StringArrayIndex(names, reviewer) < 0 {
+			names = append(names, reviewer)
+		}
+	}
+	users := []map[string]interface{}{}
+	for _, name := range names {
+		users = append(users, map[string]interface{}{
+			"user": map[string]string{"name": name},
+		})
+	}
+	_, err = b.updatePullRequest(projectKey, repo, bPR, map[string]interface{}{
+		"title":       bPR.Title,
+		"description": bPR.Description,
+		"reviewers":   users,
+	})
+	if err != nil {
+		return errors.Wrapf(err, "requesting reviews of pull request %d on %s/%s from %s", *pr.Number, projectKey, repo, strings.Join(reviewers, ", "))
+	}
+	return nil
 }
 
 func parseBitBucketServerURL(URL string) (string, string) {
@@ -570,6 +683,11 @@ func (b *BitbucketServerProvider) populatePullRequest(answer *GitPullRequest, bP
 	answer.LastCommitSha = bPR.FromRef.LatestCommit
 	answer.Title = bPR.Title
 	answer.Body = bPR.Description
+	answer.Labels = pullRequestLabels(bPR.Description)
+	answer.HeadRef = &bPR.FromRef.DisplayID
+	// the organisation of bitbucket server repositories is the lower case project key
+	headOwner := strings.ToLower(bPR.FromRef.Repository.Project.Key)
+	answer.HeadOwner = &headOwner
 
 	if bPR.State == "MERGED" {
 		merged := true
@@ -1276,9 +1394,20 @@ func (b *BitbucketServerProvider) ListCommits(owner, repoName string, opt *ListC
 }
 
 // AddLabelsToIssue adds labels to issues or pullrequests
+// Bitbucket has no labels so they are only supported on pull requests where they are stored in the description
 func (b *BitbucketServerProvider) AddLabelsToIssue(owner, repo string, number int, labels []string) error {
-	log.Logger().Warnf("Adding labels not supported on bitbucket server yet for repo %s/%s issue %d labels %v", owner, repo, number, labels)
-	return nil
+	projectKey := strings.ToUpper(owner)
+	bPR, err := b.getPullRequest(projectKey, repo, number)
+	if err != nil {
+		log.Logger().Warnf("Adding labels to issues is not supported on bitbucket server, no pull request %d found on %s/%s", number, owner, repo)
+		return nil
+	}
+	body := PullRequestBodyWithLabels(bPR.Description, labels)
+	if body == bPR.Description {
+		return nil
+	}
+	_, err = b.updatePullRequest(projectKey, repo, bPR, bPR.Title, body)
+	return err
 }
 
 // GetLatestRelease fetches the latest release from the git provider for org and name
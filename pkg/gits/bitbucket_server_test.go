@@ -4,6 +4,8 @@ package gits_test
 
 import (
 	"context"
+	"encoding/json"
+	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -12,6 +14,8 @@ import (
 	"github.com/jenkins-x/jx/v2/pkg/auth"
 	"github.com/jenkins-x/jx/v2/pkg/gits"
 	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
 )
 
@@ -448,3 +452,42 @@ func TestBitbucketServerProviderTestSuite(t *testing.T) {
 func (suite *BitbucketServerProviderTestSuite) TearDownSuite() {
 	suite.server.Close()
 }
+
+func TestBitbucketServerProviderRequestReviewers(t *testing.T) {
+	t.Parallel()
+
+	var update map[string]interface{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/rest/api/1.0/projects/TEST-ORG/repos/test-repo/pull-requests/1", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPut {
+			data, _ := ioutil.ReadAll(r.Body)
+			json.Unmarshal(data, &update) //nolint:errcheck
+		}
+		w.Write([]byte(`{"id": 1, "version": 3, "title": "my title", "description": "my description",
+			"reviewers": [{"user": {"name": "existing"}, "role": "REVIEWER"}]}`)) //nolint:errcheck
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	provider, err := gits.NewBitbucketServerProvider(&auth.AuthServer{URL: server.URL, Kind: gits.KindBitBucketServer},
+		&auth.UserAuth{Username: "test-user", ApiToken: "test-token"}, nil)
+	require.NoError(t, err)
+
+	number := 1
+	pr := &gits.GitPullRequest{
+		URL:    server.URL + "/projects/TEST-ORG/repos/test-repo/pull-requests/1",
+		Repo:   "test-repo",
+		Number: &number,
+	}
+	err = provider.(gits.PullRequestReviewerRequester).RequestReviewers(pr, []string{"jdoe", "existing"})
+	require.NoError(t, err)
+
+	require.NotNil(t, update)
+	assert.Equal(t, float64(3), update["version"])
+	assert.Equal(t, "my title", update["title"])
+	assert.Equal(t, "my description", update["description"])
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"user": map[string]interface{}{"name": "existing"}},
+		map[string]interface{}{"user": map[string]interface{}{"name": "jdoe"}},
+	}, update["reviewers"], "the existing reviewers should be kept")
+}
@@ -0,0 +1,53 @@
+package gits
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/jenkins-x/jx/v2/pkg/log"
+)
+
+// cloneCleanup records the contents of a directory before cloning into it so that only what a failed clone
+// created is removed
+type cloneCleanup struct {
+	dir     string
+	existed bool
+	entries map[string]bool
+}
+
+// newCloneCleanup records whether the directory exists and what it contains before a clone
+func newCloneCleanup(dir string) *cloneCleanup {
+	c := &cloneCleanup{
+		dir:     dir,
+		entries: map[string]bool{},
+	}
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return c
+	}
+	c.existed = true
+	for _, entry := range entries {
+		c.entries[entry.Name()] = true
+	}
+	return c
+}
+
+// clean removes the directory if the clone created it, otherwise any files the clone added to it
+func (c *cloneCleanup) clean() {
+	if !c.existed {
+		if err := os.RemoveAll(c.dir); err != nil {
+			log.Logger().Debugf("failed to clean up %s: %s", c.dir, err)
+		}
+		return
+	}
+	entries, _ := ioutil.ReadDir(c.dir)
+	for _, entry := range entries {
+		if c.entries[entry.Name()] {
+			continue
+		}
+		if err := os.RemoveAll(filepath.Join(c.dir, entry.Name())); err != nil {
+			log.Logger().Debugf("failed to clean up %s: %s", c.dir, err)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+// +build unit
+
+package gits
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	v1 "github.com/jenkins-x/jx/v2/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCloneCleanupKeepsExistingFiles(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "test-clone-cleanup")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	existing := filepath.Join(dir, "existing.txt")
+	err = ioutil.WriteFile(existing, []byte("keep"), util.DefaultWritePermissions)
+	require.NoError(t, err)
+
+	cleanup := newCloneCleanup(dir)
+
+	err = os.MkdirAll(filepath.Join(dir, ".git", "objects"), util.DefaultWritePermissions)
+	require.NoError(t, err)
+	cleanup.clean()
+
+	assert.FileExists(t, existing)
+	exists, err := util.DirExists(filepath.Join(dir, ".git"))
+	require.NoError(t, err)
+	assert.False(t, exists, "the directory created by the clone should be removed")
+}
+
+func TestCloneCleanupRemovesCreatedDir(t *testing.T) {
+	t.Parallel()
+
+	parent, err := ioutil.TempDir("", "test-clone-cleanup")
+	require.NoError(t, err)
+	defer os.RemoveAll(parent)
+
+	dir := filepath.Join(parent, "repo")
+	cleanup := newCloneCleanup(dir)
+
+	err = os.MkdirAll(filepath.Join(dir, ".git"), util.DefaultWritePermissions)
+	require.NoError(t, err)
+	cleanup.clean()
+
+	exists, err := util.DirExists(dir)
+	require.NoError(t, err)
+	assert.False(t, exists, "the directory created by the clone should be removed")
+}
+
+func TestCloneFromMirrorOnlyRemovesWhatTheMirrorCloneWrote(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "test-clone-mirror")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	existing := filepath.Join(dir, "existing.txt")
+	err = ioutil.WriteFile(existing, []byte("keep"), util.DefaultWritePermissions)
+	require.NoError(t, err)
+
+	mirrors := []v1.GitMirror{
+		{OriginURL: "https://github.com/", MirrorURL: "https://git.example.com/github/"},
+	}
+	var cloned []string
+	err = cloneFromMirror(nil, mirrors, "https://github.com/jenkins-x/jx.git", dir, func(gitURL string) error {
+		cloned = append(cloned, gitURL)
+		if len(cloned) == 1 {
+			err := ioutil.WriteFile(filepath.Join(dir, "partial"), []byte("partial"), util.DefaultWritePermissions)
+			require.NoError(t, err)
+			return errors.New("connection reset")
+		}
+		_, err := os.Stat(filepath.Join(dir, "partial"))
+		assert.True(t, os.IsNotExist(err), "the files written by the failed mirror clone should be removed")
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"https://git.example.com/github/jenkins-x/jx.git", "https://github.com/jenkins-x/jx.git"}, cloned)
+	assert.FileExists(t, existing)
+}
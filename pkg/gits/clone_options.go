@@ -0,0 +1,120 @@
+package gits
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+)
+
+// BlobNoneFilter the partial clone filter which clones all commits and trees but fetches file contents on demand
+const BlobNoneFilter = "blob:none"
+
+// CloneOptions the options used to reduce the amount of data cloned or fetched from large repositories
+type CloneOptions struct {
+	// Depth limits the history to the given number of commits, 0 clones the full history
+	Depth int
+	// Filter a partial clone filter such as blob:none
+	Filter string
+	// SparsePaths if specified only these paths are checked out
+	SparsePaths []string
+	// Branch the branch or tag to check out, defaults to the default branch of the remote
+	Branch string
+	// Bare creates a bare repository without a working tree
+	Bare bool
+}
+
+// PartialCloneOptions returns the options for a partial clone which defers fetching file contents until they are
+// checked out while keeping the full history and tags available
+func PartialCloneOptions() *CloneOptions {
+	return &CloneOptions{
+		Filter: BlobNoneFilter,
+	}
+}
+
+// fetchArgs returns the arguments shared by git clone and git fetch
+func (o *CloneOptions) fetchArgs() []string {
+	args := []string{}
+	if o == nil {
+		return args
+	}
+	if o.Depth > 0 {
+		args = append(args, fmt.Sprintf("--depth=%d", o.Depth))
+	}
+	if o.Filter != "" {
+		args = append(args, fmt.Sprintf("--filter=%s", o.Filter))
+	}
+	return args
+}
+
+// CloneWithOptions clones the given git URL into the given directory using the options to limit the history,
+// blobs or paths which are cloned
+func (g *GitCLI) CloneWithOptions(url string, dir string, options *CloneOptions) error {
+	return cloneFromMirror(g, g.mirrors, url, dir, func(gitURL string) error {
+		return g.cloneWithOptions(gitURL, dir, options)
+	})
+}
+
+func (g *GitCLI) cloneWithOptions(url string, dir string, options *CloneOptions) error {
+	if options == nil {
+		options = &CloneOptions{}
+	}
+	sparse := len(options.SparsePaths) > 0
+	if sparse && options.Bare {
+		return errors.Errorf("cannot use a sparse checkout with a bare clone")
+	}
+	args := []string{"clone"}
+	if options.Bare {
+		args = append(args, "--bare")
+	}
+	if options.Branch != "" {
+		args = append(args, "--branch", options.Branch)
+	}
+	if sparse {
+		args = append(args, "--no-checkout")
+	}
+	args = append(args, options.fetchArgs()...)
+	args = append(args, url, dir)
+	err := g.networkCmd("", args...)
+	if err != nil {
+		return errors.Wrapf(err, "running git %s", strings.Join(args, " "))
+	}
+	if sparse {
+		return g.sparseCheckout(dir, options.SparsePaths)
+	}
+	return nil
+}
+
+// FetchBranchWithOptions fetches the refspecs from the repo using the options to limit the history or blobs fetched
+func (g *GitCLI) FetchBranchWithOptions(dir string, repo string, options *CloneOptions, refspecs ...string) error {
+	args := append([]string{"fetch", repo}, options.fetchArgs()...)
+	args = append(args, refspecs...)
+	return errors.WithStack(g.networkCmd(dir, args...))
+}
+
+// sparseCheckout populates the working tree of a clone made with --no-checkout with only the given paths
+func (g *GitCLI) sparseCheckout(dir string, paths []string) error {
+	err := g.gitCmd(dir, "config", "core.sparseCheckout", "true")
+	if err != nil {
+		return errors.Wrapf(err, "enabling sparse checkout in %s", dir)
+	}
+	infoDir := filepath.Join(dir, ".git", "info")
+	err = os.MkdirAll(infoDir, util.DefaultWritePermissions)
+	if err != nil {
+		return errors.Wrapf(err, "creating %s", infoDir)
+	}
+	patterns := strings.Join(paths, "\n") + "\n"
+	err = ioutil.WriteFile(filepath.Join(infoDir, "sparse-checkout"), []byte(patterns), util.DefaultWritePermissions)
+	if err != nil {
+		return errors.Wrapf(err, "writing the sparse checkout paths in %s", dir)
+	}
+	err = g.gitCmd(dir, "read-tree", "-mu", "HEAD")
+	if err != nil {
+		return errors.Wrapf(err, "checking out %s in %s", strings.Join(paths, ", "), dir)
+	}
+	return nil
+}
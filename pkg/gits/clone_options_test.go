@@ -0,0 +1,47 @@
+// +build unit
+
+package gits_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jenkins-x/jx/v2/pkg/gits"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCloneWithSparsePaths(t *testing.T) {
+	t.Parallel()
+
+	tmpDir, err := ioutil.TempDir("", "test-clone-options")
+	require.NoError(t, err)
+	defer os.RemoveAll(tmpDir) //nolint:errcheck
+
+	repoDir := filepath.Join(tmpDir, "repo")
+	goGit := gits.NewGoGit()
+	require.NoError(t, goGit.Init(repoDir))
+	writeTestGitConfig(t, repoDir)
+	for _, name := range []string{"env/values.yaml", "docs/README.md"} {
+		path := filepath.Join(repoDir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), util.DefaultWritePermissions))
+		require.NoError(t, ioutil.WriteFile(path, []byte(name), 0600))
+	}
+	require.NoError(t, goGit.Add(repoDir, "-A"))
+	require.NoError(t, goGit.CommitDir(repoDir, "initial commit"))
+
+	cloneDir := filepath.Join(tmpDir, "clone")
+	err = gits.NewGitCLI().CloneWithOptions("file://"+repoDir, cloneDir, &gits.CloneOptions{
+		Depth:       1,
+		Filter:      gits.BlobNoneFilter,
+		SparsePaths: []string{"env/"},
+	})
+	require.NoError(t, err)
+
+	assert.FileExists(t, filepath.Join(cloneDir, "env", "values.yaml"))
+	_, err = os.Stat(filepath.Join(cloneDir, "docs", "README.md"))
+	assert.True(t, os.IsNotExist(err), "docs/README.md should not be checked out")
+}
@@ -0,0 +1,118 @@
+package gits
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/jenkins-x/jx/v2/pkg/config"
+	"github.com/jenkins-x/jx/v2/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+)
+
+const (
+	gnupgHomeEnvVar = "GNUPGHOME"
+
+	commitSigningGPGHomeDir = "gnupg"
+	commitSigningSSHKeyFile = "commit-signing-key"
+)
+
+// CommitSigning the key used to sign the commits made by a Gitter
+type CommitSigning struct {
+	// Format the kind of key
+	Format config.CommitSigningFormat
+	// Key the ASCII armored GPG private key or the SSH private key
+	Key string
+	// KeyID the ID of the GPG key to sign with, defaults to the key of the committer email
+	KeyID string
+}
+
+// CommitSigner is implemented by Gitters which can sign the commits they make
+type CommitSigner interface {
+	// ConfigureCommitSigning makes all subsequent commits signed with the given key
+	ConfigureCommitSigning(signing *CommitSigning) error
+}
+
+// ConfigureCommitSigning makes all subsequent commits, including merges and cherry picks, signed with the given key
+func (g *GitCLI) ConfigureCommitSigning(signing *CommitSigning) error {
+	if strings.TrimSpace(signing.Key) == "" {
+		return errors.New("no commit signing key specified")
+	}
+	configDir, err := util.ConfigDir()
+	if err != nil {
+		return errors.Wrap(err, "finding the jx config dir")
+	}
+	switch signing.Format {
+	case config.CommitSigningFormatGPG, "":
+		return g.configureGPGSigning(filepath.Join(configDir, commitSigningGPGHomeDir), signing)
+	case config.CommitSigningFormatSSH:
+		return g.configureSSHSigning(filepath.Join(configDir, commitSigningSSHKeyFile), signing)
+	default:
+		return fmt.Errorf("unknown commit signing format %s, must be one of %s", signing.Format,
+			strings.Join(config.CommitSigningFormatValues, ", "))
+	}
+}
+
+// configureGPGSigning imports the key into a dedicated GPG home dir so the user's own keyring is left alone
+func (g *GitCLI) configureGPGSigning(gnupgHome string, signing *CommitSigning) error {
+	err := os.MkdirAll(gnupgHome, 0700)
+	if err != nil {
+		return errors.Wrapf(err, "creating %s", gnupgHome)
+	}
+	cmd := util.Command{
+		Name: "gpg",
+		Args: []string{"--batch", "--import"},
+		Env:  map[string]string{gnupgHomeEnvVar: gnupgHome},
+		In:   strings.NewReader(signing.Key),
+	}
+	output, err := cmd.RunWithoutRetry()
+	if err != nil {
+		return errors.Wrapf(err, "importing the commit signing key: %s", output)
+	}
+	g.setEnv(gnupgHomeEnvVar, gnupgHome)
+	g.signingConfig = []string{"commit.gpgSign=true", "gpg.format=openpgp"}
+	if signing.KeyID != "" {
+		g.signingConfig = append(g.signingConfig, "user.signingKey="+signing.KeyID)
+	}
+	log.Logger().Debugf("signing commits with GPG key %s", signing.KeyID)
+	return nil
+}
+
+// configureSSHSigning writes the key to a file as git passes the key file to ssh-keygen
+func (g *GitCLI) configureSSHSigning(keyFile string, signing *CommitSigning) error {
+	key := strings.TrimSpace(signing.Key) + "\n"
+	err := ioutil.WriteFile(keyFile, []byte(key), 0600)
+	if err != nil {
+		return errors.Wrapf(err, "writing the commit signing key to %s", keyFile)
+	}
+	g.signingConfig = []string{"commit.gpgSign=true", "gpg.format=ssh", "user.signingKey=" + keyFile}
+	log.Logger().Debugf("signing commits with SSH key %s", keyFile)
+	return nil
+}
+
+// IsSigningCommits returns true if commits are signed
+func (g *GitCLI) IsSigningCommits() bool {
+	return len(g.signingConfig) > 0
+}
+
+// configArgs returns the arguments which set the configuration every git command is run with
+func (g *GitCLI) configArgs(args []string) []string {
+	if len(g.signingConfig) == 0 {
+		return args
+	}
+	answer := make([]string, 0, 2*len(g.signingConfig)+len(args))
+	for _, value := range g.signingConfig {
+		answer = append(answer, "-c", value)
+	}
+	return append(answer, args...)
+}
+
+func (g *GitCLI) setEnv(name string, value string) {
+	if g.Env == nil {
+		g.Env = map[string]string{}
+	}
+	g.Env[name] = value
+}
@@ -11,6 +11,8 @@ const (
 	KindGitlab = "gitlab"
 	// KindGitHub git kind for github
 	KindGitHub = "github"
+	// KindAzureDevOps git kind for Azure DevOps Repos
+	KindAzureDevOps = "azuredevops"
 	// KindGitFake git kind for fake git
 	KindGitFake = "fakegit"
 	// KindUnknown git kind for unknown git
@@ -19,6 +21,9 @@ const (
 	// BitbucketCloudURL the default URL for BitBucket Cloud
 	BitbucketCloudURL = "https://bitbucket.org"
 
+	// AzureDevOpsURL the default URL for Azure DevOps
+	AzureDevOpsURL = "https://dev.azure.com"
+
 	// FakeGitURL the default URL for the fake git provider
 	FakeGitURL = "https://fake.git"
 
@@ -27,5 +32,5 @@ const (
 )
 
 var (
-	KindGits = []string{KindBitBucketCloud, KindBitBucketServer, KindGitea, KindGitHub, KindGitlab}
+	KindGits = []string{KindAzureDevOps, KindBitBucketCloud, KindBitBucketServer, KindGitea, KindGitHub, KindGitlab}
 )
@@ -12,10 +12,12 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/pkg/errors"
 
+	v1 "github.com/jenkins-x/jx/v2/pkg/apis/jenkins.io/v1"
 	"github.com/jenkins-x/jx/v2/pkg/auth"
 	"github.com/jenkins-x/jx/v2/pkg/log"
 	"github.com/jenkins-x/jx/v2/pkg/util"
@@ -35,12 +37,24 @@ var (
 // GitCLI implements common git actions based on git CLI
 type GitCLI struct {
 	Env map[string]string
+	// RetryPolicy how network operations such as clone, fetch and push are retried, nil disables retries
+	RetryPolicy *RetryPolicy
+
+	// signingConfig the git configuration used to sign commits
+	signingConfig []string
+	// mirrors the mirrors which repositories are cloned from before falling back to their origin
+	mirrors []v1.GitMirror
+
+	sshLock sync.Mutex
+	// sshAuths the prepared ssh authentication of each git host
+	sshAuths map[string]*auth.SSHAuth
 }
 
 // NewGitCLI creates a new GitCLI instance
 func NewGitCLI() *GitCLI {
 	cli := &GitCLI{
-		Env: map[string]string{},
+		Env:         map[string]string{},
+		RetryPolicy: DefaultRetryPolicy(),
 	}
 	// Ensure that error output is in English so parsing work
 	cli.Env["LC_ALL"] = "C"
@@ -119,11 +133,19 @@ func (g *GitCLI) FindGitConfigDir(dir string) (string, string, error) {
 
 // Clone clones the given git URL into the given directory
 func (g *GitCLI) Clone(url string, dir string) error {
-	return g.clone(dir, url, "", false, false, "", "", "")
+	return cloneFromMirror(g, g.mirrors, url, dir, func(gitURL string) error {
+		return g.clone(dir, gitURL, "", false, false, "", "", "")
+	})
 }
 
-// Clone clones a single branch of the given git URL into the given directory
+// ShallowCloneBranch clones a single branch of the given git URL into the given directory
 func (g *GitCLI) ShallowCloneBranch(gitURL string, branch string, dir string) error {
+	return cloneFromMirror(g, g.mirrors, gitURL, dir, func(gitURL string) error {
+		return g.shallowCloneBranch(gitURL, branch, dir)
+	})
+}
+
+func (g *GitCLI) shallowCloneBranch(gitURL string, branch string, dir string) error {
 	var err error
 	verbose := true
 	remoteName := "origin"
@@ -178,7 +200,9 @@ func (g *GitCLI) ShallowCloneBranch(gitURL string, branch string, dir string) er
 
 // ShallowClone shallow clones the repo at url from the specified commitish or pull request to a local master branch
 func (g *GitCLI) ShallowClone(dir string, url string, commitish string, pullRequest string) error {
-	return g.clone(dir, url, "", true, false, "master", commitish, pullRequest)
+	return cloneFromMirror(g, g.mirrors, url, dir, func(gitURL string) error {
+		return g.clone(dir, gitURL, "", true, false, "master", commitish, pullRequest)
+	})
 }
 
 // clone is a safer implementation of the `git clone` method
@@ -266,17 +290,17 @@ func (g *GitCLI) clone(dir string, gitURL string, remoteName string, shallow boo
 
 // Pull pulls the Git repository in the given directory
 func (g *GitCLI) Pull(dir string) error {
-	return g.gitCmd(dir, "pull")
+	return g.networkCmd(dir, "pull")
 }
 
 // PullRemoteBranches pulls the remote Git tags from the given directory
 func (g *GitCLI) PullRemoteBranches(dir string) error {
-	return g.gitCmd(dir, "pull", "--all")
+	return g.networkCmd(dir, "pull", "--all")
 }
 
 // DeleteRemoteBranch deletes the remote branch in the given directory
 func (g *GitCLI) DeleteRemoteBranch(dir string, remoteName string, branch string) error {
-	return g.gitCmd(dir, "push", remoteName, "--delete", branch)
+	return g.networkCmd(dir, "push", remoteName, "--delete", branch)
 }
 
 // DeleteLocalBranch deletes the local branch in the given directory
@@ -299,12 +323,12 @@ func (g *GitCLI) CloneOrPull(url string, dir string) error {
 
 // PullUpstream pulls the remote upstream branch into master branch into the given directory
 func (g *GitCLI) PullUpstream(dir string) error {
-	return g.gitCmd(dir, "pull", "-r", "upstream", "master")
+	return g.networkCmd(dir, "pull", "-r", "upstream", "master")
 }
 
 // ResetToUpstream resets the given branch to the upstream version
 func (g *GitCLI) ResetToUpstream(dir string, branch string) error {
-	err := g.gitCmd(dir, "fetch", "upstream")
+	err := g.networkCmd(dir, "fetch", "upstream")
 	if err != nil {
 		return err
 	}
@@ -320,17 +344,21 @@ func (g *GitCLI) AddRemote(dir string, name string, url string) error {
 			return err
 		}
 	}
-	return nil
+	return g.configureRemoteSSH(dir, url)
 }
 
 // UpdateRemote updates the URL of the remote repository
 func (g *GitCLI) UpdateRemote(dir, url string) error {
-	return g.gitCmd(dir, "remote", "set-url", "origin", url)
+	err := g.gitCmd(dir, "remote", "set-url", "origin", url)
+	if err != nil {
+		return err
+	}
+	return g.configureRemoteSSH(dir, url)
 }
 
 // RemoteUpdate performs a git remote update
 func (g *GitCLI) RemoteUpdate(dir string) error {
-	return g.gitCmd(dir, "remote", "update")
+	return g.networkCmd(dir, "remote", "update")
 }
 
 // StashPush stashes the current changes from the given directory
@@ -454,7 +482,7 @@ func (g *GitCLI) Branch(dir string) (string, error) {
 
 // WriteOperation performs a generic write operation, with nicer error handling
 func (g *GitCLI) WriteOperation(dir string, args ...string) error {
-	return errors.Wrap(g.gitCmd(dir, args...),
+	return errors.Wrap(g.networkCmd(dir, args...),
 		"Have you set up a git credential helper? See https://help.github.com/articles/caching-your-github-password-in-git/\n")
 }
 
@@ -525,6 +553,17 @@ func (g *GitCLI) CommitIfChanges(dir string, message string) error {
 func (g *GitCLI) GetCommits(dir string, startSha string, endSha string) ([]GitCommit, error) {
 	return g.getCommits(dir, fmt.Sprintf("%s..%s", startSha, endSha))
 }
+
+// GetCommitsForFile returns the most recent commits which changed the file, newest first, returning every commit if
+// maxCount is not positive
+func (g *GitCLI) GetCommitsForFile(dir string, file string, maxCount int) ([]GitCommit, error) {
+	var args []string
+	if maxCount > 0 {
+		args = append(args, fmt.Sprintf("--max-count=%d", maxCount))
+	}
+	return g.getCommits(dir, append(args, "--", file)...)
+}
+
 func (g *GitCLI) getCommits(dir string, args ...string) ([]GitCommit, error) {
 	// use a custom format to get commits, using %x1e to separate commits and %x1f to separate fields
 	args = append([]string{"log", "--format=%H%x1f%an%x1f%ae%x1f%cn%x1f%ce%x1f%s%n%b%x1e"}, args...)
@@ -590,20 +629,27 @@ func (g *GitCLI) gitCmd(dir string, args ...string) error {
 	cmd := util.Command{
 		Dir:  dir,
 		Name: "git",
-		Args: args,
-		Env:  g.Env,
+		Args: g.configArgs(args),
+		Env:  g.commandEnv(args),
 	}
 	log.Logger().Debug(cmd.String())
 	output, err := cmd.RunWithoutRetry()
 	return errors.Wrapf(err, "git output: %s", output)
 }
 
+// networkCmd runs a git command which talks to a remote repository, retrying transient network failures
+func (g *GitCLI) networkCmd(dir string, args ...string) error {
+	return g.RetryPolicy.Retry("git "+args[0], func() error {
+		return g.gitCmd(dir, args...)
+	})
+}
+
 func (g *GitCLI) gitCmdWithOutput(dir string, args ...string) (string, error) {
 	cmd := util.Command{
 		Dir:  dir,
 		Name: "git",
-		Args: args,
-		Env:  g.Env,
+		Args: g.configArgs(args),
+		Env:  g.commandEnv(args),
 	}
 	log.Logger().Debug(cmd.String())
 	return cmd.RunWithoutRetry()
@@ -611,6 +657,13 @@ func (g *GitCLI) gitCmdWithOutput(dir string, args ...string) (string, error) {
 
 // CreateAuthenticatedURL creates the Git repository URL with the username and password encoded for HTTPS based URLs
 func (g *GitCLI) CreateAuthenticatedURL(cloneURL string, userAuth *auth.UserAuth) (string, error) {
+	if userAuth != nil && userAuth.SSH != nil {
+		err := g.ConfigureSSH(cloneURL, userAuth.SSH)
+		if err != nil {
+			return "", errors.Wrap(err, "configuring ssh authentication")
+		}
+		return SSHCloneURL(cloneURL)
+	}
 	u, err := url.Parse(cloneURL)
 	if err != nil {
 		// already a git/ssh url?
@@ -736,7 +789,7 @@ func (g *GitCLI) fetchBranch(dir string, repo string, unshallow bool, shallow bo
 	for _, refspec := range refspecs {
 		args = append(args, refspec)
 	}
-	err := g.gitCmd(dir, args...)
+	err := g.networkCmd(dir, args...)
 	if err != nil {
 		return errors.WithStack(err)
 	}
@@ -772,7 +825,7 @@ func (g *GitCLI) SetRemoteURL(dir string, name string, gitURL string) error {
 			return err
 		}
 	}
-	return nil
+	return g.configureRemoteSSH(dir, gitURL)
 }
 
 func (g *GitCLI) parseGitConfig(gitConf string) (*gitcfg.Config, error) {
@@ -959,12 +1012,12 @@ func (g *GitCLI) GetLatestCommitMessage(dir string) (string, error) {
 
 // FetchTags fetches all the tags
 func (g *GitCLI) FetchTags(dir string) error {
-	return g.gitCmd(dir, "fetch", "--tags")
+	return g.networkCmd(dir, "fetch", "--tags")
 }
 
 // FetchRemoteTags fetches all the tags from a remote repository
 func (g *GitCLI) FetchRemoteTags(dir string, repo string) error {
-	return g.gitCmd(dir, "fetch", repo, "--tags")
+	return g.networkCmd(dir, "fetch", repo, "--tags")
 }
 
 // Tags returns all tags from the repository at the given directory
@@ -1079,7 +1132,7 @@ func (g *GitCLI) LoadFileFromBranch(dir string, branch string, file string) (str
 
 // FetchUnshallow runs git fetch --unshallow in dir
 func (g *GitCLI) FetchUnshallow(dir string) error {
-	err := g.gitCmd(dir, "fetch", "--unshallow")
+	err := g.networkCmd(dir, "fetch", "--unshallow")
 	if err != nil {
 		return errors.Wrapf(err, "running git fetch --unshallow %s", dir)
 	}
@@ -1225,7 +1278,7 @@ func (g *GitCLI) Remotes(dir string) ([]string, error) {
 
 // CloneBare will create a bare clone of url
 func (g *GitCLI) CloneBare(dir string, url string) error {
-	err := g.gitCmd(dir, "clone", "--bare", url, dir)
+	err := g.networkCmd(dir, "clone", "--bare", url, dir)
 	if err != nil {
 		return errors.Wrapf(err, "running git clone --bare %s", url)
 	}
@@ -1234,7 +1287,7 @@ func (g *GitCLI) CloneBare(dir string, url string) error {
 
 // PushMirror will push the dir as a mirror to url
 func (g *GitCLI) PushMirror(dir string, url string) error {
-	err := g.gitCmd(dir, "push", "--mirror", url)
+	err := g.networkCmd(dir, "push", "--mirror", url)
 	if err != nil {
 		return errors.Wrapf(err, "running git push --mirror %s", url)
 	}
@@ -140,6 +140,11 @@ func (g *GitFake) Clone(url string, directory string) error {
 	return nil
 }
 
+// CloneWithOptions clones the repo to the given dir
+func (g *GitFake) CloneWithOptions(url string, directory string, options *CloneOptions) error {
+	return nil
+}
+
 // ShallowCloneBranch shallow clone of a branch
 func (g *GitFake) ShallowCloneBranch(url string, branch string, directory string) error {
 	return nil
@@ -399,6 +404,11 @@ func (g *GitFake) FetchBranchShallow(dir string, repo string, refspec ...string)
 	return nil
 }
 
+// FetchBranchWithOptions fetches a branch
+func (g *GitFake) FetchBranchWithOptions(dir string, repo string, options *CloneOptions, refspec ...string) error {
+	return nil
+}
+
 // StashPush git stash
 func (g *GitFake) StashPush(dir string) error {
 	return nil
@@ -626,6 +636,11 @@ func (g *GitFake) GetCommits(dir string, startSha string, endSha string) ([]GitC
 	return nil, nil
 }
 
+// GetCommitsForFile returns the most recent commits which changed the file, newest first
+func (g *GitFake) GetCommitsForFile(dir string, file string, maxCount int) ([]GitCommit, error) {
+	return nil, nil
+}
+
 // RevParse runs git rev-parse on rev
 func (g *GitFake) RevParse(dir string, rev string) (string, error) {
 	return "", nil
@@ -0,0 +1,711 @@
+package gits
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/jenkins-x/jx/v2/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+
+	git "gopkg.in/src-d/go-git.v4"
+	gitcfg "gopkg.in/src-d/go-git.v4/config"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+	formatcfg "gopkg.in/src-d/go-git.v4/plumbing/format/config"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+	"gopkg.in/src-d/go-git.v4/plumbing/transport"
+	githttp "gopkg.in/src-d/go-git.v4/plumbing/transport/http"
+)
+
+const (
+	// GitImplEnvVar the environment variable used to select the Gitter implementation
+	GitImplEnvVar = "JX_GIT_IMPL"
+
+	// GitImplCLI the Gitter implementation which uses the git binary
+	GitImplCLI = "cli"
+
+	// GitImplGoGit the Gitter implementation which uses the pure Go go-git library
+	GitImplGoGit = "go-git"
+
+	anonymousRemoteName = "anonymous"
+)
+
+// GoGit is a Gitter which implements the common operations (clone, fetch, branch, commit, cherry-pick and push)
+// with go-git so that no git binary needs to be installed. Any other operation falls back to the git binary.
+type GoGit struct {
+	*GitCLI
+}
+
+// NewGoGit creates a new GoGit instance
+func NewGoGit() *GoGit {
+	return &GoGit{
+		GitCLI: NewGitCLI(),
+	}
+}
+
+// NewGitter creates the Gitter for the given implementation name, defaulting to the git binary
+func NewGitter(impl string) (Gitter, error) {
+	switch impl {
+	case "", GitImplCLI:
+		return NewGitCLI(), nil
+	case GitImplGoGit:
+		return NewGoGit(), nil
+	default:
+		return nil, fmt.Errorf("unknown git implementation %s, must be one of %s or %s", impl, GitImplCLI, GitImplGoGit)
+	}
+}
+
+// Init inits a git repository into the given directory
+func (g *GoGit) Init(dir string) error {
+	_, err := git.PlainInit(dir, false)
+	return err
+}
+
+// Clone clones the given git URL into the given directory
+func (g *GoGit) Clone(url string, dir string) error {
+	return g.CloneWithOptions(url, dir, nil)
+}
+
+// CloneWithOptions clones the given git URL into the given directory. Partial and sparse clones are not supported
+// by go-git so fall back to the git binary
+func (g *GoGit) CloneWithOptions(url string, dir string, options *CloneOptions) error {
+	return cloneFromMirror(g, g.mirrors, url, dir, func(gitURL string) error {
+		return g.cloneWithOptions(gitURL, dir, options)
+	})
+}
+
+func (g *GoGit) cloneWithOptions(url string, dir string, options *CloneOptions) error {
+	if options == nil {
+		return g.plainClone(dir, false, &git.CloneOptions{
+			URL:  url,
+			Auth: g.authForURL(url),
+		})
+	}
+	if options.Filter != "" || len(options.SparsePaths) > 0 {
+		return g.GitCLI.cloneWithOptions(url, dir, options)
+	}
+	cloneOptions := &git.CloneOptions{
+		URL:   url,
+		Auth:  g.authForURL(url),
+		Depth: options.Depth,
+	}
+	if options.Branch != "" {
+		cloneOptions.ReferenceName = plumbing.NewBranchReferenceName(options.Branch)
+		cloneOptions.SingleBranch = options.Depth > 0
+	}
+	return g.plainClone(dir, options.Bare, cloneOptions)
+}
+
+// plainClone clones the repository. Before retrying a clone which failed with a transient error anything the
+// failed attempt created is removed, leaving any existing contents of the directory alone
+func (g *GoGit) plainClone(dir string, bare bool, options *git.CloneOptions) error {
+	cleanup := newCloneCleanup(dir)
+	attempts := 0
+	err := g.RetryPolicy.Retry("git clone", func() error {
+		if attempts > 0 {
+			cleanup.clean()
+		}
+		attempts++
+		_, err := git.PlainClone(dir, bare, options)
+		return err
+	})
+	if err != nil {
+		return errors.Wrapf(err, "cloning %s into %s", options.URL, dir)
+	}
+	// operations which fall back to the git binary use the ssh authentication of the origin
+	return g.configureRemoteSSH(dir, options.URL)
+}
+
+// FetchBranch fetches the refspecs from the repo, which is either a remote name or a URL
+func (g *GoGit) FetchBranch(dir string, repo string, refspec ...string) error {
+	return g.fetch(dir, repo, 0, refspec...)
+}
+
+// FetchBranchShallow fetches the refspecs from the repo with a depth of 1
+func (g *GoGit) FetchBranchShallow(dir string, repo string, refspec ...string) error {
+	return g.fetch(dir, repo, 1, refspec...)
+}
+
+// FetchBranchWithOptions fetches the refspecs from the repo. Partial fetches are not supported by go-git so fall
+// back to the git binary
+func (g *GoGit) FetchBranchWithOptions(dir string, repo string, options *CloneOptions, refspec ...string) error {
+	if options == nil {
+		return g.FetchBranch(dir, repo, refspec...)
+	}
+	if options.Filter != "" {
+		return g.GitCLI.FetchBranchWithOptions(dir, repo, options, refspec...)
+	}
+	return g.fetch(dir, repo, options.Depth, refspec...)
+}
+
+func (g *GoGit) fetch(dir string, repo string, depth int, refspec ...string) error {
+	repository, err := g.open(dir)
+	if err != nil {
+		return err
+	}
+	remote, auth, err := g.remote(repository, repo)
+	if err != nil {
+		return err
+	}
+	name := remote.Config().Name
+	specs := []gitcfg.RefSpec{}
+	for _, s := range refspec {
+		spec, err := g.fetchRefSpec(name, s)
+		if err != nil {
+			return err
+		}
+		specs = append(specs, spec)
+	}
+	if len(specs) == 0 && name == anonymousRemoteName {
+		specs = append(specs, gitcfg.RefSpec(fmt.Sprintf("+refs/heads/*:refs/remotes/%s/*", name)))
+	}
+	err = g.RetryPolicy.Retry("git fetch", func() error {
+		err := remote.Fetch(&git.FetchOptions{
+			RemoteName: name,
+			RefSpecs:   specs,
+			Depth:      depth,
+			Auth:       auth,
+		})
+		if err == git.NoErrAlreadyUpToDate {
+			return nil
+		}
+		return err
+	})
+	if err != nil {
+		return errors.Wrapf(err, "fetching %s from %s", strings.Join(refspec, " "), repo)
+	}
+	return nil
+}
+
+// Branch returns the current branch of the repository located at the given directory
+func (g *GoGit) Branch(dir string) (string, error) {
+	repository, err := g.open(dir)
+	if err != nil {
+		return "", err
+	}
+	head, err := repository.Head()
+	if err != nil {
+		return "", errors.Wrapf(err, "resolving HEAD in %s", dir)
+	}
+	if !head.Name().IsBranch() {
+		return "HEAD", nil
+	}
+	return head.Name().Short(), nil
+}
+
+// CreateBranch creates a branch with the given name at HEAD in the Git repository from the given directory
+func (g *GoGit) CreateBranch(dir string, branch string) error {
+	return g.CreateBranchFrom(dir, branch, "HEAD")
+}
+
+// CreateBranchFrom creates a new branch called branchName from startPoint
+func (g *GoGit) CreateBranchFrom(dir string, branchName string, startPoint string) error {
+	repository, err := g.open(dir)
+	if err != nil {
+		return err
+	}
+	hash, err := repository.ResolveRevision(plumbing.Revision(startPoint))
+	if err != nil {
+		return errors.Wrapf(err, "resolving %s in %s", startPoint, dir)
+	}
+	return repository.Storer.SetReference(plumbing.NewHashReference(plumbing.NewBranchReferenceName(branchName), *hash))
+}
+
+// Checkout checks out the given branch, or commit if there is no such branch
+func (g *GoGit) Checkout(dir string, branch string) error {
+	repository, err := g.open(dir)
+	if err != nil {
+		return err
+	}
+	worktree, err := repository.Worktree()
+	if err != nil {
+		return err
+	}
+	options := &git.CheckoutOptions{
+		Branch: plumbing.NewBranchReferenceName(branch),
+		Keep:   true,
+	}
+	_, err = repository.Reference(options.Branch, true)
+	if err == plumbing.ErrReferenceNotFound {
+		hash, err := repository.ResolveRevision(plumbing.Revision(branch))
+		if err != nil {
+			return errors.Wrapf(err, "resolving %s in %s", branch, dir)
+		}
+		options = &git.CheckoutOptions{
+			Hash: *hash,
+			Keep: true,
+		}
+	} else if err != nil {
+		return err
+	}
+	return errors.Wrapf(worktree.Checkout(options), "checking out %s in %s", branch, dir)
+}
+
+// Add stages the given files, or all the changes when given -A, --all or .
+func (g *GoGit) Add(dir string, args ...string) error {
+	repository, err := g.open(dir)
+	if err != nil {
+		return err
+	}
+	worktree, err := repository.Worktree()
+	if err != nil {
+		return err
+	}
+	root := worktree.Filesystem.Root()
+	for _, arg := range args {
+		if arg == "-A" || arg == "--all" {
+			return g.addAll(worktree, "")
+		}
+		if strings.HasPrefix(arg, "-") {
+			continue
+		}
+		path, err := filepath.Rel(root, filepath.Join(dir, arg))
+		if err != nil {
+			return err
+		}
+		path = filepath.ToSlash(path)
+		if path == "." {
+			path = ""
+		}
+		err = g.addAll(worktree, path)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// HasChanges indicates if there are any changes in the repository from the given directory
+func (g *GoGit) HasChanges(dir string) (bool, error) {
+	repository, err := g.open(dir)
+	if err != nil {
+		return false, err
+	}
+	worktree, err := repository.Worktree()
+	if err != nil {
+		return false, err
+	}
+	status, err := worktree.Status()
+	if err != nil {
+		return false, err
+	}
+	return !status.IsClean(), nil
+}
+
+// CommitDir commits the staged changes of the repository at the given directory with the given message. Signed
+// commits are made with the git binary as go-git cannot sign them
+func (g *GoGit) CommitDir(dir string, message string) error {
+	if g.IsSigningCommits() {
+		return g.GitCLI.CommitDir(dir, message)
+	}
+	return g.commit(dir, message, false)
+}
+
+// AddCommit commits all the changes to tracked files of the repository at the given directory with the given message
+func (g *GoGit) AddCommit(dir string, msg string) error {
+	if g.IsSigningCommits() {
+		return g.GitCLI.AddCommit(dir, msg)
+	}
+	return g.commit(dir, msg, true)
+}
+
+// GetLatestCommitSha returns the sha of the last commit
+func (g *GoGit) GetLatestCommitSha(dir string) (string, error) {
+	return g.RevParse(dir, "HEAD")
+}
+
+// RevParse returns the sha of the given revision
+func (g *GoGit) RevParse(dir string, rev string) (string, error) {
+	repository, err := g.open(dir)
+	if err != nil {
+		return "", err
+	}
+	hash, err := repository.ResolveRevision(plumbing.Revision(rev))
+	if err != nil {
+		return "", errors.Wrapf(err, "resolving %s in %s", rev, dir)
+	}
+	return hash.String(), nil
+}
+
+// CherryPick applies the changes of the given commit onto the current branch, failing if any of the changed files
+// has diverged from the parent of the commit
+func (g *GoGit) CherryPick(dir string, commitish string) error {
+	if g.IsSigningCommits() {
+		return g.GitCLI.CherryPick(dir, commitish)
+	}
+	repository, err := g.open(dir)
+	if err != nil {
+		return err
+	}
+	hash, err := repository.ResolveRevision(plumbing.Revision(commitish))
+	if err != nil {
+		return errors.Wrapf(err, "resolving %s in %s", commitish, dir)
+	}
+	commit, err := repository.CommitObject(*hash)
+	if err != nil {
+		return err
+	}
+	if commit.NumParents() != 1 {
+		return fmt.Errorf("cannot cherry pick %s as it has %d parents", commitish, commit.NumParents())
+	}
+	parent, err := commit.Parent(0)
+	if err != nil {
+		return err
+	}
+	parentTree, err := parent.Tree()
+	if err != nil {
+		return err
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return err
+	}
+	changes, err := object.DiffTree(parentTree, tree)
+	if err != nil {
+		return errors.Wrapf(err, "diffing %s against its parent", commitish)
+	}
+	head, err := repository.Head()
+	if err != nil {
+		return err
+	}
+	headCommit, err := repository.CommitObject(head.Hash())
+	if err != nil {
+		return err
+	}
+	headTree, err := headCommit.Tree()
+	if err != nil {
+		return err
+	}
+	worktree, err := repository.Worktree()
+	if err != nil {
+		return err
+	}
+	root := worktree.Filesystem.Root()
+
+	// check the whole change applies cleanly before touching the working tree
+	for _, change := range changes {
+		from, to, err := change.Files()
+		if err != nil {
+			return err
+		}
+		if from != nil {
+			current, err := headTree.File(from.Name)
+			if err != nil || current.Hash != from.Hash {
+				return fmt.Errorf("cannot cherry pick %s as %s has conflicting changes", commitish, from.Name)
+			}
+		} else if to != nil {
+			current, err := headTree.File(to.Name)
+			if err == nil && current.Hash != to.Hash {
+				return fmt.Errorf("cannot cherry pick %s as %s has conflicting changes", commitish, to.Name)
+			}
+		}
+	}
+	for _, change := range changes {
+		from, to, err := change.Files()
+		if err != nil {
+			return err
+		}
+		if from != nil && (to == nil || to.Name != from.Name) {
+			_, err = worktree.Remove(from.Name)
+			if err != nil {
+				return errors.Wrapf(err, "removing %s", from.Name)
+			}
+		}
+		if to == nil {
+			continue
+		}
+		contents, err := to.Contents()
+		if err != nil {
+			return err
+		}
+		mode, err := to.Mode.ToOSFileMode()
+		if err != nil {
+			return err
+		}
+		path := filepath.Join(root, filepath.FromSlash(to.Name))
+		err = os.MkdirAll(filepath.Dir(path), util.DefaultWritePermissions)
+		if err != nil {
+			return err
+		}
+		err = ioutil.WriteFile(path, []byte(contents), mode)
+		if err != nil {
+			return errors.Wrapf(err, "writing %s", path)
+		}
+		_, err = worktree.Add(to.Name)
+		if err != nil {
+			return errors.Wrapf(err, "adding %s", to.Name)
+		}
+	}
+	committer, err := g.signature(repository)
+	if err != nil {
+		return err
+	}
+	author := commit.Author
+	_, err = worktree.Commit(commit.Message, &git.CommitOptions{
+		Author:    &author,
+		Committer: committer,
+	})
+	return errors.Wrapf(err, "committing the cherry pick of %s", commitish)
+}
+
+// Push pushes the refspecs of the repository at the given directory to the remote, which is either a remote name
+// or a URL. If no refspec is given the current branch is pushed
+func (g *GoGit) Push(dir string, remote string, force bool, refspec ...string) error {
+	repository, err := g.open(dir)
+	if err != nil {
+		return err
+	}
+	r, auth, err := g.remote(repository, remote)
+	if err != nil {
+		return err
+	}
+	if len(refspec) == 0 {
+		refspec = []string{"HEAD"}
+	}
+	specs := []gitcfg.RefSpec{}
+	for _, s := range refspec {
+		spec, err := g.pushRefSpec(repository, s, force)
+		if err != nil {
+			return err
+		}
+		specs = append(specs, spec)
+	}
+	err = g.RetryPolicy.Retry("git push", func() error {
+		err := r.Push(&git.PushOptions{
+			RemoteName: r.Config().Name,
+			RefSpecs:   specs,
+			Auth:       auth,
+		})
+		if err == git.NoErrAlreadyUpToDate {
+			return nil
+		}
+		return err
+	})
+	if err != nil {
+		return errors.Wrapf(err, "pushing %s to %s", strings.Join(refspec, " "), remote)
+	}
+	return nil
+}
+
+// ForcePushBranch does a force push of the local branch into the remote branch of the repository at the given directory
+func (g *GoGit) ForcePushBranch(dir string, localBranch string, remoteBranch string) error {
+	return g.Push(dir, "origin", true, fmt.Sprintf("%s:%s", localBranch, remoteBranch))
+}
+
+// PushMaster pushes the master branch into the origin
+func (g *GoGit) PushMaster(dir string) error {
+	return g.Push(dir, "origin", false, "master")
+}
+
+func (g *GoGit) open(dir string) (*git.Repository, error) {
+	repository, err := git.PlainOpenWithOptions(dir, &git.PlainOpenOptions{DetectDotGit: true})
+	if err != nil {
+		return nil, errors.Wrapf(err, "opening the git repository at %s", dir)
+	}
+	return repository, nil
+}
+
+// remote returns the named remote or an anonymous remote if the name is a URL
+func (g *GoGit) remote(repository *git.Repository, nameOrURL string) (*git.Remote, transport.AuthMethod, error) {
+	if strings.Contains(nameOrURL, "://") || strings.HasPrefix(nameOrURL, gitPrefix) {
+		remote := git.NewRemote(repository.Storer, &gitcfg.RemoteConfig{
+			Name: anonymousRemoteName,
+			URLs: []string{nameOrURL},
+		})
+		return remote, g.authForURL(nameOrURL), nil
+	}
+	remote, err := repository.Remote(nameOrURL)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "finding remote %s", nameOrURL)
+	}
+	var auth transport.AuthMethod
+	if urls := remote.Config().URLs; len(urls) > 0 {
+		auth = g.authForURL(urls[0])
+	}
+	return remote, auth, nil
+}
+
+func (g *GoGit) fetchRefSpec(remote string, spec string) (gitcfg.RefSpec, error) {
+	force := strings.HasPrefix(spec, "+")
+	spec = strings.TrimPrefix(spec, "+")
+	parts := strings.SplitN(spec, ":", 2)
+	src := qualifiedRefName(parts[0])
+	dst := ""
+	if len(parts) == 2 && parts[1] != "" {
+		dst = qualifiedRefName(parts[1])
+	} else {
+		dst = fmt.Sprintf("refs/remotes/%s/%s", remote, plumbing.ReferenceName(src).Short())
+	}
+	return newRefSpec(src, dst, force)
+}
+
+func (g *GoGit) pushRefSpec(repository *git.Repository, spec string, force bool) (gitcfg.RefSpec, error) {
+	force = force || strings.HasPrefix(spec, "+")
+	spec = strings.TrimPrefix(spec, "+")
+	parts := strings.SplitN(spec, ":", 2)
+	src := parts[0]
+	if src == "HEAD" {
+		head, err := repository.Head()
+		if err != nil {
+			return "", err
+		}
+		if !head.Name().IsBranch() {
+			return "", fmt.Errorf("cannot push HEAD as it is not on a branch")
+		}
+		src = head.Name().String()
+	} else if src != "" {
+		src = g.localRefName(repository, src)
+	}
+	dst := src
+	if len(parts) == 2 && parts[1] != "" {
+		dst = qualifiedRefName(parts[1])
+	}
+	return newRefSpec(src, dst, force)
+}
+
+// localRefName qualifies the name with whichever of a branch or tag exists locally
+func (g *GoGit) localRefName(repository *git.Repository, name string) string {
+	if strings.HasPrefix(name, "refs/") {
+		return name
+	}
+	tag := plumbing.NewTagReferenceName(name)
+	if _, err := repository.Reference(plumbing.NewBranchReferenceName(name), false); err != nil {
+		if _, err := repository.Reference(tag, false); err == nil {
+			return tag.String()
+		}
+	}
+	return plumbing.NewBranchReferenceName(name).String()
+}
+
+func (g *GoGit) addAll(worktree *git.Worktree, prefix string) error {
+	status, err := worktree.Status()
+	if err != nil {
+		return err
+	}
+	for path, s := range status {
+		if prefix != "" && path != prefix && !strings.HasPrefix(path, prefix+"/") {
+			continue
+		}
+		switch s.Worktree {
+		case git.Unmodified:
+			continue
+		case git.Deleted:
+			_, err = worktree.Remove(path)
+		default:
+			_, err = worktree.Add(path)
+		}
+		if err != nil {
+			return errors.Wrapf(err, "adding %s", path)
+		}
+	}
+	return nil
+}
+
+func (g *GoGit) commit(dir string, message string, all bool) error {
+	repository, err := g.open(dir)
+	if err != nil {
+		return err
+	}
+	worktree, err := repository.Worktree()
+	if err != nil {
+		return err
+	}
+	signature, err := g.signature(repository)
+	if err != nil {
+		return err
+	}
+	_, err = worktree.Commit(message, &git.CommitOptions{
+		All:    all,
+		Author: signature,
+	})
+	return errors.Wrapf(err, "committing in %s", dir)
+}
+
+// signature returns the author to use for commits, taking the GIT_AUTHOR_NAME and GIT_AUTHOR_EMAIL environment
+// variables over the user of the repository configuration over the user of the global configuration
+func (g *GoGit) signature(repository *git.Repository) (*object.Signature, error) {
+	name := os.Getenv("GIT_AUTHOR_NAME")
+	email := os.Getenv("GIT_AUTHOR_EMAIL")
+	configs := []*formatcfg.Config{}
+	cfg, err := repository.Config()
+	if err == nil {
+		configs = append(configs, cfg.Raw)
+	}
+	configs = append(configs, globalGitConfig())
+	for _, c := range configs {
+		if c == nil {
+			continue
+		}
+		user := c.Section("user")
+		if name == "" {
+			name = user.Option("name")
+		}
+		if email == "" {
+			email = user.Option("email")
+		}
+	}
+	if name == "" || email == "" {
+		return nil, fmt.Errorf("no git user.name and user.email configured")
+	}
+	return &object.Signature{
+		Name:  name,
+		Email: email,
+		When:  time.Now(),
+	}, nil
+}
+
+func globalGitConfig() *formatcfg.Config {
+	f, err := os.Open(filepath.Join(util.HomeDir(), ".gitconfig"))
+	if err != nil {
+		return nil
+	}
+	defer f.Close() //nolint:errcheck
+	cfg := formatcfg.New()
+	err = formatcfg.NewDecoder(f).Decode(cfg)
+	if err != nil {
+		return nil
+	}
+	return cfg
+}
+
+// authFromURL returns the basic auth from the user info of a http(s) URL
+func authFromURL(rawURL string) transport.AuthMethod {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.User == nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return nil
+	}
+	password, _ := u.User.Password()
+	return &githttp.BasicAuth{
+		Username: u.User.Username(),
+		Password: password,
+	}
+}
+
+// qualifiedRefName turns a short name such as master or pull/1/head into a full reference name
+func qualifiedRefName(name string) string {
+	switch {
+	case strings.HasPrefix(name, "refs/"):
+		return name
+	case strings.HasPrefix(name, "pull/"):
+		return "refs/" + name
+	default:
+		return plumbing.NewBranchReferenceName(name).String()
+	}
+}
+
+func newRefSpec(src string, dst string, force bool) (gitcfg.RefSpec, error) {
+	spec := gitcfg.RefSpec(fmt.Sprintf("%s:%s", src, dst))
+	if force {
+		spec = "+" + spec
+	}
+	err := spec.Validate()
+	if err != nil {
+		return "", errors.Wrapf(err, "invalid refspec %s", spec)
+	}
+	return spec, nil
+}
@@ -0,0 +1,91 @@
+// +build unit
+
+package gits_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jenkins-x/jx/v2/pkg/gits"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGoGit(t *testing.T) {
+	t.Parallel()
+
+	tmpDir, err := ioutil.TempDir("", "test-go-git")
+	require.NoError(t, err)
+	defer os.RemoveAll(tmpDir) //nolint:errcheck
+
+	repoDir := filepath.Join(tmpDir, "repo")
+	git := gits.NewGoGit()
+	require.NoError(t, git.Init(repoDir))
+	writeTestGitConfig(t, repoDir)
+
+	readme := filepath.Join(repoDir, "README.md")
+	require.NoError(t, ioutil.WriteFile(readme, []byte("hello\n"), 0600))
+	require.NoError(t, git.Add(repoDir, "."))
+	require.NoError(t, git.CommitDir(repoDir, "initial commit"))
+
+	changed, err := git.HasChanges(repoDir)
+	require.NoError(t, err)
+	assert.False(t, changed)
+
+	require.NoError(t, git.CreateBranch(repoDir, "feature"))
+	require.NoError(t, git.Checkout(repoDir, "feature"))
+	branch, err := git.Branch(repoDir)
+	require.NoError(t, err)
+	assert.Equal(t, "feature", branch)
+
+	require.NoError(t, ioutil.WriteFile(readme, []byte("hello world\n"), 0600))
+	require.NoError(t, git.AddCommit(repoDir, "update the readme"))
+	sha, err := git.GetLatestCommitSha(repoDir)
+	require.NoError(t, err)
+
+	require.NoError(t, git.Checkout(repoDir, "master"))
+	data, err := ioutil.ReadFile(readme)
+	require.NoError(t, err)
+	assert.Equal(t, "hello\n", string(data))
+
+	require.NoError(t, git.CherryPick(repoDir, sha))
+	data, err = ioutil.ReadFile(readme)
+	require.NoError(t, err)
+	assert.Equal(t, "hello world\n", string(data))
+	picked, err := git.GetLatestCommitSha(repoDir)
+	require.NoError(t, err)
+	assert.NotEqual(t, sha, picked)
+
+	cloneDir := filepath.Join(tmpDir, "clone")
+	require.NoError(t, git.Clone(repoDir, cloneDir))
+	require.NoError(t, git.CreateBranch(cloneDir, "pushed"))
+	require.NoError(t, git.Push(cloneDir, "origin", false, "pushed"))
+	pushed, err := git.RevParse(repoDir, "pushed")
+	require.NoError(t, err)
+	assert.Equal(t, picked, pushed)
+}
+
+func TestNewGitter(t *testing.T) {
+	t.Parallel()
+
+	git, err := gits.NewGitter("")
+	require.NoError(t, err)
+	assert.IsType(t, &gits.GitCLI{}, git)
+
+	git, err = gits.NewGitter(gits.GitImplGoGit)
+	require.NoError(t, err)
+	assert.IsType(t, &gits.GoGit{}, git)
+
+	_, err = gits.NewGitter("svn")
+	assert.Error(t, err)
+}
+
+func writeTestGitConfig(t *testing.T, dir string) {
+	f, err := os.OpenFile(filepath.Join(dir, ".git", "config"), os.O_APPEND|os.O_WRONLY, 0600)
+	require.NoError(t, err)
+	defer f.Close() //nolint:errcheck
+	_, err = f.WriteString("[user]\n\tname = test-author\n\temail = test-author@acme.com\n")
+	require.NoError(t, err)
+}
@@ -46,6 +46,12 @@ func (g *GitLocal) Clone(url string, dir string) error {
 	return g.GitFake.Clone(url, dir)
 }
 
+// CloneWithOptions clones the given git URL into the given directory
+// Faked out
+func (g *GitLocal) CloneWithOptions(url string, dir string, options *CloneOptions) error {
+	return g.GitFake.CloneWithOptions(url, dir, options)
+}
+
 // ShallowCloneBranch clones a single branch of the given git URL into the given directory
 // Faked out
 func (g *GitLocal) ShallowCloneBranch(url string, branch string, dir string) error {
@@ -265,6 +271,12 @@ func (g *GitLocal) FetchBranchShallow(dir string, repo string, refspec ...string
 	return g.GitFake.FetchBranchShallow(dir, repo, refspec...)
 }
 
+// FetchBranchWithOptions fetches a branch
+// Faked out
+func (g *GitLocal) FetchBranchWithOptions(dir string, repo string, options *CloneOptions, refspec ...string) error {
+	return g.GitFake.FetchBranchWithOptions(dir, repo, options, refspec...)
+}
+
 // FetchBranchUnshallow fetches a branch
 // Faked out
 func (g *GitLocal) FetchBranchUnshallow(dir string, repo string, refspec ...string) error {
@@ -488,6 +500,11 @@ func (g *GitLocal) GetCommits(dir string, startSha string, endSha string) ([]Git
 	return g.GitCLI.GetCommits(dir, startSha, endSha)
 }
 
+// GetCommitsForFile returns the most recent commits which changed the file, newest first
+func (g *GitLocal) GetCommitsForFile(dir string, file string, maxCount int) ([]GitCommit, error) {
+	return g.GitCLI.GetCommitsForFile(dir, file, maxCount)
+}
+
 // RevParse runs git rev parse
 func (g *GitLocal) RevParse(dir string, rev string) (string, error) {
 	return g.GitCLI.RevParse(dir, rev)
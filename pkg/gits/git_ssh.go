@@ -0,0 +1,323 @@
+package gits
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/jenkins-x/jx/v2/pkg/auth"
+	"github.com/jenkins-x/jx/v2/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+	"gopkg.in/src-d/go-git.v4/plumbing/transport"
+	gitssh "gopkg.in/src-d/go-git.v4/plumbing/transport/ssh"
+)
+
+const (
+	// gitSSHCommandEnvVar the environment variable git uses to run ssh
+	gitSSHCommandEnvVar = "GIT_SSH_COMMAND"
+
+	// sshPrivateKeyFilePrefix the prefix of the files inline private keys are written to, followed by a hash of the host
+	sshPrivateKeyFilePrefix = "jx-git-ssh-key-"
+)
+
+// SSHConfigurer is implemented by Gitters which support ssh authentication
+type SSHConfigurer interface {
+	// ConfigureSSH makes subsequent git operations against the host of the git URL use the given ssh authentication
+	ConfigureSSH(gitURL string, sshAuth *auth.SSHAuth) error
+}
+
+// IsSSHURL returns true if the git URL uses the ssh protocol
+func IsSSHURL(gitURL string) bool {
+	return strings.HasPrefix(gitURL, gitPrefix) || strings.HasPrefix(gitURL, "ssh://")
+}
+
+// SSHCloneURL converts a https git URL into the equivalent git@host:owner/repo URL
+func SSHCloneURL(gitURL string) (string, error) {
+	if IsSSHURL(gitURL) {
+		return gitURL, nil
+	}
+	u, err := url.Parse(gitURL)
+	if err != nil {
+		return "", errors.Wrapf(err, "parsing git URL %s", gitURL)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return "", fmt.Errorf("cannot convert %s into a ssh URL", gitURL)
+	}
+	path := strings.TrimPrefix(u.Path, "/")
+	if !strings.HasSuffix(path, ".git") {
+		path += ".git"
+	}
+	return fmt.Sprintf("%s%s:%s", gitPrefix, u.Hostname(), path), nil
+}
+
+// DefaultKnownHostsFile returns the known_hosts file of the current user
+func DefaultKnownHostsFile() string {
+	return filepath.Join(util.HomeDir(), ".ssh", "known_hosts")
+}
+
+// SSHCommand returns the ssh command git should use for the given ssh authentication
+func SSHCommand(sshAuth *auth.SSHAuth) string {
+	args := []string{"ssh"}
+	if sshAuth.PrivateKeyFile != "" {
+		args = append(args, "-i", shellQuote(sshAuth.PrivateKeyFile), "-o", "IdentitiesOnly=yes")
+	}
+	args = append(args, "-o", "UserKnownHostsFile="+shellQuote(knownHostsFile(sshAuth)))
+	if sshAuth.AcceptNewHostKeys {
+		args = append(args, "-o", "StrictHostKeyChecking=accept-new")
+	} else {
+		args = append(args, "-o", "StrictHostKeyChecking=yes")
+	}
+	return strings.Join(args, " ")
+}
+
+// PrepareSSHAuth returns the ssh authentication with the private key written to a file of the host, if it was only
+// specified inline, and any missing known hosts added to the known hosts file
+func PrepareSSHAuth(host string, sshAuth *auth.SSHAuth) (*auth.SSHAuth, error) {
+	answer := *sshAuth
+	if answer.PrivateKeyFile == "" && answer.PrivateKey != "" {
+		dir := filepath.Join(util.HomeDir(), ".ssh")
+		err := os.MkdirAll(dir, 0700)
+		if err != nil {
+			return nil, errors.Wrapf(err, "creating %s", dir)
+		}
+		answer.PrivateKeyFile = filepath.Join(dir, sshPrivateKeyFileName(host))
+		key := strings.TrimSpace(answer.PrivateKey) + "\n"
+		err = ioutil.WriteFile(answer.PrivateKeyFile, []byte(key), 0600)
+		if err != nil {
+			return nil, errors.Wrapf(err, "writing the ssh private key to %s", answer.PrivateKeyFile)
+		}
+	}
+	err := ensureKnownHosts(knownHostsFile(&answer), answer.KnownHosts)
+	if err != nil {
+		return nil, err
+	}
+	return &answer, nil
+}
+
+// sshPrivateKeyFileName returns the name of the file the inline private key of the host is written to so that the
+// keys of different git servers do not overwrite each other
+func sshPrivateKeyFileName(host string) string {
+	hash := sha256.Sum256([]byte(host))
+	return sshPrivateKeyFilePrefix + hex.EncodeToString(hash[:])[:16]
+}
+
+// ConfigureSSH makes subsequent git operations against the host of the git URL use the given ssh authentication. The
+// ssh command is passed to each git invocation with a URL of the host and stored in the configuration of the
+// repositories whose remotes are set to such a URL, so other hosts keep using the default ssh configuration
+func (g *GitCLI) ConfigureSSH(gitURL string, sshAuth *auth.SSHAuth) error {
+	host := sshHost(gitURL)
+	if host == "" {
+		return fmt.Errorf("no host in git URL %s", gitURL)
+	}
+	prepared, err := PrepareSSHAuth(host, sshAuth)
+	if err != nil {
+		return err
+	}
+	g.sshLock.Lock()
+	defer g.sshLock.Unlock()
+	if g.sshAuths == nil {
+		g.sshAuths = map[string]*auth.SSHAuth{}
+	}
+	g.sshAuths[host] = prepared
+	return nil
+}
+
+// sshAuthForURL returns the ssh authentication configured for the host of the ssh git URL or nil
+func (g *GitCLI) sshAuthForURL(gitURL string) *auth.SSHAuth {
+	if !IsSSHURL(gitURL) {
+		return nil
+	}
+	g.sshLock.Lock()
+	defer g.sshLock.Unlock()
+	return g.sshAuths[sshHost(gitURL)]
+}
+
+// commandEnv returns the environment of a git invocation with the given arguments, running ssh with the
+// authentication of the host of the first ssh URL argument which has one
+func (g *GitCLI) commandEnv(args []string) map[string]string {
+	for _, arg := range args {
+		sshAuth := g.sshAuthForURL(arg)
+		if sshAuth == nil {
+			continue
+		}
+		env := map[string]string{}
+		for k, v := range g.Env {
+			env[k] = v
+		}
+		env[gitSSHCommandEnvVar] = SSHCommand(sshAuth)
+		return env
+	}
+	return g.Env
+}
+
+// configureRemoteSSH stores the ssh command of the host of the remote URL in the configuration of the repository so
+// that fetching from and pushing to the remote by name uses it
+func (g *GitCLI) configureRemoteSSH(dir string, gitURL string) error {
+	sshAuth := g.sshAuthForURL(gitURL)
+	if sshAuth == nil {
+		return nil
+	}
+	return g.gitCmd(dir, "config", "core.sshCommand", SSHCommand(sshAuth))
+}
+
+// CreateAuthenticatedURL creates the Git repository URL with the username and password encoded for HTTPS based URLs
+// or converts it into a ssh URL if the user authenticates with ssh
+func (g *GoGit) CreateAuthenticatedURL(cloneURL string, userAuth *auth.UserAuth) (string, error) {
+	if userAuth != nil && userAuth.SSH != nil {
+		err := g.ConfigureSSH(cloneURL, userAuth.SSH)
+		if err != nil {
+			return "", errors.Wrap(err, "configuring ssh authentication")
+		}
+		return SSHCloneURL(cloneURL)
+	}
+	return g.GitCLI.CreateAuthenticatedURL(cloneURL, userAuth)
+}
+
+// authForURL returns the go-git authentication for the given URL
+func (g *GoGit) authForURL(rawURL string) transport.AuthMethod {
+	if !IsSSHURL(rawURL) {
+		return authFromURL(rawURL)
+	}
+	sshAuth := g.sshAuthForURL(rawURL)
+	if sshAuth == nil {
+		// go-git defaults to the ssh agent and the default known hosts
+		return nil
+	}
+	user := sshUser(rawURL)
+	var method gitssh.AuthMethod
+	if sshAuth.PrivateKeyFile != "" {
+		keys, err := gitssh.NewPublicKeysFromFile(user, sshAuth.PrivateKeyFile, "")
+		if err != nil {
+			log.Logger().Warnf("failed to load the ssh private key %s: %s", sshAuth.PrivateKeyFile, err)
+			return nil
+		}
+		keys.HostKeyCallback = hostKeyCallback(sshAuth)
+		method = keys
+	} else {
+		agent, err := gitssh.NewSSHAgentAuth(user)
+		if err != nil {
+			log.Logger().Warnf("failed to connect to the ssh agent: %s", err)
+			return nil
+		}
+		agent.HostKeyCallback = hostKeyCallback(sshAuth)
+		method = agent
+	}
+	return method
+}
+
+// hostKeyCallback verifies host keys against the known hosts file, adding the keys of unknown hosts if allowed
+func hostKeyCallback(sshAuth *auth.SSHAuth) ssh.HostKeyCallback {
+	fileName := knownHostsFile(sshAuth)
+	return func(hostname string, remote net.Addr, key ssh.PublicKey) error {
+		if _, err := os.Stat(fileName); os.IsNotExist(err) {
+			if !sshAuth.AcceptNewHostKeys {
+				return fmt.Errorf("no known hosts file %s to verify the host key of %s", fileName, hostname)
+			}
+			return addKnownHost(fileName, hostname, key)
+		}
+		callback, err := knownhosts.New(fileName)
+		if err != nil {
+			return errors.Wrapf(err, "reading the known hosts file %s", fileName)
+		}
+		err = callback(hostname, remote, key)
+		if keyErr, ok := err.(*knownhosts.KeyError); ok && len(keyErr.Want) == 0 && sshAuth.AcceptNewHostKeys {
+			return addKnownHost(fileName, hostname, key)
+		}
+		return err
+	}
+}
+
+func addKnownHost(fileName string, hostname string, key ssh.PublicKey) error {
+	log.Logger().Infof("adding the host key of %s to %s", hostname, fileName)
+	return ensureKnownHosts(fileName, []string{knownhosts.Line([]string{knownhosts.Normalize(hostname)}, key)})
+}
+
+// sshHost returns the lower case host name, without any user or port, of a https or ssh git URL
+func sshHost(gitURL string) string {
+	if u, err := url.Parse(gitURL); err == nil && u.Host != "" {
+		return strings.ToLower(u.Hostname())
+	}
+	// git@host:owner/repo.git
+	host := gitURL
+	if i := strings.Index(host, ":"); i >= 0 {
+		host = host[:i]
+	}
+	if i := strings.LastIndex(host, "@"); i >= 0 {
+		host = host[i+1:]
+	}
+	if strings.Contains(host, "/") {
+		return ""
+	}
+	return strings.ToLower(host)
+}
+
+// sshUser returns the user of a ssh git URL, defaulting to git
+func sshUser(gitURL string) string {
+	if u, err := url.Parse(gitURL); err == nil && u.Scheme == "ssh" && u.User != nil {
+		return u.User.Username()
+	}
+	if i := strings.Index(gitURL, "@"); i > 0 && !strings.Contains(gitURL[:i], "/") {
+		return gitURL[:i]
+	}
+	return "git"
+}
+
+func knownHostsFile(sshAuth *auth.SSHAuth) string {
+	if sshAuth.KnownHostsFile != "" {
+		return sshAuth.KnownHostsFile
+	}
+	return DefaultKnownHostsFile()
+}
+
+// ensureKnownHosts appends the lines which are not already in the known hosts file
+func ensureKnownHosts(fileName string, lines []string) error {
+	if len(lines) == 0 {
+		return nil
+	}
+	data, err := ioutil.ReadFile(fileName)
+	if err != nil && !os.IsNotExist(err) {
+		return errors.Wrapf(err, "reading %s", fileName)
+	}
+	existing := map[string]bool{}
+	for _, line := range strings.Split(string(data), "\n") {
+		existing[strings.TrimSpace(line)] = true
+	}
+	missing := []string{}
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if line != "" && !existing[line] {
+			missing = append(missing, line)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	err = os.MkdirAll(filepath.Dir(fileName), 0700)
+	if err != nil {
+		return errors.Wrapf(err, "creating the directory of %s", fileName)
+	}
+	out, err := os.OpenFile(fileName, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return errors.Wrapf(err, "opening %s", fileName)
+	}
+	defer out.Close() //nolint:errcheck
+	text := strings.Join(missing, "\n") + "\n"
+	if len(data) > 0 && !strings.HasSuffix(string(data), "\n") {
+		text = "\n" + text
+	}
+	_, err = out.WriteString(text)
+	return errors.Wrapf(err, "writing %s", fileName)
+}
+
+func shellQuote(text string) string {
+	return "'" + strings.Replace(text, "'", `'\''`, -1) + "'"
+}
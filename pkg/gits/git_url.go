@@ -148,6 +148,11 @@ func ParseGitURL(text string) (*GitRepository, error) {
 			answer.Host = arr[0]
 			answer.Organisation = arr[1]
 			answer.Name = arr[len(arr)-1]
+			// Azure DevOps SSH URLs are of the form git@ssh.dev.azure.com:v3/<org>/<project>/<repo>
+			if arr[1] == "v3" && len(arr) == 5 {
+				answer.Organisation = arr[2]
+				answer.Project = arr[3]
+			}
 			return &answer, nil
 		}
 	}
@@ -218,6 +223,9 @@ func parsePath(path string, info *GitRepository, requireRepo bool) (*GitReposito
 	trimPath = strings.TrimSuffix(trimPath, ".git")
 
 	arr := strings.Split(trimPath, "/")
+	if parseAzureDevOpsPath(arr, info) {
+		return info, nil
+	}
 	if len(arr) >= 2 {
 		// We're assuming the beginning of the path is of the form /<org>/<repo> or /<org>/<subgroup>/.../<repo>
 		info.Organisation = arr[0]
@@ -238,6 +246,9 @@ func parsePath(path string, info *GitRepository, requireRepo bool) (*GitReposito
 // SaasGitKind returns the kind for SaaS Git providers or "" if the URL could not be deduced
 func SaasGitKind(gitServiceUrl string) string {
 	gitServiceUrl = strings.TrimSuffix(gitServiceUrl, "/")
+	if kind := registeredProviderKind(gitServiceUrl); kind != "" {
+		return kind
+	}
 	switch gitServiceUrl {
 	case "http://github.com":
 		return KindGitHub
@@ -251,10 +262,15 @@ func SaasGitKind(gitServiceUrl string) string {
 		return KindBitBucketCloud
 	case "http://fake.git", FakeGitURL:
 		return KindGitFake
+	case AzureDevOpsURL:
+		return KindAzureDevOps
 	default:
 		if strings.HasPrefix(gitServiceUrl, "https://github") {
 			return KindGitHub
 		}
+		if IsAzureDevOpsURL(gitServiceUrl) {
+			return KindAzureDevOps
+		}
 		return ""
 	}
 }
@@ -269,5 +285,8 @@ func HttpCloneURL(repo *GitRepository, kind string) string {
 		return util.UrlJoin(host, "scm", repo.Organisation, repo.Name) + ".git"
 
 	}
+	if kind == KindAzureDevOps {
+		return AzureDevOpsCloneURL(repo)
+	}
 	return repo.HttpsURL() + ".git"
 }
@@ -133,6 +133,18 @@ func TestParseGitURL(t *testing.T) {
 		{
 			"https://bitbucketserver.com/projects/myproject/repos/foo/pull-requests/1/overview", "bitbucketserver.com", "myproject", "foo",
 		},
+		{
+			"https://dev.azure.com/myorg/myproject/_git/foo", "dev.azure.com", "myorg", "foo",
+		},
+		{
+			"https://myuser@dev.azure.com/myorg/myproject/_git/foo/pullrequest/12", "dev.azure.com", "myorg", "foo",
+		},
+		{
+			"https://myorg.visualstudio.com/DefaultCollection/myproject/_git/foo", "myorg.visualstudio.com", "myorg", "foo",
+		},
+		{
+			"git@ssh.dev.azure.com:v3/myorg/myproject/foo", "ssh.dev.azure.com", "myorg", "foo",
+		},
 	}
 	for _, data := range testCases {
 		info, err := gits.ParseGitURL(data.url)
@@ -158,6 +170,14 @@ func TestSaasKind(t *testing.T) {
 			gitURL: "https://github.test.com",
 			kind:   gits.KindGitHub,
 		},
+		"Azure DevOps": {
+			gitURL: "https://dev.azure.com",
+			kind:   gits.KindAzureDevOps,
+		},
+		"Azure DevOps legacy": {
+			gitURL: "https://myorg.visualstudio.com",
+			kind:   gits.KindAzureDevOps,
+		},
 	}
 
 	for name, tc := range tests {
@@ -224,6 +244,17 @@ func TestGitInfoHttpCloneURL(t *testing.T) {
 			kind:     gits.KindBitBucketServer,
 			expected: "https://bbs.something.com/scm/some-org/some-repo.git",
 		},
+		{
+			name: "azure devops",
+			gitInfo: &gits.GitRepository{
+				Name:         "some-repo",
+				Host:         "dev.azure.com",
+				Organisation: "some-org",
+				Project:      "some-project",
+			},
+			kind:     gits.KindAzureDevOps,
+			expected: "https://dev.azure.com/some-org/some-project/_git/some-repo",
+		},
 		{
 			name: "no kind",
 			gitInfo: &gits.GitRepository{
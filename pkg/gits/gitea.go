@@ -1,7 +1,6 @@
 package gits
 
 import (
-	"errors"
 	"fmt"
 	"os"
 	"strconv"
@@ -17,6 +16,9 @@ import (
 	"github.com/jenkins-x/jx/v2/pkg/util"
 )
 
+// giteaDefaultLabelColor the color of labels created when labelling pull requests
+const giteaDefaultLabelColor = "#ededed"
+
 type GiteaProvider struct {
 	Username string
 	Client   *gitea.Client
@@ -28,6 +30,7 @@ type GiteaProvider struct {
 
 func NewGiteaProvider(server *auth.AuthServer, user *auth.UserAuth, git Gitter) (GitProvider, error) {
 	client := gitea.NewClient(server.URL, user.ApiToken)
+	client.SetHTTPClient(NewAPIClient(0))
 
 	provider := GiteaProvider{
 		Client:   client,
@@ -172,6 +175,7 @@ func toGiteaRepo(name string, repo *gitea.Repository) *GitRepository {
 		HTMLURL:          repo.HTMLURL,
 		SSHURL:           repo.SSHURL,
 		Fork:             repo.Fork,
+		DefaultBranch:    repo.DefaultBranch,
 	}
 }
 
@@ -257,13 +261,65 @@ func (p *GiteaProvider) CreateWebHook(data *GitWebHookArguments) error {
 	return err
 }
 
+// ListWebHooks lists all webhooks for the specified repo.
 func (p *GiteaProvider) ListWebHooks(owner string, repo string) ([]*GitWebHookArguments, error) {
 	webHooks := []*GitWebHookArguments{}
-	return webHooks, fmt.Errorf("not implemented!")
+	hooks, err := p.Client.ListRepoHooks(owner, repo, gitea.ListHooksOptions{})
+	if err != nil {
+		return webHooks, errors2.Wrapf(err, "listing webhooks for %s/%s", owner, repo)
+	}
+	for _, hook := range hooks {
+		webHooks = append(webHooks, &GitWebHookArguments{
+			ID:    hook.ID,
+			Owner: owner,
+			Repo: &GitRepository{
+				Organisation: owner,
+				Name:         repo,
+			},
+			URL: hook.Config["url"],
+		})
+	}
+	return webHooks, nil
 }
 
+// UpdateWebHook updates the webhook with the existing URL or creates it if it does not exist
 func (p *GiteaProvider) UpdateWebHook(data *GitWebHookArguments) error {
-	return fmt.Errorf("not implemented!")
+	owner := data.Owner
+	if owner == "" {
+		owner = p.Username
+	}
+	if data.Repo == nil || data.Repo.Name == "" {
+		return fmt.Errorf("Missing property Repo")
+	}
+	repo := data.Repo.Name
+	hooks, err := p.Client.ListRepoHooks(owner, repo, gitea.ListHooksOptions{})
+	if err != nil {
+		return errors2.Wrapf(err, "listing webhooks for %s/%s", owner, repo)
+	}
+	for _, hook := range hooks {
+		if hook.Config["url"] != data.ExistingURL {
+			continue
+		}
+		config := map[string]string{
+			"url":          data.URL,
+			"content_type": "json",
+		}
+		if data.Secret != "" {
+			config["secret"] = data.Secret
+		}
+		active := true
+		log.Logger().Infof("Updating Gitea webhook for %s/%s from %s to %s", util.ColorInfo(owner), util.ColorInfo(repo), data.ExistingURL, util.ColorInfo(data.URL))
+		err = p.Client.EditRepoHook(owner, repo, hook.ID, gitea.EditHookOption{
+			Config: config,
+			Events: []string{"create", "push", "pull_request"},
+			Active: &active,
+		})
+		if err != nil {
+			return errors2.Wrapf(err, "updating webhook %d for %s/%s", hook.ID, owner, repo)
+		}
+		return nil
+	}
+	return p.CreateWebHook(data)
 }
 
 func (p *GiteaProvider) CreatePullRequest(data *GitPullRequestArguments) (*GitPullRequest, error) {
@@ -274,6 +330,7 @@ func (p *GiteaProvider) CreatePullRequest(data *GitPullRequestArguments) (*GitPu
 	head := data.Head
 	base := data.Base
 	config := gitea.CreatePullRequestOption{}
+	var err error
 	if title != "" {
 		config.Title = title
 	}
@@ -286,26 +343,74 @@ func (p *GiteaProvider) CreatePullRequest(data *GitPullRequestArguments) (*GitPu
 	if base != "" {
 		config.Base = base
 	}
+	if len(data.Labels) > 0 {
+		config.Labels, err = p.labelIDs(owner, repo, data.Labels)
+		if err != nil {
+			return nil, err
+		}
+	}
 	pr, err := p.Client.CreatePullRequest(owner, repo, config)
 	if err != nil {
 		return nil, err
 	}
-	id := int(pr.Index)
-	answer := &GitPullRequest{
-		URL:    pr.HTMLURL,
-		Number: &id,
-		Owner:  data.GitRepository.Organisation,
-		Repo:   data.GitRepository.Name,
-	}
-	if pr.Head != nil {
-		answer.LastCommitSha = pr.Head.Sha
-	}
-	return answer, nil
+	return p.toPullRequest(owner, repo, pr), nil
 }
 
 // UpdatePullRequest updates pull request with number using data
 func (p *GiteaProvider) UpdatePullRequest(data *GitPullRequestArguments, number int) (*GitPullRequest, error) {
-	return nil, errors2.Errorf("Not yet implemented for gitea")
+	owner := data.GitRepository.Organisation
+	repo := data.GitRepository.Name
+	config := gitea.EditPullRequestOption{
+		Title: data.Title,
+		Body:  data.Body,
+	}
+	pr, err := p.Client.EditPullRequest(owner, repo, int64(number), config)
+	if err != nil {
+		return nil, errors2.Wrapf(err, "updating pull request %s/%s #%d", owner, repo, number)
+	}
+	return p.toPullRequest(owner, repo, pr), nil
+}
+
+// AssignPullRequest assigns the pull request to the given users
+func (p *GiteaProvider) AssignPullRequest(pr *GitPullRequest, assignees []string) error {
+	if pr.Number == nil {
+		return fmt.Errorf("missing number for pull request %s", pr.URL)
+	}
+	_, err := p.Client.EditPullRequest(pr.Owner, pr.Repo, int64(*pr.Number), gitea.EditPullRequestOption{
+		Assignees: assignees,
+	})
+	return err
+}
+
+// labelIDs returns the IDs of the labels in the repository creating any labels which do not exist
+func (p *GiteaProvider) labelIDs(owner string, repo string, labels []string) ([]int64, error) {
+	existing, err := p.Client.ListRepoLabels(owner, repo, gitea.ListLabelsOptions{})
+	if err != nil {
+		return nil, errors2.Wrapf(err, "listing labels for %s/%s", owner, repo)
+	}
+	answer := []int64{}
+	for _, name := range labels {
+		found := false
+		for _, label := range existing {
+			if label.Name == name {
+				answer = append(answer, label.ID)
+				found = true
+				break
+			}
+		}
+		if found {
+			continue
+		}
+		label, err := p.Client.CreateLabel(owner, repo, gitea.CreateLabelOption{
+			Name:  name,
+			Color: giteaDefaultLabelColor,
+		})
+		if err != nil {
+			return nil, errors2.Wrapf(err, "creating label %s for %s/%s", name, owner, repo)
+		}
+		answer = append(answer, label.ID)
+	}
+	return answer, nil
 }
 
 func (p *GiteaProvider) UpdatePullRequestStatus(pr *GitPullRequest) error {
@@ -338,9 +443,26 @@ func (p *GiteaProvider) updatePullRequest(pr *GitPullRequest, source *gitea.Pull
 	head := source.Head
 	if head != nil {
 		pr.LastCommitSha = head.Sha
+		headRef := head.Ref
+		pr.HeadRef = &headRef
+		if head.Repository != nil && head.Repository.Owner != nil {
+			headOwner := head.Repository.Owner.UserName
+			pr.HeadOwner = &headOwner
+		}
 	} else {
 		pr.LastCommitSha = ""
 	}
+	pr.Labels = nil
+	for _, l := range source.Labels {
+		label := l
+		pr.Labels = append(pr.Labels, &Label{
+			ID:          &label.ID,
+			URL:         &label.URL,
+			Name:        &label.Name,
+			Color:       &label.Color,
+			Description: &label.Description,
+		})
+	}
 	/*
 		TODO
 
@@ -354,7 +476,7 @@ func (p *GiteaProvider) updatePullRequest(pr *GitPullRequest, source *gitea.Pull
 func (p *GiteaProvider) toPullRequest(owner string, repo string, pr *gitea.PullRequest) *GitPullRequest {
 	id := int(pr.Index)
 	answer := &GitPullRequest{
-		URL:    pr.URL,
+		URL:    pr.HTMLURL,
 		Owner:  owner,
 		Repo:   repo,
 		Number: &id,
@@ -365,7 +487,9 @@ func (p *GiteaProvider) toPullRequest(owner string, repo string, pr *gitea.PullR
 
 // ListOpenPullRequests lists the open pull requests
 func (p *GiteaProvider) ListOpenPullRequests(owner string, repo string) ([]*GitPullRequest, error) {
-	opt := gitea.ListPullRequestsOptions{}
+	opt := gitea.ListPullRequestsOptions{
+		State: gitea.StateOpen,
+	}
 	answer := []*GitPullRequest{}
 	for {
 		prs, err := p.Client.ListRepoPullRequests(owner, repo, opt)
@@ -428,14 +552,29 @@ func (p *GiteaProvider) IssueURL(org string, name string, number int, isPull boo
 	return url
 }
 
+// SearchIssues returns the issues in the state of the filter, which is open, closed or all, or for any other filter the
+// open issues with all of its comma separated labels
 func (p *GiteaProvider) SearchIssues(org string, name string, filter string) ([]*GitIssue, error) {
 	opts := gitea.ListIssueOption{}
-	// TODO apply the filter?
+	switch filter {
+	case "":
+	case string(gitea.StateOpen), string(gitea.StateClosed), string(gitea.StateAll):
+		opts.State = gitea.StateType(filter)
+	default:
+		for _, label := range strings.Split(filter, ",") {
+			label = strings.TrimSpace(label)
+			if label != "" {
+				opts.Labels = append(opts.Labels, label)
+			}
+		}
+	}
 	return p.searchIssuesWithOptions(org, name, opts)
 }
 
 func (p *GiteaProvider) SearchIssuesClosedSince(org string, name string, t time.Time) ([]*GitIssue, error) {
-	opts := gitea.ListIssueOption{}
+	opts := gitea.ListIssueOption{
+		State: gitea.StateClosed,
+	}
 	issues, err := p.searchIssuesWithOptions(org, name, opts)
 	if err != nil {
 		return issues, err
@@ -581,7 +720,7 @@ func (p *GiteaProvider) ListCommitStatus(org string, repo string, sha string) ([
 	}
 	for _, result := range results {
 		status := &GitRepoStatus{
-			ID:          string(result.ID),
+			ID:          strconv.FormatInt(result.ID, 10),
 			Context:     result.Context,
 			URL:         result.URL,
 			TargetURL:   result.TargetURL,
@@ -593,8 +732,25 @@ func (p *GiteaProvider) ListCommitStatus(org string, repo string, sha string) ([
 	return answer, nil
 }
 
-func (b *GiteaProvider) UpdateCommitStatus(org string, repo string, sha string, status *GitRepoStatus) (*GitRepoStatus, error) {
-	return &GitRepoStatus{}, errors.New("TODO")
+// UpdateCommitStatus updates the status of a specified commit in a specified repo.
+func (p *GiteaProvider) UpdateCommitStatus(org string, repo string, sha string, status *GitRepoStatus) (*GitRepoStatus, error) {
+	result, err := p.Client.CreateStatus(org, repo, sha, gitea.CreateStatusOption{
+		State:       gitea.StatusState(status.State),
+		TargetURL:   status.TargetURL,
+		Description: status.Description,
+		Context:     status.Context,
+	})
+	if err != nil {
+		return nil, errors2.Wrapf(err, "updating status for %s/%s with ref %s", org, repo, sha)
+	}
+	return &GitRepoStatus{
+		ID:          strconv.FormatInt(result.ID, 10),
+		Context:     result.Context,
+		URL:         result.URL,
+		TargetURL:   result.TargetURL,
+		State:       string(result.State),
+		Description: result.Description,
+	}, nil
 }
 
 func (p *GiteaProvider) RenameRepository(org string, name string, newName string) (*GitRepository, error) {
@@ -786,8 +942,29 @@ func (p *GiteaProvider) AcceptInvitation(ID int64) (*github.Response, error) {
 	return &github.Response{}, nil
 }
 
+// GetContent returns the base64 encoded content of the file at the path of the ref, which defaults to the default
+// branch if blank
 func (p *GiteaProvider) GetContent(org string, name string, path string, ref string) (*GitFileContent, error) {
-	return nil, fmt.Errorf("Getting content not supported on gitea")
+	content, err := p.Client.GetContents(org, name, ref, path)
+	if err != nil {
+		return nil, errors2.Wrapf(err, "getting the content of %s in %s/%s", path, org, name)
+	}
+	if content.Type != "file" {
+		return nil, fmt.Errorf("%s in %s/%s is a %s rather than a file", path, org, name, content.Type)
+	}
+	return &GitFileContent{
+		Type:        content.Type,
+		Encoding:    util.DereferenceString(content.Encoding),
+		Size:        int(content.Size),
+		Name:        content.Name,
+		Path:        content.Path,
+		Content:     util.DereferenceString(content.Content),
+		Sha:         content.SHA,
+		Url:         util.DereferenceString(content.URL),
+		GitUrl:      util.DereferenceString(content.GitURL),
+		HtmlUrl:     util.DereferenceString(content.HTMLURL),
+		DownloadUrl: util.DereferenceString(content.DownloadURL),
+	}, nil
 }
 
 // ShouldForkForPullReques treturns true if we should create a personal fork of this repository
@@ -796,13 +973,74 @@ func (p *GiteaProvider) ShouldForkForPullRequest(originalOwner string, repoName
 	return originalOwner != username
 }
 
+// ListCommits lists the commits of the repository starting from the SHA or branch of the arguments. Gitea cannot
+// filter the commits by path, author or date so those arguments are not supported
 func (p *GiteaProvider) ListCommits(owner, repo string, opt *ListCommitsArguments) ([]*GitCommit, error) {
-	return nil, fmt.Errorf("Listing commits not supported on gitea")
+	if opt.Path != "" || opt.Author != "" || !opt.Since.IsZero() || !opt.Until.IsZero() {
+		return nil, fmt.Errorf("listing commits by path, author or date is not supported on gitea")
+	}
+	commits, err := p.Client.ListRepoCommits(owner, repo, gitea.ListCommitOptions{
+		ListOptions: gitea.ListOptions{
+			Page:     opt.Page,
+			PageSize: opt.PerPage,
+		},
+		SHA: opt.SHA,
+	})
+	if err != nil {
+		return nil, errors2.Wrapf(err, "listing the commits of %s/%s", owner, repo)
+	}
+	answer := []*GitCommit{}
+	for _, commit := range commits {
+		answer = append(answer, toGiteaCommit(commit))
+	}
+	return answer, nil
+}
+
+func toGiteaCommit(commit *gitea.Commit) *GitCommit {
+	answer := &GitCommit{
+		URL: commit.HTMLURL,
+	}
+	if commit.CommitMeta != nil {
+		answer.SHA = commit.SHA
+	}
+	if commit.Author != nil {
+		answer.Author = toGiteaUser(commit.Author)
+	}
+	if commit.Committer != nil {
+		answer.Committer = toGiteaUser(commit.Committer)
+	}
+	if commit.RepoCommit != nil {
+		answer.Message = commit.RepoCommit.Message
+		// the commits of users unknown to gitea only have the git identity
+		if answer.Author == nil && commit.RepoCommit.Author != nil {
+			answer.Author = &GitUser{
+				Name:  commit.RepoCommit.Author.Name,
+				Email: commit.RepoCommit.Author.Email,
+			}
+		}
+		if answer.Committer == nil && commit.RepoCommit.Committer != nil {
+			answer.Committer = &GitUser{
+				Name:  commit.RepoCommit.Committer.Name,
+				Email: commit.RepoCommit.Committer.Email,
+			}
+		}
+	}
+	return answer
 }
 
 // AddLabelsToIssue adds labels to issues or pullrequests
 func (p *GiteaProvider) AddLabelsToIssue(owner, repo string, number int, labels []string) error {
-	return fmt.Errorf("Getting content not supported on gitea")
+	ids, err := p.labelIDs(owner, repo, labels)
+	if err != nil {
+		return err
+	}
+	_, err = p.Client.AddIssueLabels(owner, repo, int64(number), gitea.IssueLabelsOption{
+		Labels: ids,
+	})
+	if err != nil {
+		return errors2.Wrapf(err, "adding labels %s to %s/%s #%d", strings.Join(labels, ", "), owner, repo, number)
+	}
+	return nil
 }
 
 // GetLatestRelease fetches the latest release from the git provider for org and name
@@ -864,7 +1102,7 @@ func (p *GiteaProvider) GetProjects(owner string, repo string) ([]GitProject, er
 	return nil, nil
 }
 
-//ConfigureFeatures sets specific features as enabled or disabled for owner/repo
+// ConfigureFeatures sets specific features as enabled or disabled for owner/repo
 func (p *GiteaProvider) ConfigureFeatures(owner string, repo string, issues *bool, projects *bool, wikis *bool) (*GitRepository, error) {
 	return nil, nil
 }
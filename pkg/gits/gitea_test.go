@@ -0,0 +1,127 @@
+// +build unit
+
+package gits_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/jenkins-x/jx/v2/pkg/auth"
+	"github.com/jenkins-x/jx/v2/pkg/gits"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newGiteaTestProvider returns a Gitea provider talking to a test server with the handlers of the API paths
+func newGiteaTestProvider(t *testing.T, handlers map[string]http.HandlerFunc) (gits.GitProvider, func()) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/version", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"version": "1.12.0"}`)
+	})
+	for path, handler := range handlers {
+		mux.HandleFunc(path, handler)
+	}
+	server := httptest.NewServer(mux)
+
+	provider, err := gits.NewGiteaProvider(&auth.AuthServer{URL: server.URL, Kind: gits.KindGitea},
+		&auth.UserAuth{Username: "test-user", ApiToken: "test-token"}, nil)
+	require.NoError(t, err)
+	return provider, server.Close
+}
+
+func TestGiteaSearchIssues(t *testing.T) {
+	t.Parallel()
+
+	var queries []url.Values
+	provider, closeServer := newGiteaTestProvider(t, map[string]http.HandlerFunc{
+		"/api/v1/repos/myorg/myrepo/issues": func(w http.ResponseWriter, r *http.Request) {
+			queries = append(queries, r.URL.Query())
+			fmt.Fprint(w, `[{"id": 3, "title": "flaky test", "state": "open", "user": {"login": "jstrachan"},
+				"labels": [{"name": "bug"}, {"name": "help wanted"}]}]`)
+		},
+	})
+	defer closeServer()
+
+	issues, err := provider.SearchIssues("myorg", "myrepo", "bug, help wanted")
+	require.NoError(t, err)
+	require.Len(t, issues, 1)
+	assert.Equal(t, "flaky test", issues[0].Title)
+	require.Len(t, issues[0].Labels, 2)
+	assert.Equal(t, "help wanted", issues[0].Labels[1].Name)
+
+	_, err = provider.SearchIssues("myorg", "myrepo", "closed")
+	require.NoError(t, err)
+
+	require.Len(t, queries, 2)
+	assert.Equal(t, "bug,help wanted", queries[0].Get("labels"), "the filter should be sent as labels")
+	assert.Equal(t, "closed", queries[1].Get("state"), "a state filter should be sent as the state")
+	assert.Equal(t, "", queries[1].Get("labels"))
+}
+
+func TestGiteaGetContent(t *testing.T) {
+	t.Parallel()
+
+	var ref string
+	provider, closeServer := newGiteaTestProvider(t, map[string]http.HandlerFunc{
+		"/api/v1/repos/myorg/myrepo/contents/charts/myapp/values.yaml": func(w http.ResponseWriter, r *http.Request) {
+			ref = r.URL.Query().Get("ref")
+			fmt.Fprint(w, `{"name": "values.yaml", "path": "charts/myapp/values.yaml", "sha": "abc123", "type": "file",
+				"size": 11, "encoding": "base64", "content": "cmVwbGljYXM6IDE="}`)
+		},
+		"/api/v1/repos/myorg/myrepo/contents/charts": func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, `{"name": "charts", "path": "charts", "sha": "def456", "type": "dir"}`)
+		},
+	})
+	defer closeServer()
+
+	content, err := provider.GetContent("myorg", "myrepo", "charts/myapp/values.yaml", "v1.0.0")
+	require.NoError(t, err)
+	assert.Equal(t, "v1.0.0", ref)
+	assert.Equal(t, "values.yaml", content.Name)
+	assert.Equal(t, "charts/myapp/values.yaml", content.Path)
+	assert.Equal(t, "abc123", content.Sha)
+	assert.Equal(t, "base64", content.Encoding)
+	assert.Equal(t, "cmVwbGljYXM6IDE=", content.Content)
+
+	_, err = provider.GetContent("myorg", "myrepo", "charts", "")
+	assert.Error(t, err, "getting the content of a directory should fail")
+}
+
+func TestGiteaListCommits(t *testing.T) {
+	t.Parallel()
+
+	var query url.Values
+	provider, closeServer := newGiteaTestProvider(t, map[string]http.HandlerFunc{
+		"/api/v1/repos/myorg/myrepo/commits": func(w http.ResponseWriter, r *http.Request) {
+			query = r.URL.Query()
+			fmt.Fprint(w, `[
+				{"sha": "abc123", "html_url": "https://gitea.example.com/myorg/myrepo/commit/abc123",
+					"commit": {"message": "fix: the build", "author": {"name": "James", "email": "james@example.com"}},
+					"author": {"login": "jstrachan", "full_name": "James Strachan", "email": "james@example.com"}},
+				{"sha": "def456", "html_url": "https://gitea.example.com/myorg/myrepo/commit/def456",
+					"commit": {"message": "chore: release", "author": {"name": "Bot", "email": "bot@example.com"}}}
+			]`)
+		},
+	})
+	defer closeServer()
+
+	commits, err := provider.ListCommits("myorg", "myrepo", &gits.ListCommitsArguments{SHA: "main", Page: 2})
+	require.NoError(t, err)
+	assert.Equal(t, "main", query.Get("sha"))
+	assert.Equal(t, "2", query.Get("page"))
+
+	require.Len(t, commits, 2)
+	assert.Equal(t, "abc123", commits[0].SHA)
+	assert.Equal(t, "fix: the build", commits[0].Message)
+	assert.Equal(t, "https://gitea.example.com/myorg/myrepo/commit/abc123", commits[0].URL)
+	require.NotNil(t, commits[0].Author)
+	assert.Equal(t, "jstrachan", commits[0].Author.Login)
+	require.NotNil(t, commits[1].Author)
+	assert.Equal(t, "bot@example.com", commits[1].Author.Email, "the git identity should be used for unknown users")
+
+	_, err = provider.ListCommits("myorg", "myrepo", &gits.ListCommitsArguments{Path: "README.md"})
+	assert.Error(t, err, "filtering by path is not supported")
+}
@@ -15,6 +15,7 @@ import (
 	"github.com/pkg/errors"
 
 	"github.com/google/go-github/github"
+	v1 "github.com/jenkins-x/jx/v2/pkg/apis/jenkins.io/v1"
 	"github.com/jenkins-x/jx/v2/pkg/auth"
 	"github.com/jenkins-x/jx/v2/pkg/log"
 	"github.com/jenkins-x/jx/v2/pkg/util"
@@ -33,6 +34,9 @@ type GitHubProvider struct {
 	Server auth.AuthServer
 	User   auth.UserAuth
 	Git    Gitter
+
+	// tokenSource mints installation tokens when authenticating as a GitHub App
+	tokenSource oauth2.TokenSource
 }
 
 func NewGitHubProvider(server *auth.AuthServer, user *auth.UserAuth, git Gitter) (GitProvider, error) {
@@ -46,17 +50,37 @@ func NewGitHubProvider(server *auth.AuthServer, user *auth.UserAuth, git Gitter)
 		Git:      git,
 	}
 
-	ts := oauth2.StaticTokenSource(
-		&oauth2.Token{AccessToken: user.ApiToken},
-	)
-	tc := oauth2.NewClient(ctx, ts)
+	var ts oauth2.TokenSource
+	if user.IsGithubApp() {
+		var err error
+		ts, err = NewGitHubAppTokenSource(server.URL, user)
+		if err != nil {
+			return nil, err
+		}
+		provider.tokenSource = ts
+	} else {
+		ts = oauth2.StaticTokenSource(
+			&oauth2.Token{AccessToken: user.ApiToken},
+		)
+	}
+	// the token is added before caching so that responses are cached per user
+	tc := &http.Client{
+		Transport: &oauth2.Transport{
+			Source: oauth2.ReuseTokenSource(nil, ts),
+			Base:   NewAPITransport(nil),
+		},
+	}
 
 	traceGitHubAPI := os.Getenv("TRACE_GITHUB_API")
 	if traceGitHubAPI == "1" || traceGitHubAPI == "on" {
 		tc.Transport = &trace.Tracer{tc.Transport}
 	}
 
-	return newGitHubProviderFromOauthClient(tc, provider)
+	gp, err := newGitHubProviderFromOauthClient(tc, provider)
+	if err != nil || !UseGitHubGraphQL() {
+		return gp, err
+	}
+	return NewGitHubGraphQLProvider(gp.(*GitHubProvider), tc), nil
 }
 
 // NewAnonymousGitHubProvider returns a new GitHubProvider without any authentication
@@ -70,7 +94,7 @@ func NewAnonymousGitHubProvider(server *auth.AuthServer, git Gitter) (GitProvide
 		Git:     git,
 	}
 
-	return newGitHubProviderFromOauthClient(nil, provider)
+	return newGitHubProviderFromOauthClient(NewAPIClient(0), provider)
 }
 
 func newGitHubProviderFromOauthClient(tc *http.Client, provider GitHubProvider) (GitProvider, error) {
@@ -383,6 +407,7 @@ func toGitHubRepo(name string, org string, repo *github.Repository) *GitReposito
 		HasWiki:          repo.GetHasWiki(),
 		HasProjects:      repo.GetHasProjects(),
 		Archived:         repo.GetArchived(),
+		DefaultBranch:    repo.GetDefaultBranch(),
 	}
 }
 
@@ -609,7 +634,14 @@ func (p *GitHubProvider) CreatePullRequest(data *GitPullRequestArguments) (*GitP
 	if base != "" {
 		config.Base = github.String(base)
 	}
-	pr, resp, err := p.Client.PullRequests.Create(p.Context, owner, repo, config)
+	var pr *github.PullRequest
+	var resp *github.Response
+	var err error
+	if data.Draft {
+		pr, resp, err = p.createDraftPullRequest(owner, repo, config)
+	} else {
+		pr, resp, err = p.Client.PullRequests.Create(p.Context, owner, repo, config)
+	}
 	if err != nil {
 		if resp != nil && resp.Body != nil {
 			data, err2 := ioutil.ReadAll(resp.Body)
@@ -627,6 +659,167 @@ func (p *GitHubProvider) CreatePullRequest(data *GitPullRequestArguments) (*GitP
 	}, nil
 }
 
+// createDraftPullRequest creates a draft pull request which the version of go-github we use does not support
+func (p *GitHubProvider) createDraftPullRequest(owner string, repo string, config *github.NewPullRequest) (*github.PullRequest, *github.Response, error) {
+	body := struct {
+		*github.NewPullRequest
+		Draft bool `json:"draft"`
+	}{
+		NewPullRequest: config,
+		Draft:          true,
+	}
+	req, err := p.Client.NewRequest(http.MethodPost, fmt.Sprintf("repos/%v/%v/pulls", owner, repo), body)
+	if err != nil {
+		return nil, nil, err
+	}
+	req.Header.Set("Accept", "application/vnd.github.shadow-cat-preview+json")
+	pr := &github.PullRequest{}
+	resp, err := p.Client.Do(p.Context, req, pr)
+	if err != nil {
+		return nil, resp, err
+	}
+	return pr, resp, nil
+}
+
+// RequestReviewers requests reviews of the pull request from the given users
+func (p *GitHubProvider) RequestReviewers(pr *GitPullRequest, reviewers []string) error {
+	if pr.Number == nil {
+		return fmt.Errorf("missing number for pull request %s", pr.URL)
+	}
+	_, _, err := p.Client.PullRequests.RequestReviewers(p.Context, pr.Owner, pr.Repo, *pr.Number, github.ReviewersRequest{
+		Reviewers: reviewers,
+	})
+	return err
+}
+
+// RequestTeamReviewers requests reviews of the pull request from the given teams of the organisation
+func (p *GitHubProvider) RequestTeamReviewers(pr *GitPullRequest, teams []string) error {
+	if pr.Number == nil {
+		return fmt.Errorf("missing number for pull request %s", pr.URL)
+	}
+	_, _, err := p.Client.PullRequests.RequestReviewers(p.Context, pr.Owner, pr.Repo, *pr.Number, github.ReviewersRequest{
+		TeamReviewers: teams,
+	})
+	return err
+}
+
+// AssignPullRequest assigns the pull request to the given users
+func (p *GitHubProvider) AssignPullRequest(pr *GitPullRequest, assignees []string) error {
+	if pr.Number == nil {
+		return fmt.Errorf("missing number for pull request %s", pr.URL)
+	}
+	_, _, err := p.Client.Issues.AddAssignees(p.Context, pr.Owner, pr.Repo, *pr.Number, assignees)
+	return err
+}
+
+// EnableAutoMerge makes GitHub merge the pull request once its required checks pass. Auto merge must be allowed in
+// the settings of the repository
+func (p *GitHubProvider) EnableAutoMerge(pr *GitPullRequest) error {
+	id, err := p.pullRequestNodeID(pr)
+	if err != nil {
+		return err
+	}
+	result := map[string]interface{}{}
+	return p.graphQL(p.Context, `mutation($id: ID!) {
+  enablePullRequestAutoMerge(input: {pullRequestId: $id}) { clientMutationId }
+}`, map[string]interface{}{
+		"id": id,
+	}, &result)
+}
+
+// EnqueuePullRequest adds the pull request to the merge queue of its base branch. The branch protection of the base
+// branch must require a merge queue
+func (p *GitHubProvider) EnqueuePullRequest(pr *GitPullRequest) error {
+	id, err := p.pullRequestNodeID(pr)
+	if err != nil {
+		return err
+	}
+	result := map[string]interface{}{}
+	return p.graphQL(p.Context, `mutation($id: ID!) {
+  enqueuePullRequest(input: {pullRequestId: $id}) { clientMutationId }
+}`, map[string]interface{}{
+		"id": id,
+	}, &result)
+}
+
+// GetMergeQueueEntry returns the entry of the pull request in the merge queue of its base branch, or nil if it is not
+// queued
+func (p *GitHubProvider) GetMergeQueueEntry(pr *GitPullRequest) (*MergeQueueEntry, error) {
+	if pr.Number == nil {
+		return nil, fmt.Errorf("missing number for pull request %s", pr.URL)
+	}
+	result := struct {
+		Repository struct {
+			PullRequest struct {
+				MergeQueueEntry *struct {
+					State    string `json:"state"`
+					Position int    `json:"position"`
+				} `json:"mergeQueueEntry"`
+			} `json:"pullRequest"`
+		} `json:"repository"`
+	}{}
+	err := p.graphQL(WithReadOnlyRequest(p.Context), `query($owner: String!, $repo: String!, $number: Int!) {
+  repository(owner: $owner, name: $repo) { pullRequest(number: $number) { mergeQueueEntry { state position } } }
+}`, map[string]interface{}{
+		"owner":  pr.Owner,
+		"repo":   pr.Repo,
+		"number": *pr.Number,
+	}, &result)
+	if err != nil {
+		return nil, errors.Wrapf(err, "getting the merge queue entry of pull request %s", pr.URL)
+	}
+	entry := result.Repository.PullRequest.MergeQueueEntry
+	if entry == nil {
+		return nil, nil
+	}
+	return &MergeQueueEntry{
+		State:    entry.State,
+		Position: entry.Position,
+	}, nil
+}
+
+// pullRequestNodeID returns the GraphQL node ID of the pull request
+func (p *GitHubProvider) pullRequestNodeID(pr *GitPullRequest) (string, error) {
+	if pr.Number == nil {
+		return "", fmt.Errorf("missing number for pull request %s", pr.URL)
+	}
+	lookup := struct {
+		Repository struct {
+			PullRequest struct {
+				ID string `json:"id"`
+			} `json:"pullRequest"`
+		} `json:"repository"`
+	}{}
+	err := p.graphQL(WithReadOnlyRequest(p.Context), `query($owner: String!, $repo: String!, $number: Int!) {
+  repository(owner: $owner, name: $repo) { pullRequest(number: $number) { id } }
+}`, map[string]interface{}{
+		"owner":  pr.Owner,
+		"repo":   pr.Repo,
+		"number": *pr.Number,
+	}, &lookup)
+	if err != nil {
+		return "", errors.Wrapf(err, "finding the node ID of pull request %s", pr.URL)
+	}
+	return lookup.Repository.PullRequest.ID, nil
+}
+
+// graphQL runs a GraphQL query or mutation with the client of the REST API
+func (p *GitHubProvider) graphQL(ctx context.Context, query string, variables map[string]interface{}, result interface{}) error {
+	req, err := p.Client.NewRequest(http.MethodPost, GitHubGraphQLURL(p.Server.URL), map[string]interface{}{
+		"query":     query,
+		"variables": variables,
+	})
+	if err != nil {
+		return err
+	}
+	response := graphQLResponse{}
+	_, err = p.Client.Do(ctx, req, &response)
+	if err != nil {
+		return err
+	}
+	return response.decode(result)
+}
+
 // UpdatePullRequest updates pull request with number using data
 func (p *GitHubProvider) UpdatePullRequest(data *GitPullRequestArguments, number int) (*GitPullRequest, error) {
 	owner := data.GitRepository.Organisation
@@ -1370,10 +1563,29 @@ func (p *GitHubProvider) CurrentUsername() string {
 	return p.Username
 }
 
+// UserAuth returns the user authentication. When authenticating as a GitHub App the API token is a freshly minted
+// installation token so that it can be used in authenticated git URLs
 func (p *GitHubProvider) UserAuth() auth.UserAuth {
+	if p.tokenSource != nil {
+		user, err := githubAppUserAuth(p.tokenSource, &p.User)
+		if err != nil {
+			log.Logger().Warnf("%s", err)
+			return p.User
+		}
+		return *user
+	}
 	return p.User
 }
 
+// GetTokenInfo returns the OAuth scopes of the token and when it expires
+func (p *GitHubProvider) GetTokenInfo() (*TokenInfo, error) {
+	_, resp, err := p.Client.Users.Get(p.Context, "")
+	if err != nil {
+		return nil, errors.Wrap(err, "getting the authenticated user")
+	}
+	return parseGitHubTokenInfo(resp.Header), nil
+}
+
 func (p *GitHubProvider) UserInfo(username string) *GitUser {
 	user, _, err := p.Client.Users.Get(p.Context, username)
 	if user == nil || err != nil {
@@ -1537,7 +1749,7 @@ func (p *GitHubProvider) toProject(project *github.Project, state string) GitPro
 	}
 }
 
-//ConfigureFeatures sets specific features as enabled or disabled for owner/repo
+// ConfigureFeatures sets specific features as enabled or disabled for owner/repo
 func (p *GitHubProvider) ConfigureFeatures(owner string, repo string, issues *bool, projects *bool, wikis *bool) (*GitRepository, error) {
 	r, _, err := p.Client.Repositories.Get(p.Context, owner, repo)
 	if err != nil {
@@ -1559,6 +1771,88 @@ func (p *GitHubProvider) ConfigureFeatures(owner string, repo string, issues *bo
 	return toGitHubRepo(repo, owner, r), nil
 }
 
+// ConfigureRepositorySettings configures the merge strategies and labels of the repository and protects the branch
+func (p *GitHubProvider) ConfigureRepositorySettings(owner string, repo string, branch string, settings *v1.RepositorySettings) error {
+	if len(settings.MergeStrategies) > 0 {
+		edit := &github.Repository{
+			AllowMergeCommit: github.Bool(util.Contains(settings.MergeStrategies, MergeStrategyMerge)),
+			AllowSquashMerge: github.Bool(util.Contains(settings.MergeStrategies, MergeStrategySquash)),
+			AllowRebaseMerge: github.Bool(util.Contains(settings.MergeStrategies, MergeStrategyRebase)),
+		}
+		_, _, err := p.Client.Repositories.Edit(p.Context, owner, repo, edit)
+		if err != nil {
+			return errors.Wrapf(err, "Failed to edit the merge strategies of repository %s/%s", owner, repo)
+		}
+	}
+
+	if protection := settings.BranchProtection; protection != nil {
+		preq := &github.ProtectionRequest{
+			EnforceAdmins: protection.EnforceAdmins,
+		}
+		if len(protection.RequiredStatusChecks) > 0 || protection.Strict {
+			preq.RequiredStatusChecks = &github.RequiredStatusChecks{
+				Strict:   protection.Strict,
+				Contexts: protection.RequiredStatusChecks,
+			}
+		}
+		if protection.RequiredApprovingReviewCount > 0 || protection.DismissStaleReviews || protection.RequireCodeOwnerReviews {
+			preq.RequiredPullRequestReviews = &github.PullRequestReviewsEnforcementRequest{
+				DismissStaleReviews:          protection.DismissStaleReviews,
+				RequireCodeOwnerReviews:      protection.RequireCodeOwnerReviews,
+				RequiredApprovingReviewCount: protection.RequiredApprovingReviewCount,
+			}
+		}
+		_, _, err := p.Client.Repositories.UpdateBranchProtection(p.Context, owner, repo, branch, preq)
+		if err != nil {
+			return errors.Wrapf(err, "Failed to protect branch %s of repository %s/%s", branch, owner, repo)
+		}
+	}
+
+	if len(settings.Labels) > 0 {
+		existing := map[string]*github.Label{}
+		options := &github.ListOptions{
+			Page:    0,
+			PerPage: pageSize,
+		}
+		for {
+			labels, _, err := p.Client.Issues.ListLabels(p.Context, owner, repo, options)
+			if err != nil {
+				return errors.Wrapf(err, "Failed to list the labels of repository %s/%s", owner, repo)
+			}
+			for _, label := range labels {
+				existing[strings.ToLower(label.GetName())] = label
+			}
+			if len(labels) < pageSize || len(labels) == 0 {
+				break
+			}
+			options.Page += 1
+		}
+		for _, l := range settings.Labels {
+			label := &github.Label{
+				Name:        github.String(l.Name),
+				Color:       github.String(strings.TrimPrefix(l.Color, "#")),
+				Description: github.String(l.Description),
+			}
+			current := existing[strings.ToLower(l.Name)]
+			if current == nil {
+				_, _, err := p.Client.Issues.CreateLabel(p.Context, owner, repo, label)
+				if err != nil {
+					return errors.Wrapf(err, "Failed to create label %s in repository %s/%s", l.Name, owner, repo)
+				}
+				continue
+			}
+			if current.GetColor() == label.GetColor() && current.GetDescription() == label.GetDescription() {
+				continue
+			}
+			_, _, err := p.Client.Issues.EditLabel(p.Context, owner, repo, current.GetName(), label)
+			if err != nil {
+				return errors.Wrapf(err, "Failed to edit label %s of repository %s/%s", l.Name, owner, repo)
+			}
+		}
+	}
+	return nil
+}
+
 // IsWikiEnabled returns true if a wiki is enabled for owner/repo
 func (p *GitHubProvider) IsWikiEnabled(owner string, repo string) (bool, error) {
 	gitURL := fmt.Sprintf("%s/%s/%s.wiki.git", p.Server.URL, owner, repo)
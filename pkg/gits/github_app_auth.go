@@ -0,0 +1,217 @@
+package gits
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/jenkins-x/jx/v2/pkg/auth"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+	"golang.org/x/oauth2"
+)
+
+const (
+	// GitHubAppTokenUsername the username used with GitHub App installation tokens in git URLs
+	GitHubAppTokenUsername = "x-access-token"
+
+	// githubAppJWTLifetime GitHub rejects app JWTs which expire more than 10 minutes in the future
+	githubAppJWTLifetime = 9 * time.Minute
+)
+
+// GitHubAppTokenSource mints installation access tokens for a GitHub App
+type GitHubAppTokenSource struct {
+	AppID          int64
+	InstallationID int64
+	PrivateKey     *rsa.PrivateKey
+	APIURL         string
+	Client         *http.Client
+	// Repositories the names of the repositories the tokens are restricted to, all repositories of the installation
+	// if empty
+	Repositories []string
+	// Permissions the permissions the tokens are restricted to, all permissions of the installation if empty
+	Permissions map[string]string
+}
+
+// NewGitHubAppTokenSource creates a token source which mints installation tokens for the GitHub App of the given
+// user, refreshing them before they expire
+func NewGitHubAppTokenSource(serverURL string, user *auth.UserAuth) (oauth2.TokenSource, error) {
+	source, err := newGitHubAppTokenSource(serverURL, user)
+	if err != nil {
+		return nil, err
+	}
+	return oauth2.ReuseTokenSource(nil, source), nil
+}
+
+// MintScopedGitHubAppToken mints an installation token for the GitHub App of the given user which can only access the
+// given repositories with the given permissions
+func MintScopedGitHubAppToken(serverURL string, user *auth.UserAuth, repositories []string, permissions map[string]string) (*oauth2.Token, error) {
+	source, err := newGitHubAppTokenSource(serverURL, user)
+	if err != nil {
+		return nil, err
+	}
+	source.Repositories = repositories
+	source.Permissions = permissions
+	token, err := source.Token()
+	if err != nil {
+		return nil, errors.Wrapf(err, "minting an installation token for GitHub App %d", user.GithubAppID)
+	}
+	return token, nil
+}
+
+func newGitHubAppTokenSource(serverURL string, user *auth.UserAuth) (*GitHubAppTokenSource, error) {
+	if !user.IsGithubApp() {
+		return nil, fmt.Errorf("the user %s has no GitHub App ID, installation ID and private key", user.Username)
+	}
+	key, err := parseRSAPrivateKey([]byte(user.GithubAppPrivateKey))
+	if err != nil {
+		return nil, errors.Wrapf(err, "parsing the private key of GitHub App %d", user.GithubAppID)
+	}
+	apiURL := "https://api.github.com/"
+	if !IsGitHubServerURL(serverURL) {
+		apiURL = GitHubEnterpriseApiEndpointURL(serverURL)
+	}
+	return &GitHubAppTokenSource{
+		AppID:          user.GithubAppID,
+		InstallationID: user.GithubAppInstallationID,
+		PrivateKey:     key,
+		APIURL:         apiURL,
+		Client:         NewRetryClient(time.Minute),
+	}, nil
+}
+
+// GitHubAppUserAuth returns a copy of the user with a freshly minted installation token as the API token so it can
+// be used in authenticated git URLs and credential helpers. Users which are not GitHub Apps are returned as is
+func GitHubAppUserAuth(serverURL string, user *auth.UserAuth) (*auth.UserAuth, error) {
+	if !user.IsGithubApp() {
+		return user, nil
+	}
+	source, err := NewGitHubAppTokenSource(serverURL, user)
+	if err != nil {
+		return nil, err
+	}
+	return githubAppUserAuth(source, user)
+}
+
+func githubAppUserAuth(source oauth2.TokenSource, user *auth.UserAuth) (*auth.UserAuth, error) {
+	token, err := source.Token()
+	if err != nil {
+		return nil, errors.Wrapf(err, "minting an installation token for GitHub App %d", user.GithubAppID)
+	}
+	answer := *user
+	answer.Username = GitHubAppTokenUsername
+	answer.ApiToken = token.AccessToken
+	return &answer, nil
+}
+
+// githubAppTokenScope the repositories and permissions an installation token is restricted to
+type githubAppTokenScope struct {
+	Repositories []string          `json:"repositories,omitempty"`
+	Permissions  map[string]string `json:"permissions,omitempty"`
+}
+
+// Token mints a new installation access token
+func (s *GitHubAppTokenSource) Token() (*oauth2.Token, error) {
+	jwt, err := s.jwt(time.Now())
+	if err != nil {
+		return nil, err
+	}
+	u := util.UrlJoin(s.APIURL, "app", "installations", fmt.Sprintf("%d", s.InstallationID), "access_tokens")
+	var body io.Reader
+	if len(s.Repositories) > 0 || len(s.Permissions) > 0 {
+		scope, err := json.Marshal(&githubAppTokenScope{
+			Repositories: s.Repositories,
+			Permissions:  s.Permissions,
+		})
+		if err != nil {
+			return nil, err
+		}
+		body = bytes.NewReader(scope)
+	}
+	req, err := http.NewRequest(http.MethodPost, u, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+jwt)
+	req.Header.Set("Accept", "application/vnd.github.machine-man-preview+json")
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		return nil, errors.Wrapf(err, "requesting an installation token from %s", u)
+	}
+	defer resp.Body.Close() //nolint:errcheck
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusCreated {
+		return nil, fmt.Errorf("requesting an installation token from %s returned %s: %s", u, resp.Status, string(data))
+	}
+	result := struct {
+		Token     string    `json:"token"`
+		ExpiresAt time.Time `json:"expires_at"`
+	}{}
+	err = json.Unmarshal(data, &result)
+	if err != nil {
+		return nil, errors.Wrapf(err, "parsing the installation token returned from %s", u)
+	}
+	return &oauth2.Token{
+		AccessToken: result.Token,
+		TokenType:   "token",
+		Expiry:      result.ExpiresAt,
+	}, nil
+}
+
+// jwt creates the RS256 signed JSON Web Token used to authenticate as the app
+func (s *GitHubAppTokenSource) jwt(now time.Time) (string, error) {
+	header, err := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	if err != nil {
+		return "", err
+	}
+	claims, err := json.Marshal(map[string]int64{
+		// allow for clock drift between us and GitHub
+		"iat": now.Add(-time.Minute).Unix(),
+		"exp": now.Add(githubAppJWTLifetime).Unix(),
+		"iss": s.AppID,
+	})
+	if err != nil {
+		return "", err
+	}
+	unsigned := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(unsigned))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, s.PrivateKey, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", errors.Wrap(err, "signing the GitHub App JWT")
+	}
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+func parseRSAPrivateKey(data []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode([]byte(strings.TrimSpace(string(data))))
+	if block == nil {
+		return nil, errors.New("no PEM encoded key found")
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	rsaKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("the private key is not an RSA key")
+	}
+	return rsaKey, nil
+}
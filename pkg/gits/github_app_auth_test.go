@@ -0,0 +1,100 @@
+// +build unit
+
+package gits_test
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/jenkins-x/jx/v2/pkg/auth"
+	"github.com/jenkins-x/jx/v2/pkg/gits"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGitHubAppUserAuth(t *testing.T) {
+	t.Parallel()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	privateKey := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/api/v3/app/installations/5678/access_tokens", r.URL.Path)
+
+		parts := strings.Split(strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "), ".")
+		require.Len(t, parts, 3)
+		digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+		signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+		require.NoError(t, err)
+		assert.NoError(t, rsa.VerifyPKCS1v15(&key.PublicKey, crypto.SHA256, digest[:], signature))
+
+		data, err := base64.RawURLEncoding.DecodeString(parts[1])
+		require.NoError(t, err)
+		claims := map[string]int64{}
+		require.NoError(t, json.Unmarshal(data, &claims))
+		assert.Equal(t, int64(1234), claims["iss"])
+
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"token": "v1.installation-token", "expires_at": "2099-01-01T00:00:00Z"}`)) //nolint:errcheck
+	}))
+	defer server.Close()
+
+	user := &auth.UserAuth{
+		Username:                "my-bot",
+		GithubAppID:             1234,
+		GithubAppInstallationID: 5678,
+		GithubAppPrivateKey:     string(privateKey),
+	}
+	assert.True(t, user.IsGithubApp())
+	assert.False(t, user.IsInvalid())
+
+	appUser, err := gits.GitHubAppUserAuth(server.URL, user)
+	require.NoError(t, err)
+	assert.Equal(t, gits.GitHubAppTokenUsername, appUser.Username)
+	assert.Equal(t, "v1.installation-token", appUser.ApiToken)
+	assert.Equal(t, "my-bot", user.Username, "the original user should not be modified")
+}
+
+func TestMintScopedGitHubAppToken(t *testing.T) {
+	t.Parallel()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	privateKey := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		scope := map[string]interface{}{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&scope))
+		assert.Equal(t, map[string]interface{}{
+			"repositories": []interface{}{"myapp"},
+			"permissions":  map[string]interface{}{"contents": "read"},
+		}, scope)
+
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"token": "v1.scoped-token", "expires_at": "2099-01-01T00:00:00Z"}`)) //nolint:errcheck
+	}))
+	defer server.Close()
+
+	user := &auth.UserAuth{
+		Username:                "my-bot",
+		GithubAppID:             1234,
+		GithubAppInstallationID: 5678,
+		GithubAppPrivateKey:     string(privateKey),
+	}
+	token, err := gits.MintScopedGitHubAppToken(server.URL, user, []string{"myapp"}, map[string]string{"contents": "read"})
+	require.NoError(t, err)
+	assert.Equal(t, "v1.scoped-token", token.AccessToken)
+	assert.Equal(t, 2099, token.Expiry.Year())
+}
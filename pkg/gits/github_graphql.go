@@ -0,0 +1,496 @@
+package gits
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/github"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+)
+
+const (
+	// GitHubAPIEnvVar the environment variable to choose the GitHub API used by the GitHub provider
+	GitHubAPIEnvVar = "JX_GITHUB_API"
+
+	// GitHubAPIGraphQL the value of $JX_GITHUB_API which uses the v4 GraphQL API for pull requests, reviews and
+	// repository metadata
+	GitHubAPIGraphQL = "graphql"
+
+	graphQLPageSize = 100
+)
+
+// OpenPullRequestLabelLister is implemented by git providers which can filter open pull requests by label on the
+// server
+type OpenPullRequestLabelLister interface {
+	// ListOpenPullRequestsWithLabels lists the open pull requests which have any of the given labels
+	ListOpenPullRequestsWithLabels(owner string, repo string, labels []string) ([]*GitPullRequest, error)
+}
+
+// PullRequestReviewLister is implemented by git providers which can list the reviews of a pull request
+type PullRequestReviewLister interface {
+	// ListPullRequestReviews lists the reviews of the given pull request
+	ListPullRequestReviews(owner string, repo string, number int) ([]*GitPullRequestReview, error)
+}
+
+// GitHubGraphQLProvider is a GitHubProvider which uses the v4 GraphQL API to fetch pull requests, reviews and
+// repository metadata in a single request rather than a REST call per page and per pull request
+type GitHubGraphQLProvider struct {
+	*GitHubProvider
+
+	GraphQLURL string
+	httpClient *http.Client
+}
+
+// UseGitHubGraphQL returns true if the GitHub provider should use the GraphQL API
+func UseGitHubGraphQL() bool {
+	return strings.EqualFold(os.Getenv(GitHubAPIEnvVar), GitHubAPIGraphQL)
+}
+
+// NewGitHubGraphQLProvider creates a provider which uses the GraphQL API of the server of the given REST provider
+// with the same authenticated http client
+func NewGitHubGraphQLProvider(provider *GitHubProvider, httpClient *http.Client) *GitHubGraphQLProvider {
+	return &GitHubGraphQLProvider{
+		GitHubProvider: provider,
+		GraphQLURL:     GitHubGraphQLURL(provider.Server.URL),
+		httpClient:     httpClient,
+	}
+}
+
+// GitHubGraphQLURL returns the GraphQL endpoint of the given GitHub or GitHub Enterprise server
+func GitHubGraphQLURL(serverURL string) string {
+	if IsGitHubServerURL(serverURL) {
+		return "https://api.github.com/graphql"
+	}
+	u := strings.TrimSuffix(serverURL, "/")
+	if i := strings.Index(u, "/api/"); i > 0 {
+		u = u[:i]
+	}
+	return util.UrlJoin(u, "api", "graphql")
+}
+
+const graphQLPullRequestFields = `
+	number
+	url
+	title
+	body
+	state
+	mergeable
+	merged
+	mergedAt
+	closedAt
+	updatedAt
+	headRefName
+	headRefOid
+	headRepositoryOwner { login }
+	mergeCommit { oid }
+	author { login avatarUrl url }
+	assignees(first: 100) { nodes { login } }
+	reviewRequests(first: 100) { nodes { requestedReviewer { ... on User { login } } } }
+	labels(first: 100) { nodes { name color description url isDefault } }`
+
+const graphQLOpenPullRequestsQuery = `query($owner: String!, $repo: String!, $labels: [String!], $first: Int!, $after: String) {
+  repository(owner: $owner, name: $repo) {
+    pullRequests(states: OPEN, labels: $labels, first: $first, after: $after) {
+      pageInfo { hasNextPage endCursor }
+      nodes {` + graphQLPullRequestFields + `
+      }
+    }
+  }
+}`
+
+const graphQLPullRequestReviewsQuery = `query($owner: String!, $repo: String!, $number: Int!, $first: Int!, $after: String) {
+  repository(owner: $owner, name: $repo) {
+    pullRequest(number: $number) {
+      reviews(first: $first, after: $after) {
+        pageInfo { hasNextPage endCursor }
+        nodes {
+          state
+          body
+          submittedAt
+          url
+          author { login avatarUrl url }
+        }
+      }
+    }
+  }
+}`
+
+const graphQLRepositoryQuery = `query($owner: String!, $repo: String!) {
+  repository(owner: $owner, name: $repo) {
+    databaseId
+    name
+    url
+    sshUrl
+    isFork
+    isPrivate
+    isArchived
+    hasIssuesEnabled
+    hasWikiEnabled
+    hasProjectsEnabled
+    mergeCommitAllowed
+    primaryLanguage { name }
+    stargazers { totalCount }
+    issues(states: OPEN) { totalCount }
+    pullRequests(states: OPEN) { totalCount }
+  }
+}`
+
+type graphQLPageInfo struct {
+	HasNextPage bool   `json:"hasNextPage"`
+	EndCursor   string `json:"endCursor"`
+}
+
+type graphQLActor struct {
+	Login     string `json:"login"`
+	AvatarURL string `json:"avatarUrl"`
+	URL       string `json:"url"`
+}
+
+type graphQLPullRequest struct {
+	Number              int           `json:"number"`
+	URL                 string        `json:"url"`
+	Title               string        `json:"title"`
+	Body                string        `json:"body"`
+	State               string        `json:"state"`
+	Mergeable           string        `json:"mergeable"`
+	Merged              bool          `json:"merged"`
+	MergedAt            *time.Time    `json:"mergedAt"`
+	ClosedAt            *time.Time    `json:"closedAt"`
+	UpdatedAt           *time.Time    `json:"updatedAt"`
+	HeadRefName         string        `json:"headRefName"`
+	HeadRefOid          string        `json:"headRefOid"`
+	HeadRepositoryOwner *graphQLActor `json:"headRepositoryOwner"`
+	MergeCommit         *struct {
+		Oid string `json:"oid"`
+	} `json:"mergeCommit"`
+	Author    *graphQLActor `json:"author"`
+	Assignees struct {
+		Nodes []graphQLActor `json:"nodes"`
+	} `json:"assignees"`
+	ReviewRequests struct {
+		Nodes []struct {
+			RequestedReviewer *graphQLActor `json:"requestedReviewer"`
+		} `json:"nodes"`
+	} `json:"reviewRequests"`
+	Labels struct {
+		Nodes []struct {
+			Name        string `json:"name"`
+			Color       string `json:"color"`
+			Description string `json:"description"`
+			URL         string `json:"url"`
+			IsDefault   bool   `json:"isDefault"`
+		} `json:"nodes"`
+	} `json:"labels"`
+}
+
+type graphQLPullRequestReview struct {
+	State       string        `json:"state"`
+	Body        string        `json:"body"`
+	SubmittedAt *time.Time    `json:"submittedAt"`
+	URL         string        `json:"url"`
+	Author      *graphQLActor `json:"author"`
+}
+
+type graphQLRepository struct {
+	DatabaseID         int64  `json:"databaseId"`
+	Name               string `json:"name"`
+	URL                string `json:"url"`
+	SSHURL             string `json:"sshUrl"`
+	IsFork             bool   `json:"isFork"`
+	IsPrivate          bool   `json:"isPrivate"`
+	IsArchived         bool   `json:"isArchived"`
+	HasIssuesEnabled   bool   `json:"hasIssuesEnabled"`
+	HasWikiEnabled     bool   `json:"hasWikiEnabled"`
+	HasProjectsEnabled bool   `json:"hasProjectsEnabled"`
+	MergeCommitAllowed bool   `json:"mergeCommitAllowed"`
+	PrimaryLanguage    *struct {
+		Name string `json:"name"`
+	} `json:"primaryLanguage"`
+	Stargazers   graphQLCount `json:"stargazers"`
+	Issues       graphQLCount `json:"issues"`
+	PullRequests graphQLCount `json:"pullRequests"`
+}
+
+type graphQLCount struct {
+	TotalCount int `json:"totalCount"`
+}
+
+// ListOpenPullRequests lists the open pull requests
+func (p *GitHubGraphQLProvider) ListOpenPullRequests(owner string, repo string) ([]*GitPullRequest, error) {
+	return p.listOpenPullRequests(owner, repo, nil)
+}
+
+// ListOpenPullRequestsWithLabels lists the open pull requests which have any of the given labels
+func (p *GitHubGraphQLProvider) ListOpenPullRequestsWithLabels(owner string, repo string, labels []string) ([]*GitPullRequest, error) {
+	return p.listOpenPullRequests(owner, repo, labels)
+}
+
+func (p *GitHubGraphQLProvider) listOpenPullRequests(owner string, repo string, labels []string) ([]*GitPullRequest, error) {
+	variables := map[string]interface{}{
+		"owner": owner,
+		"repo":  repo,
+		"first": graphQLPageSize,
+	}
+	if len(labels) > 0 {
+		variables["labels"] = labels
+	}
+	answer := []*GitPullRequest{}
+	for {
+		result := struct {
+			Repository *struct {
+				PullRequests struct {
+					PageInfo graphQLPageInfo      `json:"pageInfo"`
+					Nodes    []graphQLPullRequest `json:"nodes"`
+				} `json:"pullRequests"`
+			} `json:"repository"`
+		}{}
+		err := p.query(graphQLOpenPullRequestsQuery, variables, &result)
+		if err != nil {
+			return answer, errors.Wrapf(err, "listing open pull requests on %s/%s", owner, repo)
+		}
+		if result.Repository == nil {
+			return answer, fmt.Errorf("repository %s/%s not found", owner, repo)
+		}
+		for i := range result.Repository.PullRequests.Nodes {
+			answer = append(answer, p.toGitPullRequest(owner, repo, &result.Repository.PullRequests.Nodes[i]))
+		}
+		pageInfo := result.Repository.PullRequests.PageInfo
+		if !pageInfo.HasNextPage {
+			break
+		}
+		variables["after"] = pageInfo.EndCursor
+	}
+	return answer, nil
+}
+
+// ListPullRequestReviews lists the submitted reviews of the given pull request
+func (p *GitHubGraphQLProvider) ListPullRequestReviews(owner string, repo string, number int) ([]*GitPullRequestReview, error) {
+	variables := map[string]interface{}{
+		"owner":  owner,
+		"repo":   repo,
+		"number": number,
+		"first":  graphQLPageSize,
+	}
+	answer := []*GitPullRequestReview{}
+	for {
+		result := struct {
+			Repository *struct {
+				PullRequest *struct {
+					Reviews struct {
+						PageInfo graphQLPageInfo            `json:"pageInfo"`
+						Nodes    []graphQLPullRequestReview `json:"nodes"`
+					} `json:"reviews"`
+				} `json:"pullRequest"`
+			} `json:"repository"`
+		}{}
+		err := p.query(graphQLPullRequestReviewsQuery, variables, &result)
+		if err != nil {
+			return answer, errors.Wrapf(err, "listing the reviews of pull request %s/%s#%d", owner, repo, number)
+		}
+		if result.Repository == nil || result.Repository.PullRequest == nil {
+			return answer, fmt.Errorf("pull request %s/%s#%d not found", owner, repo, number)
+		}
+		for _, review := range result.Repository.PullRequest.Reviews.Nodes {
+			answer = append(answer, &GitPullRequestReview{
+				Author:      toGraphQLUser(review.Author),
+				State:       review.State,
+				Body:        review.Body,
+				URL:         review.URL,
+				SubmittedAt: review.SubmittedAt,
+			})
+		}
+		pageInfo := result.Repository.PullRequest.Reviews.PageInfo
+		if !pageInfo.HasNextPage {
+			break
+		}
+		variables["after"] = pageInfo.EndCursor
+	}
+	return answer, nil
+}
+
+// GetRepository returns the metadata of a repository
+func (p *GitHubGraphQLProvider) GetRepository(org string, name string) (*GitRepository, error) {
+	result := struct {
+		Repository *graphQLRepository `json:"repository"`
+	}{}
+	variables := map[string]interface{}{
+		"owner": org,
+		"repo":  name,
+	}
+	err := p.query(graphQLRepositoryQuery, variables, &result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get repository %s/%s due to: %s", org, name, err)
+	}
+	if result.Repository == nil {
+		return nil, fmt.Errorf("failed to get repository %s/%s due to: not found", org, name)
+	}
+	repo := result.Repository
+	answer := &GitRepository{
+		ID:               repo.DatabaseID,
+		Name:             name,
+		AllowMergeCommit: repo.MergeCommitAllowed,
+		CloneURL:         repo.URL + ".git",
+		HTMLURL:          repo.URL,
+		SSHURL:           repo.SSHURL,
+		URL:              p.apiURL("repos", org, name),
+		Fork:             repo.IsFork,
+		Stars:            repo.Stargazers.TotalCount,
+		Private:          repo.IsPrivate,
+		Organisation:     org,
+		HasIssues:        repo.HasIssuesEnabled,
+		// the REST API counts open pull requests as open issues
+		OpenIssueCount: repo.Issues.TotalCount + repo.PullRequests.TotalCount,
+		HasWiki:        repo.HasWikiEnabled,
+		HasProjects:    repo.HasProjectsEnabled,
+		Archived:       repo.IsArchived,
+	}
+	if repo.PrimaryLanguage != nil {
+		answer.Language = repo.PrimaryLanguage.Name
+	}
+	return answer, nil
+}
+
+// toGitPullRequest converts a GraphQL pull request into the same form as the REST provider returns
+func (p *GitHubGraphQLProvider) toGitPullRequest(owner string, repo string, source *graphQLPullRequest) *GitPullRequest {
+	number := source.Number
+	state := strings.ToLower(source.State)
+	merged := source.Merged
+	pr := &GitPullRequest{
+		URL:                source.URL,
+		Owner:              owner,
+		Repo:               repo,
+		Number:             &number,
+		Merged:             &merged,
+		State:              &state,
+		Title:              source.Title,
+		Body:               source.Body,
+		LastCommitSha:      source.HeadRefOid,
+		Author:             toGraphQLUser(source.Author),
+		MergedAt:           source.MergedAt,
+		ClosedAt:           source.ClosedAt,
+		UpdatedAt:          source.UpdatedAt,
+		Assignees:          []*GitUser{},
+		RequestedReviewers: []*GitUser{},
+		Labels:             []*Label{},
+	}
+	switch source.Mergeable {
+	case "MERGEABLE":
+		pr.Mergeable = github.Bool(true)
+	case "CONFLICTING":
+		pr.Mergeable = github.Bool(false)
+	}
+	if source.HeadRefName != "" {
+		pr.HeadRef = github.String(source.HeadRefName)
+	}
+	if source.HeadRepositoryOwner != nil {
+		pr.HeadOwner = github.String(source.HeadRepositoryOwner.Login)
+	}
+	if source.MergeCommit != nil {
+		pr.MergeCommitSHA = github.String(source.MergeCommit.Oid)
+	}
+	if source.HeadRefOid != "" {
+		pr.StatusesURL = github.String(p.apiURL("repos", owner, repo, "statuses", source.HeadRefOid))
+	}
+	pr.IssueURL = github.String(p.apiURL("repos", owner, repo, "issues", fmt.Sprintf("%d", number)))
+	for _, u := range source.Assignees.Nodes {
+		pr.Assignees = append(pr.Assignees, &GitUser{Login: u.Login})
+	}
+	for _, r := range source.ReviewRequests.Nodes {
+		// team review requests have no login
+		if r.RequestedReviewer != nil && r.RequestedReviewer.Login != "" {
+			pr.RequestedReviewers = append(pr.RequestedReviewers, &GitUser{Login: r.RequestedReviewer.Login})
+		}
+	}
+	for _, l := range source.Labels.Nodes {
+		pr.Labels = append(pr.Labels, &Label{
+			Name:        github.String(l.Name),
+			URL:         github.String(l.URL),
+			Color:       github.String(l.Color),
+			Description: github.String(l.Description),
+			Default:     github.Bool(l.IsDefault),
+		})
+	}
+	return pr
+}
+
+func toGraphQLUser(actor *graphQLActor) *GitUser {
+	if actor == nil {
+		return nil
+	}
+	return &GitUser{
+		Login:     actor.Login,
+		AvatarURL: actor.AvatarURL,
+		URL:       actor.URL,
+	}
+}
+
+// apiURL returns the REST API URL of the given path so the results match those of the REST provider
+func (p *GitHubGraphQLProvider) apiURL(paths ...string) string {
+	base := "https://api.github.com/"
+	if p.Client != nil && p.Client.BaseURL != nil {
+		base = p.Client.BaseURL.String()
+	}
+	return util.UrlJoin(append([]string{base}, paths...)...)
+}
+
+// query runs the GraphQL query and unmarshals its data into the result
+func (p *GitHubGraphQLProvider) query(query string, variables map[string]interface{}, result interface{}) error {
+	body, err := json.Marshal(map[string]interface{}{
+		"query":     query,
+		"variables": variables,
+	})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, p.GraphQLURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(WithReadOnlyRequest(p.Context))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return errors.Wrapf(err, "querying %s", p.GraphQLURL)
+	}
+	defer resp.Body.Close() //nolint:errcheck
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("querying %s returned %s: %s", p.GraphQLURL, resp.Status, string(data))
+	}
+	response := graphQLResponse{}
+	err = json.Unmarshal(data, &response)
+	if err != nil {
+		return errors.Wrapf(err, "parsing the response from %s", p.GraphQLURL)
+	}
+	return response.decode(result)
+}
+
+// graphQLResponse the body of a GraphQL response
+type graphQLResponse struct {
+	Data   json.RawMessage `json:"data"`
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+// decode unmarshals the data of the response into the result or returns the errors of the response
+func (r *graphQLResponse) decode(result interface{}) error {
+	if len(r.Errors) > 0 {
+		messages := make([]string, 0, len(r.Errors))
+		for _, e := range r.Errors {
+			messages = append(messages, e.Message)
+		}
+		return errors.New(strings.Join(messages, "; "))
+	}
+	return json.Unmarshal(r.Data, result)
+}
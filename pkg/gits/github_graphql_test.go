@@ -0,0 +1,93 @@
+// +build unit
+
+package gits_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jenkins-x/jx/v2/pkg/auth"
+	"github.com/jenkins-x/jx/v2/pkg/gits"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGitHubGraphQLURL(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "https://api.github.com/graphql", gits.GitHubGraphQLURL("https://github.com"))
+	assert.Equal(t, "https://github.example.com/api/graphql", gits.GitHubGraphQLURL("https://github.example.com/"))
+	assert.Equal(t, "https://github.example.com/api/graphql", gits.GitHubGraphQLURL("https://github.example.com/api/v3/"))
+}
+
+func TestGitHubGraphQLFilterOpenPullRequests(t *testing.T) {
+	t.Parallel()
+
+	pages := []string{
+		`{"data":{"repository":{"pullRequests":{"pageInfo":{"hasNextPage":true,"endCursor":"c1"},"nodes":[
+			{"number":1,"url":"https://github.com/jx/env/pull/1","title":"promote","state":"OPEN","mergeable":"MERGEABLE",
+			 "headRefName":"promote-app","headRefOid":"abc","headRepositoryOwner":{"login":"jx"},"author":{"login":"bot"},
+			 "labels":{"nodes":[{"name":"env/staging"},{"name":"updatebot"}]}}]}}}}`,
+		`{"data":{"repository":{"pullRequests":{"pageInfo":{"hasNextPage":false},"nodes":[
+			{"number":2,"title":"other","state":"OPEN","mergeable":"UNKNOWN","labels":{"nodes":[{"name":"updatebot"}]}}]}}}}`,
+	}
+	var requests []map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		body := map[string]interface{}{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		requests = append(requests, body["variables"].(map[string]interface{}))
+		_, _ = w.Write([]byte(pages[len(requests)-1]))
+	}))
+	defer server.Close()
+
+	provider := gits.NewGitHubGraphQLProvider(&gits.GitHubProvider{
+		Server:  auth.AuthServer{URL: "https://github.com"},
+		Context: context.Background(),
+	}, server.Client())
+	provider.GraphQLURL = server.URL
+
+	prs, err := gits.FilterOpenPullRequests(provider, "jx", "env", gits.PullRequestFilter{
+		Labels: []string{"updatebot", "env/staging"},
+	})
+	require.NoError(t, err)
+
+	require.Len(t, requests, 2)
+	assert.Equal(t, []interface{}{"updatebot", "env/staging"}, requests[0]["labels"])
+	assert.Nil(t, requests[0]["after"])
+	assert.Equal(t, "c1", requests[1]["after"])
+
+	require.Len(t, prs, 1, "only the pull request with all the labels should match")
+	pr := prs[0]
+	assert.Equal(t, 1, *pr.Number)
+	assert.Equal(t, "open", *pr.State)
+	assert.Equal(t, "promote-app", util.DereferenceString(pr.HeadRef))
+	assert.Equal(t, "jx", util.DereferenceString(pr.HeadOwner))
+	assert.Equal(t, "abc", pr.LastCommitSha)
+	assert.Equal(t, "bot", pr.Author.Login)
+	assert.True(t, *pr.Mergeable)
+	assert.Equal(t, "https://api.github.com/repos/jx/env/statuses/abc", util.DereferenceString(pr.StatusesURL))
+}
+
+func TestGitHubGraphQLErrors(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"data":null,"errors":[{"message":"Could not resolve to a Repository"}]}`))
+	}))
+	defer server.Close()
+
+	provider := gits.NewGitHubGraphQLProvider(&gits.GitHubProvider{
+		Server:  auth.AuthServer{URL: "https://github.com"},
+		Context: context.Background(),
+	}, server.Client())
+	provider.GraphQLURL = server.URL
+
+	_, err := provider.GetRepository("jx", "missing")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Could not resolve to a Repository")
+}
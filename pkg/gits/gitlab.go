@@ -18,6 +18,9 @@ import (
 	"github.com/xanzy/go-gitlab"
 )
 
+// gitlabDraftPrefix the title prefix which marks a merge request as a work in progress
+const gitlabDraftPrefix = "WIP: "
+
 type GitlabProvider struct {
 	Username string
 	Client   *gitlab.Client
@@ -30,7 +33,7 @@ type GitlabProvider struct {
 
 func NewGitlabProvider(server *auth.AuthServer, user *auth.UserAuth, git Gitter) (GitProvider, error) {
 	u := server.URL
-	c := gitlab.NewClient(nil, user.ApiToken)
+	c := gitlab.NewClient(NewAPIClient(0), user.ApiToken)
 	if !IsGitLabServerURL(u) {
 		if err := c.SetBaseURL(u); err != nil {
 			return nil, err
@@ -126,13 +129,14 @@ func fromGitlabProject(p *gitlab.Project) *GitRepository {
 		org = p.Namespace.Path
 	}
 	return &GitRepository{
-		Organisation: org,
-		Project:      org,
-		Name:         p.Name,
-		HTMLURL:      p.WebURL,
-		SSHURL:       p.SSHURLToRepo,
-		CloneURL:     p.HTTPURLToRepo,
-		Fork:         p.ForkedFromProject != nil,
+		Organisation:  org,
+		Project:       org,
+		Name:          p.Name,
+		HTMLURL:       p.WebURL,
+		SSHURL:        p.SSHURLToRepo,
+		CloneURL:      p.HTTPURLToRepo,
+		Fork:          p.ForkedFromProject != nil,
+		DefaultBranch: p.DefaultBranch,
 	}
 }
 
@@ -263,6 +267,9 @@ func (g *GitlabProvider) CreatePullRequest(data *GitPullRequestArguments) (*GitP
 	body := data.Body
 	head := data.Head
 	base := data.Base
+	if data.Draft && !strings.HasPrefix(title, gitlabDraftPrefix) {
+		title = gitlabDraftPrefix + title
+	}
 
 	o := &gitlab.CreateMergeRequestOptions{
 		Title:        &title,
@@ -549,6 +556,44 @@ func (g *GitlabProvider) MergePullRequest(pr *GitPullRequest, message string) er
 	return err
 }
 
+// AssignPullRequest assigns the merge request to the given users
+func (g *GitlabProvider) AssignPullRequest(pr *GitPullRequest, assignees []string) error {
+	if pr.Number == nil {
+		return fmt.Errorf("missing number for merge request %s", pr.URL)
+	}
+	ids := []int{}
+	for _, assignee := range assignees {
+		id, err := g.getUserID(assignee)
+		if err != nil {
+			return errors2.Wrapf(err, "finding the user %s", assignee)
+		}
+		if id == nil {
+			return fmt.Errorf("no user %s found", assignee)
+		}
+		ids = append(ids, *id)
+	}
+	pid, err := g.projectId(pr.Owner, g.Username, pr.Repo)
+	if err != nil {
+		return err
+	}
+	_, _, err = g.Client.MergeRequests.UpdateMergeRequest(pid, *pr.Number, &gitlab.UpdateMergeRequestOptions{AssigneeIDs: ids})
+	return err
+}
+
+// EnableAutoMerge makes GitLab merge the merge request once its pipeline succeeds
+func (g *GitlabProvider) EnableAutoMerge(pr *GitPullRequest) error {
+	if pr.Number == nil {
+		return fmt.Errorf("missing number for merge request %s", pr.URL)
+	}
+	pid, err := g.projectId(pr.Owner, g.Username, pr.Repo)
+	if err != nil {
+		return err
+	}
+	opt := &gitlab.AcceptMergeRequestOptions{MergeWhenPipelineSucceeds: gitlab.Bool(true)}
+	_, _, err = g.Client.MergeRequests.AcceptMergeRequest(pid, *pr.Number, opt)
+	return err
+}
+
 func (g *GitlabProvider) CreateWebHook(data *GitWebHookArguments) error {
 	pid, err := g.projectId(data.Owner, g.Username, data.Repo.Name)
 	if err != nil {
@@ -956,7 +1001,7 @@ func (g *GitlabProvider) GetProjects(owner string, repo string) ([]GitProject, e
 	return nil, nil
 }
 
-//ConfigureFeatures sets specific features as enabled or disabled for owner/repo
+// ConfigureFeatures sets specific features as enabled or disabled for owner/repo
 func (g *GitlabProvider) ConfigureFeatures(owner string, repo string, issues *bool, projects *bool, wikis *bool) (*GitRepository, error) {
 	return nil, nil
 }
@@ -8,7 +8,6 @@ import (
 	"os/user"
 	"path"
 	"path/filepath"
-	"sort"
 	"strings"
 
 	"gopkg.in/src-d/go-git.v4/config"
@@ -197,197 +196,33 @@ func GitProviderURL(text string) string {
 // It creates a branch called branchName from a base.
 // It uses the pullRequestDetails for the message and title for the commit and PR.
 // It uses and updates pullRequestInfo to identify whether to rebase an existing PR.
+//
+// Deprecated: use PullRequestCreator which also supports draft pull requests, reviewers, assignees and auto merge
 func PushRepoAndCreatePullRequest(dir string, upstreamRepo *GitRepository, forkRepo *GitRepository, base string, prDetails *PullRequestDetails, filter *PullRequestFilter, commit bool, commitMessage string, push bool, dryRun bool, gitter Gitter, provider GitProvider) (*PullRequestInfo, error) {
-	userAuth := provider.UserAuth()
-	if commit {
-		err := gitter.Add(dir, "-A")
-		if err != nil {
-			return nil, errors.WithStack(err)
-		}
-		changed, err := gitter.HasChanges(dir)
-		if err != nil {
-			return nil, errors.WithStack(err)
-		}
-		if !changed {
-			log.Logger().Warnf("No changes made to the source code in %s. Code must be up to date!", dir)
-			return nil, nil
-		}
-		if commitMessage == "" {
-			commitMessage = prDetails.Message
-		}
-		err = gitter.CommitDir(dir, commitMessage)
-		if err != nil {
-			return nil, errors.WithStack(err)
-		}
-	}
-
-	headPrefix := ""
-
-	username := upstreamRepo.Organisation
-	cloneURL := upstreamRepo.CloneURL
-	if forkRepo != nil {
-		username = forkRepo.Organisation
-		cloneURL = forkRepo.CloneURL
-	}
-
-	if upstreamRepo.Organisation != username {
-		headPrefix = username + ":"
-	}
-
-	gha := &GitPullRequestArguments{
-		GitRepository: upstreamRepo,
-		Title:         prDetails.Title,
-		Body:          prDetails.Message,
+	creator := &PullRequestCreator{
+		Dir:           dir,
+		UpstreamRepo:  upstreamRepo,
+		ForkRepo:      forkRepo,
 		Base:          base,
-		Labels:        prDetails.Labels,
-	}
-	var existingPr *GitPullRequest
-
-	forkPushURL, err := gitter.CreateAuthenticatedURL(cloneURL, &userAuth)
-	if err != nil {
-		return nil, errors.Wrapf(err, "creating push URL for %s", cloneURL)
-	}
-
-	if filter != nil && push {
-		// lets rebase an existing PR
-		existingPrs, err := FilterOpenPullRequests(provider, upstreamRepo.Organisation, upstreamRepo.Name, *filter)
-		if err != nil {
-			return nil, errors.Wrapf(err, "finding existing PRs using filter %s on repo %s/%s", filter.String(), upstreamRepo.Organisation, upstreamRepo.Name)
-		}
-
-		if len(existingPrs) > 1 {
-			sort.SliceStable(existingPrs, func(i, j int) bool {
-				// sort in descending order of PR numbers (assumes PRs numbers increment!)
-				return util.DereferenceInt(existingPrs[j].Number) < util.DereferenceInt(existingPrs[i].Number)
-			})
-			prs := make([]string, 0)
-			for _, pr := range existingPrs {
-				prs = append(prs, pr.URL)
-			}
-			log.Logger().Debugf("Found more than one PR %s using filter %s on repo %s/%s so rebasing latest PR %s", strings.Join(prs, ", "), filter.String(), upstreamRepo.Organisation, upstreamRepo.Name, existingPrs[:1][0].URL)
-			existingPr = existingPrs[0]
-		} else if len(existingPrs) == 1 {
-			existingPr = existingPrs[0]
-		}
-	}
-	remoteBranch := prDetails.BranchName
-	if existingPr != nil {
-		if util.DereferenceString(existingPr.HeadOwner) == username && existingPr.HeadRef != nil && existingPr.Number != nil {
-			remote := "origin"
-			if forkRepo != nil && forkRepo.Fork {
-				remote = "upstream"
-			}
-			changeBranch, err := gitter.Branch(dir)
-			if err != nil {
-				return nil, errors.WithStack(err)
-			}
-			localBranchUUID, err := uuid.NewV4()
-			if err != nil {
-				return nil, errors.Wrapf(err, "creating UUID for local branch")
-			}
-			// We use this "dummy" local branch to pull into to avoid having to work with FETCH_HEAD as our local
-			// representation of the remote branch. This is an oddity of the pull/%d/head remote.
-			localBranch := localBranchUUID.String()
-			remoteBranch = *existingPr.HeadRef
-			fetchRefSpec := fmt.Sprintf("pull/%d/head:%s", *existingPr.Number, localBranch)
-			err = gitter.FetchBranch(dir, remote, fetchRefSpec)
-			if err != nil {
-				return nil, errors.Wrapf(err, "fetching %s for merge", fetchRefSpec)
-			}
-
-			err = gitter.CreateBranchFrom(dir, prDetails.BranchName, localBranch)
-			if err != nil {
-				return nil, errors.Wrapf(err, "creating branch %s from %s", prDetails.BranchName, fetchRefSpec)
-			}
-			err = gitter.Checkout(dir, prDetails.BranchName)
-			if err != nil {
-				return nil, errors.Wrapf(err, "checking out branch %s", prDetails.BranchName)
-			}
-			err = gitter.MergeTheirs(dir, changeBranch)
-			if err != nil {
-				return nil, errors.Wrapf(err, "merging %s into %s", changeBranch, fetchRefSpec)
-			}
-			err = gitter.RebaseTheirs(dir, fmt.Sprintf(localBranch), "", true)
-			if err != nil {
-				return nil, errors.WithStack(err)
-			}
-			changedFiles, err := gitter.ListChangedFilesFromBranch(dir, localBranch)
-			if err != nil {
-				return nil, errors.Wrap(err, "failed to list changed files")
-			}
-			if changedFiles == "" {
-				log.Logger().Info("No file changes since the existing PR. Nothing to push.")
-				return nil, nil
-			}
-		} else {
-			// We can only update an existing PR if the owner of that PR is this user, so we clear the existingPr
-			existingPr = nil
-		}
-	}
-	var pr *GitPullRequest
-	if !dryRun && existingPr != nil {
-		gha.Head = headPrefix + remoteBranch
-		// work out the minimal similar title
-		if strings.HasPrefix(existingPr.Title, "chore(deps): bump ") {
-			origWords := strings.Split(existingPr.Title, " ")
-			newWords := strings.Split(prDetails.Title, " ")
-			answer := make([]string, 0)
-			for i, w := range newWords {
-				if len(origWords) > i && origWords[i] == w {
-					answer = append(answer, w)
-				}
-			}
-			if answer[len(answer)-1] == "bump" {
-				// if there are no similarities in the actual dependency, then add a generic form of words
-				answer = append(answer, "dependency", "versions")
-			}
-			if answer[len(answer)-1] == "to" || answer[len(answer)-1] == "from" {
-				// remove trailing prepositions
-				answer = answer[:len(answer)-1]
-			}
-			gha.Title = strings.Join(answer, " ")
-		} else {
-			gha.Title = prDetails.Title
-		}
-		gha.Body = fmt.Sprintf("%s\n<hr />\n\n%s", prDetails.Message, existingPr.Body)
-		var err error
-		pr, err = provider.UpdatePullRequest(gha, *existingPr.Number)
-		if err != nil {
-			return nil, errors.Wrapf(err, "updating pull request %s", existingPr.URL)
-		}
-		log.Logger().Infof("Updated Pull Request: %s", util.ColorInfo(pr.URL))
-	}
-	if dryRun {
-		log.Logger().Infof("Commit created but not pushed; would have updated pull request %s with %s and used commit message %s. Please manually delete %s when you are done", util.ColorInfo(existingPr.URL), prDetails.String(), commitMessage, util.ColorInfo(dir))
-		return nil, nil
-	} else if push {
-		err := gitter.Push(dir, forkPushURL, true, fmt.Sprintf("%s:%s", "HEAD", remoteBranch))
-		if err != nil {
-			return nil, errors.Wrapf(err, "pushing merged branch %s", remoteBranch)
-		}
-	}
-	if existingPr == nil {
-		gha.Head = headPrefix + prDetails.BranchName
-
-		pr, err = provider.CreatePullRequest(gha)
-		if err != nil {
-			return nil, errors.Wrapf(err, "creating pull request with arguments %v", gha.String())
-		}
-		log.Logger().Infof("Created Pull Request: %s", util.ColorInfo(pr.URL))
-	}
+		Details:       prDetails,
+		Filter:        filter,
+		Commit:        commit,
+		CommitMessage: commitMessage,
+		Push:          push,
+		DryRun:        dryRun,
+		Gitter:        gitter,
+		Provider:      provider,
+	}
+	return creator.Create()
+}
 
-	prInfo := &PullRequestInfo{
-		GitProvider:          provider,
-		PullRequest:          pr,
-		PullRequestArguments: gha,
+// pullRequestFetchRefSpec returns the refspec used to fetch the head of the pull request into the local branch
+func pullRequestFetchRefSpec(provider GitProvider, pr *GitPullRequest, localBranch string) string {
+	if provider.Kind() == KindAzureDevOps {
+		// Azure DevOps does not expose a head ref for pull requests so lets fetch the source branch
+		return fmt.Sprintf("refs/heads/%s:%s", util.DereferenceString(pr.HeadRef), localBranch)
 	}
-
-	err = addLabelsToPullRequest(prInfo, prDetails.Labels)
-	if err != nil {
-		return nil, errors.Wrapf(err, "failed to add labels %+v to PR %s", prDetails.Labels, pr.URL)
-	}
-
-	return prInfo, nil
+	return fmt.Sprintf("pull/%d/head:%s", *pr.Number, localBranch)
 }
 
 // addLabelsToPullRequest adds the provided labels, if not already present, to the provided pull request
@@ -759,7 +594,15 @@ func (f *PullRequestFilter) String() string {
 
 // FilterOpenPullRequests looks for any pull requests on the owner/repo where all the labels match
 func FilterOpenPullRequests(provider GitProvider, owner string, repo string, filter PullRequestFilter) ([]*GitPullRequest, error) {
-	openPRs, err := provider.ListOpenPullRequests(owner, repo)
+	var openPRs []*GitPullRequest
+	var err error
+	labelLister, ok := provider.(OpenPullRequestLabelLister)
+	if ok && len(filter.Labels) > 0 && filter.Number == nil {
+		// lets only fetch the pull requests with any of the labels, those without all of them are filtered out below
+		openPRs, err = labelLister.ListOpenPullRequestsWithLabels(owner, repo, filter.Labels)
+	} else {
+		openPRs, err = provider.ListOpenPullRequests(owner, repo)
+	}
 	if err != nil {
 		return nil, errors.Wrapf(err, "listing open pull requests on %s/%s", owner, repo)
 	}
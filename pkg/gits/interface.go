@@ -199,6 +199,8 @@ type Gitter interface {
 
 	Init(dir string) error
 	Clone(url string, directory string) error
+	// CloneWithOptions clones the repository using the given options to limit the history, blobs or paths cloned
+	CloneWithOptions(url string, directory string, options *CloneOptions) error
 	CloneBare(dir string, url string) error
 	PushMirror(dir string, url string) error
 
@@ -244,6 +246,8 @@ type Gitter interface {
 	ConvertToValidBranchName(name string) string
 	FetchBranch(dir string, repo string, refspec ...string) error
 	FetchBranchShallow(dir string, repo string, refspec ...string) error
+	// FetchBranchWithOptions fetches the refspecs using the given options to limit the history or blobs fetched
+	FetchBranchWithOptions(dir string, repo string, options *CloneOptions, refspec ...string) error
 	FetchBranchUnshallow(dir string, repo string, refspec ...string) error
 	Merge(dir string, commitish string) error
 	MergeTheirs(dir string, commitish string) error
@@ -283,6 +287,9 @@ type Gitter interface {
 	GetLatestCommitSha(dir string) (string, error)
 	GetFirstCommitSha(dir string) (string, error)
 	GetCommits(dir string, start string, end string) ([]GitCommit, error)
+	// GetCommitsForFile returns the most recent commits which changed the file, newest first, returning every commit if
+	// maxCount is not positive
+	GetCommitsForFile(dir string, file string, maxCount int) ([]GitCommit, error)
 	RevParse(dir string, rev string) (string, error)
 	GetCommitsNotOnAnyRemote(dir string, branch string) ([]GitCommit, error)
 	Describe(dir string, contains bool, commitish string, abbrev string, fallback bool) (string, string, error)
@@ -305,6 +312,20 @@ type PullRequestDetails struct {
 	BranchName string
 	Title      string
 	Labels     []string
+	// CommitMessage the message of the commit of the changes, defaults to the Message
+	CommitMessage string
+	// Draft creates the pull request as a draft, if supported by the git provider
+	Draft bool
+	// AutoMerge makes the git provider merge the pull request once its checks pass
+	AutoMerge bool
+	// MergeQueue adds the pull request to the merge queue of the git provider
+	MergeQueue bool
+	// Reviewers the users whose reviews of the pull request are requested
+	Reviewers []string
+	// TeamReviewers the teams whose reviews of the pull request are requested, if supported by the git provider
+	TeamReviewers []string
+	// Assignees the users the pull request is assigned to
+	Assignees []string
 }
 
 func (p *PullRequestDetails) String() string {
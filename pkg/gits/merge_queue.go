@@ -0,0 +1,78 @@
+package gits
+
+import (
+	"fmt"
+
+	"github.com/jenkins-x/jx/v2/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+)
+
+const (
+	// MergeQueueStateQueued the pull request is waiting in the merge queue
+	MergeQueueStateQueued = "QUEUED"
+	// MergeQueueStateAwaitingChecks the checks of the pull request are running in the merge queue
+	MergeQueueStateAwaitingChecks = "AWAITING_CHECKS"
+	// MergeQueueStateMergeable the pull request passed its checks and is about to be merged
+	MergeQueueStateMergeable = "MERGEABLE"
+	// MergeQueueStateUnmergeable the pull request cannot be merged by the merge queue
+	MergeQueueStateUnmergeable = "UNMERGEABLE"
+)
+
+// MergeQueueEntry is the entry of a pull request in a merge queue
+type MergeQueueEntry struct {
+	// State the state of the pull request in the queue, such as QUEUED or AWAITING_CHECKS
+	State string
+	// Position the position of the pull request in the queue, starting at 1
+	Position int
+}
+
+// String returns a description of the entry for logging
+func (e *MergeQueueEntry) String() string {
+	return fmt.Sprintf("%s at position %d", e.State, e.Position)
+}
+
+// PullRequestMergeQueuer is implemented by git providers with merge queues, which merge a pull request once its
+// checks pass against the base branch together with the pull requests queued ahead of it
+type PullRequestMergeQueuer interface {
+	// EnqueuePullRequest adds the pull request to the merge queue of its base branch
+	EnqueuePullRequest(pr *GitPullRequest) error
+	// GetMergeQueueEntry returns the entry of the pull request in the merge queue, or nil if it is not queued
+	GetMergeQueueEntry(pr *GitPullRequest) (*MergeQueueEntry, error)
+}
+
+// enqueue adds the pull request to the merge queue, returning false if the git provider cannot so that the pull
+// request is merged some other way
+func (c *PullRequestCreator) enqueue(pr *GitPullRequest) bool {
+	queuer, ok := c.Provider.(PullRequestMergeQueuer)
+	if !ok {
+		log.Logger().Debugf("%s does not support merge queues so %s is not queued", c.Provider.Kind(), pr.URL)
+		return false
+	}
+	err := queuer.EnqueuePullRequest(pr)
+	if err != nil {
+		log.Logger().Warnf("failed to add PR %s to the merge queue so enabling auto merge instead: %s", pr.URL, err)
+		return false
+	}
+	log.Logger().Infof("Pull Request %s was added to the merge queue", util.ColorInfo(pr.URL))
+	return true
+}
+
+// logMergeQueueChanges logs the state of the pull request in the merge queue if it changed since it was last seen,
+// returning an error if the merge queue cannot merge the pull request
+func logMergeQueueChanges(queuer PullRequestMergeQueuer, pr *GitPullRequest, last *MergeQueueEntry) (*MergeQueueEntry, error) {
+	entry, err := queuer.GetMergeQueueEntry(pr)
+	if err != nil {
+		log.Logger().Warnf("failed to get the merge queue entry of pull request %s: %s", pr.URL, err)
+		return last, nil
+	}
+	switch {
+	case entry == nil && last != nil:
+		log.Logger().Warnf("Pull Request %s was removed from the merge queue", util.ColorInfo(pr.URL))
+	case entry != nil && (last == nil || *entry != *last):
+		log.Logger().Infof("Pull Request %s is %s in the merge queue", util.ColorInfo(pr.URL), util.ColorInfo(entry.String()))
+	}
+	if entry != nil && entry.State == MergeQueueStateUnmergeable {
+		return entry, fmt.Errorf("the merge queue cannot merge pull request %s", pr.URL)
+	}
+	return entry, nil
+}
@@ -0,0 +1,109 @@
+package gits
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	v1 "github.com/jenkins-x/jx/v2/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/v2/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+)
+
+// MirrorConfigurer is implemented by Gitters which can clone repositories from mirrors
+type MirrorConfigurer interface {
+	// ConfigureMirrors makes subsequent clones try the mirror of a repository before its origin
+	ConfigureMirrors(mirrors []v1.GitMirror)
+}
+
+// MirrorURL returns the URL of the mirror of the given git URL or blank if it is not mirrored. If more than one
+// mirror matches the mirror with the longest origin URL is used
+func MirrorURL(gitURL string, mirrors []v1.GitMirror) string {
+	u := withoutUserInfo(gitURL)
+	answer := ""
+	longest := 0
+	for _, mirror := range mirrors {
+		origin := mirror.OriginURL
+		if origin == "" || mirror.MirrorURL == "" || len(origin) <= longest {
+			continue
+		}
+		rest, ok := trimURLPrefix(u, origin)
+		if !ok {
+			continue
+		}
+		answer = strings.TrimSuffix(mirror.MirrorURL, "/")
+		if rest != "" {
+			answer = util.UrlJoin(answer, rest)
+		}
+		longest = len(origin)
+	}
+	return answer
+}
+
+// trimURLPrefix returns the rest of the URL after the prefix if the URL starts with it, ignoring a trailing slash
+// on the prefix so that https://github.com/jenkins-x does not match https://github.com/jenkins-x-apps/foo
+func trimURLPrefix(u string, prefix string) (string, bool) {
+	prefix = strings.TrimSuffix(prefix, "/")
+	if u == prefix {
+		return "", true
+	}
+	if !strings.HasPrefix(u, prefix+"/") {
+		return "", false
+	}
+	return strings.TrimPrefix(u, prefix+"/"), true
+}
+
+// withoutUserInfo removes any user name and password from the URL
+func withoutUserInfo(gitURL string) string {
+	u, err := url.Parse(gitURL)
+	if err != nil || u.User == nil {
+		return gitURL
+	}
+	u.User = nil
+	return u.String()
+}
+
+// ConfigureMirrors makes subsequent clones try the mirror of a repository before its origin
+func (g *GitCLI) ConfigureMirrors(mirrors []v1.GitMirror) {
+	g.mirrors = mirrors
+}
+
+// cloneFromMirror clones the repository from its mirror, if it has one, falling back to the origin if the mirror
+// fails. The origin remote of a repository cloned from a mirror is changed to the origin URL so that changes are
+// pushed to the origin
+func cloneFromMirror(gitter Gitter, mirrors []v1.GitMirror, gitURL string, dir string, clone func(gitURL string) error) error {
+	mirrorURL := MirrorURL(gitURL, mirrors)
+	if mirrorURL == "" {
+		return clone(gitURL)
+	}
+	cleanup := newCloneCleanup(dir)
+	err := clone(mirrorURL)
+	if err == nil {
+		err = gitter.SetRemoteURL(dir, "origin", gitURL)
+		if err == nil {
+			log.Logger().Debugf("cloned %s from the mirror %s", withoutUserInfo(gitURL), mirrorURL)
+			return nil
+		}
+	}
+	log.Logger().Warnf("failed to clone %s from the mirror %s, falling back to the origin: %s",
+		withoutUserInfo(gitURL), mirrorURL, err)
+	cleanup.clean()
+	return clone(gitURL)
+}
+
+// MirrorURLForHost returns the URL prefix of the mirror of the origin URL on a mirror host which mirrors
+// repositories under their origin host and path, such as https://mirror.example.com/github.com/jenkins-x
+func MirrorURLForHost(host string, originURL string) (string, error) {
+	u, err := url.Parse(originURL)
+	if err != nil {
+		return "", errors.Wrapf(err, "parsing the origin URL %s", originURL)
+	}
+	if u.Host == "" {
+		return "", fmt.Errorf("the origin URL %s has no host", originURL)
+	}
+	if !strings.Contains(host, "://") {
+		host = "https://" + host
+	}
+	return strings.TrimSuffix(util.UrlJoin(host, u.Host, strings.Trim(u.Path, "/")), "/"), nil
+}
@@ -261,6 +261,21 @@ func (mock *MockGitter) CloneBare(_param0 string, _param1 string) error {
 	return ret0
 }
 
+func (mock *MockGitter) CloneWithOptions(_param0 string, _param1 string, _param2 *gits.CloneOptions) error {
+	if mock == nil {
+		panic("mock must not be nil. Use myMock := NewMockGitter().")
+	}
+	params := []pegomock.Param{_param0, _param1, _param2}
+	result := pegomock.GetGenericMockFrom(mock).Invoke("CloneWithOptions", params, []reflect.Type{reflect.TypeOf((*error)(nil)).Elem()})
+	var ret0 error
+	if len(result) != 0 {
+		if result[0] != nil {
+			ret0 = result[0].(error)
+		}
+	}
+	return ret0
+}
+
 func (mock *MockGitter) CloneOrPull(_param0 string, _param1 string) error {
 	if mock == nil {
 		panic("mock must not be nil. Use myMock := NewMockGitter().")
@@ -568,6 +583,24 @@ func (mock *MockGitter) FetchBranchShallow(_param0 string, _param1 string, _para
 	return ret0
 }
 
+func (mock *MockGitter) FetchBranchWithOptions(_param0 string, _param1 string, _param2 *gits.CloneOptions, _param3 ...string) error {
+	if mock == nil {
+		panic("mock must not be nil. Use myMock := NewMockGitter().")
+	}
+	params := []pegomock.Param{_param0, _param1, _param2}
+	for _, param := range _param3 {
+		params = append(params, param)
+	}
+	result := pegomock.GetGenericMockFrom(mock).Invoke("FetchBranchWithOptions", params, []reflect.Type{reflect.TypeOf((*error)(nil)).Elem()})
+	var ret0 error
+	if len(result) != 0 {
+		if result[0] != nil {
+			ret0 = result[0].(error)
+		}
+	}
+	return ret0
+}
+
 func (mock *MockGitter) FetchBranchUnshallow(_param0 string, _param1 string, _param2 ...string) error {
 	if mock == nil {
 		panic("mock must not be nil. Use myMock := NewMockGitter().")
@@ -791,6 +824,25 @@ func (mock *MockGitter) GetCommits(_param0 string, _param1 string, _param2 strin
 	return ret0, ret1
 }
 
+func (mock *MockGitter) GetCommitsForFile(_param0 string, _param1 string, _param2 int) ([]gits.GitCommit, error) {
+	if mock == nil {
+		panic("mock must not be nil. Use myMock := NewMockGitter().")
+	}
+	params := []pegomock.Param{_param0, _param1, _param2}
+	result := pegomock.GetGenericMockFrom(mock).Invoke("GetCommitsForFile", params, []reflect.Type{reflect.TypeOf((*[]gits.GitCommit)(nil)).Elem(), reflect.TypeOf((*error)(nil)).Elem()})
+	var ret0 []gits.GitCommit
+	var ret1 error
+	if len(result) != 0 {
+		if result[0] != nil {
+			ret0 = result[0].([]gits.GitCommit)
+		}
+		if result[1] != nil {
+			ret1 = result[1].(error)
+		}
+	}
+	return ret0, ret1
+}
+
 func (mock *MockGitter) GetCommitsNotOnAnyRemote(_param0 string, _param1 string) ([]gits.GitCommit, error) {
 	if mock == nil {
 		panic("mock must not be nil. Use myMock := NewMockGitter().")
@@ -2249,6 +2301,41 @@ func (c *MockGitter_CloneBare_OngoingVerification) GetAllCapturedArguments() (_p
 	return
 }
 
+func (verifier *VerifierMockGitter) CloneWithOptions(_param0 string, _param1 string, _param2 *gits.CloneOptions) *MockGitter_CloneWithOptions_OngoingVerification {
+	params := []pegomock.Param{_param0, _param1, _param2}
+	methodInvocations := pegomock.GetGenericMockFrom(verifier.mock).Verify(verifier.inOrderContext, verifier.invocationCountMatcher, "CloneWithOptions", params, verifier.timeout)
+	return &MockGitter_CloneWithOptions_OngoingVerification{mock: verifier.mock, methodInvocations: methodInvocations}
+}
+
+type MockGitter_CloneWithOptions_OngoingVerification struct {
+	mock              *MockGitter
+	methodInvocations []pegomock.MethodInvocation
+}
+
+func (c *MockGitter_CloneWithOptions_OngoingVerification) GetCapturedArguments() (string, string, *gits.CloneOptions) {
+	_param0, _param1, _param2 := c.GetAllCapturedArguments()
+	return _param0[len(_param0)-1], _param1[len(_param1)-1], _param2[len(_param2)-1]
+}
+
+func (c *MockGitter_CloneWithOptions_OngoingVerification) GetAllCapturedArguments() (_param0 []string, _param1 []string, _param2 []*gits.CloneOptions) {
+	params := pegomock.GetGenericMockFrom(c.mock).GetInvocationParams(c.methodInvocations)
+	if len(params) > 0 {
+		_param0 = make([]string, len(c.methodInvocations))
+		for u, param := range params[0] {
+			_param0[u] = param.(string)
+		}
+		_param1 = make([]string, len(c.methodInvocations))
+		for u, param := range params[1] {
+			_param1[u] = param.(string)
+		}
+		_param2 = make([]*gits.CloneOptions, len(c.methodInvocations))
+		for u, param := range params[2] {
+			_param2[u] = param.(*gits.CloneOptions)
+		}
+	}
+	return
+}
+
 func (verifier *VerifierMockGitter) CloneOrPull(_param0 string, _param1 string) *MockGitter_CloneOrPull_OngoingVerification {
 	params := []pegomock.Param{_param0, _param1}
 	methodInvocations := pegomock.GetGenericMockFrom(verifier.mock).Verify(verifier.inOrderContext, verifier.invocationCountMatcher, "CloneOrPull", params, verifier.timeout)
@@ -2843,6 +2930,53 @@ func (c *MockGitter_FetchBranchShallow_OngoingVerification) GetAllCapturedArgume
 	return
 }
 
+func (verifier *VerifierMockGitter) FetchBranchWithOptions(_param0 string, _param1 string, _param2 *gits.CloneOptions, _param3 ...string) *MockGitter_FetchBranchWithOptions_OngoingVerification {
+	params := []pegomock.Param{_param0, _param1, _param2}
+	for _, param := range _param3 {
+		params = append(params, param)
+	}
+	methodInvocations := pegomock.GetGenericMockFrom(verifier.mock).Verify(verifier.inOrderContext, verifier.invocationCountMatcher, "FetchBranchWithOptions", params, verifier.timeout)
+	return &MockGitter_FetchBranchWithOptions_OngoingVerification{mock: verifier.mock, methodInvocations: methodInvocations}
+}
+
+type MockGitter_FetchBranchWithOptions_OngoingVerification struct {
+	mock              *MockGitter
+	methodInvocations []pegomock.MethodInvocation
+}
+
+func (c *MockGitter_FetchBranchWithOptions_OngoingVerification) GetCapturedArguments() (string, string, *gits.CloneOptions, []string) {
+	_param0, _param1, _param2, _param3 := c.GetAllCapturedArguments()
+	return _param0[len(_param0)-1], _param1[len(_param1)-1], _param2[len(_param2)-1], _param3[len(_param3)-1]
+}
+
+func (c *MockGitter_FetchBranchWithOptions_OngoingVerification) GetAllCapturedArguments() (_param0 []string, _param1 []string, _param2 []*gits.CloneOptions, _param3 [][]string) {
+	params := pegomock.GetGenericMockFrom(c.mock).GetInvocationParams(c.methodInvocations)
+	if len(params) > 0 {
+		_param0 = make([]string, len(c.methodInvocations))
+		for u, param := range params[0] {
+			_param0[u] = param.(string)
+		}
+		_param1 = make([]string, len(c.methodInvocations))
+		for u, param := range params[1] {
+			_param1[u] = param.(string)
+		}
+		_param2 = make([]*gits.CloneOptions, len(c.methodInvocations))
+		for u, param := range params[2] {
+			_param2[u] = param.(*gits.CloneOptions)
+		}
+		_param3 = make([][]string, len(c.methodInvocations))
+		for u := 0; u < len(c.methodInvocations); u++ {
+			_param3[u] = make([]string, len(params)-3)
+			for x := 3; x < len(params); x++ {
+				if params[x][u] != nil {
+					_param3[u][x-3] = params[x][u].(string)
+				}
+			}
+		}
+	}
+	return
+}
+
 func (verifier *VerifierMockGitter) FetchBranchUnshallow(_param0 string, _param1 string, _param2 ...string) *MockGitter_FetchBranchUnshallow_OngoingVerification {
 	params := []pegomock.Param{_param0, _param1}
 	for _, param := range _param2 {
@@ -3215,6 +3349,41 @@ func (c *MockGitter_GetCommits_OngoingVerification) GetAllCapturedArguments() (_
 	return
 }
 
+func (verifier *VerifierMockGitter) GetCommitsForFile(_param0 string, _param1 string, _param2 int) *MockGitter_GetCommitsForFile_OngoingVerification {
+	params := []pegomock.Param{_param0, _param1, _param2}
+	methodInvocations := pegomock.GetGenericMockFrom(verifier.mock).Verify(verifier.inOrderContext, verifier.invocationCountMatcher, "GetCommitsForFile", params, verifier.timeout)
+	return &MockGitter_GetCommitsForFile_OngoingVerification{mock: verifier.mock, methodInvocations: methodInvocations}
+}
+
+type MockGitter_GetCommitsForFile_OngoingVerification struct {
+	mock              *MockGitter
+	methodInvocations []pegomock.MethodInvocation
+}
+
+func (c *MockGitter_GetCommitsForFile_OngoingVerification) GetCapturedArguments() (string, string, int) {
+	_param0, _param1, _param2 := c.GetAllCapturedArguments()
+	return _param0[len(_param0)-1], _param1[len(_param1)-1], _param2[len(_param2)-1]
+}
+
+func (c *MockGitter_GetCommitsForFile_OngoingVerification) GetAllCapturedArguments() (_param0 []string, _param1 []string, _param2 []int) {
+	params := pegomock.GetGenericMockFrom(c.mock).GetInvocationParams(c.methodInvocations)
+	if len(params) > 0 {
+		_param0 = make([]string, len(c.methodInvocations))
+		for u, param := range params[0] {
+			_param0[u] = param.(string)
+		}
+		_param1 = make([]string, len(c.methodInvocations))
+		for u, param := range params[1] {
+			_param1[u] = param.(string)
+		}
+		_param2 = make([]int, len(c.methodInvocations))
+		for u, param := range params[2] {
+			_param2[u] = param.(int)
+		}
+	}
+	return
+}
+
 func (verifier *VerifierMockGitter) GetCommitsNotOnAnyRemote(_param0 string, _param1 string) *MockGitter_GetCommitsNotOnAnyRemote_OngoingVerification {
 	params := []pegomock.Param{_param0, _param1}
 	methodInvocations := pegomock.GetGenericMockFrom(verifier.mock).Verify(verifier.inOrderContext, verifier.invocationCountMatcher, "GetCommitsNotOnAnyRemote", params, verifier.timeout)
@@ -19,7 +19,9 @@ import (
 	"sort"
 	"strings"
 
+	"github.com/jenkins-x/jx/v2/pkg/config"
 	"github.com/jenkins-x/jx/v2/pkg/dependencymatrix"
+	"github.com/jenkins-x/jx/v2/pkg/gitops/batch"
 	"github.com/jenkins-x/jx/v2/pkg/gits"
 	"github.com/jenkins-x/jx/v2/pkg/gits/releases"
 	"github.com/jenkins-x/jx/v2/pkg/log"
@@ -55,6 +57,14 @@ type PullRequestOperation struct {
 	AuthorEmail   string
 	SkipAutoMerge bool
 	Labels        []string
+	Draft         bool
+	Reviewers     []string
+	TeamReviewers []string
+	Assignees     []string
+	// NamingConventions the templates of the branch names, titles and commit messages of the pull requests
+	NamingConventions *config.PullRequestsConfig
+	// Provenance describes how the changes were generated, if specified it is embedded in the pull requests
+	Provenance *gits.Provenance
 }
 
 // ChangeFilesFn is the function called to create the pull request
@@ -63,46 +73,48 @@ type ChangeFilesFn func(dir string, gitInfo *gits.GitRepository) ([]string, erro
 // CreatePullRequest will fork (if needed) and pull a git repo, then perform the update, and finally create or update a
 // PR for the change. Any open PR on the repo with the `updatebot` label will be updated.
 func (o *PullRequestOperation) CreatePullRequest(kind string, update ChangeFilesFn) (*gits.PullRequestInfo, error) {
-	var result *gits.PullRequestInfo
-	for _, gitURL := range o.GitURLs {
-		dir, err := ioutil.TempDir("", "create-pr")
-		if err != nil {
-			return nil, err
-		}
-		provider, _, err := o.CreateGitProviderForURLWithoutKind(gitURL)
-		if err != nil {
-			return nil, errors.Wrapf(err, "creating git provider for directory %s", dir)
-		}
-
-		dir, _, upstreamInfo, forkInfo, err := gits.ForkAndPullRepo(gitURL, dir, o.Base, o.BranchName, provider, o.Git(), "")
-		if err != nil {
-			return nil, errors.Wrapf(err, "failed to fork and pull %s", o.GitURLs)
-		}
-
-		gitInfo := upstreamInfo
-		if forkInfo != nil {
-			gitInfo = forkInfo
-		}
-		commitMessage, details, err := o.updateAndGenerateMessagesAndDependencyMatrix(dir, kind, upstreamInfo.Host, gitInfo, update)
+	labels := []string{}
+	if !o.SkipAutoMerge {
+		labels = append(labels, "updatebot")
+	}
+	if len(o.Labels) > 0 {
+		labels = append(labels, o.Labels...)
+	}
+	b := &batch.Batch{
+		GitURLs:       o.GitURLs,
+		Base:          o.Base,
+		BranchName:    o.BranchName,
+		Labels:        labels,
+		SkipCommit:    o.SkipCommit,
+		DryRun:        o.DryRun,
+		Draft:         o.Draft,
+		Reviewers:     o.Reviewers,
+		TeamReviewers: o.TeamReviewers,
+		Assignees:     o.Assignees,
+		Provenance:    o.Provenance,
+		Gitter:        o.Git(),
+		CreateProvider: func(gitURL string) (gits.GitProvider, error) {
+			provider, _, err := o.CreateGitProviderForURLWithoutKind(gitURL)
+			return provider, err
+		},
+	}
+	results, err := b.Apply(func(dir string, gitInfo *gits.GitRepository) (*batch.Change, error) {
+		commitMessage, details, err := o.updateAndGenerateMessagesAndDependencyMatrix(dir, kind, gitInfo.Host, gitInfo, update)
 		if err != nil {
 			return nil, errors.WithStack(err)
 		}
-
-		labels := []string{}
-		if !o.SkipAutoMerge {
-			labels = append(labels, "updatebot")
-		}
-		if len(o.Labels) > 0 {
-			labels = append(labels, o.Labels...)
-		}
-		filter := &gits.PullRequestFilter{
-			Labels: labels,
-		}
-
-		details.Labels = labels
-		result, err = gits.PushRepoAndCreatePullRequest(dir, upstreamInfo, forkInfo, o.Base, details, filter, !o.SkipCommit, commitMessage, true, o.DryRun, o.Git(), provider)
-		if err != nil {
-			return nil, errors.Wrapf(err, "failed to create PR for base %s and head branch %s from temp dir %s", o.Base, details.BranchName, dir)
+		return &batch.Change{
+			Details:       details,
+			CommitMessage: commitMessage,
+		}, nil
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to create PRs for base %s and head branch %s", o.Base, o.BranchName)
+	}
+	var result *gits.PullRequestInfo
+	for _, r := range results {
+		if r.PullRequestInfo != nil {
+			result = r.PullRequestInfo
 		}
 	}
 	return result, nil
@@ -219,7 +231,29 @@ func (o *PullRequestOperation) updateAndGenerateMessagesAndDependencyMatrix(dir
 			}
 		}
 	}
-	return commitMessage, details, nil
+
+	details.CommitMessage = commitMessage
+	err = details.ApplyNamingConventions(o.NamingConventions, gits.PullRequestNamingValues{
+		Kind:      kind,
+		Version:   version,
+		Component: o.componentName(),
+	}, o.Git())
+	if err != nil {
+		return "", nil, errors.Wrap(err, "applying the pull request naming conventions")
+	}
+	return details.CommitMessage, details, nil
+}
+
+// componentName returns the name of the component being updated, defaulting to the name of the source repository
+func (o *PullRequestOperation) componentName() string {
+	if o.Component != "" {
+		return o.Component
+	}
+	gitInfo, err := gits.ParseGitURL(o.SrcGitURL)
+	if err != nil {
+		return ""
+	}
+	return gitInfo.Name
 }
 
 // AddDependencyMatrixUpdatePaths retrieves the upstreamDependencyAsset and converts it to a slice of DependencyUpdates, prepending the updateDependency to the path
@@ -557,6 +591,7 @@ func CreateChartChangeFilesFn(name string, version string, kind string, pro *Pul
 		if version == "" && kind == string(versionstream.KindChart) {
 			parts := strings.Split(name, "/")
 			searchName := name
+			ociRepo := ""
 			if len(parts) == 2 {
 				prefixes, err := versionstream.GetRepositoryPrefixes(dir)
 				if err != nil {
@@ -574,14 +609,25 @@ func CreateChartChangeFilesFn(name string, version string, kind string, pro *Pul
 					if err != nil {
 						return nil, errors.Wrapf(err, "adding repository %s with url %s", prefix, urls[0])
 					}
+					if helm.IsOCIRepository(urls[0]) {
+						ociRepo = urls[0]
+					}
 				}
 				searchName = fmt.Sprintf("%s/%s", prefix, parts[1])
 			}
-			c, err := helm.FindLatestChart(searchName, helmer)
-			if err != nil {
-				return nil, errors.Wrapf(err, "failed to find latest chart version for %s", name)
+			if ociRepo != "" {
+				latest, err := helm.FindLatestOCIChartVersion(parts[1], ociRepo, "", "", helmer)
+				if err != nil {
+					return nil, errors.Wrapf(err, "failed to find latest chart version for %s", name)
+				}
+				version = latest
+			} else {
+				c, err := helm.FindLatestChart(searchName, helmer)
+				if err != nil {
+					return nil, errors.Wrapf(err, "failed to find latest chart version for %s", name)
+				}
+				version = c.ChartVersion
 			}
-			version = c.ChartVersion
 			log.Logger().Infof("found latest version %s for chart %s\n", util.ColorInfo(version), util.ColorInfo(name))
 		}
 		pro.Version = version
@@ -596,7 +642,11 @@ func CreateChartChangeFilesFn(name string, version string, kind string, pro *Pul
 			}
 		}
 		if pro.SrcGitURL == "" {
-			err := helm.InspectChart(name, version, "", "", "", helmer, func(dir string) error {
+			repo, err := helm.OCIRepositoryForChart(dir, name)
+			if err != nil {
+				return nil, err
+			}
+			err = helm.InspectChart(name, version, repo, "", "", helmer, func(dir string) error {
 				fileName, err := helm.FindChartFileName(dir)
 				if err != nil {
 					return errors.Wrapf(err, "find chart file")
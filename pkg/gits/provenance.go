@@ -0,0 +1,168 @@
+package gits
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/jenkins-x/jx/v2/pkg/version"
+	"github.com/pkg/errors"
+)
+
+const (
+	// ProvenanceTriggeredByEnvVar the environment variable naming the user who triggered a change jx generates
+	ProvenanceTriggeredByEnvVar = "JX_TRIGGERED_BY"
+
+	provenanceBlockStart = "<!-- jx-provenance"
+	provenanceBlockEnd   = "-->"
+)
+
+// Provenance describes how jx generated a pull request or commit so that the change can be audited. It is embedded
+// as a hidden block in the bodies of the pull requests and as trailers in the commit messages
+type Provenance struct {
+	// Kind what generated the change, such as promote, upgrade or the kind of dependency updated
+	Kind string `json:"kind,omitempty"`
+	// ToolVersion the version of jx which generated the change
+	ToolVersion string `json:"toolVersion,omitempty"`
+	// VersionStreamURL the version stream the change was generated from
+	VersionStreamURL string `json:"versionStreamURL,omitempty"`
+	// VersionStreamRef the git SHA or ref of the version stream
+	VersionStreamRef string `json:"versionStreamRef,omitempty"`
+	// TriggeredBy the user who triggered the change
+	TriggeredBy string `json:"triggeredBy,omitempty"`
+	// Pipeline the pipeline which generated the change, such as myorg/myapp/master #3
+	Pipeline string `json:"pipeline,omitempty"`
+}
+
+// provenanceTrailer maps a field of the provenance to its git trailer
+type provenanceTrailer struct {
+	key   string
+	value func(p *Provenance) *string
+}
+
+var provenanceTrailers = []provenanceTrailer{
+	{"Jx-Kind", func(p *Provenance) *string { return &p.Kind }},
+	{"Jx-Version", func(p *Provenance) *string { return &p.ToolVersion }},
+	{"Jx-Version-Stream", func(p *Provenance) *string { return &p.VersionStreamURL }},
+	{"Jx-Version-Stream-Ref", func(p *Provenance) *string { return &p.VersionStreamRef }},
+	{"Jx-Triggered-By", func(p *Provenance) *string { return &p.TriggeredBy }},
+	{"Jx-Pipeline", func(p *Provenance) *string { return &p.Pipeline }},
+}
+
+// NewProvenance creates the provenance of a change of the given kind generated by this version of jx, taking the
+// user who triggered it and the pipeline running it from the environment
+func NewProvenance(kind string) *Provenance {
+	return &Provenance{
+		Kind:        kind,
+		ToolVersion: version.GetVersion(),
+		TriggeredBy: os.Getenv(ProvenanceTriggeredByEnvVar),
+		Pipeline:    pipelineFromEnv(),
+	}
+}
+
+// pipelineFromEnv describes the pipeline this is running in, if any
+func pipelineFromEnv() string {
+	owner := os.Getenv("REPO_OWNER")
+	repo := os.Getenv("REPO_NAME")
+	build := os.Getenv("BUILD_NUMBER")
+	if build == "" {
+		build = os.Getenv("BUILD_ID")
+	}
+	if owner == "" || repo == "" {
+		return os.Getenv("JOB_NAME")
+	}
+	answer := owner + "/" + repo
+	if branch := os.Getenv("BRANCH_NAME"); branch != "" {
+		answer += "/" + branch
+	}
+	if build != "" {
+		answer += " #" + build
+	}
+	return answer
+}
+
+// AddToBody returns the body of a pull request with the provenance block at the end, replacing any provenance block
+// already in it. The body is returned unchanged if there is no provenance
+func (p *Provenance) AddToBody(body string) (string, error) {
+	if p == nil {
+		return body, nil
+	}
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return body, errors.Wrap(err, "marshalling the provenance")
+	}
+	body = strings.TrimRight(RemoveProvenance(body), "\n")
+	return fmt.Sprintf("%s\n\n%s\n%s\n%s\n", body, provenanceBlockStart, string(data), provenanceBlockEnd), nil
+}
+
+// AddTrailers returns the commit message with the provenance appended as git trailers. The message is returned
+// unchanged if there is no provenance
+func (p *Provenance) AddTrailers(message string) string {
+	if p == nil {
+		return message
+	}
+	lines := []string{}
+	for _, trailer := range provenanceTrailers {
+		value := strings.TrimSpace(*trailer.value(p))
+		if value != "" {
+			lines = append(lines, fmt.Sprintf("%s: %s", trailer.key, value))
+		}
+	}
+	if len(lines) == 0 {
+		return message
+	}
+	return strings.TrimRight(message, "\n") + "\n\n" + strings.Join(lines, "\n")
+}
+
+// RemoveProvenance returns the body of a pull request without its provenance block
+func RemoveProvenance(body string) string {
+	start := strings.Index(body, provenanceBlockStart)
+	if start < 0 {
+		return body
+	}
+	end := strings.Index(body[start:], provenanceBlockEnd)
+	if end < 0 {
+		return body
+	}
+	return body[:start] + body[start+end+len(provenanceBlockEnd):]
+}
+
+// ParseProvenance returns the provenance embedded in the body of a pull request, or nil if it has none
+func ParseProvenance(body string) (*Provenance, error) {
+	start := strings.Index(body, provenanceBlockStart)
+	if start < 0 {
+		return nil, nil
+	}
+	rest := body[start+len(provenanceBlockStart):]
+	end := strings.Index(rest, provenanceBlockEnd)
+	if end < 0 {
+		return nil, errors.New("the provenance block is not terminated")
+	}
+	answer := &Provenance{}
+	err := json.Unmarshal([]byte(rest[:end]), answer)
+	if err != nil {
+		return nil, errors.Wrap(err, "parsing the provenance block")
+	}
+	return answer, nil
+}
+
+// ParseProvenanceTrailers returns the provenance in the trailers of a commit message, or nil if it has none
+func ParseProvenanceTrailers(message string) *Provenance {
+	var answer *Provenance
+	scanner := bufio.NewScanner(strings.NewReader(message))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		for _, trailer := range provenanceTrailers {
+			prefix := trailer.key + ":"
+			if strings.HasPrefix(line, prefix) {
+				if answer == nil {
+					answer = &Provenance{}
+				}
+				*trailer.value(answer) = strings.TrimSpace(strings.TrimPrefix(line, prefix))
+			}
+		}
+	}
+	return answer
+}
@@ -0,0 +1,71 @@
+// +build unit
+
+package gits_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jenkins-x/jx/v2/pkg/gits"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProvenanceBody(t *testing.T) {
+	t.Parallel()
+
+	provenance := &gits.Provenance{
+		Kind:             "promote",
+		ToolVersion:      "2.1.0",
+		VersionStreamURL: "https://github.com/jenkins-x/jenkins-x-versions.git",
+		VersionStreamRef: "0123abc",
+		TriggeredBy:      "jstrachan",
+		Pipeline:         "myorg/myapp/master #3",
+	}
+	body, err := provenance.AddToBody("chore: Promote myapp to version 1.2.3")
+	require.NoError(t, err)
+	assert.Contains(t, body, "chore: Promote myapp to version 1.2.3\n\n<!-- jx-provenance\n")
+
+	parsed, err := gits.ParseProvenance(body)
+	require.NoError(t, err)
+	assert.Equal(t, provenance, parsed)
+
+	provenance.ToolVersion = "2.1.1"
+	updated, err := provenance.AddToBody("chore: Promote myapp to version 1.2.4\n<hr />\n\n" + body)
+	require.NoError(t, err)
+	assert.Equal(t, 1, strings.Count(updated, "<!-- jx-provenance"), "the old provenance block should be replaced")
+	parsed, err = gits.ParseProvenance(updated)
+	require.NoError(t, err)
+	assert.Equal(t, "2.1.1", parsed.ToolVersion)
+	assert.Equal(t, "chore: Promote myapp to version 1.2.3", strings.TrimSpace(gits.RemoveProvenance(body)))
+
+	parsed, err = gits.ParseProvenance("a pull request someone else created")
+	require.NoError(t, err)
+	assert.Nil(t, parsed)
+
+	var none *gits.Provenance
+	body, err = none.AddToBody("unchanged")
+	require.NoError(t, err)
+	assert.Equal(t, "unchanged", body)
+}
+
+func TestProvenanceTrailers(t *testing.T) {
+	t.Parallel()
+
+	provenance := &gits.Provenance{
+		Kind:             "upgrade",
+		ToolVersion:      "2.1.0",
+		VersionStreamURL: "https://github.com/jenkins-x/jenkins-x-versions.git",
+		VersionStreamRef: "v1.0.100",
+	}
+	message := provenance.AddTrailers("feat: upgrade version stream\n")
+	assert.Equal(t, `feat: upgrade version stream
+
+Jx-Kind: upgrade
+Jx-Version: 2.1.0
+Jx-Version-Stream: https://github.com/jenkins-x/jenkins-x-versions.git
+Jx-Version-Stream-Ref: v1.0.100`, message)
+
+	assert.Equal(t, provenance, gits.ParseProvenanceTrailers(message))
+	assert.Nil(t, gits.ParseProvenanceTrailers("fix: a commit someone else made"))
+}
@@ -39,6 +39,8 @@ type GitRepository struct {
 	HasWiki          bool
 	HasProjects      bool
 	Archived         bool
+	// DefaultBranch the default branch of the repository if the git provider reports it
+	DefaultBranch string
 }
 
 type GitPullRequest struct {
@@ -67,6 +69,24 @@ type GitPullRequest struct {
 	HeadOwner          *string // HeadOwner is the string the PR is created from
 }
 
+// GitPullRequestReview represents a review of a pull request
+type GitPullRequestReview struct {
+	Author      *GitUser
+	State       string
+	Body        string
+	URL         string
+	SubmittedAt *time.Time
+}
+
+// GitPullRequestComment represents a comment on the conversation of a pull request
+type GitPullRequestComment struct {
+	ID        int64
+	Author    *GitUser
+	Body      string
+	URL       string
+	CreatedAt *time.Time
+}
+
 // Label represents a label on an Issue
 type Label struct {
 	ID          *int64
@@ -174,6 +194,8 @@ type GitPullRequestArguments struct {
 	Base          string
 	GitRepository *GitRepository
 	Labels        []string
+	// Draft creates the pull request as a draft, ignored by git providers which do not support drafts
+	Draft bool
 }
 
 func (a *GitPullRequestArguments) String() string {
@@ -265,6 +287,9 @@ func CreateProvider(server *auth.AuthServer, user *auth.UserAuth, git Gitter) (G
 	if server.Kind == "" {
 		server.Kind = SaasGitKind(server.URL)
 	}
+	if factory := registeredProviderFactory(server.Kind); factory != nil {
+		return factory(server, user, git)
+	}
 	if server.Kind == KindBitBucketCloud {
 		return NewBitbucketCloudProvider(server, user, git)
 	} else if server.Kind == KindBitBucketServer {
@@ -273,6 +298,8 @@ func CreateProvider(server *auth.AuthServer, user *auth.UserAuth, git Gitter) (G
 		return NewGiteaProvider(server, user, git)
 	} else if server.Kind == KindGitlab {
 		return NewGitlabProvider(server, user, git)
+	} else if server.Kind == KindAzureDevOps {
+		return NewAzureDevOpsProvider(server, user, git)
 	} else if server.Kind == KindGitFake {
 		return NewFakeProvider(), nil
 	} else {
@@ -307,6 +334,8 @@ func ProviderAccessTokenURL(kind string, url string, username string) string {
 		return GiteaAccessTokenURL(url)
 	case KindGitlab:
 		return GitlabAccessTokenURL(url)
+	case KindAzureDevOps:
+		return AzureDevOpsAccessTokenURL(url)
 	default:
 		return GitHubAccessTokenURL(url)
 	}
@@ -449,6 +478,9 @@ func (i *GitRepository) PickOrCreateProvider(authConfigSvc auth.ConfigService, m
 			}
 		}
 	}
+	if userAuth == nil {
+		userAuth = server.FindUserAuthForRepository(i.Organisation, i.Name)
+	}
 	if userAuth == nil {
 		userAuth, err = config.PickServerUserAuth(server, message, batchMode, i.Organisation, handles)
 		if err != nil {
@@ -462,7 +494,7 @@ func (i *GitRepository) PickOrCreateProvider(authConfigSvc auth.ConfigService, m
 }
 
 func (i *GitRepository) CreateProviderForUser(server *auth.AuthServer, user *auth.UserAuth, gitKind string, git Gitter) (GitProvider, error) {
-	if i.Host == GitHubHost {
+	if i.Host == GitHubHost && registeredProviderFactory(KindGitHub) == nil {
 		return NewGitHubProvider(server, user, git)
 	}
 	if gitKind != "" && server.Kind != gitKind {
@@ -473,6 +505,12 @@ func (i *GitRepository) CreateProviderForUser(server *auth.AuthServer, user *aut
 
 func (i *GitRepository) CreateProvider(inCluster bool, authConfigSvc auth.ConfigService, gitKind string, ghOwner string, git Gitter, batchMode bool, handles util.IOFileHandles) (GitProvider, error) {
 	hostUrl := i.HostURLWithoutUser()
+	if ghOwner == "" {
+		server := authConfigSvc.Config().GetServer(hostUrl)
+		if user := server.FindUserAuthForRepository(i.Organisation, i.Name); user != nil && !user.IsInvalid() {
+			return i.CreateProviderForUser(server, user, gitKind, git)
+		}
+	}
 	return CreateProviderForURL(inCluster, authConfigSvc, gitKind, hostUrl, ghOwner, git, batchMode, handles)
 }
 
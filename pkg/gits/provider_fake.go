@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"github.com/google/go-github/github"
+	v1 "github.com/jenkins-x/jx/v2/pkg/apis/jenkins.io/v1"
 	"github.com/jenkins-x/jx/v2/pkg/auth"
 	"github.com/jenkins-x/jx/v2/pkg/log"
 	"github.com/jenkins-x/jx/v2/pkg/util"
@@ -74,6 +75,8 @@ type FakeRepository struct {
 	CloneDir           string
 	Projects           []GitProject
 	WikiEnabled        bool
+	Settings           *v1.RepositorySettings
+	ProtectedBranch    string
 }
 
 type FakeProvider struct {
@@ -833,6 +836,18 @@ func (f *FakeProvider) ConfigureFeatures(owner string, repo string, issues *bool
 	return nil, errors.Errorf("unable to find %s/%s", owner, repo)
 }
 
+// ConfigureRepositorySettings records the settings and protected branch of the repository
+func (f *FakeProvider) ConfigureRepositorySettings(owner string, repo string, branch string, settings *v1.RepositorySettings) error {
+	for _, r := range f.Repositories[owner] {
+		if r.Name() == repo {
+			r.Settings = settings.DeepCopy()
+			r.ProtectedBranch = branch
+			return nil
+		}
+	}
+	return errors.Errorf("unable to find %s/%s", owner, repo)
+}
+
 // IsWikiEnabled returns true if a wiki is enabled for owner/repo
 func (f *FakeProvider) IsWikiEnabled(owner string, repo string) (bool, error) {
 	if repos, ok := f.Repositories[owner]; ok {
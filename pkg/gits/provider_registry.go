@@ -0,0 +1,76 @@
+package gits
+
+import (
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/jenkins-x/jx/v2/pkg/auth"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+)
+
+// ProviderFactory creates a git provider for the given server and user
+type ProviderFactory func(server *auth.AuthServer, user *auth.UserAuth, git Gitter) (GitProvider, error)
+
+var (
+	providerRegistryLock sync.RWMutex
+	providerFactories    = map[string]ProviderFactory{}
+	providerServerURLs   = map[string]string{}
+)
+
+// RegisterProvider registers a factory for the given git kind so that git providers can be added without modifying
+// jx. The kind is returned by GitKinds so that it can be chosen when picking the kind of a git server.
+//
+// A registered factory is used in preference to the built in provider of the same kind.
+func RegisterProvider(kind string, factory ProviderFactory) {
+	providerRegistryLock.Lock()
+	defer providerRegistryLock.Unlock()
+
+	providerFactories[kind] = factory
+}
+
+// GitKinds returns the sorted kinds of the built in git providers and of the registered ones
+func GitKinds() []string {
+	providerRegistryLock.RLock()
+	defer providerRegistryLock.RUnlock()
+
+	answer := append([]string{}, KindGits...)
+	for kind := range providerFactories {
+		if util.StringArrayIndex(answer, kind) < 0 {
+			answer = append(answer, kind)
+		}
+	}
+	sort.Strings(answer)
+	return answer
+}
+
+// RegisterProviderServerURL registers the server URL of a git provider so that the kind of git repositories hosted
+// on the server can be detected from their URL by SaasGitKind
+func RegisterProviderServerURL(kind string, serverURL string) {
+	providerRegistryLock.Lock()
+	defer providerRegistryLock.Unlock()
+
+	providerServerURLs[strings.TrimSuffix(serverURL, "/")] = kind
+}
+
+// registeredProviderFactory returns the registered factory for the kind or nil if there is none
+func registeredProviderFactory(kind string) ProviderFactory {
+	providerRegistryLock.RLock()
+	defer providerRegistryLock.RUnlock()
+
+	return providerFactories[kind]
+}
+
+// registeredProviderKind returns the kind registered for the server URL or "" if there is none
+func registeredProviderKind(serverURL string) string {
+	providerRegistryLock.RLock()
+	defer providerRegistryLock.RUnlock()
+
+	serverURL = strings.TrimSuffix(serverURL, "/")
+	for u, kind := range providerServerURLs {
+		if serverURL == u || strings.HasPrefix(serverURL, u+"/") {
+			return kind
+		}
+	}
+	return ""
+}
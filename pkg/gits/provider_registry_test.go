@@ -0,0 +1,77 @@
+// +build unit
+
+package gits
+
+import (
+	"testing"
+
+	"github.com/jenkins-x/jx/v2/pkg/auth"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// restoreProviderRegistry returns a function restoring the registered providers to their current state
+func restoreProviderRegistry() func() {
+	providerRegistryLock.Lock()
+	defer providerRegistryLock.Unlock()
+
+	factories := map[string]ProviderFactory{}
+	for k, v := range providerFactories {
+		factories[k] = v
+	}
+	serverURLs := map[string]string{}
+	for k, v := range providerServerURLs {
+		serverURLs[k] = v
+	}
+	return func() {
+		providerRegistryLock.Lock()
+		defer providerRegistryLock.Unlock()
+
+		providerFactories = factories
+		providerServerURLs = serverURLs
+	}
+}
+
+func TestRegisterProvider(t *testing.T) {
+	defer restoreProviderRegistry()()
+
+	kind := "registrytest"
+	fakeProvider := NewFakeProvider()
+	RegisterProvider(kind, func(server *auth.AuthServer, user *auth.UserAuth, git Gitter) (GitProvider, error) {
+		return fakeProvider, nil
+	})
+	RegisterProviderServerURL(kind, "https://registrytest.example.com/")
+
+	assert.True(t, util.StringArrayIndex(GitKinds(), kind) >= 0, "the kind should be returned by GitKinds")
+	assert.True(t, util.StringArrayIndex(KindGits, kind) < 0, "KindGits should not be modified")
+	assert.Equal(t, kind, SaasGitKind("https://registrytest.example.com"))
+	assert.Equal(t, kind, SaasGitKind("https://registrytest.example.com/myorg"))
+	assert.Equal(t, "", SaasGitKind("https://registrytest.example.company.com"))
+
+	provider, err := CreateProvider(&auth.AuthServer{URL: "https://registrytest.example.com"}, &auth.UserAuth{Username: "test"}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, fakeProvider, provider)
+
+	gitInfo, err := ParseGitURL("https://registrytest.example.com/myorg/myrepo.git")
+	require.NoError(t, err)
+	provider, err = gitInfo.CreateProviderForUser(&auth.AuthServer{URL: "https://registrytest.example.com"}, &auth.UserAuth{Username: "test"}, kind, nil)
+	require.NoError(t, err)
+	assert.Equal(t, fakeProvider, provider)
+}
+
+func TestGitKinds(t *testing.T) {
+	defer restoreProviderRegistry()()
+
+	assert.Equal(t, KindGits, GitKinds())
+
+	RegisterProvider("aaa-registrytest", func(server *auth.AuthServer, user *auth.UserAuth, git Gitter) (GitProvider, error) {
+		return NewFakeProvider(), nil
+	})
+	RegisterProvider(KindGitHub, func(server *auth.AuthServer, user *auth.UserAuth, git Gitter) (GitProvider, error) {
+		return NewFakeProvider(), nil
+	})
+	kinds := GitKinds()
+	assert.Equal(t, "aaa-registrytest", kinds[0], "the kinds should be sorted")
+	assert.Len(t, kinds, len(KindGits)+1, "overriding a built in kind should not duplicate it")
+}
@@ -0,0 +1,93 @@
+package gits
+
+import (
+	"strings"
+
+	"github.com/google/go-github/github"
+	"github.com/pkg/errors"
+)
+
+// PullRequestCommentLister is implemented by git providers which can list the comments on the conversation of a
+// pull request, such as the ChatOps commands people comment
+type PullRequestCommentLister interface {
+	// ListPullRequestComments lists the comments of the given pull request in the order they were created
+	ListPullRequestComments(owner string, repo string, number int) ([]*GitPullRequestComment, error)
+}
+
+// PullRequestCommentEditor is implemented by git providers which can edit the comments on the conversation of a pull
+// request so that a comment can be kept up to date rather than adding a new comment each time
+type PullRequestCommentEditor interface {
+	// EditPullRequestComment replaces the body of the given comment of the pull request
+	EditPullRequestComment(owner string, repo string, comment *GitPullRequestComment, body string) error
+}
+
+// FindMarkedComment returns the last of the comments which contains the marker or nil if there is none
+func FindMarkedComment(comments []*GitPullRequestComment, marker string) *GitPullRequestComment {
+	for i := len(comments) - 1; i >= 0; i-- {
+		if comments[i] != nil && strings.Contains(comments[i].Body, marker) {
+			return comments[i]
+		}
+	}
+	return nil
+}
+
+// UpsertPullRequestComment updates the comment of the pull request which contains the marker with the body, which
+// should contain the marker, or adds the comment if there is none or the git provider cannot edit comments
+func UpsertPullRequestComment(provider GitProvider, pr *GitPullRequest, marker string, body string) error {
+	lister, canList := provider.(PullRequestCommentLister)
+	editor, canEdit := provider.(PullRequestCommentEditor)
+	if canList && canEdit && pr.Number != nil {
+		comments, err := lister.ListPullRequestComments(pr.Owner, pr.Repo, *pr.Number)
+		if err != nil {
+			return err
+		}
+		comment := FindMarkedComment(comments, marker)
+		if comment != nil {
+			if comment.Body == body {
+				return nil
+			}
+			return editor.EditPullRequestComment(pr.Owner, pr.Repo, comment, body)
+		}
+	}
+	return provider.AddPRComment(pr, body)
+}
+
+// ListPullRequestComments lists the comments of the given pull request in the order they were created
+func (p *GitHubProvider) ListPullRequestComments(owner string, repo string, number int) ([]*GitPullRequestComment, error) {
+	opt := &github.IssueListCommentsOptions{
+		ListOptions: github.ListOptions{
+			Page:    0,
+			PerPage: pageSize,
+		},
+	}
+	answer := []*GitPullRequestComment{}
+	for {
+		comments, _, err := p.Client.Issues.ListComments(p.Context, owner, repo, number, opt)
+		if err != nil {
+			return answer, errors.Wrapf(err, "listing the comments of pull request %s/%s#%d", owner, repo, number)
+		}
+		for _, comment := range comments {
+			answer = append(answer, &GitPullRequestComment{
+				ID:        comment.GetID(),
+				Author:    toGitHubUser(comment.User),
+				Body:      asText(comment.Body),
+				URL:       asText(comment.HTMLURL),
+				CreatedAt: comment.CreatedAt,
+			})
+		}
+		if len(comments) < pageSize || len(comments) == 0 {
+			break
+		}
+		opt.Page++
+	}
+	return answer, nil
+}
+
+// EditPullRequestComment replaces the body of the given comment of the pull request
+func (p *GitHubProvider) EditPullRequestComment(owner string, repo string, comment *GitPullRequestComment, body string) error {
+	_, _, err := p.Client.Issues.EditComment(p.Context, owner, repo, comment.ID, &github.IssueComment{Body: &body})
+	if err != nil {
+		return errors.Wrapf(err, "editing comment %d of %s/%s", comment.ID, owner, repo)
+	}
+	return nil
+}
@@ -0,0 +1,27 @@
+// +build unit
+
+package gits_test
+
+import (
+	"testing"
+
+	"github.com/jenkins-x/jx/v2/pkg/gits"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindMarkedComment(t *testing.T) {
+	t.Parallel()
+
+	comments := []*gits.GitPullRequestComment{
+		{ID: 1, Body: "<!-- jx-preview -->\nbuild 1"},
+		{ID: 2, Body: "/lgtm"},
+		{ID: 3, Body: "<!-- jx-preview -->\nbuild 2"},
+		{ID: 4, Body: "/approve"},
+	}
+	comment := gits.FindMarkedComment(comments, "<!-- jx-preview -->")
+	if assert.NotNil(t, comment) {
+		assert.Equal(t, int64(3), comment.ID)
+	}
+	assert.Nil(t, gits.FindMarkedComment(comments, "<!-- jx-other -->"))
+	assert.Nil(t, gits.FindMarkedComment(nil, "<!-- jx-preview -->"))
+}
@@ -0,0 +1,468 @@
+package gits
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/jenkins-x/jx/v2/pkg/config"
+	"github.com/jenkins-x/jx/v2/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+)
+
+// AutoMergeLabels the labels which make the merge bot of the pipeline merge a pull request once its checks pass
+var AutoMergeLabels = []string{LabelUpdatebot}
+
+// PullRequestAutoMerger is implemented by git providers which can merge a pull request themselves once its checks pass
+type PullRequestAutoMerger interface {
+	// EnableAutoMerge makes the git provider merge the pull request once its checks pass
+	EnableAutoMerge(pr *GitPullRequest) error
+}
+
+// PullRequestReviewerRequester is implemented by git providers which can request reviews of a pull request
+type PullRequestReviewerRequester interface {
+	// RequestReviewers requests reviews of the pull request from the given users
+	RequestReviewers(pr *GitPullRequest, reviewers []string) error
+}
+
+// PullRequestTeamReviewerRequester is implemented by git providers which can request reviews of a pull request from
+// teams. Bitbucket has no teams of reviewers so its providers do not implement it
+type PullRequestTeamReviewerRequester interface {
+	// RequestTeamReviewers requests reviews of the pull request from the given teams
+	RequestTeamReviewers(pr *GitPullRequest, teams []string) error
+}
+
+// PullRequestAssigner is implemented by git providers which can assign pull requests to users. Bitbucket and Azure
+// DevOps pull requests have no assignees, only reviewers, so their providers do not implement it
+type PullRequestAssigner interface {
+	// AssignPullRequest assigns the pull request to the given users
+	AssignPullRequest(pr *GitPullRequest, assignees []string) error
+}
+
+// AddDefaultReviewers adds the default reviewers and assignees of the pull requests jx generates to the details
+func (p *PullRequestDetails) AddDefaultReviewers(defaults *config.PullRequestsConfig) {
+	if defaults == nil {
+		return
+	}
+	p.Reviewers = mergeUnique(p.Reviewers, defaults.Reviewers)
+	p.TeamReviewers = mergeUnique(p.TeamReviewers, defaults.TeamReviewers)
+	p.Assignees = mergeUnique(p.Assignees, defaults.Assignees)
+}
+
+// PullRequestCreator commits and pushes the changes in a local clone and creates a pull request for them, or
+// updates an existing pull request if one matches
+type PullRequestCreator struct {
+	// Dir the directory of the local clone
+	Dir string
+	// UpstreamRepo the repository the pull request is created on
+	UpstreamRepo *GitRepository
+	// ForkRepo the fork the changes are pushed to, if nil they are pushed to the upstream repository
+	ForkRepo *GitRepository
+	// Base the branch the pull request is merged into
+	Base string
+	// Details the branch, title, message and labels of the pull request
+	Details *PullRequestDetails
+	// Filter finds an existing pull request to update instead of creating a new one
+	Filter *PullRequestFilter
+	// UpdateIfExists updates an open pull request from the same branch instead of creating a new one if no Filter
+	// is specified
+	UpdateIfExists bool
+	// Commit commits all the changes in the directory first
+	Commit bool
+	// CommitMessage the commit message, defaults to the pull request message
+	CommitMessage string
+	// Push pushes the branch before creating the pull request
+	Push bool
+	// DryRun commits the changes without pushing them or creating a pull request
+	DryRun bool
+	// Draft creates the pull request as a draft, if supported by the git provider. A draft is also created if
+	// Details.Draft is set
+	Draft bool
+	// Reviewers the users asked to review the pull request in addition to Details.Reviewers
+	Reviewers []string
+	// TeamReviewers the teams asked to review the pull request in addition to Details.TeamReviewers
+	TeamReviewers []string
+	// Assignees the users the pull request is assigned to in addition to Details.Assignees
+	Assignees []string
+	// AutoMerge makes the git provider merge the pull request once its checks pass, or labels the pull request so
+	// that the merge bot of the pipeline does if the git provider cannot. This is also done if Details.AutoMerge is set
+	AutoMerge bool
+	// MergeQueue adds the pull request to the merge queue of the git provider, falling back to AutoMerge if the git
+	// provider has no merge queue. This is also done if Details.MergeQueue is set
+	MergeQueue bool
+	// Provenance describes how the change was generated. It is embedded in the body of the pull request and added to
+	// the commit message as trailers
+	Provenance *Provenance
+
+	Gitter   Gitter
+	Provider GitProvider
+}
+
+// Create commits and pushes the changes and creates or updates the pull request. A nil result is returned if there
+// are no changes to create a pull request for or if this is a dry run
+func (c *PullRequestCreator) Create() (*PullRequestInfo, error) {
+	if c.Details == nil {
+		return nil, errors.New("no pull request details specified")
+	}
+	if c.UpstreamRepo == nil {
+		return nil, errors.New("no upstream repository specified")
+	}
+	dir := c.Dir
+	prDetails := c.Details
+	upstreamRepo := c.UpstreamRepo
+	forkRepo := c.ForkRepo
+	gitter := c.Gitter
+	provider := c.Provider
+
+	draft := c.Draft || prDetails.Draft
+	autoMerge := c.AutoMerge || prDetails.AutoMerge
+	mergeQueue := c.MergeQueue || prDetails.MergeQueue
+
+	userAuth := provider.UserAuth()
+	commitMessage := c.CommitMessage
+	if c.Commit {
+		err := gitter.Add(dir, "-A")
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		changed, err := gitter.HasChanges(dir)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		if !changed {
+			log.Logger().Warnf("No changes made to the source code in %s. Code must be up to date!", dir)
+			return nil, nil
+		}
+		if commitMessage == "" {
+			commitMessage = prDetails.CommitMessage
+		}
+		if commitMessage == "" {
+			commitMessage = prDetails.Message
+		}
+		commitMessage = c.Provenance.AddTrailers(commitMessage)
+		err = gitter.CommitDir(dir, commitMessage)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+	}
+
+	headPrefix := ""
+
+	username := upstreamRepo.Organisation
+	cloneURL := upstreamRepo.CloneURL
+	if forkRepo != nil {
+		username = forkRepo.Organisation
+		cloneURL = forkRepo.CloneURL
+	}
+
+	if upstreamRepo.Organisation != username {
+		headPrefix = username + ":"
+	}
+
+	body, err := c.Provenance.AddToBody(prDetails.Message)
+	if err != nil {
+		return nil, err
+	}
+	gha := &GitPullRequestArguments{
+		GitRepository: upstreamRepo,
+		Title:         prDetails.Title,
+		Body:          body,
+		Base:          c.Base,
+		Labels:        prDetails.Labels,
+		Draft:         draft,
+	}
+
+	forkPushURL, err := gitter.CreateAuthenticatedURL(cloneURL, &userAuth)
+	if err != nil {
+		return nil, errors.Wrapf(err, "creating push URL for %s", cloneURL)
+	}
+
+	var existingPr *GitPullRequest
+	if c.Push {
+		existingPr, err = c.findExistingPullRequest(username)
+		if err != nil {
+			return nil, err
+		}
+	}
+	remoteBranch := prDetails.BranchName
+	if existingPr != nil {
+		if util.DereferenceString(existingPr.HeadOwner) == username && existingPr.HeadRef != nil && existingPr.Number != nil {
+			remote := "origin"
+			if forkRepo != nil && forkRepo.Fork {
+				remote = "upstream"
+			}
+			changeBranch, err := gitter.Branch(dir)
+			if err != nil {
+				return nil, errors.WithStack(err)
+			}
+			localBranchUUID, err := uuid.NewV4()
+			if err != nil {
+				return nil, errors.Wrapf(err, "creating UUID for local branch")
+			}
+			// We use this "dummy" local branch to pull into to avoid having to work with FETCH_HEAD as our local
+			// representation of the remote branch. This is an oddity of the pull/%d/head remote.
+			localBranch := localBranchUUID.String()
+			remoteBranch = *existingPr.HeadRef
+			fetchRefSpec := pullRequestFetchRefSpec(provider, existingPr, localBranch)
+			err = gitter.FetchBranch(dir, remote, fetchRefSpec)
+			if err != nil {
+				return nil, errors.Wrapf(err, "fetching %s for merge", fetchRefSpec)
+			}
+
+			err = gitter.CreateBranchFrom(dir, prDetails.BranchName, localBranch)
+			if err != nil {
+				return nil, errors.Wrapf(err, "creating branch %s from %s", prDetails.BranchName, fetchRefSpec)
+			}
+			err = gitter.Checkout(dir, prDetails.BranchName)
+			if err != nil {
+				return nil, errors.Wrapf(err, "checking out branch %s", prDetails.BranchName)
+			}
+			err = gitter.MergeTheirs(dir, changeBranch)
+			if err != nil {
+				return nil, errors.Wrapf(err, "merging %s into %s", changeBranch, fetchRefSpec)
+			}
+			err = gitter.RebaseTheirs(dir, fmt.Sprintf(localBranch), "", true)
+			if err != nil {
+				return nil, errors.WithStack(err)
+			}
+			changedFiles, err := gitter.ListChangedFilesFromBranch(dir, localBranch)
+			if err != nil {
+				return nil, errors.Wrap(err, "failed to list changed files")
+			}
+			if changedFiles == "" {
+				log.Logger().Info("No file changes since the existing PR. Nothing to push.")
+				return nil, nil
+			}
+		} else {
+			// We can only update an existing PR if the owner of that PR is this user, so we clear the existingPr
+			existingPr = nil
+		}
+	}
+	var pr *GitPullRequest
+	if !c.DryRun && existingPr != nil {
+		gha.Head = headPrefix + remoteBranch
+		gha.Title = mergedPullRequestTitle(existingPr.Title, prDetails.Title)
+		existingBody := existingPr.Body
+		if c.Provenance != nil {
+			existingBody = RemoveProvenance(existingBody)
+		}
+		gha.Body, err = c.Provenance.AddToBody(fmt.Sprintf("%s\n<hr />\n\n%s", prDetails.Message, existingBody))
+		if err != nil {
+			return nil, err
+		}
+		pr, err = provider.UpdatePullRequest(gha, *existingPr.Number)
+		if err != nil {
+			return nil, errors.Wrapf(err, "updating pull request %s", existingPr.URL)
+		}
+		log.Logger().Infof("Updated Pull Request: %s", util.ColorInfo(pr.URL))
+	}
+	if c.DryRun {
+		if existingPr != nil {
+			log.Logger().Infof("Commit created but not pushed; would have updated pull request %s with %s and used commit message %s. Please manually delete %s when you are done", util.ColorInfo(existingPr.URL), prDetails.String(), commitMessage, util.ColorInfo(dir))
+		} else {
+			log.Logger().Infof("Commit created but not pushed; would have created a pull request with %s and used commit message %s. Please manually delete %s when you are done", prDetails.String(), commitMessage, util.ColorInfo(dir))
+		}
+		return nil, nil
+	} else if c.Push {
+		err := gitter.Push(dir, forkPushURL, true, fmt.Sprintf("%s:%s", "HEAD", remoteBranch))
+		if err != nil {
+			return nil, errors.Wrapf(err, "pushing merged branch %s", remoteBranch)
+		}
+	}
+	if existingPr == nil {
+		gha.Head = headPrefix + prDetails.BranchName
+		if draft && !SupportsDraftPullRequests(provider) {
+			log.Logger().Warnf("%s does not support draft pull requests so creating a regular pull request", provider.Kind())
+		}
+
+		pr, err = provider.CreatePullRequest(gha)
+		if err != nil {
+			return nil, errors.Wrapf(err, "creating pull request with arguments %v", gha.String())
+		}
+		log.Logger().Infof("Created Pull Request: %s", util.ColorInfo(pr.URL))
+	}
+
+	prInfo := &PullRequestInfo{
+		GitProvider:          provider,
+		PullRequest:          pr,
+		PullRequestArguments: gha,
+	}
+
+	labels := prDetails.Labels
+	queued := mergeQueue && c.enqueue(pr)
+	if (autoMerge || mergeQueue) && !queued && !c.enableAutoMerge(pr) {
+		labels = append([]string{}, labels...)
+		for _, label := range AutoMergeLabels {
+			if util.StringArrayIndex(labels, label) < 0 {
+				labels = append(labels, label)
+			}
+		}
+	}
+	err = addLabelsToPullRequest(prInfo, labels)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to add labels %+v to PR %s", labels, pr.URL)
+	}
+	err = c.assignPullRequest(pr)
+	if err != nil {
+		return nil, err
+	}
+	err = c.requestReviewers(pr)
+	if err != nil {
+		return nil, err
+	}
+	return prInfo, nil
+}
+
+// findExistingPullRequest finds the latest open pull request matching the filter, or from the same branch if
+// UpdateIfExists is enabled
+func (c *PullRequestCreator) findExistingPullRequest(username string) (*GitPullRequest, error) {
+	upstreamRepo := c.UpstreamRepo
+	var existingPrs []*GitPullRequest
+	var err error
+	description := ""
+	if c.Filter != nil {
+		description = fmt.Sprintf("filter %s", c.Filter.String())
+		existingPrs, err = FilterOpenPullRequests(c.Provider, upstreamRepo.Organisation, upstreamRepo.Name, *c.Filter)
+	} else if c.UpdateIfExists {
+		description = fmt.Sprintf("branch %s", c.Details.BranchName)
+		var openPrs []*GitPullRequest
+		openPrs, err = c.Provider.ListOpenPullRequests(upstreamRepo.Organisation, upstreamRepo.Name)
+		for _, pr := range openPrs {
+			if util.DereferenceString(pr.HeadRef) == c.Details.BranchName && util.DereferenceString(pr.HeadOwner) == username {
+				existingPrs = append(existingPrs, pr)
+			}
+		}
+	} else {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "finding existing PRs using %s on repo %s/%s", description, upstreamRepo.Organisation, upstreamRepo.Name)
+	}
+	if len(existingPrs) == 0 {
+		return nil, nil
+	}
+	if len(existingPrs) > 1 {
+		sort.SliceStable(existingPrs, func(i, j int) bool {
+			// sort in descending order of PR numbers (assumes PRs numbers increment!)
+			return util.DereferenceInt(existingPrs[j].Number) < util.DereferenceInt(existingPrs[i].Number)
+		})
+		prs := make([]string, 0)
+		for _, pr := range existingPrs {
+			prs = append(prs, pr.URL)
+		}
+		log.Logger().Debugf("Found more than one PR %s using %s on repo %s/%s so rebasing latest PR %s", strings.Join(prs, ", "), description, upstreamRepo.Organisation, upstreamRepo.Name, existingPrs[0].URL)
+	}
+	return existingPrs[0], nil
+}
+
+// enableAutoMerge makes the git provider merge the pull request once its checks pass, returning false if it cannot
+// so that the pull request is labelled for the merge bot instead
+func (c *PullRequestCreator) enableAutoMerge(pr *GitPullRequest) bool {
+	merger, ok := c.Provider.(PullRequestAutoMerger)
+	if !ok {
+		log.Logger().Debugf("%s does not support auto merging pull requests so labelling %s with %s", c.Provider.Kind(), pr.URL, strings.Join(AutoMergeLabels, ", "))
+		return false
+	}
+	err := merger.EnableAutoMerge(pr)
+	if err != nil {
+		log.Logger().Warnf("failed to enable auto merge of PR %s so labelling it with %s instead: %s", pr.URL, strings.Join(AutoMergeLabels, ", "), err)
+		return false
+	}
+	log.Logger().Infof("Pull Request %s will be merged once its checks pass", util.ColorInfo(pr.URL))
+	return true
+}
+
+func (c *PullRequestCreator) assignPullRequest(pr *GitPullRequest) error {
+	assignees := mergeUnique(c.Assignees, c.Details.Assignees)
+	if len(assignees) == 0 {
+		return nil
+	}
+	assigner, ok := c.Provider.(PullRequestAssigner)
+	if !ok {
+		log.Logger().Warnf("%s does not support assigning pull requests so %s is not assigned to %s", c.Provider.Kind(), pr.URL, strings.Join(assignees, ", "))
+		return nil
+	}
+	err := assigner.AssignPullRequest(pr, assignees)
+	if err != nil {
+		return errors.Wrapf(err, "assigning PR %s to %s", pr.URL, strings.Join(assignees, ", "))
+	}
+	return nil
+}
+
+func (c *PullRequestCreator) requestReviewers(pr *GitPullRequest) error {
+	reviewers := mergeUnique(c.Reviewers, c.Details.Reviewers)
+	if len(reviewers) > 0 {
+		requester, ok := c.Provider.(PullRequestReviewerRequester)
+		if !ok {
+			log.Logger().Warnf("%s does not support requesting reviews so no review of %s is requested from %s", c.Provider.Kind(), pr.URL, strings.Join(reviewers, ", "))
+		} else {
+			err := requester.RequestReviewers(pr, reviewers)
+			if err != nil {
+				return errors.Wrapf(err, "requesting reviews of PR %s from %s", pr.URL, strings.Join(reviewers, ", "))
+			}
+			log.Logger().Infof("Requested reviews of Pull Request %s from %s", pr.URL, util.ColorInfo(strings.Join(reviewers, ", ")))
+		}
+	}
+
+	teams := mergeUnique(c.TeamReviewers, c.Details.TeamReviewers)
+	if len(teams) > 0 {
+		requester, ok := c.Provider.(PullRequestTeamReviewerRequester)
+		if !ok {
+			log.Logger().Warnf("%s does not support requesting reviews from teams so no review of %s is requested from %s", c.Provider.Kind(), pr.URL, strings.Join(teams, ", "))
+			return nil
+		}
+		err := requester.RequestTeamReviewers(pr, teams)
+		if err != nil {
+			return errors.Wrapf(err, "requesting reviews of PR %s from the teams %s", pr.URL, strings.Join(teams, ", "))
+		}
+		log.Logger().Infof("Requested reviews of Pull Request %s from the teams %s", pr.URL, util.ColorInfo(strings.Join(teams, ", ")))
+	}
+	return nil
+}
+
+// mergeUnique returns the values of both slices without duplicates
+func mergeUnique(values []string, more []string) []string {
+	answer := []string{}
+	for _, v := range append(append([]string{}, values...), more...) {
+		if v != "" && util.StringArrayIndex(answer, v) < 0 {
+			answer = append(answer, v)
+		}
+	}
+	return answer
+}
+
+// SupportsDraftPullRequests returns true if the git provider can create draft pull requests
+func SupportsDraftPullRequests(provider GitProvider) bool {
+	kind := provider.Kind()
+	return kind == KindGitHub || kind == KindGitlab
+}
+
+// mergedPullRequestTitle returns the title of an existing pull request updated with another change, using the words
+// the titles of dependency bumps have in common
+func mergedPullRequestTitle(existingTitle string, title string) string {
+	if !strings.HasPrefix(existingTitle, "chore(deps): bump ") {
+		return title
+	}
+	origWords := strings.Split(existingTitle, " ")
+	newWords := strings.Split(title, " ")
+	answer := make([]string, 0)
+	for i, w := range newWords {
+		if len(origWords) > i && origWords[i] == w {
+			answer = append(answer, w)
+		}
+	}
+	if len(answer) == 0 {
+		return title
+	}
+	if answer[len(answer)-1] == "bump" {
+		// if there are no similarities in the actual dependency, then add a generic form of words
+		answer = append(answer, "dependency", "versions")
+	}
+	if answer[len(answer)-1] == "to" || answer[len(answer)-1] == "from" {
+		// remove trailing prepositions
+		answer = answer[:len(answer)-1]
+	}
+	return strings.Join(answer, " ")
+}
@@ -0,0 +1,170 @@
+// +build unit
+
+package gits_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jenkins-x/jx/v2/pkg/config"
+	"github.com/jenkins-x/jx/v2/pkg/gits"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// autoMergingProvider a fake provider which can merge pull requests once their checks pass
+type autoMergingProvider struct {
+	*gits.FakeProvider
+	autoMerged []int
+}
+
+func (p *autoMergingProvider) EnableAutoMerge(pr *gits.GitPullRequest) error {
+	p.autoMerged = append(p.autoMerged, *pr.Number)
+	return nil
+}
+
+func TestPullRequestCreatorAutoMerge(t *testing.T) {
+	gitter := gits.NewGitCLI()
+	acmeRepo, dir := createPullRequestCreatorRepo(t, gitter)
+	defer os.RemoveAll(acmeRepo.BaseDir) //nolint:errcheck
+	defer os.RemoveAll(dir)              //nolint:errcheck
+	provider := gits.NewFakeProvider(acmeRepo)
+
+	creator := &gits.PullRequestCreator{
+		Dir:          dir,
+		UpstreamRepo: acmeRepo.GitRepo,
+		Base:         "master",
+		Details: &gits.PullRequestDetails{
+			BranchName: "contributing",
+			Title:      "docs: add contributing guide",
+			Message:    "docs: add contributing guide",
+			Labels:     []string{"docs", gits.LabelUpdatebot},
+		},
+		Commit:    true,
+		Push:      true,
+		AutoMerge: true,
+		Assignees: []string{"wile"},
+		Gitter:    gitter,
+		Provider:  provider,
+	}
+	info, err := creator.Create()
+	require.NoError(t, err)
+	require.NotNil(t, info)
+
+	prs, err := provider.ListOpenPullRequests("acme", "roadrunner")
+	require.NoError(t, err)
+	require.Len(t, prs, 1)
+	assert.Equal(t, []string{"docs", gits.LabelUpdatebot}, pullRequestLabels(prs[0]), "the auto merge label should only be added once")
+}
+
+func TestPullRequestCreatorNativeAutoMerge(t *testing.T) {
+	gitter := gits.NewGitCLI()
+	acmeRepo, dir := createPullRequestCreatorRepo(t, gitter)
+	defer os.RemoveAll(acmeRepo.BaseDir) //nolint:errcheck
+	defer os.RemoveAll(dir)              //nolint:errcheck
+	provider := &autoMergingProvider{FakeProvider: gits.NewFakeProvider(acmeRepo)}
+
+	creator := &gits.PullRequestCreator{
+		Dir:          dir,
+		UpstreamRepo: acmeRepo.GitRepo,
+		Base:         "master",
+		Details: &gits.PullRequestDetails{
+			BranchName: "contributing",
+			Title:      "docs: add contributing guide",
+			Message:    "docs: add contributing guide",
+			Labels:     []string{"docs"},
+			AutoMerge:  true,
+		},
+		Commit:   true,
+		Push:     true,
+		Gitter:   gitter,
+		Provider: provider,
+	}
+	info, err := creator.Create()
+	require.NoError(t, err)
+	require.NotNil(t, info)
+
+	prs, err := provider.ListOpenPullRequests("acme", "roadrunner")
+	require.NoError(t, err)
+	require.Len(t, prs, 1)
+	assert.Equal(t, []int{*prs[0].Number}, provider.autoMerged)
+	assert.Equal(t, []string{"docs"}, pullRequestLabels(prs[0]), "the auto merge label is not needed if the provider merges the PR")
+}
+
+func TestPullRequestCreatorMergeQueueFallsBackToAutoMerge(t *testing.T) {
+	gitter := gits.NewGitCLI()
+	acmeRepo, dir := createPullRequestCreatorRepo(t, gitter)
+	defer os.RemoveAll(acmeRepo.BaseDir) //nolint:errcheck
+	defer os.RemoveAll(dir)              //nolint:errcheck
+	provider := &autoMergingProvider{FakeProvider: gits.NewFakeProvider(acmeRepo)}
+
+	creator := &gits.PullRequestCreator{
+		Dir:          dir,
+		UpstreamRepo: acmeRepo.GitRepo,
+		Base:         "master",
+		Details: &gits.PullRequestDetails{
+			BranchName: "contributing",
+			Title:      "docs: add contributing guide",
+			Message:    "docs: add contributing guide",
+			Labels:     []string{"docs"},
+		},
+		Commit:     true,
+		Push:       true,
+		MergeQueue: true,
+		Gitter:     gitter,
+		Provider:   provider,
+	}
+	info, err := creator.Create()
+	require.NoError(t, err)
+	require.NotNil(t, info)
+
+	prs, err := provider.ListOpenPullRequests("acme", "roadrunner")
+	require.NoError(t, err)
+	require.Len(t, prs, 1)
+	assert.Equal(t, []int{*prs[0].Number}, provider.autoMerged, "the PR should be auto merged as the provider has no merge queue")
+}
+
+func createPullRequestCreatorRepo(t *testing.T, gitter gits.Gitter) (*gits.FakeRepository, string) {
+	acmeRepo, err := gits.NewFakeRepository("acme", "roadrunner", func(dir string) error {
+		return ioutil.WriteFile(filepath.Join(dir, "README"), []byte("Hello there!"), 0600)
+	}, gitter)
+	require.NoError(t, err)
+
+	dir, err := ioutil.TempDir("", "pr-creator")
+	require.NoError(t, err)
+	err = gitter.Clone(acmeRepo.GitRepo.CloneURL, dir)
+	require.NoError(t, err)
+	err = ioutil.WriteFile(filepath.Join(dir, "CONTRIBUTING"), []byte("Welcome!"), 0600)
+	require.NoError(t, err)
+	return acmeRepo, dir
+}
+
+func pullRequestLabels(pr *gits.GitPullRequest) []string {
+	labels := []string{}
+	for _, label := range pr.Labels {
+		labels = append(labels, util.DereferenceString(label.Name))
+	}
+	return labels
+}
+
+func TestPullRequestDetailsAddDefaultReviewers(t *testing.T) {
+	t.Parallel()
+
+	details := &gits.PullRequestDetails{
+		Reviewers: []string{"wile"},
+	}
+	details.AddDefaultReviewers(nil)
+	assert.Equal(t, []string{"wile"}, details.Reviewers)
+
+	details.AddDefaultReviewers(&config.PullRequestsConfig{
+		Reviewers:     []string{"roadrunner", "wile"},
+		TeamReviewers: []string{"acme/maintainers"},
+		Assignees:     []string{"wile"},
+	})
+	assert.Equal(t, []string{"wile", "roadrunner"}, details.Reviewers)
+	assert.Equal(t, []string{"acme/maintainers"}, details.TeamReviewers)
+	assert.Equal(t, []string{"wile"}, details.Assignees)
+}
@@ -0,0 +1,61 @@
+package gits
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/jenkins-x/jx/v2/pkg/util"
+)
+
+const (
+	labelsMarkerPrefix = "<!-- jx-labels: "
+	labelsMarkerSuffix = " -->"
+)
+
+// labelsMarkerRegex matches the hidden markers storing labels in pull request descriptions
+var labelsMarkerRegex = regexp.MustCompile(`\n*<!-- jx-labels: ([^\n]*?) -->\n*`)
+
+// PullRequestLabelsFromBody returns the labels stored in the description of a pull request on a git provider which
+// does not support labels, such as Bitbucket
+func PullRequestLabelsFromBody(body string) []string {
+	var answer []string
+	for _, match := range labelsMarkerRegex.FindAllStringSubmatch(body, -1) {
+		for _, label := range strings.Split(match[1], ",") {
+			label = strings.TrimSpace(label)
+			if label != "" && util.StringArrayIndex(answer, label) < 0 {
+				answer = append(answer, label)
+			}
+		}
+	}
+	return answer
+}
+
+// PullRequestBodyWithLabels returns the description of a pull request with the given labels added to the hidden
+// marker at the end of it, so that pull requests can be filtered by labels on git providers which do not support them
+func PullRequestBodyWithLabels(body string, labels []string) string {
+	all := PullRequestLabelsFromBody(body)
+	for _, label := range labels {
+		if label != "" && util.StringArrayIndex(all, label) < 0 {
+			all = append(all, label)
+		}
+	}
+	body = strings.TrimRight(labelsMarkerRegex.ReplaceAllString(body, "\n\n"), "\n")
+	if len(all) == 0 {
+		return body
+	}
+	marker := labelsMarkerPrefix + strings.Join(all, ", ") + labelsMarkerSuffix
+	if body == "" {
+		return marker
+	}
+	return body + "\n\n" + marker
+}
+
+// pullRequestLabels returns the labels stored in the description of a pull request
+func pullRequestLabels(body string) []*Label {
+	answer := []*Label{}
+	for _, name := range PullRequestLabelsFromBody(body) {
+		n := name
+		answer = append(answer, &Label{Name: &n})
+	}
+	return answer
+}
@@ -0,0 +1,26 @@
+// +build unit
+
+package gits_test
+
+import (
+	"testing"
+
+	"github.com/jenkins-x/jx/v2/pkg/gits"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPullRequestBodyWithLabels(t *testing.T) {
+	t.Parallel()
+
+	body := gits.PullRequestBodyWithLabels("Upgrade the boot config", []string{"jenkins-x", "boot-upgrade"})
+	assert.Equal(t, "Upgrade the boot config\n\n<!-- jx-labels: jenkins-x, boot-upgrade -->", body)
+	assert.Equal(t, []string{"jenkins-x", "boot-upgrade"}, gits.PullRequestLabelsFromBody(body))
+
+	// updating a pull request prepends the new message to the old body, keeping the labels in a single marker
+	updated := gits.PullRequestBodyWithLabels("Upgrade again\n<hr />\n\n"+body, []string{"updatebot", "jenkins-x"})
+	assert.Equal(t, "Upgrade again\n<hr />\n\nUpgrade the boot config\n\n<!-- jx-labels: jenkins-x, boot-upgrade, updatebot -->", updated)
+
+	assert.Equal(t, "no labels", gits.PullRequestBodyWithLabels("no labels", nil))
+	assert.Equal(t, "<!-- jx-labels: updatebot -->", gits.PullRequestBodyWithLabels("", []string{"updatebot"}))
+	assert.Empty(t, gits.PullRequestLabelsFromBody("no labels"))
+}
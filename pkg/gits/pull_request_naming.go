@@ -0,0 +1,98 @@
+package gits
+
+import (
+	"bytes"
+	"strings"
+	"text/template"
+
+	"github.com/jenkins-x/jx/v2/pkg/config"
+	"github.com/pkg/errors"
+)
+
+// PullRequestNamingValues the values available to the templates of the branch names, titles and commit messages of
+// the pull requests jx generates
+type PullRequestNamingValues struct {
+	// Kind what the pull request changes, such as promote, upgrade or the kind of dependency updated
+	Kind string
+	// Version the version the pull request changes to
+	Version string
+	// Component the application, chart or dependency the pull request changes
+	Component string
+	// Environment the environment the pull request promotes to, if any
+	Environment string
+	// BranchName the branch name jx would use without a template
+	BranchName string
+	// Title the title jx would use without a template
+	Title string
+	// Message the message jx would use without a template
+	Message string
+	// CommitMessage the commit message jx would use without a template
+	CommitMessage string
+}
+
+// ApplyNamingConventions renders the branch name, title and commit message templates configured for the pull
+// requests jx generates onto the details. Any name without a template is left as it is. Branch names are converted
+// into valid git branch names using the gitter if one is specified
+func (p *PullRequestDetails) ApplyNamingConventions(conventions *config.PullRequestsConfig, values PullRequestNamingValues, gitter Gitter) error {
+	if conventions == nil {
+		return nil
+	}
+	if values.BranchName == "" {
+		values.BranchName = p.BranchName
+	}
+	if values.Title == "" {
+		values.Title = p.Title
+	}
+	if values.Message == "" {
+		values.Message = p.Message
+	}
+	if values.CommitMessage == "" {
+		values.CommitMessage = p.CommitMessage
+	}
+	if values.CommitMessage == "" {
+		values.CommitMessage = values.Message
+	}
+
+	branchName, err := renderNamingTemplate("branchName", conventions.BranchNameTemplate, values)
+	if err != nil {
+		return err
+	}
+	title, err := renderNamingTemplate("title", conventions.TitleTemplate, values)
+	if err != nil {
+		return err
+	}
+	commitMessage, err := renderNamingTemplate("commitMessage", conventions.CommitMessageTemplate, values)
+	if err != nil {
+		return err
+	}
+	if branchName != "" {
+		if gitter != nil {
+			branchName = gitter.ConvertToValidBranchName(branchName)
+		}
+		p.BranchName = branchName
+	}
+	if title != "" {
+		p.Title = title
+	}
+	if commitMessage != "" {
+		p.CommitMessage = commitMessage
+	}
+	return nil
+}
+
+// renderNamingTemplate renders the template, returning blank if there is no template
+func renderNamingTemplate(name string, text string, values PullRequestNamingValues) (string, error) {
+	if strings.TrimSpace(text) == "" {
+		return "", nil
+	}
+	tmpl, err := template.New(name).Parse(text)
+	if err != nil {
+		return "", errors.Wrapf(err, "parsing the pull request %s template %s", name, text)
+	}
+	var buf bytes.Buffer
+	err = tmpl.Execute(&buf, values)
+	if err != nil {
+		return "", errors.Wrapf(err, "rendering the pull request %s template %s", name, text)
+	}
+	return strings.TrimSpace(buf.String()), nil
+}
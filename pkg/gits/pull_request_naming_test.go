@@ -0,0 +1,58 @@
+// +build unit
+
+package gits_test
+
+import (
+	"testing"
+
+	"github.com/jenkins-x/jx/v2/pkg/config"
+	"github.com/jenkins-x/jx/v2/pkg/gits"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPullRequestDetailsApplyNamingConventions(t *testing.T) {
+	t.Parallel()
+
+	details := &gits.PullRequestDetails{
+		BranchName: "promote-myapp-1.2.3",
+		Title:      "chore: myapp to 1.2.3",
+		Message:    "chore: Promote myapp to version 1.2.3",
+	}
+	conventions := &config.PullRequestsConfig{
+		BranchNameTemplate:    "jx/{{ .Kind }}/{{ .Environment }}/{{ .Component }} {{ .Version }}",
+		TitleTemplate:         "chore({{ .Environment }}): promote {{ .Component }} to {{ .Version }}",
+		CommitMessageTemplate: "chore(deploy): {{ .CommitMessage }}\n\nRefs: JX-1",
+	}
+	err := details.ApplyNamingConventions(conventions, gits.PullRequestNamingValues{
+		Kind:        "promote",
+		Version:     "1.2.3",
+		Component:   "myapp",
+		Environment: "staging",
+	}, gits.NewGitCLI())
+	require.NoError(t, err)
+
+	assert.Equal(t, "jx/promote/staging/myapp_1.2.3", details.BranchName)
+	assert.Equal(t, "chore(staging): promote myapp to 1.2.3", details.Title)
+	assert.Equal(t, "chore(deploy): chore: Promote myapp to version 1.2.3\n\nRefs: JX-1", details.CommitMessage)
+	assert.Equal(t, "chore: Promote myapp to version 1.2.3", details.Message, "the message should not change")
+}
+
+func TestPullRequestDetailsApplyNamingConventionsWithoutTemplates(t *testing.T) {
+	t.Parallel()
+
+	details := &gits.PullRequestDetails{
+		BranchName: "jx_boot_upgrade",
+		Title:      "feat(config): upgrade configuration",
+	}
+	err := details.ApplyNamingConventions(&config.PullRequestsConfig{}, gits.PullRequestNamingValues{Kind: "upgrade"}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "jx_boot_upgrade", details.BranchName)
+	assert.Equal(t, "feat(config): upgrade configuration", details.Title)
+	assert.Empty(t, details.CommitMessage)
+
+	err = details.ApplyNamingConventions(&config.PullRequestsConfig{
+		TitleTemplate: "{{ .Unknown }}",
+	}, gits.PullRequestNamingValues{}, nil)
+	assert.Error(t, err, "unknown template values should fail")
+}
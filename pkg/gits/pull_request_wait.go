@@ -0,0 +1,124 @@
+package gits
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jenkins-x/jx/v2/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+)
+
+// DefaultPullRequestPollInterval how often a pull request is polled while waiting for it to merge
+const DefaultPullRequestPollInterval = 20 * time.Second
+
+// WaitForPullRequestToMerge polls the pull request until it is merged, logging the state of each of its checks and of
+// its merge queue entry as they change. An error is returned if the pull request is closed without being merged, if
+// any of the checks of its last commit fail, if the merge queue cannot merge it or if it is not merged within the
+// timeout
+func WaitForPullRequestToMerge(provider GitProvider, pr *GitPullRequest, timeout time.Duration, pollInterval time.Duration) error {
+	if pollInterval <= 0 {
+		pollInterval = DefaultPullRequestPollInterval
+	}
+	end := time.Now().Add(timeout)
+	log.Logger().Infof("Waiting for Pull Request %s to merge", util.ColorInfo(pr.URL))
+
+	sha := ""
+	states := map[string]string{}
+	queuer, _ := provider.(PullRequestMergeQueuer)
+	var queueEntry *MergeQueueEntry
+	for {
+		err := provider.UpdatePullRequestStatus(pr)
+		if err != nil {
+			return errors.Wrapf(err, "getting the status of pull request %s", pr.URL)
+		}
+		if pr.Merged != nil && *pr.Merged {
+			log.Logger().Infof("Pull Request %s is merged", util.ColorInfo(pr.URL))
+			return nil
+		}
+		if pr.IsClosed() {
+			return fmt.Errorf("pull request %s was closed without being merged", pr.URL)
+		}
+		if queuer != nil {
+			queueEntry, err = logMergeQueueChanges(queuer, pr, queueEntry)
+			if err != nil {
+				return err
+			}
+		}
+		if pr.LastCommitSha != "" {
+			if pr.LastCommitSha != sha {
+				// the checks of a new commit start again
+				sha = pr.LastCommitSha
+				states = map[string]string{}
+			}
+			statuses, err := provider.ListCommitStatus(pr.Owner, pr.Repo, sha)
+			if err != nil {
+				log.Logger().Warnf("failed to get the checks of pull request %s: %s", pr.URL, err)
+			} else {
+				failed := logCheckChanges(latestCommitStatuses(statuses), states)
+				if len(failed) > 0 {
+					return fmt.Errorf("the checks %s of pull request %s failed", strings.Join(failed, ", "), pr.URL)
+				}
+			}
+		}
+		if time.Now().After(end) {
+			return fmt.Errorf("timed out waiting for pull request %s to merge after %s", pr.URL, timeout.String())
+		}
+		time.Sleep(pollInterval)
+	}
+}
+
+// latestCommitStatuses returns the latest status of each check, assuming the statuses are listed newest first as git
+// providers do
+func latestCommitStatuses(statuses []*GitRepoStatus) []*GitRepoStatus {
+	answer := []*GitRepoStatus{}
+	found := map[string]bool{}
+	for _, status := range statuses {
+		if status == nil || found[status.Context] {
+			continue
+		}
+		found[status.Context] = true
+		answer = append(answer, status)
+	}
+	sort.SliceStable(answer, func(i, j int) bool {
+		return answer[i].Context < answer[j].Context
+	})
+	return answer
+}
+
+// logCheckChanges logs the checks whose state changed since they were last seen and returns the names of the checks
+// which failed
+func logCheckChanges(statuses []*GitRepoStatus, states map[string]string) []string {
+	failed := []string{}
+	for _, status := range statuses {
+		name := status.Context
+		if name == "" {
+			name = "status"
+		}
+		if states[name] != status.State {
+			states[name] = status.State
+			message := fmt.Sprintf("check %s is %s", util.ColorInfo(name), colorCheckState(status.State))
+			if status.TargetURL != "" {
+				message += " " + status.TargetURL
+			}
+			log.Logger().Info(message)
+		}
+		if status.IsFailed() {
+			failed = append(failed, name)
+		}
+	}
+	return failed
+}
+
+func colorCheckState(state string) string {
+	switch state {
+	case "success":
+		return util.ColorInfo(state)
+	case "error", "failure":
+		return util.ColorError(state)
+	default:
+		return util.ColorWarning(state)
+	}
+}
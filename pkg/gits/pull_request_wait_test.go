@@ -0,0 +1,118 @@
+// +build unit
+
+package gits_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jenkins-x/jx/v2/pkg/gits"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// pollingProvider a fake provider which returns the next state of a pull request each time it is polled
+type pollingProvider struct {
+	*gits.FakeProvider
+	polls    int
+	merged   []bool
+	statuses [][]*gits.GitRepoStatus
+}
+
+func (p *pollingProvider) UpdatePullRequestStatus(pr *gits.GitPullRequest) error {
+	merged := p.merged[p.polls]
+	pr.Merged = &merged
+	pr.LastCommitSha = "abc"
+	p.polls++
+	return nil
+}
+
+func (p *pollingProvider) ListCommitStatus(org string, repo string, sha string) ([]*gits.GitRepoStatus, error) {
+	return p.statuses[p.polls-1], nil
+}
+
+func TestWaitForPullRequestToMerge(t *testing.T) {
+	t.Parallel()
+
+	provider := &pollingProvider{
+		merged: []bool{false, false, true},
+		statuses: [][]*gits.GitRepoStatus{
+			{{Context: "pr-build", State: "pending"}},
+			{{Context: "pr-build", State: "success"}, {Context: "pr-build", State: "pending"}},
+		},
+	}
+	pr := &gits.GitPullRequest{URL: "https://github.com/acme/roadrunner/pull/1", Owner: "acme", Repo: "roadrunner"}
+	err := gits.WaitForPullRequestToMerge(provider, pr, time.Minute, time.Millisecond)
+	require.NoError(t, err)
+	assert.Equal(t, 3, provider.polls)
+}
+
+func TestWaitForPullRequestToMergeFailedCheck(t *testing.T) {
+	t.Parallel()
+
+	provider := &pollingProvider{
+		merged: []bool{false, false},
+		statuses: [][]*gits.GitRepoStatus{
+			{{Context: "pr-build", State: "pending"}, {Context: "lint", State: "success"}},
+			{{Context: "pr-build", State: "failure"}, {Context: "pr-build", State: "pending"}},
+		},
+	}
+	pr := &gits.GitPullRequest{URL: "https://github.com/acme/roadrunner/pull/1", Owner: "acme", Repo: "roadrunner"}
+	err := gits.WaitForPullRequestToMerge(provider, pr, time.Minute, time.Millisecond)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "the checks pr-build of pull request")
+	assert.Equal(t, 2, provider.polls)
+}
+
+func TestWaitForPullRequestToMergeTimeout(t *testing.T) {
+	t.Parallel()
+
+	provider := &pollingProvider{
+		merged:   []bool{false},
+		statuses: [][]*gits.GitRepoStatus{{{Context: "pr-build", State: "pending"}}},
+	}
+	pr := &gits.GitPullRequest{URL: "https://github.com/acme/roadrunner/pull/1", Owner: "acme", Repo: "roadrunner"}
+	err := gits.WaitForPullRequestToMerge(provider, pr, 0, time.Millisecond)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "timed out")
+}
+
+// queuingProvider a fake provider with a merge queue which returns the next entry of a pull request each time it is
+// polled
+type queuingProvider struct {
+	pollingProvider
+	entries []*gits.MergeQueueEntry
+}
+
+func (p *queuingProvider) EnqueuePullRequest(pr *gits.GitPullRequest) error {
+	return nil
+}
+
+func (p *queuingProvider) GetMergeQueueEntry(pr *gits.GitPullRequest) (*gits.MergeQueueEntry, error) {
+	return p.entries[p.polls-1], nil
+}
+
+func TestWaitForPullRequestToMergeUnmergeableInMergeQueue(t *testing.T) {
+	t.Parallel()
+
+	provider := &queuingProvider{
+		pollingProvider: pollingProvider{
+			merged: []bool{false, false, false},
+			statuses: [][]*gits.GitRepoStatus{
+				{{Context: "pr-build", State: "success"}},
+				{{Context: "pr-build", State: "success"}},
+				{{Context: "pr-build", State: "success"}},
+			},
+		},
+		entries: []*gits.MergeQueueEntry{
+			{State: gits.MergeQueueStateQueued, Position: 2},
+			{State: gits.MergeQueueStateAwaitingChecks, Position: 1},
+			{State: gits.MergeQueueStateUnmergeable, Position: 1},
+		},
+	}
+	pr := &gits.GitPullRequest{URL: "https://github.com/acme/roadrunner/pull/1", Owner: "acme", Repo: "roadrunner"}
+	err := gits.WaitForPullRequestToMerge(provider, pr, time.Minute, time.Millisecond)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "the merge queue cannot merge pull request")
+	assert.Equal(t, 3, provider.polls)
+}
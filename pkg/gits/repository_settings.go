@@ -0,0 +1,73 @@
+package gits
+
+import (
+	"fmt"
+
+	v1 "github.com/jenkins-x/jx/v2/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/v2/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+)
+
+const (
+	// MergeStrategyMerge merges Pull Requests with a merge commit
+	MergeStrategyMerge = "merge"
+	// MergeStrategySquash squashes the commits of Pull Requests into one commit
+	MergeStrategySquash = "squash"
+	// MergeStrategyRebase rebases the commits of Pull Requests onto the base branch
+	MergeStrategyRebase = "rebase"
+)
+
+// MergeStrategies the merge strategies of the repository settings of a team
+var MergeStrategies = []string{MergeStrategyMerge, MergeStrategySquash, MergeStrategyRebase}
+
+// RepositorySettingsConfigurer is implemented by git providers which can configure the branch protection, merge
+// strategies and labels of a repository
+type RepositorySettingsConfigurer interface {
+	// ConfigureRepositorySettings configures the repository with the settings, protecting the given branch
+	ConfigureRepositorySettings(owner string, repo string, branch string, settings *v1.RepositorySettings) error
+}
+
+// ValidateRepositorySettings returns an error if the settings cannot be applied to a repository
+func ValidateRepositorySettings(settings *v1.RepositorySettings) error {
+	if settings == nil {
+		return nil
+	}
+	for _, strategy := range settings.MergeStrategies {
+		if util.StringArrayIndex(MergeStrategies, strategy) < 0 {
+			return util.InvalidOption("mergeStrategies", strategy, MergeStrategies)
+		}
+	}
+	for _, label := range settings.Labels {
+		if label.Name == "" {
+			return fmt.Errorf("the repository labels must have a name")
+		}
+	}
+	protection := settings.BranchProtection
+	if protection != nil && protection.RequiredApprovingReviewCount < 0 {
+		return fmt.Errorf("the required approving review count %d cannot be negative", protection.RequiredApprovingReviewCount)
+	}
+	return nil
+}
+
+// ApplyRepositorySettings configures the repository with the settings of the team, returning false if there are no
+// settings or the git provider cannot configure repositories
+func ApplyRepositorySettings(provider GitProvider, owner string, repo string, branch string, settings *v1.RepositorySettings) (bool, error) {
+	if settings == nil {
+		return false, nil
+	}
+	err := ValidateRepositorySettings(settings)
+	if err != nil {
+		return false, err
+	}
+	configurer, ok := provider.(RepositorySettingsConfigurer)
+	if !ok {
+		log.Logger().Warnf("%s does not support configuring repositories so the settings of %s/%s were not changed", provider.Kind(), owner, repo)
+		return false, nil
+	}
+	err = configurer.ConfigureRepositorySettings(owner, repo, branch, settings)
+	if err != nil {
+		return false, err
+	}
+	log.Logger().Infof("Configured the repository settings of %s", util.ColorInfo(fmt.Sprintf("%s/%s", owner, repo)))
+	return true, nil
+}
@@ -0,0 +1,66 @@
+// +build unit
+
+package gits_test
+
+import (
+	"testing"
+
+	v1 "github.com/jenkins-x/jx/v2/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/v2/pkg/gits"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyRepositorySettings(t *testing.T) {
+	t.Parallel()
+
+	repo, err := gits.NewFakeRepository("acme", "roadrunner", nil, nil)
+	require.NoError(t, err)
+	provider := gits.NewFakeProvider(repo)
+
+	configured, err := gits.ApplyRepositorySettings(provider, "acme", "roadrunner", "master", nil)
+	require.NoError(t, err)
+	assert.False(t, configured, "configured without settings")
+	assert.Nil(t, repo.Settings)
+
+	settings := &v1.RepositorySettings{
+		BranchProtection: &v1.RepositoryBranchProtection{
+			RequiredStatusChecks:         []string{"pr-build"},
+			RequiredApprovingReviewCount: 1,
+		},
+		MergeStrategies: []string{gits.MergeStrategySquash},
+		Labels:          []v1.RepositoryLabel{{Name: "approved", Color: "00ff00"}},
+	}
+	configured, err = gits.ApplyRepositorySettings(provider, "acme", "roadrunner", "main", settings)
+	require.NoError(t, err)
+	assert.True(t, configured, "not configured")
+	assert.Equal(t, settings, repo.Settings)
+	assert.Equal(t, "main", repo.ProtectedBranch)
+
+	_, err = gits.ApplyRepositorySettings(provider, "acme", "coyote", "master", settings)
+	assert.Error(t, err, "configured a missing repository")
+}
+
+func TestValidateRepositorySettings(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		settings *v1.RepositorySettings
+		valid    bool
+	}{
+		{"nil", nil, true},
+		{"merge strategies", &v1.RepositorySettings{MergeStrategies: gits.MergeStrategies}, true},
+		{"unknown merge strategy", &v1.RepositorySettings{MergeStrategies: []string{"fast-forward"}}, false},
+		{"label without name", &v1.RepositorySettings{Labels: []v1.RepositoryLabel{{Color: "ededed"}}}, false},
+		{"negative review count", &v1.RepositorySettings{BranchProtection: &v1.RepositoryBranchProtection{RequiredApprovingReviewCount: -1}}, false},
+	}
+	for _, tc := range testCases {
+		err := gits.ValidateRepositorySettings(tc.settings)
+		if tc.valid {
+			assert.NoError(t, err, tc.name)
+		} else {
+			assert.Error(t, err, tc.name)
+		}
+	}
+}
@@ -0,0 +1,254 @@
+package gits
+
+import (
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cenkalti/backoff"
+	"github.com/jenkins-x/jx/v2/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+)
+
+const (
+	// RetryTimeoutEnvVar the environment variable to override how long network git operations and git provider API
+	// calls are retried for, e.g. 5m. A value of 0 disables retries
+	RetryTimeoutEnvVar = "JX_GIT_RETRY_TIMEOUT"
+
+	// RetryMaxAttemptsEnvVar the environment variable to override the maximum number of retries
+	RetryMaxAttemptsEnvVar = "JX_GIT_RETRY_MAX_ATTEMPTS"
+
+	defaultRetryTimeout     = 2 * time.Minute
+	defaultRetryMaxAttempts = 5
+)
+
+// transientGitErrors the messages of git failures which are worth retrying
+var transientGitErrors = []string{
+	"could not resolve host",
+	"connection timed out",
+	"connection reset",
+	"connection refused",
+	"operation timed out",
+	"tls handshake timeout",
+	"early eof",
+	"the remote end hung up unexpectedly",
+	"rpc failed",
+	"i/o timeout",
+	"unexpected disconnect",
+	"internal server error",
+	"bad gateway",
+	"service unavailable",
+	"gateway timeout",
+	"too many requests",
+	"the requested url returned error: 5",
+}
+
+// RetryPolicy describes how network git operations and git provider API calls are retried
+type RetryPolicy struct {
+	// Timeout the maximum total time spent retrying, 0 disables retries
+	Timeout time.Duration
+	// MaxAttempts the maximum number of retries, 0 retries until the timeout
+	MaxAttempts int
+	// InitialInterval the wait before the first retry
+	InitialInterval time.Duration
+	// MaxInterval the maximum wait between retries
+	MaxInterval time.Duration
+	// Jitter the randomization factor applied to each wait
+	Jitter float64
+}
+
+// DefaultRetryPolicy returns the retry policy configured from the environment
+func DefaultRetryPolicy() *RetryPolicy {
+	policy := &RetryPolicy{
+		Timeout:         defaultRetryTimeout,
+		MaxAttempts:     defaultRetryMaxAttempts,
+		InitialInterval: time.Second,
+		MaxInterval:     30 * time.Second,
+		Jitter:          0.5,
+	}
+	if value := os.Getenv(RetryTimeoutEnvVar); value != "" {
+		timeout, err := time.ParseDuration(value)
+		if err != nil {
+			log.Logger().Warnf("ignoring invalid duration %s in $%s", value, RetryTimeoutEnvVar)
+		} else {
+			policy.Timeout = timeout
+		}
+	}
+	if value := os.Getenv(RetryMaxAttemptsEnvVar); value != "" {
+		attempts, err := strconv.Atoi(value)
+		if err != nil {
+			log.Logger().Warnf("ignoring invalid number %s in $%s", value, RetryMaxAttemptsEnvVar)
+		} else {
+			policy.MaxAttempts = attempts
+		}
+	}
+	return policy
+}
+
+// NewBackOff creates the backoff for a single retried operation
+func (p *RetryPolicy) NewBackOff() backoff.BackOff {
+	if p == nil || p.Timeout <= 0 {
+		return &backoff.StopBackOff{}
+	}
+	exponential := backoff.NewExponentialBackOff()
+	exponential.MaxElapsedTime = p.Timeout
+	if p.InitialInterval > 0 {
+		exponential.InitialInterval = p.InitialInterval
+	}
+	if p.MaxInterval > 0 {
+		exponential.MaxInterval = p.MaxInterval
+	}
+	exponential.RandomizationFactor = p.Jitter
+	exponential.Reset()
+	if p.MaxAttempts > 0 {
+		return backoff.WithMaxRetries(exponential, uint64(p.MaxAttempts))
+	}
+	return exponential
+}
+
+// Retry invokes the operation until it succeeds, fails with a non transient error or the policy gives up
+func (p *RetryPolicy) Retry(description string, operation func() error) error {
+	f := func() error {
+		err := operation()
+		if err != nil && !IsTransientGitError(err) {
+			return backoff.Permanent(err)
+		}
+		return err
+	}
+	notify := func(err error, wait time.Duration) {
+		log.Logger().Warnf("%s failed, retrying in %s: %s", description, wait.Round(time.Millisecond), err)
+	}
+	err := backoff.RetryNotify(f, p.NewBackOff(), notify)
+	if permanent, ok := err.(*backoff.PermanentError); ok {
+		return permanent.Err
+	}
+	return err
+}
+
+// IsTransientGitError returns true if the error looks like a network failure which may succeed if retried
+func IsTransientGitError(err error) bool {
+	if err == nil {
+		return false
+	}
+	message := strings.ToLower(err.Error())
+	for _, text := range transientGitErrors {
+		if strings.Contains(message, text) {
+			return true
+		}
+	}
+	return false
+}
+
+// RetryTransport is a http.RoundTripper which retries requests which fail with a network error, a server error or
+// because of rate limiting, honouring the Retry-After and X-RateLimit headers
+type RetryTransport struct {
+	Base   http.RoundTripper
+	Policy *RetryPolicy
+}
+
+// NewRetryTransport wraps the given transport, or the default jx transport if nil, with the default retry policy
+func NewRetryTransport(base http.RoundTripper) *RetryTransport {
+	if base == nil {
+		base = util.GetClient().Transport
+	}
+	return &RetryTransport{
+		Base:   base,
+		Policy: DefaultRetryPolicy(),
+	}
+}
+
+// NewRetryClient returns a http client which retries transient failures
+func NewRetryClient(timeout time.Duration) *http.Client {
+	return &http.Client{
+		Transport: NewRetryTransport(nil),
+		Timeout:   timeout,
+	}
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *RetryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	bo := t.Policy.NewBackOff()
+	deadline := time.Now().Add(t.Policy.timeout())
+	for {
+		resp, err := t.Base.RoundTrip(req)
+		if !shouldRetryResponse(req, resp, err) || (req.Body != nil && req.GetBody == nil) {
+			return resp, err
+		}
+		wait := bo.NextBackOff()
+		if wait == backoff.Stop {
+			return resp, err
+		}
+		if resp != nil {
+			if rateLimitWait, ok := rateLimitWait(resp); ok {
+				wait = rateLimitWait
+			}
+		}
+		if time.Now().Add(wait).After(deadline) {
+			return resp, err
+		}
+		if req.GetBody != nil {
+			body, bodyErr := req.GetBody()
+			if bodyErr != nil {
+				return resp, err
+			}
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+		if resp != nil {
+			resp.Body.Close() //nolint:errcheck
+			log.Logger().Debugf("%s %s returned %s, retrying in %s", req.Method, req.URL, resp.Status, wait)
+		} else {
+			log.Logger().Debugf("%s %s failed, retrying in %s: %s", req.Method, req.URL, wait, err)
+		}
+		select {
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		case <-time.After(wait):
+		}
+	}
+}
+
+func (p *RetryPolicy) timeout() time.Duration {
+	if p == nil {
+		return 0
+	}
+	return p.Timeout
+}
+
+// shouldRetryResponse returns true if the request failed transiently. Requests which may have changed state on the
+// server are only retried when rate limited
+func shouldRetryResponse(req *http.Request, resp *http.Response, err error) bool {
+	idempotent := (req.Method != http.MethodPost && req.Method != http.MethodPatch) || isReadOnlyRequest(req)
+	if err != nil {
+		return idempotent
+	}
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests:
+		return true
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return idempotent
+	case http.StatusForbidden:
+		return resp.Header.Get("Retry-After") != "" || resp.Header.Get("X-RateLimit-Remaining") == "0"
+	}
+	return false
+}
+
+// rateLimitWait returns how long the server asked us to wait before retrying
+func rateLimitWait(resp *http.Response) (time.Duration, bool) {
+	if value := resp.Header.Get("Retry-After"); value != "" {
+		if seconds, err := strconv.Atoi(value); err == nil {
+			return time.Duration(seconds) * time.Second, true
+		}
+		if date, err := http.ParseTime(value); err == nil {
+			return time.Until(date), true
+		}
+	}
+	if resp.Header.Get("X-RateLimit-Remaining") == "0" {
+		if reset, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+			return time.Until(time.Unix(reset, 0)), true
+		}
+	}
+	return 0, false
+}
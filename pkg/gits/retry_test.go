@@ -0,0 +1,85 @@
+// +build unit
+
+package gits_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jenkins-x/jx/v2/pkg/gits"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testRetryPolicy() *gits.RetryPolicy {
+	return &gits.RetryPolicy{
+		Timeout:         10 * time.Second,
+		MaxAttempts:     3,
+		InitialInterval: time.Millisecond,
+		MaxInterval:     10 * time.Millisecond,
+	}
+}
+
+func TestRetryPolicyRetriesTransientErrors(t *testing.T) {
+	t.Parallel()
+
+	attempts := 0
+	err := testRetryPolicy().Retry("git fetch", func() error {
+		attempts++
+		if attempts < 3 {
+			return errors.New("fatal: unable to access 'https://github.com/jenkins-x/jx.git/': Could not resolve host: github.com")
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 3, attempts)
+
+	attempts = 0
+	err = testRetryPolicy().Retry("git push", func() error {
+		attempts++
+		return errors.New("! [rejected] master -> master (non-fast-forward)")
+	})
+	require.Error(t, err)
+	assert.Equal(t, 1, attempts, "non transient errors should not be retried")
+}
+
+func TestRetryTransportHonoursRateLimits(t *testing.T) {
+	t.Parallel()
+
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		switch requests {
+		case 1:
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+		case 2:
+			w.Header().Set("X-RateLimit-Remaining", "0")
+			w.Header().Set("X-RateLimit-Reset", "0")
+			w.WriteHeader(http.StatusForbidden)
+		default:
+			w.Write([]byte("ok")) //nolint:errcheck
+		}
+	}))
+	defer server.Close()
+
+	client := &http.Client{
+		Transport: &gits.RetryTransport{
+			Base:   http.DefaultTransport,
+			Policy: testRetryPolicy(),
+		},
+	}
+	resp, err := client.Get(server.URL)
+	require.NoError(t, err)
+	defer resp.Body.Close() //nolint:errcheck
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, 3, requests)
+
+	resp, err = client.Post(server.URL, "text/plain", nil)
+	require.NoError(t, err)
+	defer resp.Body.Close() //nolint:errcheck
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
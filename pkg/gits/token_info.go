@@ -0,0 +1,44 @@
+package gits
+
+import (
+	"net/http"
+	"strings"
+	"time"
+)
+
+// githubTokenExpirationFormat the format of the expiry of GitHub tokens in the GitHub-Authentication-Token-Expiration
+// response header
+const githubTokenExpirationFormat = "2006-01-02 15:04:05 MST"
+
+// TokenInfo describes the permissions and lifetime of the token used to access a git provider
+type TokenInfo struct {
+	// Scopes the OAuth scopes granted to the token
+	Scopes []string
+	// ExpiresAt when the token expires, nil if it never expires
+	ExpiresAt *time.Time
+}
+
+// TokenInspector is implemented by git providers which can describe the token they are accessed with
+type TokenInspector interface {
+	// GetTokenInfo returns the scopes and expiry of the token
+	GetTokenInfo() (*TokenInfo, error)
+}
+
+// parseGitHubTokenInfo reads the scopes and expiry of the token from the headers of a GitHub API response
+func parseGitHubTokenInfo(header http.Header) *TokenInfo {
+	answer := &TokenInfo{}
+	for _, scope := range strings.Split(header.Get("X-OAuth-Scopes"), ",") {
+		scope = strings.TrimSpace(scope)
+		if scope != "" {
+			answer.Scopes = append(answer.Scopes, scope)
+		}
+	}
+	expiry := strings.TrimSpace(header.Get("GitHub-Authentication-Token-Expiration"))
+	if expiry != "" {
+		t, err := time.Parse(githubTokenExpirationFormat, expiry)
+		if err == nil {
+			answer.ExpiresAt = &t
+		}
+	}
+	return answer
+}
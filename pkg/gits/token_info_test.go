@@ -0,0 +1,28 @@
+// +build unit
+
+package gits
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseGitHubTokenInfo(t *testing.T) {
+	t.Parallel()
+
+	header := http.Header{}
+	header.Set("X-OAuth-Scopes", "repo, admin:repo_hook,  read:org")
+	header.Set("GitHub-Authentication-Token-Expiration", "2026-11-01 12:30:00 UTC")
+
+	info := parseGitHubTokenInfo(header)
+	assert.Equal(t, []string{"repo", "admin:repo_hook", "read:org"}, info.Scopes)
+	require.NotNil(t, info.ExpiresAt)
+	assert.Equal(t, "2026-11-01T12:30:00Z", info.ExpiresAt.UTC().Format("2006-01-02T15:04:05Z07:00"))
+
+	info = parseGitHubTokenInfo(http.Header{})
+	assert.Empty(t, info.Scopes)
+	assert.Nil(t, info.ExpiresAt, "tokens without an expiry header never expire")
+}
@@ -0,0 +1,253 @@
+package health
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/jenkins-x/jx/v2/pkg/table"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	apiextensionsclientset "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// CheckStatus the status of a check of a component of the cluster
+type CheckStatus string
+
+const (
+	// CheckStatusOK the component is healthy
+	CheckStatusOK CheckStatus = "OK"
+	// CheckStatusWarning the component works but needs attention
+	CheckStatusWarning CheckStatus = "WARNING"
+	// CheckStatusError the component is broken
+	CheckStatusError CheckStatus = "ERROR"
+	// CheckStatusSkipped the component is not used by the cluster
+	CheckStatusSkipped CheckStatus = "SKIPPED"
+)
+
+// CheckResult the result of the check of a component of the cluster
+type CheckResult struct {
+	// Component the name of the component such as tekton or chartmuseum
+	Component string `json:"component"`
+	// Status the status of the component
+	Status CheckStatus `json:"status"`
+	// Message describes the status
+	Message string `json:"message"`
+	// Remediation how to fix the component if it is not healthy
+	Remediation string `json:"remediation,omitempty"`
+}
+
+// ClusterReport the results of the checks of the components of a cluster
+type ClusterReport struct {
+	// Namespace the development namespace of the cluster
+	Namespace string `json:"namespace"`
+	// Checks the results of the checks
+	Checks []CheckResult `json:"checks"`
+}
+
+// Add adds the result of a check
+func (r *ClusterReport) Add(result CheckResult) {
+	r.Checks = append(r.Checks, result)
+}
+
+// Failed returns the components which are broken
+func (r *ClusterReport) Failed() []string {
+	answer := []string{}
+	for _, c := range r.Checks {
+		if c.Status == CheckStatusError {
+			answer = append(answer, c.Component)
+		}
+	}
+	return answer
+}
+
+// Render writes the report as a table with the status of each component colored, followed by the remediation hints
+// of the components which are not healthy
+func (r *ClusterReport) Render(out io.Writer) {
+	t := table.CreateTable(out)
+	t.AddRow("COMPONENT", "STATUS", "MESSAGE")
+	for _, c := range r.Checks {
+		t.AddRow(c.Component, colorStatus(c.Status), c.Message)
+	}
+	t.Render()
+
+	hints := []string{}
+	for _, c := range r.Checks {
+		if c.Remediation != "" && (c.Status == CheckStatusError || c.Status == CheckStatusWarning) {
+			hints = append(hints, fmt.Sprintf("  %s: %s", c.Component, c.Remediation))
+		}
+	}
+	if len(hints) > 0 {
+		fmt.Fprintf(out, "\nTo fix the components which are not healthy:\n%s\n", strings.Join(hints, "\n"))
+	}
+}
+
+func colorStatus(status CheckStatus) string {
+	switch status {
+	case CheckStatusOK:
+		return util.ColorInfo(status)
+	case CheckStatusWarning:
+		return util.ColorWarning(status)
+	case CheckStatusError:
+		return util.ColorError(status)
+	default:
+		return util.ColorStatus(status)
+	}
+}
+
+// CheckDeployments checks that the deployments of a component are ready. If none of the deployments exist the
+// component is not installed, which is an error if it is required
+func CheckDeployments(kubeClient kubernetes.Interface, ns string, component string, names []string, required bool, remediation string) CheckResult {
+	result := CheckResult{
+		Component:   component,
+		Remediation: remediation,
+	}
+	missing := []string{}
+	notReady := []string{}
+	for _, name := range names {
+		d, err := kubeClient.AppsV1().Deployments(ns).Get(name, metav1.GetOptions{})
+		if err != nil {
+			if k8sErrors.IsNotFound(err) {
+				missing = append(missing, name)
+				continue
+			}
+			result.Status = CheckStatusError
+			result.Message = fmt.Sprintf("failed to get deployment %s: %s", name, err.Error())
+			return result
+		}
+		replicas := int32(1)
+		if d.Spec.Replicas != nil {
+			replicas = *d.Spec.Replicas
+		}
+		if d.Status.ReadyReplicas < replicas {
+			notReady = append(notReady, fmt.Sprintf("%s has %d/%d ready replicas", name, d.Status.ReadyReplicas, replicas))
+		}
+	}
+	switch {
+	case len(missing) == len(names) && !required:
+		result.Status = CheckStatusSkipped
+		result.Message = "not installed"
+		result.Remediation = ""
+	case len(missing) == len(names):
+		result.Status = CheckStatusError
+		result.Message = fmt.Sprintf("not installed in namespace %s", ns)
+	case len(missing) > 0:
+		result.Status = CheckStatusError
+		result.Message = fmt.Sprintf("missing deployments %s", strings.Join(missing, ", "))
+	case len(notReady) > 0:
+		result.Status = CheckStatusError
+		result.Message = strings.Join(notReady, ", ")
+	default:
+		result.Status = CheckStatusOK
+		result.Message = fmt.Sprintf("deployments %s are ready", strings.Join(names, ", "))
+	}
+	return result
+}
+
+// CheckCRDs checks that the custom resource definitions are registered
+func CheckCRDs(apiClient apiextensionsclientset.Interface, names []string, remediation string) CheckResult {
+	result := CheckResult{
+		Component:   "crds",
+		Remediation: remediation,
+	}
+	list, err := apiClient.ApiextensionsV1beta1().CustomResourceDefinitions().List(metav1.ListOptions{})
+	if err != nil {
+		result.Status = CheckStatusError
+		result.Message = fmt.Sprintf("failed to list the custom resource definitions: %s", err.Error())
+		return result
+	}
+	registered := map[string]bool{}
+	for _, crd := range list.Items {
+		registered[crd.Name] = true
+	}
+	missing := []string{}
+	for _, name := range names {
+		if !registered[name] {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		result.Status = CheckStatusError
+		result.Message = fmt.Sprintf("missing %s", strings.Join(missing, ", "))
+		return result
+	}
+	result.Status = CheckStatusOK
+	result.Message = fmt.Sprintf("%d custom resource definitions are registered", len(names))
+	return result
+}
+
+// ComponentVersion the version of a component in the cluster and the version the version stream expects
+type ComponentVersion struct {
+	// Name the name of the component
+	Name string
+	// Current the version in the cluster, blank if it is unknown
+	Current string
+	// Expected the version of the version stream, blank if it is unknown
+	Expected string
+}
+
+// CheckVersions checks for skew between the versions of the components in the cluster and the version stream
+func CheckVersions(versions []ComponentVersion, remediation string) CheckResult {
+	result := CheckResult{
+		Component:   "versions",
+		Remediation: remediation,
+	}
+	skewed := []string{}
+	unknown := []string{}
+	for _, v := range versions {
+		if v.Current == "" || v.Expected == "" {
+			unknown = append(unknown, v.Name)
+			continue
+		}
+		if strings.TrimPrefix(v.Current, "v") != strings.TrimPrefix(v.Expected, "v") {
+			skewed = append(skewed, fmt.Sprintf("%s is %s rather than %s", v.Name, v.Current, v.Expected))
+		}
+	}
+	switch {
+	case len(skewed) > 0:
+		result.Status = CheckStatusWarning
+		result.Message = strings.Join(skewed, ", ")
+	case len(unknown) == len(versions):
+		result.Status = CheckStatusSkipped
+		result.Message = "no versions could be compared with the version stream"
+		result.Remediation = ""
+	default:
+		result.Status = CheckStatusOK
+		result.Message = "the versions match the version stream"
+		if len(unknown) > 0 {
+			result.Message += fmt.Sprintf(" apart from %s which could not be compared", strings.Join(unknown, ", "))
+		}
+	}
+	return result
+}
+
+// DeploymentChartVersion returns the version of the chart of the deployment from its chart label, or blank if it
+// has no chart label
+func DeploymentChartVersion(kubeClient kubernetes.Interface, ns string, name string) (string, error) {
+	d, err := kubeClient.AppsV1().Deployments(ns).Get(name, metav1.GetOptions{})
+	if err != nil {
+		if k8sErrors.IsNotFound(err) {
+			return "", nil
+		}
+		return "", err
+	}
+	chart := d.Labels["helm.sh/chart"]
+	if chart == "" {
+		chart = d.Labels["chart"]
+	}
+	return ChartLabelVersion(chart), nil
+}
+
+// ChartLabelVersion returns the version of a chart label such as 'tekton-0.0.54'
+func ChartLabelVersion(label string) string {
+	for i := 0; i+1 < len(label); i++ {
+		if label[i] == '-' && label[i+1] >= '0' && label[i+1] <= '9' {
+			return label[i+1:]
+		}
+	}
+	return ""
+}
@@ -0,0 +1,92 @@
+// +build unit
+
+package health
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestCheckDeployments(t *testing.T) {
+	t.Parallel()
+
+	replicas := int32(2)
+	kubeClient := fake.NewSimpleClientset(
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "ready", Namespace: "jx", Labels: map[string]string{"chart": "tekton-0.0.54"}},
+			Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+			Status:     appsv1.DeploymentStatus{ReadyReplicas: 2},
+		},
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "starting", Namespace: "jx"},
+			Status:     appsv1.DeploymentStatus{ReadyReplicas: 0},
+		},
+	)
+
+	testCases := []struct {
+		names    []string
+		required bool
+		status   CheckStatus
+	}{
+		{[]string{"ready"}, true, CheckStatusOK},
+		{[]string{"ready", "starting"}, true, CheckStatusError},
+		{[]string{"ready", "missing"}, true, CheckStatusError},
+		{[]string{"missing"}, true, CheckStatusError},
+		{[]string{"missing"}, false, CheckStatusSkipped},
+	}
+	for _, tc := range testCases {
+		result := CheckDeployments(kubeClient, "jx", "tekton", tc.names, tc.required, "reinstall")
+		assert.Equal(t, tc.status, result.Status, "deployments %v: %s", tc.names, result.Message)
+	}
+
+	version, err := DeploymentChartVersion(kubeClient, "jx", "ready")
+	assert.NoError(t, err)
+	assert.Equal(t, "0.0.54", version)
+}
+
+func TestChartLabelVersion(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "0.0.54", ChartLabelVersion("tekton-0.0.54"))
+	assert.Equal(t, "1.2.3-SNAPSHOT", ChartLabelVersion("jenkins-x-chartmuseum-1.2.3-SNAPSHOT"))
+	assert.Equal(t, "", ChartLabelVersion("lighthouse"))
+	assert.Equal(t, "", ChartLabelVersion(""))
+}
+
+func TestCheckVersions(t *testing.T) {
+	t.Parallel()
+
+	result := CheckVersions([]ComponentVersion{{Name: "jx", Current: "2.1.0", Expected: "v2.1.0"}}, "upgrade")
+	assert.Equal(t, CheckStatusOK, result.Status, result.Message)
+
+	result = CheckVersions([]ComponentVersion{
+		{Name: "jx", Current: "2.1.0", Expected: "2.1.0"},
+		{Name: "jenkins-x/tekton", Current: "0.0.50", Expected: "0.0.54"},
+	}, "upgrade")
+	assert.Equal(t, CheckStatusWarning, result.Status)
+	assert.Equal(t, "jenkins-x/tekton is 0.0.50 rather than 0.0.54", result.Message)
+
+	result = CheckVersions([]ComponentVersion{{Name: "jx", Current: "2.1.0"}}, "upgrade")
+	assert.Equal(t, CheckStatusSkipped, result.Status)
+}
+
+func TestClusterReportRender(t *testing.T) {
+	t.Parallel()
+
+	report := &ClusterReport{Namespace: "jx"}
+	report.Add(CheckResult{Component: "tekton", Status: CheckStatusOK, Message: "ready"})
+	report.Add(CheckResult{Component: "ingress", Status: CheckStatusError, Message: "no domain", Remediation: "run jx upgrade ingress"})
+	report.Add(CheckResult{Component: "vault", Status: CheckStatusSkipped, Message: "not used", Remediation: "ignored"})
+
+	assert.Equal(t, []string{"ingress"}, report.Failed())
+
+	out := &bytes.Buffer{}
+	report.Render(out)
+	assert.Contains(t, out.String(), "ingress: run jx upgrade ingress")
+	assert.NotContains(t, out.String(), "ignored")
+}
@@ -27,6 +27,10 @@ type HelmCLI struct {
 	Runner     util.Commander
 	Debug      bool
 	kuber      kube.Kuber
+
+	postRenderer string
+	crdPolicy    CRDPolicy
+	hookPolicy   HookPolicy
 }
 
 // NewHelmCLIWithRunner creates a new HelmCLI interface for the given runner
@@ -66,17 +70,17 @@ func NewHelmCLI(binary string, version Version, cwd string, debug bool, args ...
 }
 
 // NewHelmCLIWithCompatibilityCheck creates a new HelmCLI and checks the compatibility with
-// the currently installed helm CLI. This will exit the program with a fatal log if the helm CLI
-// is not compatible.
+// the currently installed helm CLI. If a helm 3 CLI is installed the returned HelmCLI uses helm 3.
+// This will exit the program with a fatal log if the helm CLI is not compatible.
 func NewHelmCLIWithCompatibilityCheck(binary string, version Version, cwd string, debug bool, args ...string) *HelmCLI {
 	cli := NewHelmCLI(binary, version, cwd, debug, args...)
 	cli.checkCompatibility()
 	return cli
 }
 
-// checkCompatibility verifies whether the current helm CLI is compatible. The current
-// implementation only supports helm CLI v2. This function will exit the program if the
-// installed helm cli is not compatible.
+// checkCompatibility verifies whether the current helm CLI is compatible, switching to helm 3
+// when a helm 3 CLI is installed. This function will exit the program if the installed helm cli
+// is not compatible.
 func (h *HelmCLI) checkCompatibility() {
 	version, err := h.VersionWithArgs(false, "--client")
 	version = strings.TrimPrefix(version, "Client: ")
@@ -99,11 +103,20 @@ func (h *HelmCLI) checkCompatibility() {
 			log.Logger().Fatalf("You have set $JX_HELM3=true but your helm client version is %d", v.Major)
 		}
 	} else {
-		if v.Major > 2 {
-			log.Logger().Fatalf("Your current helm version v%d is not supported. Please downgrade to helm v2.", v.Major)
+		if v.Major > 3 {
+			log.Logger().Fatalf("Your current helm version v%d is not supported. Please use helm v3.", v.Major)
 		}
 	}
+	if v.Major == 3 {
+		h.BinVersion = V3
+	} else {
+		log.Logger().Debugf("helm v%d uses tiller which is deprecated, run %s to migrate to helm 3", v.Major, util.ColorInfo("jx step helm migrate"))
+	}
+}
 
+// IsHelm3 returns true if the helm CLI is helm 3 which does not use tiller
+func (h *HelmCLI) IsHelm3() bool {
+	return h.BinVersion == V3 || h.Binary == "helm3"
 }
 
 // SetHost is used to point at a locally running tiller
@@ -119,6 +132,42 @@ func (h *HelmCLI) SetCWD(dir string) {
 	h.CWD = dir
 }
 
+// SetPostRenderer configures the executable helm runs to modify the rendered manifests before they are installed,
+// which requires helm 3. An empty string disables post rendering
+func (h *HelmCLI) SetPostRenderer(postRenderer string) {
+	h.postRenderer = postRenderer
+}
+
+// SetInstallPolicy configures whether helm installs the CustomResourceDefinitions of charts and executes their hooks
+// when installing or upgrading them
+func (h *HelmCLI) SetInstallPolicy(crds CRDPolicy, hooks HookPolicy) {
+	h.crdPolicy = crds
+	h.hookPolicy = hooks
+}
+
+// installPolicyArgs returns the arguments which skip the CustomResourceDefinitions or hooks of a chart if configured
+func (h *HelmCLI) installPolicyArgs() []string {
+	args := []string{}
+	if h.crdPolicy.SkipCRDs() && h.IsHelm3() {
+		args = append(args, "--skip-crds")
+	}
+	if h.hookPolicy == HookPolicySkip {
+		args = append(args, "--no-hooks")
+	}
+	return args
+}
+
+// postRendererArgs returns the arguments which enable the post renderer if one is configured
+func (h *HelmCLI) postRendererArgs() ([]string, error) {
+	if h.postRenderer == "" {
+		return nil, nil
+	}
+	if !h.IsHelm3() {
+		return nil, errors.Errorf("the post renderer %s requires helm 3", h.postRenderer)
+	}
+	return []string{"--post-renderer", h.postRenderer}, nil
+}
+
 // HelmBinary return the configured helm CLI
 func (h *HelmCLI) HelmBinary() string {
 	return h.Binary
@@ -146,6 +195,10 @@ func (h *HelmCLI) runHelmWithOutput(args ...string) (string, error) {
 
 // Init executes the helm init command according with the given flags
 func (h *HelmCLI) Init(clientOnly bool, serviceAccount string, tillerNamespace string, upgrade bool) error {
+	if h.IsHelm3() {
+		// helm 3 has no tiller and needs no initialisation
+		return nil
+	}
 	args := []string{}
 	args = append(args, "init")
 	if clientOnly {
@@ -180,6 +233,28 @@ func (h *HelmCLI) AddRepo(repo, URL, username, password string) error {
 	return h.runHelm(args...)
 }
 
+// RegistryLogin logs in to the OCI registry so that charts can be pulled from it
+func (h *HelmCLI) RegistryLogin(registry string, username string, password string) error {
+	if !h.IsHelm3() {
+		return errors.Errorf("logging in to the OCI registry %s requires helm 3", registry)
+	}
+	return h.runHelm("registry", "login", registry, "--username", username, "--password", password)
+}
+
+// ociChart returns the reference of the chart in the OCI repository, logging in to the registry if there are credentials
+func (h *HelmCLI) ociChart(chart string, repo string, username string, password string) (string, error) {
+	if !h.IsHelm3() {
+		return "", errors.Errorf("the chart %s is in the OCI registry %s which requires helm 3", chart, repo)
+	}
+	if username != "" || password != "" {
+		err := h.RegistryLogin(OCIRegistry(repo), username, password)
+		if err != nil {
+			return "", errors.Wrapf(err, "logging in to the OCI registry %s", OCIRegistry(repo))
+		}
+	}
+	return OCIChartReference(repo, chart), nil
+}
+
 // RemoveRepo removes the given repo from helm
 func (h *HelmCLI) RemoveRepo(repo string) error {
 	return h.runHelm("repo", "remove", repo)
@@ -308,16 +383,28 @@ func (h *HelmCLI) BuildDependency() error {
 func (h *HelmCLI) InstallChart(chart string, releaseName string, ns string, version string, timeout int,
 	values []string, valueStrings []string, valueFiles []string, repo string, username string, password string) error {
 	var err error
+	if IsOCIRepository(repo) {
+		// charts in OCI registries are referenced directly rather than through a repository
+		chart, err = h.ociChart(chart, repo, username, password)
+		if err != nil {
+			return err
+		}
+		repo, username, password = "", "", ""
+	}
 
 	args := []string{}
-	args = append(args, "install", "--wait", "--name", releaseName, "--namespace", ns, chart)
+	if h.IsHelm3() {
+		args = append(args, "install", releaseName, chart, "--wait", "--namespace", ns)
+	} else {
+		args = append(args, "install", "--wait", "--name", releaseName, "--namespace", ns, chart)
+	}
 	repo, err = addUsernamePasswordToURL(repo, username, password)
 	if err != nil {
 		return err
 	}
 
 	if timeout != -1 {
-		if h.Binary == "helm3" {
+		if h.IsHelm3() {
 			args = append(args, "--timeout", fmt.Sprintf("%ss", strconv.Itoa(timeout)))
 		} else {
 			args = append(args, "--timeout", strconv.Itoa(timeout))
@@ -348,6 +435,12 @@ func (h *HelmCLI) InstallChart(chart string, releaseName string, ns string, vers
 	if logLevel != "" {
 		args = append(args, "-v", logLevel)
 	}
+	args = append(args, h.installPolicyArgs()...)
+	postRendererArgs, err := h.postRendererArgs()
+	if err != nil {
+		return err
+	}
+	args = append(args, postRendererArgs...)
 	if h.Debug {
 		log.Logger().Infof("Installing Chart '%s'", util.ColorInfo(strings.Join(args, " ")))
 	}
@@ -363,9 +456,18 @@ func (h *HelmCLI) InstallChart(chart string, releaseName string, ns string, vers
 // FetchChart fetches a Helm Chart
 func (h *HelmCLI) FetchChart(chart string, version string, untar bool, untardir string, repo string,
 	username string, password string) error {
+	var err error
+	if IsOCIRepository(repo) {
+		// charts in OCI registries are referenced directly rather than through a repository
+		chart, err = h.ociChart(chart, repo, username, password)
+		if err != nil {
+			return err
+		}
+		repo, username, password = "", "", ""
+	}
 	args := []string{}
 	args = append(args, "fetch", chart)
-	repo, err := addUsernamePasswordToURL(repo, username, password)
+	repo, err = addUsernamePasswordToURL(repo, username, password)
 	if err != nil {
 		return err
 	}
@@ -403,6 +505,9 @@ func (h *HelmCLI) FetchChart(chart string, version string, untar bool, untardir
 func (h *HelmCLI) Template(chart string, releaseName string, ns string, outDir string, upgrade bool,
 	values []string, valueStrings []string, valueFiles []string) error {
 	args := []string{"template", "--name", releaseName, "--namespace", ns, chart, "--output-dir", outDir, "--debug"}
+	if h.IsHelm3() {
+		args = []string{"template", releaseName, chart, "--namespace", ns, "--output-dir", outDir, "--debug"}
+	}
 	if upgrade {
 		args = append(args, "--is-upgrade")
 	}
@@ -415,11 +520,16 @@ func (h *HelmCLI) Template(chart string, releaseName string, ns string, outDir s
 	for _, valueFile := range valueFiles {
 		args = append(args, "--values", valueFile)
 	}
+	postRendererArgs, err := h.postRendererArgs()
+	if err != nil {
+		return err
+	}
+	args = append(args, postRendererArgs...)
 
 	if h.Debug {
 		log.Logger().Debugf("Generating Chart Template '%s'", util.ColorInfo(strings.Join(args, " ")))
 	}
-	err := h.runHelm(args...)
+	err = h.runHelm(args...)
 	if err != nil {
 		return errors.Wrapf(err, "Failed to run helm %s", strings.Join(args, " "))
 	}
@@ -429,6 +539,14 @@ func (h *HelmCLI) Template(chart string, releaseName string, ns string, outDir s
 // UpgradeChart upgrades a helm chart according with given helm flags
 func (h *HelmCLI) UpgradeChart(chart string, releaseName string, ns string, version string, install bool, timeout int, force bool, wait bool, values []string, valueStrings []string, valueFiles []string, repo string, username string, password string) error {
 	var err error
+	if IsOCIRepository(repo) {
+		// charts in OCI registries are referenced directly rather than through a repository
+		chart, err = h.ociChart(chart, repo, username, password)
+		if err != nil {
+			return err
+		}
+		repo, username, password = "", "", ""
+	}
 	args := []string{}
 	args = append(args, "upgrade")
 	args = append(args, "--namespace", ns)
@@ -447,7 +565,7 @@ func (h *HelmCLI) UpgradeChart(chart string, releaseName string, ns string, vers
 		args = append(args, "--force")
 	}
 	if timeout != -1 {
-		if h.BinVersion == V3 {
+		if h.IsHelm3() {
 			args = append(args, "--timeout", fmt.Sprintf("%ss", strconv.Itoa(timeout)))
 		} else {
 			args = append(args, "--timeout", strconv.Itoa(timeout))
@@ -478,6 +596,12 @@ func (h *HelmCLI) UpgradeChart(chart string, releaseName string, ns string, vers
 	if logLevel != "" {
 		args = append(args, "-v", logLevel)
 	}
+	args = append(args, h.installPolicyArgs()...)
+	postRendererArgs, err := h.postRendererArgs()
+	if err != nil {
+		return err
+	}
+	args = append(args, postRendererArgs...)
 	args = append(args, releaseName, chart)
 
 	if h.Debug {
@@ -494,6 +618,13 @@ func (h *HelmCLI) UpgradeChart(chart string, releaseName string, ns string, vers
 
 // DeleteRelease removes the given release
 func (h *HelmCLI) DeleteRelease(ns string, releaseName string, purge bool) error {
+	if h.IsHelm3() {
+		args := []string{"uninstall", releaseName, "--namespace", ns}
+		if !purge {
+			args = append(args, "--keep-history")
+		}
+		return h.runHelm(args...)
+	}
 	args := []string{}
 	args = append(args, "delete")
 	if purge {
@@ -513,7 +644,7 @@ func (h *HelmCLI) ListReleases(ns string) (map[string]ReleaseSummary, []string,
 	result := make(map[string]ReleaseSummary, 0)
 	keys := make([]string, 0)
 	if len(lines) > 1 {
-		if h.Binary == "helm" {
+		if !h.IsHelm3() {
 			for _, line := range lines[1:] {
 				fields := strings.Fields(line)
 				if len(fields) == 10 || len(fields) == 11 {
@@ -539,12 +670,13 @@ func (h *HelmCLI) ListReleases(ns string) (map[string]ReleaseSummary, []string,
 		} else {
 			for _, line := range lines[1:] {
 				fields := strings.Fields(line)
-				if len(fields) == 9 {
+				// newer helm 3 releases also output the APP VERSION column
+				if len(fields) == 9 || len(fields) == 10 {
 					chartFullName := fields[8]
 					lastDash := strings.LastIndex(chartFullName, "-")
 					releaseName := fields[0]
 					keys = append(keys, releaseName)
-					result[releaseName] = ReleaseSummary{
+					summary := ReleaseSummary{
 						ReleaseName:   fields[0],
 						Revision:      fields[2],
 						Updated:       fmt.Sprintf("%s %s %s %s", fields[3], fields[4], fields[5], fields[6]),
@@ -554,6 +686,10 @@ func (h *HelmCLI) ListReleases(ns string) (map[string]ReleaseSummary, []string,
 						Chart:         chartFullName[:lastDash],
 						ChartVersion:  chartFullName[lastDash+1:],
 					}
+					if len(fields) == 10 {
+						summary.AppVersion = fields[9]
+					}
+					result[releaseName] = summary
 				} else {
 					return nil, nil, errors.Errorf("Cannot parse %s as helm3 list output", line)
 				}
@@ -598,15 +734,22 @@ func (h *HelmCLI) FindChart() (string, error) {
 
 // StatusRelease returns the output of the helm status command for a given release
 func (h *HelmCLI) StatusRelease(ns string, releaseName string) error {
+	if h.IsHelm3() {
+		return h.runHelm("status", releaseName, "--namespace", ns)
+	}
 	return h.runHelm("status", releaseName)
 }
 
 // StatusReleaseWithOutput returns the output of the helm status command for a given release
 func (h *HelmCLI) StatusReleaseWithOutput(ns string, releaseName string, outputFormat string) (string, error) {
-	if outputFormat == "" {
-		return h.runHelmWithOutput("status", releaseName)
+	args := []string{"status", releaseName}
+	if h.IsHelm3() {
+		args = append(args, "--namespace", ns)
+	}
+	if outputFormat != "" {
+		args = append(args, "--output", outputFormat)
 	}
-	return h.runHelmWithOutput("status", releaseName, "--output", outputFormat)
+	return h.runHelmWithOutput(args...)
 }
 
 // Lint lints the helm chart from the current working directory and returns the warnings in the output
@@ -642,7 +785,7 @@ func (h *HelmCLI) Version(tls bool) (string, error) {
 // VersionWithArgs executes the helm version command and returns its output
 func (h *HelmCLI) VersionWithArgs(tls bool, extraArgs ...string) (string, error) {
 	args := []string{"version", "--short", "--client"}
-	if tls {
+	if tls && !h.IsHelm3() {
 		args = append(args, "--tls")
 	}
 	args = append(args, extraArgs...)
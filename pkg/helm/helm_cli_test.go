@@ -194,6 +194,15 @@ func TestBuildDependency(t *testing.T) {
 	verifyArgs(t, helm, runner, expectedArgs...)
 }
 
+func TestInitHelm3(t *testing.T) {
+	helm, runner := createHelmWithVersion(t, helm.V3, nil, "")
+
+	err := helm.Init(true, serviceAccount, namespace, true)
+
+	assert.NoError(t, err, "should init helm without any error")
+	runner.VerifyWasCalled(Never()).RunWithoutRetry()
+}
+
 func TestInstallChart(t *testing.T) {
 	value := []string{"test=true"}
 	valueString := []string{"context=test"}
@@ -207,6 +216,39 @@ func TestInstallChart(t *testing.T) {
 	verifyArgs(t, helm, runner, expectedArgs...)
 }
 
+func TestInstallChartHelm3(t *testing.T) {
+	value := []string{"test=true"}
+	expectedArgs := []string{"install", releaseName, chart, "--wait", "--namespace", namespace,
+		"--timeout", "600s", "--set", value[0]}
+	helm, runner := createHelmWithVersion(t, helm.V3, nil, "")
+
+	err := helm.InstallChart(chart, releaseName, namespace, "", 600, value, nil, nil, "", "", "")
+	assert.NoError(t, err, "should install the chart without any error")
+	verifyArgs(t, helm, runner, expectedArgs...)
+}
+
+func TestUpgradeChartWithPostRenderer(t *testing.T) {
+	expectedArgs := []string{"upgrade", "--namespace", namespace, "--install", "--post-renderer", "/tmp/post-render.sh",
+		releaseName, chart}
+	helm, runner := createHelmWithVersion(t, helm.V3, nil, "")
+	helm.SetPostRenderer("/tmp/post-render.sh")
+
+	err := helm.UpgradeChart(chart, releaseName, namespace, "", true, -1, false, false, nil, nil, nil, "", "", "")
+
+	assert.NoError(t, err, "should upgrade the chart without any error")
+	verifyArgs(t, helm, runner, expectedArgs...)
+}
+
+func TestPostRendererRequiresHelm3(t *testing.T) {
+	helm, runner := createHelm(t, nil, "")
+	helm.SetPostRenderer("/tmp/post-render.sh")
+
+	err := helm.UpgradeChart(chart, releaseName, namespace, "", true, -1, false, false, nil, nil, nil, "", "", "")
+
+	assert.Error(t, err, "post renderers are not supported by helm 2")
+	runner.VerifyWasCalled(Never()).RunWithoutRetry()
+}
+
 func TestUpgradeChart(t *testing.T) {
 	value := []string{"test=true"}
 	valueString := []string{"context=test"}
@@ -223,6 +265,40 @@ func TestUpgradeChart(t *testing.T) {
 	verifyArgs(t, helm, runner, expectedArgs...)
 }
 
+func TestUpgradeChartFromOCIRegistry(t *testing.T) {
+	expectedArgs := []string{"upgrade", "--namespace", namespace, "--install", "--version", "1.0.0", releaseName,
+		"oci://ghcr.io/myorg/charts/" + chart}
+	helm, runner := createHelmWithVersion(t, helm.V3, nil, "")
+
+	err := helm.UpgradeChart(chart, releaseName, namespace, "1.0.0", true, -1, false, false, nil, nil, nil,
+		"oci://ghcr.io/myorg/charts", "", "")
+
+	assert.NoError(t, err, "should upgrade the chart without any error")
+	verifyArgs(t, helm, runner, expectedArgs...)
+}
+
+func TestUpgradeChartWithInstallPolicy(t *testing.T) {
+	expectedArgs := []string{"upgrade", "--namespace", namespace, "--install", "--skip-crds", "--no-hooks", releaseName,
+		chart}
+	cli, runner := createHelmWithVersion(t, helm.V3, nil, "")
+	cli.SetInstallPolicy(helm.CRDPolicyServerSide, helm.HookPolicySkip)
+
+	err := cli.UpgradeChart(chart, releaseName, namespace, "", true, -1, false, false, nil, nil, nil, "", "", "")
+
+	assert.NoError(t, err, "should upgrade the chart without any error")
+	verifyArgs(t, cli, runner, expectedArgs...)
+}
+
+func TestRegistryLogin(t *testing.T) {
+	expectedArgs := []string{"registry", "login", "ghcr.io", "--username", "user", "--password", "pass"}
+	helm, runner := createHelmWithVersion(t, helm.V3, nil, "")
+
+	err := helm.RegistryLogin("ghcr.io", "user", "pass")
+
+	assert.NoError(t, err, "should log in to the registry without any error")
+	verifyArgs(t, helm, runner, expectedArgs...)
+}
+
 func TestDeleteRelaese(t *testing.T) {
 	expectedArgs := []string{"delete", "--purge", releaseName}
 	helm, runner := createHelm(t, nil, "")
@@ -234,6 +310,16 @@ func TestDeleteRelaese(t *testing.T) {
 	verifyArgs(t, helm, runner, expectedArgs...)
 }
 
+func TestDeleteReleaseHelm3(t *testing.T) {
+	expectedArgs := []string{"uninstall", releaseName, "--namespace", namespace, "--keep-history"}
+	helm, runner := createHelmWithVersion(t, helm.V3, nil, "")
+
+	err := helm.DeleteRelease(namespace, releaseName, false)
+
+	assert.NoError(t, err, "should delete helm chart release without any error")
+	verifyArgs(t, helm, runner, expectedArgs...)
+}
+
 func TestStatusRelease(t *testing.T) {
 	expectedArgs := []string{"status", releaseName}
 	helm, runner := createHelm(t, nil, "")
@@ -307,6 +393,32 @@ func TestStatusReleasesForHelm3(t *testing.T) {
 	}
 }
 
+func TestStatusReleaseWithOutputHelm3(t *testing.T) {
+	expectedArgs := []string{"status", releaseName, "--namespace", namespace, "--output", "json"}
+	helm, runner := createHelmWithVersion(t, helm.V3, nil, "")
+
+	_, err := helm.StatusReleaseWithOutput(namespace, releaseName, "json")
+
+	assert.NoErrorf(t, err, "should return the status of the helm chart in the release namespace")
+	verifyArgs(t, helm, runner, expectedArgs...)
+}
+
+func TestStatusReleasesForHelm3WithAppVersion(t *testing.T) {
+	output := `
+NAME 	NAMESPACE	REVISION	UPDATED                             	STATUS  	CHART              	APP VERSION
+jxing	jx       	2       	2019-05-17 15:30:07.629472 +0100 BST	deployed	nginx-ingress-1.3.1	0.24.1`
+	helm, _ := createHelmWithVersion(t, helm.V3, nil, output)
+
+	releaseMap, keys, err := helm.ListReleases("jx")
+
+	assert.NoError(t, err, "should list the releases without any error")
+	assert.Equal(t, []string{"jxing"}, keys)
+	assert.Equal(t, "DEPLOYED", releaseMap["jxing"].Status)
+	assert.Equal(t, "nginx-ingress", releaseMap["jxing"].Chart)
+	assert.Equal(t, "1.3.1", releaseMap["jxing"].ChartVersion)
+	assert.Equal(t, "0.24.1", releaseMap["jxing"].AppVersion)
+}
+
 func TestLint(t *testing.T) {
 	expectedArgs := []string{"lint",
 		"--set", "tags.jx-lint=true",
@@ -260,16 +260,16 @@ func LoadChartFile(fileName string) (*chart.Metadata, error) {
 	return &chart.Metadata{}, nil
 }
 
-// LoadValuesFile loads the values file or creates empty map if the file does not exist
+// LoadValuesFile loads the values file, decrypting it if it is encrypted with sops, or creates empty map if the file does not exist
 func LoadValuesFile(fileName string) (map[string]interface{}, error) {
 	exists, err := util.FileExists(fileName)
 	if err != nil {
 		return nil, errors.Wrapf(err, "checking %s exists", fileName)
 	}
 	if exists {
-		data, err := ioutil.ReadFile(fileName)
+		data, _, err := ReadSOPSFile(fileName)
 		if err != nil {
-			return nil, errors.Wrapf(err, "reading %s", fileName)
+			return nil, err
 		}
 		v, err := LoadValues(data)
 		if err != nil {
@@ -461,6 +461,9 @@ func CombineValueFilesToFile(outFile string, inputFiles []string, chartName stri
 
 // IsLocal returns whether this chart is being installed from the local filesystem or not
 func IsLocal(chart string) bool {
+	if IsOCIRepository(chart) {
+		return false
+	}
 	b := strings.HasPrefix(chart, "/") || strings.HasPrefix(chart, ".") || strings.Count(chart, "/") > 1
 	if !b {
 		// check if file exists, then it's local
@@ -538,6 +541,14 @@ type InstallChartOptions struct {
 func InstallFromChartOptions(options InstallChartOptions, helmer Helmer, kubeClient kubernetes.Interface,
 	installTimeout string, secretURLClient secreturl.Client) error {
 	chart := options.Chart
+	if options.Repository == "" && options.VersionsDir != "" {
+		// charts whose version stream prefix is an OCI registry are pulled from the registry
+		repo, err := OCIRepositoryForChart(options.VersionsDir, chart)
+		if err != nil {
+			return err
+		}
+		options.Repository = repo
+	}
 	if options.Version == "" {
 		versionsDir := options.VersionsDir
 		if versionsDir == "" {
@@ -646,7 +657,8 @@ func DecorateWithSecrets(valueFiles []string, secretURLClient secreturl.Client)
 	return newValuesFiles, cleanup, nil
 }
 
-// LoadParameters loads the 'parameters.yaml' file if it exists in the current directory
+// LoadParameters loads the 'parameters.yaml' file if it exists in the current directory, decrypting it if it is
+// encrypted with sops
 func LoadParameters(dir string, secretURLClient secreturl.Client) (chartutil.Values, error) {
 	fileName := filepath.Join(dir, ParametersYAMLFile)
 	exists, err := util.FileExists(fileName)
@@ -655,9 +667,9 @@ func LoadParameters(dir string, secretURLClient secreturl.Client) (chartutil.Val
 	}
 	m := map[string]interface{}{}
 	if exists {
-		data, err := ioutil.ReadFile(fileName)
+		data, _, err := ReadSOPSFile(fileName)
 		if err != nil {
-			return nil, errors.Wrapf(err, "reading %s", fileName)
+			return nil, err
 		}
 		if secretURLClient != nil {
 			text, err := secretURLClient.ReplaceURIs(string(data))
@@ -681,6 +693,21 @@ func LoadParameters(dir string, secretURLClient secreturl.Client) (chartutil.Val
 // The username and password will be stored in vault for the URL (if vault is enabled).
 func AddHelmRepoIfMissing(helmURL, repoName, username, password string, helmer Helmer,
 	secretURLClient secreturl.Client, handles util.IOFileHandles) (string, error) {
+	if IsOCIRepository(helmURL) {
+		// OCI registries are not added as helm repositories, charts are pulled from them directly once logged in
+		username, password, err := DecorateWithCredentials(helmURL, username, password, secretURLClient, handles)
+		if err != nil {
+			return "", errors.WithStack(err)
+		}
+		registry := OCIRegistry(helmURL)
+		if username != "" || password != "" {
+			err = helmer.RegistryLogin(registry, username, password)
+			if err != nil {
+				return "", errors.Wrapf(err, "failed to log in to the OCI registry %s", registry)
+			}
+		}
+		return registry, nil
+	}
 	missing, existingName, err := helmer.IsRepoMissing(helmURL)
 	if err != nil {
 		return "", errors.Wrapf(err, "failed to check if the repository with URL '%s' is missing", helmURL)
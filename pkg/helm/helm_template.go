@@ -102,6 +102,16 @@ func (h *HelmTemplate) SetCWD(dir string) {
 	h.CWD = dir
 }
 
+// SetPostRenderer configures the executable helm runs to modify the rendered manifests before they are applied
+func (h *HelmTemplate) SetPostRenderer(postRenderer string) {
+	h.Client.SetPostRenderer(postRenderer)
+}
+
+// SetInstallPolicy configures whether the CustomResourceDefinitions of charts are installed and their hooks executed
+func (h *HelmTemplate) SetInstallPolicy(crds CRDPolicy, hooks HookPolicy) {
+	h.Client.SetInstallPolicy(crds, hooks)
+}
+
 // HelmBinary return the configured helm CLI
 func (h *HelmTemplate) HelmBinary() string {
 	return h.Client.HelmBinary()
@@ -122,6 +132,11 @@ func (h *HelmTemplate) AddRepo(repo, URL, username, password string) error {
 	return h.Client.AddRepo(repo, URL, username, password)
 }
 
+// RegistryLogin logs in to the OCI registry
+func (h *HelmTemplate) RegistryLogin(registry string, username string, password string) error {
+	return h.Client.RegistryLogin(registry, username, password)
+}
+
 // RemoveRepo removes the given repo from helm
 func (h *HelmTemplate) RemoveRepo(repo string) error {
 	return h.Client.RemoveRepo(repo)
@@ -1089,6 +1104,10 @@ func (h *HelmTemplate) getChart(chartDir string, version string) (*chart.Metadat
 }
 
 func (h *HelmTemplate) runHooks(hooks []*HelmHook, hookPhase string, ns string, chart string, releaseName string, wait bool, create bool, force bool) error {
+	if h.skipHooks(hookPhase) {
+		log.Logger().Debugf("Not running the helm %s hooks of %s due to the install policy", hookPhase, releaseName)
+		return nil
+	}
 	matchingHooks := MatchingHooks(hooks, hookPhase, "")
 	for _, hook := range matchingHooks {
 		err := h.kubectlApplyFile(ns, hookPhase, wait, create, force, hook.File)
@@ -1099,7 +1118,18 @@ func (h *HelmTemplate) runHooks(hooks []*HelmHook, hookPhase string, ns string,
 	return nil
 }
 
+// skipHooks returns true if the hooks of the phase are not run due to the install policy of the client
+func (h *HelmTemplate) skipHooks(hookPhase string) bool {
+	if hookPhase == "crd-install" {
+		return h.Client.crdPolicy.SkipCRDs()
+	}
+	return h.Client.hookPolicy == HookPolicySkip
+}
+
 func (h *HelmTemplate) deleteHooks(hooks []*HelmHook, hookPhase string, hookDeletePolicy string, ns string) error {
+	if h.skipHooks(hookPhase) {
+		return nil
+	}
 	flag := os.Getenv("JX_DISABLE_DELETE_HELM_HOOKS")
 	matchingHooks := MatchingHooks(hooks, hookPhase, hookDeletePolicy)
 	for _, hook := range matchingHooks {
@@ -0,0 +1,128 @@
+package helm
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/mholt/archiver"
+	"github.com/pkg/errors"
+)
+
+// CRDPolicy defines how the CustomResourceDefinitions in the crds directories of a chart and its dependencies are
+// applied
+type CRDPolicy string
+
+// HookPolicy defines whether the hooks of a chart are executed
+type HookPolicy string
+
+const (
+	// CRDPolicyHelm helm installs the CustomResourceDefinitions, which it only does when a release is first installed
+	CRDPolicyHelm CRDPolicy = "helm"
+	// CRDPolicySkip the CustomResourceDefinitions are not applied at all
+	CRDPolicySkip CRDPolicy = "skip"
+	// CRDPolicyApplyFirst the CustomResourceDefinitions are applied with kubectl before the chart so that they are
+	// upgraded too
+	CRDPolicyApplyFirst CRDPolicy = "apply-first"
+	// CRDPolicyServerSide the CustomResourceDefinitions are applied with a kubectl server side apply before the chart,
+	// which supports CustomResourceDefinitions too large for the last applied configuration annotation
+	CRDPolicyServerSide CRDPolicy = "server-side"
+
+	// HookPolicyRun the hooks of the chart are executed
+	HookPolicyRun HookPolicy = "run"
+	// HookPolicySkip the hooks of the chart are not executed
+	HookPolicySkip HookPolicy = "skip"
+
+	// CRDsDirName the name of the directory of a chart containing its CustomResourceDefinitions
+	CRDsDirName = "crds"
+)
+
+var (
+	// CRDPolicyValues the valid CRD policies
+	CRDPolicyValues = []string{string(CRDPolicyHelm), string(CRDPolicySkip), string(CRDPolicyApplyFirst),
+		string(CRDPolicyServerSide)}
+
+	// HookPolicyValues the valid hook policies
+	HookPolicyValues = []string{string(HookPolicyRun), string(HookPolicySkip)}
+)
+
+// SkipCRDs returns true if helm should not install the CustomResourceDefinitions itself
+func (p CRDPolicy) SkipCRDs() bool {
+	return p != "" && p != CRDPolicyHelm
+}
+
+// AppliedFirst returns true if the CustomResourceDefinitions are applied with kubectl before the chart
+func (p CRDPolicy) AppliedFirst() bool {
+	return p == CRDPolicyApplyFirst || p == CRDPolicyServerSide
+}
+
+// CollectCRDs copies the CustomResourceDefinitions in the crds directories of the chart in chartDir and of its
+// packaged dependencies into outDir, returning the names of the copied files
+func CollectCRDs(chartDir string, outDir string) ([]string, error) {
+	err := os.MkdirAll(outDir, util.DefaultWritePermissions)
+	if err != nil {
+		return nil, errors.Wrapf(err, "creating directory %s", outDir)
+	}
+	answer, err := copyCRDs(chartDir, outDir, "")
+	if err != nil {
+		return nil, err
+	}
+
+	dependencies, err := filepath.Glob(filepath.Join(chartDir, "charts", "*.tgz"))
+	if err != nil {
+		return nil, errors.Wrapf(err, "finding chart dependencies in %s", filepath.Join(chartDir, "charts"))
+	}
+	for _, dependency := range dependencies {
+		tmpDir, err := ioutil.TempDir("", "jx-crds-")
+		if err != nil {
+			return nil, errors.Wrap(err, "creating temp dir")
+		}
+		err = archiver.Unarchive(dependency, tmpDir)
+		if err != nil {
+			os.RemoveAll(tmpDir) //nolint:errcheck
+			return nil, errors.Wrapf(err, "untarring %s to %s", dependency, tmpDir)
+		}
+		prefix := strings.TrimSuffix(filepath.Base(dependency), ".tgz") + "-"
+		files, err := copyCRDs(tmpDir, outDir, prefix)
+		os.RemoveAll(tmpDir) //nolint:errcheck
+		if err != nil {
+			return nil, err
+		}
+		answer = append(answer, files...)
+	}
+	return answer, nil
+}
+
+// copyCRDs copies the YAML and JSON files in any crds directory below dir into outDir, prefixing their names
+func copyCRDs(dir string, outDir string, prefix string) ([]string, error) {
+	answer := []string{}
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || filepath.Base(filepath.Dir(path)) != CRDsDirName {
+			return nil
+		}
+		ext := filepath.Ext(path)
+		if ext != ".yaml" && ext != ".yml" && ext != ".json" {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		dest := filepath.Join(outDir, prefix+strings.ReplaceAll(rel, string(filepath.Separator), "-"))
+		err = util.CopyFile(path, dest)
+		if err != nil {
+			return errors.Wrapf(err, "copying %s to %s", path, dest)
+		}
+		answer = append(answer, dest)
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "finding the CustomResourceDefinitions in %s", dir)
+	}
+	return answer, nil
+}
@@ -0,0 +1,64 @@
+// +build unit
+
+package helm_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	google_protobuf "github.com/golang/protobuf/ptypes/any"
+	"github.com/jenkins-x/jx/v2/pkg/helm"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/helm/pkg/chartutil"
+	"k8s.io/helm/pkg/proto/hapi/chart"
+)
+
+func TestCollectCRDs(t *testing.T) {
+	dir, err := ioutil.TempDir("", "test-collect-crds-")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir) //nolint:errcheck
+
+	chartDir := filepath.Join(dir, "env")
+	crdsDir := filepath.Join(chartDir, helm.CRDsDirName)
+	require.NoError(t, os.MkdirAll(crdsDir, util.DefaultWritePermissions))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(crdsDir, "cheese.yaml"), []byte("kind: CustomResourceDefinition\n"), util.DefaultWritePermissions))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(crdsDir, "README.md"), []byte("# CRDs\n"), util.DefaultWritePermissions))
+
+	chartsDir := filepath.Join(chartDir, "charts")
+	require.NoError(t, os.MkdirAll(chartsDir, util.DefaultWritePermissions))
+	_, err = chartutil.Save(&chart.Chart{
+		Metadata: &chart.Metadata{
+			ApiVersion: "v1",
+			Name:       "cert-manager",
+			Version:    "1.0.0",
+		},
+		Files: []*google_protobuf.Any{
+			{
+				TypeUrl: "crds/certificates.yaml",
+				Value:   []byte("kind: CustomResourceDefinition\n"),
+			},
+		},
+	}, chartsDir)
+	require.NoError(t, err)
+
+	outDir := filepath.Join(dir, "out")
+	files, err := helm.CollectCRDs(chartDir, outDir)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		filepath.Join(outDir, "crds-cheese.yaml"),
+		filepath.Join(outDir, "cert-manager-1.0.0-cert-manager-crds-certificates.yaml"),
+	}, files)
+}
+
+func TestCRDPolicy(t *testing.T) {
+	assert.False(t, helm.CRDPolicy("").SkipCRDs())
+	assert.False(t, helm.CRDPolicyHelm.SkipCRDs())
+	assert.True(t, helm.CRDPolicySkip.SkipCRDs())
+	assert.False(t, helm.CRDPolicySkip.AppliedFirst())
+	assert.True(t, helm.CRDPolicyApplyFirst.SkipCRDs())
+	assert.True(t, helm.CRDPolicyServerSide.AppliedFirst())
+}
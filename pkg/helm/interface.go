@@ -9,6 +9,7 @@ type Helmer interface {
 	Init(clientOnly bool, serviceAccount string, tillerNamespace string, upgrade bool) error
 	AddRepo(repo, URL, username, password string) error
 	RemoveRepo(repo string) error
+	RegistryLogin(registry string, username string, password string) error
 	ListRepos() (map[string]string, error)
 	UpdateRepo() error
 	IsRepoMissing(URL string) (bool, string, error)
@@ -30,6 +31,8 @@ type Helmer interface {
 	Version(tls bool) (string, error)
 	SearchCharts(filter string, allVersions bool) ([]ChartSummary, error)
 	SetHost(host string)
+	SetInstallPolicy(crds CRDPolicy, hooks HookPolicy)
+	SetPostRenderer(postRenderer string)
 	Env() map[string]string
 	DecryptSecrets(location string) error
 	Template(chartDir string, releaseName string, ns string, outputDir string, upgrade bool, values []string, valueStrings []string, valueFiles []string) error
@@ -0,0 +1,51 @@
+package helm
+
+import (
+	"sort"
+
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// Helm2to3PluginName the name of the helm plugin which migrates helm 2 releases to helm 3
+	Helm2to3PluginName = "2to3"
+	// Helm2to3PluginURL the URL the helm 2to3 plugin is installed from
+	Helm2to3PluginURL = "https://github.com/helm/helm-2to3"
+
+	// tillerOwnerSelector selects the ConfigMaps or Secrets tiller stores the helm 2 releases in
+	tillerOwnerSelector = "OWNER=TILLER"
+	tillerNameLabel     = "NAME"
+)
+
+// Helm2Releases returns the sorted names of the helm 2 releases stored by tiller in the given namespace, whether
+// tiller uses the ConfigMap or the Secret storage driver
+func Helm2Releases(kubeClient kubernetes.Interface, tillerNamespace string) ([]string, error) {
+	names := map[string]bool{}
+	listOptions := metav1.ListOptions{LabelSelector: tillerOwnerSelector}
+	configMaps, err := kubeClient.CoreV1().ConfigMaps(tillerNamespace).List(listOptions)
+	if err != nil {
+		return nil, errors.Wrapf(err, "listing the tiller ConfigMaps in namespace %s", tillerNamespace)
+	}
+	for _, cm := range configMaps.Items {
+		if name := cm.Labels[tillerNameLabel]; name != "" {
+			names[name] = true
+		}
+	}
+	secrets, err := kubeClient.CoreV1().Secrets(tillerNamespace).List(listOptions)
+	if err != nil {
+		return nil, errors.Wrapf(err, "listing the tiller Secrets in namespace %s", tillerNamespace)
+	}
+	for _, secret := range secrets.Items {
+		if name := secret.Labels[tillerNameLabel]; name != "" {
+			names[name] = true
+		}
+	}
+	answer := make([]string, 0, len(names))
+	for name := range names {
+		answer = append(answer, name)
+	}
+	sort.Strings(answer)
+	return answer, nil
+}
@@ -0,0 +1,45 @@
+// +build unit
+
+package helm_test
+
+import (
+	"testing"
+
+	"github.com/jenkins-x/jx/v2/pkg/helm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestHelm2Releases(t *testing.T) {
+	t.Parallel()
+
+	tillerObject := func(name string, release string, version string) metav1.ObjectMeta {
+		return metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "kube-system",
+			Labels: map[string]string{
+				"OWNER":   "TILLER",
+				"NAME":    release,
+				"VERSION": version,
+			},
+		}
+	}
+	kubeClient := fake.NewSimpleClientset(
+		&corev1.ConfigMap{ObjectMeta: tillerObject("jx-staging.v1", "jx-staging", "1")},
+		&corev1.ConfigMap{ObjectMeta: tillerObject("jx-staging.v2", "jx-staging", "2")},
+		&corev1.ConfigMap{ObjectMeta: tillerObject("jenkins-x.v1", "jenkins-x", "1")},
+		&corev1.Secret{ObjectMeta: tillerObject("jx-production.v1", "jx-production", "1")},
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "kube-system"}},
+	)
+
+	releases, err := helm.Helm2Releases(kubeClient, "kube-system")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"jenkins-x", "jx-production", "jx-staging"}, releases)
+
+	releases, err = helm.Helm2Releases(kubeClient, "jx")
+	require.NoError(t, err)
+	assert.Empty(t, releases)
+}
@@ -279,6 +279,21 @@ func (mock *MockHelmer) PackageChart() error {
 	return ret0
 }
 
+func (mock *MockHelmer) RegistryLogin(_param0 string, _param1 string, _param2 string) error {
+	if mock == nil {
+		panic("mock must not be nil. Use myMock := NewMockHelmer().")
+	}
+	params := []pegomock.Param{_param0, _param1, _param2}
+	result := pegomock.GetGenericMockFrom(mock).Invoke("RegistryLogin", params, []reflect.Type{reflect.TypeOf((*error)(nil)).Elem()})
+	var ret0 error
+	if len(result) != 0 {
+		if result[0] != nil {
+			ret0 = result[0].(error)
+		}
+	}
+	return ret0
+}
+
 func (mock *MockHelmer) RemoveRepo(_param0 string) error {
 	if mock == nil {
 		panic("mock must not be nil. Use myMock := NewMockHelmer().")
@@ -352,6 +367,22 @@ func (mock *MockHelmer) SetHost(_param0 string) {
 	pegomock.GetGenericMockFrom(mock).Invoke("SetHost", params, []reflect.Type{})
 }
 
+func (mock *MockHelmer) SetInstallPolicy(_param0 helm.CRDPolicy, _param1 helm.HookPolicy) {
+	if mock == nil {
+		panic("mock must not be nil. Use myMock := NewMockHelmer().")
+	}
+	params := []pegomock.Param{_param0, _param1}
+	pegomock.GetGenericMockFrom(mock).Invoke("SetInstallPolicy", params, []reflect.Type{})
+}
+
+func (mock *MockHelmer) SetPostRenderer(_param0 string) {
+	if mock == nil {
+		panic("mock must not be nil. Use myMock := NewMockHelmer().")
+	}
+	params := []pegomock.Param{_param0}
+	pegomock.GetGenericMockFrom(mock).Invoke("SetPostRenderer", params, []reflect.Type{})
+}
+
 func (mock *MockHelmer) StatusRelease(_param0 string, _param1 string) error {
 	if mock == nil {
 		panic("mock must not be nil. Use myMock := NewMockHelmer().")
@@ -928,6 +959,41 @@ func (c *MockHelmer_PackageChart_OngoingVerification) GetCapturedArguments() {
 func (c *MockHelmer_PackageChart_OngoingVerification) GetAllCapturedArguments() {
 }
 
+func (verifier *VerifierMockHelmer) RegistryLogin(_param0 string, _param1 string, _param2 string) *MockHelmer_RegistryLogin_OngoingVerification {
+	params := []pegomock.Param{_param0, _param1, _param2}
+	methodInvocations := pegomock.GetGenericMockFrom(verifier.mock).Verify(verifier.inOrderContext, verifier.invocationCountMatcher, "RegistryLogin", params, verifier.timeout)
+	return &MockHelmer_RegistryLogin_OngoingVerification{mock: verifier.mock, methodInvocations: methodInvocations}
+}
+
+type MockHelmer_RegistryLogin_OngoingVerification struct {
+	mock              *MockHelmer
+	methodInvocations []pegomock.MethodInvocation
+}
+
+func (c *MockHelmer_RegistryLogin_OngoingVerification) GetCapturedArguments() (string, string, string) {
+	_param0, _param1, _param2 := c.GetAllCapturedArguments()
+	return _param0[len(_param0)-1], _param1[len(_param1)-1], _param2[len(_param2)-1]
+}
+
+func (c *MockHelmer_RegistryLogin_OngoingVerification) GetAllCapturedArguments() (_param0 []string, _param1 []string, _param2 []string) {
+	params := pegomock.GetGenericMockFrom(c.mock).GetInvocationParams(c.methodInvocations)
+	if len(params) > 0 {
+		_param0 = make([]string, len(c.methodInvocations))
+		for u, param := range params[0] {
+			_param0[u] = param.(string)
+		}
+		_param1 = make([]string, len(c.methodInvocations))
+		for u, param := range params[1] {
+			_param1[u] = param.(string)
+		}
+		_param2 = make([]string, len(c.methodInvocations))
+		for u, param := range params[2] {
+			_param2[u] = param.(string)
+		}
+	}
+	return
+}
+
 func (verifier *VerifierMockHelmer) RemoveRepo(_param0 string) *MockHelmer_RemoveRepo_OngoingVerification {
 	params := []pegomock.Param{_param0}
 	methodInvocations := pegomock.GetGenericMockFrom(verifier.mock).Verify(verifier.inOrderContext, verifier.invocationCountMatcher, "RemoveRepo", params, verifier.timeout)
@@ -1084,6 +1150,64 @@ func (c *MockHelmer_SetHost_OngoingVerification) GetAllCapturedArguments() (_par
 	return
 }
 
+func (verifier *VerifierMockHelmer) SetInstallPolicy(_param0 helm.CRDPolicy, _param1 helm.HookPolicy) *MockHelmer_SetInstallPolicy_OngoingVerification {
+	params := []pegomock.Param{_param0, _param1}
+	methodInvocations := pegomock.GetGenericMockFrom(verifier.mock).Verify(verifier.inOrderContext, verifier.invocationCountMatcher, "SetInstallPolicy", params, verifier.timeout)
+	return &MockHelmer_SetInstallPolicy_OngoingVerification{mock: verifier.mock, methodInvocations: methodInvocations}
+}
+
+type MockHelmer_SetInstallPolicy_OngoingVerification struct {
+	mock              *MockHelmer
+	methodInvocations []pegomock.MethodInvocation
+}
+
+func (c *MockHelmer_SetInstallPolicy_OngoingVerification) GetCapturedArguments() (helm.CRDPolicy, helm.HookPolicy) {
+	_param0, _param1 := c.GetAllCapturedArguments()
+	return _param0[len(_param0)-1], _param1[len(_param1)-1]
+}
+
+func (c *MockHelmer_SetInstallPolicy_OngoingVerification) GetAllCapturedArguments() (_param0 []helm.CRDPolicy, _param1 []helm.HookPolicy) {
+	params := pegomock.GetGenericMockFrom(c.mock).GetInvocationParams(c.methodInvocations)
+	if len(params) > 0 {
+		_param0 = make([]helm.CRDPolicy, len(c.methodInvocations))
+		for u, param := range params[0] {
+			_param0[u] = param.(helm.CRDPolicy)
+		}
+		_param1 = make([]helm.HookPolicy, len(c.methodInvocations))
+		for u, param := range params[1] {
+			_param1[u] = param.(helm.HookPolicy)
+		}
+	}
+	return
+}
+
+func (verifier *VerifierMockHelmer) SetPostRenderer(_param0 string) *MockHelmer_SetPostRenderer_OngoingVerification {
+	params := []pegomock.Param{_param0}
+	methodInvocations := pegomock.GetGenericMockFrom(verifier.mock).Verify(verifier.inOrderContext, verifier.invocationCountMatcher, "SetPostRenderer", params, verifier.timeout)
+	return &MockHelmer_SetPostRenderer_OngoingVerification{mock: verifier.mock, methodInvocations: methodInvocations}
+}
+
+type MockHelmer_SetPostRenderer_OngoingVerification struct {
+	mock              *MockHelmer
+	methodInvocations []pegomock.MethodInvocation
+}
+
+func (c *MockHelmer_SetPostRenderer_OngoingVerification) GetCapturedArguments() string {
+	_param0 := c.GetAllCapturedArguments()
+	return _param0[len(_param0)-1]
+}
+
+func (c *MockHelmer_SetPostRenderer_OngoingVerification) GetAllCapturedArguments() (_param0 []string) {
+	params := pegomock.GetGenericMockFrom(c.mock).GetInvocationParams(c.methodInvocations)
+	if len(params) > 0 {
+		_param0 = make([]string, len(c.methodInvocations))
+		for u, param := range params[0] {
+			_param0[u] = param.(string)
+		}
+	}
+	return
+}
+
 func (verifier *VerifierMockHelmer) StatusRelease(_param0 string, _param1 string) *MockHelmer_StatusRelease_OngoingVerification {
 	params := []pegomock.Param{_param0, _param1}
 	methodInvocations := pegomock.GetGenericMockFrom(verifier.mock).Verify(verifier.inOrderContext, verifier.invocationCountMatcher, "StatusRelease", params, verifier.timeout)
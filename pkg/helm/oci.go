@@ -0,0 +1,82 @@
+package helm
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/jenkins-x/jx/v2/pkg/versionstream"
+	"github.com/pkg/errors"
+)
+
+const (
+	// OCIScheme the URL scheme of chart repositories stored in OCI registries such as GHCR, GAR or ECR
+	OCIScheme = "oci://"
+)
+
+// IsOCIRepository returns true if the chart repository is stored in an OCI registry
+func IsOCIRepository(repo string) bool {
+	return strings.HasPrefix(repo, OCIScheme)
+}
+
+// OCIRegistry returns the host of the OCI registry of the repository which is used to log in to the registry
+func OCIRegistry(repo string) string {
+	host := strings.TrimPrefix(repo, OCIScheme)
+	i := strings.Index(host, "/")
+	if i >= 0 {
+		host = host[:i]
+	}
+	return host
+}
+
+// OCIChartReference returns the reference of the chart in the OCI repository. Any repository prefix, such as the
+// prefix of the chart in the version stream, is removed from the chart name as OCI charts are referenced directly
+func OCIChartReference(repo string, chart string) string {
+	if IsOCIRepository(chart) {
+		return chart
+	}
+	parts := strings.Split(chart, "/")
+	return strings.TrimSuffix(repo, "/") + "/" + parts[len(parts)-1]
+}
+
+// SplitOCIChartReference splits the reference of a chart in an OCI registry into the repository and chart name
+func SplitOCIChartReference(ref string) (string, string) {
+	i := strings.LastIndex(ref, "/")
+	if i < len(OCIScheme) {
+		return ref, ""
+	}
+	return ref[:i], ref[i+1:]
+}
+
+// OCIRepositoryForChart returns the OCI repository of a chart of the form prefix/name when the prefix is associated
+// with an OCI registry in the version stream in versionsDir. An empty string is returned for any other chart
+func OCIRepositoryForChart(versionsDir string, chart string) (string, error) {
+	parts := strings.Split(chart, "/")
+	if len(parts) != 2 {
+		return "", nil
+	}
+	prefixes, err := versionstream.GetRepositoryPrefixes(versionsDir)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to load the repository prefixes in %s", versionsDir)
+	}
+	for _, u := range prefixes.URLsForPrefix(parts[0]) {
+		if IsOCIRepository(u) {
+			return u, nil
+		}
+	}
+	return "", nil
+}
+
+// FindLatestOCIChartVersion returns the latest version of the chart in the OCI repository. Registries cannot be
+// searched like helm repositories so the latest chart is pulled to find its version
+func FindLatestOCIChartVersion(chart string, repo string, username string, password string, helmer Helmer) (string, error) {
+	version := ""
+	err := InspectChart(chart, "", repo, username, password, helmer, func(dir string) error {
+		var err error
+		_, version, err = LoadChartNameAndVersion(filepath.Join(dir, ChartFileName))
+		return err
+	})
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to find the latest version of chart %s in %s", chart, repo)
+	}
+	return version, nil
+}
@@ -0,0 +1,36 @@
+// +build unit
+
+package helm_test
+
+import (
+	"testing"
+
+	"github.com/jenkins-x/jx/v2/pkg/helm"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsOCIRepository(t *testing.T) {
+	assert.True(t, helm.IsOCIRepository("oci://ghcr.io/myorg/charts"))
+	assert.False(t, helm.IsOCIRepository("https://charts.bitnami.com/bitnami"))
+}
+
+func TestOCIRegistry(t *testing.T) {
+	assert.Equal(t, "ghcr.io", helm.OCIRegistry("oci://ghcr.io/myorg/charts"))
+	assert.Equal(t, "europe-docker.pkg.dev", helm.OCIRegistry("oci://europe-docker.pkg.dev"))
+}
+
+func TestOCIChartReference(t *testing.T) {
+	assert.Equal(t, "oci://ghcr.io/myorg/charts/myapp", helm.OCIChartReference("oci://ghcr.io/myorg/charts/", "myorg/myapp"))
+	assert.Equal(t, "oci://ghcr.io/myorg/charts/myapp", helm.OCIChartReference("oci://ghcr.io/myorg/charts", "myapp"))
+	assert.Equal(t, "oci://gcr.io/other/myapp", helm.OCIChartReference("oci://ghcr.io/myorg/charts", "oci://gcr.io/other/myapp"))
+}
+
+func TestSplitOCIChartReference(t *testing.T) {
+	repo, chart := helm.SplitOCIChartReference("oci://ghcr.io/myorg/charts/myapp")
+	assert.Equal(t, "oci://ghcr.io/myorg/charts", repo)
+	assert.Equal(t, "myapp", chart)
+
+	repo, chart = helm.SplitOCIChartReference("oci://ghcr.io")
+	assert.Equal(t, "oci://ghcr.io", repo)
+	assert.Equal(t, "", chart)
+}
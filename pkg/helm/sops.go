@@ -0,0 +1,188 @@
+package helm
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+)
+
+const (
+	// SOPSMetadataKey the key sops stores the metadata of encrypted files under
+	SOPSMetadataKey = "sops"
+	// SOPSConfigFileName the sops configuration file whose creation rules choose the age, GPG or KMS keys to encrypt with
+	SOPSConfigFileName = ".sops.yaml"
+
+	sopsBinary = "sops"
+)
+
+// sopsKeyFlags maps the key groups in the sops metadata to the flags used to encrypt with the same keys
+var sopsKeyFlags = []struct {
+	group string
+	field string
+	flag  string
+}{
+	{"age", "recipient", "--age"},
+	{"pgp", "fp", "--pgp"},
+	{"kms", "arn", "--kms"},
+	{"gcp_kms", "resource_id", "--gcp-kms"},
+}
+
+// IsSOPSEncrypted returns true if the YAML data has been encrypted with sops
+func IsSOPSEncrypted(data []byte) bool {
+	return sopsMetadata(data) != nil
+}
+
+// ReadSOPSFile reads the file decrypting it with sops if it is encrypted. Returns true if the file was encrypted
+func ReadSOPSFile(fileName string) ([]byte, bool, error) {
+	data, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return nil, false, errors.Wrapf(err, "reading %s", fileName)
+	}
+	if !IsSOPSEncrypted(data) {
+		return data, false, nil
+	}
+	text, err := runSOPS(filepath.Dir(fileName), "--decrypt", "--input-type", "yaml", "--output-type", "yaml", filepath.Base(fileName))
+	if err != nil {
+		return nil, true, errors.Wrapf(err, "decrypting %s", fileName)
+	}
+	return []byte(text), true, nil
+}
+
+// WriteSOPSFile writes the file encrypting it with sops if it is already encrypted or a .sops.yaml configuration file
+// applies to it, so that secrets are never stored in plain text. Returns true if the file was encrypted
+func WriteSOPSFile(fileName string, data []byte) (bool, error) {
+	previous, err := readFileIfExists(fileName)
+	if err != nil {
+		return false, err
+	}
+	configFile, err := FindSOPSConfig(filepath.Dir(fileName))
+	if err != nil {
+		return false, err
+	}
+	if configFile == "" && !IsSOPSEncrypted(previous) {
+		return false, ioutil.WriteFile(fileName, data, util.DefaultWritePermissions)
+	}
+	args := []string{"--encrypt", "--in-place", "--input-type", "yaml", "--output-type", "yaml"}
+	if configFile == "" {
+		// lets encrypt with the same keys the file was encrypted with
+		args = append(args, sopsKeyArgs(previous)...)
+	}
+	args = append(args, filepath.Base(fileName))
+
+	err = ioutil.WriteFile(fileName, data, util.DefaultWritePermissions)
+	if err != nil {
+		return false, errors.Wrapf(err, "writing %s", fileName)
+	}
+	_, err = runSOPS(filepath.Dir(fileName), args...)
+	if err != nil {
+		// lets not leave the secrets in plain text
+		restoreErr := restoreFile(fileName, previous)
+		if restoreErr != nil {
+			return false, errors.Wrapf(restoreErr, "restoring %s after failing to encrypt it: %s", fileName, err)
+		}
+		return false, errors.Wrapf(err, "encrypting %s", fileName)
+	}
+	return true, nil
+}
+
+// FindSOPSConfig returns the .sops.yaml configuration file in the directory or any of its parents or an empty string
+// if there is none
+func FindSOPSConfig(dir string) (string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", errors.Wrapf(err, "resolving %s", dir)
+	}
+	for {
+		fileName := filepath.Join(dir, SOPSConfigFileName)
+		exists, err := util.FileExists(fileName)
+		if err != nil {
+			return "", errors.Wrapf(err, "checking %s exists", fileName)
+		}
+		if exists {
+			return fileName, nil
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", nil
+		}
+		dir = parent
+	}
+}
+
+// sopsMetadata returns the sops metadata of the YAML data or nil if it is not encrypted
+func sopsMetadata(data []byte) map[string]interface{} {
+	if len(data) == 0 {
+		return nil
+	}
+	m := map[string]interface{}{}
+	err := yaml.Unmarshal(data, &m)
+	if err != nil {
+		return nil
+	}
+	metadata, ok := m[SOPSMetadataKey].(map[string]interface{})
+	if !ok || metadata["mac"] == nil {
+		return nil
+	}
+	return metadata
+}
+
+// sopsKeyArgs returns the arguments which encrypt with the keys the data was encrypted with
+func sopsKeyArgs(data []byte) []string {
+	metadata := sopsMetadata(data)
+	answer := []string{}
+	for _, keyFlag := range sopsKeyFlags {
+		entries, _ := metadata[keyFlag.group].([]interface{})
+		keys := []string{}
+		for _, entry := range entries {
+			m, _ := entry.(map[string]interface{})
+			if key, ok := m[keyFlag.field].(string); ok && key != "" {
+				keys = append(keys, key)
+			}
+		}
+		if len(keys) > 0 {
+			answer = append(answer, keyFlag.flag, strings.Join(keys, ","))
+		}
+	}
+	return answer
+}
+
+func runSOPS(dir string, args ...string) (string, error) {
+	out := &bytes.Buffer{}
+	errOut := &bytes.Buffer{}
+	cmd := util.Command{
+		Name: sopsBinary,
+		Args: args,
+		Dir:  dir,
+		Out:  out,
+		Err:  errOut,
+	}
+	_, err := cmd.RunWithoutRetry()
+	if err != nil {
+		return "", errors.Wrapf(err, "running sops %s: %s", strings.Join(args, " "), strings.TrimSpace(errOut.String()))
+	}
+	return out.String(), nil
+}
+
+func readFileIfExists(fileName string) ([]byte, error) {
+	data, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, errors.Wrapf(err, "reading %s", fileName)
+	}
+	return data, nil
+}
+
+func restoreFile(fileName string, data []byte) error {
+	if data == nil {
+		return os.Remove(fileName)
+	}
+	return ioutil.WriteFile(fileName, data, util.DefaultWritePermissions)
+}
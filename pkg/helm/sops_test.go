@@ -0,0 +1,76 @@
+// +build unit
+
+package helm
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const encryptedParameters = `pipelineUser:
+    token: ENC[AES256_GCM,data:c2VjcmV0,iv:aXY=,tag:dGFn,type:str]
+sops:
+    kms: []
+    gcp_kms:
+        - resource_id: projects/myproject/locations/global/keyRings/jx/cryptoKeys/parameters
+    age:
+        - recipient: age1ql3z7hjy54pw3hyww5ayyfg7zqgvc7w3j2elw8zmrj2kg5sfn9aqmcac8p
+          enc: |
+            -----BEGIN AGE ENCRYPTED FILE-----
+            -----END AGE ENCRYPTED FILE-----
+        - recipient: age1lggyhqrw2nlhcxprm67z43rta597azn8gknawjehu9d9dl0jq3yqqvfafg
+    pgp: []
+    lastmodified: "2026-10-16T12:00:00Z"
+    mac: ENC[AES256_GCM,data:bWFj,iv:aXY=,tag:dGFn,type:str]
+    version: 3.7.1
+`
+
+func TestSOPSEncryptedParameters(t *testing.T) {
+	t.Parallel()
+
+	assert.True(t, IsSOPSEncrypted([]byte(encryptedParameters)))
+	assert.False(t, IsSOPSEncrypted([]byte("pipelineUser:\n  token: secret\n")))
+	assert.False(t, IsSOPSEncrypted([]byte("sops:\n  version: 3.7.1\n")), "files without a MAC are not encrypted")
+	assert.False(t, IsSOPSEncrypted(nil))
+
+	assert.Equal(t, []string{
+		"--age", "age1ql3z7hjy54pw3hyww5ayyfg7zqgvc7w3j2elw8zmrj2kg5sfn9aqmcac8p,age1lggyhqrw2nlhcxprm67z43rta597azn8gknawjehu9d9dl0jq3yqqvfafg",
+		"--gcp-kms", "projects/myproject/locations/global/keyRings/jx/cryptoKeys/parameters",
+	}, sopsKeyArgs([]byte(encryptedParameters)))
+}
+
+func TestWriteSOPSFileWithoutEncryption(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "test-sops-")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	envDir := filepath.Join(dir, "env")
+	require.NoError(t, os.MkdirAll(envDir, 0755))
+	fileName := filepath.Join(envDir, ParametersYAMLFile)
+
+	configFile, err := FindSOPSConfig(envDir)
+	require.NoError(t, err)
+	require.Empty(t, configFile, "there should be no sops configuration in the temporary directory")
+
+	encrypted, err := WriteSOPSFile(fileName, []byte("enableDocker: false\n"))
+	require.NoError(t, err)
+	assert.False(t, encrypted, "plain text files without a sops configuration should not be encrypted")
+
+	data, encrypted, err := ReadSOPSFile(fileName)
+	require.NoError(t, err)
+	assert.False(t, encrypted)
+	assert.Equal(t, "enableDocker: false\n", string(data))
+
+	sopsConfig := filepath.Join(dir, SOPSConfigFileName)
+	require.NoError(t, ioutil.WriteFile(sopsConfig, []byte("creation_rules:\n- path_regex: parameters.yaml\n"), 0600))
+	configFile, err = FindSOPSConfig(envDir)
+	require.NoError(t, err)
+	assert.Equal(t, sopsConfig, configFile)
+}
@@ -0,0 +1,122 @@
+package helm
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+)
+
+const (
+	// ValuesLayersDir the directory of an environment chart containing the values hierarchy
+	ValuesLayersDir = "values"
+	// BaseValuesFileName the values in the values hierarchy shared by every environment
+	BaseValuesFileName = "base.yaml"
+	// EnvironmentValuesDir the directory of the values hierarchy containing the overlay of each environment, such as
+	// environments/staging.yaml
+	EnvironmentValuesDir = "environments"
+	// AppValuesDir the directory of the values hierarchy containing the overrides of each app, such as
+	// apps/jx-app-cheese.yaml, which are nested under the name of the app
+	AppValuesDir = "apps"
+)
+
+// ValuesLayer is a values file of the values hierarchy of an environment chart
+type ValuesLayer struct {
+	// File the values file
+	File string
+	// Key the key the values are nested under, empty for values merged at the root
+	Key string
+}
+
+// ValuesLayers returns the layers of the values hierarchy in the values directory of the chart in dir in the order
+// they are merged for the environment: the base values, the overlay of the environment and then the overrides of each
+// app sorted by the name of the app. Layers which do not exist are skipped.
+func ValuesLayers(dir string, environment string) ([]ValuesLayer, error) {
+	layersDir := filepath.Join(dir, ValuesLayersDir)
+	candidates := []ValuesLayer{
+		{File: filepath.Join(layersDir, BaseValuesFileName)},
+	}
+	if environment != "" {
+		candidates = append(candidates, ValuesLayer{File: filepath.Join(layersDir, EnvironmentValuesDir, environment+".yaml")})
+	}
+	var answer []ValuesLayer
+	for _, layer := range candidates {
+		exists, err := util.FileExists(layer.File)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to check if %s exists", layer.File)
+		}
+		if exists {
+			answer = append(answer, layer)
+		}
+	}
+
+	appsDir := filepath.Join(layersDir, AppValuesDir)
+	exists, err := util.DirExists(appsDir)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to check if %s exists", appsDir)
+	}
+	if !exists {
+		return answer, nil
+	}
+	files, err := ioutil.ReadDir(appsDir)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read directory %s", appsDir)
+	}
+	var names []string
+	for _, f := range files {
+		if !f.IsDir() && (strings.HasSuffix(f.Name(), ".yaml") || strings.HasSuffix(f.Name(), ".yml")) {
+			names = append(names, f.Name())
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		answer = append(answer, ValuesLayer{
+			File: filepath.Join(appsDir, name),
+			Key:  strings.TrimSuffix(strings.TrimSuffix(name, ".yaml"), ".yml"),
+		})
+	}
+	return answer, nil
+}
+
+// MergeValuesLayers merges the layers into the values in order so that each layer overrides the values before it.
+// Maps are merged recursively whereas any other value, including lists, replaces the previous value
+func MergeValuesLayers(values map[string]interface{}, layers []ValuesLayer) error {
+	for _, layer := range layers {
+		layerValues, err := LoadValuesFile(layer.File)
+		if err != nil {
+			return errors.Wrapf(err, "failed to load values layer %s", layer.File)
+		}
+		if layer.Key != "" {
+			layerValues = map[string]interface{}{
+				layer.Key: layerValues,
+			}
+		}
+		util.CombineMapTrees(values, layerValues)
+	}
+	return nil
+}
+
+// ApplyValuesLayers merges the values hierarchy of the chart in dir for the environment into the values data,
+// returning the data unchanged if the chart has no values hierarchy
+func ApplyValuesLayers(data []byte, dir string, environment string) ([]byte, error) {
+	layers, err := ValuesLayers(dir, environment)
+	if err != nil {
+		return nil, err
+	}
+	if len(layers) == 0 {
+		return data, nil
+	}
+	values, err := LoadValues(data)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse the values")
+	}
+	err = MergeValuesLayers(values, layers)
+	if err != nil {
+		return nil, err
+	}
+	return yaml.Marshal(values)
+}
@@ -0,0 +1,84 @@
+// +build unit
+
+package helm_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ghodss/yaml"
+	"github.com/jenkins-x/jx/v2/pkg/helm"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeValuesLayer(t *testing.T, dir string, name string, text string) {
+	fileName := filepath.Join(dir, helm.ValuesLayersDir, name)
+	err := os.MkdirAll(filepath.Dir(fileName), util.DefaultWritePermissions)
+	require.NoError(t, err)
+	err = ioutil.WriteFile(fileName, []byte(text), util.DefaultWritePermissions)
+	require.NoError(t, err)
+}
+
+func TestApplyValuesLayers(t *testing.T) {
+	dir, err := ioutil.TempDir("", "test-values-layers-")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir) //nolint:errcheck
+
+	writeValuesLayer(t, dir, helm.BaseValuesFileName, `
+global:
+  domain: example.com
+  replicas: 1
+jx-app-cheese:
+  image: cheese
+  args: [a, b]
+`)
+	writeValuesLayer(t, dir, filepath.Join(helm.EnvironmentValuesDir, "production.yaml"), `
+global:
+  replicas: 3
+`)
+	writeValuesLayer(t, dir, filepath.Join(helm.EnvironmentValuesDir, "staging.yaml"), `
+global:
+  replicas: 2
+`)
+	writeValuesLayer(t, dir, filepath.Join(helm.AppValuesDir, "jx-app-cheese.yaml"), `
+args: [c]
+`)
+
+	layers, err := helm.ValuesLayers(dir, "production")
+	require.NoError(t, err)
+	require.Len(t, layers, 3)
+	assert.Equal(t, filepath.Join(dir, helm.ValuesLayersDir, helm.BaseValuesFileName), layers[0].File)
+	assert.Equal(t, filepath.Join(dir, helm.ValuesLayersDir, helm.EnvironmentValuesDir, "production.yaml"), layers[1].File)
+	assert.Equal(t, "jx-app-cheese", layers[2].Key)
+
+	data, err := helm.ApplyValuesLayers([]byte("global:\n  replicas: 0\n  version: 1.0.0\n"), dir, "production")
+	require.NoError(t, err)
+	values := map[string]interface{}{}
+	require.NoError(t, yaml.Unmarshal(data, &values))
+	assert.Equal(t, map[string]interface{}{
+		"global": map[string]interface{}{
+			"domain":   "example.com",
+			"replicas": float64(3),
+			"version":  "1.0.0",
+		},
+		"jx-app-cheese": map[string]interface{}{
+			"image": "cheese",
+			"args":  []interface{}{"c"},
+		},
+	}, values)
+}
+
+func TestApplyValuesLayersWithoutHierarchy(t *testing.T) {
+	dir, err := ioutil.TempDir("", "test-values-layers-")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir) //nolint:errcheck
+
+	data := []byte("# keep comments\nfoo: bar\n")
+	actual, err := helm.ApplyValuesLayers(data, dir, "staging")
+	require.NoError(t, err)
+	assert.Equal(t, data, actual, "the values should be unchanged when there is no values hierarchy")
+}
@@ -0,0 +1,195 @@
+package helmfile
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"github.com/jenkins-x/jx/v2/pkg/helm"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/jenkins-x/jx/v2/pkg/versionstream"
+	"github.com/pkg/errors"
+)
+
+const (
+	// HelmfileFileName the name of the helmfile generated for an environment
+	HelmfileFileName = "helmfile.yaml"
+	// ReleaseValuesDir the directory the values of each release are written to next to the helmfile
+	ReleaseValuesDir = "release-values"
+
+	// defaultEnvironmentTimeout the default timeout in seconds for each release of an environment
+	defaultEnvironmentTimeout = 600
+)
+
+// GenerateEnvironment generates the helmfile state which deploys each dependency in the requirements.yaml of an
+// environment chart as its own release in the namespace, rather than as a single umbrella chart release.
+//
+// The values of the umbrella chart are split into a values file for each release in dir, the global values are
+// passed to every release. Dependencies whose condition is false are marked as not installed so that helmfile removes
+// them. The releases keep the order of the requirements.yaml file.
+func GenerateEnvironment(dir string, ns string, requirements *helm.Requirements, values map[string]interface{}, prefixes *versionstream.RepositoryPrefixes) (*HelmState, error) {
+	state := &HelmState{
+		HelmDefaults: HelmSpec{
+			Atomic:  true,
+			Wait:    true,
+			Timeout: defaultEnvironmentTimeout,
+		},
+	}
+	if requirements == nil {
+		return state, nil
+	}
+	repoNames := map[string]string{}
+	for _, dep := range requirements.Dependencies {
+		if dep == nil {
+			continue
+		}
+		name := dep.Alias
+		if name == "" {
+			name = dep.Name
+		}
+		chart, err := chartReference(state, repoNames, dep, prefixes)
+		if err != nil {
+			return nil, err
+		}
+		release := ReleaseSpec{
+			Name:      name,
+			Namespace: ns,
+			Chart:     chart,
+			Version:   dep.Version,
+		}
+		if !conditionEnabled(values, dep.Condition) {
+			installed := false
+			release.Installed = &installed
+		}
+		valuesFile, err := writeReleaseValues(dir, name, values)
+		if err != nil {
+			return nil, err
+		}
+		if valuesFile != "" {
+			release.Values = []string{valuesFile}
+		}
+		state.Releases = append(state.Releases, release)
+	}
+	return state, nil
+}
+
+// SaveState saves the helmfile state to the given file
+func SaveState(fileName string, state *HelmState) error {
+	data, err := yaml.Marshal(state)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal helmfile data")
+	}
+	err = ioutil.WriteFile(fileName, data, util.DefaultWritePermissions)
+	if err != nil {
+		return errors.Wrapf(err, "failed to save file %s", fileName)
+	}
+	return nil
+}
+
+// chartReference returns the chart of the release, adding the repository of the dependency to the state if required
+func chartReference(state *HelmState, repoNames map[string]string, dep *helm.Dependency, prefixes *versionstream.RepositoryPrefixes) (string, error) {
+	repoURL := dep.Repository
+	if repoURL == "" {
+		return "", errors.Errorf("the dependency %s has no repository", dep.Name)
+	}
+	if strings.HasPrefix(repoURL, "file://") {
+		return strings.TrimPrefix(repoURL, "file://"), nil
+	}
+	if strings.HasPrefix(repoURL, "@") || strings.HasPrefix(repoURL, "alias:") {
+		// the repository is already referenced by the name of a local helm repository
+		repoName := strings.TrimPrefix(strings.TrimPrefix(repoURL, "@"), "alias:")
+		return repoName + "/" + dep.Name, nil
+	}
+	repoName := repoNames[repoURL]
+	if repoName == "" {
+		if prefixes != nil {
+			repoName = prefixes.PrefixForURL(repoURL)
+		}
+		if repoName == "" || repoNameUsed(repoNames, repoName) {
+			repoName = fmt.Sprintf("repo%d", len(repoNames)+1)
+		}
+		repoNames[repoURL] = repoName
+		repository := RepositorySpec{
+			Name: repoName,
+			URL:  repoURL,
+		}
+		if helm.IsOCIRepository(repoURL) {
+			repository.URL = strings.TrimPrefix(repoURL, helm.OCIScheme)
+			repository.OCI = true
+		}
+		state.Repositories = append(state.Repositories, repository)
+	}
+	return repoName + "/" + dep.Name, nil
+}
+
+func repoNameUsed(repoNames map[string]string, repoName string) bool {
+	for _, name := range repoNames {
+		if name == repoName {
+			return true
+		}
+	}
+	return false
+}
+
+// writeReleaseValues writes the values of the umbrella chart for the release along with the global values, returning
+// the name of the file relative to dir or an empty string if there are no values for the release
+func writeReleaseValues(dir string, name string, values map[string]interface{}) (string, error) {
+	releaseValues := map[string]interface{}{}
+	if m, ok := values[name].(map[string]interface{}); ok {
+		for k, v := range m {
+			releaseValues[k] = v
+		}
+	}
+	if global, ok := values["global"]; ok {
+		releaseValues["global"] = global
+	}
+	if len(releaseValues) == 0 {
+		return "", nil
+	}
+	data, err := yaml.Marshal(releaseValues)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to marshal the values of release %s", name)
+	}
+	valuesDir := filepath.Join(dir, ReleaseValuesDir)
+	err = os.MkdirAll(valuesDir, util.DefaultWritePermissions)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to create directory %s", valuesDir)
+	}
+	fileName := filepath.Join(ReleaseValuesDir, name+".yaml")
+	err = ioutil.WriteFile(filepath.Join(dir, fileName), data, util.DefaultWritePermissions)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to save file %s", fileName)
+	}
+	return fileName, nil
+}
+
+// conditionEnabled evaluates the condition of a dependency like helm does, the first path which resolves to a boolean
+// value is used and the dependency is enabled if no path resolves
+func conditionEnabled(values map[string]interface{}, condition string) bool {
+	for _, path := range strings.Split(condition, ",") {
+		path = strings.TrimSpace(path)
+		if path == "" {
+			continue
+		}
+		if enabled, ok := lookupValue(values, path).(bool); ok {
+			return enabled
+		}
+	}
+	return true
+}
+
+// lookupValue returns the value at the dotted path without modifying the values
+func lookupValue(values map[string]interface{}, path string) interface{} {
+	var answer interface{} = values
+	for _, key := range strings.Split(path, ".") {
+		m, ok := answer.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		answer = m[key]
+	}
+	return answer
+}
@@ -0,0 +1,76 @@
+// +build unit
+
+package helmfile_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ghodss/yaml"
+	"github.com/jenkins-x/jx/v2/pkg/helm"
+	"github.com/jenkins-x/jx/v2/pkg/helmfile"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateEnvironment(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "test-helmfile-environment-")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	requirements := &helm.Requirements{
+		Dependencies: []*helm.Dependency{
+			{Name: "exposecontroller", Version: "2.3.118", Repository: "https://storage.googleapis.com/chartmuseum.jenkins-x.io"},
+			{Name: "myapp", Alias: "frontend", Version: "1.0.0", Repository: "https://storage.googleapis.com/chartmuseum.jenkins-x.io"},
+			{Name: "nginx-ingress", Version: "1.2.3", Repository: "https://kubernetes-charts.storage.googleapis.com", Condition: "nginx-ingress.enabled"},
+			{Name: "local", Repository: "file://../local"},
+		},
+	}
+	values := map[string]interface{}{
+		"global": map[string]interface{}{"jxNs": "jx-staging"},
+		"frontend": map[string]interface{}{
+			"replicaCount": float64(2),
+		},
+		"nginx-ingress": map[string]interface{}{
+			"enabled": false,
+		},
+	}
+
+	state, err := helmfile.GenerateEnvironment(dir, "jx-staging", requirements, values, nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, []helmfile.RepositorySpec{
+		{Name: "repo1", URL: "https://storage.googleapis.com/chartmuseum.jenkins-x.io"},
+		{Name: "repo2", URL: "https://kubernetes-charts.storage.googleapis.com"},
+	}, state.Repositories)
+
+	require.Len(t, state.Releases, 4)
+	assert.Equal(t, "exposecontroller", state.Releases[0].Name)
+	assert.Equal(t, "repo1/exposecontroller", state.Releases[0].Chart)
+	assert.Equal(t, "2.3.118", state.Releases[0].Version)
+	assert.Equal(t, "jx-staging", state.Releases[0].Namespace)
+	assert.Nil(t, state.Releases[0].Installed)
+
+	assert.Equal(t, "frontend", state.Releases[1].Name)
+	assert.Equal(t, "repo1/myapp", state.Releases[1].Chart)
+	require.Equal(t, []string{filepath.Join(helmfile.ReleaseValuesDir, "frontend.yaml")}, state.Releases[1].Values)
+
+	require.NotNil(t, state.Releases[2].Installed)
+	assert.False(t, *state.Releases[2].Installed, "the release should be removed when its condition is false")
+
+	assert.Equal(t, "../local", state.Releases[3].Chart)
+
+	data, err := ioutil.ReadFile(filepath.Join(dir, state.Releases[1].Values[0]))
+	require.NoError(t, err)
+	releaseValues := map[string]interface{}{}
+	require.NoError(t, yaml.Unmarshal(data, &releaseValues))
+	assert.Equal(t, map[string]interface{}{
+		"replicaCount": float64(2),
+		"global":       map[string]interface{}{"jxNs": "jx-staging"},
+	}, releaseValues)
+	assert.NotContains(t, values, "exposecontroller", "the values should not be modified")
+}
@@ -85,6 +85,8 @@ type RepositorySpec struct {
 	KeyFile  string `json:"keyFile,omitempty"`
 	Username string `json:"username,omitempty"`
 	Password string `json:"password,omitempty"`
+	// OCI is true if the repository is stored in an OCI registry in which case the URL has no scheme
+	OCI bool `json:"oci,omitempty"`
 }
 
 // ReleaseSpec defines the structure of a helm release
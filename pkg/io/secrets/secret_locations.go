@@ -26,6 +26,9 @@ const (
 	VaultLocationKind SecretsLocationKind = "vault"
 	// KubeLocationKind indicates that secrets location is in Kubernetes
 	KubeLocationKind SecretsLocationKind = "kube"
+	// ExternalSecretsLocationKind indicates that secrets are stored in a cloud secret manager and synced into
+	// Kubernetes by the External Secrets Operator
+	ExternalSecretsLocationKind SecretsLocationKind = "external-secrets"
 	// AutoLocationKind indicates that secrets location needs to be dynamically determine
 	AutoLocationKind SecretsLocationKind = "auto"
 )
@@ -62,8 +65,8 @@ func (s *secretLocation) Location() SecretsLocationKind {
 		return s.location
 	}
 	value, ok := configMap[SecretsLocationKey]
-	if ok && value == string(VaultLocationKind) {
-		return VaultLocationKind
+	if ok && (value == string(VaultLocationKind) || value == string(ExternalSecretsLocationKind)) {
+		return SecretsLocationKind(value)
 	}
 	return s.location
 }
@@ -102,6 +105,8 @@ func ToSecretsLocation(location string) SecretsLocationKind {
 		return VaultLocationKind
 	case "kube":
 		return KubeLocationKind
+	case "external-secrets":
+		return ExternalSecretsLocationKind
 	default:
 		return AutoLocationKind
 	}
@@ -93,7 +93,7 @@ func createBranchSource(info *gits.GitRepository, gitProvider gits.GitProvider,
 	switch gitProvider.Kind() {
 	case gits.KindGitHub:
 		serverXml := ""
-		ghp, ok := gitProvider.(*gits.GitHubProvider)
+		ghp, ok := gitProvider.(interface{ GetEnterpriseApiURL() string })
 		if ok {
 			u := ghp.GetEnterpriseApiURL()
 			if u != "" {
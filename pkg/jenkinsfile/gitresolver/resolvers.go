@@ -6,6 +6,8 @@ import (
 	"github.com/jenkins-x/jx/v2/pkg/gits"
 	"github.com/jenkins-x/jx/v2/pkg/jenkinsfile"
 	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/jenkins-x/jx/v2/pkg/versionstream"
+	"github.com/pkg/errors"
 	"sigs.k8s.io/yaml"
 
 	"io/ioutil"
@@ -25,6 +27,37 @@ func CreateResolver(packsDir string, gitter gits.Gitter) (jenkinsfile.ImportFile
 	return moduleResolver.AsImportResolver(), nil
 }
 
+// CreateImportsResolver creates a resolver for the pipeline libraries imported by a pipeline configuration. Imports
+// without a git ref use the version of the git repository in the version stream, or master if there is none. Imports
+// of other modules are resolved by the fallback resolver
+func CreateImportsResolver(imports []*jenkinsfile.Module, gitter gits.Gitter, versionResolver *versionstream.VersionResolver, fallback jenkinsfile.ImportFileResolver) (jenkinsfile.ImportFileResolver, error) {
+	modules := &jenkinsfile.Modules{}
+	for _, m := range imports {
+		module := m.DeepCopy()
+		if module.GitRef == "" && versionResolver != nil {
+			version, err := versionResolver.ResolveGitVersion(module.GitURL)
+			if err != nil {
+				return nil, errors.Wrapf(err, "resolving the version of import %s", module.Name)
+			}
+			module.GitRef = version
+		}
+		if module.GitRef == "" {
+			module.GitRef = "master"
+		}
+		modules.Modules = append(modules.Modules, module)
+	}
+	moduleResolver, err := ResolveModules(modules, gitter)
+	if err != nil {
+		return nil, errors.Wrap(err, "resolving the imported pipeline libraries")
+	}
+	return func(importFile *jenkinsfile.ImportFile) (string, error) {
+		if moduleResolver.Modules[importFile.Import] == nil && fallback != nil {
+			return fallback(importFile)
+		}
+		return moduleResolver.ResolveImport(importFile)
+	}, nil
+}
+
 // Resolve resolves this module to a directory
 func Resolve(m *jenkinsfile.Module, gitter gits.Gitter) (*ModuleResolver, error) {
 	err := m.Validate()
@@ -0,0 +1,212 @@
+package jenkinsfile
+
+import (
+	"fmt"
+	"io/ioutil"
+
+	"github.com/jenkins-x/jx/v2/pkg/tekton/syntax"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// maxIncludeDepth the maximum depth of fragments including other fragments, which stops include cycles
+	maxIncludeDepth = 10
+)
+
+// PipelineFragment defines the steps in a file of a pipeline library which are included by pipelines
+type PipelineFragment struct {
+	Steps []*syntax.Step `json:"steps,omitempty"`
+}
+
+// LoadPipelineFragment loads the pipeline fragment in the given file
+func LoadPipelineFragment(fileName string) (*PipelineFragment, error) {
+	exists, err := util.FileExists(fileName)
+	if err != nil {
+		return nil, errors.Wrapf(err, "checking if %s exists", fileName)
+	}
+	if !exists {
+		return nil, fmt.Errorf("pipeline fragment %s does not exist", fileName)
+	}
+	data, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to load file %s", fileName)
+	}
+	fragment := &PipelineFragment{}
+	err = yaml.Unmarshal(data, fragment)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to unmarshal file %s", fileName)
+	}
+	return fragment, nil
+}
+
+// ResolveIncludes replaces the steps including files of imported pipeline libraries with the steps of those files, in
+// the lifecycles, pipelines and overrides of the configuration
+func (c *PipelineConfig) ResolveIncludes(resolver ImportFileResolver) error {
+	var err error
+	pipelines := &c.Pipelines
+	for _, lifecycles := range pipelines.All() {
+		if lifecycles == nil {
+			continue
+		}
+		for _, named := range lifecycles.All() {
+			err = named.Lifecycle.resolveIncludes(resolver)
+			if err != nil {
+				return errors.Wrapf(err, "in the %s lifecycle", named.Name)
+			}
+		}
+		err = resolvePipelineIncludes(lifecycles.Pipeline, resolver)
+		if err != nil {
+			return err
+		}
+	}
+	err = pipelines.Post.resolveIncludes(resolver)
+	if err != nil {
+		return errors.Wrap(err, "in the post lifecycle")
+	}
+	err = resolvePipelineIncludes(pipelines.Default, resolver)
+	if err != nil {
+		return err
+	}
+	for _, override := range pipelines.Overrides {
+		if override.Step != nil && override.Step.Include != nil {
+			steps, err := resolveStepIncludes([]*syntax.Step{override.Step}, resolver, 0)
+			if err != nil {
+				return errors.Wrapf(err, "in the override of pipeline %s", override.Pipeline)
+			}
+			override.Step = nil
+			override.Steps = append(steps, override.Steps...)
+		}
+		override.Steps, err = resolveStepIncludes(override.Steps, resolver, 0)
+		if err != nil {
+			return errors.Wrapf(err, "in the override of pipeline %s", override.Pipeline)
+		}
+	}
+	return nil
+}
+
+func (l *PipelineLifecycle) resolveIncludes(resolver ImportFileResolver) error {
+	if l == nil {
+		return nil
+	}
+	var err error
+	l.PreSteps, err = resolveStepIncludes(l.PreSteps, resolver, 0)
+	if err != nil {
+		return err
+	}
+	l.Steps, err = resolveStepIncludes(l.Steps, resolver, 0)
+	return err
+}
+
+func resolvePipelineIncludes(parsed *syntax.ParsedPipeline, resolver ImportFileResolver) error {
+	if parsed == nil {
+		return nil
+	}
+	for i := range parsed.Stages {
+		err := resolveStageIncludes(&parsed.Stages[i], resolver)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func resolveStageIncludes(stage *syntax.Stage, resolver ImportFileResolver) error {
+	var err error
+	stage.Steps, err = resolveStageStepIncludes(stage.Steps, resolver)
+	if err != nil {
+		return errors.Wrapf(err, "in stage %s", stage.Name)
+	}
+	for i := range stage.Stages {
+		err = resolveStageIncludes(&stage.Stages[i], resolver)
+		if err != nil {
+			return err
+		}
+	}
+	for i := range stage.Parallel {
+		err = resolveStageIncludes(&stage.Parallel[i], resolver)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// resolveStageStepIncludes resolves the includes of the steps of a stage, which are not pointers unlike those of
+// lifecycles
+func resolveStageStepIncludes(steps []syntax.Step, resolver ImportFileResolver) ([]syntax.Step, error) {
+	if len(steps) == 0 {
+		return steps, nil
+	}
+	pointers := make([]*syntax.Step, 0, len(steps))
+	for i := range steps {
+		step := steps[i]
+		if step.Loop != nil {
+			var err error
+			step.Loop.Steps, err = resolveStageStepIncludes(step.Loop.Steps, resolver)
+			if err != nil {
+				return nil, err
+			}
+		}
+		pointers = append(pointers, &step)
+	}
+	resolved, err := resolveStepIncludes(pointers, resolver, 0)
+	if err != nil {
+		return nil, err
+	}
+	answer := make([]syntax.Step, 0, len(resolved))
+	for _, step := range resolved {
+		answer = append(answer, *step)
+	}
+	return answer, nil
+}
+
+// resolveStepIncludes returns the steps with every step including a file replaced by the steps of that file
+func resolveStepIncludes(steps []*syntax.Step, resolver ImportFileResolver, depth int) ([]*syntax.Step, error) {
+	var answer []*syntax.Step
+	for _, step := range steps {
+		if step == nil {
+			continue
+		}
+		if len(step.Steps) > 0 {
+			nested, err := resolveStepIncludes(step.Steps, resolver, depth)
+			if err != nil {
+				return nil, err
+			}
+			step.Steps = nested
+		}
+		if step.Include == nil {
+			answer = append(answer, step)
+			continue
+		}
+		include := step.Include
+		if depth >= maxIncludeDepth {
+			return nil, fmt.Errorf("too many nested includes when including %s from %s, there may be an include cycle", include.File, include.Import)
+		}
+		if include.Import == "" || include.File == "" {
+			return nil, fmt.Errorf("the include of step %s must specify both an import and a file", step.Name)
+		}
+		fileName := ""
+		if resolver != nil {
+			var err error
+			fileName, err = resolver(&ImportFile{Import: include.Import, File: include.File})
+			if err != nil {
+				return nil, errors.Wrapf(err, "resolving %s from %s", include.File, include.Import)
+			}
+		}
+		if fileName == "" {
+			return nil, fmt.Errorf("cannot include %s from %s as there is no import called %s", include.File, include.Import, include.Import)
+		}
+		fragment, err := LoadPipelineFragment(fileName)
+		if err != nil {
+			return nil, err
+		}
+		included, err := resolveStepIncludes(fragment.Steps, resolver, depth+1)
+		if err != nil {
+			return nil, errors.Wrapf(err, "in %s from %s", include.File, include.Import)
+		}
+		answer = append(answer, included...)
+	}
+	return answer, nil
+}
@@ -122,6 +122,9 @@ type PipelineConfig struct {
 	Environment      string            `json:"environment,omitempty"`
 	Pipelines        Pipelines         `json:"pipelines,omitempty"`
 	ContainerOptions *corev1.Container `json:"containerOptions,omitempty"`
+	Caches           []*syntax.Cache   `json:"caches,omitempty"`
+	Imports          []*Module         `json:"imports,omitempty"`
+	Secrets          []*syntax.Secret  `json:"secrets,omitempty"`
 }
 
 // CreateJenkinsfileArguments contains the arguents to generate a Jenkinsfiles dynamically
@@ -628,6 +631,9 @@ func (c *PipelineConfig) ExtendPipeline(base *PipelineConfig, clearContainer boo
 		return err
 	}
 	c.ContainerOptions = mergedContainer
+	c.Caches = extendCaches(c.Caches, base.Caches)
+	c.Imports = extendImports(c.Imports, base.Imports)
+	c.Secrets = extendSecrets(c.Secrets, base.Secrets)
 	base.defaultContainerAndDir()
 	c.defaultContainerAndDir()
 	c.Env = syntax.CombineEnv(c.Env, base.Env)
@@ -638,6 +644,60 @@ func (c *PipelineConfig) ExtendPipeline(base *PipelineConfig, clearContainer boo
 	return nil
 }
 
+// extendCaches returns the caches followed by the caches of the base pipeline with a different name
+func extendCaches(caches []*syntax.Cache, baseCaches []*syntax.Cache) []*syntax.Cache {
+	answer := caches
+	for _, base := range baseCaches {
+		found := false
+		for _, c := range caches {
+			if c.Name == base.Name {
+				found = true
+				break
+			}
+		}
+		if !found {
+			answer = append(answer, base)
+		}
+	}
+	return answer
+}
+
+// extendSecrets returns the secrets followed by the secrets of the base pipeline which are not already injected
+func extendSecrets(secrets []*syntax.Secret, baseSecrets []*syntax.Secret) []*syntax.Secret {
+	answer := secrets
+	for _, base := range baseSecrets {
+		found := false
+		for _, s := range secrets {
+			if s != nil && base != nil && *s == *base {
+				found = true
+				break
+			}
+		}
+		if !found {
+			answer = append(answer, base)
+		}
+	}
+	return answer
+}
+
+// extendImports returns the imports followed by the imports of the base pipeline with a different name
+func extendImports(imports []*Module, baseImports []*Module) []*Module {
+	answer := imports
+	for _, base := range baseImports {
+		found := false
+		for _, m := range imports {
+			if m.Name == base.Name {
+				found = true
+				break
+			}
+		}
+		if !found {
+			answer = append(answer, base)
+		}
+	}
+	return answer
+}
+
 func (c *PipelineConfig) defaultContainerAndDir() {
 	if c.Agent != nil {
 		c.Pipelines.defaultContainerAndDir(c.Agent.GetImage(), c.Agent.Dir)
@@ -932,6 +992,11 @@ func (c *PipelineConfig) CreatePipelineForBuildPack(args CreatePipelineArguments
 	parsed := &syntax.ParsedPipeline{
 		Stages: []syntax.Stage{*stage},
 	}
+	if len(c.Caches) > 0 {
+		parsed.Options = &syntax.RootOptions{
+			Caches: c.Caches,
+		}
+	}
 
 	// If agent.container is specified, use that for default container configuration for step images.
 	containerName := c.Agent.GetImage()
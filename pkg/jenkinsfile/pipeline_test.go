@@ -3,10 +3,16 @@
 package jenkinsfile_test
 
 import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/jenkins-x/jx/v2/pkg/jenkinsfile"
+	"github.com/jenkins-x/jx/v2/pkg/tekton/syntax"
+	"github.com/jenkins-x/jx/v2/pkg/util"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestGetLifecycleReturnsSetup(t *testing.T) {
@@ -66,3 +72,87 @@ func TestGetLifecycleReturnsError(t *testing.T) {
 	_, err := lifecycles.GetLifecycle("something-else", false)
 	assert.Error(t, err)
 }
+
+func TestResolveIncludes(t *testing.T) {
+	libraryDir, err := ioutil.TempDir("", "test-pipeline-library-")
+	require.NoError(t, err)
+	defer os.RemoveAll(libraryDir) //nolint:errcheck
+
+	files := map[string]string{
+		"steps/scan.yaml": `steps:
+- name: scan
+  command: trivy
+  args: [image, $(inputs.params.version)]
+- include:
+    import: library
+    file: steps/notify.yaml
+`,
+		"steps/notify.yaml": `steps:
+- name: notify
+  command: jx step notify
+`,
+		"steps/cycle.yaml": `steps:
+- include:
+    import: library
+    file: steps/cycle.yaml
+`,
+	}
+	for name, content := range files {
+		fileName := filepath.Join(libraryDir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(fileName), util.DefaultWritePermissions))
+		require.NoError(t, ioutil.WriteFile(fileName, []byte(content), util.DefaultWritePermissions))
+	}
+	resolver := func(importFile *jenkinsfile.ImportFile) (string, error) {
+		if importFile.Import != "library" {
+			return "", nil
+		}
+		return filepath.Join(libraryDir, importFile.File), nil
+	}
+
+	config := &jenkinsfile.PipelineConfig{
+		Pipelines: jenkinsfile.Pipelines{
+			Release: &jenkinsfile.PipelineLifecycles{
+				Build: &jenkinsfile.PipelineLifecycle{
+					Steps: []*syntax.Step{
+						{Name: "build", Command: "make"},
+						{Include: &syntax.Include{Import: "library", File: "steps/scan.yaml"}},
+					},
+				},
+			},
+			PullRequest: &jenkinsfile.PipelineLifecycles{
+				Pipeline: &syntax.ParsedPipeline{
+					Stages: []syntax.Stage{{
+						Name: "ci",
+						Steps: []syntax.Step{
+							{Include: &syntax.Include{Import: "library", File: "steps/notify.yaml"}},
+							{Name: "test", Command: "make", Arguments: []string{"test"}},
+						},
+					}},
+				},
+			},
+		},
+	}
+	err = config.ResolveIncludes(resolver)
+	require.NoError(t, err)
+
+	var names []string
+	for _, step := range config.Pipelines.Release.Build.Steps {
+		names = append(names, step.Name)
+	}
+	assert.Equal(t, []string{"build", "scan", "notify"}, names)
+	assert.Equal(t, []string{"image", "$(inputs.params.version)"}, config.Pipelines.Release.Build.Steps[1].Arguments)
+
+	names = nil
+	for _, step := range config.Pipelines.PullRequest.Pipeline.Stages[0].Steps {
+		names = append(names, step.Name)
+	}
+	assert.Equal(t, []string{"notify", "test"}, names)
+
+	config.Pipelines.Release.Build.Steps = []*syntax.Step{{Include: &syntax.Include{Import: "other", File: "steps/scan.yaml"}}}
+	err = config.ResolveIncludes(resolver)
+	assert.Error(t, err, "an include of an unknown import should fail")
+
+	config.Pipelines.Release.Build.Steps = []*syntax.Step{{Include: &syntax.Include{Import: "library", File: "steps/cycle.yaml"}}}
+	err = config.ResolveIncludes(resolver)
+	assert.Error(t, err, "an include cycle should fail")
+}
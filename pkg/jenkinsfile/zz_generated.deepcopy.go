@@ -131,6 +131,39 @@ func (in *PipelineConfig) DeepCopyInto(out *PipelineConfig) {
 		*out = new(v1.Container)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Caches != nil {
+		in, out := &in.Caches, &out.Caches
+		*out = make([]*syntax.Cache, len(*in))
+		for i := range *in {
+			if (*in)[i] != nil {
+				in, out := &(*in)[i], &(*out)[i]
+				*out = new(syntax.Cache)
+				(*in).DeepCopyInto(*out)
+			}
+		}
+	}
+	if in.Imports != nil {
+		in, out := &in.Imports, &out.Imports
+		*out = make([]*Module, len(*in))
+		for i := range *in {
+			if (*in)[i] != nil {
+				in, out := &(*in)[i], &(*out)[i]
+				*out = new(Module)
+				**out = **in
+			}
+		}
+	}
+	if in.Secrets != nil {
+		in, out := &in.Secrets, &out.Secrets
+		*out = make([]*syntax.Secret, len(*in))
+		for i := range *in {
+			if (*in)[i] != nil {
+				in, out := &(*in)[i], &(*out)[i]
+				*out = new(syntax.Secret)
+				**out = **in
+			}
+		}
+	}
 	return
 }
 
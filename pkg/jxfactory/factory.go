@@ -85,9 +85,14 @@ func (f *factory) CreateKubeConfig() (*rest.Config, error) {
 	kubeConfigEnv := os.Getenv("KUBECONFIG")
 	if kubeConfigEnv != "" {
 		pathList := filepath.SplitList(kubeConfigEnv)
-		return clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+		config, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
 			&clientcmd.ClientConfigLoadingRules{Precedence: pathList},
 			&clientcmd.ConfigOverrides{ClusterInfo: clientcmdapi.Cluster{Server: masterURL}}).ClientConfig()
+		if err != nil {
+			return nil, err
+		}
+		kube.ConfigureRateLimits(config)
+		return config, nil
 	}
 	kubeconfig := f.createKubeConfigText()
 	var config *rest.Config
@@ -109,6 +114,8 @@ func (f *factory) CreateKubeConfig() (*rest.Config, error) {
 		}
 	}
 
+	kube.ConfigureRateLimits(config)
+
 	if config != nil && f.bearerToken != "" {
 		config.BearerToken = f.bearerToken
 		return config, nil
@@ -1,7 +1,13 @@
 package kube
 
 import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/pkg/errors"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
 )
 
@@ -16,3 +22,49 @@ func CreateKubeClient(kubeconfig *string) (kubernetes.Interface, error) {
 	// create the clientset
 	return kubernetes.NewForConfig(config)
 }
+
+// CreateKubeClientForContext creates a new Kubernetes client for the given context in the kube config
+func CreateKubeClientForContext(context string) (kubernetes.Interface, error) {
+	config, err := KubeConfigForContext(context)
+	if err != nil {
+		return nil, err
+	}
+	return kubernetes.NewForConfig(config)
+}
+
+// KubeConfigForContext returns the client configuration of the given context in the kube config
+func KubeConfigForContext(context string) (*rest.Config, error) {
+	config, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+		clientcmd.NewDefaultClientConfigLoadingRules(),
+		&clientcmd.ConfigOverrides{CurrentContext: context}).ClientConfig()
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to load the kube config for context %s", context)
+	}
+	ConfigureRateLimits(config)
+	return config, nil
+}
+
+// WriteKubeConfigForContext writes a copy of the kube config whose current context is the given context to a temporary
+// file so that commands such as helm and kubectl run against the cluster of the context, returning the file name
+func WriteKubeConfigForContext(context string) (string, error) {
+	config, err := clientcmd.NewDefaultClientConfigLoadingRules().Load()
+	if err != nil {
+		return "", errors.Wrap(err, "failed to load the kube config")
+	}
+	if config.Contexts[context] == nil {
+		return "", fmt.Errorf("no context %s in the kube config", context)
+	}
+	newConfig := *config
+	newConfig.CurrentContext = context
+
+	tmpDirName, err := ioutil.TempDir("", ".jx-kubeconfig-")
+	if err != nil {
+		return "", err
+	}
+	fileName := filepath.Join(tmpDirName, "config")
+	err = clientcmd.WriteToFile(newConfig, fileName)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to write the kube config for context %s", context)
+	}
+	return fileName, nil
+}
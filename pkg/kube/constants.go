@@ -100,6 +100,9 @@ const (
 	// SecretJenkinsReleaseGPG the GPG secrets for doing releases
 	SecretJenkinsReleaseGPG = "jenkins-release-gpg"
 
+	// SecretCosign the cosign key pair release pipelines sign images with
+	SecretCosign = "jx-cosign"
+
 	// SecretJenkinsPipelinePrefix prefix for a jenkins pipeline secret name
 	SecretJenkinsPipelinePrefix = "jx-pipeline-"
 
@@ -290,9 +293,31 @@ const (
 	// AnnotationIsDefaultStorageClass used to indicate a storageclass is default
 	AnnotationIsDefaultStorageClass = "storageclass.kubernetes.io/is-default-class"
 
+	// AnnotationRestartedAt is added to the pod templates of deployments when they are restarted, such as after rotating
+	// the secrets they use
+	AnnotationRestartedAt = "jenkins.io/restartedAt"
+
+	// AnnotationRotatedAt is added to secrets when their values are rotated
+	AnnotationRotatedAt = "jenkins.io/rotatedAt"
+
 	// AnnotationReleaseName is the name of the annotation that stores the release name in the preview environment
 	AnnotationReleaseName = "jenkins.io/chart-release"
 
+	// AnnotationPreviewTTL the maximum age of a preview environment after which it is garbage collected
+	AnnotationPreviewTTL = "jenkins.io/preview-ttl"
+
+	// AnnotationPreviewIdleTimeout how long a preview environment is kept without being updated with new commits
+	AnnotationPreviewIdleTimeout = "jenkins.io/preview-idle-timeout"
+
+	// AnnotationPreviewUpdatedAt the time a preview environment was last updated with a new commit
+	AnnotationPreviewUpdatedAt = "jenkins.io/preview-updated-at"
+
+	// AnnotationPreviewImage the image deployed to a preview environment
+	AnnotationPreviewImage = "jenkins.io/preview-image"
+
+	// AnnotationPreviewFixtures the fixtures provisioned for a preview environment which are destroyed with it
+	AnnotationPreviewFixtures = "jenkins.io/preview-fixtures"
+
 	// SecretDataUsername the username in a Secret/Credentials
 	SecretDataUsername = "username"
 
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/jenkins-x/jx/v2/pkg/log"
+	"github.com/pkg/errors"
 	appsv1 "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -184,3 +185,72 @@ func GetDeploymentPods(client kubernetes.Interface, name, namespace string) ([]v
 
 	return pods.Items, err
 }
+
+// RestartDeploymentsUsingSecrets restarts the deployments in the namespace whose pods use any of the secrets, as
+// volumes or environment variables, so that they pick up the new values of the secrets. The names of the restarted
+// deployments are returned
+func RestartDeploymentsUsingSecrets(client kubernetes.Interface, ns string, secretNames []string) ([]string, error) {
+	restarted := []string{}
+	if len(secretNames) == 0 {
+		return restarted, nil
+	}
+	list, err := client.AppsV1().Deployments(ns).List(metav1.ListOptions{})
+	if err != nil {
+		return restarted, errors.Wrapf(err, "listing deployments in namespace %s", ns)
+	}
+	restartedAt := time.Now().Format(time.RFC3339)
+	for i := range list.Items {
+		d := &list.Items[i]
+		if !podSpecUsesSecrets(&d.Spec.Template.Spec, secretNames) {
+			continue
+		}
+		if d.Spec.Template.Annotations == nil {
+			d.Spec.Template.Annotations = map[string]string{}
+		}
+		d.Spec.Template.Annotations[AnnotationRestartedAt] = restartedAt
+		_, err = client.AppsV1().Deployments(ns).Update(d)
+		if err != nil {
+			return restarted, errors.Wrapf(err, "restarting deployment %s in namespace %s", d.Name, ns)
+		}
+		restarted = append(restarted, d.Name)
+	}
+	return restarted, nil
+}
+
+// podSpecUsesSecrets returns true if the pod spec mounts or references any of the secrets
+func podSpecUsesSecrets(spec *v1.PodSpec, secretNames []string) bool {
+	uses := func(name string) bool {
+		for _, secretName := range secretNames {
+			if name == secretName {
+				return true
+			}
+		}
+		return false
+	}
+	for _, volume := range spec.Volumes {
+		if volume.Secret != nil && uses(volume.Secret.SecretName) {
+			return true
+		}
+		if volume.Projected != nil {
+			for _, source := range volume.Projected.Sources {
+				if source.Secret != nil && uses(source.Secret.Name) {
+					return true
+				}
+			}
+		}
+	}
+	containers := append(append([]v1.Container{}, spec.InitContainers...), spec.Containers...)
+	for _, c := range containers {
+		for _, env := range c.Env {
+			if env.ValueFrom != nil && env.ValueFrom.SecretKeyRef != nil && uses(env.ValueFrom.SecretKeyRef.Name) {
+				return true
+			}
+		}
+		for _, envFrom := range c.EnvFrom {
+			if envFrom.SecretRef != nil && uses(envFrom.SecretRef.Name) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -88,3 +88,60 @@ func TestWaitForDeploymentToBeReady(t *testing.T) {
 	assert.NoError(t, err, "Should not error")
 
 }
+
+func TestRestartDeploymentsUsingSecrets(t *testing.T) {
+	t.Parallel()
+
+	deployment := func(name string, spec v1.PodSpec) *appsv1.Deployment {
+		return &appsv1.Deployment{
+			ObjectMeta: meta_v1.ObjectMeta{
+				Name:      name,
+				Namespace: "jx",
+			},
+			Spec: appsv1.DeploymentSpec{
+				Template: v1.PodTemplateSpec{
+					Spec: spec,
+				},
+			},
+		}
+	}
+	client := kube_mocks.NewSimpleClientset(
+		deployment("hook", v1.PodSpec{
+			Containers: []v1.Container{{
+				Name: "hook",
+				Env: []v1.EnvVar{{
+					Name: "HMAC_TOKEN",
+					ValueFrom: &v1.EnvVarSource{
+						SecretKeyRef: &v1.SecretKeySelector{
+							LocalObjectReference: v1.LocalObjectReference{Name: "lighthouse-hmac-token"},
+							Key:                  "hmac",
+						},
+					},
+				}},
+			}},
+		}),
+		deployment("foghorn", v1.PodSpec{
+			Volumes: []v1.Volume{{
+				Name: "git",
+				VolumeSource: v1.VolumeSource{
+					Secret: &v1.SecretVolumeSource{SecretName: "jx-pipeline-git-github-github"},
+				},
+			}},
+		}),
+		deployment("chartmuseum", v1.PodSpec{
+			Containers: []v1.Container{{Name: "chartmuseum"}},
+		}),
+	)
+
+	restarted, err := kube.RestartDeploymentsUsingSecrets(client, "jx", []string{"lighthouse-hmac-token", "jx-pipeline-git-github-github"})
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"hook", "foghorn"}, restarted)
+
+	hook, err := client.AppsV1().Deployments("jx").Get("hook", meta_v1.GetOptions{})
+	assert.NoError(t, err)
+	assert.NotEmpty(t, hook.Spec.Template.Annotations[kube.AnnotationRestartedAt])
+
+	chartmuseum, err := client.AppsV1().Deployments("jx").Get("chartmuseum", meta_v1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Empty(t, chartmuseum.Spec.Template.Annotations)
+}
@@ -18,6 +18,7 @@ import (
 	v1 "github.com/jenkins-x/jx/v2/pkg/apis/jenkins.io/v1"
 	"github.com/jenkins-x/jx/v2/pkg/auth"
 	"github.com/jenkins-x/jx/v2/pkg/client/clientset/versioned"
+	jxlisters "github.com/jenkins-x/jx/v2/pkg/client/listers/jenkins.io/v1"
 	"github.com/jenkins-x/jx/v2/pkg/config"
 	"github.com/jenkins-x/jx/v2/pkg/gits"
 	"github.com/jenkins-x/jx/v2/pkg/log"
@@ -25,6 +26,7 @@ import (
 	survey "gopkg.in/AlecAivazis/survey.v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/client-go/kubernetes"
 )
 
@@ -398,7 +400,7 @@ func DoCreateEnvironmentGitRepo(batchMode bool, authConfigSvc auth.ConfigService
 		if err != nil {
 			return nil, nil, errors.Wrap(err, "creating push URL for environment repo")
 		}
-		err = git.Clone(pushGitURL, dir)
+		err = git.CloneWithOptions(pushGitURL, dir, gits.PartialCloneOptions())
 		if err != nil {
 			return nil, nil, errors.Wrapf(err, "cloning environment from %q into %q", pushGitURL, dir)
 		}
@@ -485,7 +487,7 @@ func DoCreateEnvironmentGitRepo(batchMode bool, authConfigSvc auth.ConfigService
 			if err != nil {
 				return nil, nil, errors.Wrap(err, "create unique directory for environment fork clone")
 			}
-			err = git.Clone(forkEnvGitURL, dir)
+			err = git.CloneWithOptions(forkEnvGitURL, dir, gits.PartialCloneOptions())
 			if err != nil {
 				return nil, nil, errors.Wrapf(err, "cloning the forked environment %q into %q", forkEnvGitURL, dir)
 			}
@@ -1071,6 +1073,29 @@ func GetDevEnvironment(jxClient versioned.Interface, ns string) (*v1.Environment
 		ns, name, selector, len(envList.Items), envList.Items)
 }
 
+// GetDevEnvironmentFromLister returns the current development environment from the cache of an Environment lister
+// rather than the API server, see GetDevEnvironment. The returned Environment is a copy which can be modified
+func GetDevEnvironmentFromLister(lister jxlisters.EnvironmentNamespaceLister) (*v1.Environment, error) {
+	name := LabelValueDevEnvironment
+	answer, err := lister.Get(name)
+	if err == nil {
+		return answer.DeepCopy(), nil
+	}
+	selector := labels.SelectorFromSet(labels.Set{LabelEnvironment: LabelValueDevEnvironment})
+	envs, err := lister.List(selector)
+	if err != nil {
+		return nil, err
+	}
+	if len(envs) == 1 {
+		return envs[0].DeepCopy(), nil
+	}
+	if len(envs) == 0 {
+		return nil, nil
+	}
+	return nil, fmt.Errorf("Error fetching dev environment resource definition, No Environment called: %s or with selector: %s found %d entries",
+		name, selector.String(), len(envs))
+}
+
 // GetPreviewEnvironmentReleaseName returns the (helm) release name for the given (preview) environment
 // or the empty string is the environment is not a preview environment, or has no release name associated with it
 func GetPreviewEnvironmentReleaseName(env *v1.Environment) string {
@@ -0,0 +1,208 @@
+package kube
+
+import (
+	"fmt"
+
+	"github.com/jenkins-x/jx/v2/pkg/config"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// GovernanceResourceName the name of the ResourceQuota, LimitRange and NetworkPolicy created from the
+	// governance section of the requirements
+	GovernanceResourceName = "jx-governance"
+
+	// LabelPodSecurityEnforce the namespace label used to enforce a Pod Security Standard level
+	LabelPodSecurityEnforce = "pod-security.kubernetes.io/enforce"
+)
+
+// ApplyNamespacePolicy creates or updates the ResourceQuota, LimitRange, NetworkPolicy and Pod Security label of the
+// namespace from the given policy. Any of these resources which are no longer specified by the policy are removed.
+func ApplyNamespacePolicy(kubeClient kubernetes.Interface, ns string, policy config.NamespacePolicyConfig) error {
+	err := applyResourceQuota(kubeClient, ns, policy.ResourceQuota)
+	if err != nil {
+		return errors.Wrapf(err, "applying the ResourceQuota in namespace %s", ns)
+	}
+	err = applyLimitRange(kubeClient, ns, policy.LimitRange)
+	if err != nil {
+		return errors.Wrapf(err, "applying the LimitRange in namespace %s", ns)
+	}
+	err = applyNetworkPolicy(kubeClient, ns, policy.NetworkPolicy)
+	if err != nil {
+		return errors.Wrapf(err, "applying the NetworkPolicy in namespace %s", ns)
+	}
+	err = applyPodSecurity(kubeClient, ns, policy.PodSecurity)
+	if err != nil {
+		return errors.Wrapf(err, "applying the pod security level in namespace %s", ns)
+	}
+	return nil
+}
+
+func applyResourceQuota(kubeClient kubernetes.Interface, ns string, hard map[string]string) error {
+	quotas := kubeClient.CoreV1().ResourceQuotas(ns)
+	existing, err := quotas.Get(GovernanceResourceName, metav1.GetOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	found := err == nil
+	if len(hard) == 0 {
+		if found {
+			return quotas.Delete(GovernanceResourceName, &metav1.DeleteOptions{})
+		}
+		return nil
+	}
+	resources, err := toResourceList(hard)
+	if err != nil {
+		return err
+	}
+	if found {
+		existing.Spec.Hard = resources
+		_, err = quotas.Update(existing)
+		return err
+	}
+	_, err = quotas.Create(&corev1.ResourceQuota{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: GovernanceResourceName,
+		},
+		Spec: corev1.ResourceQuotaSpec{
+			Hard: resources,
+		},
+	})
+	return err
+}
+
+func applyLimitRange(kubeClient kubernetes.Interface, ns string, limits *config.LimitRangeConfig) error {
+	limitRanges := kubeClient.CoreV1().LimitRanges(ns)
+	existing, err := limitRanges.Get(GovernanceResourceName, metav1.GetOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	found := err == nil
+	if limits == nil {
+		if found {
+			return limitRanges.Delete(GovernanceResourceName, &metav1.DeleteOptions{})
+		}
+		return nil
+	}
+	item := corev1.LimitRangeItem{
+		Type: corev1.LimitTypeContainer,
+	}
+	item.DefaultRequest, err = toResourceList(limits.DefaultRequests)
+	if err != nil {
+		return err
+	}
+	item.Default, err = toResourceList(limits.DefaultLimits)
+	if err != nil {
+		return err
+	}
+	item.Max, err = toResourceList(limits.Max)
+	if err != nil {
+		return err
+	}
+	spec := corev1.LimitRangeSpec{
+		Limits: []corev1.LimitRangeItem{item},
+	}
+	if found {
+		existing.Spec = spec
+		_, err = limitRanges.Update(existing)
+		return err
+	}
+	_, err = limitRanges.Create(&corev1.LimitRange{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: GovernanceResourceName,
+		},
+		Spec: spec,
+	})
+	return err
+}
+
+func applyNetworkPolicy(kubeClient kubernetes.Interface, ns string, policyType config.NetworkPolicyType) error {
+	policies := kubeClient.NetworkingV1().NetworkPolicies(ns)
+	existing, err := policies.Get(GovernanceResourceName, metav1.GetOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	found := err == nil
+
+	spec := networkingv1.NetworkPolicySpec{
+		PodSelector: metav1.LabelSelector{},
+		PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress},
+	}
+	switch policyType {
+	case "", config.NetworkPolicyTypeNone:
+		if found {
+			return policies.Delete(GovernanceResourceName, &metav1.DeleteOptions{})
+		}
+		return nil
+	case config.NetworkPolicyTypeDenyAll:
+		// no ingress rules denies all ingress traffic
+	case config.NetworkPolicyTypeSameNamespace:
+		spec.Ingress = []networkingv1.NetworkPolicyIngressRule{
+			{
+				From: []networkingv1.NetworkPolicyPeer{
+					{
+						PodSelector: &metav1.LabelSelector{},
+					},
+				},
+			},
+		}
+	default:
+		return fmt.Errorf("unsupported network policy %s", policyType)
+	}
+
+	if found {
+		existing.Spec = spec
+		_, err = policies.Update(existing)
+		return err
+	}
+	_, err = policies.Create(&networkingv1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: GovernanceResourceName,
+		},
+		Spec: spec,
+	})
+	return err
+}
+
+func applyPodSecurity(kubeClient kubernetes.Interface, ns string, level string) error {
+	namespaces := kubeClient.CoreV1().Namespaces()
+	namespace, err := namespaces.Get(ns, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	current := namespace.Labels[LabelPodSecurityEnforce]
+	if current == level {
+		return nil
+	}
+	if level == "" {
+		delete(namespace.Labels, LabelPodSecurityEnforce)
+	} else {
+		if namespace.Labels == nil {
+			namespace.Labels = map[string]string{}
+		}
+		namespace.Labels[LabelPodSecurityEnforce] = level
+	}
+	_, err = namespaces.Update(namespace)
+	return err
+}
+
+func toResourceList(values map[string]string) (corev1.ResourceList, error) {
+	if len(values) == 0 {
+		return nil, nil
+	}
+	answer := corev1.ResourceList{}
+	for k, v := range values {
+		q, err := resource.ParseQuantity(v)
+		if err != nil {
+			return nil, errors.Wrapf(err, "parsing the quantity %s of resource %s", v, k)
+		}
+		answer[corev1.ResourceName(k)] = q
+	}
+	return answer, nil
+}
@@ -0,0 +1,74 @@
+// +build unit
+
+package kube_test
+
+import (
+	"testing"
+
+	"github.com/jenkins-x/jx/v2/pkg/config"
+	"github.com/jenkins-x/jx/v2/pkg/kube"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestApplyNamespacePolicy(t *testing.T) {
+	t.Parallel()
+
+	ns := "jx-staging"
+	kubeClient := fake.NewSimpleClientset(&corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: ns,
+		},
+	})
+
+	policy := config.NamespacePolicyConfig{
+		ResourceQuota: map[string]string{"requests.cpu": "4"},
+		LimitRange: &config.LimitRangeConfig{
+			DefaultLimits: map[string]string{"memory": "512Mi"},
+		},
+		NetworkPolicy: config.NetworkPolicyTypeSameNamespace,
+		PodSecurity:   "baseline",
+	}
+	err := kube.ApplyNamespacePolicy(kubeClient, ns, policy)
+	require.NoError(t, err)
+
+	quota, err := kubeClient.CoreV1().ResourceQuotas(ns).Get(kube.GovernanceResourceName, metav1.GetOptions{})
+	require.NoError(t, err)
+	cpu := quota.Spec.Hard[corev1.ResourceName("requests.cpu")]
+	assert.Equal(t, "4", cpu.String())
+
+	limitRange, err := kubeClient.CoreV1().LimitRanges(ns).Get(kube.GovernanceResourceName, metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Len(t, limitRange.Spec.Limits, 1)
+	memory := limitRange.Spec.Limits[0].Default[corev1.ResourceMemory]
+	assert.Equal(t, "512Mi", memory.String())
+
+	networkPolicy, err := kubeClient.NetworkingV1().NetworkPolicies(ns).Get(kube.GovernanceResourceName, metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Len(t, networkPolicy.Spec.Ingress, 1)
+
+	namespace, err := kubeClient.CoreV1().Namespaces().Get(ns, metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "baseline", namespace.Labels[kube.LabelPodSecurityEnforce])
+
+	// removing the policies should remove the resources
+	err = kube.ApplyNamespacePolicy(kubeClient, ns, config.NamespacePolicyConfig{NetworkPolicy: config.NetworkPolicyTypeDenyAll})
+	require.NoError(t, err)
+
+	_, err = kubeClient.CoreV1().ResourceQuotas(ns).Get(kube.GovernanceResourceName, metav1.GetOptions{})
+	assert.True(t, apierrors.IsNotFound(err))
+	_, err = kubeClient.CoreV1().LimitRanges(ns).Get(kube.GovernanceResourceName, metav1.GetOptions{})
+	assert.True(t, apierrors.IsNotFound(err))
+
+	networkPolicy, err = kubeClient.NetworkingV1().NetworkPolicies(ns).Get(kube.GovernanceResourceName, metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Empty(t, networkPolicy.Spec.Ingress)
+
+	namespace, err = kubeClient.CoreV1().Namespaces().Get(ns, metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.NotContains(t, namespace.Labels, kube.LabelPodSecurityEnforce)
+}
@@ -0,0 +1,80 @@
+package kube
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jenkins-x/jx/v2/pkg/client/clientset/versioned"
+	jxinformers "github.com/jenkins-x/jx/v2/pkg/client/informers/externalversions"
+	jxlisters "github.com/jenkins-x/jx/v2/pkg/client/listers/jenkins.io/v1"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+const (
+	// informerResync how often the informers replay the resources they cache to their handlers
+	informerResync = time.Minute * 10
+
+	// informerSyncTimeout how long to wait for the initial list of an informer before giving up on its cache
+	informerSyncTimeout = time.Minute
+
+	// podInformerSyncTimeout how long to wait for the initial list of pods. Pods are watched by interactive commands,
+	// such as following the logs of a build, which fall back to polling so should not keep the user waiting
+	podInformerSyncTimeout = time.Second * 5
+)
+
+// NewPodLister starts an informer watching the pods of the namespace matching the label selector, which may be blank,
+// and returns a lister reading the pods from its cache once the initial list has completed. This avoids polling the
+// API server for pods in loops. The informer runs until the stop channel is closed, even if an error is returned.
+// The pods of the lister are shared with the cache so must not be modified
+func NewPodLister(kubeClient kubernetes.Interface, ns string, selector string, stop <-chan struct{}) (corelisters.PodNamespaceLister, error) {
+	factory := informers.NewSharedInformerFactoryWithOptions(kubeClient, informerResync, informers.WithNamespace(ns),
+		informers.WithTweakListOptions(func(options *metav1.ListOptions) {
+			options.LabelSelector = selector
+		}))
+	informer := factory.Core().V1().Pods()
+	synced := informer.Informer().HasSynced
+	factory.Start(stop)
+	err := waitForCacheSync(stop, synced, podInformerSyncTimeout)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to load the pods of namespace %s", ns)
+	}
+	return informer.Lister().Pods(ns), nil
+}
+
+// NewEnvironmentLister starts an informer watching the Environments of the namespace and returns a lister reading them
+// from its cache once the initial list has completed. The informer runs until the stop channel is closed, even if an
+// error is returned. The Environments of the lister are shared with the cache so must not be modified
+func NewEnvironmentLister(jxClient versioned.Interface, ns string, stop <-chan struct{}) (jxlisters.EnvironmentNamespaceLister, error) {
+	factory := jxinformers.NewSharedInformerFactoryWithOptions(jxClient, informerResync, jxinformers.WithNamespace(ns))
+	informer := factory.Jenkins().V1().Environments()
+	synced := informer.Informer().HasSynced
+	factory.Start(stop)
+	err := waitForCacheSync(stop, synced, informerSyncTimeout)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to load the Environments of namespace %s", ns)
+	}
+	return informer.Lister().Environments(ns), nil
+}
+
+// waitForCacheSync waits for the initial list of an informer, failing if it takes longer than the timeout, such as when
+// the user cannot watch the resources, or if the informer is stopped first
+func waitForCacheSync(stop <-chan struct{}, synced cache.InformerSynced, timeout time.Duration) error {
+	err := wait.PollImmediate(time.Millisecond*100, timeout, func() (bool, error) {
+		select {
+		case <-stop:
+			return false, fmt.Errorf("the informer was stopped")
+		default:
+			return synced(), nil
+		}
+	})
+	if err == wait.ErrWaitTimeout {
+		return fmt.Errorf("the informer did not sync within %s", timeout.String())
+	}
+	return err
+}
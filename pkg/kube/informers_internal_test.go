@@ -0,0 +1,25 @@
+// +build unit
+
+package kube
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWaitForCacheSyncTimesOut(t *testing.T) {
+	t.Parallel()
+
+	stop := make(chan struct{})
+	defer close(stop)
+
+	start := time.Now()
+	err := waitForCacheSync(stop, func() bool { return false }, time.Millisecond*300)
+	assert.Error(t, err, "a cache which never syncs should time out")
+	assert.True(t, time.Since(start) < informerSyncTimeout, "the given timeout should be used")
+
+	err = waitForCacheSync(stop, func() bool { return true }, time.Millisecond*300)
+	assert.NoError(t, err)
+}
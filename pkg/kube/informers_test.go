@@ -0,0 +1,74 @@
+// +build unit
+
+package kube_test
+
+import (
+	"testing"
+
+	v1 "github.com/jenkins-x/jx/v2/pkg/apis/jenkins.io/v1"
+	jxfake "github.com/jenkins-x/jx/v2/pkg/client/clientset/versioned/fake"
+	"github.com/jenkins-x/jx/v2/pkg/kube"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestNewPodLister(t *testing.T) {
+	t.Parallel()
+
+	kubeClient := fake.NewSimpleClientset(
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "build", Namespace: "jx", Labels: map[string]string{"tekton.dev/pipelineRun": "run-1"}}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "jx"}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "staging", Namespace: "jx-staging", Labels: map[string]string{"tekton.dev/pipelineRun": "run-2"}}},
+	)
+	stop := make(chan struct{})
+	defer close(stop)
+
+	lister, err := kube.NewPodLister(kubeClient, "jx", "tekton.dev/pipelineRun", stop)
+	require.NoError(t, err)
+
+	pod, err := lister.Get("build")
+	require.NoError(t, err)
+	assert.Equal(t, "run-1", pod.Labels["tekton.dev/pipelineRun"])
+
+	_, err = lister.Get("other")
+	assert.Error(t, err, "cached a pod not matching the selector")
+	_, err = lister.Get("staging")
+	assert.Error(t, err, "cached a pod of another namespace")
+}
+
+func TestGetDevEnvironmentFromLister(t *testing.T) {
+	t.Parallel()
+
+	devEnv := kube.CreateDefaultDevEnvironment("jx")
+	devEnv.Namespace = "jx"
+	devEnv.Spec.TeamSettings.VersionStreamRef = "v1.0.0"
+	jxClient := jxfake.NewSimpleClientset(
+		devEnv,
+		&v1.Environment{ObjectMeta: metav1.ObjectMeta{Name: "staging", Namespace: "jx"}},
+	)
+	stop := make(chan struct{})
+	defer close(stop)
+
+	lister, err := kube.NewEnvironmentLister(jxClient, "jx", stop)
+	require.NoError(t, err)
+
+	env, err := kube.GetDevEnvironmentFromLister(lister)
+	require.NoError(t, err)
+	require.NotNil(t, env)
+	assert.Equal(t, "v1.0.0", env.Spec.TeamSettings.VersionStreamRef)
+
+	// the returned environment is a copy so modifying it does not corrupt the cache
+	env.Spec.TeamSettings.VersionStreamRef = "v2.0.0"
+	cached, err := lister.Get(env.Name)
+	require.NoError(t, err)
+	assert.Equal(t, "v1.0.0", cached.Spec.TeamSettings.VersionStreamRef)
+
+	emptyLister, err := kube.NewEnvironmentLister(jxfake.NewSimpleClientset(), "jx", stop)
+	require.NoError(t, err)
+	env, err = kube.GetDevEnvironmentFromLister(emptyLister)
+	assert.NoError(t, err)
+	assert.Nil(t, env)
+}
@@ -7,7 +7,6 @@ import (
 	v1 "github.com/jenkins-x/jx/v2/pkg/apis/jenkins.io/v1"
 	"github.com/jenkins-x/jx/v2/pkg/client/clientset/versioned"
 	"github.com/jenkins-x/jx/v2/pkg/log"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/client-go/tools/cache"
 )
@@ -17,7 +16,8 @@ type PipelineNamespaceCache struct {
 	pipelines sync.Map
 	stop      chan struct{}
 	//Flag to indicate whether the cache has done its initial load & is in sync.
-	ready bool
+	ready     bool
+	hasSynced cache.InformerSynced
 }
 
 // NewPipelineCache creates a cache of pipelines for a namespace
@@ -29,14 +29,6 @@ func NewPipelineCache(jxClient versioned.Interface, ns string) *PipelineNamespac
 		stop: make(chan struct{}),
 	}
 
-	// lets pre-populate the cache on startup as there's not yet a way to know when the informer has completed its first list operation
-	pipelines, _ := jxClient.JenkinsV1().PipelineActivities(ns).List(metav1.ListOptions{})
-	if pipelines != nil {
-		for _, pipeline := range pipelines.Items {
-			copy := pipeline
-			pipelineCache.pipelines.Store(pipeline.Name, &copy)
-		}
-	}
 	_, pipelineController := cache.NewInformer(
 		pipelineListWatch,
 		pipeline,
@@ -56,6 +48,12 @@ func NewPipelineCache(jxClient versioned.Interface, ns string) *PipelineNamespac
 
 	go pipelineController.Run(pipelineCache.stop)
 
+	// lets wait for the informer to complete its first list operation rather than listing the pipelines a second time
+	pipelineCache.hasSynced = pipelineController.HasSynced
+	err := waitForCacheSync(pipelineCache.stop, pipelineCache.hasSynced, informerSyncTimeout)
+	if err != nil {
+		log.Logger().Warnf("the pipelines of namespace %s are not loaded yet: %s", ns, err.Error())
+	}
 	pipelineCache.ready = true
 
 	return pipelineCache
@@ -63,7 +61,7 @@ func NewPipelineCache(jxClient versioned.Interface, ns string) *PipelineNamespac
 
 // Ready returns true if this cache has done its initial load and is in sync.
 func (c *PipelineNamespaceCache) Ready() bool {
-	return c.ready
+	return c.ready && c.hasSynced()
 }
 
 // Stop closes the underlying chanel processing events which stops consuming watch events
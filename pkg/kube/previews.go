@@ -0,0 +1,72 @@
+package kube
+
+import (
+	"fmt"
+	"time"
+
+	v1 "github.com/jenkins-x/jx/v2/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/v2/pkg/config"
+	"github.com/pkg/errors"
+)
+
+// SetPreviewLifecycle records the TTL and idle timeout of the preview environment and that it was updated at the given
+// time, returning true if the annotations changed
+func SetPreviewLifecycle(env *v1.Environment, ttl string, idleTimeout string, updatedAt time.Time) bool {
+	if env.Annotations == nil {
+		env.Annotations = map[string]string{}
+	}
+	changed := false
+	set := func(key string, value string) {
+		if value == "" {
+			if _, ok := env.Annotations[key]; ok {
+				delete(env.Annotations, key)
+				changed = true
+			}
+			return
+		}
+		if env.Annotations[key] != value {
+			env.Annotations[key] = value
+			changed = true
+		}
+	}
+	set(AnnotationPreviewTTL, ttl)
+	set(AnnotationPreviewIdleTimeout, idleTimeout)
+	set(AnnotationPreviewUpdatedAt, updatedAt.UTC().Format(time.RFC3339))
+	return changed
+}
+
+// PreviewExpiry returns why the preview environment has expired at the given time or an empty string if it has not.
+// The TTL and idle timeout recorded on the environment take precedence over the team defaults
+func PreviewExpiry(env *v1.Environment, now time.Time, defaults *config.PreviewsConfig) (string, error) {
+	ttlText, idleText := config.PreviewLifecycle(defaults, nil)
+	if v, ok := env.Annotations[AnnotationPreviewTTL]; ok {
+		ttlText = v
+	}
+	if v, ok := env.Annotations[AnnotationPreviewIdleTimeout]; ok {
+		idleText = v
+	}
+	ttl, err := config.ParsePreviewDuration(ttlText)
+	if err != nil {
+		return "", errors.Wrapf(err, "invalid TTL of preview environment %s", env.Name)
+	}
+	idleTimeout, err := config.ParsePreviewDuration(idleText)
+	if err != nil {
+		return "", errors.Wrapf(err, "invalid idle timeout of preview environment %s", env.Name)
+	}
+
+	created := env.CreationTimestamp.Time
+	if ttl > 0 && !created.IsZero() && now.Sub(created) > ttl {
+		return fmt.Sprintf("it is older than its TTL of %s", ttlText), nil
+	}
+	updated := created
+	if v := env.Annotations[AnnotationPreviewUpdatedAt]; v != "" {
+		updated, err = time.Parse(time.RFC3339, v)
+		if err != nil {
+			return "", errors.Wrapf(err, "invalid %s annotation of preview environment %s", AnnotationPreviewUpdatedAt, env.Name)
+		}
+	}
+	if idleTimeout > 0 && !updated.IsZero() && now.Sub(updated) > idleTimeout {
+		return fmt.Sprintf("it has had no new commits for longer than its idle timeout of %s", idleText), nil
+	}
+	return "", nil
+}
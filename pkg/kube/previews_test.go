@@ -0,0 +1,57 @@
+// +build unit
+
+package kube_test
+
+import (
+	"testing"
+	"time"
+
+	v1 "github.com/jenkins-x/jx/v2/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/v2/pkg/config"
+	"github.com/jenkins-x/jx/v2/pkg/kube"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestPreviewExpiry(t *testing.T) {
+	t.Parallel()
+
+	created := time.Date(2020, 5, 1, 12, 0, 0, 0, time.UTC)
+	env := &v1.Environment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "myorg-myapp-pr-1",
+			CreationTimestamp: metav1.NewTime(created),
+		},
+	}
+	defaults := &config.PreviewsConfig{TTL: "7d", IdleTimeout: "2d"}
+
+	reason, err := kube.PreviewExpiry(env, created.Add(24*time.Hour), defaults)
+	require.NoError(t, err)
+	assert.Empty(t, reason)
+
+	reason, err = kube.PreviewExpiry(env, created.Add(3*24*time.Hour), defaults)
+	require.NoError(t, err)
+	assert.Contains(t, reason, "idle timeout of 2d")
+
+	// new commits reset the idle timeout but not the TTL
+	changed := kube.SetPreviewLifecycle(env, "", "", created.Add(4*24*time.Hour))
+	assert.True(t, changed)
+	reason, err = kube.PreviewExpiry(env, created.Add(5*24*time.Hour), defaults)
+	require.NoError(t, err)
+	assert.Empty(t, reason)
+
+	reason, err = kube.PreviewExpiry(env, created.Add(8*24*time.Hour), defaults)
+	require.NoError(t, err)
+	assert.Contains(t, reason, "TTL of 7d")
+
+	// the lifecycle of the repository overrides the team defaults
+	kube.SetPreviewLifecycle(env, "1h", "", created)
+	reason, err = kube.PreviewExpiry(env, created.Add(2*time.Hour), defaults)
+	require.NoError(t, err)
+	assert.Contains(t, reason, "TTL of 1h")
+
+	env.Annotations[kube.AnnotationPreviewIdleTimeout] = "whenever"
+	_, err = kube.PreviewExpiry(env, created, defaults)
+	assert.Error(t, err)
+}
@@ -0,0 +1,62 @@
+package kube
+
+import (
+	"os"
+	"strconv"
+
+	"github.com/jenkins-x/jx/v2/pkg/log"
+	"k8s.io/client-go/rest"
+)
+
+const (
+	// DefaultQPS the default number of queries per second a client may make to the Kubernetes API server which is the
+	// client-go default, so jx does not put more load on shared API servers than other clients. Commands which load
+	// many resources on big clusters can be sped up by raising it with $JX_KUBE_QPS
+	DefaultQPS = 5
+
+	// DefaultBurst the default number of queries a client may make in a burst above the QPS which is the client-go
+	// default
+	DefaultBurst = 10
+
+	// KubeQPSEnvVar the environment variable overriding the queries per second to the Kubernetes API server
+	KubeQPSEnvVar = "JX_KUBE_QPS"
+
+	// KubeBurstEnvVar the environment variable overriding the burst of queries to the Kubernetes API server
+	KubeBurstEnvVar = "JX_KUBE_BURST"
+)
+
+// ConfigureRateLimits configures the client-side rate limits of the REST config from the $JX_KUBE_QPS and
+// $JX_KUBE_BURST environment variables or the defaults. Limits which are already set on the config are kept
+func ConfigureRateLimits(config *rest.Config) {
+	if config == nil {
+		return
+	}
+	if config.QPS == 0 {
+		config.QPS = float32(DefaultQPS)
+		text := os.Getenv(KubeQPSEnvVar)
+		if text != "" {
+			qps, err := strconv.ParseFloat(text, 32)
+			if err != nil || qps <= 0 {
+				log.Logger().Warnf("ignoring $%s as %s is not a positive number", KubeQPSEnvVar, text)
+			} else {
+				config.QPS = float32(qps)
+			}
+		}
+	}
+	if config.Burst == 0 {
+		config.Burst = DefaultBurst
+		text := os.Getenv(KubeBurstEnvVar)
+		if text != "" {
+			burst, err := strconv.Atoi(text)
+			if err != nil || burst <= 0 {
+				log.Logger().Warnf("ignoring $%s as %s is not a positive number", KubeBurstEnvVar, text)
+			} else {
+				config.Burst = burst
+			}
+		}
+	}
+	if float32(config.Burst) < config.QPS {
+		// the token bucket never holds more than the burst so it would cap the QPS
+		config.Burst = int(config.QPS)
+	}
+}
@@ -0,0 +1,46 @@
+// +build unit
+
+package kube_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/jenkins-x/jx/v2/pkg/kube"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/client-go/rest"
+)
+
+func TestConfigureRateLimits(t *testing.T) {
+	oldQPS := os.Getenv(kube.KubeQPSEnvVar)
+	oldBurst := os.Getenv(kube.KubeBurstEnvVar)
+	defer func() {
+		_ = os.Setenv(kube.KubeQPSEnvVar, oldQPS)
+		_ = os.Setenv(kube.KubeBurstEnvVar, oldBurst)
+	}()
+
+	testCases := []struct {
+		qps           string
+		burst         string
+		config        rest.Config
+		expectedQPS   float32
+		expectedBurst int
+	}{
+		{"", "", rest.Config{}, kube.DefaultQPS, kube.DefaultBurst},
+		{"20", "40", rest.Config{}, 20, 40},
+		{"2.5", "", rest.Config{}, 2.5, kube.DefaultBurst},
+		{"fast", "-1", rest.Config{}, kube.DefaultQPS, kube.DefaultBurst},
+		{"200", "", rest.Config{}, 200, 200},
+		{"20", "40", rest.Config{QPS: 10, Burst: 15}, 10, 15},
+	}
+	for _, tc := range testCases {
+		_ = os.Setenv(kube.KubeQPSEnvVar, tc.qps)
+		_ = os.Setenv(kube.KubeBurstEnvVar, tc.burst)
+		config := tc.config
+		kube.ConfigureRateLimits(&config)
+		assert.Equal(t, tc.expectedQPS, config.QPS, "QPS for $%s=%s", kube.KubeQPSEnvVar, tc.qps)
+		assert.Equal(t, tc.expectedBurst, config.Burst, "burst for $%s=%s", kube.KubeBurstEnvVar, tc.burst)
+	}
+
+	kube.ConfigureRateLimits(nil)
+}
@@ -0,0 +1,102 @@
+package kube
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/tools/clientcmd/api"
+)
+
+// ShellKubeConfigEnvVar the environment variable of the overlay kube config file of a shell using its own context
+const ShellKubeConfigEnvVar = "JX_SHELL_KUBECONFIG"
+
+// ShellKubeConfig returns the overlay kube config file of the current shell or blank if the shell uses the shared
+// current context. The overlay is only used while it is still the first file of KUBECONFIG
+func ShellKubeConfig() string {
+	overlay := os.Getenv(ShellKubeConfigEnvVar)
+	if overlay == "" {
+		return ""
+	}
+	paths := filepath.SplitList(os.Getenv(clientcmd.RecommendedConfigPathEnvVar))
+	if len(paths) == 0 || paths[0] != overlay {
+		return ""
+	}
+	return overlay
+}
+
+// CreateShellKubeConfig creates a new overlay kube config file making the context current for a single shell,
+// returning the name of the file
+func CreateShellKubeConfig(config *api.Config, ctxName string) (string, error) {
+	configDir, err := util.ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	dir := filepath.Join(configDir, "kubeconfigs")
+	err = os.MkdirAll(dir, util.DefaultWritePermissions)
+	if err != nil {
+		return "", errors.Wrapf(err, "creating directory %s", dir)
+	}
+	f, err := ioutil.TempFile(dir, "shell-")
+	if err != nil {
+		return "", errors.Wrapf(err, "creating the shell kube config file in %s", dir)
+	}
+	fileName := f.Name()
+	err = f.Close()
+	if err != nil {
+		return "", err
+	}
+	err = WriteShellKubeConfig(fileName, config, ctxName)
+	if err != nil {
+		return "", err
+	}
+	return fileName, nil
+}
+
+// WriteShellKubeConfig writes the overlay kube config file of a shell with a copy of the context as the current
+// context. The clusters and users still come from the shared kube config files, but as the overlay defines the
+// context and the current context changing either of them, including the namespace, only modifies the overlay
+func WriteShellKubeConfig(fileName string, config *api.Config, ctxName string) error {
+	var ctx *api.Context
+	if config != nil && config.Contexts != nil {
+		ctx = config.Contexts[ctxName]
+	}
+	if ctx == nil {
+		return fmt.Errorf("could not find Kubernetes context %s", ctxName)
+	}
+	overlay := api.NewConfig()
+	overlay.Contexts[ctxName] = ctx.DeepCopy()
+	overlay.CurrentContext = ctxName
+	err := clientcmd.WriteToFile(*overlay, fileName)
+	if err != nil {
+		return errors.Wrapf(err, "writing the shell kube config file %s", fileName)
+	}
+	return nil
+}
+
+// ShellKubeConfigPath returns the KUBECONFIG of a shell with the overlay ahead of the shared kube config files,
+// removing any overlay the shell used before
+func ShellKubeConfigPath(overlay string) string {
+	previous := os.Getenv(ShellKubeConfigEnvVar)
+	paths := []string{overlay}
+	env := os.Getenv(clientcmd.RecommendedConfigPathEnvVar)
+	if env == "" {
+		paths = append(paths, clientcmd.RecommendedHomeFile)
+	}
+	for _, path := range filepath.SplitList(env) {
+		if path != "" && path != overlay && path != previous {
+			paths = append(paths, path)
+		}
+	}
+	return strings.Join(paths, string(os.PathListSeparator))
+}
+
+// ShellExportCommand returns the command which makes a shell use the overlay kube config file when it is evaluated
+func ShellExportCommand(overlay string) string {
+	return fmt.Sprintf("export %s=\"%s\" %s=\"%s\"", clientcmd.RecommendedConfigPathEnvVar, ShellKubeConfigPath(overlay), ShellKubeConfigEnvVar, overlay)
+}
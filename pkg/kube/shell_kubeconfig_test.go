@@ -0,0 +1,77 @@
+// +build unit
+
+package kube_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jenkins-x/jx/v2/pkg/kube"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/tools/clientcmd/api"
+)
+
+func TestShellKubeConfigIsolatesContextChanges(t *testing.T) {
+	dir, err := ioutil.TempDir("", "test-shell-kubeconfig-")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	shared := api.NewConfig()
+	shared.Clusters["dev"] = &api.Cluster{Server: "https://dev.example.com"}
+	shared.Clusters["prod"] = &api.Cluster{Server: "https://prod.example.com"}
+	shared.AuthInfos["admin"] = &api.AuthInfo{Token: "token"}
+	shared.Contexts["dev"] = &api.Context{Cluster: "dev", AuthInfo: "admin", Namespace: "jx"}
+	shared.Contexts["prod"] = &api.Context{Cluster: "prod", AuthInfo: "admin", Namespace: "jx-production"}
+	shared.CurrentContext = "dev"
+	sharedFile := filepath.Join(dir, "config")
+	require.NoError(t, clientcmd.WriteToFile(*shared, sharedFile))
+
+	overlay := filepath.Join(dir, "overlay")
+	require.NoError(t, kube.WriteShellKubeConfig(overlay, shared, "prod"))
+	assert.Error(t, kube.WriteShellKubeConfig(overlay, shared, "missing"), "wrote a missing context")
+
+	oldKubeConfig := os.Getenv(clientcmd.RecommendedConfigPathEnvVar)
+	oldOverlay := os.Getenv(kube.ShellKubeConfigEnvVar)
+	defer func() {
+		_ = os.Setenv(clientcmd.RecommendedConfigPathEnvVar, oldKubeConfig)
+		_ = os.Setenv(kube.ShellKubeConfigEnvVar, oldOverlay)
+	}()
+	require.NoError(t, os.Setenv(clientcmd.RecommendedConfigPathEnvVar, sharedFile))
+	require.NoError(t, os.Setenv(kube.ShellKubeConfigEnvVar, ""))
+	assert.Equal(t, "", kube.ShellKubeConfig())
+
+	path := kube.ShellKubeConfigPath(overlay)
+	assert.Equal(t, []string{overlay, sharedFile}, filepath.SplitList(path))
+	assert.True(t, strings.HasPrefix(kube.ShellExportCommand(overlay), "export KUBECONFIG="))
+
+	require.NoError(t, os.Setenv(clientcmd.RecommendedConfigPathEnvVar, path))
+	require.NoError(t, os.Setenv(kube.ShellKubeConfigEnvVar, overlay))
+	assert.Equal(t, overlay, kube.ShellKubeConfig())
+
+	config, po, err := kube.NewKubeConfig().LoadConfig()
+	require.NoError(t, err)
+	assert.Equal(t, "prod", config.CurrentContext)
+	assert.Equal(t, "https://prod.example.com", kube.CurrentServer(config))
+
+	// changing the namespace of the shell only changes the overlay
+	config.Contexts["prod"].Namespace = "staging"
+	require.NoError(t, clientcmd.ModifyConfig(po, *config, false))
+
+	sharedConfig, err := clientcmd.LoadFromFile(sharedFile)
+	require.NoError(t, err)
+	assert.Equal(t, "dev", sharedConfig.CurrentContext)
+	assert.Equal(t, "jx-production", sharedConfig.Contexts["prod"].Namespace)
+
+	overlayConfig, err := clientcmd.LoadFromFile(overlay)
+	require.NoError(t, err)
+	assert.Equal(t, "staging", overlayConfig.Contexts["prod"].Namespace)
+
+	// an overlay which is no longer first in KUBECONFIG is not used
+	require.NoError(t, os.Setenv(clientcmd.RecommendedConfigPathEnvVar, sharedFile))
+	assert.Equal(t, "", kube.ShellKubeConfig())
+}
@@ -14,12 +14,15 @@ const (
 
 	// ClassificationReports stores test results, coverage & quality reports
 	ClassificationReports = "reports"
+
+	// ClassificationCache stores the build caches of pipelines
+	ClassificationCache = "cache"
 )
 
 var (
 	// Classifications the common classification names
 	Classifications = []string{
-		ClassificationCoverage, ClassificationTests, ClassificationLogs, ClassificationReports,
+		ClassificationCoverage, ClassificationTests, ClassificationLogs, ClassificationReports, ClassificationCache,
 	}
 
 	// ClassificationValues the classification values as a string
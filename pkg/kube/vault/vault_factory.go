@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/jenkins-x/jx/v2/pkg/vault"
@@ -134,12 +135,26 @@ func (v *VaultClientFactory) NewVaultClient(name string, namespace string, useIn
 		KubernetesAuthPath:     vault.DefaultKubernetesAuthPath,
 	}
 
-	return v.createClient(config, vaultConfig, jwt)
+	return v.createClient(config, vaultConfig, kubernetesLogin(vaultConfig, jwt))
 }
 
 // NewVaultClientForURL creates a new Vault api.Client.
 // If namespace is nil, then the default namespace of the factory will be used
 func (v *VaultClientFactory) NewVaultClientForURL(vaultConfig vault.Vault, insecureSSLWebhook bool) (*api.Client, error) {
+	if vaultConfig.UsesTokenAuth() {
+		token, err := v.getTokenFromSecret(vaultConfig)
+		if err != nil {
+			return nil, err
+		}
+		config, err := v.vaultAPIClient(vaultConfig.URL, insecureSSLWebhook)
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to create Vault api client")
+		}
+		return v.createClient(config, vaultConfig, func(*api.Client) (string, error) {
+			return token, nil
+		})
+	}
+
 	serviceAccount, err := v.kubeClient.CoreV1().ServiceAccounts(vaultConfig.Namespace).Get(vaultConfig.ServiceAccountName, meta_v1.GetOptions{})
 	if err != nil {
 		return nil, errors.Wrapf(err, "unable to get service account '%s'", vaultConfig.ServiceAccountName)
@@ -155,10 +170,20 @@ func (v *VaultClientFactory) NewVaultClientForURL(vaultConfig vault.Vault, insec
 		return nil, errors.Wrapf(err, "unable to create Vault api client")
 	}
 
-	return v.createClient(config, vaultConfig, jwt)
+	return v.createClient(config, vaultConfig, kubernetesLogin(vaultConfig, jwt))
 }
 
-func (v *VaultClientFactory) createClient(config *api.Config, vaultConfig vault.Vault, jwt string) (*api.Client, error) {
+// loginFn authenticates against Vault returning the client token
+type loginFn func(vaultClient *api.Client) (string, error)
+
+// kubernetesLogin authenticates with the Vault Kubernetes auth method using the service account token
+func kubernetesLogin(vaultConfig vault.Vault, jwt string) loginFn {
+	return func(vaultClient *api.Client) (string, error) {
+		return getTokenFromVault(vaultConfig.Role(), jwt, vaultConfig.KubernetesAuthPath, vaultClient, authRetryTimeout)
+	}
+}
+
+func (v *VaultClientFactory) createClient(config *api.Config, vaultConfig vault.Vault, login loginFn) (*api.Client, error) {
 	vaultClient, err := api.NewClient(config)
 	if err != nil {
 		return nil, errors.Wrap(err, "creating vault client")
@@ -171,7 +196,13 @@ func (v *VaultClientFactory) createClient(config *api.Config, vaultConfig vault.
 		return nil, errors.Wrap(err, "wait for vault to be initialized and unsealed")
 	}
 
-	token, err := getTokenFromVault(vaultConfig.ServiceAccountName, jwt, vaultConfig.KubernetesAuthPath, vaultClient, authRetryTimeout)
+	// the health endpoint is only available in the root namespace so the Vault Enterprise namespace is only used
+	// once vault is ready
+	if vaultConfig.EnterpriseNamespace != "" {
+		vaultClient.SetNamespace(vaultConfig.EnterpriseNamespace)
+	}
+
+	token, err := login(vaultClient)
 	if err != nil {
 		return nil, errors.Wrapf(err, "getting Vault authentication token")
 	}
@@ -229,6 +260,19 @@ func (v *VaultClientFactory) vaultAPIClient(url string, insecureSSLWebhook bool)
 	return cfg, nil
 }
 
+// getTokenFromSecret reads the Vault token from the token secret of the Vault configuration
+func (v *VaultClientFactory) getTokenFromSecret(vaultConfig vault.Vault) (string, error) {
+	secret, err := v.kubeClient.CoreV1().Secrets(vaultConfig.Namespace).Get(vaultConfig.TokenSecretName, meta_v1.GetOptions{})
+	if err != nil {
+		return "", errors.Wrapf(err, "unable to get Vault token secret '%s' in namespace '%s'", vaultConfig.TokenSecretName, vaultConfig.Namespace)
+	}
+	token := strings.TrimSpace(string(secret.Data[vault.TokenSecretKey]))
+	if token == "" {
+		return "", errors.Errorf("no %s key in Vault token secret '%s' in namespace '%s'", vault.TokenSecretKey, vaultConfig.TokenSecretName, vaultConfig.Namespace)
+	}
+	return token, nil
+}
+
 func (v *VaultClientFactory) getServiceAccountFromVault(vault *vault.Vault) (*v1.ServiceAccount, error) {
 	return v.kubeClient.CoreV1().ServiceAccounts(vault.Namespace).Get(vault.ServiceAccountName, meta_v1.GetOptions{})
 }
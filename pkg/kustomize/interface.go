@@ -5,4 +5,5 @@ type Kustomizer interface {
 	Version(extraArgs ...string) (string, error)
 	ContainsKustomizeConfig(dir string) bool
 	FindKustomizationYamlPaths(dir string) (resource []string)
+	Build(dir string) (string, error)
 }
@@ -40,6 +40,15 @@ func (k *KustomizeCLI) Version(extraArgs ...string) (string, error) {
 	return extractSemanticVersion(version)
 }
 
+// Build executes the kustomize build command for the kustomization in the given directory and returns the manifests
+func (k *KustomizeCLI) Build(dir string) (string, error) {
+	output, err := k.runKustomizeWithOutput("build", dir)
+	if err != nil {
+		return "", errors.Wrapf(err, "running kustomize build %s", dir)
+	}
+	return output, nil
+}
+
 func (k *KustomizeCLI) runKustomizeWithOutput(args ...string) (string, error) {
 	k.Runner.SetArgs(args)
 	return k.Runner.RunWithoutRetry()
@@ -0,0 +1,94 @@
+package kustomize
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/ghodss/yaml"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+)
+
+const (
+	// KustomizationFileName the name of the kustomize configuration file
+	KustomizationFileName = "kustomization.yaml"
+	// PostRenderResourcesFileName the file the manifests rendered by helm are written to in the copy of the overlay
+	PostRenderResourcesFileName = "helm-output.yaml"
+	// PostRendererFileName the name of the executable helm runs as its post renderer
+	PostRendererFileName = "kustomize-post-renderer.sh"
+)
+
+// HasOverlay returns true if the directory contains a kustomization
+func HasOverlay(dir string) (bool, error) {
+	if dir == "" {
+		return false, nil
+	}
+	return util.FileExists(filepath.Join(dir, KustomizationFileName))
+}
+
+// PostRender applies the kustomize overlay in the directory to the manifests rendered by helm. The overlay is copied
+// to a temporary directory with the manifests added to its resources so that patches, labels and images of the
+// overlay apply to them
+func PostRender(kustomizer Kustomizer, overlayDir string, manifests []byte) ([]byte, error) {
+	tmpDir, err := ioutil.TempDir("", "jx-kustomize-post-render-")
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create a temporary directory")
+	}
+	defer os.RemoveAll(tmpDir) //nolint:errcheck
+
+	err = util.CopyDir(overlayDir, tmpDir, true)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to copy the overlay %s", overlayDir)
+	}
+	err = ioutil.WriteFile(filepath.Join(tmpDir, PostRenderResourcesFileName), manifests, util.DefaultWritePermissions)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to save file %s", PostRenderResourcesFileName)
+	}
+	err = addResource(filepath.Join(tmpDir, KustomizationFileName), PostRenderResourcesFileName)
+	if err != nil {
+		return nil, err
+	}
+	output, err := kustomizer.Build(tmpDir)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(output + "\n"), nil
+}
+
+// WritePostRenderer writes an executable into dir which helm runs as its post renderer to apply the overlay by
+// invoking 'jx step helm post-render', returning the path of the executable
+func WritePostRenderer(dir string, jxBinary string, overlayDir string) (string, error) {
+	fileName := filepath.Join(dir, PostRendererFileName)
+	script := fmt.Sprintf("#!/bin/sh\nexec %q step helm post-render --dir %q\n", jxBinary, overlayDir)
+	err := ioutil.WriteFile(fileName, []byte(script), 0755)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to save file %s", fileName)
+	}
+	return fileName, nil
+}
+
+// addResource adds the resource to the resources of the kustomization file
+func addResource(fileName string, resource string) error {
+	data, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return errors.Wrapf(err, "failed to load file %s", fileName)
+	}
+	kustomization := map[string]interface{}{}
+	err = yaml.Unmarshal(data, &kustomization)
+	if err != nil {
+		return errors.Wrapf(err, "failed to unmarshal file %s", fileName)
+	}
+	resources, _ := kustomization["resources"].([]interface{})
+	kustomization["resources"] = append(resources, resource)
+	data, err = yaml.Marshal(kustomization)
+	if err != nil {
+		return errors.Wrapf(err, "failed to marshal file %s", fileName)
+	}
+	err = ioutil.WriteFile(fileName, data, util.DefaultWritePermissions)
+	if err != nil {
+		return errors.Wrapf(err, "failed to save file %s", fileName)
+	}
+	return nil
+}
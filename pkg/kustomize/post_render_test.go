@@ -0,0 +1,69 @@
+// +build unit
+
+package kustomize_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ghodss/yaml"
+	"github.com/jenkins-x/jx/v2/pkg/kustomize"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeKustomizer struct {
+	kustomize.Kustomizer
+	kustomization map[string]interface{}
+	resources     string
+}
+
+func (k *fakeKustomizer) Build(dir string) (string, error) {
+	data, err := ioutil.ReadFile(filepath.Join(dir, kustomize.KustomizationFileName))
+	if err != nil {
+		return "", err
+	}
+	err = yaml.Unmarshal(data, &k.kustomization)
+	if err != nil {
+		return "", err
+	}
+	data, err = ioutil.ReadFile(filepath.Join(dir, kustomize.PostRenderResourcesFileName))
+	if err != nil {
+		return "", err
+	}
+	k.resources = string(data)
+	return "kind: ConfigMap", nil
+}
+
+func TestPostRender(t *testing.T) {
+	t.Parallel()
+
+	overlayDir, err := ioutil.TempDir("", "test-kustomize-post-render-")
+	require.NoError(t, err)
+	defer os.RemoveAll(overlayDir)
+
+	exists, err := kustomize.HasOverlay(overlayDir)
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	kustomization := "commonLabels:\n  team: myteam\nresources:\n- extra.yaml\n"
+	err = ioutil.WriteFile(filepath.Join(overlayDir, kustomize.KustomizationFileName), []byte(kustomization), 0600)
+	require.NoError(t, err)
+	exists, err = kustomize.HasOverlay(overlayDir)
+	require.NoError(t, err)
+	assert.True(t, exists)
+
+	kustomizer := &fakeKustomizer{}
+	output, err := kustomize.PostRender(kustomizer, overlayDir, []byte("kind: Deployment"))
+	require.NoError(t, err)
+	assert.Equal(t, "kind: ConfigMap\n", string(output))
+	assert.Equal(t, "kind: Deployment", kustomizer.resources)
+	assert.Equal(t, []interface{}{"extra.yaml", kustomize.PostRenderResourcesFileName}, kustomizer.kustomization["resources"])
+	assert.Equal(t, map[string]interface{}{"team": "myteam"}, kustomizer.kustomization["commonLabels"])
+
+	data, err := ioutil.ReadFile(filepath.Join(overlayDir, kustomize.KustomizationFileName))
+	require.NoError(t, err)
+	assert.Equal(t, kustomization, string(data), "the overlay should not be modified")
+}
@@ -2,10 +2,12 @@ package logs
 
 import (
 	"bufio"
+	"bytes"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"net/url"
+	"regexp"
 	"sort"
 	"strings"
 	"sync"
@@ -17,6 +19,7 @@ import (
 	"github.com/jenkins-x/jx/v2/pkg/errorutil"
 	"github.com/jenkins-x/jx/v2/pkg/kube/naming"
 
+	"github.com/acarl005/stripansi"
 	"github.com/fatih/color"
 	"github.com/google/uuid"
 	v1 "github.com/jenkins-x/jx/v2/pkg/apis/jenkins.io/v1"
@@ -34,7 +37,9 @@ import (
 	tektonclient "github.com/tektoncd/pipeline/pkg/client/clientset/versioned"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/client-go/kubernetes"
+	corelisters "k8s.io/client-go/listers/core/v1"
 	knativeapis "knative.dev/pkg/apis"
 )
 
@@ -51,8 +56,26 @@ type TektonLogger struct {
 	errorsChannel     chan error
 	wg                *sync.WaitGroup
 	FailIfPodFails    bool
+
+	// Multiplex streams the logs of the pods of parallel stages at the same time rather than one stage after another
+	Multiplex bool
+	// Step only streams the logs of the steps with this name
+	Step string
+	// Since only streams the logs of running pods newer than this duration
+	Since time.Duration
+
+	// podLister reads the build pods from the cache of an informer while the logs of a build are streamed
+	podLister corelisters.PodNamespaceLister
 }
 
+// ErrNoBuildPods is returned when the pods of a build have been garbage collected so its logs can only be read from
+// the long term storage bucket
+var ErrNoBuildPods = errors.New("the build pods for this build have been garbage collected and the log was not found in the long term storage bucket")
+
+// logsHeaderRegex matches the line written before the logs of each container, which is used to find the stage and step
+// of the lines of stored logs
+var logsHeaderRegex = regexp.MustCompile(`^Showing logs for build (.*) stage (.*) and container (.*)$`)
+
 // LogWriter is an interface that can be implemented to define different ways to stream / write logs
 // it's the implementer's responsibility to route those logs through the corresponding medium
 type LogWriter interface {
@@ -69,6 +92,10 @@ type retrieverFunc func(pod *corev1.Pod, container *corev1.Container) (io.Reader
 type LogLine struct {
 	Line       string
 	ShouldMask bool
+	// Stage the name of the stage the line was logged by, if known
+	Stage string
+	// Step the name of the container of the step the line was logged by, if known
+	Step string
 }
 
 // GetTektonPipelinesWithActivePipelineActivity returns list of all PipelineActivities with corresponding Tekton PipelineRuns ordered by the PipelineRun creation timestamp and a map to obtain its reference once a name has been selected
@@ -225,6 +252,11 @@ func (t TektonLogger) GetRunningBuildLogs(pa *v1.PipelineActivity, buildName str
 	loggedAllRunsForActivity := false
 	foundLogs := false
 
+	// lets watch the build pods rather than polling the API server for them while waiting for the stages to start
+	watchingPods := false
+	stop := make(chan struct{})
+	defer close(stop)
+
 	// Make sure we check again for the build pipeline if we just get the metapipeline initially, assuming the metapipeline succeeds
 	for !loggedAllRunsForActivity {
 		runsByType, err := getPipelineRunsForActivity(pa, t.TektonClient)
@@ -256,6 +288,15 @@ func (t TektonLogger) GetRunningBuildLogs(pa *v1.PipelineActivity, buildName str
 
 		// Assuming we have a run to log, go get its logs, looping until we've seen all stages for that run.
 		if runToLog != nil {
+			if !watchingPods {
+				watchingPods = true
+				podLister, err := kube.NewPodLister(t.KubeClient, pa.Namespace, builds.LabelPipelineRunName, stop)
+				if err != nil {
+					log.Logger().Debugf("polling the build pods as they cannot be watched: %s", err.Error())
+				} else {
+					t.podLister = podLister
+				}
+			}
 			structure, err := tekton.StructureForPipelineRun(t.JXClient, pa.Namespace, runToLog)
 			if err != nil {
 				return errors.Wrapf(err, "failed to get pipeline structure for %s in namespace %s", runToLog.Name, pa.Namespace)
@@ -274,7 +315,8 @@ func (t TektonLogger) GetRunningBuildLogs(pa *v1.PipelineActivity, buildName str
 
 			// Repeat until we've seen pods for all stages
 			for stagesToCheckCount > len(stagesSeen) {
-				pods, err := builds.GetPipelineRunPods(t.KubeClient, pa.Namespace, runToLog.Name)
+				stagesSeenBefore := len(stagesSeen)
+				pods, err := t.pipelineRunPods(pa.Namespace, runToLog.Name)
 				if err != nil {
 					return errors.Wrapf(err, "failed to get pods for pipeline run %s in namespace %s", runToLog.Name, pa.Namespace)
 				}
@@ -283,6 +325,7 @@ func (t TektonLogger) GetRunningBuildLogs(pa *v1.PipelineActivity, buildName str
 					return pods[i].CreationTimestamp.Before(&pods[j].CreationTimestamp)
 				})
 
+				var parallelPods []*corev1.Pod
 				for _, pod := range pods {
 					stageName := pod.Labels["jenkins.io/task-stage-name"]
 					params := builds.CreateBuildPodInfo(pod)
@@ -290,15 +333,29 @@ func (t TektonLogger) GetRunningBuildLogs(pa *v1.PipelineActivity, buildName str
 						strings.ToLower(params.Branch) == strings.ToLower(pa.Spec.GitBranch) && params.Build == pa.Spec.Build {
 						stagesSeen[stageName] = true
 						foundLogs = true
+						if t.Multiplex {
+							parallelPods = append(parallelPods, pod)
+							continue
+						}
 						err := t.getContainerLogsFromPod(pod, pa, buildName, stageName)
 						if err != nil {
 							return errors.Wrapf(err, "failed to obtain the logs for build %s and stage %s", buildName, stageName)
 						}
 					}
 				}
+				if len(parallelPods) > 0 {
+					err := t.getContainerLogsFromPods(parallelPods, pa, buildName)
+					if err != nil {
+						return errors.Wrapf(err, "failed to obtain the logs for build %s", buildName)
+					}
+				}
 				if !foundLogs {
 					break
 				}
+				if len(stagesSeen) == stagesSeenBefore {
+					// lets give the pods of the next stages time to be created
+					time.Sleep(time.Second)
+				}
 			}
 		}
 		if !foundLogs {
@@ -311,35 +368,82 @@ func (t TektonLogger) GetRunningBuildLogs(pa *v1.PipelineActivity, buildName str
 		}
 	}
 	if !foundLogs {
-		return errors.New("the build pods for this build have been garbage collected and the log was not found in the long term storage bucket")
+		return ErrNoBuildPods
 	}
 
 	return nil
 }
 
 func (t *TektonLogger) getContainerLogsFromPod(pod *corev1.Pod, pa *v1.PipelineActivity, buildName string, stageName string) error {
+	t.initializeLoggingRoutine()
+	err := t.streamContainerLogsFromPod(pod, pa, buildName, stageName)
+	// We are done using the logs and errors channels, any message in the channels should still be read before calling wg.Done()
+	t.closeLoggingChannels()
+	// Waiting so we don't finish the main routine before waiting for all traces to be printed
+	t.wg.Wait()
+	return err
+}
+
+// getContainerLogsFromPods streams the logs of the pods of parallel stages at the same time, every line is labelled
+// with its stage and step so that the LogWriter can tell them apart
+func (t *TektonLogger) getContainerLogsFromPods(pods []*corev1.Pod, pa *v1.PipelineActivity, buildName string) error {
+	t.initializeLoggingRoutine()
+	var wg sync.WaitGroup
+	var lock sync.Mutex
+	var errs []error
+	for _, pod := range pods {
+		wg.Add(1)
+		go func(pod *corev1.Pod) {
+			defer wg.Done()
+			stageName := pod.Labels["jenkins.io/task-stage-name"]
+			err := t.streamContainerLogsFromPod(pod, pa, buildName, stageName)
+			if err != nil {
+				lock.Lock()
+				errs = append(errs, errors.Wrapf(err, "stage %s", stageName))
+				lock.Unlock()
+			}
+		}(pod)
+	}
+	wg.Wait()
+	t.closeLoggingChannels()
+	t.wg.Wait()
+	return errorutil.CombineErrors(errs...)
+}
+
+// streamContainerLogsFromPod writes the logs of the containers of the pod of a stage into the logs channel
+func (t *TektonLogger) streamContainerLogsFromPod(pod *corev1.Pod, pa *v1.PipelineActivity, buildName string, stageName string) error {
 	infoColor := color.New(color.FgGreen)
 	infoColor.EnableColor()
 	errorColor := color.New(color.FgRed)
 	errorColor.EnableColor()
 	containers, _, _ := kube.GetContainersWithStatusAndIsInit(pod)
-	t.initializeLoggingRoutine()
 	for i, ic := range containers {
+		if !t.matchesStep(ic.Name) {
+			// the steps after a failed step are never run so the pipeline still stops at the failed step
+			if hasStepFailed(pod, i, t.KubeClient, pa.Namespace) {
+				break
+			}
+			continue
+		}
 		pod, err := t.waitForContainerToStart(pa.Namespace, pod, i, stageName)
 		err = t.LogWriter.WriteLog(LogLine{
 			Line: fmt.Sprintf("\nShowing logs for build %v stage %s and container %s",
 				infoColor.Sprintf(buildName), infoColor.Sprintf(stageName), infoColor.Sprintf(ic.Name)),
+			Stage: stageName,
+			Step:  ic.Name,
 		}, t.logsChannel)
 		if err != nil {
 			return errors.Wrapf(err, "there was a problem writing a single line into the logs writer")
 		}
-		err = t.fetchLogsToChannel(pa.Namespace, pod, &ic)
+		err = t.fetchLogsToChannel(pa.Namespace, pod, &ic, stageName)
 		if err != nil {
 			return errors.Wrap(err, "couldn't fetch logs into the logs channel")
 		}
 		if hasStepFailed(pod, i, t.KubeClient, pa.Namespace) {
 			err = t.LogWriter.WriteLog(LogLine{
-				Line: errorColor.Sprintf("\nPipeline failed on stage '%s' : container '%s'. The execution of the pipeline has stopped.", stageName, ic.Name),
+				Line:  errorColor.Sprintf("\nPipeline failed on stage '%s' : container '%s'. The execution of the pipeline has stopped.", stageName, ic.Name),
+				Stage: stageName,
+				Step:  ic.Name,
 			}, t.logsChannel)
 			if err != nil {
 				return err
@@ -350,13 +454,15 @@ func (t *TektonLogger) getContainerLogsFromPod(pod *corev1.Pod, pa *v1.PipelineA
 			break
 		}
 	}
-	// We are done using the logs and errors channels, any message in the channels should still be read before calling wg.Done()
-	t.closeLoggingChannels()
-	// Waiting so we don't finish the main routine before waiting for all traces to be printed
-	t.wg.Wait()
 	return nil
 }
 
+// matchesStep returns true if the logs of the container should be streamed, Tekton prefixes the names of the
+// containers of steps with 'step-'
+func (t *TektonLogger) matchesStep(containerName string) bool {
+	return t.Step == "" || containerName == t.Step || containerName == "step-"+t.Step
+}
+
 func (t *TektonLogger) syncStreamLog() {
 	err := t.LogWriter.StreamLog(t.logsChannel, t.errorsChannel)
 	if err != nil {
@@ -365,26 +471,25 @@ func (t *TektonLogger) syncStreamLog() {
 	defer t.wg.Done()
 }
 
-func (t *TektonLogger) fetchLogsToChannel(ns string, pod *corev1.Pod, container *corev1.Container) error {
-
-	if t.LogsRetrieverFunc == nil {
-		t.LogsRetrieverFunc = t.retrieveLogsFromPod
+func (t *TektonLogger) fetchLogsToChannel(ns string, pod *corev1.Pod, container *corev1.Container, stageName string) error {
+	retriever := t.LogsRetrieverFunc
+	if retriever == nil {
+		retriever = t.retrieveLogsFromPod
 	}
 
-	reader, cleanFN, err := t.LogsRetrieverFunc(pod, container)
+	reader, cleanFN, err := retriever(pod, container)
 	if err != nil {
-		t.errorsChannel <- err
 		return err
 	}
 	defer cleanFN()
-	err = writeStreamLines(reader, t.logsChannel)
+	err = writeStreamLines(reader, t.logsChannel, stageName, container.Name)
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
-func writeStreamLines(reader io.Reader, logCh chan<- LogLine) error {
+func writeStreamLines(reader io.Reader, logCh chan<- LogLine, stageName string, stepName string) error {
 	buffReader := bufio.NewReader(reader)
 	if buffReader == nil {
 		return errors.New("there was a problem obtaining a buffered reader")
@@ -397,7 +502,7 @@ func writeStreamLines(reader io.Reader, logCh chan<- LogLine) error {
 			}
 			return errors.Wrap(err, "failed to read stream")
 		}
-		logCh <- LogLine{Line: string(line), ShouldMask: true}
+		logCh <- LogLine{Line: string(line), ShouldMask: true, Stage: stageName, Step: stepName}
 	}
 }
 
@@ -436,7 +541,7 @@ func (t TektonLogger) waitForContainerToStart(ns string, pod *corev1.Pod, idx in
 	}
 	for {
 		time.Sleep(time.Second)
-		p, err := t.KubeClient.CoreV1().Pods(ns).Get(pod.Name, metav1.GetOptions{})
+		p, err := t.getPod(ns, pod.Name)
 		if err != nil {
 			return p, errors.Wrapf(err, "failed to load pod %s", pod.Name)
 		}
@@ -446,6 +551,22 @@ func (t TektonLogger) waitForContainerToStart(ns string, pod *corev1.Pod, idx in
 	}
 }
 
+// pipelineRunPods returns the pods of the pipeline run, from the cache of the pod lister if the build pods are watched
+func (t *TektonLogger) pipelineRunPods(ns string, prName string) ([]*corev1.Pod, error) {
+	if t.podLister == nil {
+		return builds.GetPipelineRunPods(t.KubeClient, ns, prName)
+	}
+	return t.podLister.List(labels.SelectorFromSet(labels.Set{builds.LabelPipelineRunName: prName}))
+}
+
+// getPod returns the latest state of the pod, from the cache of the pod lister if the build pods are watched
+func (t *TektonLogger) getPod(ns string, name string) (*corev1.Pod, error) {
+	if t.podLister == nil {
+		return t.KubeClient.CoreV1().Pods(ns).Get(name, metav1.GetOptions{})
+	}
+	return t.podLister.Get(name)
+}
+
 // StreamPipelinePersistentLogs reads logs from the provided bucket URL and writes them using the provided LogWriter
 func (t *TektonLogger) StreamPipelinePersistentLogs(logsURL string, jxClient versioned.Interface, ns string, authSvc auth.ConfigService) error {
 	t.initializeLoggingRoutine()
@@ -477,31 +598,49 @@ func (t *TektonLogger) StreamPipelinePersistentLogs(logsURL string, jxClient ver
 		if err != nil {
 			return errors.Wrapf(err, "there was a problem obtaining the log file from the github pages URL %s", logsURL)
 		}
+		if len(logBytes) > 0 {
+			return t.streamPipedLogs(bufio.NewScanner(bytes.NewReader(logBytes)), logsURL)
+		}
 	default:
 		return t.writeBlockingLine(LogLine{
 			Line: fmt.Sprintf("The provided logsURL scheme is not supported: %s", u.Scheme),
 		})
 	}
 
-	if len(logBytes) == 0 {
-		return t.writeBlockingLine(LogLine{
-			Line: "The build pods for this build have been garbage collected and we couldn't find the any stored log file",
-		})
-	}
 	return t.writeBlockingLine(LogLine{
-		Line: string(logBytes),
+		Line: "The build pods for this build have been garbage collected and we couldn't find the any stored log file",
 	})
 }
 
+// streamPipedLogs writes the lines of stored logs, finding the stage and step of each line from the headers written
+// before the logs of each container
 func (t *TektonLogger) streamPipedLogs(scanner *bufio.Scanner, logsURL string) error {
+	if t.Since > 0 {
+		log.Logger().Warnf("the logs of %s are read from the long term storage bucket so all of them are shown", logsURL)
+	}
+	stageName := ""
+	stepName := ""
+	defer func() {
+		t.closeLoggingChannels()
+		t.wg.Wait()
+	}()
 	for scanner.Scan() {
 		text := scanner.Text()
+		if matches := logsHeaderRegex.FindStringSubmatch(stripansi.Strip(text)); matches != nil {
+			stageName = matches[2]
+			stepName = matches[3]
+		}
+		if !t.matchesStep(stepName) {
+			continue
+		}
 		err := t.LogWriter.WriteLog(LogLine{
-			Line: text,
+			Line:  text,
+			Stage: stageName,
+			Step:  stepName,
 		}, t.logsChannel)
 
 		if err != nil {
-			return errors.Wrapf(err, "there was a problem streaming the log file from the GKE bucket %s", logsURL)
+			return errors.Wrapf(err, "there was a problem streaming the log file from the bucket %s", logsURL)
 		}
 
 		if t.FailIfPodFails {
@@ -548,6 +687,10 @@ func (t TektonLogger) retrieveLogsFromPod(pod *corev1.Pod, container *corev1.Con
 		Container: container.Name,
 		Follow:    true,
 	}
+	if t.Since > 0 {
+		sinceSeconds := int64(t.Since.Seconds())
+		options.SinceSeconds = &sinceSeconds
+	}
 	bytesLimit := t.LogWriter.BytesLimit()
 	if bytesLimit > 0 {
 		a := int64(bytesLimit)
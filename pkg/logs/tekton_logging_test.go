@@ -9,6 +9,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"path"
+	"strings"
 	"testing"
 
 	"github.com/acarl005/stripansi"
@@ -477,6 +478,97 @@ func TestGetRunningBuildLogsWithMultipleStages(t *testing.T) {
 		stripansi.Strip(firstLine), "'build' should be the first stage logged")
 }
 
+func TestGetRunningBuildLogsWithMultipleStagesMultiplexedForStep(t *testing.T) {
+	testCaseDir := path.Join("test_data", "multiple_stages")
+	_, _, _, _, ns := getFakeClientsAndNs(t)
+
+	podsList := tekton_helpers_test.AssertLoadPods(t, testCaseDir)
+	pipelineRuns := tekton_helpers_test.AssertLoadSinglePipelineRun(t, testCaseDir)
+	kubeClient := kubeMocks.NewSimpleClientset(podsList)
+	tektonClient := tektonMocks.NewSimpleClientset(pipelineRuns)
+	structures := tekton_helpers_test.AssertLoadSinglePipelineStructure(t, testCaseDir)
+	jxClient := jxfake.NewSimpleClientset(structures)
+
+	writer := &LineTestWriter{}
+	tl := TektonLogger{
+		KubeClient:        kubeClient,
+		JXClient:          jxClient,
+		TektonClient:      tektonClient,
+		Namespace:         ns,
+		LogWriter:         writer,
+		LogsRetrieverFunc: LogsProvider,
+		Multiplex:         true,
+		Step:              "step2",
+	}
+
+	pa := &v1.PipelineActivity{
+		ObjectMeta: v12.ObjectMeta{
+			Name:      "abayer-js-test-repo-master-1",
+			Namespace: ns,
+		},
+		Spec: v1.PipelineActivitySpec{
+			Build:         "1",
+			GitBranch:     "master",
+			GitRepository: "js-test-repo",
+			GitOwner:      "abayer",
+		},
+	}
+
+	err := tl.GetRunningBuildLogs(pa, "abayer/js-test-repo/master/1", false)
+	assert.NoError(t, err)
+
+	stages := map[string]bool{}
+	for _, line := range writer.Lines {
+		assert.Equal(t, "step-step2", line.Step, "only the logs of step2 should be streamed: %s", line.Line)
+		stages[line.Stage] = true
+	}
+	assert.Len(t, writer.Lines, 2*LogsHeadersMultiplier)
+	assert.Len(t, stages, 2, "the logs of both stages should be streamed")
+}
+
+func TestStreamPipelinePersistentLogsForStep(t *testing.T) {
+	_, _, _, commonOptions, _ := getFakeClientsAndNs(t)
+	commonOptions.SkipAuthSecretsMerge = true
+
+	storedLog := `
+Showing logs for build abayer/js-test-repo/master #1 stage build and container step-step2
+step2 of build
+Showing logs for build abayer/js-test-repo/master #1 stage build and container step-step3
+step3 of build
+Showing logs for build abayer/js-test-repo/master #1 stage test and container step-step2
+step2 of test
+`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		w.WriteHeader(200)
+		_, err := fmt.Fprint(w, storedLog)
+		assert.NoError(t, err)
+	}))
+	defer server.Close()
+
+	writer := &LineTestWriter{}
+	tl := TektonLogger{
+		LogWriter: writer,
+		Step:      "step2",
+	}
+
+	jxClient, ns, err := commonOptions.JXClient()
+	assert.NoError(t, err)
+	authSvc, err := commonOptions.GitAuthConfigService()
+	assert.NoError(t, err)
+	err = tl.StreamPipelinePersistentLogs(server.URL, jxClient, ns, authSvc)
+	assert.NoError(t, err)
+
+	var lines []string
+	for _, line := range writer.Lines {
+		if line.Step == "step-step2" && !strings.HasPrefix(line.Line, "Showing logs") {
+			lines = append(lines, line.Stage+": "+line.Line)
+		}
+	}
+	assert.Equal(t, []string{"build: step2 of build", "test: step2 of test"}, lines)
+	assert.Len(t, writer.Lines, 4, "only the headers and lines of step2 should be streamed")
+}
+
 func TestGetRunningBuildLogsWithMultipleStagesWithFailureInFirstStage(t *testing.T) {
 	testCaseDir := path.Join("test_data", "multiple_stages_with_failure_in_first_stage")
 	_, _, _, _, ns := getFakeClientsAndNs(t)
@@ -677,6 +769,27 @@ func (w TestWriter) BytesLimit() int {
 	return 0
 }
 
+// LineTestWriter keeps the streamed lines with their stages and steps, lines can be written concurrently
+type LineTestWriter struct {
+	Lines []LogLine
+}
+
+func (w *LineTestWriter) WriteLog(logLine LogLine, lch chan<- LogLine) error {
+	lch <- logLine
+	return nil
+}
+
+func (w *LineTestWriter) StreamLog(lch <-chan LogLine, ech <-chan error) error {
+	for l := range lch {
+		w.Lines = append(w.Lines, l)
+	}
+	return nil
+}
+
+func (w *LineTestWriter) BytesLimit() int {
+	return 0
+}
+
 func LogsProvider(pod *corev1.Pod, container *corev1.Container) (io.Reader, func(), error) {
 	return bytes.NewReader([]byte(fmt.Sprintf("Writing pod log for pod %s and container %s", pod.Name, container.Name))), func() {
 		//nothing to clean
@@ -0,0 +1,144 @@
+package notify
+
+import (
+	"fmt"
+	"strings"
+
+	v1 "github.com/jenkins-x/jx/v2/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/v2/pkg/log"
+	"github.com/pkg/errors"
+	"k8s.io/client-go/kubernetes"
+)
+
+// PipelineEvent an event of a pipeline which is delivered to the targets of the notification rules matching it
+type PipelineEvent struct {
+	Type       v1.PipelineEventType    `json:"type"`
+	Severity   v1.NotificationSeverity `json:"severity"`
+	Owner      string                  `json:"owner,omitempty"`
+	Repository string                  `json:"repository,omitempty"`
+	Branch     string                  `json:"branch,omitempty"`
+	Build      string                  `json:"build,omitempty"`
+	Context    string                  `json:"context,omitempty"`
+	GitURL     string                  `json:"gitUrl,omitempty"`
+	Author     string                  `json:"author,omitempty"`
+	Commit     string                  `json:"commit,omitempty"`
+	Message    string                  `json:"message,omitempty"`
+	BuildURL   string                  `json:"buildUrl,omitempty"`
+	LogsURL    string                  `json:"logsUrl,omitempty"`
+	Activity   string                  `json:"activity,omitempty"`
+}
+
+// Notifier delivers the events of pipelines to a target
+type Notifier interface {
+	Notify(event *PipelineEvent) error
+}
+
+// EventForStatusChange returns the event of a pipeline activity whose status changed from the previous status, or
+// nil if the change is not notified
+func EventForStatusChange(activity *v1.PipelineActivity, previous v1.ActivityStatusType) *PipelineEvent {
+	status := activity.Spec.Status
+	if status == previous {
+		return nil
+	}
+	var eventType v1.PipelineEventType
+	switch status {
+	case v1.ActivityStatusTypeRunning:
+		if previous.IsTerminated() {
+			return nil
+		}
+		eventType = v1.PipelineEventStarted
+	case v1.ActivityStatusTypeSucceeded:
+		eventType = v1.PipelineEventSucceeded
+	case v1.ActivityStatusTypeFailed, v1.ActivityStatusTypeError:
+		eventType = v1.PipelineEventFailed
+	default:
+		return nil
+	}
+	spec := &activity.Spec
+	return &PipelineEvent{
+		Type:       eventType,
+		Severity:   SeverityOf(eventType),
+		Owner:      spec.GitOwner,
+		Repository: spec.GitRepository,
+		Branch:     spec.GitBranch,
+		Build:      spec.Build,
+		Context:    spec.Context,
+		GitURL:     spec.GitURL,
+		Author:     spec.Author,
+		Commit:     spec.LastCommitSHA,
+		Message:    spec.LastCommitMessage,
+		BuildURL:   spec.BuildURL,
+		LogsURL:    spec.BuildLogsURL,
+		Activity:   activity.Name,
+	}
+}
+
+// SeverityOf returns the severity of the type of event
+func SeverityOf(eventType v1.PipelineEventType) v1.NotificationSeverity {
+	if eventType == v1.PipelineEventFailed {
+		return v1.NotificationSeverityError
+	}
+	return v1.NotificationSeverityInfo
+}
+
+// Title returns the one line summary of the event such as 'myorg/myrepo/master #3 failed'
+func (e *PipelineEvent) Title() string {
+	title := fmt.Sprintf("%s/%s/%s #%s", e.Owner, e.Repository, e.Branch, e.Build)
+	if e.Context != "" {
+		title += " " + e.Context
+	}
+	return title + " " + string(e.Type)
+}
+
+// Text returns the details of the event, such as the commit which triggered the pipeline
+func (e *PipelineEvent) Text() string {
+	var lines []string
+	if e.Commit != "" {
+		commit := e.Commit
+		if len(commit) > 7 {
+			commit = commit[:7]
+		}
+		line := "commit " + commit
+		if e.Author != "" {
+			line += " by " + e.Author
+		}
+		lines = append(lines, line)
+	}
+	if e.Message != "" {
+		lines = append(lines, strings.SplitN(strings.TrimSpace(e.Message), "\n", 2)[0])
+	}
+	return strings.Join(lines, "\n")
+}
+
+// URL returns the URL of the logs of the pipeline, or of its build if it has no logs yet
+func (e *PipelineEvent) URL() string {
+	if e.LogsURL != "" {
+		return e.LogsURL
+	}
+	return e.BuildURL
+}
+
+// NotifyPipelineEvent delivers the event to the targets of the rules which match it. Failing to notify a target is
+// logged so that the other targets are still notified
+func NotifyPipelineEvent(kubeClient kubernetes.Interface, ns string, rules []v1.PipelineNotificationRule, event *PipelineEvent) {
+	for _, rule := range MatchingRules(rules, event) {
+		if err := ValidateRule(&rule); err != nil {
+			log.Logger().Warnf("ignoring notification rule %s: %s", rule.Name, err)
+			continue
+		}
+		for _, target := range rule.Targets {
+			err := notifyTarget(kubeClient, ns, &target, event)
+			if err != nil {
+				log.Logger().Warnf("failed to notify the %s target of notification rule %s of %s: %s", target.Kind, rule.Name, event.Title(), err)
+			}
+		}
+	}
+}
+
+func notifyTarget(kubeClient kubernetes.Interface, ns string, target *v1.NotificationTarget, event *PipelineEvent) error {
+	notifier, err := NewNotifier(kubeClient, ns, target)
+	if err != nil {
+		return err
+	}
+	return errors.Wrapf(notifier.Notify(event), "notifying %s", target.Kind)
+}
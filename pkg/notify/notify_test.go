@@ -0,0 +1,180 @@
+// +build unit
+
+package notify_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	v1 "github.com/jenkins-x/jx/v2/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/v2/pkg/notify"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+)
+
+func TestEventForStatusChange(t *testing.T) {
+	activity := &v1.PipelineActivity{
+		ObjectMeta: metav1.ObjectMeta{Name: "myorg-myrepo-master-3"},
+		Spec: v1.PipelineActivitySpec{
+			GitOwner:          "myorg",
+			GitRepository:     "myrepo",
+			GitBranch:         "master",
+			Build:             "3",
+			LastCommitSHA:     "0123456789abcdef",
+			LastCommitMessage: "fix the build\n\nmore details",
+			Author:            "jdoe",
+			BuildLogsURL:      "https://logs.example.com/3",
+			Status:            v1.ActivityStatusTypeRunning,
+		},
+	}
+	event := notify.EventForStatusChange(activity, v1.ActivityStatusTypePending)
+	require.NotNil(t, event)
+	assert.Equal(t, v1.PipelineEventStarted, event.Type)
+	assert.Equal(t, v1.NotificationSeverityInfo, event.Severity)
+	assert.Equal(t, "myorg/myrepo/master #3 started", event.Title())
+	assert.Equal(t, "commit 0123456 by jdoe\nfix the build", event.Text())
+
+	assert.Nil(t, notify.EventForStatusChange(activity, v1.ActivityStatusTypeRunning))
+
+	activity.Spec.Status = v1.ActivityStatusTypeFailed
+	event = notify.EventForStatusChange(activity, v1.ActivityStatusTypeRunning)
+	require.NotNil(t, event)
+	assert.Equal(t, v1.PipelineEventFailed, event.Type)
+	assert.Equal(t, v1.NotificationSeverityError, event.Severity)
+
+	activity.Spec.Status = v1.ActivityStatusTypePending
+	assert.Nil(t, notify.EventForStatusChange(activity, v1.ActivityStatusTypeNone))
+}
+
+func TestRuleMatches(t *testing.T) {
+	event := &notify.PipelineEvent{
+		Type:       v1.PipelineEventFailed,
+		Severity:   v1.NotificationSeverityError,
+		Owner:      "MyOrg",
+		Repository: "myrepo",
+		Branch:     "PR-12",
+	}
+	testCases := []struct {
+		name     string
+		rule     v1.PipelineNotificationRule
+		expected bool
+	}{
+		{"empty", v1.PipelineNotificationRule{}, true},
+		{"repository", v1.PipelineNotificationRule{Repositories: []string{"myorg/*"}}, true},
+		{"other repository", v1.PipelineNotificationRule{Repositories: []string{"myorg/other"}}, false},
+		{"branch", v1.PipelineNotificationRule{Branches: []string{"master", "pr-*"}}, true},
+		{"other branch", v1.PipelineNotificationRule{Branches: []string{"master"}}, false},
+		{"event", v1.PipelineNotificationRule{Events: []v1.PipelineEventType{v1.PipelineEventFailed}}, true},
+		{"other event", v1.PipelineNotificationRule{Events: []v1.PipelineEventType{v1.PipelineEventStarted}}, false},
+		{"severity", v1.PipelineNotificationRule{Severity: v1.NotificationSeverityError}, true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, notify.RuleMatches(&tc.rule, event))
+		})
+	}
+
+	event.Type = v1.PipelineEventSucceeded
+	event.Severity = v1.NotificationSeverityInfo
+	assert.False(t, notify.RuleMatches(&v1.PipelineNotificationRule{Severity: v1.NotificationSeverityError}, event))
+}
+
+func TestValidateRule(t *testing.T) {
+	rule := &v1.PipelineNotificationRule{
+		Name:    "failures",
+		Events:  []v1.PipelineEventType{v1.PipelineEventFailed},
+		Targets: []v1.NotificationTarget{{Kind: v1.NotificationKindSlack, URL: "https://hooks.slack.com/services/abc"}},
+	}
+	assert.NoError(t, notify.ValidateRule(rule))
+
+	rule.Events = []v1.PipelineEventType{"aborted"}
+	assert.Error(t, notify.ValidateRule(rule))
+
+	rule.Events = nil
+	rule.Targets = []v1.NotificationTarget{{Kind: v1.NotificationKindEmail, URL: "smtp://smtp.example.com:587"}}
+	assert.Error(t, notify.ValidateRule(rule), "email targets must specify from and to")
+
+	rule.Targets = []v1.NotificationTarget{{Kind: v1.NotificationKindTeams}}
+	assert.Error(t, notify.ValidateRule(rule), "targets must specify a URL")
+}
+
+func TestNotifyPipelineEvent(t *testing.T) {
+	requests := map[string]map[string]interface{}{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, err := ioutil.ReadAll(r.Body)
+		require.NoError(t, err)
+		payload := map[string]interface{}{}
+		require.NoError(t, json.Unmarshal(data, &payload))
+		requests[r.URL.Path] = payload
+	}))
+	defer server.Close()
+
+	ns := "jx"
+	kubeClient := kubefake.NewSimpleClientset(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "slack-webhook", Namespace: ns},
+		Data:       map[string][]byte{"url": []byte(server.URL + "/slack\n")},
+	})
+	rules := []v1.PipelineNotificationRule{
+		{
+			Name:     "failures",
+			Branches: []string{"master"},
+			Severity: v1.NotificationSeverityError,
+			Targets: []v1.NotificationTarget{
+				{
+					Kind:    v1.NotificationKindSlack,
+					Channel: "#builds",
+					URLFrom: &corev1.SecretKeySelector{
+						LocalObjectReference: corev1.LocalObjectReference{Name: "slack-webhook"},
+						Key:                  "url",
+					},
+				},
+				{Kind: v1.NotificationKindTeams, URL: server.URL + "/teams"},
+			},
+		},
+		{
+			Name:    "everything",
+			Targets: []v1.NotificationTarget{{Kind: v1.NotificationKindWebhook, URL: server.URL + "/webhook"}},
+		},
+	}
+	event := &notify.PipelineEvent{
+		Type:       v1.PipelineEventFailed,
+		Severity:   v1.NotificationSeverityError,
+		Owner:      "myorg",
+		Repository: "myrepo",
+		Branch:     "master",
+		Build:      "3",
+		LogsURL:    "https://logs.example.com/3",
+	}
+	notify.NotifyPipelineEvent(kubeClient, ns, rules, event)
+
+	require.Len(t, requests, 3)
+	slack := requests["/slack"]
+	assert.Equal(t, "#builds", slack["channel"])
+	attachments := slack["attachments"].([]interface{})
+	require.Len(t, attachments, 1)
+	attachment := attachments[0].(map[string]interface{})
+	assert.Equal(t, "myorg/myrepo/master #3 failed", attachment["title"])
+	assert.Equal(t, "https://logs.example.com/3", attachment["title_link"])
+
+	teams := requests["/teams"]
+	assert.Equal(t, "MessageCard", teams["@type"])
+	assert.Equal(t, "myorg/myrepo/master #3 failed", teams["title"])
+
+	webhook := requests["/webhook"]
+	assert.Equal(t, "failed", webhook["type"])
+	assert.Equal(t, "error", webhook["severity"])
+	assert.Equal(t, "myrepo", webhook["repository"])
+
+	requests = map[string]map[string]interface{}{}
+	event.Type = v1.PipelineEventSucceeded
+	event.Severity = v1.NotificationSeverityInfo
+	notify.NotifyPipelineEvent(kubeClient, ns, rules, event)
+	assert.Len(t, requests, 1)
+	assert.Contains(t, requests, "/webhook")
+}
@@ -0,0 +1,94 @@
+package notify
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	v1 "github.com/jenkins-x/jx/v2/pkg/apis/jenkins.io/v1"
+	"github.com/pkg/errors"
+)
+
+var severityLevels = map[v1.NotificationSeverity]int{
+	v1.NotificationSeverityInfo:  0,
+	v1.NotificationSeverityError: 1,
+}
+
+// MatchingRules returns the rules which match the repository, branch, type and severity of the event
+func MatchingRules(rules []v1.PipelineNotificationRule, event *PipelineEvent) []v1.PipelineNotificationRule {
+	var answer []v1.PipelineNotificationRule
+	for _, rule := range rules {
+		if RuleMatches(&rule, event) {
+			answer = append(answer, rule)
+		}
+	}
+	return answer
+}
+
+// RuleMatches returns true if the rule notifies the event
+func RuleMatches(rule *v1.PipelineNotificationRule, event *PipelineEvent) bool {
+	if !matchesAny(rule.Repositories, event.Owner+"/"+event.Repository) {
+		return false
+	}
+	if !matchesAny(rule.Branches, event.Branch) {
+		return false
+	}
+	if len(rule.Events) > 0 {
+		found := false
+		for _, e := range rule.Events {
+			if e == event.Type {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return rule.Severity == "" || severityLevels[event.Severity] >= severityLevels[rule.Severity]
+}
+
+// matchesAny returns true if there are no patterns or the value matches one of the glob patterns, ignoring case as the
+// owners and branches of pipelines keep the case of the git provider
+func matchesAny(patterns []string, value string) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+	value = strings.ToLower(value)
+	for _, pattern := range patterns {
+		matched, err := path.Match(strings.ToLower(pattern), value)
+		if err == nil && matched {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateRule returns an error if the rule has an invalid pattern, event, severity or target
+func ValidateRule(rule *v1.PipelineNotificationRule) error {
+	for _, patterns := range [][]string{rule.Repositories, rule.Branches} {
+		for _, pattern := range patterns {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return fmt.Errorf("invalid pattern %s of notification rule %s: %s", pattern, rule.Name, err)
+			}
+		}
+	}
+	for _, e := range rule.Events {
+		switch e {
+		case v1.PipelineEventStarted, v1.PipelineEventSucceeded, v1.PipelineEventFailed:
+		default:
+			return fmt.Errorf("invalid event %s of notification rule %s, it must be one of %s, %s or %s", e, rule.Name,
+				v1.PipelineEventStarted, v1.PipelineEventSucceeded, v1.PipelineEventFailed)
+		}
+	}
+	if _, ok := severityLevels[rule.Severity]; rule.Severity != "" && !ok {
+		return fmt.Errorf("invalid severity %s of notification rule %s, it must be %s or %s", rule.Severity, rule.Name,
+			v1.NotificationSeverityInfo, v1.NotificationSeverityError)
+	}
+	for _, target := range rule.Targets {
+		if err := validateTarget(&target); err != nil {
+			return errors.Wrapf(err, "in notification rule %s", rule.Name)
+		}
+	}
+	return nil
+}
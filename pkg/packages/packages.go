@@ -40,6 +40,12 @@ const KustomizeVersion = "3.5.1"
 // GlooVersion binary version to use
 const GlooVersion = "1.3.23"
 
+// SyftVersion binary version to use
+const SyftVersion = "0.27.0"
+
+// CosignVersion binary version to use
+const CosignVersion = "1.2.1"
+
 // GlobalBinaryPathWhitelist binaries that require to be on the path but do not need to exist in JX_HOME/bin
 var GlobalBinaryPathWhitelist = []string{
 	"az",
@@ -0,0 +1,299 @@
+package previews
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	v1 "github.com/jenkins-x/jx/v2/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/v2/pkg/client/clientset/versioned"
+	"github.com/jenkins-x/jx/v2/pkg/config"
+	"github.com/jenkins-x/jx/v2/pkg/gits"
+	"github.com/jenkins-x/jx/v2/pkg/helm"
+	"github.com/jenkins-x/jx/v2/pkg/kube"
+	"github.com/jenkins-x/jx/v2/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// PreviewBudgetName the name of the ResourceQuota and LimitRange of the budget of a preview namespace
+	PreviewBudgetName = "preview-budget"
+)
+
+// BudgetUsage the number of preview environments of the team or a repository against its limit
+type BudgetUsage struct {
+	// Scope the team or the owner/repository
+	Scope    string
+	Previews int
+	// Limit the maximum number of preview environments, which is unlimited if zero
+	Limit int
+}
+
+// RepositoryOf returns the owner/repository of the source of the preview environment
+func RepositoryOf(env *v1.Environment) string {
+	if env.Spec.Source.URL == "" {
+		return ""
+	}
+	gitInfo, err := gits.ParseGitURL(env.Spec.Source.URL)
+	if err != nil {
+		return ""
+	}
+	return gitInfo.Organisation + "/" + gitInfo.Name
+}
+
+// LastUpdated returns when the preview environment was last updated with a new commit, or when it was created if that
+// was not recorded
+func LastUpdated(env *v1.Environment) time.Time {
+	if v := env.Annotations[kube.AnnotationPreviewUpdatedAt]; v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err == nil {
+			return t
+		}
+	}
+	return env.CreationTimestamp.Time
+}
+
+// PreviewsToEvict returns the least recently updated preview environments which have to be deleted so that a new
+// preview environment of the repository fits in the budget of the team and of the repository, or an error if the
+// budget is exceeded and eviction is disabled
+func PreviewsToEvict(envs []v1.Environment, c *config.PreviewBudgetConfig, repository string, maximumInstances int) ([]*v1.Environment, error) {
+	teamMax := 0
+	evict := false
+	if c != nil {
+		teamMax = c.MaxPreviews
+		evict = c.EvictOldest
+	}
+	repoMax, _ := c.RepositoryLimits(repository, maximumInstances)
+
+	remaining := []*v1.Environment{}
+	for i := range envs {
+		if envs[i].Spec.Kind == v1.EnvironmentKindTypePreview {
+			remaining = append(remaining, &envs[i])
+		}
+	}
+	sort.SliceStable(remaining, func(i, j int) bool {
+		return LastUpdated(remaining[i]).Before(LastUpdated(remaining[j]))
+	})
+
+	var answer []*v1.Environment
+	for {
+		repoPreviews := []*v1.Environment{}
+		for _, env := range remaining {
+			if RepositoryOf(env) == repository {
+				repoPreviews = append(repoPreviews, env)
+			}
+		}
+		repoFull := repoMax > 0 && len(repoPreviews) >= repoMax
+		teamFull := teamMax > 0 && len(remaining) >= teamMax
+		if !repoFull && !teamFull {
+			return answer, nil
+		}
+		candidates := remaining
+		if repoFull {
+			if !evict {
+				return nil, fmt.Errorf("repository %s already has %d preview environments which is the maximum of its budget", repository, len(repoPreviews))
+			}
+			candidates = repoPreviews
+		} else if !evict {
+			return nil, fmt.Errorf("the team already has %d preview environments which is the maximum of its budget", len(remaining))
+		}
+		oldest := candidates[0]
+		answer = append(answer, oldest)
+		for i, env := range remaining {
+			if env == oldest {
+				remaining = append(remaining[:i], remaining[i+1:]...)
+				break
+			}
+		}
+	}
+}
+
+// Usage returns the number of preview environments of the team and of each repository against their budget
+func Usage(envs []v1.Environment, c *config.PreviewBudgetConfig) []BudgetUsage {
+	counts := map[string]int{}
+	total := 0
+	for i := range envs {
+		if envs[i].Spec.Kind != v1.EnvironmentKindTypePreview {
+			continue
+		}
+		total++
+		if repository := RepositoryOf(&envs[i]); repository != "" {
+			counts[repository]++
+		}
+	}
+	if c != nil {
+		for repository := range c.Repositories {
+			if _, ok := counts[repository]; !ok {
+				counts[repository] = 0
+			}
+		}
+	}
+	repositories := []string{}
+	for repository := range counts {
+		repositories = append(repositories, repository)
+	}
+	sort.Strings(repositories)
+
+	teamMax := 0
+	if c != nil {
+		teamMax = c.MaxPreviews
+	}
+	answer := []BudgetUsage{{Scope: "team", Previews: total, Limit: teamMax}}
+	for _, repository := range repositories {
+		limit, _ := c.RepositoryLimits(repository, 0)
+		answer = append(answer, BudgetUsage{Scope: repository, Previews: counts[repository], Limit: limit})
+	}
+	return answer
+}
+
+// ApplyBudget creates or updates the ResourceQuota and LimitRange of the preview namespace, deleting them if the budget
+// no longer defines a quota or default requests
+func ApplyBudget(kubeClient kubernetes.Interface, ns string, quota map[string]string, defaultRequests map[string]string) error {
+	hard, err := resourceList(quota)
+	if err != nil {
+		return errors.Wrap(err, "invalid quota of the previews budget")
+	}
+	requests, err := resourceList(defaultRequests)
+	if err != nil {
+		return errors.Wrap(err, "invalid default requests of the previews budget")
+	}
+
+	quotas := kubeClient.CoreV1().ResourceQuotas(ns)
+	existingQuota, err := quotas.Get(PreviewBudgetName, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		existingQuota, err = nil, nil
+	}
+	if err != nil {
+		return errors.Wrapf(err, "getting the ResourceQuota %s in namespace %s", PreviewBudgetName, ns)
+	}
+	switch {
+	case len(hard) == 0 && existingQuota != nil:
+		err = quotas.Delete(PreviewBudgetName, &metav1.DeleteOptions{})
+	case len(hard) > 0 && existingQuota == nil:
+		_, err = quotas.Create(&corev1.ResourceQuota{
+			ObjectMeta: metav1.ObjectMeta{Name: PreviewBudgetName},
+			Spec:       corev1.ResourceQuotaSpec{Hard: hard},
+		})
+	case len(hard) > 0:
+		existingQuota.Spec.Hard = hard
+		_, err = quotas.Update(existingQuota)
+	}
+	if err != nil {
+		return errors.Wrapf(err, "applying the ResourceQuota %s in namespace %s", PreviewBudgetName, ns)
+	}
+
+	limitRanges := kubeClient.CoreV1().LimitRanges(ns)
+	existingLimits, err := limitRanges.Get(PreviewBudgetName, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		existingLimits, err = nil, nil
+	}
+	if err != nil {
+		return errors.Wrapf(err, "getting the LimitRange %s in namespace %s", PreviewBudgetName, ns)
+	}
+	limits := []corev1.LimitRangeItem{
+		{
+			Type:           corev1.LimitTypeContainer,
+			DefaultRequest: requests,
+		},
+	}
+	switch {
+	case len(requests) == 0 && existingLimits != nil:
+		err = limitRanges.Delete(PreviewBudgetName, &metav1.DeleteOptions{})
+	case len(requests) > 0 && existingLimits == nil:
+		_, err = limitRanges.Create(&corev1.LimitRange{
+			ObjectMeta: metav1.ObjectMeta{Name: PreviewBudgetName},
+			Spec:       corev1.LimitRangeSpec{Limits: limits},
+		})
+	case len(requests) > 0:
+		existingLimits.Spec.Limits = limits
+		_, err = limitRanges.Update(existingLimits)
+	}
+	if err != nil {
+		return errors.Wrapf(err, "applying the LimitRange %s in namespace %s", PreviewBudgetName, ns)
+	}
+	return nil
+}
+
+// QuotaUsage returns the used and hard resources of the ResourceQuota of the preview namespace such as
+// `requests.cpu: 250m/1`, or an empty string if it has no quota
+func QuotaUsage(kubeClient kubernetes.Interface, ns string) (string, error) {
+	quota, err := kubeClient.CoreV1().ResourceQuotas(ns).Get(PreviewBudgetName, metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return "", nil
+		}
+		return "", errors.Wrapf(err, "getting the ResourceQuota %s in namespace %s", PreviewBudgetName, ns)
+	}
+	names := []string{}
+	for name := range quota.Spec.Hard {
+		names = append(names, string(name))
+	}
+	sort.Strings(names)
+	values := []string{}
+	for _, name := range names {
+		hard := quota.Spec.Hard[corev1.ResourceName(name)]
+		used, ok := quota.Status.Used[corev1.ResourceName(name)]
+		usedText := "0"
+		if ok {
+			usedText = used.String()
+		}
+		values = append(values, fmt.Sprintf("%s: %s/%s", name, usedText, hard.String()))
+	}
+	return strings.Join(values, ", "), nil
+}
+
+// DeletePreviewEnvironment destroys the fixtures of the preview environment, deletes its helm release, the
+// environment and its namespace. The helm and preview kube clients are those of the cluster which runs the preview
+// environment
+func DeletePreviewEnvironment(kubeClient kubernetes.Interface, previewKubeClient kubernetes.Interface, jxClient versioned.Interface, helmer helm.Helmer, devNs string, env *v1.Environment) error {
+	ctx := &FixtureContext{
+		KubeClient:        kubeClient,
+		DevNamespace:      devNs,
+		PreviewName:       env.Name,
+		PreviewNamespace:  env.Spec.Namespace,
+		PreviewKubeClient: previewKubeClient,
+	}
+	err := DestroyFixtures(ctx, env)
+	if err != nil {
+		return errors.Wrapf(err, "destroying the fixtures of preview environment %s", env.Name)
+	}
+
+	releaseName := kube.GetPreviewEnvironmentReleaseName(env)
+	if len(releaseName) > 0 {
+		log.Logger().Infof("Deleting helm release: %s", util.ColorInfo(releaseName))
+		err = helmer.DeleteRelease(devNs, releaseName, true)
+		if err != nil {
+			return err
+		}
+	}
+
+	log.Logger().Infof("Deleting preview environment: %s", util.ColorInfo(env.Name))
+	err = jxClient.JenkinsV1().Environments(devNs).Delete(env.Name, &metav1.DeleteOptions{})
+	if err != nil {
+		return err
+	}
+	log.Logger().Infof("Deleted environment %s", util.ColorInfo(env.Name))
+	if env.Spec.Namespace == "" {
+		return fmt.Errorf("No namespace for environment %s", env.Name)
+	}
+	return previewKubeClient.CoreV1().Namespaces().Delete(env.Spec.Namespace, &metav1.DeleteOptions{})
+}
+
+func resourceList(values map[string]string) (corev1.ResourceList, error) {
+	answer := corev1.ResourceList{}
+	for name, value := range values {
+		q, err := resource.ParseQuantity(value)
+		if err != nil {
+			return nil, errors.Wrapf(err, "parsing %s: %s", name, value)
+		}
+		answer[corev1.ResourceName(name)] = q
+	}
+	return answer, nil
+}
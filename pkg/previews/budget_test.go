@@ -0,0 +1,117 @@
+// +build unit
+
+package previews_test
+
+import (
+	"testing"
+	"time"
+
+	v1 "github.com/jenkins-x/jx/v2/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/v2/pkg/config"
+	"github.com/jenkins-x/jx/v2/pkg/kube"
+	"github.com/jenkins-x/jx/v2/pkg/previews"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func previewEnv(name string, repository string, updated time.Time) v1.Environment {
+	return v1.Environment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+			Annotations: map[string]string{
+				kube.AnnotationPreviewUpdatedAt: updated.UTC().Format(time.RFC3339),
+			},
+		},
+		Spec: v1.EnvironmentSpec{
+			Kind:   v1.EnvironmentKindTypePreview,
+			Source: v1.EnvironmentRepository{URL: "https://github.com/" + repository + ".git"},
+		},
+	}
+}
+
+func TestPreviewsToEvict(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	envs := []v1.Environment{
+		previewEnv("myorg-app-pr-3", "myorg/app", now.Add(-1*time.Hour)),
+		previewEnv("myorg-app-pr-1", "myorg/app", now.Add(-3*time.Hour)),
+		previewEnv("myorg-other-pr-1", "myorg/other", now.Add(-5*time.Hour)),
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "staging"},
+			Spec:       v1.EnvironmentSpec{Kind: v1.EnvironmentKindTypePermanent},
+		},
+	}
+
+	evict, err := previews.PreviewsToEvict(envs, nil, "myorg/app", 0)
+	require.NoError(t, err)
+	assert.Empty(t, evict, "previews are unlimited without a budget")
+
+	_, err = previews.PreviewsToEvict(envs, &config.PreviewBudgetConfig{MaxPreviewsPerRepository: 2}, "myorg/app", 0)
+	assert.Error(t, err, "the budget is exceeded without eviction")
+
+	budget := &config.PreviewBudgetConfig{MaxPreviewsPerRepository: 2, EvictOldest: true}
+	evict, err = previews.PreviewsToEvict(envs, budget, "myorg/app", 0)
+	require.NoError(t, err)
+	require.Len(t, evict, 1)
+	assert.Equal(t, "myorg-app-pr-1", evict[0].Name, "the oldest preview of the repository is evicted")
+
+	budget = &config.PreviewBudgetConfig{MaxPreviews: 3, EvictOldest: true}
+	evict, err = previews.PreviewsToEvict(envs, budget, "myorg/app", 0)
+	require.NoError(t, err)
+	require.Len(t, evict, 1)
+	assert.Equal(t, "myorg-other-pr-1", evict[0].Name, "the oldest preview of the team is evicted")
+
+	evict, err = previews.PreviewsToEvict(envs, budget, "myorg/app", 1)
+	require.NoError(t, err)
+	require.Len(t, evict, 2, "the maximum instances of the jenkins-x.yml lowers the budget of the repository")
+	assert.Equal(t, "myorg-app-pr-1", evict[0].Name)
+	assert.Equal(t, "myorg-app-pr-3", evict[1].Name)
+
+	usage := previews.Usage(envs, &config.PreviewBudgetConfig{MaxPreviews: 3, MaxPreviewsPerRepository: 2})
+	assert.Equal(t, []previews.BudgetUsage{
+		{Scope: "team", Previews: 3, Limit: 3},
+		{Scope: "myorg/app", Previews: 2, Limit: 2},
+		{Scope: "myorg/other", Previews: 1, Limit: 2},
+	}, usage)
+}
+
+func TestApplyBudget(t *testing.T) {
+	t.Parallel()
+
+	ns := "jx-myorg-app-pr-1"
+	kubeClient := fake.NewSimpleClientset()
+
+	err := previews.ApplyBudget(kubeClient, ns, map[string]string{"requests.cpu": "1", "pods": "10"}, map[string]string{"cpu": "100m"})
+	require.NoError(t, err)
+	quota, err := kubeClient.CoreV1().ResourceQuotas(ns).Get(previews.PreviewBudgetName, metav1.GetOptions{})
+	require.NoError(t, err)
+	hard := quota.Spec.Hard["requests.cpu"]
+	assert.Equal(t, "1", hard.String())
+	limits, err := kubeClient.CoreV1().LimitRanges(ns).Get(previews.PreviewBudgetName, metav1.GetOptions{})
+	require.NoError(t, err)
+	cpu := limits.Spec.Limits[0].DefaultRequest[corev1.ResourceCPU]
+	assert.Equal(t, "100m", cpu.String())
+
+	quota.Status.Used = corev1.ResourceList{"requests.cpu": resource.MustParse("250m")}
+	_, err = kubeClient.CoreV1().ResourceQuotas(ns).Update(quota)
+	require.NoError(t, err)
+	usage, err := previews.QuotaUsage(kubeClient, ns)
+	require.NoError(t, err)
+	assert.Equal(t, "pods: 0/10, requests.cpu: 250m/1", usage)
+
+	err = previews.ApplyBudget(kubeClient, ns, nil, nil)
+	require.NoError(t, err)
+	usage, err = previews.QuotaUsage(kubeClient, ns)
+	require.NoError(t, err)
+	assert.Equal(t, "", usage, "the quota is removed with the budget")
+
+	_, err = kubeClient.CoreV1().LimitRanges(ns).Get(previews.PreviewBudgetName, metav1.GetOptions{})
+	assert.Error(t, err)
+
+	assert.Error(t, previews.ApplyBudget(kubeClient, ns, map[string]string{"requests.cpu": "lots"}, nil))
+}
@@ -0,0 +1,76 @@
+package previews
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jenkins-x/jx/v2/pkg/config"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+)
+
+// CommandProvisioner provisions preview fixtures by running the provision command of the fixture, whose output of
+// KEY=VALUE lines are the connection details, and destroys them by running its destroy command
+type CommandProvisioner struct {
+}
+
+// Provision runs the provision command of the fixture
+func (p *CommandProvisioner) Provision(ctx *FixtureContext, fixture *config.PreviewFixture) (map[string]string, error) {
+	if fixture.Provision == "" {
+		return nil, fmt.Errorf("command preview fixture %s has no provision command", fixture.Name)
+	}
+	out, err := runFixtureCommand(ctx, fixture, fixture.Provision)
+	if err != nil {
+		return nil, err
+	}
+	return ParseFixtureDetails(out)
+}
+
+// Destroy runs the destroy command of the fixture
+func (p *CommandProvisioner) Destroy(ctx *FixtureContext, fixture *config.PreviewFixture) error {
+	if fixture.Destroy == "" {
+		return nil
+	}
+	_, err := runFixtureCommand(ctx, fixture, fixture.Destroy)
+	return err
+}
+
+func runFixtureCommand(ctx *FixtureContext, fixture *config.PreviewFixture, command string) (string, error) {
+	env := map[string]string{
+		"PREVIEW_NAME":      ctx.PreviewName,
+		"PREVIEW_NAMESPACE": ctx.PreviewNamespace,
+		"FIXTURE_NAME":      fixture.Name,
+	}
+	for k, v := range fixture.Parameters {
+		env["FIXTURE_"+strings.ToUpper(k)] = v
+	}
+	cmd := util.Command{
+		Dir:  ctx.Dir,
+		Name: "sh",
+		Args: []string{"-c", command},
+		Env:  env,
+	}
+	out, err := cmd.RunWithoutRetry()
+	if err != nil {
+		return "", errors.Wrapf(err, "running %s", command)
+	}
+	return out, nil
+}
+
+// ParseFixtureDetails parses the KEY=VALUE lines of the output of a provision command, ignoring blank lines and
+// comments
+func ParseFixtureDetails(output string) (map[string]string, error) {
+	details := map[string]string{}
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		idx := strings.Index(line, "=")
+		if idx <= 0 {
+			return nil, fmt.Errorf("invalid line %q in the output of the provision command which should be KEY=VALUE", line)
+		}
+		details[line[:idx]] = line[idx+1:]
+	}
+	return details, nil
+}
@@ -0,0 +1,194 @@
+package previews
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	v1 "github.com/jenkins-x/jx/v2/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/v2/pkg/config"
+	"github.com/jenkins-x/jx/v2/pkg/errorutil"
+	"github.com/jenkins-x/jx/v2/pkg/kube"
+	"github.com/jenkins-x/jx/v2/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// LabelPreviewFixture the label of the Secrets with the connection details of preview fixtures
+	LabelPreviewFixture = "jenkins.io/preview-fixture"
+)
+
+// FixtureContext the preview environment which fixtures are provisioned for or destroyed with
+type FixtureContext struct {
+	KubeClient       kubernetes.Interface
+	DevNamespace     string
+	PreviewName      string
+	PreviewNamespace string
+	// PreviewKubeClient the client of the cluster which runs the preview namespace, which defaults to KubeClient
+	PreviewKubeClient kubernetes.Interface
+	// Dir the directory of the source code of the preview, which is empty when destroying the fixtures of a
+	// preview environment which is garbage collected
+	Dir string
+}
+
+// FixtureProvisioner provisions and destroys a kind of preview fixture
+type FixtureProvisioner interface {
+	// Provision provisions the fixture and returns its connection details
+	Provision(ctx *FixtureContext, fixture *config.PreviewFixture) (map[string]string, error)
+	// Destroy destroys the fixture
+	Destroy(ctx *FixtureContext, fixture *config.PreviewFixture) error
+}
+
+var provisioners = map[string]FixtureProvisioner{
+	"command":  &CommandProvisioner{},
+	"postgres": &PostgresProvisioner{},
+}
+
+// RegisterFixtureProvisioner registers the provisioner of a kind of preview fixture
+func RegisterFixtureProvisioner(kind string, provisioner FixtureProvisioner) {
+	provisioners[kind] = provisioner
+}
+
+// FixtureKinds returns the kinds of preview fixtures which can be provisioned
+func FixtureKinds() []string {
+	answer := []string{}
+	for k := range provisioners {
+		answer = append(answer, k)
+	}
+	sort.Strings(answer)
+	return answer
+}
+
+func provisionerFor(fixture *config.PreviewFixture) (FixtureProvisioner, error) {
+	provisioner := provisioners[fixture.Kind]
+	if provisioner == nil {
+		return nil, fmt.Errorf("preview fixture %s has unknown kind %q. Supported kinds are: %s", fixture.Name, fixture.Kind, strings.Join(FixtureKinds(), ", "))
+	}
+	return provisioner, nil
+}
+
+// ProvisionFixtures provisions the fixtures which are not provisioned yet and stores their connection details in
+// Secrets in the preview namespace
+func ProvisionFixtures(ctx *FixtureContext, fixtures []config.PreviewFixture) error {
+	previewClient := ctx.PreviewKubeClient
+	if previewClient == nil {
+		previewClient = ctx.KubeClient
+	}
+	secrets := previewClient.CoreV1().Secrets(ctx.PreviewNamespace)
+	for i := range fixtures {
+		fixture := &fixtures[i]
+		provisioner, err := provisionerFor(fixture)
+		if err != nil {
+			return err
+		}
+		_, err = secrets.Get(fixture.SecretName(), metav1.GetOptions{})
+		if err == nil {
+			log.Logger().Debugf("preview fixture %s is already provisioned", fixture.Name)
+			continue
+		}
+		if !apierrors.IsNotFound(err) {
+			return errors.Wrapf(err, "getting the Secret %s of preview fixture %s", fixture.SecretName(), fixture.Name)
+		}
+		log.Logger().Infof("Provisioning %s preview fixture %s", fixture.Kind, util.ColorInfo(fixture.Name))
+		details, err := provisioner.Provision(ctx, fixture)
+		if err != nil {
+			return errors.Wrapf(err, "provisioning preview fixture %s", fixture.Name)
+		}
+		secret := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: fixture.SecretName(),
+				Labels: map[string]string{
+					LabelPreviewFixture: fixture.Name,
+				},
+			},
+			StringData: details,
+		}
+		_, err = secrets.Create(secret)
+		if err != nil {
+			return errors.Wrapf(err, "creating the Secret %s of preview fixture %s", fixture.SecretName(), fixture.Name)
+		}
+	}
+	return nil
+}
+
+// DestroyFixtures destroys the fixtures recorded on the preview environment
+func DestroyFixtures(ctx *FixtureContext, env *v1.Environment) error {
+	fixtures, err := FixturesOf(env)
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for i := range fixtures {
+		fixture := &fixtures[i]
+		provisioner, err := provisionerFor(fixture)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		log.Logger().Infof("Destroying %s preview fixture %s", fixture.Kind, util.ColorInfo(fixture.Name))
+		err = provisioner.Destroy(ctx, fixture)
+		if err != nil {
+			errs = append(errs, errors.Wrapf(err, "destroying preview fixture %s", fixture.Name))
+		}
+	}
+	return errorutil.CombineErrors(errs...)
+}
+
+// AddFixtures records the fixtures on the preview environment so that they can be destroyed with it, keeping the
+// fixtures recorded before which are no longer configured, returning true if the annotation changed
+func AddFixtures(env *v1.Environment, fixtures []config.PreviewFixture) (bool, error) {
+	if env.Annotations == nil {
+		env.Annotations = map[string]string{}
+	}
+	recorded, err := FixturesOf(env)
+	if err != nil {
+		return false, err
+	}
+	all := append([]config.PreviewFixture{}, fixtures...)
+	names := map[string]bool{}
+	for _, f := range fixtures {
+		names[f.Name] = true
+	}
+	for _, f := range recorded {
+		if !names[f.Name] {
+			all = append(all, f)
+		}
+	}
+	value := ""
+	if len(all) > 0 {
+		data, err := json.Marshal(all)
+		if err != nil {
+			return false, errors.Wrap(err, "marshalling preview fixtures")
+		}
+		value = string(data)
+	}
+	if env.Annotations[kube.AnnotationPreviewFixtures] == value {
+		return false, nil
+	}
+	if value == "" {
+		delete(env.Annotations, kube.AnnotationPreviewFixtures)
+	} else {
+		env.Annotations[kube.AnnotationPreviewFixtures] = value
+	}
+	return true, nil
+}
+
+// FixturesOf returns the fixtures recorded on the preview environment
+func FixturesOf(env *v1.Environment) ([]config.PreviewFixture, error) {
+	value := env.Annotations[kube.AnnotationPreviewFixtures]
+	if value == "" {
+		return nil, nil
+	}
+	var fixtures []config.PreviewFixture
+	err := json.Unmarshal([]byte(value), &fixtures)
+	if err != nil {
+		return nil, errors.Wrapf(err, "parsing the %s annotation of preview environment %s", kube.AnnotationPreviewFixtures, env.Name)
+	}
+	return fixtures, nil
+}
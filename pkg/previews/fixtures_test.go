@@ -0,0 +1,92 @@
+// +build unit
+
+package previews_test
+
+import (
+	"testing"
+
+	v1 "github.com/jenkins-x/jx/v2/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/v2/pkg/config"
+	"github.com/jenkins-x/jx/v2/pkg/previews"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+type fakeProvisioner struct {
+	provisioned []string
+	destroyed   []string
+}
+
+func (p *fakeProvisioner) Provision(ctx *previews.FixtureContext, fixture *config.PreviewFixture) (map[string]string, error) {
+	p.provisioned = append(p.provisioned, fixture.Name)
+	return map[string]string{"url": "fake://" + ctx.PreviewName + "/" + fixture.Name}, nil
+}
+
+func (p *fakeProvisioner) Destroy(ctx *previews.FixtureContext, fixture *config.PreviewFixture) error {
+	p.destroyed = append(p.destroyed, fixture.Name)
+	return nil
+}
+
+func TestProvisionAndDestroyFixtures(t *testing.T) {
+	provisioner := &fakeProvisioner{}
+	previews.RegisterFixtureProvisioner("fake", provisioner)
+
+	ctx := &previews.FixtureContext{
+		KubeClient:       fake.NewSimpleClientset(),
+		DevNamespace:     "jx",
+		PreviewName:      "myorg-myapp-pr-1",
+		PreviewNamespace: "jx-myorg-myapp-pr-1",
+	}
+	fixtures := []config.PreviewFixture{
+		{Name: "db", Kind: "fake"},
+		{Name: "bucket", Kind: "fake", Secret: "bucket-credentials"},
+	}
+	env := &v1.Environment{ObjectMeta: metav1.ObjectMeta{Name: ctx.PreviewName}}
+	changed, err := previews.AddFixtures(env, fixtures)
+	require.NoError(t, err)
+	assert.True(t, changed)
+
+	err = previews.ProvisionFixtures(ctx, fixtures)
+	require.NoError(t, err)
+	secret, err := ctx.KubeClient.CoreV1().Secrets(ctx.PreviewNamespace).Get("bucket-credentials", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "fake://myorg-myapp-pr-1/bucket", secret.StringData["url"])
+
+	// fixtures are only provisioned once
+	err = previews.ProvisionFixtures(ctx, fixtures)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"db", "bucket"}, provisioner.provisioned)
+
+	// fixtures which are no longer configured are still destroyed with the preview environment
+	changed, err = previews.AddFixtures(env, fixtures[:1])
+	require.NoError(t, err)
+	assert.False(t, changed)
+	err = previews.DestroyFixtures(ctx, env)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"db", "bucket"}, provisioner.destroyed)
+
+	err = previews.ProvisionFixtures(ctx, []config.PreviewFixture{{Name: "cache", Kind: "redis"}})
+	assert.Error(t, err)
+}
+
+func TestParseFixtureDetails(t *testing.T) {
+	t.Parallel()
+
+	details, err := previews.ParseFixtureDetails("# created the bucket\nBUCKET=gs://preview-1\n\nURL=https://host/path?a=b\n")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"BUCKET": "gs://preview-1", "URL": "https://host/path?a=b"}, details)
+
+	_, err = previews.ParseFixtureDetails("created the bucket")
+	assert.Error(t, err)
+}
+
+func TestPostgresSchemaName(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "preview_myorg_myapp_pr_1_db", previews.PostgresSchemaName("myorg-myapp-pr-1", "db"))
+	name := previews.PostgresSchemaName("a-very-long-organisation-name-and-a-very-long-repository-name-pr-123", "db")
+	assert.Len(t, name, 63)
+	assert.Contains(t, name, "pr_123_db")
+}
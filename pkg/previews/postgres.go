@@ -0,0 +1,238 @@
+package previews
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/jenkins-x/jx/v2/pkg/config"
+	"github.com/jenkins-x/jx/v2/pkg/kube"
+	"github.com/jenkins-x/jx/v2/pkg/kube/naming"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// DefaultPostgresAdminSecret the Secret in the dev namespace with the host, port, database, username and password
+	// of the shared Postgres database the schemas of the preview environments are created in
+	DefaultPostgresAdminSecret = "preview-postgres"
+
+	// DefaultPostgresImage the image of the jobs which create and drop the schemas
+	DefaultPostgresImage = "postgres:12"
+
+	postgresJobTimeout = 5 * time.Minute
+)
+
+var invalidIdentifierChars = regexp.MustCompile("[^a-z0-9_]+")
+
+// PostgresProvisioner provisions preview fixtures as a schema and role of the same name in a shared Postgres database,
+// so that the default search path of the role is its schema, seeded with the SQL file of the fixture
+//
+// The parameters of the fixture are `adminSecret`, the Secret in the dev namespace with the connection details of the
+// shared database, and `image`, the image with psql to run the jobs with
+type PostgresProvisioner struct {
+}
+
+// Provision creates the schema and role of the fixture and seeds the schema
+func (p *PostgresProvisioner) Provision(ctx *FixtureContext, fixture *config.PreviewFixture) (map[string]string, error) {
+	adminSecret, err := ctx.KubeClient.CoreV1().Secrets(ctx.DevNamespace).Get(postgresAdminSecret(fixture), metav1.GetOptions{})
+	if err != nil {
+		return nil, errors.Wrapf(err, "getting the Secret %s with the connection details of the shared Postgres database", postgresAdminSecret(fixture))
+	}
+	schema := PostgresSchemaName(ctx.PreviewName, fixture.Name)
+	password, err := util.RandStringBytesMaskImprSrc(24)
+	if err != nil {
+		return nil, errors.Wrap(err, "generating the password")
+	}
+
+	files := map[string]string{
+		"run.sql": fmt.Sprintf(`DO $$
+BEGIN
+  IF NOT EXISTS (SELECT FROM pg_roles WHERE rolname = '%[1]s') THEN
+    CREATE ROLE "%[1]s" LOGIN PASSWORD '%[2]s';
+  ELSE
+    ALTER ROLE "%[1]s" LOGIN PASSWORD '%[2]s';
+  END IF;
+END
+$$;
+GRANT "%[1]s" TO CURRENT_USER;
+CREATE SCHEMA IF NOT EXISTS "%[1]s" AUTHORIZATION "%[1]s";
+`, schema, password),
+		"run.sh": `set -e
+psql -v ON_ERROR_STOP=1 -f /fixture/run.sql
+if [ -f /fixture/seed.sql ]; then
+  PGUSER="$FIXTURE_USER" PGPASSWORD="$FIXTURE_PASSWORD" psql -v ON_ERROR_STOP=1 -f /fixture/seed.sql
+fi
+`,
+		"user":     schema,
+		"password": password,
+	}
+	if fixture.Seed != "" {
+		data, err := ioutil.ReadFile(filepath.Join(ctx.Dir, fixture.Seed))
+		if err != nil {
+			return nil, errors.Wrapf(err, "reading the seed %s", fixture.Seed)
+		}
+		files["seed.sql"] = string(data)
+	}
+	err = p.runJob(ctx, fixture, "create", files)
+	if err != nil {
+		return nil, err
+	}
+
+	host := string(adminSecret.Data["host"])
+	port := string(adminSecret.Data["port"])
+	if port == "" {
+		port = "5432"
+	}
+	database := string(adminSecret.Data["database"])
+	return map[string]string{
+		"host":     host,
+		"port":     port,
+		"database": database,
+		"schema":   schema,
+		"username": schema,
+		"password": password,
+		"url":      fmt.Sprintf("postgresql://%s:%s@%s:%s/%s", schema, password, host, port, database),
+	}, nil
+}
+
+// Destroy drops the schema and role of the fixture
+func (p *PostgresProvisioner) Destroy(ctx *FixtureContext, fixture *config.PreviewFixture) error {
+	schema := PostgresSchemaName(ctx.PreviewName, fixture.Name)
+	files := map[string]string{
+		"run.sql": fmt.Sprintf(`DROP SCHEMA IF EXISTS "%[1]s" CASCADE;
+DROP ROLE IF EXISTS "%[1]s";
+`, schema),
+		"run.sh": "psql -v ON_ERROR_STOP=1 -f /fixture/run.sql\n",
+	}
+	return p.runJob(ctx, fixture, "drop", files)
+}
+
+// runJob runs the script of the files in a job in the dev namespace, the files being stored in a Secret as they can
+// contain the password of the role
+func (p *PostgresProvisioner) runJob(ctx *FixtureContext, fixture *config.PreviewFixture, action string, files map[string]string) error {
+	name := naming.ToValidNameTruncated(fmt.Sprintf("%s-%s-%s", ctx.PreviewName, fixture.Name, action), 63)
+	secrets := ctx.KubeClient.CoreV1().Secrets(ctx.DevNamespace)
+	_, err := secrets.Create(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+			Labels: map[string]string{
+				LabelPreviewFixture: fixture.Name,
+			},
+		},
+		StringData: files,
+	})
+	if err != nil {
+		return errors.Wrapf(err, "creating the Secret %s", name)
+	}
+	defer secrets.Delete(name, &metav1.DeleteOptions{}) //nolint:errcheck
+
+	adminSecret := postgresAdminSecret(fixture)
+	secretEnv := func(name string, secret string, key string) corev1.EnvVar {
+		return corev1.EnvVar{
+			Name: name,
+			ValueFrom: &corev1.EnvVarSource{
+				SecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: secret},
+					Key:                  key,
+				},
+			},
+		}
+	}
+	image := fixture.Parameters["image"]
+	if image == "" {
+		image = DefaultPostgresImage
+	}
+	backoffLimit := int32(0)
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+			Labels: map[string]string{
+				LabelPreviewFixture: fixture.Name,
+			},
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit: &backoffLimit,
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					RestartPolicy: corev1.RestartPolicyNever,
+					Containers: []corev1.Container{
+						{
+							Name:    "psql",
+							Image:   image,
+							Command: []string{"sh", "/fixture/run.sh"},
+							Env: []corev1.EnvVar{
+								secretEnv("PGHOST", adminSecret, "host"),
+								secretEnv("PGPORT", adminSecret, "port"),
+								secretEnv("PGDATABASE", adminSecret, "database"),
+								secretEnv("PGUSER", adminSecret, "username"),
+								secretEnv("PGPASSWORD", adminSecret, "password"),
+								secretEnv("FIXTURE_USER", name, "user"),
+								secretEnv("FIXTURE_PASSWORD", name, "password"),
+							},
+							VolumeMounts: []corev1.VolumeMount{
+								{
+									Name:      "fixture",
+									MountPath: "/fixture",
+								},
+							},
+						},
+					},
+					Volumes: []corev1.Volume{
+						{
+							Name: "fixture",
+							VolumeSource: corev1.VolumeSource{
+								Secret: &corev1.SecretVolumeSource{SecretName: name},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	if action == "drop" {
+		// the role and password are only needed to seed the schema
+		job.Spec.Template.Spec.Containers[0].Env = job.Spec.Template.Spec.Containers[0].Env[:5]
+	}
+	jobs := ctx.KubeClient.BatchV1().Jobs(ctx.DevNamespace)
+	_, err = jobs.Create(job)
+	if err != nil {
+		return errors.Wrapf(err, "creating the job %s", name)
+	}
+	defer kube.DeleteJob(ctx.KubeClient, ctx.DevNamespace, name) //nolint:errcheck
+
+	err = kube.WaitForJobToFinish(ctx.KubeClient, ctx.DevNamespace, name, postgresJobTimeout, false)
+	if err != nil {
+		return err
+	}
+	job, err = jobs.Get(name, metav1.GetOptions{})
+	if err != nil {
+		return errors.Wrapf(err, "getting the job %s", name)
+	}
+	if !kube.IsJobSucceeded(job) {
+		return fmt.Errorf("job %s failed to %s the schema, see its logs via: kubectl logs -n %s job/%s", name, action, ctx.DevNamespace, name)
+	}
+	return nil
+}
+
+func postgresAdminSecret(fixture *config.PreviewFixture) string {
+	if secret := fixture.Parameters["adminSecret"]; secret != "" {
+		return secret
+	}
+	return DefaultPostgresAdminSecret
+}
+
+// PostgresSchemaName returns the name of the schema and role of a postgres fixture of a preview environment
+func PostgresSchemaName(previewName string, fixtureName string) string {
+	name := invalidIdentifierChars.ReplaceAllString(strings.ToLower("preview_"+previewName+"_"+fixtureName), "_")
+	if len(name) > 63 {
+		name = name[len(name)-63:]
+	}
+	return name
+}
@@ -0,0 +1,141 @@
+package previews
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/jenkins-x/jx/v2/pkg/config"
+	"github.com/jenkins-x/jx/v2/pkg/kube"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// PreviewBasicAuthSecret the Secret in the preview namespace with the generated basic auth credentials
+	PreviewBasicAuthSecret = "preview-basic-auth"
+
+	// DefaultPreviewUsername the user name of the generated basic auth credentials by default
+	DefaultPreviewUsername = "preview"
+
+	annotationAuthType   = "nginx.ingress.kubernetes.io/auth-type"
+	annotationAuthSecret = "nginx.ingress.kubernetes.io/auth-secret"
+	annotationAuthRealm  = "nginx.ingress.kubernetes.io/auth-realm"
+	annotationAuthURL    = "nginx.ingress.kubernetes.io/auth-url"
+	annotationAuthSignin = "nginx.ingress.kubernetes.io/auth-signin"
+)
+
+// ProtectionAnnotations returns the annotations of the NGINX ingress controller which protect an ingress of a preview
+// environment
+func ProtectionAnnotations(c *config.PreviewProtectionConfig) (map[string]string, error) {
+	switch c.Kind {
+	case config.PreviewProtectionBasic:
+		return map[string]string{
+			annotationAuthType:   "basic",
+			annotationAuthSecret: PreviewBasicAuthSecret,
+			annotationAuthRealm:  "Authentication is required to access this preview environment",
+		}, nil
+	case config.PreviewProtectionOAuth2:
+		u, err := url.Parse(c.OAuth2ProxyURL)
+		if err != nil || u.Host == "" {
+			return nil, fmt.Errorf("invalid oauth2ProxyURL %q of the previews protection", c.OAuth2ProxyURL)
+		}
+		proxyURL := strings.TrimSuffix(c.OAuth2ProxyURL, "/")
+		return map[string]string{
+			annotationAuthURL:    proxyURL + "/oauth2/auth",
+			annotationAuthSignin: proxyURL + "/oauth2/start?rd=$scheme://$host$escaped_request_uri",
+		}, nil
+	default:
+		return nil, fmt.Errorf("invalid previews protection kind %q", c.Kind)
+	}
+}
+
+// ProtectPreview protects the ingresses of the preview namespace, generating the basic auth credentials the first
+// time, and returns the instructions on how to access the preview environment
+func ProtectPreview(kubeClient kubernetes.Interface, ns string, c *config.PreviewProtectionConfig) (string, error) {
+	if c == nil {
+		return "", nil
+	}
+	annotations, err := ProtectionAnnotations(c)
+	if err != nil {
+		return "", err
+	}
+	instructions := c.Instructions
+	if c.Kind == config.PreviewProtectionBasic {
+		username, password, err := ensureBasicAuthSecret(kubeClient, ns, c)
+		if err != nil {
+			return "", err
+		}
+		if instructions == "" {
+			instructions = fmt.Sprintf("The preview environment is protected by basic auth with the user name `%s` and password `%s`", username, password)
+		}
+	} else if instructions == "" {
+		instructions = fmt.Sprintf("The preview environment requires you to sign in via %s", c.OAuth2ProxyURL)
+	}
+
+	ingresses := kubeClient.ExtensionsV1beta1().Ingresses(ns)
+	list, err := ingresses.List(metav1.ListOptions{})
+	if err != nil {
+		return "", errors.Wrapf(err, "listing the ingresses in namespace %s", ns)
+	}
+	for i := range list.Items {
+		ing := &list.Items[i]
+		if ing.Annotations == nil {
+			ing.Annotations = map[string]string{}
+		}
+		modified := false
+		for k, v := range annotations {
+			if ing.Annotations[k] != v {
+				ing.Annotations[k] = v
+				modified = true
+			}
+		}
+		if modified {
+			_, err = ingresses.Update(ing)
+			if err != nil {
+				return "", errors.Wrapf(err, "protecting ingress %s", ing.Name)
+			}
+		}
+	}
+	return instructions, nil
+}
+
+// ensureBasicAuthSecret returns the basic auth credentials of the preview namespace, generating them the first time
+// so that they stay the same for each build of the preview environment
+func ensureBasicAuthSecret(kubeClient kubernetes.Interface, ns string, c *config.PreviewProtectionConfig) (string, string, error) {
+	secrets := kubeClient.CoreV1().Secrets(ns)
+	secret, err := secrets.Get(PreviewBasicAuthSecret, metav1.GetOptions{})
+	if err == nil {
+		return string(secret.Data[kube.SecretDataUsername]), string(secret.Data[kube.SecretDataPassword]), nil
+	}
+	if !apierrors.IsNotFound(err) {
+		return "", "", errors.Wrapf(err, "getting the Secret %s", PreviewBasicAuthSecret)
+	}
+	username := c.Username
+	if username == "" {
+		username = DefaultPreviewUsername
+	}
+	password, err := util.RandStringBytesMaskImprSrc(20)
+	if err != nil {
+		return "", "", errors.Wrap(err, "generating the password")
+	}
+	secret = &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: PreviewBasicAuthSecret,
+		},
+		Data: map[string][]byte{
+			kube.AUTH:               []byte(fmt.Sprintf("%s:{SHA}%s", username, util.HashPassword(password))),
+			kube.SecretDataUsername: []byte(username),
+			kube.SecretDataPassword: []byte(password),
+		},
+	}
+	_, err = secrets.Create(secret)
+	if err != nil {
+		return "", "", errors.Wrapf(err, "creating the Secret %s", PreviewBasicAuthSecret)
+	}
+	return username, password, nil
+}
@@ -0,0 +1,60 @@
+// +build unit
+
+package previews_test
+
+import (
+	"testing"
+
+	"github.com/jenkins-x/jx/v2/pkg/config"
+	"github.com/jenkins-x/jx/v2/pkg/previews"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/api/extensions/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestProtectPreviewWithBasicAuth(t *testing.T) {
+	t.Parallel()
+
+	ns := "jx-myorg-myapp-pr-1"
+	kubeClient := fake.NewSimpleClientset(&v1beta1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{Name: "myapp", Namespace: ns},
+	})
+	c := &config.PreviewProtectionConfig{Kind: config.PreviewProtectionBasic}
+
+	access, err := previews.ProtectPreview(kubeClient, ns, c)
+	require.NoError(t, err)
+	assert.Contains(t, access, "user name `preview`")
+
+	secret, err := kubeClient.CoreV1().Secrets(ns).Get(previews.PreviewBasicAuthSecret, metav1.GetOptions{})
+	require.NoError(t, err)
+	password := string(secret.Data["password"])
+	assert.Contains(t, access, password)
+	assert.Contains(t, string(secret.Data["auth"]), "preview:{SHA}")
+
+	ing, err := kubeClient.ExtensionsV1beta1().Ingresses(ns).Get("myapp", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "basic", ing.Annotations["nginx.ingress.kubernetes.io/auth-type"])
+	assert.Equal(t, previews.PreviewBasicAuthSecret, ing.Annotations["nginx.ingress.kubernetes.io/auth-secret"])
+
+	// the credentials stay the same for later builds of the preview
+	again, err := previews.ProtectPreview(kubeClient, ns, c)
+	require.NoError(t, err)
+	assert.Equal(t, access, again)
+}
+
+func TestProtectionAnnotationsForOAuth2(t *testing.T) {
+	t.Parallel()
+
+	annotations, err := previews.ProtectionAnnotations(&config.PreviewProtectionConfig{
+		Kind:           config.PreviewProtectionOAuth2,
+		OAuth2ProxyURL: "https://oauth2.example.com/",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "https://oauth2.example.com/oauth2/auth", annotations["nginx.ingress.kubernetes.io/auth-url"])
+	assert.Equal(t, "https://oauth2.example.com/oauth2/start?rd=$scheme://$host$escaped_request_uri", annotations["nginx.ingress.kubernetes.io/auth-signin"])
+
+	_, err = previews.ProtectionAnnotations(&config.PreviewProtectionConfig{Kind: config.PreviewProtectionOAuth2, OAuth2ProxyURL: "oauth2"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,203 @@
+package previews
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jenkins-x/jx/v2/pkg/config"
+	"github.com/jenkins-x/jx/v2/pkg/kube/services"
+	"github.com/jenkins-x/jx/v2/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// DefaultKEDANamespace the namespace the KEDA HTTP add-on is installed in by default
+	DefaultKEDANamespace = "keda"
+
+	// KEDAInterceptorService the service of the KEDA HTTP add-on which holds requests until the deployment is scaled up
+	KEDAInterceptorService = "keda-add-ons-http-interceptor-proxy"
+
+	// KEDAInterceptorPort the port of the interceptor service
+	KEDAInterceptorPort = 8080
+
+	// DefaultScaleDownPeriod how long a preview environment receives no requests before it is scaled to zero by default
+	DefaultScaleDownPeriod = 5 * time.Minute
+)
+
+var (
+	// HTTPScaledObjectResource the resource of the HTTPScaledObjects of the KEDA HTTP add-on
+	HTTPScaledObjectResource = schema.GroupVersionResource{Group: "http.keda.sh", Version: "v1alpha1", Resource: "httpscaledobjects"}
+)
+
+// ScaleDownPeriod returns how long a preview environment receives no requests before it is scaled to zero
+func ScaleDownPeriod(c *config.PreviewScaleToZeroConfig) time.Duration {
+	if c == nil || c.ScaleDownPeriod == "" {
+		return DefaultScaleDownPeriod
+	}
+	d, err := time.ParseDuration(c.ScaleDownPeriod)
+	if err != nil {
+		log.Logger().Warnf("invalid scaleDownPeriod %s of the previews so using %s: %s", c.ScaleDownPeriod, DefaultScaleDownPeriod, err)
+		return DefaultScaleDownPeriod
+	}
+	return d
+}
+
+// EnableKEDAScaleToZero routes the ingresses of the preview namespace through the interceptor of the KEDA HTTP add-on
+// and creates a HTTPScaledObject for the deployment behind each of them, so that the deployments are scaled to zero
+// when idle and scaled up again by their next request
+func EnableKEDAScaleToZero(kubeClient kubernetes.Interface, dynamicClient dynamic.Interface, ns string, c *config.PreviewScaleToZeroConfig) error {
+	kedaNs := DefaultKEDANamespace
+	if c != nil && c.KEDANamespace != "" {
+		kedaNs = c.KEDANamespace
+	}
+	present, err := services.IsServicePresent(kubeClient, KEDAInterceptorService, ns)
+	if err != nil {
+		return errors.Wrapf(err, "checking for the service %s in namespace %s", KEDAInterceptorService, ns)
+	}
+	if !present {
+		err = services.CreateServiceLink(kubeClient, ns, kedaNs, KEDAInterceptorService, "")
+		if err != nil {
+			return errors.Wrapf(err, "linking the KEDA interceptor into namespace %s", ns)
+		}
+	}
+
+	deployments, err := kubeClient.AppsV1().Deployments(ns).List(metav1.ListOptions{})
+	if err != nil {
+		return errors.Wrapf(err, "listing the deployments in namespace %s", ns)
+	}
+	ingresses := kubeClient.ExtensionsV1beta1().Ingresses(ns)
+	ingressList, err := ingresses.List(metav1.ListOptions{})
+	if err != nil {
+		return errors.Wrapf(err, "listing the ingresses in namespace %s", ns)
+	}
+	period := ScaleDownPeriod(c)
+	for i := range ingressList.Items {
+		ing := &ingressList.Items[i]
+		modified := false
+		for _, rule := range ing.Spec.Rules {
+			if rule.HTTP == nil || rule.Host == "" {
+				continue
+			}
+			for j := range rule.HTTP.Paths {
+				backend := &rule.HTTP.Paths[j].Backend
+				if backend.ServiceName == KEDAInterceptorService {
+					continue
+				}
+				svc, err := kubeClient.CoreV1().Services(ns).Get(backend.ServiceName, metav1.GetOptions{})
+				if err != nil {
+					return errors.Wrapf(err, "getting the service %s of ingress %s", backend.ServiceName, ing.Name)
+				}
+				deployment := findServiceDeployment(svc, deployments.Items)
+				if deployment == nil {
+					log.Logger().Warnf("not scaling the service %s of ingress %s to zero as no deployment was found for it", svc.Name, ing.Name)
+					continue
+				}
+				port, err := servicePort(svc, backend.ServicePort)
+				if err != nil {
+					return err
+				}
+				err = applyHTTPScaledObject(dynamicClient, ns, HTTPScaledObject(deployment.Name, svc.Name, port, rule.Host, period))
+				if err != nil {
+					return err
+				}
+				backend.ServiceName = KEDAInterceptorService
+				backend.ServicePort = intstr.FromInt(KEDAInterceptorPort)
+				modified = true
+				log.Logger().Infof("Deployment %s of the preview scales to zero when idle", util.ColorInfo(deployment.Name))
+			}
+		}
+		if modified {
+			_, err = ingresses.Update(ing)
+			if err != nil {
+				return errors.Wrapf(err, "routing ingress %s through the KEDA interceptor", ing.Name)
+			}
+		}
+	}
+	return nil
+}
+
+// HTTPScaledObject returns the HTTPScaledObject which scales the deployment behind the service between zero and one
+// replica for the requests to the host
+func HTTPScaledObject(deployment string, service string, port int32, host string, scaleDownPeriod time.Duration) *unstructured.Unstructured {
+	return &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": HTTPScaledObjectResource.GroupVersion().String(),
+			"kind":       "HTTPScaledObject",
+			"metadata": map[string]interface{}{
+				"name": deployment,
+			},
+			"spec": map[string]interface{}{
+				"hosts": []interface{}{host},
+				"scaleTargetRef": map[string]interface{}{
+					"deployment": deployment,
+					"service":    service,
+					"port":       int64(port),
+				},
+				"replicas": map[string]interface{}{
+					"min": int64(0),
+					"max": int64(1),
+				},
+				"scaledownPeriod": int64(scaleDownPeriod.Seconds()),
+			},
+		},
+	}
+}
+
+func applyHTTPScaledObject(dynamicClient dynamic.Interface, ns string, object *unstructured.Unstructured) error {
+	resources := dynamicClient.Resource(HTTPScaledObjectResource).Namespace(ns)
+	existing, err := resources.Get(object.GetName(), metav1.GetOptions{})
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			return errors.Wrapf(err, "getting HTTPScaledObject %s", object.GetName())
+		}
+		_, err = resources.Create(object, metav1.CreateOptions{})
+		if err != nil {
+			return errors.Wrapf(err, "creating HTTPScaledObject %s. Is the KEDA HTTP add-on installed?", object.GetName())
+		}
+		return nil
+	}
+	existing.Object["spec"] = object.Object["spec"]
+	_, err = resources.Update(existing, metav1.UpdateOptions{})
+	if err != nil {
+		return errors.Wrapf(err, "updating HTTPScaledObject %s", object.GetName())
+	}
+	return nil
+}
+
+// findServiceDeployment returns the deployment whose pods the service selects
+func findServiceDeployment(svc *corev1.Service, deployments []appsv1.Deployment) *appsv1.Deployment {
+	if len(svc.Spec.Selector) == 0 {
+		return nil
+	}
+	selector := labels.SelectorFromSet(svc.Spec.Selector)
+	for i := range deployments {
+		if selector.Matches(labels.Set(deployments[i].Spec.Template.Labels)) {
+			return &deployments[i]
+		}
+	}
+	return nil
+}
+
+// servicePort returns the number of the port of the service an ingress backend refers to
+func servicePort(svc *corev1.Service, port intstr.IntOrString) (int32, error) {
+	for _, p := range svc.Spec.Ports {
+		if (port.Type == intstr.Int && p.Port == port.IntVal) || (port.Type == intstr.String && p.Name == port.StrVal) {
+			return p.Port, nil
+		}
+	}
+	if port.Type == intstr.Int {
+		return port.IntVal, nil
+	}
+	return 0, fmt.Errorf("service %s has no port %s", svc.Name, port.String())
+}
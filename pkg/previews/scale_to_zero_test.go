@@ -0,0 +1,95 @@
+// +build unit
+
+package previews_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jenkins-x/jx/v2/pkg/config"
+	"github.com/jenkins-x/jx/v2/pkg/previews"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/api/extensions/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestEnableKEDAScaleToZero(t *testing.T) {
+	t.Parallel()
+
+	ns := "jx-myorg-myapp-pr-1"
+	kubeClient := fake.NewSimpleClientset(
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "preview-myapp", Namespace: ns},
+			Spec: appsv1.DeploymentSpec{
+				Template: corev1.PodTemplateSpec{
+					ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "preview-myapp"}},
+				},
+			},
+		},
+		&corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: "myapp", Namespace: ns},
+			Spec: corev1.ServiceSpec{
+				Selector: map[string]string{"app": "preview-myapp"},
+				Ports:    []corev1.ServicePort{{Name: "http", Port: 80}},
+			},
+		},
+		&v1beta1.Ingress{
+			ObjectMeta: metav1.ObjectMeta{Name: "myapp", Namespace: ns},
+			Spec: v1beta1.IngressSpec{
+				Rules: []v1beta1.IngressRule{{
+					Host: "myapp.jx-myorg-myapp-pr-1.example.com",
+					IngressRuleValue: v1beta1.IngressRuleValue{
+						HTTP: &v1beta1.HTTPIngressRuleValue{
+							Paths: []v1beta1.HTTPIngressPath{{
+								Backend: v1beta1.IngressBackend{ServiceName: "myapp", ServicePort: intstr.FromInt(80)},
+							}},
+						},
+					},
+				}},
+			},
+		},
+	)
+	dynamicClient := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme())
+	c := &config.PreviewScaleToZeroConfig{Enabled: true, Provider: config.ScaleToZeroProviderKEDA, ScaleDownPeriod: "10m"}
+
+	err := previews.EnableKEDAScaleToZero(kubeClient, dynamicClient, ns, c)
+	require.NoError(t, err)
+
+	link, err := kubeClient.CoreV1().Services(ns).Get(previews.KEDAInterceptorService, metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "keda-add-ons-http-interceptor-proxy.keda.svc.cluster.local", link.Spec.ExternalName)
+
+	ing, err := kubeClient.ExtensionsV1beta1().Ingresses(ns).Get("myapp", metav1.GetOptions{})
+	require.NoError(t, err)
+	backend := ing.Spec.Rules[0].HTTP.Paths[0].Backend
+	assert.Equal(t, previews.KEDAInterceptorService, backend.ServiceName)
+	assert.Equal(t, int32(previews.KEDAInterceptorPort), backend.ServicePort.IntVal)
+
+	scaledObject, err := dynamicClient.Resource(previews.HTTPScaledObjectResource).Namespace(ns).Get("preview-myapp", metav1.GetOptions{})
+	require.NoError(t, err)
+	hosts, _, _ := unstructured.NestedStringSlice(scaledObject.Object, "spec", "hosts")
+	assert.Equal(t, []string{"myapp.jx-myorg-myapp-pr-1.example.com"}, hosts)
+	service, _, _ := unstructured.NestedString(scaledObject.Object, "spec", "scaleTargetRef", "service")
+	assert.Equal(t, "myapp", service)
+	period, _, _ := unstructured.NestedInt64(scaledObject.Object, "spec", "scaledownPeriod")
+	assert.Equal(t, int64(600), period)
+
+	// enabling it again leaves the ingresses which are already routed through the interceptor
+	err = previews.EnableKEDAScaleToZero(kubeClient, dynamicClient, ns, c)
+	require.NoError(t, err)
+}
+
+func TestScaleDownPeriod(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, previews.DefaultScaleDownPeriod, previews.ScaleDownPeriod(nil))
+	assert.Equal(t, 90*time.Second, previews.ScaleDownPeriod(&config.PreviewScaleToZeroConfig{ScaleDownPeriod: "90s"}))
+}
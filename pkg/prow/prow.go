@@ -7,6 +7,7 @@ import (
 	"strings"
 
 	v1 "github.com/jenkins-x/jx/v2/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/v2/pkg/boot"
 	"github.com/jenkins-x/jx/v2/pkg/kube"
 	"github.com/jenkins-x/jx/v2/pkg/util"
 
@@ -132,6 +133,36 @@ func AddExternalPlugins(kubeClient kubernetes.Interface, repos []string, ns stri
 	return nil
 }
 
+// AddUpgradeBootCommand makes commenting boot.UpgradeCommand on a pull request of the dev environment repositories
+// run the boot upgrade pipeline
+func AddUpgradeBootCommand(kubeClient kubernetes.Interface, repos []string, ns string) error {
+	o := Options{
+		KubeClient: kubeClient,
+		Repos:      repos,
+		NS:         ns,
+		Agent:      TektonAgent,
+	}
+	prowConfig, create, err := o.GetProwConfig()
+	if err != nil {
+		return errors.Wrap(err, "getting prow config")
+	}
+	preSubmit := o.createPreSubmitUpgradeBoot()
+	for _, r := range repos {
+		found := false
+		for i, j := range prowConfig.Presubmits[r] {
+			if j.Name == preSubmit.Name {
+				found = true
+				prowConfig.Presubmits[r][i] = preSubmit
+				break
+			}
+		}
+		if !found {
+			prowConfig.Presubmits[r] = append(prowConfig.Presubmits[r], preSubmit)
+		}
+	}
+	return o.saveProwConfig(prowConfig, create)
+}
+
 // create Git repo?
 // get config and update / overwrite repos?
 // should we get the existing CM and do a diff?
@@ -151,6 +182,21 @@ func (o *Options) createPreSubmitEnvironment() config.Presubmit {
 	return ps
 }
 
+func (o *Options) createPreSubmitUpgradeBoot() config.Presubmit {
+	ps := config.Presubmit{}
+
+	ps.Name = boot.UpgradePipelineContext
+	ps.Context = boot.UpgradePipelineContext
+	ps.AlwaysRun = false
+	ps.Optional = true
+	ps.SkipReport = false
+	ps.Agent = o.Agent
+	ps.RerunCommand = boot.UpgradeCommand
+	ps.Trigger = "(?m)^" + boot.UpgradeCommand + "\\s*$"
+
+	return ps
+}
+
 func (o *Options) createPostSubmitEnvironment() config.Postsubmit {
 	ps := config.Postsubmit{}
 	ps.Name = "promotion"
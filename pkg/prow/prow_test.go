@@ -3,6 +3,7 @@
 package prow_test
 
 import (
+	"github.com/jenkins-x/jx/v2/pkg/boot"
 	"github.com/jenkins-x/jx/v2/pkg/gits"
 	"github.com/jenkins-x/jx/v2/pkg/kube"
 	"github.com/jenkins-x/jx/v2/pkg/prow"
@@ -280,6 +281,44 @@ func TestAddProwConfig(t *testing.T) {
 	}
 }
 
+func TestAddUpgradeBootCommand(t *testing.T) {
+	t.Parallel()
+	o := TestOptions{}
+	o.Setup()
+	o.Kind = prowconfig.Environment
+	o.EnvironmentNamespace = "jx"
+
+	cm := &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: kube.IngressConfigConfigmap,
+		},
+		Data: map[string]string{"domain": "dummy.domain.nip.io"},
+	}
+	_, err := o.KubeClient.CoreV1().ConfigMaps(o.NS).Create(cm)
+	assert.NoError(t, err)
+
+	err = o.AddProwConfig()
+	assert.NoError(t, err)
+
+	// adding the command twice should not duplicate the job
+	for i := 0; i < 2; i++ {
+		err = prow.AddUpgradeBootCommand(o.KubeClient, o.Repos, o.NS)
+		assert.NoError(t, err)
+	}
+
+	prowConfig, err := getProwConfig(t, o)
+	assert.NoError(t, err)
+
+	presubmits := prowConfig.Presubmits["test/repo"]
+	assert.Len(t, presubmits, 2)
+	upgrade := presubmits[1]
+	assert.Equal(t, boot.UpgradePipelineContext, upgrade.Name)
+	assert.False(t, upgrade.AlwaysRun)
+	assert.Equal(t, boot.UpgradeCommand, upgrade.RerunCommand)
+	assert.Regexp(t, upgrade.Trigger, "looks good\n/upgrade boot\n")
+	assert.NotRegexp(t, upgrade.Trigger, "/upgrade bootstrap")
+}
+
 func TestRemoveProwConfig(t *testing.T) {
 	t.Parallel()
 	o := TestOptions{}
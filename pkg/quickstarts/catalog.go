@@ -0,0 +1,231 @@
+package quickstarts
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	v1 "github.com/jenkins-x/jx/v2/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/v2/pkg/gits"
+	"github.com/jenkins-x/jx/v2/pkg/log"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/jenkins-x/jx/v2/pkg/versionstream"
+	"github.com/pkg/errors"
+	"gopkg.in/AlecAivazis/survey.v1"
+	"sigs.k8s.io/yaml"
+)
+
+// Catalog a catalog YAML file listing quickstarts and the template variables they use
+type Catalog struct {
+	versionstream.QuickStarts
+	Variables []v1.QuickStartVariable `json:"variables,omitempty"`
+}
+
+// ParseCatalog parses the YAML of a catalog of quickstarts
+func ParseCatalog(data []byte) (*Catalog, error) {
+	catalog := &Catalog{}
+	err := yaml.Unmarshal(data, catalog)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal the quickstarts catalog YAML")
+	}
+	catalog.DefaultMissingValues()
+	return catalog, nil
+}
+
+// LoadCatalog downloads the catalog of quickstarts, authenticating with the user of the git provider if the catalog
+// is in a private repository
+func LoadCatalog(u string, provider gits.GitProvider) (*Catalog, error) {
+	req, err := http.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	setBasicAuth(req, provider)
+	client := http.Client{}
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, errors.Wrapf(err, "downloading the quickstarts catalog %s", u)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("downloading the quickstarts catalog %s returned status %d", u, res.StatusCode)
+	}
+	data, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return nil, errors.Wrapf(err, "reading the quickstarts catalog %s", u)
+	}
+	catalog, err := ParseCatalog(data)
+	if err != nil {
+		return nil, errors.Wrapf(err, "parsing the quickstarts catalog %s", u)
+	}
+	return catalog, nil
+}
+
+// setBasicAuth authenticates the request with the user and API token of the git provider if it has them
+func setBasicAuth(req *http.Request, provider gits.GitProvider) {
+	if provider == nil {
+		return
+	}
+	userAuth := provider.UserAuth()
+	if userAuth.ApiToken != "" && userAuth.Username != "" {
+		log.Logger().Debugf("Requesting %s with basic auth for user: %s", req.URL.String(), userAuth.Username)
+		req.SetBasicAuth(userAuth.Username, userAuth.ApiToken)
+	}
+}
+
+// LoadCatalogQuickstarts adds the quickstarts of the catalog which match the includes and excludes of its location,
+// using the variables of the location in addition to those of the catalog
+func (model *QuickstartModel) LoadCatalogQuickstarts(provider gits.GitProvider, location *v1.QuickStartLocation, catalog *Catalog) {
+	variables := MergeVariables(catalog.Variables, location.Variables)
+	for _, from := range catalog.QuickStarts {
+		if from.ID == "" || !util.StringMatchesAny(from.Name, location.Includes, location.Excludes) {
+			continue
+		}
+		q := &Quickstart{GitProvider: provider}
+		err := model.convertToQuickStart(from, q)
+		if err != nil {
+			log.Logger().Warnf("failed to load quickstart %s of catalog %s: %s", from.ID, CatalogName(location), err.Error())
+			continue
+		}
+		q.Catalog = CatalogName(location)
+		q.Variables = variables
+		model.Add(q)
+	}
+}
+
+// LoadLocationQuickstarts adds the repositories of the owner of the location which match its includes and excludes
+// as the quickstarts of its catalog
+func (model *QuickstartModel) LoadLocationQuickstarts(provider gits.GitProvider, location *v1.QuickStartLocation) error {
+	repos, err := provider.ListRepositories(location.Owner)
+	if err != nil {
+		return err
+	}
+	for _, repo := range repos {
+		if util.StringMatchesAny(repo.Name, location.Includes, location.Excludes) {
+			q := toGitHubQuickstart(provider, location.Owner, repo)
+			q.Catalog = CatalogName(location)
+			q.Variables = location.Variables
+			model.Add(q)
+		}
+	}
+	return nil
+}
+
+// CatalogName returns the name of the catalog of the location which defaults to the owner
+func CatalogName(location *v1.QuickStartLocation) string {
+	if location.Name != "" {
+		return location.Name
+	}
+	return location.Owner
+}
+
+// MergeVariables returns the variables with the overrides replacing the variables of the same name
+func MergeVariables(variables []v1.QuickStartVariable, overrides []v1.QuickStartVariable) []v1.QuickStartVariable {
+	answer := append([]v1.QuickStartVariable{}, variables...)
+	for _, o := range overrides {
+		found := false
+		for i := range answer {
+			if answer[i].Name == o.Name {
+				answer[i] = o
+				found = true
+			}
+		}
+		if !found {
+			answer = append(answer, o)
+		}
+	}
+	return answer
+}
+
+// ResolveVariables returns the values of the template variables from the given values, asking for the missing ones
+// unless in batch mode where they default. Returns an error if a value is required or does not match the pattern of
+// its variable
+func ResolveVariables(variables []v1.QuickStartVariable, values map[string]string, batchMode bool, handles util.IOFileHandles) (map[string]string, error) {
+	surveyOpts := survey.WithStdio(handles.In, handles.Out, handles.Err)
+	answer := map[string]string{}
+	for _, variable := range variables {
+		value, ok := values[variable.Name]
+		if !ok {
+			value = variable.Default
+			if !batchMode {
+				message := variable.Description
+				if message == "" {
+					message = variable.Name
+				}
+				var prompt survey.Prompt = &survey.Input{
+					Message: message,
+					Default: variable.Default,
+				}
+				if len(variable.Values) > 0 {
+					prompt = &survey.Select{
+						Message: message,
+						Options: variable.Values,
+						Default: variable.Default,
+					}
+				}
+				err := survey.AskOne(prompt, &value, nil, surveyOpts)
+				if err != nil {
+					return nil, err
+				}
+			}
+		}
+		if value == "" && variable.Required {
+			return nil, util.MissingOption("var " + variable.Name)
+		}
+		if value != "" && len(variable.Values) > 0 && util.StringArrayIndex(variable.Values, value) < 0 {
+			return nil, util.InvalidOption("var "+variable.Name, value, variable.Values)
+		}
+		if value != "" && variable.Pattern != "" {
+			r, err := regexp.Compile(variable.Pattern)
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid pattern of quickstart variable %s", variable.Name)
+			}
+			if !r.MatchString(value) {
+				return nil, util.InvalidOptionf("var", value, "the value of %s should match %s", variable.Name, variable.Pattern)
+			}
+		}
+		answer[variable.Name] = value
+	}
+	return answer, nil
+}
+
+// VariablePlaceholder returns the placeholder of the variable in the source of a quickstart
+func VariablePlaceholder(name string) string {
+	name = strings.ToUpper(strings.NewReplacer("-", "_", ".", "_").Replace(name))
+	return util.PlaceHolderPrefix + "_" + name
+}
+
+// ReplaceVariables replaces the placeholders of the variables in the files of the generated quickstart
+func ReplaceVariables(dir string, values map[string]string) error {
+	if len(values) == 0 {
+		return nil
+	}
+	var oldnew []string
+	for _, name := range util.SortedMapKeys(values) {
+		oldnew = append(oldnew, VariablePlaceholder(name), values[name])
+	}
+	replacer := strings.NewReplacer(oldnew...)
+	return filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if info.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return errors.Wrapf(err, "reading %s", path)
+		}
+		text := replacer.Replace(string(data))
+		if text == string(data) {
+			return nil
+		}
+		return ioutil.WriteFile(path, []byte(text), info.Mode())
+	})
+}
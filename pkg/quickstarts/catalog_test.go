@@ -0,0 +1,108 @@
+// +build unit
+
+package quickstarts_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	v1 "github.com/jenkins-x/jx/v2/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/v2/pkg/quickstarts"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testCatalog = `
+defaultOwner: platform-quickstarts
+variables:
+- name: owner-team
+  description: The team owning the service
+  required: true
+  pattern: "^[a-z-]+$"
+- name: port
+  default: "8080"
+quickstarts:
+- name: spring-service
+  language: java
+  framework: spring
+  tags: [spring, java]
+  downloadZipURL: https://git.example.com/platform-quickstarts/spring-service/archive/master.zip
+- name: go-service
+  language: go
+  tags: [go]
+- name: WIP-rust-service
+  language: rust
+`
+
+func TestCatalogQuickstarts(t *testing.T) {
+	t.Parallel()
+	catalog, err := quickstarts.ParseCatalog([]byte(testCatalog))
+	require.NoError(t, err)
+	require.Len(t, catalog.QuickStarts, 3)
+	require.Len(t, catalog.Variables, 2)
+
+	location := &v1.QuickStartLocation{
+		Name:     "platform",
+		Excludes: []string{"WIP-*"},
+		Variables: []v1.QuickStartVariable{
+			{Name: "port", Default: "9090"},
+		},
+	}
+	model := quickstarts.NewQuickstartModel()
+	model.LoadCatalogQuickstarts(nil, location, catalog)
+	assert.Equal(t, []string{"platform-quickstarts/go-service", "platform-quickstarts/spring-service"}, model.SortedNames())
+
+	q := model.Quickstarts["platform-quickstarts/spring-service"]
+	assert.Equal(t, "platform", q.Catalog)
+	assert.Equal(t, "java", q.Language)
+	assert.Equal(t, "https://git.example.com/platform-quickstarts/spring-service/archive/master.zip", q.DownloadZipURL)
+	require.Len(t, q.Variables, 2)
+	assert.Equal(t, "9090", q.Variables[1].Default, "the variables of the location should override those of the catalog")
+
+	model.Add(&quickstarts.Quickstart{ID: "jenkins-x-quickstarts/spring-boot-rest", Name: "spring-boot-rest", Tags: []string{"spring"}})
+	results := model.Filter(&quickstarts.QuickstartFilter{Catalog: "platform"})
+	assert.Len(t, results, 2)
+	results = model.Filter(&quickstarts.QuickstartFilter{Tags: []string{"spring"}})
+	assert.Len(t, results, 2)
+	results = model.Filter(&quickstarts.QuickstartFilter{Catalog: "platform", Tags: []string{"spring"}})
+	require.Len(t, results, 1)
+	assert.Equal(t, q, results[0])
+}
+
+func TestResolveVariables(t *testing.T) {
+	t.Parallel()
+	variables := []v1.QuickStartVariable{
+		{Name: "owner-team", Required: true, Pattern: "^[a-z-]+$"},
+		{Name: "port", Default: "8080"},
+	}
+	handles := util.IOFileHandles{}
+
+	values, err := quickstarts.ResolveVariables(variables, map[string]string{"owner-team": "payments"}, true, handles)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"owner-team": "payments", "port": "8080"}, values)
+
+	_, err = quickstarts.ResolveVariables(variables, map[string]string{}, true, handles)
+	assert.Error(t, err, "a required variable should have a value")
+
+	_, err = quickstarts.ResolveVariables(variables, map[string]string{"owner-team": "Payments Team"}, true, handles)
+	assert.Error(t, err, "the value should match the pattern")
+}
+
+func TestReplaceVariables(t *testing.T) {
+	t.Parallel()
+	dir, err := ioutil.TempDir("", "quickstart")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	fileName := filepath.Join(dir, "values.yaml")
+	require.NoError(t, ioutil.WriteFile(fileName, []byte("team: REPLACE_ME_OWNER_TEAM\nport: REPLACE_ME_PORT\napp: REPLACE_ME_APP_NAME\n"), util.DefaultWritePermissions))
+
+	err = quickstarts.ReplaceVariables(dir, map[string]string{"owner-team": "payments", "port": "8080"})
+	require.NoError(t, err)
+	data, err := ioutil.ReadFile(fileName)
+	require.NoError(t, err)
+	assert.Equal(t, "team: payments\nport: 8080\napp: REPLACE_ME_APP_NAME\n", string(data))
+}
@@ -19,9 +19,18 @@ func (f *QuickstartFilter) Matches(q *Quickstart) bool {
 		return false
 	}
 	text := f.Text
-	if text != "" && !strings.Contains(q.ID, text) {
+	if text != "" && !strings.Contains(q.ID, text) && util.StringArrayIndex(q.Tags, text) < 0 {
 		return false
 	}
+	catalog := strings.ToLower(f.Catalog)
+	if catalog != "" && strings.ToLower(q.Catalog) != catalog {
+		return false
+	}
+	for _, tag := range f.Tags {
+		if util.StringArrayIndex(q.Tags, tag) < 0 {
+			return false
+		}
+	}
 	owner := strings.ToLower(f.Owner)
 	if owner != "" && strings.ToLower(q.Owner) != owner {
 		return false
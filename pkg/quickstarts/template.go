@@ -0,0 +1,205 @@
+package quickstarts
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+
+	v1 "github.com/jenkins-x/jx/v2/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// TemplateSchemaFileName the file of a quickstart declaring its template parameters
+	TemplateSchemaFileName = "quickstart.yaml"
+
+	defaultLeftDelim  = "{{"
+	defaultRightDelim = "}}"
+)
+
+var (
+	// defaultTemplateExcludes the files which are not rendered unless the schema has its own excludes. Helm charts
+	// use the same template syntax and are rendered when deployed
+	defaultTemplateExcludes = []string{".git/*", "charts/*"}
+)
+
+// TemplateSchema the schema of the parameters of a quickstart whose files are rendered as Go templates with their
+// values when the quickstart is created or imported
+type TemplateSchema struct {
+	// Parameters the parameters of the templates such as the group, the port or the kind of database
+	Parameters []v1.QuickStartVariable `json:"parameters,omitempty"`
+	// Includes the patterns of the paths of the files which are rendered. Defaults to all the files
+	Includes []string `json:"includes,omitempty"`
+	// Excludes the patterns of the paths of the files which are not rendered. Defaults to the .git and charts folders
+	Excludes []string `json:"excludes,omitempty"`
+	// LeftDelim the left delimiter of the templates which defaults to {{
+	LeftDelim string `json:"leftDelim,omitempty"`
+	// RightDelim the right delimiter of the templates which defaults to }}
+	RightDelim string `json:"rightDelim,omitempty"`
+}
+
+// TemplateData the data the files of a quickstart are rendered with
+type TemplateData struct {
+	// AppName the name of the application
+	AppName string
+	// Organisation the git organisation of the application
+	Organisation string
+	// Values the values of the parameters of the schema
+	Values map[string]string
+}
+
+// LoadTemplateSchema loads the template schema of the quickstart in the given directory, returning nil if it has none
+func LoadTemplateSchema(dir string) (*TemplateSchema, error) {
+	fileName := filepath.Join(dir, TemplateSchemaFileName)
+	exists, err := util.FileExists(fileName)
+	if err != nil || !exists {
+		return nil, err
+	}
+	data, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return nil, errors.Wrapf(err, "reading %s", fileName)
+	}
+	schema := &TemplateSchema{}
+	err = yaml.Unmarshal(data, schema)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unmarshalling %s", fileName)
+	}
+	return schema, nil
+}
+
+// TemplateFuncs the functions available to the templates of quickstarts
+func TemplateFuncs() template.FuncMap {
+	return template.FuncMap{
+		"lower": strings.ToLower,
+		"upper": strings.ToUpper,
+		"title": strings.Title,
+		"trim":  strings.TrimSpace,
+		"replace": func(old string, new string, text string) string {
+			return strings.Replace(text, old, new, -1)
+		},
+		// path converts a package or group such as com.example into the path com/example
+		"path": func(text string) string {
+			return strings.Replace(text, ".", "/", -1)
+		},
+	}
+}
+
+// Render renders the files of the quickstart in the given directory with the data, including the paths of the files
+// which contain templates, then removes the schema file
+func (s *TemplateSchema) Render(dir string, data *TemplateData) error {
+	leftDelim := s.LeftDelim
+	if leftDelim == "" {
+		leftDelim = defaultLeftDelim
+	}
+	rightDelim := s.RightDelim
+	if rightDelim == "" {
+		rightDelim = defaultRightDelim
+	}
+	excludes := s.Excludes
+	if len(excludes) == 0 {
+		excludes = defaultTemplateExcludes
+	}
+	render := func(name string, text string) (string, error) {
+		t, err := template.New(name).Delims(leftDelim, rightDelim).Funcs(TemplateFuncs()).Option("missingkey=error").Parse(text)
+		if err != nil {
+			return "", errors.Wrapf(err, "parsing the template %s", name)
+		}
+		var buffer bytes.Buffer
+		err = t.Execute(&buffer, data)
+		if err != nil {
+			return "", errors.Wrapf(err, "rendering the template %s", name)
+		}
+		return buffer.String(), nil
+	}
+
+	// collect the files first so that those moved by rendering their paths are not visited again
+	templateDirs := []string{}
+	files := []string{}
+	modes := map[string]os.FileMode{}
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if info.IsDir() {
+			if rel == ".git" {
+				return filepath.SkipDir
+			}
+			if strings.Contains(info.Name(), leftDelim) {
+				templateDirs = append(templateDirs, path)
+			}
+			return nil
+		}
+		if rel != TemplateSchemaFileName && util.StringMatchesAny(rel, s.Includes, excludes) {
+			files = append(files, rel)
+			modes[rel] = info.Mode()
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, rel := range files {
+		path := filepath.Join(dir, filepath.FromSlash(rel))
+		source, err := ioutil.ReadFile(path)
+		if err != nil {
+			return errors.Wrapf(err, "reading %s", path)
+		}
+		text, err := render(rel, string(source))
+		if err != nil {
+			return err
+		}
+		newPath := path
+		if strings.Contains(rel, leftDelim) {
+			newRel, err := render(rel, rel)
+			if err != nil {
+				return err
+			}
+			newPath = filepath.Join(dir, filepath.FromSlash(newRel))
+			err = os.MkdirAll(filepath.Dir(newPath), util.DefaultWritePermissions)
+			if err != nil {
+				return err
+			}
+		}
+		if newPath == path && text == string(source) {
+			continue
+		}
+		err = ioutil.WriteFile(newPath, []byte(text), modes[rel])
+		if err != nil {
+			return errors.Wrapf(err, "writing %s", newPath)
+		}
+		if newPath != path {
+			err = os.Remove(path)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	// remove the directories whose names were templates deepest first now their files have moved
+	sort.Sort(sort.Reverse(sort.StringSlice(templateDirs)))
+	for _, d := range templateDirs {
+		children, err := ioutil.ReadDir(d)
+		if err != nil {
+			return err
+		}
+		if len(children) == 0 {
+			err = os.Remove(d)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return os.Remove(filepath.Join(dir, TemplateSchemaFileName))
+}
@@ -0,0 +1,77 @@
+// +build unit
+
+package quickstarts_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jenkins-x/jx/v2/pkg/quickstarts"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testTemplateSchema = `
+parameters:
+- name: group
+  default: com.example
+- name: port
+  default: "8080"
+  pattern: "^[0-9]+$"
+- name: database
+  values: [none, mysql, postgres]
+  default: none
+`
+
+func TestRenderTemplate(t *testing.T) {
+	t.Parallel()
+	dir, err := ioutil.TempDir("", "quickstart-template")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	files := map[string]string{
+		quickstarts.TemplateSchemaFileName:                testTemplateSchema,
+		"src/main/java/{{ path .Values.group }}/App.java": "package {{ .Values.group }};\n",
+		"src/main/resources/application.properties":       "server.port={{ .Values.port }}\n{{ if eq .Values.database \"postgres\" }}db=postgres\n{{ end }}",
+		"charts/{{ .AppName }}/templates/deployment.yaml": "name: {{ .Release.Name }}\n",
+		"README.md": "# {{ .AppName }} by {{ .Organisation }}\n",
+	}
+	for name, text := range files {
+		fileName := filepath.Join(dir, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(fileName), util.DefaultWritePermissions))
+		require.NoError(t, ioutil.WriteFile(fileName, []byte(text), util.DefaultWritePermissions))
+	}
+
+	schema, err := quickstarts.LoadTemplateSchema(dir)
+	require.NoError(t, err)
+	require.NotNil(t, schema)
+	require.Len(t, schema.Parameters, 3)
+
+	values, err := quickstarts.ResolveVariables(schema.Parameters, map[string]string{"group": "com.acme", "database": "postgres"}, true, util.IOFileHandles{})
+	require.NoError(t, err)
+	_, err = quickstarts.ResolveVariables(schema.Parameters, map[string]string{"database": "oracle"}, true, util.IOFileHandles{})
+	assert.Error(t, err, "the value should be one of the values of the parameter")
+
+	err = schema.Render(dir, &quickstarts.TemplateData{AppName: "myapp", Organisation: "myorg", Values: values})
+	require.NoError(t, err)
+
+	assertFile := func(name string, expected string) {
+		data, err := ioutil.ReadFile(filepath.Join(dir, filepath.FromSlash(name)))
+		require.NoError(t, err, "reading %s", name)
+		assert.Equal(t, expected, string(data), "file %s", name)
+	}
+	assertFile("src/main/java/com/acme/App.java", "package com.acme;\n")
+	assertFile("src/main/resources/application.properties", "server.port=8080\ndb=postgres\n")
+	assertFile("README.md", "# myapp by myorg\n")
+	assertFile("charts/{{ .AppName }}/templates/deployment.yaml", "name: {{ .Release.Name }}\n")
+
+	_, err = os.Stat(filepath.Join(dir, "src", "main", "java", "{{ path .Values.group }}"))
+	assert.True(t, os.IsNotExist(err), "the template directory should be removed")
+
+	schema, err = quickstarts.LoadTemplateSchema(dir)
+	require.NoError(t, err)
+	assert.Nil(t, schema)
+}
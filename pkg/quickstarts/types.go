@@ -1,6 +1,7 @@
 package quickstarts
 
 import (
+	v1 "github.com/jenkins-x/jx/v2/pkg/apis/jenkins.io/v1"
 	"github.com/jenkins-x/jx/v2/pkg/gits"
 )
 
@@ -16,6 +17,10 @@ type Quickstart struct {
 	GitServer      string
 	GitKind        string
 	GitProvider    gits.GitProvider
+	// Catalog the name of the catalog the quickstart was loaded from
+	Catalog string
+	// Variables the template variables of the catalog of the quickstart
+	Variables []v1.QuickStartVariable
 }
 
 type QuickstartModel struct {
@@ -30,9 +35,12 @@ type QuickstartFilter struct {
 	ProjectName string
 	Tags        []string
 	AllowML     bool
+	Catalog     string
 }
 
 type QuickstartForm struct {
 	Quickstart *Quickstart
 	Name       string
+	// Variables the values of the template variables of the quickstart
+	Variables map[string]string
 }
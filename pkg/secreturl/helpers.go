@@ -25,19 +25,9 @@ func ReplaceURIs(s string, client Client, r *regexp.Regexp, schemePrefix string)
 				err = errors.Errorf("cannot parse %q as path:key", pathAndKey)
 				return ""
 			}
-			secret, err1 := client.Read(parts[0])
+			result, err1 := readKey(client, parts[0], parts[1])
 			if err1 != nil {
-				err = errors.Wrapf(err1, "reading %q from vault", parts[0])
-				return ""
-			}
-			v, ok := secret[parts[1]]
-			if !ok {
-				err = errors.Errorf("unable to find %q in secret at %q", parts[1], parts[0])
-				return ""
-			}
-			result, err1 := util.AsString(v)
-			if err1 != nil {
-				err = errors.Wrapf(err1, "converting %v to string", v)
+				err = err1
 				return ""
 			}
 			return prefix + result
@@ -50,6 +40,41 @@ func ReplaceURIs(s string, client Client, r *regexp.Regexp, schemePrefix string)
 	return answer, nil
 }
 
+// ParseURI parses a secret URI such as `vault:cluster/admin:password` returning its scheme, path and key
+func ParseURI(uri string) (string, string, string, error) {
+	parts := strings.Split(uri, ":")
+	if len(parts) != 3 || parts[0] == "" || parts[1] == "" || parts[2] == "" {
+		return "", "", "", errors.Errorf("cannot parse %q as scheme:path:key", uri)
+	}
+	return parts[0], parts[1], parts[2], nil
+}
+
+// ResolveURI returns the value of a single secret URI such as `vault:cluster/admin:password` using the secret URL client
+func ResolveURI(client Client, uri string) (string, error) {
+	_, path, key, err := ParseURI(uri)
+	if err != nil {
+		return "", err
+	}
+	return readKey(client, path, key)
+}
+
+// readKey reads the value of the key in the secret at the given path
+func readKey(client Client, path string, key string) (string, error) {
+	secret, err := client.Read(path)
+	if err != nil {
+		return "", errors.Wrapf(err, "reading %q from vault", path)
+	}
+	v, ok := secret[key]
+	if !ok {
+		return "", errors.Errorf("unable to find %q in secret at %q", key, path)
+	}
+	result, err := util.AsString(v)
+	if err != nil {
+		return "", errors.Wrapf(err, "converting %v to string", v)
+	}
+	return result, nil
+}
+
 // trimBeforePrefix remove any chars before the given prefix
 func trimBeforePrefix(s string, prefix string) (string, string) {
 	i := strings.Index(s, prefix)
@@ -149,3 +149,29 @@ password: %s
 	assert.NoError(t, err, "should replace the URIs without error")
 	assert.EqualValues(t, fmt.Sprintf(testString, testValue), result, "should replace the URIs")
 }
+
+func TestParseURI(t *testing.T) {
+	scheme, path, key, err := secreturl.ParseURI("vault:cluster/admin:password")
+	require.NoError(t, err)
+	assert.Equal(t, "vault", scheme)
+	assert.Equal(t, "cluster/admin", path)
+	assert.Equal(t, "password", key)
+
+	_, _, _, err = secreturl.ParseURI("vault:cluster/admin")
+	assert.Error(t, err, "should fail when the URI has no key")
+}
+
+func TestResolveURI(t *testing.T) {
+	secretClient := fakevault.NewFakeClient()
+
+	testValue := "value: with: colons"
+	_, err := secretClient.Write("cluster/requirements", map[string]interface{}{"cluster.project": testValue})
+	require.NoError(t, err)
+
+	result, err := secreturl.ResolveURI(secretClient, "vault:cluster/requirements:cluster.project")
+	require.NoError(t, err)
+	assert.Equal(t, testValue, result)
+
+	_, err = secreturl.ResolveURI(secretClient, "vault:cluster/requirements:missing")
+	assert.Error(t, err, "should fail when no value is found in vault")
+}
@@ -10,6 +10,7 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/jenkins-x/jx/v2/pkg/config"
 	"github.com/jenkins-x/jx/v2/pkg/secreturl"
 
 	"github.com/jenkins-x/jx/v2/pkg/log"
@@ -657,6 +658,13 @@ func (o *JSONSchemaOptions) handleBasicProperty(name string, prefixes []string,
 	if vaultDir != "" {
 		vaultPath = strings.Join([]string{vaultPath, vaultDir}, "/")
 	}
+	dereferencedFormat := strings.TrimSuffix(util.DereferenceString(t.Format), "-passthrough")
+	if (dereferencedFormat == "password" || dereferencedFormat == "token") && o.VaultScheme == string(config.SecretStorageTypeExternalSecrets) {
+		// the secrets are stored in the cloud secret manager and synced by the External Secrets Operator so lets
+		// only reference them
+		output.Set(name, secreturl.ToURI(vaultPath, vaultKey, o.VaultScheme))
+		return nil
+	}
 	ask := true
 	defaultValue := ""
 	autoAcceptMessage := ""
@@ -707,7 +715,6 @@ func (o *JSONSchemaOptions) handleBasicProperty(name string, prefixes []string,
 	validator := survey.ComposeValidators(validators...)
 	// Ask the question
 	// Custom format support for passwords
-	dereferencedFormat := strings.TrimSuffix(util.DereferenceString(t.Format), "-passthrough")
 	if dereferencedFormat == "password" || dereferencedFormat == "token" {
 		// the default value for a password is just the path, so clear those values
 		if _, ok := existingValues[name]; ok {
@@ -41,6 +41,10 @@ type PipelineCreateParam struct {
 
 	// NoReleasePrepare do not prepare the release, this passes the --no-release-prepare flag to `jx step create task`
 	NoReleasePrepare bool
+
+	// StartStep the name of the step of the build pipeline to start at, the steps before it are skipped. This passes
+	// the --start-step flag to `jx step create task`
+	StartStep string
 }
 
 // Client defines the interface for meta pipeline creation and application.
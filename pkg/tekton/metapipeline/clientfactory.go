@@ -5,11 +5,14 @@ import (
 	"io/ioutil"
 	"os"
 	"strings"
+	"sync"
 	"time"
 
+	jxv1 "github.com/jenkins-x/jx/v2/pkg/apis/jenkins.io/v1"
 	"github.com/jenkins-x/jx/v2/pkg/apps"
 	"github.com/jenkins-x/jx/v2/pkg/client/clientset/versioned"
 	jxclient "github.com/jenkins-x/jx/v2/pkg/client/clientset/versioned"
+	jxlisters "github.com/jenkins-x/jx/v2/pkg/client/listers/jenkins.io/v1"
 	"github.com/jenkins-x/jx/v2/pkg/config"
 	"github.com/jenkins-x/jx/v2/pkg/gits"
 	"github.com/jenkins-x/jx/v2/pkg/jxfactory"
@@ -44,6 +47,11 @@ type clientFactory struct {
 	versionDir       string
 	versionStreamURL string
 	versionStreamRef string
+
+	// envLister reads the dev Environment from the cache of an informer rather than for every pipeline
+	envLister     jxlisters.EnvironmentNamespaceLister
+	envListerOnce sync.Once
+	stop          chan struct{}
 }
 
 // NewMetaPipelineClient creates a new client for the creation and application of meta pipelines.
@@ -78,6 +86,7 @@ func NewMetaPipelineClientWithClientsAndNamespace(jxClient versioned.Interface,
 		versionDir:       versionDir,
 		versionStreamURL: url,
 		versionStreamRef: ref,
+		stop:             make(chan struct{}),
 	}
 
 	return &client, nil
@@ -137,6 +146,7 @@ func (c *clientFactory) Create(param PipelineCreateParam) (kube.PromoteStepActiv
 		GitInfo:             *gitInfo,
 		UseBranchAsRevision: param.UseBranchAsRevision,
 		NoReleasePrepare:    param.NoReleasePrepare,
+		StartStep:           param.StartStep,
 	}
 
 	return c.createActualCRDs(buildNumber, branchIdentifier, param.Context, param.PullRef, crdCreationParams)
@@ -166,6 +176,10 @@ func (c *clientFactory) Apply(pipelineActivity kube.PromoteStepActivityKey, crds
 
 // Close cleans up the resources use by this client.
 func (c *clientFactory) Close() error {
+	if c.stop != nil {
+		close(c.stop)
+		c.stop = nil
+	}
 	return os.RemoveAll(c.versionDir)
 }
 
@@ -200,9 +214,14 @@ func versionStreamURLAndRef(jxClient versioned.Interface, ns string) (string, st
 	if err != nil {
 		return "", "", errors.Wrap(err, "unable to retrieve team environment")
 	}
+	url, ref := teamVersionStream(devEnv)
+	return url, ref, nil
+}
 
+// teamVersionStream returns the URL and ref of the version stream of the team or the defaults
+func teamVersionStream(devEnv *jxv1.Environment) (string, string) {
 	if devEnv == nil {
-		return config.DefaultVersionsURL, config.DefaultVersionsRef, nil
+		return config.DefaultVersionsURL, config.DefaultVersionsRef
 	}
 
 	teamSettings := devEnv.Spec.TeamSettings
@@ -214,12 +233,33 @@ func versionStreamURLAndRef(jxClient versioned.Interface, ns string) (string, st
 	if ref == "" {
 		ref = config.DefaultVersionsRef
 	}
+	return url, ref
+}
 
+// teamVersionStreamURLAndRef returns the version stream of the team, watching the Environments so that the API server
+// is not asked for the dev Environment for every pipeline
+func (c *clientFactory) teamVersionStreamURLAndRef() (string, string, error) {
+	c.envListerOnce.Do(func() {
+		envLister, err := kube.NewEnvironmentLister(c.jxClient, c.ns, c.stop)
+		if err != nil {
+			logger.Warnf("looking up the version stream for each pipeline as the Environments cannot be watched: %s", err.Error())
+			return
+		}
+		c.envLister = envLister
+	})
+	if c.envLister == nil {
+		return versionStreamURLAndRef(c.jxClient, c.ns)
+	}
+	devEnv, err := kube.GetDevEnvironmentFromLister(c.envLister)
+	if err != nil {
+		return "", "", errors.Wrap(err, "unable to retrieve team environment")
+	}
+	url, ref := teamVersionStream(devEnv)
 	return url, ref, nil
 }
 
 func (c *clientFactory) cloneVersionStreamIfNeeded() error {
-	url, ref, err := versionStreamURLAndRef(c.jxClient, c.ns)
+	url, ref, err := c.teamVersionStreamURLAndRef()
 	if err != nil {
 		return err
 	}
@@ -60,6 +60,7 @@ type CRDCreationParameters struct {
 	VersionsDir         string
 	UseBranchAsRevision bool
 	NoReleasePrepare    bool
+	StartStep           string
 }
 
 // createMetaPipelineCRDs creates the Tekton CRDs needed to execute the meta pipeline.
@@ -279,6 +280,9 @@ func stepCreateTektonCRDs(params CRDCreationParameters) syntax.Step {
 	if params.NoReleasePrepare {
 		args = append(args, "--no-release-prepare")
 	}
+	if params.StartStep != "" {
+		args = append(args, "--start-step", params.StartStep)
+	}
 	for k, v := range params.Labels {
 		args = append(args, "--label", fmt.Sprintf("%s=%s", k, v))
 	}
@@ -0,0 +1,231 @@
+package syntax
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/jenkins-x/jx/v2/pkg/log"
+	"github.com/knative/pkg/apis"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// SkippedStepName the name of the step which replaces the steps of a stage whose conditions do not match
+	SkippedStepName = "skipped"
+)
+
+// ConditionContext is what the conditions of the stages and steps of a pipeline are evaluated against
+type ConditionContext struct {
+	// Branch the name of the branch being built
+	Branch string
+	// ChangedFiles the files changed by the commits being built, relative to the root of the repository. If nil the
+	// changed files are unknown and every change condition matches
+	ChangedFiles []string
+	// Env the environment variables of the build, which override those of the pipeline
+	Env map[string]string
+}
+
+func validateConditions(c *Conditions) *apis.FieldError {
+	for _, pattern := range c.Branches {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return &apis.FieldError{
+				Message: fmt.Sprintf("The branch pattern '%s' is not a valid glob", pattern),
+				Paths:   []string{"branches"},
+			}
+		}
+	}
+	for _, pattern := range c.Changes {
+		if _, err := path.Match(strings.TrimSuffix(pattern, "/**"), ""); err != nil {
+			return &apis.FieldError{
+				Message: fmt.Sprintf("The change pattern '%s' is not a valid glob", pattern),
+				Paths:   []string{"changes"},
+			}
+		}
+	}
+	for name, pattern := range c.Env {
+		if !validEnvVarName(name) {
+			return &apis.FieldError{
+				Message: fmt.Sprintf("'%s' must be a valid environment variable name", name),
+				Paths:   []string{"env"},
+			}
+		}
+		if _, err := path.Match(pattern, ""); err != nil {
+			return &apis.FieldError{
+				Message: fmt.Sprintf("The pattern '%s' of environment variable %s is not a valid glob", pattern, name),
+				Paths:   []string{"env"},
+			}
+		}
+	}
+	return nil
+}
+
+// ApplyConditions removes the steps of the pipeline whose conditions do not match the given context. The steps of a
+// stage whose conditions do not match, or which has no steps left, are replaced by a single step logging why the stage
+// was skipped so that the structure of the pipeline, and so the reported stages, stay the same.
+func (j *ParsedPipeline) ApplyConditions(ctx ConditionContext) {
+	for i := range j.Stages {
+		j.Stages[i] = applyStageConditions(j.Stages[i], j.Agent, conditionEnv(j.GetEnv(), nil), ctx)
+	}
+}
+
+func applyStageConditions(s Stage, parentAgent *Agent, parentEnv map[string]string, ctx ConditionContext) Stage {
+	agent := s.Agent
+	if agent == nil {
+		agent = parentAgent
+	}
+	env := conditionEnv(s.GetEnv(), parentEnv)
+
+	if reason := s.Conditions.unmatched(ctx, env); reason != "" {
+		log.Logger().Infof("skipping stage %s as %s", s.Name, reason)
+		return skippedStage(s, agent, reason)
+	}
+
+	if len(s.Steps) > 0 {
+		s.Steps = applyStepConditions(s.Steps, env, ctx)
+		if len(s.Steps) == 0 {
+			return skippedStage(s, agent, "none of its steps match their conditions")
+		}
+	}
+	for i := range s.Stages {
+		s.Stages[i] = applyStageConditions(s.Stages[i], agent, env, ctx)
+	}
+	for i := range s.Parallel {
+		s.Parallel[i] = applyStageConditions(s.Parallel[i], agent, env, ctx)
+	}
+	return s
+}
+
+func applyStepConditions(steps []Step, parentEnv map[string]string, ctx ConditionContext) []Step {
+	var answer []Step
+	for _, step := range steps {
+		if reason := step.Conditions.unmatched(ctx, conditionEnv(step.Env, parentEnv)); reason != "" {
+			log.Logger().Infof("skipping step %s as %s", step.Name, reason)
+			continue
+		}
+		if step.Loop != nil {
+			step.Loop.Steps = applyStepConditions(step.Loop.Steps, parentEnv, ctx)
+			if len(step.Loop.Steps) == 0 {
+				continue
+			}
+		}
+		answer = append(answer, step)
+	}
+	return answer
+}
+
+// skippedStage returns the stage with its steps and nested stages replaced by a step logging why it was skipped
+func skippedStage(s Stage, agent *Agent, reason string) Stage {
+	if agent == nil {
+		agent = &Agent{Image: DefaultContainerImage}
+	}
+	answer := Stage{
+		Name:       s.Name,
+		Agent:      agent,
+		Env:        s.GetEnv(),
+		WorkingDir: s.WorkingDir,
+		Steps: []Step{{
+			Name:      SkippedStepName,
+			Command:   "echo",
+			Arguments: []string{fmt.Sprintf("'stage %s skipped as %s'", strings.ReplaceAll(s.Name, "'", ""), reason)},
+		}},
+	}
+	if s.Options != nil {
+		answer.Options = &StageOptions{
+			RootOptions: &RootOptions{},
+			Workspace:   s.Options.Workspace,
+		}
+	}
+	return answer
+}
+
+// unmatched returns why the conditions do not match the context or an empty string if they match
+func (c *Conditions) unmatched(ctx ConditionContext, env map[string]string) string {
+	if c == nil {
+		return ""
+	}
+	if len(c.Branches) > 0 && !matchesAny(c.Branches, ctx.Branch) {
+		return fmt.Sprintf("branch %s does not match %s", ctx.Branch, strings.Join(c.Branches, ", "))
+	}
+	if len(c.Changes) > 0 && ctx.ChangedFiles != nil && !changesMatch(c.Changes, ctx.ChangedFiles) {
+		return fmt.Sprintf("no changed file matches %s", strings.Join(c.Changes, ", "))
+	}
+	if len(c.Env) > 0 {
+		names := make([]string, 0, len(c.Env))
+		for name := range c.Env {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			value := env[name]
+			if ctx.Env != nil {
+				if v, ok := ctx.Env[name]; ok {
+					value = v
+				}
+			}
+			pattern := c.Env[name]
+			if pattern == "" {
+				if value == "" {
+					return fmt.Sprintf("environment variable %s is not set", name)
+				}
+				continue
+			}
+			if !matchesAny([]string{pattern}, value) {
+				return fmt.Sprintf("environment variable %s does not match %s", name, pattern)
+			}
+		}
+	}
+	return ""
+}
+
+// conditionEnv returns the values of the environment variables overriding those of the parent
+func conditionEnv(env []corev1.EnvVar, parentEnv map[string]string) map[string]string {
+	answer := make(map[string]string, len(parentEnv)+len(env))
+	for k, v := range parentEnv {
+		answer[k] = v
+	}
+	for _, e := range env {
+		answer[e.Name] = e.Value
+	}
+	return answer
+}
+
+func matchesAny(patterns []string, value string) bool {
+	for _, pattern := range patterns {
+		if matched, err := path.Match(pattern, value); err == nil && matched {
+			return true
+		}
+	}
+	return false
+}
+
+// changesMatch returns true if any of the files matches any of the patterns. A pattern ending with '/**' matches every
+// file below a directory and a pattern without a '/' matches file names in any directory
+func changesMatch(patterns []string, files []string) bool {
+	for _, pattern := range patterns {
+		for _, file := range files {
+			if strings.HasSuffix(pattern, "/**") {
+				dir := strings.TrimSuffix(pattern, "/**")
+				if matched, err := path.Match(dir, file); err == nil && matched {
+					return true
+				}
+				parts := strings.Split(file, "/")
+				for i := 1; i < len(parts); i++ {
+					if matched, err := path.Match(dir, strings.Join(parts[:i], "/")); err == nil && matched {
+						return true
+					}
+				}
+				continue
+			}
+			name := file
+			if !strings.Contains(pattern, "/") {
+				name = path.Base(file)
+			}
+			if matched, err := path.Match(pattern, name); err == nil && matched {
+				return true
+			}
+		}
+	}
+	return false
+}
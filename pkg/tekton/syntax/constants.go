@@ -7,12 +7,33 @@ const (
 	// LabelStageName - the name for the label that will have the stage name on the Task.
 	LabelStageName = "jenkins.io/task-stage-name"
 
+	// LabelMatrixPrefix - the prefix of the labels on the Tasks of a matrix stage holding the value of each matrix axis.
+	LabelMatrixPrefix = "jenkins.io/matrix-"
+
 	// DefaultStageNameForBuildPack - the name we use for the single stage created from build packs currently.
 	DefaultStageNameForBuildPack = "from-build-pack"
 
 	// KanikoDockerImage - the default image used for Kaniko builds
 	KanikoDockerImage = "gcr.io/kaniko-project/executor:v0.22.0"
 
+	// ImageIndexDockerImage - the image used to combine the images built for each platform into a multi-arch image
+	ImageIndexDockerImage = "gcr.io/go-containerregistry/gcrane:debug"
+
+	// ImageIndexCommand - the command which combines the images built for each platform into a multi-arch image
+	ImageIndexCommand = "gcrane index append"
+
+	// JxDockerImage - the image used for the steps running jx commands, such as signing images
+	JxDockerImage = "gcr.io/jenkinsxio/builder-jx"
+
+	// SBOMCommand - the command which generates the SBOM of an image
+	SBOMCommand = "jx step syft"
+
+	// SignImageCommand - the command which signs an image
+	SignImageCommand = "jx step cosign sign"
+
 	// DefaultContainerImage - the default image used for pipelines if none is specified.
 	DefaultContainerImage = "gcr.io/jenkinsxio/builder-maven"
+
+	// CacheMountPathRoot - the directory below which the PersistentVolumeClaims of caches are mounted
+	CacheMountPathRoot = "/jx-cache"
 )
@@ -131,6 +131,38 @@ type RootOptions struct {
 	DistributeParallelAcrossNodes bool                `json:"distributeParallelAcrossNodes,omitempty"`
 	Tolerations                   []corev1.Toleration `json:"tolerations,omitempty"`
 	PodLabels                     map[string]string   `json:"podLabels,omitempty"`
+	Caches                        []*Cache            `json:"caches,omitempty"`
+	// NodeSelector the labels of the nodes the pods of the pipeline are scheduled on
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+	// SecurityContext the security context of the pods of the pipeline
+	SecurityContext *corev1.PodSecurityContext `json:"securityContext,omitempty"`
+	// Secrets the secrets of the team injected into the steps as environment variables or files
+	Secrets []*Secret `json:"secrets,omitempty"`
+}
+
+// Cache defines directories, such as a local maven repository or node_modules, which are restored before the steps
+// of a stage and saved after them so that later builds can reuse them
+type Cache struct {
+	Name  string   `json:"name"`
+	Paths []string `json:"paths"`
+	// Key optionally separates the cache from the other builds using the same name, defaults to the name
+	Key string `json:"key,omitempty"`
+	// ClaimName the PersistentVolumeClaim to store the cache in. If not specified the cache is stored in the storage
+	// location of the team for the 'cache' classifier
+	ClaimName string `json:"claimName,omitempty"`
+}
+
+// Secret defines a secret of the team which is injected into the steps of a pipeline. Without a mountPath the keys of
+// the secret, or only its key, are injected as environment variables, otherwise they are mounted as files
+type Secret struct {
+	// Name the name of the Kubernetes Secret in the namespace of the pipeline
+	Name string `json:"name"`
+	// Key optionally injects only this key of the secret
+	Key string `json:"key,omitempty"`
+	// Env the environment variable the key is injected as, defaults to the key in upper case
+	Env string `json:"env,omitempty"`
+	// MountPath the directory the keys of the secret are mounted in or, if a key is specified, the file it is mounted as
+	MountPath string `json:"mountPath,omitempty"`
 }
 
 // Stash defines files to be saved for use in a later stage, marked with a name
@@ -187,6 +219,12 @@ type Step struct {
 	// env allows defining per-step environment variables
 	Env []corev1.EnvVar `json:"env,omitempty"`
 
+	// conditions optionally define when the step is run
+	Conditions *Conditions `json:"conditions,omitempty"`
+
+	// include replaces the step with the steps of a file in an imported pipeline library
+	Include *Include `json:"include,omitempty"`
+
 	// Legacy fields from jenkinsfile.PipelineStep before it was eliminated.
 	Comment   string  `json:"comment,omitempty"`
 	Groovy    string  `json:"groovy,omitempty"`
@@ -196,6 +234,14 @@ type Step struct {
 	Sh        string  `json:"sh,omitempty"`
 }
 
+// Include references a file of steps in a pipeline library imported by the pipeline configuration
+type Include struct {
+	// Import the name of the imported pipeline library
+	Import string `json:"import"`
+	// File the path of the file in the pipeline library
+	File string `json:"file"`
+}
+
 // Loop is a special step that defines a variable, a list of possible values for that variable, and a set of steps to
 // repeat for each value for the variable, with the variable set with that value in the environment for the execution of
 // those steps.
@@ -220,11 +266,37 @@ type Stage struct {
 	Parallel   []Stage         `json:"parallel,omitempty"`
 	Post       []Post          `json:"post,omitempty"`
 	WorkingDir *string         `json:"dir,omitempty"`
+	Matrix     *Matrix         `json:"matrix,omitempty"`
+	Conditions *Conditions     `json:"conditions,omitempty"`
 
 	// Replaced by Env, retained for backwards compatibility
 	Environment []corev1.EnvVar `json:"environment,omitempty"`
 }
 
+// Matrix defines the axes of a stage which is run in parallel for each combination of the values of the axes, with
+// each value available as an environment variable named after its axis
+type Matrix struct {
+	Axes []MatrixAxis `json:"axes"`
+}
+
+// MatrixAxis defines an environment variable and the values the stage is run with
+type MatrixAxis struct {
+	Name   string   `json:"name"`
+	Values []string `json:"values"`
+}
+
+// Conditions define when a stage or step is run, every specified condition has to match
+type Conditions struct {
+	// Branches the glob patterns one of which the name of the branch being built has to match
+	Branches []string `json:"branches,omitempty"`
+	// Changes the glob patterns one of which a file changed by the commits being built has to match. A pattern ending
+	// with '/**' matches every file below a directory and a pattern without a '/' matches file names in any directory
+	Changes []string `json:"changes,omitempty"`
+	// Env the names of environment variables and the glob patterns their values have to match, an empty pattern only
+	// requires the environment variable to be set
+	Env map[string]string `json:"env,omitempty"`
+}
+
 // PostCondition is used to specify under what condition a post action should be executed.
 type PostCondition string
 
@@ -620,6 +692,24 @@ func validateStage(s Stage, parentAgent *Agent, parentVolumes []*corev1.Volume,
 		}
 	}
 
+	if s.Matrix != nil {
+		if len(s.Steps) == 0 {
+			return &apis.FieldError{
+				Message: "A matrix can only be used in a stage with steps",
+				Paths:   []string{"matrix"},
+			}
+		}
+		if err := validateMatrix(s.Matrix); err != nil {
+			return err.ViaField("matrix")
+		}
+	}
+
+	if s.Conditions != nil {
+		if err := validateConditions(s.Conditions); err != nil {
+			return err.ViaField("conditions")
+		}
+	}
+
 	if len(s.Steps) > 0 {
 		if len(s.Stages) > 0 || len(s.Parallel) > 0 {
 			return apis.ErrMultipleOneOf("steps", "stages", "parallel")
@@ -676,6 +766,34 @@ func validateStage(s Stage, parentAgent *Agent, parentVolumes []*corev1.Volume,
 	return validateStageOptions(s.Options, volumes, kubeClient, ns).ViaField("options")
 }
 
+var validEnvVarName = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`).MatchString
+
+func validateMatrix(m *Matrix) *apis.FieldError {
+	if len(m.Axes) == 0 {
+		return apis.ErrMissingField("axes")
+	}
+	names := make(map[string]bool)
+	for i, axis := range m.Axes {
+		if !validEnvVarName(axis.Name) {
+			return (&apis.FieldError{
+				Message: fmt.Sprintf("The matrix axis name '%s' must be a valid environment variable name", axis.Name),
+				Paths:   []string{"name"},
+			}).ViaFieldIndex("axes", i)
+		}
+		if names[axis.Name] {
+			return (&apis.FieldError{
+				Message: fmt.Sprintf("The matrix axis name %s is used more than once", axis.Name),
+				Paths:   []string{"name"},
+			}).ViaFieldIndex("axes", i)
+		}
+		names[axis.Name] = true
+		if len(axis.Values) == 0 {
+			return apis.ErrMissingField("values").ViaFieldIndex("axes", i)
+		}
+	}
+	return nil
+}
+
 func moreThanOneAreTrue(vals ...bool) bool {
 	count := 0
 
@@ -721,10 +839,23 @@ func validateStep(s Step) *apis.FieldError {
 		}
 	}
 
+	if s.Include != nil {
+		return &apis.FieldError{
+			Message: fmt.Sprintf("the include of %s from %s has not been resolved", s.Include.File, s.Include.Import),
+			Paths:   []string{"include"},
+		}
+	}
+
 	if s.GetCommand() == "" && s.Step == "" && s.Loop == nil {
 		return apis.ErrMissingOneOf("command", "step", "loop")
 	}
 
+	if s.Conditions != nil {
+		if err := validateConditions(s.Conditions); err != nil {
+			return err.ViaField("conditions")
+		}
+	}
+
 	if moreThanOneAreTrue(s.GetCommand() != "", s.Step != "", s.Loop != nil) {
 		return apis.ErrMultipleOneOf("command", "step", "loop")
 	}
@@ -813,6 +944,14 @@ func validateRootOptions(o *RootOptions, volumes []*corev1.Volume, kubeClient ku
 			}
 		}
 
+		if err := validateCaches(o.Caches); err != nil {
+			return err
+		}
+
+		if err := validateSecrets(o.Secrets, kubeClient, ns); err != nil {
+			return err
+		}
+
 		return validateContainerOptions(o.ContainerOptions, volumes).ViaField("containerOptions")
 	}
 
@@ -947,6 +1086,20 @@ func validateStageOptions(o *StageOptions, volumes []*corev1.Volume, kubeClient
 			}
 		}
 
+		if o.RootOptions != nil && len(o.RootOptions.NodeSelector) > 0 {
+			return &apis.FieldError{
+				Message: "nodeSelector cannot be used in a stage",
+				Paths:   []string{"nodeSelector"},
+			}
+		}
+
+		if o.RootOptions != nil && o.RootOptions.SecurityContext != nil {
+			return &apis.FieldError{
+				Message: "securityContext cannot be used in a stage",
+				Paths:   []string{"securityContext"},
+			}
+		}
+
 		return validateRootOptions(o.RootOptions, volumes, kubeClient, ns)
 	}
 
@@ -1014,6 +1167,38 @@ func validateStash(s *Stash) *apis.FieldError {
 	return nil
 }
 
+func validateCaches(caches []*Cache) *apis.FieldError {
+	names := make(map[string]bool)
+	for i, c := range caches {
+		if c == nil {
+			continue
+		}
+		if c.Name == "" {
+			return apis.ErrMissingField("name").ViaFieldIndex("caches", i)
+		}
+		if c.Name != naming.ToValidName(c.Name) {
+			return (&apis.FieldError{
+				Message: fmt.Sprintf("The cache name %s must be a valid Kubernetes name", c.Name),
+				Paths:   []string{"name"},
+			}).ViaFieldIndex("caches", i)
+		}
+		if len(c.Paths) == 0 {
+			return (&apis.FieldError{
+				Message: "paths to cache must be provided",
+				Paths:   []string{"paths"},
+			}).ViaFieldIndex("caches", i)
+		}
+		if names[c.Name] {
+			return (&apis.FieldError{
+				Message: fmt.Sprintf("The cache name %s is used more than once", c.Name),
+				Paths:   []string{"name"},
+			}).ViaFieldIndex("caches", i)
+		}
+		names[c.Name] = true
+	}
+	return nil
+}
+
 func validateWorkspace(w string) *apis.FieldError {
 	if w == "" {
 		return &apis.FieldError{
@@ -1069,6 +1254,63 @@ func (j *ParsedPipeline) GetTolerations() []corev1.Toleration {
 	return nil
 }
 
+// GetNodeSelector returns the node selector configured in the root options for this pipeline, if any.
+func (j *ParsedPipeline) GetNodeSelector() map[string]string {
+	if j.Options != nil {
+		return j.Options.NodeSelector
+	}
+	return nil
+}
+
+// GetSecurityContext returns the pod security context configured in the root options for this pipeline, if any.
+func (j *ParsedPipeline) GetSecurityContext() *corev1.PodSecurityContext {
+	if j.Options != nil {
+		return j.Options.SecurityContext
+	}
+	return nil
+}
+
+// ApplyPodDefaults applies the default resources of the containers and the default node selector, tolerations and
+// security context of the pods to the pipeline. The resources of the pipeline override the default resources of the
+// same name and the node selector, tolerations and security context of the pipeline replace the defaults.
+func (j *ParsedPipeline) ApplyPodDefaults(resources corev1.ResourceRequirements, nodeSelector map[string]string, tolerations []corev1.Toleration, securityContext *corev1.PodSecurityContext) {
+	if j.Options == nil {
+		j.Options = &RootOptions{}
+	}
+	o := j.Options
+	if len(resources.Requests) > 0 || len(resources.Limits) > 0 {
+		if o.ContainerOptions == nil {
+			o.ContainerOptions = &corev1.Container{}
+		}
+		o.ContainerOptions.Resources.Requests = defaultResourceList(o.ContainerOptions.Resources.Requests, resources.Requests)
+		o.ContainerOptions.Resources.Limits = defaultResourceList(o.ContainerOptions.Resources.Limits, resources.Limits)
+	}
+	if len(o.NodeSelector) == 0 && len(nodeSelector) > 0 {
+		o.NodeSelector = util.MergeMaps(nodeSelector)
+	}
+	if len(o.Tolerations) == 0 && len(tolerations) > 0 {
+		o.Tolerations = append([]corev1.Toleration{}, tolerations...)
+	}
+	if o.SecurityContext == nil && securityContext != nil {
+		o.SecurityContext = securityContext.DeepCopy()
+	}
+}
+
+// defaultResourceList returns the resources with the default resources added for the names they do not specify
+func defaultResourceList(resources corev1.ResourceList, defaults corev1.ResourceList) corev1.ResourceList {
+	if len(defaults) == 0 {
+		return resources
+	}
+	answer := corev1.ResourceList{}
+	for name, quantity := range defaults {
+		answer[name] = quantity.DeepCopy()
+	}
+	for name, quantity := range resources {
+		answer[name] = quantity.DeepCopy()
+	}
+	return answer
+}
+
 // GetPossibleAffinityPolicy takes the pipeline name and returns the appropriate affinity policy for pods in this
 // pipeline given its configuration, specifically of options.distributeParallelAcrossNodes.
 func (j *ParsedPipeline) GetPossibleAffinityPolicy(name string) *corev1.Affinity {
@@ -1105,6 +1347,13 @@ type StepPlaceholderReplacementArgs struct {
 	ProjectID         string
 	KanikoImage       string
 	UseKaniko         bool
+	// Platforms the platforms, such as linux/arm64, kaniko builds images for. Multiple platforms build an image for each
+	// platform which are then combined into a multi-arch image
+	Platforms []string
+	// GenerateSBOM whether the SBOMs of the images kaniko builds are generated
+	GenerateSBOM bool
+	// SignImages whether the images kaniko builds are signed, attesting their SBOMs if they are generated
+	SignImages bool
 }
 
 func (p *StepPlaceholderReplacementArgs) workingDirAsPointer() *string {
@@ -1140,7 +1389,8 @@ func (s *Stage) replacePlaceholdersInStage(parentDir *string, args StepPlacehold
 	s.WorkingDir = replacePlaceholdersInDir(s.WorkingDir, args)
 	for _, step := range s.Steps {
 		step.replacePlaceholdersInStep(args)
-		steps = append(steps, step)
+		steps = append(steps, step.platformSteps(args)...)
+		steps = append(steps, step.supplyChainSteps(args)...)
 	}
 	for _, nested := range s.Stages {
 		nested.replacePlaceholdersInStage(s.WorkingDir, args)
@@ -1192,7 +1442,8 @@ func (s *Step) replacePlaceholdersInStep(args StepPlaceholderReplacementArgs) {
 		var loopSteps []Step
 		for _, nested := range s.Loop.Steps {
 			nested.replacePlaceholdersInStep(args)
-			loopSteps = append(loopSteps, nested)
+			loopSteps = append(loopSteps, nested.platformSteps(args)...)
+			loopSteps = append(loopSteps, nested.supplyChainSteps(args)...)
 		}
 		s.Loop.Steps = loopSteps
 	}
@@ -1223,6 +1474,9 @@ func (s *Step) modifyStep(params StepPlaceholderReplacementArgs) {
 			if ipAddressRegistryRegex.MatchString(localRepo) {
 				args = append(args, "--insecure")
 			}
+			if len(params.Platforms) == 1 {
+				args = append(args, "--customPlatform="+params.Platforms[0])
+			}
 
 			s.Command = "/kaniko/executor"
 			s.Arguments = args
@@ -1412,6 +1666,8 @@ type stageToTaskParams struct {
 	parentWorkspace      string
 	parentContainer      *corev1.Container
 	parentVolumes        []*corev1.Volume
+	parentCaches         []*Cache
+	parentSecrets        []*Secret
 	depth                int8
 	enclosingStage       *transformedStage
 	previousSiblingStage *transformedStage
@@ -1424,6 +1680,9 @@ func stageToTask(params stageToTaskParams) (*transformedStage, error) {
 
 	stageContainer := &corev1.Container{}
 	var stageVolumes []*corev1.Volume
+	var stageCaches []*Cache
+	var stageSecrets []*Secret
+	var stageLabels map[string]string
 
 	if params.stage.Options != nil {
 		o := params.stage.Options
@@ -1437,6 +1696,9 @@ func stageToTask(params stageToTaskParams) (*transformedStage, error) {
 				stageContainer = o.ContainerOptions
 			}
 			stageVolumes = o.Volumes
+			stageCaches = o.Caches
+			stageSecrets = o.Secrets
+			stageLabels = o.PodLabels
 		}
 		if o.Stash != nil {
 			return nil, errors.New("Stash on stage not yet supported")
@@ -1459,6 +1721,8 @@ func stageToTask(params stageToTaskParams) (*transformedStage, error) {
 		stageContainer = merged
 	}
 	stageVolumes = append(stageVolumes, params.parentVolumes...)
+	stageCaches = mergeCaches(stageCaches, params.parentCaches)
+	stageSecrets = mergeSecrets(stageSecrets, params.parentSecrets)
 
 	env := scopedEnv(params.stage.GetEnv(), params.parentEnv)
 
@@ -1483,7 +1747,7 @@ func stageToTask(params stageToTaskParams) (*transformedStage, error) {
 			ObjectMeta: metav1.ObjectMeta{
 				Namespace: params.parentParams.Namespace,
 				Name:      MangleToRfc1035Label(fmt.Sprintf("%s-%s", params.parentParams.PipelineIdentifier, params.stage.Name), params.parentParams.BuildIdentifier),
-				Labels:    util.MergeMaps(params.parentParams.Labels, map[string]string{LabelStageName: params.stage.stageLabelName()}),
+				Labels:    util.MergeMaps(params.parentParams.Labels, stageLabels, map[string]string{LabelStageName: params.stage.stageLabelName()}),
 			},
 		}
 		// Only add the default git merge step if this is the first actual step stage - including if the stage is one of
@@ -1517,7 +1781,22 @@ func stageToTask(params stageToTaskParams) (*transformedStage, error) {
 			volumes[v.Name] = *v
 		}
 
-		for _, step := range params.stage.Steps {
+		restoreSteps, saveSteps, cacheMounts := cacheSteps(stageCaches)
+		for _, c := range stageCaches {
+			if c.ClaimName != "" {
+				volumes[c.volumeName()] = c.volume()
+			}
+		}
+		for _, secret := range stageSecrets {
+			if secret == nil {
+				continue
+			}
+			if secret.MountPath != "" {
+				volumes[secret.volumeName()] = secret.volume()
+			}
+		}
+
+		for _, step := range append(append(restoreSteps, params.stage.Steps...), saveSteps...) {
 			actualSteps, stepVolumes, newCounter, err := generateSteps(generateStepsParams{
 				stageParams:     params,
 				step:            step,
@@ -1530,7 +1809,19 @@ func stageToTask(params stageToTaskParams) (*transformedStage, error) {
 				return nil, err
 			}
 
-			stepCounter = newCounter
+			// the cache steps are named so they do not change the default names of the steps of the stage
+			if mount, isCacheStep := cacheMounts[step.Name]; isCacheStep {
+				if mount != nil {
+					for i := range actualSteps {
+						actualSteps[i].VolumeMounts = append(actualSteps[i].VolumeMounts, *mount)
+					}
+				}
+			} else {
+				stepCounter = newCounter
+				for i := range actualSteps {
+					injectSecrets(&actualSteps[i].Container, stageSecrets)
+				}
+			}
 
 			t.Spec.Steps = append(t.Spec.Steps, actualSteps...)
 			for k, v := range stepVolumes {
@@ -1572,6 +1863,8 @@ func stageToTask(params stageToTaskParams) (*transformedStage, error) {
 				parentWorkspace:      *ts.Stage.Options.Workspace,
 				parentContainer:      stageContainer,
 				parentVolumes:        stageVolumes,
+				parentCaches:         stageCaches,
+				parentSecrets:        stageSecrets,
 				depth:                params.depth + 1,
 				enclosingStage:       &ts,
 				previousSiblingStage: nestedPreviousSibling,
@@ -1601,6 +1894,8 @@ func stageToTask(params stageToTaskParams) (*transformedStage, error) {
 				parentWorkspace: *ts.Stage.Options.Workspace,
 				parentContainer: stageContainer,
 				parentVolumes:   stageVolumes,
+				parentCaches:    stageCaches,
+				parentSecrets:   stageSecrets,
 				depth:           params.depth + 1,
 				enclosingStage:  &ts,
 			})
@@ -1850,6 +2145,140 @@ func generateSteps(params generateStepsParams) ([]tektonv1alpha1.Step, map[strin
 	return steps, volumes, params.stepCounter, nil
 }
 
+// expandMatrixStages replaces each stage with a matrix by a parallel stage which runs a copy of the stage for each
+// combination of the values of the axes of the matrix
+func expandMatrixStages(stages []Stage) []Stage {
+	var answer []Stage
+	for _, s := range stages {
+		s.Stages = expandMatrixStages(s.Stages)
+		s.Parallel = expandMatrixStages(s.Parallel)
+		if s.Matrix == nil {
+			answer = append(answer, s)
+			continue
+		}
+		matrixStage := Stage{
+			Name: s.Name,
+		}
+		for _, combination := range matrixCombinations(s.Matrix.Axes) {
+			var env []corev1.EnvVar
+			labels := map[string]string{}
+			for i, axis := range s.Matrix.Axes {
+				env = append(env, corev1.EnvVar{Name: axis.Name, Value: combination[i]})
+				labels[LabelMatrixPrefix+axis.Name] = naming.ToValidValue(combination[i])
+			}
+			stage := s.DeepCopy()
+			stage.Name = fmt.Sprintf("%s-%s", s.Name, strings.Join(combination, "-"))
+			stage.Matrix = nil
+			stage.Env = CombineEnv(env, stage.GetEnv())
+			stage.Environment = nil
+			if stage.Options == nil {
+				stage.Options = &StageOptions{}
+			}
+			if stage.Options.RootOptions == nil {
+				stage.Options.RootOptions = &RootOptions{}
+			}
+			stage.Options.PodLabels = util.MergeMaps(stage.Options.PodLabels, labels)
+			matrixStage.Parallel = append(matrixStage.Parallel, *stage)
+		}
+		answer = append(answer, matrixStage)
+	}
+	return answer
+}
+
+// matrixCombinations returns every combination of the values of the axes, ordered by the first axis
+func matrixCombinations(axes []MatrixAxis) [][]string {
+	answer := [][]string{{}}
+	for _, axis := range axes {
+		var next [][]string
+		for _, combination := range answer {
+			for _, value := range axis.Values {
+				next = append(next, append(append([]string{}, combination...), value))
+			}
+		}
+		answer = next
+	}
+	return answer
+}
+
+// mergeCaches returns the caches of a stage followed by the caches inherited from its parent which it does not override
+func mergeCaches(caches []*Cache, parentCaches []*Cache) []*Cache {
+	answer := append([]*Cache{}, caches...)
+	for _, parent := range parentCaches {
+		overridden := false
+		for _, c := range caches {
+			if c.Name == parent.Name {
+				overridden = true
+				break
+			}
+		}
+		if !overridden {
+			answer = append(answer, parent)
+		}
+	}
+	return answer
+}
+
+// cacheSteps returns the steps which restore the caches before the steps of a stage and save them afterwards
+// along with the volume mounts of the steps by step name, which are nil unless the cache uses a PersistentVolumeClaim
+func cacheSteps(caches []*Cache) ([]Step, []Step, map[string]*corev1.VolumeMount) {
+	var restoreSteps []Step
+	var saveSteps []Step
+	mounts := make(map[string]*corev1.VolumeMount)
+	for _, c := range caches {
+		args := []string{"--name", c.Name}
+		if c.Key != "" {
+			args = append(args, "--key", c.Key)
+		}
+		for _, path := range c.Paths {
+			args = append(args, "--path", path)
+		}
+		if c.ClaimName != "" {
+			args = append(args, "--dir", c.mountPath())
+		}
+		restore := Step{
+			Name:      "restore-cache-" + c.Name,
+			Command:   "jx step cache restore",
+			Arguments: args,
+		}
+		save := Step{
+			Name:      "save-cache-" + c.Name,
+			Command:   "jx step cache save",
+			Arguments: args,
+		}
+		var mount *corev1.VolumeMount
+		if c.ClaimName != "" {
+			mount = &corev1.VolumeMount{
+				Name:      c.volumeName(),
+				MountPath: c.mountPath(),
+			}
+		}
+		mounts[restore.Name] = mount
+		mounts[save.Name] = mount
+		restoreSteps = append(restoreSteps, restore)
+		saveSteps = append(saveSteps, save)
+	}
+	return restoreSteps, saveSteps, mounts
+}
+
+func (c *Cache) volumeName() string {
+	return MangleToRfc1035Label("cache-"+c.Name, "")
+}
+
+func (c *Cache) mountPath() string {
+	return filepath.Join(CacheMountPathRoot, c.Name)
+}
+
+func (c *Cache) volume() corev1.Volume {
+	return corev1.Volume{
+		Name: c.volumeName(),
+		VolumeSource: corev1.VolumeSource{
+			PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+				ClaimName: c.ClaimName,
+			},
+		},
+	}
+}
+
 // PipelineRunName returns the pipeline name given the pipeline and build identifier
 func PipelineRunName(pipelineIdentifier string, buildIdentifier string) string {
 	return MangleToRfc1035Label(fmt.Sprintf("%s", pipelineIdentifier), buildIdentifier)
@@ -1877,6 +2306,8 @@ func (j *ParsedPipeline) GenerateCRDs(params CRDsFromPipelineParams) (*tektonv1a
 
 	var parentContainer *corev1.Container
 	var parentVolumes []*corev1.Volume
+	var parentCaches []*Cache
+	var parentSecrets []*Secret
 
 	baseWorkingDir := j.WorkingDir
 
@@ -1887,6 +2318,8 @@ func (j *ParsedPipeline) GenerateCRDs(params CRDsFromPipelineParams) (*tektonv1a
 		}
 		parentContainer = o.ContainerOptions
 		parentVolumes = o.Volumes
+		parentCaches = o.Caches
+		parentSecrets = o.Secrets
 	}
 
 	p := &tektonv1alpha1.Pipeline{
@@ -1927,8 +2360,9 @@ func (j *ParsedPipeline) GenerateCRDs(params CRDsFromPipelineParams) (*tektonv1a
 
 	baseEnv := j.GetEnv()
 
-	for i, s := range j.Stages {
-		isLastStage := i == len(j.Stages)-1
+	stages := expandMatrixStages(j.Stages)
+	for i, s := range stages {
+		isLastStage := i == len(stages)-1
 
 		stage, err := stageToTask(stageToTaskParams{
 			parentParams:         params,
@@ -1939,6 +2373,8 @@ func (j *ParsedPipeline) GenerateCRDs(params CRDsFromPipelineParams) (*tektonv1a
 			parentWorkspace:      "default",
 			parentContainer:      parentContainer,
 			parentVolumes:        parentVolumes,
+			parentCaches:         parentCaches,
+			parentSecrets:        parentSecrets,
 			depth:                0,
 			previousSiblingStage: previousStage,
 		})
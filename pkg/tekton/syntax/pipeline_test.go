@@ -11,6 +11,7 @@ import (
 	v1 "github.com/jenkins-x/jx/v2/pkg/apis/jenkins.io/v1"
 	"github.com/jenkins-x/jx/v2/pkg/versionstream"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"k8s.io/apimachinery/pkg/api/resource"
 
 	"github.com/google/go-cmp/cmp"
@@ -24,6 +25,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	kubefake "k8s.io/client-go/kubernetes/fake"
+	"sigs.k8s.io/yaml"
 )
 
 var (
@@ -2309,3 +2311,614 @@ func TestParsedPipelineHelpers(t *testing.T) {
 		t.Fatalf("ParsedPipeline diff -want, +got: %v", d)
 	}
 }
+
+func TestGenerateCRDsWithCaches(t *testing.T) {
+	parsed := &syntax.ParsedPipeline{
+		Agent: &syntax.Agent{
+			Image: "some-image",
+		},
+		Options: &syntax.RootOptions{
+			Caches: []*syntax.Cache{
+				{
+					Name:  "maven",
+					Paths: []string{"~/.m2/repository"},
+				},
+				{
+					Name:      "node",
+					Paths:     []string{"node_modules"},
+					ClaimName: "node-cache",
+				},
+			},
+		},
+		Stages: []syntax.Stage{{
+			Name: "build",
+			Steps: []syntax.Step{{
+				Command:   "mvn",
+				Arguments: []string{"install"},
+			}},
+			Options: &syntax.StageOptions{
+				RootOptions: &syntax.RootOptions{
+					Caches: []*syntax.Cache{{
+						Name:  "maven",
+						Key:   "release",
+						Paths: []string{"~/.m2/repository"},
+					}},
+				},
+			},
+		}},
+	}
+	require.Nil(t, parsed.Validate(context.Background()))
+
+	_, tasks, _, err := parsed.GenerateCRDs(syntax.CRDsFromPipelineParams{
+		PipelineIdentifier: "somepipeline",
+		BuildIdentifier:    "1",
+		Namespace:          "jx",
+		VersionsDir:        filepath.Join("test_data", "stable_versions"),
+		SourceDir:          "source",
+	})
+	require.NoError(t, err)
+	require.Len(t, tasks, 1)
+
+	var names []string
+	var restoreNode *tektonv1alpha1.Step
+	for i, s := range tasks[0].Spec.Steps {
+		names = append(names, s.Name)
+		if s.Name == "restore-cache-node" {
+			restoreNode = &tasks[0].Spec.Steps[i]
+		}
+	}
+	assert.Equal(t, []string{"git-merge", "restore-cache-maven", "restore-cache-node", "step2", "save-cache-maven", "save-cache-node"}, names)
+	assert.Contains(t, tasks[0].Spec.Steps[1].Args[0], "jx step cache restore --name maven --key release --path ~/.m2/repository")
+
+	require.NotNil(t, restoreNode)
+	assert.Contains(t, restoreNode.Args[0], "--dir /jx-cache/node")
+	assert.Equal(t, []corev1.VolumeMount{{Name: "cache-node", MountPath: "/jx-cache/node"}}, restoreNode.VolumeMounts)
+
+	var claims []string
+	for _, v := range tasks[0].Spec.Volumes {
+		if v.PersistentVolumeClaim != nil {
+			claims = append(claims, v.PersistentVolumeClaim.ClaimName)
+		}
+	}
+	assert.Equal(t, []string{"node-cache"}, claims)
+}
+
+func TestValidateCaches(t *testing.T) {
+	parsed := &syntax.ParsedPipeline{
+		Agent: &syntax.Agent{
+			Image: "some-image",
+		},
+		Options: &syntax.RootOptions{
+			Caches: []*syntax.Cache{{
+				Name: "maven",
+			}},
+		},
+		Stages: []syntax.Stage{{
+			Name:  "build",
+			Steps: []syntax.Step{{Command: "mvn"}},
+		}},
+	}
+	err := parsed.Validate(context.Background())
+	require.NotNil(t, err)
+	assert.Equal(t, "paths to cache must be provided", err.Message)
+}
+
+func TestGenerateCRDsWithSecrets(t *testing.T) {
+	parsed := &syntax.ParsedPipeline{}
+	err := yaml.Unmarshal([]byte(`
+agent:
+  image: some-image
+options:
+  secrets:
+  - sonar-token
+  - name: db
+    key: password
+    env: DB_PASSWORD
+stages:
+- name: build
+  options:
+    secrets:
+    - name: signing
+      key: key.gpg
+      mountPath: /secrets/key.gpg
+  steps:
+  - command: mvn
+    args:
+    - install
+`), parsed)
+	require.NoError(t, err)
+	assert.Equal(t, []*syntax.Secret{{Name: "sonar-token"}, {Name: "db", Key: "password", Env: "DB_PASSWORD"}}, parsed.Options.Secrets)
+	require.Nil(t, parsed.Validate(context.Background()))
+
+	_, tasks, _, err := parsed.GenerateCRDs(syntax.CRDsFromPipelineParams{
+		PipelineIdentifier: "somepipeline",
+		BuildIdentifier:    "1",
+		Namespace:          "jx",
+		VersionsDir:        filepath.Join("test_data", "stable_versions"),
+		SourceDir:          "source",
+	})
+	require.NoError(t, err)
+	require.Len(t, tasks, 1)
+
+	var step *tektonv1alpha1.Step
+	for i, s := range tasks[0].Spec.Steps {
+		if s.Name == "step2" {
+			step = &tasks[0].Spec.Steps[i]
+		}
+	}
+	require.NotNil(t, step)
+	assert.Equal(t, []corev1.EnvFromSource{{
+		SecretRef: &corev1.SecretEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "sonar-token"}},
+	}}, step.EnvFrom)
+	var dbPassword *corev1.EnvVar
+	for i, e := range step.Env {
+		if e.Name == "DB_PASSWORD" {
+			dbPassword = &step.Env[i]
+		}
+	}
+	require.NotNil(t, dbPassword)
+	require.NotNil(t, dbPassword.ValueFrom)
+	assert.Equal(t, &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "db"}, Key: "password"}, dbPassword.ValueFrom.SecretKeyRef)
+	assert.Equal(t, []corev1.VolumeMount{{Name: "secret-signing", MountPath: "/secrets/key.gpg", SubPath: "key.gpg", ReadOnly: true}}, step.VolumeMounts)
+
+	var secretVolumes []string
+	for _, v := range tasks[0].Spec.Volumes {
+		if v.Secret != nil {
+			secretVolumes = append(secretVolumes, v.Secret.SecretName)
+		}
+	}
+	assert.Equal(t, []string{"signing"}, secretVolumes)
+}
+
+func TestGenerateCRDsWithNilStageSecret(t *testing.T) {
+	parsed := &syntax.ParsedPipeline{}
+	err := yaml.Unmarshal([]byte(`
+agent:
+  image: some-image
+stages:
+- name: build
+  options:
+    secrets:
+    - name: signing
+      key: key.gpg
+      mountPath: /secrets/key.gpg
+  steps:
+  - command: mvn
+    args:
+    - install
+`), parsed)
+	require.NoError(t, err)
+	parsed.Stages[0].Options.Secrets = append([]*syntax.Secret{nil}, parsed.Stages[0].Options.Secrets...)
+
+	_, tasks, _, err := parsed.GenerateCRDs(syntax.CRDsFromPipelineParams{
+		PipelineIdentifier: "somepipeline",
+		BuildIdentifier:    "1",
+		Namespace:          "jx",
+		VersionsDir:        filepath.Join("test_data", "stable_versions"),
+		SourceDir:          "source",
+	})
+	require.NoError(t, err)
+	require.Len(t, tasks, 1)
+
+	var secretVolumes []string
+	for _, v := range tasks[0].Spec.Volumes {
+		if v.Secret != nil {
+			secretVolumes = append(secretVolumes, v.Secret.SecretName)
+		}
+	}
+	assert.Equal(t, []string{"signing"}, secretVolumes, "the nil secret should be skipped")
+}
+
+func TestValidateSecrets(t *testing.T) {
+	parsed := &syntax.ParsedPipeline{
+		Agent: &syntax.Agent{
+			Image: "some-image",
+		},
+		Options: &syntax.RootOptions{
+			Secrets: []*syntax.Secret{{
+				Name: "db",
+				Env:  "DB_PASSWORD",
+			}},
+		},
+		Stages: []syntax.Stage{{
+			Name:  "build",
+			Steps: []syntax.Step{{Command: "mvn"}},
+		}},
+	}
+	err := parsed.Validate(context.Background())
+	require.NotNil(t, err)
+	assert.Equal(t, "The key of secret db to inject as environment variable DB_PASSWORD must be specified", err.Message)
+
+	parsed.Options.Secrets[0].Key = "password"
+	kubeClient := kubefake.NewSimpleClientset(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "jx"},
+		Data:       map[string][]byte{"username": []byte("admin")},
+	})
+	err = parsed.ValidateInCluster(context.Background(), kubeClient, "jx")
+	require.NotNil(t, err)
+	assert.Equal(t, "Secret db does not have the key password", err.Message)
+
+	parsed.Options.Secrets[0].Name = "missing"
+	err = parsed.ValidateInCluster(context.Background(), kubeClient, "jx")
+	require.NotNil(t, err)
+	assert.Equal(t, "Secret missing does not exist, so cannot be injected into the pipeline", err.Message)
+}
+
+func TestReplacePlaceholdersWithPlatforms(t *testing.T) {
+	newPipeline := func() *syntax.ParsedPipeline {
+		return &syntax.ParsedPipeline{
+			Stages: []syntax.Stage{{
+				Name: "build",
+				Steps: []syntax.Step{{
+					Name:    "container-build",
+					Command: "skaffold build -f skaffold.yaml",
+				}},
+			}},
+		}
+	}
+	args := syntax.StepPlaceholderReplacementArgs{
+		WorkspaceDir:      "/workspace/source",
+		GitName:           "myapp",
+		GitOrg:            "myorg",
+		DockerRegistry:    "gcr.io",
+		DockerRegistryOrg: "myproject",
+		ProjectID:         "myproject",
+		KanikoImage:       "gcr.io/kaniko-project/executor:v0.22.0",
+		UseKaniko:         true,
+		Platforms:         []string{"linux/arm64"},
+	}
+
+	parsed := newPipeline()
+	parsed.ReplacePlaceholdersInStepAndStageDirs(args)
+	require.Len(t, parsed.Stages[0].Steps, 1)
+	assert.Contains(t, parsed.Stages[0].Steps[0].Arguments, "--customPlatform=linux/arm64")
+
+	args.Platforms = []string{"linux/amd64", "linux/arm64"}
+	parsed = newPipeline()
+	parsed.ReplacePlaceholdersInStepAndStageDirs(args)
+	steps := parsed.Stages[0].Steps
+	require.Len(t, steps, 3)
+
+	assert.Equal(t, "container-build-linux-amd64", steps[0].Name)
+	assert.Equal(t, "/kaniko/executor", steps[0].Command)
+	assert.Contains(t, steps[0].Arguments, "--destination=gcr.io/myproject/myapp:${inputs.params.version}-linux-amd64")
+	assert.Contains(t, steps[0].Arguments, "--customPlatform=linux/amd64")
+
+	assert.Equal(t, "container-build-linux-arm64", steps[1].Name)
+	assert.Contains(t, steps[1].Arguments, "--destination=gcr.io/myproject/myapp:${inputs.params.version}-linux-arm64")
+	assert.Contains(t, steps[1].Arguments, "--customPlatform=linux/arm64")
+
+	assert.Equal(t, "container-build-multi-arch", steps[2].Name)
+	assert.Equal(t, syntax.ImageIndexDockerImage, steps[2].Image)
+	assert.Equal(t, syntax.ImageIndexCommand, steps[2].Command)
+	assert.Equal(t, []string{
+		"--tag=gcr.io/myproject/myapp:${inputs.params.version}",
+		"--manifest=gcr.io/myproject/myapp:${inputs.params.version}-linux-amd64",
+		"--manifest=gcr.io/myproject/myapp:${inputs.params.version}-linux-arm64",
+	}, steps[2].Arguments)
+
+	assert.NoError(t, syntax.ValidatePlatform("linux/arm/v7"))
+	assert.Error(t, syntax.ValidatePlatform("arm64"))
+}
+
+func TestReplacePlaceholdersWithSupplyChain(t *testing.T) {
+	newPipeline := func() *syntax.ParsedPipeline {
+		return &syntax.ParsedPipeline{
+			Stages: []syntax.Stage{{
+				Name: "build",
+				Steps: []syntax.Step{
+					{
+						Name:    "container-build",
+						Command: "skaffold build -f skaffold.yaml",
+					},
+					{
+						Name:    "tests",
+						Command: "make test",
+					},
+				},
+			}},
+		}
+	}
+	args := syntax.StepPlaceholderReplacementArgs{
+		WorkspaceDir:      "/workspace/source",
+		GitName:           "myapp",
+		GitOrg:            "myorg",
+		DockerRegistry:    "gcr.io",
+		DockerRegistryOrg: "myproject",
+		ProjectID:         "myproject",
+		KanikoImage:       "gcr.io/kaniko-project/executor:v0.22.0",
+		UseKaniko:         true,
+	}
+
+	parsed := newPipeline()
+	parsed.ReplacePlaceholdersInStepAndStageDirs(args)
+	require.Len(t, parsed.Stages[0].Steps, 2)
+
+	args.GenerateSBOM = true
+	args.SignImages = true
+	parsed = newPipeline()
+	parsed.ReplacePlaceholdersInStepAndStageDirs(args)
+	steps := parsed.Stages[0].Steps
+	require.Len(t, steps, 4)
+
+	assert.Equal(t, "/kaniko/executor", steps[0].Command)
+
+	assert.Equal(t, "container-build-sbom", steps[1].Name)
+	assert.Equal(t, syntax.JxDockerImage, steps[1].Image)
+	assert.Equal(t, syntax.SBOMCommand, steps[1].Command)
+	assert.Equal(t, []string{"--image", "gcr.io/myproject/myapp:${inputs.params.version}", "--output-file", "sbom.spdx.json"}, steps[1].Arguments)
+	assert.Equal(t, steps[0].Dir, steps[1].Dir)
+
+	assert.Equal(t, "container-build-sign", steps[2].Name)
+	assert.Equal(t, syntax.SignImageCommand, steps[2].Command)
+	assert.Equal(t, []string{"--image", "gcr.io/myproject/myapp:${inputs.params.version}", "--sbom", "sbom.spdx.json"}, steps[2].Arguments)
+
+	assert.Equal(t, "tests", steps[3].Name)
+
+	// multi-arch images are signed once they are combined
+	args.GenerateSBOM = false
+	args.Platforms = []string{"linux/amd64", "linux/arm64"}
+	parsed = newPipeline()
+	parsed.ReplacePlaceholdersInStepAndStageDirs(args)
+	steps = parsed.Stages[0].Steps
+	require.Len(t, steps, 5)
+	assert.Equal(t, "container-build-multi-arch", steps[2].Name)
+	assert.Equal(t, "container-build-sign", steps[3].Name)
+	assert.Equal(t, []string{"--image", "gcr.io/myproject/myapp:${inputs.params.version}"}, steps[3].Arguments)
+}
+
+func TestGenerateCRDsWithMatrix(t *testing.T) {
+	parsed := &syntax.ParsedPipeline{
+		Agent: &syntax.Agent{
+			Image: "some-image",
+		},
+		Stages: []syntax.Stage{{
+			Name: "test",
+			Env:  []corev1.EnvVar{{Name: "GOARCH", Value: "386"}, {Name: "CGO_ENABLED", Value: "0"}},
+			Matrix: &syntax.Matrix{
+				Axes: []syntax.MatrixAxis{
+					{Name: "GO_VERSION", Values: []string{"1.12", "1.13"}},
+					{Name: "GOARCH", Values: []string{"amd64", "arm64"}},
+				},
+			},
+			Steps: []syntax.Step{{
+				Command:   "make",
+				Arguments: []string{"test"},
+			}},
+		}},
+	}
+	require.Nil(t, parsed.Validate(context.Background()))
+
+	pipeline, tasks, structure, err := parsed.GenerateCRDs(syntax.CRDsFromPipelineParams{
+		PipelineIdentifier: "somepipeline",
+		BuildIdentifier:    "1",
+		Namespace:          "jx",
+		VersionsDir:        filepath.Join("test_data", "stable_versions"),
+		SourceDir:          "source",
+	})
+	require.NoError(t, err)
+	require.Len(t, tasks, 4)
+	assert.Len(t, pipeline.Spec.Tasks, 4)
+	assert.Len(t, structure.Stages, 5, "the matrix stage and its combinations should be in the structure")
+
+	var names []string
+	for _, task := range tasks {
+		names = append(names, task.Labels[syntax.LabelStageName])
+	}
+	assert.Equal(t, []string{"test-1-12-amd64", "test-1-12-arm64", "test-1-13-amd64", "test-1-13-arm64"}, names)
+
+	last := tasks[3]
+	assert.Equal(t, "1.13", last.Labels[syntax.LabelMatrixPrefix+"GO_VERSION"])
+	assert.Equal(t, "arm64", last.Labels[syntax.LabelMatrixPrefix+"GOARCH"])
+	env := map[string]string{}
+	for _, e := range last.Spec.Steps[len(last.Spec.Steps)-1].Env {
+		env[e.Name] = e.Value
+	}
+	assert.Equal(t, "1.13", env["GO_VERSION"])
+	assert.Equal(t, "arm64", env["GOARCH"], "the matrix value should override the stage environment")
+	assert.Equal(t, "0", env["CGO_ENABLED"])
+}
+
+func TestValidateMatrix(t *testing.T) {
+	parsed := &syntax.ParsedPipeline{
+		Agent: &syntax.Agent{
+			Image: "some-image",
+		},
+		Stages: []syntax.Stage{{
+			Name: "test",
+			Matrix: &syntax.Matrix{
+				Axes: []syntax.MatrixAxis{{Name: "go-version", Values: []string{"1.13"}}},
+			},
+			Steps: []syntax.Step{{Command: "make"}},
+		}},
+	}
+	err := parsed.Validate(context.Background())
+	require.NotNil(t, err)
+	assert.Equal(t, "The matrix axis name 'go-version' must be a valid environment variable name", err.Message)
+}
+
+func TestApplyPodDefaults(t *testing.T) {
+	runAsUser := int64(1000)
+	defaultContext := &corev1.PodSecurityContext{RunAsUser: &runAsUser}
+	defaultTolerations := []corev1.Toleration{{
+		Key:      "jenkins-x.io/builds",
+		Operator: corev1.TolerationOpExists,
+		Effect:   corev1.TaintEffectNoSchedule,
+	}}
+	defaultResources := corev1.ResourceRequirements{
+		Requests: corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse("500m"),
+			corev1.ResourceMemory: resource.MustParse("512Mi"),
+		},
+		Limits: corev1.ResourceList{
+			corev1.ResourceMemory: resource.MustParse("2Gi"),
+		},
+	}
+
+	pipeline := &syntax.ParsedPipeline{}
+	pipeline.ApplyPodDefaults(defaultResources, map[string]string{"pool": "builds"}, defaultTolerations, defaultContext)
+	require.NotNil(t, pipeline.Options)
+	require.NotNil(t, pipeline.Options.ContainerOptions)
+	assert.Equal(t, "500m", pipeline.Options.ContainerOptions.Resources.Requests.Cpu().String())
+	assert.Equal(t, "2Gi", pipeline.Options.ContainerOptions.Resources.Limits.Memory().String())
+	assert.Equal(t, map[string]string{"pool": "builds"}, pipeline.GetNodeSelector())
+	assert.Equal(t, defaultTolerations, pipeline.GetTolerations())
+	assert.Equal(t, defaultContext, pipeline.GetSecurityContext())
+
+	// the options of the pipeline override the defaults
+	overrideTolerations := []corev1.Toleration{{Key: "gpu", Operator: corev1.TolerationOpExists}}
+	pipeline = &syntax.ParsedPipeline{
+		Options: &syntax.RootOptions{
+			ContainerOptions: &corev1.Container{
+				Resources: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{
+						corev1.ResourceMemory: resource.MustParse("4Gi"),
+					},
+				},
+			},
+			NodeSelector: map[string]string{"pool": "gpu"},
+			Tolerations:  overrideTolerations,
+		},
+	}
+	pipeline.ApplyPodDefaults(defaultResources, map[string]string{"pool": "builds"}, defaultTolerations, defaultContext)
+	assert.Equal(t, "500m", pipeline.Options.ContainerOptions.Resources.Requests.Cpu().String())
+	assert.Equal(t, "4Gi", pipeline.Options.ContainerOptions.Resources.Requests.Memory().String())
+	assert.Equal(t, "2Gi", pipeline.Options.ContainerOptions.Resources.Limits.Memory().String())
+	assert.Equal(t, map[string]string{"pool": "gpu"}, pipeline.GetNodeSelector())
+	assert.Equal(t, overrideTolerations, pipeline.GetTolerations())
+	assert.Equal(t, defaultContext, pipeline.GetSecurityContext())
+}
+
+func TestValidatePodOptionsInStage(t *testing.T) {
+	pipeline := &syntax.ParsedPipeline{
+		Agent: &syntax.Agent{Image: "maven"},
+		Stages: []syntax.Stage{{
+			Name: "build",
+			Options: &syntax.StageOptions{
+				RootOptions: &syntax.RootOptions{
+					NodeSelector: map[string]string{"pool": "builds"},
+				},
+			},
+			Steps: []syntax.Step{{Command: "mvn", Arguments: []string{"install"}}},
+		}},
+	}
+	err := pipeline.Validate(context.Background())
+	require.NotNil(t, err)
+	assert.Contains(t, err.Error(), "nodeSelector")
+}
+
+func TestApplyConditions(t *testing.T) {
+	newPipeline := func() *syntax.ParsedPipeline {
+		return &syntax.ParsedPipeline{
+			Agent: &syntax.Agent{
+				Image: "some-image",
+			},
+			Env: []corev1.EnvVar{{Name: "DEPLOY", Value: "true"}},
+			Stages: []syntax.Stage{{
+				Name: "docs",
+				Conditions: &syntax.Conditions{
+					Changes: []string{"docs/**", "*.md"},
+				},
+				Steps: []syntax.Step{{Name: "publish", Command: "make", Arguments: []string{"docs"}}},
+			}, {
+				Name: "build",
+				Steps: []syntax.Step{{
+					Name:    "compile",
+					Command: "make",
+				}, {
+					Name:    "release",
+					Command: "make",
+					Conditions: &syntax.Conditions{
+						Branches: []string{"master", "release-*"},
+						Env:      map[string]string{"DEPLOY": "true"},
+					},
+				}},
+			}},
+		}
+	}
+
+	tests := []struct {
+		name          string
+		ctx           syntax.ConditionContext
+		expectedSteps [][]string
+	}{
+		{
+			name: "pull request changing code",
+			ctx: syntax.ConditionContext{
+				Branch:       "PR-1",
+				ChangedFiles: []string{"pkg/main.go"},
+			},
+			expectedSteps: [][]string{{syntax.SkippedStepName}, {"compile"}},
+		},
+		{
+			name: "release changing docs",
+			ctx: syntax.ConditionContext{
+				Branch:       "master",
+				ChangedFiles: []string{"docs/guide/index.html", "pkg/main.go"},
+			},
+			expectedSteps: [][]string{{"publish"}, {"compile", "release"}},
+		},
+		{
+			name: "changed markdown file in any directory",
+			ctx: syntax.ConditionContext{
+				Branch:       "release-1.0",
+				ChangedFiles: []string{"charts/README.md"},
+				Env:          map[string]string{"DEPLOY": "false"},
+			},
+			expectedSteps: [][]string{{"publish"}, {"compile"}},
+		},
+		{
+			name: "unknown changed files",
+			ctx: syntax.ConditionContext{
+				Branch: "feature",
+			},
+			expectedSteps: [][]string{{"publish"}, {"compile"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parsed := newPipeline()
+			require.Nil(t, parsed.Validate(context.Background()))
+			parsed.ApplyConditions(tt.ctx)
+
+			require.Len(t, parsed.Stages, len(tt.expectedSteps))
+			for i, stage := range parsed.Stages {
+				var names []string
+				for _, step := range stage.Steps {
+					names = append(names, step.Name)
+				}
+				assert.Equal(t, tt.expectedSteps[i], names, "steps of stage %s", stage.Name)
+			}
+			_, _, _, err := parsed.GenerateCRDs(syntax.CRDsFromPipelineParams{
+				PipelineIdentifier: "somepipeline",
+				BuildIdentifier:    "1",
+				Namespace:          "jx",
+				VersionsDir:        filepath.Join("test_data", "stable_versions"),
+				SourceDir:          "source",
+			})
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestValidateConditions(t *testing.T) {
+	parsed := &syntax.ParsedPipeline{
+		Agent: &syntax.Agent{
+			Image: "some-image",
+		},
+		Stages: []syntax.Stage{{
+			Name: "test",
+			Steps: []syntax.Step{{
+				Command: "make",
+				Conditions: &syntax.Conditions{
+					Changes: []string{"src/[a-"},
+				},
+			}},
+		}},
+	}
+	err := parsed.Validate(context.Background())
+	require.NotNil(t, err)
+	assert.Equal(t, "The change pattern 'src/[a-' is not a valid glob", err.Message)
+	assert.Equal(t, []string{"stages[0].steps[0].conditions.changes"}, err.Paths)
+}
@@ -0,0 +1,88 @@
+package syntax
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const (
+	kanikoExecutorCommand = "/kaniko/executor"
+	destinationArgPrefix  = "--destination="
+)
+
+var platformRegex = regexp.MustCompile(`^[a-z0-9]+/[a-z0-9_]+(/[a-z0-9]+)?$`)
+
+// ValidatePlatform returns an error if the platform is not of the form os/arch[/variant] such as linux/arm64
+func ValidatePlatform(platform string) error {
+	if !platformRegex.MatchString(platform) {
+		return fmt.Errorf("the platform %s must be of the form os/arch[/variant] such as linux/arm64", platform)
+	}
+	return nil
+}
+
+// PlatformTagSuffix returns the suffix of the tag of the image built for the platform, such as linux-arm64
+func PlatformTagSuffix(platform string) string {
+	return strings.Replace(platform, "/", "-", -1)
+}
+
+// platformSteps returns the step unless it builds an image with kaniko for multiple platforms, in which case it returns
+// a step building the image for each platform, tagged with the platform, followed by a step combining those images into
+// a multi-arch image with the original tag
+func (s Step) platformSteps(args StepPlaceholderReplacementArgs) []Step {
+	if len(args.Platforms) < 2 {
+		return []Step{s}
+	}
+	destination := s.kanikoDestination(args)
+	if destination == "" {
+		return []Step{s}
+	}
+	name := s.Name
+	if name == "" {
+		name = "build-image"
+	}
+
+	var answer []Step
+	indexArgs := []string{"--tag=" + destination}
+	for _, platform := range args.Platforms {
+		suffix := PlatformTagSuffix(platform)
+		step := s
+		step.Name = name + "-" + suffix
+		step.Arguments = nil
+		for _, a := range s.Arguments {
+			if strings.HasPrefix(a, destinationArgPrefix) {
+				a = destinationArgPrefix + destination + "-" + suffix
+			}
+			step.Arguments = append(step.Arguments, a)
+		}
+		step.Arguments = append(step.Arguments, "--customPlatform="+platform)
+		answer = append(answer, step)
+		indexArgs = append(indexArgs, "--manifest="+destination+"-"+suffix)
+	}
+	if ipAddressRegistryRegex.MatchString(destination) {
+		indexArgs = append(indexArgs, "--insecure")
+	}
+	answer = append(answer, Step{
+		Name:      name + "-multi-arch",
+		Image:     ImageIndexDockerImage,
+		Command:   ImageIndexCommand,
+		Arguments: indexArgs,
+		Dir:       s.Dir,
+		Env:       s.Env,
+	})
+	return answer
+}
+
+// kanikoDestination returns the image the step builds if it is a kaniko step, otherwise an empty string
+func (s Step) kanikoDestination(args StepPlaceholderReplacementArgs) string {
+	if !args.UseKaniko || s.Command != kanikoExecutorCommand || s.Image != args.KanikoImage {
+		return ""
+	}
+	destination := ""
+	for _, a := range s.Arguments {
+		if strings.HasPrefix(a, destinationArgPrefix) {
+			destination = strings.TrimPrefix(a, destinationArgPrefix)
+		}
+	}
+	return destination
+}
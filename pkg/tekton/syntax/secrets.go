@@ -0,0 +1,197 @@
+package syntax
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/knative/pkg/apis"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+var invalidEnvVarCharsRegex = regexp.MustCompile(`[^a-zA-Z0-9_]+`)
+
+// UnmarshalJSON allows a secret to be specified by its name only, such as `secrets: [sonar-token]`
+func (s *Secret) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err == nil {
+		*s = Secret{Name: name}
+		return nil
+	}
+	// the alias type does not have this method so unmarshalling it does not recurse
+	type secret Secret
+	answer := secret{}
+	if err := json.Unmarshal(data, &answer); err != nil {
+		return err
+	}
+	*s = Secret(answer)
+	return nil
+}
+
+// EnvName returns the name of the environment variable the key of the secret is injected as
+func (s *Secret) EnvName() string {
+	if s.Env != "" {
+		return s.Env
+	}
+	return strings.ToUpper(invalidEnvVarCharsRegex.ReplaceAllString(s.Key, "_"))
+}
+
+func (s *Secret) volumeName() string {
+	return MangleToRfc1035Label("secret-"+s.Name, "")
+}
+
+func (s *Secret) volume() corev1.Volume {
+	return corev1.Volume{
+		Name: s.volumeName(),
+		VolumeSource: corev1.VolumeSource{
+			Secret: &corev1.SecretVolumeSource{
+				SecretName: s.Name,
+			},
+		},
+	}
+}
+
+func validateSecrets(secrets []*Secret, kubeClient kubernetes.Interface, ns string) *apis.FieldError {
+	for i, s := range secrets {
+		if s == nil {
+			continue
+		}
+		if err := validateSecret(s, kubeClient, ns); err != nil {
+			return err.ViaFieldIndex("secrets", i)
+		}
+	}
+	return nil
+}
+
+func validateSecret(s *Secret, kubeClient kubernetes.Interface, ns string) *apis.FieldError {
+	if s.Name == "" {
+		return apis.ErrMissingField("name")
+	}
+	if s.MountPath != "" {
+		if s.Env != "" {
+			return apis.ErrMultipleOneOf("env", "mountPath")
+		}
+		if !filepath.IsAbs(s.MountPath) {
+			return &apis.FieldError{
+				Message: fmt.Sprintf("The mount path %s of secret %s must be absolute", s.MountPath, s.Name),
+				Paths:   []string{"mountPath"},
+			}
+		}
+	}
+	if s.Env != "" && s.Key == "" {
+		return &apis.FieldError{
+			Message: fmt.Sprintf("The key of secret %s to inject as environment variable %s must be specified", s.Name, s.Env),
+			Paths:   []string{"key"},
+		}
+	}
+	if s.Key != "" && s.MountPath == "" && !validEnvVarName(s.EnvName()) {
+		return &apis.FieldError{
+			Message: fmt.Sprintf("'%s' must be a valid environment variable name", s.EnvName()),
+			Paths:   []string{"env"},
+		}
+	}
+	if kubeClient != nil {
+		secret, err := kubeClient.CoreV1().Secrets(ns).Get(s.Name, metav1.GetOptions{})
+		if err != nil {
+			return &apis.FieldError{
+				Message: fmt.Sprintf("Secret %s does not exist, so cannot be injected into the pipeline", s.Name),
+				Paths:   []string{"name"},
+			}
+		}
+		if s.Key != "" {
+			if _, ok := secret.Data[s.Key]; !ok {
+				return &apis.FieldError{
+					Message: fmt.Sprintf("Secret %s does not have the key %s", s.Name, s.Key),
+					Paths:   []string{"key"},
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// AddSecrets adds the secrets, such as those of the pipeline configuration, to the root options of the pipeline
+// unless the pipeline already injects them
+func (j *ParsedPipeline) AddSecrets(secrets []*Secret) {
+	if len(secrets) == 0 {
+		return
+	}
+	if j.Options == nil {
+		j.Options = &RootOptions{}
+	}
+	j.Options.Secrets = mergeSecrets(j.Options.Secrets, secrets)
+}
+
+// mergeSecrets returns the secrets of a stage followed by the secrets inherited from its parent which it does not
+// already inject
+func mergeSecrets(secrets []*Secret, parentSecrets []*Secret) []*Secret {
+	answer := append([]*Secret{}, secrets...)
+	for _, parent := range parentSecrets {
+		if parent == nil {
+			continue
+		}
+		found := false
+		for _, s := range answer {
+			if s != nil && *s == *parent {
+				found = true
+				break
+			}
+		}
+		if !found {
+			answer = append(answer, parent)
+		}
+	}
+	return answer
+}
+
+// injectSecrets adds the environment variables and volume mounts of the secrets to the container of a step. The
+// environment variables of the step take precedence over those of the secrets
+func injectSecrets(container *corev1.Container, secrets []*Secret) {
+	for _, s := range secrets {
+		if s == nil {
+			continue
+		}
+		switch {
+		case s.MountPath != "":
+			container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{
+				Name:      s.volumeName(),
+				MountPath: s.MountPath,
+				SubPath:   s.Key,
+				ReadOnly:  true,
+			})
+		case s.Key != "":
+			name := s.EnvName()
+			if hasEnvVar(container.Env, name) {
+				continue
+			}
+			container.Env = append(container.Env, corev1.EnvVar{
+				Name: name,
+				ValueFrom: &corev1.EnvVarSource{
+					SecretKeyRef: &corev1.SecretKeySelector{
+						LocalObjectReference: corev1.LocalObjectReference{Name: s.Name},
+						Key:                  s.Key,
+					},
+				},
+			})
+		default:
+			container.EnvFrom = append(container.EnvFrom, corev1.EnvFromSource{
+				SecretRef: &corev1.SecretEnvSource{
+					LocalObjectReference: corev1.LocalObjectReference{Name: s.Name},
+				},
+			})
+		}
+	}
+}
+
+func hasEnvVar(env []corev1.EnvVar, name string) bool {
+	for _, e := range env {
+		if e.Name == name {
+			return true
+		}
+	}
+	return false
+}
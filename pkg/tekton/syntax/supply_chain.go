@@ -0,0 +1,49 @@
+package syntax
+
+const (
+	// sbomFile the file, in the directory of the step building the image, the SBOM of the image is written to
+	sbomFile = "sbom.spdx.json"
+)
+
+// supplyChainSteps returns the steps generating the SBOM of the image a kaniko step builds and signing the image, if
+// they are enabled, which run after the image is pushed
+func (s Step) supplyChainSteps(args StepPlaceholderReplacementArgs) []Step {
+	if !args.GenerateSBOM && !args.SignImages {
+		return nil
+	}
+	destination := s.kanikoDestination(args)
+	if destination == "" {
+		return nil
+	}
+	name := s.Name
+	if name == "" {
+		name = "build-image"
+	}
+
+	var answer []Step
+	if args.GenerateSBOM {
+		answer = append(answer, Step{
+			Name:      name + "-sbom",
+			Image:     JxDockerImage,
+			Command:   SBOMCommand,
+			Arguments: []string{"--image", destination, "--output-file", sbomFile},
+			Dir:       s.Dir,
+			Env:       s.Env,
+		})
+	}
+	if args.SignImages {
+		arguments := []string{"--image", destination}
+		if args.GenerateSBOM {
+			arguments = append(arguments, "--sbom", sbomFile)
+		}
+		answer = append(answer, Step{
+			Name:      name + "-sign",
+			Image:     JxDockerImage,
+			Command:   SignImageCommand,
+			Arguments: arguments,
+			Dir:       s.Dir,
+			Env:       s.Env,
+		})
+	}
+	return answer
+}
@@ -0,0 +1,60 @@
+package tokenexchange
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+)
+
+// DefaultURL returns the URL of the token exchange in the development namespace
+func DefaultURL(devNamespace string) string {
+	return fmt.Sprintf("http://%s.%s%s", ServiceName, devNamespace, TokensPath)
+}
+
+// RequestTokens exchanges the token of a service account in a preview environment for tokens of the given kinds
+func RequestTokens(client *http.Client, exchangeURL string, serviceAccountToken string, kinds []string) ([]*Token, error) {
+	body, err := json.Marshal(&Request{Kinds: kinds})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest(http.MethodPost, exchangeURL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+strings.TrimSpace(serviceAccountToken))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, errors.Wrapf(err, "requesting tokens from %s", exchangeURL)
+	}
+	defer resp.Body.Close() //nolint:errcheck
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("requesting tokens from %s returned %s: %s", exchangeURL, resp.Status, strings.TrimSpace(string(data)))
+	}
+	response := &Response{}
+	err = json.Unmarshal(data, response)
+	if err != nil {
+		return nil, errors.Wrapf(err, "parsing the tokens returned from %s", exchangeURL)
+	}
+	return response.Tokens, nil
+}
+
+// RequestTokensWithServiceAccount exchanges the token of the service account the pod runs as for tokens of the
+// given kinds
+func RequestTokensWithServiceAccount(exchangeURL string, kinds []string) ([]*Token, error) {
+	data, err := ioutil.ReadFile(ServiceAccountTokenFile)
+	if err != nil {
+		return nil, errors.Wrapf(err, "reading the service account token %s", ServiceAccountTokenFile)
+	}
+	return RequestTokens(util.GetClient(), exchangeURL, string(data), kinds)
+}
@@ -0,0 +1,80 @@
+package tokenexchange
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/jenkins-x/jx/v2/pkg/gits/credentialhelper"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+)
+
+// DockerConfigFile returns the docker config file in $DOCKER_CONFIG or ~/.docker
+func DockerConfigFile() string {
+	dir := os.Getenv("DOCKER_CONFIG")
+	if dir == "" {
+		dir = filepath.Join(util.HomeDir(), ".docker")
+	}
+	return filepath.Join(dir, "config.json")
+}
+
+// GitCredentialsData returns the contents of a git credentials file for the git tokens
+func GitCredentialsData(tokens []*Token) ([]byte, error) {
+	var buffer bytes.Buffer
+	for _, token := range tokens {
+		if token.Kind != KindGit {
+			continue
+		}
+		credential, err := credentialhelper.CreateGitCredentialFromURL(token.Server, token.Username, token.Token)
+		if err != nil {
+			return nil, errors.Wrapf(err, "creating the git credential for %s", token.Server)
+		}
+		u, err := credential.URL()
+		if err != nil {
+			return nil, err
+		}
+		buffer.WriteString(u.String() + "\n")
+	}
+	return buffer.Bytes(), nil
+}
+
+// WriteDockerConfig adds the registry tokens to the docker config file keeping any other configuration in it
+func WriteDockerConfig(fileName string, tokens []*Token) error {
+	config := map[string]interface{}{}
+	data, err := ioutil.ReadFile(fileName)
+	if err != nil && !os.IsNotExist(err) {
+		return errors.Wrapf(err, "reading %s", fileName)
+	}
+	if len(data) > 0 {
+		err = json.Unmarshal(data, &config)
+		if err != nil {
+			return errors.Wrapf(err, "parsing %s", fileName)
+		}
+	}
+	auths, _ := config["auths"].(map[string]interface{})
+	if auths == nil {
+		auths = map[string]interface{}{}
+	}
+	for _, token := range tokens {
+		if token.Kind != KindRegistry {
+			continue
+		}
+		auths[token.Server] = map[string]interface{}{
+			"auth": base64.StdEncoding.EncodeToString([]byte(token.Username + ":" + token.Token)),
+		}
+	}
+	config["auths"] = auths
+	data, err = json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		return err
+	}
+	err = os.MkdirAll(filepath.Dir(fileName), util.DefaultWritePermissions)
+	if err != nil {
+		return errors.Wrapf(err, "creating the directory of %s", fileName)
+	}
+	return ioutil.WriteFile(fileName, data, 0600)
+}
@@ -0,0 +1,165 @@
+package tokenexchange
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	v1 "github.com/jenkins-x/jx/v2/pkg/apis/jenkins.io/v1"
+	"github.com/jenkins-x/jx/v2/pkg/client/clientset/versioned"
+	"github.com/jenkins-x/jx/v2/pkg/log"
+	"github.com/pkg/errors"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// HealthPath is the URL path for the HTTP endpoint that returns health status.
+	HealthPath = "/health"
+
+	serviceAccountUsernamePrefix = "system:serviceaccount:"
+	maxRequestSize               = 1 << 16
+)
+
+// HTTPTokenExchangeServer runs an HTTP server which exchanges the service account tokens of pods in preview
+// environments for short lived tokens minted for the preview
+type HTTPTokenExchangeServer struct {
+	bindAddress string
+	port        int
+	kubeClient  kubernetes.Interface
+	jxClient    versioned.Interface
+	namespace   string
+	minters     map[string]Minter
+}
+
+// NewHTTPTokenExchangeServer creates a new HTTPTokenExchangeServer which looks up the preview Environments in the
+// development namespace and mints tokens with the minters for each kind of token.
+// Use 'bindAddress' to control the address/interface the HTTP service will listen on; to listen on all interfaces
+// (i.e. 0.0.0.0 or ::) provide a blank string.
+func NewHTTPTokenExchangeServer(bindAddress string, port int, kubeClient kubernetes.Interface, jxClient versioned.Interface, namespace string, minters map[string]Minter) *HTTPTokenExchangeServer {
+	return &HTTPTokenExchangeServer{
+		bindAddress: bindAddress,
+		port:        port,
+		kubeClient:  kubeClient,
+		jxClient:    jxClient,
+		namespace:   namespace,
+		minters:     minters,
+	}
+}
+
+// Start the HTTP server.
+// This call will block until the server exits.
+func (s *HTTPTokenExchangeServer) Start() error {
+	mux := http.NewServeMux()
+	mux.Handle(TokensPath, s)
+	mux.Handle(HealthPath, http.HandlerFunc(s.health))
+
+	log.Logger().Infof("Exchanging tokens at http://%s:%d%s", s.bindAddress, s.port, TokensPath)
+	return http.ListenAndServe(s.bindAddress+":"+strconv.Itoa(s.port), mux)
+}
+
+// health returns HTTP 204 if the token exchange is healthy
+func (s *HTTPTokenExchangeServer) health(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ServeHTTP mints the requested tokens for the preview environment of the service account authenticating the request
+func (s *HTTPTokenExchangeServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+	request := &Request{}
+	err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestSize)).Decode(request)
+	if err != nil || len(request.Kinds) == 0 {
+		http.Error(w, "the request should contain the kinds of tokens to mint", http.StatusBadRequest)
+		return
+	}
+	scope, err := s.authenticate(r)
+	if err != nil {
+		log.Logger().Warnf("denied token request from %s: %s", r.RemoteAddr, err)
+		http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+		return
+	}
+
+	response := &Response{}
+	for _, kind := range request.Kinds {
+		minter := s.minters[kind]
+		if minter == nil {
+			http.Error(w, fmt.Sprintf("cannot mint %s tokens", kind), http.StatusBadRequest)
+			return
+		}
+		token, err := minter.Mint(scope)
+		if err != nil {
+			log.Logger().Errorf("failed to mint a %s token for preview %s: %s", kind, scope.Environment, err)
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+		token.Kind = kind
+		response.Tokens = append(response.Tokens, token)
+	}
+	log.Logger().Infof("Minted %s tokens for service account %s in preview namespace %s", strings.Join(request.Kinds, ", "), scope.ServiceAccount, scope.Namespace)
+
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		log.Logger().Warnf("failed to write the tokens: %s", err)
+	}
+}
+
+// authenticate reviews the bearer token of the request returning the scope of the preview environment whose
+// namespace the service account is in
+func (s *HTTPTokenExchangeServer) authenticate(r *http.Request) (*Scope, error) {
+	header := r.Header.Get("Authorization")
+	bearer := strings.TrimSpace(strings.TrimPrefix(header, "Bearer "))
+	if !strings.HasPrefix(header, "Bearer ") || bearer == "" {
+		return nil, errors.New("no bearer token")
+	}
+	review, err := s.kubeClient.AuthenticationV1().TokenReviews().Create(&authenticationv1.TokenReview{
+		Spec: authenticationv1.TokenReviewSpec{
+			Token: bearer,
+		},
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "reviewing the token")
+	}
+	if !review.Status.Authenticated {
+		return nil, errors.Errorf("the token is not authenticated: %s", review.Status.Error)
+	}
+	ns, name, err := splitServiceAccountUsername(review.Status.User.Username)
+	if err != nil {
+		return nil, err
+	}
+	return s.previewScope(ns, name)
+}
+
+// previewScope returns the scope of the preview Environment deployed to the namespace
+func (s *HTTPTokenExchangeServer) previewScope(ns string, serviceAccount string) (*Scope, error) {
+	environments, err := s.jxClient.JenkinsV1().Environments(s.namespace).List(metav1.ListOptions{})
+	if err != nil {
+		return nil, errors.Wrapf(err, "listing the environments in namespace %s", s.namespace)
+	}
+	for _, env := range environments.Items {
+		if env.Spec.Kind == v1.EnvironmentKindTypePreview && env.Spec.Namespace == ns {
+			return &Scope{
+				Namespace:      ns,
+				ServiceAccount: serviceAccount,
+				Environment:    env.Name,
+				RepositoryURL:  env.Spec.Source.URL,
+			}, nil
+		}
+	}
+	return nil, errors.Errorf("the namespace %s of service account %s is not a preview environment", ns, serviceAccount)
+}
+
+// splitServiceAccountUsername returns the namespace and name of the service account with the authenticated username
+func splitServiceAccountUsername(username string) (string, string, error) {
+	parts := strings.Split(strings.TrimPrefix(username, serviceAccountUsernamePrefix), ":")
+	if !strings.HasPrefix(username, serviceAccountUsernamePrefix) || len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", errors.Errorf("the user %s is not a service account", username)
+	}
+	return parts[0], parts[1], nil
+}
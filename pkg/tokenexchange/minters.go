@@ -0,0 +1,146 @@
+package tokenexchange
+
+import (
+	"encoding/base64"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ecr"
+	"github.com/jenkins-x/jx/v2/pkg/auth"
+	"github.com/jenkins-x/jx/v2/pkg/cloud/amazon/session"
+	"github.com/jenkins-x/jx/v2/pkg/gits"
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/pkg/errors"
+)
+
+const (
+	// gcrTokenUsername the username used with Google OAuth access tokens by container registries
+	gcrTokenUsername = "oauth2accesstoken"
+	// gcrTokenLifetime Google OAuth access tokens last an hour, lets leave a margin as we do not know when it was minted
+	gcrTokenLifetime = 55 * time.Minute
+)
+
+// DefaultGitPermissions the permissions of the git tokens minted for previews, which can read the repository and
+// report on its pull requests
+var DefaultGitPermissions = map[string]string{
+	"contents":      "read",
+	"pull_requests": "write",
+	"statuses":      "write",
+}
+
+// GitHubAppMinter mints installation tokens of the GitHub App installed in the owner of the preview repository which
+// can only access that repository
+type GitHubAppMinter struct {
+	AuthConfig  *auth.AuthConfig
+	Permissions map[string]string
+}
+
+// Mint mints a GitHub App installation token restricted to the repository of the preview
+func (m *GitHubAppMinter) Mint(scope *Scope) (*Token, error) {
+	repo, err := gits.ParseGitURL(scope.RepositoryURL)
+	if err != nil {
+		return nil, errors.Wrapf(err, "parsing the repository URL %s of preview %s", scope.RepositoryURL, scope.Environment)
+	}
+	serverURL := repo.HostURLWithoutUser()
+	server := m.AuthConfig.GetServer(serverURL)
+	if server == nil {
+		return nil, errors.Errorf("no git server %s in the auth config", serverURL)
+	}
+	user := githubAppUser(server, repo.Organisation)
+	if user == nil {
+		return nil, errors.Errorf("no GitHub App installation for %s on git server %s", repo.Organisation, serverURL)
+	}
+	permissions := m.Permissions
+	if len(permissions) == 0 {
+		permissions = DefaultGitPermissions
+	}
+	token, err := gits.MintScopedGitHubAppToken(server.URL, user, []string{repo.Name}, permissions)
+	if err != nil {
+		return nil, err
+	}
+	return &Token{
+		Server:   server.URL,
+		Username: gits.GitHubAppTokenUsername,
+		Token:    token.AccessToken,
+		Expiry:   token.Expiry,
+	}, nil
+}
+
+// githubAppUser returns the GitHub App user of the server installed in the owner or nil if there is none
+func githubAppUser(server *auth.AuthServer, owner string) *auth.UserAuth {
+	var answer *auth.UserAuth
+	for _, user := range server.Users {
+		if !user.IsGithubApp() {
+			continue
+		}
+		if user.GithubAppOwner == owner {
+			return user
+		}
+		if user.GithubAppOwner == "" && answer == nil {
+			answer = user
+		}
+	}
+	return answer
+}
+
+// ECRMinter mints ECR authorization tokens, which last 12 hours and have the permissions of the IAM identity of the
+// token exchange, so it should only be allowed to pull images
+type ECRMinter struct {
+	Registry string
+	Region   string
+}
+
+// Mint mints an ECR authorization token
+func (m *ECRMinter) Mint(scope *Scope) (*Token, error) {
+	sess, err := session.NewAwsSession("", m.Region)
+	if err != nil {
+		return nil, errors.Wrap(err, "creating the AWS session")
+	}
+	output, err := ecr.New(sess).GetAuthorizationToken(&ecr.GetAuthorizationTokenInput{})
+	if err != nil {
+		return nil, errors.Wrap(err, "getting an ECR authorization token")
+	}
+	if len(output.AuthorizationData) == 0 {
+		return nil, errors.New("no ECR authorization token returned")
+	}
+	data := output.AuthorizationData[0]
+	decoded, err := base64.StdEncoding.DecodeString(aws.StringValue(data.AuthorizationToken))
+	if err != nil {
+		return nil, errors.Wrap(err, "decoding the ECR authorization token")
+	}
+	parts := strings.SplitN(string(decoded), ":", 2)
+	if len(parts) != 2 {
+		return nil, errors.New("the ECR authorization token is not a username and password")
+	}
+	return &Token{
+		Server:   m.Registry,
+		Username: parts[0],
+		Token:    parts[1],
+		Expiry:   aws.TimeValue(data.ExpiresAt),
+	}, nil
+}
+
+// GCRMinter mints OAuth access tokens of the Google service account of the token exchange, which last an hour and
+// have its permissions, so it should only be allowed to pull images
+type GCRMinter struct {
+	Registry string
+}
+
+// Mint mints an OAuth access token with gcloud
+func (m *GCRMinter) Mint(scope *Scope) (*Token, error) {
+	cmd := util.Command{
+		Name: "gcloud",
+		Args: []string{"auth", "print-access-token"},
+	}
+	output, err := cmd.RunWithoutRetry()
+	if err != nil {
+		return nil, errors.Wrap(err, "printing the access token with gcloud")
+	}
+	return &Token{
+		Server:   m.Registry,
+		Username: gcrTokenUsername,
+		Token:    strings.TrimSpace(output),
+		Expiry:   time.Now().Add(gcrTokenLifetime),
+	}, nil
+}
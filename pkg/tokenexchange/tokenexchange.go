@@ -0,0 +1,67 @@
+// Package tokenexchange exchanges the service account tokens of the pods in preview environments for short lived git
+// and container registry tokens scoped to the preview, so the long lived pipeline secrets do not have to be copied
+// into every preview namespace.
+package tokenexchange
+
+import (
+	"time"
+)
+
+const (
+	// KindGit the kind of tokens used to access the git repository of the preview
+	KindGit = "git"
+	// KindRegistry the kind of tokens used to pull images from the container registry
+	KindRegistry = "registry"
+
+	// ServiceName the name of the service of the token exchange in the development namespace
+	ServiceName = "jx-token-exchange"
+	// TokensPath the URL path tokens are requested from
+	TokensPath = "/tokens"
+	// URLEnvVar the environment variable overriding the URL of the token exchange
+	URLEnvVar = "JX_TOKEN_EXCHANGE_URL"
+	// ServiceAccountTokenFile the file the token of the service account of a pod is mounted at
+	ServiceAccountTokenFile = "/var/run/secrets/kubernetes.io/serviceaccount/token" // #nosec
+)
+
+// Scope the preview environment a token is requested for
+type Scope struct {
+	// Namespace the namespace of the preview environment
+	Namespace string
+	// ServiceAccount the name of the service account requesting the token
+	ServiceAccount string
+	// Environment the name of the preview Environment
+	Environment string
+	// RepositoryURL the URL of the git repository the preview was created from
+	RepositoryURL string
+}
+
+// Token a short lived token minted for a preview environment
+type Token struct {
+	// Kind the kind of the token, git or registry
+	Kind string `json:"kind"`
+	// Server the URL of the git server or the host of the container registry the token is used with
+	Server string `json:"server"`
+	// Username the username to use with the token
+	Username string `json:"username"`
+	// Token the token
+	Token string `json:"token"`
+	// Expiry when the token expires
+	Expiry time.Time `json:"expiry,omitempty"`
+}
+
+// Request the tokens requested by a pod in a preview environment
+type Request struct {
+	// Kinds the kinds of tokens requested
+	Kinds []string `json:"kinds"`
+}
+
+// Response the tokens minted for a request
+type Response struct {
+	Tokens []*Token `json:"tokens"`
+}
+
+// Minter mints tokens of one kind
+type Minter interface {
+	// Mint mints a short lived token which is restricted to the scope as far as the provider supports it
+	Mint(scope *Scope) (*Token, error)
+}
@@ -21,6 +21,23 @@ func GenerateSchema(target interface{}) *schemagen.Schema {
 // ValidateYaml generates a JSON schema for the given struct type, and then validates the given YAML against that
 // schema, ignoring Containers and missing fields.
 func ValidateYaml(target interface{}, data []byte) ([]string, error) {
+	resultErrors, err := ValidateYamlErrors(target, data)
+	if err != nil {
+		return nil, err
+	}
+	if len(resultErrors) == 0 {
+		return nil, nil
+	}
+	errMsgs := []string{}
+	for _, e := range resultErrors {
+		errMsgs = append(errMsgs, e.String())
+	}
+	return errMsgs, nil
+}
+
+// ValidateYamlErrors generates a JSON schema for the given struct type, and then validates the given YAML against
+// that schema returning the underlying schema errors so that callers can access the failing field and its details
+func ValidateYamlErrors(target interface{}, data []byte) ([]gojsonschema.ResultError, error) {
 	schema := GenerateSchema(target)
 
 	dataAsJSON, err := yaml.YAMLToJSON(data)
@@ -35,12 +52,7 @@ func ValidateYaml(target interface{}, data []byte) ([]string, error) {
 		return nil, err
 	}
 	if !result.Valid() {
-		errMsgs := []string{}
-		for _, e := range result.Errors() {
-			errMsgs = append(errMsgs, e.String())
-		}
-		return errMsgs, nil
+		return result.Errors(), nil
 	}
-
 	return nil, nil
 }
@@ -0,0 +1,93 @@
+package util
+
+import (
+	"strconv"
+	"strings"
+)
+
+type yamlLine struct {
+	number int
+	indent int
+	text   string
+	item   bool
+}
+
+// YamlPathLine returns the 1-based line number of the given dot separated path such as `environments.0.namespace`
+// inside the given YAML document or 0 if the path could not be found.
+//
+// This is a simple indentation based scanner intended for reporting validation errors; it does not support
+// flow style collections or multi document files.
+func YamlPathLine(data []byte, path string) int {
+	if path == "" || path == "(root)" {
+		return 0
+	}
+	lines := parseYamlLines(string(data))
+	segments := strings.Split(strings.TrimPrefix(path, "(root)."), ".")
+
+	answer := 0
+	pos := 0
+	parent := -1
+	for _, segment := range segments {
+		index, err := strconv.Atoi(segment)
+		isIndex := err == nil
+		found := false
+		childIndent := -1
+		count := 0
+		for i := pos; i < len(lines); i++ {
+			l := lines[i]
+			if l.indent < parent || (l.indent == parent && !(isIndex && l.item)) {
+				break
+			}
+			if childIndent < 0 {
+				childIndent = l.indent
+			}
+			if l.indent != childIndent {
+				continue
+			}
+			if isIndex {
+				if !l.item {
+					continue
+				}
+				if count == index {
+					found = true
+				}
+				count++
+			} else if !l.item && (strings.HasPrefix(l.text, segment+":") || strings.HasPrefix(l.text, strconv.Quote(segment)+":")) {
+				found = true
+			}
+			if found {
+				answer = l.number
+				pos = i + 1
+				parent = l.indent
+				break
+			}
+		}
+		if !found {
+			return 0
+		}
+	}
+	return answer
+}
+
+// parseYamlLines splits the YAML text into its non empty lines splitting sequence items so that
+// `- key: value` results in an item line and a `key: value` line indented after the dash
+func parseYamlLines(text string) []yamlLine {
+	var answer []yamlLine
+	for i, raw := range strings.Split(text, "\n") {
+		trimmed := strings.TrimLeft(raw, " ")
+		indent := len(raw) - len(trimmed)
+		trimmed = strings.TrimRight(trimmed, " \r")
+		for trimmed != "" && !strings.HasPrefix(trimmed, "#") {
+			if trimmed != "-" && !strings.HasPrefix(trimmed, "- ") {
+				answer = append(answer, yamlLine{number: i + 1, indent: indent, text: trimmed})
+				break
+			}
+			answer = append(answer, yamlLine{number: i + 1, indent: indent, item: true})
+			rest := strings.TrimPrefix(trimmed, "-")
+			remainder := strings.TrimLeft(rest, " ")
+			indent += 1 + len(rest) - len(remainder)
+			trimmed = remainder
+		}
+	}
+	return answer
+}
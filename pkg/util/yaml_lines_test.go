@@ -0,0 +1,46 @@
+// +build unit
+
+package util_test
+
+import (
+	"testing"
+
+	"github.com/jenkins-x/jx/v2/pkg/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestYamlPathLine(t *testing.T) {
+	t.Parallel()
+
+	data := []byte(`cluster:
+  provider: gke
+  gke:
+    projectNumber: "123"
+environments:
+  - key: dev
+    ingress:
+      domain: foo.com
+  - key: staging
+    namespace: staging
+list:
+- a: 1
+- a: 2
+  b:
+  - x
+  - y
+`)
+	testCases := map[string]int{
+		"cluster":                   1,
+		"cluster.gke.projectNumber": 4,
+		"environments.0.ingress":    7,
+		"environments.1":            9,
+		"environments.1.namespace":  10,
+		"list.1.b.1":                16,
+		"environments.2":            0,
+		"cluster.missing":           0,
+		"(root)":                    0,
+	}
+	for path, expected := range testCases {
+		assert.Equal(t, expected, util.YamlPathLine(data, path), "line for path %s", path)
+	}
+}
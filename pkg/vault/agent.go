@@ -0,0 +1,176 @@
+package vault
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/jenkins-x/jx/v2/pkg/config"
+	"github.com/pkg/errors"
+)
+
+const (
+	// AnnotationAgentInject enables the Vault Agent injector for a pod
+	AnnotationAgentInject = "vault.hashicorp.com/agent-inject"
+	// AnnotationAgentRole the Vault Kubernetes auth role the agent logs in with
+	AnnotationAgentRole = "vault.hashicorp.com/role"
+	// AnnotationAgentNamespace the Vault Enterprise namespace of the secrets
+	AnnotationAgentNamespace = "vault.hashicorp.com/namespace"
+	// AnnotationAgentPrePopulateOnly only runs the agent as an init container, so it does not keep pods which run to
+	// completion such as pipelines running
+	AnnotationAgentPrePopulateOnly = "vault.hashicorp.com/agent-pre-populate-only"
+	// AnnotationAgentInjectSecretPrefix the prefix of the annotations naming the files the secrets are written to
+	AnnotationAgentInjectSecretPrefix = "vault.hashicorp.com/agent-inject-secret-"
+	// AnnotationAgentInjectTemplatePrefix the prefix of the annotations with the templates rendering the secrets
+	AnnotationAgentInjectTemplatePrefix = "vault.hashicorp.com/agent-inject-template-"
+
+	// AgentSecretsDir the directory the agent writes the secrets into
+	AgentSecretsDir = "/vault/secrets"
+
+	// AgentInjectorChart the chart of the Vault Agent injector
+	AgentInjectorChart = "vault"
+	// AgentInjectorRepository the chart repository of the Vault Agent injector
+	AgentInjectorRepository = "https://helm.releases.hashicorp.com"
+	// AgentInjectorReleaseName the release name of the Vault Agent injector
+	AgentInjectorReleaseName = "vault-agent-injector"
+)
+
+var (
+	agentURIRegex       = regexp.MustCompile(`vault:([-_.\w\/]+):([-_.\w]+)`)
+	invalidAgentFileRex = regexp.MustCompile(`[^-_.\w]+`)
+)
+
+// AgentSecret is a key of a secret in Vault which the Vault Agent writes into a file of the pod
+type AgentSecret struct {
+	// Path the path of the secret, such as mycluster/pipelineUser
+	Path string
+	// Key the key of the value in the secret, such as token
+	Key string
+}
+
+// FileName returns the name of the file in the AgentSecretsDir the secret is written to
+func (s AgentSecret) FileName() string {
+	return invalidAgentFileRex.ReplaceAllString(strings.Replace(s.Path, "/", "-", -1)+"-"+s.Key, "-")
+}
+
+// FilePath returns the path of the file the secret is written to
+func (s AgentSecret) FilePath() string {
+	return path.Join(AgentSecretsDir, s.FileName())
+}
+
+// ParseAgentSecret parses a path:key reference to a secret, optionally prefixed with the vault: scheme
+func ParseAgentSecret(text string) (AgentSecret, error) {
+	parts := strings.Split(strings.TrimPrefix(text, "vault:"), ":")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return AgentSecret{}, fmt.Errorf("cannot parse %q as path:key", text)
+	}
+	return AgentSecret{Path: parts[0], Key: parts[1]}, nil
+}
+
+// AgentEnabled returns true if the secrets are delivered to pods with the Vault Agent injector
+func AgentEnabled(requirements *config.RequirementsConfig) bool {
+	return requirements != nil && requirements.SecretStorage == config.SecretStorageTypeVault &&
+		requirements.Vault.Agent != nil && requirements.Vault.Agent.Enabled
+}
+
+// AgentAnnotations returns the pod annotations which make the Vault Agent injector write the secrets into files. Pods
+// which run to completion should be pre populated only
+func AgentAnnotations(secrets []AgentSecret, requirements *config.RequirementsConfig, prePopulateOnly bool) map[string]string {
+	if len(secrets) == 0 {
+		return nil
+	}
+	vaultConfig := requirements.Vault
+	role := vaultConfig.KubernetesAuthRole
+	if vaultConfig.Agent != nil && vaultConfig.Agent.Role != "" {
+		role = vaultConfig.Agent.Role
+	}
+	if role == "" {
+		role = vaultConfig.ServiceAccount
+	}
+	mountPoint := vaultConfig.SecretEngineMountPoint
+	if mountPoint == "" {
+		mountPoint = DefaultKVEngineMountPoint
+	}
+	answer := map[string]string{
+		AnnotationAgentInject: "true",
+	}
+	if role != "" {
+		answer[AnnotationAgentRole] = role
+	}
+	if vaultConfig.EnterpriseNamespace != "" {
+		answer[AnnotationAgentNamespace] = vaultConfig.EnterpriseNamespace
+	}
+	if prePopulateOnly {
+		answer[AnnotationAgentPrePopulateOnly] = "true"
+	}
+	for _, secret := range secrets {
+		name := secret.FileName()
+		vaultPath := fmt.Sprintf("%s/data/%s", mountPoint, secret.Path)
+		answer[AnnotationAgentInjectSecretPrefix+name] = vaultPath
+		answer[AnnotationAgentInjectTemplatePrefix+name] = fmt.Sprintf(`{{- with secret %q -}}{{ index .Data.data %q }}{{- end -}}`, vaultPath, secret.Key)
+	}
+	return answer
+}
+
+// AgentClient replaces the vault: URIs in the chart values with the files the Vault Agent injector writes the secrets
+// to, recording the secrets so the pods can be annotated to have them injected. The secrets are never read by jx
+type AgentClient struct {
+	secrets map[AgentSecret]bool
+}
+
+// NewAgentClient creates a secret URL client for the Vault Agent injector
+func NewAgentClient() *AgentClient {
+	return &AgentClient{
+		secrets: map[AgentSecret]bool{},
+	}
+}
+
+// Read fails as the secrets are only available to the pods they are injected into
+func (c *AgentClient) Read(secretName string) (map[string]interface{}, error) {
+	return nil, agentNotReadableError(secretName)
+}
+
+// ReadObject fails as the secrets are only available to the pods they are injected into
+func (c *AgentClient) ReadObject(secretName string, secret interface{}) error {
+	return agentNotReadableError(secretName)
+}
+
+// Write fails as the secrets have to be stored in Vault
+func (c *AgentClient) Write(secretName string, data map[string]interface{}) (map[string]interface{}, error) {
+	return nil, errors.Errorf("cannot write secret %s as secrets are injected by the Vault Agent, please store it in Vault", secretName)
+}
+
+// WriteObject fails as the secrets have to be stored in Vault
+func (c *AgentClient) WriteObject(secretName string, secret interface{}) (map[string]interface{}, error) {
+	return c.Write(secretName, nil)
+}
+
+// ReplaceURIs replaces the vault: URIs in the text with the paths of the files the secrets are injected into
+func (c *AgentClient) ReplaceURIs(text string) (string, error) {
+	return agentURIRegex.ReplaceAllStringFunc(text, func(found string) string {
+		secret, err := ParseAgentSecret(found)
+		if err != nil {
+			return found
+		}
+		c.secrets[secret] = true
+		return secret.FilePath()
+	}), nil
+}
+
+// Secrets returns the secrets which have been referenced
+func (c *AgentClient) Secrets() []AgentSecret {
+	answer := []AgentSecret{}
+	for secret := range c.secrets {
+		answer = append(answer, secret)
+	}
+	sort.Slice(answer, func(i, j int) bool {
+		return answer[i].FileName() < answer[j].FileName()
+	})
+	return answer
+}
+
+func agentNotReadableError(secretName string) error {
+	return errors.Errorf("cannot read secret %s as secrets are injected into pods by the Vault Agent", secretName)
+}
@@ -0,0 +1,62 @@
+// +build unit
+
+package vault_test
+
+import (
+	"testing"
+
+	"github.com/jenkins-x/jx/v2/pkg/config"
+	"github.com/jenkins-x/jx/v2/pkg/vault"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAgentClientReplaceURIs(t *testing.T) {
+	t.Parallel()
+
+	client := vault.NewAgentClient()
+	text, err := client.ReplaceURIs("token: vault:mycluster/pipelineUser:token\npassword: vault:mycluster/admin/nexus:password\nother: vault:mycluster/pipelineUser:token\n")
+	require.NoError(t, err)
+	assert.Equal(t, "token: /vault/secrets/mycluster-pipelineUser-token\npassword: /vault/secrets/mycluster-admin-nexus-password\nother: /vault/secrets/mycluster-pipelineUser-token\n", text)
+
+	assert.Equal(t, []vault.AgentSecret{
+		{Path: "mycluster/admin/nexus", Key: "password"},
+		{Path: "mycluster/pipelineUser", Key: "token"},
+	}, client.Secrets())
+
+	_, err = client.Read("mycluster/pipelineUser")
+	assert.Error(t, err, "secrets injected by the agent cannot be read")
+}
+
+func TestAgentAnnotations(t *testing.T) {
+	t.Parallel()
+
+	requirements := config.NewRequirementsConfig()
+	requirements.SecretStorage = config.SecretStorageTypeVault
+	requirements.Vault.ServiceAccount = "tekton-bot"
+	assert.False(t, vault.AgentEnabled(requirements))
+
+	requirements.Vault.Agent = &config.VaultAgentConfig{Enabled: true}
+	assert.True(t, vault.AgentEnabled(requirements))
+
+	secret, err := vault.ParseAgentSecret("vault:mycluster/pipelineUser:token")
+	require.NoError(t, err)
+	_, err = vault.ParseAgentSecret("mycluster/pipelineUser")
+	assert.Error(t, err)
+
+	annotations := vault.AgentAnnotations([]vault.AgentSecret{secret}, requirements, true)
+	assert.Equal(t, map[string]string{
+		"vault.hashicorp.com/agent-inject":                                       "true",
+		"vault.hashicorp.com/role":                                               "tekton-bot",
+		"vault.hashicorp.com/agent-pre-populate-only":                            "true",
+		"vault.hashicorp.com/agent-inject-secret-mycluster-pipelineUser-token":   "secret/data/mycluster/pipelineUser",
+		"vault.hashicorp.com/agent-inject-template-mycluster-pipelineUser-token": `{{- with secret "secret/data/mycluster/pipelineUser" -}}{{ index .Data.data "token" }}{{- end -}}`,
+	}, annotations)
+
+	requirements.Vault.Agent.Role = "jx-pipelines"
+	annotations = vault.AgentAnnotations([]vault.AgentSecret{secret}, requirements, false)
+	assert.Equal(t, "jx-pipelines", annotations[vault.AnnotationAgentRole])
+	assert.NotContains(t, annotations, vault.AnnotationAgentPrePopulateOnly)
+
+	assert.Empty(t, vault.AgentAnnotations(nil, requirements, true))
+}
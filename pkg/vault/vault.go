@@ -28,6 +28,27 @@ const (
 	// KubernetesAuthPath defines the path under which the Kubernetes auth method is configured.
 	KubernetesAuthPath = "vaultKubernetesAuthPath"
 
+	// KubernetesAuthRole defines the role used to login with the Kubernetes auth method.
+	KubernetesAuthRole = "vaultKubernetesAuthRole"
+
+	// EnterpriseNamespace defines the Vault Enterprise namespace the secrets and auth methods are in.
+	EnterpriseNamespace = "vaultEnterpriseNamespace"
+
+	// AuthMethod defines how to authenticate against Vault, either AuthMethodKubernetes or AuthMethodToken.
+	AuthMethod = "vaultAuthMethod"
+
+	// TokenSecret defines the name of the Kubernetes secret containing the Vault token when using token auth.
+	TokenSecret = "vaultTokenSecret"
+
+	// AuthMethodKubernetes authenticates using the token of the service account with the Vault Kubernetes auth method
+	AuthMethodKubernetes = "kubernetes"
+
+	// AuthMethodToken authenticates using a Vault token stored in a Kubernetes secret
+	AuthMethodToken = "token"
+
+	// TokenSecretKey is the key of the Vault token in the token secret
+	TokenSecretKey = "token"
+
 	// DefaultKVEngineMountPoint default mount point for the KV V2 engine
 	DefaultKVEngineMountPoint = "secret"
 
@@ -57,6 +78,18 @@ type Vault struct {
 
 	// KubernetesAuthPath is the path under which the Vault Kubernetes auth method is configured.
 	KubernetesAuthPath string
+
+	// KubernetesAuthRole is the role used to login with the Kubernetes auth method. Defaults to the service account name.
+	KubernetesAuthRole string
+
+	// EnterpriseNamespace is the Vault Enterprise namespace the secrets and auth methods are in.
+	EnterpriseNamespace string
+
+	// AuthMethod is how to authenticate against Vault, either AuthMethodKubernetes or AuthMethodToken.
+	AuthMethod string
+
+	// TokenSecretName is the name of the Kubernetes secret in Namespace containing the Vault token for token auth.
+	TokenSecretName string
 }
 
 // NewExternalVault creates a external Vault instance configuration from the provided parameters.
@@ -117,6 +150,11 @@ func FromMap(data map[string]string, defaultNamespace string) (Vault, error) {
 		namespace = defaultNamespace
 	}
 
+	authMethod := data[AuthMethod]
+	if authMethod == "" {
+		authMethod = AuthMethodKubernetes
+	}
+
 	vault := Vault{
 		Name:                   data[SystemVaultName],
 		URL:                    data[URL],
@@ -124,6 +162,10 @@ func FromMap(data map[string]string, defaultNamespace string) (Vault, error) {
 		Namespace:              namespace,
 		SecretEngineMountPoint: secretEngineMountPoint,
 		KubernetesAuthPath:     kubernetesAuthPath,
+		KubernetesAuthRole:     data[KubernetesAuthRole],
+		EnterpriseNamespace:    data[EnterpriseNamespace],
+		AuthMethod:             authMethod,
+		TokenSecretName:        data[TokenSecret],
 	}
 
 	var err error
@@ -154,10 +196,27 @@ func (v *Vault) ToMap() map[string]string {
 	data[Namespace] = v.Namespace
 	data[SecretEngineMountPoint] = v.SecretEngineMountPoint
 	data[KubernetesAuthPath] = v.KubernetesAuthPath
+	data[KubernetesAuthRole] = v.KubernetesAuthRole
+	data[EnterpriseNamespace] = v.EnterpriseNamespace
+	data[AuthMethod] = v.AuthMethod
+	data[TokenSecret] = v.TokenSecretName
 
 	return data
 }
 
+// Role returns the role used to login with the Kubernetes auth method
+func (v *Vault) Role() string {
+	if v.KubernetesAuthRole != "" {
+		return v.KubernetesAuthRole
+	}
+	return v.ServiceAccountName
+}
+
+// UsesTokenAuth returns true if a Vault token is used to authenticate rather than the Kubernetes auth method
+func (v *Vault) UsesTokenAuth() bool {
+	return v.AuthMethod == AuthMethodToken
+}
+
 // validateExternalConfiguration validates the values of the Vault configuration for an external Vault setup.
 func (v *Vault) validateExternalConfiguration() error {
 	var validationErrors []error
@@ -171,8 +230,19 @@ func (v *Vault) validateExternalConfiguration() error {
 		validationErrors = append(validationErrors, err)
 	}
 
-	if v.ServiceAccountName == "" {
-		err = errors.New("service account name cannot be empty")
+	switch v.AuthMethod {
+	case AuthMethodKubernetes:
+		if v.ServiceAccountName == "" {
+			err = errors.New("service account name cannot be empty")
+			validationErrors = append(validationErrors, err)
+		}
+	case AuthMethodToken:
+		if v.TokenSecretName == "" {
+			err = errors.New("token secret cannot be empty when using token auth")
+			validationErrors = append(validationErrors, err)
+		}
+	default:
+		err = errors.Errorf("unknown auth method '%s', use %s or %s", v.AuthMethod, AuthMethodKubernetes, AuthMethodToken)
 		validationErrors = append(validationErrors, err)
 	}
 
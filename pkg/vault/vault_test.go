@@ -113,6 +113,19 @@ func TestFromMap(t *testing.T) {
 			vault.URL:             "http://myvault.acme",
 			vault.SystemVaultName: "foo",
 		}, true, false, 1, "systemVaultName and URL cannot be specified together"},
+
+		{map[string]string{
+			vault.URL:         "http://myvault.acme",
+			vault.Namespace:   "bar",
+			vault.AuthMethod:  vault.AuthMethodToken,
+			vault.TokenSecret: "vault-token",
+		}, true, true, 0, ""},
+
+		{map[string]string{
+			vault.URL:        "http://myvault.acme",
+			vault.Namespace:  "bar",
+			vault.AuthMethod: vault.AuthMethodToken,
+		}, true, false, 1, "token secret cannot be empty when using token auth"},
 	}
 
 	for _, testConfig := range testConfigs {
@@ -134,3 +147,25 @@ func TestFromMap(t *testing.T) {
 		})
 	}
 }
+
+func TestExternalVaultEnterpriseSettings(t *testing.T) {
+	v, err := vault.FromMap(map[string]string{
+		vault.URL:                 "http://myvault.acme",
+		vault.ServiceAccount:      "jx-vault",
+		vault.Namespace:           "jx",
+		vault.KubernetesAuthRole:  "jx-role",
+		vault.EnterpriseNamespace: "engineering/cd",
+	}, "foo")
+	assert.NoError(t, err)
+	assert.Equal(t, vault.AuthMethodKubernetes, v.AuthMethod)
+	assert.False(t, v.UsesTokenAuth())
+	assert.Equal(t, "jx-role", v.Role())
+	assert.Equal(t, "engineering/cd", v.EnterpriseNamespace)
+
+	roundTrip, err := vault.FromMap(v.ToMap(), "foo")
+	assert.NoError(t, err)
+	assert.Equal(t, v, roundTrip)
+
+	v.KubernetesAuthRole = ""
+	assert.Equal(t, "jx-vault", v.Role())
+}
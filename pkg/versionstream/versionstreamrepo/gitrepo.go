@@ -167,16 +167,11 @@ func clone(wrkDir string, versionRepository string, referenceName string, gitter
 		}
 		log.Logger().Debugf("Cloning the Jenkins X versions repo %s with revision %s to %s", util.ColorInfo(versionRepository), util.ColorInfo(referenceName), util.ColorInfo(wrkDir))
 
-		err := gitter.Clone(versionRepository, wrkDir)
+		err := gitter.CloneWithOptions(versionRepository, wrkDir, gits.PartialCloneOptions())
 		if err != nil {
 			return "", errors.Wrapf(err, "failed to clone repository: %s to dir %s", versionRepository, wrkDir)
 		}
-		cmd := util.Command{
-			Dir:  wrkDir,
-			Name: "git",
-			Args: []string{"fetch", "origin", referenceName},
-		}
-		_, err = cmd.RunWithoutRetry()
+		err = gitter.FetchBranchWithOptions(wrkDir, "origin", gits.PartialCloneOptions(), referenceName)
 		if err != nil {
 			return "", errors.Wrapf(err, "failed to git fetch origin %s for repo: %s in dir %s", referenceName, versionRepository, wrkDir)
 		}